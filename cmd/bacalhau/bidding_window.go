@@ -0,0 +1,158 @@
+package bacalhau
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	biddingWindowLong = templates.LongDesc(i18n.T(`
+		Show or override when a compute node bids on jobs. Compute nodes started with
+		--bidding-window only bid on jobs during those times of the week, such as nights
+		and weekends when a workstation is idle. Opening or closing bidding overrides
+		the windows for a while, or until the override is cleared.
+
+		The --api-host and --api-port flags point at the compute node. Only the clients
+		that the compute node lists as admins can override its bidding windows.
+`))
+
+	//nolint:lll // Documentation
+	biddingWindowExample = templates.Examples(i18n.T(`
+		# Show whether the compute node is bidding on jobs now
+		bacalhau bidding-window status
+
+		# Bid on jobs for the next 2 hours, whatever the bidding windows are
+		bacalhau bidding-window open --for 2h
+
+		# Stop bidding on jobs until the override is cleared
+		bacalhau bidding-window close
+
+		# Follow the bidding windows again
+		bacalhau bidding-window clear`))
+)
+
+type BiddingWindowOptions struct {
+	For          time.Duration // How long the override lasts
+	OutputFormat string        // The output format (json or text)
+}
+
+func NewBiddingWindowOptions() *BiddingWindowOptions {
+	return &BiddingWindowOptions{
+		OutputFormat: "text",
+	}
+}
+
+func newBiddingWindowCmd() *cobra.Command {
+	options := NewBiddingWindowOptions()
+
+	biddingWindowCmd := &cobra.Command{
+		Use:     "bidding-window",
+		Short:   "Show or override when a compute node bids on jobs",
+		Long:    biddingWindowLong,
+		Example: biddingWindowExample,
+	}
+
+	override := func(open bool) func(cmd *cobra.Command, _ []string) error {
+		return func(cmd *cobra.Command, _ []string) error {
+			windowOverride := &model.BiddingWindowOverride{Open: open}
+			if options.For > 0 {
+				windowOverride.Until = time.Now().Add(options.For)
+			}
+			status, err := getComputeAPIClient().OverrideBiddingWindows(cmd.Context(), windowOverride)
+			if err != nil {
+				Fatal(cmd, fmt.Sprintf("Error overriding the bidding windows of the compute node: %s", err), 1)
+				return err
+			}
+			return printBiddingWindowStatus(cmd, status, options)
+		}
+	}
+	openCmd := &cobra.Command{
+		Use:    "open",
+		Short:  "Bid on jobs whatever the bidding windows are",
+		Args:   cobra.NoArgs,
+		PreRun: applyPorcelainLogLevel,
+		RunE:   override(true),
+	}
+	closeCmd := &cobra.Command{
+		Use:    "close",
+		Short:  "Stop bidding on jobs whatever the bidding windows are",
+		Args:   cobra.NoArgs,
+		PreRun: applyPorcelainLogLevel,
+		RunE:   override(false),
+	}
+	for _, c := range []*cobra.Command{openCmd, closeCmd} {
+		c.Flags().DurationVar(&options.For, "for", options.For,
+			`How long to override the bidding windows for. Lasts until cleared if not set.`)
+	}
+
+	clearCmd := &cobra.Command{
+		Use:    "clear",
+		Short:  "Follow the bidding windows again",
+		Args:   cobra.NoArgs,
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			status, err := getComputeAPIClient().OverrideBiddingWindows(cmd.Context(), nil)
+			if err != nil {
+				Fatal(cmd, fmt.Sprintf("Error clearing the bidding window override of the compute node: %s", err), 1)
+				return err
+			}
+			return printBiddingWindowStatus(cmd, status, options)
+		},
+	}
+
+	statusCmd := &cobra.Command{
+		Use:    "status",
+		Short:  "Show whether a compute node is bidding on jobs now",
+		Args:   cobra.NoArgs,
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			status, err := getComputeAPIClient().BiddingWindowStatus(cmd.Context())
+			if err != nil {
+				Fatal(cmd, fmt.Sprintf("Error getting the bidding windows of the compute node: %s", err), 1)
+				return err
+			}
+			return printBiddingWindowStatus(cmd, status, options)
+		},
+	}
+
+	for _, c := range []*cobra.Command{openCmd, closeCmd, clearCmd, statusCmd} {
+		c.Flags().StringVar(&options.OutputFormat, "output", options.OutputFormat, `The output format (json or text)`)
+		biddingWindowCmd.AddCommand(c)
+	}
+	return biddingWindowCmd
+}
+
+func printBiddingWindowStatus(cmd *cobra.Command, status model.BiddingWindowStatus, options *BiddingWindowOptions) error {
+	if options.OutputFormat == JSONFormat {
+		msgBytes, err := model.JSONMarshalIndentWithMax(status, 2)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error marshaling bidding window status to JSON: %s", err), 1)
+			return err
+		}
+		cmd.Printf("%s\n", msgBytes)
+		return nil
+	}
+
+	bidding := "not bidding"
+	if status.Open {
+		bidding = "bidding"
+	}
+	switch {
+	case status.Override != nil && status.Override.Until.IsZero():
+		cmd.Printf("The compute node is %s on jobs until the override is cleared\n", bidding)
+	case status.Override != nil:
+		cmd.Printf("The compute node is %s on jobs until %s\n", bidding, status.Override.Until.Format(time.RFC3339))
+	case len(status.Windows) == 0:
+		cmd.Printf("The compute node is %s on jobs, and has no bidding windows\n", bidding)
+	default:
+		cmd.Printf("The compute node is %s on jobs, and bids during %s (%s)\n",
+			bidding, strings.Join(status.Windows, ", "), status.Location)
+	}
+	return nil
+}
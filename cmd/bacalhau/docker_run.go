@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"log"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
 	"github.com/bacalhau-project/bacalhau/pkg/downloader/util"
 	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/extract"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/inline"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
 	"github.com/ipfs/go-cid"
@@ -52,24 +56,51 @@ type DockerRunOptions struct {
 	Engine           string   // Executor - executor.Executor
 	Verifier         string   // Verifier - verifier.Verifier
 	Publisher        string   // Publisher - publisher.Publisher
+	Publishers       []string // Publishers to publish with as well as Publisher
 	Inputs           []string // Array of input CIDs
 	InputUrls        []string // Array of input URLs (will be copied to IPFS)
 	InputVolumes     []string // Array of input volumes in 'CID:mount point' form
+	InputFiles       []string // Array of local files to send inline with the job in 'path:mount point' form
+	ExtractInputURLs bool     // Whether to extract input URLs that are archives into their mount point
 	OutputVolumes    []string // Array of output volumes in 'name:mount point' form
+	OutputIncludes   []string // Array of glob patterns of files to publish in 'name:glob' form
+	OutputExcludes   []string // Array of glob patterns of files not to publish in 'name:glob' form
 	Env              []string // Array of environment variables
 	IDOnly           bool     // Only print the job ID
 	Concurrency      int      // Number of concurrent jobs to run
 	Confidence       int      // Minimum number of nodes that must agree on a verification result
 	MinBids          int      // Minimum number of bids before they will be accepted (at random)
+	Gang             bool     // Start all the executions at once or none of them
+	GangTimeout      float64  // Seconds to wait for all the executions of a gang to be placed
+	MaxBudget        float64  // Most each execution may cost at the pricing of the node that runs it
 	Timeout          float64  // Job execution timeout in seconds
+	MaxWallClock     float64  // Seconds after submission that the job must complete by
+	ArrayCount       int      // Number of array indices to run the job with
+	Webhook          string   // URL to notify when the job completes
+	IPNSName         string   // Name to publish the results under with IPNS
+	NoCache          bool     // Whether to run the job even if the results of an identical job can be reused
+	EncryptTo        []string // age public keys to encrypt the results to
 	CPU              string
 	Memory           string
 	GPU              string
+	GPUModel         string
+	GPUMemory        string
+	GPUMIGProfile    string
+	Disk             string
+	IOPS             string
+	Priority         model.Priority       // How urgently to schedule the job ahead of other queued jobs
+	Retry            model.RetryPolicy    // How to retry executions that fail on other nodes
+	Affinity         model.AffinityConfig // Nodes to prefer and to avoid running the job on
+	Tolerations      []model.Toleration   // Taints of the nodes that the job may run on
+	Offer            model.Pricing        // Most the job pays per second for each resource it uses
+	TEE              model.TEEType        // The kind of TEE that the job must run in
 	Networking       model.Network
 	NetworkDomains   []string
 	WorkingDirectory string   // Working directory for docker
 	Labels           []string // Labels for the job on the Bacalhau network (for searching)
 	NodeSelector     string   // Selector (label query) to filter nodes on which this job can be executed
+	SeccompProfile   string   // Path to a seccomp profile to run the job with, or "unconfined"
+	AppArmorProfile  string   // Name of an AppArmor profile to run the job with, or "unconfined"
 
 	Image      string   // Image to execute
 	Entrypoint []string // Entrypoint to the docker image
@@ -83,6 +114,10 @@ type DockerRunOptions struct {
 	DownloadFlags model.DownloaderSettings // Settings for running Download
 
 	FilPlus bool // add a "filplus" label to the job to grab the attention of fil+ moderators
+
+	FilecoinDeal model.FilecoinDealConfig // Deals to make for the results when they are published to Filecoin
+
+	Compression model.CompressionConfig // How to compress the results before they are published
 }
 
 func NewDockerRunOptions() *DockerRunOptions {
@@ -93,6 +128,7 @@ func NewDockerRunOptions() *DockerRunOptions {
 		Inputs:             []string{},
 		InputUrls:          []string{},
 		InputVolumes:       []string{},
+		InputFiles:         []string{},
 		OutputVolumes:      []string{},
 		Env:                []string{},
 		Concurrency:        1,
@@ -102,12 +138,16 @@ func NewDockerRunOptions() *DockerRunOptions {
 		CPU:                "",
 		Memory:             "",
 		GPU:                "",
+		Disk:               "",
+		IOPS:               "",
 		Networking:         model.NetworkNone,
 		NetworkDomains:     []string{},
 		SkipSyntaxChecking: false,
 		WorkingDirectory:   "",
 		Labels:             []string{},
 		NodeSelector:       "",
+		SeccompProfile:     "",
+		AppArmorProfile:    "",
 		DownloadFlags:      *util.NewDownloadSettings(),
 		RunTimeSettings:    *NewRunTimeSettings(),
 
@@ -154,6 +194,11 @@ func newDockerRunCmd() *cobra.Command { //nolint:funlen
 		&ODR.Publisher, "publisher", ODR.Publisher,
 		`What publisher engine to use to publish the job results`,
 	)
+	dockerRunCmd.PersistentFlags().StringSliceVar(
+		&ODR.Publishers, "additional-publisher", ODR.Publishers,
+		`Another publisher engine to publish the job results with as well as --publisher. Can be repeated, `+
+			`and the job succeeds as long as one of its publishers does.`,
+	)
 	dockerRunCmd.PersistentFlags().StringSliceVarP(
 		&ODR.Inputs, "inputs", "i", ODR.Inputs,
 		`CIDs to use on the job. Mounts them at '/inputs' in the execution.`,
@@ -170,10 +215,30 @@ func newDockerRunCmd() *cobra.Command { //nolint:funlen
 		&ODR.InputVolumes, "input-volumes", "v", ODR.InputVolumes,
 		`CID:path of the input data volumes, if you need to set the path of the mounted data.`,
 	)
+	dockerRunCmd.PersistentFlags().BoolVar(
+		&ODR.ExtractInputURLs, "extract-input-urls", ODR.ExtractInputURLs,
+		`Extract the archives downloaded from HTTP, S3 and GCS input URLs into '/inputs' before the job starts, rather `+
+			`than mounting the archives themselves. Archives can be tar, tar.gz, zip or CAR files.`,
+	)
+	dockerRunCmd.PersistentFlags().StringSliceVar(
+		&ODR.InputFiles, "input-files", ODR.InputFiles,
+		`Local files or directories to send inline with the job, as path or path:mount-point. They are mounted at `+
+			`'/inputs/<name>' by default. Use this for small scripts and config files rather than adding them to IPFS first.`,
+	)
 	dockerRunCmd.PersistentFlags().StringSliceVarP(
 		&ODR.OutputVolumes, "output-volumes", "o", ODR.OutputVolumes,
 		`name:path of the output data volumes. 'outputs:/outputs' is always added.`,
 	)
+	dockerRunCmd.PersistentFlags().StringSliceVar(
+		&ODR.OutputIncludes, "output-include", ODR.OutputIncludes,
+		`Only publish the files in an output volume that match a glob, in the form 'name:glob', e.g. outputs:*.parquet. `+
+			`Globs match the path of a file in the volume, its name, or a directory it is in. Can be repeated.`,
+	)
+	dockerRunCmd.PersistentFlags().StringSliceVar(
+		&ODR.OutputExcludes, "output-exclude", ODR.OutputExcludes,
+		`Don't publish the files in an output volume that match a glob, in the form 'name:glob', e.g. outputs:tmp. `+
+			`Can be repeated.`,
+	)
 	dockerRunCmd.PersistentFlags().StringSliceVarP(
 		&ODR.Env, "env", "e", ODR.Env,
 		`The environment variables to supply to the job (e.g. --env FOO=bar --env BAR=baz)`,
@@ -190,10 +255,100 @@ func newDockerRunCmd() *cobra.Command { //nolint:funlen
 		&ODR.MinBids, "min-bids", ODR.MinBids,
 		`Minimum number of bids that must be received before concurrency-many bids will be accepted (at random)`,
 	)
+	dockerRunCmd.PersistentFlags().BoolVar(
+		&ODR.Gang, "gang", ODR.Gang,
+		`Start all concurrency-many executions at once or none of them, with the nodes holding capacity for them until then.`,
+	)
+	dockerRunCmd.PersistentFlags().Float64Var(
+		&ODR.GangTimeout, "gang-timeout", ODR.GangTimeout,
+		`Seconds to wait for enough nodes to start all the executions of a gang before the job fails (0 for the default)`,
+	)
+	dockerRunCmd.PersistentFlags().Float64Var(
+		&ODR.MaxBudget, "max-budget", ODR.MaxBudget,
+		`Most that each execution may cost over the job timeout at the pricing of the node that runs it (0 for no limit)`,
+	)
+	dockerRunCmd.PersistentFlags().Var(
+		PricingFlag(&ODR.Offer), "offer",
+		`Most that the job pays per second for each resource it uses, e.g. cpu=0.01,memory=0.001,gpu=1 with memory `+
+			`per GB. Nodes that charge more for any of them don't bid on the job.`,
+	)
+	dockerRunCmd.PersistentFlags().Var(
+		TEEFlag(&ODR.TEE), "tee",
+		`Only run the job on nodes that attest that they run in a trusted execution environment: sev-snp, tdx or any. `+
+			`The evidence of each node is shown by bacalhau describe.`,
+	)
 	dockerRunCmd.PersistentFlags().Float64Var(
 		&ODR.Timeout, "timeout", ODR.Timeout,
 		`Job execution timeout in seconds (e.g. 300 for 5 minutes and 0.1 for 100ms)`,
 	)
+	dockerRunCmd.PersistentFlags().IntVar(
+		&ODR.ArrayCount, "array", ODR.ArrayCount,
+		`Run the job once for each index from 0 to N-1 within one execution, with the index in $BACALHAU_ARRAY_INDEX. `+
+			`The results of each index are in a directory named after it.`,
+	)
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.Webhook, "webhook", ODR.Webhook,
+		`URL that the requester node POSTs the results of the job to when it completes, signed with its key.`,
+	)
+	dockerRunCmd.PersistentFlags().Var(
+		DeadlineFlag(&ODR.MaxWallClock), "deadline",
+		`Stop the job and mark it as timed out if it hasn't completed by then, as how long after it is submitted (e.g. 2h) `+
+			`or a time (e.g. 2023-05-01T12:00:00Z). This includes the time it waits to be scheduled.`,
+	)
+	dockerRunCmd.PersistentFlags().IntVar(
+		&ODR.Retry.MaxAttempts, "retry", ODR.Retry.MaxAttempts,
+		`How many times to attempt each execution of the job on different compute nodes if it fails, including the first.`,
+	)
+	dockerRunCmd.PersistentFlags().Float64Var(
+		&ODR.Retry.Backoff, "retry-backoff", ODR.Retry.Backoff,
+		`Seconds to wait before retrying a failed execution, which doubles with each failure after that.`,
+	)
+	dockerRunCmd.PersistentFlags().Var(
+		RetryReasonArrayFlag(&ODR.Retry.RetryOn), "retry-on",
+		`Which failures to retry: node-lost, error or exit-code for a non-zero exit code. Retries all of them by default.`,
+	)
+	dockerRunCmd.PersistentFlags().Var(
+		PriorityFlag(&ODR.Priority), "priority",
+		`How urgently to schedule the job ahead of other jobs that are waiting for compute capacity: low, normal or high. `+
+			`Requester nodes may limit how many high priority jobs each client has in flight.`,
+	)
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.IPNSName, "ipns-name", ODR.IPNSName,
+		`Publish the results with IPFS under an IPNS name as well, which successive runs with the same name on the same `+
+			`compute node update to point at their latest results. The IPNS name is shown by bacalhau describe.`,
+	)
+	dockerRunCmd.PersistentFlags().BoolVar(
+		&ODR.NoCache, "no-cache", ODR.NoCache,
+		`Run the job even if the compute node recently published the results of an identical job, rather than `+
+			`reusing them.`,
+	)
+	dockerRunCmd.PersistentFlags().StringSliceVar(
+		&ODR.EncryptTo, "encrypt-to", ODR.EncryptTo,
+		`age X25519 public key to encrypt the job results to before they are published, e.g. from age-keygen. `+
+			`Can be repeated. Decrypt the results with bacalhau get --identity.`,
+	)
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.Compression.Algorithm, "compress", ODR.Compression.Algorithm,
+		`Compress the job results into a single tar before they are published, with gzip or zstd. `+
+			`bacalhau get decompresses them.`,
+	)
+	dockerRunCmd.PersistentFlags().IntVar(
+		&ODR.Compression.Level, "compression-level", ODR.Compression.Level,
+		`Level to compress the job results at, from 1 to 9 for gzip and from 1 to 22 for zstd (default is the default of the algorithm).`,
+	)
+	dockerRunCmd.PersistentFlags().IntVar(
+		&ODR.FilecoinDeal.Replication, "filecoin-replication", ODR.FilecoinDeal.Replication,
+		`Number of miners to make storage deals with when the results are published to Filecoin (default 1).`,
+	)
+	dockerRunCmd.PersistentFlags().IntVar(
+		&ODR.FilecoinDeal.DurationDays, "filecoin-deal-duration", ODR.FilecoinDeal.DurationDays,
+		`Number of days that storage deals last for when the results are published to Filecoin `+
+			`(default is the storage duration of the compute node).`,
+	)
+	dockerRunCmd.PersistentFlags().BoolVar(
+		&ODR.FilecoinDeal.VerifiedDeal, "filecoin-verified-deal", ODR.FilecoinDeal.VerifiedDeal,
+		`Make verified storage deals when the results are published to Filecoin, paid for with the DataCap of the compute node.`,
+	)
 	dockerRunCmd.PersistentFlags().StringVar(
 		&ODR.CPU, "cpu", ODR.CPU,
 		`Job CPU cores (e.g. 500m, 2, 8).`,
@@ -204,7 +359,27 @@ func newDockerRunCmd() *cobra.Command { //nolint:funlen
 	)
 	dockerRunCmd.PersistentFlags().StringVar(
 		&ODR.GPU, "gpu", ODR.GPU,
-		`Job GPU requirement (e.g. 1, 2, 8).`,
+		`Job GPU requirement (e.g. 1, 2, 8), or a fraction of a GPU to share it with other jobs (e.g. 0.5).`,
+	)
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.GPUModel, "gpu-model", ODR.GPUModel,
+		`Model that each GPU of the job must be, matched against the names of the GPUs of the nodes (e.g. A100, T4).`,
+	)
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.GPUMemory, "gpu-memory", ODR.GPUMemory,
+		`Memory that each GPU of the job must have at least (e.g. 16Gb, 80Gb).`,
+	)
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.GPUMIGProfile, "gpu-mig-profile", ODR.GPUMIGProfile,
+		`MIG profile of the GPU slices to run the job on, taking as many slices as --gpu or one (e.g. 1g.10gb).`,
+	)
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.Disk, "disk", ODR.Disk,
+		`Job disk requirement, which also limits the size of the container's filesystem (e.g. 500Mb, 2Gb, 8Gb).`,
+	)
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.IOPS, "iops", ODR.IOPS,
+		`Job limit on disk read and write operations per second (e.g. 100, 1000).`,
 	)
 	dockerRunCmd.PersistentFlags().Var(
 		NetworkFlag(&ODR.Networking), "network",
@@ -239,6 +414,36 @@ func newDockerRunCmd() *cobra.Command { //nolint:funlen
 		`Selector (label query) to filter nodes on which this job can be executed, supports '=', '==', and '!='.(e.g. -s key1=value1,key2=value2). Matching objects must satisfy all of the specified label constraints.`, //nolint:lll // Documentation, ok if long.
 	)
 
+	dockerRunCmd.PersistentFlags().Var(
+		NodeSelectorFlag(&ODR.Affinity.PreferredNodeSelectors), "prefer-selector",
+		`Selector (label query) of the nodes to prefer running the job on, without ruling out the others `+
+			`(e.g. --prefer-selector zone=eu).`,
+	)
+	dockerRunCmd.PersistentFlags().Var(
+		TolerationArrayFlag(&ODR.Tolerations), "toleration",
+		`Taint of the nodes reserved for some jobs that this job may run on, as key=value:effect, or as key:effect `+
+			`for any value. The effect can be left out to tolerate any. Can be repeated.`,
+	)
+	dockerRunCmd.PersistentFlags().StringSliceVar(
+		&ODR.Affinity.AntiAffinity, "anti-affinity", ODR.Affinity.AntiAffinity,
+		`Keep the job off nodes that are running or have run another of your jobs with this annotation, `+
+			`e.g. to spread replicas across nodes. Can be repeated.`,
+	)
+	dockerRunCmd.PersistentFlags().BoolVar(
+		&ODR.Affinity.SoftAntiAffinity, "soft-anti-affinity", ODR.Affinity.SoftAntiAffinity,
+		`Prefer nodes that haven't run a job with an --anti-affinity annotation rather than ruling them out.`,
+	)
+
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.SeccompProfile, "seccomp-profile", ODR.SeccompProfile,
+		`Path to a seccomp profile to run the job with, or "unconfined". Only nodes that allow jobs to override their security profiles will run the job.`, //nolint:lll // Documentation, ok if long.
+	)
+
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.AppArmorProfile, "apparmor-profile", ODR.AppArmorProfile,
+		`Name of an AppArmor profile to run the job with, or "unconfined". Only nodes that allow jobs to override their security profiles will run the job.`, //nolint:lll // Documentation, ok if long.
+	)
+
 	dockerRunCmd.PersistentFlags().BoolVar(
 		&ODR.FilPlus, "filplus", ODR.FilPlus,
 		`Mark the job as a candidate for moderation for FIL+ rewards.`,
@@ -332,6 +537,15 @@ func CreateJob(ctx context.Context, cmdArgs []string, odr *DockerRunOptions) (*m
 		return &model.Job{}, err
 	}
 
+	var publishers []model.Publisher
+	for _, p := range odr.Publishers {
+		additionalPublisher, parseErr := model.ParsePublisher(p)
+		if parseErr != nil {
+			return &model.Job{}, parseErr
+		}
+		publishers = append(publishers, additionalPublisher)
+	}
+
 	for _, i := range odr.Inputs {
 		isValidCID := func(i string) bool {
 			c, er := cid.Decode(i)
@@ -424,5 +638,86 @@ func CreateJob(ctx context.Context, cmdArgs []string, odr *DockerRunOptions) (*m
 		return &model.Job{}, errors.Wrap(err, "CreateJobSpecAndDeal")
 	}
 
+	// like docker itself, read the seccomp profile so that it is sent with the job
+	if odr.SeccompProfile != "" && odr.SeccompProfile != "unconfined" {
+		profile, readErr := os.ReadFile(odr.SeccompProfile)
+		if readErr != nil {
+			return &model.Job{}, errors.Wrap(readErr, "failed to read seccomp profile")
+		}
+		j.Spec.Docker.SeccompProfile = string(profile)
+	} else {
+		j.Spec.Docker.SeccompProfile = odr.SeccompProfile
+	}
+	j.Spec.Docker.AppArmorProfile = odr.AppArmorProfile
+	j.Spec.Resources.Disk = odr.Disk
+	j.Spec.Resources.IOPS = odr.IOPS
+	j.Spec.Resources.GPUModel = odr.GPUModel
+	j.Spec.Resources.GPUMemory = odr.GPUMemory
+	j.Spec.Resources.GPUMIGProfile = odr.GPUMIGProfile
+	j.Spec.Array.Count = odr.ArrayCount
+	j.Spec.Publishers = publishers
+	j.Spec.Webhook = odr.Webhook
+	j.Spec.IPNSName = odr.IPNSName
+	j.Spec.NoCache = odr.NoCache
+	j.Spec.Priority = odr.Priority
+	j.Spec.MaxWallClock = odr.MaxWallClock
+	j.Spec.Retry = odr.Retry
+	j.Spec.Deal.Gang = odr.Gang
+	j.Spec.Deal.GangTimeout = odr.GangTimeout
+	j.Spec.Deal.MaxBudget = odr.MaxBudget
+	j.Spec.Deal.Offer = odr.Offer
+	j.Spec.TEE = odr.TEE
+	j.Spec.Affinity = odr.Affinity
+	j.Spec.Tolerations = odr.Tolerations
+	j.Spec.Encryption.Recipients = odr.EncryptTo
+	j.Spec.FilecoinDeal = odr.FilecoinDeal
+	j.Spec.Compression = odr.Compression
+	if err = jobutils.FilterOutputs(j.Spec.Outputs, odr.OutputIncludes, odr.OutputExcludes); err != nil {
+		return &model.Job{}, err
+	}
+
+	if odr.ExtractInputURLs {
+		for i, input := range j.Spec.Inputs {
+			switch input.StorageSource {
+			case model.StorageSourceURLDownload, model.StorageSourceS3, model.StorageSourceGCS:
+				j.Spec.Inputs[i] = extract.Request(input)
+			}
+		}
+	}
+
+	inlineInputs, err := uploadInputFiles(ctx, odr.InputFiles)
+	if err != nil {
+		return &model.Job{}, err
+	}
+	j.Spec.Inputs = append(j.Spec.Inputs, inlineInputs...)
+
 	return j, nil
 }
+
+// uploadInputFiles returns inline storage specs of local files given as path or
+// path:mount-point.
+func uploadInputFiles(ctx context.Context, inputFiles []string) ([]model.StorageSpec, error) {
+	var specs []model.StorageSpec
+	for _, inputFile := range inputFiles {
+		path, mountPoint := inputFile, ""
+		// split on the last colon so that Windows drive letters stay in the path
+		if i := strings.LastIndex(inputFile, ":"); i > 0 && strings.HasPrefix(inputFile[i+1:], "/") {
+			path, mountPoint = inputFile[:i], inputFile[i+1:]
+		}
+		spec, err := inline.NewStorage().Upload(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		if mountPoint == "" {
+			// files are mounted as themselves and directories as their parent,
+			// so both end up at /inputs/<name>
+			mountPoint = "/inputs"
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				mountPoint = "/inputs/" + filepath.Base(path)
+			}
+		}
+		spec.Path = mountPoint
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
@@ -0,0 +1,118 @@
+package bacalhau
+
+import (
+	"fmt"
+	"time"
+
+	compute_publicapi "github.com/bacalhau-project/bacalhau/pkg/compute/publicapi"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	drainLong = templates.LongDesc(i18n.T(`
+		Drain a compute node so that it can be taken out of the network, such as for
+		an upgrade. The node stops bidding on new jobs, waits for the executions it has
+		to finish, and then shuts down. Executions that haven't finished by the deadline
+		are left to the requester node to retry elsewhere.
+
+		The --api-host and --api-port flags point at the compute node to drain. Only the
+		clients that the compute node lists as admins can drain it.
+`))
+
+	//nolint:lll // Documentation
+	drainExample = templates.Examples(i18n.T(`
+		# Drain the compute node, waiting as long as its longest job can run
+		bacalhau drain --api-host 10.0.0.5
+
+		# Drain the compute node, shutting it down within 30 minutes
+		bacalhau drain --api-host 10.0.0.5 --timeout 30m
+
+		# Show how draining the compute node is going
+		bacalhau drain status --api-host 10.0.0.5`))
+)
+
+type DrainOptions struct {
+	Timeout      time.Duration // How long to wait for executions to finish
+	OutputFormat string        // The output format (json or text)
+}
+
+func NewDrainOptions() *DrainOptions {
+	return &DrainOptions{
+		OutputFormat: "text",
+	}
+}
+
+func newDrainCmd() *cobra.Command {
+	options := NewDrainOptions()
+
+	drainCmd := &cobra.Command{
+		Use:     "drain",
+		Short:   "Drain a compute node so that it stops taking jobs and shuts down",
+		Long:    drainLong,
+		Example: drainExample,
+		Args:    cobra.NoArgs,
+		PreRun:  applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			status, err := getComputeAPIClient().Drain(cmd.Context(), options.Timeout)
+			if err != nil {
+				Fatal(cmd, fmt.Sprintf("Error draining the compute node: %s", err), 1)
+				return err
+			}
+			return printDrainStatus(cmd, status, options)
+		},
+	}
+	drainCmd.Flags().DurationVar(&options.Timeout, "timeout", options.Timeout,
+		`How long to wait for executions to finish before shutting down anyway. Defaults to the longest a job can run on the node.`)
+
+	statusCmd := &cobra.Command{
+		Use:    "status",
+		Short:  "Show how draining a compute node is going",
+		Args:   cobra.NoArgs,
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			status, err := getComputeAPIClient().DrainStatus(cmd.Context())
+			if err != nil {
+				Fatal(cmd, fmt.Sprintf("Error getting the drain status of the compute node: %s", err), 1)
+				return err
+			}
+			return printDrainStatus(cmd, status, options)
+		},
+	}
+
+	for _, c := range []*cobra.Command{drainCmd, statusCmd} {
+		c.Flags().StringVar(&options.OutputFormat, "output", options.OutputFormat, `The output format (json or text)`)
+	}
+
+	drainCmd.AddCommand(statusCmd)
+	return drainCmd
+}
+
+func getComputeAPIClient() *compute_publicapi.ComputeAPIClient {
+	return compute_publicapi.NewComputeAPIClient(fmt.Sprintf("http://%s:%d", apiHost, apiPort))
+}
+
+func printDrainStatus(cmd *cobra.Command, status model.DrainStatus, options *DrainOptions) error {
+	if options.OutputFormat == JSONFormat {
+		msgBytes, err := model.JSONMarshalIndentWithMax(status, 2)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error marshaling drain status to JSON: %s", err), 1)
+			return err
+		}
+		cmd.Printf("%s\n", msgBytes)
+		return nil
+	}
+
+	switch {
+	case !status.Draining:
+		cmd.Println("The compute node is not draining")
+	case status.Drained:
+		cmd.Println("The compute node has drained and is shutting down")
+	default:
+		cmd.Printf("The compute node is draining with %d executions to finish, and shuts down by %s\n",
+			status.ActiveExecutions, status.Deadline.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -3,12 +3,23 @@ package bacalhau
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/job"
 	"github.com/bacalhau-project/bacalhau/pkg/logger"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/bittorrent"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/filecoin"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/gcs"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/huggingface"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/inline"
+	localdirectory "github.com/bacalhau-project/bacalhau/pkg/storage/local_directory"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/s3"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/url/urldownload"
+	"github.com/c2h5oh/datasize"
+	big2 "github.com/filecoin-project/go-state-types/big"
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // A Parser is a function that can convert a string into a native object.
@@ -181,6 +192,51 @@ func NewIPFSStorageSpecArrayFlag(value *[]model.StorageSpec) *ArrayValueFlag[mod
 }
 
 func parseURLStorageSpec(inputURL string) (model.StorageSpec, error) {
+	if _, _, err := s3.ParseURL(inputURL); err == nil {
+		return model.StorageSpec{
+			StorageSource: model.StorageSourceS3,
+			URL:           strings.Trim(inputURL, " '\""),
+			Path:          "/inputs",
+		}, nil
+	}
+	if _, _, err := gcs.ParseURL(inputURL); err == nil {
+		return model.StorageSpec{
+			StorageSource: model.StorageSourceGCS,
+			URL:           strings.Trim(inputURL, " '\""),
+			Path:          "/inputs",
+		}, nil
+	}
+	if _, err := huggingface.ParseURL(inputURL); err == nil {
+		return model.StorageSpec{
+			StorageSource: model.StorageSourceHuggingFace,
+			URL:           strings.Trim(inputURL, " '\""),
+			Path:          "/inputs",
+		}, nil
+	}
+	if spec, err := localdirectory.ParseURL(inputURL); err == nil {
+		return spec, nil
+	}
+	if spec, err := filecoin.ParseURL(inputURL); err == nil {
+		return spec, nil
+	}
+	if _, err := bittorrent.ParseURL(inputURL); err == nil {
+		return model.StorageSpec{
+			StorageSource: model.StorageSourceBitTorrent,
+			URL:           strings.Trim(inputURL, " '\""),
+			Path:          "/inputs",
+		}, nil
+	}
+	if strings.HasPrefix(strings.Trim(inputURL, " '\""), inline.URLPrefix) {
+		if _, err := inline.ParseURL(inputURL); err != nil {
+			return model.StorageSpec{}, err
+		}
+		return model.StorageSpec{
+			StorageSource: model.StorageSourceInline,
+			URL:           strings.Trim(inputURL, " '\""),
+			Path:          "/inputs",
+		}, nil
+	}
+
 	u, err := urldownload.IsURLSupported(inputURL)
 	if err != nil {
 		return model.StorageSpec{}, err
@@ -201,6 +257,15 @@ func NewURLStorageSpecArrayFlag(value *[]model.StorageSpec) *ArrayValueFlag[mode
 	}
 }
 
+func DataSizeFlag(value *datasize.ByteSize) *ValueFlag[datasize.ByteSize] {
+	return &ValueFlag[datasize.ByteSize]{
+		value:    value,
+		parser:   datasize.ParseString,
+		stringer: func(v *datasize.ByteSize) string { return v.HR() },
+		typeStr:  "size",
+	}
+}
+
 func VerifierFlag(value *model.Verifier) *ValueFlag[model.Verifier] {
 	return &ValueFlag[model.Verifier]{
 		value:    value,
@@ -219,6 +284,15 @@ func PublisherFlag(value *model.Publisher) *ValueFlag[model.Publisher] {
 	}
 }
 
+func PublisherArrayFlag(value *[]model.Publisher) *ArrayValueFlag[model.Publisher] {
+	return &ArrayValueFlag[model.Publisher]{
+		value:    value,
+		parser:   model.ParsePublisher,
+		stringer: func(p *model.Publisher) string { return p.String() },
+		typeStr:  "publisher",
+	}
+}
+
 func NetworkFlag(value *model.Network) *ValueFlag[model.Network] {
 	return &ValueFlag[model.Network]{
 		value:    value,
@@ -228,6 +302,110 @@ func NetworkFlag(value *model.Network) *ValueFlag[model.Network] {
 	}
 }
 
+func PriorityFlag(value *model.Priority) *ValueFlag[model.Priority] {
+	return &ValueFlag[model.Priority]{
+		value:    value,
+		parser:   model.ParsePriority,
+		stringer: func(p *model.Priority) string { return p.String() },
+		typeStr:  "priority",
+	}
+}
+
+func PricingFlag(value *model.Pricing) *ValueFlag[model.Pricing] {
+	return &ValueFlag[model.Pricing]{
+		value:    value,
+		parser:   model.ParsePricing,
+		stringer: func(p *model.Pricing) string { return p.String() },
+		typeStr:  "resource=price",
+	}
+}
+
+func TEEFlag(value *model.TEEType) *ValueFlag[model.TEEType] {
+	return &ValueFlag[model.TEEType]{
+		value:    value,
+		parser:   model.ParseTEEType,
+		stringer: func(t *model.TEEType) string { return string(*t) },
+		typeStr:  "tee",
+	}
+}
+
+func RetryReasonArrayFlag(value *[]model.RetryReason) *ArrayValueFlag[model.RetryReason] {
+	return &ArrayValueFlag[model.RetryReason]{
+		value:    value,
+		parser:   model.ParseRetryReason,
+		stringer: func(r *model.RetryReason) string { return string(*r) },
+		typeStr:  "retry-reason",
+	}
+}
+
+func TaintArrayFlag(value *[]model.Taint) *ArrayValueFlag[model.Taint] {
+	return &ArrayValueFlag[model.Taint]{
+		value:    value,
+		parser:   model.ParseTaint,
+		stringer: func(t *model.Taint) string { return t.String() },
+		typeStr:  "taint",
+	}
+}
+
+func TolerationArrayFlag(value *[]model.Toleration) *ArrayValueFlag[model.Toleration] {
+	return &ArrayValueFlag[model.Toleration]{
+		value:    value,
+		parser:   model.ParseToleration,
+		stringer: func(t *model.Toleration) string { return t.String() },
+		typeStr:  "toleration",
+	}
+}
+
+// NodeSelectorFlag sets node selector requirements from a label query, like
+// the --selector flag.
+func NodeSelectorFlag(value *[]model.LabelSelectorRequirement) *ValueFlag[[]model.LabelSelectorRequirement] {
+	return &ValueFlag[[]model.LabelSelectorRequirement]{
+		value:  value,
+		parser: job.ParseNodeSelector,
+		stringer: func(requirements *[]model.LabelSelectorRequirement) string {
+			parsed, err := model.FromLabelSelectorRequirements(*requirements...)
+			if err != nil {
+				return ""
+			}
+			return labels.NewSelector().Add(parsed...).String()
+		},
+		typeStr: "selector",
+	}
+}
+
+// DeadlineFlag sets a max wall clock in seconds from either how long the job
+// may take, like 2h, or the time it must complete by, like 2023-05-01T12:00:00Z.
+func DeadlineFlag(value *float64) *ValueFlag[float64] {
+	return &ValueFlag[float64]{
+		value:  value,
+		parser: func(s string) (float64, error) { return parseDeadline(s, time.Now()) },
+		stringer: func(seconds *float64) string {
+			if *seconds == 0 {
+				return ""
+			}
+			return time.Duration(*seconds * float64(time.Second)).String()
+		},
+		typeStr: "duration|time",
+	}
+}
+
+func parseDeadline(s string, now time.Time) (float64, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return 0, fmt.Errorf("deadline %s must be positive", s)
+		}
+		return d.Seconds(), nil
+	}
+	deadline, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, fmt.Errorf("deadline %q must be a duration like 2h or a time like 2023-05-01T12:00:00Z", s)
+	}
+	if !deadline.After(now) {
+		return 0, fmt.Errorf("deadline %s has already passed", s)
+	}
+	return deadline.Sub(now).Seconds(), nil
+}
+
 func LoggingFlag(value *logger.LogMode) *ValueFlag[logger.LogMode] {
 	return &ValueFlag[logger.LogMode]{
 		value:    value,
@@ -277,3 +455,27 @@ func ExcludedTagFlag(value *[]model.ExcludedTag) *ArrayValueFlag[model.ExcludedT
 		typeStr:  "tag",
 	}
 }
+
+func NewAllowedLocalPathArrayFlag(value *[]localdirectory.AllowedPath) *ArrayValueFlag[localdirectory.AllowedPath] {
+	return &ArrayValueFlag[localdirectory.AllowedPath]{
+		value:    value,
+		parser:   localdirectory.ParseAllowedPath,
+		stringer: func(a *localdirectory.AllowedPath) string { return a.String() },
+		typeStr:  "path[:rw]",
+	}
+}
+
+// AttoFILFlag is a flag of an amount of attoFIL.
+func AttoFILFlag(value *big2.Int) *ValueFlag[big2.Int] {
+	return &ValueFlag[big2.Int]{
+		value:  value,
+		parser: big2.FromString,
+		stringer: func(v *big2.Int) string {
+			if v.Int == nil {
+				return "0"
+			}
+			return v.String()
+		},
+		typeStr: "attoFIL",
+	}
+}
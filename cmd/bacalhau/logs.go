@@ -0,0 +1,109 @@
+package bacalhau
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/util/logstream"
+	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	logsLong = templates.LongDesc(i18n.T(`
+		Show the output of a job.
+
+		If the job is still running, the output is streamed live from the compute
+		node running it. Otherwise, the output recorded when the job completed is shown.
+`))
+
+	//nolint:lll // Documentation
+	logsExample = templates.Examples(i18n.T(`
+		# Show the output of a job
+		bacalhau logs 51225160-807e-48b8-88c9-28311c7899e1
+
+		# Follow the output of a running job until it completes, with a short ID.
+		bacalhau logs --follow ebd9bf2f
+`))
+)
+
+type LogsOptions struct {
+	Follow bool
+}
+
+func NewLogsOptions() *LogsOptions {
+	return &LogsOptions{
+		Follow: false,
+	}
+}
+
+func newLogsCmd() *cobra.Command {
+	logsOptions := NewLogsOptions()
+
+	logsCmd := &cobra.Command{
+		Use:     "logs [id]",
+		Short:   "Show the output of a job",
+		Long:    logsLong,
+		Example: logsExample,
+		Args:    cobra.ExactArgs(1),
+		PreRun:  applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return logs(cmd, cmdArgs, logsOptions)
+		},
+	}
+
+	logsCmd.PersistentFlags().BoolVarP(
+		&logsOptions.Follow, "follow", "f", logsOptions.Follow,
+		`Keep streaming the output of a running job until it completes`,
+	)
+	return logsCmd
+}
+
+func logs(cmd *cobra.Command, cmdArgs []string, options *LogsOptions) error {
+	ctx := cmd.Context()
+	requestedJobID := cmdArgs[0]
+
+	apiClient := GetAPIClient()
+	jobInfo, foundJob, err := apiClient.Get(ctx, requestedJobID)
+	if err != nil {
+		if er, ok := err.(*bacerrors.ErrorResponse); ok {
+			Fatal(cmd, er.Message, 1)
+			return nil
+		}
+		Fatal(cmd, fmt.Sprintf("Unknown error trying to get job (ID: %s): %+v", requestedJobID, err), 1)
+		return nil
+	}
+	if !foundJob {
+		Fatal(cmd, fmt.Sprintf("Job not found (ID: %s)", requestedJobID), 1)
+		return nil
+	}
+
+	reader, err := apiClient.Logs(ctx, jobInfo.Job.Metadata.ID, options.Follow)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Failed to stream logs of job (ID: %s): %s", requestedJobID, err), 1)
+		return nil
+	}
+	defer reader.Close()
+
+	for {
+		df, err := logstream.NewDataFrameFromReader(reader)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error reading logs of job (ID: %s): %s", requestedJobID, err), 1)
+			return nil
+		}
+
+		switch df.Tag {
+		case logstream.StdoutStreamTag:
+			_, err = cmd.OutOrStdout().Write(df.Data)
+		case logstream.StderrStreamTag:
+			_, err = cmd.ErrOrStderr().Write(df.Data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
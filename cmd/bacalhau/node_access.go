@@ -0,0 +1,161 @@
+package bacalhau
+
+import (
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	nodeAccessLong = templates.LongDesc(i18n.T(`
+		Show and manage which compute nodes the requester node schedules executions on,
+		without changing which nodes are part of the network. Denying a node quarantines
+		it, such as when it misbehaves. Allowing nodes keeps the requester node to the
+		nodes that are allowed.
+
+		The rules set here replace what the lists the requester node was started with say
+		about the same nodes. Only the clients that the requester node lists as node access
+		admins can set and delete rules.
+`))
+
+	//nolint:lll // Documentation
+	nodeAccessExample = templates.Examples(i18n.T(`
+		# Show which nodes are allowed and denied
+		bacalhau node-access list
+
+		# Stop scheduling executions on a node that misbehaves
+		bacalhau node-access deny QmNode... --reason "results fail verification"
+
+		# Schedule executions on the node again
+		bacalhau node-access delete QmNode...`))
+)
+
+type NodeAccessOptions struct {
+	Reason       string // Why the node is allowed or denied
+	HideHeader   bool   // Hide the column headers
+	NoStyle      bool   // Remove all styling from table output.
+	OutputFormat string // The output format (json or text)
+}
+
+func NewNodeAccessOptions() *NodeAccessOptions {
+	return &NodeAccessOptions{
+		OutputFormat: "text",
+	}
+}
+
+func newNodeAccessCmd() *cobra.Command {
+	options := NewNodeAccessOptions()
+
+	nodeAccessCmd := &cobra.Command{
+		Use:     "node-access",
+		Short:   "Show and manage which compute nodes executions are scheduled on",
+		Long:    nodeAccessLong,
+		Example: nodeAccessExample,
+	}
+
+	listCmd := &cobra.Command{
+		Use:    "list",
+		Short:  "List the nodes that are allowed or denied",
+		Args:   cobra.NoArgs,
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return listNodeAccessRules(cmd, options)
+		},
+	}
+
+	setCmd := func(access model.NodeAccess, use, short, reasonUsage string) *cobra.Command {
+		c := &cobra.Command{
+			Use:    use + " [node-id]",
+			Short:  short,
+			Args:   cobra.ExactArgs(1),
+			PreRun: applyPorcelainLogLevel,
+			RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+				rule, err := GetAPIClient().SetNodeAccessRule(cmd.Context(), model.NodeAccessRule{
+					NodeID: cmdArgs[0],
+					Access: access,
+					Reason: options.Reason,
+				})
+				if err != nil {
+					Fatal(cmd, fmt.Sprintf("Error setting node access rule of node %s: %s", cmdArgs[0], err), 1)
+					return err
+				}
+				return printNodeAccessRules(cmd, []model.NodeAccessRule{rule}, options)
+			},
+		}
+		c.Flags().StringVar(&options.Reason, "reason", options.Reason, reasonUsage)
+		return c
+	}
+	allowCmd := setCmd(model.NodeAccessAllow, "allow",
+		"Allow scheduling executions on a node, after which only allowed nodes are scheduled on", `Why the node is allowed`)
+	denyCmd := setCmd(model.NodeAccessDeny, "deny", "Stop scheduling executions on a node", `Why the node is denied`)
+
+	deleteCmd := &cobra.Command{
+		Use:    "delete [node-id]",
+		Short:  "Delete the rule set for a node, after which the lists the requester node was started with apply to it",
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			if err := GetAPIClient().DeleteNodeAccessRule(cmd.Context(), cmdArgs[0]); err != nil {
+				Fatal(cmd, fmt.Sprintf("Error deleting node access rule of node %s: %s", cmdArgs[0], err), 1)
+				return err
+			}
+			cmd.Printf("Node %s has no node access rule set\n", cmdArgs[0])
+			return nil
+		},
+	}
+
+	for _, c := range []*cobra.Command{listCmd, allowCmd, denyCmd} {
+		c.Flags().BoolVar(&options.HideHeader, "hide-header", options.HideHeader, `do not print the column headers.`)
+		c.Flags().BoolVar(&options.NoStyle, "no-style", options.NoStyle, `remove all styling from table output.`)
+		c.Flags().StringVar(&options.OutputFormat, "output", options.OutputFormat, `The output format (json or text)`)
+	}
+
+	nodeAccessCmd.AddCommand(listCmd, allowCmd, denyCmd, deleteCmd)
+	return nodeAccessCmd
+}
+
+func listNodeAccessRules(cmd *cobra.Command, options *NodeAccessOptions) error {
+	rules, err := GetAPIClient().ListNodeAccessRules(cmd.Context())
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error listing node access rules: %s", err), 1)
+		return err
+	}
+	return printNodeAccessRules(cmd, rules, options)
+}
+
+func printNodeAccessRules(cmd *cobra.Command, rules []model.NodeAccessRule, options *NodeAccessOptions) error {
+	if options.OutputFormat == JSONFormat {
+		msgBytes, err := model.JSONMarshalIndentWithMax(rules, 2)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error marshaling node access rules to JSON: %s", err), 1)
+			return err
+		}
+		cmd.Printf("%s\n", msgBytes)
+		return nil
+	}
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(cmd.OutOrStdout())
+	if !options.HideHeader {
+		tw.AppendHeader(table.Row{"node", "access", "reason", "set by"})
+	}
+	for _, rule := range rules {
+		setBy := rule.CreatedBy
+		if setBy == "" {
+			setBy = "requester config"
+		}
+		tw.AppendRow(table.Row{rule.NodeID, string(rule.Access), rule.Reason, setBy})
+	}
+	if options.NoStyle {
+		tw.SetStyle(table.StyleDefault)
+		tw.Style().Options = table.OptionsNoBordersAndSeparators
+	} else {
+		tw.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	}
+	tw.Render()
+	return nil
+}
@@ -0,0 +1,120 @@
+package bacalhau
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	queueLong = templates.LongDesc(i18n.T(`
+		Show the jobs that wait in the queue of the requester node to start, with the job
+		that starts next first.
+
+		Each job says why it hasn't started yet: it waits for more urgent jobs and jobs
+		queued before it to start first, for nodes with the capacity to run it, for more
+		nodes that suit it to join the network, or for other jobs of its client to finish
+		because of the client's quota. The estimated start is from how quickly jobs have
+		recently left the queue.
+`))
+
+	queueExample = templates.Examples(i18n.T(`
+		# Show the jobs that wait to start
+		bacalhau queue
+
+		# Show where a job is in the queue and why it waits
+		bacalhau queue 51225160`))
+)
+
+type QueueOptions struct {
+	HideHeader   bool   // Hide the column headers
+	NoStyle      bool   // Remove all styling from table output.
+	OutputFormat string // The output format (json or text)
+	OutputWide   bool   // Print full values in the table results
+}
+
+func NewQueueOptions() *QueueOptions {
+	return &QueueOptions{
+		OutputFormat: "text",
+	}
+}
+
+func newQueueCmd() *cobra.Command {
+	options := NewQueueOptions()
+
+	queueCmd := &cobra.Command{
+		Use:     "queue [job-id]",
+		Short:   "Show the jobs that wait to start, and why",
+		Long:    queueLong,
+		Example: queueExample,
+		Args:    cobra.MaximumNArgs(1),
+		PreRun:  applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return showQueue(cmd, cmdArgs, options)
+		},
+	}
+	queueCmd.Flags().BoolVar(&options.HideHeader, "hide-header", options.HideHeader, `do not print the column headers.`)
+	queueCmd.Flags().BoolVar(&options.NoStyle, "no-style", options.NoStyle, `remove all styling from table output.`)
+	queueCmd.Flags().StringVar(&options.OutputFormat, "output", options.OutputFormat,
+		`The output format for the queued jobs (json or text)`)
+	queueCmd.Flags().BoolVar(&options.OutputWide, "wide", options.OutputWide, `Print full values in the table results`)
+	return queueCmd
+}
+
+func showQueue(cmd *cobra.Command, cmdArgs []string, options *QueueOptions) error {
+	jobID := ""
+	if len(cmdArgs) > 0 {
+		jobID = cmdArgs[0]
+	}
+	jobs, err := GetAPIClient().GetQueue(cmd.Context(), jobID)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error getting the queue: %s", err), 1)
+		return err
+	}
+
+	if options.OutputFormat == JSONFormat {
+		var msgBytes []byte
+		msgBytes, err = model.JSONMarshalWithMax(jobs)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error marshaling queued jobs to JSON: %s", err), 1)
+			return err
+		}
+		cmd.Printf("%s\n", msgBytes)
+		return nil
+	}
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(cmd.OutOrStdout())
+	if !options.HideHeader {
+		tw.AppendHeader(table.Row{"position", "job", "priority", "waiting", "reason", "estimated start", "message"})
+	}
+	for _, queued := range jobs {
+		estimate := "unknown"
+		if !queued.EstimatedStartAt.IsZero() {
+			estimate = "in " + time.Until(queued.EstimatedStartAt).Round(time.Second).String()
+		}
+		tw.AppendRow(table.Row{
+			strconv.Itoa(queued.Position),
+			shortID(options.OutputWide, queued.JobID),
+			queued.Priority.String(),
+			time.Since(queued.QueuedAt).Round(time.Second).String(),
+			string(queued.Reason),
+			estimate,
+			shortenString(options.OutputWide, queued.Message),
+		})
+	}
+	if options.NoStyle {
+		tw.SetStyle(table.StyleDefault)
+		tw.Style().Options = table.OptionsNoBordersAndSeparators
+	} else {
+		tw.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	}
+	tw.Render()
+	return nil
+}
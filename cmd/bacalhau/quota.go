@@ -0,0 +1,257 @@
+package bacalhau
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
+	"github.com/c2h5oh/datasize"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	quotaLong = templates.LongDesc(i18n.T(`
+		Show and manage the quotas that limit what each client can submit to the requester
+		node and run at once. Clients that don't have their own quota get the default
+		quota that the requester node was started with.
+
+		Only the clients that the requester node lists as quota admins can set and delete
+		quotas.
+`))
+
+	//nolint:lll // Documentation
+	quotaExample = templates.Examples(i18n.T(`
+		# Show your quota and how much of it you are using
+		bacalhau quota get
+
+		# Let a client run 10 jobs at once and queue 100 more
+		bacalhau quota set 5f1b2c3d... --max-concurrent-jobs 10 --max-queued-jobs 100
+
+		# Go back to the default quota for a client
+		bacalhau quota delete 5f1b2c3d...`))
+)
+
+type QuotaOptions struct {
+	Quota        model.ClientQuota // The quota to set
+	HideHeader   bool              // Hide the column headers
+	NoStyle      bool              // Remove all styling from table output.
+	OutputFormat string            // The output format (json or text)
+}
+
+func NewQuotaOptions() *QuotaOptions {
+	return &QuotaOptions{
+		OutputFormat: "text",
+	}
+}
+
+func newQuotaCmd() *cobra.Command {
+	options := NewQuotaOptions()
+
+	quotaCmd := &cobra.Command{
+		Use:     "quota",
+		Short:   "Show and manage the quotas of clients",
+		Long:    quotaLong,
+		Example: quotaExample,
+	}
+
+	getCmd := &cobra.Command{
+		Use:    "get [client-id]",
+		Short:  "Show the quota of a client, which is you by default, and how much of it is used",
+		Args:   cobra.MaximumNArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return getQuota(cmd, cmdArgs, options)
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:    "list",
+		Short:  "List the clients that have their own quota",
+		Args:   cobra.NoArgs,
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return listQuotas(cmd, options)
+		},
+	}
+
+	setCmd := &cobra.Command{
+		Use:    "set [client-id]",
+		Short:  "Set the quota of a client, replacing any it had",
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			options.Quota.ClientID = cmdArgs[0]
+			quota, err := GetAPIClient().SetQuota(cmd.Context(), options.Quota)
+			if err != nil {
+				Fatal(cmd, fmt.Sprintf("Error setting quota of client %s: %s", cmdArgs[0], err), 1)
+				return err
+			}
+			return printQuota(cmd, quota, nil, options)
+		},
+	}
+	setCmd.Flags().IntVar(&options.Quota.MaxConcurrentJobs, "max-concurrent-jobs", 0,
+		`How many jobs the client can run at once. Jobs beyond it wait in the queue.`)
+	setCmd.Flags().IntVar(&options.Quota.MaxQueuedJobs, "max-queued-jobs", 0,
+		`How many jobs the client can have waiting in the queue.`)
+	setCmd.Flags().IntVar(&options.Quota.MaxSubmissionsPerMinute, "max-submissions-per-minute", 0,
+		`How many jobs the client can submit a minute.`)
+	setCmd.Flags().StringVar(&options.Quota.MaxResources.CPU, "max-cpu", "",
+		`The most CPU that the client's jobs in flight can request between them (e.g. 500m, 8).`)
+	setCmd.Flags().StringVar(&options.Quota.MaxResources.Memory, "max-memory", "",
+		`The most memory that the client's jobs in flight can request between them (e.g. 32Gb).`)
+	setCmd.Flags().StringVar(&options.Quota.MaxResources.GPU, "max-gpu", "",
+		`The most GPUs that the client's jobs in flight can request between them.`)
+
+	deleteCmd := &cobra.Command{
+		Use:    "delete [client-id]",
+		Short:  "Delete the quota of a client, after which the default quota applies to it",
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			if err := GetAPIClient().DeleteQuota(cmd.Context(), cmdArgs[0]); err != nil {
+				Fatal(cmd, fmt.Sprintf("Error deleting quota of client %s: %s", cmdArgs[0], err), 1)
+				return err
+			}
+			cmd.Printf("Client %s has the default quota\n", cmdArgs[0])
+			return nil
+		},
+	}
+
+	for _, c := range []*cobra.Command{getCmd, listCmd, setCmd} {
+		c.Flags().BoolVar(&options.HideHeader, "hide-header", options.HideHeader, `do not print the column headers.`)
+		c.Flags().BoolVar(&options.NoStyle, "no-style", options.NoStyle, `remove all styling from table output.`)
+		c.Flags().StringVar(&options.OutputFormat, "output", options.OutputFormat, `The output format (json or text)`)
+	}
+
+	quotaCmd.AddCommand(getCmd, listCmd, setCmd, deleteCmd)
+	return quotaCmd
+}
+
+func getQuota(cmd *cobra.Command, cmdArgs []string, options *QuotaOptions) error {
+	clientID := ""
+	if len(cmdArgs) > 0 {
+		clientID = cmdArgs[0]
+	}
+	quota, usage, err := GetAPIClient().GetQuota(cmd.Context(), clientID)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error getting quota: %s", err), 1)
+		return err
+	}
+	return printQuota(cmd, quota, &usage, options)
+}
+
+func listQuotas(cmd *cobra.Command, options *QuotaOptions) error {
+	quotas, err := GetAPIClient().ListQuotas(cmd.Context())
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error listing quotas: %s", err), 1)
+		return err
+	}
+
+	if options.OutputFormat == JSONFormat {
+		msgBytes, err := model.JSONMarshalIndentWithMax(quotas, 2)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error marshaling quotas to JSON: %s", err), 1)
+			return err
+		}
+		cmd.Printf("%s\n", msgBytes)
+		return nil
+	}
+
+	tw := newQuotaTable(cmd, options,
+		table.Row{"client", "concurrent jobs", "queued jobs", "submissions per minute", "cpu", "memory", "gpu"})
+	for _, quota := range quotas {
+		tw.AppendRow(table.Row{
+			quota.ClientID,
+			formatLimit(quota.MaxConcurrentJobs),
+			formatLimit(quota.MaxQueuedJobs),
+			formatLimit(quota.MaxSubmissionsPerMinute),
+			formatResourceLimit(quota.MaxResources.CPU),
+			formatResourceLimit(quota.MaxResources.Memory),
+			formatResourceLimit(quota.MaxResources.GPU),
+		})
+	}
+	tw.Render()
+	return nil
+}
+
+// printQuota prints each limit of the quota, and how much of it is used if the
+// usage is known.
+func printQuota(cmd *cobra.Command, quota model.ClientQuota, usage *model.ClientUsage, options *QuotaOptions) error {
+	if options.OutputFormat == JSONFormat {
+		msgBytes, err := model.JSONMarshalIndentWithMax(struct {
+			Quota model.ClientQuota
+			Usage *model.ClientUsage `json:",omitempty"`
+		}{quota, usage}, 2)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error marshaling quota to JSON: %s", err), 1)
+			return err
+		}
+		cmd.Printf("%s\n", msgBytes)
+		return nil
+	}
+
+	if quota.ClientID == "" {
+		cmd.Println("The client has the default quota")
+	}
+	header := table.Row{"limit", "quota"}
+	if usage != nil {
+		header = append(header, "used")
+	}
+	tw := newQuotaTable(cmd, options, header)
+	rows := []table.Row{
+		{"concurrent jobs", formatLimit(quota.MaxConcurrentJobs)},
+		{"queued jobs", formatLimit(quota.MaxQueuedJobs)},
+		{"submissions per minute", formatLimit(quota.MaxSubmissionsPerMinute)},
+		{"cpu", formatResourceLimit(quota.MaxResources.CPU)},
+		{"memory", formatResourceLimit(quota.MaxResources.Memory)},
+		{"gpu", formatResourceLimit(quota.MaxResources.GPU)},
+	}
+	if usage != nil {
+		used := []string{
+			strconv.Itoa(usage.RunningJobs),
+			strconv.Itoa(usage.QueuedJobs),
+			strconv.Itoa(usage.SubmissionsLastMinute),
+			strconv.FormatFloat(usage.Resources.CPU, 'g', -1, 64),
+			datasize.ByteSize(usage.Resources.Memory).HR(),
+			strconv.FormatUint(usage.Resources.GPU, 10),
+		}
+		for i := range rows {
+			rows[i] = append(rows[i], used[i])
+		}
+	}
+	tw.AppendRows(rows)
+	tw.Render()
+	return nil
+}
+
+func newQuotaTable(cmd *cobra.Command, options *QuotaOptions, header table.Row) table.Writer {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(cmd.OutOrStdout())
+	if !options.HideHeader {
+		tw.AppendHeader(header)
+	}
+	if options.NoStyle {
+		tw.SetStyle(table.StyleDefault)
+		tw.Style().Options = table.OptionsNoBordersAndSeparators
+	} else {
+		tw.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	}
+	return tw
+}
+
+func formatLimit(limit int) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return strconv.Itoa(limit)
+}
+
+func formatResourceLimit(limit string) string {
+	if limit == "" {
+		return "unlimited"
+	}
+	return limit
+}
@@ -106,12 +106,37 @@ func NewRootCmd() *cobra.Command {
 	// Get the results of a job
 	RootCmd.AddCommand(newGetCmd())
 
+	// Stream the output of a job
+	RootCmd.AddCommand(newLogsCmd())
+
 	// Cancel a job
 	RootCmd.AddCommand(newCancelCmd())
 
 	// List jobs
 	RootCmd.AddCommand(newListCmd())
 
+	// Show the jobs that wait to start
+	RootCmd.AddCommand(newQueueCmd())
+
+	// Submit jobs on a cron schedule
+	RootCmd.AddCommand(newScheduleCmd())
+
+	// Run jobs that depend on the outputs of other jobs
+	RootCmd.AddCommand(newWorkflowCmd())
+
+	// Show and manage the quotas of clients
+	RootCmd.AddCommand(newQuotaCmd())
+	RootCmd.AddCommand(newNodeAccessCmd())
+
+	// Drain a compute node before taking it out of the network
+	RootCmd.AddCommand(newDrainCmd())
+
+	// Show or override when a compute node bids on jobs
+	RootCmd.AddCommand(newBiddingWindowCmd())
+
+	// Manage job templates and submit jobs from them
+	RootCmd.AddCommand(newTemplateCmd())
+
 	// ====== Run a server
 
 	// Serve commands
@@ -0,0 +1,234 @@
+package bacalhau
+
+import (
+	"fmt"
+	"strings"
+
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	scheduleLong = templates.LongDesc(i18n.T(`
+		Manage jobs that the requester node submits each time a cron expression fires.
+
+		Cron expressions have five fields, for the minute, hour, day of the month, month
+		and day of the week, and are evaluated in UTC. Descriptors like @hourly and
+		@daily are accepted too.
+`))
+
+	//nolint:lll // Documentation
+	scheduleExample = templates.Examples(i18n.T(`
+		# Submit the job in job.yaml at the start of every hour
+		bacalhau schedule create --cron "0 * * * *" ./job.yaml
+
+		# List your schedules and when they next run
+		bacalhau schedule list
+
+		# Submit the job of a schedule now, without waiting for it to fire
+		bacalhau schedule trigger ebd9bf2f
+
+		# Stop a schedule submitting jobs until it's resumed
+		bacalhau schedule pause ebd9bf2f`))
+)
+
+type ScheduleOptions struct {
+	Cron         string // The cron expression of when to submit the job
+	KeepRuns     int    // How many of the most recent runs to keep in the history
+	HideHeader   bool   // Hide the column headers
+	NoStyle      bool   // Remove all styling from table output.
+	OutputFormat string // The output format for the list of schedules (json or text)
+	OutputWide   bool   // Print full values in the table results
+}
+
+func NewScheduleOptions() *ScheduleOptions {
+	return &ScheduleOptions{
+		KeepRuns:     requester.DefaultKeepRuns,
+		OutputFormat: "text",
+	}
+}
+
+func newScheduleCmd() *cobra.Command {
+	options := NewScheduleOptions()
+
+	scheduleCmd := &cobra.Command{
+		Use:     "schedule",
+		Short:   "Manage jobs that are submitted on a cron schedule",
+		Long:    scheduleLong,
+		Example: scheduleExample,
+	}
+
+	createCmd := &cobra.Command{
+		Use:    "create [file]",
+		Short:  "Create a schedule that submits the job in a json or yaml file",
+		Args:   cobra.MaximumNArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return createSchedule(cmd, cmdArgs, options)
+		},
+	}
+	createCmd.Flags().StringVar(&options.Cron, "cron", options.Cron,
+		`When to submit the job, as a cron expression (e.g. "*/15 * * * *" for every 15 minutes) in UTC.`)
+	createCmd.Flags().IntVar(&options.KeepRuns, "keep-runs", options.KeepRuns,
+		`How many of the most recent runs of the schedule to keep in its history.`)
+	_ = createCmd.MarkFlagRequired("cron")
+
+	listCmd := &cobra.Command{
+		Use:    "list",
+		Short:  "List your schedules",
+		Args:   cobra.NoArgs,
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return listSchedules(cmd, options)
+		},
+	}
+	listCmd.Flags().BoolVar(&options.HideHeader, "hide-header", options.HideHeader, `do not print the column headers.`)
+	listCmd.Flags().BoolVar(&options.NoStyle, "no-style", options.NoStyle, `remove all styling from table output.`)
+	listCmd.Flags().StringVar(&options.OutputFormat, "output", options.OutputFormat,
+		`The output format for the list of schedules (json or text)`)
+	listCmd.Flags().BoolVar(&options.OutputWide, "wide", options.OutputWide, `Print full values in the table results`)
+
+	scheduleCmd.AddCommand(
+		createCmd,
+		listCmd,
+		newScheduleActionCmd(model.ScheduleActionPause, "Stop a schedule submitting jobs until it's resumed"),
+		newScheduleActionCmd(model.ScheduleActionResume, "Start a paused schedule submitting jobs again"),
+		newScheduleActionCmd(model.ScheduleActionTrigger, "Submit the job of a schedule now"),
+		newScheduleActionCmd(model.ScheduleActionDelete, "Delete a schedule, leaving the jobs it submitted"),
+	)
+	return scheduleCmd
+}
+
+func newScheduleActionCmd(action model.ScheduleAction, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:    string(action) + " [id]",
+		Short:  short,
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			schedule, err := GetAPIClient().UpdateSchedule(cmd.Context(), cmdArgs[0], action)
+			if err != nil {
+				Fatal(cmd, fmt.Sprintf("Error trying to %s schedule %s: %s", action, cmdArgs[0], err), 1)
+				return nil
+			}
+			if action == model.ScheduleActionTrigger && len(schedule.History) > 0 {
+				run := schedule.History[len(schedule.History)-1]
+				if run.Error != "" {
+					Fatal(cmd, fmt.Sprintf("Error submitting the job of schedule %s: %s", schedule.ID, run.Error), 1)
+					return nil
+				}
+				cmd.Printf("Job ID: %s\n", run.JobID)
+				return nil
+			}
+			cmd.Printf("Schedule %s: %s\n", schedule.ID, scheduleState(schedule, action))
+			return nil
+		},
+	}
+}
+
+func createSchedule(cmd *cobra.Command, cmdArgs []string, options *ScheduleOptions) error {
+	ctx := cmd.Context()
+
+	data, err := readFileOrStdin(cmd, cmdArgs)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error reading job: %s", err), 1)
+		return err
+	}
+
+	j, err := model.NewJobWithSaneProductionDefaults()
+	if err != nil {
+		return err
+	}
+	// the yaml parser reads json as well
+	if err = model.YAMLUnmarshalWithMax(data, &j); err != nil || j == nil {
+		Fatal(cmd, fmt.Sprintf("Error parsing job: %s", err), 1)
+		return err
+	}
+	if err = jobutils.VerifyJob(ctx, j); err != nil {
+		Fatal(cmd, fmt.Sprintf("Error verifying job: %s", err), 1)
+		return err
+	}
+
+	schedule, err := GetAPIClient().CreateSchedule(ctx, j, options.Cron, options.KeepRuns)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error creating schedule: %s", err), 1)
+		return err
+	}
+	cmd.Printf("Schedule ID: %s\nNext run: %s\n", schedule.ID, schedule.NextRunAt.Format("2006-01-02 15:04:05 MST"))
+	return nil
+}
+
+func listSchedules(cmd *cobra.Command, options *ScheduleOptions) error {
+	schedules, err := GetAPIClient().ListSchedules(cmd.Context())
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error listing schedules: %s", err), 1)
+		return err
+	}
+
+	if options.OutputFormat == JSONFormat {
+		var msgBytes []byte
+		msgBytes, err = model.JSONMarshalWithMax(schedules)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error marshaling schedules to JSON: %s", err), 1)
+			return err
+		}
+		cmd.Printf("%s\n", msgBytes)
+		return nil
+	}
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(cmd.OutOrStdout())
+	if !options.HideHeader {
+		tw.AppendHeader(table.Row{"id", "cron", "state", "next run", "last job"})
+	}
+	for _, schedule := range schedules {
+		tw.AppendRow(summarizeSchedule(schedule, options))
+	}
+	if options.NoStyle {
+		tw.SetStyle(table.StyleDefault)
+		tw.Style().Options = table.OptionsNoBordersAndSeparators
+	} else {
+		tw.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	}
+	tw.Render()
+	return nil
+}
+
+// Renders schedule details into a table row
+func summarizeSchedule(schedule model.JobSchedule, options *ScheduleOptions) table.Row {
+	nextRun := schedule.NextRunAt.Format("06-01-02-15:04")
+	if schedule.Paused {
+		nextRun = ""
+	}
+	lastJob := ""
+	if len(schedule.History) > 0 {
+		run := schedule.History[len(schedule.History)-1]
+		lastJob = shortID(options.OutputWide, run.JobID)
+		if run.Error != "" {
+			lastJob = shortenString(options.OutputWide, "failed: "+run.Error)
+		}
+	}
+	return table.Row{
+		shortID(options.OutputWide, schedule.ID),
+		schedule.Cron,
+		scheduleState(schedule, ""),
+		nextRun,
+		lastJob,
+	}
+}
+
+func scheduleState(schedule model.JobSchedule, action model.ScheduleAction) string {
+	switch {
+	case action == model.ScheduleActionDelete:
+		return "deleted"
+	case schedule.Paused:
+		return "paused"
+	default:
+		return strings.Join([]string{"active", "next run", schedule.NextRunAt.Format("2006-01-02 15:04 MST")}, ", ")
+	}
+}
@@ -3,15 +3,33 @@ package bacalhau
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	compute_bidstrategies "github.com/bacalhau-project/bacalhau/pkg/compute/bidstrategy"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/apptainer"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/containerd"
+	docker_executor "github.com/bacalhau-project/bacalhau/pkg/executor/docker"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/duckdb"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/kubernetes"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/plugin"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/process"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/python"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/ssh"
 	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs/pinning"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/boltdb"
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
 	"github.com/bacalhau-project/bacalhau/pkg/libp2p"
 	"github.com/bacalhau-project/bacalhau/pkg/libp2p/rcmgr"
@@ -19,10 +37,27 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/node"
 	filecoinlotus "github.com/bacalhau-project/bacalhau/pkg/publisher/filecoin_lotus"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/local"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/azureblob"
+	storage_bidstrategy "github.com/bacalhau-project/bacalhau/pkg/storage/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/bittorrent"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/cache"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/extract"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/filecoin"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/gcs"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/huggingface"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/ipfs/mount"
+	localdirectory "github.com/bacalhau-project/bacalhau/pkg/storage/local_directory"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/prefetch"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/s3"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/url/urldownload"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
 	"github.com/multiformats/go-multiaddr"
 
+	"github.com/c2h5oh/datasize"
+	big2 "github.com/filecoin-project/go-state-types/big"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"k8s.io/kubectl/pkg/util/i18n"
@@ -60,6 +95,7 @@ type ServeOptions struct {
 	IPFSConnect                           string            // The multiaddress to connect to for IPFS.
 	FilecoinUnsealedPath                  string            // Go template to turn a Filecoin CID into a local filepath with the unsealed data.
 	EstuaryAPIKey                         string            // The API key used when using the estuary API.
+	IPFSPinningServices                   []string          // The remote pinning services to pin results and uploaded inputs to, as ENDPOINT=TOKEN.
 	HostAddress                           string            // The host address to listen on.
 	SwarmPort                             int               // The host port for libp2p network.
 	JobSelectionDataLocality              string            // The data locality to use for job selection.
@@ -67,20 +103,164 @@ type ServeOptions struct {
 	JobSelectionDataAcceptNetworked       bool              // Whether to accept jobs that require network access.
 	JobSelectionProbeHTTP                 string            // The HTTP URL to use for job selection.
 	JobSelectionProbeExec                 string            // The executable to use for job selection.
+	JobSelectionProbeWasm                 []string          // The WASM modules to use for job selection
+	BidStrategyChain                      string            // YAML or JSON file listing the bid strategies of the compute node in order
+	AllowedImages                         []string          // Patterns of the only images that the compute node runs
+	DeniedImages                          []string          // Patterns of the images that the compute node doesn't run
+	AllowedWasmModules                    []string          // Patterns of the only WASM modules that the compute node runs
+	DeniedWasmModules                     []string          // Patterns of the WASM modules that the compute node doesn't run
+	JobModerationURL                      string            // The moderation service that approves jobs before the node bids on them
+	JobModerationToken                    string            // The bearer token to send to the moderation service
+	JobModerationPollInterval             time.Duration     // How often to ask the moderation service again about jobs it hasn't decided on
+	JobModerationTimeout                  time.Duration     // How long to wait for the moderation service to decide on a job
+	ClientReputationHalfLife              time.Duration     // How long until the executions of clients count half as much in their reputations
+	ClientAbuseLimit                      float64           // How many recent abuses of the compute node make it reject the jobs of a client
+	ClientAbuseDelay                      time.Duration     // How long the compute node waits to bid on jobs per recent abuse by their client
+	ClientAbuseMaxDelay                   time.Duration     // The longest the compute node waits to bid on the jobs of abusive clients
 	LimitTotalCPU                         string            // The total amount of CPU the system can be using at one time.
 	LimitTotalMemory                      string            // The total amount of memory the system can be using at one time.
 	LimitTotalGPU                         string            // The total amount of GPU the system can be using at one time.
 	LimitJobCPU                           string            // The amount of CPU the system can be using at one time for a single job.
 	LimitJobMemory                        string            // The amount of memory the system can be using at one time for a single job.
 	LimitJobGPU                           string            // The amount of GPU the system can be using at one time for a single job.
+	EngineConcurrency                     map[string]int    // How many executions of each engine may run at once
+	LimitExecutions                       int               // How many executions the node can have and still bid on jobs
+	LimitLoadAverage                      float64           // How high the load average per CPU can be for the node to bid on jobs
+	LimitDiskUsage                        float64           // How much of the disk can be used for the node to bid on jobs
+	BidProbability                        float64           // The chance that the compute node bids on each job it could run
+	BidProbabilityByLoad                  bool              // Whether the compute node bids on fewer jobs the more capacity it uses
 	LotusFilecoinStorageDuration          time.Duration     // How long deals should be for the Lotus Filecoin publisher
 	LotusFilecoinPathDirectory            string            // The location of the Lotus configuration directory which contains config.toml, etc
 	LotusFilecoinUploadDirectory          string            // Directory to put files when uploading to Lotus (optional)
 	LotusFilecoinMaximumPing              time.Duration     // The maximum ping allowed when selecting a Filecoin miner
+	LotusFilecoinRetrievalDirectory       string            // Directory for Lotus to export inputs retrieved from Filecoin into (optional)
+	LotusFilecoinRetrievalMaxPrice        big2.Int          // The most to pay to retrieve an input from Filecoin, in attoFIL
+	LocalPublisherDirectory               string            // Directory to keep results in for the local publisher, which is only enabled if set
+	LocalPublisherAddress                 string            // Address for the local publisher to serve results on
+	LocalPublisherURL                     string            // URL that clients download results of the local publisher from (optional)
+	LocalPublisherToken                   string            // Token that clients must send to download results of the local publisher (generated if empty)
+	WebhookAllowedNetworks                []string          // Networks that the webhooks of jobs can be on, on top of public addresses
+	MaxHighPriorityJobsPerClient          int               // The most high priority jobs that each client can have in flight
+	RequireClientSignatures               bool              // Whether the node only accepts and bids on jobs signed by their clients
+	Preemption                            bool              // Whether high priority jobs may preempt executions of less urgent jobs
+	PreemptionProtectedClients            []string          // IDs of clients whose executions are never preempted
+	Preemptible                           bool              // Whether the requester may preempt executions on the compute node
+	Pricing                               model.Pricing     // What the compute node charges for the resources executions use
+	Taints                                []model.Taint     // Taints that keep the jobs that don't tolerate them off the compute node
+	TEE                                   model.TEEType     // The kind of TEE that the compute node runs in
+	TEEReportDir                          string            // Where the kernel creates the attestation reports of the TEE
+	TEECertificates                       string            // PEM file of the certificates that the evidence of the TEE is verified with
+	ComputeAdmins                         []string          // IDs of clients that can drain the compute node
+	BiddingWindows                        []string          // The times of the week that the compute node bids on jobs
+	BiddingTimezone                       string            // The time zone of the bidding windows
+	Benchmark                             bool              // Whether the compute node benchmarks itself when it starts
+	BenchmarkNetworkURL                   string            // URL the compute node downloads to benchmark its network
+	DiskUsageCheckInterval                time.Duration     // How often the compute node measures the scratch space of executions
+	QuotaMaxConcurrentJobs                int               // How many jobs each client without its own quota can run at once
+	QuotaMaxQueuedJobs                    int               // How many jobs each client without its own quota can queue
+	QuotaMaxSubmissionsPerMinute          int               // How many jobs each client without its own quota can submit a minute
+	QuotaMaxCPU                           string            // The most CPU that the jobs of each client can request between them
+	QuotaMaxMemory                        string            // The most memory that the jobs of each client can request between them
+	QuotaMaxGPU                           string            // The most GPUs that the jobs of each client can request between them
+	QuotaAdmins                           []string          // IDs of clients that can set the quotas of other clients
+	NodeAllowlist                         []string          // IDs of the only compute nodes that executions are scheduled on
+	NodeDenylist                          []string          // IDs of compute nodes that executions aren't scheduled on
+	NodeAccessAdmins                      []string          // IDs of clients that can allow and deny compute nodes
+	LostNodeReschedules                   int               // How many times executions are rescheduled when their node is lost
+	LostNodeGracePeriod                   time.Duration     // How long a compute node can be disconnected before it is lost
+	PlacementStrategy                     string            // Whether executions are spread over nodes or bin-packed onto few
+	SpeculationSlowdownFactor             float64           // How many times slower than the others an execution is duplicated at
+	SpeculationMinRuntime                 time.Duration     // How long an execution must run before it can be duplicated
+	BackfillMaxTimeout                    time.Duration     // The longest timeout of jobs that start ahead of jobs waiting for capacity
+	BackfillMaxDelay                      time.Duration     // How long the job at the front of the queue waits before backfilling stops
+	LatencyMaxTimeout                     time.Duration     // The longest timeout of jobs that prefer nodes near the requester
+	LatencyMaxLatency                     time.Duration     // The round trip time at which nodes stop being preferred for being near
+	ReputationHalfLife                    time.Duration     // How long until the outcomes of executions count half as much in node reputations
+	ExternalRankerURL                     string            // Where an external service ranks the compute nodes for each job
+	ExternalRankerTimeout                 time.Duration     // How long the external ranker is waited for
+	NodeCatalogPath                       string            // File that lists the compute nodes, instead of discovering them
+	DNSDiscoveryName                      string            // DNS name that more compute nodes are resolved from
+	DNSDiscoveryRefreshInterval           time.Duration     // How long the nodes resolved from DNS are kept
+	TEETrustRoots                         string            // PEM file of the vendor roots that the evidence of TEEs must chain to
 	JobExecutionTimeoutClientIDBypassList []string          // IDs of clients that can submit jobs more than the configured job execution timeout
 	Labels                                map[string]string // Labels to apply to the node that can be used for node selection and filtering
 	IPFSSwarmAddresses                    []string          // IPFS multiaddresses that the in-process IPFS should connect to
 	PrivateInternalIPFS                   bool              // Whether the in-process IPFS should automatically discover other IPFS nodes
+	DockerUserNamespace                   bool              // Whether docker jobs must run in a user namespace
+	DockerSeccompProfile                  string            // Path to the seccomp profile to run docker jobs with
+	DockerAppArmorProfile                 string            // Name of the AppArmor profile to run docker jobs with
+	DockerAllowSecurityProfileOverride    bool              // Whether docker jobs can choose their own seccomp and AppArmor profiles
+	DockerIODevices                       []string          // Block devices to enforce the IOPS limits of docker jobs on
+	DockerRequireDiskLimits               bool              // Whether the node declines docker jobs whose disk limits it can't enforce
+	DockerRuntime                         string            // The OCI runtime to run docker jobs with, e.g. runsc
+	DockerScratchPath                     string            // Where to mount the scratch volume of docker jobs
+	DockerScratchSize                     datasize.ByteSize // The size of the scratch volume of docker jobs that don't request disk
+	ContainerdAddress                     string            // Socket of the containerd to run docker jobs with, instead of the docker daemon
+	ContainerdNamespace                   string            // The containerd namespace to run docker jobs in
+	KubernetesExecutor                    bool              // Whether to run docker jobs as pods in a Kubernetes cluster
+	KubernetesKubeconfig                  string            // The kubeconfig to connect to the Kubernetes cluster with
+	KubernetesNamespace                   string            // The namespace to run docker jobs in on Kubernetes
+	KubernetesHelperImage                 string            // The image used to copy inputs and outputs in and out of pods
+	ApptainerBinary                       string            // The apptainer or singularity command to run apptainer jobs with
+	ApptainerImageCacheDir                string            // Where to keep the images of apptainer jobs once converted to SIF
+	ApptainerResourceLimits               bool              // Whether to enforce CPU and memory limits on apptainer jobs
+	ProcessExecutor                       bool              // Whether to run process jobs directly on the host
+	ProcessCgroupParent                   string            // Cgroup to enforce the resource limits of process jobs in
+	PythonInterpreter                     string            // The python to create the virtualenvs of python jobs with
+	PythonVirtualenvCacheDir              string            // Where to keep the virtualenvs of python jobs
+	ExecutorPluginsDir                    string            // Where to discover executor plugins from
+	SSHHost                               string            // The remote host to run process jobs on over SSH
+	SSHUser                               string            // Who to log in to the SSH host as
+	SSHIdentityFile                       string            // The private key to log in to the SSH host with
+	SSHKnownHostsFile                     string            // The known hosts file with the key of the SSH host
+	SSHWorkDir                            string            // Where to create job directories on the SSH host
+	DuckDBExecutor                        bool              // Whether to run SQL queries over the inputs of duckdb jobs
+	DuckDBBinary                          string            // The duckdb command to run queries with
+	S3Region                              string            // The region of the S3 buckets to download inputs from
+	S3Endpoint                            string            // An S3 compatible store to download inputs from instead of AWS
+	S3Profile                             string            // The AWS profile to download inputs from S3 as
+	S3DownloadConcurrency                 int               // How many objects of an S3 input to download at once
+	URLDownloadRetries                    int               // How many times to retry downloading URL inputs
+	URLDownloadRetryWaitMax               time.Duration     // The longest to wait between retries of URL inputs
+	URLDownloadMaxRedirects               int               // How many redirects to follow when downloading URL inputs
+	URLDownloadCacheDir                   string            // Where to keep URL inputs served with an ETag
+	URLDownloadStreams                    int               // How many ranges of a URL input to download at once
+	URLDownloadChunkSize                  datasize.ByteSize // How large the ranges of a URL input are
+	AzureManagedIdentityClientID          string            // The managed identity to download Azure blobs as
+	AzureBlobDownloadConcurrency          int               // How many blobs of an Azure input to download at once
+	GCSCredentialsFile                    string            // The service account key or workload identity config to download GCS objects as
+	GCSDownloadConcurrency                int               // How many objects of a GCS input to download at once
+	HuggingFaceEndpoint                   string            // The Hugging Face Hub, or a mirror of it, to download models and datasets from
+	HuggingFaceToken                      string            // The token to download private and gated models and datasets with
+	HuggingFaceCacheDir                   string            // Where to keep downloaded models and datasets
+	BitTorrentListenPort                  int               // The port to accept connections from BitTorrent peers on
+	BitTorrentNoUpload                    bool              // Whether to stop sharing torrent inputs with other peers
+	BitTorrentNoDHT                       bool              // Whether to stop finding BitTorrent peers through the DHT
+	StorageCacheSize                      datasize.ByteSize // How much disk to keep downloaded inputs in for later jobs
+	StorageCacheDir                       string            // Where to keep downloaded inputs for later jobs
+	IPFSLazyMountMinSize                  datasize.ByteSize // The size from which IPFS inputs are mounted with FUSE rather than fetched
+	PrefetchBudget                        datasize.ByteSize // How much input data to fetch at once for jobs that have been bid on
+	ExtractMaxSize                        datasize.ByteSize // The most that the archive of an input may extract to
+	ExtractMaxFiles                       int               // The most entries that the archive of an input may have
+	InputSources                          []string          // The only storage sources that inputs can be from
+	InputCIDs                             []string          // The only CIDs that inputs can be
+	InputURLPrefixes                      []string          // What the URLs of inputs must start with
+	LimitInputSize                        datasize.ByteSize // The most that the inputs of a job can add up to
+	PublishAttempts                       int               // How many times to try publishing results with each publisher in a row
+	PublishPendingTimeout                 time.Duration     // How long to keep trying to publish results before failing
+	CheckpointInterval                    time.Duration     // How often to checkpoint executions that can be, or never if zero
+	CheckpointDir                         string            // Where to keep the checkpoints of executions
+	ResultCacheTTL                        time.Duration     // How long to reuse published results for identical jobs, or never if zero
+	ResultCacheMaxEntries                 int               // The most published results to keep for identical jobs
+	ResultCacheNetworked                  bool              // Whether to reuse the results of jobs with network access too
+	ScratchGCInterval                     time.Duration     // How often to remove the scratch space executions left behind, or never if negative
+	ScratchGCMinAge                       time.Duration     // How long scratch space must go unmodified before it is removed
+	ScratchGCDiskPressure                 float64           // The fraction of the disk used from which scratch space is removed whatever its age
+	JobStorePath                          string            // File to keep requester jobs in (default: jobs.db in the bacalhau dir)
+	JobStoreInMemory                      bool              // Whether to keep the jobs of the requester in memory, losing them on restart
+
+	// The directories of the node that jobs may mount
+	AllowListedLocalPaths []localdirectory.AllowedPath
 }
 
 func NewServeOptions() *ServeOptions {
@@ -90,6 +270,7 @@ func NewServeOptions() *ServeOptions {
 		IPFSConnect:                     "",
 		FilecoinUnsealedPath:            "",
 		EstuaryAPIKey:                   os.Getenv("ESTUARY_API_KEY"),
+		IPFSPinningServices:             strings.Fields(os.Getenv("IPFS_PINNING_SERVICES")),
 		HostAddress:                     "0.0.0.0",
 		SwarmPort:                       DefaultSwarmPort,
 		JobSelectionDataLocality:        "local",
@@ -97,14 +278,56 @@ func NewServeOptions() *ServeOptions {
 		JobSelectionDataAcceptNetworked: false,
 		JobSelectionProbeHTTP:           "",
 		JobSelectionProbeExec:           "",
+		JobModerationToken:              os.Getenv("BACALHAU_JOB_MODERATION_TOKEN"),
+		JobModerationPollInterval:       bidstrategy.DefaultModerationPollInterval,
+		JobModerationTimeout:            bidstrategy.DefaultModerationTimeout,
+		ClientReputationHalfLife:        compute.DefaultClientReputationHalfLife,
+		BidProbability:                  1,
 		LimitTotalCPU:                   "",
 		LimitTotalMemory:                "",
 		LimitTotalGPU:                   "",
 		LimitJobCPU:                     "",
 		LimitJobMemory:                  "",
 		LimitJobGPU:                     "",
+		EngineConcurrency:               map[string]int{},
 		LotusFilecoinPathDirectory:      os.Getenv("LOTUS_PATH"),
 		LotusFilecoinMaximumPing:        2 * time.Second,
+		LocalPublisherAddress:           local.DefaultAddress,
+		LocalPublisherToken:             os.Getenv("BACALHAU_LOCAL_PUBLISHER_TOKEN"),
+		ContainerdNamespace:             containerd.DefaultNamespace,
+		KubernetesNamespace:             kubernetes.DefaultNamespace,
+		KubernetesHelperImage:           kubernetes.DefaultHelperImage,
+		ApptainerBinary:                 apptainer.DefaultBinary,
+		SSHWorkDir:                      ssh.DefaultWorkDir,
+		PythonInterpreter:               python.DefaultInterpreter,
+		DuckDBBinary:                    duckdb.DefaultBinary,
+		S3DownloadConcurrency:           s3.DefaultDownloadConcurrency,
+		URLDownloadRetries:              config.GetDownloadURLRequestRetries(),
+		URLDownloadRetryWaitMax:         urldownload.DefaultRetryWaitMax,
+		URLDownloadMaxRedirects:         urldownload.DefaultMaxRedirects,
+		URLDownloadStreams:              urldownload.DefaultStreams,
+		URLDownloadChunkSize:            urldownload.DefaultChunkSize,
+		AzureBlobDownloadConcurrency:    azureblob.DefaultDownloadConcurrency,
+		GCSDownloadConcurrency:          gcs.DefaultDownloadConcurrency,
+		HuggingFaceEndpoint:             huggingface.DefaultEndpoint,
+		HuggingFaceToken:                os.Getenv("HF_TOKEN"),
+		ExtractMaxSize:                  extract.DefaultMaxSize,
+		ExtractMaxFiles:                 extract.DefaultMaxFiles,
+		LostNodeReschedules:             node.DefaultRequesterConfig.LostNodeReschedules,
+		LostNodeGracePeriod:             node.DefaultRequesterConfig.LostNodeGracePeriod,
+		SpeculationMinRuntime:           requester.DefaultSpeculationMinRuntime,
+		BackfillMaxDelay:                requester.DefaultBackfillMaxDelay,
+		LatencyMaxLatency:               requester.DefaultMaxLatency,
+		ReputationHalfLife:              node.DefaultRequesterConfig.ReputationHalfLife,
+		ExternalRankerTimeout:           node.DefaultRequesterConfig.ExternalRankerTimeout,
+		DNSDiscoveryRefreshInterval:     node.DefaultRequesterConfig.DNSDiscoveryRefreshInterval,
+		PublishAttempts:                 compute.DefaultPublishRetryOptions.Attempts,
+		PublishPendingTimeout:           compute.DefaultPublishRetryOptions.PendingTimeout,
+		DiskUsageCheckInterval:          node.DefaultComputeConfig.DiskUsageCheckInterval,
+		ResultCacheMaxEntries:           compute.DefaultResultCacheOptions.MaxEntries,
+		ScratchGCInterval:               compute.DefaultScratchGCOptions.Interval,
+		ScratchGCMinAge:                 compute.DefaultScratchGCOptions.MinAge,
+		ScratchGCDiskPressure:           compute.DefaultScratchGCOptions.DiskPressure,
 	}
 }
 
@@ -129,6 +352,69 @@ func setupJobSelectionCLIFlags(cmd *cobra.Command, OS *ServeOptions) {
 		&OS.JobSelectionProbeExec, "job-selection-probe-exec", OS.JobSelectionProbeExec,
 		`Use the result of a exec an external program to decide if we should take on the job.`,
 	)
+	cmd.PersistentFlags().StringSliceVar(
+		&OS.JobSelectionProbeWasm, "job-selection-probe-wasm", OS.JobSelectionProbeWasm,
+		`Use the result of a WASM module to decide if we should take on the job. The module is run as a WASI command `+
+			`with the same input as the exec probe, and exits with zero to take on the job. Can be specified more than once.`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&OS.BidStrategyChain, "bid-strategy-chain", OS.BidStrategyChain,
+		`YAML or JSON file listing the strategies that decide whether this node bids on jobs, in order, such as `+
+			`"strategies: [{name: drain}, {name: load, params: {MaxExecutions: 10}}, {name: price, phase: resource}]". `+
+			`Replaces the default strategies, including the WASM modules of --job-selection-probe-wasm.`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&OS.AllowedImages, "allowed-images", OS.AllowedImages,
+		`Only take on jobs whose image matches one of these patterns, where * matches anything (e.g. ubuntu:*,ghcr.io/org/*).`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&OS.DeniedImages, "denied-images", OS.DeniedImages,
+		`Don't take on jobs whose image matches one of these patterns, even if they are allowed.`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&OS.AllowedWasmModules, "allowed-wasm-modules", OS.AllowedWasmModules,
+		`Only take on WASM jobs whose modules' CIDs, or URLs, match one of these patterns.`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&OS.DeniedWasmModules, "denied-wasm-modules", OS.DeniedWasmModules,
+		`Don't take on WASM jobs with a module whose CID, or URL, matches one of these patterns, even if it is allowed.`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&OS.JobModerationURL, "job-moderation-url", OS.JobModerationURL,
+		`A moderation service to POST jobs to for approval before bidding on them, such as by a person or a policy `+
+			`server. It responds like the HTTP probe, or with shouldWait to be asked again until it decides.`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&OS.JobModerationToken, "job-moderation-token", OS.JobModerationToken,
+		`The bearer token to send to the moderation service. Defaults to $BACALHAU_JOB_MODERATION_TOKEN.`,
+	)
+	cmd.PersistentFlags().DurationVar(
+		&OS.JobModerationPollInterval, "job-moderation-poll-interval", OS.JobModerationPollInterval,
+		`How often to ask the moderation service again about jobs it hasn't decided on yet.`,
+	)
+	cmd.PersistentFlags().DurationVar(
+		&OS.JobModerationTimeout, "job-moderation-timeout", OS.JobModerationTimeout,
+		`How long to wait for the moderation service to decide on a job before rejecting it.`,
+	)
+	cmd.PersistentFlags().DurationVar(
+		&OS.ClientReputationHalfLife, "client-reputation-half-life", OS.ClientReputationHalfLife,
+		`How long it takes for the jobs of clients to count half as much in their reputations on this node, `+
+			`so that clients that stop abusing it recover.`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&OS.ClientAbuseLimit, "client-abuse-limit", OS.ClientAbuseLimit,
+		`Reject the jobs of clients that recently had this many jobs cancelled while running on this node, `+
+			`fail verification or use more resources than they declared. 0 means no limit.`,
+	)
+	cmd.PersistentFlags().DurationVar(
+		&OS.ClientAbuseDelay, "client-abuse-delay", OS.ClientAbuseDelay,
+		`How long to wait before bidding on the jobs of a client for each of its recent abuses of this node, `+
+			`so that other nodes get to bid on them first. 0 means no delay.`,
+	)
+	cmd.PersistentFlags().DurationVar(
+		&OS.ClientAbuseMaxDelay, "client-abuse-max-delay", OS.ClientAbuseMaxDelay,
+		`The longest to wait before bidding on the jobs of abusive clients. 0 means no limit.`,
+	)
 }
 
 func setupCapacityManagerCLIFlags(cmd *cobra.Command, OS *ServeOptions) {
@@ -156,10 +442,106 @@ func setupCapacityManagerCLIFlags(cmd *cobra.Command, OS *ServeOptions) {
 		&OS.LimitJobGPU, "limit-job-gpu", OS.LimitJobGPU,
 		`Job GPU limit for single job (e.g. 1, 2, or 8).`,
 	)
+	cmd.PersistentFlags().StringToIntVar(
+		&OS.EngineConcurrency, "limit-engine-concurrency", OS.EngineConcurrency,
+		`How many executions of each engine may run at once on top of the resource limits (e.g. docker=2,wasm=50). `+
+			`Engines that aren't set are only limited by resources.`,
+	)
+	cmd.PersistentFlags().IntVar(
+		&OS.LimitExecutions, "limit-executions", OS.LimitExecutions,
+		`Stop bidding on jobs while this many executions are running or accepted to run. 0 means no limit.`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&OS.LimitLoadAverage, "limit-load-average", OS.LimitLoadAverage,
+		`Stop bidding on jobs while the 1 minute load average of the host per CPU is above this (e.g. 1.5). `+
+			`Only checked on Linux. 0 means no limit.`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&OS.LimitDiskUsage, "limit-disk-usage", OS.LimitDiskUsage,
+		`Stop bidding on jobs while more than this fraction of the disk of the storage path is used (e.g. 0.9). `+
+			`0 means no limit.`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&OS.BidProbability, "bid-probability", OS.BidProbability,
+		`The chance that this node bids on each job that it could run, at random (e.g. 0.25), so that in large `+
+			`networks every node doesn't race to bid on every job.`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&OS.BidProbabilityByLoad, "bid-probability-by-load", OS.BidProbabilityByLoad,
+		`Lower the chance of bidding on jobs as this node uses more of its capacity, down to never once it is full.`,
+	)
 	cmd.PersistentFlags().StringSliceVar(
 		&OS.JobExecutionTimeoutClientIDBypassList, "job-execution-timeout-bypass-client-id", OS.JobExecutionTimeoutClientIDBypassList,
 		`List of IDs of clients that are allowed to bypass the job execution timeout check`,
 	)
+	cmd.PersistentFlags().BoolVar(
+		&OS.Preemptible, "preemptible", OS.Preemptible,
+		`Allow the requester to stop executions on this node to make room for high priority jobs, `+
+			`executing the jobs it stops again later.`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&OS.Pricing.CPUSecond, "price-cpu-second", OS.Pricing.CPUSecond,
+		`What this node charges per CPU core per second that executions run for. `+
+			`The node doesn't bid on jobs whose max budget doesn't cover running them until their timeout.`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&OS.Pricing.MemoryGBSecond, "price-memory-gb-second", OS.Pricing.MemoryGBSecond,
+		`What this node charges per GB of memory per second that executions run for.`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&OS.Pricing.GPUSecond, "price-gpu-second", OS.Pricing.GPUSecond,
+		`What this node charges per GPU per second that executions run for.`,
+	)
+	cmd.PersistentFlags().Var(
+		TaintArrayFlag(&OS.Taints), "taints",
+		`Taints that dedicate this node to the jobs that tolerate them, as key=value:effect or key:effect `+
+			`(e.g. reserved=teamA:NoSchedule). The effect is NoSchedule to keep other jobs off the node, `+
+			`or PreferNoSchedule to place them on it only if other nodes don't suit them as well.`,
+	)
+	cmd.PersistentFlags().Var(
+		TEEFlag(&OS.TEE), "tee",
+		`The kind of trusted execution environment that this node runs in, sev-snp or tdx. The node attaches `+
+			`attestation evidence from the TEE to its bids on jobs that require attested execution.`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&OS.TEEReportDir, "tee-report-dir", OS.TEEReportDir,
+		`The configfs-tsm directory that the kernel creates the attestation reports of the TEE in `+
+			`(default `+compute.DefaultTSMReportDir+`).`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&OS.TEECertificates, "tee-certificates", OS.TEECertificates,
+		`PEM file of the VCEK, ASK and ARK of a SEV-SNP node, leaf first, that requesters verify its attestation `+
+			`reports with, if the kernel doesn't return them with the reports.`,
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&OS.ComputeAdmins, "compute-admin-client-id", OS.ComputeAdmins,
+		`IDs of clients that can drain this compute node with bacalhau node drain, or override its bidding windows `+
+			`with bacalhau bidding-window, as well as the client of the node itself.`,
+	)
+	cmd.PersistentFlags().StringArrayVar(
+		&OS.BiddingWindows, "bidding-window", OS.BiddingWindows,
+		`Only bid on jobs during this time of the week, as days, times or both (e.g. "mon-fri 18:00-08:00" or "sat,sun"). `+
+			`Days are when the window starts, so it can end the next day. Can be specified more than once. `+
+			`Bids all the time if not set.`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&OS.BiddingTimezone, "bidding-timezone", OS.BiddingTimezone,
+		`The time zone of the bidding windows (e.g. Europe/London). Defaults to the local time zone.`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&OS.Benchmark, "benchmark", OS.Benchmark,
+		`Measure how fast the CPU, disk and network of this node are when it starts, for requesters to estimate `+
+			`how long jobs take to complete on it.`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&OS.BenchmarkNetworkURL, "benchmark-network-url", OS.BenchmarkNetworkURL,
+		`URL that this node downloads to benchmark its network when --benchmark is set. The network isn't benchmarked if it is empty.`,
+	)
+	cmd.PersistentFlags().DurationVar(
+		&OS.DiskUsageCheckInterval, "disk-usage-check-interval", OS.DiskUsageCheckInterval,
+		`How often this node measures the scratch space that running executions use. Executions that use more `+
+			`than the disk they declared are stopped. A negative interval turns this off.`,
+	)
 }
 
 func setupLibp2pCLIFlags(cmd *cobra.Command, OS *ServeOptions) {
@@ -220,6 +602,31 @@ func getJobSelectionConfig(OS *ServeOptions) model.JobSelectionPolicy {
 func getComputeConfig(OS *ServeOptions) node.ComputeConfig {
 	return node.NewComputeConfigWith(node.ComputeConfigParams{
 		JobSelectionPolicy: getJobSelectionConfig(OS),
+		BidStrategyModules: OS.JobSelectionProbeWasm,
+		BidStrategyChain:   parseBidStrategyChain(OS.BidStrategyChain),
+		Workloads: compute_bidstrategies.WorkloadStrategyParams{
+			AllowedImages:  OS.AllowedImages,
+			DeniedImages:   OS.DeniedImages,
+			AllowedModules: OS.AllowedWasmModules,
+			DeniedModules:  OS.DeniedWasmModules,
+		},
+		LoadLimits: compute_bidstrategies.LoadLimits{
+			MaxExecutions:  OS.LimitExecutions,
+			MaxLoadAverage: OS.LimitLoadAverage,
+			MaxDiskUsage:   OS.LimitDiskUsage,
+		},
+		Moderation: bidstrategy.ModerationStrategyParams{
+			URL:          OS.JobModerationURL,
+			Token:        OS.JobModerationToken,
+			PollInterval: OS.JobModerationPollInterval,
+			Timeout:      OS.JobModerationTimeout,
+		},
+		ClientReputation: compute.ClientReputationsParams{
+			HalfLife:      OS.ClientReputationHalfLife,
+			RejectAbuses:  OS.ClientAbuseLimit,
+			DelayPerAbuse: OS.ClientAbuseDelay,
+			MaxDelay:      OS.ClientAbuseMaxDelay,
+		},
 		TotalResourceLimits: capacity.ParseResourceUsageConfig(model.ResourceUsageConfig{
 			CPU:    OS.LimitTotalCPU,
 			Memory: OS.LimitTotalMemory,
@@ -230,17 +637,309 @@ func getComputeConfig(OS *ServeOptions) node.ComputeConfig {
 			Memory: OS.LimitJobMemory,
 			GPU:    OS.LimitJobGPU,
 		}),
+		BidProbability:                        OS.BidProbability,
+		BidProbabilityScaleByLoad:             OS.BidProbabilityByLoad,
 		IgnorePhysicalResourceLimits:          os.Getenv("BACALHAU_CAPACITY_MANAGER_OVER_COMMIT") != "",
+		EngineConcurrency:                     parseEngineConcurrency(OS.EngineConcurrency),
 		JobExecutionTimeoutClientIDBypassList: OS.JobExecutionTimeoutClientIDBypassList,
+		Preemptible:                           OS.Preemptible,
+		Pricing:                               OS.Pricing,
+		Taints:                                OS.Taints,
+		TEE:                                   OS.TEE,
+		TEEReportDir:                          OS.TEEReportDir,
+		TEECertificates:                       OS.TEECertificates,
+		RequireClientSignatures:               OS.RequireClientSignatures,
+		Admins:                                OS.ComputeAdmins,
+		BiddingWindows:                        parseBiddingWindows(OS.BiddingWindows),
+		BiddingWindowsLocation:                parseBiddingTimezone(OS.BiddingTimezone),
+		Benchmark:                             OS.Benchmark,
+		BenchmarkNetworkURL:                   OS.BenchmarkNetworkURL,
+		DiskUsageCheckInterval:                OS.DiskUsageCheckInterval,
+		DockerOptions: docker_executor.ExecutorOptions{
+			UserNamespace:                OS.DockerUserNamespace,
+			SeccompProfile:               OS.DockerSeccompProfile,
+			AppArmorProfile:              OS.DockerAppArmorProfile,
+			AllowSecurityProfileOverride: OS.DockerAllowSecurityProfileOverride,
+			IODevices:                    OS.DockerIODevices,
+			RequireDiskLimits:            OS.DockerRequireDiskLimits,
+			Runtime:                      OS.DockerRuntime,
+			ScratchPath:                  OS.DockerScratchPath,
+			ScratchSize:                  OS.DockerScratchSize,
+		},
+		ContainerdOptions: containerd.ExecutorOptions{
+			Address:         OS.ContainerdAddress,
+			Namespace:       OS.ContainerdNamespace,
+			UserNamespace:   OS.DockerUserNamespace,
+			SeccompProfile:  OS.DockerSeccompProfile,
+			AppArmorProfile: OS.DockerAppArmorProfile,
+		},
+		KubernetesOptions: kubernetes.ExecutorOptions{
+			Enabled:     OS.KubernetesExecutor,
+			Kubeconfig:  OS.KubernetesKubeconfig,
+			Namespace:   OS.KubernetesNamespace,
+			HelperImage: OS.KubernetesHelperImage,
+		},
+		ApptainerOptions: apptainer.ExecutorOptions{
+			Binary:         OS.ApptainerBinary,
+			ImageCacheDir:  OS.ApptainerImageCacheDir,
+			ResourceLimits: OS.ApptainerResourceLimits,
+		},
+		ProcessOptions: process.ExecutorOptions{
+			Enabled:      OS.ProcessExecutor,
+			CgroupParent: OS.ProcessCgroupParent,
+		},
+		PythonOptions: python.ExecutorOptions{
+			Interpreter:        OS.PythonInterpreter,
+			VirtualenvCacheDir: OS.PythonVirtualenvCacheDir,
+		},
+		PluginOptions: plugin.ExecutorOptions{
+			Directory: OS.ExecutorPluginsDir,
+		},
+		SSHOptions: ssh.ExecutorOptions{
+			Host:           OS.SSHHost,
+			User:           OS.SSHUser,
+			IdentityFile:   OS.SSHIdentityFile,
+			KnownHostsFile: OS.SSHKnownHostsFile,
+			WorkDir:        OS.SSHWorkDir,
+		},
+		DuckDBOptions: duckdb.ExecutorOptions{
+			Enabled: OS.DuckDBExecutor,
+			Binary:  OS.DuckDBBinary,
+		},
+		S3Options: s3.StorageOptions{
+			Region:              OS.S3Region,
+			Endpoint:            OS.S3Endpoint,
+			Profile:             OS.S3Profile,
+			DownloadConcurrency: OS.S3DownloadConcurrency,
+		},
+		URLDownloadOptions: urldownload.StorageOptions{
+			RetryMax:     OS.URLDownloadRetries,
+			RetryWaitMax: OS.URLDownloadRetryWaitMax,
+			MaxRedirects: OS.URLDownloadMaxRedirects,
+			CacheDir:     OS.URLDownloadCacheDir,
+			Streams:      OS.URLDownloadStreams,
+			ChunkSize:    OS.URLDownloadChunkSize,
+		},
+		AzureBlobOptions: azureblob.StorageOptions{
+			ManagedIdentityClientID: OS.AzureManagedIdentityClientID,
+			DownloadConcurrency:     OS.AzureBlobDownloadConcurrency,
+		},
+		GCSOptions: gcs.StorageOptions{
+			CredentialsFile:     OS.GCSCredentialsFile,
+			DownloadConcurrency: OS.GCSDownloadConcurrency,
+		},
+		HuggingFaceOptions: huggingface.StorageOptions{
+			Endpoint: OS.HuggingFaceEndpoint,
+			Token:    OS.HuggingFaceToken,
+			CacheDir: OS.HuggingFaceCacheDir,
+		},
+		LocalDirectoryOptions: localdirectory.StorageOptions{
+			AllowedPaths: OS.AllowListedLocalPaths,
+		},
+		BitTorrentOptions: bittorrent.StorageOptions{
+			ListenPort: OS.BitTorrentListenPort,
+			NoUpload:   OS.BitTorrentNoUpload,
+			NoDHT:      OS.BitTorrentNoDHT,
+		},
+		FilecoinOptions: filecoin.StorageOptions{
+			PathDir:   OS.LotusFilecoinPathDirectory,
+			ExportDir: OS.LotusFilecoinRetrievalDirectory,
+			MaxPrice:  OS.LotusFilecoinRetrievalMaxPrice,
+		},
+		StorageCacheOptions: cache.Options{
+			Dir:     OS.StorageCacheDir,
+			MaxSize: OS.StorageCacheSize,
+		},
+		IPFSMountOptions: mount.Options{
+			MinSize: OS.IPFSLazyMountMinSize,
+		},
+		PrefetchOptions: prefetch.Options{
+			Budget: OS.PrefetchBudget,
+		},
+		ExtractOptions: extract.Options{
+			MaxSize:  OS.ExtractMaxSize,
+			MaxFiles: OS.ExtractMaxFiles,
+		},
+		InputPolicy: storage_bidstrategy.InputPolicy{
+			AllowedSources:     parseInputSources(OS.InputSources),
+			AllowedCIDs:        OS.InputCIDs,
+			AllowedURLPrefixes: OS.InputURLPrefixes,
+			MaxInputSize:       OS.LimitInputSize.Bytes(),
+		},
+		PublishRetryOptions: compute.PublishRetryOptions{
+			Attempts:       OS.PublishAttempts,
+			PendingTimeout: OS.PublishPendingTimeout,
+		},
+		CheckpointOptions: compute.CheckpointOptions{
+			Interval: OS.CheckpointInterval,
+			Dir:      OS.CheckpointDir,
+		},
+		ResultCacheOptions: compute.ResultCacheOptions{
+			TTL:        OS.ResultCacheTTL,
+			MaxEntries: OS.ResultCacheMaxEntries,
+			Networked:  OS.ResultCacheNetworked,
+		},
+		ScratchGCOptions: compute.ScratchGCOptions{
+			Interval:     OS.ScratchGCInterval,
+			MinAge:       OS.ScratchGCMinAge,
+			DiskPressure: OS.ScratchGCDiskPressure,
+		},
 	})
 }
 
+// parseBidStrategyChain loads the chain in --bid-strategy-chain, which serve validates, or returns nil for the default
+// one.
+func parseBidStrategyChain(path string) *bidstrategy.ChainConfig {
+	if path == "" {
+		return nil
+	}
+	chain, err := bidstrategy.LoadChainConfig(path)
+	if err != nil {
+		return nil
+	}
+	return &chain
+}
+
+// parseEngineConcurrency maps the engines named in --limit-engine-concurrency, which serve validates, to their caps.
+func parseEngineConcurrency(limits map[string]int) map[model.Engine]int {
+	engineConcurrency := make(map[model.Engine]int, len(limits))
+	for name, count := range limits {
+		if engine, err := model.ParseEngine(name); err == nil {
+			engineConcurrency[engine] = count
+		}
+	}
+	return engineConcurrency
+}
+
+// parseBiddingWindows parses the windows in --bidding-window, which serve validates.
+func parseBiddingWindows(windows []string) []compute.TimeWindow {
+	parsed := make([]compute.TimeWindow, 0, len(windows))
+	for _, window := range windows {
+		if timeWindow, err := compute.ParseTimeWindow(window); err == nil {
+			parsed = append(parsed, timeWindow)
+		}
+	}
+	return parsed
+}
+
+// parseBiddingTimezone loads the time zone in --bidding-timezone, which serve validates, or returns nil for the local
+// one.
+func parseBiddingTimezone(name string) *time.Location {
+	if name == "" {
+		return nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil
+	}
+	return location
+}
+
+// parseInputSources maps the storage sources named in --input-sources, which serve validates, to their types.
+func parseInputSources(names []string) []model.StorageSourceType {
+	sources := make([]model.StorageSourceType, 0, len(names))
+	for _, name := range names {
+		if source, err := model.ParseStorageSourceType(name); err == nil {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// parseNetworks parses networks in CIDR notation.
+func parseNetworks(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// mustParseNetworks parses networks that have already been validated.
+func mustParseNetworks(cidrs []string) []*net.IPNet {
+	networks, err := parseNetworks(cidrs)
+	if err != nil {
+		panic(err)
+	}
+	return networks
+}
+
 func getRequesterConfig(OS *ServeOptions) node.RequesterConfig {
 	return node.NewRequesterConfigWith(node.RequesterConfigParams{
-		JobSelectionPolicy: getJobSelectionConfig(OS),
+		JobSelectionPolicy:           getJobSelectionConfig(OS),
+		WebhookAllowedNetworks:       mustParseNetworks(OS.WebhookAllowedNetworks),
+		MaxHighPriorityJobsPerClient: OS.MaxHighPriorityJobsPerClient,
+		RequireClientSignatures:      OS.RequireClientSignatures,
+		Preemption: requester.PreemptionPolicy{
+			Enabled:          OS.Preemption,
+			ProtectedClients: OS.PreemptionProtectedClients,
+		},
+		DefaultQuota: model.ClientQuota{
+			MaxConcurrentJobs: OS.QuotaMaxConcurrentJobs,
+			MaxQueuedJobs:     OS.QuotaMaxQueuedJobs,
+			MaxResources: model.ResourceUsageConfig{
+				CPU:    OS.QuotaMaxCPU,
+				Memory: OS.QuotaMaxMemory,
+				GPU:    OS.QuotaMaxGPU,
+			},
+			MaxSubmissionsPerMinute: OS.QuotaMaxSubmissionsPerMinute,
+		},
+		QuotaAdmins:         OS.QuotaAdmins,
+		NodeAllowlist:       OS.NodeAllowlist,
+		NodeDenylist:        OS.NodeDenylist,
+		NodeAccessAdmins:    OS.NodeAccessAdmins,
+		LostNodeReschedules: OS.LostNodeReschedules,
+		LostNodeGracePeriod: OS.LostNodeGracePeriod,
+		Placement:           requester.PlacementStrategy(OS.PlacementStrategy),
+		Speculation: requester.SpeculationPolicy{
+			SlowdownFactor: OS.SpeculationSlowdownFactor,
+			MinRuntime:     OS.SpeculationMinRuntime,
+		},
+		Backfill: requester.BackfillPolicy{
+			MaxTimeout: OS.BackfillMaxTimeout,
+			MaxDelay:   OS.BackfillMaxDelay,
+		},
+		Latency: requester.LatencyPolicy{
+			MaxTimeout: OS.LatencyMaxTimeout,
+			MaxLatency: OS.LatencyMaxLatency,
+		},
+		ReputationHalfLife:    OS.ReputationHalfLife,
+		ExternalRankerURL:     OS.ExternalRankerURL,
+		ExternalRankerTimeout: OS.ExternalRankerTimeout,
+		NodeCatalogPath:       OS.NodeCatalogPath,
+
+		DNSDiscoveryName:            OS.DNSDiscoveryName,
+		DNSDiscoveryRefreshInterval: OS.DNSDiscoveryRefreshInterval,
+		TEETrustRoots:               OS.TEETrustRoots,
 	})
 }
 
+// getJobStore returns the store that the requester keeps jobs in, which is a
+// file unless the node isn't a requester or the jobs should be kept in memory.
+func getJobStore(OS *ServeOptions, isRequesterNode bool, cm *system.CleanupManager) (jobstore.Store, error) {
+	if !isRequesterNode || OS.JobStoreInMemory {
+		return inmemory.NewJobStore(), nil
+	}
+	path := OS.JobStorePath
+	if path == "" {
+		configDir, err := system.EnsureConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(configDir, "jobs.db")
+	}
+	store, err := boltdb.NewJobStore(path)
+	if err != nil {
+		return nil, err
+	}
+	cm.RegisterCallback(store.Close)
+	return store, nil
+}
+
 func newServeCmd() *cobra.Command {
 	OS := NewServeOptions()
 
@@ -276,6 +975,12 @@ func newServeCmd() *cobra.Command {
 		&OS.EstuaryAPIKey, "estuary-api-key", OS.EstuaryAPIKey,
 		`The API key used when using the estuary API.`,
 	)
+	serveCmd.PersistentFlags().StringArrayVar(
+		&OS.IPFSPinningServices, "ipfs-pinning-service", OS.IPFSPinningServices,
+		`A remote pinning service to pin published results and uploaded inputs to, as ENDPOINT=TOKEN `+
+			`(e.g. https://api.pinata.cloud/psa=<JWT>). Can be repeated, and defaults to the space separated `+
+			`services in IPFS_PINNING_SERVICES.`,
+	)
 	serveCmd.PersistentFlags().DurationVar(
 		&OS.LotusFilecoinStorageDuration, "lotus-storage-duration", OS.LotusFilecoinStorageDuration,
 		"Duration to store data in Lotus Filecoin for.",
@@ -292,6 +997,194 @@ func newServeCmd() *cobra.Command {
 		&OS.LotusFilecoinMaximumPing, "lotus-max-ping", OS.LotusFilecoinMaximumPing,
 		"The highest ping a Filecoin miner could have when selecting.",
 	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.LotusFilecoinRetrievalDirectory, "lotus-retrieval-directory", OS.LotusFilecoinRetrievalDirectory,
+		"Directory for Lotus to export filecoin:// inputs into, which must be at the same path for Lotus and the node. "+
+			"Defaults to a directory in BACALHAU_STORAGE_PATH.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.URLDownloadStreams, "url-download-streams", OS.URLDownloadStreams,
+		"How many ranges of a URL input to download at once, if its server supports range requests. "+
+			"Interrupted ranges are resumed from where they stopped.",
+	)
+	serveCmd.PersistentFlags().Var(
+		DataSizeFlag(&OS.URLDownloadChunkSize), "url-download-chunk-size",
+		"How large the ranges of a URL input are. Inputs smaller than two ranges are downloaded in a single stream.",
+	)
+	serveCmd.PersistentFlags().Var(
+		AttoFILFlag(&OS.LotusFilecoinRetrievalMaxPrice), "lotus-retrieval-max-price",
+		"The most to pay a Filecoin miner to retrieve a filecoin:// input, in attoFIL. Only free retrievals are made if 0.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.LocalPublisherDirectory, "local-publisher-directory", OS.LocalPublisherDirectory,
+		"Directory to keep the results of jobs published with the local publisher in. "+
+			"The local publisher is only enabled if this is set.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.LocalPublisherAddress, "local-publisher-address", OS.LocalPublisherAddress,
+		"Address to serve the results of the local publisher on.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.LocalPublisherURL, "local-publisher-url", OS.LocalPublisherURL,
+		"URL that clients download the results of the local publisher from. Defaults to the address they are served on, "+
+			"and must be set if that address is on all interfaces.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.LocalPublisherToken, "local-publisher-token", OS.LocalPublisherToken,
+		"Token that clients must send to download the results of the local publisher. "+
+			"Defaults to BACALHAU_LOCAL_PUBLISHER_TOKEN. If neither is set, a token is generated and kept in "+
+			"the .token file of the local publisher directory.",
+	)
+	serveCmd.PersistentFlags().StringSliceVar(
+		&OS.WebhookAllowedNetworks, "webhook-allowed-networks", OS.WebhookAllowedNetworks,
+		"Networks in CIDR notation that the webhooks of jobs can be on, e.g. 10.0.0.0/8. Webhooks are only sent to public addresses otherwise. "+
+			"Notifications are signed with the node's key in the X-Bacalhau-Signature and X-Bacalhau-Public-Key headers.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.MaxHighPriorityJobsPerClient, "max-high-priority-jobs-per-client", OS.MaxHighPriorityJobsPerClient,
+		"The most high priority jobs that each client can have in flight on the requester node, "+
+			"so that no client can take all of the scarce compute capacity for itself. There is no limit if it is 0.",
+	)
+	serveCmd.PersistentFlags().BoolVar(
+		&OS.RequireClientSignatures, "require-client-signatures", OS.RequireClientSignatures,
+		"Only accept and bid on jobs that are signed by their clients, so that compute nodes can trust who submitted them "+
+			"and that their specs are the ones their clients signed, apart from the timeout and concurrency that the requester can raise. "+
+			"Runs of schedules and stages of workflows aren't signed by their clients, so they are rejected.",
+	)
+	serveCmd.PersistentFlags().BoolVar(
+		&OS.Preemption, "preemption", OS.Preemption,
+		"Let high priority jobs that no compute node has the capacity for preempt executions of less urgent jobs "+
+			"on the nodes that allow it with --preemptible. The jobs of preempted executions are executed again later.",
+	)
+	serveCmd.PersistentFlags().StringSliceVar(
+		&OS.PreemptionProtectedClients, "preemption-protected-client-id", OS.PreemptionProtectedClients,
+		"IDs of clients whose executions are never preempted.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.QuotaMaxConcurrentJobs, "quota-max-concurrent-jobs", OS.QuotaMaxConcurrentJobs,
+		"How many jobs each client can run at once, unless a quota admin set its own quota. "+
+			"Jobs beyond it wait in the queue. There is no limit if it is 0.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.QuotaMaxQueuedJobs, "quota-max-queued-jobs", OS.QuotaMaxQueuedJobs,
+		"How many jobs each client can have waiting in the queue, unless a quota admin set its own quota. "+
+			"Jobs submitted beyond it are rejected. There is no limit if it is 0.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.QuotaMaxSubmissionsPerMinute, "quota-max-submissions-per-minute", OS.QuotaMaxSubmissionsPerMinute,
+		"How many jobs each client can submit a minute, unless a quota admin set its own quota. There is no limit if it is 0.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.QuotaMaxCPU, "quota-max-cpu", OS.QuotaMaxCPU,
+		"The most CPU that the jobs in flight of each client can request between them (e.g. 500m, 8), "+
+			"unless a quota admin set its own quota.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.QuotaMaxMemory, "quota-max-memory", OS.QuotaMaxMemory,
+		"The most memory that the jobs in flight of each client can request between them (e.g. 32Gb), "+
+			"unless a quota admin set its own quota.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.QuotaMaxGPU, "quota-max-gpu", OS.QuotaMaxGPU,
+		"The most GPUs that the jobs in flight of each client can request between them, unless a quota admin set its own quota.",
+	)
+	serveCmd.PersistentFlags().StringSliceVar(
+		&OS.QuotaAdmins, "quota-admin-client-id", OS.QuotaAdmins,
+		"IDs of clients that can set the quotas of other clients with bacalhau quota set.",
+	)
+	serveCmd.PersistentFlags().StringSliceVar(
+		&OS.NodeAllowlist, "requester-node-allowlist", OS.NodeAllowlist,
+		"IDs of the only compute nodes that the requester schedules executions on. All nodes are allowed if it is empty.",
+	)
+	serveCmd.PersistentFlags().StringSliceVar(
+		&OS.NodeDenylist, "requester-node-denylist", OS.NodeDenylist,
+		"IDs of compute nodes that the requester doesn't schedule executions on, such as nodes that misbehave, "+
+			"which stay part of the network.",
+	)
+	serveCmd.PersistentFlags().StringSliceVar(
+		&OS.NodeAccessAdmins, "node-access-admin-client-id", OS.NodeAccessAdmins,
+		"IDs of clients that can allow and deny compute nodes at runtime with bacalhau node-access.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.LostNodeReschedules, "lost-node-reschedules", OS.LostNodeReschedules,
+		"How many times each execution of a job is rescheduled on another node when its compute node is lost, "+
+			"on top of the retries of the job's retry policy. Executions are never rescheduled if it is negative.",
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.LostNodeGracePeriod, "lost-node-grace-period", OS.LostNodeGracePeriod,
+		"How long a compute node can stay disconnected before it is considered lost and its executions are rescheduled.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.PlacementStrategy, "placement-strategy", OS.PlacementStrategy,
+		"How executions are placed on compute nodes: spread to place them on the least busy nodes for fault tolerance, "+
+			"or bin-pack to place them on the busiest nodes that fit them so that the others stay idle. "+
+			"Executions are placed at random if it is not set.",
+	)
+	serveCmd.PersistentFlags().Float64Var(
+		&OS.SpeculationSlowdownFactor, "speculation-slowdown-factor", OS.SpeculationSlowdownFactor,
+		"Duplicate executions on another compute node once they have run this many times longer than the executions "+
+			"of their job that finished, and take whichever finishes first. Executions are never duplicated if it is 0.",
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.SpeculationMinRuntime, "speculation-min-runtime", OS.SpeculationMinRuntime,
+		"How long executions must run before they can be duplicated with --speculation-slowdown-factor.",
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.BackfillMaxTimeout, "backfill-max-timeout", OS.BackfillMaxTimeout,
+		"Start queued jobs with a single execution and at most this timeout in the capacity left over while jobs "+
+			"queued before them wait for enough capacity, such as gangs. Jobs are never backfilled if it is 0.",
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.BackfillMaxDelay, "backfill-max-delay", OS.BackfillMaxDelay,
+		"How long the job at the front of the queue can wait for capacity before jobs stop being backfilled ahead of it, "+
+			"so that it gets the capacity that frees up. There is no limit if it is 0.",
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.LatencyMaxTimeout, "latency-max-timeout", OS.LatencyMaxTimeout,
+		"Prefer the compute nodes with the shortest round trip time from the requester for jobs with at most this timeout. "+
+			"Jobs annotated with "+requester.LatencySensitiveAnnotation+" prefer them whatever their timeout.",
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.LatencyMaxLatency, "latency-max-latency", OS.LatencyMaxLatency,
+		"The round trip time to a compute node at which latency sensitive jobs stop preferring it. "+
+			"Nodes are never preferred for being near if it is 0.",
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.ReputationHalfLife, "requester-reputation-half-life", OS.ReputationHalfLife,
+		"How long it takes for the outcomes of executions to count half as much in the reputations of their compute nodes, "+
+			"which the requester prefers reliable nodes by. Flaky nodes recover their reputation over time.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.ExternalRankerURL, "requester-external-ranker-url", OS.ExternalRankerURL,
+		"URL that each job and its candidate compute nodes are posted to as JSON, for an external service to respond with "+
+			`{"Ranks": {"<node id>": <rank>}} that add to the ranks the requester gives the nodes. Ranks below 0 exclude nodes.`,
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.ExternalRankerTimeout, "requester-external-ranker-timeout", OS.ExternalRankerTimeout,
+		"How long the external ranker is waited for before the requester ranks the nodes without it.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.NodeCatalogPath, "requester-node-catalog", OS.NodeCatalogPath,
+		"YAML or JSON file that lists the compute nodes to place jobs on, instead of discovering them from the network, "+
+			`as {"Nodes": [{"Addresses": ["/ip4/10.0.0.1/tcp/1235/p2p/<node id>"], "Engines": ["docker"], "Labels": {}}]}. `+
+			"The file is reloaded when it changes.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.DNSDiscoveryName, "requester-dns-discovery-name", OS.DNSDiscoveryName,
+		"DNS name that more compute nodes to place jobs on are resolved from. Its TXT records that start with dnsaddr= "+
+			"hold the multiaddresses of nodes, and the targets of the SRV records of _bacalhau._tcp.<name> are nodes "+
+			"whose peer ids are in the TXT records of each target that start with peer=.",
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.DNSDiscoveryRefreshInterval, "requester-dns-discovery-refresh-interval", OS.DNSDiscoveryRefreshInterval,
+		"How long the compute nodes resolved from DNS are kept before the name is resolved again.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.TEETrustRoots, "requester-tee-trust-roots", OS.TEETrustRoots,
+		"PEM file of the root certificates of the hardware vendors, such as AMD's ARKs and Intel's SGX root CA, "+
+			"that the attestation evidence of compute nodes must chain to. Bids on jobs that require attested "+
+			"execution are rejected if it isn't set.",
+	)
 	serveCmd.PersistentFlags().StringSliceVar(
 		&OS.IPFSSwarmAddresses, "ipfs-swarm-addr", OS.IPFSSwarmAddresses,
 		"IPFS multiaddress to connect the in-process IPFS node to - cannot be used with --ipfs-connect.",
@@ -302,6 +1195,325 @@ func newServeCmd() *cobra.Command {
 			"cannot be used with --ipfs-connect.",
 	)
 
+	serveCmd.PersistentFlags().BoolVar(
+		&OS.DockerUserNamespace, "docker-user-namespace", OS.DockerUserNamespace,
+		"Run docker jobs in a user namespace so that root in a job container is not root on the host - "+
+			"requires a rootless docker daemon or one with user namespace remapping enabled.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.DockerSeccompProfile, "docker-seccomp-profile", OS.DockerSeccompProfile,
+		"Path to a seccomp profile to run docker jobs with, instead of docker's default profile.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.DockerAppArmorProfile, "docker-apparmor-profile", OS.DockerAppArmorProfile,
+		"Name of an AppArmor profile loaded on the host to run docker jobs with, instead of docker's default profile.",
+	)
+	serveCmd.PersistentFlags().BoolVar(
+		&OS.DockerAllowSecurityProfileOverride, "docker-allow-security-profile-override", OS.DockerAllowSecurityProfileOverride,
+		"Accept docker jobs that choose their own seccomp or AppArmor profile - use a job selection probe to vet them.",
+	)
+	serveCmd.PersistentFlags().StringSliceVar(
+		&OS.DockerIODevices, "docker-io-device", OS.DockerIODevices,
+		"Block device (e.g. /dev/sda) to enforce the IOPS limits of docker jobs on. "+
+			"The node doesn't bid on docker jobs that request IOPS limits if none are set.",
+	)
+	serveCmd.PersistentFlags().BoolVar(
+		&OS.DockerRequireDiskLimits, "docker-require-disk-limits", OS.DockerRequireDiskLimits,
+		"Don't bid on docker jobs that request disk if docker's storage driver can't limit the size of their containers, "+
+			"e.g. overlay2 on ext4. Those jobs run without the limit otherwise.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.DockerRuntime, "docker-runtime", OS.DockerRuntime,
+		"OCI runtime registered with the docker daemon to run docker jobs with, e.g. runsc to sandbox them in gVisor. "+
+			"The node advertises it with the "+docker_executor.SandboxedRuntimeLabel+" label, so jobs can select it.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.DockerScratchPath, "docker-scratch-path", OS.DockerScratchPath,
+		"Where to mount the writable scratch volume of docker jobs in their containers, which is limited to the disk "+
+			"the job requested and counts towards its memory. Defaults to "+docker_executor.DefaultScratchPath+".",
+	)
+	serveCmd.PersistentFlags().Var(
+		DataSizeFlag(&OS.DockerScratchSize), "docker-scratch-size",
+		"The size of the scratch volume of docker jobs that don't request disk (e.g. 1GB). Defaults to half the memory "+
+			"of the host if 0.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.ContainerdAddress, "containerd-address", OS.ContainerdAddress,
+		"Run docker jobs directly against the containerd listening on this socket, instead of the docker daemon "+
+			"(e.g. "+containerd.DefaultAddress+", or /run/k3s/containerd/containerd.sock on k3s nodes).",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.ContainerdNamespace, "containerd-namespace", OS.ContainerdNamespace,
+		"The containerd namespace to run docker jobs in, when using --containerd-address.",
+	)
+	serveCmd.PersistentFlags().BoolVar(
+		&OS.KubernetesExecutor, "kubernetes-executor", OS.KubernetesExecutor,
+		"Run docker jobs as pods in a Kubernetes cluster, instead of on this node.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.KubernetesKubeconfig, "kubernetes-kubeconfig", OS.KubernetesKubeconfig,
+		"The kubeconfig to connect to the Kubernetes cluster with. The in-cluster configuration is used if not set.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.KubernetesNamespace, "kubernetes-namespace", OS.KubernetesNamespace,
+		"The Kubernetes namespace to run docker jobs in, when using --kubernetes-executor.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.KubernetesHelperImage, "kubernetes-helper-image", OS.KubernetesHelperImage,
+		"The image, containing sh and tar, used to copy the inputs and outputs of jobs in and out of their pods.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.ApptainerBinary, "apptainer-binary", OS.ApptainerBinary,
+		"The apptainer command to run apptainer jobs with. Use singularity on hosts that haven't moved to apptainer.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.ApptainerImageCacheDir, "apptainer-image-cache-dir", OS.ApptainerImageCacheDir,
+		"Where to keep the images of apptainer jobs once converted to SIF files. Defaults to a directory in BACALHAU_STORAGE_PATH.",
+	)
+	serveCmd.PersistentFlags().BoolVar(
+		&OS.ApptainerResourceLimits, "apptainer-resource-limits", OS.ApptainerResourceLimits,
+		"Enforce the CPU and memory limits of apptainer jobs, which needs apptainer to be able to use cgroups.",
+	)
+	serveCmd.PersistentFlags().BoolVar(
+		&OS.ProcessExecutor, "process-executor", OS.ProcessExecutor,
+		"Run process jobs directly on the host, without a container. Only enable this if every client submitting jobs is trusted.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.ProcessCgroupParent, "process-cgroup-parent", OS.ProcessCgroupParent,
+		"A cgroup v2 directory delegated to bacalhau (e.g. /sys/fs/cgroup/bacalhau.slice) to enforce the CPU and memory limits "+
+			"of process jobs in.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.PythonInterpreter, "python-interpreter", OS.PythonInterpreter,
+		"The python to create the virtualenvs of python jobs with. Python jobs run as processes, so need --process-executor.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.PythonVirtualenvCacheDir, "python-virtualenv-cache-dir", OS.PythonVirtualenvCacheDir,
+		"Where to keep the virtualenvs of python jobs, one for each set of requirements. "+
+			"Defaults to a directory in BACALHAU_STORAGE_PATH.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.ExecutorPluginsDir, "executor-plugins-dir", OS.ExecutorPluginsDir,
+		"Directory of executor plugins to run plugin jobs with. Each executable in it is a plugin, named after the file.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.SSHHost, "ssh-host", OS.SSHHost,
+		"Run process jobs on this remote host (host or host:port) over SSH instead of on the compute node, "+
+			"e.g. to attach a GPU machine that can't run docker.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.SSHUser, "ssh-user", OS.SSHUser,
+		"The user to log in to the SSH host as.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.SSHIdentityFile, "ssh-identity-file", OS.SSHIdentityFile,
+		"The private key to log in to the SSH host with. The keys of the running ssh-agent are used if not set.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.SSHKnownHostsFile, "ssh-known-hosts-file", OS.SSHKnownHostsFile,
+		"A known hosts file with the key of the SSH host. Defaults to ~/.ssh/known_hosts.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.SSHWorkDir, "ssh-work-dir", OS.SSHWorkDir,
+		"The directory on the SSH host to create job directories in.",
+	)
+	serveCmd.PersistentFlags().BoolVar(
+		&OS.DuckDBExecutor, "duckdb-executor", OS.DuckDBExecutor,
+		"Run the SQL queries of duckdb jobs over their inputs. Queries can only access the files of their inputs and outputs, "+
+			"but run on the host without other isolation, so only enable this if the clients submitting jobs are trusted.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.DuckDBBinary, "duckdb-binary", OS.DuckDBBinary,
+		"The duckdb command line shell to run the queries of duckdb jobs with. Requires DuckDB 1.2 or later.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.S3Region, "s3-region", OS.S3Region,
+		"The region of the S3 buckets to download inputs from. Defaults to the region of the environment or AWS config.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.S3Endpoint, "s3-endpoint", OS.S3Endpoint,
+		"The endpoint of an S3 compatible store, such as MinIO, to download S3 inputs from instead of AWS.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.S3Profile, "s3-profile", OS.S3Profile,
+		"The profile of the AWS config and credentials files to download S3 inputs as. "+
+			"Credentials are otherwise found as by the AWS CLI.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.S3DownloadConcurrency, "s3-download-concurrency", OS.S3DownloadConcurrency,
+		"How many objects of an S3 input to download at once.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.URLDownloadRetries, "url-download-retries", OS.URLDownloadRetries,
+		"How many times to retry downloading a URL input, waiting twice as long after each failure. Use -1 to not retry.",
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.URLDownloadRetryWaitMax, "url-download-retry-wait-max", OS.URLDownloadRetryWaitMax,
+		"The longest to wait between retries of a URL input, unless the server asks for longer with Retry-After.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.URLDownloadMaxRedirects, "url-download-max-redirects", OS.URLDownloadMaxRedirects,
+		"How many redirects to follow when downloading a URL input, or -1 for none. "+
+			"Redirects from https to http are never followed.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.URLDownloadCacheDir, "url-download-cache-dir", OS.URLDownloadCacheDir,
+		"Where to keep URL inputs served with an ETag, to only download them again if they change. "+
+			"Defaults to a directory in BACALHAU_STORAGE_PATH.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.AzureManagedIdentityClientID, "azure-managed-identity-client-id", OS.AzureManagedIdentityClientID,
+		"The client ID of a user assigned managed identity to download Azure blobs without a SAS token as. "+
+			"Credentials are otherwise found as by the Azure SDKs.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.AzureBlobDownloadConcurrency, "azure-blob-download-concurrency", OS.AzureBlobDownloadConcurrency,
+		"How many blobs of an Azure input to download at once.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.GCSCredentialsFile, "gcs-credentials-file", OS.GCSCredentialsFile,
+		"The JSON key of a service account, or workload identity federation config, to download GCS objects as. "+
+			"Application default credentials are otherwise used.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.GCSDownloadConcurrency, "gcs-download-concurrency", OS.GCSDownloadConcurrency,
+		"How many objects of a GCS input to download at once.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.HuggingFaceEndpoint, "huggingface-endpoint", OS.HuggingFaceEndpoint,
+		"The Hugging Face Hub, or a mirror of it, to download huggingface:// inputs from.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.HuggingFaceToken, "huggingface-token", OS.HuggingFaceToken,
+		"The token to download private and gated Hugging Face models and datasets with. Defaults to $HF_TOKEN.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.HuggingFaceCacheDir, "huggingface-cache-dir", OS.HuggingFaceCacheDir,
+		"Where to keep Hugging Face models and datasets by commit, to only download each once. "+
+			"Defaults to a directory in BACALHAU_STORAGE_PATH.",
+	)
+	serveCmd.PersistentFlags().Var(
+		NewAllowedLocalPathArrayFlag(&OS.AllowListedLocalPaths), "allow-listed-local-paths",
+		"A directory of the node that jobs may mount as file:// inputs, along with everything beneath it. "+
+			"Jobs may only write to it if it ends with :rw. Can be given more than once.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.BitTorrentListenPort, "bittorrent-listen-port", OS.BitTorrentListenPort,
+		"The port to accept connections from BitTorrent peers on, or 0 for any free port.",
+	)
+	serveCmd.PersistentFlags().BoolVar(
+		&OS.BitTorrentNoUpload, "bittorrent-no-upload", OS.BitTorrentNoUpload,
+		"Stop sharing the pieces of torrent inputs with other peers while jobs use them.",
+	)
+	serveCmd.PersistentFlags().BoolVar(
+		&OS.BitTorrentNoDHT, "bittorrent-no-dht", OS.BitTorrentNoDHT,
+		"Only find BitTorrent peers through trackers, rather than through the DHT too.",
+	)
+	serveCmd.PersistentFlags().Var(
+		DataSizeFlag(&OS.StorageCacheSize), "storage-cache-size",
+		"How much disk to keep downloaded IPFS and S3 inputs in, so that later jobs using the same data don't download it "+
+			"again (e.g. 20GB). Nothing is kept if 0.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.StorageCacheDir, "storage-cache-dir", OS.StorageCacheDir,
+		"Where to keep downloaded inputs for later jobs, which should be on the same filesystem as the storage path.",
+	)
+	serveCmd.PersistentFlags().Var(
+		DataSizeFlag(&OS.IPFSLazyMountMinSize), "ipfs-lazy-mount-min-size",
+		"The size from which IPFS inputs are mounted with FUSE, and only fetched as jobs read them, rather than fetched "+
+			"before jobs start (e.g. 10GB). Needs FUSE on the node. Nothing is mounted if 0.",
+	)
+	serveCmd.PersistentFlags().Var(
+		DataSizeFlag(&OS.PrefetchBudget), "prefetch-on-bid-budget",
+		"How much input data to start fetching as soon as the node bids on jobs, so that the jobs it wins start sooner "+
+			"(e.g. 5GB). Inputs of bids that are rejected are cleaned up. Nothing is prefetched if 0.",
+	)
+	serveCmd.PersistentFlags().Var(
+		DataSizeFlag(&OS.ExtractMaxSize), "input-extract-max-size",
+		"The most that the archive of an input that jobs ask to extract may extract to.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.ExtractMaxFiles, "input-extract-max-files", OS.ExtractMaxFiles,
+		"The most files and directories that the archive of an input that jobs ask to extract may have.",
+	)
+	serveCmd.PersistentFlags().StringSliceVar(
+		&OS.InputSources, "input-sources", OS.InputSources,
+		"The only storage sources that the inputs of jobs can be from (e.g. ipfs,s3). Any source is allowed if empty.",
+	)
+	serveCmd.PersistentFlags().StringSliceVar(
+		&OS.InputCIDs, "input-cids", OS.InputCIDs,
+		"The only CIDs that the inputs of jobs can be, for inputs with a CID. Any CID is allowed if empty.",
+	)
+	serveCmd.PersistentFlags().StringSliceVar(
+		&OS.InputURLPrefixes, "input-url-prefixes", OS.InputURLPrefixes,
+		"What the URLs of the inputs of jobs must start with, for inputs with a URL (e.g. https://data.example.com/). "+
+			"Any URL is allowed if empty.",
+	)
+	serveCmd.PersistentFlags().Var(
+		DataSizeFlag(&OS.LimitInputSize), "limit-input-size",
+		"The most that the inputs of a job can add up to for the node to take it on, checked before they are "+
+			"downloaded (e.g. 50GB). There is no limit if 0.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.PublishAttempts, "publish-attempts", OS.PublishAttempts,
+		"How many times to try publishing the results of a job with each publisher, backing off exponentially, "+
+			"before publishing is left pending and tried again later.",
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.PublishPendingTimeout, "publish-pending-timeout", OS.PublishPendingTimeout,
+		"How long to keep trying to publish the results of a job before its execution fails, "+
+			"so that a publisher that is down for a while doesn't lose the results.",
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.CheckpointInterval, "checkpoint-interval", OS.CheckpointInterval,
+		"How often to checkpoint running executions, for them to resume from their last checkpoint rather than "+
+			"start over when they run again after the node restarts. Only docker executions of jobs with a single "+
+			"execution are checkpointed, with CRIU, which needs the docker daemon's experimental features. "+
+			"Nothing is checkpointed if 0.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.CheckpointDir, "checkpoint-dir", OS.CheckpointDir,
+		"Where to keep the checkpoints of executions, which other nodes that share the directory resume "+
+			"executions from too. Defaults to checkpoints in the bacalhau directory.",
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.ResultCacheTTL, "result-cache-ttl", OS.ResultCacheTTL,
+		"How long to reuse the published results of a job for identical jobs that are submitted again, rather "+
+			"than running them again. Only jobs that aren't verified by replication and whose inputs are addressed "+
+			"by their content are reused. Results are never reused if 0.",
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.ResultCacheMaxEntries, "result-cache-max-entries", OS.ResultCacheMaxEntries,
+		"The most published results to keep for identical jobs, dropping the oldest first.",
+	)
+	serveCmd.PersistentFlags().BoolVar(
+		&OS.ResultCacheNetworked, "result-cache-networked", OS.ResultCacheNetworked,
+		"Reuse the results of jobs with network access too, which may depend on what the jobs fetch.",
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.ScratchGCInterval, "scratch-gc-interval", OS.ScratchGCInterval,
+		"How often to remove the results and temporary files that executions left behind, such as when the node "+
+			"crashed or the process of an execution was killed. Nothing is removed if negative.",
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.ScratchGCMinAge, "scratch-gc-min-age", OS.ScratchGCMinAge,
+		"How long the scratch space that executions left behind must have gone unmodified before it is removed.",
+	)
+	serveCmd.PersistentFlags().Float64Var(
+		&OS.ScratchGCDiskPressure, "scratch-gc-disk-pressure", OS.ScratchGCDiskPressure,
+		"The fraction of the disk of the scratch space that, once used, has the scratch space that executions "+
+			"left behind removed however recently it was modified.",
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.JobStorePath, "job-store-path", OS.JobStorePath,
+		"The file to keep the jobs of the requester node in, so that in-flight and past jobs survive it restarting. "+
+			"Defaults to jobs.db in the bacalhau directory.",
+	)
+	serveCmd.PersistentFlags().BoolVar(
+		&OS.JobStoreInMemory, "job-store-in-memory", OS.JobStoreInMemory,
+		"Keep the jobs of the requester node in memory rather than in a file, losing them when it restarts.",
+	)
+
 	setupLibp2pCLIFlags(serveCmd, OS)
 	setupJobSelectionCLIFlags(serveCmd, OS)
 	setupCapacityManagerCLIFlags(serveCmd, OS)
@@ -329,6 +1541,83 @@ func serve(cmd *cobra.Command, OS *ServeOptions) error {
 		return fmt.Errorf("--job-selection-data-locality must be either 'local' or 'anywhere'")
 	}
 
+	if _, err := requester.ParsePlacementStrategy(OS.PlacementStrategy); err != nil {
+		return fmt.Errorf("--placement-strategy: %w", err)
+	}
+
+	if _, err := parseNetworks(OS.WebhookAllowedNetworks); err != nil {
+		return fmt.Errorf("--webhook-allowed-networks: %w", err)
+	}
+
+	for name, count := range OS.EngineConcurrency {
+		if _, err := model.ParseEngine(name); err != nil {
+			return fmt.Errorf("--limit-engine-concurrency: %w", err)
+		}
+		if count < 1 {
+			return fmt.Errorf("--limit-engine-concurrency: %s must be at least 1", name)
+		}
+	}
+
+	if OS.LimitExecutions < 0 || OS.LimitLoadAverage < 0 {
+		return fmt.Errorf("--limit-executions and --limit-load-average can't be negative")
+	}
+	if OS.LimitDiskUsage < 0 || OS.LimitDiskUsage > 1 {
+		return fmt.Errorf("--limit-disk-usage must be between 0 and 1")
+	}
+
+	if OS.BidStrategyChain != "" {
+		if len(OS.JobSelectionProbeWasm) > 0 {
+			return fmt.Errorf("--bid-strategy-chain lists its own wasm strategies, so it can't be used with --job-selection-probe-wasm")
+		}
+		if _, err := bidstrategy.LoadChainConfig(OS.BidStrategyChain); err != nil {
+			return fmt.Errorf("--bid-strategy-chain: %w", err)
+		}
+	}
+
+	if OS.BidProbability <= 0 || OS.BidProbability > 1 {
+		return fmt.Errorf("--bid-probability must be more than 0 and at most 1")
+	}
+
+	if OS.ClientReputationHalfLife <= 0 {
+		return fmt.Errorf("--client-reputation-half-life must be positive")
+	}
+	if OS.ClientAbuseLimit < 0 || OS.ClientAbuseDelay < 0 || OS.ClientAbuseMaxDelay < 0 {
+		return fmt.Errorf("--client-abuse-limit, --client-abuse-delay and --client-abuse-max-delay can't be negative")
+	}
+
+	if OS.TEE == model.TEEAny {
+		return fmt.Errorf("--tee must be the kind of TEE that the node runs in: sev-snp or tdx")
+	}
+
+	for _, window := range OS.BiddingWindows {
+		if _, err := compute.ParseTimeWindow(window); err != nil {
+			return fmt.Errorf("--bidding-window: %w", err)
+		}
+	}
+	if OS.BiddingTimezone != "" {
+		if _, err := time.LoadLocation(OS.BiddingTimezone); err != nil {
+			return fmt.Errorf("--bidding-timezone: %w", err)
+		}
+	}
+
+	for _, name := range OS.InputSources {
+		if _, err := model.ParseStorageSourceType(name); err != nil {
+			return fmt.Errorf("--input-sources: %w", err)
+		}
+	}
+
+	if OS.ScratchGCDiskPressure <= 0 || OS.ScratchGCDiskPressure > 1 {
+		return fmt.Errorf("--scratch-gc-disk-pressure must be more than 0 and at most 1")
+	}
+
+	if OS.SpeculationSlowdownFactor != 0 && OS.SpeculationSlowdownFactor <= 1 {
+		return fmt.Errorf("--speculation-slowdown-factor must be either 0 or more than 1")
+	}
+
+	if err := OS.Pricing.Validate(); err != nil {
+		return fmt.Errorf("--price-*: %w", err)
+	}
+
 	if OS.IPFSConnect != "" && OS.PrivateInternalIPFS {
 		return fmt.Errorf("--private-internal-ipfs cannot be used with --ipfs-connect")
 	}
@@ -337,6 +1626,15 @@ func serve(cmd *cobra.Command, OS *ServeOptions) error {
 		return fmt.Errorf("--ipfs-swarm-addr cannot be used with --ipfs-connect")
 	}
 
+	pinningServices := make([]pinning.Service, 0, len(OS.IPFSPinningServices))
+	for _, value := range OS.IPFSPinningServices {
+		service, err := pinning.ParseService(value)
+		if err != nil {
+			return fmt.Errorf("--ipfs-pinning-service: %w", err)
+		}
+		pinningServices = append(pinningServices, service)
+	}
+
 	// Establishing p2p connection
 	peers, err := getPeers(OS)
 	if err != nil {
@@ -359,15 +1657,19 @@ func serve(cmd *cobra.Command, OS *ServeOptions) error {
 		return err
 	}
 
-	datastore := inmemory.NewJobStore()
+	datastore, err := getJobStore(OS, isRequesterNode, cm)
 	if err != nil {
-		return fmt.Errorf("error creating in memory datastore: %s", err)
+		return fmt.Errorf("error creating job store: %w", err)
 	}
 	AutoLabels := AutoOutputLabels()
 	combinedMap := make(map[string]string)
 	for key, value := range AutoLabels {
 		combinedMap[key] = value
 	}
+	// only the docker daemon runs jobs in the runtime, not containerd or kubernetes
+	if OS.DockerRuntime != "" && OS.ContainerdAddress == "" && !OS.KubernetesExecutor {
+		combinedMap[docker_executor.SandboxedRuntimeLabel] = OS.DockerRuntime
+	}
 
 	for key, value := range OS.Labels {
 		combinedMap[key] = value
@@ -380,6 +1682,7 @@ func serve(cmd *cobra.Command, OS *ServeOptions) error {
 		Host:                 libp2pHost,
 		FilecoinUnsealedPath: OS.FilecoinUnsealedPath,
 		EstuaryAPIKey:        OS.EstuaryAPIKey,
+		IPFSPinningServices:  pinningServices,
 		HostAddress:          OS.HostAddress,
 		APIPort:              apiPort,
 		ComputeConfig:        getComputeConfig(OS),
@@ -400,6 +1703,15 @@ func serve(cmd *cobra.Command, OS *ServeOptions) error {
 		}
 	}
 
+	if OS.LocalPublisherDirectory != "" {
+		nodeConfig.LocalPublisherConfig = &local.PublisherConfig{
+			Directory: OS.LocalPublisherDirectory,
+			Address:   OS.LocalPublisherAddress,
+			URL:       OS.LocalPublisherURL,
+			Token:     OS.LocalPublisherToken,
+		}
+	}
+
 	// Create node
 	standardNode, err := node.NewStandardNode(ctx, nodeConfig)
 	if err != nil {
@@ -453,7 +1765,16 @@ func serve(cmd *cobra.Command, OS *ServeOptions) error {
 		}
 	}
 
-	<-ctx.Done() // block until killed
+	// block until killed, or until the compute node has drained
+	var drained <-chan struct{}
+	if standardNode.ComputeNode != nil {
+		drained = standardNode.ComputeNode.Drained()
+	}
+	select {
+	case <-ctx.Done():
+	case <-drained:
+		cmd.Println("The compute node has drained, shutting down")
+	}
 	return nil
 }
 
@@ -0,0 +1,251 @@
+package bacalhau
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	templateLong = templates.LongDesc(i18n.T(`
+		Manage the job templates that the requester node hosts, and submit jobs from them.
+
+		A template is a job spec with a name, such as the image, resources and publisher
+		that a team's jobs share. The strings in the spec can refer to the parameters of
+		the template, like {{.input}}, which are given values when a job is submitted from
+		it. Jobs can also override any field of the spec of the template.
+
+		Only the client that created a template can change or delete it.
+`))
+
+	//nolint:lll // Documentation
+	templateExample = templates.Examples(i18n.T(`
+		# Host the job in job.yaml as a template whose spec refers to {{.input}} and {{.epochs}}
+		bacalhau template set train ./job.yaml --param input --param epochs=10
+
+		# Submit a job from the template
+		bacalhau template run train -p input=ipfs://QmY5... -p epochs=20
+
+		# Submit a job from the template, overriding its spec with the fields set in overrides.yaml
+		bacalhau template run train ./overrides.yaml -p input=ipfs://QmY5...
+
+		# List the templates
+		bacalhau template list`))
+)
+
+type TemplateOptions struct {
+	Description  string            // What the jobs submitted from the template do
+	Parameters   []string          // The parameters of the template, with their defaults
+	Values       map[string]string // The values of the parameters of the template to submit a job with
+	HideHeader   bool              // Hide the column headers
+	NoStyle      bool              // Remove all styling from table output.
+	OutputFormat string            // The output format (json or text)
+}
+
+func NewTemplateOptions() *TemplateOptions {
+	return &TemplateOptions{
+		Values:       map[string]string{},
+		OutputFormat: "text",
+	}
+}
+
+func newTemplateCmd() *cobra.Command {
+	options := NewTemplateOptions()
+
+	templateCmd := &cobra.Command{
+		Use:     "template",
+		Short:   "Manage job templates and submit jobs from them",
+		Long:    templateLong,
+		Example: templateExample,
+	}
+
+	setCmd := &cobra.Command{
+		Use:    "set [name] [file]",
+		Short:  "Host the job in a json or yaml file as a template, replacing any template of the same name",
+		Args:   cobra.RangeArgs(1, 2),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return setTemplate(cmd, cmdArgs, options)
+		},
+	}
+	setCmd.Flags().StringVar(&options.Description, "description", options.Description,
+		`What the jobs submitted from the template do.`)
+	setCmd.Flags().StringArrayVar(&options.Parameters, "param", options.Parameters,
+		`A parameter that the spec refers to, as name=default, or as name if jobs must give it a value. `+
+			`Can be repeated.`)
+
+	runCmd := &cobra.Command{
+		Use:    "run [name] [overrides-file]",
+		Short:  "Submit a job from a template, overriding its spec with the fields set in a json or yaml file",
+		Args:   cobra.RangeArgs(1, 2),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return runTemplate(cmd, cmdArgs, options)
+		},
+	}
+	runCmd.Flags().StringToStringVarP(&options.Values, "param", "p", options.Values,
+		`The value of a parameter of the template, as name=value. Can be repeated.`)
+
+	getCmd := &cobra.Command{
+		Use:    "get [name]",
+		Short:  "Show a template",
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			template, err := GetAPIClient().GetTemplate(cmd.Context(), cmdArgs[0])
+			if err != nil {
+				Fatal(cmd, fmt.Sprintf("Error getting template %s: %s", cmdArgs[0], err), 1)
+				return err
+			}
+			msgBytes, err := model.JSONMarshalIndentWithMax(template, 2)
+			if err != nil {
+				Fatal(cmd, fmt.Sprintf("Error marshaling template to JSON: %s", err), 1)
+				return err
+			}
+			cmd.Printf("%s\n", msgBytes)
+			return nil
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:    "list",
+		Short:  "List the templates",
+		Args:   cobra.NoArgs,
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return listTemplates(cmd, options)
+		},
+	}
+	listCmd.Flags().BoolVar(&options.HideHeader, "hide-header", options.HideHeader, `do not print the column headers.`)
+	listCmd.Flags().BoolVar(&options.NoStyle, "no-style", options.NoStyle, `remove all styling from table output.`)
+	listCmd.Flags().StringVar(&options.OutputFormat, "output", options.OutputFormat,
+		`The output format for the list of templates (json or text)`)
+
+	deleteCmd := &cobra.Command{
+		Use:    "delete [name]",
+		Short:  "Delete a template, leaving the jobs that were submitted from it",
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			if err := GetAPIClient().DeleteTemplate(cmd.Context(), cmdArgs[0]); err != nil {
+				Fatal(cmd, fmt.Sprintf("Error deleting template %s: %s", cmdArgs[0], err), 1)
+				return err
+			}
+			cmd.Printf("Deleted template %s\n", cmdArgs[0])
+			return nil
+		},
+	}
+
+	templateCmd.AddCommand(setCmd, runCmd, getCmd, listCmd, deleteCmd)
+	return templateCmd
+}
+
+func setTemplate(cmd *cobra.Command, cmdArgs []string, options *TemplateOptions) error {
+	data, err := readFileOrStdin(cmd, cmdArgs[1:])
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error reading job: %s", err), 1)
+		return err
+	}
+	j, err := model.NewJobWithSaneProductionDefaults()
+	if err != nil {
+		return err
+	}
+	// the yaml parser reads json as well
+	if err = model.YAMLUnmarshalWithMax(data, &j); err != nil || j == nil {
+		Fatal(cmd, fmt.Sprintf("Error parsing job: %s", err), 1)
+		return err
+	}
+
+	template := model.JobTemplate{
+		Name:        cmdArgs[0],
+		Description: options.Description,
+		APIVersion:  j.APIVersion,
+		Spec:        j.Spec,
+	}
+	for _, param := range options.Parameters {
+		name, defaultValue, _ := strings.Cut(param, "=")
+		template.Parameters = append(template.Parameters, model.TemplateParameter{Name: name, Default: defaultValue})
+	}
+
+	template, err = GetAPIClient().SetTemplate(cmd.Context(), template)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error setting template: %s", err), 1)
+		return err
+	}
+	cmd.Printf("Template: %s\n", template.Name)
+	return nil
+}
+
+func runTemplate(cmd *cobra.Command, cmdArgs []string, options *TemplateOptions) error {
+	var overrides *model.Job
+	if len(cmdArgs) > 1 {
+		data, err := os.ReadFile(cmdArgs[1])
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error reading overrides: %s", err), 1)
+			return err
+		}
+		if err = model.YAMLUnmarshalWithMax(data, &overrides); err != nil || overrides == nil {
+			Fatal(cmd, fmt.Sprintf("Error parsing overrides: %s", err), 1)
+			return err
+		}
+	}
+
+	j, err := GetAPIClient().SubmitFromTemplate(cmd.Context(), cmdArgs[0], options.Values, overrides)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error submitting job from template %s: %s", cmdArgs[0], err), 1)
+		return err
+	}
+	cmd.Printf("Job ID: %s\n", j.Metadata.ID)
+	return nil
+}
+
+func listTemplates(cmd *cobra.Command, options *TemplateOptions) error {
+	list, err := GetAPIClient().ListTemplates(cmd.Context())
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error listing templates: %s", err), 1)
+		return err
+	}
+
+	if options.OutputFormat == JSONFormat {
+		var msgBytes []byte
+		msgBytes, err = model.JSONMarshalWithMax(list)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error marshaling templates to JSON: %s", err), 1)
+			return err
+		}
+		cmd.Printf("%s\n", msgBytes)
+		return nil
+	}
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(cmd.OutOrStdout())
+	if !options.HideHeader {
+		tw.AppendHeader(table.Row{"name", "parameters", "updated", "description"})
+	}
+	for _, template := range list {
+		var params []string
+		for _, param := range template.Parameters {
+			params = append(params, param.Name)
+		}
+		tw.AppendRow(table.Row{
+			template.Name,
+			strings.Join(params, ", "),
+			template.UpdatedAt.Format("2006-01-02 15:04:05 MST"),
+			template.Description,
+		})
+	}
+	if options.NoStyle {
+		tw.SetStyle(table.StyleDefault)
+		tw.Style().Options = table.OptionsNoBordersAndSeparators
+	} else {
+		tw.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	}
+	tw.Render()
+	return nil
+}
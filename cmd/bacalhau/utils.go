@@ -168,6 +168,12 @@ func NewIPFSDownloadFlags(settings *model.DownloaderSettings) *pflag.FlagSet {
 		settings.OutputDir, "Directory to write the output to.")
 	flags.StringVar(&settings.IPFSSwarmAddrs, "ipfs-swarm-addrs",
 		settings.IPFSSwarmAddrs, "Comma-separated list of IPFS nodes to connect to.")
+	flags.BoolVar(&settings.CAR, "car",
+		settings.CAR, fmt.Sprintf("Write the results as a single %s file instead of as files.", model.DownloadCARFilename))
+	flags.StringVar(&settings.IdentityFile, "identity",
+		settings.IdentityFile, "age identity file to decrypt encrypted results with. Defaults to BACALHAU_IDENTITY_FILE.")
+	flags.BoolVar(&settings.RequireSignedResults, "require-signed-results",
+		settings.RequireSignedResults, "Refuse results that aren't signed by the compute nodes that published them.")
 	return flags
 }
 
@@ -418,6 +424,11 @@ func downloadResultsHandler(
 		return err
 	}
 
+	err = downloader.VerifyResultSignatures(ctx, j.Job.Metadata.ID, results, &processedDownloadSettings)
+	if err != nil {
+		return err
+	}
+
 	downloaderProvider := util.NewStandardDownloaders(cm, &processedDownloadSettings)
 	if err != nil {
 		return err
@@ -451,6 +462,15 @@ func submitJob(ctx context.Context,
 	return j, err
 }
 
+// readFileOrStdin reads the file named by the first argument, or stdin if
+// there are no arguments.
+func readFileOrStdin(cmd *cobra.Command, args []string) ([]byte, error) {
+	if len(args) == 0 {
+		return ReadFromStdinIfAvailable(cmd, args)
+	}
+	return os.ReadFile(args[0])
+}
+
 func ReadFromStdinIfAvailable(cmd *cobra.Command, args []string) ([]byte, error) {
 	if len(args) == 0 {
 		r := bufio.NewReader(cmd.InOrStdin())
@@ -9,6 +9,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
 	"github.com/invopop/jsonschema"
+	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 
 	"k8s.io/kubectl/pkg/util/i18n"
@@ -36,6 +37,9 @@ var (
 
 		# Output the jsonschema for a bacalhau job
 		bacalhau validate --output-schema
+
+		# Show which nodes the requester would place the job on and why, without running it
+		bacalhau validate --placement ./job.yaml
 `))
 )
 
@@ -44,6 +48,8 @@ type ValidateOptions struct {
 	OutputFormat    string // Output format (json or yaml)
 	OutputSchema    bool   // Output the schema to stdout
 	OutputDirectory string // Output directory for the job
+	Placement       bool   // Show which nodes the requester would place the job on
+	NoStyle         bool   // Remove all styling from table output
 }
 
 func NewValidateOptions() *ValidateOptions {
@@ -73,6 +79,14 @@ func newValidateCmd() *cobra.Command {
 		&OV.OutputSchema, "output-schema", OV.OutputSchema,
 		`Output the JSON schema for a Job to stdout then exit`,
 	)
+	validateCmd.PersistentFlags().BoolVar(
+		&OV.Placement, "placement", OV.Placement,
+		`Also show which nodes the requester would place the job on and why, without running it`,
+	)
+	validateCmd.PersistentFlags().BoolVar(
+		&OV.NoStyle, "no-style", OV.NoStyle,
+		`remove all styling from the placement table.`,
+	)
 
 	return validateCmd
 }
@@ -159,6 +173,46 @@ func validate(cmd *cobra.Command, cmdArgs []string, OV *ValidateOptions) error {
 		}
 		Fatal(cmd, msg, 1)
 	}
+
+	if OV.Placement {
+		if OV.Filename == "" {
+			if err = model.YAMLUnmarshalWithMax(byteResult, &j); err != nil {
+				Fatal(cmd, fmt.Sprintf("Error unmarshaling yaml from stdin: %s", err), 1)
+			}
+		}
+		return printPlacement(cmd, j, OV)
+	}
+	return nil
+}
+
+// printPlacement asks the requester where it would place the job and prints
+// each node it found for the job, with the nodes it prefers first.
+func printPlacement(cmd *cobra.Command, j *model.Job, OV *ValidateOptions) error {
+	plan, err := GetAPIClient().SimulatePlacement(cmd.Context(), j)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error simulating the placement of the job: %s", err), 1)
+		return err
+	}
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(cmd.OutOrStdout())
+	tw.AppendHeader(table.Row{"node", "rank", "capacity", "selected", "reason"})
+	if OV.NoStyle {
+		tw.SetStyle(table.StyleDefault)
+		tw.Style().Options = table.OptionsNoBordersAndSeparators
+	} else {
+		tw.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	}
+	for _, node := range plan.Nodes {
+		tw.AppendRow(table.Row{shortID(false, node.NodeID), node.Rank, node.HasCapacity, node.Selected, node.Reason})
+	}
+	tw.Render()
+
+	if plan.CanStart {
+		cmd.Printf("The Job would start on %d of the selected nodes\n", plan.RequestedNodes)
+	} else {
+		cmd.Printf("The Job can't start now: %s\n", plan.Reason)
+	}
 	return nil
 }
 
@@ -189,6 +243,9 @@ func GenerateJobJSONSchema() ([]byte, error) {
 		{Name: "Publisher",
 			Path:  "$defs.Spec.properties.Publisher",
 			Enums: model.PublisherNames()},
+		{Name: "Publishers",
+			Path:  "$defs.Spec.properties.Publishers.items",
+			Enums: model.PublisherNames()},
 		{Name: "StorageSource",
 			Path:  "$defs.StorageSpec.properties.StorageSource",
 			Enums: model.StorageSourceNames()},
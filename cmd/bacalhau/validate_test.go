@@ -53,3 +53,17 @@ func (s *ValidateSuite) TestValidate() {
 
 	}
 }
+
+func (s *ValidateSuite) TestValidatePlacement() {
+	_, out, err := ExecuteTestCobraCommand("validate",
+		"--api-host", s.host,
+		"--api-port", s.port,
+		"--placement",
+		"--no-style",
+		"../../testdata/job-noop.yaml",
+	)
+	require.NoError(s.T(), err)
+	require.Contains(s.T(), out, "The Job is valid")
+	require.Contains(s.T(), out, "among the")
+	require.Contains(s.T(), out, "The Job would start on 1 of the selected nodes")
+}
@@ -84,6 +84,26 @@ func newRunWasmCmd() *cobra.Command {
 		`Selector (label query) to filter nodes on which this job can be executed, supports '=', '==', and '!='.(e.g. -s key1=value1,key2=value2). Matching objects must satisfy all of the specified label constraints.`, //nolint:lll // Documentation, ok if long.
 	)
 
+	runWasmCommand.PersistentFlags().Var(
+		NodeSelectorFlag(&wasmJob.Spec.Affinity.PreferredNodeSelectors), "prefer-selector",
+		`Selector (label query) of the nodes to prefer running the job on, without ruling out the others `+
+			`(e.g. --prefer-selector zone=eu).`,
+	)
+	runWasmCommand.PersistentFlags().Var(
+		TolerationArrayFlag(&wasmJob.Spec.Tolerations), "toleration",
+		`Taint of the nodes reserved for some jobs that this job may run on, as key=value:effect, or as key:effect `+
+			`for any value. The effect can be left out to tolerate any. Can be repeated.`,
+	)
+	runWasmCommand.PersistentFlags().StringSliceVar(
+		&wasmJob.Spec.Affinity.AntiAffinity, "anti-affinity", wasmJob.Spec.Affinity.AntiAffinity,
+		`Keep the job off nodes that are running or have run another of your jobs with this annotation, `+
+			`e.g. to spread replicas across nodes. Can be repeated.`,
+	)
+	runWasmCommand.PersistentFlags().BoolVar(
+		&wasmJob.Spec.Affinity.SoftAntiAffinity, "soft-anti-affinity", wasmJob.Spec.Affinity.SoftAntiAffinity,
+		`Prefer nodes that haven't run a job with an --anti-affinity annotation rather than ruling them out.`,
+	)
+
 	runWasmCommand.PersistentFlags().Var(
 		VerifierFlag(&wasmJob.Spec.Verifier), "verifier",
 		`What verification engine to use to run the job`,
@@ -92,6 +112,11 @@ func newRunWasmCmd() *cobra.Command {
 		PublisherFlag(&wasmJob.Spec.Publisher), "publisher",
 		`What publisher engine to use to publish the job results`,
 	)
+	runWasmCommand.PersistentFlags().Var(
+		PublisherArrayFlag(&wasmJob.Spec.Publishers), "additional-publisher",
+		`Another publisher engine to publish the job results with as well as --publisher. Can be repeated, `+
+			`and the job succeeds as long as one of its publishers does.`,
+	)
 	runWasmCommand.PersistentFlags().IntVarP(
 		&wasmJob.Spec.Deal.Concurrency, "concurrency", "c", wasmJob.Spec.Deal.Concurrency,
 		`How many nodes should run the job`,
@@ -104,10 +129,95 @@ func newRunWasmCmd() *cobra.Command {
 		&wasmJob.Spec.Deal.MinBids, "min-bids", wasmJob.Spec.Deal.MinBids,
 		`Minimum number of bids that must be received before concurrency-many bids will be accepted (at random)`,
 	)
+	runWasmCommand.PersistentFlags().BoolVar(
+		&wasmJob.Spec.Deal.Gang, "gang", wasmJob.Spec.Deal.Gang,
+		`Start all concurrency-many executions at once or none of them, with the nodes holding capacity for them until then.`,
+	)
+	runWasmCommand.PersistentFlags().Float64Var(
+		&wasmJob.Spec.Deal.GangTimeout, "gang-timeout", wasmJob.Spec.Deal.GangTimeout,
+		`Seconds to wait for enough nodes to start all the executions of a gang before the job fails (0 for the default)`,
+	)
+	runWasmCommand.PersistentFlags().Float64Var(
+		&wasmJob.Spec.Deal.MaxBudget, "max-budget", wasmJob.Spec.Deal.MaxBudget,
+		`Most that each execution may cost over the job timeout at the pricing of the node that runs it (0 for no limit)`,
+	)
+	runWasmCommand.PersistentFlags().Var(
+		PricingFlag(&wasmJob.Spec.Deal.Offer), "offer",
+		`Most that the job pays per second for each resource it uses, e.g. cpu=0.01,memory=0.001,gpu=1 with memory `+
+			`per GB. Nodes that charge more for any of them don't bid on the job.`,
+	)
+	runWasmCommand.PersistentFlags().Var(
+		TEEFlag(&wasmJob.Spec.TEE), "tee",
+		`Only run the job on nodes that attest that they run in a trusted execution environment: sev-snp, tdx or any. `+
+			`The evidence of each node is shown by bacalhau describe.`,
+	)
 	runWasmCommand.PersistentFlags().Float64Var(
 		&wasmJob.Spec.Timeout, "timeout", wasmJob.Spec.Timeout,
 		`Job execution timeout in seconds (e.g. 300 for 5 minutes and 0.1 for 100ms)`,
 	)
+	runWasmCommand.PersistentFlags().StringVar(
+		&wasmJob.Spec.Webhook, "webhook", wasmJob.Spec.Webhook,
+		`URL that the requester node POSTs the results of the job to when it completes, signed with its key.`,
+	)
+	runWasmCommand.PersistentFlags().Var(
+		DeadlineFlag(&wasmJob.Spec.MaxWallClock), "deadline",
+		`Stop the job and mark it as timed out if it hasn't completed by then, as how long after it is submitted (e.g. 2h) `+
+			`or a time (e.g. 2023-05-01T12:00:00Z). This includes the time it waits to be scheduled.`,
+	)
+	runWasmCommand.PersistentFlags().IntVar(
+		&wasmJob.Spec.Retry.MaxAttempts, "retry", wasmJob.Spec.Retry.MaxAttempts,
+		`How many times to attempt each execution of the job on different compute nodes if it fails, including the first.`,
+	)
+	runWasmCommand.PersistentFlags().Float64Var(
+		&wasmJob.Spec.Retry.Backoff, "retry-backoff", wasmJob.Spec.Retry.Backoff,
+		`Seconds to wait before retrying a failed execution, which doubles with each failure after that.`,
+	)
+	runWasmCommand.PersistentFlags().Var(
+		RetryReasonArrayFlag(&wasmJob.Spec.Retry.RetryOn), "retry-on",
+		`Which failures to retry: node-lost, error or exit-code for a non-zero exit code. Retries all of them by default.`,
+	)
+	runWasmCommand.PersistentFlags().Var(
+		PriorityFlag(&wasmJob.Spec.Priority), "priority",
+		`How urgently to schedule the job ahead of other jobs that are waiting for compute capacity: low, normal or high. `+
+			`Requester nodes may limit how many high priority jobs each client has in flight.`,
+	)
+	runWasmCommand.PersistentFlags().StringVar(
+		&wasmJob.Spec.IPNSName, "ipns-name", wasmJob.Spec.IPNSName,
+		`Publish the results with IPFS under an IPNS name as well, which successive runs with the same name on the same `+
+			`compute node update to point at their latest results. The IPNS name is shown by bacalhau describe.`,
+	)
+	runWasmCommand.PersistentFlags().BoolVar(
+		&wasmJob.Spec.NoCache, "no-cache", wasmJob.Spec.NoCache,
+		`Run the job even if the compute node recently published the results of an identical job, rather than `+
+			`reusing them.`,
+	)
+	runWasmCommand.PersistentFlags().StringSliceVar(
+		&wasmJob.Spec.Encryption.Recipients, "encrypt-to", wasmJob.Spec.Encryption.Recipients,
+		`age X25519 public key to encrypt the job results to before they are published, e.g. from age-keygen. `+
+			`Can be repeated. Decrypt the results with bacalhau get --identity.`,
+	)
+	runWasmCommand.PersistentFlags().StringVar(
+		&wasmJob.Spec.Compression.Algorithm, "compress", wasmJob.Spec.Compression.Algorithm,
+		`Compress the job results into a single tar before they are published, with gzip or zstd. `+
+			`bacalhau get decompresses them.`,
+	)
+	runWasmCommand.PersistentFlags().IntVar(
+		&wasmJob.Spec.Compression.Level, "compression-level", wasmJob.Spec.Compression.Level,
+		`Level to compress the job results at, from 1 to 9 for gzip and from 1 to 22 for zstd (default is the default of the algorithm).`,
+	)
+	runWasmCommand.PersistentFlags().IntVar(
+		&wasmJob.Spec.FilecoinDeal.Replication, "filecoin-replication", wasmJob.Spec.FilecoinDeal.Replication,
+		`Number of miners to make storage deals with when the results are published to Filecoin (default 1).`,
+	)
+	runWasmCommand.PersistentFlags().IntVar(
+		&wasmJob.Spec.FilecoinDeal.DurationDays, "filecoin-deal-duration", wasmJob.Spec.FilecoinDeal.DurationDays,
+		`Number of days that storage deals last for when the results are published to Filecoin `+
+			`(default is the storage duration of the compute node).`,
+	)
+	runWasmCommand.PersistentFlags().BoolVar(
+		&wasmJob.Spec.FilecoinDeal.VerifiedDeal, "filecoin-verified-deal", wasmJob.Spec.FilecoinDeal.VerifiedDeal,
+		`Make verified storage deals when the results are published to Filecoin, paid for with the DataCap of the compute node.`,
+	)
 	runWasmCommand.PersistentFlags().StringVar(
 		&wasmJob.Spec.Wasm.EntryPoint, "entry-point", wasmJob.Spec.Wasm.EntryPoint,
 		`The name of the WASM function in the entry module to call. This should be a zero-parameter zero-result function that
@@ -0,0 +1,331 @@
+package bacalhau
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	workflowLong = templates.LongDesc(i18n.T(`
+		Run a set of jobs, called stages, where some stages depend on others. The requester
+		node submits each stage once the stages it depends on have completed, mounting the
+		results they published as inputs. Stages that depend on a stage that failed are
+		skipped.
+
+		Workflows are described in a json or yaml file with the API version and the stages,
+		where each stage has a name, the spec of its job, the stages it depends on and the
+		stages whose outputs it reads:
+
+		  APIVersion: V1beta1
+		  Stages:
+		    - Name: extract
+		      Spec: ...
+		    - Name: transform
+		      Inputs:
+		        - Stage: extract
+		          Path: /inputs/extracted
+		      Spec: ...
+		    - Name: notify
+		      DependsOn: [transform]
+		      Spec: ...
+`))
+
+	//nolint:lll // Documentation
+	workflowExample = templates.Examples(i18n.T(`
+		# Submit the workflow in workflow.yaml and wait for it to finish
+		bacalhau workflow submit --wait ./workflow.yaml
+
+		# Show the state of each stage of a workflow
+		bacalhau workflow describe 5f1b2c3d
+
+		# List your workflows
+		bacalhau workflow list
+
+		# Cancel the running and pending stages of a workflow
+		bacalhau workflow cancel 5f1b2c3d`))
+)
+
+// workflowFile is a workflow as it's written in a file. The specs of the
+// stages are decoded over the defaults for jobs.
+type workflowFile struct {
+	APIVersion string `json:"APIVersion"`
+	Stages     []struct {
+		Name      string                `json:"Name"`
+		DependsOn []string              `json:"DependsOn"`
+		Inputs    []model.WorkflowInput `json:"Inputs"`
+		Spec      json.RawMessage       `json:"Spec"`
+	} `json:"Stages"`
+}
+
+type WorkflowOptions struct {
+	Wait         bool          // Wait for the workflow to finish after submitting it
+	WaitInterval time.Duration // How often to check on the workflow while waiting
+	HideHeader   bool          // Hide the column headers
+	NoStyle      bool          // Remove all styling from table output.
+	OutputFormat string        // The output format (json or text)
+	OutputWide   bool          // Print full values in the table results
+}
+
+func NewWorkflowOptions() *WorkflowOptions {
+	return &WorkflowOptions{
+		WaitInterval: 2 * time.Second,
+		OutputFormat: "text",
+	}
+}
+
+func newWorkflowCmd() *cobra.Command {
+	options := NewWorkflowOptions()
+
+	workflowCmd := &cobra.Command{
+		Use:     "workflow",
+		Short:   "Run jobs that depend on the outputs of other jobs",
+		Long:    workflowLong,
+		Example: workflowExample,
+	}
+
+	submitCmd := &cobra.Command{
+		Use:    "submit [file]",
+		Short:  "Submit the workflow in a json or yaml file",
+		Args:   cobra.MaximumNArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return submitWorkflow(cmd, cmdArgs, options)
+		},
+	}
+	submitCmd.Flags().BoolVar(&options.Wait, "wait", options.Wait,
+		`Wait for the workflow to finish, and exit with an error if it didn't complete.`)
+
+	describeCmd := &cobra.Command{
+		Use:    "describe [id]",
+		Short:  "Show the state of each stage of a workflow",
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			workflow, err := GetAPIClient().GetWorkflow(cmd.Context(), cmdArgs[0])
+			if err != nil {
+				Fatal(cmd, fmt.Sprintf("Error getting workflow %s: %s", cmdArgs[0], err), 1)
+				return err
+			}
+			return printWorkflow(cmd, workflow, options)
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:    "list",
+		Short:  "List your workflows",
+		Args:   cobra.NoArgs,
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return listWorkflows(cmd, options)
+		},
+	}
+
+	cancelCmd := &cobra.Command{
+		Use:    "cancel [id]",
+		Short:  "Cancel the running and pending stages of a workflow",
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			workflow, err := GetAPIClient().CancelWorkflow(cmd.Context(), cmdArgs[0], "")
+			if err != nil {
+				Fatal(cmd, fmt.Sprintf("Error cancelling workflow %s: %s", cmdArgs[0], err), 1)
+				return err
+			}
+			cmd.Printf("Workflow %s: %s\n", workflow.ID, workflow.State)
+			return nil
+		},
+	}
+
+	for _, c := range []*cobra.Command{describeCmd, listCmd} {
+		c.Flags().BoolVar(&options.HideHeader, "hide-header", options.HideHeader, `do not print the column headers.`)
+		c.Flags().BoolVar(&options.NoStyle, "no-style", options.NoStyle, `remove all styling from table output.`)
+		c.Flags().StringVar(&options.OutputFormat, "output", options.OutputFormat, `The output format (json or text)`)
+		c.Flags().BoolVar(&options.OutputWide, "wide", options.OutputWide, `Print full values in the table results`)
+	}
+
+	workflowCmd.AddCommand(submitCmd, describeCmd, listCmd, cancelCmd)
+	return workflowCmd
+}
+
+func submitWorkflow(cmd *cobra.Command, cmdArgs []string, options *WorkflowOptions) error {
+	ctx := cmd.Context()
+
+	data, err := readFileOrStdin(cmd, cmdArgs)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error reading workflow: %s", err), 1)
+		return err
+	}
+	stages, apiVersion, err := parseWorkflow(cmd, data)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error parsing workflow: %s", err), 1)
+		return err
+	}
+
+	workflow, err := GetAPIClient().SubmitWorkflow(ctx, apiVersion, stages)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error submitting workflow: %s", err), 1)
+		return err
+	}
+	cmd.Printf("Workflow ID: %s\n", workflow.ID)
+	if !options.Wait {
+		return nil
+	}
+
+	ticker := time.NewTicker(options.WaitInterval)
+	defer ticker.Stop()
+	for !workflow.State.IsTerminal() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		workflow, err = GetAPIClient().GetWorkflow(ctx, workflow.ID)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error getting workflow %s: %s", workflow.ID, err), 1)
+			return err
+		}
+	}
+	if err = printWorkflow(cmd, workflow, options); err != nil {
+		return err
+	}
+	if workflow.State != model.WorkflowStateCompleted {
+		Fatal(cmd, fmt.Sprintf("Workflow %s is %s", workflow.ID, workflow.State), 1)
+	}
+	return nil
+}
+
+// parseWorkflow reads the stages of a workflow, decoding the spec of each
+// stage over the defaults for jobs and checking it like a single job.
+func parseWorkflow(cmd *cobra.Command, data []byte) ([]model.WorkflowStageSpec, string, error) {
+	var file workflowFile
+	// the yaml parser reads json as well
+	if err := model.YAMLUnmarshalWithMax(data, &file); err != nil {
+		return nil, "", err
+	}
+
+	stages := make([]model.WorkflowStageSpec, 0, len(file.Stages))
+	apiVersion := file.APIVersion
+	for _, stage := range file.Stages {
+		j, err := model.NewJobWithSaneProductionDefaults()
+		if err != nil {
+			return nil, "", err
+		}
+		if apiVersion == "" {
+			apiVersion = j.APIVersion
+		}
+		j.APIVersion = apiVersion
+		if len(stage.Spec) > 0 {
+			if err = model.JSONUnmarshalWithMax(stage.Spec, &j.Spec); err != nil {
+				return nil, "", fmt.Errorf("stage %s: %w", stage.Name, err)
+			}
+		}
+		if err = jobutils.VerifyJob(cmd.Context(), j); err != nil {
+			return nil, "", fmt.Errorf("stage %s: %w", stage.Name, err)
+		}
+		stages = append(stages, model.WorkflowStageSpec{
+			Name:      stage.Name,
+			DependsOn: stage.DependsOn,
+			Inputs:    stage.Inputs,
+			Spec:      j.Spec,
+		})
+	}
+	return stages, apiVersion, nil
+}
+
+func printWorkflow(cmd *cobra.Command, workflow model.Workflow, options *WorkflowOptions) error {
+	if options.OutputFormat == JSONFormat {
+		msgBytes, err := model.JSONMarshalIndentWithMax(workflow, 2)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error marshaling workflow to JSON: %s", err), 1)
+			return err
+		}
+		cmd.Printf("%s\n", msgBytes)
+		return nil
+	}
+
+	cmd.Printf("Workflow %s: %s\n", workflow.ID, workflow.State)
+	tw := newWorkflowTable(cmd, options, table.Row{"stage", "state", "job", "depends on", "error"})
+	for _, stage := range workflow.Stages {
+		tw.AppendRow(table.Row{
+			stage.Name,
+			stage.State,
+			shortID(options.OutputWide, stage.JobID),
+			strings.Join(stage.Dependencies(), ", "),
+			shortenString(options.OutputWide, stage.Error),
+		})
+	}
+	tw.Render()
+	return nil
+}
+
+func listWorkflows(cmd *cobra.Command, options *WorkflowOptions) error {
+	workflows, err := GetAPIClient().ListWorkflows(cmd.Context())
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error listing workflows: %s", err), 1)
+		return err
+	}
+
+	if options.OutputFormat == JSONFormat {
+		var msgBytes []byte
+		msgBytes, err = model.JSONMarshalWithMax(workflows)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error marshaling workflows to JSON: %s", err), 1)
+			return err
+		}
+		cmd.Printf("%s\n", msgBytes)
+		return nil
+	}
+
+	tw := newWorkflowTable(cmd, options, table.Row{"created", "id", "state", "stages"})
+	for _, workflow := range workflows {
+		tw.AppendRow(table.Row{
+			shortenTime(options.OutputWide, workflow.CreatedAt),
+			shortID(options.OutputWide, workflow.ID),
+			workflow.State,
+			summarizeStages(workflow.Stages),
+		})
+	}
+	tw.Render()
+	return nil
+}
+
+// summarizeStages counts the stages in each state, e.g. "2 Completed, 1 Running".
+func summarizeStages(stages []model.WorkflowStage) string {
+	var states []model.WorkflowStageState
+	counts := make(map[model.WorkflowStageState]int)
+	for _, stage := range stages {
+		if counts[stage.State] == 0 {
+			states = append(states, stage.State)
+		}
+		counts[stage.State]++
+	}
+	summary := make([]string, 0, len(states))
+	for _, state := range states {
+		summary = append(summary, fmt.Sprintf("%d %s", counts[state], state))
+	}
+	return strings.Join(summary, ", ")
+}
+
+func newWorkflowTable(cmd *cobra.Command, options *WorkflowOptions, header table.Row) table.Writer {
+	tw := table.NewWriter()
+	tw.SetOutputMirror(cmd.OutOrStdout())
+	if !options.HideHeader {
+		tw.AppendHeader(header)
+	}
+	if options.NoStyle {
+		tw.SetStyle(table.StyleDefault)
+		tw.Style().Options = table.OptionsNoBordersAndSeparators
+	} else {
+		tw.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	}
+	return tw
+}
@@ -0,0 +1,137 @@
+package attestation
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/google/uuid"
+)
+
+// The layout of a SEV-SNP attestation report, from the SEV Secure Nested
+// Paging Firmware ABI Specification.
+const (
+	snpReportSize         = 0x4A0
+	snpVersionOffset      = 0x00
+	snpPolicyOffset       = 0x08
+	snpSignatureAlgOffset = 0x34
+	snpReportDataOffset   = 0x50
+	snpReportDataSize     = 64
+	snpSignatureOffset    = 0x2A0
+	snpSignatureCompSize  = 72
+
+	snpMinVersion = 2
+	// snpSignatureAlgECDSAP384 is ECDSA P-384 with SHA-384
+	snpSignatureAlgECDSAP384 = 1
+	// snpPolicyDebug is the bit of the guest policy that allows the VM to be
+	// debugged, which lets the host read and change its memory
+	snpPolicyDebug = 1 << 19
+)
+
+// The GUIDs of the certificates in the certificate table that the sev_guest
+// driver returns with a SEV-SNP attestation report.
+var (
+	snpVCEKGUID = uuid.MustParse("63da758d-e664-4564-adc5-f4b93be8accd")
+	snpASKGUID  = uuid.MustParse("4ab7b379-bbac-4fe4-a02f-05aef327c782")
+	snpARKGUID  = uuid.MustParse("c0b406a4-a803-4952-9743-3fb6014cd0ae")
+)
+
+// snpCertTableEntrySize is the size of an entry in the certificate table: a
+// GUID, and the offset and length of the certificate in the table.
+const snpCertTableEntrySize = 16 + 4 + 4
+
+// ParseSEVCertTable returns the VCEK, ASK and ARK in the certificate table
+// that the sev_guest driver returns with a SEV-SNP attestation report, as DER
+// certificates, leaf first. The table ends with an entry whose GUID is zero.
+func ParseSEVCertTable(table []byte) ([][]byte, error) {
+	found := map[uuid.UUID][]byte{}
+	for i := 0; ; i += snpCertTableEntrySize {
+		if i+snpCertTableEntrySize > len(table) {
+			return nil, errors.New("the certificate table isn't terminated")
+		}
+		guid, err := uuid.FromBytes(table[i : i+16])
+		if err != nil {
+			return nil, err
+		}
+		if guid == uuid.Nil {
+			break
+		}
+		offset := int(binary.LittleEndian.Uint32(table[i+16:]))
+		length := int(binary.LittleEndian.Uint32(table[i+20:]))
+		if offset < 0 || length < 0 || offset > len(table) || length > len(table)-offset {
+			return nil, fmt.Errorf("certificate %s is outside of the certificate table", guid)
+		}
+		found[guid] = table[offset : offset+length]
+	}
+
+	var certs [][]byte
+	for _, guid := range []uuid.UUID{snpVCEKGUID, snpASKGUID, snpARKGUID} {
+		cert, ok := found[guid]
+		if !ok {
+			return nil, fmt.Errorf("the certificate table has no certificate %s", guid)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// verifySEVSNP checks that the attestation report is signed by the VCEK, the
+// first of the certificates, that the VCEK chains to one of the roots, and
+// that the VM can't be debugged by the host. It returns the report data.
+func verifySEVSNP(report []byte, certificates [][]byte, roots *x509.CertPool) ([]byte, error) {
+	if len(report) != snpReportSize {
+		return nil, fmt.Errorf("the attestation report is %d bytes rather than %d", len(report), snpReportSize)
+	}
+	if version := binary.LittleEndian.Uint32(report[snpVersionOffset:]); version < snpMinVersion {
+		return nil, fmt.Errorf("the attestation report has unsupported version %d", version)
+	}
+	if alg := binary.LittleEndian.Uint32(report[snpSignatureAlgOffset:]); alg != snpSignatureAlgECDSAP384 {
+		return nil, fmt.Errorf("the attestation report is signed with unsupported algorithm %d", alg)
+	}
+	if binary.LittleEndian.Uint64(report[snpPolicyOffset:])&snpPolicyDebug != 0 {
+		return nil, errors.New("the policy of the VM allows the host to debug it")
+	}
+
+	if len(certificates) == 0 {
+		return nil, errors.New("the attestation report has no VCEK certificate")
+	}
+	var certs []*x509.Certificate
+	for _, der := range certificates {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the certificates of the attestation report: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	vcek, ok := certs[0].PublicKey.(*ecdsa.PublicKey)
+	if !ok || vcek.Curve != elliptic.P384() {
+		return nil, errors.New("the VCEK certificate doesn't have a P-384 ECDSA key")
+	}
+	if err := verifyChain(certs[0], certs[1:], roots); err != nil {
+		return nil, err
+	}
+
+	signature := report[snpSignatureOffset:]
+	r := littleEndianInt(signature[:snpSignatureCompSize])
+	s := littleEndianInt(signature[snpSignatureCompSize : 2*snpSignatureCompSize])
+	digest := sha512.Sum384(report[:snpSignatureOffset])
+	if !ecdsa.Verify(vcek, digest[:], r, s) {
+		return nil, errors.New("the attestation report isn't signed by the VCEK")
+	}
+	return bytes.Clone(report[snpReportDataOffset : snpReportDataOffset+snpReportDataSize]), nil
+}
+
+// littleEndianInt returns the unsigned integer in little-endian bytes.
+func littleEndianInt(b []byte) *big.Int {
+	reversed := make([]byte, len(b))
+	for i := range b {
+		reversed[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(reversed)
+}
@@ -0,0 +1,215 @@
+package attestation
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// The layout of a version 4 TDX quote, from the Intel TDX DCAP Quoting
+// Library API.
+const (
+	tdxHeaderSize       = 48
+	tdxVersionOffset    = 0
+	tdxAttKeyTypeOffset = 2
+	tdxTEETypeOffset    = 4
+	tdxBodySize         = 584
+	tdxAttributesOffset = tdxHeaderSize + 120
+	tdxReportDataOffset = tdxHeaderSize + 520
+	tdxReportDataSize   = 64
+	tdxSignedSize       = tdxHeaderSize + tdxBodySize
+
+	tdxQuoteVersion = 4
+	tdxTEEType      = 0x81
+	// tdxAttKeyTypeECDSAP256 is an ECDSA P-256 attestation key
+	tdxAttKeyTypeECDSAP256 = 2
+	// tdxAttributesDebug is the bit of the TD attributes that allows the TD
+	// to be debugged, which lets the host read and change its memory
+	tdxAttributesDebug = 1
+
+	// the types of the certification data in the signature data of a quote
+	tdxCertDataQEReport = 6
+	tdxCertDataPCKChain = 5
+
+	ecdsaP256SignatureSize = 64
+	ecdsaP256KeySize       = 64
+)
+
+// The layout of the report of the quoting enclave that signs a TDX quote.
+const (
+	qeReportSize             = 384
+	qeReportAttributesOffset = 48
+	qeReportMRSignerOffset   = 128
+	qeReportProdIDOffset     = 256
+	qeReportDataOffset       = 320
+	// qeAttributesDebug is the bit of the enclave attributes that allows the
+	// enclave to be debugged
+	qeAttributesDebug = 1 << 1
+)
+
+// tdxQEProdID is the product ID of Intel's TDX quoting enclave.
+const tdxQEProdID = 2
+
+// tdxQEMRSigner is the hash of the key that Intel signs its TDX quoting
+// enclave with.
+var tdxQEMRSigner = mustDecodeHex("dc9e2a7c6f948f17474e34a7fc43ed030f7c1563f1babddf6340c82e0e54a8c5")
+
+// verifyTDX checks that the TDX quote is signed by an attestation key of
+// Intel's quoting enclave, whose report is signed by a PCK certificate that
+// chains to one of the roots, and that the TD can't be debugged by the host.
+// It returns the report data of the TD.
+func verifyTDX(quote []byte, roots *x509.CertPool) ([]byte, error) {
+	r := &quoteReader{data: quote}
+	header := r.next(tdxHeaderSize)
+	body := r.next(tdxBodySize)
+	signatureData := r.next(int(r.uint32()))
+	if r.err != nil {
+		return nil, fmt.Errorf("the quote is truncated: %w", r.err)
+	}
+	if keyType := binary.LittleEndian.Uint16(header[tdxAttKeyTypeOffset:]); keyType != tdxAttKeyTypeECDSAP256 {
+		return nil, fmt.Errorf("the quote is signed with unsupported attestation key type %d", keyType)
+	}
+	if binary.LittleEndian.Uint64(quote[tdxAttributesOffset:])&tdxAttributesDebug != 0 {
+		return nil, errors.New("the attributes of the TD allow the host to debug it")
+	}
+
+	r = &quoteReader{data: signatureData}
+	quoteSignature := r.next(ecdsaP256SignatureSize)
+	attKeyBytes := r.next(ecdsaP256KeySize)
+	certDataType := r.uint16()
+	certData := r.next(int(r.uint32()))
+	if r.err != nil {
+		return nil, fmt.Errorf("the signature data of the quote is truncated: %w", r.err)
+	}
+	if certDataType != tdxCertDataQEReport {
+		return nil, fmt.Errorf("the quote has unsupported certification data type %d", certDataType)
+	}
+
+	r = &quoteReader{data: certData}
+	qeReport := r.next(qeReportSize)
+	qeReportSignature := r.next(ecdsaP256SignatureSize)
+	qeAuthData := r.next(int(r.uint16()))
+	pckDataType := r.uint16()
+	pckData := r.next(int(r.uint32()))
+	if r.err != nil {
+		return nil, fmt.Errorf("the certification data of the quote is truncated: %w", r.err)
+	}
+	if pckDataType != tdxCertDataPCKChain {
+		return nil, fmt.Errorf("the quote has unsupported PCK certification data type %d", pckDataType)
+	}
+
+	certs, err := parsePEMCertificates(bytes.TrimRight(pckData, "\x00"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the PCK certificates of the quote: %w", err)
+	}
+	pck, ok := certs[0].PublicKey.(*ecdsa.PublicKey)
+	if !ok || pck.Curve != elliptic.P256() {
+		return nil, errors.New("the PCK certificate doesn't have a P-256 ECDSA key")
+	}
+	if err = verifyChain(certs[0], certs[1:], roots); err != nil {
+		return nil, err
+	}
+	if !verifyP256(pck, qeReport, qeReportSignature) {
+		return nil, errors.New("the report of the quoting enclave isn't signed by the PCK")
+	}
+	if err = verifyQEIdentity(qeReport); err != nil {
+		return nil, err
+	}
+
+	// the quoting enclave vouches for the attestation key in its report data
+	binding := sha256.Sum256(append(bytes.Clone(attKeyBytes), qeAuthData...))
+	qeReportData := qeReport[qeReportDataOffset:]
+	if !bytes.Equal(qeReportData[:sha256.Size], binding[:]) || !isZero(qeReportData[sha256.Size:]) {
+		return nil, errors.New("the attestation key of the quote isn't the one of the quoting enclave")
+	}
+	x := new(big.Int).SetBytes(attKeyBytes[:ecdsaP256KeySize/2])
+	y := new(big.Int).SetBytes(attKeyBytes[ecdsaP256KeySize/2:])
+	if !elliptic.P256().IsOnCurve(x, y) {
+		return nil, errors.New("the attestation key of the quote isn't a P-256 key")
+	}
+	attKey := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	if !verifyP256(attKey, quote[:tdxSignedSize], quoteSignature) {
+		return nil, errors.New("the quote isn't signed by the attestation key")
+	}
+	return bytes.Clone(body[tdxReportDataOffset-tdxHeaderSize : tdxReportDataOffset-tdxHeaderSize+tdxReportDataSize]), nil
+}
+
+// verifyQEIdentity checks that the report is of Intel's TDX quoting enclave,
+// and that the enclave can't be debugged.
+func verifyQEIdentity(qeReport []byte) error {
+	if !bytes.Equal(qeReport[qeReportMRSignerOffset:qeReportMRSignerOffset+sha256.Size], tdxQEMRSigner) ||
+		binary.LittleEndian.Uint16(qeReport[qeReportProdIDOffset:]) != tdxQEProdID {
+		return errors.New("the quote isn't signed by Intel's TDX quoting enclave")
+	}
+	if qeReport[qeReportAttributesOffset]&qeAttributesDebug != 0 {
+		return errors.New("the quoting enclave of the quote can be debugged")
+	}
+	return nil
+}
+
+// verifyP256 checks an ECDSA signature of SHA-256 of the data, which is the
+// big-endian r and s of the signature.
+func verifyP256(key *ecdsa.PublicKey, data, signature []byte) bool {
+	digest := sha256.Sum256(data)
+	r := new(big.Int).SetBytes(signature[:ecdsaP256SignatureSize/2])
+	s := new(big.Int).SetBytes(signature[ecdsaP256SignatureSize/2:])
+	return ecdsa.Verify(key, digest[:], r, s)
+}
+
+// quoteReader reads the fields of a quote in order, and records whether any
+// of them were past the end of the data.
+type quoteReader struct {
+	data []byte
+	err  error
+}
+
+func (r *quoteReader) next(size int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if size < 0 || size > len(r.data) {
+		r.err = fmt.Errorf("needed %d bytes but only %d are left", size, len(r.data))
+		return nil
+	}
+	field := r.data[:size]
+	r.data = r.data[size:]
+	return field
+}
+
+func (r *quoteReader) uint16() uint16 {
+	if b := r.next(2); b != nil { //nolint:gomnd
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *quoteReader) uint32() uint32 {
+	if b := r.next(4); b != nil { //nolint:gomnd
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,168 @@
+// Package attestation verifies the evidence that compute nodes attach to their
+// bids on jobs that require attested execution: the attestation reports of
+// AMD SEV-SNP VMs and the quotes of Intel TDX trust domains, which are signed
+// by keys that chain to the hardware vendors' root certificates.
+package attestation
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+type VerifierParams struct {
+	// Roots are the root certificates of the hardware vendors that evidence
+	// must chain to, such as AMD's ARKs and Intel's SGX root CA. No evidence
+	// is verified if there are none.
+	Roots *x509.CertPool
+}
+
+// Verifier checks that evidence is signed by the hardware of a TEE, through a
+// certificate chain that ends in one of its trusted roots, and that it binds
+// the bid of a node to a job.
+type Verifier struct {
+	roots *x509.CertPool
+}
+
+func NewVerifier(params VerifierParams) *Verifier {
+	return &Verifier{roots: params.Roots}
+}
+
+// Verify returns an error unless the attestation is evidence from a kind of
+// TEE that the job requires, signed by its hardware and bound to the job and
+// the node. The kind of TEE is the one the evidence is from, rather than the
+// one the node declares.
+func (v *Verifier) Verify(a *model.Attestation, required model.TEEType, jobID, nodeID string) error {
+	if a == nil {
+		return fmt.Errorf("node %s didn't attest that it runs in a TEE", nodeID)
+	}
+	if v.roots == nil {
+		return fmt.Errorf("the requester has no trusted roots to verify the attestation of node %s with", nodeID)
+	}
+	typ, err := EvidenceType(a.Evidence)
+	if err != nil {
+		return fmt.Errorf("the attestation of node %s is invalid: %w", nodeID, err)
+	}
+	if typ != a.Type {
+		return fmt.Errorf("node %s says it runs in a %s TEE but its evidence is from %s", nodeID, a.Type, typ)
+	}
+	if !typ.Satisfies(required) {
+		return fmt.Errorf("node %s runs in a %s TEE rather than %s", nodeID, typ, required)
+	}
+
+	var reportData []byte
+	switch typ {
+	case model.TEESEVSNP:
+		reportData, err = verifySEVSNP(a.Evidence, a.Certificates, v.roots)
+	case model.TEETDX:
+		reportData, err = verifyTDX(a.Evidence, v.roots)
+	}
+	if err != nil {
+		return fmt.Errorf("the %s attestation of node %s is invalid: %w", typ, nodeID, err)
+	}
+
+	expected := model.AttestationReportData(jobID, nodeID)
+	if !bytes.Equal(reportData, expected) || !bytes.Equal(a.ReportData, expected) {
+		return fmt.Errorf("the attestation of node %s isn't bound to job %s", nodeID, jobID)
+	}
+	return nil
+}
+
+// EvidenceType returns the kind of TEE that the evidence is from: a TDX quote
+// has a version 4 header for a TDX TEE, and a SEV-SNP attestation report has
+// a fixed size.
+func EvidenceType(evidence []byte) (model.TEEType, error) {
+	if len(evidence) >= tdxHeaderSize &&
+		binary.LittleEndian.Uint16(evidence[tdxVersionOffset:]) == tdxQuoteVersion &&
+		binary.LittleEndian.Uint32(evidence[tdxTEETypeOffset:]) == tdxTEEType {
+		return model.TEETDX, nil
+	}
+	if len(evidence) == snpReportSize {
+		return model.TEESEVSNP, nil
+	}
+	return "", errors.New("the evidence is neither a SEV-SNP attestation report nor a TDX quote")
+}
+
+// LoadTrustRoots reads the root certificates that evidence must chain to from
+// a PEM file.
+func LoadTrustRoots(path string) (*x509.CertPool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the trusted roots of TEEs: %w", err)
+	}
+	certs, err := parsePEMCertificates(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the trusted roots of TEEs in %s: %w", path, err)
+	}
+	roots := x509.NewCertPool()
+	for _, cert := range certs {
+		roots.AddCert(cert)
+	}
+	return roots, nil
+}
+
+// LoadCertificates reads the certificates that evidence is verified with from
+// a PEM file, and returns them as DER certificates in the order of the file.
+func LoadCertificates(path string) ([][]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the certificates of the TEE: %w", err)
+	}
+	certs, err := parsePEMCertificates(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the certificates of the TEE in %s: %w", path, err)
+	}
+	der := make([][]byte, 0, len(certs))
+	for _, cert := range certs {
+		der = append(der, cert.Raw)
+	}
+	return der, nil
+}
+
+// parsePEMCertificates returns the certificates in PEM data, in order.
+func parsePEMCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("there are no certificates")
+	}
+	return certs, nil
+}
+
+// verifyChain checks that the leaf certificate chains to one of the roots
+// through the intermediates.
+func verifyChain(leaf *x509.Certificate, intermediates []*x509.Certificate, roots *x509.CertPool) error {
+	pool := x509.NewCertPool()
+	for _, cert := range intermediates {
+		pool.AddCert(cert)
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: pool,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("the certificate %q doesn't chain to a trusted root: %w", leaf.Subject.CommonName, err)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+//go:build unit || !integration
+
+package attestation
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// vendor is a certificate chain like the ones of AMD and Intel: a root, an
+// intermediate, and a leaf whose key signs evidence.
+type vendor struct {
+	roots *x509.CertPool
+	chain [][]byte
+	key   *ecdsa.PrivateKey
+}
+
+func newVendor(t *testing.T, curve elliptic.Curve) vendor {
+	rootKey, root := newCertificate(t, curve, "root", nil, nil)
+	intermediateKey, intermediate := newCertificate(t, curve, "intermediate", root, rootKey)
+	leafKey, leaf := newCertificate(t, curve, "leaf", intermediate, intermediateKey)
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	return vendor{roots: roots, chain: [][]byte{leaf.Raw, intermediate.Raw, root.Raw}, key: leafKey}
+}
+
+func newCertificate(
+	t *testing.T, curve elliptic.Curve, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey,
+) (*ecdsa.PrivateKey, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  parent == nil || name == "intermediate",
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return key, cert
+}
+
+// signedSEVReport returns an attestation report of a SEV-SNP VM, which the
+// change can alter before the VCEK signs it.
+func signedSEVReport(t *testing.T, vcek *ecdsa.PrivateKey, reportData []byte, change func(report []byte)) []byte {
+	report := make([]byte, snpReportSize)
+	binary.LittleEndian.PutUint32(report[snpVersionOffset:], 2)
+	binary.LittleEndian.PutUint32(report[snpSignatureAlgOffset:], snpSignatureAlgECDSAP384)
+	copy(report[snpReportDataOffset:], reportData)
+	change(report)
+
+	digest := sha512.Sum384(report[:snpSignatureOffset])
+	r, s, err := ecdsa.Sign(rand.Reader, vcek, digest[:])
+	require.NoError(t, err)
+	signature := report[snpSignatureOffset:]
+	r.FillBytes(signature[:snpSignatureCompSize])
+	s.FillBytes(signature[snpSignatureCompSize : 2*snpSignatureCompSize])
+	reverse(signature[:snpSignatureCompSize])
+	reverse(signature[snpSignatureCompSize : 2*snpSignatureCompSize])
+	return report
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+// signedTDXQuote returns a quote of a TDX trust domain, whose quoting enclave
+// is certified by the PCK, and which the change can alter before its
+// attestation key signs it.
+func signedTDXQuote(t *testing.T, pck vendor, reportData []byte, change func(quote, qeReport []byte)) []byte {
+	attKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	attKeyBytes := make([]byte, ecdsaP256KeySize)
+	attKey.X.FillBytes(attKeyBytes[:32])
+	attKey.Y.FillBytes(attKeyBytes[32:])
+	authData := []byte("auth")
+
+	qeReport := make([]byte, qeReportSize)
+	copy(qeReport[qeReportMRSignerOffset:], tdxQEMRSigner)
+	binary.LittleEndian.PutUint16(qeReport[qeReportProdIDOffset:], tdxQEProdID)
+	binding := sha256.Sum256(append(bytes.Clone(attKeyBytes), authData...))
+	copy(qeReport[qeReportDataOffset:], binding[:])
+
+	quote := make([]byte, tdxSignedSize)
+	binary.LittleEndian.PutUint16(quote[tdxVersionOffset:], tdxQuoteVersion)
+	binary.LittleEndian.PutUint16(quote[tdxAttKeyTypeOffset:], tdxAttKeyTypeECDSAP256)
+	binary.LittleEndian.PutUint32(quote[tdxTEETypeOffset:], tdxTEEType)
+	copy(quote[tdxReportDataOffset:], reportData)
+	change(quote, qeReport)
+
+	var pckChain []byte
+	for _, der := range pck.chain {
+		pckChain = append(pckChain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	certData := append(bytes.Clone(qeReport), signP256(t, pck.key, qeReport)...)
+	certData = binary.LittleEndian.AppendUint16(certData, uint16(len(authData)))
+	certData = append(certData, authData...)
+	certData = binary.LittleEndian.AppendUint16(certData, tdxCertDataPCKChain)
+	certData = binary.LittleEndian.AppendUint32(certData, uint32(len(pckChain)))
+	certData = append(certData, pckChain...)
+
+	signatureData := append(signP256(t, attKey, quote), attKeyBytes...)
+	signatureData = binary.LittleEndian.AppendUint16(signatureData, tdxCertDataQEReport)
+	signatureData = binary.LittleEndian.AppendUint32(signatureData, uint32(len(certData)))
+	signatureData = append(signatureData, certData...)
+
+	quote = binary.LittleEndian.AppendUint32(quote, uint32(len(signatureData)))
+	return append(quote, signatureData...)
+}
+
+func signP256(t *testing.T, key *ecdsa.PrivateKey, data []byte) []byte {
+	digest := sha256.Sum256(data)
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	signature := make([]byte, ecdsaP256SignatureSize)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signature
+}
+
+func TestVerifySEVSNP(t *testing.T) {
+	amd := newVendor(t, elliptic.P384())
+	verifier := NewVerifier(VerifierParams{Roots: amd.roots})
+	reportData := model.AttestationReportData("job", "node")
+	attestation := func(change func(report []byte)) *model.Attestation {
+		return &model.Attestation{
+			Type:         model.TEESEVSNP,
+			Evidence:     signedSEVReport(t, amd.key, reportData, change),
+			Certificates: amd.chain,
+			ReportData:   reportData,
+		}
+	}
+
+	valid := attestation(func([]byte) {})
+	require.NoError(t, verifier.Verify(valid, model.TEESEVSNP, "job", "node"))
+	require.NoError(t, verifier.Verify(valid, model.TEEAny, "job", "node"))
+	require.Error(t, verifier.Verify(valid, model.TEETDX, "job", "node"), "the job requires another kind of TEE")
+	require.Error(t, verifier.Verify(valid, model.TEEAny, "other-job", "node"), "the evidence is for another job")
+	require.Error(t, verifier.Verify(valid, model.TEEAny, "job", "other-node"), "the evidence is from another node")
+	require.Error(t, verifier.Verify(nil, model.TEEAny, "job", "node"))
+
+	forged := *valid
+	forged.Evidence = bytes.Clone(valid.Evidence)
+	forged.Evidence[snpReportDataOffset-1] ^= 1
+	require.Error(t, verifier.Verify(&forged, model.TEEAny, "job", "node"), "the evidence isn't signed by the VCEK")
+
+	unsigned := *valid
+	unsigned.Evidence = bytes.Clone(valid.Evidence)
+	copy(unsigned.Evidence[snpSignatureOffset:], make([]byte, snpReportSize-snpSignatureOffset))
+	require.Error(t, verifier.Verify(&unsigned, model.TEEAny, "job", "node"), "the evidence isn't signed")
+
+	debug := attestation(func(report []byte) { binary.LittleEndian.PutUint64(report[snpPolicyOffset:], snpPolicyDebug) })
+	require.Error(t, verifier.Verify(debug, model.TEEAny, "job", "node"), "the host can debug the VM")
+
+	noCertificates := *valid
+	noCertificates.Certificates = nil
+	require.Error(t, verifier.Verify(&noCertificates, model.TEEAny, "job", "node"), "there is no VCEK")
+
+	selfSigned := newVendor(t, elliptic.P384())
+	untrusted := &model.Attestation{
+		Type:         model.TEESEVSNP,
+		Evidence:     signedSEVReport(t, selfSigned.key, reportData, func([]byte) {}),
+		Certificates: selfSigned.chain,
+		ReportData:   reportData,
+	}
+	require.Error(t, verifier.Verify(untrusted, model.TEEAny, "job", "node"), "the VCEK isn't from AMD")
+
+	mislabelled := *valid
+	mislabelled.Type = model.TEETDX
+	require.Error(t, verifier.Verify(&mislabelled, model.TEEAny, "job", "node"), "the node lies about its TEE")
+
+	require.Error(t, NewVerifier(VerifierParams{}).Verify(valid, model.TEEAny, "job", "node"), "there are no trusted roots")
+}
+
+func TestVerifyTDX(t *testing.T) {
+	intel := newVendor(t, elliptic.P256())
+	verifier := NewVerifier(VerifierParams{Roots: intel.roots})
+	reportData := model.AttestationReportData("job", "node")
+	attestation := func(change func(quote, qeReport []byte)) *model.Attestation {
+		return &model.Attestation{
+			Type:       model.TEETDX,
+			Evidence:   signedTDXQuote(t, intel, reportData, change),
+			ReportData: reportData,
+		}
+	}
+
+	valid := attestation(func([]byte, []byte) {})
+	require.NoError(t, verifier.Verify(valid, model.TEETDX, "job", "node"))
+	require.NoError(t, verifier.Verify(valid, model.TEEAny, "job", "node"))
+	require.Error(t, verifier.Verify(valid, model.TEESEVSNP, "job", "node"), "the job requires another kind of TEE")
+	require.Error(t, verifier.Verify(valid, model.TEEAny, "other-job", "node"), "the evidence is for another job")
+
+	forged := *valid
+	forged.Evidence = bytes.Clone(valid.Evidence)
+	forged.Evidence[tdxReportDataOffset-1] ^= 1
+	require.Error(t, verifier.Verify(&forged, model.TEEAny, "job", "node"), "the quote isn't signed by the attestation key")
+
+	truncated := *valid
+	truncated.Evidence = valid.Evidence[:tdxSignedSize]
+	require.Error(t, verifier.Verify(&truncated, model.TEEAny, "job", "node"), "the quote isn't signed")
+
+	debug := attestation(func(quote, _ []byte) { quote[tdxAttributesOffset] |= tdxAttributesDebug })
+	require.Error(t, verifier.Verify(debug, model.TEEAny, "job", "node"), "the host can debug the TD")
+
+	otherEnclave := attestation(func(_, qeReport []byte) { qeReport[qeReportMRSignerOffset] ^= 1 })
+	require.Error(t, verifier.Verify(otherEnclave, model.TEEAny, "job", "node"), "the quote isn't from Intel's quoting enclave")
+
+	otherKey := attestation(func(_, qeReport []byte) { qeReport[qeReportDataOffset] ^= 1 })
+	require.Error(t, verifier.Verify(otherKey, model.TEEAny, "job", "node"), "the quoting enclave doesn't vouch for the key")
+
+	selfSigned := newVendor(t, elliptic.P256())
+	untrusted := &model.Attestation{
+		Type:       model.TEETDX,
+		Evidence:   signedTDXQuote(t, selfSigned, reportData, func([]byte, []byte) {}),
+		ReportData: reportData,
+	}
+	require.Error(t, verifier.Verify(untrusted, model.TEEAny, "job", "node"), "the PCK isn't from Intel")
+}
+
+func TestEvidenceType(t *testing.T) {
+	_, err := EvidenceType(make([]byte, 100))
+	require.Error(t, err)
+}
+
+func TestParseSEVCertTable(t *testing.T) {
+	certs := [][]byte{[]byte("vcek"), []byte("ask"), []byte("ark")}
+	guids := [][]byte{snpVCEKGUID[:], snpASKGUID[:], snpARKGUID[:]}
+	table := func(entries int) []byte {
+		headerSize := (entries + 1) * snpCertTableEntrySize
+		header := make([]byte, 0, headerSize)
+		var data []byte
+		for i := entries - 1; i >= 0; i-- {
+			header = append(header, guids[i]...)
+			header = binary.LittleEndian.AppendUint32(header, uint32(headerSize+len(data)))
+			header = binary.LittleEndian.AppendUint32(header, uint32(len(certs[i])))
+			data = append(data, certs[i]...)
+		}
+		header = append(header, make([]byte, snpCertTableEntrySize)...)
+		return append(header, data...)
+	}
+
+	parsed, err := ParseSEVCertTable(table(3))
+	require.NoError(t, err)
+	require.Equal(t, certs, parsed)
+
+	_, err = ParseSEVCertTable(table(2))
+	require.Error(t, err, "the table has no ARK")
+
+	_, err = ParseSEVCertTable(table(3)[:snpCertTableEntrySize])
+	require.Error(t, err, "the table isn't terminated")
+}
+
+func TestLoadTrustRoots(t *testing.T) {
+	amd := newVendor(t, elliptic.P384())
+	path := filepath.Join(t.TempDir(), "roots.pem")
+	root := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: amd.chain[2]})
+	require.NoError(t, os.WriteFile(path, root, 0600))
+
+	roots, err := LoadTrustRoots(path)
+	require.NoError(t, err)
+	require.True(t, roots.Equal(amd.roots))
+
+	require.NoError(t, os.WriteFile(path, []byte("not a certificate"), 0600))
+	_, err = LoadTrustRoots(path)
+	require.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package bidstrategy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"sigs.k8s.io/yaml"
+)
+
+// StrategyPhase is when a strategy in a chain is asked whether the node should bid on a job.
+type StrategyPhase string
+
+const (
+	// StrategyPhaseAny asks the strategy both before and after the resources of the job are calculated, which is how
+	// strategies are asked unless the chain says otherwise.
+	StrategyPhaseAny StrategyPhase = ""
+	// StrategyPhaseSemantic only asks the strategy about what the job is, before its resources are calculated.
+	StrategyPhaseSemantic StrategyPhase = "semantic"
+	// StrategyPhaseResource only asks the strategy about the resources that the job needs.
+	StrategyPhaseResource StrategyPhase = "resource"
+)
+
+// StrategyConfig is a strategy in a chain.
+type StrategyConfig struct {
+	// Name is the name that the strategy is registered with
+	Name string `json:"name"`
+	// Phase is when the strategy is asked about jobs, or both before and after their resources are calculated if empty
+	Phase StrategyPhase `json:"phase,omitempty"`
+	// Params configure the strategy, in the form that its factory takes
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// ChainConfig lists the strategies that a compute node asks whether to bid on jobs, in order. The first strategy that
+// rejects a job stops the chain.
+type ChainConfig struct {
+	Strategies []StrategyConfig `json:"strategies"`
+}
+
+// LoadChainConfig reads the chain from a YAML or JSON file.
+func LoadChainConfig(path string) (ChainConfig, error) {
+	var config ChainConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err = yaml.UnmarshalStrict(data, &config); err != nil {
+		return config, fmt.Errorf("bid strategy chain %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// StrategyFactory builds a strategy from its params in a chain, which are empty if the chain doesn't set them.
+type StrategyFactory func(params json.RawMessage) (BidStrategy, error)
+
+// NewChainFromConfig builds the strategies of the chain with the factories that they are registered with.
+func NewChainFromConfig(config ChainConfig, factories map[string]StrategyFactory) (*ChainedBidStrategy, error) {
+	chain := NewChainedBidStrategy()
+	for i, strategyConfig := range config.Strategies {
+		factory, ok := factories[strategyConfig.Name]
+		if !ok {
+			names := make([]string, 0, len(factories))
+			for name := range factories {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("bid strategy %d: unknown strategy %q, which should be one of %s",
+				i, strategyConfig.Name, strings.Join(names, ", "))
+		}
+		strategy, err := factory(strategyConfig.Params)
+		if err != nil {
+			return nil, fmt.Errorf("bid strategy %d (%s): %w", i, strategyConfig.Name, err)
+		}
+		switch strategyConfig.Phase {
+		case StrategyPhaseAny:
+		case StrategyPhaseSemantic, StrategyPhaseResource:
+			strategy = &phasedStrategy{BidStrategy: strategy, phase: strategyConfig.Phase}
+		default:
+			return nil, fmt.Errorf("bid strategy %d (%s): unknown phase %q, which should be %s or %s",
+				i, strategyConfig.Name, strategyConfig.Phase, StrategyPhaseSemantic, StrategyPhaseResource)
+		}
+		chain.AddStrategy(strategy)
+	}
+	return chain, nil
+}
+
+// DecodeParams decodes the params of a strategy in a chain into the value, which keeps what it was set to for the
+// params that aren't given. Unknown params are an error.
+func DecodeParams(params json.RawMessage, value interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		return fmt.Errorf("invalid params: %w", err)
+	}
+	return nil
+}
+
+// NoParams returns a factory for a strategy that takes no params in a chain, such as one that is configured by the
+// flags of the node.
+func NoParams(strategy BidStrategy) StrategyFactory {
+	return func(params json.RawMessage) (BidStrategy, error) {
+		if len(params) > 0 && string(params) != "null" {
+			return nil, fmt.Errorf("the strategy takes no params")
+		}
+		return strategy, nil
+	}
+}
+
+// phasedStrategy only asks the strategy in one of the phases of bidding.
+type phasedStrategy struct {
+	BidStrategy
+	phase StrategyPhase
+}
+
+func (s *phasedStrategy) ShouldBid(ctx context.Context, request BidStrategyRequest) (BidStrategyResponse, error) {
+	if s.phase != StrategyPhaseSemantic {
+		return NewShouldBidResponse(), nil
+	}
+	return s.BidStrategy.ShouldBid(ctx, request)
+}
+
+func (s *phasedStrategy) ShouldBidBasedOnUsage(
+	ctx context.Context, request BidStrategyRequest, usage model.ResourceUsageData) (BidStrategyResponse, error) {
+	if s.phase != StrategyPhaseResource {
+		return NewShouldBidResponse(), nil
+	}
+	return s.BidStrategy.ShouldBidBasedOnUsage(ctx, request, usage)
+}
@@ -0,0 +1,84 @@
+//go:build unit || !integration
+
+package bidstrategy
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// rejectStrategy rejects all jobs in both phases.
+type rejectStrategy struct {
+	reason string
+}
+
+func (s rejectStrategy) ShouldBid(context.Context, BidStrategyRequest) (BidStrategyResponse, error) {
+	return BidStrategyResponse{Reason: s.reason}, nil
+}
+
+func (s rejectStrategy) ShouldBidBasedOnUsage(
+	context.Context, BidStrategyRequest, model.ResourceUsageData) (BidStrategyResponse, error) {
+	return BidStrategyResponse{Reason: s.reason}, nil
+}
+
+func TestNewChainFromConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chain.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+strategies:
+  - name: reject
+    phase: resource
+    params:
+      reason: too big
+`), 0600))
+	config, err := LoadChainConfig(path)
+	require.NoError(t, err)
+
+	factories := map[string]StrategyFactory{
+		"reject": func(params json.RawMessage) (BidStrategy, error) {
+			strategy := rejectStrategy{reason: "rejected"}
+			var decoded struct{ Reason string }
+			if err := DecodeParams(params, &decoded); err != nil {
+				return nil, err
+			}
+			if decoded.Reason != "" {
+				strategy.reason = decoded.Reason
+			}
+			return strategy, nil
+		},
+		"noop": NoParams(NewChainedBidStrategy()),
+	}
+	chain, err := NewChainFromConfig(config, factories)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	response, err := chain.ShouldBid(ctx, getBidStrategyRequest())
+	require.NoError(t, err)
+	require.True(t, response.ShouldBid, "resource strategies aren't asked before resources are calculated")
+	response, err = chain.ShouldBidBasedOnUsage(ctx, getBidStrategyRequest(), model.ResourceUsageData{})
+	require.NoError(t, err)
+	require.False(t, response.ShouldBid)
+	require.Equal(t, "too big", response.Reason)
+
+	for _, invalid := range []ChainConfig{
+		{Strategies: []StrategyConfig{{Name: "unknown"}}},
+		{Strategies: []StrategyConfig{{Name: "reject", Phase: "later"}}},
+		{Strategies: []StrategyConfig{{Name: "reject", Params: json.RawMessage(`{"unknown": 1}`)}}},
+		{Strategies: []StrategyConfig{{Name: "noop", Params: json.RawMessage(`{"reason": "none"}`)}}},
+	} {
+		_, err = NewChainFromConfig(invalid, factories)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestLoadChainConfigRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chain.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"strategies": [{"name": "noop", "order": 1}]}`), 0600))
+	_, err := LoadChainConfig(path)
+	require.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package bidstrategy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+type ClientSignatureStrategyParams struct {
+	// Require is whether the node only bids on jobs that are signed by their
+	// client
+	Require bool
+}
+
+// ClientSignatureStrategy doesn't bid on jobs whose client signature is
+// invalid, so that the strategies after it can trust the ClientID of the jobs
+// they are asked about, nor on jobs that aren't signed if it requires them to
+// be.
+type ClientSignatureStrategy struct {
+	require bool
+}
+
+func NewClientSignatureStrategy(params ClientSignatureStrategyParams) *ClientSignatureStrategy {
+	return &ClientSignatureStrategy{
+		require: params.Require,
+	}
+}
+
+func (s *ClientSignatureStrategy) ShouldBid(_ context.Context, request BidStrategyRequest) (BidStrategyResponse, error) {
+	if request.Job.Metadata.ClientSignature == nil && !s.require {
+		return NewShouldBidResponse(), nil
+	}
+	if err := job.VerifyClientSignature(request.Job); err != nil {
+		return BidStrategyResponse{ShouldBid: false, Reason: fmt.Sprintf("the node can't verify the job's client: %s", err)}, nil
+	}
+	return NewShouldBidResponse(), nil
+}
+
+func (s *ClientSignatureStrategy) ShouldBidBasedOnUsage(
+	ctx context.Context, request BidStrategyRequest, _ model.ResourceUsageData) (BidStrategyResponse, error) {
+	return s.ShouldBid(ctx, request)
+}
+
+// compile-time interface check
+var _ BidStrategy = (*ClientSignatureStrategy)(nil)
@@ -0,0 +1,51 @@
+//go:build unit || !integration
+
+package bidstrategy
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientSignatureStrategy(t *testing.T) {
+	system.InitConfigForTesting(t)
+	payload, err := json.Marshal(model.JobCreatePayload{ClientID: system.GetClientID(), Spec: &model.Spec{}, Nonce: "nonce"})
+	require.NoError(t, err)
+	signature, err := system.SignForClient(payload)
+	require.NoError(t, err)
+	signed := &model.ClientSignature{Payload: payload, Signature: signature, PublicKey: system.GetClientPublicKey()}
+
+	tests := []struct {
+		name      string
+		require   bool
+		clientID  string
+		signature *model.ClientSignature
+		shouldBid bool
+	}{
+		{name: "unsigned", shouldBid: true},
+		{name: "unsigned-required", require: true, shouldBid: false},
+		{name: "signed", clientID: system.GetClientID(), signature: signed, shouldBid: true},
+		{name: "signed-required", require: true, clientID: system.GetClientID(), signature: signed, shouldBid: true},
+		{name: "other-client", clientID: "other", signature: signed, shouldBid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			subject := NewClientSignatureStrategy(ClientSignatureStrategyParams{Require: test.require})
+			job := model.Job{Metadata: model.Metadata{ClientID: test.clientID, ClientSignature: test.signature}}
+			if test.signature != nil {
+				job.Metadata.ID = jobutils.SignedJobID(test.signature.Payload)
+			}
+
+			response, err := subject.ShouldBid(context.Background(), BidStrategyRequest{Job: job})
+			require.NoError(t, err)
+			require.Equal(t, test.shouldBid, response.ShouldBid, response.Reason)
+		})
+	}
+}
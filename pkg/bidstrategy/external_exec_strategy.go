@@ -34,7 +34,7 @@ func (s *ExternalCommandStrategy) ShouldBid(ctx context.Context, request BidStra
 
 	// TODO: Use context to trace exec call
 
-	data := getJobSelectionPolicyProbeData(request)
+	data := GetJobSelectionPolicyProbeData(request)
 	jsonData, err := model.JSONMarshalWithMax(data)
 
 	if err != nil {
@@ -10,7 +10,6 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
 	"github.com/pkg/errors"
-	"github.com/rs/zerolog/log"
 )
 
 type ExternalHTTPStrategyParams struct {
@@ -31,8 +30,14 @@ func (s *ExternalHTTPStrategy) ShouldBid(ctx context.Context, request BidStrateg
 	if s.url == "" {
 		return NewShouldBidResponse(), nil
 	}
+	return postProbe(ctx, s.url, nil, request)
+}
 
-	data := getJobSelectionPolicyProbeData(request)
+// postProbe POSTs the probe data of the request to the url with the header,
+// and returns the response of the url as JSON, or that the job should be bid on
+// if the url succeeded with anything else.
+func postProbe(ctx context.Context, url string, header http.Header, request BidStrategyRequest) (BidStrategyResponse, error) {
+	data := GetJobSelectionPolicyProbeData(request)
 	jsonData, err := model.JSONMarshalWithMax(data)
 
 	if err != nil {
@@ -40,22 +45,25 @@ func (s *ExternalHTTPStrategy) ShouldBid(ctx context.Context, request BidStrateg
 	}
 
 	body := bytes.NewBuffer(jsonData)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, body)
-	req.Header.Add("Content-Type", "application/json")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
 	if err != nil {
-		log.Ctx(ctx).Error().Msgf("could not create http request with context: %s", s.url)
+		return BidStrategyResponse{}, fmt.Errorf("ExternalHTTPStrategy: could not create http request: %s %w", url, err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
 	}
+	req.Header.Set("Content-Type", "application/json")
 	resp, err := http.DefaultClient.Do(req) //nolint:bodyclose
 	if err != nil {
 		return BidStrategyResponse{},
-			fmt.Errorf("ExternalHTTPStrategy: error http POST job selection policy probe data: %s %w", s.url, err)
+			fmt.Errorf("ExternalHTTPStrategy: error http POST job selection policy probe data: %s %w", url, err)
 	}
-	defer closer.DrainAndCloseWithLogOnError(ctx, s.url, resp.Body)
+	defer closer.DrainAndCloseWithLogOnError(ctx, url, resp.Body)
 
 	if resp.StatusCode >= http.StatusBadRequest {
 		return BidStrategyResponse{
 			ShouldBid: false,
-			Reason:    fmt.Sprintf("url `%s` returned %d status code", s.url, resp.StatusCode),
+			Reason:    fmt.Sprintf("url `%s` returned %d status code", url, resp.StatusCode),
 		}, nil
 	}
 
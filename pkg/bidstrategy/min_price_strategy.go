@@ -0,0 +1,55 @@
+package bidstrategy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+type MinPriceStrategyParams struct {
+	Pricing model.Pricing
+}
+
+// MinPriceStrategy only bids on jobs that offer at least what the node charges
+// per second for each of the resources that they use.
+type MinPriceStrategy struct {
+	pricing model.Pricing
+}
+
+func NewMinPriceStrategy(params MinPriceStrategyParams) *MinPriceStrategy {
+	return &MinPriceStrategy{
+		pricing: params.Pricing,
+	}
+}
+
+func (s *MinPriceStrategy) ShouldBid(context.Context, BidStrategyRequest) (BidStrategyResponse, error) {
+	return NewShouldBidResponse(), nil
+}
+
+func (s *MinPriceStrategy) ShouldBidBasedOnUsage(
+	_ context.Context, request BidStrategyRequest, resourceUsage model.ResourceUsageData) (BidStrategyResponse, error) {
+	offer := request.Job.Spec.Deal.Offer
+	if offer.IsZero() || s.pricing.IsZero() {
+		return NewShouldBidResponse(), nil
+	}
+	for _, price := range []struct {
+		unit    string
+		used    bool
+		offered float64
+		minimum float64
+	}{
+		{"CPU second", resourceUsage.CPU > 0, offer.CPUSecond, s.pricing.CPUSecond},
+		{"GB second of memory", resourceUsage.Memory > 0, offer.MemoryGBSecond, s.pricing.MemoryGBSecond},
+		{"GPU second", resourceUsage.GPU > 0, offer.GPUSecond, s.pricing.GPUSecond},
+	} {
+		if price.used && price.offered < price.minimum {
+			return BidStrategyResponse{
+				ShouldBid: false,
+				Reason: fmt.Sprintf("job offers %g per %s, less than the node's minimum of %g",
+					price.offered, price.unit, price.minimum),
+			}, nil
+		}
+	}
+	return NewShouldBidResponse(), nil
+}
@@ -0,0 +1,55 @@
+//go:build unit || !integration
+
+package bidstrategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinPriceStrategy(t *testing.T) {
+	pricing := model.Pricing{CPUSecond: 0.01, MemoryGBSecond: 0.001, GPUSecond: 1}
+	usage := model.ResourceUsageData{CPU: 2, Memory: 4 << 30}
+	tests := []struct {
+		name      string
+		pricing   model.Pricing
+		offer     model.Pricing
+		shouldBid bool
+		reason    string
+	}{
+		{name: "no-offer", pricing: pricing, shouldBid: true},
+		{name: "free-node", offer: model.Pricing{CPUSecond: 0.001}, shouldBid: true},
+		{name: "matching-offer", pricing: pricing, offer: model.Pricing{CPUSecond: 0.01, MemoryGBSecond: 0.002}, shouldBid: true},
+		{
+			name:      "low-cpu-offer",
+			pricing:   pricing,
+			offer:     model.Pricing{CPUSecond: 0.005, MemoryGBSecond: 0.001},
+			shouldBid: false,
+			reason:    "job offers 0.005 per CPU second, less than the node's minimum of 0.01",
+		},
+		{
+			name:      "missing-memory-offer",
+			pricing:   pricing,
+			offer:     model.Pricing{CPUSecond: 0.01},
+			shouldBid: false,
+			reason:    "job offers 0 per GB second of memory, less than the node's minimum of 0.001",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			subject := NewMinPriceStrategy(MinPriceStrategyParams{Pricing: test.pricing})
+			request := getBidStrategyRequest()
+			request.Job.Spec.Deal.Offer = test.offer
+
+			response, err := subject.ShouldBidBasedOnUsage(context.Background(), request, usage)
+			require.NoError(t, err)
+			assert.Equal(t, test.shouldBid, response.ShouldBid)
+			assert.Equal(t, test.reason, response.Reason)
+		})
+	}
+}
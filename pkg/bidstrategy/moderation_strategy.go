@@ -0,0 +1,100 @@
+package bidstrategy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultModerationPollInterval = 10 * time.Second
+	DefaultModerationTimeout      = 10 * time.Minute
+)
+
+type ModerationStrategyParams struct {
+	// URL is where the moderation service is, which isn't asked about jobs if empty
+	URL string
+	// Token is sent as a bearer token, for the service to know the node asked it
+	Token string
+	// PollInterval is how often to ask again about jobs that the service hasn't decided on yet
+	PollInterval time.Duration
+	// Timeout is how long to wait for the service to decide before the job is rejected
+	Timeout time.Duration
+}
+
+// ModerationStrategy asks an operator's moderation service whether to bid on
+// jobs, so that jobs can be approved by a person or a policy server. The job is
+// POSTed to the service like to the HTTP probe, and the service responds whether
+// to bid, or that it is waiting to decide, in which case it is asked again until
+// it decides or the timeout passes. Jobs are rejected if the service can't be
+// reached, as it hasn't approved them.
+type ModerationStrategy struct {
+	url          string
+	header       http.Header
+	pollInterval time.Duration
+	timeout      time.Duration
+}
+
+func NewModerationStrategy(params ModerationStrategyParams) *ModerationStrategy {
+	header := http.Header{}
+	if params.Token != "" {
+		header.Set("Authorization", "Bearer "+params.Token)
+	}
+	pollInterval := params.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultModerationPollInterval
+	}
+	timeout := params.Timeout
+	if timeout <= 0 {
+		timeout = DefaultModerationTimeout
+	}
+	return &ModerationStrategy{
+		url:          params.URL,
+		header:       header,
+		pollInterval: pollInterval,
+		timeout:      timeout,
+	}
+}
+
+func (s *ModerationStrategy) ShouldBid(ctx context.Context, request BidStrategyRequest) (BidStrategyResponse, error) {
+	if s.url == "" {
+		return NewShouldBidResponse(), nil
+	}
+
+	deadline := time.NewTimer(s.timeout)
+	defer deadline.Stop()
+	for {
+		response, err := postProbe(ctx, s.url, s.header, request)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msgf("Failed to ask the moderation service about job %s", request.Job.Metadata.ID)
+			return BidStrategyResponse{ShouldBid: false, Reason: "the moderation service is unavailable"}, nil
+		}
+		if !response.ShouldWait {
+			return response, nil
+		}
+
+		log.Ctx(ctx).Debug().Msgf("Waiting for the moderation service to decide on job %s: %s", request.Job.Metadata.ID, response.Reason)
+		select {
+		case <-time.After(s.pollInterval):
+		case <-deadline.C:
+			return BidStrategyResponse{
+				ShouldBid: false,
+				Reason:    fmt.Sprintf("the moderation service didn't decide within %s", s.timeout),
+			}, nil
+		case <-ctx.Done():
+			return BidStrategyResponse{}, ctx.Err()
+		}
+	}
+}
+
+func (s *ModerationStrategy) ShouldBidBasedOnUsage(
+	context.Context, BidStrategyRequest, model.ResourceUsageData) (BidStrategyResponse, error) {
+	return NewShouldBidResponse(), nil
+}
+
+// compile-time interface check
+var _ BidStrategy = (*ModerationStrategy)(nil)
@@ -0,0 +1,68 @@
+//go:build unit || !integration
+
+package bidstrategy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerationStrategyWaitsForDecision(t *testing.T) {
+	var asked atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var data JobSelectionPolicyProbeData
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&data))
+		require.Equal(t, "job-id", data.JobID)
+
+		w.Header().Add("Content-Type", "application/json")
+		if asked.Add(1) < 3 {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"shouldWait": true, "reason": "waiting for an operator"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"shouldBid": true, "reason": "approved"}`))
+	}))
+	defer svr.Close()
+
+	strategy := NewModerationStrategy(ModerationStrategyParams{URL: svr.URL, Token: "secret", PollInterval: time.Millisecond})
+	result, err := strategy.ShouldBid(context.Background(), getBidStrategyRequest())
+	require.NoError(t, err)
+	require.True(t, result.ShouldBid)
+	require.Equal(t, int32(3), asked.Load(), "the service is asked until it decides")
+}
+
+func TestModerationStrategyRejectsUndecidedJobs(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"shouldWait": true}`))
+	}))
+	defer svr.Close()
+
+	strategy := NewModerationStrategy(ModerationStrategyParams{
+		URL:          svr.URL,
+		PollInterval: time.Millisecond,
+		Timeout:      20 * time.Millisecond,
+	})
+	result, err := strategy.ShouldBid(context.Background(), getBidStrategyRequest())
+	require.NoError(t, err)
+	require.False(t, result.ShouldBid)
+	require.Contains(t, result.Reason, "didn't decide")
+
+	svr.Close()
+	result, err = strategy.ShouldBid(context.Background(), getBidStrategyRequest())
+	require.NoError(t, err)
+	require.False(t, result.ShouldBid, "jobs aren't bid on while the service is unavailable")
+
+	result, err = NewModerationStrategy(ModerationStrategyParams{}).ShouldBid(context.Background(), getBidStrategyRequest())
+	require.NoError(t, err)
+	require.True(t, result.ShouldBid, "jobs aren't moderated without a service")
+}
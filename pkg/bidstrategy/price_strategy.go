@@ -0,0 +1,43 @@
+package bidstrategy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+type PriceStrategyParams struct {
+	Pricing model.Pricing
+}
+
+// PriceStrategy only bids on jobs whose budget covers what the node charges
+// for running them until their timeout.
+type PriceStrategy struct {
+	pricing model.Pricing
+}
+
+func NewPriceStrategy(params PriceStrategyParams) *PriceStrategy {
+	return &PriceStrategy{
+		pricing: params.Pricing,
+	}
+}
+
+func (s *PriceStrategy) ShouldBid(context.Context, BidStrategyRequest) (BidStrategyResponse, error) {
+	return NewShouldBidResponse(), nil
+}
+
+func (s *PriceStrategy) ShouldBidBasedOnUsage(
+	_ context.Context, request BidStrategyRequest, resourceUsage model.ResourceUsageData) (BidStrategyResponse, error) {
+	budget := request.Job.Spec.Deal.MaxBudget
+	if budget <= 0 || s.pricing.IsZero() {
+		return NewShouldBidResponse(), nil
+	}
+	if cost := s.pricing.Cost(resourceUsage, request.Job.Spec.GetTimeout()); cost > budget {
+		return BidStrategyResponse{
+			ShouldBid: false,
+			Reason:    fmt.Sprintf("job would cost up to %g, more than its max budget of %g", cost, budget),
+		}, nil
+	}
+	return NewShouldBidResponse(), nil
+}
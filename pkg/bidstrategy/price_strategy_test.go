@@ -0,0 +1,49 @@
+//go:build unit || !integration
+
+package bidstrategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceStrategy(t *testing.T) {
+	pricing := model.Pricing{CPUSecond: 0.01, MemoryGBSecond: 0.001, GPUSecond: 1}
+	usage := model.ResourceUsageData{CPU: 2, Memory: 4 << 30}
+	tests := []struct {
+		name      string
+		pricing   model.Pricing
+		budget    float64
+		shouldBid bool
+		reason    string
+	}{
+		{name: "no-budget", pricing: pricing, shouldBid: true},
+		{name: "free-node", budget: 0.01, shouldBid: true},
+		{name: "within-budget", pricing: pricing, budget: 2.4, shouldBid: true},
+		{
+			name:      "over-budget",
+			pricing:   pricing,
+			budget:    2,
+			shouldBid: false,
+			reason:    "job would cost up to 2.4, more than its max budget of 2",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			subject := NewPriceStrategy(PriceStrategyParams{Pricing: test.pricing})
+			request := getBidStrategyRequest()
+			request.Job.Spec.Timeout = 100
+			request.Job.Spec.Deal.MaxBudget = test.budget
+
+			response, err := subject.ShouldBidBasedOnUsage(context.Background(), request, usage)
+			require.NoError(t, err)
+			assert.Equal(t, test.shouldBid, response.ShouldBid)
+			assert.Equal(t, test.reason, response.Reason)
+		})
+	}
+}
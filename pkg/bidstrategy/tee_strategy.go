@@ -0,0 +1,48 @@
+package bidstrategy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+type TEEStrategyParams struct {
+	// TEE is the kind of trusted execution environment that the node runs in,
+	// if any
+	TEE model.TEEType
+}
+
+// TEEStrategy only bids on jobs that require attested execution if the node
+// runs in the kind of TEE that they require.
+type TEEStrategy struct {
+	tee model.TEEType
+}
+
+func NewTEEStrategy(params TEEStrategyParams) *TEEStrategy {
+	return &TEEStrategy{
+		tee: params.TEE,
+	}
+}
+
+func (s *TEEStrategy) ShouldBid(_ context.Context, request BidStrategyRequest) (BidStrategyResponse, error) {
+	required := request.Job.Spec.TEE
+	if required == "" || s.tee.Satisfies(required) {
+		return NewShouldBidResponse(), nil
+	}
+	if s.tee == "" {
+		return BidStrategyResponse{ShouldBid: false, Reason: "job requires a TEE, and the node doesn't run in one"}, nil
+	}
+	return BidStrategyResponse{
+		ShouldBid: false,
+		Reason:    fmt.Sprintf("job requires a %s TEE, and the node runs in %s", required, s.tee),
+	}, nil
+}
+
+func (s *TEEStrategy) ShouldBidBasedOnUsage(
+	ctx context.Context, request BidStrategyRequest, _ model.ResourceUsageData) (BidStrategyResponse, error) {
+	return s.ShouldBid(ctx, request)
+}
+
+// compile-time interface check
+var _ BidStrategy = (*TEEStrategy)(nil)
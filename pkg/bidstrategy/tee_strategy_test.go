@@ -0,0 +1,39 @@
+//go:build unit || !integration
+
+package bidstrategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTEEStrategy(t *testing.T) {
+	tests := []struct {
+		name      string
+		tee       model.TEEType
+		required  model.TEEType
+		shouldBid bool
+	}{
+		{name: "not-required", shouldBid: true},
+		{name: "not-required-in-tee", tee: model.TEESEVSNP, shouldBid: true},
+		{name: "any-tee", tee: model.TEETDX, required: model.TEEAny, shouldBid: true},
+		{name: "matching-tee", tee: model.TEESEVSNP, required: model.TEESEVSNP, shouldBid: true},
+		{name: "other-tee", tee: model.TEETDX, required: model.TEESEVSNP, shouldBid: false},
+		{name: "no-tee", required: model.TEEAny, shouldBid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			subject := NewTEEStrategy(TEEStrategyParams{TEE: test.tee})
+			request := getBidStrategyRequest()
+			request.Job.Spec.TEE = test.required
+
+			response, err := subject.ShouldBid(context.Background(), request)
+			require.NoError(t, err)
+			require.Equal(t, test.shouldBid, response.ShouldBid, response.Reason)
+		})
+	}
+}
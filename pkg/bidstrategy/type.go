@@ -31,16 +31,18 @@ type BidStrategy interface {
 // the JSON data we send to http or exec probes
 // TODO: can we just use the BidStrategyRequest struct?
 type JobSelectionPolicyProbeData struct {
-	NodeID string     `json:"node_id"`
-	JobID  string     `json:"job_id"`
-	Spec   model.Spec `json:"spec"`
+	NodeID   string     `json:"node_id"`
+	JobID    string     `json:"job_id"`
+	ClientID string     `json:"client_id,omitempty"`
+	Spec     model.Spec `json:"spec"`
 }
 
 // Return JobSelectionPolicyProbeData for the given request
-func getJobSelectionPolicyProbeData(request BidStrategyRequest) JobSelectionPolicyProbeData {
+func GetJobSelectionPolicyProbeData(request BidStrategyRequest) JobSelectionPolicyProbeData {
 	return JobSelectionPolicyProbeData{
-		NodeID: request.NodeID,
-		JobID:  request.Job.Metadata.ID,
-		Spec:   request.Job.Spec,
+		NodeID:   request.NodeID,
+		JobID:    request.Job.Metadata.ID,
+		ClientID: request.Job.Metadata.ClientID,
+		Spec:     request.Job.Spec,
 	}
 }
@@ -0,0 +1,145 @@
+// Package compression compresses the results of jobs into a single tar before
+// they are published, and decompresses them when they are downloaded, so that
+// large outputs cost less to store and transfer.
+package compression
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/bacalhau-project/bacalhau/pkg/util/targzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	Gzip = "gzip"
+	Zstd = "zstd"
+
+	maxZstdLevel = 22
+)
+
+// resultsFilenames are what compressed results are published as.
+var resultsFilenames = map[string]string{
+	Gzip: "results.tar.gz",
+	Zstd: "results.tar.zst",
+}
+
+// Validate returns an error if the results can't be compressed as configured.
+func Validate(config model.CompressionConfig) error {
+	if config.Algorithm == "" {
+		if config.Level != 0 {
+			return fmt.Errorf("compression level %d needs a compression algorithm", config.Level)
+		}
+		return nil
+	}
+	maxLevel := map[string]int{Gzip: gzip.BestCompression, Zstd: maxZstdLevel}[config.Algorithm]
+	if maxLevel == 0 {
+		return fmt.Errorf("unknown compression algorithm %q, must be %s or %s", config.Algorithm, Gzip, Zstd)
+	}
+	if config.Level < 0 || config.Level > maxLevel {
+		return fmt.Errorf("%s compression level must be between 1 and %d", config.Algorithm, maxLevel)
+	}
+	return nil
+}
+
+// CompressResults writes the results in the result path as a tar compressed
+// as configured into the output path.
+func CompressResults(resultPath, outputPath string, config model.CompressionConfig) error {
+	if err := Validate(config); err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(outputPath, resultsFilenames[config.Algorithm]))
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError("compressed results", f)
+
+	w, err := newWriter(f, config)
+	if err != nil {
+		return err
+	}
+	if err = targzip.TarDirectory(w, resultPath); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func newWriter(w io.Writer, config model.CompressionConfig) (io.WriteCloser, error) {
+	if config.Algorithm == Zstd {
+		level := zstd.SpeedDefault
+		if config.Level != 0 {
+			level = zstd.EncoderLevelFromZstd(config.Level)
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(level))
+	}
+	level := gzip.DefaultCompression
+	if config.Level != 0 {
+		level = config.Level
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
+// compressedResults returns the algorithm and path of the compressed results
+// in the directory, if it only holds them.
+func compressedResults(dir string) (string, string, bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || !entries[0].Type().IsRegular() {
+		return "", "", false
+	}
+	for algorithm, name := range resultsFilenames {
+		if entries[0].Name() == name {
+			return algorithm, filepath.Join(dir, name), true
+		}
+	}
+	return "", "", false
+}
+
+// IsCompressed returns whether the downloaded results in the directory are
+// compressed.
+func IsCompressed(dir string) bool {
+	_, _, ok := compressedResults(dir)
+	return ok
+}
+
+// DecompressResults replaces the compressed results in the directory with the
+// results they were compressed from.
+func DecompressResults(dir string) error {
+	algorithm, path, ok := compressedResults(dir)
+	if !ok {
+		return fmt.Errorf("no compressed results in %s", dir)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	err = decompress(f, algorithm, dir)
+	closer.CloseWithLogOnError("compressed results", f)
+	if err != nil {
+		return fmt.Errorf("failed to decompress results: %w", err)
+	}
+	return os.Remove(path)
+}
+
+func decompress(r io.Reader, algorithm, dir string) error {
+	if algorithm == Zstd {
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		return targzip.UntarDirectory(zr, dir)
+	}
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	return targzip.UntarDirectory(zr, dir)
+}
@@ -0,0 +1,75 @@
+//go:build unit || !integration
+
+package compression
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressAndDecompressResults(t *testing.T) {
+	for _, config := range []model.CompressionConfig{
+		{Algorithm: Gzip},
+		{Algorithm: Gzip, Level: 9},
+		{Algorithm: Zstd},
+		{Algorithm: Zstd, Level: 19},
+	} {
+		config := config
+		t.Run(config.Algorithm, func(t *testing.T) {
+			text := strings.Repeat("a,b\n", 1000)
+			results := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(results, "stdout"), []byte("hello"), 0644))
+			require.NoError(t, os.MkdirAll(filepath.Join(results, "outputs"), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(results, "outputs", "data.csv"), []byte(text), 0644))
+
+			compressed := t.TempDir()
+			require.False(t, IsCompressed(compressed))
+			require.NoError(t, CompressResults(results, compressed, config))
+			require.True(t, IsCompressed(compressed))
+
+			info, err := os.Stat(filepath.Join(compressed, resultsFilenames[config.Algorithm]))
+			require.NoError(t, err)
+			require.Less(t, info.Size(), int64(len(text)))
+
+			require.NoError(t, DecompressResults(compressed))
+			require.False(t, IsCompressed(compressed))
+
+			stdout, err := os.ReadFile(filepath.Join(compressed, "stdout"))
+			require.NoError(t, err)
+			require.Equal(t, "hello", string(stdout))
+			data, err := os.ReadFile(filepath.Join(compressed, "outputs", "data.csv"))
+			require.NoError(t, err)
+			require.Equal(t, text, string(data))
+		})
+	}
+}
+
+func TestIsCompressedOnlyWithTheCompressedResults(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "results.tar.gz"), []byte("not results"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stdout"), []byte("hello"), 0644))
+	require.False(t, IsCompressed(dir), "results that happen to hold an archive aren't compressed")
+}
+
+func TestValidate(t *testing.T) {
+	for _, config := range []model.CompressionConfig{
+		{},
+		{Algorithm: Gzip, Level: 1},
+		{Algorithm: Zstd, Level: 22},
+	} {
+		require.NoError(t, Validate(config), config)
+	}
+	for _, config := range []model.CompressionConfig{
+		{Level: 1},
+		{Algorithm: "bzip2"},
+		{Algorithm: Gzip, Level: 10},
+		{Algorithm: Zstd, Level: -1},
+	} {
+		require.Error(t, Validate(config), config)
+	}
+}
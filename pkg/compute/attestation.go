@@ -0,0 +1,135 @@
+package compute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/attestation"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// DefaultTSMReportDir is where the Linux kernel's configfs-tsm interface
+// creates attestation reports for SEV-SNP and TDX guests.
+const DefaultTSMReportDir = "/sys/kernel/config/tsm/report"
+
+// Attester produces evidence from the hardware of the TEE that the node runs
+// in, which the node attaches to its bids on jobs that require one.
+type Attester interface {
+	// TEE is the kind of TEE that the node runs in
+	TEE() model.TEEType
+	// Attest returns evidence that signs the report data
+	Attest(ctx context.Context, reportData []byte) (*model.Attestation, error)
+}
+
+type TSMAttesterParams struct {
+	// TEE is the kind of TEE that the node runs in
+	TEE model.TEEType
+	// ReportDir is the configfs-tsm report directory, or DefaultTSMReportDir
+	// if empty
+	ReportDir string
+	// CertificatesFile is the PEM file of the VCEK, ASK and ARK of a SEV-SNP
+	// VM, leaf first, that are attached to its evidence if the kernel doesn't
+	// return them in the auxblob of the report
+	CertificatesFile string
+}
+
+// TSMAttester gets evidence from the TEE through the configfs-tsm interface of
+// the Linux kernel, which the sev_guest and tdx_guest drivers provide.
+type TSMAttester struct {
+	tee          model.TEEType
+	reportDir    string
+	certificates [][]byte
+	newEntry     func(dir string) (string, error)
+}
+
+func NewTSMAttester(params TSMAttesterParams) (*TSMAttester, error) {
+	reportDir := params.ReportDir
+	if reportDir == "" {
+		reportDir = DefaultTSMReportDir
+	}
+	if _, err := os.Stat(reportDir); err != nil {
+		return nil, fmt.Errorf("the node can't attest that it runs in a %s TEE: %w", params.TEE, err)
+	}
+	var certificates [][]byte
+	if params.CertificatesFile != "" {
+		var err error
+		certificates, err = attestation.LoadCertificates(params.CertificatesFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &TSMAttester{
+		tee:          params.TEE,
+		reportDir:    reportDir,
+		certificates: certificates,
+		newEntry: func(dir string) (string, error) {
+			return os.MkdirTemp(dir, "bacalhau-")
+		},
+	}, nil
+}
+
+func (a *TSMAttester) TEE() model.TEEType {
+	return a.tee
+}
+
+// Attest creates a report entry, writes the report data to its inblob and
+// reads the evidence that the TEE generates for it from its outblob. The
+// certificates of a SEV-SNP report are read from the auxblob, if the kernel
+// returns them there.
+func (a *TSMAttester) Attest(_ context.Context, reportData []byte) (*model.Attestation, error) {
+	entry, err := a.newEntry(a.reportDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attestation report: %w", err)
+	}
+	defer func() { _ = os.Remove(entry) }()
+
+	if err = os.WriteFile(filepath.Join(entry, "inblob"), reportData, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write attestation report data: %w", err)
+	}
+	evidence, err := os.ReadFile(filepath.Join(entry, "outblob"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attestation evidence: %w", err)
+	}
+	provider, err := os.ReadFile(filepath.Join(entry, "provider"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attestation provider: %w", err)
+	}
+	var certificates [][]byte
+	if a.tee == model.TEESEVSNP {
+		certificates, err = a.sevCertificates(entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &model.Attestation{
+		Type:         a.tee,
+		Provider:     strings.TrimSpace(string(provider)),
+		Evidence:     evidence,
+		Certificates: certificates,
+		ReportData:   reportData,
+	}, nil
+}
+
+// sevCertificates returns the VCEK, ASK and ARK that the attestation report
+// of a SEV-SNP VM is verified with, from the certificate table in the auxblob
+// of the report entry or else from the certificates file.
+func (a *TSMAttester) sevCertificates(entry string) ([][]byte, error) {
+	table, err := os.ReadFile(filepath.Join(entry, "auxblob"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read attestation certificates: %w", err)
+	}
+	if len(table) > 0 {
+		return attestation.ParseSEVCertTable(table)
+	}
+	if len(a.certificates) == 0 {
+		return nil, errors.New("there are no certificates to verify the attestation report with")
+	}
+	return a.certificates, nil
+}
+
+// compile-time interface check
+var _ Attester = (*TSMAttester)(nil)
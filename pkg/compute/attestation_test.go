@@ -0,0 +1,128 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// reportEntry returns a function that creates a report entry the way the
+// kernel would, with an outblob and the given auxblob, if any, up front.
+func reportEntry(evidence, auxblob []byte) func(dir string) (string, error) {
+	return func(dir string) (string, error) {
+		entry, err := os.MkdirTemp(dir, "report-")
+		if err != nil {
+			return "", err
+		}
+		if err = os.WriteFile(filepath.Join(entry, "outblob"), evidence, 0600); err != nil {
+			return "", err
+		}
+		if auxblob != nil {
+			if err = os.WriteFile(filepath.Join(entry, "auxblob"), auxblob, 0600); err != nil {
+				return "", err
+			}
+		}
+		return entry, os.WriteFile(filepath.Join(entry, "provider"), []byte("sev_guest\n"), 0600)
+	}
+}
+
+// sevCertTable returns a certificate table with the VCEK, ASK and ARK, as the
+// sev_guest driver returns it in the auxblob.
+func sevCertTable(vcek, ask, ark []byte) []byte {
+	entries := map[string][]byte{
+		"63da758d-e664-4564-adc5-f4b93be8accd": vcek,
+		"4ab7b379-bbac-4fe4-a02f-05aef327c782": ask,
+		"c0b406a4-a803-4952-9743-3fb6014cd0ae": ark,
+	}
+	offset := (len(entries) + 1) * 24
+	var header, data []byte
+	for guid, cert := range entries {
+		id := uuid.MustParse(guid)
+		header = append(header, id[:]...)
+		header = binary.LittleEndian.AppendUint32(header, uint32(offset+len(data)))
+		header = binary.LittleEndian.AppendUint32(header, uint32(len(cert)))
+		data = append(data, cert...)
+	}
+	header = append(header, make([]byte, 24)...)
+	return append(header, data...)
+}
+
+func selfSignedCertificatePEM(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "vcek"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestTSMAttester(t *testing.T) {
+	_, err := NewTSMAttester(TSMAttesterParams{TEE: model.TEESEVSNP, ReportDir: filepath.Join(t.TempDir(), "missing")})
+	require.Error(t, err, "the node can't attest without configfs-tsm")
+
+	attester, err := NewTSMAttester(TSMAttesterParams{TEE: model.TEESEVSNP, ReportDir: t.TempDir()})
+	require.NoError(t, err)
+	require.Equal(t, model.TEESEVSNP, attester.TEE())
+
+	// the kernel generates the outblob from the inblob, which the test does up front
+	reportData := model.AttestationReportData("job", "node")
+	evidence := append(make([]byte, 0x50), reportData...)
+	attester.newEntry = reportEntry(evidence, sevCertTable([]byte("vcek"), []byte("ask"), []byte("ark")))
+
+	attestation, err := attester.Attest(context.Background(), reportData)
+	require.NoError(t, err)
+	require.Equal(t, model.TEESEVSNP, attestation.Type)
+	require.Equal(t, "sev_guest", attestation.Provider)
+	require.Equal(t, evidence, attestation.Evidence)
+	require.Equal(t, reportData, attestation.ReportData)
+	require.Equal(t, [][]byte{[]byte("vcek"), []byte("ask"), []byte("ark")}, attestation.Certificates)
+
+	attester.newEntry = reportEntry(evidence, nil)
+	_, err = attester.Attest(context.Background(), reportData)
+	require.Error(t, err, "there are no certificates to verify the report with")
+}
+
+func TestTSMAttesterCertificatesFile(t *testing.T) {
+	_, err := NewTSMAttester(TSMAttesterParams{
+		TEE:              model.TEESEVSNP,
+		ReportDir:        t.TempDir(),
+		CertificatesFile: filepath.Join(t.TempDir(), "missing.pem"),
+	})
+	require.Error(t, err)
+
+	certificatesFile := filepath.Join(t.TempDir(), "certs.pem")
+	require.NoError(t, os.WriteFile(certificatesFile, selfSignedCertificatePEM(t), 0600))
+	attester, err := NewTSMAttester(TSMAttesterParams{
+		TEE:              model.TEESEVSNP,
+		ReportDir:        t.TempDir(),
+		CertificatesFile: certificatesFile,
+	})
+	require.NoError(t, err)
+
+	reportData := model.AttestationReportData("job", "node")
+	attester.newEntry = reportEntry(append(make([]byte, 0x50), reportData...), nil)
+	attestation, err := attester.Attest(context.Background(), reportData)
+	require.NoError(t, err)
+	require.Len(t, attestation.Certificates, 1)
+}
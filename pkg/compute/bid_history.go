@@ -0,0 +1,62 @@
+package compute
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultBidHistorySize is how many of its latest bid decisions the compute node keeps.
+const DefaultBidHistorySize = 100
+
+// BidDecision is whether the compute node bid on a job, and why not if it didn't.
+type BidDecision struct {
+	JobID    string `json:"JobID"`
+	ClientID string `json:"ClientID,omitempty"`
+	// ExecutionID is the execution the node created for the job if it bid
+	ExecutionID string    `json:"ExecutionID,omitempty"`
+	Bid         bool      `json:"Bid"`
+	Reason      string    `json:"Reason,omitempty"`
+	Time        time.Time `json:"Time"`
+}
+
+// BidHistory keeps the latest bid decisions of the compute node, for operators to see why it did or didn't bid on
+// jobs. A nil history keeps nothing.
+type BidHistory struct {
+	mu        sync.Mutex
+	decisions []BidDecision
+	// where the next decision goes once the history is full
+	next int
+}
+
+func NewBidHistory(size int) *BidHistory {
+	return &BidHistory{decisions: make([]BidDecision, 0, size)}
+}
+
+// Record keeps the decision, dropping the oldest one if the history is full.
+func (h *BidHistory) Record(decision BidDecision) {
+	if h == nil || cap(h.decisions) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.decisions) < cap(h.decisions) {
+		h.decisions = append(h.decisions, decision)
+		return
+	}
+	h.decisions[h.next] = decision
+	h.next = (h.next + 1) % len(h.decisions)
+}
+
+// Recent returns the decisions that are kept, latest first.
+func (h *BidHistory) Recent() []BidDecision {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	recent := make([]BidDecision, 0, len(h.decisions))
+	for i := 1; i <= len(h.decisions); i++ {
+		recent = append(recent, h.decisions[(h.next-i+len(h.decisions))%len(h.decisions)])
+	}
+	return recent
+}
@@ -0,0 +1,244 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"golang.org/x/exp/slices"
+)
+
+// TimeWindow is a time of the week that the compute node bids on jobs.
+type TimeWindow struct {
+	// Days are the days that the window starts on, or every day if empty
+	Days []time.Weekday
+	// Start and End are the times of day that the window starts and ends, from midnight. The window ends the next
+	// day if End isn't after Start, so it lasts all day if both are zero.
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseTimeWindow parses windows of days and times of day, such as "mon-fri 18:00-08:00" for weeknights,
+// "sat,sun" for weekends or "22:00-06:00" for every night. Days are the days that the window starts on.
+func ParseTimeWindow(window string) (TimeWindow, error) {
+	var parsed TimeWindow
+	fields := strings.Fields(strings.ToLower(window))
+	if len(fields) == 0 || len(fields) > 2 {
+		return parsed, fmt.Errorf("time window %q should be days, times or days and times", window)
+	}
+	for _, field := range fields {
+		var err error
+		if strings.Contains(field, ":") {
+			parsed.Start, parsed.End, err = parseTimesOfDay(field)
+		} else {
+			parsed.Days, err = parseDays(field)
+		}
+		if err != nil {
+			return parsed, fmt.Errorf("time window %q: %w", window, err)
+		}
+	}
+	return parsed, nil
+}
+
+// Contains returns whether the time is in the window, in the time's location.
+func (w TimeWindow) Contains(t time.Time) bool {
+	timeOfDay := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if w.Start < w.End {
+		return w.startsOn(t.Weekday()) && w.Start <= timeOfDay && timeOfDay < w.End
+	}
+	// the window ends the next day
+	yesterday := (t.Weekday() + 6) % 7
+	return (w.startsOn(t.Weekday()) && timeOfDay >= w.Start) || (w.startsOn(yesterday) && timeOfDay < w.End)
+}
+
+func (w TimeWindow) String() string {
+	var parts []string
+	if len(w.Days) > 0 {
+		days := make([]string, 0, len(w.Days))
+		for _, day := range w.Days {
+			days = append(days, dayName(day))
+		}
+		parts = append(parts, strings.Join(days, ","))
+	}
+	if w.Start != 0 || w.End != 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%s-%s", formatTimeOfDay(w.Start), formatTimeOfDay(w.End)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (w TimeWindow) startsOn(day time.Weekday) bool {
+	return len(w.Days) == 0 || slices.Contains(w.Days, day)
+}
+
+// parseDays parses days such as "mon", "sat,sun" or "mon-fri", where ranges can wrap around the week.
+func parseDays(field string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, item := range strings.Split(field, ",") {
+		from, to, isRange := strings.Cut(item, "-")
+		first, err := parseDay(from)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if isRange {
+			if last, err = parseDay(to); err != nil {
+				return nil, err
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			if !slices.Contains(days, day) {
+				days = append(days, day)
+			}
+			if day == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func parseDay(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if name == dayName(day) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown day %q, which should be one of mon, tue, wed, thu, fri, sat or sun", name)
+}
+
+func dayName(day time.Weekday) string {
+	return strings.ToLower(day.String()[:3])
+}
+
+// parseTimesOfDay parses times such as "18:00-08:00".
+func parseTimesOfDay(field string) (time.Duration, time.Duration, error) {
+	from, to, found := strings.Cut(field, "-")
+	if !found {
+		return 0, 0, fmt.Errorf("times %q should be a start and end such as 18:00-08:00", field)
+	}
+	start, err := parseTimeOfDay(from)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseTimeOfDay(to)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	hours, minutes, found := strings.Cut(value, ":")
+	h, hErr := strconv.Atoi(hours)
+	m, mErr := strconv.Atoi(minutes)
+	if !found || hErr != nil || mErr != nil || h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("time of day %q should be between 00:00 and 24:00", value)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+func formatTimeOfDay(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+type BiddingWindowsParams struct {
+	// Windows are the times that the node bids on jobs, or all the time if empty
+	Windows []TimeWindow
+	// Location is the time zone of the windows, which is the local one if nil
+	Location *time.Location
+	// Admins are the clients that can override the windows
+	Admins []string
+}
+
+// BiddingWindows only bid on jobs during the node's windows, such as nights and weekends when a workstation is idle.
+// Admins can override the windows to open or close bidding for a while.
+type BiddingWindows struct {
+	windows  []TimeWindow
+	location *time.Location
+	admins   []string
+	now      func() time.Time
+
+	mu       sync.Mutex
+	override *model.BiddingWindowOverride
+}
+
+func NewBiddingWindows(params BiddingWindowsParams) *BiddingWindows {
+	location := params.Location
+	if location == nil {
+		location = time.Local
+	}
+	return &BiddingWindows{
+		windows:  params.Windows,
+		location: location,
+		admins:   params.Admins,
+		now:      time.Now,
+	}
+}
+
+// IsAdmin returns whether the client can override the windows.
+func (b *BiddingWindows) IsAdmin(clientID string) bool {
+	return slices.Contains(b.admins, clientID)
+}
+
+// Override opens or closes bidding until the override ends, whatever the windows are, or follows the windows again
+// if the override is nil.
+func (b *BiddingWindows) Override(override *model.BiddingWindowOverride) model.BiddingWindowStatus {
+	b.mu.Lock()
+	b.override = override
+	b.mu.Unlock()
+	return b.Status()
+}
+
+// Status returns whether the node bids on jobs now.
+func (b *BiddingWindows) Status() model.BiddingWindowStatus {
+	now := b.now()
+	b.mu.Lock()
+	if b.override != nil && !b.override.Until.IsZero() && !now.Before(b.override.Until) {
+		b.override = nil
+	}
+	override := b.override
+	b.mu.Unlock()
+
+	status := model.BiddingWindowStatus{
+		Location: b.location.String(),
+		Override: override,
+	}
+	for _, window := range b.windows {
+		status.Windows = append(status.Windows, window.String())
+	}
+	if override != nil {
+		status.Open = override.Open
+		return status
+	}
+	status.Open = len(b.windows) == 0
+	for _, window := range b.windows {
+		status.Open = status.Open || window.Contains(now.In(b.location))
+	}
+	return status
+}
+
+// ShouldBid rejects all jobs outside of the windows.
+func (b *BiddingWindows) ShouldBid(context.Context, bidstrategy.BidStrategyRequest) (bidstrategy.BidStrategyResponse, error) {
+	status := b.Status()
+	if status.Open {
+		return bidstrategy.NewShouldBidResponse(), nil
+	}
+	reason := fmt.Sprintf("the node only bids during %s (%s)", strings.Join(status.Windows, ", "), status.Location)
+	if status.Override != nil {
+		reason = "the node's bidding has been closed by an admin"
+	}
+	return bidstrategy.BidStrategyResponse{ShouldBid: false, Reason: reason}, nil
+}
+
+func (b *BiddingWindows) ShouldBidBasedOnUsage(
+	ctx context.Context, request bidstrategy.BidStrategyRequest, _ model.ResourceUsageData) (bidstrategy.BidStrategyResponse, error) {
+	return b.ShouldBid(ctx, request)
+}
+
+// compile-time interface check
+var _ bidstrategy.BidStrategy = (*BiddingWindows)(nil)
@@ -0,0 +1,86 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeWindow(t *testing.T) {
+	for window, expected := range map[string]string{
+		"mon-fri 18:00-08:00": "mon,tue,wed,thu,fri 18:00-08:00",
+		"Sat,Sun":             "sat,sun",
+		"22:00-06:00":         "22:00-06:00",
+		"fri-mon":             "fri,sat,sun,mon",
+		"09:30-24:00 wed":     "wed 09:30-24:00",
+	} {
+		parsed, err := ParseTimeWindow(window)
+		require.NoError(t, err, window)
+		require.Equal(t, expected, parsed.String(), window)
+	}
+
+	for _, window := range []string{"", "weekdays", "mon 18:00", "25:00-01:00", "mon 1:00-2:00 extra"} {
+		_, err := ParseTimeWindow(window)
+		require.Error(t, err, window)
+	}
+}
+
+func TestTimeWindowContains(t *testing.T) {
+	weeknights, err := ParseTimeWindow("mon-fri 18:00-08:00")
+	require.NoError(t, err)
+	weekends, err := ParseTimeWindow("sat,sun")
+	require.NoError(t, err)
+
+	// 2023-05-01 is a monday
+	at := func(day int, hour int) time.Time { return time.Date(2023, 5, day, hour, 0, 0, 0, time.UTC) }
+	require.False(t, weeknights.Contains(at(1, 12)))
+	require.True(t, weeknights.Contains(at(1, 18)))
+	require.True(t, weeknights.Contains(at(2, 7)), "monday's window ends on tuesday")
+	require.True(t, weeknights.Contains(at(6, 7)), "friday's window ends on saturday")
+	require.False(t, weeknights.Contains(at(7, 7)), "there is no window on saturday")
+	require.False(t, weeknights.Contains(at(1, 7)), "there is no window on sunday")
+	require.True(t, weekends.Contains(at(6, 12)))
+	require.True(t, weekends.Contains(at(7, 23)))
+	require.False(t, weekends.Contains(at(8, 0)))
+}
+
+func TestBiddingWindows(t *testing.T) {
+	ctx := context.Background()
+	nights, err := ParseTimeWindow("22:00-06:00")
+	require.NoError(t, err)
+	location, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	windows := NewBiddingWindows(BiddingWindowsParams{Windows: []TimeWindow{nights}, Location: location})
+	now := time.Date(2023, 5, 1, 3, 0, 0, 0, time.UTC) // 23:00 in New York
+	windows.now = func() time.Time { return now }
+	shouldBid := func() bool {
+		response, err := windows.ShouldBid(ctx, bidstrategy.BidStrategyRequest{})
+		require.NoError(t, err)
+		return response.ShouldBid
+	}
+	require.True(t, shouldBid())
+
+	now = now.Add(12 * time.Hour)
+	require.False(t, shouldBid())
+
+	status := windows.Override(&model.BiddingWindowOverride{Open: true, Until: now.Add(time.Hour)})
+	require.True(t, status.Open)
+	require.True(t, shouldBid())
+
+	now = now.Add(time.Hour)
+	require.False(t, shouldBid(), "the override has ended")
+	require.Nil(t, windows.Status().Override)
+
+	windows.Override(&model.BiddingWindowOverride{Open: false})
+	now = now.Add(-12 * time.Hour)
+	require.False(t, shouldBid(), "an override without an end lasts until it is cleared")
+	windows.Override(nil)
+	require.True(t, shouldBid())
+}
@@ -0,0 +1,80 @@
+package bidstrategy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/c2h5oh/datasize"
+	"github.com/rs/zerolog/log"
+)
+
+type DiskSpaceStrategyParams struct {
+	// StoragePath is where inputs are prepared
+	StoragePath             string
+	EnqueuedCapacityTracker capacity.Tracker
+}
+
+// DiskSpaceStrategy skips bidding on jobs whose inputs wouldn't fit in the
+// disk space that is actually free, rather than the disk space that the node
+// is configured to have, as other processes and cached inputs use it too.
+type DiskSpaceStrategy struct {
+	storagePath             string
+	enqueuedCapacityTracker capacity.Tracker
+}
+
+func NewDiskSpaceStrategy(params DiskSpaceStrategyParams) *DiskSpaceStrategy {
+	return &DiskSpaceStrategy{
+		storagePath:             params.StoragePath,
+		enqueuedCapacityTracker: params.EnqueuedCapacityTracker,
+	}
+}
+
+func (s *DiskSpaceStrategy) ShouldBid(context.Context, bidstrategy.BidStrategyRequest) (bidstrategy.BidStrategyResponse, error) {
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+func (s *DiskSpaceStrategy) ShouldBidBasedOnUsage(
+	ctx context.Context, request bidstrategy.BidStrategyRequest, usage model.ResourceUsageData) (bidstrategy.BidStrategyResponse, error) {
+	if usage.Disk == 0 {
+		return bidstrategy.NewShouldBidResponse(), nil
+	}
+
+	space, err := util.DiskSpace(s.storagePath)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Unable to check free disk space before bidding")
+		return bidstrategy.NewShouldBidResponse(), nil
+	}
+
+	// executions that are waiting to run haven't used their disk space yet
+	free := space.Free
+	if enqueued := s.enqueuedDisk(ctx); enqueued < free {
+		free -= enqueued
+	} else {
+		free = 0
+	}
+
+	if usage.Disk > free {
+		return bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason: fmt.Sprintf("not enough free disk space: job needs %s but %s is free",
+				datasize.ByteSize(usage.Disk).HR(), datasize.ByteSize(free).HR()),
+		}, nil
+	}
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+func (s *DiskSpaceStrategy) enqueuedDisk(ctx context.Context) uint64 {
+	maxDisk := s.enqueuedCapacityTracker.GetMaxCapacity(ctx).Disk
+	availableDisk := s.enqueuedCapacityTracker.GetAvailableCapacity(ctx).Disk
+	if availableDisk >= maxDisk {
+		return 0
+	}
+	return maxDisk - availableDisk
+}
+
+// compile-time interface check
+var _ bidstrategy.BidStrategy = (*DiskSpaceStrategy)(nil)
@@ -0,0 +1,44 @@
+package bidstrategy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+type GPUStrategyParams struct {
+	// GPUs are the GPUs of the node
+	GPUs []model.GPU
+}
+
+// GPUStrategy only bids on jobs that require GPUs of a model or with enough
+// memory if the node has enough GPUs that match.
+type GPUStrategy struct {
+	gpus []model.GPU
+}
+
+func NewGPUStrategy(params GPUStrategyParams) *GPUStrategy {
+	return &GPUStrategy{
+		gpus: params.GPUs,
+	}
+}
+
+func (s *GPUStrategy) ShouldBid(_ context.Context, request bidstrategy.BidStrategyRequest) (bidstrategy.BidStrategyResponse, error) {
+	resources := request.Job.Spec.Resources
+	if !capacity.SatisfiesGPURequirements(resources, s.gpus) {
+		return bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason: fmt.Sprintf("node doesn't have %s GPUs of model %q with at least %q of memory",
+				resources.GPU, resources.GPUModel, resources.GPUMemory),
+		}, nil
+	}
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+func (s *GPUStrategy) ShouldBidBasedOnUsage(
+	context.Context, bidstrategy.BidStrategyRequest, model.ResourceUsageData) (bidstrategy.BidStrategyResponse, error) {
+	return bidstrategy.NewShouldBidResponse(), nil
+}
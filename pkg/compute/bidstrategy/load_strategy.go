@@ -0,0 +1,126 @@
+package bidstrategy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/rs/zerolog/log"
+)
+
+// LoadLimits are how loaded the node can be and still bid on jobs. Each limit
+// is disabled if zero.
+type LoadLimits struct {
+	// MaxExecutions is how many executions the node can be running or have
+	// accepted to run
+	MaxExecutions int
+	// MaxLoadAverage is the 1 minute load average of the host, per CPU
+	MaxLoadAverage float64
+	// MaxDiskUsage is the fraction of the disk of the storage path that is used
+	MaxDiskUsage float64
+}
+
+type LoadStrategyParams struct {
+	Limits LoadLimits
+	Store  store.ExecutionStore
+	// StoragePath is where inputs are prepared
+	StoragePath string
+}
+
+// LoadStrategy skips bidding on jobs while the node is loaded above its
+// limits, as the capacity that the node is configured to have doesn't account
+// for other processes on the host, or for executions using less or more than
+// they were allocated.
+type LoadStrategy struct {
+	limits      LoadLimits
+	store       store.ExecutionStore
+	storagePath string
+	loadAverage func() (float64, error)
+}
+
+func NewLoadStrategy(params LoadStrategyParams) *LoadStrategy {
+	return &LoadStrategy{
+		limits:      params.Limits,
+		store:       params.Store,
+		storagePath: params.StoragePath,
+		loadAverage: loadAverage,
+	}
+}
+
+func (s *LoadStrategy) ShouldBid(ctx context.Context, _ bidstrategy.BidStrategyRequest) (bidstrategy.BidStrategyResponse, error) {
+	if s.limits.MaxExecutions > 0 {
+		executions, err := s.store.GetActiveExecutions(ctx)
+		if err != nil {
+			return bidstrategy.BidStrategyResponse{}, err
+		}
+		accepted := 0
+		for _, execution := range executions {
+			if execution.State == store.ExecutionStateBidAccepted || execution.State == store.ExecutionStateRunning {
+				accepted++
+			}
+		}
+		if accepted >= s.limits.MaxExecutions {
+			return bidstrategy.BidStrategyResponse{
+				ShouldBid: false,
+				Reason:    fmt.Sprintf("node is running %d executions, which is its limit", accepted),
+			}, nil
+		}
+	}
+
+	if s.limits.MaxLoadAverage > 0 {
+		load, err := s.loadAverage()
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Unable to check the load average before bidding")
+		} else if perCPU := load / float64(runtime.NumCPU()); perCPU > s.limits.MaxLoadAverage {
+			return bidstrategy.BidStrategyResponse{
+				ShouldBid: false,
+				Reason: fmt.Sprintf("node load average is %.2f per CPU, above its limit of %.2f",
+					perCPU, s.limits.MaxLoadAverage),
+			}, nil
+		}
+	}
+
+	if s.limits.MaxDiskUsage > 0 {
+		space, err := util.DiskSpace(s.storagePath)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("Unable to check disk usage before bidding")
+		} else if used := float64(space.Total-space.Free) / float64(space.Total); used > s.limits.MaxDiskUsage {
+			return bidstrategy.BidStrategyResponse{
+				ShouldBid: false,
+				Reason: fmt.Sprintf("node disk is %.0f%% used, above its limit of %.0f%%",
+					used*100, s.limits.MaxDiskUsage*100),
+			}, nil
+		}
+	}
+
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+func (s *LoadStrategy) ShouldBidBasedOnUsage(
+	context.Context, bidstrategy.BidStrategyRequest, model.ResourceUsageData) (bidstrategy.BidStrategyResponse, error) {
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+// loadAverage returns the 1 minute load average of the host, which is only
+// known on Linux.
+func loadAverage() (float64, error) {
+	contents, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(contents))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("unexpected /proc/loadavg: %q", contents)
+	}
+	return strconv.ParseFloat(fields[0], 64)
+}
+
+// compile-time interface check
+var _ bidstrategy.BidStrategy = (*LoadStrategy)(nil)
@@ -0,0 +1,44 @@
+//go:build unit || !integration
+
+package bidstrategy
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadStrategy(t *testing.T) {
+	ctx := context.Background()
+	executionStore := inmemory.NewStore()
+	for _, state := range []store.ExecutionState{store.ExecutionStateCreated, store.ExecutionStateRunning} {
+		execution := store.NewExecution(state.String(), model.Job{}, "requester", model.ResourceUsageData{})
+		require.NoError(t, executionStore.CreateExecution(ctx, *execution))
+		require.NoError(t, executionStore.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+			ExecutionID: execution.ID,
+			NewState:    state,
+		}))
+	}
+
+	shouldBid := func(limits LoadLimits, load float64) bool {
+		strategy := NewLoadStrategy(LoadStrategyParams{Limits: limits, Store: executionStore, StoragePath: t.TempDir()})
+		strategy.loadAverage = func() (float64, error) { return load * float64(runtime.NumCPU()), nil }
+		response, err := strategy.ShouldBid(ctx, bidstrategy.BidStrategyRequest{})
+		require.NoError(t, err)
+		return response.ShouldBid
+	}
+
+	require.True(t, shouldBid(LoadLimits{}, 10), "no limits")
+	require.True(t, shouldBid(LoadLimits{MaxExecutions: 2}, 0), "executions that aren't accepted don't count")
+	require.False(t, shouldBid(LoadLimits{MaxExecutions: 1}, 0))
+	require.True(t, shouldBid(LoadLimits{MaxLoadAverage: 1.5}, 1))
+	require.False(t, shouldBid(LoadLimits{MaxLoadAverage: 1.5}, 2))
+	require.True(t, shouldBid(LoadLimits{MaxDiskUsage: 1}, 0))
+	require.False(t, shouldBid(LoadLimits{MaxDiskUsage: 0.0001}, 0), "any disk usage is above the limit")
+}
@@ -0,0 +1,106 @@
+package bidstrategy
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// randomPrecision is how many equally likely values the random numbers that
+// bids are decided by can take, which is the precision of a float64.
+const randomPrecision = 1 << 53
+
+type ProbabilityStrategyParams struct {
+	// Probability is the chance that the node bids on each job that it could
+	// run, between 0 and 1, or 1 if zero
+	Probability float64
+	// ScaleByLoad lowers the chance of bidding as the node uses more of its
+	// capacity, down to zero once it is full
+	ScaleByLoad bool
+	// RunningCapacityTracker is the capacity of the node that is in use
+	RunningCapacityTracker capacity.Tracker
+}
+
+// ProbabilityStrategy only bids on a share of the jobs that the node could
+// run, at random, so that in large networks every node doesn't race to bid on
+// every job and the requester gets a more diverse set of bids.
+type ProbabilityStrategy struct {
+	probability float64
+	scaleByLoad bool
+	tracker     capacity.Tracker
+	random      func() (float64, error)
+}
+
+func NewProbabilityStrategy(params ProbabilityStrategyParams) *ProbabilityStrategy {
+	probability := params.Probability
+	if probability == 0 {
+		probability = 1
+	}
+	return &ProbabilityStrategy{
+		probability: probability,
+		scaleByLoad: params.ScaleByLoad,
+		tracker:     params.RunningCapacityTracker,
+		random:      random,
+	}
+}
+
+func (s *ProbabilityStrategy) ShouldBid(context.Context, bidstrategy.BidStrategyRequest) (bidstrategy.BidStrategyResponse, error) {
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+// ShouldBidBasedOnUsage decides at random, once the other strategies have
+// found that the node could run the job.
+func (s *ProbabilityStrategy) ShouldBidBasedOnUsage(
+	ctx context.Context, _ bidstrategy.BidStrategyRequest, _ model.ResourceUsageData) (bidstrategy.BidStrategyResponse, error) {
+	probability := s.probability
+	if s.scaleByLoad && s.tracker != nil {
+		probability *= 1 - usedFraction(s.tracker.GetAvailableCapacity(ctx), s.tracker.GetMaxCapacity(ctx))
+	}
+	if probability >= 1 {
+		return bidstrategy.NewShouldBidResponse(), nil
+	}
+	value, err := s.random()
+	if err != nil {
+		return bidstrategy.BidStrategyResponse{}, err
+	}
+	if value >= probability {
+		return bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason:    fmt.Sprintf("the node only bids on %.0f%% of jobs, at random", probability*100), //nolint:gomnd
+		}, nil
+	}
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+// usedFraction returns the share of the resource that the node uses the most of.
+func usedFraction(available, total model.ResourceUsageData) float64 {
+	var used float64
+	for _, resource := range []struct{ available, total float64 }{
+		{available.CPU, total.CPU},
+		{float64(available.Memory), float64(total.Memory)},
+		{float64(available.GPU), float64(total.GPU)},
+	} {
+		if resource.total > 0 {
+			used = math.Max(used, 1-resource.available/resource.total)
+		}
+	}
+	return math.Min(math.Max(used, 0), 1)
+}
+
+// random returns a number between 0 and 1, excluding 1.
+func random() (float64, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(randomPrecision))
+	if err != nil {
+		return 0, err
+	}
+	return float64(n.Int64()) / randomPrecision, nil
+}
+
+// compile-time interface check
+var _ bidstrategy.BidStrategy = (*ProbabilityStrategy)(nil)
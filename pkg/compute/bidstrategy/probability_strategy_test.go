@@ -0,0 +1,49 @@
+//go:build unit || !integration
+
+package bidstrategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbabilityStrategy(t *testing.T) {
+	ctx := context.Background()
+	tracker := capacity.NewLocalTracker(capacity.LocalTrackerParams{
+		MaxCapacity: model.ResourceUsageData{CPU: 4, Memory: 1000},
+	})
+	shouldBid := func(strategy *ProbabilityStrategy, value float64) bidstrategy.BidStrategyResponse {
+		strategy.random = func() (float64, error) { return value, nil }
+		response, err := strategy.ShouldBidBasedOnUsage(ctx, bidstrategy.BidStrategyRequest{}, model.ResourceUsageData{})
+		require.NoError(t, err)
+		return response
+	}
+
+	always := NewProbabilityStrategy(ProbabilityStrategyParams{})
+	require.True(t, shouldBid(always, 0.99).ShouldBid)
+
+	half := NewProbabilityStrategy(ProbabilityStrategyParams{Probability: 0.5})
+	require.True(t, shouldBid(half, 0.4).ShouldBid)
+	response := shouldBid(half, 0.6)
+	require.False(t, response.ShouldBid)
+	require.Equal(t, "the node only bids on 50% of jobs, at random", response.Reason)
+
+	byLoad := NewProbabilityStrategy(ProbabilityStrategyParams{ScaleByLoad: true, RunningCapacityTracker: tracker})
+	require.True(t, shouldBid(byLoad, 0.99).ShouldBid, "an idle node always bids")
+	require.True(t, tracker.AddIfHasCapacity(ctx, model.ResourceUsageData{CPU: 1, Memory: 750}))
+	require.True(t, shouldBid(byLoad, 0.2).ShouldBid)
+	require.False(t, shouldBid(byLoad, 0.3).ShouldBid, "a node using 75% of its memory bids on 25% of jobs")
+}
+
+func TestRandom(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		value, err := random()
+		require.NoError(t, err)
+		require.True(t, value >= 0 && value < 1, value)
+	}
+}
@@ -0,0 +1,42 @@
+package bidstrategy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+type TaintsStrategyParams struct {
+	// Taints are the taints of the node
+	Taints []model.Taint
+}
+
+// TaintsStrategy only bids on jobs that tolerate the NoSchedule taints of the
+// node, so that nodes dedicated to some jobs don't run others.
+type TaintsStrategy struct {
+	taints []model.Taint
+}
+
+func NewTaintsStrategy(params TaintsStrategyParams) *TaintsStrategy {
+	return &TaintsStrategy{
+		taints: params.Taints,
+	}
+}
+
+func (s *TaintsStrategy) ShouldBid(_ context.Context, request bidstrategy.BidStrategyRequest) (bidstrategy.BidStrategyResponse, error) {
+	untolerated := model.UntoleratedTaints(s.taints, request.Job.Spec.Tolerations, model.TaintEffectNoSchedule)
+	if len(untolerated) > 0 {
+		return bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason:    fmt.Sprintf("job doesn't tolerate the node's taints %v", untolerated),
+		}, nil
+	}
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+func (s *TaintsStrategy) ShouldBidBasedOnUsage(
+	context.Context, bidstrategy.BidStrategyRequest, model.ResourceUsageData) (bidstrategy.BidStrategyResponse, error) {
+	return bidstrategy.NewShouldBidResponse(), nil
+}
@@ -0,0 +1,144 @@
+package bidstrategy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// WorkloadStrategyParams are the images and WASM modules that the node runs.
+// Patterns match the whole image or module, with * matching anything. Images
+// are matched as written in the job, fully qualified and in the short form
+// that Docker shows, so that ubuntu:* and docker.io/library/ubuntu:* both
+// match ubuntu:22.04 and docker.io/library/ubuntu:22.04. Modules are matched
+// by CID, or by URL if they don't have one.
+type WorkloadStrategyParams struct {
+	// AllowedImages are the only images that the node runs, unless empty
+	AllowedImages []string
+	// DeniedImages are the images that the node doesn't run, even if allowed
+	DeniedImages []string
+	// AllowedModules are the only WASM modules that the node runs, unless empty
+	AllowedModules []string
+	// DeniedModules are the WASM modules that the node doesn't run, even if allowed
+	DeniedModules []string
+}
+
+// WorkloadStrategy only bids on jobs that run the images and WASM modules that
+// the node allows, so that operators can restrict their node to a catalog of
+// workloads. Jobs of engines that run neither aren't restricted.
+type WorkloadStrategy struct {
+	allowedImages  []*regexp.Regexp
+	deniedImages   []*regexp.Regexp
+	allowedModules []*regexp.Regexp
+	deniedModules  []*regexp.Regexp
+}
+
+func NewWorkloadStrategy(params WorkloadStrategyParams) *WorkloadStrategy {
+	return &WorkloadStrategy{
+		allowedImages:  compilePatterns(params.AllowedImages),
+		deniedImages:   compilePatterns(params.DeniedImages),
+		allowedModules: compilePatterns(params.AllowedModules),
+		deniedModules:  compilePatterns(params.DeniedModules),
+	}
+}
+
+func (s *WorkloadStrategy) ShouldBid(_ context.Context, request bidstrategy.BidStrategyRequest) (bidstrategy.BidStrategyResponse, error) {
+	spec := request.Job.Spec
+	if image := spec.Docker.Image; image != "" {
+		qualified := qualifyImage(image)
+		names := []string{image, qualified, shortenImage(qualified)}
+		if reason := checkWorkload("image", image, names, s.allowedImages, s.deniedImages); reason != "" {
+			return bidstrategy.BidStrategyResponse{ShouldBid: false, Reason: reason}, nil
+		}
+	}
+
+	if spec.Engine == model.EngineWasm {
+		modules := append([]model.StorageSpec{spec.Wasm.EntryModule}, spec.Wasm.ImportModules...)
+		for _, module := range modules {
+			name := module.CID
+			if name == "" {
+				name = module.URL
+			}
+			if reason := checkWorkload("WASM module", name, []string{name}, s.allowedModules, s.deniedModules); reason != "" {
+				return bidstrategy.BidStrategyResponse{ShouldBid: false, Reason: reason}, nil
+			}
+		}
+	}
+
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+func (s *WorkloadStrategy) ShouldBidBasedOnUsage(
+	context.Context, bidstrategy.BidStrategyRequest, model.ResourceUsageData) (bidstrategy.BidStrategyResponse, error) {
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+// checkWorkload returns why the node doesn't run the workload, given the names
+// it is matched by, or nothing if it does.
+func checkWorkload(kind, workload string, names []string, allowed, denied []*regexp.Regexp) string {
+	if matchesAny(denied, names) {
+		return fmt.Sprintf("%s %s is denied on this node", kind, workload)
+	}
+	if len(allowed) > 0 && !matchesAny(allowed, names) {
+		return fmt.Sprintf("%s %s isn't allowed on this node", kind, workload)
+	}
+	return ""
+}
+
+func matchesAny(patterns []*regexp.Regexp, names []string) bool {
+	for _, pattern := range patterns {
+		for _, name := range names {
+			if pattern.MatchString(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compilePatterns turns patterns where * matches anything into expressions
+// that match the whole name.
+func compilePatterns(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		expression := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, `.*`)
+		compiled = append(compiled, regexp.MustCompile("^"+expression+"$"))
+	}
+	return compiled
+}
+
+// qualifyImage returns the image with the registry, repository and tag that
+// Docker assumes when they are left out, e.g. ubuntu becomes
+// docker.io/library/ubuntu:latest.
+func qualifyImage(image string) string {
+	name, digest, pinned := strings.Cut(image, "@")
+	if domain, _, found := strings.Cut(name, "/"); !found {
+		name = "docker.io/library/" + name
+	} else if !strings.ContainsAny(domain, ".:") && domain != "localhost" {
+		name = "docker.io/" + name
+	}
+	if pinned {
+		return name + "@" + digest
+	}
+	// a colon after the last slash separates the tag, rather than a registry port
+	if !strings.Contains(name[strings.LastIndex(name, "/")+1:], ":") {
+		name += ":latest"
+	}
+	return name
+}
+
+// shortenImage returns the qualified image without the registry and repository
+// that Docker assumes, e.g. docker.io/library/ubuntu:latest becomes ubuntu:latest.
+func shortenImage(qualified string) string {
+	if short, found := strings.CutPrefix(qualified, "docker.io/library/"); found {
+		return short
+	}
+	return strings.TrimPrefix(qualified, "docker.io/")
+}
+
+// compile-time interface check
+var _ bidstrategy.BidStrategy = (*WorkloadStrategy)(nil)
@@ -0,0 +1,58 @@
+//go:build unit || !integration
+
+package bidstrategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQualifyImage(t *testing.T) {
+	for image, expected := range map[string]string{
+		"ubuntu":                         "docker.io/library/ubuntu:latest",
+		"ubuntu:22.04":                   "docker.io/library/ubuntu:22.04",
+		"bacalhauproject/python:3.11":    "docker.io/bacalhauproject/python:3.11",
+		"ghcr.io/org/image":              "ghcr.io/org/image:latest",
+		"localhost:5000/image":           "localhost:5000/image:latest",
+		"ubuntu@sha256:abc":              "docker.io/library/ubuntu@sha256:abc",
+		"docker.io/library/ubuntu:22.04": "docker.io/library/ubuntu:22.04",
+	} {
+		require.Equal(t, expected, qualifyImage(image), image)
+	}
+}
+
+func TestWorkloadStrategy(t *testing.T) {
+	strategy := NewWorkloadStrategy(WorkloadStrategyParams{
+		AllowedImages:  []string{"ubuntu:*", "ghcr.io/org/*"},
+		DeniedImages:   []string{"ghcr.io/org/untrusted:*"},
+		AllowedModules: []string{"QmAllowed*"},
+	})
+	shouldBid := func(spec model.Spec) bool {
+		response, err := strategy.ShouldBid(context.Background(), bidstrategy.BidStrategyRequest{Job: model.Job{Spec: spec}})
+		require.NoError(t, err)
+		return response.ShouldBid
+	}
+	docker := func(image string) model.Spec {
+		return model.Spec{Engine: model.EngineDocker, Docker: model.JobSpecDocker{Image: image}}
+	}
+	wasm := func(cids ...string) model.Spec {
+		spec := model.Spec{Engine: model.EngineWasm, Wasm: model.JobSpecWasm{EntryModule: model.StorageSpec{CID: cids[0]}}}
+		for _, cid := range cids[1:] {
+			spec.Wasm.ImportModules = append(spec.Wasm.ImportModules, model.StorageSpec{CID: cid})
+		}
+		return spec
+	}
+
+	require.True(t, shouldBid(docker("ubuntu:22.04")))
+	require.True(t, shouldBid(docker("docker.io/library/ubuntu:22.04")))
+	require.True(t, shouldBid(docker("ghcr.io/org/image:1")))
+	require.False(t, shouldBid(docker("ghcr.io/org/untrusted:1")))
+	require.False(t, shouldBid(docker("alpine")))
+	require.True(t, shouldBid(wasm("QmAllowedEntry", "QmAllowedImport")))
+	require.False(t, shouldBid(wasm("QmAllowedEntry", "QmOther")))
+	require.True(t, shouldBid(model.Spec{Engine: model.EngineDuckDB}), "engines without images or modules aren't restricted")
+}
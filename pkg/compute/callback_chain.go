@@ -29,6 +29,12 @@ func (c ChainedCallback) OnPublishComplete(ctx context.Context, result PublishRe
 	}
 }
 
+func (c ChainedCallback) OnPublishPending(ctx context.Context, result PublishPendingResult) {
+	for _, callback := range c.callbacks {
+		callback.OnPublishPending(ctx, result)
+	}
+}
+
 func (c ChainedCallback) OnCancelComplete(ctx context.Context, result CancelResult) {
 	for _, callback := range c.callbacks {
 		callback.OnCancelComplete(ctx, result)
@@ -0,0 +1,157 @@
+package capacity
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// GPURequest is what an execution needs of the GPUs of the node.
+type GPURequest struct {
+	// Count is how many whole GPUs, or MIG slices if MIGProfile is set
+	Count uint64
+	// Fraction is the share of a single GPU, below 1, for executions that share
+	// a GPU rather than taking it whole
+	Fraction float64
+	// MIGProfile is the profile of the MIG slices, if the execution runs on slices
+	MIGProfile string
+	// Model is what the names of the GPUs must contain, case-insensitively
+	Model string
+	// MinMemory is the memory that each GPU or slice must have at least
+	MinMemory uint64
+}
+
+// ParseGPURequest returns what the resources require of the GPUs of the node.
+func ParseGPURequest(resources model.ResourceUsageConfig) GPURequest {
+	request := GPURequest{
+		Count:      ConvertGPUString(resources.GPU),
+		Fraction:   ConvertGPUFractionString(resources.GPU),
+		MIGProfile: strings.ToLower(strings.TrimSpace(resources.GPUMIGProfile)),
+		Model:      strings.ToLower(strings.TrimSpace(resources.GPUModel)),
+		MinMemory:  ConvertBytesString(resources.GPUMemory),
+	}
+	if request.MIGProfile != "" && request.Count == 0 {
+		request.Count = 1
+	}
+	return request
+}
+
+// IsZero returns whether no GPUs are requested.
+func (r GPURequest) IsZero() bool {
+	return r.Count == 0 && r.Fraction == 0
+}
+
+// ConvertGPUFractionString returns the share of a GPU that is requested, if it
+// is a fraction between 0 and 1 rather than a number of whole GPUs.
+func ConvertGPUFractionString(val string) float64 {
+	if !strings.Contains(val, ".") {
+		return 0
+	}
+	ret, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+	if err != nil || ret <= 0 || ret >= 1 {
+		return 0
+	}
+	return ret
+}
+
+// HasGPURequirements returns whether the resources require GPUs of a model,
+// with enough memory, shares of a GPU or MIG slices, rather than any whole GPUs.
+func HasGPURequirements(resources model.ResourceUsageConfig) bool {
+	return resources.GPUModel != "" || resources.GPUMemory != "" ||
+		resources.GPUMIGProfile != "" || ConvertGPUFractionString(resources.GPU) > 0
+}
+
+// MatchingGPUs returns the GPUs that are of the model and have at least the
+// memory that the resources require of each GPU.
+func MatchingGPUs(resources model.ResourceUsageConfig, gpus []model.GPU) []model.GPU {
+	request := ParseGPURequest(resources)
+	var result []model.GPU
+	for _, gpu := range gpus {
+		if request.matches(gpu) {
+			result = append(result, gpu)
+		}
+	}
+	return result
+}
+
+// SatisfiesGPURequirements returns whether the GPUs can satisfy what the
+// resources require of them when none of them are in use.
+func SatisfiesGPURequirements(resources model.ResourceUsageConfig, gpus []model.GPU) bool {
+	if !HasGPURequirements(resources) {
+		return true
+	}
+	_, ok := pickGPUs(gpus, nil, ParseGPURequest(resources))
+	return ok
+}
+
+func (r GPURequest) matches(gpu model.GPU) bool {
+	if r.Model != "" && !strings.Contains(strings.ToLower(gpu.Name), r.Model) {
+		return false
+	}
+	return gpu.Memory >= r.MinMemory
+}
+
+// gpuKey identifies a GPU or MIG slice among the GPUs of the node.
+func gpuKey(gpu model.GPU) string {
+	if gpu.UUID != "" {
+		return gpu.UUID
+	}
+	return strconv.Itoa(gpu.Index) + "/" + gpu.MIGProfile
+}
+
+// pickGPUs returns the GPUs or MIG slices that satisfy the request given the
+// share of each that is in use, or false if there aren't enough free. Whole
+// GPUs and slices are only given to one execution each, while executions that
+// request a fraction of a GPU share the GPU that is most in use that still
+// has room for them, to keep other GPUs whole. GPUs that are partitioned into
+// MIG slices are only given out as slices.
+func pickGPUs(gpus []model.GPU, used map[string]float64, request GPURequest) ([]model.GPU, bool) {
+	partitioned := make(map[int]bool)
+	for _, gpu := range gpus {
+		if gpu.MIGProfile != "" {
+			partitioned[gpu.Index] = true
+		}
+	}
+
+	var candidates []model.GPU
+	for _, gpu := range gpus {
+		if !request.matches(gpu) {
+			continue
+		}
+		if request.MIGProfile != "" {
+			if strings.EqualFold(gpu.MIGProfile, request.MIGProfile) && used[gpuKey(gpu)] == 0 {
+				candidates = append(candidates, gpu)
+			}
+			continue
+		}
+		if gpu.MIGProfile != "" || partitioned[gpu.Index] {
+			continue
+		}
+		if request.Fraction > 0 {
+			if used[gpuKey(gpu)]+request.Fraction <= 1+fractionTolerance {
+				candidates = append(candidates, gpu)
+			}
+		} else if used[gpuKey(gpu)] == 0 {
+			candidates = append(candidates, gpu)
+		}
+	}
+
+	if request.Fraction > 0 {
+		if len(candidates) == 0 {
+			return nil, false
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return used[gpuKey(candidates[i])] > used[gpuKey(candidates[j])]
+		})
+		return candidates[:1], true
+	}
+	if uint64(len(candidates)) < request.Count {
+		return nil, false
+	}
+	return candidates[:request.Count], true
+}
+
+// fractionTolerance lets shares such as three thirds fill a GPU despite rounding.
+const fractionTolerance = 1e-9
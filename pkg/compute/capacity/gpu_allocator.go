@@ -0,0 +1,95 @@
+package capacity
+
+import (
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	sync "github.com/bacalhau-project/golang-mutex-tracer"
+)
+
+type GPUAllocatorParams struct {
+	// GPUs are the GPUs and MIG slices of the node
+	GPUs []model.GPU
+}
+
+// GPUAllocator keeps track of which GPUs and MIG slices of the node each
+// execution runs on, so that executions that share a GPU never take more than
+// all of it. Whole GPUs are otherwise still counted by the Tracker, which is
+// all there is for nodes that don't describe their GPUs.
+type GPUAllocator struct {
+	gpus        []model.GPU
+	used        map[string]float64
+	allocations map[string]gpuAllocation
+	mu          sync.Mutex
+}
+
+type gpuAllocation struct {
+	gpus  []model.GPU
+	share float64
+}
+
+func NewGPUAllocator(params GPUAllocatorParams) *GPUAllocator {
+	return &GPUAllocator{
+		gpus:        params.GPUs,
+		used:        make(map[string]float64),
+		allocations: make(map[string]gpuAllocation),
+	}
+}
+
+// IsWithinLimits returns whether the request could be allocated if none of the GPUs were in use.
+func (a *GPUAllocator) IsWithinLimits(request GPURequest) bool {
+	if request.IsZero() {
+		return true
+	}
+	if len(a.gpus) == 0 {
+		return request.Fraction == 0 && request.MIGProfile == ""
+	}
+	_, ok := pickGPUs(a.gpus, nil, request)
+	return ok
+}
+
+// Allocate picks the GPUs or MIG slices that the execution runs on, and returns false if there aren't enough free.
+// Executions that don't request GPUs, or that request whole GPUs of a node that doesn't describe its GPUs, are
+// allocated none.
+func (a *GPUAllocator) Allocate(executionID string, request GPURequest) ([]model.GPU, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if allocation, ok := a.allocations[executionID]; ok {
+		return allocation.gpus, true
+	}
+	if request.IsZero() {
+		return nil, true
+	}
+	if len(a.gpus) == 0 {
+		return nil, request.Fraction == 0 && request.MIGProfile == ""
+	}
+	gpus, ok := pickGPUs(a.gpus, a.used, request)
+	if !ok {
+		return nil, false
+	}
+	share := request.Fraction
+	if share == 0 {
+		share = 1
+	}
+	for _, gpu := range gpus {
+		a.used[gpuKey(gpu)] += share
+	}
+	a.allocations[executionID] = gpuAllocation{gpus: gpus, share: share}
+	return gpus, true
+}
+
+// Release frees the GPUs that were allocated to the execution.
+func (a *GPUAllocator) Release(executionID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	allocation, ok := a.allocations[executionID]
+	if !ok {
+		return
+	}
+	for _, gpu := range allocation.gpus {
+		key := gpuKey(gpu)
+		a.used[key] -= allocation.share
+		if a.used[key] <= fractionTolerance {
+			delete(a.used, key)
+		}
+	}
+	delete(a.allocations, executionID)
+}
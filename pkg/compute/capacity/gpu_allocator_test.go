@@ -0,0 +1,73 @@
+//go:build unit || !integration
+
+package capacity
+
+import (
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGPUAllocatorSharesFractions(t *testing.T) {
+	allocator := NewGPUAllocator(GPUAllocatorParams{GPUs: []model.GPU{
+		{Index: 0, Name: "Tesla T4", Memory: 16 << 30, UUID: "GPU-0"},
+		{Index: 1, Name: "Tesla T4", Memory: 16 << 30, UUID: "GPU-1"},
+	}})
+	half := ParseGPURequest(model.ResourceUsageConfig{GPU: "0.5"})
+
+	first, ok := allocator.Allocate("first", half)
+	require.True(t, ok)
+	second, ok := allocator.Allocate("second", half)
+	require.True(t, ok)
+	require.Equal(t, first, second, "halves share a GPU to keep the other one whole")
+
+	whole, ok := allocator.Allocate("whole", ParseGPURequest(model.ResourceUsageConfig{GPU: "1"}))
+	require.True(t, ok)
+	require.NotEqual(t, first, whole)
+
+	_, ok = allocator.Allocate("third", half)
+	require.False(t, ok, "both GPUs are fully in use")
+
+	allocator.Release("first")
+	third, ok := allocator.Allocate("third", half)
+	require.True(t, ok)
+	require.Equal(t, first, third)
+}
+
+func TestGPUAllocatorMIGSlices(t *testing.T) {
+	allocator := NewGPUAllocator(GPUAllocatorParams{GPUs: []model.GPU{
+		{Index: 0, Name: "NVIDIA A100-SXM4-40GB", Memory: 40 << 30, UUID: "GPU-0"},
+		{Index: 0, Name: "NVIDIA A100-SXM4-40GB", Memory: 5 << 30, UUID: "MIG-0", MIGProfile: "1g.5gb"},
+		{Index: 0, Name: "NVIDIA A100-SXM4-40GB", Memory: 5 << 30, UUID: "MIG-1", MIGProfile: "1g.5gb"},
+		{Index: 1, Name: "NVIDIA A100-SXM4-40GB", Memory: 40 << 30, UUID: "GPU-1"},
+	}})
+	slice := ParseGPURequest(model.ResourceUsageConfig{GPUMIGProfile: "1g.5gb"})
+
+	first, ok := allocator.Allocate("first", slice)
+	require.True(t, ok)
+	second, ok := allocator.Allocate("second", slice)
+	require.True(t, ok)
+	require.NotEqual(t, first[0].UUID, second[0].UUID)
+	_, ok = allocator.Allocate("third", slice)
+	require.False(t, ok)
+
+	// the partitioned GPU is only given out as slices
+	whole, ok := allocator.Allocate("whole", ParseGPURequest(model.ResourceUsageConfig{GPU: "1"}))
+	require.True(t, ok)
+	require.Equal(t, "GPU-1", whole[0].UUID)
+	_, ok = allocator.Allocate("fraction", ParseGPURequest(model.ResourceUsageConfig{GPU: "0.5"}))
+	require.False(t, ok)
+
+	require.False(t, allocator.IsWithinLimits(ParseGPURequest(model.ResourceUsageConfig{GPUMIGProfile: "2g.10gb"})))
+}
+
+func TestGPUAllocatorWithoutGPUs(t *testing.T) {
+	allocator := NewGPUAllocator(GPUAllocatorParams{})
+
+	gpus, ok := allocator.Allocate("whole", ParseGPURequest(model.ResourceUsageConfig{GPU: "2"}))
+	require.True(t, ok, "whole GPUs are only counted by the tracker")
+	require.Empty(t, gpus)
+	_, ok = allocator.Allocate("fraction", ParseGPURequest(model.ResourceUsageConfig{GPU: "0.5"}))
+	require.False(t, ok)
+}
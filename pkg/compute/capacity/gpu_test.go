@@ -0,0 +1,31 @@
+//go:build unit || !integration
+
+package capacity
+
+import (
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSatisfiesGPURequirements(t *testing.T) {
+	gpus := []model.GPU{
+		{Index: 0, Name: "NVIDIA A100-SXM4-80GB", Memory: 80 << 30},
+		{Index: 1, Name: "NVIDIA A100-PCIE-40GB", Memory: 40 << 30},
+		{Index: 2, Name: "Tesla T4", Memory: 16 << 30},
+	}
+	for _, test := range []struct {
+		resources model.ResourceUsageConfig
+		satisfied bool
+	}{
+		{resources: model.ResourceUsageConfig{GPU: "3"}, satisfied: true},
+		{resources: model.ResourceUsageConfig{GPU: "2", GPUModel: "a100"}, satisfied: true},
+		{resources: model.ResourceUsageConfig{GPU: "3", GPUModel: "A100"}, satisfied: false},
+		{resources: model.ResourceUsageConfig{GPU: "1", GPUModel: "A100", GPUMemory: "80Gi"}, satisfied: true},
+		{resources: model.ResourceUsageConfig{GPU: "2", GPUMemory: "40Gb"}, satisfied: true},
+		{resources: model.ResourceUsageConfig{GPU: "1", GPUModel: "H100"}, satisfied: false},
+	} {
+		require.Equal(t, test.satisfied, SatisfiesGPURequirements(test.resources, gpus), "%+v", test.resources)
+	}
+}
@@ -41,6 +41,14 @@ func ConvertGPUString(val string) uint64 {
 	return ret
 }
 
+func ConvertIOPSString(val string) uint64 {
+	ret, err := strconv.ParseUint(val, 10, 64) //nolint:gomnd
+	if err != nil {
+		return 0
+	}
+	return ret
+}
+
 func convertCPUStringWithError(val string) (float64, error) {
 	if val == "" {
 		return 0, nil
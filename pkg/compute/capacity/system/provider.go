@@ -2,14 +2,17 @@ package system
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
 	"github.com/bacalhau-project/bacalhau/pkg/config"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/c2h5oh/datasize"
 	"github.com/pbnjay/memory"
 	"github.com/ricochet2200/go-disk-usage/du"
 )
@@ -17,6 +20,9 @@ import (
 // NvidiaCLI is the path to the Nvidia helper binary
 const NvidiaCLI = "nvidia-container-cli"
 
+// NvidiaSMI is the path to the Nvidia binary that describes the GPUs
+const NvidiaSMI = "nvidia-smi"
+
 type PhysicalCapacityProvider struct {
 }
 
@@ -94,5 +100,119 @@ func numSystemGPUs() (uint64, error) {
 	return numDevices, nil
 }
 
+// GetGPUs wraps nvidia-smi to get the model, memory and UUID of each GPU and
+// of the MIG slices they are partitioned into, which is empty if nvidia-smi is
+// not installed.
+func (p *PhysicalCapacityProvider) GetGPUs(ctx context.Context) ([]model.GPU, error) {
+	nvidiaPath, err := exec.LookPath(NvidiaSMI)
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	resp, err := exec.CommandContext(ctx, nvidiaPath,
+		"--query-gpu=index,name,memory.total,uuid", "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return nil, err
+	}
+	gpus, err := parseGPUs(string(resp))
+	if err != nil {
+		return nil, err
+	}
+	list, err := exec.CommandContext(ctx, nvidiaPath, "-L").Output()
+	if err != nil {
+		// the whole GPUs can still be allocated without their MIG slices
+		return gpus, nil
+	}
+	return append(gpus, parseMIGSlices(string(list), gpus)...), nil
+}
+
+// parseGPUs parses the csv that nvidia-smi describes the GPUs with, which has
+// the memory of each GPU in MiB, and optionally its UUID.
+func parseGPUs(output string) ([]model.GPU, error) {
+	var gpus []model.GPU
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) != 3 && len(fields) != 4 { //nolint:gomnd // index, name, memory and uuid
+			return nil, fmt.Errorf("unexpected GPU description %q", line)
+		}
+		index, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid GPU index in %q: %w", line, err)
+		}
+		memoryMiB, err := strconv.ParseUint(strings.TrimSpace(fields[2]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GPU memory in %q: %w", line, err)
+		}
+		gpu := model.GPU{
+			Index:  index,
+			Name:   strings.TrimSpace(fields[1]),
+			Memory: memoryMiB * uint64(datasize.MB),
+		}
+		if len(fields) == 4 { //nolint:gomnd
+			gpu.UUID = strings.TrimSpace(fields[3])
+		}
+		gpus = append(gpus, gpu)
+	}
+	return gpus, nil
+}
+
+// parseMIGSlices parses the MIG slices out of the list of devices that
+// nvidia-smi -L describes, where each slice is listed under its GPU as in
+//
+//	GPU 0: NVIDIA A100-SXM4-40GB (UUID: GPU-5d5ba0d6-...)
+//	  MIG 1g.5gb      Device  0: (UUID: MIG-c6d4f1ef-...)
+//
+// The slices have the name of their GPU and the memory of their profile.
+func parseMIGSlices(output string, gpus []model.GPU) []model.GPU {
+	var slices []model.GPU
+	parent := -1
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		fields := strings.Fields(line)
+		if len(fields) < 2 { //nolint:gomnd
+			continue
+		}
+		if fields[0] == "GPU" {
+			index, err := strconv.Atoi(strings.TrimSuffix(fields[1], ":"))
+			if err != nil {
+				parent = -1
+				continue
+			}
+			parent = index
+			continue
+		}
+		if fields[0] != "MIG" || parent < 0 {
+			continue
+		}
+		_, uuid, found := strings.Cut(line, "UUID: ")
+		if !found {
+			continue
+		}
+		slice := model.GPU{
+			Index:      parent,
+			MIGProfile: fields[1],
+			UUID:       strings.TrimSuffix(strings.TrimSpace(uuid), ")"),
+		}
+		for _, gpu := range gpus {
+			if gpu.Index == parent {
+				slice.Name = gpu.Name
+			}
+		}
+		// the profile ends with the memory of the slice, e.g. 5gb in 1g.5gb
+		if _, memory, ok := strings.Cut(slice.MIGProfile, "."); ok {
+			if size, err := datasize.ParseString(memory); err == nil {
+				slice.Memory = size.Bytes()
+			}
+		}
+		slices = append(slices, slice)
+	}
+	return slices
+}
+
 // compile-time check that the provider implements the interface
-var _ capacity.Provider = (*PhysicalCapacityProvider)(nil)
+var _ capacity.GPUProvider = (*PhysicalCapacityProvider)(nil)
@@ -0,0 +1,38 @@
+//go:build unit || !integration
+
+package system
+
+import (
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGPUs(t *testing.T) {
+	gpus, err := parseGPUs("0, NVIDIA A100-SXM4-80GB, 81920\n1, Tesla T4, 15360\n")
+	require.NoError(t, err)
+	require.Equal(t, []model.GPU{
+		{Index: 0, Name: "NVIDIA A100-SXM4-80GB", Memory: 80 << 30},
+		{Index: 1, Name: "Tesla T4", Memory: 15 << 30},
+	}, gpus)
+
+	_, err = parseGPUs("0, Tesla T4\n")
+	require.Error(t, err)
+}
+
+func TestParseMIGSlices(t *testing.T) {
+	gpus, err := parseGPUs("0, NVIDIA A100-SXM4-40GB, 40960, GPU-5d5ba0d6\n1, Tesla T4, 15360, GPU-e91edf3b\n")
+	require.NoError(t, err)
+	require.Equal(t, "GPU-5d5ba0d6", gpus[0].UUID)
+
+	slices := parseMIGSlices(`GPU 0: NVIDIA A100-SXM4-40GB (UUID: GPU-5d5ba0d6)
+  MIG 1g.5gb      Device  0: (UUID: MIG-c6d4f1ef)
+  MIG 2g.10gb     Device  1: (UUID: MIG-1bcb4e62)
+GPU 1: Tesla T4 (UUID: GPU-e91edf3b)
+`, gpus)
+	require.Equal(t, []model.GPU{
+		{Index: 0, Name: "NVIDIA A100-SXM4-40GB", Memory: 5 << 30, UUID: "MIG-c6d4f1ef", MIGProfile: "1g.5gb"},
+		{Index: 0, Name: "NVIDIA A100-SXM4-40GB", Memory: 10 << 30, UUID: "MIG-1bcb4e62", MIGProfile: "2g.10gb"},
+	}, slices)
+}
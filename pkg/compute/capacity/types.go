@@ -35,3 +35,10 @@ type UsageCalculator interface {
 type Provider interface {
 	GetAvailableCapacity(ctx context.Context) (model.ResourceUsageData, error)
 }
+
+// GPUProvider is a Provider that can also describe the model and memory of
+// each GPU of the compute node.
+type GPUProvider interface {
+	Provider
+	GetGPUs(ctx context.Context) ([]model.GPU, error)
+}
@@ -0,0 +1,114 @@
+package compute
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// CheckpointOptions configure how the executions of executors that can checkpoint jobs are checkpointed, so that
+// they resume from their last checkpoint rather than start over when they run again after the node restarts, or on
+// another node that shares the checkpoint directory.
+type CheckpointOptions struct {
+	// Interval is how often running executions are checkpointed, or never if zero.
+	Interval time.Duration
+	// Dir is where the checkpoints are kept, one for each job.
+	Dir string
+}
+
+// checkpoints returns whether the executions of the job are checkpointed. Jobs
+// with more than one execution, such as those replicated for verification,
+// aren't, as their executions would share the checkpoint of the job and
+// resume from each other's state rather than run independently.
+func (o CheckpointOptions) checkpoints(job model.Job) bool {
+	return o.Interval > 0 && o.Dir != "" && job.Spec.Deal.Concurrency <= 1
+}
+
+// checkpointDir is where the last checkpoint of the job is kept.
+func (o CheckpointOptions) checkpointDir(job model.Job) string {
+	return filepath.Join(o.Dir, job.ID())
+}
+
+// checkpointPeriodically checkpoints the running job every interval until the context is done.
+func (o CheckpointOptions) checkpointPeriodically(
+	ctx context.Context, checkpointer executor.Checkpointer, job model.Job, resultsDir string) {
+	ticker := time.NewTicker(o.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := o.checkpoint(ctx, checkpointer, job, resultsDir); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("failed to checkpoint execution")
+			}
+		}
+	}
+}
+
+// checkpoint writes a new checkpoint of the job next to the last one, and only replaces the last one once it is
+// complete, so that a checkpoint that fails doesn't lose the last one.
+func (o CheckpointOptions) checkpoint(
+	ctx context.Context, checkpointer executor.Checkpointer, job model.Job, resultsDir string) error {
+	dir := o.checkpointDir(job)
+	next := dir + ".next"
+	if err := os.RemoveAll(next); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(next, os.ModePerm); err != nil {
+		return err
+	}
+	if err := checkpointer.Checkpoint(ctx, job, resultsDir, next); err != nil {
+		_ = os.RemoveAll(next)
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.Rename(next, dir)
+}
+
+// runCheckpointed runs the job from its last checkpoint if it has one, and checkpoints it while it runs. The
+// checkpoint is removed once the execution finishes or is cancelled, and kept if it is stopped otherwise, such as by
+// the node shutting down or the execution timing out, for the job to resume from when it runs again.
+func (e *BaseExecutor) runCheckpointed(
+	ctx context.Context,
+	executionID string,
+	jobExecutor executor.Executor,
+	checkpointer executor.Checkpointer,
+	job model.Job,
+	resultFolder string,
+) (*model.RunCommandResult, error) {
+	dir := e.checkpoint.checkpointDir(job)
+	checkpointCtx, stopCheckpoints := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		e.checkpoint.checkpointPeriodically(checkpointCtx, checkpointer, job, resultFolder)
+	}()
+
+	var result *model.RunCommandResult
+	var err error
+	if _, statErr := os.Stat(dir); statErr == nil {
+		log.Ctx(ctx).Info().Msgf("Resuming execution from checkpoint %s", dir)
+		result, err = checkpointer.Resume(ctx, job, dir, resultFolder)
+	} else {
+		result, err = jobExecutor.Run(ctx, job, resultFolder)
+	}
+	stopCheckpoints()
+	<-stopped
+
+	// executions that are cancelled are no longer among the cancellers
+	_, running := e.cancellers.Get(executionID)
+	if ctx.Err() == nil || !running {
+		if removeErr := os.RemoveAll(dir); removeErr != nil {
+			log.Ctx(ctx).Warn().Err(removeErr).Msgf("failed to remove checkpoint %s", dir)
+		}
+	}
+	return result, err
+}
@@ -0,0 +1,109 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCheckpointer runs until it is stopped, checkpointing how long it has run.
+type fakeCheckpointer struct {
+	executor.Executor
+	checkpointed chan string
+	resumedFrom  chan string
+	stop         chan struct{}
+}
+
+func (f fakeCheckpointer) Run(ctx context.Context, _ model.Job, _ string) (*model.RunCommandResult, error) {
+	select {
+	case <-f.stop:
+		return &model.RunCommandResult{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f fakeCheckpointer) Checkpoint(_ context.Context, _ model.Job, _ string, checkpointDir string) error {
+	if err := os.WriteFile(filepath.Join(checkpointDir, "state"), []byte("state"), 0600); err != nil {
+		return err
+	}
+	select {
+	case f.checkpointed <- checkpointDir:
+	default:
+	}
+	return nil
+}
+
+func (f fakeCheckpointer) Resume(ctx context.Context, job model.Job, checkpointDir string, resultsDir string) (*model.RunCommandResult, error) {
+	f.resumedFrom <- checkpointDir
+	return f.Run(ctx, job, resultsDir)
+}
+
+func TestRunCheckpointedKeepsCheckpointOfStoppedExecution(t *testing.T) {
+	options := CheckpointOptions{Interval: 10 * time.Millisecond, Dir: t.TempDir()}
+	e := NewBaseExecutor(BaseExecutorParams{Checkpoint: options})
+	fake := fakeCheckpointer{
+		checkpointed: make(chan string, 1),
+		resumedFrom:  make(chan string, 1),
+		stop:         make(chan struct{}),
+	}
+	job := model.Job{Metadata: model.Metadata{ID: "job"}}
+	checkpointDir := options.checkpointDir(job)
+
+	// the node stops the execution, e.g. when it shuts down, after it was checkpointed
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancellers.Put("first", cancel)
+	go func() {
+		<-fake.checkpointed
+		cancel()
+	}()
+	_, err := e.runCheckpointed(ctx, "first", fake, fake, job, t.TempDir())
+	require.Error(t, err)
+	require.FileExists(t, filepath.Join(checkpointDir, "state"))
+
+	// the job resumes from the checkpoint when it runs again, which is removed once it finishes
+	e.cancellers.Put("second", func() {})
+	close(fake.stop)
+	_, err = e.runCheckpointed(context.Background(), "second", fake, fake, job, t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, checkpointDir, <-fake.resumedFrom)
+	require.NoDirExists(t, checkpointDir)
+}
+
+func TestRunCheckpointedRemovesCheckpointOfCancelledExecution(t *testing.T) {
+	options := CheckpointOptions{Interval: 10 * time.Millisecond, Dir: t.TempDir()}
+	e := NewBaseExecutor(BaseExecutorParams{Checkpoint: options})
+	fake := fakeCheckpointer{
+		checkpointed: make(chan string, 1),
+		resumedFrom:  make(chan string, 1),
+		stop:         make(chan struct{}),
+	}
+	job := model.Job{Metadata: model.Metadata{ID: "job"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-fake.checkpointed
+		// cancelled executions are taken out of the cancellers before they are cancelled
+		cancel()
+	}()
+	_, err := e.runCheckpointed(ctx, "cancelled", fake, fake, job, t.TempDir())
+	require.Error(t, err)
+	require.NoDirExists(t, options.checkpointDir(job))
+}
+
+func TestCheckpointsOnlyJobsWithOneExecution(t *testing.T) {
+	options := CheckpointOptions{Interval: time.Minute, Dir: t.TempDir()}
+	require.True(t, options.checkpoints(model.Job{}))
+	require.True(t, options.checkpoints(model.Job{Spec: model.Spec{Deal: model.Deal{Concurrency: 1}}}))
+	require.False(t, options.checkpoints(model.Job{Spec: model.Spec{Deal: model.Deal{Concurrency: 3}}}),
+		"the executions would resume from each other's checkpoints")
+	require.False(t, CheckpointOptions{Dir: t.TempDir()}.checkpoints(model.Job{}), "checkpoints are disabled")
+}
@@ -0,0 +1,127 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultClientReputationHalfLife is how long it takes for the executions of clients to count half as much in their
+// reputations by default.
+const DefaultClientReputationHalfLife = 24 * time.Hour
+
+type ClientReputationsParams struct {
+	// HalfLife is how long it takes for executions to count half as much in the reputations of their clients
+	HalfLife time.Duration
+	// RejectAbuses is how many recent abuses of the node make it reject the jobs of a client, or never if zero
+	RejectAbuses float64
+	// DelayPerAbuse is how long the node waits before bidding on the jobs of a client for each of its recent abuses,
+	// so that other nodes get to bid first, or not at all if zero
+	DelayPerAbuse time.Duration
+	// MaxDelay is the longest that the node waits before bidding on the jobs of a client, or no limit if zero
+	MaxDelay time.Duration
+}
+
+// ClientReputations keep track of how the jobs of each client have treated the node: whether they were cancelled while
+// running, failed verification or used more resources than they declared. The node delays its bids on the jobs of
+// clients that abuse it, and rejects them once they have abused it too often. Nil reputations keep nothing.
+type ClientReputations struct {
+	halfLife      time.Duration
+	rejectAbuses  float64
+	delayPerAbuse time.Duration
+	maxDelay      time.Duration
+	now           func() time.Time
+
+	mu          sync.Mutex
+	reputations map[string]model.ClientReputation
+}
+
+func NewClientReputations(params ClientReputationsParams) *ClientReputations {
+	halfLife := params.HalfLife
+	if halfLife == 0 {
+		halfLife = DefaultClientReputationHalfLife
+	}
+	return &ClientReputations{
+		halfLife:      halfLife,
+		rejectAbuses:  params.RejectAbuses,
+		delayPerAbuse: params.DelayPerAbuse,
+		maxDelay:      params.MaxDelay,
+		now:           time.Now,
+		reputations:   make(map[string]model.ClientReputation),
+	}
+}
+
+// Record counts how an execution of the job ended in the reputation of its client.
+func (c *ClientReputations) Record(ctx context.Context, job model.Job, outcome model.ClientOutcome) {
+	if c == nil || job.Metadata.ClientID == "" {
+		return
+	}
+	log.Ctx(ctx).Debug().Msgf("recording %s outcome of job %s for client %s", outcome, job.ID(), job.Metadata.ClientID)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	reputation := c.reputations[job.Metadata.ClientID]
+	reputation.ClientID = job.Metadata.ClientID
+	c.reputations[job.Metadata.ClientID] = reputation.Record(outcome, c.now(), c.halfLife)
+}
+
+// Get returns the reputation of the client, decayed to now.
+func (c *ClientReputations) Get(clientID string) model.ClientReputation {
+	if c == nil {
+		return model.ClientReputation{ClientID: clientID}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	reputation, ok := c.reputations[clientID]
+	if !ok {
+		return model.ClientReputation{ClientID: clientID}
+	}
+	return reputation.Decay(c.now(), c.halfLife)
+}
+
+// delay returns how long to wait before bidding on the jobs of a client with the reputation.
+func (c *ClientReputations) delay(reputation model.ClientReputation) time.Duration {
+	delay := time.Duration(reputation.Abuses() * float64(c.delayPerAbuse))
+	if c.maxDelay > 0 && delay > c.maxDelay {
+		delay = c.maxDelay
+	}
+	return delay
+}
+
+// ShouldBid rejects the jobs of clients that have abused the node too often recently, and delays bidding on the jobs
+// of clients that have abused it at all.
+func (c *ClientReputations) ShouldBid(ctx context.Context, request bidstrategy.BidStrategyRequest) (bidstrategy.BidStrategyResponse, error) {
+	if c == nil {
+		return bidstrategy.NewShouldBidResponse(), nil
+	}
+	reputation := c.Get(request.Job.Metadata.ClientID)
+	if c.rejectAbuses > 0 && reputation.Abuses() >= c.rejectAbuses {
+		return bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason: fmt.Sprintf("client %s has recently had %.1f jobs cancelled, fail verification or overrun their "+
+				"resources on the node", request.Job.Metadata.ClientID, reputation.Abuses()),
+		}, nil
+	}
+	if delay := c.delay(reputation); delay > 0 {
+		log.Ctx(ctx).Debug().Msgf("Waiting %s before bidding on job %s of client %s with a poor reputation",
+			delay, request.Job.ID(), request.Job.Metadata.ClientID)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return bidstrategy.BidStrategyResponse{}, ctx.Err()
+		}
+	}
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+func (c *ClientReputations) ShouldBidBasedOnUsage(
+	context.Context, bidstrategy.BidStrategyRequest, model.ResourceUsageData) (bidstrategy.BidStrategyResponse, error) {
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+// compile-time interface check
+var _ bidstrategy.BidStrategy = (*ClientReputations)(nil)
@@ -0,0 +1,58 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientReputations(t *testing.T) {
+	ctx := context.Background()
+	reputations := NewClientReputations(ClientReputationsParams{
+		HalfLife:      time.Hour,
+		RejectAbuses:  2,
+		DelayPerAbuse: time.Millisecond,
+	})
+	now := time.Now()
+	reputations.now = func() time.Time { return now }
+
+	job := model.Job{Metadata: model.Metadata{ID: "job", ClientID: "client"}}
+	shouldBid := func() bidstrategy.BidStrategyResponse {
+		response, err := reputations.ShouldBid(ctx, bidstrategy.BidStrategyRequest{Job: job})
+		require.NoError(t, err)
+		return response
+	}
+
+	reputations.Record(ctx, job, model.ClientOutcomeCompleted)
+	reputations.Record(ctx, job, model.ClientOutcomeCancelled)
+	require.Equal(t, 1.0, reputations.Get("client").Abuses())
+	require.Equal(t, time.Millisecond, reputations.delay(reputations.Get("client")))
+	require.True(t, shouldBid().ShouldBid, "an abuse delays bids without rejecting them")
+
+	reputations.Record(ctx, job, model.ClientOutcomeResourceOverrun)
+	response := shouldBid()
+	require.False(t, response.ShouldBid)
+	require.Contains(t, response.Reason, "client client has recently had 2.0 jobs")
+
+	now = now.Add(time.Hour)
+	require.Equal(t, 1.0, reputations.Get("client").Abuses(), "abuses count half as much after a half life")
+	require.True(t, shouldBid().ShouldBid)
+	require.Zero(t, reputations.Get("other").Abuses())
+}
+
+func TestClientReputationsMaxDelay(t *testing.T) {
+	reputations := NewClientReputations(ClientReputationsParams{DelayPerAbuse: time.Minute, MaxDelay: 90 * time.Second})
+	require.Equal(t, 90*time.Second, reputations.delay(model.ClientReputation{Cancellations: 2, VerificationFailures: 1}))
+
+	var nilReputations *ClientReputations
+	nilReputations.Record(context.Background(), model.Job{Metadata: model.Metadata{ClientID: "client"}}, model.ClientOutcomeCancelled)
+	response, err := nilReputations.ShouldBid(context.Background(), bidstrategy.BidStrategyRequest{})
+	require.NoError(t, err)
+	require.True(t, response.ShouldBid)
+}
@@ -0,0 +1,124 @@
+package compute
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultDiskUsageCheckInterval is how often the scratch space that running
+// executions use is measured.
+const DefaultDiskUsageCheckInterval = 10 * time.Second
+
+// diskUsageMonitor measures how much scratch space an execution uses while it
+// runs, and cancels the execution if it uses more than its limit.
+type diskUsageMonitor struct {
+	dir      string
+	limit    uint64
+	interval time.Duration
+	cancel   context.CancelFunc
+
+	mu       sync.Mutex
+	peak     uint64
+	exceeded bool
+	done     chan struct{}
+	stopped  chan struct{}
+}
+
+// startDiskUsageMonitor measures the directory every interval until it is
+// stopped, and calls cancel if it grows beyond the limit, unless the limit is
+// zero.
+func startDiskUsageMonitor(
+	ctx context.Context, dir string, limit uint64, interval time.Duration, cancel context.CancelFunc) *diskUsageMonitor {
+	m := &diskUsageMonitor{
+		dir:      dir,
+		limit:    limit,
+		interval: interval,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go m.run(ctx)
+	return m
+}
+
+func (m *diskUsageMonitor) run(ctx context.Context) {
+	defer close(m.stopped)
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.measure(ctx)
+		case <-m.done:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *diskUsageMonitor) measure(ctx context.Context) {
+	used, err := dirSize(m.dir)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msgf("failed to measure the scratch space used in %s", m.dir)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if used > m.peak {
+		m.peak = used
+	}
+	if m.limit > 0 && used > m.limit && !m.exceeded {
+		m.exceeded = true
+		log.Ctx(ctx).Warn().Msgf("cancelling execution that used %s of scratch space, more than its limit of %s",
+			datasize.ByteSize(used).HR(), datasize.ByteSize(m.limit).HR())
+		m.cancel()
+	}
+}
+
+// Stop stops measuring after measuring one last time, and returns the most
+// scratch space the execution used and whether it used more than its limit.
+func (m *diskUsageMonitor) Stop(ctx context.Context) (peak uint64, exceeded bool) {
+	close(m.done)
+	<-m.stopped
+	m.measure(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.peak, m.exceeded
+}
+
+// dirSize returns the total size of the regular files in the directory,
+// skipping files that are removed while it is walked.
+func dirSize(dir string) (uint64, error) {
+	var size uint64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		size += uint64(info.Size())
+		return nil
+	})
+	return size, err
+}
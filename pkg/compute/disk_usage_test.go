@@ -0,0 +1,60 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stdout"), make([]byte, 100), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "outputs", "nested"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "outputs", "nested", "data"), make([]byte, 50), 0600))
+
+	size, err := dirSize(dir)
+	require.NoError(t, err)
+	require.Equal(t, uint64(150), size)
+
+	size, err = dirSize(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	require.Zero(t, size)
+}
+
+func TestDiskUsageMonitorCancelsOverLimit(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	monitor := startDiskUsageMonitor(ctx, dir, 100, 10*time.Millisecond, cancel)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data"), make([]byte, 200), 0600))
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("execution wasn't cancelled after exceeding its disk")
+	}
+	peak, exceeded := monitor.Stop(context.Background())
+	require.True(t, exceeded)
+	require.Equal(t, uint64(200), peak)
+}
+
+func TestDiskUsageMonitorWithinLimit(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	monitor := startDiskUsageMonitor(ctx, dir, 0, time.Hour, cancel)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data"), make([]byte, 200), 0600))
+
+	peak, exceeded := monitor.Stop(context.Background())
+	require.False(t, exceeded)
+	require.Equal(t, uint64(200), peak)
+	require.NoError(t, ctx.Err())
+}
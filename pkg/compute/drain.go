@@ -0,0 +1,147 @@
+package compute
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/logger"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/slices"
+)
+
+// DefaultDrainCheckInterval is how often a draining node checks whether its executions have finished.
+const DefaultDrainCheckInterval = 5 * time.Second
+
+type DrainerParams struct {
+	ID    string
+	Store store.ExecutionStore
+	// Timeout is how long to wait for executions to finish when the drain doesn't say
+	Timeout time.Duration
+	// CheckInterval is how often to check whether the executions have finished
+	CheckInterval time.Duration
+	// Admins are the clients that can drain the node
+	Admins []string
+}
+
+// Drainer takes the compute node out of the network without failing the executions it has, such as for rolling
+// upgrades. Once it is asked to drain, the node stops bidding on new jobs, waits for its executions to finish or the
+// deadline to pass, and then shuts down.
+type Drainer struct {
+	id            string
+	store         store.ExecutionStore
+	timeout       time.Duration
+	checkInterval time.Duration
+	admins        []string
+
+	mu      sync.Mutex
+	status  model.DrainStatus
+	drained chan struct{}
+}
+
+func NewDrainer(params DrainerParams) *Drainer {
+	checkInterval := params.CheckInterval
+	if checkInterval == 0 {
+		checkInterval = DefaultDrainCheckInterval
+	}
+	return &Drainer{
+		id:            params.ID,
+		store:         params.Store,
+		timeout:       params.Timeout,
+		checkInterval: checkInterval,
+		admins:        params.Admins,
+		drained:       make(chan struct{}),
+	}
+}
+
+// IsAdmin returns whether the client can drain the node.
+func (d *Drainer) IsAdmin(clientID string) bool {
+	return slices.Contains(d.admins, clientID)
+}
+
+// Drain stops the node bidding on new jobs and shuts it down once its executions finish, or after the timeout if
+// they haven't, using the default timeout if it is zero. Draining a node that is draining doesn't change its deadline.
+func (d *Drainer) Drain(ctx context.Context, timeout time.Duration) (model.DrainStatus, error) {
+	d.mu.Lock()
+	if !d.status.Draining {
+		if timeout <= 0 {
+			timeout = d.timeout
+		}
+		now := time.Now()
+		d.status = model.DrainStatus{Draining: true, StartedAt: now, Deadline: now.Add(timeout)}
+		log.Ctx(ctx).Info().Msgf("Draining the node until its executions finish or %s", d.status.Deadline)
+		go d.wait(logger.ContextWithNodeIDLogger(context.Background(), d.id), d.status.Deadline)
+	}
+	d.mu.Unlock()
+	return d.Status(ctx)
+}
+
+// Status returns how draining the node is going.
+func (d *Drainer) Status(ctx context.Context) (model.DrainStatus, error) {
+	executions, err := d.store.GetActiveExecutions(ctx)
+	if err != nil {
+		return model.DrainStatus{}, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status := d.status
+	status.ActiveExecutions = len(executions)
+	return status, nil
+}
+
+// Drained is closed once the node has drained and should shut down.
+func (d *Drainer) Drained() <-chan struct{} {
+	return d.drained
+}
+
+func (d *Drainer) wait(ctx context.Context, deadline time.Time) {
+	d.waitForExecutions(ctx, deadline)
+	d.mu.Lock()
+	d.status.Drained = true
+	d.mu.Unlock()
+	close(d.drained)
+}
+
+// waitForExecutions returns once the node has no active executions, or at the deadline.
+func (d *Drainer) waitForExecutions(ctx context.Context, deadline time.Time) {
+	ticker := time.NewTicker(d.checkInterval)
+	defer ticker.Stop()
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	for {
+		executions, err := d.store.GetActiveExecutions(ctx)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("Failed to check the executions of the draining node")
+		} else if len(executions) == 0 {
+			log.Ctx(ctx).Info().Msg("Node drained as its executions finished")
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-timer.C:
+			log.Ctx(ctx).Warn().Msgf("Node drained at its deadline with %d executions unfinished", len(executions))
+			return
+		}
+	}
+}
+
+// ShouldBid rejects all jobs once the node is draining.
+func (d *Drainer) ShouldBid(context.Context, bidstrategy.BidStrategyRequest) (bidstrategy.BidStrategyResponse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.status.Draining {
+		return bidstrategy.BidStrategyResponse{ShouldBid: false, Reason: "the node is draining"}, nil
+	}
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+func (d *Drainer) ShouldBidBasedOnUsage(
+	ctx context.Context, request bidstrategy.BidStrategyRequest, _ model.ResourceUsageData) (bidstrategy.BidStrategyResponse, error) {
+	return d.ShouldBid(ctx, request)
+}
+
+// compile-time interface check
+var _ bidstrategy.BidStrategy = (*Drainer)(nil)
@@ -0,0 +1,82 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainerWaitsForExecutions(t *testing.T) {
+	ctx := context.Background()
+	executionStore := inmemory.NewStore()
+	execution := store.NewExecution("execution", model.Job{Metadata: model.Metadata{ID: "job"}}, "requester", model.ResourceUsageData{})
+	require.NoError(t, executionStore.CreateExecution(ctx, *execution))
+
+	drainer := NewDrainer(DrainerParams{
+		ID:            "node",
+		Store:         executionStore,
+		Timeout:       time.Minute,
+		CheckInterval: 10 * time.Millisecond,
+	})
+	response, err := drainer.ShouldBid(ctx, bidstrategy.BidStrategyRequest{})
+	require.NoError(t, err)
+	require.True(t, response.ShouldBid)
+
+	status, err := drainer.Drain(ctx, 0)
+	require.NoError(t, err)
+	require.True(t, status.Draining)
+	require.Equal(t, 1, status.ActiveExecutions)
+	require.Equal(t, time.Minute, status.Deadline.Sub(status.StartedAt))
+
+	response, err = drainer.ShouldBid(ctx, bidstrategy.BidStrategyRequest{})
+	require.NoError(t, err)
+	require.False(t, response.ShouldBid, "a draining node doesn't bid")
+
+	select {
+	case <-drainer.Drained():
+		t.Fatal("the node drained with an active execution")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, executionStore.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID: execution.ID,
+		NewState:    store.ExecutionStateCancelled,
+	}))
+	select {
+	case <-drainer.Drained():
+	case <-time.After(time.Second):
+		t.Fatal("the node didn't drain once its executions finished")
+	}
+	status, err = drainer.Status(ctx)
+	require.NoError(t, err)
+	require.True(t, status.Drained)
+	require.Zero(t, status.ActiveExecutions)
+}
+
+func TestDrainerDrainsAtDeadline(t *testing.T) {
+	ctx := context.Background()
+	executionStore := inmemory.NewStore()
+	execution := store.NewExecution("execution", model.Job{Metadata: model.Metadata{ID: "job"}}, "requester", model.ResourceUsageData{})
+	require.NoError(t, executionStore.CreateExecution(ctx, *execution))
+
+	drainer := NewDrainer(DrainerParams{ID: "node", Store: executionStore, Timeout: time.Hour, CheckInterval: time.Hour})
+	first, err := drainer.Drain(ctx, 20*time.Millisecond)
+	require.NoError(t, err)
+	again, err := drainer.Drain(ctx, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, first.Deadline, again.Deadline, "draining again keeps the deadline")
+
+	select {
+	case <-drainer.Drained():
+	case <-time.After(time.Second):
+		t.Fatal("the node didn't drain at its deadline")
+	}
+}
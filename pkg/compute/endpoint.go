@@ -3,11 +3,15 @@ package compute
 import (
 	"context"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/prefetch"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -20,6 +24,24 @@ type BaseEndpointParams struct {
 	UsageCalculator capacity.UsageCalculator
 	BidStrategy     bidstrategy.BidStrategy
 	Executor        Executor
+	Executors       executor.ExecutorProvider
+	// Prefetcher starts fetching the inputs of executions once the node bids
+	// on them, if set
+	Prefetcher *prefetch.Prefetcher
+	// Reserver holds capacity for the executions of gang jobs from when the
+	// node bids on them, if set
+	Reserver CapacityReserver
+	// BidHistory keeps the latest bid decisions of the node, if set
+	BidHistory *BidHistory
+	// Pricing is what the node charges for the resources that executions use,
+	// which it quotes in its bids
+	Pricing model.Pricing
+	// ClientReputations records how executions ended in the reputations of
+	// their clients, if set
+	ClientReputations *ClientReputations
+	// Attester attaches evidence of the TEE that the node runs in to its bids
+	// on jobs that require one, if set
+	Attester Attester
 }
 
 // Base implementation of Endpoint
@@ -29,6 +51,13 @@ type BaseEndpoint struct {
 	usageCalculator capacity.UsageCalculator
 	bidStrategy     bidstrategy.BidStrategy
 	executor        Executor
+	executors       executor.ExecutorProvider
+	prefetcher      *prefetch.Prefetcher
+	reserver        CapacityReserver
+	bidHistory      *BidHistory
+	pricing         model.Pricing
+	reputations     *ClientReputations
+	attester        Attester
 }
 
 func NewBaseEndpoint(params BaseEndpointParams) BaseEndpoint {
@@ -38,6 +67,13 @@ func NewBaseEndpoint(params BaseEndpointParams) BaseEndpoint {
 		usageCalculator: params.UsageCalculator,
 		bidStrategy:     params.BidStrategy,
 		executor:        params.Executor,
+		executors:       params.Executors,
+		prefetcher:      params.Prefetcher,
+		reserver:        params.Reserver,
+		bidHistory:      params.BidHistory,
+		pricing:         params.Pricing,
+		reputations:     params.ClientReputations,
+		attester:        params.Attester,
 	}
 }
 
@@ -79,7 +115,16 @@ func (s BaseEndpoint) AskForBid(ctx context.Context, request AskForBidRequest) (
 		}
 	}
 
-	return s.prepareAskForBidResponse(ctx, request, jobRequirements, bidStrategyResponse)
+	response, err := s.prepareAskForBidResponse(ctx, request, jobRequirements, bidStrategyResponse)
+	s.bidHistory.Record(BidDecision{
+		JobID:       request.Job.Metadata.ID,
+		ClientID:    request.Job.Metadata.ClientID,
+		ExecutionID: response.ExecutionID,
+		Bid:         response.Accepted,
+		Reason:      response.Reason,
+		Time:        time.Now(),
+	})
+	return response, err
 }
 
 // Enqueues the job in the execution executionStore, and returns the response.
@@ -99,6 +144,18 @@ func (s BaseEndpoint) prepareAskForBidResponse(
 		}, nil
 	}
 
+	attestation, err := s.attest(ctx, request.Job)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("error attesting for job %s", request.Job.Metadata.ID)
+		return AskForBidResponse{
+			ExecutionMetadata: ExecutionMetadata{
+				JobID: request.Job.Metadata.ID,
+			},
+			Accepted: false,
+			Reason:   "error attesting that the node runs in a TEE",
+		}, nil
+	}
+
 	execution := *store.NewExecution(
 		"e-"+uuid.NewString(),
 		request.Job,
@@ -106,8 +163,21 @@ func (s BaseEndpoint) prepareAskForBidResponse(
 		resourceUsage,
 	)
 
-	err := s.executionStore.CreateExecution(ctx, execution)
+	// the executions of a gang must all be able to start once the requester node accepts them, so the node only bids
+	// with capacity that it holds for the execution until then
+	if request.Job.Spec.Deal.Gang && s.reserver != nil && !s.reserver.Reserve(ctx, execution) {
+		return AskForBidResponse{
+			ExecutionMetadata: ExecutionMetadata{
+				JobID: request.Job.Metadata.ID,
+			},
+			Accepted: false,
+			Reason:   "not enough free capacity to reserve for the gang",
+		}, nil
+	}
+
+	err = s.executionStore.CreateExecution(ctx, execution)
 	if err != nil {
+		s.releaseReservation(ctx, execution.ID)
 		log.Ctx(ctx).Error().Err(err).Msgf("error adding job %s to backlog", execution.Job)
 		return AskForBidResponse{
 			ExecutionMetadata: ExecutionMetadata{
@@ -118,16 +188,38 @@ func (s BaseEndpoint) prepareAskForBidResponse(
 		}, err
 	} else {
 		log.Ctx(ctx).Debug().Msgf("bidding for job %s with execution %s", execution.Job, execution.ID)
+		s.prefetcher.Prefetch(ctx, execution.ID, request.Job.Spec.Inputs)
 		return AskForBidResponse{
 			ExecutionMetadata: ExecutionMetadata{
 				ExecutionID: execution.ID,
 				JobID:       request.Job.Metadata.ID,
 			},
-			Accepted: true,
+			Accepted:    true,
+			Quote:       s.quote(request.Job, resourceUsage),
+			Attestation: attestation,
 		}, nil
 	}
 }
 
+// attest returns evidence of the TEE that the node runs in, bound to the job and the node, if the job requires one.
+func (s BaseEndpoint) attest(ctx context.Context, job model.Job) (*model.Attestation, error) {
+	if job.Spec.TEE == "" || s.attester == nil {
+		return nil, nil
+	}
+	return s.attester.Attest(ctx, model.AttestationReportData(job.Metadata.ID, s.id))
+}
+
+// quote returns what the node charges for running the job until its timeout, or nil if the node doesn't charge.
+func (s BaseEndpoint) quote(job model.Job, resourceUsage model.ResourceUsageData) *model.Quote {
+	if s.pricing.IsZero() {
+		return nil
+	}
+	return &model.Quote{
+		Pricing: s.pricing,
+		Cost:    s.pricing.Cost(resourceUsage, job.Spec.GetTimeout()),
+	}
+}
+
 func (s BaseEndpoint) BidAccepted(ctx context.Context, request BidAcceptedRequest) (BidAcceptedResponse, error) {
 	log.Ctx(ctx).Debug().Msgf("bid accepted: %s", request.ExecutionID)
 	err := s.executionStore.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
@@ -167,6 +259,8 @@ func (s BaseEndpoint) BidRejected(ctx context.Context, request BidRejectedReques
 	if err != nil {
 		return BidRejectedResponse{}, err
 	}
+	s.prefetcher.Release(ctx, request.ExecutionID)
+	s.releaseReservation(ctx, request.ExecutionID)
 	execution, err := s.executionStore.GetExecution(ctx, request.ExecutionID)
 	if err != nil {
 		return BidRejectedResponse{}, err
@@ -190,6 +284,7 @@ func (s BaseEndpoint) ResultAccepted(ctx context.Context, request ResultAccepted
 	if err != nil {
 		return ResultAcceptedResponse{}, err
 	}
+	s.reputations.Record(ctx, execution.Job, model.ClientOutcomeCompleted)
 
 	err = s.executor.Publish(ctx, execution)
 	if err != nil {
@@ -215,6 +310,7 @@ func (s BaseEndpoint) ResultRejected(ctx context.Context, request ResultRejected
 	if err != nil {
 		return ResultRejectedResponse{}, err
 	}
+	s.reputations.Record(ctx, execution.Job, model.ClientOutcomeVerificationFailed)
 	return ResultRejectedResponse{
 		ExecutionMetadata: NewExecutionMetadata(execution),
 	}, nil
@@ -235,6 +331,9 @@ func (s BaseEndpoint) CancelExecution(ctx context.Context, request CancelExecuti
 		if err != nil {
 			return CancelExecutionResponse{}, err
 		}
+		if request.ByClient {
+			s.reputations.Record(ctx, execution.Job, model.ClientOutcomeCancelled)
+		}
 	}
 
 	err = s.executionStore.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
@@ -245,10 +344,46 @@ func (s BaseEndpoint) CancelExecution(ctx context.Context, request CancelExecuti
 	if err != nil {
 		return CancelExecutionResponse{}, err
 	}
+	s.prefetcher.Release(ctx, request.ExecutionID)
+	s.releaseReservation(ctx, request.ExecutionID)
 	return CancelExecutionResponse{
 		ExecutionMetadata: NewExecutionMetadata(execution),
 	}, nil
 }
 
+// releaseReservation frees the capacity reserved for an execution that won't run.
+func (s BaseEndpoint) releaseReservation(ctx context.Context, executionID string) {
+	if s.reserver != nil {
+		s.reserver.Release(ctx, executionID)
+	}
+}
+
+func (s BaseEndpoint) ExecutionLogs(ctx context.Context, request ExecutionLogsRequest) (io.ReadCloser, error) {
+	log.Ctx(ctx).Debug().Msgf("streaming logs of execution %s", request.ExecutionID)
+	execution, err := s.executionStore.GetExecution(ctx, request.ExecutionID)
+	if err != nil {
+		return nil, err
+	}
+	// executors clean up after themselves once an execution completes, so
+	// output can only be streamed while the execution is still running
+	if execution.State != store.ExecutionStateRunning {
+		return nil, fmt.Errorf("cannot stream logs of execution %s in state %s", execution.ID, execution.State)
+	}
+	// stdout and stderr are part of the encrypted results, so are only readable by the recipients
+	if len(execution.Job.Spec.Encryption.Recipients) > 0 {
+		return nil, fmt.Errorf("cannot stream logs of execution %s as its results are encrypted", execution.ID)
+	}
+	if s.executors == nil {
+		return nil, fmt.Errorf("log streaming is not supported by this compute node")
+	}
+
+	jobExecutor, err := s.executors.Get(ctx, execution.Job.Spec.Engine)
+	if err != nil {
+		return nil, err
+	}
+	return jobExecutor.GetOutputStream(ctx, execution.Job, request.Follow)
+}
+
 // Compile-time interface check:
 var _ Endpoint = (*BaseEndpoint)(nil)
+var _ LogStreamer = (*BaseEndpoint)(nil)
@@ -0,0 +1,32 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionLogsRefusesEncryptedJobs(t *testing.T) {
+	ctx := context.Background()
+	executionStore := inmemory.NewStore()
+	job := model.Job{
+		Metadata: model.Metadata{ID: "job"},
+		Spec:     model.Spec{Encryption: model.EncryptionConfig{Recipients: []string{"age1recipient"}}},
+	}
+	execution := store.NewExecution("execution", job, "requester", model.ResourceUsageData{})
+	require.NoError(t, executionStore.CreateExecution(ctx, *execution))
+	require.NoError(t, executionStore.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID: execution.ID,
+		NewState:    store.ExecutionStateRunning,
+	}))
+
+	endpoint := BaseEndpoint{executionStore: executionStore}
+	_, err := endpoint.ExecutionLogs(ctx, ExecutionLogsRequest{ExecutionID: execution.ID})
+	require.ErrorContains(t, err, "encrypted")
+}
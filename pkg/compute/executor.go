@@ -2,14 +2,30 @@ package compute
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/bacalhau-project/bacalhau/pkg/compression"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/encryption"
 	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/logger"
+	"github.com/bacalhau-project/bacalhau/pkg/merkle"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/prefetch"
 	"github.com/bacalhau-project/bacalhau/pkg/util/generic"
 	"github.com/bacalhau-project/bacalhau/pkg/verifier"
+	"github.com/c2h5oh/datasize"
+	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/rs/zerolog/log"
+	"go.uber.org/multierr"
 )
 
 type BaseExecutorParams struct {
@@ -19,7 +35,22 @@ type BaseExecutorParams struct {
 	Executors       executor.ExecutorProvider
 	Verifiers       verifier.VerifierProvider
 	Publishers      publisher.PublisherProvider
+	Prefetcher      *prefetch.Prefetcher
 	SimulatorConfig model.SimulatorConfigCompute
+	PublishRetry    PublishRetryOptions
+	// DiskUsageCheckInterval is how often the scratch space of running
+	// executions is measured, or never if zero
+	DiskUsageCheckInterval time.Duration
+	// Checkpoint configures how executions are checkpointed, if their executor can
+	Checkpoint CheckpointOptions
+	// ResultCache configures how identical jobs reuse the results the node published
+	ResultCache ResultCacheOptions
+	// ClientReputations records the executions that overran their resources
+	// in the reputations of their clients, if set
+	ClientReputations *ClientReputations
+	// SigningKey signs the manifests of the results that the node publishes,
+	// so that anyone can verify which node produced them, if set
+	SigningKey crypto.PrivKey
 }
 
 // BaseExecutor is the base implementation for backend service.
@@ -29,10 +60,18 @@ type BaseExecutor struct {
 	callback        Callback
 	store           store.ExecutionStore
 	cancellers      generic.SyncMap[string, context.CancelFunc]
+	exitCodes       generic.SyncMap[string, int]
 	executors       executor.ExecutorProvider
 	verifiers       verifier.VerifierProvider
 	publishers      publisher.PublisherProvider
+	prefetcher      *prefetch.Prefetcher
 	simulatorConfig model.SimulatorConfigCompute
+	publishRetry    PublishRetryOptions
+	diskUsageCheck  time.Duration
+	checkpoint      CheckpointOptions
+	resultCache     *resultCache
+	reputations     *ClientReputations
+	signingKey      crypto.PrivKey
 }
 
 func NewBaseExecutor(params BaseExecutorParams) *BaseExecutor {
@@ -43,7 +82,14 @@ func NewBaseExecutor(params BaseExecutorParams) *BaseExecutor {
 		executors:       params.Executors,
 		verifiers:       params.Verifiers,
 		publishers:      params.Publishers,
+		prefetcher:      params.Prefetcher,
 		simulatorConfig: params.SimulatorConfig,
+		publishRetry:    params.PublishRetry.withDefaults(),
+		diskUsageCheck:  params.DiskUsageCheckInterval,
+		checkpoint:      params.Checkpoint,
+		resultCache:     newResultCache(params.ResultCache),
+		reputations:     params.ClientReputations,
+		signingKey:      params.SigningKey,
 	}
 }
 
@@ -68,6 +114,8 @@ func (e *BaseExecutor) Run(ctx context.Context, execution store.Execution) (err
 			e.handleFailure(ctx, execution, err, "Running")
 		}
 	}()
+	// inputs that were prefetched but not used by the executor
+	defer e.prefetcher.Release(ctx, execution.ID)
 
 	log.Ctx(ctx).Debug().Msg("Running execution")
 	err = e.store.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
@@ -95,9 +143,39 @@ func (e *BaseExecutor) Run(ctx context.Context, execution store.Execution) (err
 	}
 
 	var runCommandResult *model.RunCommandResult
+	var diskUsage uint64
+	comment := ""
 
-	if !e.simulatorConfig.IsBadActor {
-		runCommandResult, err = jobExecutor.Run(ctx, execution.Job, resultFolder)
+	if cached, reused := e.resultCache.reuse(execution.ID, execution.Job); reused {
+		log.Ctx(ctx).Info().Msg("Reusing the published results of an identical job")
+		runCommandResult, comment = cached, "reused the published results of an identical job"
+	} else if !e.simulatorConfig.IsBadActor {
+		// executions that write more than the disk they declared are stopped
+		var monitor *diskUsageMonitor
+		if e.diskUsageCheck > 0 {
+			limit := capacity.ParseResourceUsageConfig(execution.Job.Spec.Resources).Disk
+			monitor = startDiskUsageMonitor(ctx, resultFolder, limit, e.diskUsageCheck, cancel)
+		}
+		checkpointer, canCheckpoint := jobExecutor.(executor.Checkpointer)
+		if execution.Job.Spec.Array.Count > 0 {
+			runCommandResult, err = executor.RunArray(ctx, jobExecutor, execution.Job, resultFolder)
+		} else if canCheckpoint && e.checkpoint.checkpoints(execution.Job) {
+			runCommandResult, err = e.runCheckpointed(ctx, execution.ID, jobExecutor, checkpointer, execution.Job, resultFolder)
+		} else {
+			runCommandResult, err = jobExecutor.Run(ctx, execution.Job, resultFolder)
+		}
+		if monitor != nil {
+			var exceeded bool
+			diskUsage, exceeded = monitor.Stop(ctx)
+			if exceeded {
+				e.reputations.Record(ctx, execution.Job, model.ClientOutcomeResourceOverrun)
+				err = fmt.Errorf("execution used %s of scratch space, more than the %s it declared",
+					datasize.ByteSize(diskUsage).HR(), datasize.ByteSize(monitor.limit).HR())
+			}
+			if runCommandResult != nil {
+				runCommandResult.DiskUsage = diskUsage
+			}
+		}
 		if err != nil {
 			jobsFailed.Add(ctx, 1)
 		} else {
@@ -108,6 +186,8 @@ func (e *BaseExecutor) Run(ctx context.Context, execution store.Execution) (err
 			log.Ctx(ctx).Error().Err(err).Msg("failed to run execution")
 			return
 		}
+		e.resultCache.runComplete(execution.ID, execution.Job, runCommandResult)
+		comment = diskUsageComment(diskUsage)
 	}
 
 	proposal, err := jobVerifier.GetProposal(ctx, execution.Job, resultFolder)
@@ -119,11 +199,24 @@ func (e *BaseExecutor) Run(ctx context.Context, execution store.Execution) (err
 		ExecutionID:   execution.ID,
 		ExpectedState: store.ExecutionStateRunning,
 		NewState:      store.ExecutionStateWaitingVerification,
+		Comment:       comment,
 	})
 	if err != nil {
 		return
 	}
 
+	// the exit code is signed with the results once they are published
+	if runCommandResult != nil && e.signingKey != nil {
+		e.exitCodes.Put(execution.ID, runCommandResult.ExitCode)
+	}
+
+	// stdout and stderr are part of the encrypted results
+	if runCommandResult != nil && len(execution.Job.Spec.Encryption.Recipients) > 0 {
+		redacted := *runCommandResult
+		redacted.STDOUT, redacted.STDERR = "", ""
+		runCommandResult = &redacted
+	}
+
 	e.callback.OnRunComplete(ctx, RunResult{
 		ExecutionMetadata: NewExecutionMetadata(execution),
 		RoutingMetadata: RoutingMetadata{
@@ -137,7 +230,19 @@ func (e *BaseExecutor) Run(ctx context.Context, execution store.Execution) (err
 }
 
 // Publish the result of an execution after it has been verified.
-func (e *BaseExecutor) Publish(ctx context.Context, execution store.Execution) (err error) {
+func (e *BaseExecutor) Publish(ctx context.Context, execution store.Execution) error {
+	return e.publishExecution(ctx, execution, store.ExecutionStateResultAccepted, time.Now())
+}
+
+// publishExecution publishes the result of an execution that is in the
+// expected state. If publishing fails, it is left pending and tried again
+// later, until the pending timeout has passed since it was first tried.
+func (e *BaseExecutor) publishExecution(
+	ctx context.Context,
+	execution store.Execution,
+	expectedState store.ExecutionState,
+	firstTried time.Time,
+) (err error) {
 	defer func() {
 		if err != nil {
 			e.handleFailure(ctx, execution, err, "Publishing")
@@ -146,7 +251,7 @@ func (e *BaseExecutor) Publish(ctx context.Context, execution store.Execution) (
 	log.Ctx(ctx).Debug().Msgf("Publishing execution %s", execution.ID)
 	err = e.store.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
 		ExecutionID:   execution.ID,
-		ExpectedState: store.ExecutionStateResultAccepted,
+		ExpectedState: expectedState,
 		NewState:      store.ExecutionStatePublishing,
 	})
 	if err != nil {
@@ -156,18 +261,36 @@ func (e *BaseExecutor) Publish(ctx context.Context, execution store.Execution) (
 	if err != nil {
 		return
 	}
-	resultFolder, err := jobVerifier.GetResultPath(ctx, execution.Job)
+	// the exit code is read before the results are published, so that the
+	// execution fails rather than publishes results that the node can't sign
+	exitCode, err := e.resultExitCode(ctx, execution, jobVerifier)
 	if err != nil {
 		return
 	}
-	jobPublisher, err := e.publishers.Get(ctx, execution.Job.Spec.Publisher)
-	if err != nil {
-		return
+	var publishedResult model.StorageSpec
+	var publisherResults []model.PublisherResult
+	var skippedOutputs *model.SkippedOutputs
+	if cached, reused := e.resultCache.reusedResult(execution.ID); reused {
+		publishedResult, publisherResults = cached.published, cached.publisherResults
+	} else {
+		publishedResult, publisherResults, skippedOutputs, err = e.publishResults(ctx, execution, jobVerifier)
+		if err != nil {
+			if ctx.Err() == nil && time.Since(firstTried)+e.publishRetry.MaxBackoff < e.publishRetry.PendingTimeout {
+				return e.publishLater(ctx, execution, firstTried, err)
+			}
+			return
+		}
+		e.resultCache.publishComplete(execution.ID, execution.Job, publishedResult, publisherResults)
 	}
-	publishedResult, err := jobPublisher.PublishResult(ctx, execution.Job, e.ID, resultFolder)
-	if err != nil {
-		return
+	e.resultCache.forget(execution.ID)
+
+	// the results are published, so failing to sign them doesn't fail the
+	// execution, and the requester and clients see that they aren't signed
+	resultSignature, signErr := e.signResult(execution, exitCode, publishedResult)
+	if signErr != nil {
+		log.Ctx(ctx).Error().Err(signErr).Str("execution", execution.ID).Msg("Failed to sign the published results")
 	}
+	e.exitCodes.Delete(execution.ID)
 
 	log.Ctx(ctx).Debug().
 		Str("execution", execution.ID).
@@ -189,11 +312,204 @@ func (e *BaseExecutor) Publish(ctx context.Context, execution store.Execution) (
 			SourcePeerID: e.ID,
 			TargetPeerID: execution.RequesterNodeID,
 		},
-		PublishResult: publishedResult,
+		PublishResult:    publishedResult,
+		PublisherResults: publisherResults,
+		SkippedOutputs:   skippedOutputs,
+		ResultSignature:  resultSignature,
 	})
 	return err
 }
 
+// resultExitCode returns the exit code to sign the results of the execution with, if the node signs results. It is the
+// one the execution ran with, or is read from the results if the node restarted since it ran.
+func (e *BaseExecutor) resultExitCode(
+	ctx context.Context,
+	execution store.Execution,
+	jobVerifier verifier.Verifier,
+) (int, error) {
+	if e.signingKey == nil {
+		return 0, nil
+	}
+	if exitCode, found := e.exitCodes.Get(execution.ID); found {
+		return exitCode, nil
+	}
+	resultFolder, err := jobVerifier.GetResultPath(ctx, execution.Job)
+	if err != nil {
+		return 0, err
+	}
+	content, err := os.ReadFile(filepath.Join(resultFolder, model.DownloadFilenameExitCode))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the exit code of the results: %w", err)
+	}
+	exitCode, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse the exit code of the results: %w", err)
+	}
+	return exitCode, nil
+}
+
+// signResult signs the manifest of the published result of the execution with the node's key, if it has one.
+func (e *BaseExecutor) signResult(
+	execution store.Execution,
+	exitCode int,
+	published model.StorageSpec,
+) (*model.ResultSignature, error) {
+	if e.signingKey == nil {
+		return nil, nil
+	}
+	return model.SignResultManifest(e.signingKey, model.ResultManifest{
+		JobID:      execution.Job.Metadata.ID,
+		NodeID:     e.ID,
+		ResultCID:  published.CID,
+		MerkleRoot: published.MerkleRoot,
+		ExitCode:   exitCode,
+	})
+}
+
+// publishResults filters the outputs of the execution and publishes its results.
+func (e *BaseExecutor) publishResults(
+	ctx context.Context,
+	execution store.Execution,
+	jobVerifier verifier.Verifier,
+) (model.StorageSpec, []model.PublisherResult, *model.SkippedOutputs, error) {
+	resultFolder, err := jobVerifier.GetResultPath(ctx, execution.Job)
+	if err != nil {
+		return model.StorageSpec{}, nil, nil, err
+	}
+	var skippedOutputs *model.SkippedOutputs
+	if publisher.HasOutputFilters(execution.Job) {
+		filteredFolder, tempErr := os.MkdirTemp("", "bacalhau-filtered-results-*")
+		if tempErr != nil {
+			return model.StorageSpec{}, nil, nil, tempErr
+		}
+		defer func() { _ = os.RemoveAll(filteredFolder) }()
+		skipped, filterErr := publisher.FilterOutputs(execution.Job, resultFolder, filteredFolder)
+		if filterErr != nil {
+			return model.StorageSpec{}, nil, nil, fmt.Errorf("failed to filter outputs: %w", filterErr)
+		}
+		if skipped.Count > 0 {
+			log.Ctx(ctx).Debug().Int("count", skipped.Count).Int64("size", skipped.Size).Msg("Skipped publishing outputs")
+		}
+		skippedOutputs, resultFolder = &skipped, filteredFolder
+	}
+	publishedResult, publisherResults, err := e.publish(ctx, execution.Job, resultFolder)
+	return publishedResult, publisherResults, skippedOutputs, err
+}
+
+// publishLater leaves publishing the result of the execution pending after it
+// failed, and tries again once the max backoff has passed if the execution is
+// still pending then.
+func (e *BaseExecutor) publishLater(ctx context.Context, execution store.Execution, firstTried time.Time, publishErr error) error {
+	log.Ctx(ctx).Warn().Err(publishErr).Msgf("Publishing execution %s is pending", execution.ID)
+	err := e.store.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID:   execution.ID,
+		ExpectedState: store.ExecutionStatePublishing,
+		NewState:      store.ExecutionStatePublishPending,
+		Comment:       publishErr.Error(),
+	})
+	if err != nil {
+		return err
+	}
+
+	retryAt := time.Now().Add(e.publishRetry.MaxBackoff)
+	e.callback.OnPublishPending(ctx, PublishPendingResult{
+		ExecutionMetadata: NewExecutionMetadata(execution),
+		RoutingMetadata: RoutingMetadata{
+			SourcePeerID: e.ID,
+			TargetPeerID: execution.RequesterNodeID,
+		},
+		Err:     publishErr.Error(),
+		RetryAt: retryAt,
+	})
+
+	time.AfterFunc(time.Until(retryAt), func() {
+		retryCtx := logger.ContextWithNodeIDLogger(context.Background(), e.ID)
+		pending, err := e.store.GetExecution(retryCtx, execution.ID)
+		if err != nil || pending.State != store.ExecutionStatePublishPending {
+			// e.g. the execution was cancelled while it was pending
+			return
+		}
+		_ = e.publishExecution(retryCtx, pending, store.ExecutionStatePublishPending, firstTried)
+	})
+	return nil
+}
+
+// publish publishes the results with each of the publishers of the job at
+// once, retrying each of them if they fail, and encrypting the results first
+// if the job has recipients. It returns the result of the first publisher to
+// succeed in the order of the job, and how each of them went, failing only if
+// all of them fail.
+func (e *BaseExecutor) publish(
+	ctx context.Context,
+	job model.Job,
+	resultFolder string,
+) (model.StorageSpec, []model.PublisherResult, error) {
+	if job.Spec.Compression.Algorithm != "" {
+		compressedFolder, err := os.MkdirTemp("", "bacalhau-compressed-results-*")
+		if err != nil {
+			return model.StorageSpec{}, nil, err
+		}
+		defer func() { _ = os.RemoveAll(compressedFolder) }()
+		if err = compression.CompressResults(resultFolder, compressedFolder, job.Spec.Compression); err != nil {
+			return model.StorageSpec{}, nil, fmt.Errorf("failed to compress results: %w", err)
+		}
+		resultFolder = compressedFolder
+	}
+
+	if len(job.Spec.Encryption.Recipients) > 0 {
+		encryptedFolder, err := os.MkdirTemp("", "bacalhau-encrypted-results-*")
+		if err != nil {
+			return model.StorageSpec{}, nil, err
+		}
+		defer func() { _ = os.RemoveAll(encryptedFolder) }()
+		if err = encryption.EncryptResults(resultFolder, encryptedFolder, job.Spec.Encryption.Recipients); err != nil {
+			return model.StorageSpec{}, nil, fmt.Errorf("failed to encrypt results: %w", err)
+		}
+		resultFolder = encryptedFolder
+	}
+
+	// the root is over the results as they are published, so clients can check them before decrypting them
+	merkleRoot, err := merkle.Root(resultFolder)
+	if err != nil {
+		return model.StorageSpec{}, nil, fmt.Errorf("failed to compute the merkle root of results: %w", err)
+	}
+
+	publishers := job.Spec.AllPublishers()
+	results := make([]model.PublisherResult, len(publishers))
+	errs := make([]error, len(publishers))
+	var wg sync.WaitGroup
+	for i, publisherType := range publishers {
+		i, publisherType := i, publisherType
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].Publisher = publisherType
+			jobPublisher, err := e.publishers.Get(ctx, publisherType)
+			if err == nil {
+				results[i].Data, err = e.publishWithRetries(ctx, jobPublisher, job, resultFolder)
+			}
+			if err == nil {
+				results[i].Data.MerkleRoot = merkleRoot
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+				errs[i] = fmt.Errorf("failed to publish with %s: %w", publisherType, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		if errs[i] == nil {
+			if err := multierr.Combine(errs...); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Str("job", job.Metadata.ID).Msg("Published with some of the publishers of the job")
+			}
+			return result.Data, results, nil
+		}
+	}
+	return model.StorageSpec{}, results, multierr.Combine(errs...)
+}
+
 // Cancel the execution.
 func (e *BaseExecutor) Cancel(ctx context.Context, execution store.Execution) (err error) {
 	defer func() {
@@ -203,6 +519,7 @@ func (e *BaseExecutor) Cancel(ctx context.Context, execution store.Execution) (e
 	}()
 
 	log.Ctx(ctx).Debug().Str("execution", execution.ID).Msg("Canceling execution")
+	e.resultCache.forget(execution.ID)
 	if cancel, found := e.cancellers.Get(execution.ID); found {
 		e.cancellers.Delete(execution.ID)
 		cancel()
@@ -218,8 +535,18 @@ func (e *BaseExecutor) Cancel(ctx context.Context, execution store.Execution) (e
 	return err
 }
 
+// diskUsageComment records the scratch space an execution used in its state history, if it was measured.
+func diskUsageComment(diskUsage uint64) string {
+	if diskUsage == 0 {
+		return ""
+	}
+	return fmt.Sprintf("used %s of scratch space", datasize.ByteSize(diskUsage).HR())
+}
+
 func (e *BaseExecutor) handleFailure(ctx context.Context, execution store.Execution, err error, operation string) {
 	log.Ctx(ctx).Error().Err(err).Msgf("%s execution %s failed", operation, execution.ID)
+	e.resultCache.forget(execution.ID)
+	e.exitCodes.Delete(execution.ID)
 	updateError := e.store.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
 		ExecutionID: execution.ID,
 		NewState:    store.ExecutionStateFailed,
@@ -7,7 +7,9 @@ import (
 
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
 	"github.com/bacalhau-project/bacalhau/pkg/logger"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	sync "github.com/bacalhau-project/golang-mutex-tracer"
 )
@@ -15,6 +17,10 @@ import (
 type bufferTask struct {
 	execution  store.Execution
 	enqueuedAt time.Time
+	// releases the capacity of a reserved execution that was never run
+	expiry *time.Timer
+	// the GPUs or MIG slices the execution runs on, if the node describes its GPUs
+	gpus []model.GPU
 }
 
 func newBufferTask(execution store.Execution) *bufferTask {
@@ -32,6 +38,14 @@ type ExecutorBufferParams struct {
 	EnqueuedCapacityTracker    capacity.Tracker
 	DefaultJobExecutionTimeout time.Duration
 	BackoffDuration            time.Duration
+	// ReservationTimeout is how long capacity is reserved for an execution
+	// that doesn't run, with zero meaning until it is released
+	ReservationTimeout time.Duration
+	// GPUAllocator picks the GPUs or MIG slices that executions run on, if set
+	GPUAllocator *capacity.GPUAllocator
+	// EngineConcurrency caps how many executions of each engine run at once,
+	// on top of the resources they use, with engines not in it uncapped
+	EngineConcurrency map[model.Engine]int
 }
 
 // ExecutorBuffer is a backend.Executor implementation that buffers executions locally until enough capacity is
@@ -44,11 +58,15 @@ type ExecutorBuffer struct {
 	ID                         string
 	runningCapacity            capacity.Tracker
 	enqueuedCapacity           capacity.Tracker
+	gpuAllocator               *capacity.GPUAllocator
+	engineConcurrency          map[model.Engine]int
 	delegateService            Executor
 	callback                   Callback
 	running                    map[string]*bufferTask
 	enqueued                   map[string]*bufferTask
 	enqueuedList               []string
+	reserved                   map[string]*bufferTask
+	reservationTimeout         time.Duration
 	defaultJobExecutionTimeout time.Duration
 	backoffDuration            time.Duration
 	backoffUntil               time.Time
@@ -60,11 +78,15 @@ func NewExecutorBuffer(params ExecutorBufferParams) *ExecutorBuffer {
 		ID:                         params.ID,
 		runningCapacity:            params.RunningCapacityTracker,
 		enqueuedCapacity:           params.EnqueuedCapacityTracker,
+		gpuAllocator:               params.GPUAllocator,
+		engineConcurrency:          params.EngineConcurrency,
 		delegateService:            params.DelegateExecutor,
 		callback:                   params.Callback,
 		running:                    make(map[string]*bufferTask),
 		enqueued:                   make(map[string]*bufferTask),
 		enqueuedList:               make([]string, 0),
+		reserved:                   make(map[string]*bufferTask),
+		reservationTimeout:         params.ReservationTimeout,
 		defaultJobExecutionTimeout: params.DefaultJobExecutionTimeout,
 		backoffDuration:            params.BackoffDuration,
 	}
@@ -77,7 +99,8 @@ func NewExecutorBuffer(params ExecutorBufferParams) *ExecutorBuffer {
 	return r
 }
 
-// Run enqueues the execution and tries to run it if there is enough capacity.
+// Run enqueues the execution and tries to run it if there is enough capacity. Executions that have capacity reserved
+// for them run straight away.
 func (s *ExecutorBuffer) Run(ctx context.Context, execution store.Execution) (err error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -101,6 +124,10 @@ func (s *ExecutorBuffer) Run(ctx context.Context, execution store.Execution) (er
 		err = fmt.Errorf("not enough capacity to run job")
 		return
 	}
+	if s.gpuAllocator != nil && !s.gpuAllocator.IsWithinLimits(capacity.ParseGPURequest(execution.Job.Spec.Resources)) {
+		err = fmt.Errorf("not enough GPUs to run job")
+		return
+	}
 	if _, ok := s.enqueued[execution.ID]; ok {
 		err = fmt.Errorf("execution %s already enqueued", execution.ID)
 		return
@@ -109,6 +136,17 @@ func (s *ExecutorBuffer) Run(ctx context.Context, execution store.Execution) (er
 		err = fmt.Errorf("execution %s already running", execution.ID)
 		return
 	}
+	if task, ok := s.reserved[execution.ID]; ok {
+		if task.expiry != nil {
+			task.expiry.Stop()
+		}
+		delete(s.reserved, execution.ID)
+		// the running capacity was added when the capacity was reserved
+		task.execution = execution
+		s.running[execution.ID] = task
+		go s.doRun(logger.ContextWithNodeIDLogger(context.Background(), s.ID), task)
+		return
+	}
 	if !s.enqueuedCapacity.AddIfHasCapacity(ctx, execution.ResourceUsage) {
 		err = fmt.Errorf("not enough capacity to enqueue job")
 		return
@@ -134,6 +172,7 @@ func (s *ExecutorBuffer) doRun(ctx context.Context, task *bufferTask) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	ctx = executor.ContextWithGPUs(ctx, task.gpus)
 	ch := make(chan error)
 	go func() {
 		ch <- s.delegateService.Run(ctx, task.execution)
@@ -157,6 +196,7 @@ func (s *ExecutorBuffer) doRun(ctx context.Context, task *bufferTask) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.runningCapacity.Remove(ctx, task.execution.ResourceUsage)
+	s.releaseGPUs(task.execution.ID)
 	delete(s.running, task.execution.ID)
 	s.deque()
 }
@@ -179,7 +219,8 @@ func (s *ExecutorBuffer) deque() {
 	for _, executionID := range s.enqueuedList {
 		task := s.enqueued[executionID]
 
-		if s.runningCapacity.AddIfHasCapacity(ctx, task.execution.ResourceUsage) {
+		if s.engineHasCapacity(task.execution.Job.Spec.Engine) &&
+			s.runningCapacity.AddIfHasCapacity(ctx, task.execution.ResourceUsage) && s.allocateGPUs(ctx, task) {
 			s.enqueuedCapacity.Remove(ctx, task.execution.ResourceUsage)
 			delete(s.enqueued, executionID)
 			s.running[executionID] = task
@@ -192,6 +233,88 @@ func (s *ExecutorBuffer) deque() {
 	s.backoffUntil = time.Now().Add(s.backoffDuration)
 }
 
+// Reserve takes the capacity that the execution needs from the running capacity, so that it can run as soon as it is
+// accepted, and returns false if there isn't enough of it free.
+func (s *ExecutorBuffer) Reserve(ctx context.Context, execution store.Execution) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.reserved[execution.ID]; ok {
+		return true
+	}
+	if !s.engineHasCapacity(execution.Job.Spec.Engine) ||
+		!s.runningCapacity.AddIfHasCapacity(ctx, execution.ResourceUsage) {
+		return false
+	}
+	task := newBufferTask(execution)
+	if !s.allocateGPUs(ctx, task) {
+		return false
+	}
+	if s.reservationTimeout > 0 {
+		task.expiry = time.AfterFunc(s.reservationTimeout, func() {
+			s.Release(logger.ContextWithNodeIDLogger(context.Background(), s.ID), execution.ID)
+		})
+	}
+	s.reserved[execution.ID] = task
+	return true
+}
+
+// Release gives back the capacity reserved for the execution if it hasn't run, and tries to run enqueued executions
+// with it.
+func (s *ExecutorBuffer) Release(ctx context.Context, executionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task, ok := s.reserved[executionID]
+	if !ok {
+		return
+	}
+	if task.expiry != nil {
+		task.expiry.Stop()
+	}
+	delete(s.reserved, executionID)
+	s.runningCapacity.Remove(ctx, task.execution.ResourceUsage)
+	s.releaseGPUs(executionID)
+	s.deque()
+}
+
+// engineHasCapacity is whether another execution of the engine can run without going over its cap, counting the
+// executions that run or have capacity reserved for them. It must be called with the lock held.
+func (s *ExecutorBuffer) engineHasCapacity(engine model.Engine) bool {
+	limit, ok := s.engineConcurrency[engine]
+	if !ok || limit <= 0 {
+		return true
+	}
+	count := 0
+	for _, tasks := range []map[string]*bufferTask{s.running, s.reserved} {
+		for _, task := range tasks {
+			if task.execution.Job.Spec.Engine == engine {
+				count++
+			}
+		}
+	}
+	return count < limit
+}
+
+// allocateGPUs picks the GPUs that the execution runs on once it has taken its running capacity, and gives the
+// running capacity back if there aren't enough GPUs free.
+func (s *ExecutorBuffer) allocateGPUs(ctx context.Context, task *bufferTask) bool {
+	if s.gpuAllocator == nil {
+		return true
+	}
+	gpus, ok := s.gpuAllocator.Allocate(task.execution.ID, capacity.ParseGPURequest(task.execution.Job.Spec.Resources))
+	if !ok {
+		s.runningCapacity.Remove(ctx, task.execution.ResourceUsage)
+		return false
+	}
+	task.gpus = gpus
+	return true
+}
+
+func (s *ExecutorBuffer) releaseGPUs(executionID string) {
+	if s.gpuAllocator != nil {
+		s.gpuAllocator.Release(executionID)
+	}
+}
+
 func (s *ExecutorBuffer) Publish(_ context.Context, execution store.Execution) error {
 	// TODO: Enqueue publish tasks
 	go func() {
@@ -235,6 +358,11 @@ func (s *ExecutorBuffer) EnqueuedExecutions() []store.Execution {
 	return s.mapValues(s.enqueued)
 }
 
+// ReservedExecutions return list of executions that capacity is reserved for
+func (s *ExecutorBuffer) ReservedExecutions() []store.Execution {
+	return s.mapValues(s.reserved)
+}
+
 func (s *ExecutorBuffer) mapValues(m map[string]*bufferTask) []store.Execution {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -247,3 +375,4 @@ func (s *ExecutorBuffer) mapValues(m map[string]*bufferTask) []store.Execution {
 
 // compile-time interface check
 var _ Executor = (*ExecutorBuffer)(nil)
+var _ CapacityReserver = (*ExecutorBuffer)(nil)
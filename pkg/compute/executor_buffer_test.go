@@ -0,0 +1,77 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestExecutorBuffer(reservationTimeout time.Duration) *ExecutorBuffer {
+	return NewExecutorBuffer(ExecutorBufferParams{
+		ID: "node",
+		RunningCapacityTracker: capacity.NewLocalTracker(capacity.LocalTrackerParams{
+			MaxCapacity: model.ResourceUsageData{CPU: 1},
+		}),
+		EnqueuedCapacityTracker: capacity.NewLocalTracker(capacity.LocalTrackerParams{
+			MaxCapacity: model.ResourceUsageData{CPU: 1},
+		}),
+		ReservationTimeout: reservationTimeout,
+	})
+}
+
+func TestExecutorBufferReservesCapacity(t *testing.T) {
+	ctx := context.Background()
+	buffer := newTestExecutorBuffer(0)
+	execution := func(id string) store.Execution {
+		return *store.NewExecution(id, model.Job{}, "requester", model.ResourceUsageData{CPU: 0.5})
+	}
+
+	require.True(t, buffer.Reserve(ctx, execution("e1")))
+	require.True(t, buffer.Reserve(ctx, execution("e1")), "reserving again keeps the reservation")
+	require.True(t, buffer.Reserve(ctx, execution("e2")))
+	require.False(t, buffer.Reserve(ctx, execution("e3")), "all the capacity is reserved")
+
+	buffer.Release(ctx, "e1")
+	buffer.Release(ctx, "e1")
+	require.True(t, buffer.Reserve(ctx, execution("e3")), "releasing frees the capacity once")
+	require.False(t, buffer.Reserve(ctx, execution("e4")))
+}
+
+func TestExecutorBufferReservationsExpire(t *testing.T) {
+	ctx := context.Background()
+	buffer := newTestExecutorBuffer(10 * time.Millisecond)
+	execution := func(id string) store.Execution {
+		return *store.NewExecution(id, model.Job{}, "requester", model.ResourceUsageData{CPU: 1})
+	}
+
+	require.True(t, buffer.Reserve(ctx, execution("e1")))
+	require.False(t, buffer.Reserve(ctx, execution("e2")))
+	require.Eventually(t, func() bool {
+		return buffer.Reserve(ctx, execution("e2"))
+	}, time.Second, 10*time.Millisecond, "the capacity of reservations is freed when they expire")
+}
+
+func TestExecutorBufferCapsEngineConcurrency(t *testing.T) {
+	ctx := context.Background()
+	buffer := newTestExecutorBuffer(0)
+	buffer.engineConcurrency = map[model.Engine]int{model.EngineDocker: 1}
+	execution := func(id string, engine model.Engine) store.Execution {
+		job := model.Job{Spec: model.Spec{Engine: engine}}
+		return *store.NewExecution(id, job, "requester", model.ResourceUsageData{CPU: 0.1})
+	}
+
+	require.True(t, buffer.Reserve(ctx, execution("docker1", model.EngineDocker)))
+	require.False(t, buffer.Reserve(ctx, execution("docker2", model.EngineDocker)), "docker is capped at one execution")
+	require.True(t, buffer.Reserve(ctx, execution("wasm1", model.EngineWasm)))
+	require.True(t, buffer.Reserve(ctx, execution("wasm2", model.EngineWasm)), "other engines aren't capped")
+
+	buffer.Release(ctx, "docker1")
+	require.True(t, buffer.Reserve(ctx, execution("docker2", model.EngineDocker)))
+}
@@ -0,0 +1,265 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/encryption"
+	"github.com/bacalhau-project/bacalhau/pkg/merkle"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/bacalhau-project/bacalhau/pkg/verifier"
+	"github.com/bacalhau-project/bacalhau/pkg/verifier/noop"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePublisher struct {
+	result    model.StorageSpec
+	err       error
+	published func(resultPath string)
+}
+
+func (p fakePublisher) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (p fakePublisher) PublishResult(_ context.Context, _ model.Job, _ string, resultPath string) (model.StorageSpec, error) {
+	if p.published != nil {
+		p.published(resultPath)
+	}
+	return p.result, p.err
+}
+
+func TestPublishWithAllPublishers(t *testing.T) {
+	e := NewBaseExecutor(BaseExecutorParams{
+		Publishers: model.NewMappedProvider(map[model.Publisher]publisher.Publisher{
+			model.PublisherIpfs:  fakePublisher{err: errors.New("unreachable")},
+			model.PublisherLocal: fakePublisher{result: model.StorageSpec{Name: "local"}},
+		}),
+		PublishRetry: PublishRetryOptions{Backoff: time.Millisecond},
+	})
+	job := model.Job{Spec: model.Spec{
+		Publisher:  model.PublisherIpfs,
+		Publishers: []model.Publisher{model.PublisherLocal},
+	}}
+
+	resultFolder := t.TempDir()
+	merkleRoot, err := merkle.Root(resultFolder)
+	require.NoError(t, err)
+	result, results, err := e.publish(context.Background(), job, resultFolder)
+	require.NoError(t, err, "publishing succeeds as long as one of the publishers succeeds")
+	require.Equal(t, "local", result.Name)
+	require.Equal(t, merkleRoot, result.MerkleRoot)
+	require.Equal(t, []model.PublisherResult{
+		{Publisher: model.PublisherIpfs, Error: "unreachable"},
+		{Publisher: model.PublisherLocal, Data: model.StorageSpec{Name: "local", MerkleRoot: merkleRoot}},
+	}, results)
+
+	job.Spec.Publishers = []model.Publisher{model.PublisherEstuary}
+	_, results, err = e.publish(context.Background(), job, t.TempDir())
+	require.Error(t, err, "publishing fails if all of the publishers fail")
+	require.Len(t, results, 2)
+	require.NotEmpty(t, results[1].Error, "publishers that aren't installed fail")
+}
+
+func TestPublishEncryptsResults(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	resultFolder := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(resultFolder, "stdout"), []byte("secret"), 0644))
+
+	var published []string
+	e := NewBaseExecutor(BaseExecutorParams{
+		Publishers: model.NewMappedProvider(map[model.Publisher]publisher.Publisher{
+			model.PublisherIpfs: fakePublisher{published: func(resultPath string) {
+				entries, err := os.ReadDir(resultPath)
+				require.NoError(t, err)
+				for _, entry := range entries {
+					published = append(published, entry.Name())
+				}
+			}},
+		}),
+	})
+	job := model.Job{Spec: model.Spec{
+		Publisher:  model.PublisherIpfs,
+		Encryption: model.EncryptionConfig{Recipients: []string{identity.Recipient().String()}},
+	}}
+
+	_, _, err = e.publish(context.Background(), job, resultFolder)
+	require.NoError(t, err)
+	require.Equal(t, []string{encryption.ResultsFilename}, published, "only the encrypted results are published")
+}
+
+// flakyPublisher fails to publish until it has been called enough times.
+type flakyPublisher struct {
+	failures int32
+	calls    *atomic.Int32
+}
+
+func (p flakyPublisher) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (p flakyPublisher) PublishResult(context.Context, model.Job, string, string) (model.StorageSpec, error) {
+	if p.calls.Add(1) <= p.failures {
+		return model.StorageSpec{}, errors.New("connection reset")
+	}
+	return model.StorageSpec{Name: "published"}, nil
+}
+
+func TestPublishRetriesFailedPublishers(t *testing.T) {
+	calls := new(atomic.Int32)
+	e := NewBaseExecutor(BaseExecutorParams{
+		Publishers: model.NewMappedProvider(map[model.Publisher]publisher.Publisher{
+			model.PublisherIpfs: flakyPublisher{failures: 2, calls: calls},
+		}),
+		PublishRetry: PublishRetryOptions{Attempts: 3, Backoff: time.Millisecond},
+	})
+
+	result, _, err := e.publish(context.Background(), model.Job{Spec: model.Spec{Publisher: model.PublisherIpfs}}, t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, "published", result.Name)
+	require.Equal(t, int32(3), calls.Load())
+}
+
+// publishCallback records the publishing callbacks of executions.
+type publishCallback struct {
+	pending   chan PublishPendingResult
+	published chan PublishResult
+	failed    chan ComputeError
+}
+
+func (c publishCallback) OnRunComplete(context.Context, RunResult) {}
+func (c publishCallback) OnPublishComplete(_ context.Context, result PublishResult) {
+	c.published <- result
+}
+func (c publishCallback) OnPublishPending(_ context.Context, result PublishPendingResult) {
+	c.pending <- result
+}
+func (c publishCallback) OnCancelComplete(context.Context, CancelResult) {}
+func (c publishCallback) OnComputeFailure(_ context.Context, err ComputeError) {
+	c.failed <- err
+}
+
+func TestPublishIsPendingUntilPublishersRecover(t *testing.T) {
+	ctx := context.Background()
+	cm := system.NewCleanupManager()
+	t.Cleanup(func() { cm.Cleanup(ctx) })
+	noopVerifier, err := noop.NewNoopVerifier(ctx, cm)
+	require.NoError(t, err)
+
+	executionStore := inmemory.NewStore()
+	callback := publishCallback{
+		pending:   make(chan PublishPendingResult, 1),
+		published: make(chan PublishResult, 1),
+		failed:    make(chan ComputeError, 1),
+	}
+	calls := new(atomic.Int32)
+	e := NewBaseExecutor(BaseExecutorParams{
+		ID:        "node",
+		Callback:  callback,
+		Store:     executionStore,
+		Verifiers: model.NewMappedProvider(map[model.Verifier]verifier.Verifier{model.VerifierNoop: noopVerifier}),
+		Publishers: model.NewMappedProvider(map[model.Publisher]publisher.Publisher{
+			model.PublisherIpfs: flakyPublisher{failures: 2, calls: calls},
+		}),
+		PublishRetry: PublishRetryOptions{Attempts: 2, Backoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond},
+	})
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: "job"},
+		Spec:     model.Spec{Verifier: model.VerifierNoop, Publisher: model.PublisherIpfs},
+	}
+	execution := *store.NewExecution("execution", job, "requester", model.ResourceUsageData{})
+	require.NoError(t, executionStore.CreateExecution(ctx, execution))
+	require.NoError(t, executionStore.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID: execution.ID,
+		NewState:    store.ExecutionStateResultAccepted,
+	}))
+
+	require.NoError(t, e.Publish(ctx, execution), "publishing is pending rather than failed")
+	pending := <-callback.pending
+	require.Equal(t, "failed to publish with Ipfs: connection reset", pending.Err)
+	current, err := executionStore.GetExecution(ctx, execution.ID)
+	require.NoError(t, err)
+	require.Equal(t, store.ExecutionStatePublishPending, current.State)
+
+	select {
+	case published := <-callback.published:
+		require.Equal(t, "published", published.PublishResult.Name)
+	case failure := <-callback.failed:
+		require.Fail(t, "publishing failed", failure.Err)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "publishing wasn't tried again")
+	}
+	current, err = executionStore.GetExecution(ctx, execution.ID)
+	require.NoError(t, err)
+	require.Equal(t, store.ExecutionStateCompleted, current.State)
+}
+
+func TestPublishSignsResults(t *testing.T) {
+	ctx := context.Background()
+	cm := system.NewCleanupManager()
+	t.Cleanup(func() { cm.Cleanup(ctx) })
+	noopVerifier, err := noop.NewNoopVerifier(ctx, cm)
+	require.NoError(t, err)
+	key, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	require.NoError(t, err)
+	nodeID, err := peer.IDFromPrivateKey(key)
+	require.NoError(t, err)
+
+	executionStore := inmemory.NewStore()
+	callback := publishCallback{
+		published: make(chan PublishResult, 1),
+		failed:    make(chan ComputeError, 1),
+	}
+	e := NewBaseExecutor(BaseExecutorParams{
+		ID:        nodeID.String(),
+		Callback:  callback,
+		Store:     executionStore,
+		Verifiers: model.NewMappedProvider(map[model.Verifier]verifier.Verifier{model.VerifierNoop: noopVerifier}),
+		Publishers: model.NewMappedProvider(map[model.Publisher]publisher.Publisher{
+			model.PublisherIpfs: fakePublisher{result: model.StorageSpec{CID: "QmResult"}},
+		}),
+		SigningKey: key,
+	})
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: "job"},
+		Spec:     model.Spec{Verifier: model.VerifierNoop, Publisher: model.PublisherIpfs},
+	}
+	resultFolder, err := noopVerifier.GetResultPath(ctx, job)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(resultFolder, model.DownloadFilenameExitCode), []byte("3"), 0o600))
+	execution := *store.NewExecution("execution", job, "requester", model.ResourceUsageData{})
+	require.NoError(t, executionStore.CreateExecution(ctx, execution))
+	require.NoError(t, executionStore.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID: execution.ID,
+		NewState:    store.ExecutionStateResultAccepted,
+	}))
+
+	require.NoError(t, e.Publish(ctx, execution))
+	select {
+	case published := <-callback.published:
+		require.NotNil(t, published.ResultSignature)
+		require.NoError(t, published.ResultSignature.Verify("job", nodeID.String(), published.PublishResult))
+		require.Equal(t, "QmResult", published.ResultSignature.Manifest.ResultCID)
+		require.Equal(t, 3, published.ResultSignature.Manifest.ExitCode)
+	case failure := <-callback.failed:
+		require.Fail(t, "publishing failed", failure.Err)
+	}
+}
@@ -0,0 +1,144 @@
+package compute
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/cache"
+)
+
+// Introspection is what the compute node is doing, for operators to debug a
+// single node without going through the requester node.
+type Introspection struct {
+	NodeID     string                   `json:"NodeID"`
+	Executions []ExecutionIntrospection `json:"Executions"`
+	Resources  ResourceIntrospection    `json:"Resources"`
+	// StorageCache is what the node keeps of the inputs of earlier executions
+	StorageCache []cache.Entry `json:"StorageCache"`
+	// Bids are the latest bid decisions of the node, latest first
+	Bids []BidDecision `json:"Bids"`
+}
+
+// ExecutionIntrospection is an active execution of the compute node.
+type ExecutionIntrospection struct {
+	ExecutionID string `json:"ExecutionID"`
+	JobID       string `json:"JobID"`
+	ClientID    string `json:"ClientID,omitempty"`
+	Engine      string `json:"Engine"`
+	State       string `json:"State"`
+	// Buffer is whether the execution is running, enqueued to run, or has
+	// capacity reserved for it, if the node is running it
+	Buffer        string                  `json:"Buffer,omitempty"`
+	ResourceUsage model.ResourceUsageData `json:"ResourceUsage"`
+	LatestComment string                  `json:"LatestComment,omitempty"`
+	CreateTime    time.Time               `json:"CreateTime"`
+	UpdateTime    time.Time               `json:"UpdateTime"`
+}
+
+const (
+	bufferRunning  = "running"
+	bufferEnqueued = "enqueued"
+	bufferReserved = "reserved"
+)
+
+// ResourceIntrospection is how the resources of the compute node are used.
+type ResourceIntrospection struct {
+	// Total is what the node runs executions with
+	Total model.ResourceUsageData `json:"Total"`
+	// Running is what the running executions were allocated
+	Running model.ResourceUsageData `json:"Running"`
+	// Reserved is what is held for executions of gangs until they start
+	Reserved model.ResourceUsageData `json:"Reserved"`
+	// Enqueued is what the executions that wait to run will need
+	Enqueued model.ResourceUsageData `json:"Enqueued"`
+	// Available is what is left for executions to run with
+	Available model.ResourceUsageData `json:"Available"`
+}
+
+type IntrospectorParams struct {
+	ID                     string
+	Store                  store.ExecutionStore
+	ExecutorBuffer         *ExecutorBuffer
+	RunningCapacityTracker capacity.Tracker
+	// StorageCache keeps the inputs of executions, if set
+	StorageCache *cache.Cache
+	// BidHistory keeps the latest bid decisions of the node, if set
+	BidHistory *BidHistory
+}
+
+// Introspector gathers what the compute node is doing.
+type Introspector struct {
+	id                     string
+	store                  store.ExecutionStore
+	executorBuffer         *ExecutorBuffer
+	runningCapacityTracker capacity.Tracker
+	storageCache           *cache.Cache
+	bidHistory             *BidHistory
+}
+
+func NewIntrospector(params IntrospectorParams) *Introspector {
+	return &Introspector{
+		id:                     params.ID,
+		store:                  params.Store,
+		executorBuffer:         params.ExecutorBuffer,
+		runningCapacityTracker: params.RunningCapacityTracker,
+		storageCache:           params.StorageCache,
+		bidHistory:             params.BidHistory,
+	}
+}
+
+func (i *Introspector) Introspect(ctx context.Context) (Introspection, error) {
+	executions, err := i.store.GetActiveExecutions(ctx)
+	if err != nil {
+		return Introspection{}, err
+	}
+
+	// the buffer of each execution, and what the executions in each buffer use
+	buffers := make(map[string]string)
+	allocated := func(buffer string, bufferExecutions []store.Execution) model.ResourceUsageData {
+		var usage model.ResourceUsageData
+		for _, execution := range bufferExecutions {
+			buffers[execution.ID] = buffer
+			usage = usage.Add(execution.ResourceUsage)
+		}
+		return usage
+	}
+	resources := ResourceIntrospection{
+		Total:     i.runningCapacityTracker.GetMaxCapacity(ctx),
+		Running:   allocated(bufferRunning, i.executorBuffer.RunningExecutions()),
+		Reserved:  allocated(bufferReserved, i.executorBuffer.ReservedExecutions()),
+		Enqueued:  allocated(bufferEnqueued, i.executorBuffer.EnqueuedExecutions()),
+		Available: i.runningCapacityTracker.GetAvailableCapacity(ctx),
+	}
+
+	introspections := make([]ExecutionIntrospection, 0, len(executions))
+	for _, execution := range executions {
+		introspections = append(introspections, ExecutionIntrospection{
+			ExecutionID:   execution.ID,
+			JobID:         execution.Job.Metadata.ID,
+			ClientID:      execution.Job.Metadata.ClientID,
+			Engine:        execution.Job.Spec.Engine.String(),
+			State:         execution.State.String(),
+			Buffer:        buffers[execution.ID],
+			ResourceUsage: execution.ResourceUsage,
+			LatestComment: execution.LatestComment,
+			CreateTime:    execution.CreateTime,
+			UpdateTime:    execution.UpdateTime,
+		})
+	}
+	sort.Slice(introspections, func(a, b int) bool {
+		return introspections[a].CreateTime.Before(introspections[b].CreateTime)
+	})
+
+	return Introspection{
+		NodeID:       i.id,
+		Executions:   introspections,
+		Resources:    resources,
+		StorageCache: i.storageCache.Entries(),
+		Bids:         i.bidHistory.Recent(),
+	}, nil
+}
@@ -0,0 +1,63 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBidHistoryKeepsLatestDecisions(t *testing.T) {
+	history := NewBidHistory(2)
+	for _, jobID := range []string{"first", "second", "third"} {
+		history.Record(BidDecision{JobID: jobID})
+	}
+	recent := history.Recent()
+	require.Len(t, recent, 2)
+	require.Equal(t, "third", recent[0].JobID)
+	require.Equal(t, "second", recent[1].JobID)
+
+	var disabled *BidHistory
+	disabled.Record(BidDecision{JobID: "first"})
+	require.Empty(t, disabled.Recent())
+}
+
+func TestIntrospectorShowsExecutionsAndResources(t *testing.T) {
+	ctx := context.Background()
+	executionStore := inmemory.NewStore()
+	buffer := newTestExecutorBuffer(0)
+	history := NewBidHistory(DefaultBidHistorySize)
+
+	job := model.Job{Metadata: model.Metadata{ID: "job", ClientID: "client"}, Spec: model.Spec{Engine: model.EngineWasm}}
+	execution := store.NewExecution("execution", job, "requester", model.ResourceUsageData{CPU: 0.5})
+	require.NoError(t, executionStore.CreateExecution(ctx, *execution))
+	require.True(t, buffer.Reserve(ctx, *execution))
+	history.Record(BidDecision{JobID: "job", ExecutionID: "execution", Bid: true})
+	history.Record(BidDecision{JobID: "other", Reason: "not enough capacity"})
+
+	introspection, err := NewIntrospector(IntrospectorParams{
+		ID:                     "node",
+		Store:                  executionStore,
+		ExecutorBuffer:         buffer,
+		RunningCapacityTracker: buffer.runningCapacity,
+		BidHistory:             history,
+	}).Introspect(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, "node", introspection.NodeID)
+	require.Len(t, introspection.Executions, 1)
+	require.Equal(t, "job", introspection.Executions[0].JobID)
+	require.Equal(t, "client", introspection.Executions[0].ClientID)
+	require.Equal(t, bufferReserved, introspection.Executions[0].Buffer)
+	require.Equal(t, model.ResourceUsageData{CPU: 1}, introspection.Resources.Total)
+	require.Equal(t, model.ResourceUsageData{CPU: 0.5}, introspection.Resources.Reserved)
+	require.Equal(t, model.ResourceUsageData{CPU: 0.5}, introspection.Resources.Available)
+	require.Empty(t, introspection.StorageCache, "the node has no storage cache")
+	require.Len(t, introspection.Bids, 2)
+	require.Equal(t, "not enough capacity", introspection.Bids[0].Reason)
+}
@@ -6,6 +6,9 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
 	"github.com/bacalhau-project/bacalhau/pkg/executor"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/locality"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/rs/zerolog/log"
 )
 
 type NodeInfoProviderParams struct {
@@ -13,6 +16,22 @@ type NodeInfoProviderParams struct {
 	CapacityTracker    capacity.Tracker
 	ExecutorBuffer     *ExecutorBuffer
 	MaxJobRequirements model.ResourceUsageData
+	// StoragePath is where inputs are prepared, whose disk space is reported
+	StoragePath string
+	// Preemptible is whether the requester may preempt executions on the node
+	Preemptible bool
+	// LocalData tracks the inputs that the node holds locally, if set
+	LocalData *locality.Tracker
+	// Pricing is what the node charges for the resources executions use
+	Pricing model.Pricing
+	// GPUs are the model and memory of each GPU of the node
+	GPUs []model.GPU
+	// Taints keep the jobs that don't tolerate them off the node
+	Taints []model.Taint
+	// TEE is the kind of TEE that the node runs in, if any
+	TEE model.TEEType
+	// Benchmarks provide how fast the node measured itself to be, if set
+	Benchmarks BenchmarkProvider
 }
 
 type NodeInfoProvider struct {
@@ -20,6 +39,14 @@ type NodeInfoProvider struct {
 	capacityTracker    capacity.Tracker
 	executorBuffer     *ExecutorBuffer
 	maxJobRequirements model.ResourceUsageData
+	storagePath        string
+	preemptible        bool
+	localData          *locality.Tracker
+	pricing            model.Pricing
+	gpus               []model.GPU
+	taints             []model.Taint
+	tee                model.TEEType
+	benchmarks         BenchmarkProvider
 }
 
 func NewNodeInfoProvider(params NodeInfoProviderParams) *NodeInfoProvider {
@@ -28,6 +55,14 @@ func NewNodeInfoProvider(params NodeInfoProviderParams) *NodeInfoProvider {
 		capacityTracker:    params.CapacityTracker,
 		executorBuffer:     params.ExecutorBuffer,
 		maxJobRequirements: params.MaxJobRequirements,
+		storagePath:        params.StoragePath,
+		preemptible:        params.Preemptible,
+		localData:          params.LocalData,
+		pricing:            params.Pricing,
+		gpus:               params.GPUs,
+		taints:             params.Taints,
+		tee:                params.TEE,
+		benchmarks:         params.Benchmarks,
 	}
 }
 
@@ -39,6 +74,20 @@ func (n *NodeInfoProvider) GetComputeInfo(ctx context.Context) model.ComputeNode
 		}
 	}
 
+	var scratchDisk model.DiskSpace
+	if n.storagePath != "" {
+		space, err := util.DiskSpace(n.storagePath)
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Unable to get scratch disk space")
+		}
+		scratchDisk = space
+	}
+
+	var benchmark model.NodeBenchmark
+	if n.benchmarks != nil {
+		benchmark = n.benchmarks.GetBenchmark()
+	}
+
 	return model.ComputeNodeInfo{
 		ExecutionEngines:   executionEngines,
 		MaxCapacity:        n.capacityTracker.GetMaxCapacity(ctx),
@@ -46,6 +95,14 @@ func (n *NodeInfoProvider) GetComputeInfo(ctx context.Context) model.ComputeNode
 		MaxJobRequirements: n.maxJobRequirements,
 		RunningExecutions:  len(n.executorBuffer.RunningExecutions()),
 		EnqueuedExecutions: len(n.executorBuffer.EnqueuedExecutions()),
+		ScratchDisk:        scratchDisk,
+		Preemptible:        n.preemptible,
+		LocalCIDs:          n.localData.CIDs(),
+		Pricing:            n.pricing,
+		GPUs:               n.gpus,
+		Taints:             n.taints,
+		TEE:                n.tee,
+		Benchmark:          benchmark,
 	}
 }
 
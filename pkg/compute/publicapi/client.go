@@ -2,7 +2,10 @@ package publicapi
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
@@ -37,3 +40,99 @@ func (apiClient *ComputeAPIClient) Debug(ctx context.Context) (map[string]model.
 
 	return res, nil
 }
+
+// Drain stops the node bidding on new jobs and shuts it down once its
+// executions finish, or after the timeout if they haven't, which this client
+// must be an admin of the node to do.
+func (apiClient *ComputeAPIClient) Drain(ctx context.Context, timeout time.Duration) (model.DrainStatus, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/compute/publicapi.ComputeAPIClient.Drain")
+	defer span.End()
+
+	adminRequest, err := apiClient.NewAdminRequest(ctx)
+	if err != nil {
+		return model.DrainStatus{}, err
+	}
+	payload := model.DrainPayload{
+		ClientID:     system.GetClientID(),
+		AdminRequest: adminRequest,
+		Timeout:      timeout.Seconds(),
+	}
+	var res model.DrainStatus
+	err = apiClient.postSigned(ctx, APIPrefix+"drain", payload, &res)
+	return res, err
+}
+
+// DrainStatus returns whether the node is draining, and how many executions
+// it has left to finish.
+func (apiClient *ComputeAPIClient) DrainStatus(ctx context.Context) (model.DrainStatus, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/compute/publicapi.ComputeAPIClient.DrainStatus")
+	defer span.End()
+
+	var res model.DrainStatus
+	err := apiClient.Post(ctx, APIPrefix+"drain/status", struct{}{}, &res)
+	return res, err
+}
+
+// OverrideBiddingWindows opens or closes bidding on the node until the
+// override ends, whatever its bidding windows are, or follows the windows again
+// if the override is nil, which this client must be an admin of the node to do.
+func (apiClient *ComputeAPIClient) OverrideBiddingWindows(
+	ctx context.Context, override *model.BiddingWindowOverride) (model.BiddingWindowStatus, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/compute/publicapi.ComputeAPIClient.OverrideBiddingWindows")
+	defer span.End()
+
+	adminRequest, err := apiClient.NewAdminRequest(ctx)
+	if err != nil {
+		return model.BiddingWindowStatus{}, err
+	}
+	payload := model.BiddingWindowPayload{
+		ClientID:     system.GetClientID(),
+		AdminRequest: adminRequest,
+		Override:     override,
+	}
+	var res model.BiddingWindowStatus
+	err = apiClient.postSigned(ctx, APIPrefix+"bidding-window", payload, &res)
+	return res, err
+}
+
+// BiddingWindowStatus returns whether the node bids on jobs now, given its
+// bidding windows and any override of them.
+func (apiClient *ComputeAPIClient) BiddingWindowStatus(ctx context.Context) (model.BiddingWindowStatus, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/compute/publicapi.ComputeAPIClient.BiddingWindowStatus")
+	defer span.End()
+
+	var res model.BiddingWindowStatus
+	err := apiClient.Post(ctx, APIPrefix+"bidding-window/status", struct{}{}, &res)
+	return res, err
+}
+
+// Introspect returns what the node is doing, which only clients on the node
+// itself can see.
+func (apiClient *ComputeAPIClient) Introspect(ctx context.Context) (compute.Introspection, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/compute/publicapi.ComputeAPIClient.Introspect")
+	defer span.End()
+
+	var res compute.Introspection
+	err := apiClient.Post(ctx, APIPrefix+"introspect", struct{}{}, &res)
+	return res, err
+}
+
+func (apiClient *ComputeAPIClient) postSigned(ctx context.Context, api string, payload any, res any) error {
+	jsonData, err := model.JSONMarshalWithMax(payload)
+	if err != nil {
+		return err
+	}
+	rawPayloadJSON := json.RawMessage(jsonData)
+
+	signature, err := system.SignForClient(rawPayloadJSON)
+	if err != nil {
+		return err
+	}
+
+	req := publicapi.SignedRequest{
+		Payload:         &rawPayloadJSON,
+		ClientSignature: signature,
+		ClientPublicKey: system.GetClientPublicKey(),
+	}
+	return apiClient.Post(ctx, api, req, res)
+}
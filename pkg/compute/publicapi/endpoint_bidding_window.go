@@ -0,0 +1,84 @@
+package publicapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+)
+
+var errBiddingWindowsNotSupported = errors.New("this compute node does not support bidding windows")
+
+// biddingWindowRequest documents the payload of the signed request to override the bidding windows of the node.
+type biddingWindowRequest struct { //nolint:unused // Swagger wants this
+	Payload         model.BiddingWindowPayload `json:"payload" validate:"required"`
+	ClientSignature string                     `json:"signature" validate:"required"`
+	ClientPublicKey string                     `json:"client_public_key" validate:"required"`
+}
+
+// overrideBiddingWindow godoc
+//
+//	@ID				pkg/compute/publicapi/overrideBiddingWindow
+//	@Summary		Opens or closes bidding on the compute node for a while whatever its bidding windows are, which only admins can do.
+//	@Tags			Compute Node
+//	@Accept			json
+//	@Produce		json
+//	@Param			biddingWindowRequest	body		biddingWindowRequest	true	" "
+//	@Success		200						{object}	model.BiddingWindowStatus
+//	@Failure		400						{object}	string
+//	@Failure		403						{object}	string
+//	@Router			/compute/bidding-window [post]
+func (s *ComputeAPIServer) overrideBiddingWindow(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.biddingWindows == nil {
+		publicapi.HTTPError(ctx, res, errBiddingWindowsNotSupported, http.StatusNotImplemented)
+		return
+	}
+	payload, err := publicapi.UnmarshalSigned[model.BiddingWindowPayload](req.Body)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	// we know the admin is who signed the request
+	if !s.biddingWindows.IsAdmin(payload.ClientID) {
+		publicapi.HTTPError(ctx, res, fmt.Errorf("client %s can't override the bidding windows of the node", payload.ClientID),
+			http.StatusForbidden)
+		return
+	}
+	if err := s.apiServer.AcceptAdminRequest(payload.AdminRequest); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusForbidden)
+		return
+	}
+	writeBiddingWindowStatus(ctx, res, s.biddingWindows.Override(payload.Override))
+}
+
+// biddingWindowStatus godoc
+//
+//	@ID				pkg/compute/publicapi/biddingWindowStatus
+//	@Summary		Returns whether the compute node bids on jobs now, given its bidding windows and any override of them.
+//	@Tags			Compute Node
+//	@Produce		json
+//	@Success		200	{object}	model.BiddingWindowStatus
+//	@Router			/compute/bidding-window/status [post]
+func (s *ComputeAPIServer) biddingWindowStatus(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.biddingWindows == nil {
+		publicapi.HTTPError(ctx, res, errBiddingWindowsNotSupported, http.StatusNotImplemented)
+		return
+	}
+	writeBiddingWindowStatus(ctx, res, s.biddingWindows.Status())
+}
+
+func writeBiddingWindowStatus(ctx context.Context, res http.ResponseWriter, status model.BiddingWindowStatus) {
+	res.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(res).Encode(status); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
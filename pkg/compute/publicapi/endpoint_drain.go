@@ -0,0 +1,101 @@
+package publicapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+)
+
+var errDrainNotSupported = errors.New("this compute node does not support draining")
+
+// drainRequest documents the payload of the signed request to drain the node.
+type drainRequest struct { //nolint:unused // Swagger wants this
+	Payload         model.DrainPayload `json:"payload" validate:"required"`
+	ClientSignature string             `json:"signature" validate:"required"`
+	ClientPublicKey string             `json:"client_public_key" validate:"required"`
+}
+
+// drain godoc
+//
+//	@ID				pkg/compute/publicapi/drain
+//	@Summary		Stops the compute node bidding on new jobs and shuts it down once its executions finish, which only admins can do.
+//	@Tags			Compute Node
+//	@Accept			json
+//	@Produce		json
+//	@Param			drainRequest	body		drainRequest	true	" "
+//	@Success		200				{object}	model.DrainStatus
+//	@Failure		400				{object}	string
+//	@Failure		403				{object}	string
+//	@Failure		500				{object}	string
+//	@Router			/compute/drain [post]
+func (s *ComputeAPIServer) drain(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.drainer == nil {
+		publicapi.HTTPError(ctx, res, errDrainNotSupported, http.StatusNotImplemented)
+		return
+	}
+	payload, err := publicapi.UnmarshalSigned[model.DrainPayload](req.Body)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	// we know the admin is who signed the request
+	if !s.drainer.IsAdmin(payload.ClientID) {
+		publicapi.HTTPError(ctx, res, fmt.Errorf("client %s can't drain the node", payload.ClientID), http.StatusForbidden)
+		return
+	}
+	if err := s.apiServer.AcceptAdminRequest(payload.AdminRequest); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusForbidden)
+		return
+	}
+	if payload.Timeout < 0 {
+		publicapi.HTTPError(ctx, res, fmt.Errorf("the drain timeout must be >= 0"), http.StatusBadRequest)
+		return
+	}
+
+	status, err := s.drainer.Drain(ctx, time.Duration(payload.Timeout*float64(time.Second)))
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+	writeDrainStatus(ctx, res, status)
+}
+
+// drainStatus godoc
+//
+//	@ID				pkg/compute/publicapi/drainStatus
+//	@Summary		Returns whether the compute node is draining, and how many executions it has left to finish.
+//	@Tags			Compute Node
+//	@Produce		json
+//	@Success		200	{object}	model.DrainStatus
+//	@Failure		500	{object}	string
+//	@Router			/compute/drain/status [post]
+func (s *ComputeAPIServer) drainStatus(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.drainer == nil {
+		publicapi.HTTPError(ctx, res, errDrainNotSupported, http.StatusNotImplemented)
+		return
+	}
+	status, err := s.drainer.Status(ctx)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+	writeDrainStatus(ctx, res, status)
+}
+
+func writeDrainStatus(ctx context.Context, res http.ResponseWriter, status model.DrainStatus) {
+	res.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(res).Encode(status); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,55 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+)
+
+var errIntrospectNotSupported = errors.New("this compute node does not support introspection")
+
+// introspect godoc
+//
+//	@ID				pkg/compute/publicapi/introspect
+//	@Summary		Returns the active executions of the compute node, how its resources are used, what its storage cache keeps and its latest bid decisions. Only clients on the node itself can introspect it.
+//	@Tags			Compute Node
+//	@Produce		json
+//	@Success		200	{object}	compute.Introspection
+//	@Failure		403	{object}	string
+//	@Failure		500	{object}	string
+//	@Router			/compute/introspect [get]
+func (s *ComputeAPIServer) introspect(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.introspector == nil {
+		publicapi.HTTPError(ctx, res, errIntrospectNotSupported, http.StatusNotImplemented)
+		return
+	}
+	// what the node is doing includes the jobs and inputs of its clients, so it is only shown to operators of the node
+	if !isLocalRequest(req) {
+		publicapi.HTTPError(ctx, res, errors.New("the compute node can only be introspected from the node itself"), http.StatusForbidden)
+		return
+	}
+
+	introspection, err := s.introspector.Introspect(ctx)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(res).Encode(introspection); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
+
+// isLocalRequest returns whether the request came from the loopback interface.
+func isLocalRequest(req *http.Request) bool {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
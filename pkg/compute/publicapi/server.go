@@ -3,6 +3,7 @@ package publicapi
 import (
 	"net/http"
 
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
 )
@@ -12,23 +13,40 @@ const APIPrefix = "compute/"
 type ComputeAPIServerParams struct {
 	APIServer          *publicapi.APIServer
 	DebugInfoProviders []model.DebugInfoProvider
+	// Drainer drains the node, if it can be
+	Drainer *compute.Drainer
+	// Introspector shows what the node is doing, if it can be introspected
+	Introspector *compute.Introspector
+	// BiddingWindows are when the node bids on jobs, if they can be overridden
+	BiddingWindows *compute.BiddingWindows
 }
 
 type ComputeAPIServer struct {
 	apiServer          *publicapi.APIServer
 	debugInfoProviders []model.DebugInfoProvider
+	drainer            *compute.Drainer
+	introspector       *compute.Introspector
+	biddingWindows     *compute.BiddingWindows
 }
 
 func NewComputeAPIServer(params ComputeAPIServerParams) *ComputeAPIServer {
 	return &ComputeAPIServer{
 		apiServer:          params.APIServer,
 		debugInfoProviders: params.DebugInfoProviders,
+		drainer:            params.Drainer,
+		introspector:       params.Introspector,
+		biddingWindows:     params.BiddingWindows,
 	}
 }
 
 func (s *ComputeAPIServer) RegisterAllHandlers() error {
 	handlerConfigs := []publicapi.HandlerConfig{
 		{URI: "/" + APIPrefix + "debug", Handler: http.HandlerFunc(s.debug)},
+		{URI: "/" + APIPrefix + "drain", Handler: http.HandlerFunc(s.drain)},
+		{URI: "/" + APIPrefix + "drain/status", Handler: http.HandlerFunc(s.drainStatus)},
+		{URI: "/" + APIPrefix + "introspect", Handler: http.HandlerFunc(s.introspect)},
+		{URI: "/" + APIPrefix + "bidding-window", Handler: http.HandlerFunc(s.overrideBiddingWindow)},
+		{URI: "/" + APIPrefix + "bidding-window/status", Handler: http.HandlerFunc(s.biddingWindowStatus)},
 	}
 	return s.apiServer.RegisterHandlers(handlerConfigs...)
 }
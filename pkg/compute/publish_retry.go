@@ -0,0 +1,77 @@
+package compute
+
+import (
+	"context"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/rs/zerolog/log"
+)
+
+// PublishRetryOptions configure how publishing results is retried, so that a
+// transient failure of a publisher or the network after a long execution
+// doesn't lose its results.
+type PublishRetryOptions struct {
+	// Attempts is how many times results are published with each publisher
+	// before publishing is left pending.
+	Attempts int
+	// Backoff is how long to wait after the first failed attempt, which
+	// doubles after each attempt up to MaxBackoff.
+	Backoff time.Duration
+	// MaxBackoff is the most to wait between attempts, and how long to wait
+	// before trying to publish pending results again.
+	MaxBackoff time.Duration
+	// PendingTimeout is how long to keep trying to publish pending results
+	// before the execution fails.
+	PendingTimeout time.Duration
+}
+
+var DefaultPublishRetryOptions = PublishRetryOptions{
+	Attempts:       3,
+	Backoff:        time.Second,
+	MaxBackoff:     time.Minute,
+	PendingTimeout: 24 * time.Hour,
+}
+
+func (o PublishRetryOptions) withDefaults() PublishRetryOptions {
+	if o.Attempts == 0 {
+		o.Attempts = DefaultPublishRetryOptions.Attempts
+	}
+	if o.Backoff == 0 {
+		o.Backoff = DefaultPublishRetryOptions.Backoff
+	}
+	if o.MaxBackoff == 0 {
+		o.MaxBackoff = DefaultPublishRetryOptions.MaxBackoff
+	}
+	if o.PendingTimeout == 0 {
+		o.PendingTimeout = DefaultPublishRetryOptions.PendingTimeout
+	}
+	return o
+}
+
+// publishWithRetries publishes the results with the publisher, backing off
+// exponentially between attempts if it fails. Publishers are expected to be
+// idempotent, e.g. IPFS only stores the blocks it didn't already have again.
+func (e *BaseExecutor) publishWithRetries(
+	ctx context.Context,
+	jobPublisher publisher.Publisher,
+	job model.Job,
+	resultFolder string,
+) (model.StorageSpec, error) {
+	backoff := e.publishRetry.Backoff
+	for attempt := 1; ; attempt++ {
+		spec, err := jobPublisher.PublishResult(ctx, job, e.ID, resultFolder)
+		if err == nil || attempt >= e.publishRetry.Attempts {
+			return spec, err
+		}
+		log.Ctx(ctx).Warn().Err(err).Int("attempt", attempt).Dur("backoff", backoff).Msg("Failed to publish results, retrying")
+		select {
+		case <-ctx.Done():
+			return model.StorageSpec{}, err
+		case <-time.After(backoff):
+		}
+		backoff = system.Min(2*backoff, e.publishRetry.MaxBackoff)
+	}
+}
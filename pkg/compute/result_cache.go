@@ -0,0 +1,205 @@
+package compute
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/generic"
+)
+
+// ResultCacheOptions configure the cache of the results that the node has published, which lets identical jobs that
+// are submitted again reuse them rather than run again.
+type ResultCacheOptions struct {
+	// TTL is how long published results are reused for, or never if zero.
+	TTL time.Duration
+	// MaxEntries is the most results that are cached, dropping the oldest first, or unlimited if zero.
+	MaxEntries int
+	// Networked also caches the results of jobs with network access, which may depend on what the jobs fetch.
+	Networked bool
+}
+
+var DefaultResultCacheOptions = ResultCacheOptions{
+	MaxEntries: 1000,
+}
+
+type cachedResult struct {
+	runCommandResult *model.RunCommandResult
+	published        model.StorageSpec
+	publisherResults []model.PublisherResult
+	expires          time.Time
+}
+
+// resultCache maps the hash of a job to the results that were published for it. Only the results of jobs that are
+// verified by no one else are reused, as verifying by replication relies on each execution computing the results.
+type resultCache struct {
+	options ResultCacheOptions
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedResult
+
+	// the results of the executions that ran, until they are published
+	ran generic.SyncMap[string, *model.RunCommandResult]
+	// the cached results that executions reuse, until they are published
+	reused generic.SyncMap[string, cachedResult]
+}
+
+func newResultCache(options ResultCacheOptions) *resultCache {
+	if options.TTL <= 0 {
+		return nil
+	}
+	return &resultCache{
+		options: options,
+		now:     time.Now,
+		entries: make(map[string]cachedResult),
+	}
+}
+
+// resultCacheKey hashes what the results of the job depend on: what it runs, its inputs and outputs, and how the
+// results are published.
+func resultCacheKey(job model.Job) (string, error) {
+	spec := job.Spec
+	data, err := json.Marshal(struct {
+		Engine       model.Engine
+		Docker       model.JobSpecDocker
+		Language     model.JobSpecLanguage
+		Wasm         model.JobSpecWasm
+		Process      model.JobSpecProcess
+		Python       model.JobSpecPython
+		Plugin       model.JobSpecPlugin
+		DuckDB       model.JobSpecDuckDB
+		Network      model.NetworkConfig
+		Array        model.ArrayConfig
+		Inputs       []model.StorageSpec
+		Outputs      []model.StorageSpec
+		Publishers   []model.Publisher
+		Compression  model.CompressionConfig
+		Encryption   model.EncryptionConfig
+		FilecoinDeal model.FilecoinDealConfig
+	}{
+		spec.Engine, spec.Docker, spec.Language, spec.Wasm, spec.Process, spec.Python, spec.Plugin, spec.DuckDB,
+		spec.Network, spec.Array, spec.Inputs, spec.Outputs, spec.AllPublishers(), spec.Compression, spec.Encryption,
+		spec.FilecoinDeal,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cacheable is whether the results of the job are the same each time it runs, as far as the node can tell.
+func (c *resultCache) cacheable(job model.Job) bool {
+	if c == nil || job.Spec.NoCache || job.Spec.Verifier != model.VerifierNoop || job.Spec.IPNSName != "" {
+		return false
+	}
+	if !job.Spec.Network.Disabled() && !c.options.Networked {
+		return false
+	}
+	// inputs that aren't addressed by their content may change between runs
+	for _, input := range job.Spec.Inputs {
+		if input.CID == "" && input.Digest == "" && input.StorageSource != model.StorageSourceInline {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *resultCache) get(job model.Job) (cachedResult, bool) {
+	if !c.cacheable(job) {
+		return cachedResult{}, false
+	}
+	key, err := resultCacheKey(job)
+	if err != nil {
+		return cachedResult{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if ok && !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return cachedResult{}, false
+	}
+	return entry, ok
+}
+
+// reuse returns the run result of an identical job whose results were published, and remembers that the execution
+// publishes the same results.
+func (c *resultCache) reuse(executionID string, job model.Job) (*model.RunCommandResult, bool) {
+	entry, ok := c.get(job)
+	if !ok {
+		return nil, false
+	}
+	c.reused.Put(executionID, entry)
+	result := *entry.runCommandResult
+	return &result, true
+}
+
+// reusedResult returns the published results that the execution reuses, if it does.
+func (c *resultCache) reusedResult(executionID string) (cachedResult, bool) {
+	if c == nil {
+		return cachedResult{}, false
+	}
+	return c.reused.Get(executionID)
+}
+
+// runComplete remembers the result of an execution that ran, to cache once its results are published.
+func (c *resultCache) runComplete(executionID string, job model.Job, result *model.RunCommandResult) {
+	if result == nil || !c.cacheable(job) {
+		return
+	}
+	c.ran.Put(executionID, result)
+}
+
+// publishComplete caches the published results of an execution that ran.
+func (c *resultCache) publishComplete(
+	executionID string, job model.Job, published model.StorageSpec, publisherResults []model.PublisherResult) {
+	if c == nil {
+		return
+	}
+	result, ok := c.ran.Get(executionID)
+	if !ok {
+		return
+	}
+	key, err := resultCacheKey(job)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cachedResult{
+		runCommandResult: result,
+		published:        published,
+		publisherResults: publisherResults,
+		expires:          c.now().Add(c.options.TTL),
+	}
+	for c.options.MaxEntries > 0 && len(c.entries) > c.options.MaxEntries {
+		c.evictOldest()
+	}
+}
+
+// evictOldest drops the entry that expires first. It must be called with the lock held.
+func (c *resultCache) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if oldestKey == "" || entry.expires.Before(oldest) {
+			oldestKey, oldest = key, entry.expires
+		}
+	}
+	delete(c.entries, oldestKey)
+}
+
+// forget drops what is remembered about the execution once it is published, fails or is cancelled.
+func (c *resultCache) forget(executionID string) {
+	if c == nil {
+		return
+	}
+	c.ran.Delete(executionID)
+	c.reused.Delete(executionID)
+}
@@ -0,0 +1,91 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func cacheableJob(id string) model.Job {
+	return model.Job{
+		Metadata: model.Metadata{ID: id},
+		Spec: model.Spec{
+			Engine:    model.EngineWasm,
+			Verifier:  model.VerifierNoop,
+			Publisher: model.PublisherIpfs,
+			Inputs:    []model.StorageSpec{{StorageSource: model.StorageSourceIPFS, CID: "QmInput", Path: "/inputs"}},
+		},
+	}
+}
+
+func TestResultCacheReusesPublishedResults(t *testing.T) {
+	cache := newResultCache(ResultCacheOptions{TTL: time.Hour})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	first := cacheableJob("first")
+	cache.runComplete("first", first, &model.RunCommandResult{STDOUT: "hello"})
+	cache.publishComplete("first", first, model.StorageSpec{CID: "QmResult"}, nil)
+	cache.forget("first")
+
+	// an identical job submitted again, by another client or with another deal, reuses the results
+	second := cacheableJob("second")
+	second.Metadata.ClientID = "another-client"
+	second.Spec.Deal = model.Deal{Concurrency: 1}
+	result, ok := cache.reuse("second", second)
+	require.True(t, ok)
+	require.Equal(t, "hello", result.STDOUT)
+	reused, ok := cache.reusedResult("second")
+	require.True(t, ok)
+	require.Equal(t, "QmResult", reused.published.CID)
+
+	different := cacheableJob("different")
+	different.Spec.Inputs[0].CID = "QmOtherInput"
+	_, ok = cache.reuse("different", different)
+	require.False(t, ok, "jobs with other inputs run")
+
+	now = now.Add(time.Hour)
+	_, ok = cache.reuse("third", cacheableJob("third"))
+	require.False(t, ok, "results are reused until they expire")
+}
+
+func TestResultCacheOnlyCachesDeterministicJobs(t *testing.T) {
+	cache := newResultCache(ResultCacheOptions{TTL: time.Hour})
+	require.True(t, cache.cacheable(cacheableJob("job")))
+
+	for name, change := range map[string]func(*model.Spec){
+		"opted out":      func(spec *model.Spec) { spec.NoCache = true },
+		"verified":       func(spec *model.Spec) { spec.Verifier = model.VerifierDeterministic },
+		"IPNS name":      func(spec *model.Spec) { spec.IPNSName = "latest" },
+		"network access": func(spec *model.Spec) { spec.Network.Type = model.NetworkFull },
+		"URL input": func(spec *model.Spec) {
+			spec.Inputs = []model.StorageSpec{{StorageSource: model.StorageSourceURLDownload, URL: "https://example.com"}}
+		},
+	} {
+		job := cacheableJob("job")
+		change(&job.Spec)
+		require.False(t, cache.cacheable(job), name)
+	}
+
+	require.Nil(t, newResultCache(ResultCacheOptions{}), "results aren't cached without a TTL")
+}
+
+func TestResultCacheEvictsOldestEntries(t *testing.T) {
+	cache := newResultCache(ResultCacheOptions{TTL: time.Hour, MaxEntries: 1})
+	first, second := cacheableJob("first"), cacheableJob("second")
+	second.Spec.Inputs[0].CID = "QmSecond"
+
+	cache.runComplete("first", first, &model.RunCommandResult{})
+	cache.publishComplete("first", first, model.StorageSpec{}, nil)
+	cache.runComplete("second", second, &model.RunCommandResult{})
+	cache.publishComplete("second", second, model.StorageSpec{}, nil)
+
+	_, ok := cache.get(first)
+	require.False(t, ok)
+	_, ok = cache.get(second)
+	require.True(t, ok)
+}
@@ -0,0 +1,254 @@
+package compute
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/c2h5oh/datasize"
+	"github.com/rs/zerolog/log"
+)
+
+// ScratchGCOptions configure how the compute node removes the scratch space
+// that executions left behind, such as when the node crashed or the process
+// of an execution was killed before it cleaned up.
+type ScratchGCOptions struct {
+	// Interval is how often to look for scratch space to remove, or never if
+	// negative.
+	Interval time.Duration
+	// MinAge is how long scratch space must have gone unmodified before it
+	// is removed.
+	MinAge time.Duration
+	// DiskPressure is the fraction of the disk of the scratch space that,
+	// once used, has scratch space removed however recently it was modified.
+	DiskPressure float64
+}
+
+var DefaultScratchGCOptions = ScratchGCOptions{
+	Interval:     10 * time.Minute,
+	MinAge:       6 * time.Hour,
+	DiskPressure: 0.9,
+}
+
+func (o ScratchGCOptions) withDefaults() ScratchGCOptions {
+	if o.Interval == 0 {
+		o.Interval = DefaultScratchGCOptions.Interval
+	}
+	if o.MinAge == 0 {
+		o.MinAge = DefaultScratchGCOptions.MinAge
+	}
+	if o.DiskPressure == 0 {
+		o.DiskPressure = DefaultScratchGCOptions.DiskPressure
+	}
+	return o
+}
+
+// resultsDirPrefix is the prefix of the directories that verifiers keep the
+// results of executions in, with a directory for each job.
+const resultsDirPrefix = "bacalhau-results"
+
+// scratchPrefixes are the prefixes of the other temporary directories and
+// files that executions create, which they remove once they finish.
+var scratchPrefixes = []string{
+	"bacalhau-filtered-results-",
+	"bacalhau-compressed-results-",
+	"bacalhau-encrypted-results-",
+	"bacalhau-process-",
+	"bacalhau-duckdb-",
+	"bacalhau-python-",
+	"bacalhau-stdout-",
+	"bacalhau-stderr-",
+}
+
+type ScratchGCParams struct {
+	Store   store.ExecutionStore
+	Options ScratchGCOptions
+	// Dir is where executions keep their scratch space, which is the
+	// temporary directory if empty.
+	Dir string
+}
+
+// ScratchGC periodically removes the scratch space of executions that is no
+// longer used, which would otherwise accumulate until the disk fills.
+// The results of a job are orphaned once the node has no active execution of
+// the job, and other scratch space is orphaned if it was last modified before
+// the oldest active execution was created, as no active execution can be
+// using it. Orphaned scratch space is removed once it hasn't been modified
+// for the minimum age, or straight away while the disk is under pressure.
+type ScratchGC struct {
+	store   store.ExecutionStore
+	options ScratchGCOptions
+	dir     string
+	now     func() time.Time
+}
+
+func NewScratchGC(params ScratchGCParams) *ScratchGC {
+	dir := params.Dir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return &ScratchGC{
+		store:   params.Store,
+		options: params.Options.withDefaults(),
+		dir:     dir,
+		now:     time.Now,
+	}
+}
+
+func (gc *ScratchGC) Start(ctx context.Context) {
+	if gc.options.Interval < 0 {
+		return
+	}
+	log.Ctx(ctx).Debug().Msgf("starting scratch space GC of %s with interval %s", gc.dir, gc.options.Interval)
+	ticker := time.NewTicker(gc.options.Interval)
+	defer ticker.Stop()
+	for {
+		gc.collect(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// collect removes the orphaned scratch space, returning how much it freed.
+func (gc *ScratchGC) collect(ctx context.Context) uint64 {
+	executions, err := gc.store.GetActiveExecutions(ctx)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to get the active executions to remove orphaned scratch space")
+		return 0
+	}
+	activeJobs := make(map[string]bool, len(executions))
+	var oldestActive time.Time
+	for _, execution := range executions {
+		activeJobs[execution.Job.ID()] = true
+		if oldestActive.IsZero() || execution.CreateTime.Before(oldestActive) {
+			oldestActive = execution.CreateTime
+		}
+	}
+
+	entries, err := os.ReadDir(gc.dir)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msgf("Failed to list the scratch space in %s", gc.dir)
+		return 0
+	}
+	minModified := gc.now().Add(-gc.options.MinAge)
+	if gc.underPressure(ctx) {
+		minModified = gc.now()
+	}
+
+	var freed uint64
+	for _, entry := range entries {
+		path := filepath.Join(gc.dir, entry.Name())
+		switch {
+		case strings.HasPrefix(entry.Name(), resultsDirPrefix) && entry.IsDir():
+			freed += gc.collectResults(ctx, path, activeJobs, minModified)
+		case hasScratchPrefix(entry.Name()):
+			freed += gc.remove(ctx, path, func(modified time.Time) bool {
+				return modified.Before(minModified) && (oldestActive.IsZero() || modified.Before(oldestActive))
+			})
+		}
+	}
+	if freed > 0 {
+		log.Ctx(ctx).Info().Msgf("Removed %s of orphaned scratch space", datasize.ByteSize(freed).HR())
+	}
+	return freed
+}
+
+// collectResults removes the results of the jobs that the node has no active
+// executions of, and the results directory once it is empty.
+func (gc *ScratchGC) collectResults(ctx context.Context, dir string, activeJobs map[string]bool, minModified time.Time) uint64 {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msgf("Failed to list the results in %s", dir)
+		return 0
+	}
+	var freed uint64
+	for _, entry := range entries {
+		if activeJobs[entry.Name()] {
+			continue
+		}
+		freed += gc.remove(ctx, filepath.Join(dir, entry.Name()), func(modified time.Time) bool {
+			return modified.Before(minModified)
+		})
+	}
+	// verifiers create the results directory again when they need it
+	if info, err := os.Stat(dir); err == nil && info.ModTime().Before(minModified) {
+		_ = os.Remove(dir)
+	}
+	return freed
+}
+
+// remove removes the file or directory if orphaned says it is, given when
+// anything in it was last modified, returning how much it freed.
+func (gc *ScratchGC) remove(ctx context.Context, path string, orphaned func(modified time.Time) bool) uint64 {
+	modified, size, err := lastModified(path)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msgf("Failed to check the scratch space %s", path)
+		return 0
+	}
+	if !orphaned(modified) {
+		return 0
+	}
+	if err := os.RemoveAll(path); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msgf("Failed to remove the orphaned scratch space %s", path)
+		return 0
+	}
+	log.Ctx(ctx).Debug().Msgf("Removed the orphaned scratch space %s last modified at %s", path, modified)
+	return size
+}
+
+func (gc *ScratchGC) underPressure(ctx context.Context) bool {
+	space, err := util.DiskSpace(gc.dir)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msgf("Failed to check the disk space of %s", gc.dir)
+		return false
+	}
+	return float64(space.Total-space.Free) >= gc.options.DiskPressure*float64(space.Total)
+}
+
+func hasScratchPrefix(name string) bool {
+	for _, prefix := range scratchPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastModified returns when anything in the file or directory was last
+// modified, and the size of its files.
+func lastModified(path string) (time.Time, uint64, error) {
+	var modified time.Time
+	var size uint64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+		if d.Type().IsRegular() {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+	return modified, size, err
+}
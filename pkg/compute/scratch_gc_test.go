@@ -0,0 +1,75 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func writeScratch(t *testing.T, path string, modified time.Time) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("scratch"), 0644))
+	require.NoError(t, os.Chtimes(path, modified, modified))
+	require.NoError(t, os.Chtimes(filepath.Dir(path), modified, modified))
+}
+
+func TestScratchGCRemovesOrphanedScratchSpace(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	now := time.Now()
+	old := now.Add(-2 * time.Hour)
+
+	executionStore := inmemory.NewStore()
+	execution := store.NewExecution("execution", model.Job{Metadata: model.Metadata{ID: "active"}}, "requester", model.ResourceUsageData{})
+	execution.CreateTime = now.Add(-3 * time.Hour)
+	require.NoError(t, executionStore.CreateExecution(ctx, *execution))
+
+	writeScratch(t, filepath.Join(dir, "bacalhau-results123", "active", "stdout"), old)
+	writeScratch(t, filepath.Join(dir, "bacalhau-results123", "finished", "stdout"), old)
+	writeScratch(t, filepath.Join(dir, "bacalhau-results123", "recent", "stdout"), now)
+	writeScratch(t, filepath.Join(dir, "bacalhau-process-before", "main"), now.Add(-4*time.Hour))
+	writeScratch(t, filepath.Join(dir, "bacalhau-process-since", "main"), old)
+	writeScratch(t, filepath.Join(dir, "bacalhau-lotus-path-dir", "config"), now.Add(-4*time.Hour))
+
+	gc := NewScratchGC(ScratchGCParams{
+		Store:   executionStore,
+		Options: ScratchGCOptions{MinAge: time.Hour, DiskPressure: 1},
+		Dir:     dir,
+	})
+	require.Equal(t, uint64(2*len("scratch")), gc.collect(ctx))
+
+	require.DirExists(t, filepath.Join(dir, "bacalhau-results123", "active"), "the results of active executions are kept")
+	require.NoDirExists(t, filepath.Join(dir, "bacalhau-results123", "finished"))
+	require.DirExists(t, filepath.Join(dir, "bacalhau-results123", "recent"), "recently modified results are kept")
+	require.NoDirExists(t, filepath.Join(dir, "bacalhau-process-before"))
+	require.DirExists(t, filepath.Join(dir, "bacalhau-process-since"), "an active execution may be using it")
+	require.DirExists(t, filepath.Join(dir, "bacalhau-lotus-path-dir"), "only the scratch space of executions is removed")
+}
+
+func TestScratchGCIgnoresAgeUnderDiskPressure(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeScratch(t, filepath.Join(dir, "bacalhau-stdout-123"), time.Now().Add(-time.Second))
+
+	gc := NewScratchGC(ScratchGCParams{
+		Store:   inmemory.NewStore(),
+		Options: ScratchGCOptions{MinAge: time.Hour},
+		Dir:     dir,
+	})
+	gc.collect(ctx)
+	require.FileExists(t, filepath.Join(dir, "bacalhau-stdout-123"))
+
+	// any disk usage counts as pressure
+	gc.options.DiskPressure = 0.0001
+	gc.collect(ctx)
+	require.NoFileExists(t, filepath.Join(dir, "bacalhau-stdout-123"))
+}
@@ -0,0 +1,190 @@
+package sensors
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultBenchmarkCPUDuration is how long the CPU of the node is benchmarked for.
+	DefaultBenchmarkCPUDuration = time.Second
+	// DefaultBenchmarkDiskBytes is how many bytes are written to benchmark the disk of the node.
+	DefaultBenchmarkDiskBytes = 64 * 1024 * 1024
+	// DefaultBenchmarkNetworkTimeout is how long the download that benchmarks the network of the node may take.
+	DefaultBenchmarkNetworkTimeout = 30 * time.Second
+
+	benchmarkChunkSize = 1024 * 1024
+)
+
+type BenchmarkSensorParams struct {
+	// StoragePath is the directory on the disk that inputs are prepared on,
+	// which isn't benchmarked if it is empty
+	StoragePath string
+	// NetworkURL is downloaded to benchmark the network, which isn't
+	// benchmarked if it is empty
+	NetworkURL string
+	// CPUDuration is how long the CPU is benchmarked for, DefaultBenchmarkCPUDuration if zero
+	CPUDuration time.Duration
+	// DiskBytes is how many bytes are written to benchmark the disk, DefaultBenchmarkDiskBytes if zero
+	DiskBytes int
+	// NetworkTimeout is how long the download may take, DefaultBenchmarkNetworkTimeout if zero
+	NetworkTimeout time.Duration
+}
+
+// BenchmarkSensor measures how fast the CPU, disk and network of the compute
+// node are once when it starts, for the requester to estimate how long jobs
+// take to complete on the node. What fails to be measured is left out.
+type BenchmarkSensor struct {
+	storagePath    string
+	networkURL     string
+	cpuDuration    time.Duration
+	diskBytes      int
+	networkTimeout time.Duration
+
+	mu        sync.RWMutex
+	benchmark model.NodeBenchmark
+}
+
+// NewBenchmarkSensor create a new BenchmarkSensor from BenchmarkSensorParams
+func NewBenchmarkSensor(params BenchmarkSensorParams) *BenchmarkSensor {
+	s := &BenchmarkSensor{
+		storagePath:    params.StoragePath,
+		networkURL:     params.NetworkURL,
+		cpuDuration:    params.CPUDuration,
+		diskBytes:      params.DiskBytes,
+		networkTimeout: params.NetworkTimeout,
+	}
+	if s.cpuDuration <= 0 {
+		s.cpuDuration = DefaultBenchmarkCPUDuration
+	}
+	if s.diskBytes <= 0 {
+		s.diskBytes = DefaultBenchmarkDiskBytes
+	}
+	if s.networkTimeout <= 0 {
+		s.networkTimeout = DefaultBenchmarkNetworkTimeout
+	}
+	return s
+}
+
+// Start benchmarks the node, and returns once it is done.
+func (s *BenchmarkSensor) Start(ctx context.Context) {
+	benchmark := model.NodeBenchmark{
+		CPUScore: s.benchmarkCPU(ctx),
+	}
+	if s.storagePath != "" {
+		throughput, err := s.benchmarkDisk(ctx)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("failed to benchmark the disk of the node")
+		}
+		benchmark.DiskThroughput = throughput
+	}
+	if s.networkURL != "" {
+		bandwidth, err := s.benchmarkNetwork(ctx)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("failed to benchmark the network of the node")
+		}
+		benchmark.NetworkBandwidth = bandwidth
+	}
+	benchmark.BenchmarkedAt = time.Now()
+	log.Ctx(ctx).Info().Msgf("benchmarked the node with CPU score %.0f MB/s, disk throughput %.0f B/s and network bandwidth %.0f B/s",
+		benchmark.CPUScore, benchmark.DiskThroughput, benchmark.NetworkBandwidth)
+
+	s.mu.Lock()
+	s.benchmark = benchmark
+	s.mu.Unlock()
+}
+
+// GetBenchmark implements compute.BenchmarkProvider
+func (s *BenchmarkSensor) GetBenchmark() model.NodeBenchmark {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.benchmark
+}
+
+// benchmarkCPU returns how many MB a second a single core hashes.
+func (s *BenchmarkSensor) benchmarkCPU(ctx context.Context) float64 {
+	chunk := make([]byte, benchmarkChunkSize)
+	hash := sha256.New()
+	hashed := 0
+	start := time.Now()
+	for time.Since(start) < s.cpuDuration && ctx.Err() == nil {
+		_, _ = hash.Write(chunk)
+		hashed += len(chunk)
+	}
+	return float64(hashed) / benchmarkChunkSize / time.Since(start).Seconds()
+}
+
+// benchmarkDisk returns how many bytes a second are written to the storage path, synced to the disk.
+func (s *BenchmarkSensor) benchmarkDisk(ctx context.Context) (float64, error) {
+	file, err := os.CreateTemp(s.storagePath, "benchmark-*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	chunk := make([]byte, benchmarkChunkSize)
+	if _, err = rand.Read(chunk); err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	for written := 0; written < s.diskBytes; written += len(chunk) {
+		if err = ctx.Err(); err != nil {
+			return 0, err
+		}
+		if _, err = file.Write(chunk); err != nil {
+			return 0, err
+		}
+	}
+	if err = file.Sync(); err != nil {
+		return 0, err
+	}
+	return float64(s.diskBytes) / time.Since(start).Seconds(), nil
+}
+
+// benchmarkNetwork returns how many bytes a second the network URL downloads at.
+func (s *BenchmarkSensor) benchmarkNetwork(ctx context.Context) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.networkTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.networkURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("downloading %s returned status %d", s.networkURL, resp.StatusCode)
+	}
+	downloaded, err := io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	return float64(downloaded) / time.Since(start).Seconds(), nil
+}
+
+// GetDebugInfo implements model.DebugInfoProvider
+func (s *BenchmarkSensor) GetDebugInfo(context.Context) (model.DebugInfo, error) {
+	return model.DebugInfo{
+		Component: "Benchmark",
+		Info:      s.GetBenchmark(),
+	}, nil
+}
+
+var _ model.DebugInfoProvider = (*BenchmarkSensor)(nil)
@@ -0,0 +1,54 @@
+//go:build unit || !integration
+
+package sensors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchmarkSensor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(make([]byte, 1024*1024))
+	}))
+	defer server.Close()
+
+	storagePath := t.TempDir()
+	sensor := NewBenchmarkSensor(BenchmarkSensorParams{
+		StoragePath: storagePath,
+		NetworkURL:  server.URL,
+		CPUDuration: 10 * time.Millisecond,
+		DiskBytes:   1024 * 1024,
+	})
+	require.True(t, sensor.GetBenchmark().IsZero())
+
+	sensor.Start(context.Background())
+	benchmark := sensor.GetBenchmark()
+	require.Positive(t, benchmark.CPUScore)
+	require.Positive(t, benchmark.DiskThroughput)
+	require.Positive(t, benchmark.NetworkBandwidth)
+	require.False(t, benchmark.BenchmarkedAt.IsZero())
+
+	entries, err := os.ReadDir(storagePath)
+	require.NoError(t, err)
+	require.Empty(t, entries, "the file written to benchmark the disk is removed")
+}
+
+func TestBenchmarkSensorLeavesOutWhatFails(t *testing.T) {
+	sensor := NewBenchmarkSensor(BenchmarkSensorParams{
+		StoragePath: "/does/not/exist",
+		NetworkURL:  "http://127.0.0.1:1",
+		CPUDuration: 10 * time.Millisecond,
+	})
+	sensor.Start(context.Background())
+	benchmark := sensor.GetBenchmark()
+	require.Positive(t, benchmark.CPUScore)
+	require.Zero(t, benchmark.DiskThroughput)
+	require.Zero(t, benchmark.NetworkBandwidth)
+}
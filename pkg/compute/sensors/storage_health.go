@@ -0,0 +1,152 @@
+package sensors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/instrument"
+)
+
+type StorageHealthSensorParams struct {
+	Storages storage.StorageProvider
+	Interval time.Duration
+	// Timeout of the health check of each storage, which defaults to the
+	// interval.
+	Timeout time.Duration
+}
+
+// StorageHealth is the result of the latest health check of a storage.
+type StorageHealth struct {
+	Available bool      `json:"Available"`
+	Error     string    `json:"Error,omitempty"`
+	CheckedAt time.Time `json:"CheckedAt"`
+}
+
+// StorageHealthSensor periodically checks the health of the installed
+// storages of the compute node, logging when they become unavailable or
+// recover, and reporting whether they are available as the storage_available
+// metric and as debug info.
+type StorageHealthSensor struct {
+	storages storage.StorageProvider
+	interval time.Duration
+	timeout  time.Duration
+
+	mu     sync.RWMutex
+	health map[model.StorageSourceType]StorageHealth
+}
+
+// NewStorageHealthSensor create a new StorageHealthSensor from StorageHealthSensorParams
+func NewStorageHealthSensor(params StorageHealthSensorParams) *StorageHealthSensor {
+	timeout := params.Timeout
+	if timeout <= 0 {
+		timeout = params.Interval
+	}
+	return &StorageHealthSensor{
+		storages: params.Storages,
+		interval: params.Interval,
+		timeout:  timeout,
+		health:   make(map[model.StorageSourceType]StorageHealth),
+	}
+}
+
+func (s *StorageHealthSensor) Start(ctx context.Context) {
+	log.Ctx(ctx).Debug().Msgf("starting new storage health sensor with interval %s", s.interval)
+	meter := global.MeterProvider().Meter("storage")
+	available, err := meter.Int64ObservableGauge(
+		"storage_available",
+		instrument.WithDescription("Whether the storage passed its latest health check, as 1 or 0"),
+	)
+	if err == nil {
+		registration, err := meter.RegisterCallback(func(_ context.Context, observer metric.Observer) error {
+			for source, health := range s.snapshot() {
+				var value int64
+				if health.Available {
+					value = 1
+				}
+				observer.ObserveInt64(available, value, attribute.String("source", source.String()))
+			}
+			return nil
+		}, available)
+		if err == nil {
+			defer func() { _ = registration.Unregister() }()
+		}
+	}
+
+	ticker := time.NewTicker(s.interval)
+	s.sense(ctx)
+	for {
+		select {
+		case <-ticker.C:
+			s.sense(ctx)
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (s *StorageHealthSensor) sense(ctx context.Context) {
+	for _, source := range model.StorageSourceTypes() {
+		if !s.storages.Has(ctx, source) {
+			continue
+		}
+		err := s.check(ctx, source)
+		health := StorageHealth{Available: err == nil, CheckedAt: time.Now()}
+		if err != nil {
+			health.Error = err.Error()
+		}
+
+		s.mu.Lock()
+		previous, checked := s.health[source]
+		s.health[source] = health
+		s.mu.Unlock()
+
+		if err != nil && (!checked || previous.Available) {
+			log.Ctx(ctx).Warn().Err(err).Stringer("source", source).Msg("storage is unavailable")
+		} else if err == nil && checked && !previous.Available {
+			log.Ctx(ctx).Info().Stringer("source", source).Msg("storage is available again")
+		}
+	}
+}
+
+func (s *StorageHealthSensor) check(ctx context.Context, source model.StorageSourceType) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	provider, err := s.storages.Get(ctx, source)
+	if err != nil {
+		return err
+	}
+	return provider.HealthCheck(ctx)
+}
+
+func (s *StorageHealthSensor) snapshot() map[model.StorageSourceType]StorageHealth {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	health := make(map[model.StorageSourceType]StorageHealth, len(s.health))
+	for source, h := range s.health {
+		health[source] = h
+	}
+	return health
+}
+
+// GetDebugInfo implements model.DebugInfoProvider
+func (s *StorageHealthSensor) GetDebugInfo(context.Context) (model.DebugInfo, error) {
+	info := make(map[string]StorageHealth)
+	for source, health := range s.snapshot() {
+		info[source.String()] = health
+	}
+	return model.DebugInfo{
+		Component: "StorageHealth",
+		Info:      info,
+	}, nil
+}
+
+var _ model.DebugInfoProvider = (*StorageHealthSensor)(nil)
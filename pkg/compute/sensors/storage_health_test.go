@@ -0,0 +1,48 @@
+//go:build unit || !integration
+
+package sensors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/noop"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageHealthSensor(t *testing.T) {
+	ctx := context.Background()
+	var s3Err error
+	s3 := noop.NewNoopStorage(noop.StorageConfig{ExternalHooks: noop.StorageConfigExternalHooks{
+		HealthCheck: func(context.Context) error { return s3Err },
+	}})
+	notInstalled := noop.NewNoopStorage(noop.StorageConfig{ExternalHooks: noop.StorageConfigExternalHooks{
+		IsInstalled: func(context.Context) (bool, error) { return false, nil },
+	}})
+	sensor := NewStorageHealthSensor(StorageHealthSensorParams{
+		Storages: model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{
+			model.StorageSourceIPFS: noop.NewNoopStorage(noop.StorageConfig{}),
+			model.StorageSourceS3:   s3,
+			model.StorageSourceGCS:  notInstalled,
+		}),
+	})
+
+	s3Err = errors.New("unreachable")
+	sensor.sense(ctx)
+	health := sensor.snapshot()
+	require.Len(t, health, 2, "storages that aren't installed aren't checked")
+	require.True(t, health[model.StorageSourceIPFS].Available)
+	require.False(t, health[model.StorageSourceS3].Available)
+	require.Equal(t, "unreachable", health[model.StorageSourceS3].Error)
+
+	s3Err = nil
+	sensor.sense(ctx)
+	require.True(t, sensor.snapshot()[model.StorageSourceS3].Available)
+
+	info, err := sensor.GetDebugInfo(ctx)
+	require.NoError(t, err)
+	require.Contains(t, info.Info, model.StorageSourceS3.String())
+}
@@ -69,6 +69,11 @@ func (proxy *PersistentJobStore) GetExecutionCount(ctx context.Context) (uint, e
 	return readCounter(jsonFilepath)
 }
 
+// GetActiveExecutions implements store.ExecutionStore
+func (proxy *PersistentJobStore) GetActiveExecutions(ctx context.Context) ([]store.Execution, error) {
+	return proxy.store.GetActiveExecutions(ctx)
+}
+
 // GetExecutionHistory implements store.ExecutionStore
 func (proxy *PersistentJobStore) GetExecutionHistory(ctx context.Context, id string) ([]store.ExecutionHistory, error) {
 	return proxy.store.GetExecutionHistory(ctx, id)
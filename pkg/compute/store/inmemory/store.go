@@ -55,6 +55,18 @@ func (s *Store) GetExecutions(ctx context.Context, jobID string) ([]store.Execut
 	return executions, nil
 }
 
+func (s *Store) GetActiveExecutions(ctx context.Context) ([]store.Execution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	executions := make([]store.Execution, 0)
+	for _, execution := range s.executionMap {
+		if execution.State.IsActive() {
+			executions = append(executions, execution)
+		}
+	}
+	return executions, nil
+}
+
 func (s *Store) GetExecutionHistory(ctx context.Context, id string) ([]store.ExecutionHistory, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
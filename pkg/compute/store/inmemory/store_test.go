@@ -244,6 +244,22 @@ func (s *Suite) TestGetExecutionHistory_DoesntExist() {
 	s.ErrorAs(err, &store.ErrExecutionHistoryNotFound{})
 }
 
+func (s *Suite) TestGetActiveExecutions() {
+	ctx := context.Background()
+	completed := newExecution()
+	s.NoError(s.executionStore.CreateExecution(ctx, s.execution))
+	s.NoError(s.executionStore.CreateExecution(ctx, completed))
+	s.NoError(s.executionStore.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID: completed.ID,
+		NewState:    store.ExecutionStateCompleted,
+	}))
+
+	executions, err := s.executionStore.GetActiveExecutions(ctx)
+	s.NoError(err)
+	s.Len(executions, 1)
+	s.Equal(s.execution.ID, executions[0].ID)
+}
+
 func newExecution() store.Execution {
 	return *store.NewExecution(
 		uuid.NewString(),
@@ -13,18 +13,22 @@ const (
 	ExecutionStateCompleted
 	ExecutionStateFailed
 	ExecutionStateCancelled
+	// ExecutionStatePublishPending is when publishing the results failed, and
+	// will be tried again later.
+	ExecutionStatePublishPending
 )
 
 // IsActive returns true if the execution is active
 func (s ExecutionState) IsActive() bool {
 	return s == ExecutionStateCreated || s == ExecutionStateBidAccepted || s == ExecutionStateRunning ||
-		s == ExecutionStateWaitingVerification || s == ExecutionStateResultAccepted || s == ExecutionStatePublishing
+		s == ExecutionStateWaitingVerification || s == ExecutionStateResultAccepted || s == ExecutionStatePublishing ||
+		s == ExecutionStatePublishPending
 }
 
 // IsExecuting returns true if the execution is running in the backend
 func (s ExecutionState) IsExecuting() bool {
 	return s == ExecutionStateRunning || s == ExecutionStateWaitingVerification ||
-		s == ExecutionStateResultAccepted || s == ExecutionStatePublishing
+		s == ExecutionStateResultAccepted || s == ExecutionStatePublishing || s == ExecutionStatePublishPending
 }
 
 // IsTerminal returns true if the execution is terminal
@@ -18,11 +18,12 @@ func _() {
 	_ = x[ExecutionStateCompleted-7]
 	_ = x[ExecutionStateFailed-8]
 	_ = x[ExecutionStateCancelled-9]
+	_ = x[ExecutionStatePublishPending-10]
 }
 
-const _ExecutionState_name = "UndefinedCreatedBidAcceptedRunningWaitingVerificationResultAcceptedPublishingCompletedFailedCancelled"
+const _ExecutionState_name = "UndefinedCreatedBidAcceptedRunningWaitingVerificationResultAcceptedPublishingCompletedFailedCancelledPublishPending"
 
-var _ExecutionState_index = [...]uint8{0, 9, 16, 27, 34, 53, 67, 77, 86, 92, 101}
+var _ExecutionState_index = [...]uint8{0, 9, 16, 27, 34, 53, 67, 77, 86, 92, 101, 115}
 
 func (i ExecutionState) String() string {
 	if i < 0 || i >= ExecutionState(len(_ExecutionState_index)-1) {
@@ -83,6 +83,8 @@ type ExecutionStore interface {
 	GetExecution(ctx context.Context, id string) (Execution, error)
 	// GetExecutions returns all the executions for a given job
 	GetExecutions(ctx context.Context, jobID string) ([]Execution, error)
+	// GetActiveExecutions returns the executions that haven't completed, failed or been cancelled
+	GetActiveExecutions(ctx context.Context) ([]Execution, error)
 	// GetExecutionHistory returns the history of an execution
 	GetExecutionHistory(ctx context.Context, id string) ([]ExecutionHistory, error)
 	// CreateExecution creates a new execution for a given job
@@ -2,6 +2,8 @@ package compute
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
@@ -27,6 +29,18 @@ type Endpoint interface {
 	CancelExecution(context.Context, CancelExecutionRequest) (CancelExecutionResponse, error)
 }
 
+// LogStreamer provides access to the output of executions while they are running on the compute node.
+type LogStreamer interface {
+	// ExecutionLogs returns the stdout and stderr of a running execution, multiplexed as logstream data frames.
+	ExecutionLogs(context.Context, ExecutionLogsRequest) (io.ReadCloser, error)
+}
+
+// BenchmarkProvider provides how fast the compute node measured itself to be.
+type BenchmarkProvider interface {
+	// GetBenchmark returns the latest benchmark of the node, which is zero until it was benchmarked.
+	GetBenchmark() model.NodeBenchmark
+}
+
 // Executor Backend service that is responsible for running and publishing executions.
 // Implementations can be synchronous or asynchronous by using Callbacks.
 type Executor interface {
@@ -38,10 +52,21 @@ type Executor interface {
 	Cancel(ctx context.Context, execution store.Execution) error
 }
 
+// CapacityReserver holds capacity for executions before their bids are accepted, so that all the executions of a
+// gang job can start at once on the nodes that bid on it.
+type CapacityReserver interface {
+	// Reserve holds the capacity that the execution needs until it runs or is released, and returns false if the
+	// node doesn't have that capacity free.
+	Reserve(ctx context.Context, execution store.Execution) bool
+	// Release frees the capacity held for the execution, if any.
+	Release(ctx context.Context, executionID string)
+}
+
 // Callback Callbacks are used to notify the caller of the result of a job execution.
 type Callback interface {
 	OnRunComplete(ctx context.Context, result RunResult)
 	OnPublishComplete(ctx context.Context, result PublishResult)
+	OnPublishPending(ctx context.Context, result PublishPendingResult)
 	OnCancelComplete(ctx context.Context, result CancelResult)
 	OnComputeFailure(ctx context.Context, err ComputeError)
 }
@@ -77,6 +102,12 @@ type AskForBidResponse struct {
 	ExecutionMetadata
 	Accepted bool
 	Reason   string
+	// Quote is what the node charges for the execution if it bid on it and
+	// charges for its resources
+	Quote *model.Quote
+	// Attestation is evidence of the TEE that the node runs in if it bid on
+	// a job that requires one
+	Attestation *model.Attestation
 }
 
 type BidAcceptedRequest struct {
@@ -123,12 +154,27 @@ type CancelExecutionRequest struct {
 	RoutingMetadata
 	ExecutionID   string
 	Justification string
+	// ByClient is whether the client that submitted the job cancelled it
+	ByClient bool
 }
 
 type CancelExecutionResponse struct {
 	ExecutionMetadata
 }
 
+type ExecutionLogsRequest struct {
+	RoutingMetadata
+	ExecutionID string
+	// Follow keeps the stream open until the execution stops producing output.
+	Follow bool
+}
+
+type ExecutionLogsResponse struct {
+	ExecutionMetadata
+	// Err is set if the logs could not be streamed, in which case no data follows the response.
+	Err string
+}
+
 ///////////////////////////////////
 // Callback result models
 ///////////////////////////////////
@@ -146,6 +192,24 @@ type PublishResult struct {
 	RoutingMetadata
 	ExecutionMetadata
 	PublishResult model.StorageSpec
+	// PublisherResults are how publishing went with each of the publishers
+	// of the job, which PublishResult is the first successful result of.
+	PublisherResults []model.PublisherResult
+	// SkippedOutputs are the files in the output volumes that weren't
+	// published, if any of the volumes filter what is published.
+	SkippedOutputs *model.SkippedOutputs
+	// ResultSignature is the node's signature of the manifest of the result,
+	// if the node has a key to sign it with
+	ResultSignature *model.ResultSignature
+}
+
+// PublishPendingResult Result of a job publish that failed and will be tried again later, that is returned to the
+// caller through a Callback.
+type PublishPendingResult struct {
+	RoutingMetadata
+	ExecutionMetadata
+	Err     string
+	RetryAt time.Time
 }
 
 // CancelResult Result of a job cancel that is returned to the caller through a Callback.
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,6 +19,7 @@ import (
 	dockerclient "github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/opencontainers/go-digest"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
@@ -30,6 +32,8 @@ import (
 const ImagePullError = `Could not pull image %q - could be due to repo/image not existing, ` +
 	`or registry needing authorization`
 
+const ImageDigestMismatchError = `Image %q resolved to digests %v which do not include the pinned digest %s`
+
 type Client struct {
 	tracing.TracedClient
 }
@@ -157,7 +161,7 @@ func (c *Client) FollowLogs(ctx context.Context, id string) (stdout, stderr io.R
 	return stdoutReader, stderrReader, nil
 }
 
-func (c *Client) GetOutputStream(ctx context.Context, id string, since string) (io.ReadCloser, error) {
+func (c *Client) GetOutputStream(ctx context.Context, id string, since string, follow bool) (io.ReadCloser, error) {
 	cont, err := c.ContainerInspect(ctx, id)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get container")
@@ -166,7 +170,7 @@ func (c *Client) GetOutputStream(ctx context.Context, id string, since string) (
 	logOptions := types.ContainerLogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
-		Follow:     true,
+		Follow:     follow,
 	}
 	if since != "" {
 		logOptions.Since = since
@@ -229,6 +233,103 @@ func (c *Client) SupportedPlatforms(ctx context.Context) ([]v1.Platform, error)
 	}, nil
 }
 
+// UsesUserNamespace returns whether the daemon runs containers in a user
+// namespace, either because it is running rootless or because user namespace
+// remapping is enabled, so that root inside a container is not root on the host.
+func (c *Client) UsesUserNamespace(ctx context.Context) (bool, error) {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get docker daemon info")
+	}
+
+	securityOptions, err := types.DecodeSecurityOptions(info.SecurityOptions)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to decode docker daemon security options")
+	}
+
+	return slices.ContainsFunc(securityOptions, func(opt types.SecurityOpt) bool {
+		return opt.Name == "userns" || opt.Name == "rootless"
+	}), nil
+}
+
+// SupportsStorageQuota returns whether the storage driver of the daemon can
+// limit the size of the writable filesystem of containers. Overlay2 can only
+// do so on xfs mounted with pquota, which the daemon doesn't report, so
+// creating a container with a size limit can still fail.
+func (c *Client) SupportsStorageQuota(ctx context.Context) (bool, error) {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get docker daemon info")
+	}
+	switch info.Driver {
+	case "btrfs", "zfs", "devicemapper", "windowsfilter":
+		return true, nil
+	case "overlay2":
+		for _, status := range info.DriverStatus {
+			if len(status) == 2 && status[0] == "Backing Filesystem" {
+				return status[1] == "xfs", nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// HasRuntime returns whether the docker daemon has an OCI runtime registered
+// under the name, e.g. runsc for gVisor.
+func (c *Client) HasRuntime(ctx context.Context, name string) (bool, error) {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get docker daemon info")
+	}
+	_, ok := info.Runtimes[name]
+	return ok, nil
+}
+
+// ImageDigests returns the registry digests that a locally available image is
+// known by. Images that were built locally and never pushed have no digests.
+func (c *Client) ImageDigests(ctx context.Context, image string) ([]digest.Digest, error) {
+	inspect, _, err := c.ImageInspectWithRaw(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+
+	digests := make([]digest.Digest, 0, len(inspect.RepoDigests))
+	for _, repoDigest := range inspect.RepoDigests {
+		_, value, found := strings.Cut(repoDigest, "@")
+		if !found {
+			continue
+		}
+		parsed, err := digest.Parse(value)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("image", image).Msgf("Ignoring invalid repo digest %q", repoDigest)
+			continue
+		}
+		digests = append(digests, parsed)
+	}
+	return digests, nil
+}
+
+// ResolveImageDigest returns the digest the image should be recorded as having
+// run with. If pinned is set, the local image must be known by that digest.
+func (c *Client) ResolveImageDigest(ctx context.Context, image string, pinned digest.Digest) (digest.Digest, error) {
+	digests, err := c.ImageDigests(ctx, image)
+	if err != nil {
+		return "", err
+	}
+
+	if pinned != "" {
+		if !slices.Contains(digests, pinned) {
+			return "", fmt.Errorf(ImageDigestMismatchError, image, digests, pinned)
+		}
+		return pinned, nil
+	}
+
+	if len(digests) == 0 {
+		return "", nil
+	}
+	return digests[0], nil
+}
+
 func (c *Client) PullImage(ctx context.Context, image string) error {
 	_, _, err := c.ImageInspectWithRaw(ctx, image)
 	if err == nil {
@@ -38,6 +38,13 @@ type TracedClient struct {
 	hostname string
 }
 
+func (c TracedClient) CheckpointCreate(ctx context.Context, containerID string, options types.CheckpointCreateOptions) error {
+	ctx, span := c.span(ctx, "checkpoint.create")
+	defer span.End()
+
+	return telemetry.RecordErrorOnSpan(span)(c.client.CheckpointCreate(ctx, containerID, options))
+}
+
 func (c TracedClient) ContainerCreate(
 	ctx context.Context,
 	config *container.Config,
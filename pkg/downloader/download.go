@@ -6,7 +6,13 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/bacalhau-project/bacalhau/pkg/compression"
+	"github.com/bacalhau-project/bacalhau/pkg/encryption"
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs/car"
+	"github.com/bacalhau-project/bacalhau/pkg/merkle"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	"github.com/rs/zerolog/log"
@@ -73,18 +79,42 @@ func DownloadResults( //nolint:funlen,gocyclo
 	downloadedCids := map[string]string{}
 
 	for _, publishedResult := range publishedResults {
-		cidDownloadDir := filepath.Join(cidParentDir, publishedResult.Data.CID)
-		_, ok := downloadedCids[publishedResult.Data.CID]
+		// results that aren't content addressed are told apart by their name
+		key := publishedResult.Data.CID
+		if key == "" {
+			key = publishedResult.Data.Name
+		}
+		cidDownloadDir := filepath.Join(cidParentDir, key)
+		_, ok := downloadedCids[key]
 		if !ok {
 			downloader, err := downloadProvider.Get(ctx, publishedResult.Data.StorageSource) //nolint
 			err = downloader.FetchResult(ctx, publishedResult, cidDownloadDir)
 			if err != nil {
 				return err
 			}
-			downloadedCids[publishedResult.Data.CID] = cidDownloadDir
+			if err = verifyMerkleRoot(ctx, publishedResult, cidDownloadDir); err != nil {
+				return err
+			}
+			if encryption.IsEncrypted(cidDownloadDir) {
+				if err = decryptResults(cidDownloadDir, settings); err != nil {
+					return err
+				}
+			}
+			if compression.IsCompressed(cidDownloadDir) {
+				if err = compression.DecompressResults(cidDownloadDir); err != nil {
+					return err
+				}
+			}
+			if err = verifyExitCode(ctx, publishedResult, cidDownloadDir); err != nil {
+				return err
+			}
+			downloadedCids[key] = cidDownloadDir
 		}
 	}
 
+	if settings.CAR {
+		return writeCAR(ctx, resultsOutputDir, cidParentDir, downloadedCids)
+	}
 	for _, cidDownloadDir := range downloadedCids {
 		err = moveData(ctx, resultsOutputDir, cidDownloadDir, len(downloadedCids) > 1)
 		if err != nil {
@@ -94,6 +124,99 @@ func DownloadResults( //nolint:funlen,gocyclo
 	return os.RemoveAll(cidParentDir)
 }
 
+// VerifyResultSignatures checks that the results of the job are signed by the
+// compute nodes that published them, if they are signed, and that all of them
+// are signed if the settings require it.
+func VerifyResultSignatures(
+	ctx context.Context, jobID string, publishedResults []model.PublishedResult, settings *model.DownloaderSettings) error {
+	for _, publishedResult := range publishedResults {
+		if publishedResult.ResultSignature == nil && !settings.RequireSignedResults {
+			log.Ctx(ctx).Debug().Str("node", publishedResult.NodeID).Msg("Result was published without a signature")
+			continue
+		}
+		err := publishedResult.ResultSignature.Verify(jobID, publishedResult.NodeID, publishedResult.Data)
+		if err != nil {
+			return fmt.Errorf("result of job %s failed verification: %w", jobID, err)
+		}
+		log.Ctx(ctx).Debug().Str("node", publishedResult.NodeID).Msg("Verified the signature of the result")
+	}
+	return nil
+}
+
+// verifyMerkleRoot checks the downloaded result against the merkle root that
+// it was published with, if it has one, before it is decrypted or decompressed.
+func verifyMerkleRoot(ctx context.Context, publishedResult model.PublishedResult, dir string) error {
+	if publishedResult.Data.MerkleRoot == "" {
+		log.Ctx(ctx).Debug().Str("node", publishedResult.NodeID).Msg("Result was published without a merkle root")
+		return nil
+	}
+	if err := merkle.Verify(dir, publishedResult.Data.MerkleRoot); err != nil {
+		return fmt.Errorf("result published by node %s failed verification: %w", publishedResult.NodeID, err)
+	}
+	log.Ctx(ctx).Debug().Str("root", publishedResult.Data.MerkleRoot).Msg("Verified result against its merkle root")
+	return nil
+}
+
+// verifyExitCode checks the exit code in the downloaded result against the one
+// that the node signed, if the result is signed.
+func verifyExitCode(ctx context.Context, publishedResult model.PublishedResult, dir string) error {
+	if publishedResult.ResultSignature == nil {
+		return nil
+	}
+	signed := publishedResult.ResultSignature.Manifest.ExitCode
+	content, err := os.ReadFile(filepath.Join(dir, model.DownloadFilenameExitCode))
+	if err != nil {
+		return fmt.Errorf("result published by node %s has no exit code to check against the signed one: %w",
+			publishedResult.NodeID, err)
+	}
+	exitCode, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return fmt.Errorf("result published by node %s has an invalid exit code: %w", publishedResult.NodeID, err)
+	}
+	if exitCode != signed {
+		return fmt.Errorf("result published by node %s has exit code %d rather than the signed %d",
+			publishedResult.NodeID, exitCode, signed)
+	}
+	log.Ctx(ctx).Debug().Int("exitCode", exitCode).Msg("Verified exit code against the signed one")
+	return nil
+}
+
+// decryptResults decrypts the downloaded results in the directory with the
+// identities in the identity file of the settings.
+func decryptResults(dir string, settings *model.DownloaderSettings) error {
+	if settings.IdentityFile == "" {
+		return fmt.Errorf("results are encrypted, and need an identity file to decrypt them with")
+	}
+	identities, err := encryption.ReadIdentities(settings.IdentityFile)
+	if err != nil {
+		return err
+	}
+	return encryption.DecryptResults(dir, identities)
+}
+
+// writeCAR writes the downloaded results, merged as they would be into the
+// output dir, as a single CAR file in it.
+func writeCAR(ctx context.Context, resultsOutputDir, cidParentDir string, downloadedCids map[string]string) error {
+	defer os.RemoveAll(cidParentDir)
+	mergedDir, err := os.MkdirTemp(cidParentDir, "merged")
+	if err != nil {
+		return err
+	}
+	for _, cidDownloadDir := range downloadedCids {
+		err = moveData(ctx, mergedDir, cidDownloadDir, len(downloadedCids) > 1)
+		if err != nil {
+			return err
+		}
+	}
+	carPath := filepath.Join(resultsOutputDir, model.DownloadCARFilename)
+	root, err := car.CreateCar(ctx, mergedDir, carPath, 1)
+	if err != nil {
+		return fmt.Errorf("failed to write results as a CAR: %w", err)
+	}
+	log.Ctx(ctx).Info().Str("root", root).Msgf("Wrote results to %s", carPath)
+	return nil
+}
+
 func moveData(
 	ctx context.Context,
 	volumeDir string,
@@ -10,12 +10,16 @@ import (
 	"strings"
 	"testing"
 
+	"filippo.io/age"
+	"github.com/bacalhau-project/bacalhau/pkg/encryption"
 	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
 
 	ipfs2 "github.com/bacalhau-project/bacalhau/pkg/downloader/ipfs"
 	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs/car"
 
 	"github.com/bacalhau-project/bacalhau/pkg/logger"
+	"github.com/bacalhau-project/bacalhau/pkg/merkle"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	"github.com/stretchr/testify/require"
@@ -237,3 +241,135 @@ func (ds *DownloaderSuite) TestCustomVolumeNames() {
 
 	requireFileExists(ds, "secrets", "private.pem")
 }
+
+func (ds *DownloaderSuite) TestCAROutput() {
+	var hello []byte
+	cid := mockOutput(ds, func(dir string) {
+		hello = mockFile(ds, dir, "outputs", "hello.txt")
+	})
+
+	ds.downloadSettings.CAR = true
+	err := DownloadResults(
+		context.Background(),
+		[]model.PublishedResult{
+			{
+				NodeID: "testnode",
+				Data: model.StorageSpec{
+					StorageSource: model.StorageSourceIPFS,
+					Name:          "result-0",
+					CID:           cid,
+				},
+			},
+		},
+		ds.downloadProvider,
+		ds.downloadSettings,
+	)
+	require.NoError(ds.T(), err)
+
+	entries, err := os.ReadDir(ds.outputDir)
+	require.NoError(ds.T(), err)
+	require.Len(ds.T(), entries, 1, "only the CAR is written")
+
+	extracted := ds.T().TempDir()
+	require.NoError(ds.T(), car.ExtractCar(context.Background(), requireFileExists(ds, model.DownloadCARFilename), extracted))
+	contents, err := os.ReadFile(filepath.Join(extracted, "outputs", "hello.txt"))
+	require.NoError(ds.T(), err)
+	require.Equal(ds.T(), hello, contents)
+}
+
+func (ds *DownloaderSuite) TestEncryptedOutput() {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(ds.T(), err)
+	identityFile := filepath.Join(ds.T().TempDir(), "identity.txt")
+	require.NoError(ds.T(), os.WriteFile(identityFile, []byte(identity.String()), 0600))
+
+	var stdout, hello []byte
+	cid := mockOutput(ds, func(dir string) {
+		results := ds.T().TempDir()
+		stdout = mockFile(ds, results, "stdout")
+		hello = mockFile(ds, results, "outputs", "hello.txt")
+		require.NoError(ds.T(), encryption.EncryptResults(results, dir, []string{identity.Recipient().String()}))
+	})
+	results := []model.PublishedResult{
+		{
+			NodeID: "testnode",
+			Data: model.StorageSpec{
+				StorageSource: model.StorageSourceIPFS,
+				Name:          "result-0",
+				CID:           cid,
+			},
+		},
+	}
+
+	err = DownloadResults(context.Background(), results, ds.downloadProvider, ds.downloadSettings)
+	require.Error(ds.T(), err, "encrypted results can't be downloaded without an identity")
+
+	ds.outputDir = ds.T().TempDir()
+	ds.downloadSettings.OutputDir = ds.outputDir
+	ds.downloadSettings.IdentityFile = identityFile
+	err = DownloadResults(context.Background(), results, ds.downloadProvider, ds.downloadSettings)
+	require.NoError(ds.T(), err)
+	requireFile(ds, stdout, "stdout")
+	requireFile(ds, hello, "outputs", "hello.txt")
+	require.NoFileExists(ds.T(), filepath.Join(ds.outputDir, encryption.ResultsFilename))
+}
+
+func (ds *DownloaderSuite) TestMerkleRootIsVerified() {
+	var root string
+	var hello []byte
+	cid := mockOutput(ds, func(dir string) {
+		hello = mockFile(ds, dir, "outputs", "hello.txt")
+		var err error
+		root, err = merkle.Root(dir)
+		require.NoError(ds.T(), err)
+	})
+	results := []model.PublishedResult{
+		{
+			NodeID: "testnode",
+			Data: model.StorageSpec{
+				StorageSource: model.StorageSourceIPFS,
+				Name:          "result-0",
+				CID:           cid,
+				MerkleRoot:    root,
+			},
+		},
+	}
+
+	err := DownloadResults(context.Background(), results, ds.downloadProvider, ds.downloadSettings)
+	require.NoError(ds.T(), err)
+	requireFile(ds, hello, "outputs", "hello.txt")
+
+	otherRoot, err := merkle.Root(ds.T().TempDir())
+	require.NoError(ds.T(), err)
+	results[0].Data.MerkleRoot = otherRoot
+	ds.outputDir = ds.T().TempDir()
+	ds.downloadSettings.OutputDir = ds.outputDir
+	err = DownloadResults(context.Background(), results, ds.downloadProvider, ds.downloadSettings)
+	require.ErrorContains(ds.T(), err, "tampered")
+}
+
+func (ds *DownloaderSuite) TestSignedExitCodeIsVerified() {
+	cid := mockOutput(ds, func(dir string) {
+		require.NoError(ds.T(), os.WriteFile(filepath.Join(dir, model.DownloadFilenameExitCode), []byte("0"), 0644))
+	})
+	results := []model.PublishedResult{
+		{
+			NodeID: "testnode",
+			Data: model.StorageSpec{
+				StorageSource: model.StorageSourceIPFS,
+				Name:          "result-0",
+				CID:           cid,
+			},
+			ResultSignature: &model.ResultSignature{Manifest: model.ResultManifest{ExitCode: 0}},
+		},
+	}
+
+	err := DownloadResults(context.Background(), results, ds.downloadProvider, ds.downloadSettings)
+	require.NoError(ds.T(), err)
+
+	results[0].ResultSignature.Manifest.ExitCode = 1
+	ds.outputDir = ds.T().TempDir()
+	ds.downloadSettings.OutputDir = ds.outputDir
+	err = DownloadResults(context.Background(), results, ds.downloadProvider, ds.downloadSettings)
+	require.ErrorContains(ds.T(), err, "rather than the signed 1")
+}
@@ -0,0 +1,64 @@
+// Package local downloads results that compute nodes kept and served with the
+// local publisher.
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/bacalhau-project/bacalhau/pkg/util/targzip"
+	"github.com/rs/zerolog/log"
+)
+
+type Downloader struct {
+	Settings *model.DownloaderSettings
+}
+
+func NewLocalDownloader(settings *model.DownloaderSettings) *Downloader {
+	return &Downloader{
+		Settings: settings,
+	}
+}
+
+func (d *Downloader) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+// FetchResult downloads the archive of the result from the node that serves
+// it, sending the configured token if there is one, and extracts it into the
+// download path.
+func (d *Downloader) FetchResult(ctx context.Context, result model.PublishedResult, downloadPath string) error {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/downloader/local.Downloader.FetchResult")
+	defer span.End()
+
+	log.Ctx(ctx).Debug().Msgf("Downloading result '%s' from '%s' to '%s'...", result.Data.Name, result.Data.URL, downloadPath)
+
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(d.Settings.Timeout))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, result.Data.URL, nil)
+	if err != nil {
+		return err
+	}
+	if d.Settings.LocalPublisherToken != "" {
+		req.Header.Set("Authorization", "Bearer "+d.Settings.LocalPublisherToken)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Ctx(ctx).Error().Msg("Timed out while downloading result.")
+		}
+		return err
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "http response", res.Body)
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download result from %s: %s", result.Data.URL, res.Status)
+	}
+	return targzip.DecompressDirectory(res.Body, downloadPath)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/downloader"
 	"github.com/bacalhau-project/bacalhau/pkg/downloader/estuary"
 	"github.com/bacalhau-project/bacalhau/pkg/downloader/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/downloader/local"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 )
@@ -15,8 +16,10 @@ func NewDownloadSettings() *model.DownloaderSettings {
 	settings := model.DownloaderSettings{
 		Timeout: model.DefaultIPFSTimeout,
 		// we leave this blank so the CLI will auto-create a job folder in pwd
-		OutputDir:      "",
-		IPFSSwarmAddrs: "",
+		OutputDir:           "",
+		IPFSSwarmAddrs:      "",
+		LocalPublisherToken: os.Getenv("BACALHAU_LOCAL_PUBLISHER_TOKEN"),
+		IdentityFile:        os.Getenv("BACALHAU_IDENTITY_FILE"),
 	}
 	if os.Getenv("BACALHAU_IPFS_SWARM_ADDRESSES") != "" {
 		settings.IPFSSwarmAddrs = os.Getenv("BACALHAU_IPFS_SWARM_ADDRESSES")
@@ -31,9 +34,12 @@ func NewStandardDownloaders(
 	settings *model.DownloaderSettings) downloader.DownloaderProvider {
 	ipfsDownloader := ipfs.NewIPFSDownloader(cm, settings)
 	estuaryDownloader := estuary.NewEstuaryDownloader(cm, settings)
+	localDownloader := local.NewLocalDownloader(settings)
 
 	return model.NewMappedProvider(map[model.StorageSourceType]downloader.Downloader{
 		model.StorageSourceIPFS:    ipfsDownloader,
 		model.StorageSourceEstuary: estuaryDownloader,
+		// results of the local publisher are served by the node that ran them
+		model.StorageSourceURLDownload: localDownloader,
 	})
 }
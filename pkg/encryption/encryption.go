@@ -0,0 +1,100 @@
+// Package encryption encrypts the results of jobs to the age X25519 public
+// keys of their clients before they are published, and decrypts them when
+// they are downloaded, so that results published to public networks like IPFS
+// and Filecoin stay confidential.
+//
+// Results are encrypted as a single gzipped tar, so that neither the names nor
+// the sizes of the files in them are published.
+package encryption
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"filippo.io/age"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/bacalhau-project/bacalhau/pkg/util/targzip"
+)
+
+// ResultsFilename is what encrypted results are published as.
+const ResultsFilename = "results.tar.gz.age"
+
+// ParseRecipients parses age X25519 public keys, e.g. age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p.
+func ParseRecipients(recipients []string) ([]age.Recipient, error) {
+	parsed := make([]age.Recipient, 0, len(recipients))
+	for _, recipient := range recipients {
+		r, err := age.ParseX25519Recipient(recipient)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, r)
+	}
+	return parsed, nil
+}
+
+// ReadIdentities reads the age identities in a file, as written by age-keygen.
+func ReadIdentities(path string) ([]age.Identity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.CloseWithLogOnError("identity file", f)
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identities from %s: %w", path, err)
+	}
+	return identities, nil
+}
+
+// EncryptResults writes the results in the result path to the recipients, as
+// ResultsFilename in the output path.
+func EncryptResults(resultPath, outputPath string, recipients []string) error {
+	parsed, err := ParseRecipients(recipients)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(outputPath, ResultsFilename))
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError("encrypted results", f)
+
+	w, err := age.Encrypt(f, parsed...)
+	if err != nil {
+		return err
+	}
+	if err = targzip.CompressDirectory(w, resultPath); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// IsEncrypted returns whether the downloaded results in the directory are
+// encrypted.
+func IsEncrypted(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ResultsFilename))
+	return err == nil
+}
+
+// DecryptResults replaces the encrypted results in the directory with the
+// results they were encrypted from, using one of the identities.
+func DecryptResults(dir string, identities []age.Identity) error {
+	path := filepath.Join(dir, ResultsFilename)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	r, err := age.Decrypt(f, identities...)
+	if err == nil {
+		err = targzip.DecompressDirectory(r, dir)
+	}
+	closer.CloseWithLogOnError("encrypted results", f)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt results: %w", err)
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,44 @@
+//go:build unit || !integration
+
+package encryption
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptAndDecryptResults(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	results := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(results, "stdout"), []byte("hello"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(results, "outputs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(results, "outputs", "data.csv"), []byte("a,b"), 0644))
+
+	encrypted := t.TempDir()
+	require.NoError(t, EncryptResults(results, encrypted, []string{identity.Recipient().String()}))
+	require.True(t, IsEncrypted(encrypted))
+
+	require.Error(t, DecryptResults(encrypted, []age.Identity{other}), "only the recipients can decrypt the results")
+	require.NoError(t, DecryptResults(encrypted, []age.Identity{identity}))
+	require.False(t, IsEncrypted(encrypted))
+
+	stdout, err := os.ReadFile(filepath.Join(encrypted, "stdout"))
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(stdout))
+	data, err := os.ReadFile(filepath.Join(encrypted, "outputs", "data.csv"))
+	require.NoError(t, err)
+	require.Equal(t, "a,b", string(data))
+}
+
+func TestParseRecipients(t *testing.T) {
+	_, err := ParseRecipients([]string{"not-a-key"})
+	require.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package apptainer
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// bind is a directory or file on the compute node bound into the container.
+type bind struct {
+	source   string
+	target   string
+	readOnly bool
+}
+
+func (b bind) String() string {
+	if b.readOnly {
+		return fmt.Sprintf("%s:%s:ro", b.source, b.target)
+	}
+	return fmt.Sprintf("%s:%s", b.source, b.target)
+}
+
+// runArgs returns the apptainer arguments that run the job from the SIF file.
+// The job runs in its own filesystem, PID and IPC namespaces, but shares the
+// network of the compute node as isolating it needs root.
+func runArgs(job model.Job, sif string, binds []bind, resourceLimits bool) []string {
+	spec := job.Spec.Docker
+
+	// run the image's own entrypoint unless the job overrides it
+	args := []string{"run"}
+	if len(spec.Entrypoint) > 0 {
+		args = []string{"exec"}
+	}
+	args = append(args, "--containall")
+
+	for _, b := range binds {
+		args = append(args, "--bind", b.String())
+	}
+	if spec.WorkingDirectory != "" {
+		args = append(args, "--pwd", spec.WorkingDirectory)
+	}
+
+	resources := capacity.ParseResourceUsageConfig(job.Spec.Resources)
+	if resources.GPU > 0 {
+		args = append(args, "--nv")
+	}
+	if resourceLimits {
+		if resources.CPU > 0 {
+			args = append(args, "--cpus", strconv.FormatFloat(resources.CPU, 'f', -1, 64))
+		}
+		if resources.Memory > 0 {
+			args = append(args, "--memory", strconv.FormatUint(resources.Memory, 10))
+		}
+	}
+
+	args = append(args, sif)
+	return append(args, spec.Entrypoint...)
+}
+
+// jobEnv passes the environment of the job through apptainer's own variables,
+// as --containall doesn't let the environment of the compute node through.
+func jobEnv(binary string, variables []string, jsonJobSpec string) []string {
+	prefix := "APPTAINERENV_"
+	if strings.Contains(filepath.Base(binary), "singularity") {
+		prefix = "SINGULARITYENV_"
+	}
+
+	env := make([]string, 0, len(variables)+1)
+	for _, variable := range variables {
+		env = append(env, prefix+variable)
+	}
+	return append(env, prefix+"BACALHAU_JOB_SPEC="+jsonJobSpec)
+}
+
+// sifName is the name of the SIF file the image is converted to in the cache.
+func sifName(image string) string {
+	return fmt.Sprintf("%x.sif", sha256.Sum256([]byte(image)))
+}
@@ -0,0 +1,59 @@
+//go:build unit || !integration
+
+package apptainer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBidsBasedOnSupportedFeatures(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		spec      model.Spec
+		shouldBid bool
+	}{
+		{
+			name:      "no networking",
+			spec:      model.Spec{Engine: model.EngineApptainer, Network: model.NetworkConfig{Type: model.NetworkNone}},
+			shouldBid: true,
+		},
+		{
+			name:      "full networking",
+			spec:      model.Spec{Engine: model.EngineApptainer, Network: model.NetworkConfig{Type: model.NetworkFull}},
+			shouldBid: true,
+		},
+		{
+			name:      "http networking",
+			spec:      model.Spec{Engine: model.EngineApptainer, Network: model.NetworkConfig{Type: model.NetworkHTTP}},
+			shouldBid: false,
+		},
+		{
+			name:      "security profile override",
+			spec:      model.Spec{Engine: model.EngineApptainer, Docker: model.JobSpecDocker{AppArmorProfile: "unconfined"}},
+			shouldBid: false,
+		},
+		{
+			name:      "IOPS limits",
+			spec:      model.Spec{Engine: model.EngineApptainer, Resources: model.ResourceUsageConfig{IOPS: "100"}},
+			shouldBid: false,
+		},
+		{
+			name:      "other engines",
+			spec:      model.Spec{Engine: model.EngineWasm, Network: model.NetworkConfig{Type: model.NetworkHTTP}},
+			shouldBid: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			response, err := NewBidStrategy().ShouldBid(context.Background(), bidstrategy.BidStrategyRequest{
+				Job: model.Job{Spec: test.spec},
+			})
+			require.NoError(t, err)
+			require.Equal(t, test.shouldBid, response.ShouldBid)
+		})
+	}
+}
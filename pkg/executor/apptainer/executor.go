@@ -0,0 +1,238 @@
+package apptainer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/docker"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"go.uber.org/multierr"
+)
+
+// DefaultBinary is the apptainer command run if no other is configured. Sites
+// still on singularity can use that binary instead, as it takes the same
+// arguments.
+const DefaultBinary = "apptainer"
+
+// ExecutorOptions configures how apptainer is run.
+type ExecutorOptions struct {
+	// Binary is the apptainer, or singularity, command to run jobs with.
+	Binary string
+	// ImageCacheDir is where the images of jobs are kept once they have been
+	// converted to SIF files. Images referenced by tag are only converted once,
+	// so they need removing from the cache to pick up newer versions.
+	ImageCacheDir string
+	// ResourceLimits enforces the CPU and memory limits of jobs, which needs
+	// apptainer to be able to use cgroups on the host.
+	ResourceLimits bool
+}
+
+// Executor runs the docker images of jobs with apptainer, for HPC sites that
+// can't run a docker daemon on their shared clusters.
+type Executor struct {
+	// the storage providers we can implement for a job
+	StorageProvider storage.StorageProvider
+
+	options ExecutorOptions
+}
+
+func NewExecutor(
+	_ context.Context,
+	storageProvider storage.StorageProvider,
+	options ExecutorOptions,
+) (*Executor, error) {
+	if options.Binary == "" {
+		options.Binary = DefaultBinary
+	}
+	if options.ImageCacheDir == "" {
+		options.ImageCacheDir = filepath.Join(config.GetStoragePath(), "bacalhau-apptainer")
+	}
+
+	return &Executor{
+		StorageProvider: storageProvider,
+		options:         options,
+	}, nil
+}
+
+// IsInstalled checks if apptainer is on the PATH of the compute node.
+func (e *Executor) IsInstalled(context.Context) (bool, error) {
+	_, err := exec.LookPath(e.options.Binary)
+	return err == nil, nil
+}
+
+// GetBidStrategy implements executor.Executor
+func (e *Executor) GetBidStrategy(context.Context) (bidstrategy.BidStrategy, error) {
+	return NewBidStrategy(), nil
+}
+
+func (e *Executor) HasStorageLocally(ctx context.Context, volume model.StorageSpec) (bool, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/apptainer.Executor.HasStorageLocally")
+	defer span.End()
+
+	s, err := e.StorageProvider.Get(ctx, volume.StorageSource)
+	if err != nil {
+		return false, err
+	}
+
+	return s.HasStorageLocally(ctx, volume)
+}
+
+func (e *Executor) GetVolumeSize(ctx context.Context, volume model.StorageSpec) (uint64, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/apptainer.Executor.GetVolumeSize")
+	defer span.End()
+
+	storageProvider, err := e.StorageProvider.Get(ctx, volume.StorageSource)
+	if err != nil {
+		return 0, err
+	}
+	return storageProvider.GetVolumeSize(ctx, volume)
+}
+
+//nolint:funlen
+func (e *Executor) Run(
+	ctx context.Context,
+	job model.Job,
+	jobResultsDir string,
+) (*model.RunCommandResult, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/apptainer.Executor.Run")
+	defer span.End()
+
+	inputVolumes, err := storage.ParallelPrepareStorage(ctx, e.StorageProvider, job.Spec.Inputs)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	var binds []bind
+	for spec, volume := range inputVolumes {
+		if volume.Type != storage.StorageVolumeConnectorBind {
+			return executor.FailResult(fmt.Errorf("unknown storage volume type: %s", volume.Type))
+		}
+		log.Ctx(ctx).Trace().Msgf("Input Volume: %+v %+v", spec, volume)
+		binds = append(binds, bind{source: volume.Source, target: volume.Target, readOnly: !volume.ReadWrite})
+	}
+
+	for _, output := range job.Spec.Outputs {
+		if output.Name == "" {
+			return executor.FailResult(fmt.Errorf("output volume has no name: %+v", output))
+		}
+		if output.Path == "" {
+			return executor.FailResult(fmt.Errorf("output volume has no path: %+v", output))
+		}
+
+		srcd := filepath.Join(jobResultsDir, output.Name)
+		if err = os.Mkdir(srcd, util.OS_ALL_R|util.OS_ALL_X|util.OS_USER_W); err != nil {
+			return executor.FailResult(err)
+		}
+		log.Ctx(ctx).Trace().Msgf("Output Volume: %+v", output)
+		binds = append(binds, bind{source: srcd, target: output.Path})
+	}
+
+	image, err := e.pullImage(ctx, job.Spec.Docker.Image)
+	if err != nil {
+		return executor.FailResult(errors.Wrapf(err, docker.ImagePullError, job.Spec.Docker.Image))
+	}
+
+	jsonJobSpec, err := model.JSONMarshalWithMax(job.Spec)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	// buffer the output on disk until the job exits, as only then do we know its exit code
+	stdout, err := os.CreateTemp("", "bacalhau-stdout-*")
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	defer executor.CloseAndRemove(ctx, stdout)
+	stderr, err := os.CreateTemp("", "bacalhau-stderr-*")
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	defer executor.CloseAndRemove(ctx, stderr)
+
+	args := runArgs(job, image, binds, e.options.ResourceLimits)
+	cmd := exec.CommandContext(ctx, e.options.Binary, args...) //nolint:gosec // running the job is the point
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = append(os.Environ(), jobEnv(e.options.Binary, job.Spec.Docker.EnvironmentVariables, string(jsonJobSpec))...)
+
+	log.Ctx(ctx).Debug().Strs("Args", args).Msg("Running apptainer job")
+	runErr := cmd.Run()
+
+	exitCode := 0
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) && ctx.Err() == nil {
+		// a non-zero exit code is reported as the result of the job, rather than as an error
+		exitCode = exitErr.ExitCode()
+		runErr = nil
+	} else if runErr != nil {
+		exitCode = -1
+		if ctx.Err() != nil {
+			runErr = ctx.Err()
+		}
+	}
+
+	_, stdoutErr := stdout.Seek(0, io.SeekStart)
+	_, stderrErr := stderr.Seek(0, io.SeekStart)
+
+	result, err := executor.WriteJobResults(
+		jobResultsDir,
+		stdout,
+		stderr,
+		exitCode,
+		multierr.Combine(runErr, stdoutErr, stderrErr),
+	)
+	if pinnedDigest, digestErr := job.Spec.Docker.PinnedDigest(); digestErr == nil {
+		result.ImageDigest = pinnedDigest.String()
+	}
+	return result, err
+}
+
+func (e *Executor) GetOutputStream(context.Context, model.Job, bool) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not implemented for apptainer executor")
+}
+
+// pullImage converts the docker image into a SIF file in the image cache, if
+// it hasn't been already, and returns the path of the SIF file.
+func (e *Executor) pullImage(ctx context.Context, image string) (string, error) {
+	if err := os.MkdirAll(e.options.ImageCacheDir, util.OS_USER_RWX); err != nil {
+		return "", err
+	}
+
+	sif := filepath.Join(e.options.ImageCacheDir, sifName(image))
+	if _, err := os.Stat(sif); err == nil {
+		return sif, nil
+	}
+
+	// pull to a temporary file first so that concurrent jobs never see a partial image
+	partial, err := os.CreateTemp(e.options.ImageCacheDir, ".pull-*.sif")
+	if err != nil {
+		return "", err
+	}
+	_ = partial.Close()
+	defer os.Remove(partial.Name())
+
+	log.Ctx(ctx).Debug().Str("Image", image).Str("SIF", sif).Msg("Converting image to SIF")
+	//nolint:gosec // the image is passed as a single argument
+	output, err := exec.CommandContext(ctx, e.options.Binary, "pull", "--force", partial.Name(), "docker://"+image).
+		CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return sif, os.Rename(partial.Name(), sif)
+}
+
+// Compile-time interface check:
+var _ executor.Executor = (*Executor)(nil)
@@ -0,0 +1,107 @@
+//go:build (unit || !integration) && unix
+
+package apptainer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeApptainer converts images by writing their name into the SIF file, and
+// runs jobs by printing their arguments and environment.
+const fakeApptainer = `#!/bin/sh
+if [ "$1" = "pull" ]; then
+	echo "$4" > "$3"
+	exit 0
+fi
+echo "$@"
+echo "$APPTAINERENV_GREETING"
+exit 2
+`
+
+func TestRunArgs(t *testing.T) {
+	job := model.Job{Spec: model.Spec{
+		Engine: model.EngineApptainer,
+		Docker: model.JobSpecDocker{
+			Image:            "ubuntu",
+			Entrypoint:       []string{"echo", "hello"},
+			WorkingDirectory: "/work",
+		},
+		Resources: model.ResourceUsageConfig{CPU: "500m", Memory: "1Ki", GPU: "1"},
+	}}
+	binds := []bind{
+		{source: "/tmp/input", target: "/inputs", readOnly: true},
+		{source: "/tmp/output", target: "/outputs"},
+	}
+
+	require.Equal(t, []string{
+		"exec", "--containall",
+		"--bind", "/tmp/input:/inputs:ro",
+		"--bind", "/tmp/output:/outputs",
+		"--pwd", "/work",
+		"--nv",
+		"image.sif", "echo", "hello",
+	}, runArgs(job, "image.sif", binds, false))
+
+	require.Equal(t, []string{
+		"exec", "--containall",
+		"--pwd", "/work",
+		"--nv",
+		"--cpus", "0.5",
+		"--memory", "1024",
+		"image.sif", "echo", "hello",
+	}, runArgs(job, "image.sif", nil, true))
+
+	job.Spec.Docker.Entrypoint = nil
+	require.Equal(t, []string{"run", "--containall", "--pwd", "/work", "--nv", "image.sif"}, runArgs(job, "image.sif", nil, false))
+}
+
+func TestJobEnv(t *testing.T) {
+	require.Equal(t,
+		[]string{"APPTAINERENV_A=b", "APPTAINERENV_BACALHAU_JOB_SPEC={}"},
+		jobEnv("apptainer", []string{"A=b"}, "{}"))
+	require.Equal(t,
+		[]string{"SINGULARITYENV_BACALHAU_JOB_SPEC={}"},
+		jobEnv("/usr/bin/singularity", nil, "{}"))
+}
+
+func TestRunConvertsImageOnce(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "apptainer")
+	require.NoError(t, os.WriteFile(binary, []byte(fakeApptainer), 0700))
+	cacheDir := t.TempDir()
+
+	exec, err := NewExecutor(context.Background(), nil, ExecutorOptions{Binary: binary, ImageCacheDir: cacheDir})
+	require.NoError(t, err)
+	installed, err := exec.IsInstalled(context.Background())
+	require.NoError(t, err)
+	require.True(t, installed)
+
+	job := model.Job{Spec: model.Spec{
+		Engine: model.EngineApptainer,
+		Docker: model.JobSpecDocker{
+			Image:                "ubuntu",
+			EnvironmentVariables: []string{"GREETING=hello"},
+		},
+	}}
+	sif := filepath.Join(cacheDir, sifName("ubuntu"))
+
+	for i := 0; i < 2; i++ {
+		result, err := exec.Run(context.Background(), job, t.TempDir())
+		require.NoError(t, err)
+		require.Equal(t, 2, result.ExitCode)
+		require.Equal(t, "run --containall "+sif+"\nhello\n", result.STDOUT)
+	}
+
+	contents, err := os.ReadFile(sif)
+	require.NoError(t, err)
+	require.Equal(t, "docker://ubuntu\n", string(contents))
+
+	entries, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/rs/zerolog/log"
+	"go.uber.org/multierr"
+)
+
+const (
+	// ArrayIndexEnvVar is set to the index that each run of an array job is for.
+	ArrayIndexEnvVar = "BACALHAU_ARRAY_INDEX"
+	// ArrayCountEnvVar is set to the number of indices of an array job.
+	ArrayCountEnvVar = "BACALHAU_ARRAY_COUNT"
+)
+
+// RunArray runs an array job with the executor once for each of its indices,
+// in turn. The results of each index are written to a directory named after
+// the index in the results directory, with a summary of the exit code of every
+// index written as the output of the job itself.
+//
+// An index failing doesn't stop the others from running. The exit code of the
+// job is that of the first index to exit with a non-zero code.
+func RunArray(ctx context.Context, e Executor, job model.Job, resultsDir string) (*model.RunCommandResult, error) {
+	var summary strings.Builder
+	var runErr error
+	exitCode := 0
+
+	for index := 0; index < job.Spec.Array.Count; index++ {
+		if ctx.Err() != nil {
+			runErr = multierr.Append(runErr, ctx.Err())
+			break
+		}
+
+		indexDir := filepath.Join(resultsDir, strconv.Itoa(index))
+		if err := os.Mkdir(indexDir, util.OS_ALL_R|util.OS_ALL_X|util.OS_USER_W); err != nil {
+			return FailResult(err)
+		}
+
+		log.Ctx(ctx).Debug().Int("Index", index).Msg("Running array job index")
+		result, err := e.Run(ctx, withArrayIndex(job, index), indexDir)
+		if err != nil {
+			runErr = multierr.Append(runErr, fmt.Errorf("index %d: %w", index, err))
+			fmt.Fprintf(&summary, "%d: error: %s\n", index, err)
+			continue
+		}
+
+		fmt.Fprintf(&summary, "%d: exit code %d\n", index, result.ExitCode)
+		if exitCode == 0 {
+			exitCode = result.ExitCode
+		}
+	}
+
+	return WriteJobResults(resultsDir, strings.NewReader(summary.String()), nil, exitCode, runErr)
+}
+
+// withArrayIndex returns a copy of the job that tells the executor which index
+// it is running, through the environment of the job.
+func withArrayIndex(job model.Job, index int) model.Job {
+	variables := map[string]string{
+		ArrayIndexEnvVar: strconv.Itoa(index),
+		ArrayCountEnvVar: strconv.Itoa(job.Spec.Array.Count),
+	}
+	env := []string{
+		ArrayIndexEnvVar + "=" + variables[ArrayIndexEnvVar],
+		ArrayCountEnvVar + "=" + variables[ArrayCountEnvVar],
+	}
+
+	// copy anything we append to or set, so that indices don't share it
+	switch job.Spec.Engine {
+	case model.EngineDocker, model.EngineApptainer:
+		job.Spec.Docker.EnvironmentVariables = append(append([]string{}, job.Spec.Docker.EnvironmentVariables...), env...)
+	case model.EngineProcess:
+		job.Spec.Process.EnvironmentVariables = append(append([]string{}, job.Spec.Process.EnvironmentVariables...), env...)
+	case model.EnginePython:
+		job.Spec.Python.EnvironmentVariables = append(append([]string{}, job.Spec.Python.EnvironmentVariables...), env...)
+	case model.EngineWasm:
+		job.Spec.Wasm.EnvironmentVariables = mergeVariables(job.Spec.Wasm.EnvironmentVariables, variables)
+	case model.EnginePlugin:
+		job.Spec.Plugin.Parameters = mergeVariables(job.Spec.Plugin.Parameters, variables)
+	}
+	return job
+}
+
+func mergeVariables(existing, extra map[string]string) map[string]string {
+	merged := make(map[string]string, len(existing)+len(extra))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for key, value := range extra {
+		merged[key] = value
+	}
+	return merged
+}
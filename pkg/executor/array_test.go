@@ -0,0 +1,53 @@
+//go:build unit || !integration
+
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/noop"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunArray(t *testing.T) {
+	noopExecutor := noop.NewNoopExecutorWithConfig(noop.ExecutorConfig{
+		ExternalHooks: noop.ExecutorConfigExternalHooks{
+			JobHandler: func(ctx context.Context, job model.Job, resultsDir string) (*model.RunCommandResult, error) {
+				env := strings.Join(job.Spec.Docker.EnvironmentVariables, " ")
+				if strings.Contains(env, executor.ArrayIndexEnvVar+"=2") {
+					return executor.FailResult(fmt.Errorf("boom"))
+				}
+				exitCode := 0
+				if strings.Contains(env, executor.ArrayIndexEnvVar+"=1") {
+					exitCode = 4
+				}
+				return executor.WriteJobResults(resultsDir, strings.NewReader(env), nil, exitCode, nil)
+			},
+		},
+	})
+
+	job := model.Job{Spec: model.Spec{
+		Engine: model.EngineDocker,
+		Docker: model.JobSpecDocker{EnvironmentVariables: []string{"GREETING=hello"}},
+		Array:  model.ArrayConfig{Count: 4},
+	}}
+	resultsDir := t.TempDir()
+	result, err := executor.RunArray(context.Background(), noopExecutor, job, resultsDir)
+	require.Error(t, err)
+	require.Equal(t, 4, result.ExitCode)
+	require.Equal(t, "0: exit code 0\n1: exit code 4\n2: error: boom\n3: exit code 0\n", result.STDOUT)
+
+	stdout, err := os.ReadFile(filepath.Join(resultsDir, "3", model.DownloadFilenameStdout))
+	require.NoError(t, err)
+	require.Equal(t, "GREETING=hello BACALHAU_ARRAY_INDEX=3 BACALHAU_ARRAY_COUNT=4", string(stdout))
+
+	// indices don't change the job they were run from
+	require.Equal(t, []string{"GREETING=hello"}, job.Spec.Docker.EnvironmentVariables)
+}
@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// checkpointResultsDir is where in a checkpoint the results of the job are saved.
+const checkpointResultsDir = "results"
+
+// SaveCheckpointResults copies what the job wrote to its results directory so
+// far into the checkpoint, as the state of the job refers to those files.
+func SaveCheckpointResults(resultsDir string, checkpointDir string) error {
+	return copyTree(resultsDir, filepath.Join(checkpointDir, checkpointResultsDir))
+}
+
+// RestoreCheckpointResults copies the results saved in the checkpoint into the
+// results directory of the job that is resumed from it.
+func RestoreCheckpointResults(checkpointDir string, resultsDir string) error {
+	return copyTree(filepath.Join(checkpointDir, checkpointResultsDir), resultsDir)
+}
+
+// copyTree copies the directories and regular files under src to dst,
+// overwriting files that are already there.
+func copyTree(src string, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+func copyFile(src string, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
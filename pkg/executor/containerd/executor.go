@@ -0,0 +1,453 @@
+package containerd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/docker"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	pkgUtil "github.com/bacalhau-project/bacalhau/pkg/util"
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/cio"
+	"github.com/containerd/containerd/containers"
+	"github.com/containerd/containerd/contrib/nvidia"
+	"github.com/containerd/containerd/oci"
+	reference "github.com/containerd/containerd/reference/docker"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.uber.org/multierr"
+)
+
+const (
+	// DefaultAddress is where containerd listens on most hosts. k3s nodes run
+	// their own containerd at /run/k3s/containerd/containerd.sock.
+	DefaultAddress = "/run/containerd/containerd.sock"
+	// DefaultNamespace keeps the images and containers of jobs apart from the
+	// ones of other containerd clients on the host.
+	DefaultNamespace = "bacalhau"
+
+	// The period over which CPU limits are enforced, the same as docker's default.
+	cpuPeriod = 100000
+
+	labelExecutorName = "bacalhau-executor"
+	labelJobName      = "bacalhau-jobID"
+)
+
+// ExecutorOptions configures how to connect to containerd, and how job
+// containers are confined, the same as the docker executor's options.
+type ExecutorOptions struct {
+	// Address is the path of containerd's socket.
+	Address string
+	// Namespace is the containerd namespace that jobs are run in.
+	Namespace string
+	// UserNamespace requires job containers to run in a user namespace, which
+	// the containerd executor can't do, so it refuses to start if it is set.
+	UserNamespace bool
+	// SeccompProfile is the path to a seccomp profile to run job containers
+	// with, instead of the default profile.
+	SeccompProfile string
+	// AppArmorProfile is the name of an AppArmor profile loaded on the host to
+	// run job containers with, instead of the default profile.
+	AppArmorProfile string
+}
+
+// Executor runs docker jobs directly against containerd, without needing a
+// docker daemon on the compute node.
+type Executor struct {
+	// used to allow multiple executors to run against the same containerd
+	ID string
+
+	// the storage providers we can implement for a job
+	StorageProvider storage.StorageProvider
+
+	options ExecutorOptions
+	client  *containerd.Client
+}
+
+func NewExecutor(
+	_ context.Context,
+	cm *system.CleanupManager,
+	id string,
+	storageProvider storage.StorageProvider,
+	options ExecutorOptions,
+) (*Executor, error) {
+	if options.Address == "" {
+		options.Address = DefaultAddress
+	}
+	if options.Namespace == "" {
+		options.Namespace = DefaultNamespace
+	}
+	if options.UserNamespace {
+		return nil, fmt.Errorf("the containerd executor can't run jobs in a user namespace - use the docker executor")
+	}
+	if err := validateSeccompProfile(options.SeccompProfile); err != nil {
+		return nil, err
+	}
+
+	client, err := containerd.New(options.Address, containerd.WithDefaultNamespace(options.Namespace))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to containerd at %s", options.Address)
+	}
+
+	e := &Executor{
+		ID:              id,
+		StorageProvider: storageProvider,
+		options:         options,
+		client:          client,
+	}
+
+	cm.RegisterCallbackWithContext(e.cleanupAll)
+	cm.RegisterCallback(client.Close)
+
+	return e, nil
+}
+
+// IsInstalled checks if containerd is reachable.
+func (e *Executor) IsInstalled(ctx context.Context) (bool, error) {
+	return e.client.IsServing(ctx)
+}
+
+// GetBidStrategy implements executor.Executor
+func (e *Executor) GetBidStrategy(context.Context) (bidstrategy.BidStrategy, error) {
+	return NewBidStrategy(), nil
+}
+
+func (e *Executor) HasStorageLocally(ctx context.Context, volume model.StorageSpec) (bool, error) {
+	//nolint:ineffassign,staticcheck
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/containerd.Executor.HasStorageLocally")
+	defer span.End()
+
+	s, err := e.StorageProvider.Get(ctx, volume.StorageSource)
+	if err != nil {
+		return false, err
+	}
+
+	return s.HasStorageLocally(ctx, volume)
+}
+
+func (e *Executor) GetVolumeSize(ctx context.Context, volume model.StorageSpec) (uint64, error) {
+	s, err := e.StorageProvider.Get(ctx, volume.StorageSource)
+	if err != nil {
+		return 0, err
+	}
+	return s.GetVolumeSize(ctx, volume)
+}
+
+//nolint:funlen // will clean up
+func (e *Executor) Run(
+	ctx context.Context,
+	job model.Job,
+	jobResultsDir string,
+) (*model.RunCommandResult, error) {
+	//nolint:ineffassign,staticcheck
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/containerd.Executor.Run")
+	defer span.End()
+	defer e.cleanupJob(ctx, job)
+
+	inputVolumes, err := storage.ParallelPrepareStorage(ctx, e.StorageProvider, job.Spec.Inputs)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	var mounts []specs.Mount
+	for spec, volumeMount := range inputVolumes {
+		if volumeMount.Type != storage.StorageVolumeConnectorBind {
+			return executor.FailResult(fmt.Errorf("unknown storage volume type: %s", volumeMount.Type))
+		}
+		log.Ctx(ctx).Trace().Msgf("Input Volume: %+v %+v", spec, volumeMount)
+		mounts = append(mounts, bindMount(volumeMount.Source, volumeMount.Target, !volumeMount.ReadWrite))
+	}
+
+	for _, output := range job.Spec.Outputs {
+		if output.Name == "" {
+			return executor.FailResult(fmt.Errorf("output volume has no name: %+v", output))
+		}
+		if output.Path == "" {
+			return executor.FailResult(fmt.Errorf("output volume has no path: %+v", output))
+		}
+
+		srcd := filepath.Join(jobResultsDir, output.Name)
+		err = os.Mkdir(srcd, util.OS_ALL_R|util.OS_ALL_X|util.OS_USER_W)
+		if err != nil {
+			return executor.FailResult(err)
+		}
+
+		log.Ctx(ctx).Trace().Msgf("Output Volume: %+v", output)
+		mounts = append(mounts, bindMount(srcd, output.Path, false))
+	}
+
+	pinnedDigest, err := job.Spec.Docker.PinnedDigest()
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	image, err := e.pullImage(ctx, job.Spec.Docker.Image)
+	if err != nil {
+		return executor.FailResult(errors.Wrapf(err, docker.ImagePullError, job.Spec.Docker.Image))
+	}
+	imageDigest := image.Target().Digest
+	if pinnedDigest != "" && pinnedDigest != imageDigest {
+		err = fmt.Errorf(docker.ImageDigestMismatchError, job.Spec.Docker.Image, []string{imageDigest.String()}, pinnedDigest)
+		return executor.FailResult(err)
+	}
+	log.Ctx(ctx).Debug().Str("Image", job.Spec.Docker.Image).Str("Digest", imageDigest.String()).Msg("Resolved image digest")
+
+	jsonJobSpec, err := model.JSONMarshalWithMax(job.Spec)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	specOpts := []oci.SpecOpts{
+		oci.WithImageConfig(image),
+		oci.WithEnv(append(job.Spec.Docker.EnvironmentVariables,
+			fmt.Sprintf("BACALHAU_JOB_SPEC=%s", string(jsonJobSpec)),
+		)),
+		oci.WithMounts(mounts),
+	}
+	if len(job.Spec.Docker.Entrypoint) > 0 {
+		specOpts = append(specOpts, oci.WithProcessArgs(job.Spec.Docker.Entrypoint...))
+	}
+	if job.Spec.Docker.WorkingDirectory != "" {
+		specOpts = append(specOpts, oci.WithProcessCwd(job.Spec.Docker.WorkingDirectory))
+	}
+	specOpts = append(specOpts, resourceSpecOpts(job, executor.AllocatedGPUs(ctx))...)
+	specOpts = append(specOpts, networkSpecOpts(job)...)
+	// after the capabilities are set, which the default seccomp profile depends on
+	specOpts = append(specOpts, e.securitySpecOpts()...)
+
+	jobContainer, err := e.client.NewContainer(
+		ctx,
+		e.jobContainerName(job),
+		containerd.WithImage(image),
+		containerd.WithNewSnapshot(e.jobContainerName(job), image),
+		containerd.WithNewSpec(specOpts...),
+		containerd.WithContainerLabels(e.jobContainerLabels(job)),
+	)
+	if err != nil {
+		return executor.FailResult(errors.Wrap(err, "failed to create container"))
+	}
+
+	ctx = log.Ctx(ctx).With().Str("Container", jobContainer.ID()).Logger().WithContext(ctx)
+
+	// buffer the output on disk until the task exits, as only then do we know its exit code
+	stdout, err := os.CreateTemp("", "bacalhau-stdout-*")
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	defer executor.CloseAndRemove(ctx, stdout)
+	stderr, err := os.CreateTemp("", "bacalhau-stderr-*")
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	defer executor.CloseAndRemove(ctx, stderr)
+
+	task, err := jobContainer.NewTask(ctx, cio.NewCreator(cio.WithStreams(nil, stdout, stderr)))
+	if err != nil {
+		return executor.FailResult(errors.Wrap(err, "failed to create task"))
+	}
+
+	exitCh, err := task.Wait(ctx)
+	if err != nil {
+		return executor.FailResult(errors.Wrap(err, "failed to wait for task"))
+	}
+	if err = task.Start(ctx); err != nil {
+		// Special error to alert people about bad executable
+		internalTaskStartErrorMsg := "failed to start container"
+		if strings.Contains(err.Error(), "executable file not found") {
+			internalTaskStartErrorMsg = "Executable file not found"
+		}
+		return executor.FailResult(errors.Wrap(err, internalTaskStartErrorMsg))
+	}
+
+	// the idea here is even if the task errors
+	// we want to capture stdout, stderr and feed it back to the user
+	var taskError error
+	var taskExitStatusCode uint32
+	select {
+	case <-ctx.Done():
+		taskError = ctx.Err()
+	case exitStatus := <-exitCh:
+		taskExitStatusCode, _, taskError = exitStatus.Result()
+	}
+
+	// Can't use the original context as it may have already been timed out
+	detachedContext, cancel := context.WithTimeout(pkgUtil.NewDetachedContext(ctx), 10*time.Second)
+	defer cancel()
+	if taskError != nil {
+		_ = task.Kill(detachedContext, syscall.SIGKILL)
+	}
+	if _, err = task.Delete(detachedContext, containerd.WithProcessKill); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to delete task")
+	}
+
+	_, stdoutErr := stdout.Seek(0, io.SeekStart)
+	_, stderrErr := stderr.Seek(0, io.SeekStart)
+
+	result, err := executor.WriteJobResults(
+		jobResultsDir,
+		stdout,
+		stderr,
+		int(taskExitStatusCode),
+		multierr.Combine(taskError, stdoutErr, stderrErr),
+	)
+	result.ImageDigest = imageDigest.String()
+	return result, err
+}
+
+func (e *Executor) GetOutputStream(context.Context, model.Job, bool) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not implemented for containerd executor")
+}
+
+func (e *Executor) pullImage(ctx context.Context, image string) (containerd.Image, error) {
+	// unlike docker, containerd only understands fully qualified references
+	ref, err := reference.ParseDockerRef(image)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, set := os.LookupEnv("SKIP_IMAGE_PULL"); set {
+		return e.client.GetImage(ctx, ref.String())
+	}
+	return e.client.Pull(ctx, ref.String(), containerd.WithPullUnpack)
+}
+
+func (e *Executor) cleanupJob(ctx context.Context, job model.Job) {
+	// Use a detached context in case the current one has already been canceled
+	separateCtx, cancel := context.WithTimeout(pkgUtil.NewDetachedContext(ctx), 1*time.Minute)
+	defer cancel()
+	if config.ShouldKeepStack() {
+		return
+	}
+
+	err := e.removeContainersWithLabel(separateCtx, labelJobName, e.labelJobValue(job))
+	logLevel := map[bool]zerolog.Level{true: zerolog.DebugLevel, false: zerolog.ErrorLevel}[err == nil]
+	log.Ctx(ctx).WithLevel(logLevel).Err(err).Msg("Cleaned up job containerd resources")
+}
+
+func (e *Executor) cleanupAll(ctx context.Context) error {
+	// We have to use a detached context, rather than the one passed in to `NewExecutor`, as it may have already been
+	// canceled and so would prevent us from performing any cleanup work.
+	safeCtx := pkgUtil.NewDetachedContext(ctx)
+	if config.ShouldKeepStack() {
+		return nil
+	}
+
+	err := e.removeContainersWithLabel(safeCtx, labelExecutorName, e.ID)
+	logLevel := map[bool]zerolog.Level{true: zerolog.DebugLevel, false: zerolog.ErrorLevel}[err == nil]
+	log.Ctx(ctx).WithLevel(logLevel).Err(err).Msg("Cleaned up all containerd resources")
+
+	return nil
+}
+
+func (e *Executor) removeContainersWithLabel(ctx context.Context, labelName, labelValue string) error {
+	containers, err := e.client.Containers(ctx, fmt.Sprintf("labels.%q==%s", labelName, labelValue))
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	for _, c := range containers {
+		if task, taskErr := c.Task(ctx, nil); taskErr == nil {
+			_, taskErr = task.Delete(ctx, containerd.WithProcessKill)
+			errs = multierr.Append(errs, taskErr)
+		}
+		errs = multierr.Append(errs, c.Delete(ctx, containerd.WithSnapshotCleanup))
+	}
+	return errs
+}
+
+func (e *Executor) jobContainerName(job model.Job) string {
+	return strings.Join([]string{"bacalhau", e.ID, job.ID(), "executor"}, "-")
+}
+
+func (e *Executor) jobContainerLabels(job model.Job) map[string]string {
+	return map[string]string{
+		labelExecutorName: e.ID,
+		labelJobName:      e.labelJobValue(job),
+	}
+}
+
+func (e *Executor) labelJobValue(job model.Job) string {
+	return e.ID + job.ID()
+}
+
+func bindMount(source, target string, readOnly bool) specs.Mount {
+	options := []string{"rbind", "rw"}
+	if readOnly {
+		options = []string{"rbind", "ro"}
+	}
+	return specs.Mount{
+		Type:        "bind",
+		Source:      source,
+		Destination: target,
+		Options:     options,
+	}
+}
+
+func resourceSpecOpts(job model.Job, gpus []model.GPU) []oci.SpecOpts {
+	var opts []oci.SpecOpts
+	resourceRequirements := capacity.ParseResourceUsageConfig(job.Spec.Resources)
+	if resourceRequirements.Memory > 0 {
+		opts = append(opts, oci.WithMemoryLimit(resourceRequirements.Memory))
+	}
+	if resourceRequirements.CPU > 0 {
+		opts = append(opts, withCPULimit(int64(resourceRequirements.CPU*cpuPeriod), cpuPeriod))
+	}
+	if len(gpus) > 0 {
+		opts = append(opts, nvidia.WithGPUs(nvidia.WithDeviceUUIDs(executor.GPUDeviceIDs(gpus)...), nvidia.WithAllCapabilities))
+	} else if resourceRequirements.GPU > 0 {
+		// the node doesn't describe its GPUs to allocate them
+		opts = append(opts, nvidia.WithGPUs(nvidia.WithDevices(0), nvidia.WithAllCapabilities))
+	}
+	return opts
+}
+
+// withCPULimit is the same as oci.WithCPUCFS, which is only available when
+// building for linux, so that the compute node still builds elsewhere.
+func withCPULimit(quota int64, period uint64) oci.SpecOpts {
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *specs.Spec) error {
+		if s.Linux == nil {
+			s.Linux = &specs.Linux{}
+		}
+		if s.Linux.Resources == nil {
+			s.Linux.Resources = &specs.LinuxResources{}
+		}
+		if s.Linux.Resources.CPU == nil {
+			s.Linux.Resources.CPU = &specs.LinuxCPU{}
+		}
+		s.Linux.Resources.CPU.Quota = &quota
+		s.Linux.Resources.CPU.Period = &period
+		return nil
+	}
+}
+
+func networkSpecOpts(job model.Job) []oci.SpecOpts {
+	// without any options, the container gets its own network namespace with
+	// only a loopback interface, which is what jobs without networking need
+	if job.Spec.Network.Type != model.NetworkFull {
+		return nil
+	}
+	return []oci.SpecOpts{
+		oci.WithHostNamespace(specs.NetworkNamespace),
+		oci.WithHostHostsFile,
+		oci.WithHostResolvconf,
+	}
+}
+
+// Compile-time interface check:
+var _ executor.Executor = (*Executor)(nil)
@@ -0,0 +1,50 @@
+package containerd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/containerd/containerd/contrib/apparmor"
+	"github.com/containerd/containerd/contrib/seccomp"
+	"github.com/containerd/containerd/oci"
+	hostapparmor "github.com/containerd/containerd/pkg/apparmor"
+)
+
+// defaultAppArmorProfile is the profile that containerd generates for job
+// containers on hosts with AppArmor, like docker-default for docker.
+const defaultAppArmorProfile = "bacalhau-default"
+
+// validateSeccompProfile checks that the seccomp profile at path can be read,
+// so that the node doesn't start if jobs would fail to run.
+func validateSeccompProfile(path string) error {
+	if path == "" {
+		return nil
+	}
+	profile, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read seccomp profile: %w", err)
+	}
+	if !json.Valid(profile) {
+		return fmt.Errorf("seccomp profile %s is not valid JSON", path)
+	}
+	return nil
+}
+
+// securitySpecOpts confines job containers with the node's seccomp and AppArmor
+// profiles, or else with the default ones, so that they are isolated as much as
+// the docker executor isolates them.
+func (e *Executor) securitySpecOpts() []oci.SpecOpts {
+	var opts []oci.SpecOpts
+	if e.options.SeccompProfile != "" {
+		opts = append(opts, seccomp.WithProfile(e.options.SeccompProfile))
+	} else {
+		opts = append(opts, seccomp.WithDefaultProfile())
+	}
+	if e.options.AppArmorProfile != "" {
+		opts = append(opts, apparmor.WithProfile(e.options.AppArmorProfile))
+	} else if hostapparmor.HostSupports() {
+		opts = append(opts, apparmor.WithDefaultProfile(defaultAppArmorProfile))
+	}
+	return opts
+}
@@ -0,0 +1,38 @@
+//go:build unit || !integration
+
+package containerd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/containerd/containerd/containers"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+)
+
+func applySecuritySpecOpts(t *testing.T, e *Executor) *specs.Spec {
+	spec := &specs.Spec{Process: &specs.Process{Capabilities: &specs.LinuxCapabilities{}}, Linux: &specs.Linux{}}
+	for _, opt := range e.securitySpecOpts() {
+		require.NoError(t, opt(context.Background(), nil, &containers.Container{}, spec))
+	}
+	return spec
+}
+
+func TestSecuritySpecOpts(t *testing.T) {
+	if runtime.GOOS == "linux" {
+		spec := applySecuritySpecOpts(t, &Executor{})
+		require.NotNil(t, spec.Linux.Seccomp, "jobs run with the default seccomp profile")
+	}
+
+	profile := filepath.Join(t.TempDir(), "seccomp.json")
+	require.NoError(t, os.WriteFile(profile, []byte(`{"defaultAction":"SCMP_ACT_ERRNO"}`), 0o600))
+	require.NoError(t, validateSeccompProfile(profile))
+	spec := applySecuritySpecOpts(t, &Executor{options: ExecutorOptions{SeccompProfile: profile}})
+	require.Equal(t, specs.LinuxSeccompAction("SCMP_ACT_ERRNO"), spec.Linux.Seccomp.DefaultAction)
+
+	require.Error(t, validateSeccompProfile(filepath.Join(t.TempDir(), "missing.json")))
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
@@ -19,6 +20,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	pkgUtil "github.com/bacalhau-project/bacalhau/pkg/util"
+	"github.com/c2h5oh/datasize"
 	dockertypes "github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
@@ -35,6 +37,48 @@ const (
 	labelJobName      = "bacalhau-jobID"
 )
 
+// checkpointID is the name of the checkpoint in the checkpoint directory of a job.
+const checkpointID = "bacalhau"
+
+// SandboxedRuntimeLabel is the node label that advertises the OCI runtime job
+// containers are sandboxed in, so that jobs can select nodes with e.g.
+// Sandboxed-Runtime=runsc.
+const SandboxedRuntimeLabel = "Sandboxed-Runtime"
+
+// ExecutorOptions lets node operators restrict how job containers are run.
+type ExecutorOptions struct {
+	// UserNamespace requires job containers to run in a user namespace, so that
+	// root inside a container is not root on the host. The docker daemon must
+	// either be rootless or have user namespace remapping enabled.
+	UserNamespace bool
+	// SeccompProfile is the path to a seccomp profile to run job containers
+	// with, instead of docker's default profile.
+	SeccompProfile string
+	// AppArmorProfile is the name of an AppArmor profile loaded on the host to
+	// run job containers with, instead of docker's default profile.
+	AppArmorProfile string
+	// AllowSecurityProfileOverride lets jobs choose their own seccomp and
+	// AppArmor profiles instead of the ones above.
+	AllowSecurityProfileOverride bool
+	// IODevices are the block devices, e.g. /dev/sda, that the IOPS limits of
+	// jobs are enforced on.
+	IODevices []string
+	// RequireDiskLimits declines jobs that request disk when the storage
+	// driver can't limit the size of their containers' filesystem. Those jobs
+	// run without the limit otherwise, as disk is also a scheduling hint.
+	RequireDiskLimits bool
+	// Runtime is the OCI runtime registered with the docker daemon to run job
+	// containers with, e.g. runsc to sandbox them in gVisor, instead of the
+	// daemon's default runtime.
+	Runtime string
+	// ScratchPath is where the scratch volume of executions is mounted in
+	// their containers, which is DefaultScratchPath if empty.
+	ScratchPath string
+	// ScratchSize limits the scratch volume of executions that don't request
+	// disk, which docker limits to half the memory of the host if zero.
+	ScratchSize datasize.ByteSize
+}
+
 type Executor struct {
 	// used to allow multiple docker executors to run against the same docker server
 	ID string
@@ -42,23 +86,58 @@ type Executor struct {
 	// the storage providers we can implement for a job
 	StorageProvider storage.StorageProvider
 
-	client *docker.Client
+	options        ExecutorOptions
+	seccompProfile string
+	client         *docker.Client
+
+	// set to 1 once creating a container with a size limit failed, as the
+	// storage driver can't enforce the disk limits of jobs then
+	storageQuotaUnsupported uint32
 }
 
 func NewExecutor(
-	_ context.Context,
+	ctx context.Context,
 	cm *system.CleanupManager,
 	id string,
 	storageProvider storage.StorageProvider,
+	options ExecutorOptions,
 ) (*Executor, error) {
 	dockerClient, err := docker.NewDockerClient()
 	if err != nil {
 		return nil, err
 	}
 
+	if options.UserNamespace {
+		usesUserNamespace, err := dockerClient.UsesUserNamespace(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !usesUserNamespace {
+			return nil, fmt.Errorf("running jobs in a user namespace requires a rootless docker daemon " +
+				"or one with user namespace remapping enabled")
+		}
+	}
+
+	if options.Runtime != "" {
+		hasRuntime, err := dockerClient.HasRuntime(ctx, options.Runtime)
+		if err != nil {
+			return nil, err
+		}
+		if !hasRuntime {
+			return nil, fmt.Errorf("docker daemon has no runtime named %q registered", options.Runtime)
+		}
+	}
+
+	seccompProfile, err := loadSeccompProfile(options.SeccompProfile)
+	if err != nil {
+		return nil, err
+	}
+
 	de := &Executor{
 		ID:              id,
 		StorageProvider: storageProvider,
+		options:         options,
+		seccompProfile:  seccompProfile,
 		client:          dockerClient,
 	}
 
@@ -78,7 +157,11 @@ func (e *Executor) IsInstalled(ctx context.Context) (bool, error) {
 
 // GetBidStrategy implements executor.Executor
 func (e *Executor) GetBidStrategy(context.Context) (bidstrategy.BidStrategy, error) {
-	return NewBidStrategy(e.client), nil
+	return bidstrategy.NewChainedBidStrategy(
+		NewBidStrategy(e.client),
+		NewSecurityProfileBidStrategy(e.options.AllowSecurityProfileOverride),
+		NewResourceLimitsBidStrategy(e.options.IODevices, e.options.RequireDiskLimits, e.canEnforceDiskLimit),
+	), nil
 }
 
 func (e *Executor) HasStorageLocally(ctx context.Context, volume model.StorageSpec) (bool, error) {
@@ -108,9 +191,51 @@ func (e *Executor) Run(
 	job model.Job,
 	jobResultsDir string,
 ) (*model.RunCommandResult, error) {
-	//nolint:ineffassign,staticcheck
 	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/docker.Executor.Run")
 	defer span.End()
+	return e.run(ctx, job, jobResultsDir, "")
+}
+
+// Checkpoint implements executor.Checkpointer with CRIU, which the docker daemon needs experimental features enabled
+// for. The job keeps running, and outputs it writes while its results are copied into the checkpoint may be missing
+// when it is resumed.
+func (e *Executor) Checkpoint(ctx context.Context, job model.Job, resultsDir string, checkpointDir string) error {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/docker.Executor.Checkpoint")
+	defer span.End()
+
+	err := e.client.CheckpointCreate(ctx, e.jobContainerName(job), dockertypes.CheckpointCreateOptions{
+		CheckpointID:  checkpointID,
+		CheckpointDir: checkpointDir,
+		Exit:          false,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to checkpoint container")
+	}
+	return executor.SaveCheckpointResults(resultsDir, checkpointDir)
+}
+
+// Resume implements executor.Checkpointer by creating the container of the job again, and starting it from the
+// checkpoint rather than from its entrypoint.
+func (e *Executor) Resume(
+	ctx context.Context,
+	job model.Job,
+	checkpointDir string,
+	jobResultsDir string,
+) (*model.RunCommandResult, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/docker.Executor.Resume")
+	defer span.End()
+	return e.run(ctx, job, jobResultsDir, checkpointDir)
+}
+
+// run runs the job, from the checkpoint in checkpointDir if it is set.
+//
+//nolint:funlen,gocyclo
+func (e *Executor) run(
+	ctx context.Context,
+	job model.Job,
+	jobResultsDir string,
+	checkpointDir string,
+) (*model.RunCommandResult, error) {
 	defer e.cleanupJob(ctx, job)
 
 	inputVolumes, err := storage.ParallelPrepareStorage(ctx, e.StorageProvider, job.Spec.Inputs)
@@ -126,8 +251,9 @@ func (e *Executor) Run(
 			log.Ctx(ctx).Trace().Msgf("Input Volume: %+v %+v", spec, volumeMount)
 			mounts = append(mounts, mount.Mount{
 				Type: mount.TypeBind,
-				// this is an input volume so is read only
-				ReadOnly: true,
+				// this is an input volume so is read only, unless the
+				// storage allows jobs to write to it
+				ReadOnly: !volumeMount.ReadWrite,
 				Source:   volumeMount.Source,
 				Target:   volumeMount.Target,
 			})
@@ -156,6 +282,15 @@ func (e *Executor) Run(
 		if err != nil {
 			return executor.FailResult(err)
 		}
+		if e.options.UserNamespace {
+			// root in the container is remapped to an unprivileged user on the host,
+			// which needs to be able to write to the output volume. Chmod explicitly
+			// as the mode passed to mkdir is subject to the umask.
+			err = os.Chmod(srcd, util.OS_ALL_RWX)
+			if err != nil {
+				return executor.FailResult(err)
+			}
+		}
 
 		log.Ctx(ctx).Trace().Msgf("Output Volume: %+v", output)
 
@@ -174,6 +309,25 @@ func (e *Executor) Run(
 		})
 	}
 
+	// scratch data is kept in a volume of its own, whose size is limited, rather than the container's filesystem
+	var scratchEnv []string
+	if scratch, ok := e.scratchMount(job, mounts); ok {
+		mounts = append(mounts, scratch)
+		scratchEnv = append(scratchEnv, fmt.Sprintf("%s=%s", scratchDirEnvVar, scratch.Target))
+	}
+
+	// the files that the checkpointed job had open must be there again when it resumes
+	if checkpointDir != "" {
+		if err = executor.RestoreCheckpointResults(checkpointDir, jobResultsDir); err != nil {
+			return executor.FailResult(errors.Wrap(err, "failed to restore results from checkpoint"))
+		}
+	}
+
+	pinnedDigest, err := job.Spec.Docker.PinnedDigest()
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
 	if _, set := os.LookupEnv("SKIP_IMAGE_PULL"); !set {
 		if pullErr := e.client.PullImage(ctx, job.Spec.Docker.Image); pullErr != nil {
 			pullErr = errors.Wrapf(pullErr, docker.ImagePullError, job.Spec.Docker.Image)
@@ -181,6 +335,14 @@ func (e *Executor) Run(
 		}
 	}
 
+	// verify the image we are about to run is the one the job was pinned to,
+	// and record what we actually ran so the execution can be reproduced
+	imageDigest, err := e.client.ResolveImageDigest(ctx, job.Spec.Docker.Image, pinnedDigest)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	log.Ctx(ctx).Debug().Str("Image", job.Spec.Docker.Image).Str("Digest", imageDigest.String()).Msg("Resolved image digest")
+
 	// json the job spec and pass it into all containers
 	// TODO: check if this will overwrite a user supplied version of this value
 	// (which is what we actually want to happen)
@@ -194,6 +356,7 @@ func (e *Executor) Run(
 	useEnv := append(job.Spec.Docker.EnvironmentVariables,
 		fmt.Sprintf("BACALHAU_JOB_SPEC=%s", string(jsonJobSpec)),
 	)
+	useEnv = append(useEnv, scratchEnv...)
 
 	containerConfig := &container.Config{
 		Image:      job.Spec.Docker.Image,
@@ -208,20 +371,35 @@ func (e *Executor) Run(
 
 	resourceRequirements := capacity.ParseResourceUsageConfig(job.Spec.Resources)
 
-	// Create GPU request if the job requests it
+	// Create GPU request if the job requests it, for the GPUs or MIG slices the execution was allocated
 	var deviceRequests []container.DeviceRequest
-	if resourceRequirements.GPU > 0 {
+	if gpus := executor.AllocatedGPUs(ctx); len(gpus) > 0 {
 		deviceRequests = append(deviceRequests,
 			container.DeviceRequest{
-				DeviceIDs:    []string{"0"}, // TODO: how do we know which device ID to use?
+				DeviceIDs:    executor.GPUDeviceIDs(gpus),
+				Capabilities: [][]string{{"gpu"}},
+			},
+		)
+		log.Ctx(ctx).Trace().Msgf("Adding GPUs %v to request", executor.GPUDeviceIDs(gpus))
+	} else if resourceRequirements.GPU > 0 {
+		deviceRequests = append(deviceRequests,
+			container.DeviceRequest{
+				DeviceIDs:    []string{"0"}, // the node doesn't describe its GPUs to allocate them
 				Capabilities: [][]string{{"gpu"}},
 			},
 		)
 		log.Ctx(ctx).Trace().Msgf("Adding %d GPUs to request", resourceRequirements.GPU)
 	}
 
+	securityOpts, err := e.securityOptions(job)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
 	hostConfig := &container.HostConfig{
-		Mounts: mounts,
+		Mounts:      mounts,
+		SecurityOpt: securityOpts,
+		Runtime:     e.options.Runtime,
 		Resources: container.Resources{
 			Memory:         int64(resourceRequirements.Memory),
 			NanoCPUs:       int64(resourceRequirements.CPU * NanoCPUCoefficient),
@@ -229,6 +407,13 @@ func (e *Executor) Run(
 		},
 	}
 
+	if atomic.LoadUint32(&e.storageQuotaUnsupported) == 0 || e.options.RequireDiskLimits {
+		applyDiskLimit(job, hostConfig)
+	}
+	if err = e.applyIOLimits(job, hostConfig); err != nil {
+		return executor.FailResult(err)
+	}
+
 	// Create a network if the job requests it
 	err = e.setupNetworkForJob(ctx, job, containerConfig, hostConfig)
 	if err != nil {
@@ -243,16 +428,38 @@ func (e *Executor) Run(
 		nil,
 		e.jobContainerName(job),
 	)
+	if err != nil && hostConfig.StorageOpt != nil && isStorageQuotaUnsupported(err) {
+		atomic.StoreUint32(&e.storageQuotaUnsupported, 1)
+		if e.options.RequireDiskLimits {
+			// the node stops bidding on jobs that need a disk limit rather than running them without one
+			return executor.FailResult(fmt.Errorf("docker's storage driver can't enforce the disk limit of the job: %w", err))
+		}
+		log.Ctx(ctx).Warn().Err(err).Msg("Docker's storage driver can't enforce disk limits, running the job without one")
+		hostConfig.StorageOpt = nil
+		jobContainer, err = e.client.ContainerCreate(
+			ctx,
+			containerConfig,
+			hostConfig,
+			nil,
+			nil,
+			e.jobContainerName(job),
+		)
+	}
 	if err != nil {
 		return executor.FailResult(errors.Wrap(err, "failed to create container"))
 	}
 
 	ctx = log.Ctx(ctx).With().Str("Container", jobContainer.ID).Logger().WithContext(ctx)
 
+	startOptions := dockertypes.ContainerStartOptions{}
+	if checkpointDir != "" {
+		startOptions.CheckpointID = checkpointID
+		startOptions.CheckpointDir = checkpointDir
+	}
 	containerStartError := e.client.ContainerStart(
 		ctx,
 		jobContainer.ID,
-		dockertypes.ContainerStartOptions{},
+		startOptions,
 	)
 	if containerStartError != nil {
 		// Special error to alert people about bad executable
@@ -289,16 +496,18 @@ func (e *Executor) Run(
 	stdoutPipe, stderrPipe, logsErr := e.client.FollowLogs(detachedContext, jobContainer.ID)
 	log.Ctx(detachedContext).Debug().Err(logsErr).Msg("Captured stdout/stderr for container")
 
-	return executor.WriteJobResults(
+	result, err := executor.WriteJobResults(
 		jobResultsDir,
 		stdoutPipe,
 		stderrPipe,
 		int(containerExitStatusCode),
 		multierr.Combine(containerError, logsErr),
 	)
+	result.ImageDigest = imageDigest.String()
+	return result, err
 }
 
-func (e *Executor) GetOutputStream(ctx context.Context, job model.Job) (io.ReadCloser, error) {
+func (e *Executor) GetOutputStream(ctx context.Context, job model.Job, follow bool) (io.ReadCloser, error) {
 	ctrID, err := e.client.FindContainer(ctx, labelJobName, e.labelJobValue(job))
 	if err != nil {
 		return nil, err
@@ -306,7 +515,7 @@ func (e *Executor) GetOutputStream(ctx context.Context, job model.Job) (io.ReadC
 
 	// As for the output stream since 1 which docker interprets as a unix timestamp, so this
 	// should retrieve all of the logs since the start of the execution.
-	reader, err := e.client.GetOutputStream(ctx, ctrID, "1")
+	reader, err := e.client.GetOutputStream(ctx, ctrID, "1", follow)
 	if err != nil {
 		return nil, err
 	}
@@ -366,3 +575,4 @@ func (e *Executor) labelJobValue(job model.Job) string {
 
 // Compile-time interface check:
 var _ executor.Executor = (*Executor)(nil)
+var _ executor.Checkpointer = (*Executor)(nil)
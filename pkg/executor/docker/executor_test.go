@@ -49,6 +49,7 @@ func (s *ExecutorTestSuite) SetupTest() {
 		s.cm,
 		"bacalhau-executor-unittest",
 		model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{}),
+		ExecutorOptions{},
 	)
 	require.NoError(s.T(), err)
 
@@ -305,6 +306,80 @@ func (s *ExecutorTestSuite) TestDockerNetworkingAppendsHTTPHeader() {
 	require.Equal(s.T(), "test", result.STDOUT, result.STDOUT)
 }
 
+func (s *ExecutorTestSuite) TestDockerRecordsImageDigest() {
+	result, err := s.runJob(model.Spec{
+		Engine: model.EngineDocker,
+		Docker: model.JobSpecDocker{
+			Image:      "ubuntu",
+			Entrypoint: []string{"true"},
+		},
+	})
+	require.NoError(s.T(), err)
+	require.NotEmpty(s.T(), result.ImageDigest)
+
+	pinned, err := s.runJob(model.Spec{
+		Engine: model.EngineDocker,
+		Docker: model.JobSpecDocker{
+			Image:      "ubuntu@" + result.ImageDigest,
+			Entrypoint: []string{"true"},
+		},
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), result.ImageDigest, pinned.ImageDigest)
+}
+
+func (s *ExecutorTestSuite) TestDockerRejectsMismatchedImageDigest() {
+	_, err := s.runJob(model.Spec{
+		Engine: model.EngineDocker,
+		Docker: model.JobSpecDocker{
+			Image:      "ubuntu",
+			Entrypoint: []string{"true"},
+		},
+	})
+	require.NoError(s.T(), err)
+
+	// don't pull so that the executor has to verify against the local image
+	s.T().Setenv("SKIP_IMAGE_PULL", "true")
+	_, err = s.runJob(model.Spec{
+		Engine: model.EngineDocker,
+		Docker: model.JobSpecDocker{
+			Image:      "ubuntu@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			Entrypoint: []string{"true"},
+		},
+	})
+	require.Error(s.T(), err)
+}
+
+func (s *ExecutorTestSuite) TestUserNamespaceRequiresSupportingDaemon() {
+	ctx := context.Background()
+	supported, err := s.executor.client.UsesUserNamespace(ctx)
+	require.NoError(s.T(), err)
+
+	_, err = NewExecutor(
+		ctx,
+		s.cm,
+		"bacalhau-executor-userns-unittest",
+		model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{}),
+		ExecutorOptions{UserNamespace: true},
+	)
+	if supported {
+		require.NoError(s.T(), err)
+	} else {
+		require.Error(s.T(), err)
+	}
+}
+
+func (s *ExecutorTestSuite) TestRuntimeMustBeRegisteredWithDaemon() {
+	_, err := NewExecutor(
+		context.Background(),
+		s.cm,
+		"bacalhau-executor-runtime-unittest",
+		model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{}),
+		ExecutorOptions{Runtime: "bacalhau-no-such-runtime"},
+	)
+	require.Error(s.T(), err)
+}
+
 func (s *ExecutorTestSuite) TestTimesOutCorrectly() {
 	expected := "message after sleep"
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -348,7 +423,7 @@ func (s *ExecutorTestSuite) TestDockerStreamsAlreadyComplete() {
 	}()
 
 	job := model.Job{Metadata: model.Metadata{ID: id}, Spec: spec}
-	reader, err := s.executor.GetOutputStream(ctx, job)
+	reader, err := s.executor.GetOutputStream(ctx, job, true)
 
 	<-done
 	require.Nil(s.T(), reader)
@@ -383,7 +458,7 @@ func (s *ExecutorTestSuite) TestDockerStreamsSlowTask() {
 	time.Sleep(time.Duration(500) * time.Millisecond)
 
 	job := model.Job{Metadata: model.Metadata{ID: id}, Spec: spec}
-	reader, err := s.executor.GetOutputStream(ctx, job)
+	reader, err := s.executor.GetOutputStream(ctx, job, true)
 
 	require.NotNil(s.T(), reader)
 	require.NoError(s.T(), err)
@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/docker/docker/api/types/mount"
+)
+
+// DefaultScratchPath is where the scratch volume of executions is mounted in
+// their containers, so that the temporary files of jobs are kept in it.
+const DefaultScratchPath = "/tmp"
+
+// scratchDirEnvVar tells jobs where their scratch volume is mounted.
+const scratchDirEnvVar = "BACALHAU_SCRATCH_DIR"
+
+func (e *Executor) scratchPath() string {
+	if e.options.ScratchPath != "" {
+		return e.options.ScratchPath
+	}
+	return DefaultScratchPath
+}
+
+// scratchMount returns the writable volume that the job keeps its scratch
+// data in, rather than the container's filesystem. It is a tmpfs, which
+// counts towards the memory of the job, limited to the disk the job requested
+// or else to the scratch size of the node. Nothing is mounted if the job
+// mounts one of its volumes at the scratch path itself.
+func (e *Executor) scratchMount(job model.Job, mounts []mount.Mount) (mount.Mount, bool) {
+	path := e.scratchPath()
+	for _, m := range mounts {
+		if m.Target == path {
+			return mount.Mount{}, false
+		}
+	}
+	size := capacity.ConvertBytesString(job.Spec.Resources.Disk)
+	if size == 0 {
+		size = e.options.ScratchSize.Bytes()
+	}
+	return mount.Mount{
+		Type:   mount.TypeTmpfs,
+		Target: path,
+		TmpfsOptions: &mount.TmpfsOptions{
+			// docker defaults to half the memory of the host if zero
+			SizeBytes: int64(size),
+			// writable by whichever user the job runs as, like /tmp
+			Mode: 0o1777,
+		},
+	}, true
+}
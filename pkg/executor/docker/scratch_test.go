@@ -0,0 +1,30 @@
+//go:build unit || !integration
+
+package docker
+
+import (
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/c2h5oh/datasize"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScratchMount(t *testing.T) {
+	executor := &Executor{options: ExecutorOptions{ScratchSize: datasize.MB}}
+
+	scratch, ok := executor.scratchMount(jobWithResources(model.ResourceUsageConfig{Disk: "1Mi"}), nil)
+	require.True(t, ok)
+	require.Equal(t, mount.TypeTmpfs, scratch.Type)
+	require.Equal(t, DefaultScratchPath, scratch.Target)
+	require.Equal(t, int64(1<<20), scratch.TmpfsOptions.SizeBytes, "the scratch volume is limited to the disk the job requested")
+
+	scratch, ok = executor.scratchMount(jobWithResources(model.ResourceUsageConfig{}), nil)
+	require.True(t, ok)
+	require.Equal(t, int64(datasize.MB), scratch.TmpfsOptions.SizeBytes, "or else to the scratch size of the node")
+
+	executor.options.ScratchPath = "/scratch"
+	_, ok = executor.scratchMount(jobWithResources(model.ResourceUsageConfig{}), []mount.Mount{{Target: "/scratch"}})
+	require.False(t, ok, "jobs that mount a volume at the scratch path keep it")
+}
@@ -0,0 +1,93 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// loadSeccompProfile reads the seccomp profile at path, returning its contents
+// in the form docker expects them as a security option.
+func loadSeccompProfile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	profile, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read seccomp profile: %w", err)
+	}
+	if !json.Valid(profile) {
+		return "", fmt.Errorf("seccomp profile %s is not valid JSON", path)
+	}
+	return string(profile), nil
+}
+
+// securityOptions returns the docker security options to run the job with,
+// applying the job's own profiles over the node's defaults.
+func (e *Executor) securityOptions(job model.Job) ([]string, error) {
+	if job.Spec.Docker.OverridesSecurityProfiles() && !e.options.AllowSecurityProfileOverride {
+		return nil, fmt.Errorf("job overrides security profiles, which this node does not allow")
+	}
+
+	var securityOpts []string
+	seccompProfile := e.seccompProfile
+	if job.Spec.Docker.SeccompProfile != "" {
+		seccompProfile = job.Spec.Docker.SeccompProfile
+	}
+	if seccompProfile != "" {
+		securityOpts = append(securityOpts, "seccomp="+seccompProfile)
+	}
+
+	appArmorProfile := e.options.AppArmorProfile
+	if job.Spec.Docker.AppArmorProfile != "" {
+		appArmorProfile = job.Spec.Docker.AppArmorProfile
+	}
+	if appArmorProfile != "" {
+		securityOpts = append(securityOpts, "apparmor="+appArmorProfile)
+	}
+	return securityOpts, nil
+}
+
+// NewSecurityProfileBidStrategy returns a bid strategy that rejects docker jobs
+// that override the node's security profiles, unless the node allows it.
+// Operators that allow overrides can still vet them using a job selection probe.
+func NewSecurityProfileBidStrategy(allowOverride bool) bidstrategy.BidStrategy {
+	return &securityProfileBidStrategy{allowOverride: allowOverride}
+}
+
+type securityProfileBidStrategy struct {
+	allowOverride bool
+}
+
+// ShouldBid implements bidstrategy.BidStrategy
+func (s *securityProfileBidStrategy) ShouldBid(
+	_ context.Context,
+	request bidstrategy.BidStrategyRequest,
+) (bidstrategy.BidStrategyResponse, error) {
+	if request.Job.Spec.Engine != model.EngineDocker || s.allowOverride {
+		return bidstrategy.NewShouldBidResponse(), nil
+	}
+
+	if request.Job.Spec.Docker.OverridesSecurityProfiles() {
+		return bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason:    "Node does not allow jobs to override its seccomp or AppArmor profiles",
+		}, nil
+	}
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+// ShouldBidBasedOnUsage implements bidstrategy.BidStrategy
+func (*securityProfileBidStrategy) ShouldBidBasedOnUsage(
+	_ context.Context,
+	_ bidstrategy.BidStrategyRequest,
+	_ model.ResourceUsageData,
+) (bidstrategy.BidStrategyResponse, error) {
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+var _ bidstrategy.BidStrategy = (*securityProfileBidStrategy)(nil)
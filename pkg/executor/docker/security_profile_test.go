@@ -0,0 +1,100 @@
+//go:build unit || !integration
+
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func jobWithSecurityProfiles(seccompProfile, appArmorProfile string) model.Job {
+	return model.Job{
+		Spec: model.Spec{
+			Engine: model.EngineDocker,
+			Docker: model.JobSpecDocker{
+				Image:           "ubuntu",
+				SeccompProfile:  seccompProfile,
+				AppArmorProfile: appArmorProfile,
+			},
+		},
+	}
+}
+
+func TestSecurityOptions(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		executor Executor
+		job      model.Job
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "docker defaults",
+			job:      jobWithSecurityProfiles("", ""),
+			expected: nil,
+		},
+		{
+			name: "node defaults",
+			executor: Executor{
+				options:        ExecutorOptions{AppArmorProfile: "bacalhau-jobs"},
+				seccompProfile: `{"defaultAction":"SCMP_ACT_ERRNO"}`,
+			},
+			job:      jobWithSecurityProfiles("", ""),
+			expected: []string{`seccomp={"defaultAction":"SCMP_ACT_ERRNO"}`, "apparmor=bacalhau-jobs"},
+		},
+		{
+			name: "job overrides node defaults",
+			executor: Executor{
+				options: ExecutorOptions{
+					AppArmorProfile:              "bacalhau-jobs",
+					AllowSecurityProfileOverride: true,
+				},
+				seccompProfile: `{"defaultAction":"SCMP_ACT_ERRNO"}`,
+			},
+			job:      jobWithSecurityProfiles("unconfined", "other"),
+			expected: []string{"seccomp=unconfined", "apparmor=other"},
+		},
+		{
+			name: "job overrides without permission",
+			executor: Executor{
+				options: ExecutorOptions{AppArmorProfile: "bacalhau-jobs"},
+			},
+			job: jobWithSecurityProfiles("", "unconfined"),
+			err: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			securityOpts, err := test.executor.securityOptions(test.job)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, securityOpts)
+		})
+	}
+}
+
+func TestBidsBasedOnSecurityProfileOverride(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		allowOverride bool
+		job           model.Job
+		shouldBid     bool
+	}{
+		{name: "no override", job: jobWithSecurityProfiles("", ""), shouldBid: true},
+		{name: "override rejected", job: jobWithSecurityProfiles("unconfined", ""), shouldBid: false},
+		{name: "override allowed", allowOverride: true, job: jobWithSecurityProfiles("", "unconfined"), shouldBid: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			strategy := NewSecurityProfileBidStrategy(test.allowOverride)
+			response, err := strategy.ShouldBid(context.Background(), bidstrategy.BidStrategyRequest{Job: test.job})
+			require.NoError(t, err)
+			require.Equal(t, test.shouldBid, response.ShouldBid)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/docker/docker/api/types/blkiodev"
+	"github.com/docker/docker/api/types/container"
+)
+
+// applyIOLimits throttles the job's disk operations on the devices the node
+// operator configured, as docker can only throttle specific block devices.
+func (e *Executor) applyIOLimits(job model.Job, hostConfig *container.HostConfig) error {
+	iops := capacity.ConvertIOPSString(job.Spec.Resources.IOPS)
+	if iops == 0 {
+		return nil
+	}
+	if len(e.options.IODevices) == 0 {
+		return fmt.Errorf("job requested a limit of %d IOPS but no devices are configured to enforce it", iops)
+	}
+
+	for _, device := range e.options.IODevices {
+		hostConfig.BlkioDeviceReadIOps = append(hostConfig.BlkioDeviceReadIOps, &blkiodev.ThrottleDevice{Path: device, Rate: iops})
+		hostConfig.BlkioDeviceWriteIOps = append(hostConfig.BlkioDeviceWriteIOps, &blkiodev.ThrottleDevice{Path: device, Rate: iops})
+	}
+	return nil
+}
+
+// applyDiskLimit limits the size of the container's writable filesystem to the
+// disk the job requested. Input, output and scratch volumes are mounted
+// separately and so are not included.
+func applyDiskLimit(job model.Job, hostConfig *container.HostConfig) {
+	disk := capacity.ConvertBytesString(job.Spec.Resources.Disk)
+	if disk == 0 {
+		return
+	}
+	hostConfig.StorageOpt = map[string]string{"size": strconv.FormatUint(disk, 10)}
+}
+
+// isStorageQuotaUnsupported returns whether the error is docker refusing to
+// create a container because its storage driver cannot enforce a size quota.
+func isStorageQuotaUnsupported(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "storage-opt") || strings.Contains(msg, "storage opt")
+}
+
+// canEnforceDiskLimit returns whether the storage driver can limit the size of
+// the filesystem of job containers.
+func (e *Executor) canEnforceDiskLimit(ctx context.Context) (bool, error) {
+	if atomic.LoadUint32(&e.storageQuotaUnsupported) == 1 {
+		return false, nil
+	}
+	return e.client.SupportsStorageQuota(ctx)
+}
+
+// NewResourceLimitsBidStrategy returns a bid strategy that rejects docker jobs
+// that request IOPS limits that the node can't enforce, rather than running
+// them without the limits. Jobs whose disk limits it can't enforce are only
+// rejected if the operator requires disk limits, as disk is also a hint for
+// scheduling that jobs request without needing it enforced.
+func NewResourceLimitsBidStrategy(
+	ioDevices []string, requireDiskLimits bool, canEnforceDiskLimit func(context.Context) (bool, error)) bidstrategy.BidStrategy {
+	return &resourceLimitsBidStrategy{
+		ioDevices:           ioDevices,
+		requireDiskLimits:   requireDiskLimits,
+		canEnforceDiskLimit: canEnforceDiskLimit,
+	}
+}
+
+type resourceLimitsBidStrategy struct {
+	ioDevices           []string
+	requireDiskLimits   bool
+	canEnforceDiskLimit func(context.Context) (bool, error)
+}
+
+// ShouldBid implements bidstrategy.BidStrategy
+func (s *resourceLimitsBidStrategy) ShouldBid(
+	ctx context.Context,
+	request bidstrategy.BidStrategyRequest,
+) (bidstrategy.BidStrategyResponse, error) {
+	if request.Job.Spec.Engine != model.EngineDocker {
+		return bidstrategy.NewShouldBidResponse(), nil
+	}
+
+	resources := request.Job.Spec.Resources
+	if capacity.ConvertIOPSString(resources.IOPS) > 0 && len(s.ioDevices) == 0 {
+		return bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason:    "Node has no devices configured to enforce IOPS limits on",
+		}, nil
+	}
+	if s.requireDiskLimits && capacity.ConvertBytesString(resources.Disk) > 0 {
+		canEnforce, err := s.canEnforceDiskLimit(ctx)
+		if err != nil {
+			return bidstrategy.BidStrategyResponse{}, err
+		}
+		if !canEnforce {
+			return bidstrategy.BidStrategyResponse{
+				ShouldBid: false,
+				Reason:    "Node's docker storage driver can't enforce disk limits",
+			}, nil
+		}
+	}
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+// ShouldBidBasedOnUsage implements bidstrategy.BidStrategy
+func (*resourceLimitsBidStrategy) ShouldBidBasedOnUsage(
+	_ context.Context,
+	_ bidstrategy.BidStrategyRequest,
+	_ model.ResourceUsageData,
+) (bidstrategy.BidStrategyResponse, error) {
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+var _ bidstrategy.BidStrategy = (*resourceLimitsBidStrategy)(nil)
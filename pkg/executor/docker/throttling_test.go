@@ -0,0 +1,81 @@
+//go:build unit || !integration
+
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/docker/docker/api/types/blkiodev"
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/require"
+)
+
+func jobWithResources(resources model.ResourceUsageConfig) model.Job {
+	return model.Job{
+		Spec: model.Spec{
+			Engine:    model.EngineDocker,
+			Resources: resources,
+		},
+	}
+}
+
+func TestApplyDiskLimit(t *testing.T) {
+	hostConfig := &container.HostConfig{}
+	applyDiskLimit(jobWithResources(model.ResourceUsageConfig{}), hostConfig)
+	require.Nil(t, hostConfig.StorageOpt)
+
+	applyDiskLimit(jobWithResources(model.ResourceUsageConfig{Disk: "1Mi"}), hostConfig)
+	require.Equal(t, map[string]string{"size": "1048576"}, hostConfig.StorageOpt)
+
+	require.True(t, isStorageQuotaUnsupported(errors.New("--storage-opt is supported only for overlay over xfs with 'pquota' mount option")))
+	require.False(t, isStorageQuotaUnsupported(errors.New("No such image: ubuntu")))
+}
+
+func TestApplyIOLimits(t *testing.T) {
+	job := jobWithResources(model.ResourceUsageConfig{IOPS: "100"})
+
+	hostConfig := &container.HostConfig{}
+	require.Error(t, (&Executor{}).applyIOLimits(job, hostConfig), "the limit can't be enforced without devices")
+
+	executor := &Executor{options: ExecutorOptions{IODevices: []string{"/dev/sda"}}}
+	require.NoError(t, executor.applyIOLimits(job, hostConfig))
+	expected := []*blkiodev.ThrottleDevice{{Path: "/dev/sda", Rate: 100}}
+	require.Equal(t, expected, hostConfig.BlkioDeviceReadIOps)
+	require.Equal(t, expected, hostConfig.BlkioDeviceWriteIOps)
+}
+
+func TestResourceLimitsBidStrategy(t *testing.T) {
+	quotas := func(supported bool) func(context.Context) (bool, error) {
+		return func(context.Context) (bool, error) { return supported, nil }
+	}
+	tests := []struct {
+		name      string
+		resources model.ResourceUsageConfig
+		devices   []string
+		require   bool
+		quotas    bool
+		shouldBid bool
+	}{
+		{name: "no-limits", shouldBid: true},
+		{name: "iops", resources: model.ResourceUsageConfig{IOPS: "100"}, devices: []string{"/dev/sda"}, shouldBid: true},
+		{name: "iops-without-devices", resources: model.ResourceUsageConfig{IOPS: "100"}, shouldBid: false},
+		{name: "disk", resources: model.ResourceUsageConfig{Disk: "1Gi"}, quotas: true, shouldBid: true},
+		{name: "disk-without-quotas", resources: model.ResourceUsageConfig{Disk: "1Gi"}, shouldBid: true},
+		{name: "required-disk", resources: model.ResourceUsageConfig{Disk: "1Gi"}, require: true, quotas: true, shouldBid: true},
+		{name: "required-disk-without-quotas", resources: model.ResourceUsageConfig{Disk: "1Gi"}, require: true, shouldBid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			strategy := NewResourceLimitsBidStrategy(test.devices, test.require, quotas(test.quotas))
+			response, err := strategy.ShouldBid(context.Background(), bidstrategy.BidStrategyRequest{
+				Job: jobWithResources(test.resources),
+			})
+			require.NoError(t, err)
+			require.Equal(t, test.shouldBid, response.ShouldBid, response.Reason)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package duckdb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"go.uber.org/multierr"
+)
+
+// DefaultBinary is the duckdb command run if no other is configured.
+const DefaultBinary = "duckdb"
+
+// ExecutorOptions configures running SQL queries with DuckDB.
+type ExecutorOptions struct {
+	// Enabled lets the compute node run duckdb jobs. Queries are limited to
+	// the files of their inputs and outputs, but run on the host without any
+	// other isolation, so it should only be enabled where the clients
+	// submitting jobs are trusted.
+	Enabled bool
+	// Binary is the duckdb command line shell to run queries with, which must
+	// be DuckDB 1.2 or later to limit the files queries can access. Bacalhau
+	// is built without cgo, so it can't embed DuckDB as a library.
+	Binary string
+}
+
+// Executor runs the SQL query of a job over its inputs with DuckDB, writing
+// the result set to its output, for querying data where it lives without
+// needing a container image.
+//
+// Like the process executor, each query runs in its own directory, with the
+// inputs linked into it at their paths relative to that directory.
+type Executor struct {
+	// the storage providers we can implement for a job
+	StorageProvider storage.StorageProvider
+
+	options ExecutorOptions
+}
+
+func NewExecutor(
+	_ context.Context,
+	storageProvider storage.StorageProvider,
+	options ExecutorOptions,
+) (*Executor, error) {
+	if options.Binary == "" {
+		options.Binary = DefaultBinary
+	}
+
+	return &Executor{
+		StorageProvider: storageProvider,
+		options:         options,
+	}, nil
+}
+
+// IsInstalled checks if duckdb is on the PATH of the compute node.
+func (e *Executor) IsInstalled(context.Context) (bool, error) {
+	_, err := exec.LookPath(e.options.Binary)
+	return err == nil, nil
+}
+
+// GetBidStrategy implements executor.Executor
+func (e *Executor) GetBidStrategy(context.Context) (bidstrategy.BidStrategy, error) {
+	return bidstrategy.NewChainedBidStrategy(), nil
+}
+
+func (e *Executor) HasStorageLocally(ctx context.Context, volume model.StorageSpec) (bool, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/duckdb.Executor.HasStorageLocally")
+	defer span.End()
+
+	s, err := e.StorageProvider.Get(ctx, volume.StorageSource)
+	if err != nil {
+		return false, err
+	}
+
+	return s.HasStorageLocally(ctx, volume)
+}
+
+func (e *Executor) GetVolumeSize(ctx context.Context, volume model.StorageSpec) (uint64, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/duckdb.Executor.GetVolumeSize")
+	defer span.End()
+
+	storageProvider, err := e.StorageProvider.Get(ctx, volume.StorageSource)
+	if err != nil {
+		return 0, err
+	}
+	return storageProvider.GetVolumeSize(ctx, volume)
+}
+
+//nolint:funlen
+func (e *Executor) Run(
+	ctx context.Context,
+	job model.Job,
+	jobResultsDir string,
+) (*model.RunCommandResult, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/duckdb.Executor.Run")
+	defer span.End()
+
+	if len(job.Spec.Outputs) == 0 {
+		return executor.FailResult(fmt.Errorf("duckdb job has no output to write the result set to"))
+	}
+	if err := validateQuery(job.Spec.DuckDB.Query); err != nil {
+		return executor.FailResult(err)
+	}
+
+	jobDir, err := os.MkdirTemp("", "bacalhau-duckdb-*")
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	defer func() {
+		if removeErr := os.RemoveAll(jobDir); removeErr != nil {
+			log.Ctx(ctx).Debug().Err(removeErr).Str("Dir", jobDir).Msg("failed to remove job directory")
+		}
+	}()
+
+	inputVolumes, err := storage.ParallelPrepareStorage(ctx, e.StorageProvider, job.Spec.Inputs)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	// the query can only access the files of its inputs and outputs
+	var paths []string
+	for input, volume := range inputVolumes {
+		if volume.Type != storage.StorageVolumeConnectorBind {
+			return executor.FailResult(fmt.Errorf("unknown storage volume type: %s", volume.Type))
+		}
+		log.Ctx(ctx).Trace().Msgf("Input Volume: %+v %+v", input, volume)
+		if err = executor.LinkIntoJobDir(jobDir, volume.Source, volume.Target); err != nil {
+			return executor.FailResult(err)
+		}
+		paths = append(paths, jobPath(volume.Target))
+	}
+
+	for _, output := range job.Spec.Outputs {
+		if output.Name == "" {
+			return executor.FailResult(fmt.Errorf("output volume has no name: %+v", output))
+		}
+		if output.Path == "" {
+			return executor.FailResult(fmt.Errorf("output volume has no path: %+v", output))
+		}
+
+		srcd := filepath.Join(jobResultsDir, output.Name)
+		if err = os.Mkdir(srcd, util.OS_ALL_R|util.OS_ALL_X|util.OS_USER_W); err != nil {
+			return executor.FailResult(err)
+		}
+		log.Ctx(ctx).Trace().Msgf("Output Volume: %+v", output)
+		if err = executor.LinkIntoJobDir(jobDir, srcd, output.Path); err != nil {
+			return executor.FailResult(err)
+		}
+		paths = append(paths, jobPath(output.Path))
+	}
+
+	// the result set is written to the first output
+	format, err := resultFormat(job.Spec.DuckDB)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	script := queryScript(
+		job.Spec.DuckDB.Query,
+		format,
+		capacity.ParseResourceUsageConfig(job.Spec.Resources),
+		resultPath(job.Spec.Outputs[0].Path, format),
+		paths,
+	)
+
+	// buffer the output on disk until the query finishes, as only then do we know its exit code
+	stdout, err := os.CreateTemp("", "bacalhau-stdout-*")
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	defer executor.CloseAndRemove(ctx, stdout)
+	stderr, err := os.CreateTemp("", "bacalhau-stderr-*")
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	defer executor.CloseAndRemove(ctx, stderr)
+
+	// the query is passed on stdin rather than as an argument, as it may be too long for the command line.
+	// -bail stops at the first failed statement, so the query isn't run if its file access can't be limited
+	cmd := exec.CommandContext(ctx, e.options.Binary, "-bail", ":memory:") //nolint:gosec // the binary is configured by the operator
+	cmd.Dir = jobDir
+	cmd.Stdin = strings.NewReader(script)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + jobDir,
+	}
+
+	log.Ctx(ctx).Debug().Str("Query", job.Spec.DuckDB.Query).Msg("Running duckdb job")
+	runErr := cmd.Run()
+
+	exitCode := 0
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) && ctx.Err() == nil {
+		// a failed query is reported as the result of the job, rather than as an error
+		exitCode = exitErr.ExitCode()
+		runErr = nil
+	} else if runErr != nil {
+		exitCode = -1
+		if ctx.Err() != nil {
+			runErr = ctx.Err()
+		}
+	}
+
+	_, stdoutErr := stdout.Seek(0, io.SeekStart)
+	_, stderrErr := stderr.Seek(0, io.SeekStart)
+
+	return executor.WriteJobResults(
+		jobResultsDir,
+		stdout,
+		stderr,
+		exitCode,
+		multierr.Combine(runErr, stdoutErr, stderrErr),
+	)
+}
+
+func (e *Executor) GetOutputStream(context.Context, model.Job, bool) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not implemented for duckdb executor")
+}
+
+// Compile-time interface check:
+var _ executor.Executor = (*Executor)(nil)
@@ -0,0 +1,111 @@
+//go:build (unit || !integration) && unix
+
+package duckdb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultFormat(t *testing.T) {
+	format, err := resultFormat(model.JobSpecDuckDB{})
+	require.NoError(t, err)
+	require.Equal(t, "csv", format)
+
+	format, err = resultFormat(model.JobSpecDuckDB{Format: "Parquet"})
+	require.NoError(t, err)
+	require.Equal(t, "parquet", format)
+
+	_, err = resultFormat(model.JobSpecDuckDB{Format: "xlsx"})
+	require.Error(t, err)
+}
+
+func TestQueryScript(t *testing.T) {
+	script := queryScript(
+		"SELECT * FROM 'inputs/data.csv' -- all of it;\n",
+		"parquet",
+		model.ResourceUsageData{CPU: 1.5, Memory: 1024},
+		resultPath("/outputs/it's", "parquet"),
+		[]string{"inputs/data.csv", "outputs/it's"},
+	)
+	require.Equal(t, "SET threads TO 1;\n"+
+		"SET memory_limit = '1024B';\n"+
+		"SET allowed_paths = ['inputs/data.csv', 'outputs/it''s'];\n"+
+		"SET allowed_directories = ['inputs/data.csv/', 'outputs/it''s/'];\n"+
+		"SET enable_external_access = false;\n"+
+		"SET lock_configuration = true;\n"+
+		"COPY (\nSELECT * FROM 'inputs/data.csv' -- all of it\n) TO 'outputs/it''s/result.parquet' (FORMAT PARQUET);\n",
+		script)
+}
+
+func TestRun(t *testing.T) {
+	// a fake duckdb that writes the script it is given to the result file
+	binary := filepath.Join(t.TempDir(), "duckdb")
+	require.NoError(t, os.WriteFile(binary, []byte(`#!/bin/sh
+cat > outputs/result.csv
+echo failed >&2
+exit 2
+`), 0755))
+
+	e, err := NewExecutor(context.Background(), model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{}),
+		ExecutorOptions{Enabled: true, Binary: binary})
+	require.NoError(t, err)
+
+	installed, err := e.IsInstalled(context.Background())
+	require.NoError(t, err)
+	require.True(t, installed)
+
+	job := model.Job{Spec: model.Spec{
+		Engine:  model.EngineDuckDB,
+		DuckDB:  model.JobSpecDuckDB{Query: "SELECT 1"},
+		Outputs: []model.StorageSpec{{Name: "outputs", Path: "/outputs"}},
+	}}
+	resultsDir := t.TempDir()
+	result, err := e.Run(context.Background(), job, resultsDir)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.ExitCode)
+	require.Equal(t, "failed\n", result.STDERR)
+
+	contents, err := os.ReadFile(filepath.Join(resultsDir, "outputs", "result.csv"))
+	require.NoError(t, err)
+	require.Equal(t, "SET allowed_paths = ['outputs'];\n"+
+		"SET allowed_directories = ['outputs/'];\n"+
+		"SET enable_external_access = false;\n"+
+		"SET lock_configuration = true;\n"+
+		"COPY (\nSELECT 1\n) TO 'outputs/result.csv' (FORMAT CSV, HEADER);\n", string(contents))
+}
+
+func TestValidateQuery(t *testing.T) {
+	for _, query := range []string{
+		"SELECT 1",
+		"SELECT 1;",
+		"SELECT 1; ;\n",
+		"SELECT 'a;b', \"c;d\" FROM t",
+		"SELECT 'it''s;' -- a comment; with a semicolon",
+		"SELECT /* a; /* nested; */ comment */ 1",
+		"SELECT $$a;b$$, $tag$c;d$tag$, $1",
+		"SELECT E'\\';'",
+	} {
+		require.NoError(t, validateQuery(query), query)
+	}
+
+	for _, query := range []string{
+		"SELECT 1; SELECT 2",
+		"SELECT 1) TO '/tmp/x' (FORMAT CSV); COPY (SELECT 1",
+		"SELECT 'a'';' ; DROP TABLE t",
+		"SELECT E'\\\\'; SELECT 2",
+		"SELECT 1\n.shell id",
+		"  .read /etc/passwd",
+		"SELECT 'unterminated",
+		"SELECT /* unterminated /* nested */ 1",
+		"SELECT $tag$unterminated$",
+	} {
+		require.Error(t, validateQuery(query), query)
+	}
+}
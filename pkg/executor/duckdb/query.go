@@ -0,0 +1,188 @@
+package duckdb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// the options of COPY that write result sets in each format, by the name jobs
+// use for the format
+var formatOptions = map[string]string{
+	"csv":     "(FORMAT CSV, HEADER)",
+	"json":    "(FORMAT JSON)",
+	"parquet": "(FORMAT PARQUET)",
+}
+
+const defaultFormat = "csv"
+
+// resultFormat returns the name of the format the job writes its result set in.
+func resultFormat(spec model.JobSpecDuckDB) (string, error) {
+	if spec.Format == "" {
+		return defaultFormat, nil
+	}
+	format := strings.ToLower(spec.Format)
+	if _, ok := formatOptions[format]; !ok {
+		return "", fmt.Errorf("unknown duckdb result format %q", spec.Format)
+	}
+	return format, nil
+}
+
+// jobPath returns the path an input or output is linked at, relative to the
+// job directory.
+func jobPath(target string) string {
+	return strings.TrimLeft(path.Clean(filepath.ToSlash(target)), "/")
+}
+
+// resultPath returns where the result set is written, relative to the job
+// directory, given the path of the output it is written to.
+func resultPath(outputPath, format string) string {
+	return path.Join(jobPath(outputPath), "result."+format)
+}
+
+// queryScript returns the statements that run the query within the resource
+// limits of the job, and write its result set to the result file. The query
+// can only access the files at the given paths relative to the job directory,
+// i.e. its inputs and outputs.
+func queryScript(query, format string, resources model.ResourceUsageData, resultFile string, paths []string) string {
+	var script strings.Builder
+	if resources.CPU > 0 {
+		fmt.Fprintf(&script, "SET threads TO %d;\n", int(math.Max(1, math.Floor(resources.CPU))))
+	}
+	if resources.Memory > 0 {
+		fmt.Fprintf(&script, "SET memory_limit = '%dB';\n", resources.Memory)
+	}
+
+	// each path may be a file or a directory, so it is allowed as both
+	allowedPaths := make([]string, 0, len(paths))
+	allowedDirectories := make([]string, 0, len(paths))
+	for _, p := range paths {
+		allowedPaths = append(allowedPaths, quoteString(p))
+		allowedDirectories = append(allowedDirectories, quoteString(p+"/"))
+	}
+	fmt.Fprintf(&script, "SET allowed_paths = [%s];\n", strings.Join(allowedPaths, ", "))
+	fmt.Fprintf(&script, "SET allowed_directories = [%s];\n", strings.Join(allowedDirectories, ", "))
+	script.WriteString("SET enable_external_access = false;\n")
+	script.WriteString("SET lock_configuration = true;\n")
+
+	// the query is wrapped in COPY so that only its result set is written, so
+	// it must be a single statement. It goes on its own lines in case it ends
+	// in a comment.
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	fmt.Fprintf(&script, "COPY (\n%s\n) TO %s %s;\n", query, quoteString(resultFile), formatOptions[format])
+	return script.String()
+}
+
+// quoteString quotes the value as a SQL string literal.
+func quoteString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// validateQuery checks the query is a single statement, so that it can't
+// escape the COPY it is wrapped in, and that no line of it could be taken as
+// a dot command of the duckdb shell.
+func validateQuery(query string) error {
+	for _, line := range strings.Split(query, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), ".") {
+			return errors.New("duckdb queries can't contain lines starting with '.'")
+		}
+	}
+
+	for i := 0; i < len(query); i++ {
+		var end int
+		switch {
+		case query[i] == '\'' || query[i] == '"':
+			end = quotedEnd(query, i)
+		case strings.HasPrefix(query[i:], "--"):
+			end = strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return nil
+			}
+			end += i
+		case strings.HasPrefix(query[i:], "/*"):
+			end = commentEnd(query, i)
+		case query[i] == '$':
+			end = dollarQuotedEnd(query, i)
+		case query[i] == ';':
+			if strings.TrimLeft(query[i:], "; \t\r\n") != "" {
+				return errors.New("duckdb queries must be a single statement")
+			}
+			return nil
+		default:
+			continue
+		}
+		if end < 0 {
+			return errors.New("duckdb query has an unterminated string, identifier or comment")
+		}
+		i = end
+	}
+	return nil
+}
+
+// quotedEnd returns the index of the quote that ends the string or identifier
+// starting at start, or -1 if it isn't terminated. Quotes are escaped by
+// doubling them, or with a backslash in E'...' strings.
+func quotedEnd(query string, start int) int {
+	quote := query[start]
+	backslashEscapes := quote == '\'' && start > 0 &&
+		(query[start-1] == 'e' || query[start-1] == 'E') &&
+		(start < 2 || !isIdentifierByte(query[start-2]))
+	for i := start + 1; i < len(query); i++ {
+		switch {
+		case backslashEscapes && query[i] == '\\':
+			i++
+		case query[i] == quote && i+1 < len(query) && query[i+1] == quote:
+			i++
+		case query[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// commentEnd returns the index of the last byte of the block comment starting
+// at start, which may be nested, or -1 if it isn't terminated.
+func commentEnd(query string, start int) int {
+	depth := 0
+	for i := start; i+1 < len(query); i++ {
+		switch query[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// dollarQuotedEnd returns the index of the last byte of the $tag$ quoted string
+// starting at start, or start if it isn't a dollar quote, e.g. a parameter.
+func dollarQuotedEnd(query string, start int) int {
+	tagEnd := start + 1
+	for tagEnd < len(query) && isIdentifierByte(query[tagEnd]) && (query[tagEnd] < '0' || query[tagEnd] > '9') {
+		tagEnd++
+	}
+	if tagEnd >= len(query) || query[tagEnd] != '$' {
+		return start
+	}
+	tag := query[start : tagEnd+1]
+	end := strings.Index(query[tagEnd+1:], tag)
+	if end < 0 {
+		return -1
+	}
+	return tagEnd + end + len(tag)
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
@@ -0,0 +1,41 @@
+package executor
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+type allocatedGPUsKey struct{}
+
+// ContextWithGPUs returns a context that tells the executor which GPUs or MIG
+// slices of the node the execution was allocated.
+func ContextWithGPUs(ctx context.Context, gpus []model.GPU) context.Context {
+	if len(gpus) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, allocatedGPUsKey{}, gpus)
+}
+
+// AllocatedGPUs returns the GPUs or MIG slices that the execution was
+// allocated, which is none if the node doesn't describe its GPUs, in which
+// case executors fall back to the GPUs they pick themselves.
+func AllocatedGPUs(ctx context.Context) []model.GPU {
+	gpus, _ := ctx.Value(allocatedGPUsKey{}).([]model.GPU)
+	return gpus
+}
+
+// GPUDeviceIDs returns how the container runtimes address the GPUs, which is
+// by their UUIDs if known, or by their indexes otherwise.
+func GPUDeviceIDs(gpus []model.GPU) []string {
+	ids := make([]string, 0, len(gpus))
+	for _, gpu := range gpus {
+		if gpu.UUID != "" {
+			ids = append(ids, gpu.UUID)
+		} else {
+			ids = append(ids, strconv.Itoa(gpu.Index))
+		}
+	}
+	return ids
+}
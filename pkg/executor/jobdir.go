@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/rs/zerolog/log"
+	"go.uber.org/multierr"
+)
+
+// LinkIntoJobDir makes source available in the job directory at the path the
+// job expects it, taken relative to the job directory. It is used by executors
+// that run jobs in a directory on the host rather than with mounts.
+func LinkIntoJobDir(jobDir, source, target string) error {
+	relativeTarget := strings.TrimLeft(filepath.Clean(target), string(filepath.Separator))
+	if !filepath.IsLocal(relativeTarget) {
+		return fmt.Errorf("path %q is outside of the job directory", target)
+	}
+
+	link := filepath.Join(jobDir, relativeTarget)
+	if err := os.MkdirAll(filepath.Dir(link), util.OS_USER_RWX); err != nil {
+		return err
+	}
+	return os.Symlink(source, link)
+}
+
+// CloseAndRemove closes and deletes a temporary file that captured the output
+// of a job, logging rather than failing the job if it can't.
+func CloseAndRemove(ctx context.Context, file *os.File) {
+	err := multierr.Combine(file.Close(), os.Remove(file.Name()))
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Str("File", file.Name()).Msg("failed to remove job output")
+	}
+}
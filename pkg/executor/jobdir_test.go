@@ -0,0 +1,22 @@
+//go:build (unit || !integration) && unix
+
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkIntoJobDir(t *testing.T) {
+	jobDir := t.TempDir()
+	source := t.TempDir()
+	require.NoError(t, LinkIntoJobDir(jobDir, source, "/inputs/data"))
+	target, err := os.Readlink(filepath.Join(jobDir, "inputs", "data"))
+	require.NoError(t, err)
+	require.Equal(t, source, target)
+
+	require.Error(t, LinkIntoJobDir(jobDir, t.TempDir(), "../etc"))
+}
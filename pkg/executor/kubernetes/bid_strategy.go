@@ -0,0 +1,60 @@
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// NewBidStrategy returns a bid strategy that rejects docker jobs asking for
+// features that Kubernetes pods cannot provide.
+func NewBidStrategy() bidstrategy.BidStrategy {
+	return &featuresBidStrategy{}
+}
+
+type featuresBidStrategy struct{}
+
+// ShouldBid implements bidstrategy.BidStrategy
+func (s *featuresBidStrategy) ShouldBid(
+	_ context.Context,
+	request bidstrategy.BidStrategyRequest,
+) (bidstrategy.BidStrategyResponse, error) {
+	if request.Job.Spec.Engine != model.EngineDocker {
+		return bidstrategy.NewShouldBidResponse(), nil
+	}
+
+	if request.Job.Spec.Network.Type == model.NetworkHTTP {
+		return bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason:    "Node runs jobs on Kubernetes, which does not support HTTP networking",
+		}, nil
+	}
+
+	if request.Job.Spec.Docker.OverridesSecurityProfiles() {
+		return bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason:    "Node runs jobs on Kubernetes, which does not support overriding security profiles",
+		}, nil
+	}
+
+	if request.Job.Spec.Resources.IOPS != "" {
+		return bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason:    "Node runs jobs on Kubernetes, which does not support IOPS limits",
+		}, nil
+	}
+
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+// ShouldBidBasedOnUsage implements bidstrategy.BidStrategy
+func (*featuresBidStrategy) ShouldBidBasedOnUsage(
+	_ context.Context,
+	_ bidstrategy.BidStrategyRequest,
+	_ model.ResourceUsageData,
+) (bidstrategy.BidStrategyResponse, error) {
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+var _ bidstrategy.BidStrategy = (*featuresBidStrategy)(nil)
@@ -0,0 +1,193 @@
+package kubernetes
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// copyInputs streams the inputs into the pod as a tar archive, then lets the
+// job start.
+func (e *Executor) copyInputs(ctx context.Context, podName string, inputs []podInput) error {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeInputsTar(writer, inputs))
+	}()
+	defer reader.Close()
+
+	script := fmt.Sprintf("tar xf - -C %s && touch %s", inputsDir, path.Join(inputsDir, inputsReadyFile))
+	return e.exec(ctx, podName, inputsContainerName, []string{"sh", "-c", script}, reader, io.Discard)
+}
+
+// copyOutput copies the contents of the output out of the pod into dst.
+func (e *Executor) copyOutput(ctx context.Context, podName string, output model.StorageSpec, dst string) error {
+	reader, writer := io.Pipe()
+	extracted := make(chan error, 1)
+	go func() {
+		err := extractTar(reader, dst)
+		// drain anything left so the remote tar is never blocked on writing
+		_, _ = io.Copy(io.Discard, reader)
+		extracted <- err
+	}()
+
+	command := []string{"tar", "cf", "-", "-C", path.Join(outputsDir, output.Name), "."}
+	execErr := e.exec(ctx, podName, outputsContainerName, command, nil, writer)
+	writer.CloseWithError(execErr)
+	if err := <-extracted; err != nil {
+		return err
+	}
+	return execErr
+}
+
+// exec runs the command in a container of the pod, like kubectl exec.
+func (e *Executor) exec(
+	ctx context.Context,
+	podName, containerName string,
+	command []string,
+	stdin io.Reader,
+	stdout io.Writer,
+) error {
+	req := e.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(e.options.Namespace).
+		Name(podName).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: containerName,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(e.restConfig, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to run %q in container %s: %s", command, containerName, stderr.String())
+	}
+	return nil
+}
+
+// writeInputsTar writes each input into the archive under its index, which is
+// the sub path the job container mounts it from.
+func writeInputsTar(w io.Writer, inputs []podInput) error {
+	tw := tar.NewWriter(w)
+	for i, input := range inputs {
+		if err := addToTar(tw, input.source, strconv.Itoa(i)); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// addToTar adds the file or directory at src to the archive, named from name.
+func addToTar(tw *tar.Writer, src, name string) error {
+	return filepath.WalkDir(src, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		relative, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(name, filepath.ToSlash(relative))
+		if err = tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		data, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer closer.CloseWithLogOnError(file, data)
+		_, err = io.Copy(tw, data)
+		return err
+	})
+}
+
+// extractTar extracts the regular files and directories in the archive into
+// dst, rejecting any entries that would be written outside of it.
+func extractTar(r io.Reader, dst string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.FromSlash(path.Clean(header.Name))
+		if name == "." {
+			continue
+		}
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("archive contains path outside of output: %q", header.Name)
+		}
+		target := filepath.Join(dst, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, util.OS_ALL_R|util.OS_ALL_X|util.OS_USER_W); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err = extractFile(tr, target); err != nil {
+				return err
+			}
+		default:
+			// links and devices could point outside of the output, so are not copied
+			continue
+		}
+	}
+}
+
+func extractFile(r io.Reader, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), util.OS_ALL_R|util.OS_ALL_X|util.OS_USER_W); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, util.OS_ALL_R|util.OS_USER_W)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError(target, file)
+	_, err = io.Copy(file, r)
+	return err
+}
@@ -0,0 +1,348 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/docker"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	pkgUtil "github.com/bacalhau-project/bacalhau/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// DefaultNamespace is the namespace job pods are created in if none is configured.
+	DefaultNamespace = "bacalhau"
+	// DefaultHelperImage is used to copy the inputs and outputs of jobs in and
+	// out of their pods if no other image is configured.
+	DefaultHelperImage = "busybox:1.36"
+
+	// how often the status of a job's pod is checked while waiting on it
+	pollInterval = time.Second
+)
+
+// ExecutorOptions configures the Kubernetes cluster that jobs are delegated to.
+type ExecutorOptions struct {
+	// Enabled runs docker jobs as pods in the cluster rather than on the
+	// compute node.
+	Enabled bool
+	// Kubeconfig is the path of the kubeconfig used to connect to the
+	// cluster. The in-cluster configuration is used if it is empty.
+	Kubeconfig string
+	// Namespace is the namespace that job pods are created in.
+	Namespace string
+	// HelperImage is used to copy the inputs and outputs of jobs in and out of
+	// their pods. It must contain sh and tar.
+	HelperImage string
+}
+
+// Executor runs docker jobs as pods in a Kubernetes cluster, letting operators
+// that already run Kubernetes expose its spare capacity to the network without
+// running docker on the compute node.
+//
+// The inputs of a job are prepared on the compute node and copied into the pod
+// before the job starts, and its outputs are copied out again once it exits.
+// Kubernetes doesn't keep the stdout and stderr of containers apart, so all of
+// the output of a job is reported as its stdout.
+type Executor struct {
+	// used to allow multiple executors to run against the same cluster
+	ID string
+
+	// the storage providers we can implement for a job
+	StorageProvider storage.StorageProvider
+
+	options    ExecutorOptions
+	restConfig *rest.Config
+	client     k8s.Interface
+}
+
+func NewExecutor(
+	ctx context.Context,
+	cm *system.CleanupManager,
+	id string,
+	storageProvider storage.StorageProvider,
+	options ExecutorOptions,
+) (*Executor, error) {
+	if options.Namespace == "" {
+		options.Namespace = DefaultNamespace
+	}
+	if options.HelperImage == "" {
+		options.HelperImage = DefaultHelperImage
+	}
+
+	restConfig, err := clientcmd.BuildConfigFromFlags("", options.Kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load kubernetes configuration")
+	}
+	client, err := k8s.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	e := &Executor{
+		ID:              id,
+		StorageProvider: storageProvider,
+		options:         options,
+		restConfig:      restConfig,
+		client:          client,
+	}
+
+	if err = e.ensureNetworkPolicy(ctx); err != nil {
+		return nil, err
+	}
+
+	cm.RegisterCallbackWithContext(e.cleanupAll)
+
+	return e, nil
+}
+
+// IsInstalled checks if the cluster is reachable.
+func (e *Executor) IsInstalled(context.Context) (bool, error) {
+	_, err := e.client.Discovery().ServerVersion()
+	return err == nil, nil
+}
+
+// GetBidStrategy implements executor.Executor
+func (e *Executor) GetBidStrategy(context.Context) (bidstrategy.BidStrategy, error) {
+	return NewBidStrategy(), nil
+}
+
+func (e *Executor) HasStorageLocally(ctx context.Context, volume model.StorageSpec) (bool, error) {
+	//nolint:ineffassign,staticcheck
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/kubernetes.Executor.HasStorageLocally")
+	defer span.End()
+
+	s, err := e.StorageProvider.Get(ctx, volume.StorageSource)
+	if err != nil {
+		return false, err
+	}
+
+	return s.HasStorageLocally(ctx, volume)
+}
+
+func (e *Executor) GetVolumeSize(ctx context.Context, volume model.StorageSpec) (uint64, error) {
+	storageProvider, err := e.StorageProvider.Get(ctx, volume.StorageSource)
+	if err != nil {
+		return 0, err
+	}
+	return storageProvider.GetVolumeSize(ctx, volume)
+}
+
+//nolint:funlen,gocyclo
+func (e *Executor) Run(
+	ctx context.Context,
+	job model.Job,
+	jobResultsDir string,
+) (*model.RunCommandResult, error) {
+	//nolint:ineffassign,staticcheck
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/kubernetes.Executor.Run")
+	defer span.End()
+
+	inputVolumes, err := storage.ParallelPrepareStorage(ctx, e.StorageProvider, job.Spec.Inputs)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	var inputs []podInput
+	for spec, volume := range inputVolumes {
+		if volume.Type != storage.StorageVolumeConnectorBind {
+			return executor.FailResult(fmt.Errorf("unknown storage volume type: %s", volume.Type))
+		}
+		log.Ctx(ctx).Trace().Msgf("Input Volume: %+v %+v", spec, volume)
+		inputs = append(inputs, podInput{source: volume.Source, target: volume.Target})
+	}
+
+	for _, output := range job.Spec.Outputs {
+		if output.Name == "" {
+			return executor.FailResult(fmt.Errorf("output volume has no name: %+v", output))
+		}
+		if output.Path == "" {
+			return executor.FailResult(fmt.Errorf("output volume has no path: %+v", output))
+		}
+	}
+
+	jsonJobSpec, err := model.JSONMarshalWithMax(job.Spec)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	pod, err := e.client.CoreV1().Pods(e.options.Namespace).Create(
+		ctx,
+		e.podForJob(job, inputs, string(jsonJobSpec)),
+		metav1.CreateOptions{},
+	)
+	if err != nil {
+		return executor.FailResult(errors.Wrap(err, "failed to create pod"))
+	}
+	defer e.deletePod(ctx, pod.Name)
+
+	ctx = log.Ctx(ctx).With().Str("Pod", pod.Name).Logger().WithContext(ctx)
+
+	if len(inputs) > 0 {
+		if _, err = e.waitForContainer(ctx, pod.Name, inputsContainerName, isRunning); err != nil {
+			return executor.FailResult(errors.Wrap(err, "failed to wait for pod to accept inputs"))
+		}
+		if err = e.copyInputs(ctx, pod.Name, inputs); err != nil {
+			return executor.FailResult(errors.Wrap(err, "failed to copy inputs into pod"))
+		}
+	}
+
+	status, err := e.waitForContainer(ctx, pod.Name, jobContainerName, isTerminated)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	// the idea here is even if the job errors
+	// we want to capture its output and feed it back to the user
+	var copyErr error
+	for _, output := range job.Spec.Outputs {
+		srcd := filepath.Join(jobResultsDir, output.Name)
+		if copyErr = os.Mkdir(srcd, util.OS_ALL_R|util.OS_ALL_X|util.OS_USER_W); copyErr != nil {
+			break
+		}
+		if copyErr = e.copyOutput(ctx, pod.Name, output, srcd); copyErr != nil {
+			copyErr = errors.Wrapf(copyErr, "failed to copy output %s from pod", output.Name)
+			break
+		}
+	}
+
+	logs, logsErr := e.client.CoreV1().Pods(e.options.Namespace).
+		GetLogs(pod.Name, &corev1.PodLogOptions{Container: jobContainerName}).
+		Stream(ctx)
+	if logsErr != nil {
+		logs = io.NopCloser(bytes.NewReader(nil))
+	}
+	defer logs.Close()
+
+	result, err := executor.WriteJobResults(
+		jobResultsDir,
+		logs,
+		bytes.NewReader(nil),
+		int(status.State.Terminated.ExitCode),
+		copyErr,
+	)
+	result.ImageDigest = imageDigest(status.ImageID)
+	return result, err
+}
+
+func (e *Executor) GetOutputStream(context.Context, model.Job, bool) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not implemented for kubernetes executor")
+}
+
+type containerCondition func(corev1.ContainerStatus) bool
+
+func isRunning(status corev1.ContainerStatus) bool {
+	return status.State.Running != nil
+}
+
+func isTerminated(status corev1.ContainerStatus) bool {
+	return status.State.Terminated != nil
+}
+
+// waitForContainer polls the pod until the named container meets the
+// condition, failing early if the pod can no longer get there.
+func (e *Executor) waitForContainer(
+	ctx context.Context,
+	podName, containerName string,
+	condition containerCondition,
+) (corev1.ContainerStatus, error) {
+	var status corev1.ContainerStatus
+	err := wait.PollImmediateUntilWithContext(ctx, pollInterval, func(ctx context.Context) (bool, error) {
+		pod, err := e.client.CoreV1().Pods(e.options.Namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		var statuses []corev1.ContainerStatus
+		statuses = append(statuses, pod.Status.InitContainerStatuses...)
+		statuses = append(statuses, pod.Status.ContainerStatuses...)
+		for _, containerStatus := range statuses {
+			if containerStatus.Name != containerName {
+				continue
+			}
+			if condition(containerStatus) {
+				status = containerStatus
+				return true, nil
+			}
+			if waiting := containerStatus.State.Waiting; waiting != nil && isImagePullFailure(waiting.Reason) {
+				return false, fmt.Errorf(docker.ImagePullError, containerStatus.Image)
+			}
+		}
+
+		if pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
+			return false, fmt.Errorf("pod finished before container %s was %s: %s",
+				containerName, pod.Status.Phase, pod.Status.Message)
+		}
+		return false, nil
+	})
+	return status, err
+}
+
+func isImagePullFailure(reason string) bool {
+	switch reason {
+	case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+		return true
+	default:
+		return false
+	}
+}
+
+// imageDigest extracts the digest from the image ID reported for a container,
+// e.g. docker.io/library/ubuntu@sha256:... or docker-pullable://ubuntu@sha256:...
+func imageDigest(imageID string) string {
+	if _, digest, found := strings.Cut(imageID, "@"); found {
+		return digest
+	}
+	return strings.TrimPrefix(imageID, "docker://")
+}
+
+func (e *Executor) deletePod(ctx context.Context, podName string) {
+	if config.ShouldKeepStack() {
+		return
+	}
+	// Can't use the original context as it may have already been timed out
+	detachedContext, cancel := context.WithTimeout(pkgUtil.NewDetachedContext(ctx), 10*time.Second)
+	defer cancel()
+	err := e.client.CoreV1().Pods(e.options.Namespace).Delete(detachedContext, podName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to delete pod")
+	}
+}
+
+func (e *Executor) cleanupAll(ctx context.Context) error {
+	// We have to use a detached context, rather than the one passed in to `NewExecutor`, as it may have already been
+	// canceled and so would prevent us from performing any cleanup work.
+	safeCtx := pkgUtil.NewDetachedContext(ctx)
+	if config.ShouldKeepStack() {
+		return nil
+	}
+
+	return e.client.CoreV1().Pods(e.options.Namespace).DeleteCollection(
+		safeCtx,
+		metav1.DeleteOptions{},
+		metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", labelExecutorName, e.ID)},
+	)
+}
+
+// Compile-time interface check:
+var _ executor.Executor = (*Executor)(nil)
@@ -0,0 +1,127 @@
+//go:build unit || !integration
+
+package kubernetes
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func testExecutor() *Executor {
+	return &Executor{
+		ID:      "executor",
+		options: ExecutorOptions{Namespace: DefaultNamespace, HelperImage: DefaultHelperImage},
+	}
+}
+
+func TestPodForJob(t *testing.T) {
+	job := model.Job{
+		Metadata: model.Metadata{ID: "job-id"},
+		Spec: model.Spec{
+			Engine: model.EngineDocker,
+			Docker: model.JobSpecDocker{
+				Image:                "ubuntu",
+				Entrypoint:           []string{"echo", "hello"},
+				EnvironmentVariables: []string{"GREETING=hello=world"},
+				WorkingDirectory:     "/work",
+			},
+			Resources: model.ResourceUsageConfig{CPU: "500m", Memory: "1Gi", GPU: "1"},
+			Network:   model.NetworkConfig{Type: model.NetworkNone},
+			Outputs:   []model.StorageSpec{{Name: "outputs", Path: "/outputs"}},
+		},
+	}
+	inputs := []podInput{{source: "/tmp/input", target: "/inputs"}}
+
+	pod := testExecutor().podForJob(job, inputs, "{}")
+	require.Equal(t, DefaultNamespace, pod.Namespace)
+	require.Equal(t, map[string]string{
+		labelExecutorName: "executor",
+		labelJobName:      "job-id",
+		labelNetworkName:  networkNoneLabel,
+	}, pod.Labels)
+	require.Equal(t, corev1.RestartPolicyNever, pod.Spec.RestartPolicy)
+
+	require.Len(t, pod.Spec.InitContainers, 1)
+	require.Equal(t, inputsContainerName, pod.Spec.InitContainers[0].Name)
+
+	require.Len(t, pod.Spec.Containers, 2)
+	jobContainer := pod.Spec.Containers[0]
+	require.Equal(t, jobContainerName, jobContainer.Name)
+	require.Equal(t, "ubuntu", jobContainer.Image)
+	require.Equal(t, []string{"echo", "hello"}, jobContainer.Command)
+	require.Equal(t, "/work", jobContainer.WorkingDir)
+	require.Equal(t, []corev1.EnvVar{
+		{Name: "GREETING", Value: "hello=world"},
+		{Name: "BACALHAU_JOB_SPEC", Value: "{}"},
+	}, jobContainer.Env)
+	require.Equal(t, []corev1.VolumeMount{
+		{Name: inputsVolumeName, MountPath: "/inputs", SubPath: "0", ReadOnly: true},
+		{Name: "bacalhau-output-0", MountPath: "/outputs"},
+	}, jobContainer.VolumeMounts)
+
+	limits := jobContainer.Resources.Limits
+	require.True(t, resource.MustParse("500m").Equal(limits[corev1.ResourceCPU]))
+	require.True(t, resource.MustParse("1Gi").Equal(limits[corev1.ResourceMemory]))
+	require.True(t, resource.MustParse("1").Equal(limits[gpuResourceName]))
+
+	outputsContainer := pod.Spec.Containers[1]
+	require.Equal(t, outputsContainerName, outputsContainer.Name)
+	require.Equal(t, []corev1.VolumeMount{
+		{Name: "bacalhau-output-0", MountPath: "/bacalhau/outputs/outputs"},
+	}, outputsContainer.VolumeMounts)
+}
+
+func TestPodForJobWithoutInputsOrOutputs(t *testing.T) {
+	job := model.Job{Spec: model.Spec{
+		Engine:  model.EngineDocker,
+		Docker:  model.JobSpecDocker{Image: "ubuntu"},
+		Network: model.NetworkConfig{Type: model.NetworkFull},
+	}}
+
+	pod := testExecutor().podForJob(job, nil, "{}")
+	require.Empty(t, pod.Spec.InitContainers)
+	require.Len(t, pod.Spec.Containers, 1)
+	require.Empty(t, pod.Spec.Volumes)
+	require.NotContains(t, pod.Labels, labelNetworkName)
+}
+
+func TestInputsTarRoundTrip(t *testing.T) {
+	inputDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(inputDir, "nested"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(inputDir, "nested", "file.txt"), []byte("hello"), 0644))
+	inputFile := filepath.Join(t.TempDir(), "single.txt")
+	require.NoError(t, os.WriteFile(inputFile, []byte("world"), 0644))
+
+	var archive bytes.Buffer
+	require.NoError(t, writeInputsTar(&archive, []podInput{{source: inputDir}, {source: inputFile}}))
+
+	dst := t.TempDir()
+	require.NoError(t, extractTar(&archive, dst))
+
+	contents, err := os.ReadFile(filepath.Join(dst, "0", "nested", "file.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(contents))
+
+	contents, err = os.ReadFile(filepath.Join(dst, "1"))
+	require.NoError(t, err)
+	require.Equal(t, "world", string(contents))
+}
+
+func TestExtractTarRejectsPathsOutsideDestination(t *testing.T) {
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../escape.txt", Typeflag: tar.TypeReg, Size: 1, Mode: 0644}))
+	_, err := tw.Write([]byte("x"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	require.Error(t, extractTar(&archive, t.TempDir()))
+}
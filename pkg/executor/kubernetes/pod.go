@@ -0,0 +1,189 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	labelExecutorName = "bacalhau-executor"
+	labelJobName      = "bacalhau-jobID"
+	// pods with this label have all of their egress traffic denied
+	labelNetworkName     = "bacalhau-network"
+	networkNoneLabel     = "none"
+	networkPolicyName    = "bacalhau-deny-egress"
+	gpuResourceName      = "nvidia.com/gpu"
+	jobContainerName     = "job"
+	inputsContainerName  = "inputs"
+	outputsContainerName = "outputs"
+	inputsVolumeName     = "bacalhau-inputs"
+	inputsDir            = "/bacalhau/inputs"
+	outputsDir           = "/bacalhau/outputs"
+	// created once all inputs are copied into the pod, to let the job start
+	inputsReadyFile = ".ready"
+)
+
+// podInput is an input of a job that was prepared on the compute node.
+type podInput struct {
+	// the path of the input on the compute node
+	source string
+	// the path the job expects the input at
+	target string
+}
+
+// podForJob describes the pod that runs a job. An init container holds the
+// pod back until the inputs are copied in, and a sidecar container keeps the
+// outputs around after the job exits until they are copied out.
+func (e *Executor) podForJob(job model.Job, inputs []podInput, jsonJobSpec string) *corev1.Pod {
+	labels := map[string]string{
+		labelExecutorName: e.ID,
+		labelJobName:      job.ID(),
+	}
+	if job.Spec.Network.Type == model.NetworkNone {
+		labels[labelNetworkName] = networkNoneLabel
+	}
+
+	jobContainer := corev1.Container{
+		Name:       jobContainerName,
+		Image:      job.Spec.Docker.Image,
+		Command:    job.Spec.Docker.Entrypoint,
+		WorkingDir: job.Spec.Docker.WorkingDirectory,
+		Env:        jobEnv(job.Spec.Docker.EnvironmentVariables, jsonJobSpec),
+		Resources:  jobResources(job.Spec.Resources),
+	}
+
+	var volumes []corev1.Volume
+	var initContainers []corev1.Container
+	containers := []corev1.Container{}
+
+	if len(inputs) > 0 {
+		volumes = append(volumes, emptyDirVolume(inputsVolumeName))
+		for i, input := range inputs {
+			jobContainer.VolumeMounts = append(jobContainer.VolumeMounts, corev1.VolumeMount{
+				Name:      inputsVolumeName,
+				MountPath: input.target,
+				SubPath:   strconv.Itoa(i),
+				ReadOnly:  true,
+			})
+		}
+		initContainers = append(initContainers, corev1.Container{
+			Name:  inputsContainerName,
+			Image: e.options.HelperImage,
+			Command: []string{"sh", "-c",
+				fmt.Sprintf("until [ -f %s ]; do sleep 1; done", path.Join(inputsDir, inputsReadyFile)),
+			},
+			VolumeMounts: []corev1.VolumeMount{{Name: inputsVolumeName, MountPath: inputsDir}},
+		})
+	}
+
+	if len(job.Spec.Outputs) > 0 {
+		outputsContainer := corev1.Container{
+			Name:    outputsContainerName,
+			Image:   e.options.HelperImage,
+			Command: []string{"sh", "-c", "trap 'exit 0' TERM; while true; do sleep 1; done"},
+		}
+		for i, output := range job.Spec.Outputs {
+			volumeName := fmt.Sprintf("bacalhau-output-%d", i)
+			volumes = append(volumes, emptyDirVolume(volumeName))
+			jobContainer.VolumeMounts = append(jobContainer.VolumeMounts, corev1.VolumeMount{
+				Name:      volumeName,
+				MountPath: output.Path,
+			})
+			outputsContainer.VolumeMounts = append(outputsContainer.VolumeMounts, corev1.VolumeMount{
+				Name:      volumeName,
+				MountPath: path.Join(outputsDir, output.Name),
+			})
+		}
+		containers = append(containers, outputsContainer)
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "bacalhau-",
+			Namespace:    e.options.Namespace,
+			Labels:       labels,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:  corev1.RestartPolicyNever,
+			InitContainers: initContainers,
+			Containers:     append([]corev1.Container{jobContainer}, containers...),
+			Volumes:        volumes,
+			// jobs don't need to talk to the cluster
+			AutomountServiceAccountToken: new(bool),
+		},
+	}
+}
+
+func emptyDirVolume(name string) corev1.Volume {
+	return corev1.Volume{
+		Name:         name,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}
+}
+
+func jobEnv(variables []string, jsonJobSpec string) []corev1.EnvVar {
+	var env []corev1.EnvVar
+	for _, variable := range variables {
+		name, value, _ := strings.Cut(variable, "=")
+		env = append(env, corev1.EnvVar{Name: name, Value: value})
+	}
+	return append(env, corev1.EnvVar{Name: "BACALHAU_JOB_SPEC", Value: jsonJobSpec})
+}
+
+func jobResources(config model.ResourceUsageConfig) corev1.ResourceRequirements {
+	requirements := capacity.ParseResourceUsageConfig(config)
+	limits := corev1.ResourceList{}
+	if requirements.CPU > 0 {
+		limits[corev1.ResourceCPU] = *resource.NewMilliQuantity(int64(requirements.CPU*1000), resource.DecimalSI) //nolint:gomnd
+	}
+	if requirements.Memory > 0 {
+		limits[corev1.ResourceMemory] = *resource.NewQuantity(int64(requirements.Memory), resource.BinarySI)
+	}
+	if requirements.Disk > 0 {
+		limits[corev1.ResourceEphemeralStorage] = *resource.NewQuantity(int64(requirements.Disk), resource.BinarySI)
+	}
+	if requirements.GPU > 0 {
+		limits[gpuResourceName] = *resource.NewQuantity(int64(requirements.GPU), resource.DecimalSI)
+	}
+	if len(limits) == 0 {
+		return corev1.ResourceRequirements{}
+	}
+	// requesting the same as the limits reserves the resources for the job
+	return corev1.ResourceRequirements{Limits: limits, Requests: limits}
+}
+
+// ensureNetworkPolicy creates the policy that denies egress to jobs that don't
+// need networking. It is only enforced if the network plugin of the cluster
+// supports network policies.
+func (e *Executor) ensureNetworkPolicy(ctx context.Context) error {
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      networkPolicyName,
+			Namespace: e.options.Namespace,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{labelNetworkName: networkNoneLabel},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+		},
+	}
+
+	_, err := e.client.NetworkingV1().NetworkPolicies(e.options.Namespace).Create(ctx, policy, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrap(err, "failed to create network policy for jobs without networking")
+	}
+	return nil
+}
@@ -91,7 +91,7 @@ func (e *NoopExecutor) Run(
 	return &model.RunCommandResult{}, nil
 }
 
-func (e *NoopExecutor) GetOutputStream(ctx context.Context, job model.Job) (io.ReadCloser, error) {
+func (e *NoopExecutor) GetOutputStream(ctx context.Context, job model.Job, follow bool) (io.ReadCloser, error) {
 	return nil, fmt.Errorf("not implemented for NoopExecutor")
 }
 
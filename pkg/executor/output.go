@@ -0,0 +1,164 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/bacalhau-project/bacalhau/pkg/util/logstream"
+)
+
+// maxStreamedOutput is how much of the output of a running job is kept for
+// streams that start after it was written. Older output is dropped first.
+const maxStreamedOutput = 8 * 1024 * 1024
+
+// OutputStreams keeps the output of the jobs an executor is running, for
+// executors that capture the stdout and stderr of jobs themselves, so that
+// GetOutputStream can stream it while the jobs run.
+type OutputStreams struct {
+	mu   sync.Mutex
+	jobs map[string]*outputStream
+}
+
+func NewOutputStreams() *OutputStreams {
+	return &OutputStreams{jobs: make(map[string]*outputStream)}
+}
+
+// Start keeps the output of the job until the returned function is called once
+// the job is done, which also ends the streams that follow the output.
+func (s *OutputStreams) Start(jobID string) (stdout, stderr io.Writer, done func()) {
+	stream := &outputStream{updated: make(chan struct{})}
+	s.mu.Lock()
+	s.jobs[jobID] = stream
+	s.mu.Unlock()
+
+	done = func() {
+		stream.close()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.jobs[jobID] == stream {
+			delete(s.jobs, jobID)
+		}
+	}
+	return outputWriter{stream, logstream.StdoutStreamTag}, outputWriter{stream, logstream.StderrStreamTag}, done
+}
+
+// Stream returns the output of the running job as logstream data frames. If
+// follow is true the stream stays open until the job is done.
+func (s *OutputStreams) Stream(ctx context.Context, jobID string, follow bool) (io.ReadCloser, error) {
+	s.mu.Lock()
+	stream, ok := s.jobs[jobID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("job %s is not running", jobID)
+	}
+	return &outputReader{ctx: ctx, stream: stream, follow: follow, closed: make(chan struct{})}, nil
+}
+
+// outputStream is the output of a job as data frames, numbered from the first
+// one it wrote so that readers keep their place as older frames are dropped.
+type outputStream struct {
+	mu      sync.Mutex
+	frames  [][]byte
+	first   int
+	size    int
+	done    bool
+	updated chan struct{}
+}
+
+func (o *outputStream) append(frame []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.frames = append(o.frames, frame)
+	o.size += len(frame)
+	for o.size > maxStreamedOutput && len(o.frames) > 1 {
+		o.size -= len(o.frames[0])
+		o.frames[0] = nil
+		o.frames = o.frames[1:]
+		o.first++
+	}
+	o.notify()
+}
+
+func (o *outputStream) close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.done = true
+	o.notify()
+}
+
+// notify wakes the readers waiting for output. It must be called with the lock held.
+func (o *outputStream) notify() {
+	close(o.updated)
+	o.updated = make(chan struct{})
+}
+
+// next returns the frame at index, or the first one still kept if it was
+// dropped, along with its index. If there is no such frame yet it returns a
+// channel that is closed when there might be, or nil if the job is done.
+func (o *outputStream) next(index int) (frame []byte, frameIndex int, wait <-chan struct{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if index < o.first {
+		index = o.first
+	}
+	if index < o.first+len(o.frames) {
+		return o.frames[index-o.first], index, nil
+	}
+	if o.done {
+		return nil, index, nil
+	}
+	return nil, index, o.updated
+}
+
+type outputWriter struct {
+	stream *outputStream
+	tag    logstream.StreamTag
+}
+
+func (w outputWriter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		w.stream.append(logstream.NewDataFrameFromData(w.tag, p).ToBytes())
+	}
+	return len(p), nil
+}
+
+type outputReader struct {
+	ctx     context.Context
+	stream  *outputStream
+	follow  bool
+	index   int
+	pending []byte
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func (r *outputReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		frame, index, wait := r.stream.next(r.index)
+		if frame != nil {
+			r.pending = frame
+			r.index = index + 1
+			break
+		}
+		if wait == nil || !r.follow {
+			return 0, io.EOF
+		}
+		select {
+		case <-wait:
+		case <-r.closed:
+			return 0, io.EOF
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *outputReader) Close() error {
+	r.once.Do(func() { close(r.closed) })
+	return nil
+}
@@ -0,0 +1,69 @@
+//go:build unit || !integration
+
+package executor
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/util/logstream"
+	"github.com/stretchr/testify/require"
+)
+
+func readFrame(t *testing.T, reader io.Reader) logstream.DataFrame {
+	frame, err := logstream.NewDataFrameFromReader(reader)
+	require.NoError(t, err)
+	return frame
+}
+
+func TestOutputStreamsFollowUntilDone(t *testing.T) {
+	streams := NewOutputStreams()
+	stdout, stderr, done := streams.Start("job")
+	_, _ = stdout.Write([]byte("before"))
+
+	reader, err := streams.Stream(context.Background(), "job", true)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	frame := readFrame(t, reader)
+	require.Equal(t, logstream.StdoutStreamTag, frame.Tag)
+	require.Equal(t, "before", string(frame.Data))
+
+	_, _ = stderr.Write([]byte("after"))
+	frame = readFrame(t, reader)
+	require.Equal(t, logstream.StderrStreamTag, frame.Tag)
+	require.Equal(t, "after", string(frame.Data))
+
+	done()
+	_, err = logstream.NewDataFrameFromReader(reader)
+	require.ErrorIs(t, err, io.EOF)
+
+	_, err = streams.Stream(context.Background(), "job", false)
+	require.Error(t, err, "the output of jobs that are done isn't kept")
+}
+
+func TestOutputStreamsWithoutFollow(t *testing.T) {
+	streams := NewOutputStreams()
+	stdout, _, done := streams.Start("job")
+	defer done()
+	_, _ = stdout.Write([]byte("hello"))
+
+	reader, err := streams.Stream(context.Background(), "job", false)
+	require.NoError(t, err)
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, logstream.NewDataFrameFromData(logstream.StdoutStreamTag, []byte("hello")).ToBytes(), output)
+}
+
+func TestOutputStreamsDropOldestOutput(t *testing.T) {
+	streams := NewOutputStreams()
+	stdout, _, done := streams.Start("job")
+	defer done()
+	_, _ = stdout.Write([]byte("first"))
+	_, _ = stdout.Write(make([]byte, maxStreamedOutput))
+
+	reader, err := streams.Stream(context.Background(), "job", false)
+	require.NoError(t, err)
+	require.Len(t, readFrame(t, reader).Data, maxStreamedOutput)
+}
@@ -0,0 +1,281 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/hashicorp/go-hclog"
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/rs/zerolog/log"
+)
+
+// ExecutorOptions configures where executor plugins are discovered from.
+type ExecutorOptions struct {
+	// Directory holds the executor plugins. Each executable in it is a plugin,
+	// named after its file name without any extension.
+	Directory string
+}
+
+// Executor runs plugin jobs with the plugin they name. Plugins are started the
+// first time a job needs them, and restarted if they have exited since.
+type Executor struct {
+	// the storage providers we can implement for a job
+	StorageProvider storage.StorageProvider
+
+	// the paths of the plugins in the plugins directory, by name
+	paths map[string]string
+
+	mu      sync.Mutex
+	clients map[string]*goplugin.Client
+}
+
+func NewExecutor(
+	_ context.Context,
+	cm *system.CleanupManager,
+	storageProvider storage.StorageProvider,
+	options ExecutorOptions,
+) (*Executor, error) {
+	paths, err := discover(options.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Executor{
+		StorageProvider: storageProvider,
+		paths:           paths,
+		clients:         make(map[string]*goplugin.Client),
+	}
+	cm.RegisterCallback(func() error {
+		e.stop()
+		return nil
+	})
+	return e, nil
+}
+
+// discover returns the executables in the directory, by name.
+func discover(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read executor plugins directory: %w", err)
+	}
+
+	paths := make(map[string]string)
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		// stat rather than use the entry, so that plugins can be symlinked in
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !isExecutable(info) {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		paths[name] = path
+	}
+	return paths, nil
+}
+
+func isExecutable(info os.FileInfo) bool {
+	if !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(info.Name()), ".exe")
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+// plugin returns the named plugin, starting it if it isn't running.
+func (e *Executor) plugin(name string) (Plugin, error) {
+	path, ok := e.paths[name]
+	if !ok {
+		return nil, fmt.Errorf("no executor plugin named %q is installed", name)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	client, ok := e.clients[name]
+	if !ok || client.Exited() {
+		client = goplugin.NewClient(&goplugin.ClientConfig{
+			HandshakeConfig:  Handshake,
+			Plugins:          goplugin.PluginSet{pluginName: &executorPlugin{}},
+			Cmd:              exec.Command(path),
+			AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolGRPC},
+			Logger: hclog.New(&hclog.LoggerOptions{
+				Name:   "executor-plugin." + name,
+				Output: os.Stderr,
+				// plugins aren't checksummed, which go-plugin warns about every time one starts
+				Level: hclog.Error,
+			}),
+		})
+		e.clients[name] = client
+	}
+
+	protocol, err := client.Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start executor plugin %q: %w", name, err)
+	}
+	dispensed, err := protocol.Dispense(pluginName)
+	if err != nil {
+		return nil, err
+	}
+	return dispensed.(Plugin), nil
+}
+
+func (e *Executor) stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, client := range e.clients {
+		client.Kill()
+	}
+}
+
+// IsInstalled checks if any plugins are installed. Whether a job's plugin is
+// installed is checked when bidding on it.
+func (e *Executor) IsInstalled(context.Context) (bool, error) {
+	return len(e.paths) > 0, nil
+}
+
+// GetBidStrategy implements executor.Executor
+func (e *Executor) GetBidStrategy(context.Context) (bidstrategy.BidStrategy, error) {
+	return &pluginBidStrategy{executor: e}, nil
+}
+
+func (e *Executor) HasStorageLocally(ctx context.Context, volume model.StorageSpec) (bool, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/plugin.Executor.HasStorageLocally")
+	defer span.End()
+
+	s, err := e.StorageProvider.Get(ctx, volume.StorageSource)
+	if err != nil {
+		return false, err
+	}
+
+	return s.HasStorageLocally(ctx, volume)
+}
+
+func (e *Executor) GetVolumeSize(ctx context.Context, volume model.StorageSpec) (uint64, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/plugin.Executor.GetVolumeSize")
+	defer span.End()
+
+	storageProvider, err := e.StorageProvider.Get(ctx, volume.StorageSource)
+	if err != nil {
+		return 0, err
+	}
+	return storageProvider.GetVolumeSize(ctx, volume)
+}
+
+func (e *Executor) Run(
+	ctx context.Context,
+	job model.Job,
+	jobResultsDir string,
+) (*model.RunCommandResult, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/plugin.Executor.Run")
+	defer span.End()
+
+	plugin, err := e.plugin(job.Spec.Plugin.Name)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	inputVolumes, err := storage.ParallelPrepareStorage(ctx, e.StorageProvider, job.Spec.Inputs)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	request := RunRequest{Job: job, ResultsDir: jobResultsDir}
+	for _, volume := range inputVolumes {
+		request.Inputs = append(request.Inputs, volume)
+	}
+
+	for _, output := range job.Spec.Outputs {
+		if output.Name == "" {
+			return executor.FailResult(fmt.Errorf("output volume has no name: %+v", output))
+		}
+		err = os.Mkdir(filepath.Join(jobResultsDir, output.Name), util.OS_ALL_R|util.OS_ALL_X|util.OS_USER_W)
+		if err != nil {
+			return executor.FailResult(err)
+		}
+	}
+
+	log.Ctx(ctx).Debug().Str("Plugin", job.Spec.Plugin.Name).Msg("Running job with executor plugin")
+	response, runErr := plugin.Run(ctx, request)
+	return executor.WriteJobResults(
+		jobResultsDir,
+		strings.NewReader(response.STDOUT),
+		strings.NewReader(response.STDERR),
+		response.ExitCode,
+		runErr,
+	)
+}
+
+func (e *Executor) GetOutputStream(context.Context, model.Job, bool) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not implemented for plugin executor")
+}
+
+// pluginBidStrategy rejects jobs for plugins that aren't installed, and asks
+// the plugin about the rest.
+type pluginBidStrategy struct {
+	executor *Executor
+}
+
+// ShouldBid implements bidstrategy.BidStrategy
+func (s *pluginBidStrategy) ShouldBid(
+	ctx context.Context,
+	request bidstrategy.BidStrategyRequest,
+) (bidstrategy.BidStrategyResponse, error) {
+	if request.Job.Spec.Engine != model.EnginePlugin {
+		return bidstrategy.NewShouldBidResponse(), nil
+	}
+
+	name := request.Job.Spec.Plugin.Name
+	if _, ok := s.executor.paths[name]; !ok {
+		return bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason:    fmt.Sprintf("Executor plugin %s is not installed", name),
+		}, nil
+	}
+
+	plugin, err := s.executor.plugin(name)
+	if err != nil {
+		return bidstrategy.BidStrategyResponse{}, err
+	}
+	installed, err := plugin.IsInstalled(ctx)
+	if err != nil {
+		return bidstrategy.BidStrategyResponse{}, err
+	}
+	if !installed {
+		return bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason:    fmt.Sprintf("Executor plugin %s is not ready to run jobs", name),
+		}, nil
+	}
+	return plugin.ShouldBid(ctx, request)
+}
+
+// ShouldBidBasedOnUsage implements bidstrategy.BidStrategy
+func (*pluginBidStrategy) ShouldBidBasedOnUsage(
+	context.Context,
+	bidstrategy.BidStrategyRequest,
+	model.ResourceUsageData,
+) (bidstrategy.BidStrategyResponse, error) {
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+// Compile-time interface checks:
+var _ executor.Executor = (*Executor)(nil)
+var _ bidstrategy.BidStrategy = (*pluginBidStrategy)(nil)
@@ -0,0 +1,136 @@
+//go:build (unit || !integration) && unix
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/require"
+)
+
+// testPlugin echoes its parameters and writes a file into each output.
+type testPlugin struct{}
+
+func (testPlugin) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (testPlugin) ShouldBid(_ context.Context, request bidstrategy.BidStrategyRequest) (bidstrategy.BidStrategyResponse, error) {
+	if request.Job.Spec.Plugin.Parameters["model"] == "" {
+		return bidstrategy.BidStrategyResponse{ShouldBid: false, Reason: "no model"}, nil
+	}
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+func (testPlugin) Run(_ context.Context, request RunRequest) (RunResponse, error) {
+	for _, output := range request.Job.Spec.Outputs {
+		err := os.WriteFile(filepath.Join(request.ResultsDir, output.Name, "result.txt"), []byte("simulated"), 0644)
+		if err != nil {
+			return RunResponse{}, err
+		}
+	}
+	return RunResponse{
+		STDOUT:   fmt.Sprintf("simulating %s", request.Job.Spec.Plugin.Parameters["model"]),
+		ExitCode: 2,
+	}, nil
+}
+
+// TestHelperPlugin is run as the plugin process by the tests below.
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv(Handshake.MagicCookieKey) == "" {
+		t.Skip("only run as a plugin")
+	}
+	Serve(testPlugin{})
+}
+
+// installTestPlugin installs this test binary as a plugin named simulator.
+func installTestPlugin(t *testing.T) string {
+	dir := t.TempDir()
+	script := fmt.Sprintf("#!/bin/sh\nexec %q -test.run=TestHelperPlugin\n", os.Args[0])
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "simulator.sh"), []byte(script), 0755))
+	return dir
+}
+
+func pluginJob(parameters map[string]string) model.Job {
+	return model.Job{Spec: model.Spec{
+		Engine:  model.EnginePlugin,
+		Plugin:  model.JobSpecPlugin{Name: "simulator", Parameters: parameters},
+		Outputs: []model.StorageSpec{{Name: "outputs", Path: "/outputs"}},
+	}}
+}
+
+func TestDiscoverOnlyFindsExecutables(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "simulator"), nil, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), nil, 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested"), 0755))
+
+	paths, err := discover(dir)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"simulator": filepath.Join(dir, "simulator")}, paths)
+}
+
+func TestGRPCRoundTrip(t *testing.T) {
+	client, _ := goplugin.TestPluginGRPCConn(t, map[string]goplugin.Plugin{pluginName: &executorPlugin{impl: testPlugin{}}})
+	defer client.Close()
+	dispensed, err := client.Dispense(pluginName)
+	require.NoError(t, err)
+	remote := dispensed.(Plugin)
+
+	installed, err := remote.IsInstalled(context.Background())
+	require.NoError(t, err)
+	require.True(t, installed)
+
+	response, err := remote.ShouldBid(context.Background(), bidstrategy.BidStrategyRequest{Job: pluginJob(nil)})
+	require.NoError(t, err)
+	require.Equal(t, bidstrategy.BidStrategyResponse{ShouldBid: false, Reason: "no model"}, response)
+
+	job := pluginJob(map[string]string{"model": "weather"})
+	job.Spec.Outputs = nil
+	run, err := remote.Run(context.Background(), RunRequest{
+		Job:    job,
+		Inputs: []storage.StorageVolume{{Source: "/tmp/input", Target: "/inputs"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, RunResponse{STDOUT: "simulating weather", ExitCode: 2}, run)
+}
+
+func TestRunWithPluginProcess(t *testing.T) {
+	cm := system.NewCleanupManager()
+	defer cm.Cleanup(context.Background())
+
+	e, err := NewExecutor(context.Background(), cm, model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{}),
+		ExecutorOptions{Directory: installTestPlugin(t)})
+	require.NoError(t, err)
+
+	strategy, err := e.GetBidStrategy(context.Background())
+	require.NoError(t, err)
+	response, err := strategy.ShouldBid(context.Background(), bidstrategy.BidStrategyRequest{Job: pluginJob(nil)})
+	require.NoError(t, err)
+	require.False(t, response.ShouldBid)
+
+	missing := pluginJob(nil)
+	missing.Spec.Plugin.Name = "missing"
+	response, err = strategy.ShouldBid(context.Background(), bidstrategy.BidStrategyRequest{Job: missing})
+	require.NoError(t, err)
+	require.False(t, response.ShouldBid)
+
+	resultsDir := t.TempDir()
+	result, err := e.Run(context.Background(), pluginJob(map[string]string{"model": "weather"}), resultsDir)
+	require.NoError(t, err)
+	require.Equal(t, "simulating weather", result.STDOUT)
+	require.Equal(t, 2, result.ExitCode)
+
+	contents, err := os.ReadFile(filepath.Join(resultsDir, "outputs", "result.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "simulated", string(contents))
+}
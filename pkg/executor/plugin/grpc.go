@@ -0,0 +1,140 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	goplugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// Messages are encoded as JSON rather than protobuf, like the other protocols
+// between bacalhau nodes, so that plugins exchange the same models as the rest
+// of bacalhau. The service is described here rather than generated, and is
+// called with the json content subtype so that the services go-plugin itself
+// registers keep using protobuf.
+const serviceName = "bacalhau.executor.v1.Executor"
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func init() { //nolint:gochecknoinits // grpc servers look up codecs by the content subtype of calls
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type isInstalledResponse struct {
+	Installed bool
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*Plugin)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod("IsInstalled", func(ctx context.Context, impl Plugin, _ *struct{}) (isInstalledResponse, error) {
+			installed, err := impl.IsInstalled(ctx)
+			return isInstalledResponse{Installed: installed}, err
+		}),
+		unaryMethod("ShouldBid", func(
+			ctx context.Context, impl Plugin, request *bidstrategy.BidStrategyRequest,
+		) (bidstrategy.BidStrategyResponse, error) {
+			return impl.ShouldBid(ctx, *request)
+		}),
+		unaryMethod("Run", func(ctx context.Context, impl Plugin, request *RunRequest) (RunResponse, error) {
+			return impl.Run(ctx, *request)
+		}),
+	},
+	Metadata: "pkg/executor/plugin/grpc.go",
+}
+
+func fullMethod(method string) string {
+	return "/" + serviceName + "/" + method
+}
+
+// unaryMethod describes a method of the service the way protoc would generate it.
+func unaryMethod[Request any, Response any](
+	method string,
+	call func(context.Context, Plugin, *Request) (Response, error),
+) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: method,
+		Handler: func(
+			srv interface{},
+			ctx context.Context,
+			decode func(interface{}) error,
+			interceptor grpc.UnaryServerInterceptor,
+		) (interface{}, error) {
+			request := new(Request)
+			if err := decode(request); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, request interface{}) (interface{}, error) {
+				return call(ctx, srv.(Plugin), request.(*Request))
+			}
+			if interceptor == nil {
+				return handler(ctx, request)
+			}
+			return interceptor(ctx, request, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod(method)}, handler)
+		},
+	}
+}
+
+// executorPlugin is how go-plugin serves and dispenses a Plugin.
+type executorPlugin struct {
+	goplugin.NetRPCUnsupportedPlugin
+	impl Plugin
+}
+
+func (p *executorPlugin) GRPCServer(_ *goplugin.GRPCBroker, server *grpc.Server) error {
+	server.RegisterService(&serviceDesc, p.impl)
+	return nil
+}
+
+func (p *executorPlugin) GRPCClient(_ context.Context, _ *goplugin.GRPCBroker, conn *grpc.ClientConn) (interface{}, error) {
+	return &grpcClient{conn: conn}, nil
+}
+
+// grpcClient calls a Plugin running in another process.
+type grpcClient struct {
+	conn *grpc.ClientConn
+}
+
+func (c *grpcClient) invoke(ctx context.Context, method string, request, response interface{}) error {
+	return c.conn.Invoke(ctx, fullMethod(method), request, response, grpc.CallContentSubtype(jsonCodec{}.Name()))
+}
+
+func (c *grpcClient) IsInstalled(ctx context.Context) (bool, error) {
+	var response isInstalledResponse
+	err := c.invoke(ctx, "IsInstalled", &struct{}{}, &response)
+	return response.Installed, err
+}
+
+func (c *grpcClient) ShouldBid(
+	ctx context.Context,
+	request bidstrategy.BidStrategyRequest,
+) (bidstrategy.BidStrategyResponse, error) {
+	var response bidstrategy.BidStrategyResponse
+	err := c.invoke(ctx, "ShouldBid", &request, &response)
+	return response, err
+}
+
+func (c *grpcClient) Run(ctx context.Context, request RunRequest) (RunResponse, error) {
+	var response RunResponse
+	err := c.invoke(ctx, "Run", &request, &response)
+	return response, err
+}
+
+var _ goplugin.GRPCPlugin = (*executorPlugin)(nil)
+var _ Plugin = (*grpcClient)(nil)
@@ -0,0 +1,82 @@
+package plugin
+
+/*
+Executor plugins let third parties ship engines as separate binaries, which a
+compute node discovers from its plugins directory and runs jobs with, without
+bacalhau being recompiled. Plugins are run with hashicorp/go-plugin and talk to
+the compute node over gRPC.
+
+A plugin is a binary whose main calls Serve:
+
+	func main() {
+		plugin.Serve(&mySimulator{})
+	}
+
+Jobs are run by a plugin if their engine is Plugin and they name it:
+
+	Engine: Plugin
+	Plugin:
+	  Name: my-simulator
+	  Parameters:
+	    model: weather
+*/
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// Handshake is shared by the compute node and its plugins, so that plugins
+// built for another protocol version are refused, and so that running a
+// plugin binary by hand explains it is not meant to be run directly.
+var Handshake = goplugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "BACALHAU_EXECUTOR_PLUGIN",
+	MagicCookieValue: "4d6e5a8c-7c2b-4f43-9b55-0c3f1b0e3a17",
+}
+
+// the name the executor is dispensed under by go-plugin
+const pluginName = "executor"
+
+// Plugin is implemented by executor plugins. The compute node prepares the
+// inputs of jobs and writes their results, so plugins only need to run them.
+type Plugin interface {
+	// IsInstalled checks if whatever the plugin runs jobs with is available.
+	IsInstalled(ctx context.Context) (bool, error)
+
+	// ShouldBid returns a positive response if the plugin could run the job.
+	ShouldBid(ctx context.Context, request bidstrategy.BidStrategyRequest) (bidstrategy.BidStrategyResponse, error)
+
+	// Run runs the job, writing each of its outputs into a directory of the
+	// same name in the results directory.
+	Run(ctx context.Context, request RunRequest) (RunResponse, error)
+}
+
+type RunRequest struct {
+	Job model.Job
+	// Inputs are where the inputs of the job have been prepared on the
+	// compute node.
+	Inputs []storage.StorageVolume
+	// ResultsDir is where the outputs of the job are collected from.
+	ResultsDir string
+}
+
+type RunResponse struct {
+	STDOUT   string
+	STDERR   string
+	ExitCode int
+}
+
+// Serve runs the plugin until the compute node that started it exits. It is
+// meant to be called from the main function of the plugin binary.
+func Serve(impl Plugin) {
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins:         goplugin.PluginSet{pluginName: &executorPlugin{impl: impl}},
+		GRPCServer:      goplugin.DefaultGRPCServer,
+	})
+}
@@ -0,0 +1,91 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/rs/zerolog/log"
+)
+
+// The period over which CPU limits are enforced, the same as docker's default.
+const cpuPeriod = 100000
+
+// cgroup is a cgroup v2 directory that a single job is run in.
+type cgroup struct {
+	path string
+	dir  *os.File
+}
+
+// checkCgroupParent verifies the parent is a cgroup v2 directory that the CPU
+// and memory controllers are enabled for, so that limits can be set on jobs.
+func checkCgroupParent(parent string) error {
+	controllers, err := os.ReadFile(filepath.Join(parent, "cgroup.subtree_control"))
+	if err != nil {
+		return fmt.Errorf("%s is not a cgroup v2 directory: %w", parent, err)
+	}
+	for _, controller := range []string{"cpu", "memory"} {
+		if !containsField(string(controllers), controller) {
+			return fmt.Errorf("the %s controller is not enabled for the children of cgroup %s", controller, parent)
+		}
+	}
+	return nil
+}
+
+// newCgroup creates a cgroup under the parent that limits processes in it to
+// the given resources. The cgroup is removed again if the limits can't be set.
+func newCgroup(parent, name string, resources model.ResourceUsageData) (*cgroup, error) {
+	c := &cgroup{path: filepath.Join(parent, name)}
+	if err := os.Mkdir(c.path, util.OS_USER_RWX|util.OS_ALL_R|util.OS_ALL_X); err != nil {
+		return nil, err
+	}
+	if err := c.setLimits(resources); err != nil {
+		_ = os.Remove(c.path)
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *cgroup) setLimits(resources model.ResourceUsageData) error {
+	if resources.Memory > 0 {
+		if err := c.write("memory.max", strconv.FormatUint(resources.Memory, 10)); err != nil {
+			return err
+		}
+	}
+	if resources.CPU > 0 {
+		quota := int64(resources.CPU * cpuPeriod)
+		if err := c.write("cpu.max", fmt.Sprintf("%d %d", quota, cpuPeriod)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *cgroup) write(file, value string) error {
+	return os.WriteFile(filepath.Join(c.path, file), []byte(value), util.OS_USER_RW)
+}
+
+// Remove deletes the cgroup, which the kernel only allows once all of its
+// processes have exited.
+func (c *cgroup) Remove(ctx context.Context) {
+	if c.dir != nil {
+		_ = c.dir.Close()
+	}
+	if err := os.Remove(c.path); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Str("Cgroup", c.path).Msg("failed to remove cgroup")
+	}
+}
+
+func containsField(s, field string) bool {
+	for _, f := range strings.Fields(s) {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
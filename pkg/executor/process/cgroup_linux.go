@@ -0,0 +1,24 @@
+package process
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Attach makes the command start inside the cgroup, so that none of its
+// processes can escape the limits by forking before being moved into it.
+func (c *cgroup) Attach(cmd *exec.Cmd) error {
+	dir, err := os.Open(c.path)
+	if err != nil {
+		return err
+	}
+	c.dir = dir
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(dir.Fd())
+	return nil
+}
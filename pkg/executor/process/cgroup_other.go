@@ -0,0 +1,12 @@
+//go:build !linux
+
+package process
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+func (c *cgroup) Attach(*exec.Cmd) error {
+	return fmt.Errorf("cgroups are only supported on linux")
+}
@@ -0,0 +1,243 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"go.uber.org/multierr"
+)
+
+// ExecutorOptions configures running jobs directly on the compute node.
+type ExecutorOptions struct {
+	// Enabled lets the compute node run process jobs. Jobs run with the
+	// privileges of the compute node and share its network and filesystem, so
+	// it must only be enabled where every client submitting jobs is trusted.
+	Enabled bool
+	// CgroupParent is a cgroup v2 directory delegated to the compute node, such
+	// as /sys/fs/cgroup/bacalhau.slice, to enforce the CPU and memory limits of
+	// jobs in. Limits are not enforced if it is not set.
+	CgroupParent string
+}
+
+// Executor runs the command of a job as a process on the compute node, for
+// single operator clusters where containers are unnecessary overhead.
+//
+// Each job runs in its own directory, with the inputs and outputs of the job
+// linked into it at their paths relative to that directory. For example, an
+// input at /inputs is available to the job at ./inputs.
+type Executor struct {
+	// the storage providers we can implement for a job
+	StorageProvider storage.StorageProvider
+
+	options ExecutorOptions
+	outputs *executor.OutputStreams
+}
+
+func NewExecutor(
+	_ context.Context,
+	storageProvider storage.StorageProvider,
+	options ExecutorOptions,
+) (*Executor, error) {
+	if options.CgroupParent != "" {
+		if err := checkCgroupParent(options.CgroupParent); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Executor{
+		StorageProvider: storageProvider,
+		options:         options,
+		outputs:         executor.NewOutputStreams(),
+	}, nil
+}
+
+// IsInstalled returns true as processes can always be run on the compute node.
+func (e *Executor) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+// GetBidStrategy implements executor.Executor
+func (e *Executor) GetBidStrategy(context.Context) (bidstrategy.BidStrategy, error) {
+	return bidstrategy.NewChainedBidStrategy(), nil
+}
+
+func (e *Executor) HasStorageLocally(ctx context.Context, volume model.StorageSpec) (bool, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/process.Executor.HasStorageLocally")
+	defer span.End()
+
+	s, err := e.StorageProvider.Get(ctx, volume.StorageSource)
+	if err != nil {
+		return false, err
+	}
+
+	return s.HasStorageLocally(ctx, volume)
+}
+
+func (e *Executor) GetVolumeSize(ctx context.Context, volume model.StorageSpec) (uint64, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/process.Executor.GetVolumeSize")
+	defer span.End()
+
+	storageProvider, err := e.StorageProvider.Get(ctx, volume.StorageSource)
+	if err != nil {
+		return 0, err
+	}
+	return storageProvider.GetVolumeSize(ctx, volume)
+}
+
+//nolint:funlen
+func (e *Executor) Run(
+	ctx context.Context,
+	job model.Job,
+	jobResultsDir string,
+) (*model.RunCommandResult, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/process.Executor.Run")
+	defer span.End()
+
+	spec := job.Spec.Process
+	if spec.Command == "" {
+		return executor.FailResult(fmt.Errorf("process job has no command"))
+	}
+
+	jobDir, err := os.MkdirTemp("", "bacalhau-process-*")
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	defer func() {
+		if removeErr := os.RemoveAll(jobDir); removeErr != nil {
+			log.Ctx(ctx).Debug().Err(removeErr).Str("Dir", jobDir).Msg("failed to remove job directory")
+		}
+	}()
+
+	inputVolumes, err := storage.ParallelPrepareStorage(ctx, e.StorageProvider, job.Spec.Inputs)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	for input, volume := range inputVolumes {
+		if volume.Type != storage.StorageVolumeConnectorBind {
+			return executor.FailResult(fmt.Errorf("unknown storage volume type: %s", volume.Type))
+		}
+		log.Ctx(ctx).Trace().Msgf("Input Volume: %+v %+v", input, volume)
+		if err = executor.LinkIntoJobDir(jobDir, volume.Source, volume.Target); err != nil {
+			return executor.FailResult(err)
+		}
+	}
+
+	for _, output := range job.Spec.Outputs {
+		if output.Name == "" {
+			return executor.FailResult(fmt.Errorf("output volume has no name: %+v", output))
+		}
+		if output.Path == "" {
+			return executor.FailResult(fmt.Errorf("output volume has no path: %+v", output))
+		}
+
+		srcd := filepath.Join(jobResultsDir, output.Name)
+		if err = os.Mkdir(srcd, util.OS_ALL_R|util.OS_ALL_X|util.OS_USER_W); err != nil {
+			return executor.FailResult(err)
+		}
+		log.Ctx(ctx).Trace().Msgf("Output Volume: %+v", output)
+		if err = executor.LinkIntoJobDir(jobDir, srcd, output.Path); err != nil {
+			return executor.FailResult(err)
+		}
+	}
+
+	workingDir := jobDir
+	if spec.WorkingDirectory != "" {
+		if !filepath.IsLocal(spec.WorkingDirectory) {
+			return executor.FailResult(fmt.Errorf("working directory %q must be relative to the job directory", spec.WorkingDirectory))
+		}
+		workingDir = filepath.Join(jobDir, spec.WorkingDirectory)
+	}
+
+	jsonJobSpec, err := model.JSONMarshalWithMax(job.Spec)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	// buffer the output on disk until the process exits, as only then do we know its exit code
+	stdout, err := os.CreateTemp("", "bacalhau-stdout-*")
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	defer executor.CloseAndRemove(ctx, stdout)
+	stderr, err := os.CreateTemp("", "bacalhau-stderr-*")
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	defer executor.CloseAndRemove(ctx, stderr)
+	streamStdout, streamStderr, streamDone := e.outputs.Start(job.ID())
+	defer streamDone()
+
+	cmd := exec.CommandContext(ctx, spec.Command, spec.Arguments...) //nolint:gosec // running the job is the point
+	cmd.Dir = workingDir
+	cmd.Stdout = io.MultiWriter(stdout, streamStdout)
+	cmd.Stderr = io.MultiWriter(stderr, streamStderr)
+	// don't leak the environment of the compute node, such as credentials, into jobs
+	cmd.Env = append([]string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + jobDir,
+	}, spec.EnvironmentVariables...)
+	cmd.Env = append(cmd.Env, fmt.Sprintf("BACALHAU_JOB_SPEC=%s", string(jsonJobSpec)))
+	killProcessGroupOnCancel(cmd)
+
+	resources := capacity.ParseResourceUsageConfig(job.Spec.Resources)
+	if e.options.CgroupParent != "" {
+		jobCgroup, cgroupErr := newCgroup(e.options.CgroupParent, filepath.Base(jobDir), resources)
+		if cgroupErr != nil {
+			return executor.FailResult(errors.Wrap(cgroupErr, "failed to create cgroup"))
+		}
+		defer jobCgroup.Remove(ctx)
+		if cgroupErr = jobCgroup.Attach(cmd); cgroupErr != nil {
+			return executor.FailResult(errors.Wrap(cgroupErr, "failed to run process in cgroup"))
+		}
+	} else if resources.CPU > 0 || resources.Memory > 0 {
+		log.Ctx(ctx).Debug().Msg("Not enforcing resource limits of process job as no cgroup is configured")
+	}
+
+	log.Ctx(ctx).Debug().Str("Command", spec.Command).Strs("Arguments", spec.Arguments).Msg("Running process job")
+	runErr := cmd.Run()
+
+	exitCode := 0
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) && ctx.Err() == nil {
+		// a non-zero exit code is reported as the result of the job, rather than as an error
+		exitCode = exitErr.ExitCode()
+		runErr = nil
+	} else if runErr != nil {
+		exitCode = -1
+		if ctx.Err() != nil {
+			runErr = ctx.Err()
+		}
+	}
+
+	_, stdoutErr := stdout.Seek(0, io.SeekStart)
+	_, stderrErr := stderr.Seek(0, io.SeekStart)
+
+	return executor.WriteJobResults(
+		jobResultsDir,
+		stdout,
+		stderr,
+		exitCode,
+		multierr.Combine(runErr, stdoutErr, stderrErr),
+	)
+}
+
+func (e *Executor) GetOutputStream(ctx context.Context, job model.Job, follow bool) (io.ReadCloser, error) {
+	return e.outputs.Stream(ctx, job.ID(), follow)
+}
+
+// Compile-time interface check:
+var _ executor.Executor = (*Executor)(nil)
@@ -0,0 +1,95 @@
+//go:build (unit || !integration) && unix
+
+package process
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func runProcessJob(t *testing.T, spec model.JobSpecProcess, outputs ...model.StorageSpec) (*model.RunCommandResult, string) {
+	exec, err := NewExecutor(context.Background(), nil, ExecutorOptions{Enabled: true})
+	require.NoError(t, err)
+
+	job := model.Job{Spec: model.Spec{Engine: model.EngineProcess, Process: spec, Outputs: outputs}}
+	resultsDir := t.TempDir()
+	result, err := exec.Run(context.Background(), job, resultsDir)
+	require.NoError(t, err)
+	return result, resultsDir
+}
+
+func TestRunCapturesOutputAndExitCode(t *testing.T) {
+	result, _ := runProcessJob(t, model.JobSpecProcess{
+		Command:              "sh",
+		Arguments:            []string{"-c", `echo "$GREETING"; echo oops >&2; exit 3`},
+		EnvironmentVariables: []string{"GREETING=hello"},
+	})
+	require.Equal(t, "hello\n", result.STDOUT)
+	require.Equal(t, "oops\n", result.STDERR)
+	require.Equal(t, 3, result.ExitCode)
+	require.Empty(t, result.ErrorMsg)
+}
+
+func TestRunWritesOutputsRelativeToJobDir(t *testing.T) {
+	result, resultsDir := runProcessJob(t, model.JobSpecProcess{
+		Command:   "sh",
+		Arguments: []string{"-c", "echo done > outputs/result.txt"},
+	}, model.StorageSpec{Name: "outputs", Path: "/outputs"})
+	require.Zero(t, result.ExitCode)
+
+	contents, err := os.ReadFile(filepath.Join(resultsDir, "outputs", "result.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "done\n", string(contents))
+}
+
+func TestRunDoesNotLeakNodeEnvironment(t *testing.T) {
+	t.Setenv("NODE_SECRET", "secret")
+	result, _ := runProcessJob(t, model.JobSpecProcess{
+		Command:   "sh",
+		Arguments: []string{"-c", `echo -n "$NODE_SECRET"`},
+	})
+	require.Empty(t, result.STDOUT)
+}
+
+func TestRunRejectsWorkingDirectoryOutsideJobDir(t *testing.T) {
+	exec, err := NewExecutor(context.Background(), nil, ExecutorOptions{Enabled: true})
+	require.NoError(t, err)
+
+	job := model.Job{Spec: model.Spec{
+		Engine:  model.EngineProcess,
+		Process: model.JobSpecProcess{Command: "pwd", WorkingDirectory: "../.."},
+	}}
+	result, err := exec.Run(context.Background(), job, t.TempDir())
+	require.Error(t, err)
+	require.NotEmpty(t, result.ErrorMsg)
+}
+
+func TestNewCgroupWritesLimits(t *testing.T) {
+	parent := t.TempDir()
+	c, err := newCgroup(parent, "job", model.ResourceUsageData{CPU: 0.5, Memory: 1024})
+	require.NoError(t, err)
+
+	memoryMax, err := os.ReadFile(filepath.Join(c.path, "memory.max"))
+	require.NoError(t, err)
+	require.Equal(t, "1024", string(memoryMax))
+
+	cpuMax, err := os.ReadFile(filepath.Join(c.path, "cpu.max"))
+	require.NoError(t, err)
+	require.Equal(t, "50000 100000", string(cpuMax))
+}
+
+func TestCheckCgroupParentRequiresControllers(t *testing.T) {
+	parent := t.TempDir()
+	require.Error(t, checkCgroupParent(parent))
+
+	require.NoError(t, os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("cpu io"), 0600))
+	require.Error(t, checkCgroupParent(parent))
+
+	require.NoError(t, os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("cpu io memory"), 0600))
+	require.NoError(t, checkCgroupParent(parent))
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package process
+
+import (
+	"os/exec"
+)
+
+// killProcessGroupOnCancel leaves the default behaviour of only killing the
+// job's process when it is cancelled, as process groups are unix specific.
+func killProcessGroupOnCancel(*exec.Cmd) {}
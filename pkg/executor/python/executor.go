@@ -0,0 +1,215 @@
+package python
+
+/*
+The python executor wraps the process executor. It runs the script of a job
+with the interpreter of a virtualenv that has the job's requirements installed,
+which is built the first time they are asked for and reused by later jobs.
+*/
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultInterpreter is the python used to create virtualenvs if no other is
+// configured.
+const DefaultInterpreter = "python3"
+
+// written into a virtualenv once its requirements are installed, so that
+// virtualenvs left half built by a crash are rebuilt
+const completeMarker = ".bacalhau-complete"
+
+// ExecutorOptions configures how virtualenvs are built.
+type ExecutorOptions struct {
+	// Interpreter is the python used to create virtualenvs.
+	Interpreter string
+	// VirtualenvCacheDir is where virtualenvs are kept, one for each set of
+	// requirements.
+	VirtualenvCacheDir string
+}
+
+type Executor struct {
+	executors executor.ExecutorProvider
+	options   ExecutorOptions
+
+	// serializes building the same virtualenv
+	buildLocks sync.Map
+}
+
+func NewExecutor(
+	executors executor.ExecutorProvider,
+	options ExecutorOptions,
+) (*Executor, error) {
+	if options.Interpreter == "" {
+		options.Interpreter = DefaultInterpreter
+	}
+	if options.VirtualenvCacheDir == "" {
+		options.VirtualenvCacheDir = filepath.Join(config.GetStoragePath(), "bacalhau-virtualenvs")
+	}
+
+	return &Executor{
+		executors: executors,
+		options:   options,
+	}, nil
+}
+
+func (e *Executor) IsInstalled(ctx context.Context) (bool, error) {
+	if _, err := exec.LookPath(e.options.Interpreter); err != nil {
+		return false, nil
+	}
+	processExecutor, err := e.executors.Get(ctx, model.EngineProcess)
+	if err != nil {
+		return false, err
+	}
+	return processExecutor.IsInstalled(ctx)
+}
+
+func (e *Executor) HasStorageLocally(ctx context.Context, volume model.StorageSpec) (bool, error) {
+	processExecutor, err := e.executors.Get(ctx, model.EngineProcess)
+	if err != nil {
+		return false, err
+	}
+	return processExecutor.HasStorageLocally(ctx, volume)
+}
+
+func (e *Executor) GetVolumeSize(ctx context.Context, volume model.StorageSpec) (uint64, error) {
+	processExecutor, err := e.executors.Get(ctx, model.EngineProcess)
+	if err != nil {
+		return 0, err
+	}
+	return processExecutor.GetVolumeSize(ctx, volume)
+}
+
+func (e *Executor) GetBidStrategy(ctx context.Context) (bidstrategy.BidStrategy, error) {
+	processExecutor, err := e.executors.Get(ctx, model.EngineProcess)
+	if err != nil {
+		return nil, err
+	}
+	return processExecutor.GetBidStrategy(ctx)
+}
+
+func (e *Executor) Run(ctx context.Context, job model.Job, resultsDir string) (*model.RunCommandResult, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/python.Executor.Run")
+	defer span.End()
+
+	processExecutor, err := e.executors.Get(ctx, model.EngineProcess)
+	if err != nil {
+		return nil, err
+	}
+
+	virtualenv, err := e.virtualenv(ctx, job.Spec.Python.Requirements)
+	if err != nil {
+		return executor.FailResult(errors.Wrap(err, "failed to build virtualenv"))
+	}
+
+	script, err := os.CreateTemp("", "bacalhau-python-*.py")
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	defer os.Remove(script.Name())
+	_, err = script.WriteString(job.Spec.Python.Script)
+	if closeErr := script.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	// translate the python jobspec into a process that runs the script
+	job.Spec.Engine = model.EngineProcess
+	job.Spec.Process = model.JobSpecProcess{
+		Command:   interpreterPath(virtualenv),
+		Arguments: append([]string{script.Name()}, job.Spec.Python.Arguments...),
+		EnvironmentVariables: append(
+			[]string{"VIRTUAL_ENV=" + virtualenv},
+			job.Spec.Python.EnvironmentVariables...,
+		),
+	}
+	return processExecutor.Run(ctx, job, resultsDir)
+}
+
+func (e *Executor) GetOutputStream(ctx context.Context, job model.Job, follow bool) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not implemented for python executor")
+}
+
+// virtualenv returns the path of a virtualenv with the requirements installed,
+// building it if it doesn't exist yet.
+func (e *Executor) virtualenv(ctx context.Context, requirements []string) (string, error) {
+	for _, requirement := range requirements {
+		if strings.HasPrefix(requirement, "-") {
+			return "", fmt.Errorf("requirement %q must not be a pip option", requirement)
+		}
+	}
+
+	path := filepath.Join(e.options.VirtualenvCacheDir, requirementsHash(e.options.Interpreter, requirements))
+
+	lock, _ := e.buildLocks.LoadOrStore(path, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	if exists, err := system.PathExists(filepath.Join(path, completeMarker)); err != nil || exists {
+		return path, err
+	}
+
+	log.Ctx(ctx).Debug().Str("Virtualenv", path).Strs("Requirements", requirements).Msg("Building virtualenv")
+	if err := os.RemoveAll(path); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(e.options.VirtualenvCacheDir, util.OS_USER_RWX); err != nil {
+		return "", err
+	}
+	if err := run(ctx, e.options.Interpreter, "-m", "venv", path); err != nil {
+		return "", err
+	}
+	if len(requirements) > 0 {
+		args := append([]string{"-m", "pip", "install", "--no-input", "--disable-pip-version-check"}, requirements...)
+		if err := run(ctx, interpreterPath(path), args...); err != nil {
+			return "", err
+		}
+	}
+	return path, os.WriteFile(filepath.Join(path, completeMarker), nil, util.OS_USER_RW)
+}
+
+func run(ctx context.Context, name string, args ...string) error {
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// requirementsHash identifies a virtualenv by its interpreter and requirements,
+// regardless of the order the requirements are listed in.
+func requirementsHash(interpreter string, requirements []string) string {
+	sorted := append([]string{}, requirements...)
+	sort.Strings(sorted)
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(interpreter+"\n"+strings.Join(sorted, "\n"))))
+}
+
+func interpreterPath(virtualenv string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(virtualenv, "Scripts", "python.exe")
+	}
+	return filepath.Join(virtualenv, "bin", "python")
+}
+
+// Compile-time check that Executor implements the Executor interface.
+var _ executor.Executor = (*Executor)(nil)
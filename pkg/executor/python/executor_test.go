@@ -0,0 +1,66 @@
+//go:build (unit || !integration) && unix
+
+package python
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/process"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequirementsHashIgnoresOrder(t *testing.T) {
+	require.Equal(t,
+		requirementsHash("python3", []string{"numpy==1.24.2", "pandas"}),
+		requirementsHash("python3", []string{"pandas", "numpy==1.24.2"}),
+	)
+	require.NotEqual(t,
+		requirementsHash("python3", []string{"pandas"}),
+		requirementsHash("python3.11", []string{"pandas"}),
+	)
+}
+
+func newTestExecutor(t *testing.T) *Executor {
+	processExecutor, err := process.NewExecutor(context.Background(), nil, process.ExecutorOptions{Enabled: true})
+	require.NoError(t, err)
+	executors := model.NewMappedProvider(map[model.Engine]executor.Executor{model.EngineProcess: processExecutor})
+
+	e, err := NewExecutor(executors, ExecutorOptions{VirtualenvCacheDir: t.TempDir()})
+	require.NoError(t, err)
+	return e
+}
+
+func TestVirtualenvRejectsPipOptions(t *testing.T) {
+	_, err := newTestExecutor(t).virtualenv(context.Background(), []string{"--index-url=http://example.com"})
+	require.Error(t, err)
+}
+
+func TestRunScriptInVirtualenv(t *testing.T) {
+	if _, err := exec.LookPath(DefaultInterpreter); err != nil {
+		t.Skip("python is not installed")
+	}
+	e := newTestExecutor(t)
+
+	job := model.Job{Spec: model.Spec{
+		Engine: model.EnginePython,
+		Python: model.JobSpecPython{
+			Script:    "import os, sys\nprint(sys.argv[1], os.environ['VIRTUAL_ENV'] == sys.prefix)",
+			Arguments: []string{"hello"},
+		},
+	}}
+	result, err := e.Run(context.Background(), job, t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, 0, result.ExitCode, result.STDERR)
+	require.Equal(t, "hello True\n", result.STDOUT)
+
+	// a second job with the same requirements reuses the virtualenv
+	first, err := e.virtualenv(context.Background(), nil)
+	require.NoError(t, err)
+	second, err := e.virtualenv(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+}
@@ -0,0 +1,398 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/pkg/sftp"
+	"github.com/rs/zerolog/log"
+	"go.uber.org/multierr"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	// DefaultPort is the port connected to if the host doesn't have one.
+	DefaultPort = "22"
+	// DefaultWorkDir is where job directories are created on the remote host
+	// if no other directory is configured.
+	DefaultWorkDir = "/tmp/bacalhau"
+)
+
+// ExecutorOptions configures the remote host that process jobs are run on.
+type ExecutorOptions struct {
+	// Host is the address of the remote host, as host or host:port. Process
+	// jobs are only run over SSH if it is set.
+	Host string
+	// User is who to log in to the remote host as.
+	User string
+	// IdentityFile is the private key to authenticate with. The keys of the
+	// running ssh-agent are used if it is not set.
+	IdentityFile string
+	// KnownHostsFile has the host key of the remote host, which must be known
+	// before jobs are run on it. Defaults to ~/.ssh/known_hosts.
+	KnownHostsFile string
+	// WorkDir is where job directories are created on the remote host.
+	WorkDir string
+}
+
+// Executor runs the command of process jobs on a remote host over SSH, so that
+// machines that can't run a compute node themselves, such as GPU boxes without
+// docker, can still run jobs.
+//
+// Like the process executor, each job runs in its own directory, with its
+// inputs copied in, and its outputs copied back, at their paths relative to
+// that directory. The remote host is expected to be a unix system.
+type Executor struct {
+	// the storage providers we can implement for a job
+	StorageProvider storage.StorageProvider
+
+	options ExecutorOptions
+	address string
+	config  *ssh.ClientConfig
+}
+
+func NewExecutor(
+	_ context.Context,
+	storageProvider storage.StorageProvider,
+	options ExecutorOptions,
+) (*Executor, error) {
+	if options.WorkDir == "" {
+		options.WorkDir = DefaultWorkDir
+	}
+	if !path.IsAbs(options.WorkDir) {
+		return nil, fmt.Errorf("SSH work directory %q must be an absolute path", options.WorkDir)
+	}
+
+	address := options.Host
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, DefaultPort)
+	}
+
+	config, err := clientConfig(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Executor{
+		StorageProvider: storageProvider,
+		options:         options,
+		address:         address,
+		config:          config,
+	}, nil
+}
+
+func clientConfig(options ExecutorOptions) (*ssh.ClientConfig, error) {
+	knownHostsFile := options.KnownHostsFile
+	if knownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read SSH known hosts")
+	}
+
+	var auth ssh.AuthMethod
+	if options.IdentityFile != "" {
+		key, err := os.ReadFile(options.IdentityFile)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse SSH identity file")
+		}
+		auth = ssh.PublicKeys(signer)
+	} else {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, fmt.Errorf("running jobs over SSH needs an identity file or a running ssh-agent")
+		}
+		auth = ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+			conn, err := net.Dial("unix", socket)
+			if err != nil {
+				return nil, err
+			}
+			return agent.NewClient(conn).Signers()
+		})
+	}
+
+	return &ssh.ClientConfig{
+		User:            options.User,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: hostKeyCallback,
+	}, nil
+}
+
+// IsInstalled checks if the remote host can be logged in to.
+func (e *Executor) IsInstalled(ctx context.Context) (bool, error) {
+	client, err := e.dial(ctx)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Str("Host", e.options.Host).Msg("Cannot connect to SSH host")
+		return false, nil
+	}
+	return true, client.Close()
+}
+
+// GetBidStrategy implements executor.Executor
+func (e *Executor) GetBidStrategy(context.Context) (bidstrategy.BidStrategy, error) {
+	return bidstrategy.NewChainedBidStrategy(), nil
+}
+
+func (e *Executor) HasStorageLocally(ctx context.Context, volume model.StorageSpec) (bool, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/ssh.Executor.HasStorageLocally")
+	defer span.End()
+
+	s, err := e.StorageProvider.Get(ctx, volume.StorageSource)
+	if err != nil {
+		return false, err
+	}
+
+	return s.HasStorageLocally(ctx, volume)
+}
+
+func (e *Executor) GetVolumeSize(ctx context.Context, volume model.StorageSpec) (uint64, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/ssh.Executor.GetVolumeSize")
+	defer span.End()
+
+	storageProvider, err := e.StorageProvider.Get(ctx, volume.StorageSource)
+	if err != nil {
+		return 0, err
+	}
+	return storageProvider.GetVolumeSize(ctx, volume)
+}
+
+//nolint:funlen,gocyclo
+func (e *Executor) Run(
+	ctx context.Context,
+	job model.Job,
+	jobResultsDir string,
+) (*model.RunCommandResult, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/executor/ssh.Executor.Run")
+	defer span.End()
+
+	spec := job.Spec.Process
+	if spec.Command == "" {
+		return executor.FailResult(fmt.Errorf("process job has no command"))
+	}
+	if spec.WorkingDirectory != "" && !filepath.IsLocal(spec.WorkingDirectory) {
+		return executor.FailResult(fmt.Errorf("working directory %q must be relative to the job directory", spec.WorkingDirectory))
+	}
+
+	inputVolumes, err := storage.ParallelPrepareStorage(ctx, e.StorageProvider, job.Spec.Inputs)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	client, err := e.dial(ctx)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	defer client.Close()
+
+	sftpClient, err := sftp.NewClient(client)
+	if err != nil {
+		return executor.FailResult(errors.Wrap(err, "failed to start SFTP session"))
+	}
+	defer sftpClient.Close()
+
+	if err = sftpClient.MkdirAll(e.options.WorkDir); err != nil {
+		return executor.FailResult(err)
+	}
+	jobDir := path.Join(e.options.WorkDir, "job-"+uuid.NewString())
+	if err = sftpClient.Mkdir(jobDir); err != nil {
+		return executor.FailResult(err)
+	}
+	defer func() {
+		if removeErr := runCommand(ctx, client, "rm -rf "+shellQuote(jobDir), io.Discard, io.Discard); removeErr != nil {
+			log.Ctx(ctx).Debug().Err(removeErr).Str("Dir", jobDir).Msg("failed to remove remote job directory")
+		}
+	}()
+
+	for input, volume := range inputVolumes {
+		if volume.Type != storage.StorageVolumeConnectorBind {
+			return executor.FailResult(fmt.Errorf("unknown storage volume type: %s", volume.Type))
+		}
+		log.Ctx(ctx).Trace().Msgf("Input Volume: %+v %+v", input, volume)
+		remotePath, pathErr := jobPath(jobDir, volume.Target)
+		if pathErr != nil {
+			return executor.FailResult(pathErr)
+		}
+		if err = upload(sftpClient, volume.Source, remotePath); err != nil {
+			return executor.FailResult(errors.Wrapf(err, "failed to copy input %s to SSH host", volume.Target))
+		}
+	}
+
+	outputs := make(map[string]string, len(job.Spec.Outputs))
+	for _, output := range job.Spec.Outputs {
+		if output.Name == "" {
+			return executor.FailResult(fmt.Errorf("output volume has no name: %+v", output))
+		}
+		if output.Path == "" {
+			return executor.FailResult(fmt.Errorf("output volume has no path: %+v", output))
+		}
+		remotePath, pathErr := jobPath(jobDir, output.Path)
+		if pathErr != nil {
+			return executor.FailResult(pathErr)
+		}
+		if err = sftpClient.MkdirAll(remotePath); err != nil {
+			return executor.FailResult(err)
+		}
+		outputs[output.Name] = remotePath
+	}
+
+	jsonJobSpec, err := model.JSONMarshalWithMax(job.Spec)
+	if err != nil {
+		return executor.FailResult(err)
+	}
+
+	// buffer the output on disk until the command exits, as only then do we know its exit code
+	stdout, err := os.CreateTemp("", "bacalhau-stdout-*")
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	defer executor.CloseAndRemove(ctx, stdout)
+	stderr, err := os.CreateTemp("", "bacalhau-stderr-*")
+	if err != nil {
+		return executor.FailResult(err)
+	}
+	defer executor.CloseAndRemove(ctx, stderr)
+
+	log.Ctx(ctx).Debug().Str("Host", e.options.Host).Str("Command", spec.Command).Strs("Arguments", spec.Arguments).
+		Msg("Running process job over SSH")
+	runErr := runCommand(ctx, client, remoteCommand(spec, jobDir, string(jsonJobSpec)), stdout, stderr)
+
+	exitCode := 0
+	var exitErr *ssh.ExitError
+	if errors.As(runErr, &exitErr) && ctx.Err() == nil {
+		// a non-zero exit code is reported as the result of the job, rather than as an error
+		exitCode = exitErr.ExitStatus()
+		runErr = nil
+	} else if runErr != nil {
+		exitCode = -1
+		if ctx.Err() != nil {
+			runErr = ctx.Err()
+		}
+	}
+
+	if runErr == nil {
+		for name, remotePath := range outputs {
+			localPath := filepath.Join(jobResultsDir, name)
+			if err = os.Mkdir(localPath, util.OS_ALL_R|util.OS_ALL_X|util.OS_USER_W); err != nil {
+				return executor.FailResult(err)
+			}
+			if err = download(sftpClient, remotePath, localPath); err != nil {
+				return executor.FailResult(errors.Wrapf(err, "failed to copy output %s from SSH host", name))
+			}
+		}
+	}
+
+	_, stdoutErr := stdout.Seek(0, io.SeekStart)
+	_, stderrErr := stderr.Seek(0, io.SeekStart)
+
+	return executor.WriteJobResults(
+		jobResultsDir,
+		stdout,
+		stderr,
+		exitCode,
+		multierr.Combine(runErr, stdoutErr, stderrErr),
+	)
+}
+
+func (e *Executor) GetOutputStream(context.Context, model.Job, bool) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not implemented for ssh executor")
+}
+
+func (e *Executor) dial(ctx context.Context) (*ssh.Client, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", e.address)
+	if err != nil {
+		return nil, err
+	}
+	sshConn, channels, requests, err := ssh.NewClientConn(conn, e.address, e.config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrapf(err, "failed to connect to SSH host %s", e.options.Host)
+	}
+	return ssh.NewClient(sshConn, channels, requests), nil
+}
+
+// runCommand runs the command in a new session, killing it if the context is
+// cancelled first.
+func runCommand(ctx context.Context, client *ssh.Client, command string, stdout, stderr io.Writer) error {
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			// not every SSH server supports signals, so also close the session,
+			// which at least stops us waiting on the command
+			_ = session.Signal(ssh.SIGKILL)
+			_ = session.Close()
+		case <-done:
+		}
+	}()
+
+	return session.Run(command)
+}
+
+// jobPath returns where the target path of an input or output is in the
+// job directory.
+func jobPath(jobDir, target string) (string, error) {
+	relativeTarget := strings.TrimLeft(path.Clean(filepath.ToSlash(target)), "/")
+	if !filepath.IsLocal(filepath.FromSlash(relativeTarget)) {
+		return "", fmt.Errorf("path %q is outside of the job directory", target)
+	}
+	return path.Join(jobDir, relativeTarget), nil
+}
+
+// remoteCommand returns the shell command that runs the job in its directory,
+// with only the environment it asks for.
+func remoteCommand(spec model.JobSpecProcess, jobDir, jsonJobSpec string) string {
+	workingDir := path.Join(jobDir, filepath.ToSlash(spec.WorkingDirectory))
+
+	words := []string{"exec", "env", "-i", `PATH="$PATH"`, "HOME=" + shellQuote(jobDir)}
+	for _, variable := range spec.EnvironmentVariables {
+		words = append(words, shellQuote(variable))
+	}
+	words = append(words, shellQuote("BACALHAU_JOB_SPEC="+jsonJobSpec), shellQuote(spec.Command))
+	for _, argument := range spec.Arguments {
+		words = append(words, shellQuote(argument))
+	}
+	return "cd " + shellQuote(workingDir) + " && " + strings.Join(words, " ")
+}
+
+// shellQuote quotes the word so that a POSIX shell passes it on unchanged.
+func shellQuote(word string) string {
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// Compile-time interface check:
+var _ executor.Executor = (*Executor)(nil)
@@ -0,0 +1,232 @@
+//go:build (unit || !integration) && unix
+
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestShellQuote(t *testing.T) {
+	out, err := exec.Command("sh", "-c", "printf %s "+shellQuote(`it's "$HOME" \n`)).Output()
+	require.NoError(t, err)
+	require.Equal(t, `it's "$HOME" \n`, string(out))
+}
+
+func TestJobPath(t *testing.T) {
+	p, err := jobPath("/work/job", "/inputs/data")
+	require.NoError(t, err)
+	require.Equal(t, "/work/job/inputs/data", p)
+
+	_, err = jobPath("/work/job", "../etc")
+	require.Error(t, err)
+}
+
+func TestRemoteCommandOnlyPassesJobEnvironment(t *testing.T) {
+	t.Setenv("NODE_SECRET", "secret")
+	jobDir := t.TempDir()
+	command := remoteCommand(model.JobSpecProcess{
+		Command:              "sh",
+		Arguments:            []string{"-c", `echo "$GREETING $NODE_SECRET $(pwd)"`},
+		EnvironmentVariables: []string{"GREETING=hello world"},
+	}, jobDir, "{}")
+
+	out, err := exec.Command("sh", "-c", command).Output()
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("hello world  %s\n", jobDir), string(out))
+}
+
+func TestTransferRoundTrip(t *testing.T) {
+	client := pipeSFTPClient(t)
+
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "nested"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "nested", "file.txt"), []byte("hello"), 0644))
+
+	remote := filepath.Join(t.TempDir(), "job", "inputs")
+	require.NoError(t, upload(client, src, remote))
+	require.NoError(t, os.Symlink("/etc/passwd", filepath.Join(remote, "link")))
+
+	dst := t.TempDir()
+	require.NoError(t, download(client, remote, dst))
+
+	contents, err := os.ReadFile(filepath.Join(dst, "nested", "file.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(contents))
+	require.NoFileExists(t, filepath.Join(dst, "link"))
+}
+
+func TestRunOverSSH(t *testing.T) {
+	options := startTestServer(t)
+	e, err := NewExecutor(context.Background(), model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{}), options)
+	require.NoError(t, err)
+
+	installed, err := e.IsInstalled(context.Background())
+	require.NoError(t, err)
+	require.True(t, installed)
+
+	job := model.Job{Spec: model.Spec{
+		Engine: model.EngineProcess,
+		Process: model.JobSpecProcess{
+			Command:   "sh",
+			Arguments: []string{"-c", "echo hello; echo done > outputs/result.txt; exit 3"},
+		},
+		Outputs: []model.StorageSpec{{Name: "outputs", Path: "/outputs"}},
+	}}
+	resultsDir := t.TempDir()
+	result, err := e.Run(context.Background(), job, resultsDir)
+	require.NoError(t, err)
+	require.Equal(t, "hello\n", result.STDOUT)
+	require.Equal(t, 3, result.ExitCode)
+
+	contents, err := os.ReadFile(filepath.Join(resultsDir, "outputs", "result.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "done\n", string(contents))
+
+	// the remote job directory is removed
+	entries, err := os.ReadDir(options.WorkDir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+// pipeSFTPClient returns an SFTP client of a server for the local filesystem.
+func pipeSFTPClient(t *testing.T) *sftp.Client {
+	clientConn, serverConn := net.Pipe()
+	server, err := sftp.NewServer(serverConn)
+	require.NoError(t, err)
+	go func() { _ = server.Serve() }()
+
+	client, err := sftp.NewClientPipe(clientConn, clientConn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = server.Close()
+	})
+	return client
+}
+
+// startTestServer starts an SSH server that runs commands locally with sh, and
+// returns the options to connect to it.
+func startTestServer(t *testing.T) ExecutorOptions {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+
+	_, userKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	userSigner, err := ssh.NewSignerFromKey(userKey)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(userSigner.PublicKey().Marshal()) {
+				return nil, fmt.Errorf("unknown key")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			go serveTestConn(conn, config)
+		}
+	}()
+
+	dir := t.TempDir()
+	knownHosts := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{listener.Addr().String()}, hostSigner.PublicKey())
+	require.NoError(t, os.WriteFile(knownHosts, []byte(line+"\n"), 0600))
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(userKey)
+	require.NoError(t, err)
+	identity := filepath.Join(dir, "id_ed25519")
+	require.NoError(t, os.WriteFile(identity, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), 0600))
+
+	workDir := filepath.Join(dir, "work")
+	require.NoError(t, os.Mkdir(workDir, 0755))
+	return ExecutorOptions{
+		Host:           listener.Addr().String(),
+		User:           "bacalhau",
+		IdentityFile:   identity,
+		KnownHostsFile: knownHosts,
+		WorkDir:        workDir,
+	}
+}
+
+func serveTestConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go serveTestSession(channel, channelRequests)
+	}
+}
+
+func serveTestSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	for request := range requests {
+		switch request.Type {
+		case "subsystem":
+			_ = request.Reply(payloadString(request) == "sftp", nil)
+			server, err := sftp.NewServer(channel)
+			if err != nil {
+				return
+			}
+			_ = server.Serve()
+			return
+		case "exec":
+			_ = request.Reply(true, nil)
+			cmd := exec.Command("sh", "-c", payloadString(request)) //nolint:gosec // test server
+			cmd.Stdout = channel
+			cmd.Stderr = channel.Stderr()
+			exitCode := 0
+			if err := cmd.Run(); err != nil {
+				exitCode = cmd.ProcessState.ExitCode()
+			}
+			status := make([]byte, 4)
+			binary.BigEndian.PutUint32(status, uint32(exitCode))
+			_, _ = channel.SendRequest("exit-status", false, status)
+			return
+		default:
+			_ = request.Reply(false, nil)
+		}
+	}
+}
+
+// payloadString returns the length prefixed string that is the payload of exec
+// and subsystem requests.
+func payloadString(request *ssh.Request) string {
+	return string(request.Payload[4 : 4+binary.BigEndian.Uint32(request.Payload)])
+}
@@ -0,0 +1,110 @@
+package ssh
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/pkg/sftp"
+)
+
+// upload copies the local file or directory at src to dst on the remote host.
+func upload(client *sftp.Client, src, dst string) error {
+	if err := client.MkdirAll(path.Dir(dst)); err != nil {
+		return err
+	}
+	return filepath.WalkDir(src, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+		target := path.Join(dst, filepath.ToSlash(relative))
+
+		if entry.IsDir() {
+			return client.MkdirAll(target)
+		}
+		if !entry.Type().IsRegular() {
+			// inputs are prepared by storage providers, which only write files and directories
+			return nil
+		}
+		return uploadFile(client, file, target)
+	})
+}
+
+func uploadFile(client *sftp.Client, src, dst string) error {
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError(src, source)
+
+	target, err := client.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError(dst, target)
+
+	_, err = io.Copy(target, source)
+	return err
+}
+
+// download copies the contents of the remote directory at src into the local
+// directory dst, skipping anything that isn't a regular file or directory so
+// that jobs can't link to files outside of their outputs.
+func download(client *sftp.Client, src, dst string) error {
+	walker := client.Walk(src)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(filepath.FromSlash(src), filepath.FromSlash(walker.Path()))
+		if err != nil {
+			return err
+		}
+		if relative == "." {
+			continue
+		}
+		if !filepath.IsLocal(relative) {
+			return fmt.Errorf("remote path %q is outside of the output", walker.Path())
+		}
+		target := filepath.Join(dst, relative)
+
+		info := walker.Stat()
+		switch {
+		case info.IsDir():
+			if err = os.MkdirAll(target, util.OS_ALL_R|util.OS_ALL_X|util.OS_USER_W); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			if err = downloadFile(client, walker.Path(), target); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func downloadFile(client *sftp.Client, src, dst string) error {
+	source, err := client.Open(src)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError(src, source)
+
+	target, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, util.OS_ALL_R|util.OS_USER_W)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError(dst, target)
+
+	_, err = io.Copy(target, source)
+	return err
+}
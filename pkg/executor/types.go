@@ -31,8 +31,9 @@ type Executor interface {
 	//    alongside cpu & memory usage
 	GetVolumeSize(context.Context, model.StorageSpec) (uint64, error)
 
-	// GetOutputStream retrieves a muxed stream from the executor
-	GetOutputStream(ctx context.Context, job model.Job) (io.ReadCloser, error)
+	// GetOutputStream retrieves a muxed stream from the executor. If follow is
+	// true the stream stays open for as long as the job is producing output.
+	GetOutputStream(ctx context.Context, job model.Job, follow bool) (io.ReadCloser, error)
 
 	// run the given job - it's expected that we have already prepared the job
 	// this will return a local filesystem path to the jobs results
@@ -42,3 +43,17 @@ type Executor interface {
 		resultsDir string,
 	) (*model.RunCommandResult, error)
 }
+
+// Checkpointer is an Executor that can save the state of a running job, so
+// that the job can be resumed from that state after the compute node restarts,
+// or on another node that can read the checkpoint.
+type Checkpointer interface {
+	// Checkpoint saves the state of the running job to the directory, along
+	// with what it wrote to its results directory so far, and leaves the job
+	// running.
+	Checkpoint(ctx context.Context, job model.Job, resultsDir string, checkpointDir string) error
+
+	// Resume runs the job from the state checkpointed to the directory, rather
+	// than from its start as Run does.
+	Resume(ctx context.Context, job model.Job, checkpointDir string, resultsDir string) (*model.RunCommandResult, error)
+}
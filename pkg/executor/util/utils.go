@@ -4,19 +4,41 @@ import (
 	"context"
 
 	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/apptainer"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/containerd"
 	"github.com/bacalhau-project/bacalhau/pkg/executor/docker"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/duckdb"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/kubernetes"
 	"github.com/bacalhau-project/bacalhau/pkg/executor/language"
 	noop_executor "github.com/bacalhau-project/bacalhau/pkg/executor/noop"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/plugin"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/process"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/python"
 	pythonwasm "github.com/bacalhau-project/bacalhau/pkg/executor/python_wasm"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/ssh"
 	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm"
 	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs/pinning"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/azureblob"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/bittorrent"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/cache"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/combo"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/extract"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/filecoin"
 	filecoinunsealed "github.com/bacalhau-project/bacalhau/pkg/storage/filecoin_unsealed"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/gcs"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/git"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/huggingface"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/inline"
 	ipfs_storage "github.com/bacalhau-project/bacalhau/pkg/storage/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/ipfs/mount"
+	localdirectory "github.com/bacalhau-project/bacalhau/pkg/storage/local_directory"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/locality"
 	noop_storage "github.com/bacalhau-project/bacalhau/pkg/storage/noop"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/prefetch"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/s3"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/tracing"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/url/urldownload"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
@@ -26,11 +48,49 @@ type StandardStorageProviderOptions struct {
 	API                  ipfs.Client
 	FilecoinUnsealedPath string
 	DownloadPath         string
+	S3                   s3.StorageOptions
+	URLDownload          urldownload.StorageOptions
+	AzureBlob            azureblob.StorageOptions
+	GCS                  gcs.StorageOptions
+	HuggingFace          huggingface.StorageOptions
+	LocalDirectory       localdirectory.StorageOptions
+	BitTorrent           bittorrent.StorageOptions
+	Filecoin             filecoin.StorageOptions
+	Extract              extract.Options
+	Cache                cache.Options
+	// StorageCache is the cache that the storages of the node share, which is
+	// created from Cache if nil
+	StorageCache *cache.Cache
+	// PinningServices are where content uploaded to IPFS is pinned remotely
+	PinningServices []pinning.Service
+	IPFSMount       mount.Options
 }
 
 type StandardExecutorOptions struct {
 	DockerID string
-	Storage  StandardStorageProviderOptions
+	Docker   docker.ExecutorOptions
+	// Containerd runs docker jobs directly against containerd rather than the
+	// docker daemon, if its address is set
+	Containerd containerd.ExecutorOptions
+	// Kubernetes runs docker jobs as pods in a cluster, if enabled
+	Kubernetes kubernetes.ExecutorOptions
+	Apptainer  apptainer.ExecutorOptions
+	// Process runs jobs directly on the host, if enabled
+	Process process.ExecutorOptions
+	// Python runs python jobs as processes, so only if Process is enabled
+	Python python.ExecutorOptions
+	// SSH runs process jobs on a remote host instead, if its host is set
+	SSH ssh.ExecutorOptions
+	// Plugin runs plugin jobs with the executor plugins in its directory, if set
+	Plugin plugin.ExecutorOptions
+	// DuckDB runs SQL queries over the inputs of jobs, if enabled
+	DuckDB  duckdb.ExecutorOptions
+	Storage StandardStorageProviderOptions
+	// Prefetcher hands over the inputs that the compute node prefetched when
+	// bidding to the executors, if set
+	Prefetcher *prefetch.Prefetcher
+	// LocalData records the inputs that the executors prepare, if set
+	LocalData *locality.Tracker
 }
 
 func NewStandardStorageProvider(
@@ -38,12 +98,12 @@ func NewStandardStorageProvider(
 	cm *system.CleanupManager,
 	options StandardStorageProviderOptions,
 ) (storage.StorageProvider, error) {
-	ipfsAPICopyStorage, err := ipfs_storage.NewStorage(cm, options.API)
+	ipfsAPICopyStorage, err := ipfs_storage.NewStorage(cm, options.API, pinning.NewPinner(options.PinningServices, options.API))
 	if err != nil {
 		return nil, err
 	}
 
-	urlDownloadStorage, err := urldownload.NewStorage(cm)
+	urlDownloadStorage, err := urldownload.NewStorage(cm, options.URLDownload)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +115,62 @@ func NewStandardStorageProvider(
 
 	inlineStorage := inline.NewStorage()
 
-	var useIPFSDriver storage.Storage = ipfsAPICopyStorage
+	s3Storage, err := s3.NewStorage(cm, options.S3)
+	if err != nil {
+		return nil, err
+	}
+
+	gitStorage, err := git.NewStorage(cm)
+	if err != nil {
+		return nil, err
+	}
+
+	azureBlobStorage, err := azureblob.NewStorage(cm, options.AzureBlob)
+	if err != nil {
+		return nil, err
+	}
+
+	gcsStorage, err := gcs.NewStorage(cm, options.GCS)
+	if err != nil {
+		return nil, err
+	}
+
+	localDirectoryStorage, err := localdirectory.NewStorage(cm, options.LocalDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	bitTorrentStorage, err := bittorrent.NewStorage(cm, options.BitTorrent)
+	if err != nil {
+		return nil, err
+	}
+
+	filecoinStorage, err := filecoin.NewStorage(cm, options.Filecoin)
+	if err != nil {
+		return nil, err
+	}
+
+	extractor, err := extract.New(cm, options.Extract)
+	if err != nil {
+		return nil, err
+	}
+
+	storageCache := options.StorageCache
+	if storageCache == nil {
+		storageCache, err = cache.New(cm, options.Cache)
+		if err != nil {
+			return nil, err
+		}
+	}
+	mounter, err := mount.New(cm, options.API, options.IPFSMount)
+	if err != nil {
+		return nil, err
+	}
+	// only providers that download into volumes of their own are cached, and
+	// inputs that are mounted lazily are never downloaded
+	cachedIPFSStorage := mounter.Wrap(storageCache.Wrap(ipfsAPICopyStorage))
+
+	var useIPFSDriver = cachedIPFSStorage
 
 	// if we are using a FilecoinUnsealedPath then construct a combo
 	// driver that will give preference to the filecoin unsealed driver
@@ -66,22 +181,22 @@ func NewStandardStorageProvider(
 			func(ctx context.Context) ([]storage.Storage, error) {
 				return []storage.Storage{
 					filecoinUnsealedStorage,
-					ipfsAPICopyStorage,
+					cachedIPFSStorage,
 				}, nil
 			},
 			func(ctx context.Context, spec model.StorageSpec) (storage.Storage, error) {
 				filecoinUnsealedHasCid, err := filecoinUnsealedStorage.HasStorageLocally(ctx, spec)
 				if err != nil {
-					return ipfsAPICopyStorage, err
+					return cachedIPFSStorage, err
 				}
 				if filecoinUnsealedHasCid {
 					return filecoinUnsealedStorage, nil
 				} else {
-					return ipfsAPICopyStorage, nil
+					return cachedIPFSStorage, nil
 				}
 			},
 			func(ctx context.Context) (storage.Storage, error) {
-				return ipfsAPICopyStorage, nil
+				return cachedIPFSStorage, nil
 			},
 		)
 
@@ -92,12 +207,21 @@ func NewStandardStorageProvider(
 		useIPFSDriver = comboDriver
 	}
 
-	return model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{
+	// archives are extracted from whichever storage prepared them
+	return extractor.Wrap(model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{
 		model.StorageSourceIPFS:             tracing.Wrap(useIPFSDriver),
 		model.StorageSourceURLDownload:      tracing.Wrap(urlDownloadStorage),
 		model.StorageSourceFilecoinUnsealed: tracing.Wrap(filecoinUnsealedStorage),
 		model.StorageSourceInline:           tracing.Wrap(inlineStorage),
-	}), nil
+		model.StorageSourceS3:               tracing.Wrap(storageCache.Wrap(s3Storage)),
+		model.StorageSourceGit:              tracing.Wrap(gitStorage),
+		model.StorageSourceAzureBlob:        tracing.Wrap(azureBlobStorage),
+		model.StorageSourceGCS:              tracing.Wrap(gcsStorage),
+		model.StorageSourceHuggingFace:      tracing.Wrap(huggingface.NewStorage(options.HuggingFace)),
+		model.StorageSourceLocalDirectory:   tracing.Wrap(localDirectoryStorage),
+		model.StorageSourceBitTorrent:       tracing.Wrap(bitTorrentStorage),
+		model.StorageSourceFilecoin:         tracing.Wrap(filecoinStorage),
+	})), nil
 }
 
 func NewNoopStorageProvider(
@@ -118,8 +242,16 @@ func NewStandardExecutorProvider(
 	if err != nil {
 		return nil, err
 	}
+	storageProvider = executorOptions.LocalData.Wrap(executorOptions.Prefetcher.Wrap(storageProvider))
 
-	dockerExecutor, err := docker.NewExecutor(ctx, cm, executorOptions.DockerID, storageProvider)
+	var dockerExecutor executor.Executor
+	if executorOptions.Kubernetes.Enabled {
+		dockerExecutor, err = kubernetes.NewExecutor(ctx, cm, executorOptions.DockerID, storageProvider, executorOptions.Kubernetes)
+	} else if executorOptions.Containerd.Address != "" {
+		dockerExecutor, err = containerd.NewExecutor(ctx, cm, executorOptions.DockerID, storageProvider, executorOptions.Containerd)
+	} else {
+		dockerExecutor, err = docker.NewExecutor(ctx, cm, executorOptions.DockerID, storageProvider, executorOptions.Docker)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -129,9 +261,15 @@ func NewStandardExecutorProvider(
 		return nil, err
 	}
 
+	apptainerExecutor, err := apptainer.NewExecutor(ctx, storageProvider, executorOptions.Apptainer)
+	if err != nil {
+		return nil, err
+	}
+
 	executors := model.NewMappedProvider(map[model.Engine]executor.Executor{
-		model.EngineDocker: dockerExecutor,
-		model.EngineWasm:   wasmExecutor,
+		model.EngineDocker:    dockerExecutor,
+		model.EngineWasm:      wasmExecutor,
+		model.EngineApptainer: apptainerExecutor,
 	})
 
 	// language executors wrap other executors, so pass them a reference to all
@@ -148,6 +286,43 @@ func NewStandardExecutorProvider(
 	}
 	executors.Add(model.EnginePythonWasm, exPythonWasm)
 
+	if executorOptions.SSH.Host != "" {
+		// python jobs aren't run, as their virtualenvs are built on the compute node
+		exSSH, err := ssh.NewExecutor(ctx, storageProvider, executorOptions.SSH)
+		if err != nil {
+			return nil, err
+		}
+		executors.Add(model.EngineProcess, exSSH)
+	} else if executorOptions.Process.Enabled {
+		exProcess, err := process.NewExecutor(ctx, storageProvider, executorOptions.Process)
+		if err != nil {
+			return nil, err
+		}
+		executors.Add(model.EngineProcess, exProcess)
+
+		exPython, err := python.NewExecutor(executors, executorOptions.Python)
+		if err != nil {
+			return nil, err
+		}
+		executors.Add(model.EnginePython, exPython)
+	}
+
+	if executorOptions.Plugin.Directory != "" {
+		exPlugin, err := plugin.NewExecutor(ctx, cm, storageProvider, executorOptions.Plugin)
+		if err != nil {
+			return nil, err
+		}
+		executors.Add(model.EnginePlugin, exPlugin)
+	}
+
+	if executorOptions.DuckDB.Enabled {
+		exDuckDB, err := duckdb.NewExecutor(ctx, storageProvider, executorOptions.DuckDB)
+		if err != nil {
+			return nil, err
+		}
+		executors.Add(model.EngineDuckDB, exDuckDB)
+	}
+
 	return executors, nil
 }
 
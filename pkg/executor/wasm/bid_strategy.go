@@ -0,0 +1,144 @@
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/logger"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+const (
+	// DefaultBidStrategyTimeout is how long a bid strategy module can take to decide.
+	DefaultBidStrategyTimeout = 10 * time.Second
+
+	// bidStrategyMemoryLimitPages limits the memory of bid strategy modules to 64MiB, in 64KiB WASM pages.
+	bidStrategyMemoryLimitPages = 1024
+)
+
+type BidStrategyParams struct {
+	// Path is the WASM module that decides whether to bid on jobs
+	Path string
+	// Timeout is how long the module can take to decide before the job is rejected
+	Timeout time.Duration
+}
+
+// BidStrategy runs an operator's WASM module to decide whether to bid on jobs,
+// so that custom policies don't require rebuilding the node. The module is run
+// as a WASI command like the exec probe: it reads the job selection probe data
+// as JSON from stdin, or from the BACALHAU_JOB_SELECTION_PROBE_DATA variable,
+// and exits with zero to bid or non-zero to reject the job, for the reason it
+// wrote to stdout if any. The module has no filesystem or network access.
+type BidStrategy struct {
+	name    string
+	runtime wazero.Runtime
+	module  wazero.CompiledModule
+	timeout time.Duration
+	// calls names each instance of the module uniquely, as the runtime requires
+	calls atomic.Uint64
+}
+
+func NewBidStrategy(ctx context.Context, params BidStrategyParams) (*BidStrategy, error) {
+	timeout := params.Timeout
+	if timeout <= 0 {
+		timeout = DefaultBidStrategyTimeout
+	}
+
+	config := wazero.NewRuntimeConfig().
+		WithCloseOnContextDone(true).
+		WithMemoryLimitPages(bidStrategyMemoryLimitPages)
+	runtime := tracedRuntime{wazero.NewRuntimeWithConfig(ctx, config)}
+
+	module, err := LoadModule(ctx, runtime, params.Path)
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to load the bid strategy module %s: %w", params.Path, err)
+	}
+	if err = ValidateModuleAsEntryPoint(module, "_start"); err != nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("bid strategy module %s is not a WASI command: %w", params.Path, err)
+	}
+	if _, err = wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		_ = runtime.Close(ctx)
+		return nil, err
+	}
+
+	return &BidStrategy{
+		name:    strings.TrimSuffix(filepath.Base(params.Path), filepath.Ext(params.Path)),
+		runtime: runtime,
+		module:  module,
+		timeout: timeout,
+	}, nil
+}
+
+func (s *BidStrategy) ShouldBid(ctx context.Context, request bidstrategy.BidStrategyRequest) (bidstrategy.BidStrategyResponse, error) {
+	data, err := model.JSONMarshalWithMax(bidstrategy.GetJobSelectionPolicyProbeData(request))
+	if err != nil {
+		return bidstrategy.BidStrategyResponse{},
+			fmt.Errorf("WASM BidStrategy: error marshaling job selection policy probe data: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	config := wazero.NewModuleConfig().
+		WithName(fmt.Sprintf("%s-%d", s.name, s.calls.Add(1))).
+		WithArgs(s.name).
+		WithEnv("BACALHAU_JOB_SELECTION_PROBE_DATA", string(data)).
+		WithStdin(bytes.NewReader(data)).
+		WithStdout(stdout).
+		WithStderr(stderr)
+
+	// the module runs its _start function when instantiated, which exits with the decision
+	exitCode := 0
+	instance, err := s.runtime.InstantiateModule(ctx, s.module, config)
+	var errExit *sys.ExitError
+	if errors.As(err, &errExit) {
+		exitCode = int(errExit.ExitCode())
+	} else if err != nil {
+		logger.LogStream(ctx, stderr)
+		log.Ctx(ctx).Debug().Err(err).Str("Module", s.name).Msg("The job selection WASM module failed")
+		return bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason:    fmt.Sprintf("WASM module %s failed to decide", s.name),
+		}, nil
+	}
+	if instance != nil {
+		_ = instance.Close(ctx)
+	}
+
+	if exitCode == 0 {
+		return bidstrategy.NewShouldBidResponse(), nil
+	}
+	reason := strings.TrimSpace(stdout.String())
+	if reason == "" {
+		reason = fmt.Sprintf("WASM module %s returned non-zero exit code %d", s.name, exitCode)
+	}
+	return bidstrategy.BidStrategyResponse{ShouldBid: false, Reason: reason}, nil
+}
+
+func (s *BidStrategy) ShouldBidBasedOnUsage(
+	context.Context, bidstrategy.BidStrategyRequest, model.ResourceUsageData) (bidstrategy.BidStrategyResponse, error) {
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+// Close releases the runtime of the module.
+func (s *BidStrategy) Close(ctx context.Context) error {
+	return s.runtime.Close(ctx)
+}
+
+// compile-time interface check
+var _ bidstrategy.BidStrategy = (*BidStrategy)(nil)
@@ -0,0 +1,68 @@
+//go:build unit || !integration
+
+package wasm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// rejectModule is a WASI command that exits with code 3, assembled by hand as
+// there is no toolchain for WASM modules in the tests.
+var rejectModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// types: (i32) -> () and () -> ()
+	0x01, 0x08, 0x02, 0x60, 0x01, 0x7f, 0x00, 0x60, 0x00, 0x00,
+	// imports: wasi_snapshot_preview1.proc_exit
+	0x02, 0x24, 0x01,
+	0x16, 'w', 'a', 's', 'i', '_', 's', 'n', 'a', 'p', 's', 'h', 'o', 't', '_', 'p', 'r', 'e', 'v', 'i', 'e', 'w', '1',
+	0x09, 'p', 'r', 'o', 'c', '_', 'e', 'x', 'i', 't',
+	0x00, 0x00,
+	// functions: _start
+	0x03, 0x02, 0x01, 0x01,
+	// exports: _start
+	0x07, 0x0a, 0x01, 0x06, '_', 's', 't', 'a', 'r', 't', 0x00, 0x01,
+	// code: proc_exit(3)
+	0x0a, 0x08, 0x01, 0x06, 0x00, 0x41, 0x03, 0x10, 0x00, 0x0b,
+}
+
+func TestBidStrategy(t *testing.T) {
+	ctx := context.Background()
+	request := bidstrategy.BidStrategyRequest{
+		NodeID: "node",
+		Job:    model.Job{Metadata: model.Metadata{ID: "job"}},
+	}
+
+	accept, err := NewBidStrategy(ctx, BidStrategyParams{Path: "../../../testdata/wasm/exit_code/main.wasm"})
+	require.NoError(t, err)
+	defer accept.Close(ctx)
+
+	// the module can be run for each job
+	for i := 0; i < 2; i++ {
+		response, err := accept.ShouldBid(ctx, request)
+		require.NoError(t, err)
+		require.True(t, response.ShouldBid)
+	}
+
+	path := filepath.Join(t.TempDir(), "reject.wasm")
+	require.NoError(t, os.WriteFile(path, rejectModule, 0644))
+	reject, err := NewBidStrategy(ctx, BidStrategyParams{Path: path})
+	require.NoError(t, err)
+	defer reject.Close(ctx)
+
+	response, err := reject.ShouldBid(ctx, request)
+	require.NoError(t, err)
+	require.False(t, response.ShouldBid)
+	require.Equal(t, "WASM module reject returned non-zero exit code 3", response.Reason)
+}
+
+func TestBidStrategyRequiresModule(t *testing.T) {
+	_, err := NewBidStrategy(context.Background(), BidStrategyParams{Path: filepath.Join(t.TempDir(), "missing.wasm")})
+	require.Error(t, err)
+}
@@ -31,11 +31,13 @@ import (
 
 type Executor struct {
 	StorageProvider storage.StorageProvider
+	outputs         *executor.OutputStreams
 }
 
 func NewExecutor(_ context.Context, storageProvider storage.StorageProvider) (*Executor, error) {
 	return &Executor{
 		StorageProvider: storageProvider,
+		outputs:         executor.NewOutputStreams(),
 	}, nil
 }
 
@@ -181,13 +183,15 @@ func (e *Executor) Run(ctx context.Context, job model.Job, jobResultsDir string)
 	// later. Finally, add the filesystem which contains our input and output.
 	stdout := new(bytes.Buffer)
 	stderr := new(bytes.Buffer)
+	streamStdout, streamStderr, streamDone := e.outputs.Start(job.ID())
+	defer streamDone()
 
 	args := append([]string{module.Name()}, job.Spec.Wasm.Parameters...)
 
 	config := wazero.NewModuleConfig().
 		WithStartFunctions().
-		WithStdout(stdout).
-		WithStderr(stderr).
+		WithStdout(io.MultiWriter(stdout, streamStdout)).
+		WithStderr(io.MultiWriter(stderr, streamStderr)).
 		WithArgs(args...).
 		WithFS(rootFs)
 
@@ -253,8 +257,8 @@ func (e *Executor) Run(ctx context.Context, job model.Job, jobResultsDir string)
 	return executor.WriteJobResults(jobResultsDir, stdout, stderr, exitCode, wasmErr)
 }
 
-func (e *Executor) GetOutputStream(context.Context, model.Job) (io.ReadCloser, error) {
-	return nil, fmt.Errorf("not implemented for wasm executor")
+func (e *Executor) GetOutputStream(ctx context.Context, job model.Job, follow bool) (io.ReadCloser, error) {
+	return e.outputs.Stream(ctx, job.ID(), follow)
 }
 
 // Compile-time check that Executor implements the Executor interface.
@@ -1,6 +1,4 @@
-//go:build integration
-
-package devstack
+package car
 
 import (
 	"bytes"
@@ -12,6 +10,7 @@ import (
 	"path"
 	"path/filepath"
 
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-unixfsnode"
 	"github.com/ipfs/go-unixfsnode/data"
@@ -27,13 +26,75 @@ import (
 
 var ErrNotDir = fmt.Errorf("not a directory")
 
+// Limits bounds what a car extracts to. A block can be linked any number of
+// times, so a small car can extract to far more than its own size. Zero values
+// are unlimited.
+type Limits struct {
+	// MaxSize is the most bytes the files of the car may extract to.
+	MaxSize uint64
+	// MaxFiles is the most files, directories and symlinks the car may have.
+	MaxFiles int
+}
+
 // ExtractCar pulls files and directories out of a car
 func ExtractCar(ctx context.Context, file string, outputDir string) error {
+	return ExtractCarWithLimits(ctx, file, outputDir, Limits{})
+}
+
+// ExtractCarWithLimits pulls files and directories out of a car, failing once
+// more than the limits have been extracted.
+func ExtractCarWithLimits(ctx context.Context, file string, outputDir string, limits Limits) error {
 	bs, err := blockstore.OpenReadOnly(file)
 	if err != nil {
 		return err
 	}
+	defer closer.CloseWithLogOnError("car", bs)
+	return extract(ctx, bs, outputDir, &budget{limits: limits})
+}
+
+// ExtractCarBytes pulls files and directories out of a car that is held in
+// memory, e.g. because it was sent inline with a job.
+func ExtractCarBytes(ctx context.Context, data []byte, outputDir string) error {
+	bs, err := blockstore.NewReadOnly(bytes.NewReader(data), nil)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError("car", bs)
+	return extract(ctx, bs, outputDir, &budget{})
+}
+
+// budget counts what has been extracted against the limits.
+type budget struct {
+	limits Limits
+	size   uint64
+	files  int
+}
+
+func (b *budget) entry() error {
+	b.files++
+	if b.limits.MaxFiles > 0 && b.files > b.limits.MaxFiles {
+		return fmt.Errorf("car has more than %d entries", b.limits.MaxFiles)
+	}
+	return nil
+}
 
+func (b *budget) copy(w io.Writer, r io.Reader) error {
+	if b.limits.MaxSize == 0 {
+		_, err := io.Copy(w, r)
+		return err
+	}
+	n, err := io.CopyN(w, r, int64(b.limits.MaxSize-b.size)+1)
+	b.size += uint64(n)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if b.size > b.limits.MaxSize {
+		return fmt.Errorf("car extracts to more than %d bytes", b.limits.MaxSize)
+	}
+	return nil
+}
+
+func extract(ctx context.Context, bs *blockstore.ReadOnly, outputDir string, b *budget) error {
 	ls := cidlink.DefaultLinkSystem()
 	ls.TrustedStorage = true
 	ls.StorageReadOpener = func(_ ipld.LinkContext, l ipld.Link) (io.Reader, error) {
@@ -54,7 +115,7 @@ func ExtractCar(ctx context.Context, file string, outputDir string) error {
 	}
 
 	for _, root := range roots {
-		if err := extractRoot(ctx, &ls, root, outputDir); err != nil {
+		if err := extractRoot(ctx, &ls, root, outputDir, b); err != nil {
 			return err
 		}
 	}
@@ -62,9 +123,22 @@ func ExtractCar(ctx context.Context, file string, outputDir string) error {
 	return nil
 }
 
-func extractRoot(ctx context.Context, ls *ipld.LinkSystem, root cid.Cid, outputDir string) error {
+func extractRoot(ctx context.Context, ls *ipld.LinkSystem, root cid.Cid, outputDir string, b *budget) error {
 	if root.Prefix().Codec == cid.Raw {
-		return nil
+		// a file small enough to be a single raw block, which has no name
+		raw, err := ls.LoadRaw(ipld.LinkContext{}, cidlink.Link{Cid: root})
+		if err != nil {
+			return err
+		}
+		if err = b.entry(); err != nil {
+			return err
+		}
+		f, err := os.Create(filepath.Join(outputDir, "unknown"))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return b.copy(f, bytes.NewReader(raw))
 	}
 
 	pbn, err := ls.Load(ipld.LinkContext{}, cidlink.Link{Cid: root}, dagpb.Type.PBNode)
@@ -87,7 +161,7 @@ func extractRoot(ctx context.Context, ls *ipld.LinkSystem, root cid.Cid, outputD
 			return err
 		}
 	}
-	if err := extractDir(ctx, ls, ufn, outputResolvedDir, "/"); err != nil {
+	if err := extractDir(ctx, ls, ufn, outputResolvedDir, "/", b); err != nil {
 		if !errors.Is(err, ErrNotDir) {
 			return fmt.Errorf("%s: %w", root, err)
 		}
@@ -104,7 +178,10 @@ func extractRoot(ctx context.Context, ls *ipld.LinkSystem, root cid.Cid, outputD
 			return err
 		}
 		if ufsNode.DataType.Int() == data.Data_File || ufsNode.DataType.Int() == data.Data_Raw {
-			if err := extractFile(ctx, ls, pbnode, filepath.Join(outputResolvedDir, "unknown")); err != nil {
+			if err := b.entry(); err != nil {
+				return err
+			}
+			if err := extractFile(ctx, ls, pbnode, filepath.Join(outputResolvedDir, "unknown"), b); err != nil {
 				return err
 			}
 		}
@@ -132,7 +209,7 @@ func resolvePath(root, pth string) (string, error) {
 	return joined, nil
 }
 
-func extractDir(ctx context.Context, ls *ipld.LinkSystem, n ipld.Node, outputRoot, outputPath string) error {
+func extractDir(ctx context.Context, ls *ipld.LinkSystem, n ipld.Node, outputRoot, outputPath string, b *budget) error {
 	dirPath, err := resolvePath(outputRoot, outputPath)
 	if err != nil {
 		return err
@@ -153,6 +230,9 @@ func extractDir(ctx context.Context, ls *ipld.LinkSystem, n ipld.Node, outputRoo
 			if err != nil {
 				return err
 			}
+			if err = b.entry(); err != nil {
+				return err
+			}
 			nextRes, err := resolvePath(outputRoot, path.Join(outputPath, ks))
 			if err != nil {
 				return err
@@ -172,7 +252,7 @@ func extractDir(ctx context.Context, ls *ipld.LinkSystem, n ipld.Node, outputRoo
 			}
 			// degenerate files are handled here.
 			if dest.Kind() == ipld.Kind_Bytes {
-				if err := extractFile(ctx, ls, dest, nextRes); err != nil {
+				if err := extractFile(ctx, ls, dest, nextRes, b); err != nil {
 					return err
 				}
 				continue
@@ -205,11 +285,11 @@ func extractDir(ctx context.Context, ls *ipld.LinkSystem, n ipld.Node, outputRoo
 					return err
 				}
 
-				if err := extractDir(ctx, ls, ufn, outputRoot, path.Join(outputPath, ks)); err != nil {
+				if err := extractDir(ctx, ls, ufn, outputRoot, path.Join(outputPath, ks), b); err != nil {
 					return err
 				}
 			} else if ufsNode.DataType.Int() == data.Data_File || ufsNode.DataType.Int() == data.Data_Raw {
-				if err := extractFile(ctx, ls, pbnode, nextRes); err != nil {
+				if err := extractFile(ctx, ls, pbnode, nextRes, b); err != nil {
 					return err
 				}
 			} else if ufsNode.DataType.Int() == data.Data_Symlink {
@@ -224,7 +304,7 @@ func extractDir(ctx context.Context, ls *ipld.LinkSystem, n ipld.Node, outputRoo
 	return ErrNotDir
 }
 
-func extractFile(ctx context.Context, ls *ipld.LinkSystem, n ipld.Node, outputName string) error {
+func extractFile(ctx context.Context, ls *ipld.LinkSystem, n ipld.Node, outputName string, b *budget) error {
 	node, err := file.NewUnixFSFile(ctx, n, ls)
 	if err != nil {
 		return err
@@ -239,7 +319,5 @@ func extractFile(ctx context.Context, ls *ipld.LinkSystem, n ipld.Node, outputNa
 		return err
 	}
 	defer f.Close()
-	_, err = io.Copy(f, nlr)
-
-	return err
+	return b.copy(f, nlr)
 }
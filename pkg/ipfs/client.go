@@ -188,6 +188,45 @@ func (cl Client) Put(ctx context.Context, inputPath string) (string, error) {
 	return cid, nil
 }
 
+// PublishName points the IPNS name of the key with the name at the CID, and
+// returns the IPNS name. The key is generated in the keystore of the node the
+// first time it is published, so the IPNS name stays the same after that.
+func (cl Client) PublishName(ctx context.Context, keyName, cid string) (string, error) {
+	hasKey := func() (bool, error) {
+		keys, err := cl.API.Key().List(ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to list IPNS keys: %w", err)
+		}
+		for _, key := range keys {
+			if key.Name() == keyName {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	found, err := hasKey()
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		if _, err = cl.API.Key().Generate(ctx, keyName); err != nil {
+			// another execution may have generated it at the same time
+			if found, _ = hasKey(); !found {
+				return "", fmt.Errorf("failed to generate IPNS key %s: %w", keyName, err)
+			}
+		}
+	}
+
+	entry, err := cl.API.Name().Publish(ctx, icorepath.New(cid),
+		icoreoptions.Name.Key(keyName),
+		icoreoptions.Name.AllowOffline(true),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to publish IPNS name of key %s: %w", keyName, err)
+	}
+	return entry.Name(), nil
+}
+
 type IPLDType int
 
 const (
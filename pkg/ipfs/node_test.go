@@ -100,6 +100,37 @@ func (s *NodeSuite) TestFunctionality() {
 }
 
 // a normal test function and pass our suite to suite.Run
+func (s *NodeSuite) TestPublishName() {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(10*time.Second))
+	defer cancel()
+
+	cm := system.NewCleanupManager()
+	s.T().Cleanup(func() {
+		cm.Cleanup(context.Background())
+	})
+
+	n, err := NewLocalNode(ctx, cm, nil)
+	s.Require().NoError(err)
+	cl := n.Client()
+
+	var names []string
+	for _, content := range []string{"first", "second"} {
+		filePath := filepath.Join(s.T().TempDir(), "results.txt")
+		s.Require().NoError(os.WriteFile(filePath, []byte(content), 0644))
+		cid, err := cl.Put(ctx, filePath)
+		s.Require().NoError(err)
+
+		name, err := cl.PublishName(ctx, "results", cid)
+		s.Require().NoError(err)
+		names = append(names, name)
+
+		resolved, err := cl.API.Name().Resolve(ctx, name)
+		s.Require().NoError(err)
+		s.Require().Equal("/ipfs/"+cid, resolved.String(), "the name points at the latest results")
+	}
+	s.Require().Equal(names[0], names[1], "the name stays the same")
+}
+
 func TestNodeSuite(t *testing.T) {
 	suite.Run(t, new(NodeSuite))
 }
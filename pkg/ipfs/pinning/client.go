@@ -0,0 +1,150 @@
+// Package pinning pins content to remote pinning services, such as Pinata or
+// web3.storage, through the IPFS Pinning Service API, so that published
+// results and uploaded inputs stay available once the nodes that added them
+// have garbage collected them or gone away.
+//
+// See https://ipfs.github.io/pinning-services-api-spec/ for the API.
+package pinning
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+)
+
+// Status is the status of a pin request.
+type Status string
+
+const (
+	StatusQueued  Status = "queued"
+	StatusPinning Status = "pinning"
+	StatusPinned  Status = "pinned"
+	StatusFailed  Status = "failed"
+)
+
+// Pin is content to pin.
+type Pin struct {
+	CID     string            `json:"cid"`
+	Name    string            `json:"name,omitempty"`
+	Origins []string          `json:"origins,omitempty"`
+	Meta    map[string]string `json:"meta,omitempty"`
+}
+
+// PinStatus is a pin request as the service knows it.
+type PinStatus struct {
+	RequestID string   `json:"requestid"`
+	Status    Status   `json:"status"`
+	Pin       Pin      `json:"pin"`
+	Delegates []string `json:"delegates"`
+}
+
+type pinResults struct {
+	Count   int         `json:"count"`
+	Results []PinStatus `json:"results"`
+}
+
+// Service is a remote pinning service that a node pins content to.
+type Service struct {
+	// Endpoint is the base URL of the API, e.g. https://api.pinata.cloud/psa
+	Endpoint string
+	// Token is the access token of the account to pin content to
+	Token string
+}
+
+// ParseService returns the service of a string like ENDPOINT=TOKEN.
+func ParseService(value string) (Service, error) {
+	endpoint, token, ok := strings.Cut(value, "=")
+	if !ok || token == "" {
+		return Service{}, fmt.Errorf("pinning service %q must be given as ENDPOINT=TOKEN", value)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Service{}, fmt.Errorf("pinning service endpoint %q must be an http(s) URL", endpoint)
+	}
+	return Service{Endpoint: strings.TrimSuffix(endpoint, "/"), Token: token}, nil
+}
+
+// Client talks to a single pinning service.
+type Client struct {
+	service Service
+	client  *http.Client
+}
+
+func NewClient(service Service) *Client {
+	return &Client{
+		service: service,
+		client:  http.DefaultClient,
+	}
+}
+
+// Add requests that the service pins the content.
+func (c *Client) Add(ctx context.Context, pin Pin) (PinStatus, error) {
+	body, err := json.Marshal(pin)
+	if err != nil {
+		return PinStatus{}, err
+	}
+	var status PinStatus
+	if err = c.do(ctx, http.MethodPost, "/pins", body, http.StatusAccepted, &status); err != nil {
+		return PinStatus{}, err
+	}
+	return status, nil
+}
+
+// Pinned returns whether the service has pinned the content already, or is
+// going to.
+func (c *Client) Pinned(ctx context.Context, cid string) (bool, error) {
+	query := url.Values{
+		"cid":    {cid},
+		"status": {strings.Join([]string{string(StatusQueued), string(StatusPinning), string(StatusPinned)}, ",")},
+		"limit":  {"1"},
+	}
+	var results pinResults
+	if err := c.do(ctx, http.MethodGet, "/pins?"+query.Encode(), nil, http.StatusOK, &results); err != nil {
+		return false, err
+	}
+	return results.Count > 0, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body []byte, expected int, result any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.service.Endpoint+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.service.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("pinning service %s: %w", c.service.Endpoint, err)
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "pinning-response", res.Body)
+
+	if res.StatusCode != expected {
+		var failure struct {
+			Error struct {
+				Reason  string `json:"reason"`
+				Details string `json:"details"`
+			} `json:"error"`
+		}
+		if json.NewDecoder(res.Body).Decode(&failure) == nil && failure.Error.Reason != "" {
+			return fmt.Errorf("pinning service %s: %s: %s %s",
+				c.service.Endpoint, res.Status, failure.Error.Reason, failure.Error.Details)
+		}
+		return fmt.Errorf("pinning service %s: unexpected status %s", c.service.Endpoint, res.Status)
+	}
+	if err = json.NewDecoder(res.Body).Decode(result); err != nil {
+		return fmt.Errorf("pinning service %s: invalid response: %w", c.service.Endpoint, err)
+	}
+	return nil
+}
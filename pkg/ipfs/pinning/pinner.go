@@ -0,0 +1,76 @@
+package pinning
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/rs/zerolog/log"
+	"go.uber.org/multierr"
+)
+
+// Pinner pins content to each of the pinning services of a node.
+type Pinner struct {
+	clients []*Client
+	// returns the addresses that services can fetch content from
+	origins func(context.Context) ([]string, error)
+}
+
+// NewPinner returns a pinner of the services, which tells them to fetch
+// content from the IPFS node of the client. It returns nil if there are no
+// services.
+func NewPinner(services []Service, cl ipfs.Client) *Pinner {
+	if len(services) == 0 {
+		return nil
+	}
+	clients := make([]*Client, 0, len(services))
+	for _, service := range services {
+		clients = append(clients, NewClient(service))
+	}
+	return &Pinner{clients: clients, origins: cl.SwarmAddresses}
+}
+
+// Pin pins the content to each service that hasn't got it pinned already. The
+// services pin content in the background, so it may not be pinned yet once
+// Pin returns. A nil pinner pins nothing.
+func (p *Pinner) Pin(ctx context.Context, cid, name string, meta map[string]string) error {
+	if p == nil {
+		return nil
+	}
+
+	pin := Pin{CID: cid, Name: name, Meta: meta}
+	origins, err := p.origins(ctx)
+	if err != nil {
+		// services can still find the content through the DHT
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to find origins of content to pin")
+	} else {
+		pin.Origins = origins
+	}
+
+	var errs error
+	for _, client := range p.clients {
+		pinned, err := client.Pinned(ctx, cid)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		if pinned {
+			continue
+		}
+		status, err := client.Add(ctx, pin)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		if status.Status == StatusFailed {
+			errs = multierr.Append(errs, fmt.Errorf("pinning service %s failed to pin %s", client.service.Endpoint, cid))
+			continue
+		}
+		log.Ctx(ctx).Debug().
+			Str("cid", cid).
+			Str("endpoint", client.service.Endpoint).
+			Str("request", status.RequestID).
+			Msg("Requested remote pin")
+	}
+	return errs
+}
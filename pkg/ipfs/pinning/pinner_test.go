@@ -0,0 +1,128 @@
+//go:build unit || !integration
+
+package pinning
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/stretchr/testify/require"
+)
+
+// pinningService keeps the pins that it is asked for, as a pinning service that
+// pins them straight away.
+type pinningService struct {
+	mu   sync.Mutex
+	pins map[string]Pin
+}
+
+func newPinningService(t *testing.T, token string) (*pinningService, Service) {
+	s := &pinningService{pins: map[string]Pin{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"reason":"UNAUTHORIZED","details":"bad token"}}`))
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/pins":
+			var pin Pin
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&pin))
+			s.pins[pin.CID] = pin
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(PinStatus{RequestID: pin.CID, Status: StatusPinned, Pin: pin})
+		case r.Method == http.MethodGet && r.URL.Path == "/pins":
+			results := pinResults{Results: []PinStatus{}}
+			if pin, ok := s.pins[r.URL.Query().Get("cid")]; ok {
+				results.Count = 1
+				results.Results = append(results.Results, PinStatus{RequestID: pin.CID, Status: StatusPinned, Pin: pin})
+			}
+			_ = json.NewEncoder(w).Encode(results)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return s, Service{Endpoint: server.URL, Token: token}
+}
+
+func newTestPinner(origins []string, services ...Service) *Pinner {
+	p := NewPinner(services, ipfs.Client{})
+	p.origins = func(context.Context) ([]string, error) {
+		if origins == nil {
+			return nil, errors.New("no IPFS node")
+		}
+		return origins, nil
+	}
+	return p
+}
+
+func TestPinsToEachService(t *testing.T) {
+	ctx := context.Background()
+	first, firstService := newPinningService(t, "first-token")
+	second, secondService := newPinningService(t, "second-token")
+	origins := []string{"/ip4/127.0.0.1/tcp/4001/p2p/QmPeer"}
+	p := newTestPinner(origins, firstService, secondService)
+
+	require.NoError(t, p.Pin(ctx, "QmResults", "job-results", map[string]string{"job": "123"}))
+	for _, s := range []*pinningService{first, second} {
+		require.Equal(t, Pin{
+			CID:     "QmResults",
+			Name:    "job-results",
+			Origins: origins,
+			Meta:    map[string]string{"job": "123"},
+		}, s.pins["QmResults"])
+	}
+
+	// pinned content isn't pinned again
+	delete(first.pins, "QmResults")
+	second.pins["QmResults"] = Pin{CID: "QmResults", Name: "already pinned"}
+	require.NoError(t, p.Pin(ctx, "QmResults", "job-results", nil))
+	require.Equal(t, "job-results", first.pins["QmResults"].Name)
+	require.Equal(t, "already pinned", second.pins["QmResults"].Name)
+}
+
+func TestPinsWithoutOrigins(t *testing.T) {
+	s, service := newPinningService(t, "token")
+	p := newTestPinner(nil, service)
+
+	require.NoError(t, p.Pin(context.Background(), "QmInput", "input", nil))
+	require.Contains(t, s.pins, "QmInput")
+	require.Empty(t, s.pins["QmInput"].Origins)
+}
+
+func TestPinFailsForEachFailingService(t *testing.T) {
+	s, service := newPinningService(t, "token")
+	_, other := newPinningService(t, "other-token")
+	other.Token = "wrong-token"
+	p := newTestPinner([]string{}, other, service)
+
+	err := p.Pin(context.Background(), "QmResults", "job-results", nil)
+	require.ErrorContains(t, err, "UNAUTHORIZED")
+	require.Contains(t, s.pins, "QmResults", "pinned to the other services nonetheless")
+}
+
+func TestNilPinner(t *testing.T) {
+	var p *Pinner
+	require.Nil(t, NewPinner(nil, ipfs.Client{}))
+	require.NoError(t, p.Pin(context.Background(), "QmResults", "job-results", nil))
+}
+
+func TestParseService(t *testing.T) {
+	service, err := ParseService("https://api.pinata.cloud/psa/=eyJhbGciOiJIUzI1NiJ9.e30.sig")
+	require.NoError(t, err)
+	require.Equal(t, Service{Endpoint: "https://api.pinata.cloud/psa", Token: "eyJhbGciOiJIUzI1NiJ9.e30.sig"}, service)
+
+	for _, value := range []string{"https://api.pinata.cloud/psa", "https://api.pinata.cloud/psa=", "api.pinata.cloud=token"} {
+		_, err = ParseService(value)
+		require.Error(t, err, value)
+	}
+}
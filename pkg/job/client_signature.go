@@ -0,0 +1,122 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/google/uuid"
+)
+
+// signedJobNamespace is the namespace of the IDs of the jobs that are derived
+// from the payloads their clients signed.
+var signedJobNamespace = uuid.MustParse("1b6b5b0e-6f0c-4b8e-9d36-3f3c1c7c5a10")
+
+// SignedJobID returns the ID of the job that a client submitted with the
+// signed payload. The payload has a nonce, so each payload is for one job,
+// and its signature can't be reused for another.
+func SignedJobID(payload json.RawMessage) string {
+	return uuid.NewSHA1(signedJobNamespace, payload).String()
+}
+
+// VerifyClientSignature returns an error unless the job was submitted with a
+// payload that the client of the job signed, so that the ClientID of the job
+// can be trusted, and the job is the one that the payload is for. The ID of
+// the job must be derived from the payload, and the spec of the job must be
+// the signed spec, with only what the requester is allowed to change changed.
+func VerifyClientSignature(j model.Job) error {
+	signature := j.Metadata.ClientSignature
+	if signature == nil {
+		return errors.New("job isn't signed by its client")
+	}
+	if err := system.Verify(signature.Payload, signature.Signature, signature.PublicKey); err != nil {
+		return fmt.Errorf("client's signature is invalid: %w", err)
+	}
+	ok, err := system.PublicKeyMatchesID(signature.PublicKey, j.Metadata.ClientID)
+	if err != nil {
+		return fmt.Errorf("error verifying client ID: %w", err)
+	}
+	if !ok {
+		return errors.New("client's public key does not match client ID")
+	}
+	var payload model.JobCreatePayload
+	if err = json.Unmarshal(signature.Payload, &payload); err != nil {
+		return fmt.Errorf("error unmarshalling signed payload: %w", err)
+	}
+	if payload.ClientID != j.Metadata.ClientID {
+		return fmt.Errorf("signed payload is from client %s rather than %s", payload.ClientID, j.Metadata.ClientID)
+	}
+	if payload.Nonce == "" {
+		return errors.New("signed payload has no nonce, so its signature could be reused for other jobs")
+	}
+	if id := SignedJobID(signature.Payload); j.Metadata.ID != id {
+		return fmt.Errorf("job %s isn't job %s that the signed payload is for", j.Metadata.ID, id)
+	}
+	signedSpec := model.Spec{}
+	if payload.Spec != nil {
+		signedSpec = *payload.Spec
+	}
+	return verifySignedSpec(signedSpec, j.Spec)
+}
+
+// verifySignedSpec returns an error if the spec of a job isn't the spec that
+// its client signed. The only changes that the requester may make are the
+// ones its transforms make: raising the timeout and the concurrency of the
+// job, which fills them in if they are unset. Clients resolve templates before
+// they sign, so nothing else is filled in.
+func verifySignedSpec(signed, spec model.Spec) error {
+	var signedJSON, specJSON map[string]any
+	for target, source := range map[*map[string]any]model.Spec{&signedJSON: signed, &specJSON: spec} {
+		raw, err := json.Marshal(source)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(raw, target); err != nil {
+			return err
+		}
+	}
+	return compareSignedJSON("", signedJSON, specJSON)
+}
+
+// requesterRaisedFields are the fields of a spec that the requester can raise
+// above the signed value.
+var requesterRaisedFields = map[string]bool{
+	"Timeout":          true,
+	"Deal.Concurrency": true,
+}
+
+func compareSignedJSON(prefix string, signed, spec map[string]any) error {
+	keys := make(map[string]bool, len(signed)+len(spec))
+	for key := range signed {
+		keys[key] = true
+	}
+	for key := range spec {
+		keys[key] = true
+	}
+	for key := range keys {
+		path := prefix + key
+		signedValue, specValue := signed[key], spec[key]
+		signedObject, isObject := signedValue.(map[string]any)
+		specObject, specIsObject := specValue.(map[string]any)
+		if isObject && specIsObject {
+			if err := compareSignedJSON(path+".", signedObject, specObject); err != nil {
+				return err
+			}
+			continue
+		}
+		if reflect.DeepEqual(signedValue, specValue) {
+			continue
+		}
+		// unset numbers are left out of the JSON of a spec
+		signedNumber, isNumber := signedValue.(float64)
+		specNumber, specIsNumber := specValue.(float64)
+		if requesterRaisedFields[path] && (isNumber || signedValue == nil) && specIsNumber && specNumber > signedNumber {
+			continue
+		}
+		return fmt.Errorf("the %s of the job isn't the one its client signed", path)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+//go:build unit || !integration
+
+package job
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/stretchr/testify/require"
+)
+
+func signedSpec() model.Spec {
+	return model.Spec{
+		Engine:  model.EngineDocker,
+		Docker:  model.JobSpecDocker{Image: "ubuntu", Entrypoint: []string{"echo", "hello"}},
+		Timeout: 10,
+		Deal:    model.Deal{Concurrency: 1},
+	}
+}
+
+func signedJob(t *testing.T, clientID string) model.Job {
+	spec := signedSpec()
+	payload, err := json.Marshal(model.JobCreatePayload{ClientID: clientID, Spec: &spec, Nonce: "nonce"})
+	require.NoError(t, err)
+	signature, err := system.SignForClient(payload)
+	require.NoError(t, err)
+	return model.Job{
+		Metadata: model.Metadata{
+			ID:       SignedJobID(payload),
+			ClientID: system.GetClientID(),
+			ClientSignature: &model.ClientSignature{
+				Payload:   payload,
+				Signature: signature,
+				PublicKey: system.GetClientPublicKey(),
+			},
+		},
+		Spec: signedSpec(),
+	}
+}
+
+func TestVerifyClientSignature(t *testing.T) {
+	system.InitConfigForTesting(t)
+
+	require.NoError(t, VerifyClientSignature(signedJob(t, system.GetClientID())))
+
+	require.Error(t, VerifyClientSignature(model.Job{Metadata: model.Metadata{ClientID: system.GetClientID()}}),
+		"the job isn't signed")
+
+	other := signedJob(t, system.GetClientID())
+	other.Metadata.ClientID = "other"
+	require.Error(t, VerifyClientSignature(other), "the job is from another client")
+
+	require.Error(t, VerifyClientSignature(signedJob(t, "other")), "the payload is from another client")
+
+	tampered := signedJob(t, system.GetClientID())
+	tampered.Metadata.ClientSignature.Payload = json.RawMessage(`{"ClientID":"` + system.GetClientID() + `"}`)
+	require.Error(t, VerifyClientSignature(tampered), "the payload isn't the one that was signed")
+
+	reused := signedJob(t, system.GetClientID())
+	reused.Metadata.ID = "another-job"
+	require.Error(t, VerifyClientSignature(reused), "the signature is reused for another job")
+
+	payload, err := json.Marshal(model.JobCreatePayload{ClientID: system.GetClientID(), Spec: &model.Spec{}})
+	require.NoError(t, err)
+	signature, err := system.SignForClient(payload)
+	require.NoError(t, err)
+	noNonce := model.Job{Metadata: model.Metadata{
+		ID:       SignedJobID(payload),
+		ClientID: system.GetClientID(),
+		ClientSignature: &model.ClientSignature{
+			Payload:   payload,
+			Signature: signature,
+			PublicKey: system.GetClientPublicKey(),
+		},
+	}}
+	require.Error(t, VerifyClientSignature(noNonce), "the payload has no nonce")
+}
+
+func TestVerifyClientSignatureBindsTheSpec(t *testing.T) {
+	system.InitConfigForTesting(t)
+
+	for name, test := range map[string]struct {
+		change func(*model.Spec)
+		valid  bool
+	}{
+		"unchanged":            {change: func(*model.Spec) {}, valid: true},
+		"timeout raised":       {change: func(s *model.Spec) { s.Timeout = 1800 }, valid: true},
+		"concurrency raised":   {change: func(s *model.Spec) { s.Deal.Concurrency = 3 }, valid: true},
+		"image changed":        {change: func(s *model.Spec) { s.Docker.Image = "evil" }},
+		"entrypoint changed":   {change: func(s *model.Spec) { s.Docker.Entrypoint = []string{"rm", "-rf", "/"} }},
+		"engine changed":       {change: func(s *model.Spec) { s.Engine = model.EngineWasm }},
+		"timeout lowered":      {change: func(s *model.Spec) { s.Timeout = 1 }},
+		"set field cleared":    {change: func(s *model.Spec) { s.Docker = model.JobSpecDocker{} }},
+		"unset field filled":   {change: func(s *model.Spec) { s.Docker.WorkingDirectory = "/work" }},
+		"confidence filled":    {change: func(s *model.Spec) { s.Deal.Confidence = 1 }},
+		"network filled":       {change: func(s *model.Spec) { s.Network.Type = model.NetworkFull }},
+		"env variables filled": {change: func(s *model.Spec) { s.Docker.EnvironmentVariables = []string{"LD_PRELOAD=/evil.so"} }},
+		"inputs filled": {change: func(s *model.Spec) {
+			s.Inputs = []model.StorageSpec{{StorageSource: model.StorageSourceURLDownload, URL: "https://evil.com", Path: "/inputs"}}
+		}},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			j := signedJob(t, system.GetClientID())
+			test.change(&j.Spec)
+			if test.valid {
+				require.NoError(t, VerifyClientSignature(j))
+			} else {
+				require.Error(t, VerifyClientSignature(j))
+			}
+		})
+	}
+}
+
+func TestVerifyClientSignatureFillsUnsetTimeout(t *testing.T) {
+	system.InitConfigForTesting(t)
+
+	spec := model.Spec{
+		Engine: model.EngineDocker,
+		Docker: model.JobSpecDocker{Image: "ubuntu"},
+		Deal:   model.Deal{Concurrency: 1},
+	}
+	payload, err := json.Marshal(model.JobCreatePayload{ClientID: system.GetClientID(), Spec: &spec, Nonce: "nonce"})
+	require.NoError(t, err)
+	signature, err := system.SignForClient(payload)
+	require.NoError(t, err)
+	j := model.Job{
+		Metadata: model.Metadata{
+			ID:       SignedJobID(payload),
+			ClientID: system.GetClientID(),
+			ClientSignature: &model.ClientSignature{
+				Payload:   payload,
+				Signature: signature,
+				PublicKey: system.GetClientPublicKey(),
+			},
+		},
+		Spec: spec,
+	}
+	j.Spec.Timeout = 1800
+	require.NoError(t, VerifyClientSignature(j), "the requester fills in the default timeout")
+}
@@ -208,8 +208,9 @@ func (resolver *StateResolver) GetResults(ctx context.Context, jobID string) ([]
 
 	for _, executionState := range GetCompletedVerifiedExecutionStates(jobState) {
 		results = append(results, model.PublishedResult{
-			NodeID: executionState.NodeID,
-			Data:   executionState.PublishedResult,
+			NodeID:          executionState.NodeID,
+			Data:            executionState.PublishedResult,
+			ResultSignature: executionState.ResultSignature,
 		})
 	}
 
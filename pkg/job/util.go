@@ -8,6 +8,14 @@ import (
 	"strings"
 
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/bittorrent"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/filecoin"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/gcs"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/git"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/huggingface"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/inline"
+	localdirectory "github.com/bacalhau-project/bacalhau/pkg/storage/local_directory"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/s3"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/url/urldownload"
 	"github.com/rs/zerolog/log"
 	"k8s.io/apimachinery/pkg/labels"
@@ -47,6 +55,62 @@ func buildJobInputs(inputVolumes, inputUrls []string) ([]model.StorageSpec, erro
 
 	// We expect the input URLs to be of the form `url:pathToMountInTheContainer` or `url`
 	for _, inputURL := range inputUrls {
+		if repoURL, ref, err := git.ParseURL(inputURL); err == nil {
+			jobInputs = append(jobInputs, gitStorageSpec(repoURL, ref))
+			continue
+		}
+		if _, _, err := s3.ParseURL(inputURL); err == nil {
+			jobInputs = append(jobInputs, model.StorageSpec{
+				StorageSource: model.StorageSourceS3,
+				URL:           strings.Trim(inputURL, " '\""),
+				Path:          "/inputs",
+			})
+			continue
+		}
+		if _, _, err := gcs.ParseURL(inputURL); err == nil {
+			jobInputs = append(jobInputs, model.StorageSpec{
+				StorageSource: model.StorageSourceGCS,
+				URL:           strings.Trim(inputURL, " '\""),
+				Path:          "/inputs",
+			})
+			continue
+		}
+		if _, err := huggingface.ParseURL(inputURL); err == nil {
+			jobInputs = append(jobInputs, model.StorageSpec{
+				StorageSource: model.StorageSourceHuggingFace,
+				URL:           strings.Trim(inputURL, " '\""),
+				Path:          "/inputs",
+			})
+			continue
+		}
+		if spec, err := localdirectory.ParseURL(inputURL); err == nil {
+			jobInputs = append(jobInputs, spec)
+			continue
+		}
+		if spec, err := filecoin.ParseURL(inputURL); err == nil {
+			jobInputs = append(jobInputs, spec)
+			continue
+		}
+		if _, err := bittorrent.ParseURL(inputURL); err == nil {
+			jobInputs = append(jobInputs, model.StorageSpec{
+				StorageSource: model.StorageSourceBitTorrent,
+				URL:           strings.Trim(inputURL, " '\""),
+				Path:          "/inputs",
+			})
+			continue
+		}
+		if strings.HasPrefix(strings.Trim(inputURL, " '\""), inline.URLPrefix) {
+			if _, err := inline.ParseURL(inputURL); err != nil {
+				return nil, err
+			}
+			jobInputs = append(jobInputs, model.StorageSpec{
+				StorageSource: model.StorageSourceInline,
+				URL:           strings.Trim(inputURL, " '\""),
+				Path:          "/inputs",
+			})
+			continue
+		}
+
 		// should loop through all available storage providers?
 		u, err := urldownload.IsURLSupported(inputURL)
 		if err != nil {
@@ -75,6 +139,20 @@ func buildJobInputs(inputVolumes, inputUrls []string) ([]model.StorageSpec, erro
 	return jobInputs, nil
 }
 
+// gitStorageSpec returns the storage spec of an input cloned from a repository,
+// at the ref if it is given.
+func gitStorageSpec(repoURL, ref string) model.StorageSpec {
+	spec := model.StorageSpec{
+		StorageSource: model.StorageSourceGit,
+		URL:           repoURL,
+		Path:          "/inputs",
+	}
+	if ref != "" {
+		spec.Metadata = map[string]string{git.RefMetadataKey: ref}
+	}
+	return spec
+}
+
 func buildJobOutputs(ctx context.Context, outputVolumes []string) ([]model.StorageSpec, error) {
 	outputVolumesMap := make(map[string]model.StorageSpec)
 	outputVolumes = append(outputVolumes, "outputs:/outputs")
@@ -113,6 +191,39 @@ func buildJobOutputs(ctx context.Context, outputVolumes []string) ([]model.Stora
 	return returnOutputVolumes, nil
 }
 
+// FilterOutputs adds include and exclude patterns in 'name:glob' form to the
+// output volumes with those names.
+func FilterOutputs(outputs []model.StorageSpec, includes, excludes []string) error {
+	for _, include := range includes {
+		i, pattern, err := parseOutputFilter(outputs, include)
+		if err != nil {
+			return err
+		}
+		outputs[i].Include = append(outputs[i].Include, pattern)
+	}
+	for _, exclude := range excludes {
+		i, pattern, err := parseOutputFilter(outputs, exclude)
+		if err != nil {
+			return err
+		}
+		outputs[i].Exclude = append(outputs[i].Exclude, pattern)
+	}
+	return nil
+}
+
+func parseOutputFilter(outputs []model.StorageSpec, filter string) (int, string, error) {
+	name, pattern, ok := strings.Cut(filter, ":")
+	if !ok || name == "" || pattern == "" {
+		return 0, "", fmt.Errorf("invalid output filter, must be 'name:glob': %s", filter)
+	}
+	for i, output := range outputs {
+		if output.Name == name {
+			return i, pattern, nil
+		}
+	}
+	return 0, "", fmt.Errorf("output filter for unknown output volume: %s", filter)
+}
+
 // ShortID shortens a Job ID e.g. `c42603b4-b418-4827-a9ca-d5a43338f2fe` to `c42603b4`
 func ShortID(id string) string {
 	if len(id) < model.ShortIDLength {
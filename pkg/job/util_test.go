@@ -72,6 +72,28 @@ func (s *JobUtilSuite) TestRun_URLs() {
 			{submittedURL: `'https://data.cityofnewyork.us/api/views/t29m-gskq/rows.csv?accessType=DOWNLOAD&foo=bar'`,
 				valid:    true,
 				errorMsg: "TYPE: With single quotes"},
+			{submittedURL: "s3://bucket/data/",
+				valid:    true,
+				errorMsg: "TYPE: S3 prefix"},
+			{submittedURL: "gs://bucket/data/file.csv",
+				valid:    true,
+				errorMsg: "TYPE: GCS object"},
+			{submittedURL: "huggingface://openai/whisper-tiny@main",
+				valid:    true,
+				errorMsg: "TYPE: Hugging Face model"},
+			{submittedURL: "magnet:?xt=urn:btih:c9e15763f722f23e98a29decdfae341b98d53056&dn=dataset",
+				valid:    true,
+				errorMsg: "TYPE: Magnet link"},
+			{submittedURL: "filecoin://bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku?miner=f01234",
+				valid:    true,
+				errorMsg: "TYPE: Filecoin deal"},
+			{submittedURL: "data:text/plain;base64,aGVsbG8gd29ybGQ=",
+				valid:    true,
+				errorMsg: "TYPE: Inline data"},
+			{submittedURL: "git+https://github.com/bacalhau-project/bacalhau.git#main",
+				convertedURL: "https://github.com/bacalhau-project/bacalhau.git",
+				valid:        true,
+				errorMsg:     "TYPE: Git repository"},
 		}
 
 		for _, testURL := range testURLs {
@@ -156,3 +178,16 @@ func (s *JobUtilSuite) TestStateSummary() {
 		})
 	}
 }
+
+func (s *JobUtilSuite) TestFilterOutputs() {
+	outputs := []model.StorageSpec{{Name: "outputs", Path: "/outputs"}, {Name: "tmp", Path: "/tmp"}}
+	err := FilterOutputs(outputs, []string{"outputs:*.parquet", "outputs:reports"}, []string{"tmp:*"})
+	s.Require().NoError(err)
+	s.Equal([]string{"*.parquet", "reports"}, outputs[0].Include)
+	s.Empty(outputs[0].Exclude)
+	s.Empty(outputs[1].Include)
+	s.Equal([]string{"*"}, outputs[1].Exclude)
+
+	s.Error(FilterOutputs(outputs, []string{"*.parquet"}, nil), "filters must name the output volume")
+	s.Error(FilterOutputs(outputs, nil, []string{"logs:*.log"}), "filters must be for an output volume")
+}
@@ -3,11 +3,25 @@ package job
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/bacalhau-project/bacalhau/pkg/compression"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/encryption"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/inline"
+	"golang.org/x/exp/slices"
 )
 
+// ipnsNameRegex matches the IPNS names that results can be published under,
+// which are used in the names of keys on the compute node.
+var ipnsNameRegex = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
 // VerifyJobCreatePayload verifies the values in a job creation request are legal.
 func VerifyJobCreatePayload(ctx context.Context, jc *model.JobCreatePayload) error {
 	if jc.ClientID == "" {
@@ -42,6 +56,18 @@ func VerifyJob(ctx context.Context, j *model.Job) error {
 		return fmt.Errorf("confidence must be >= 0")
 	}
 
+	if j.Spec.Deal.GangTimeout < 0 {
+		return fmt.Errorf("gang timeout must be >= 0")
+	}
+
+	if j.Spec.Deal.MaxBudget < 0 {
+		return fmt.Errorf("max budget must be >= 0")
+	}
+
+	if err := j.Spec.Deal.Offer.Validate(); err != nil {
+		return fmt.Errorf("invalid offer: %w", err)
+	}
+
 	if !model.IsValidEngine(j.Spec.Engine) {
 		return fmt.Errorf("invalid executor type: %s", j.Spec.Engine.String())
 	}
@@ -54,10 +80,151 @@ func VerifyJob(ctx context.Context, j *model.Job) error {
 		return fmt.Errorf("invalid publisher type: %s", j.Spec.Publisher.String())
 	}
 
+	for _, p := range j.Spec.Publishers {
+		if !model.IsValidPublisher(p) {
+			return fmt.Errorf("invalid publisher type: %s", p.String())
+		}
+	}
+
 	if err := j.Spec.Network.IsValid(); err != nil {
 		return err
 	}
 
+	if j.Spec.Engine == model.EngineProcess && j.Spec.Process.Command == "" {
+		return fmt.Errorf("process jobs must have a command")
+	}
+
+	if j.Spec.Engine == model.EngineApptainer && j.Spec.Docker.Image == "" {
+		return fmt.Errorf("apptainer jobs must have an image")
+	}
+
+	if j.Spec.Engine == model.EnginePython && j.Spec.Python.Script == "" {
+		return fmt.Errorf("python jobs must have a script")
+	}
+
+	if j.Spec.Engine == model.EnginePlugin && j.Spec.Plugin.Name == "" {
+		return fmt.Errorf("plugin jobs must name the plugin to run them with")
+	}
+
+	if j.Spec.Engine == model.EngineDuckDB {
+		if j.Spec.DuckDB.Query == "" {
+			return fmt.Errorf("duckdb jobs must have a query")
+		}
+		if len(j.Spec.Outputs) == 0 {
+			return fmt.Errorf("duckdb jobs must have an output to write the result set to")
+		}
+	}
+
+	if j.Spec.Array.Count < 0 {
+		return fmt.Errorf("array count must be >= 0")
+	}
+
+	if j.Spec.Array.Count > 0 && (j.Spec.Engine == model.EngineLanguage || j.Spec.Engine == model.EnginePythonWasm) {
+		return fmt.Errorf("%s jobs cannot be run as arrays", j.Spec.Engine)
+	}
+
+	if j.Spec.MaxWallClock < 0 {
+		return fmt.Errorf("max wall clock must be >= 0")
+	}
+
+	if j.Spec.Priority < model.PriorityLow || j.Spec.Priority > model.PriorityHigh {
+		return fmt.Errorf("unknown job priority %s", j.Spec.Priority)
+	}
+
+	if j.Spec.Retry.MaxAttempts < 0 {
+		return fmt.Errorf("retry max attempts must be >= 0")
+	}
+
+	if j.Spec.Retry.Backoff < 0 {
+		return fmt.Errorf("retry backoff must be >= 0")
+	}
+
+	for _, reason := range j.Spec.Retry.RetryOn {
+		if _, err := model.ParseRetryReason(string(reason)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := model.FromLabelSelectorRequirements(j.Spec.Affinity.PreferredNodeSelectors...); err != nil {
+		return fmt.Errorf("invalid preferred node selectors: %w", err)
+	}
+
+	for _, toleration := range j.Spec.Tolerations {
+		if err := toleration.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if j.Spec.Webhook != "" {
+		if u, err := url.Parse(j.Spec.Webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook must be an http or https URL: %s", j.Spec.Webhook)
+		}
+	}
+
+	if j.Spec.IPNSName != "" {
+		if !ipnsNameRegex.MatchString(j.Spec.IPNSName) {
+			return fmt.Errorf("IPNS name must be up to 64 letters, digits, '.', '_' and '-': %s", j.Spec.IPNSName)
+		}
+		if !slices.Contains(j.Spec.AllPublishers(), model.PublisherIpfs) {
+			return fmt.Errorf("results can only be published under an IPNS name by the IPFS publisher")
+		}
+	}
+
+	if j.Spec.FilecoinDeal.Replication < 0 {
+		return fmt.Errorf("filecoin deal replication must be >= 0")
+	}
+
+	if j.Spec.FilecoinDeal.DurationDays < 0 {
+		return fmt.Errorf("filecoin deal duration must be >= 0")
+	}
+
+	for _, output := range j.Spec.Outputs {
+		if err := publisher.ValidateOutputFilters(output); err != nil {
+			return err
+		}
+	}
+
+	if err := compression.Validate(j.Spec.Compression); err != nil {
+		return err
+	}
+
+	if _, err := encryption.ParseRecipients(j.Spec.Encryption.Recipients); err != nil {
+		return fmt.Errorf("invalid encryption recipient: %w", err)
+	}
+
+	if j.Spec.TEE != "" {
+		if _, err := model.ParseTEEType(string(j.Spec.TEE)); err != nil {
+			return fmt.Errorf("invalid TEE: %w", err)
+		}
+	}
+
+	if _, err := j.Spec.Docker.PinnedDigest(); err != nil {
+		return err
+	}
+
+	if j.Spec.Resources.IOPS != "" {
+		if iops, err := strconv.ParseUint(j.Spec.Resources.IOPS, 10, 64); err != nil || iops == 0 {
+			return fmt.Errorf("invalid IOPS %q: must be a positive whole number", j.Spec.Resources.IOPS)
+		}
+	}
+
+	if strings.Contains(j.Spec.Resources.GPU, ".") && capacity.ConvertGPUFractionString(j.Spec.Resources.GPU) == 0 {
+		return fmt.Errorf("invalid GPU %q: must be a whole number of GPUs, or a fraction of one between 0 and 1",
+			j.Spec.Resources.GPU)
+	}
+	if j.Spec.Resources.GPUMIGProfile != "" && capacity.ConvertGPUFractionString(j.Spec.Resources.GPU) > 0 {
+		return fmt.Errorf("MIG slices are taken whole rather than shared as fractions of a GPU")
+	}
+
+	if j.Spec.Resources.GPUModel != "" || j.Spec.Resources.GPUMemory != "" {
+		if capacity.ParseGPURequest(j.Spec.Resources).IsZero() {
+			return fmt.Errorf("a GPU model or GPU memory requires at least one GPU")
+		}
+		if j.Spec.Resources.GPUMemory != "" && capacity.ConvertBytesString(j.Spec.Resources.GPUMemory) == 0 {
+			return fmt.Errorf("invalid GPU memory %q", j.Spec.Resources.GPUMemory)
+		}
+	}
+
 	if j.Spec.Deal.Confidence > j.Spec.Deal.Concurrency {
 		return fmt.Errorf("the deal confidence cannot be higher than the concurrency")
 	}
@@ -66,6 +233,14 @@ func VerifyJob(ctx context.Context, j *model.Job) error {
 		if !model.IsValidStorageSourceType(inputVolume.StorageSource) {
 			return fmt.Errorf("invalid input volume type: %s", inputVolume.StorageSource.String())
 		}
+		if _, err := inputVolume.ExpectedDigest(); err != nil {
+			return err
+		}
+		if inputVolume.StorageSource == model.StorageSourceInline {
+			if _, err := inline.ParseURL(inputVolume.URL); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -0,0 +1,409 @@
+// Package boltdb provides a job store that keeps jobs, their state and their
+// history in a BoltDB file, so that in-flight and historical jobs survive the
+// requester node restarting.
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/imdario/mergo"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+)
+
+const (
+	newJobComment = "Job created"
+
+	// openTimeout is how long to wait for another process to release the
+	// database, e.g. another node using the same bacalhau directory.
+	openTimeout = 5 * time.Second
+)
+
+var (
+	jobsBucket        = []byte("jobs")
+	statesBucket      = []byte("states")
+	historyBucket     = []byte("history")
+	inProgressBucket  = []byte("inprogress")
+	schedulesBucket   = []byte("schedules")
+	workflowsBucket   = []byte("workflows")
+	quotasBucket      = []byte("quotas")
+	templatesBucket   = []byte("templates")
+	reputationsBucket = []byte("reputations")
+	nodeAccessBucket  = []byte("nodeaccess")
+)
+
+type JobStore struct {
+	db *bolt.DB
+}
+
+// NewJobStore opens the job store in the file at the path, creating it if it
+// doesn't exist. The store must be closed once it is no longer used.
+func NewJobStore(path string) (*JobStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open job store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{
+			jobsBucket, statesBucket, historyBucket, inProgressBucket, schedulesBucket, workflowsBucket, quotasBucket,
+			templatesBucket, reputationsBucket, nodeAccessBucket,
+		} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		closer.CloseWithLogOnError("job store", db)
+		return nil, fmt.Errorf("failed to create job store buckets: %w", err)
+	}
+	return &JobStore{db: db}, nil
+}
+
+// Close closes the database, after which the store can't be used.
+func (d *JobStore) Close() error {
+	return d.db.Close()
+}
+
+// Gets a job from the datastore.
+//
+// Errors:
+//
+//   - error-job-not-found        		  -- if the job is not found
+func (d *JobStore) GetJob(_ context.Context, id string) (j model.Job, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		j, err = getJob(tx, id)
+		return err
+	})
+	return j, err
+}
+
+func (d *JobStore) GetJobs(_ context.Context, query jobstore.JobQuery) (jobs []model.Job, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		if query.ID != "" {
+			j, err := getJob(tx, query.ID)
+			if err != nil {
+				return err
+			}
+			jobs = []model.Job{j}
+			return nil
+		}
+
+		var all []model.Job
+		err := tx.Bucket(jobsBucket).ForEach(func(_, v []byte) error {
+			var j model.Job
+			if err := json.Unmarshal(v, &j); err != nil {
+				return err
+			}
+			all = append(all, j)
+			return nil
+		})
+		jobs = jobstore.FilterJobs(query, all)
+		return err
+	})
+	return jobs, err
+}
+
+func (d *JobStore) GetJobState(_ context.Context, jobID string) (state model.JobState, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		state, err = getJobState(tx, jobID)
+		if errors.As(err, &jobstore.ErrJobNotFound{}) {
+			return bacerrors.NewJobNotFound(jobID)
+		}
+		return err
+	})
+	return state, err
+}
+
+func (d *JobStore) GetInProgressJobs(_ context.Context) (result []model.JobWithInfo, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(inProgressBucket).ForEach(func(k, _ []byte) error {
+			j, err := getJob(tx, string(k))
+			if err != nil {
+				return err
+			}
+			state, err := getJobState(tx, string(k))
+			if err != nil {
+				return err
+			}
+			result = append(result, model.JobWithInfo{Job: j, State: state})
+			return nil
+		})
+	})
+	return result, err
+}
+
+func (d *JobStore) GetJobHistory(_ context.Context, jobID string) (history []model.JobHistory, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucket).Bucket([]byte(jobID))
+		if bucket == nil {
+			return jobstore.NewErrJobNotFound(jobID)
+		}
+		return bucket.ForEach(func(_, v []byte) error {
+			var entry model.JobHistory
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			history = append(history, entry)
+			return nil
+		})
+	})
+	return history, err
+}
+
+func (d *JobStore) GetJobsCount(ctx context.Context, query jobstore.JobQuery) (int, error) {
+	useQuery := query
+	useQuery.Limit = 0
+	useQuery.Offset = 0
+	jobs, err := d.GetJobs(ctx, useQuery)
+	if err != nil {
+		return 0, err
+	}
+	return len(jobs), nil
+}
+
+func (d *JobStore) CreateJob(_ context.Context, job model.Job) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(jobsBucket).Get([]byte(job.Metadata.ID)) != nil {
+			return jobstore.NewErrJobAlreadyExists(job.Metadata.ID)
+		}
+		if err := put(tx.Bucket(jobsBucket), job.Metadata.ID, job); err != nil {
+			return err
+		}
+
+		// populate job state
+		jobState := model.JobState{
+			JobID:      job.Metadata.ID,
+			State:      model.JobStateNew,
+			Version:    1,
+			CreateTime: time.Now(),
+			UpdateTime: time.Now(),
+		}
+		if err := put(tx.Bucket(statesBucket), job.Metadata.ID, jobState); err != nil {
+			return err
+		}
+		if err := tx.Bucket(inProgressBucket).Put([]byte(job.Metadata.ID), []byte{}); err != nil {
+			return err
+		}
+		return appendJobHistory(tx, jobState, model.JobStateNew, newJobComment)
+	})
+}
+
+// helper method to read a single job in a transaction. This is used by both GetJob and GetJobs.
+func getJob(tx *bolt.Tx, id string) (model.Job, error) {
+	if len(id) < model.ShortIDLength {
+		return model.Job{}, bacerrors.NewJobNotFound(id)
+	}
+
+	bucket := tx.Bucket(jobsBucket)
+	v := bucket.Get([]byte(id))
+
+	// support for short job IDs
+	if v == nil && jobutils.ShortID(id) == id {
+		// passed in a short id, need to resolve the long id first
+		k, kv := bucket.Cursor().Seek([]byte(id))
+		if k != nil && bytes.HasPrefix(k, []byte(id)) {
+			v = kv
+		}
+	}
+
+	if v == nil {
+		return model.Job{}, bacerrors.NewJobNotFound(id)
+	}
+	var j model.Job
+	err := json.Unmarshal(v, &j)
+	return j, err
+}
+
+func getJobState(tx *bolt.Tx, jobID string) (model.JobState, error) {
+	v := tx.Bucket(statesBucket).Get([]byte(jobID))
+	if v == nil {
+		return model.JobState{}, jobstore.NewErrJobNotFound(jobID)
+	}
+	var state model.JobState
+	err := json.Unmarshal(v, &state)
+	return state, err
+}
+
+func (d *JobStore) UpdateJobState(_ context.Context, request jobstore.UpdateJobStateRequest) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		// get the existing job state
+		jobState, err := getJobState(tx, request.JobID)
+		if err != nil {
+			return err
+		}
+
+		// check the expected state
+		if err = request.Condition.Validate(jobState); err != nil {
+			return err
+		}
+		if jobState.State.IsTerminal() {
+			return jobstore.NewErrJobAlreadyTerminal(request.JobID, jobState.State, request.NewState)
+		}
+
+		// update the job state
+		previousState := jobState.State
+		jobState.State = request.NewState
+		jobState.Version++
+		jobState.UpdateTime = time.Now()
+		if err = put(tx.Bucket(statesBucket), request.JobID, jobState); err != nil {
+			return err
+		}
+		if request.NewState.IsTerminal() {
+			if err = tx.Bucket(inProgressBucket).Delete([]byte(request.JobID)); err != nil {
+				return err
+			}
+		}
+		return appendJobHistory(tx, jobState, previousState, request.Comment)
+	})
+}
+
+func (d *JobStore) CreateExecution(_ context.Context, execution model.ExecutionState) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		jobState, err := getJobState(tx, execution.JobID)
+		if err != nil {
+			return err
+		}
+		for _, e := range jobState.Executions {
+			if e.ID() == execution.ID() {
+				return jobstore.NewErrExecutionAlreadyExists(execution.ID())
+			}
+		}
+		if execution.CreateTime.IsZero() {
+			execution.CreateTime = time.Now()
+		}
+		if execution.UpdateTime.IsZero() {
+			execution.UpdateTime = execution.CreateTime
+		}
+		if execution.Version == 0 {
+			execution.Version = 1
+		}
+		jobState.Executions = append(jobState.Executions, execution)
+		if err = put(tx.Bucket(statesBucket), execution.JobID, jobState); err != nil {
+			return err
+		}
+		return appendExecutionHistory(tx, execution, model.ExecutionStateNew, "")
+	})
+}
+
+func (d *JobStore) UpdateExecution(_ context.Context, request jobstore.UpdateExecutionRequest) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		// find the existing execution
+		jobState, err := getJobState(tx, request.ExecutionID.JobID)
+		if err != nil {
+			return err
+		}
+		var existingExecution model.ExecutionState
+		executionIndex := -1
+		for i, e := range jobState.Executions {
+			if e.ID() == request.ExecutionID {
+				existingExecution = e
+				executionIndex = i
+				break
+			}
+		}
+		if executionIndex == -1 {
+			return jobstore.NewErrExecutionNotFound(request.ExecutionID)
+		}
+
+		// check the expected state
+		if err = request.Condition.Validate(existingExecution); err != nil {
+			return err
+		}
+		if existingExecution.State.IsTerminal() {
+			return jobstore.NewErrExecutionAlreadyTerminal(request.ExecutionID, existingExecution.State, request.NewValues.State)
+		}
+
+		// populate default values
+		newExecution := request.NewValues
+		if newExecution.CreateTime.IsZero() {
+			newExecution.CreateTime = existingExecution.CreateTime
+		}
+		if newExecution.UpdateTime.IsZero() {
+			newExecution.UpdateTime = time.Now()
+		}
+		if newExecution.Version == 0 {
+			newExecution.Version = existingExecution.Version + 1
+		}
+
+		if err = mergo.Merge(&newExecution, existingExecution); err != nil {
+			return err
+		}
+
+		// update the execution
+		previousState := existingExecution.State
+		jobState.Executions[executionIndex] = newExecution
+		if err = put(tx.Bucket(statesBucket), newExecution.JobID, jobState); err != nil {
+			return err
+		}
+		return appendExecutionHistory(tx, newExecution, previousState, request.Comment)
+	})
+}
+
+func appendJobHistory(tx *bolt.Tx, updateJob model.JobState, previousState model.JobStateType, comment string) error {
+	return appendHistory(tx, model.JobHistory{
+		Type:  model.JobHistoryTypeJobLevel,
+		JobID: updateJob.JobID,
+		JobState: &model.StateChange[model.JobStateType]{
+			Previous: previousState,
+			New:      updateJob.State,
+		},
+		NewVersion: updateJob.Version,
+		Comment:    comment,
+		Time:       updateJob.UpdateTime,
+	})
+}
+
+func appendExecutionHistory(
+	tx *bolt.Tx, updatedExecution model.ExecutionState, previousState model.ExecutionStateType, comment string) error {
+	return appendHistory(tx, model.JobHistory{
+		Type:             model.JobHistoryTypeExecutionLevel,
+		JobID:            updatedExecution.JobID,
+		NodeID:           updatedExecution.NodeID,
+		ComputeReference: updatedExecution.ComputeReference,
+		ExecutionState: &model.StateChange[model.ExecutionStateType]{
+			Previous: previousState,
+			New:      updatedExecution.State,
+		},
+		NewVersion: updatedExecution.Version,
+		Comment:    comment,
+		Time:       updatedExecution.UpdateTime,
+	})
+}
+
+// appendHistory adds the entry to the bucket of the history of its job, keyed
+// by a sequence so that the history is read back in the order it happened.
+func appendHistory(tx *bolt.Tx, entry model.JobHistory) error {
+	bucket, err := tx.Bucket(historyBucket).CreateBucketIfNotExists([]byte(entry.JobID))
+	if err != nil {
+		return err
+	}
+	seq, err := bucket.NextSequence()
+	if err != nil {
+		return err
+	}
+	return put(bucket, string(binary.BigEndian.AppendUint64(nil, seq)), entry)
+}
+
+func put(bucket *bolt.Bucket, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(key), data)
+}
+
+// Static check to ensure that JobStore implements jobstore.Store:
+var _ jobstore.Store = (*JobStore)(nil)
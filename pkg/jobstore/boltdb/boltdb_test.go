@@ -0,0 +1,309 @@
+//go:build unit || !integration
+
+package boltdb
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+const jobID = "4a8a2b5f-5b9c-4a4e-9b53-6d3c8f0c1e2a"
+
+func newJob(id, clientID string, annotations ...string) model.Job {
+	j := model.NewJob()
+	j.Metadata.ID = id
+	j.Metadata.ClientID = clientID
+	j.Metadata.CreatedAt = time.Now()
+	j.Spec.Annotations = annotations
+	return *j
+}
+
+func runJob(t *testing.T, store jobstore.Store) {
+	ctx := context.Background()
+	require.NoError(t, store.CreateJob(ctx, newJob(jobID, "client")))
+	require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:     jobID,
+		Condition: jobstore.UpdateJobCondition{ExpectedState: model.JobStateNew},
+		NewState:  model.JobStateInProgress,
+		Comment:   "started",
+	}))
+	require.NoError(t, store.CreateExecution(ctx, model.ExecutionState{
+		JobID:            jobID,
+		NodeID:           "node",
+		ComputeReference: "execution",
+		State:            model.ExecutionStateAskForBid,
+	}))
+	require.NoError(t, store.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: model.ExecutionID{JobID: jobID, NodeID: "node", ExecutionID: "execution"},
+		Condition:   jobstore.UpdateExecutionCondition{ExpectedState: model.ExecutionStateAskForBid},
+		NewValues:   model.ExecutionState{State: model.ExecutionStateBidAccepted},
+	}))
+}
+
+func TestJobsSurviveReopening(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jobs.db")
+
+	store, err := NewJobStore(path)
+	require.NoError(t, err)
+	runJob(t, store)
+	require.NoError(t, store.Close())
+
+	store, err = NewJobStore(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, store.Close()) })
+
+	j, err := store.GetJob(ctx, jobID[:model.ShortIDLength])
+	require.NoError(t, err)
+	require.Equal(t, "client", j.Metadata.ClientID)
+
+	state, err := store.GetJobState(ctx, jobID)
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateInProgress, state.State)
+	require.Equal(t, 2, state.Version)
+	require.Len(t, state.Executions, 1)
+	require.Equal(t, model.ExecutionStateBidAccepted, state.Executions[0].State)
+	require.Equal(t, "node", state.Executions[0].NodeID, "the update keeps the values it doesn't change")
+	require.Equal(t, 2, state.Executions[0].Version)
+
+	inProgress, err := store.GetInProgressJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, inProgress, 1)
+
+	history, err := store.GetJobHistory(ctx, jobID)
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+	require.Equal(t, "Job created", history[0].Comment)
+	require.Equal(t, "started", history[1].Comment)
+	require.Equal(t, model.ExecutionStateBidAccepted, history[3].ExecutionState.New)
+
+	require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    jobID,
+		NewState: model.JobStateCompleted,
+	}))
+	inProgress, err = store.GetInProgressJobs(ctx)
+	require.NoError(t, err)
+	require.Empty(t, inProgress)
+	require.Error(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    jobID,
+		NewState: model.JobStateInProgress,
+	}), "terminal jobs can't be updated")
+}
+
+func TestUpdateExecutionKeepsCreateTime(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewJobStore(filepath.Join(t.TempDir(), "jobs.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, store.Close()) })
+
+	for _, s := range []jobstore.Store{store, inmemory.NewJobStore()} {
+		start := time.Now()
+		runJob(t, s)
+		state, err := s.GetJobState(ctx, jobID)
+		require.NoError(t, err)
+		execution := state.Executions[0]
+		require.False(t, execution.CreateTime.Before(start))
+		require.True(t, execution.UpdateTime.After(execution.CreateTime), "executions are updated after they are created")
+	}
+}
+
+func TestGetJobs(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewJobStore(filepath.Join(t.TempDir(), "jobs.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, store.Close()) })
+
+	require.NoError(t, store.CreateJob(ctx, newJob("b-job-id", "client", "keep")))
+	require.NoError(t, store.CreateJob(ctx, newJob("a-job-id", "client", "skip")))
+	require.NoError(t, store.CreateJob(ctx, newJob("c-job-id", "other")))
+	require.ErrorAs(t, store.CreateJob(ctx, newJob("c-job-id", "other")), &jobstore.ErrJobAlreadyExists{})
+
+	jobs, err := store.GetJobs(ctx, jobstore.JobQuery{ClientID: "client", SortBy: "id"})
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	require.Equal(t, "a-job-id", jobs[0].Metadata.ID)
+
+	jobs, err = store.GetJobs(ctx, jobstore.JobQuery{ReturnAll: true, ExcludeTags: []model.ExcludedTag{"skip"}})
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+
+	count, err := store.GetJobsCount(ctx, jobstore.JobQuery{ClientID: "client", Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	_, err = store.GetJob(ctx, "missing-job-id")
+	require.Error(t, err)
+}
+
+func TestSchedulesSurviveReopening(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jobs.db")
+
+	store, err := NewJobStore(path)
+	require.NoError(t, err)
+	schedule := model.JobSchedule{ID: jobID, ClientID: "client", Cron: "@hourly", KeepRuns: 1, CreatedAt: time.Now()}
+	require.NoError(t, store.CreateSchedule(ctx, schedule))
+	require.ErrorAs(t, store.CreateSchedule(ctx, schedule), &jobstore.ErrScheduleAlreadyExists{})
+	schedule.AddRun(model.ScheduleRun{JobID: "first"})
+	schedule.AddRun(model.ScheduleRun{JobID: "second"})
+	require.NoError(t, store.UpdateSchedule(ctx, schedule))
+	require.NoError(t, store.Close())
+
+	store, err = NewJobStore(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, store.Close()) })
+
+	found, err := store.GetSchedule(ctx, jobID[:model.ShortIDLength])
+	require.NoError(t, err)
+	require.Equal(t, "@hourly", found.Cron)
+	require.Len(t, found.History, 1, "only the most recent runs are kept")
+	require.Equal(t, "second", found.History[0].JobID)
+
+	schedules, err := store.GetSchedules(ctx, "other")
+	require.NoError(t, err)
+	require.Empty(t, schedules)
+
+	require.NoError(t, store.DeleteSchedule(ctx, jobID))
+	_, err = store.GetSchedule(ctx, jobID)
+	require.ErrorAs(t, err, &jobstore.ErrScheduleNotFound{})
+}
+
+func TestWorkflowsSurviveReopening(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jobs.db")
+
+	store, err := NewJobStore(path)
+	require.NoError(t, err)
+	workflow := model.Workflow{
+		ID:       jobID,
+		ClientID: "client",
+		State:    model.WorkflowStateInProgress,
+		Stages: []model.WorkflowStage{{
+			WorkflowStageSpec: model.WorkflowStageSpec{Name: "extract"},
+			State:             model.WorkflowStageRunning,
+			JobID:             "job-id",
+		}},
+	}
+	require.NoError(t, store.CreateWorkflow(ctx, workflow))
+	require.ErrorAs(t, store.CreateWorkflow(ctx, workflow), &jobstore.ErrWorkflowAlreadyExists{})
+	workflow.Stages[0].State = model.WorkflowStageCompleted
+	require.NoError(t, store.UpdateWorkflow(ctx, workflow))
+	require.NoError(t, store.Close())
+
+	store, err = NewJobStore(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, store.Close()) })
+
+	found, err := store.GetWorkflow(ctx, jobID[:model.ShortIDLength])
+	require.NoError(t, err)
+	require.Equal(t, "extract", found.Stages[0].Name)
+	require.Equal(t, model.WorkflowStageCompleted, found.Stages[0].State)
+
+	workflows, err := store.GetWorkflows(ctx, "client")
+	require.NoError(t, err)
+	require.Len(t, workflows, 1)
+
+	_, err = store.GetWorkflow(ctx, "missing-workflow")
+	require.ErrorAs(t, err, &jobstore.ErrWorkflowNotFound{})
+}
+
+func TestQuotasSurviveReopening(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jobs.db")
+
+	store, err := NewJobStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.SetQuota(ctx, model.ClientQuota{ClientID: "client", MaxQueuedJobs: 5}))
+	require.NoError(t, store.SetQuota(ctx, model.ClientQuota{ClientID: "client", MaxQueuedJobs: 10}))
+	require.NoError(t, store.SetQuota(ctx, model.ClientQuota{ClientID: "another", MaxConcurrentJobs: 1}))
+	require.NoError(t, store.DeleteQuota(ctx, "another"))
+	require.NoError(t, store.Close())
+
+	store, err = NewJobStore(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, store.Close()) })
+
+	quotas, err := store.GetQuotas(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []model.ClientQuota{{ClientID: "client", MaxQueuedJobs: 10}}, quotas)
+
+	_, err = store.GetQuota(ctx, "another")
+	require.ErrorAs(t, err, &jobstore.ErrQuotaNotFound{})
+}
+
+func TestTemplatesSurviveReopening(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jobs.db")
+
+	store, err := NewJobStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.SetTemplate(ctx, model.JobTemplate{Name: "train", ClientID: "client", Description: "v1"}))
+	require.NoError(t, store.SetTemplate(ctx, model.JobTemplate{Name: "train", ClientID: "client", Description: "v2"}))
+	require.NoError(t, store.SetTemplate(ctx, model.JobTemplate{Name: "etl", ClientID: "client"}))
+	require.NoError(t, store.DeleteTemplate(ctx, "etl"))
+	require.NoError(t, store.Close())
+
+	store, err = NewJobStore(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, store.Close()) })
+
+	templates, err := store.GetTemplates(ctx)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	require.Equal(t, "v2", templates[0].Description)
+
+	_, err = store.GetTemplate(ctx, "etl")
+	require.ErrorAs(t, err, &jobstore.ErrTemplateNotFound{})
+}
+
+func TestReputationsSurviveReopening(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jobs.db")
+
+	store, err := NewJobStore(path)
+	require.NoError(t, err)
+	_, err = store.RecordOutcome(ctx, "node", model.ExecutionOutcomeSucceeded, time.Hour)
+	require.NoError(t, err)
+	reputation, err := store.RecordOutcome(ctx, "node", model.ExecutionOutcomeTimedOut, time.Hour)
+	require.NoError(t, err)
+	require.InDelta(t, 1, reputation.Successes, 0.01)
+	require.InDelta(t, 1, reputation.Timeouts, 0.01)
+	require.NoError(t, store.Close())
+
+	store, err = NewJobStore(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, store.Close()) })
+
+	reputations, err := store.GetReputations(ctx)
+	require.NoError(t, err)
+	require.Len(t, reputations, 1)
+	require.Equal(t, "node", reputations[0].NodeID)
+	require.InDelta(t, 2, reputations[0].Executions(), 0.01)
+}
+
+func TestNodeAccessRules(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewJobStore(filepath.Join(t.TempDir(), "jobs.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, store.Close()) })
+
+	require.NoError(t, store.SetNodeAccessRule(ctx, model.NodeAccessRule{NodeID: "b", Access: model.NodeAccessDeny}))
+	require.NoError(t, store.SetNodeAccessRule(ctx, model.NodeAccessRule{NodeID: "a", Access: model.NodeAccessDeny}))
+	require.NoError(t, store.SetNodeAccessRule(ctx, model.NodeAccessRule{NodeID: "a", Access: model.NodeAccessAllow}))
+
+	rules, err := store.GetNodeAccessRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	require.Equal(t, "a", rules[0].NodeID)
+	require.Equal(t, model.NodeAccessAllow, rules[0].Access)
+
+	require.NoError(t, store.DeleteNodeAccessRule(ctx, "a"))
+	require.ErrorAs(t, store.DeleteNodeAccessRule(ctx, "a"), &jobstore.ErrNodeAccessRuleNotFound{})
+}
@@ -0,0 +1,45 @@
+package boltdb
+
+import (
+	"context"
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+func (d *JobStore) GetNodeAccessRules(_ context.Context) (result []model.NodeAccessRule, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(nodeAccessBucket).ForEach(func(_, v []byte) error {
+			var rule model.NodeAccessRule
+			if err := json.Unmarshal(v, &rule); err != nil {
+				return err
+			}
+			result = append(result, rule)
+			return nil
+		})
+	})
+	jobstore.SortNodeAccessRules(result)
+	return result, err
+}
+
+func (d *JobStore) SetNodeAccessRule(_ context.Context, rule model.NodeAccessRule) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(nodeAccessBucket), rule.NodeID, rule)
+	})
+}
+
+func (d *JobStore) DeleteNodeAccessRule(_ context.Context, nodeID string) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(nodeAccessBucket)
+		if bucket.Get([]byte(nodeID)) == nil {
+			return jobstore.NewErrNodeAccessRuleNotFound(nodeID)
+		}
+		return bucket.Delete([]byte(nodeID))
+	})
+}
+
+// Static check to ensure that JobStore implements jobstore.NodeAccessStore:
+var _ jobstore.NodeAccessStore = (*JobStore)(nil)
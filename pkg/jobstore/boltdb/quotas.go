@@ -0,0 +1,56 @@
+package boltdb
+
+import (
+	"context"
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+func (d *JobStore) GetQuota(_ context.Context, clientID string) (quota model.ClientQuota, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(quotasBucket).Get([]byte(clientID))
+		if v == nil {
+			return jobstore.NewErrQuotaNotFound(clientID)
+		}
+		return json.Unmarshal(v, &quota)
+	})
+	return quota, err
+}
+
+func (d *JobStore) GetQuotas(_ context.Context) (result []model.ClientQuota, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(quotasBucket).ForEach(func(_, v []byte) error {
+			var quota model.ClientQuota
+			if err := json.Unmarshal(v, &quota); err != nil {
+				return err
+			}
+			result = append(result, quota)
+			return nil
+		})
+	})
+	jobstore.SortQuotas(result)
+	return result, err
+}
+
+func (d *JobStore) SetQuota(_ context.Context, quota model.ClientQuota) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(quotasBucket), quota.ClientID, quota)
+	})
+}
+
+func (d *JobStore) DeleteQuota(_ context.Context, clientID string) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(quotasBucket)
+		if bucket.Get([]byte(clientID)) == nil {
+			return jobstore.NewErrQuotaNotFound(clientID)
+		}
+		return bucket.Delete([]byte(clientID))
+	})
+}
+
+// Static check to ensure that JobStore implements jobstore.QuotaStore:
+var _ jobstore.QuotaStore = (*JobStore)(nil)
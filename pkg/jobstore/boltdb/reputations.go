@@ -0,0 +1,46 @@
+package boltdb
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+func (d *JobStore) GetReputations(_ context.Context) (result []model.NodeReputation, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(reputationsBucket).ForEach(func(_, v []byte) error {
+			var reputation model.NodeReputation
+			if err := json.Unmarshal(v, &reputation); err != nil {
+				return err
+			}
+			result = append(result, reputation)
+			return nil
+		})
+	})
+	jobstore.SortReputations(result)
+	return result, err
+}
+
+func (d *JobStore) RecordOutcome(
+	_ context.Context, nodeID string, outcome model.ExecutionOutcome, halfLife time.Duration) (result model.NodeReputation, err error) {
+	err = d.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(reputationsBucket)
+		result = model.NodeReputation{NodeID: nodeID}
+		if v := bucket.Get([]byte(nodeID)); v != nil {
+			if err := json.Unmarshal(v, &result); err != nil {
+				return err
+			}
+		}
+		result = result.Record(outcome, time.Now(), halfLife)
+		return put(bucket, nodeID, result)
+	})
+	return result, err
+}
+
+// Static check to ensure that JobStore implements jobstore.ReputationStore:
+var _ jobstore.ReputationStore = (*JobStore)(nil)
@@ -0,0 +1,95 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+func (d *JobStore) GetSchedule(_ context.Context, id string) (schedule model.JobSchedule, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		schedule, err = getSchedule(tx, id)
+		return err
+	})
+	return schedule, err
+}
+
+func getSchedule(tx *bolt.Tx, id string) (model.JobSchedule, error) {
+	if len(id) < model.ShortIDLength {
+		return model.JobSchedule{}, jobstore.NewErrScheduleNotFound(id)
+	}
+
+	bucket := tx.Bucket(schedulesBucket)
+	v := bucket.Get([]byte(id))
+
+	// support for short schedule IDs
+	if v == nil && jobutils.ShortID(id) == id {
+		k, kv := bucket.Cursor().Seek([]byte(id))
+		if k != nil && bytes.HasPrefix(k, []byte(id)) {
+			v = kv
+		}
+	}
+
+	if v == nil {
+		return model.JobSchedule{}, jobstore.NewErrScheduleNotFound(id)
+	}
+	var schedule model.JobSchedule
+	err := json.Unmarshal(v, &schedule)
+	return schedule, err
+}
+
+func (d *JobStore) GetSchedules(_ context.Context, clientID string) (result []model.JobSchedule, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(schedulesBucket).ForEach(func(_, v []byte) error {
+			var schedule model.JobSchedule
+			if err := json.Unmarshal(v, &schedule); err != nil {
+				return err
+			}
+			if clientID == "" || schedule.ClientID == clientID {
+				result = append(result, schedule)
+			}
+			return nil
+		})
+	})
+	jobstore.SortSchedules(result)
+	return result, err
+}
+
+func (d *JobStore) CreateSchedule(_ context.Context, schedule model.JobSchedule) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(schedulesBucket)
+		if bucket.Get([]byte(schedule.ID)) != nil {
+			return jobstore.NewErrScheduleAlreadyExists(schedule.ID)
+		}
+		return put(bucket, schedule.ID, schedule)
+	})
+}
+
+func (d *JobStore) UpdateSchedule(_ context.Context, schedule model.JobSchedule) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(schedulesBucket)
+		if bucket.Get([]byte(schedule.ID)) == nil {
+			return jobstore.NewErrScheduleNotFound(schedule.ID)
+		}
+		return put(bucket, schedule.ID, schedule)
+	})
+}
+
+func (d *JobStore) DeleteSchedule(_ context.Context, id string) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(schedulesBucket)
+		if bucket.Get([]byte(id)) == nil {
+			return jobstore.NewErrScheduleNotFound(id)
+		}
+		return bucket.Delete([]byte(id))
+	})
+}
+
+// Static check to ensure that JobStore implements jobstore.ScheduleStore:
+var _ jobstore.ScheduleStore = (*JobStore)(nil)
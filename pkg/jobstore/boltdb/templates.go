@@ -0,0 +1,56 @@
+package boltdb
+
+import (
+	"context"
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+func (d *JobStore) GetTemplate(_ context.Context, name string) (template model.JobTemplate, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(templatesBucket).Get([]byte(name))
+		if v == nil {
+			return jobstore.NewErrTemplateNotFound(name)
+		}
+		return json.Unmarshal(v, &template)
+	})
+	return template, err
+}
+
+func (d *JobStore) GetTemplates(_ context.Context) (result []model.JobTemplate, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(templatesBucket).ForEach(func(_, v []byte) error {
+			var template model.JobTemplate
+			if err := json.Unmarshal(v, &template); err != nil {
+				return err
+			}
+			result = append(result, template)
+			return nil
+		})
+	})
+	jobstore.SortTemplates(result)
+	return result, err
+}
+
+func (d *JobStore) SetTemplate(_ context.Context, template model.JobTemplate) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(templatesBucket), template.Name, template)
+	})
+}
+
+func (d *JobStore) DeleteTemplate(_ context.Context, name string) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(templatesBucket)
+		if bucket.Get([]byte(name)) == nil {
+			return jobstore.NewErrTemplateNotFound(name)
+		}
+		return bucket.Delete([]byte(name))
+	})
+}
+
+// Static check to ensure that JobStore implements jobstore.TemplateStore:
+var _ jobstore.TemplateStore = (*JobStore)(nil)
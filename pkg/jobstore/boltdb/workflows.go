@@ -0,0 +1,77 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+func (d *JobStore) GetWorkflow(_ context.Context, id string) (workflow model.Workflow, err error) {
+	if len(id) < model.ShortIDLength {
+		return model.Workflow{}, jobstore.NewErrWorkflowNotFound(id)
+	}
+	err = d.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(workflowsBucket)
+		v := bucket.Get([]byte(id))
+
+		// support for short workflow IDs
+		if v == nil && jobutils.ShortID(id) == id {
+			k, kv := bucket.Cursor().Seek([]byte(id))
+			if k != nil && bytes.HasPrefix(k, []byte(id)) {
+				v = kv
+			}
+		}
+
+		if v == nil {
+			return jobstore.NewErrWorkflowNotFound(id)
+		}
+		return json.Unmarshal(v, &workflow)
+	})
+	return workflow, err
+}
+
+func (d *JobStore) GetWorkflows(_ context.Context, clientID string) (result []model.Workflow, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(workflowsBucket).ForEach(func(_, v []byte) error {
+			var workflow model.Workflow
+			if err := json.Unmarshal(v, &workflow); err != nil {
+				return err
+			}
+			if clientID == "" || workflow.ClientID == clientID {
+				result = append(result, workflow)
+			}
+			return nil
+		})
+	})
+	jobstore.SortWorkflows(result)
+	return result, err
+}
+
+func (d *JobStore) CreateWorkflow(_ context.Context, workflow model.Workflow) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(workflowsBucket)
+		if bucket.Get([]byte(workflow.ID)) != nil {
+			return jobstore.NewErrWorkflowAlreadyExists(workflow.ID)
+		}
+		return put(bucket, workflow.ID, workflow)
+	})
+}
+
+func (d *JobStore) UpdateWorkflow(_ context.Context, workflow model.Workflow) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(workflowsBucket)
+		if bucket.Get([]byte(workflow.ID)) == nil {
+			return jobstore.NewErrWorkflowNotFound(workflow.ID)
+		}
+		return put(bucket, workflow.ID, workflow)
+	})
+}
+
+// Static check to ensure that JobStore implements jobstore.WorkflowStore:
+var _ jobstore.WorkflowStore = (*JobStore)(nil)
@@ -32,6 +32,32 @@ func (e ErrJobAlreadyExists) Error() string {
 	return "job already exists: " + e.JobID
 }
 
+// ErrScheduleNotFound is returned when the schedule is not found
+type ErrScheduleNotFound struct {
+	ScheduleID string
+}
+
+func NewErrScheduleNotFound(id string) ErrScheduleNotFound {
+	return ErrScheduleNotFound{ScheduleID: id}
+}
+
+func (e ErrScheduleNotFound) Error() string {
+	return "schedule not found: " + e.ScheduleID
+}
+
+// ErrScheduleAlreadyExists is returned when a schedule already exists
+type ErrScheduleAlreadyExists struct {
+	ScheduleID string
+}
+
+func NewErrScheduleAlreadyExists(id string) ErrScheduleAlreadyExists {
+	return ErrScheduleAlreadyExists{ScheduleID: id}
+}
+
+func (e ErrScheduleAlreadyExists) Error() string {
+	return "schedule already exists: " + e.ScheduleID
+}
+
 // ErrInvalidJobState is returned when an job is in an invalid state.
 type ErrInvalidJobState struct {
 	JobID    string
@@ -154,3 +180,68 @@ func (e ErrExecutionAlreadyTerminal) Error() string {
 	return fmt.Sprintf("execution %s is in terminal state %s and cannot transition to %s",
 		e.ExecutionID, e.Actual.String(), e.NewState.String())
 }
+
+// ErrWorkflowNotFound is returned when the workflow is not found
+type ErrWorkflowNotFound struct {
+	WorkflowID string
+}
+
+func NewErrWorkflowNotFound(id string) ErrWorkflowNotFound {
+	return ErrWorkflowNotFound{WorkflowID: id}
+}
+
+func (e ErrWorkflowNotFound) Error() string {
+	return "workflow not found: " + e.WorkflowID
+}
+
+// ErrWorkflowAlreadyExists is returned when a workflow already exists
+type ErrWorkflowAlreadyExists struct {
+	WorkflowID string
+}
+
+func NewErrWorkflowAlreadyExists(id string) ErrWorkflowAlreadyExists {
+	return ErrWorkflowAlreadyExists{WorkflowID: id}
+}
+
+func (e ErrWorkflowAlreadyExists) Error() string {
+	return "workflow already exists: " + e.WorkflowID
+}
+
+// ErrQuotaNotFound is returned when the client has no quota of its own
+type ErrQuotaNotFound struct {
+	ClientID string
+}
+
+func NewErrQuotaNotFound(clientID string) ErrQuotaNotFound {
+	return ErrQuotaNotFound{ClientID: clientID}
+}
+
+func (e ErrQuotaNotFound) Error() string {
+	return "quota not found for client: " + e.ClientID
+}
+
+// ErrNodeAccessRuleNotFound is returned when the node has no access rule
+type ErrNodeAccessRuleNotFound struct {
+	NodeID string
+}
+
+func NewErrNodeAccessRuleNotFound(nodeID string) ErrNodeAccessRuleNotFound {
+	return ErrNodeAccessRuleNotFound{NodeID: nodeID}
+}
+
+func (e ErrNodeAccessRuleNotFound) Error() string {
+	return "node access rule not found for node: " + e.NodeID
+}
+
+// ErrTemplateNotFound is returned when the template could not be found
+type ErrTemplateNotFound struct {
+	Name string
+}
+
+func NewErrTemplateNotFound(name string) ErrTemplateNotFound {
+	return ErrTemplateNotFound{Name: name}
+}
+
+func (e ErrTemplateNotFound) Error() string {
+	return "template not found: " + e.Name
+}
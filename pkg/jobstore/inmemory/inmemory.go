@@ -2,13 +2,11 @@ package inmemory
 
 import (
 	"context"
-	"sort"
 	"time"
 
 	sync "github.com/bacalhau-project/golang-mutex-tracer"
 	"github.com/imdario/mergo"
 	"golang.org/x/exp/maps"
-	"golang.org/x/exp/slices"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
 	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
@@ -20,19 +18,31 @@ const newJobComment = "Job created"
 
 type JobStore struct {
 	// we keep pointers to these things because we will update them partially
-	jobs       map[string]model.Job
-	states     map[string]model.JobState
-	history    map[string][]model.JobHistory
-	inprogress map[string]struct{}
-	mtx        sync.RWMutex
+	jobs        map[string]model.Job
+	states      map[string]model.JobState
+	history     map[string][]model.JobHistory
+	inprogress  map[string]struct{}
+	schedules   map[string]model.JobSchedule
+	workflows   map[string]model.Workflow
+	quotas      map[string]model.ClientQuota
+	templates   map[string]model.JobTemplate
+	reputations map[string]model.NodeReputation
+	nodeAccess  map[string]model.NodeAccessRule
+	mtx         sync.RWMutex
 }
 
 func NewJobStore() *JobStore {
 	res := &JobStore{
-		jobs:       make(map[string]model.Job),
-		states:     make(map[string]model.JobState),
-		history:    make(map[string][]model.JobHistory),
-		inprogress: make(map[string]struct{}),
+		jobs:        make(map[string]model.Job),
+		states:      make(map[string]model.JobState),
+		history:     make(map[string][]model.JobHistory),
+		inprogress:  make(map[string]struct{}),
+		schedules:   make(map[string]model.JobSchedule),
+		workflows:   make(map[string]model.Workflow),
+		quotas:      make(map[string]model.ClientQuota),
+		templates:   make(map[string]model.JobTemplate),
+		reputations: make(map[string]model.NodeReputation),
+		nodeAccess:  make(map[string]model.NodeAccessRule),
 	}
 	res.mtx.EnableTracerWithOpts(sync.Opts{
 		Threshold: 10 * time.Millisecond,
@@ -55,8 +65,6 @@ func (d *JobStore) GetJob(_ context.Context, id string) (model.Job, error) {
 func (d *JobStore) GetJobs(ctx context.Context, query jobstore.JobQuery) ([]model.Job, error) {
 	d.mtx.RLock()
 	defer d.mtx.RUnlock()
-	var result []model.Job
-
 	if query.ID != "" {
 		j, err := d.getJob(query.ID)
 		if err != nil {
@@ -65,58 +73,7 @@ func (d *JobStore) GetJobs(ctx context.Context, query jobstore.JobQuery) ([]mode
 		return []model.Job{j}, nil
 	}
 
-	for _, j := range maps.Values(d.jobs) {
-		if query.Limit > 0 && len(result) == query.Limit {
-			break
-		}
-
-		if !query.ReturnAll && query.ClientID != "" && query.ClientID != j.Metadata.ClientID {
-			// Job is not for the requesting client, so ignore it.
-			continue
-		}
-
-		// If we are not using include tags, by default every job is included.
-		// If a job is specifically included, that overrides it being excluded.
-		included := len(query.IncludeTags) == 0
-		for _, tag := range j.Spec.Annotations {
-			if slices.Contains(query.IncludeTags, model.IncludedTag(tag)) {
-				included = true
-				break
-			}
-			if slices.Contains(query.ExcludeTags, model.ExcludedTag(tag)) {
-				included = false
-				break
-			}
-		}
-
-		if !included {
-			continue
-		}
-
-		result = append(result, j)
-	}
-
-	listSorter := func(i, j int) bool {
-		switch query.SortBy {
-		case "id":
-			if query.SortReverse {
-				// what does it mean to sort by ID?
-				return result[i].Metadata.ID > result[j].Metadata.ID
-			} else {
-				return result[i].Metadata.ID < result[j].Metadata.ID
-			}
-		case "created_at":
-			if query.SortReverse {
-				return result[i].Metadata.CreatedAt.UTC().Unix() > result[j].Metadata.CreatedAt.UTC().Unix()
-			} else {
-				return result[i].Metadata.CreatedAt.UTC().Unix() < result[j].Metadata.CreatedAt.UTC().Unix()
-			}
-		default:
-			return false
-		}
-	}
-	sort.Slice(result, listSorter)
-	return result, nil
+	return jobstore.FilterJobs(query, maps.Values(d.jobs)), nil
 }
 
 func (d *JobStore) GetJobState(_ context.Context, jobID string) (model.JobState, error) {
@@ -305,10 +262,10 @@ func (d *JobStore) UpdateExecution(_ context.Context, request jobstore.UpdateExe
 	// populate default values
 	newExecution := request.NewValues
 	if newExecution.CreateTime.IsZero() {
-		newExecution.CreateTime = time.Now()
+		newExecution.CreateTime = existingExecution.CreateTime
 	}
 	if newExecution.UpdateTime.IsZero() {
-		newExecution.UpdateTime = existingExecution.CreateTime
+		newExecution.UpdateTime = time.Now()
 	}
 	if newExecution.Version == 0 {
 		newExecution.Version = existingExecution.Version + 1
@@ -0,0 +1,39 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+func (d *JobStore) GetNodeAccessRules(_ context.Context) ([]model.NodeAccessRule, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	result := make([]model.NodeAccessRule, 0, len(d.nodeAccess))
+	for _, rule := range d.nodeAccess {
+		result = append(result, rule)
+	}
+	jobstore.SortNodeAccessRules(result)
+	return result, nil
+}
+
+func (d *JobStore) SetNodeAccessRule(_ context.Context, rule model.NodeAccessRule) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.nodeAccess[rule.NodeID] = rule
+	return nil
+}
+
+func (d *JobStore) DeleteNodeAccessRule(_ context.Context, nodeID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.nodeAccess[nodeID]; !ok {
+		return jobstore.NewErrNodeAccessRuleNotFound(nodeID)
+	}
+	delete(d.nodeAccess, nodeID)
+	return nil
+}
+
+// Static check to ensure that JobStore implements jobstore.NodeAccessStore:
+var _ jobstore.NodeAccessStore = (*JobStore)(nil)
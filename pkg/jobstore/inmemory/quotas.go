@@ -0,0 +1,49 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+func (d *JobStore) GetQuota(_ context.Context, clientID string) (model.ClientQuota, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	quota, ok := d.quotas[clientID]
+	if !ok {
+		return model.ClientQuota{}, jobstore.NewErrQuotaNotFound(clientID)
+	}
+	return quota, nil
+}
+
+func (d *JobStore) GetQuotas(_ context.Context) ([]model.ClientQuota, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	result := make([]model.ClientQuota, 0, len(d.quotas))
+	for _, quota := range d.quotas {
+		result = append(result, quota)
+	}
+	jobstore.SortQuotas(result)
+	return result, nil
+}
+
+func (d *JobStore) SetQuota(_ context.Context, quota model.ClientQuota) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.quotas[quota.ClientID] = quota
+	return nil
+}
+
+func (d *JobStore) DeleteQuota(_ context.Context, clientID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.quotas[clientID]; !ok {
+		return jobstore.NewErrQuotaNotFound(clientID)
+	}
+	delete(d.quotas, clientID)
+	return nil
+}
+
+// Static check to ensure that JobStore implements jobstore.QuotaStore:
+var _ jobstore.QuotaStore = (*JobStore)(nil)
@@ -0,0 +1,36 @@
+package inmemory
+
+import (
+	"context"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+func (d *JobStore) GetReputations(_ context.Context) ([]model.NodeReputation, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	result := make([]model.NodeReputation, 0, len(d.reputations))
+	for _, reputation := range d.reputations {
+		result = append(result, reputation)
+	}
+	jobstore.SortReputations(result)
+	return result, nil
+}
+
+func (d *JobStore) RecordOutcome(
+	_ context.Context, nodeID string, outcome model.ExecutionOutcome, halfLife time.Duration) (model.NodeReputation, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	reputation, ok := d.reputations[nodeID]
+	if !ok {
+		reputation = model.NodeReputation{NodeID: nodeID}
+	}
+	reputation = reputation.Record(outcome, time.Now(), halfLife)
+	d.reputations[nodeID] = reputation
+	return reputation, nil
+}
+
+// Static check to ensure that JobStore implements jobstore.ReputationStore:
+var _ jobstore.ReputationStore = (*JobStore)(nil)
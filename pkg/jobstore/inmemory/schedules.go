@@ -0,0 +1,83 @@
+package inmemory
+
+import (
+	"context"
+
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+func (d *JobStore) GetSchedule(_ context.Context, id string) (model.JobSchedule, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	return d.getSchedule(id)
+}
+
+func (d *JobStore) getSchedule(id string) (model.JobSchedule, error) {
+	if len(id) < model.ShortIDLength {
+		return model.JobSchedule{}, jobstore.NewErrScheduleNotFound(id)
+	}
+
+	// support for short schedule IDs
+	if jobutils.ShortID(id) == id {
+		for k := range d.schedules {
+			if jobutils.ShortID(k) == id {
+				id = k
+				break
+			}
+		}
+	}
+
+	schedule, ok := d.schedules[id]
+	if !ok {
+		return model.JobSchedule{}, jobstore.NewErrScheduleNotFound(id)
+	}
+	return schedule, nil
+}
+
+func (d *JobStore) GetSchedules(_ context.Context, clientID string) ([]model.JobSchedule, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	var result []model.JobSchedule
+	for _, schedule := range d.schedules {
+		if clientID == "" || schedule.ClientID == clientID {
+			result = append(result, schedule)
+		}
+	}
+	jobstore.SortSchedules(result)
+	return result, nil
+}
+
+func (d *JobStore) CreateSchedule(_ context.Context, schedule model.JobSchedule) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.schedules[schedule.ID]; ok {
+		return jobstore.NewErrScheduleAlreadyExists(schedule.ID)
+	}
+	d.schedules[schedule.ID] = schedule
+	return nil
+}
+
+func (d *JobStore) UpdateSchedule(_ context.Context, schedule model.JobSchedule) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.schedules[schedule.ID]; !ok {
+		return jobstore.NewErrScheduleNotFound(schedule.ID)
+	}
+	d.schedules[schedule.ID] = schedule
+	return nil
+}
+
+func (d *JobStore) DeleteSchedule(_ context.Context, id string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.schedules[id]; !ok {
+		return jobstore.NewErrScheduleNotFound(id)
+	}
+	delete(d.schedules, id)
+	return nil
+}
+
+// Static check to ensure that JobStore implements jobstore.ScheduleStore:
+var _ jobstore.ScheduleStore = (*JobStore)(nil)
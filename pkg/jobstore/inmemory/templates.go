@@ -0,0 +1,49 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+func (d *JobStore) GetTemplate(_ context.Context, name string) (model.JobTemplate, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	template, ok := d.templates[name]
+	if !ok {
+		return model.JobTemplate{}, jobstore.NewErrTemplateNotFound(name)
+	}
+	return template, nil
+}
+
+func (d *JobStore) GetTemplates(_ context.Context) ([]model.JobTemplate, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	result := make([]model.JobTemplate, 0, len(d.templates))
+	for _, template := range d.templates {
+		result = append(result, template)
+	}
+	jobstore.SortTemplates(result)
+	return result, nil
+}
+
+func (d *JobStore) SetTemplate(_ context.Context, template model.JobTemplate) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.templates[template.Name] = template
+	return nil
+}
+
+func (d *JobStore) DeleteTemplate(_ context.Context, name string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.templates[name]; !ok {
+		return jobstore.NewErrTemplateNotFound(name)
+	}
+	delete(d.templates, name)
+	return nil
+}
+
+// Static check to ensure that JobStore implements jobstore.TemplateStore:
+var _ jobstore.TemplateStore = (*JobStore)(nil)
@@ -0,0 +1,69 @@
+package inmemory
+
+import (
+	"context"
+
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+func (d *JobStore) GetWorkflow(_ context.Context, id string) (model.Workflow, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	if len(id) < model.ShortIDLength {
+		return model.Workflow{}, jobstore.NewErrWorkflowNotFound(id)
+	}
+
+	// support for short workflow IDs
+	if jobutils.ShortID(id) == id {
+		for k := range d.workflows {
+			if jobutils.ShortID(k) == id {
+				id = k
+				break
+			}
+		}
+	}
+
+	workflow, ok := d.workflows[id]
+	if !ok {
+		return model.Workflow{}, jobstore.NewErrWorkflowNotFound(id)
+	}
+	return workflow, nil
+}
+
+func (d *JobStore) GetWorkflows(_ context.Context, clientID string) ([]model.Workflow, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	var result []model.Workflow
+	for _, workflow := range d.workflows {
+		if clientID == "" || workflow.ClientID == clientID {
+			result = append(result, workflow)
+		}
+	}
+	jobstore.SortWorkflows(result)
+	return result, nil
+}
+
+func (d *JobStore) CreateWorkflow(_ context.Context, workflow model.Workflow) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.workflows[workflow.ID]; ok {
+		return jobstore.NewErrWorkflowAlreadyExists(workflow.ID)
+	}
+	d.workflows[workflow.ID] = workflow
+	return nil
+}
+
+func (d *JobStore) UpdateWorkflow(_ context.Context, workflow model.Workflow) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.workflows[workflow.ID]; !ok {
+		return jobstore.NewErrWorkflowNotFound(workflow.ID)
+	}
+	d.workflows[workflow.ID] = workflow
+	return nil
+}
+
+// Static check to ensure that JobStore implements jobstore.WorkflowStore:
+var _ jobstore.WorkflowStore = (*JobStore)(nil)
@@ -0,0 +1,108 @@
+package jobstore
+
+import (
+	"sort"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"golang.org/x/exp/slices"
+)
+
+// FilterJobs returns the jobs that match the query, sorted as it asks, so
+// that stores that can't filter jobs themselves answer queries the same way.
+func FilterJobs(query JobQuery, jobs []model.Job) []model.Job {
+	var result []model.Job
+	for _, j := range jobs {
+		if query.Limit > 0 && len(result) == query.Limit {
+			break
+		}
+
+		if !query.ReturnAll && query.ClientID != "" && query.ClientID != j.Metadata.ClientID {
+			// Job is not for the requesting client, so ignore it.
+			continue
+		}
+
+		// If we are not using include tags, by default every job is included.
+		// If a job is specifically included, that overrides it being excluded.
+		included := len(query.IncludeTags) == 0
+		for _, tag := range j.Spec.Annotations {
+			if slices.Contains(query.IncludeTags, model.IncludedTag(tag)) {
+				included = true
+				break
+			}
+			if slices.Contains(query.ExcludeTags, model.ExcludedTag(tag)) {
+				included = false
+				break
+			}
+		}
+
+		if !included {
+			continue
+		}
+
+		result = append(result, j)
+	}
+
+	listSorter := func(i, j int) bool {
+		switch query.SortBy {
+		case "id":
+			if query.SortReverse {
+				// what does it mean to sort by ID?
+				return result[i].Metadata.ID > result[j].Metadata.ID
+			} else {
+				return result[i].Metadata.ID < result[j].Metadata.ID
+			}
+		case "created_at":
+			if query.SortReverse {
+				return result[i].Metadata.CreatedAt.UTC().Unix() > result[j].Metadata.CreatedAt.UTC().Unix()
+			} else {
+				return result[i].Metadata.CreatedAt.UTC().Unix() < result[j].Metadata.CreatedAt.UTC().Unix()
+			}
+		default:
+			return false
+		}
+	}
+	sort.Slice(result, listSorter)
+	return result
+}
+
+// SortSchedules sorts the schedules by when they were created, oldest first.
+func SortSchedules(schedules []model.JobSchedule) {
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].CreatedAt.Before(schedules[j].CreatedAt)
+	})
+}
+
+// SortWorkflows sorts the workflows by when they were created, oldest first.
+func SortWorkflows(workflows []model.Workflow) {
+	sort.Slice(workflows, func(i, j int) bool {
+		return workflows[i].CreatedAt.Before(workflows[j].CreatedAt)
+	})
+}
+
+// SortTemplates sorts the templates by name.
+func SortTemplates(templates []model.JobTemplate) {
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+}
+
+// SortQuotas sorts the quotas by the client they are for.
+func SortQuotas(quotas []model.ClientQuota) {
+	sort.Slice(quotas, func(i, j int) bool {
+		return quotas[i].ClientID < quotas[j].ClientID
+	})
+}
+
+// SortNodeAccessRules sorts the rules by the node they are for.
+func SortNodeAccessRules(rules []model.NodeAccessRule) {
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].NodeID < rules[j].NodeID
+	})
+}
+
+// SortReputations sorts the reputations by the node they are of.
+func SortReputations(reputations []model.NodeReputation) {
+	sort.Slice(reputations, func(i, j int) bool {
+		return reputations[i].NodeID < reputations[j].NodeID
+	})
+}
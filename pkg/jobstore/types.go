@@ -2,6 +2,7 @@ package jobstore
 
 import (
 	"context"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 )
@@ -36,6 +37,76 @@ type Store interface {
 	UpdateExecution(ctx context.Context, request UpdateExecutionRequest) error
 }
 
+// A ScheduleStore persists the schedules of jobs that the requester submits
+// each time their cron expression fires.
+type ScheduleStore interface {
+	// GetSchedule gets the schedule with the id, which can be a short id.
+	GetSchedule(ctx context.Context, id string) (model.JobSchedule, error)
+	// GetSchedules gets the schedules of the client, or of all clients if the
+	// client id is empty.
+	GetSchedules(ctx context.Context, clientID string) ([]model.JobSchedule, error)
+	CreateSchedule(ctx context.Context, schedule model.JobSchedule) error
+	// UpdateSchedule replaces the schedule with the same id.
+	UpdateSchedule(ctx context.Context, schedule model.JobSchedule) error
+	DeleteSchedule(ctx context.Context, id string) error
+}
+
+// A WorkflowStore persists the workflows whose stages the requester submits
+// once the stages they depend on have completed.
+type WorkflowStore interface {
+	// GetWorkflow gets the workflow with the id, which can be a short id.
+	GetWorkflow(ctx context.Context, id string) (model.Workflow, error)
+	// GetWorkflows gets the workflows of the client, or of all clients if the
+	// client id is empty.
+	GetWorkflows(ctx context.Context, clientID string) ([]model.Workflow, error)
+	CreateWorkflow(ctx context.Context, workflow model.Workflow) error
+	// UpdateWorkflow replaces the workflow with the same id.
+	UpdateWorkflow(ctx context.Context, workflow model.Workflow) error
+}
+
+// A QuotaStore persists the quotas of the clients that have their own, rather
+// than the requester's default quota.
+type QuotaStore interface {
+	GetQuota(ctx context.Context, clientID string) (model.ClientQuota, error)
+	GetQuotas(ctx context.Context) ([]model.ClientQuota, error)
+	// SetQuota creates the quota of its client, or replaces it if it exists.
+	SetQuota(ctx context.Context, quota model.ClientQuota) error
+	DeleteQuota(ctx context.Context, clientID string) error
+}
+
+// A TemplateStore persists the job templates that clients submit jobs from.
+type TemplateStore interface {
+	GetTemplate(ctx context.Context, name string) (model.JobTemplate, error)
+	GetTemplates(ctx context.Context) ([]model.JobTemplate, error)
+	// SetTemplate creates the template, or replaces it if one with the same
+	// name exists.
+	SetTemplate(ctx context.Context, template model.JobTemplate) error
+	DeleteTemplate(ctx context.Context, name string) error
+}
+
+// A ReputationStore persists how reliably each compute node has run the
+// executions that the requester gave it.
+type ReputationStore interface {
+	// GetReputations gets the reputations of the nodes that have run any
+	// executions, as they were when last updated.
+	GetReputations(ctx context.Context) ([]model.NodeReputation, error)
+	// RecordOutcome counts the outcome of an execution in the reputation of
+	// the node, after decaying the reputation by the half life, and returns
+	// the reputation as it is afterwards.
+	RecordOutcome(
+		ctx context.Context, nodeID string, outcome model.ExecutionOutcome, halfLife time.Duration) (model.NodeReputation, error)
+}
+
+// A NodeAccessStore persists the rules that admins set at runtime to allow or
+// deny scheduling executions on compute nodes.
+type NodeAccessStore interface {
+	GetNodeAccessRules(ctx context.Context) ([]model.NodeAccessRule, error)
+	// SetNodeAccessRule creates the rule for its node, or replaces it if it
+	// exists.
+	SetNodeAccessRule(ctx context.Context, rule model.NodeAccessRule) error
+	DeleteNodeAccessRule(ctx context.Context, nodeID string) error
+}
+
 type UpdateJobStateRequest struct {
 	JobID     string
 	Condition UpdateJobCondition
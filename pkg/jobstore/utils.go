@@ -15,22 +15,24 @@ func GetStateResolver(db Store) *jobutils.StateResolver {
 	)
 }
 
-// StopJob a helper function to fail a job and all its executions.
-func StopJob(ctx context.Context, db Store, jobID string, reason string, userRequested bool) ([]model.ExecutionState, error) {
+// StopJob a helper function to move a job to a terminal state, e.g. failed or cancelled, and cancel all its executions.
+func StopJob(ctx context.Context, db Store, jobID string, reason string, newJobState model.JobStateType) ([]model.ExecutionState, error) {
 	// update job state
-	newJobState := model.JobStateError
-	unexpectedJobState := model.JobStateCancelled
-	if userRequested {
-		newJobState = model.JobStateCancelled
-		unexpectedJobState = model.JobStateError
+	var unexpectedJobStates []model.JobStateType
+	for _, state := range []model.JobStateType{
+		model.JobStateCompleted,
+		model.JobStateCancelled,
+		model.JobStateError,
+		model.JobStateTimedOut,
+	} {
+		if state != newJobState {
+			unexpectedJobStates = append(unexpectedJobStates, state)
+		}
 	}
 	err := db.UpdateJobState(ctx, UpdateJobStateRequest{
 		JobID: jobID,
 		Condition: UpdateJobCondition{
-			UnexpectedStates: []model.JobStateType{
-				model.JobStateCompleted,
-				unexpectedJobState,
-			},
+			UnexpectedStates: unexpectedJobStates,
 		},
 		NewState: newJobState,
 		Comment:  reason,
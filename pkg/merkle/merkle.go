@@ -0,0 +1,151 @@
+// Package merkle computes the root of a Merkle tree over the files of job
+// results when they are published, so that clients can check that the
+// results they download are the ones that were published.
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const rootPrefix = "sha256:"
+
+// Domain separation of the hashes of leaves and nodes, so that a node can't be
+// passed off as a leaf, nor a symlink as a file.
+const (
+	leafPrefix byte = 0
+	nodePrefix byte = 1
+	linkPrefix byte = 2
+)
+
+// Root returns the root of a Merkle tree over the regular files and symlinks
+// in the directory, as sha256:<hex>. The leaves of the tree hash the path of
+// each file relative to the directory and the hash of its contents, or the
+// target of each symlink, in the order of their paths, so that renaming,
+// adding, removing or relinking a file changes the root. It returns an error
+// if the directory holds anything else, such as a device or a named pipe,
+// which the root couldn't vouch for.
+func Root(dir string) (string, error) {
+	leaves, err := leafHashes(dir)
+	if err != nil {
+		return "", err
+	}
+	return rootPrefix + hex.EncodeToString(treeRoot(leaves)), nil
+}
+
+// Verify returns an error if the Merkle root of the files in the directory
+// isn't the expected root.
+func Verify(dir, root string) error {
+	if !strings.HasPrefix(root, rootPrefix) {
+		return fmt.Errorf("unsupported merkle root %q, which should start with %s", root, rootPrefix)
+	}
+	actual, err := Root(dir)
+	if err != nil {
+		return err
+	}
+	if actual != root {
+		return fmt.Errorf("merkle root of %s is %s rather than the published %s, so the results may have been tampered with",
+			dir, actual, root)
+	}
+	return nil
+}
+
+func leafHashes(dir string) ([][]byte, error) {
+	var paths []string
+	links := make(map[string]bool)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case d.Type().IsRegular():
+		case d.Type()&fs.ModeSymlink != 0:
+			links[rel] = true
+		default:
+			return fmt.Errorf("%s is neither a regular file, a directory nor a symlink", path)
+		}
+		paths = append(paths, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	leaves := make([][]byte, 0, len(paths))
+	for _, path := range paths {
+		h := sha256.New()
+		if links[path] {
+			target, err := os.Readlink(filepath.Join(dir, filepath.FromSlash(path)))
+			if err != nil {
+				return nil, err
+			}
+			h.Write([]byte{linkPrefix})
+			_, _ = fmt.Fprintf(h, "%d:%s%d:%s", len(path), path, len(target), target)
+		} else {
+			contentHash, err := hashFile(filepath.Join(dir, filepath.FromSlash(path)))
+			if err != nil {
+				return nil, err
+			}
+			h.Write([]byte{leafPrefix})
+			_, _ = fmt.Fprintf(h, "%d:%s", len(path), path)
+			h.Write(contentHash)
+		}
+		leaves = append(leaves, h.Sum(nil))
+	}
+	return leaves, nil
+}
+
+func hashFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// treeRoot hashes pairs of nodes up to the root, promoting the last node of a
+// level with an odd number of them. The root of no leaves is the hash of
+// nothing.
+func treeRoot(level [][]byte) []byte {
+	if len(level) == 0 {
+		empty := sha256.Sum256(nil)
+		return empty[:]
+	}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			h := sha256.New()
+			h.Write([]byte{nodePrefix})
+			h.Write(level[i])
+			h.Write(level[i+1])
+			next = append(next, h.Sum(nil))
+		}
+		level = next
+	}
+	return level[0]
+}
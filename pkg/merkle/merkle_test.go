@@ -0,0 +1,68 @@
+//go:build unit || !integration
+
+package merkle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootAndVerify(t *testing.T) {
+	results := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(results, "stdout"), []byte("hello"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(results, "exitCode"), []byte("0"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(results, "outputs", "empty"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(results, "outputs", "data.csv"), []byte("a,b\n"), 0644))
+
+	root, err := Root(results)
+	require.NoError(t, err)
+	require.Regexp(t, "^sha256:[0-9a-f]{64}$", root)
+	require.NoError(t, Verify(results, root))
+
+	again, err := Root(results)
+	require.NoError(t, err)
+	require.Equal(t, root, again, "the root doesn't depend on the order files are walked in")
+
+	require.NoError(t, os.WriteFile(filepath.Join(results, "outputs", "data.csv"), []byte("a,c\n"), 0644))
+	require.Error(t, Verify(results, root), "a file was changed")
+
+	require.NoError(t, os.WriteFile(filepath.Join(results, "outputs", "data.csv"), []byte("a,b\n"), 0644))
+	require.NoError(t, os.Rename(filepath.Join(results, "stdout"), filepath.Join(results, "stderr")))
+	require.Error(t, Verify(results, root), "a file was renamed")
+
+	require.NoError(t, os.Rename(filepath.Join(results, "stderr"), filepath.Join(results, "stdout")))
+	require.NoError(t, Verify(results, root))
+	require.NoError(t, os.WriteFile(filepath.Join(results, "extra"), nil, 0644))
+	require.Error(t, Verify(results, root), "a file was added")
+
+	require.Error(t, Verify(results, "md5:abc"))
+}
+
+func TestEmptyRoot(t *testing.T) {
+	root, err := Root(t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", root)
+}
+
+func TestRootCoversSymlinks(t *testing.T) {
+	results := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(results, "stdout"), []byte("hello"), 0644))
+	root, err := Root(results)
+	require.NoError(t, err)
+
+	require.NoError(t, os.Symlink("/etc/passwd", filepath.Join(results, "link")))
+	require.Error(t, Verify(results, root), "a symlink was added")
+
+	linked, err := Root(results)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(results, "link")))
+	require.NoError(t, os.Symlink("/etc/shadow", filepath.Join(results, "link")))
+	require.Error(t, Verify(results, linked), "a symlink was relinked")
+
+	require.NoError(t, os.Remove(filepath.Join(results, "link")))
+	require.NoError(t, os.WriteFile(filepath.Join(results, "link"), []byte("/etc/shadow"), 0644))
+	require.Error(t, Verify(results, linked), "a symlink was replaced by a file")
+}
@@ -0,0 +1,18 @@
+//go:build (unit || !integration) && unix
+
+package merkle
+
+import (
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootRejectsOtherFileTypes(t *testing.T) {
+	results := t.TempDir()
+	require.NoError(t, syscall.Mkfifo(filepath.Join(results, "pipe"), 0644))
+	_, err := Root(results)
+	require.Error(t, err)
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// AdminRequestTTL is how long a node accepts an admin request after the
+// client makes it.
+const AdminRequestTTL = 5 * time.Minute
+
+// AdminRequest is part of the payload of every request that only admins can
+// make. It ties the signed payload to the node it is for and to a short time,
+// and makes it unique, so that a captured request can't be replayed later or
+// against another node.
+type AdminRequest struct {
+	// the id of the node that the request is for
+	TargetNodeID string `json:"TargetNodeID,omitempty" validate:"required"`
+
+	// when the node stops accepting the request
+	ExpiresAt time.Time `json:"ExpiresAt,omitempty" validate:"required"`
+
+	// a random value, so that the node can accept the request only once
+	Nonce string `json:"Nonce,omitempty" validate:"required"`
+}
+
+// NewAdminRequest returns an admin request for the node that expires after
+// AdminRequestTTL.
+func NewAdminRequest(targetNodeID string) (AdminRequest, error) {
+	nonce, err := NewNonce()
+	if err != nil {
+		return AdminRequest{}, err
+	}
+	return AdminRequest{
+		TargetNodeID: targetNodeID,
+		ExpiresAt:    time.Now().Add(AdminRequestTTL).UTC(),
+		Nonce:        nonce,
+	}, nil
+}
+
+// NewNonce returns a random value that makes a signed payload unique.
+func NewNonce() (string, error) {
+	nonce := make([]byte, 16) //nolint:gomnd
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("error generating nonce: %w", err)
+	}
+	return hex.EncodeToString(nonce), nil
+}
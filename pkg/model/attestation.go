@@ -0,0 +1,75 @@
+package model
+
+import (
+	"crypto/sha512"
+	"fmt"
+)
+
+// TEEType is a kind of trusted execution environment, such as a confidential
+// VM, that a compute node runs in and that a job can require to run in.
+type TEEType string
+
+const (
+	// TEEAny is for jobs that can run in any of the kinds of TEE.
+	TEEAny TEEType = "any"
+
+	// TEESEVSNP is an AMD SEV-SNP confidential VM.
+	TEESEVSNP TEEType = "sev-snp"
+
+	// TEETDX is an Intel TDX trust domain.
+	TEETDX TEEType = "tdx"
+)
+
+// TEETypes are the kinds of TEE that compute nodes can run in.
+func TEETypes() []TEEType {
+	return []TEEType{TEESEVSNP, TEETDX}
+}
+
+func ParseTEEType(s string) (TEEType, error) {
+	for _, typ := range append(TEETypes(), TEEAny) {
+		if equal(string(typ), s) {
+			return typ, nil
+		}
+	}
+	return "", fmt.Errorf("%T: unknown type '%s'", TEEAny, s)
+}
+
+// Satisfies returns whether a node that runs in this kind of TEE can run jobs
+// that require the given kind.
+func (t TEEType) Satisfies(required TEEType) bool {
+	if t == "" || t == TEEAny {
+		return false
+	}
+	return required == TEEAny || required == t
+}
+
+// Attestation is evidence from the hardware of a compute node's TEE that the
+// node runs in it, which the node attaches to its bids on jobs that require
+// attested execution.
+type Attestation struct {
+	// Type is the kind of TEE that the node says produced the evidence, which
+	// requesters check against the evidence itself
+	Type TEEType `json:"Type"`
+	// Provider is what produced the evidence on the node, such as the
+	// sev_guest or tdx_guest kernel driver
+	Provider string `json:"Provider,omitempty"`
+	// Evidence is the attestation report of a SEV-SNP VM or the quote of a
+	// TDX trust domain, signed by keys rooted in the hardware vendor's
+	// certificates
+	Evidence []byte `json:"Evidence"`
+	// Certificates are the DER certificates that the evidence is verified
+	// with, leaf first, if the evidence doesn't carry them itself: the VCEK,
+	// ASK and ARK of a SEV-SNP VM
+	Certificates [][]byte `json:"Certificates,omitempty"`
+	// ReportData is what the node asked the hardware to sign into the
+	// evidence, which binds it to the job and the node
+	ReportData []byte `json:"ReportData"`
+}
+
+// AttestationReportData returns the report data that binds the evidence of a
+// node's TEE to its bid on a job, so that evidence can't be replayed for other
+// jobs or by other nodes.
+func AttestationReportData(jobID, nodeID string) []byte {
+	data := sha512.Sum512([]byte(jobID + "\x00" + nodeID))
+	return data[:]
+}
@@ -0,0 +1,19 @@
+//go:build unit || !integration
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTEEType(t *testing.T) {
+	for _, typ := range []TEEType{TEEAny, TEESEVSNP, TEETDX} {
+		parsed, err := ParseTEEType(string(typ))
+		require.NoError(t, err)
+		require.Equal(t, typ, parsed)
+	}
+	_, err := ParseTEEType("sgx")
+	require.Error(t, err)
+}
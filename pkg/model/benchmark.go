@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// NodeBenchmark is how fast a compute node measured itself to be, which the
+// requester estimates how long jobs take to complete on the node from.
+type NodeBenchmark struct {
+	// CPUScore is how many MB a second a single core of the node hashes with
+	// SHA-256, or zero if it wasn't measured
+	CPUScore float64 `json:"CPUScore,omitempty"`
+	// DiskThroughput is how many bytes a second the node writes to the disk
+	// that inputs are prepared on, or zero if it wasn't measured
+	DiskThroughput float64 `json:"DiskThroughput,omitempty"`
+	// NetworkBandwidth is how many bytes a second the node downloads, or zero
+	// if it wasn't measured
+	NetworkBandwidth float64 `json:"NetworkBandwidth,omitempty"`
+	// BenchmarkedAt is when the node was benchmarked
+	BenchmarkedAt time.Time `json:"BenchmarkedAt,omitempty"`
+}
+
+// IsZero returns whether the node wasn't benchmarked.
+func (b NodeBenchmark) IsZero() bool {
+	return b.CPUScore == 0 && b.DiskThroughput == 0 && b.NetworkBandwidth == 0
+}
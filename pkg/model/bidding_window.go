@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// BiddingWindowOverride opens or closes the bidding windows of a compute node
+// for a while, such as to take on jobs while a workstation that normally only
+// bids at night is idle.
+type BiddingWindowOverride struct {
+	// Open is whether the node bids on jobs while the override lasts
+	Open bool `json:"Open"`
+	// Until is when the node follows its bidding windows again, or never if zero
+	Until time.Time `json:"Until,omitempty"`
+}
+
+// BiddingWindowStatus is whether a compute node is bidding on jobs given its
+// bidding windows and any override of them.
+type BiddingWindowStatus struct {
+	// Open is whether the node bids on jobs now
+	Open bool `json:"Open"`
+	// Windows are the times that the node bids on jobs, or all the time if empty
+	Windows []string `json:"Windows,omitempty"`
+	// Location is the time zone of the windows
+	Location string `json:"Location"`
+	// Override is the override of the windows, if there is one
+	Override *BiddingWindowOverride `json:"Override,omitempty"`
+}
+
+type BiddingWindowPayload struct {
+	// the id of the client that is overriding the windows, which must be an admin
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the compute node whose windows to override, and when the request expires
+	AdminRequest
+
+	// the override of the node's bidding windows, or none to follow them again
+	Override *BiddingWindowOverride `json:"Override,omitempty"`
+}
+
+func (p BiddingWindowPayload) GetClientID() string {
+	return p.ClientID
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// ClientOutcome is how an execution that a compute node ran for a client
+// ended, as far as the reputation of the client is concerned.
+type ClientOutcome string
+
+const (
+	// ClientOutcomeCompleted is when the results of the execution were
+	// accepted.
+	ClientOutcomeCompleted ClientOutcome = "Completed"
+	// ClientOutcomeCancelled is when the client cancelled the job while the
+	// node was running it.
+	ClientOutcomeCancelled ClientOutcome = "Cancelled"
+	// ClientOutcomeVerificationFailed is when the verifier rejected the
+	// results of the execution.
+	ClientOutcomeVerificationFailed ClientOutcome = "VerificationFailed"
+	// ClientOutcomeResourceOverrun is when the execution used more of a
+	// resource than the job declared.
+	ClientOutcomeResourceOverrun ClientOutcome = "ResourceOverrun"
+)
+
+// ClientReputation is how the jobs of a client have treated a compute node.
+// The counts decay over time, like those of NodeReputation, so that clients
+// that stop abusing the node recover.
+type ClientReputation struct {
+	// ClientID is the id of the client
+	ClientID string `json:"ClientID"`
+	// Completions is the decayed count of executions whose results were
+	// accepted
+	Completions float64 `json:"Completions"`
+	// Cancellations is the decayed count of executions that the client
+	// cancelled while the node was running them
+	Cancellations float64 `json:"Cancellations"`
+	// VerificationFailures is the decayed count of executions whose results
+	// the verifier rejected
+	VerificationFailures float64 `json:"VerificationFailures"`
+	// ResourceOverruns is the decayed count of executions that used more
+	// resources than their jobs declared
+	ResourceOverruns float64 `json:"ResourceOverruns"`
+	// UpdatedAt is when the counts were last decayed
+	UpdatedAt time.Time `json:"UpdatedAt"`
+}
+
+// Decay returns the reputation with its counts halved for each half life
+// that passed since it was last updated.
+func (r ClientReputation) Decay(now time.Time, halfLife time.Duration) ClientReputation {
+	if halfLife > 0 && !r.UpdatedAt.IsZero() && now.After(r.UpdatedAt) {
+		factor := math.Pow(0.5, float64(now.Sub(r.UpdatedAt))/float64(halfLife)) //nolint:gomnd // halving
+		r.Completions *= factor
+		r.Cancellations *= factor
+		r.VerificationFailures *= factor
+		r.ResourceOverruns *= factor
+	}
+	r.UpdatedAt = now
+	return r
+}
+
+// Record returns the reputation decayed to the time, with the outcome counted.
+func (r ClientReputation) Record(outcome ClientOutcome, now time.Time, halfLife time.Duration) ClientReputation {
+	r = r.Decay(now, halfLife)
+	switch outcome {
+	case ClientOutcomeCompleted:
+		r.Completions++
+	case ClientOutcomeCancelled:
+		r.Cancellations++
+	case ClientOutcomeVerificationFailed:
+		r.VerificationFailures++
+	case ClientOutcomeResourceOverrun:
+		r.ResourceOverruns++
+	}
+	return r
+}
+
+// Abuses returns the decayed count of the executions that the client
+// cancelled, that failed verification or that overran their resources.
+func (r ClientReputation) Abuses() float64 {
+	return r.Cancellations + r.VerificationFailures + r.ResourceOverruns
+}
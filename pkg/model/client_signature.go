@@ -0,0 +1,15 @@
+package model
+
+import "encoding/json"
+
+// ClientSignature is a client's signature over the payload of a request it
+// made, which proves that the client with the public key made the request.
+type ClientSignature struct {
+	// Payload is the JSON that the client signed
+	Payload json.RawMessage `json:"Payload"`
+	// Signature is the base64 encoding of the client's signature of the payload
+	Signature string `json:"Signature"`
+	// PublicKey is the base64 encoding of the client's public key, which the
+	// client's ID is derived from
+	PublicKey string `json:"PublicKey"`
+}
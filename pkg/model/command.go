@@ -18,6 +18,12 @@ type RunCommandResult struct {
 
 	// Runner error
 	ErrorMsg string `json:"runnerError"`
+
+	// digest of the image the job ran in, for engines that run images.
+	ImageDigest string `json:"imageDigest,omitempty"`
+
+	// peak bytes of scratch space the execution used, if it was measured.
+	DiskUsage uint64 `json:"diskUsage,omitempty"`
 }
 
 func NewRunCommandResult() *RunCommandResult {
@@ -7,6 +7,7 @@ const (
 	DownloadFilenameStderr   = "stderr"
 	DownloadFilenameExitCode = "exitCode"
 	DownloadCIDsFolderName   = "raw"
+	DownloadCARFilename      = "results.car"
 	DownloadFolderPerm       = 0755
 	DownloadFilePerm         = 0644
 	DefaultIPFSTimeout       = 5 * time.Minute
@@ -17,4 +18,17 @@ type DownloaderSettings struct {
 	OutputDir      string
 	IPFSSwarmAddrs string
 	LocalIPFS      bool
+	// CAR writes the results as a single CARv1 file instead of as files, to
+	// upload elsewhere or move offline.
+	CAR bool
+	// LocalPublisherToken is sent to download results that were published
+	// with the local publisher, if the node that serves them requires it.
+	LocalPublisherToken string
+	// IdentityFile holds the age identities to decrypt results that were
+	// encrypted to the client with.
+	IdentityFile string
+	// RequireSignedResults refuses results that aren't signed by the compute
+	// nodes that published them. Results with invalid signatures are always
+	// refused.
+	RequireSignedResults bool
 }
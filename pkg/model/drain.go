@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// DrainStatus is how draining a compute node is going. A node that drains
+// stops bidding on new jobs, waits for the executions it has to finish, and
+// then shuts down.
+type DrainStatus struct {
+	// Draining is whether the node has been asked to drain
+	Draining bool `json:"Draining"`
+	// StartedAt is when the node started draining
+	StartedAt time.Time `json:"StartedAt,omitempty"`
+	// Deadline is when the node shuts down even if executions haven't finished
+	Deadline time.Time `json:"Deadline,omitempty"`
+	// ActiveExecutions is how many executions the node still has to finish
+	ActiveExecutions int `json:"ActiveExecutions"`
+	// Drained is whether the node has finished draining and is shutting down
+	Drained bool `json:"Drained"`
+}
+
+type DrainPayload struct {
+	// the id of the client that is draining the node, which must be an admin
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the compute node to drain, and when the request expires
+	AdminRequest
+
+	// how long in seconds to wait for executions to finish before the node
+	// shuts down anyway, or the node's default if zero
+	Timeout float64 `json:"Timeout,omitempty"`
+}
+
+func (p DrainPayload) GetClientID() string {
+	return p.ClientID
+}
@@ -15,6 +15,11 @@ const (
 	EngineWasm
 	EngineLanguage   // wraps python_wasm
 	EnginePythonWasm // wraps docker
+	EngineProcess    // runs directly on the host
+	EngineApptainer  // runs the docker image of the job with apptainer
+	EnginePython     // wraps process
+	EnginePlugin     // runs the job with an executor plugin
+	EngineDuckDB     // runs a SQL query over the inputs of the job
 	engineDone       // must be last
 )
 
@@ -14,12 +14,17 @@ func _() {
 	_ = x[EngineWasm-3]
 	_ = x[EngineLanguage-4]
 	_ = x[EnginePythonWasm-5]
-	_ = x[engineDone-6]
+	_ = x[EngineProcess-6]
+	_ = x[EngineApptainer-7]
+	_ = x[EnginePython-8]
+	_ = x[EnginePlugin-9]
+	_ = x[EngineDuckDB-10]
+	_ = x[engineDone-11]
 }
 
-const _Engine_name = "engineUnknownNoopDockerWasmLanguagePythonWasmengineDone"
+const _Engine_name = "engineUnknownNoopDockerWasmLanguagePythonWasmProcessApptainerPythonPluginDuckDBengineDone"
 
-var _Engine_index = [...]uint8{0, 13, 17, 23, 27, 35, 45, 55}
+var _Engine_index = [...]uint8{0, 13, 17, 23, 27, 35, 45, 52, 61, 67, 73, 79, 89}
 
 func (i Engine) String() string {
 	if i < 0 || i >= Engine(len(_Engine_index)-1) {
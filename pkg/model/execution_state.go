@@ -99,11 +99,21 @@ type ExecutionState struct {
 	State ExecutionStateType `json:"State"`
 	// an arbitrary status message
 	Status string `json:"Status,omitempty"`
+	// what the compute node quoted for the execution when it bid, if it charges for it
+	Quote *Quote `json:"Quote,omitempty"`
+	// the evidence of the TEE that the compute node runs in, if the job requires one
+	Attestation *Attestation `json:"Attestation,omitempty"`
 	// the proposed results for this execution
 	// this will be resolved by the verifier somehow
 	VerificationProposal []byte             `json:"VerificationProposal,omitempty"`
 	VerificationResult   VerificationResult `json:"VerificationResult,omitempty"`
 	PublishedResult      StorageSpec        `json:"PublishedResults,omitempty"`
+	// how publishing the results went with each of the publishers of the job
+	PublisherResults []PublisherResult `json:"PublisherResults,omitempty"`
+	// the files in the output volumes that weren't published
+	SkippedOutputs *SkippedOutputs `json:"SkippedOutputs,omitempty"`
+	// the compute node's signature of the manifest of the published results, if it signed them
+	ResultSignature *ResultSignature `json:"ResultSignature,omitempty"`
 
 	// RunOutput of the job
 	RunOutput *RunCommandResult `json:"RunOutput,omitempty"`
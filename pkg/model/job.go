@@ -1,9 +1,13 @@
 package model
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/imdario/mergo"
+	"github.com/opencontainers/go-digest"
+	"golang.org/x/exp/slices"
 	"k8s.io/apimachinery/pkg/selection"
 )
 
@@ -37,6 +41,12 @@ type Metadata struct {
 	// The ID of the client that created this job.
 	ClientID string `json:"ClientID,omitempty" example:"ac13188e93c97a9c2e7cf8e86c7313156a73436036f30da1ececc2ce79f9ea51"`
 
+	// The client's signature of the payload that it submitted the job with,
+	// which lets compute nodes verify the ClientID, the ID and the spec of the
+	// job. Jobs that the requester submits on behalf of clients, such as the
+	// runs of schedules, have none.
+	ClientSignature *ClientSignature `json:"ClientSignature,omitempty"`
+
 	Requester JobRequester `json:"Requester,omitempty"`
 }
 type JobRequester struct {
@@ -95,7 +105,7 @@ type Deal struct {
 	// The number of nodes that must agree on a verification result
 	// this is used by the different verifiers - for example the
 	// deterministic verifier requires the winning group size
-	// to be at least this size
+	// to be at least this size, or a majority of the executions if unset
 	Confidence int `json:"Confidence,omitempty"`
 	// The minimum number of bids that must be received before the Requester
 	// node will randomly accept concurrency-many of them. This allows the
@@ -103,6 +113,29 @@ type Deal struct {
 	// jobs will be spread evenly across the network (assuming that this value
 	// is some large proportion of the size of the network).
 	MinBids int `json:"MinBids,omitempty"`
+	// Gang places all concurrency-many executions at once or not at all, for
+	// jobs whose executions must run at the same time such as distributed
+	// training. The compute nodes hold the capacity that they bid with until
+	// the requester node has bids from enough of them.
+	Gang bool `json:"Gang,omitempty"`
+	// How long in seconds the requester node waits for enough bids to place
+	// a gang before it gives up on the job. Zero means the default.
+	GangTimeout float64 `json:"GangTimeout,omitempty"`
+	// MaxBudget is the most that each execution of the job may cost on the
+	// compute node that runs it, over the timeout of the job and at the
+	// pricing that the node advertises. Zero means no limit.
+	MaxBudget float64 `json:"MaxBudget,omitempty"`
+	// Offer is the most that the job pays per second for each of the
+	// resources that it uses. Compute nodes that charge more for any of them
+	// don't bid on the job. Zero means the job doesn't offer prices, so that
+	// it may run on any node within its max budget.
+	Offer Pricing `json:"Offer,omitempty"`
+}
+
+// GetGangTimeout returns how long to wait for a gang to be placed, or zero
+// to use the default of the requester node.
+func (d Deal) GetGangTimeout() time.Duration {
+	return time.Duration(d.GangTimeout * float64(time.Second))
 }
 
 // LabelSelectorRequirement A selector that contains values, a key, and an operator that relates the key and values.
@@ -131,11 +164,19 @@ type Spec struct {
 
 	// there can be multiple publishers for the job
 	Publisher Publisher `json:"Publisher,omitempty"`
+	// Publishers that the results are published with as well as Publisher.
+	// Executions succeed as long as one of the publishers succeeds, and record
+	// how each of them went.
+	Publishers []Publisher `json:"Publishers,omitempty"`
 
 	// executor specific data
 	Docker   JobSpecDocker   `json:"Docker,omitempty"`
 	Language JobSpecLanguage `json:"Language,omitempty"`
 	Wasm     JobSpecWasm     `json:"Wasm,omitempty"`
+	Process  JobSpecProcess  `json:"Process,omitempty"`
+	Python   JobSpecPython   `json:"Python,omitempty"`
+	Plugin   JobSpecPlugin   `json:"Plugin,omitempty"`
+	DuckDB   JobSpecDuckDB   `json:"DuckDB,omitempty"`
 
 	// the compute (cpu, ram) resources this job requires
 	Resources ResourceUsageConfig `json:"Resources,omitempty"`
@@ -147,6 +188,11 @@ type Spec struct {
 	// This includes the time required to run, verify and publish results
 	Timeout float64 `json:"Timeout,omitempty"`
 
+	// How long in seconds after the job is submitted that the requester stops
+	// it and marks it as timed out if it hasn't completed, including the time
+	// it waits to be scheduled. There is no deadline if it is zero.
+	MaxWallClock float64 `json:"MaxWallClock,omitempty"`
+
 	// the data volumes we will read in the job
 	// for example "read this ipfs cid"
 	// TODO: #667 Replace with "Inputs", "Outputs" (note the caps) for yaml/json when we update the n.js file
@@ -162,11 +208,118 @@ type Spec struct {
 	// NodeSelectors is a selector which must be true for the compute node to run this job.
 	NodeSelectors []LabelSelectorRequirement `json:"NodeSelectors,omitempty"`
 
+	// Tolerations are the taints of the compute nodes that the job may run
+	// on, which are otherwise kept for the jobs that tolerate them.
+	Tolerations []Toleration `json:"Tolerations,omitempty"`
+
+	// Which compute nodes the job prefers and which it avoids, on top of the
+	// node selectors that nodes must match
+	Affinity AffinityConfig `json:"Affinity,omitempty"`
+
 	// Do not track specified by the client
 	DoNotTrack bool `json:"DoNotTrack,omitempty"`
 
 	// The deal the client has made, such as which job bids they have accepted.
 	Deal Deal `json:"Deal,omitempty"`
+
+	// How urgently the requester schedules the job ahead of other jobs that
+	// are waiting for compute capacity
+	Priority Priority `json:"Priority,omitempty"`
+
+	// How the requester retries executions of the job that fail on other
+	// compute nodes
+	Retry RetryPolicy `json:"Retry,omitempty"`
+
+	// Runs the job once for each index of an array, within a single execution
+	Array ArrayConfig `json:"Array,omitempty"`
+
+	// URL that the requester node POSTs a signed notification with the
+	// results of the job to when it completes
+	Webhook string `json:"Webhook,omitempty"`
+
+	// Name that the IPFS publisher publishes the results under with IPNS, so
+	// that successive runs of jobs with the same name update the same IPNS
+	// record to point at their latest results
+	IPNSName string `json:"IPNSName,omitempty"`
+
+	// Compresses the results before they are published
+	Compression CompressionConfig `json:"Compression,omitempty"`
+
+	// Encrypts the results before they are published
+	Encryption EncryptionConfig `json:"Encryption,omitempty"`
+
+	// The deals made for the results when they are published to Filecoin
+	FilecoinDeal FilecoinDealConfig `json:"FilecoinDeal,omitempty"`
+
+	// Runs the job even if the compute node recently published the results
+	// of an identical job, rather than reusing them
+	NoCache bool `json:"NoCache,omitempty"`
+
+	// The kind of trusted execution environment that the job must run in,
+	// which compute nodes attest to with evidence from their hardware when
+	// they bid on the job
+	TEE TEEType `json:"TEE,omitempty"`
+}
+
+// FilecoinDealConfig configures the storage deals that are made for the
+// results of a job when they are published to Filecoin.
+type FilecoinDealConfig struct {
+	// How many miners to make deals with, defaulting to one. The cheapest
+	// miners are chosen.
+	Replication int `json:"Replication,omitempty"`
+	// How many days the deals last for, defaulting to the storage duration of
+	// the compute node.
+	DurationDays int `json:"DurationDays,omitempty"`
+	// Whether to make verified deals, paid for with the DataCap of the wallet
+	// of the compute node.
+	VerifiedDeal bool `json:"VerifiedDeal,omitempty"`
+}
+
+// CompressionConfig compresses the results of a job into a single tar before
+// they are published, which bacalhau get decompresses when it downloads them.
+type CompressionConfig struct {
+	// The algorithm to compress with, gzip or zstd. Results are only
+	// compressed if there is one.
+	Algorithm string `json:"Algorithm,omitempty"`
+	// The level to compress at, from 1 to 9 for gzip and from 1 to 22 for
+	// zstd, defaulting to the default level of the algorithm.
+	Level int `json:"Level,omitempty"`
+}
+
+// EncryptionConfig encrypts the results of a job before they are published,
+// so that only its client can read them.
+type EncryptionConfig struct {
+	// The age X25519 public keys to encrypt the results to. Results are only
+	// encrypted if there are any, and then stdout and stderr aren't reported
+	// to the requester node either.
+	Recipients []string `json:"Recipients,omitempty"`
+}
+
+// AffinityConfig says which compute nodes the requester prefers to run a job
+// on, and which it keeps the job off, on top of the node selectors that nodes
+// must match.
+type AffinityConfig struct {
+	// Selectors that the nodes matching are ranked above the nodes that don't,
+	// without ruling those out.
+	PreferredNodeSelectors []LabelSelectorRequirement `json:"PreferredNodeSelectors,omitempty"`
+	// Annotations that select the sibling jobs of the job, which are the other
+	// jobs of the same client with any of the annotations. The job isn't run
+	// on nodes that are running or have run a sibling job, e.g. to spread
+	// replicas across nodes.
+	AntiAffinity []string `json:"AntiAffinity,omitempty"`
+	// Ranks the nodes that ran a sibling job below the others rather than
+	// ruling them out, for when there may not be enough other nodes.
+	SoftAntiAffinity bool `json:"SoftAntiAffinity,omitempty"`
+}
+
+// ArrayConfig fans a job out over a range of indices within a single
+// execution, like the array jobs of HPC schedulers, so that clients don't have
+// to submit many near identical jobs.
+type ArrayConfig struct {
+	// The number of indices, from 0 to Count-1, to run the job with. Indices run
+	// one after another, within the resources and timeout of the job, each with
+	// its own results directory named after the index.
+	Count int `json:"Count,omitempty"`
 }
 
 // Return timeout duration
@@ -174,6 +327,18 @@ func (s *Spec) GetTimeout() time.Duration {
 	return time.Duration(s.Timeout * float64(time.Second))
 }
 
+// AllPublishers returns the publishers that the results are published with,
+// starting with Publisher and without duplicates.
+func (s *Spec) AllPublishers() []Publisher {
+	publishers := []Publisher{s.Publisher}
+	for _, p := range s.Publishers {
+		if !slices.Contains(publishers, p) {
+			publishers = append(publishers, p)
+		}
+	}
+	return publishers
+}
+
 // Return pointers to all the storage specs in the spec.
 func (s *Spec) AllStorageSpecs() []*StorageSpec {
 	storages := []*StorageSpec{
@@ -203,6 +368,32 @@ type JobSpecDocker struct {
 	EnvironmentVariables []string `json:"EnvironmentVariables,omitempty"`
 	// working directory inside the container
 	WorkingDirectory string `json:"WorkingDirectory,omitempty"`
+	// optionally override the seccomp profile chosen by the compute node, either
+	// with the JSON contents of a profile or with "unconfined"
+	SeccompProfile string `json:"SeccompProfile,omitempty"`
+	// optionally override the AppArmor profile chosen by the compute node, with
+	// the name of a profile loaded on the node or with "unconfined"
+	AppArmorProfile string `json:"AppArmorProfile,omitempty"`
+}
+
+// OverridesSecurityProfiles returns whether the job asks for security profiles
+// other than the ones the compute node applies by default.
+func (d JobSpecDocker) OverridesSecurityProfiles() bool {
+	return d.SeccompProfile != "" || d.AppArmorProfile != ""
+}
+
+// PinnedDigest returns the digest the image is pinned to when it is referenced
+// as `name@sha256:...`, or an empty digest if the image is referenced by tag.
+func (d JobSpecDocker) PinnedDigest() (digest.Digest, error) {
+	_, pinned, found := strings.Cut(d.Image, "@")
+	if !found {
+		return "", nil
+	}
+	parsed, err := digest.Parse(pinned)
+	if err != nil {
+		return "", fmt.Errorf("invalid digest in image reference %q: %w", d.Image, err)
+	}
+	return parsed, nil
 }
 
 // for language style executors (can target docker or wasm)
@@ -243,6 +434,49 @@ type JobSpecWasm struct {
 	ImportModules []StorageSpec `json:"ImportModules,omitempty"`
 }
 
+// for executors that run a command directly on the compute node
+type JobSpecProcess struct {
+	// the program to run, looked up on the PATH of the compute node
+	Command string `json:"Command,omitempty"`
+	// the arguments to run the program with
+	Arguments []string `json:"Arguments,omitempty"`
+	// a list of KEY=VALUE environment variables to run the program with
+	EnvironmentVariables []string `json:"EnvironmentVariables,omitempty"`
+	// working directory, relative to the directory the job is run in
+	WorkingDirectory string `json:"WorkingDirectory,omitempty"`
+}
+
+// for executors that run a SQL query over the inputs of the job with DuckDB
+type JobSpecDuckDB struct {
+	// the query to run, which reads the inputs at their paths relative to
+	// the directory the job is run in, e.g. SELECT * FROM 'inputs/data.csv'
+	Query string `json:"Query,omitempty"`
+	// the format to write the result set to the output in: csv, json or
+	// parquet. Defaults to csv.
+	Format string `json:"Format,omitempty"`
+}
+
+// for executors provided by plugins, which are separate binaries installed on
+// the compute node
+type JobSpecPlugin struct {
+	// the name of the plugin to run the job with
+	Name string `json:"Name,omitempty"`
+	// parameters of the job that only the plugin understands
+	Parameters map[string]string `json:"Parameters,omitempty"`
+}
+
+// for executors that run a python script in a managed virtualenv
+type JobSpecPython struct {
+	// the source code of the script to run
+	Script string `json:"Script,omitempty"`
+	// pip requirements to install into the virtualenv, e.g. numpy==1.24.2
+	Requirements []string `json:"Requirements,omitempty"`
+	// the arguments to run the script with
+	Arguments []string `json:"Arguments,omitempty"`
+	// a list of KEY=VALUE environment variables to run the script with
+	EnvironmentVariables []string `json:"EnvironmentVariables,omitempty"`
+}
+
 // we emit these to other nodes so they update their
 // state locally and can emit events locally
 type JobEvent struct {
@@ -293,6 +527,22 @@ type JobCreatePayload struct {
 
 	// The specification of this job.
 	Spec *Spec `json:"Spec,omitempty" validate:"required"`
+
+	// The name of the template to submit the job from, whose spec the
+	// specification of this job overrides
+	Template string `json:"Template,omitempty"`
+
+	// The values of the parameters of the template
+	TemplateParameters map[string]string `json:"TemplateParameters,omitempty"`
+
+	// A random value that makes the payload unique, so that the ID of the job
+	// can be derived from the signed payload and its signature is only good
+	// for that job
+	Nonce string `json:"Nonce,omitempty"`
+
+	// The client's signature of this payload, which the requester checks and
+	// keeps with the job. It isn't part of the payload that is signed.
+	ClientSignature *ClientSignature `json:"-"`
 }
 
 func (j JobCreatePayload) GetClientID() string {
@@ -27,12 +27,15 @@ const (
 
 	// Job is waiting to be scheduled.
 	JobStateQueued
+
+	// Job didn't complete within its timeout or max wall clock, and was stopped.
+	JobStateTimedOut
 )
 
 // IsTerminal returns true if the given job type signals the end of the lifecycle of
 // that job and that no change in the state can be expected.
 func (s JobStateType) IsTerminal() bool {
-	return s == JobStateCompleted || s == JobStateError || s == JobStateCancelled
+	return s == JobStateCompleted || s == JobStateError || s == JobStateCancelled || s == JobStateTimedOut
 }
 
 func (s JobStateType) MarshalText() ([]byte, error) {
@@ -41,7 +44,7 @@ func (s JobStateType) MarshalText() ([]byte, error) {
 
 func (s *JobStateType) UnmarshalText(text []byte) (err error) {
 	name := string(text)
-	for typ := JobStateNew; typ <= JobStateCompleted; typ++ {
+	for typ := JobStateNew; typ <= JobStateTimedOut; typ++ {
 		if equal(typ.String(), name) {
 			*s = typ
 			return
@@ -14,11 +14,12 @@ func _() {
 	_ = x[JobStateError-3]
 	_ = x[JobStateCompleted-4]
 	_ = x[JobStateQueued-5]
+	_ = x[JobStateTimedOut-6]
 }
 
-const _JobStateType_name = "NewInProgressCancelledErrorCompletedQueued"
+const _JobStateType_name = "NewInProgressCancelledErrorCompletedQueuedTimedOut"
 
-var _JobStateType_index = [...]uint8{0, 3, 13, 22, 27, 36, 42}
+var _JobStateType_index = [...]uint8{0, 3, 13, 22, 27, 36, 42, 50}
 
 func (i JobStateType) String() string {
 	if i < 0 || i >= JobStateType(len(_JobStateType_index)-1) {
@@ -0,0 +1,19 @@
+//go:build unit || !integration
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobStateTypeTextRoundTrip(t *testing.T) {
+	for typ := JobStateNew; typ <= JobStateTimedOut; typ++ {
+		text, err := typ.MarshalText()
+		require.NoError(t, err)
+		var parsed JobStateType
+		require.NoError(t, parsed.UnmarshalText(text))
+		require.Equal(t, typ, parsed, string(text))
+	}
+}
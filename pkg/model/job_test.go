@@ -0,0 +1,46 @@
+//go:build unit || !integration
+
+package model
+
+import (
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobSpecDocker_PinnedDigest(t *testing.T) {
+	const sha = "sha256:b5a61709a9a44284d88fb12e5c48db0409cfad5b69d4ff8224077c57302df9cf"
+	tests := []struct {
+		name    string
+		image   string
+		want    digest.Digest
+		wantErr bool
+	}{
+		{name: "tag", image: "ubuntu:latest", want: ""},
+		{name: "no-tag", image: "ubuntu", want: ""},
+		{name: "digest", image: "ubuntu@" + sha, want: sha},
+		{name: "tag-and-digest", image: "ghcr.io/org/ubuntu:22.04@" + sha, want: sha},
+		{name: "invalid-digest", image: "ubuntu@sha256:abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JobSpecDocker{Image: tt.image}.PinnedDigest()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSpec_AllPublishers(t *testing.T) {
+	spec := Spec{
+		Publisher:  PublisherIpfs,
+		Publishers: []Publisher{PublisherLocal, PublisherIpfs, PublisherEstuary},
+	}
+	require.Equal(t, []Publisher{PublisherIpfs, PublisherLocal, PublisherEstuary}, spec.AllPublishers())
+	require.Equal(t, []Publisher{PublisherNoop}, (&Spec{Publisher: PublisherNoop}).AllPublishers())
+}
@@ -69,13 +69,17 @@ const (
 	// not hear back it will be stuck in reserving the resources for the job
 	JobEventInvalidRequest
 
+	// a requester node stopped a job that didn't complete within its
+	// timeout or max wall clock
+	JobEventTimedOut
+
 	jobEventDone // must be last
 )
 
 // IsTerminal returns true if the given event type signals the end of the
 // lifecycle of a job. After this, all nodes can safely ignore the job.
 func (je JobEventType) IsTerminal() bool {
-	return je == JobEventError || je == JobEventResultsPublished || je == JobEventCanceled
+	return je == JobEventError || je == JobEventResultsPublished || je == JobEventCanceled || je == JobEventTimedOut
 }
 
 // IsIgnorable returns true if given event type signals that a node can safely
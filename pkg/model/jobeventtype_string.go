@@ -25,12 +25,13 @@ func _() {
 	_ = x[JobEventError-14]
 	_ = x[JobEventCanceled-15]
 	_ = x[JobEventInvalidRequest-16]
-	_ = x[jobEventDone-17]
+	_ = x[JobEventTimedOut-17]
+	_ = x[jobEventDone-18]
 }
 
-const _JobEventType_name = "jobEventUnknownInitialSubmissionCreatedDealUpdatedBidBidAcceptedBidRejectedBidCancelledRunningComputeErrorResultsProposedResultsAcceptedResultsRejectedResultsPublishedErrorCanceledInvalidRequestjobEventDone"
+const _JobEventType_name = "jobEventUnknownInitialSubmissionCreatedDealUpdatedBidBidAcceptedBidRejectedBidCancelledRunningComputeErrorResultsProposedResultsAcceptedResultsRejectedResultsPublishedErrorCanceledInvalidRequestTimedOutjobEventDone"
 
-var _JobEventType_index = [...]uint8{0, 15, 32, 39, 50, 53, 64, 75, 87, 94, 106, 121, 136, 151, 167, 172, 180, 194, 206}
+var _JobEventType_index = [...]uint8{0, 15, 32, 39, 50, 53, 64, 75, 87, 94, 106, 121, 136, 151, 167, 172, 180, 194, 202, 214}
 
 func (i JobEventType) String() string {
 	if i < 0 || i >= JobEventType(len(_JobEventType_index)-1) {
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// NodeAccess is whether a requester schedules executions on a compute node.
+type NodeAccess string
+
+const (
+	// NodeAccessAllow lets the requester schedule executions on the node. If
+	// any node is allowed, the requester only schedules executions on the
+	// nodes that are allowed.
+	NodeAccessAllow NodeAccess = "Allow"
+	// NodeAccessDeny keeps the requester from scheduling executions on the
+	// node, such as to quarantine a node that misbehaves.
+	NodeAccessDeny NodeAccess = "Deny"
+)
+
+// IsValid returns whether the access is known.
+func (a NodeAccess) IsValid() bool {
+	return a == NodeAccessAllow || a == NodeAccessDeny
+}
+
+// NodeAccessRule allows or denies the requester scheduling executions on a
+// compute node, without changing whether the node is part of the network.
+type NodeAccessRule struct {
+	// NodeID is the id of the compute node
+	NodeID string `json:"NodeID"`
+	// Access is whether executions are scheduled on the node
+	Access NodeAccess `json:"Access"`
+	// Reason says why the node is allowed or denied
+	Reason string `json:"Reason,omitempty"`
+	// CreatedBy is the client that created the rule, which is empty for the
+	// rules that the requester was started with
+	CreatedBy string `json:"CreatedBy,omitempty"`
+	// CreatedAt is when the rule was created
+	CreatedAt time.Time `json:"CreatedAt,omitempty"`
+}
+
+// Validate returns an error if the rule doesn't say which node it is for or
+// whether the node is allowed.
+func (r NodeAccessRule) Validate() error {
+	if r.NodeID == "" {
+		return fmt.Errorf("the node access rule must be for a node")
+	}
+	if !r.Access.IsValid() {
+		return fmt.Errorf("the node access rule has access %q rather than %s or %s", r.Access, NodeAccessAllow, NodeAccessDeny)
+	}
+	return nil
+}
+
+type NodeAccessSetPayload struct {
+	// the id of the client that is setting the rule, which must be an admin
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the requester node that the request is for, and when it expires
+	AdminRequest
+
+	// the rule to set, which replaces any rule for the same node
+	Rule NodeAccessRule `json:"Rule"`
+}
+
+func (p NodeAccessSetPayload) GetClientID() string {
+	return p.ClientID
+}
+
+type NodeAccessDeletePayload struct {
+	// the id of the client that is deleting the rule, which must be an admin
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the requester node that the request is for, and when it expires
+	AdminRequest
+
+	// the node whose rule to delete
+	NodeID string `json:"NodeID,omitempty" validate:"required"`
+}
+
+func (p NodeAccessDeletePayload) GetClientID() string {
+	return p.ClientID
+}
@@ -41,4 +41,57 @@ type ComputeNodeInfo struct {
 	MaxJobRequirements ResourceUsageData `json:"MaxJobRequirements"`
 	RunningExecutions  int               `json:"RunningExecutions"`
 	EnqueuedExecutions int               `json:"EnqueuedExecutions"`
+	// ScratchDisk is the space of the disk that inputs are prepared on, which
+	// is zero if it couldn't be measured.
+	ScratchDisk DiskSpace `json:"ScratchDisk"`
+	// Preemptible is whether the requester may stop executions on the node to
+	// make room for more urgent jobs.
+	Preemptible bool `json:"Preemptible"`
+	// LocalCIDs are the CIDs of the inputs that the node prepared most
+	// recently, which it likely still holds locally.
+	LocalCIDs []string `json:"LocalCIDs,omitempty"`
+	// Pricing is what the node charges for the resources that executions use.
+	Pricing Pricing `json:"Pricing,omitempty"`
+	// GPUs are the GPUs of the node, which jobs that require GPUs of a model
+	// or with enough memory are matched against.
+	GPUs []GPU `json:"GPUs,omitempty"`
+	// Taints keep the jobs that don't tolerate them off the node, or make
+	// them prefer other nodes.
+	Taints []Taint `json:"Taints,omitempty"`
+	// TEE is the kind of trusted execution environment that the node runs
+	// in, if any, which jobs that require attested execution are matched
+	// against.
+	TEE TEEType `json:"TEE,omitempty"`
+	// Benchmark is how fast the node measured itself to be, which is zero if
+	// it wasn't benchmarked.
+	Benchmark NodeBenchmark `json:"Benchmark,omitempty"`
+}
+
+// GPU is a GPU of a compute node.
+type GPU struct {
+	// Index is the index of the GPU on the node
+	Index int `json:"Index"`
+	// Name is the name of the model of the GPU, such as NVIDIA A100-SXM4-80GB
+	Name string `json:"Name"`
+	// Memory is how much memory the GPU has, in bytes
+	Memory uint64 `json:"Memory"`
+	// UUID is how container runtimes address the GPU, if known
+	UUID string `json:"UUID,omitempty"`
+	// MIGProfile is the profile of the slice, such as 1g.10gb, if this is a
+	// MIG slice of the GPU at Index rather than a whole GPU
+	MIGProfile string `json:"MIGProfile,omitempty"`
+}
+
+// DiskSpace is the size of a filesystem and how much of it is free.
+type DiskSpace struct {
+	Total uint64 `json:"Total"`
+	Free  uint64 `json:"Free"`
+}
+
+// Utilization returns the fraction of the disk that is in use, from 0 to 1.
+func (d DiskSpace) Utilization() float64 {
+	if d.Total == 0 {
+		return 0
+	}
+	return 1 - float64(d.Free)/float64(d.Total)
 }
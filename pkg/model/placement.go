@@ -0,0 +1,34 @@
+package model
+
+// PlacementPlan is where a requester node would place the executions of a job
+// if it were submitted now, and why, without running the job.
+type PlacementPlan struct {
+	// RequestedNodes is how many nodes the job needs to bid on it to start
+	RequestedNodes int `json:"RequestedNodes"`
+	// CanStart is whether the job could start now
+	CanStart bool `json:"CanStart"`
+	// Reason is why the job can't start now, if it can't
+	Reason string `json:"Reason,omitempty"`
+	// Nodes are the nodes that were discovered for the job, with the most
+	// preferable first
+	Nodes []NodePlacement `json:"Nodes"`
+}
+
+// NodePlacement is how a requester node ranked a node for a job it placed,
+// and whether it would ask the node to bid on the job.
+type NodePlacement struct {
+	// NodeID is the id of the node
+	NodeID string `json:"NodeID"`
+	// Rank is the rank of the node, which is negative if the node isn't
+	// suitable to execute the job
+	Rank int `json:"Rank"`
+	// Ranks is the rank that each ranker gave the node, by the name of the
+	// ranker, if the requester node can tell
+	Ranks map[string]int `json:"Ranks,omitempty"`
+	// HasCapacity is whether the node has the capacity to run the job now
+	HasCapacity bool `json:"HasCapacity"`
+	// Selected is whether the node would be asked to bid on the job
+	Selected bool `json:"Selected"`
+	// Reason is why the node would or wouldn't be asked to bid on the job
+	Reason string `json:"Reason"`
+}
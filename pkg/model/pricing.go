@@ -0,0 +1,89 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+)
+
+// Pricing is what a compute node charges for the resources that executions
+// use, per second that they run for. Resources without a price are free.
+type Pricing struct {
+	// CPUSecond is the price of a CPU core per second
+	CPUSecond float64 `json:"CPUSecond,omitempty"`
+	// MemoryGBSecond is the price of a GB of memory per second
+	MemoryGBSecond float64 `json:"MemoryGBSecond,omitempty"`
+	// GPUSecond is the price of a GPU per second
+	GPUSecond float64 `json:"GPUSecond,omitempty"`
+}
+
+// ParsePricing parses prices per second such as "cpu=0.01,memory=0.001,gpu=1",
+// where memory is priced per GB. Resources that aren't listed are free.
+func ParsePricing(s string) (Pricing, error) {
+	var pricing Pricing
+	for _, item := range strings.Split(s, ",") {
+		resource, value, found := strings.Cut(strings.TrimSpace(item), "=")
+		price, err := strconv.ParseFloat(value, 64)
+		if !found || err != nil {
+			return pricing, fmt.Errorf("price %q should be in 'resource=price' form, such as cpu=0.01", item)
+		}
+		switch strings.ToLower(resource) {
+		case "cpu":
+			pricing.CPUSecond = price
+		case "memory":
+			pricing.MemoryGBSecond = price
+		case "gpu":
+			pricing.GPUSecond = price
+		default:
+			return pricing, fmt.Errorf("unknown resource %q, which should be one of cpu, memory or gpu", resource)
+		}
+	}
+	return pricing, pricing.Validate()
+}
+
+func (p Pricing) String() string {
+	var prices []string
+	for _, price := range []struct {
+		resource string
+		value    float64
+	}{{"cpu", p.CPUSecond}, {"memory", p.MemoryGBSecond}, {"gpu", p.GPUSecond}} {
+		if price.value != 0 {
+			prices = append(prices, fmt.Sprintf("%s=%g", price.resource, price.value))
+		}
+	}
+	return strings.Join(prices, ",")
+}
+
+// IsZero returns whether all the resources are free.
+func (p Pricing) IsZero() bool {
+	return p == Pricing{}
+}
+
+// Validate returns an error if any of the prices is negative.
+func (p Pricing) Validate() error {
+	if p.CPUSecond < 0 || p.MemoryGBSecond < 0 || p.GPUSecond < 0 {
+		return fmt.Errorf("prices must be >= 0, got %+v", p)
+	}
+	return nil
+}
+
+// Cost returns what an execution that uses the resources costs when it runs
+// for the duration.
+func (p Pricing) Cost(usage ResourceUsageData, duration time.Duration) float64 {
+	perSecond := usage.CPU*p.CPUSecond +
+		float64(usage.Memory)/float64(datasize.GB)*p.MemoryGBSecond +
+		float64(usage.GPU)*p.GPUSecond
+	return perSecond * duration.Seconds()
+}
+
+// Quote is what a compute node charges for running an execution, which it
+// attaches to its bid so that the requester node can compare bids.
+type Quote struct {
+	// Pricing is what the node charges for the resources of the execution
+	Pricing Pricing `json:"Pricing"`
+	// Cost is what the execution costs if it runs until the job's timeout
+	Cost float64 `json:"Cost"`
+}
@@ -0,0 +1,21 @@
+//go:build unit || !integration
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePricing(t *testing.T) {
+	pricing, err := ParsePricing("cpu=0.01, Memory=0.001,gpu=1")
+	require.NoError(t, err)
+	require.Equal(t, Pricing{CPUSecond: 0.01, MemoryGBSecond: 0.001, GPUSecond: 1}, pricing)
+	require.Equal(t, "cpu=0.01,memory=0.001,gpu=1", pricing.String())
+
+	for _, s := range []string{"", "cpu", "cpu=cheap", "disk=1", "gpu=-1"} {
+		_, err := ParsePricing(s)
+		require.Error(t, err, s)
+	}
+}
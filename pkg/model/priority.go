@@ -0,0 +1,40 @@
+package model
+
+import "fmt"
+
+// Priority is how urgently the requester schedules a job ahead of the other
+// jobs that are waiting for compute capacity.
+//
+//go:generate stringer -type=Priority --trimprefix=Priority
+type Priority int
+
+const (
+	// PriorityLow is for bulk and batch jobs that can wait for other jobs.
+	PriorityLow Priority = iota - 1
+
+	// PriorityNormal is the priority of jobs that don't set one.
+	PriorityNormal
+
+	// PriorityHigh is for urgent jobs, which are scheduled ahead of all others.
+	PriorityHigh
+)
+
+func ParsePriority(s string) (Priority, error) {
+	for typ := PriorityLow; typ <= PriorityHigh; typ++ {
+		if equal(typ.String(), s) {
+			return typ, nil
+		}
+	}
+
+	return PriorityNormal, fmt.Errorf("%T: unknown type '%s'", PriorityNormal, s)
+}
+
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Priority) UnmarshalText(text []byte) (err error) {
+	name := string(text)
+	*p, err = ParsePriority(name)
+	return
+}
@@ -0,0 +1,33 @@
+//go:build unit || !integration
+
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePriority(t *testing.T) {
+	for _, s := range []string{"low", "Normal", " HIGH "} {
+		_, err := ParsePriority(s)
+		require.NoError(t, err, s)
+	}
+	_, err := ParsePriority("urgent")
+	require.Error(t, err)
+}
+
+func TestPriorityIsOmittedUnlessSet(t *testing.T) {
+	data, err := json.Marshal(Spec{})
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "Priority")
+
+	data, err = json.Marshal(Spec{Priority: PriorityLow})
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"Priority":"Low"`)
+
+	var spec Spec
+	require.NoError(t, json.Unmarshal(data, &spec))
+	require.Equal(t, PriorityLow, spec.Priority)
+}
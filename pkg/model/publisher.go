@@ -13,6 +13,7 @@ const (
 	PublisherIpfs
 	PublisherFilecoin
 	PublisherEstuary
+	PublisherLocal
 	publisherDone // must be last
 )
 
@@ -56,3 +57,24 @@ func (p *Publisher) UnmarshalText(text []byte) (err error) {
 	*p, err = ParsePublisher(name)
 	return
 }
+
+// PublisherResult is how publishing the results of an execution with one of
+// the publishers of its job went.
+type PublisherResult struct {
+	Publisher Publisher   `json:"Publisher"`
+	Data      StorageSpec `json:"Data,omitempty"`
+	Error     string      `json:"Error,omitempty"`
+}
+
+// SkippedOutputs reports the files in the output volumes of an execution that
+// weren't published because of the include and exclude patterns of the
+// volumes.
+type SkippedOutputs struct {
+	// The number of files that were skipped.
+	Count int `json:"Count"`
+	// The total size of the files that were skipped, in bytes.
+	Size int64 `json:"Size"`
+	// The paths of the files that were skipped, relative to the results of
+	// the execution, which only lists the first of them if there are many.
+	Paths []string `json:"Paths,omitempty"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// QueueReason is why a queued job hasn't started yet.
+type QueueReason string
+
+const (
+	// QueueReasonStarting is when nothing holds the job back, and it starts
+	// the next time the queue is dispatched.
+	QueueReasonStarting QueueReason = "Starting"
+	// QueueReasonPriority is when the job waits for the more urgent jobs and
+	// the jobs queued before it to start first.
+	QueueReasonPriority QueueReason = "Priority"
+	// QueueReasonNoCapacity is when not enough of the nodes that suit the job
+	// have the capacity to run it now.
+	QueueReasonNoCapacity QueueReason = "NoCapacity"
+	// QueueReasonNoMatchingNodes is when not enough nodes suit the job, which
+	// fails when it reaches the front of the queue unless more join.
+	QueueReasonNoMatchingNodes QueueReason = "NoMatchingNodes"
+	// QueueReasonQuota is when the client of the job is running as many jobs
+	// as its quota allows.
+	QueueReasonQuota QueueReason = "Quota"
+)
+
+// QueuedJob is a job that waits in the queue of the requester to start, with
+// where it is in the queue and why it waits.
+type QueuedJob struct {
+	// JobID is the id of the job
+	JobID string `json:"JobID"`
+	// ClientID is the id of the client that submitted the job
+	ClientID string `json:"ClientID"`
+	// Priority is the priority of the job
+	Priority Priority `json:"Priority"`
+	// Position is where the job is in the queue, starting at 1 for the job
+	// that starts next
+	Position int `json:"Position"`
+	// QueuedAt is when the job was queued
+	QueuedAt time.Time `json:"QueuedAt"`
+	// Reason is why the job hasn't started yet
+	Reason QueueReason `json:"Reason"`
+	// Message says more about why the job hasn't started yet
+	Message string `json:"Message,omitempty"`
+	// EstimatedStartAt is when the job is expected to start from how quickly
+	// jobs have recently left the queue, or zero if it can't be told
+	EstimatedStartAt time.Time `json:"EstimatedStartAt,omitempty"`
+}
@@ -0,0 +1,66 @@
+package model
+
+// ClientQuota limits how much a client can submit to a requester node, so
+// that no client can crowd out the others. Each limit is unlimited if it is
+// zero.
+type ClientQuota struct {
+	// ClientID is the client the quota is for, which is empty for the quota
+	// of clients that don't have their own
+	ClientID string `json:"ClientID,omitempty"`
+	// MaxConcurrentJobs is how many of the client's jobs can run at once.
+	// Jobs beyond it wait in the queue until one of the others finishes.
+	MaxConcurrentJobs int `json:"MaxConcurrentJobs,omitempty"`
+	// MaxQueuedJobs is how many of the client's jobs can wait in the queue.
+	// Jobs submitted beyond it are rejected.
+	MaxQueuedJobs int `json:"MaxQueuedJobs,omitempty"`
+	// MaxResources is the most resources that the client's jobs in flight can
+	// request between them. Jobs submitted beyond it are rejected.
+	MaxResources ResourceUsageConfig `json:"MaxResources,omitempty"`
+	// MaxSubmissionsPerMinute is how many jobs the client can submit each
+	// minute. Jobs submitted beyond it are rejected.
+	MaxSubmissionsPerMinute int `json:"MaxSubmissionsPerMinute,omitempty"`
+}
+
+// ClientUsage is how much of its quota a client is using.
+type ClientUsage struct {
+	// RunningJobs is how many of the client's jobs are running
+	RunningJobs int `json:"RunningJobs"`
+	// QueuedJobs is how many of the client's jobs are waiting to run
+	QueuedJobs int `json:"QueuedJobs"`
+	// Resources are the resources that the client's jobs in flight request
+	Resources ResourceUsageData `json:"Resources"`
+	// SubmissionsLastMinute is how many jobs the client submitted in the last
+	// minute
+	SubmissionsLastMinute int `json:"SubmissionsLastMinute"`
+}
+
+type QuotaSetPayload struct {
+	// the id of the client that is setting the quota, which must be an admin
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the requester node that the request is for, and when it expires
+	AdminRequest
+
+	// the quota to set, which replaces any quota of the same client
+	Quota ClientQuota `json:"Quota"`
+}
+
+func (q QuotaSetPayload) GetClientID() string {
+	return q.ClientID
+}
+
+type QuotaDeletePayload struct {
+	// the id of the client that is deleting the quota, which must be an admin
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the requester node that the request is for, and when it expires
+	AdminRequest
+
+	// the client whose quota to delete, after which the default quota applies
+	// to it
+	QuotaClientID string `json:"QuotaClientID,omitempty" validate:"required"`
+}
+
+func (q QuotaDeletePayload) GetClientID() string {
+	return q.ClientID
+}
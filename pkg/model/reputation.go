@@ -0,0 +1,91 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// ExecutionOutcome is how an execution that a compute node was given ended,
+// as far as the reputation of the node is concerned.
+type ExecutionOutcome string
+
+const (
+	// ExecutionOutcomeSucceeded is when the node published the results of
+	// the execution.
+	ExecutionOutcomeSucceeded ExecutionOutcome = "Succeeded"
+	// ExecutionOutcomeFailed is when the execution failed on the node, or the
+	// node left the network while running it.
+	ExecutionOutcomeFailed ExecutionOutcome = "Failed"
+	// ExecutionOutcomeVerificationFailed is when the verifier rejected the
+	// results that the node proposed.
+	ExecutionOutcomeVerificationFailed ExecutionOutcome = "VerificationFailed"
+	// ExecutionOutcomeTimedOut is when the job timed out while the node was
+	// still running the execution.
+	ExecutionOutcomeTimedOut ExecutionOutcome = "TimedOut"
+)
+
+// NodeReputation is how reliably a compute node has run the executions that
+// the requester gave it. The counts decay over time, so that the outcomes of
+// recent executions weigh more than old ones and flaky nodes can recover.
+type NodeReputation struct {
+	// NodeID is the id of the compute node
+	NodeID string `json:"NodeID"`
+	// Successes is the decayed count of executions the node published the
+	// results of
+	Successes float64 `json:"Successes"`
+	// Failures is the decayed count of executions that failed on the node
+	Failures float64 `json:"Failures"`
+	// VerificationFailures is the decayed count of executions whose results
+	// the verifier rejected
+	VerificationFailures float64 `json:"VerificationFailures"`
+	// Timeouts is the decayed count of executions the node was still running
+	// when their jobs timed out
+	Timeouts float64 `json:"Timeouts"`
+	// UpdatedAt is when the counts were last decayed
+	UpdatedAt time.Time `json:"UpdatedAt"`
+}
+
+// Decay returns the reputation with its counts halved for each half life
+// that passed since it was last updated.
+func (r NodeReputation) Decay(now time.Time, halfLife time.Duration) NodeReputation {
+	if halfLife > 0 && !r.UpdatedAt.IsZero() && now.After(r.UpdatedAt) {
+		factor := math.Pow(0.5, float64(now.Sub(r.UpdatedAt))/float64(halfLife)) //nolint:gomnd // halving
+		r.Successes *= factor
+		r.Failures *= factor
+		r.VerificationFailures *= factor
+		r.Timeouts *= factor
+	}
+	r.UpdatedAt = now
+	return r
+}
+
+// Record returns the reputation decayed to the time, with the outcome counted.
+func (r NodeReputation) Record(outcome ExecutionOutcome, now time.Time, halfLife time.Duration) NodeReputation {
+	r = r.Decay(now, halfLife)
+	switch outcome {
+	case ExecutionOutcomeSucceeded:
+		r.Successes++
+	case ExecutionOutcomeFailed:
+		r.Failures++
+	case ExecutionOutcomeVerificationFailed:
+		r.VerificationFailures++
+	case ExecutionOutcomeTimedOut:
+		r.Timeouts++
+	}
+	return r
+}
+
+// Executions returns the decayed count of the executions the reputation is
+// made of.
+func (r NodeReputation) Executions() float64 {
+	return r.Successes + r.Failures + r.VerificationFailures + r.Timeouts
+}
+
+// SuccessRate returns the share of the executions that succeeded, or zero if
+// there were none.
+func (r NodeReputation) SuccessRate() float64 {
+	if r.Executions() == 0 {
+		return 0
+	}
+	return r.Successes / r.Executions()
+}
@@ -0,0 +1,23 @@
+//go:build unit || !integration
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeReputationDecays(t *testing.T) {
+	start := time.Now()
+	reputation := NodeReputation{}.
+		Record(ExecutionOutcomeFailed, start, time.Hour).
+		Record(ExecutionOutcomeFailed, start, time.Hour)
+	require.Equal(t, 2.0, reputation.Failures)
+	require.Equal(t, 0.0, reputation.SuccessRate())
+
+	reputation = reputation.Record(ExecutionOutcomeSucceeded, start.Add(2*time.Hour), time.Hour)
+	require.InDelta(t, 0.5, reputation.Failures, 0.001, "two half lives quarter the failures")
+	require.InDelta(t, 2.0/3, reputation.SuccessRate(), 0.001)
+}
@@ -16,8 +16,18 @@ type ResourceUsageConfig struct {
 	// github.com/c2h5oh/datasize string
 
 	Disk string `json:"Disk,omitempty"`
-	GPU  string `json:"GPU"` // unsigned integer string
-
+	GPU  string `json:"GPU"` // unsigned integer string, or a fraction below 1 (e.g. 0.5) to share a GPU
+	// maximum read and write operations per second on the node's disks, unsigned integer string
+	IOPS string `json:"IOPS,omitempty"`
+	// model that each GPU must be, matched case-insensitively against the
+	// names of the GPUs that nodes advertise (e.g. A100)
+	GPUModel string `json:"GPUModel,omitempty"`
+	// memory that each GPU must have at least
+	// github.com/c2h5oh/datasize string
+	GPUMemory string `json:"GPUMemory,omitempty"`
+	// MIG profile of the GPU slices to run on (e.g. 1g.10gb), taking as many
+	// slices as GPUs, or one if GPU is not set
+	GPUMIGProfile string `json:"GPUMIGProfile,omitempty"`
 }
 
 // these are the numeric values in bytes for ResourceUsageConfig
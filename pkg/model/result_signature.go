@@ -0,0 +1,99 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// ResultManifest is what a compute node vouches for when it signs the result
+// of an execution that it published.
+type ResultManifest struct {
+	// JobID is the job that the result is of
+	JobID string `json:"JobID"`
+	// NodeID is the compute node that executed the job and published the result
+	NodeID string `json:"NodeID"`
+	// ResultCID is the CID of the published result, if it is content addressed
+	ResultCID string `json:"ResultCID,omitempty"`
+	// MerkleRoot is the merkle root of the published result
+	MerkleRoot string `json:"MerkleRoot,omitempty"`
+	// ExitCode is the exit code of the execution
+	ExitCode int `json:"ExitCode"`
+}
+
+// ResultSignature is a compute node's signature of the manifest of a result
+// that it published, with the key that its node ID is derived from, so that
+// anyone can verify which node produced the result.
+type ResultSignature struct {
+	Manifest ResultManifest `json:"Manifest"`
+	// Signature is the base64 encoding of the node's signature of the JSON
+	// encoding of the manifest
+	Signature string `json:"Signature"`
+	// PublicKey is the base64 encoding of the node's libp2p public key
+	PublicKey string `json:"PublicKey"`
+}
+
+// SignResultManifest signs the manifest with the private key of a node.
+func SignResultManifest(key crypto.PrivKey, manifest ResultManifest) (*ResultSignature, error) {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := key.Sign(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign result manifest: %w", err)
+	}
+	publicKey, err := crypto.MarshalPublicKey(key.GetPublic())
+	if err != nil {
+		return nil, err
+	}
+	return &ResultSignature{
+		Manifest:  manifest,
+		Signature: base64.StdEncoding.EncodeToString(signature),
+		PublicKey: base64.StdEncoding.EncodeToString(publicKey),
+	}, nil
+}
+
+// Verify returns an error unless the signature is by the node with the ID,
+// over a manifest of the job's result as it was published.
+func (s *ResultSignature) Verify(jobID, nodeID string, result StorageSpec) error {
+	if s == nil {
+		return fmt.Errorf("the result of node %s isn't signed", nodeID)
+	}
+	manifest := s.Manifest
+	if manifest.JobID != jobID || manifest.NodeID != nodeID ||
+		manifest.ResultCID != result.CID || manifest.MerkleRoot != result.MerkleRoot {
+		return fmt.Errorf("the signed manifest of node %s doesn't match its result of job %s", nodeID, jobID)
+	}
+	publicKeyBytes, err := base64.StdEncoding.DecodeString(s.PublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to decode the public key of node %s: %w", nodeID, err)
+	}
+	publicKey, err := crypto.UnmarshalPublicKey(publicKeyBytes)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal the public key of node %s: %w", nodeID, err)
+	}
+	keyID, err := peer.IDFromPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	if keyID.String() != nodeID {
+		return fmt.Errorf("the result of node %s is signed by the key of node %s", nodeID, keyID)
+	}
+	signature, err := base64.StdEncoding.DecodeString(s.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to decode the signature of node %s: %w", nodeID, err)
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	ok, err := publicKey.Verify(data, signature)
+	if err != nil || !ok {
+		return fmt.Errorf("the result signature of node %s is invalid", nodeID)
+	}
+	return nil
+}
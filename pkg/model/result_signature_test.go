@@ -0,0 +1,44 @@
+//go:build unit || !integration
+
+package model
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func newNodeKey(t *testing.T) (crypto.PrivKey, string) {
+	key, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	require.NoError(t, err)
+	id, err := peer.IDFromPrivateKey(key)
+	require.NoError(t, err)
+	return key, id.String()
+}
+
+func TestResultSignatureVerify(t *testing.T) {
+	key, nodeID := newNodeKey(t)
+	otherKey, _ := newNodeKey(t)
+	result := StorageSpec{CID: "QmResult", MerkleRoot: "sha256:root"}
+	manifest := ResultManifest{JobID: "job", NodeID: nodeID, ResultCID: result.CID, MerkleRoot: result.MerkleRoot, ExitCode: 1}
+
+	signature, err := SignResultManifest(key, manifest)
+	require.NoError(t, err)
+	require.NoError(t, signature.Verify("job", nodeID, result))
+	require.Error(t, signature.Verify("other-job", nodeID, result), "the manifest is of another job")
+	require.Error(t, signature.Verify("job", nodeID, StorageSpec{CID: "QmOther"}), "the manifest is of another result")
+
+	var missing *ResultSignature
+	require.Error(t, missing.Verify("job", nodeID, result))
+
+	otherSignature, err := SignResultManifest(otherKey, manifest)
+	require.NoError(t, err)
+	require.Error(t, otherSignature.Verify("job", nodeID, result), "the manifest is signed by another node")
+
+	tampered := *signature
+	tampered.Manifest.ExitCode = 0
+	require.Error(t, tampered.Verify("job", nodeID, result), "the manifest isn't the one that was signed")
+}
@@ -0,0 +1,63 @@
+package model
+
+import "fmt"
+
+// RetryReason is a kind of failure of an execution that the requester can
+// retry on another compute node.
+type RetryReason string
+
+const (
+	// RetryOnNodeLost is when the compute node running the execution leaves
+	// the network.
+	RetryOnNodeLost RetryReason = "node-lost"
+
+	// RetryOnError is when the compute node reports that the execution failed,
+	// e.g. because it couldn't fetch the inputs or the execution timed out.
+	RetryOnError RetryReason = "error"
+
+	// RetryOnExitCode is when the execution completes with a non-zero exit code.
+	RetryOnExitCode RetryReason = "exit-code"
+)
+
+func RetryReasons() []RetryReason {
+	return []RetryReason{RetryOnNodeLost, RetryOnError, RetryOnExitCode}
+}
+
+func ParseRetryReason(s string) (RetryReason, error) {
+	for _, reason := range RetryReasons() {
+		if equal(string(reason), s) {
+			return reason, nil
+		}
+	}
+	return "", fmt.Errorf("%T: unknown type '%s'", RetryOnError, s)
+}
+
+// RetryPolicy is how the requester retries the executions of a job that fail
+// on other compute nodes, so that transient failures don't need the user to
+// submit the job again.
+type RetryPolicy struct {
+	// MaxAttempts is how many times each of the executions the job needs is
+	// attempted, including the first. Executions aren't retried if it's 0 or 1.
+	MaxAttempts int `json:"MaxAttempts,omitempty"`
+	// Backoff is how many seconds to wait before the first retry, which
+	// doubles with each failed execution after that.
+	Backoff float64 `json:"Backoff,omitempty"`
+	// RetryOn are the kinds of failures to retry, or all of them if empty.
+	RetryOn []RetryReason `json:"RetryOn,omitempty"`
+}
+
+// RetriesOn returns whether executions that fail for the reason are retried.
+func (p RetryPolicy) RetriesOn(reason RetryReason) bool {
+	if p.MaxAttempts <= 1 {
+		return false
+	}
+	if len(p.RetryOn) == 0 {
+		return true
+	}
+	for _, retryOn := range p.RetryOn {
+		if equal(string(retryOn), string(reason)) {
+			return true
+		}
+	}
+	return false
+}
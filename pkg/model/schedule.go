@@ -0,0 +1,104 @@
+package model
+
+import "time"
+
+// JobSchedule is a job that the requester submits again each time its cron
+// expression fires, e.g. to process the data that arrived since the last run.
+type JobSchedule struct {
+	// ID is the id of the schedule, which is not the id of any of its jobs
+	ID string `json:"ID"`
+	// ClientID is the id of the client that created the schedule, whose jobs
+	// it submits
+	ClientID string `json:"ClientID"`
+	// Cron is a standard cron expression with five fields, or a descriptor
+	// like @hourly, which is evaluated in UTC
+	Cron string `json:"Cron"`
+	// APIVersion is the version of the API that the spec is for
+	APIVersion string `json:"APIVersion"`
+	// Spec is the spec of each job that the schedule submits
+	Spec Spec `json:"Spec"`
+	// Paused is whether the schedule has stopped submitting jobs until it's
+	// resumed
+	Paused bool `json:"Paused,omitempty"`
+	// KeepRuns is how many of the most recent runs are kept in the history
+	KeepRuns int `json:"KeepRuns"`
+	// CreatedAt is when the schedule was created
+	CreatedAt time.Time `json:"CreatedAt"`
+	// NextRunAt is when the schedule next submits a job, unless it's paused
+	NextRunAt time.Time `json:"NextRunAt"`
+	// History are the most recent runs of the schedule, oldest first
+	History []ScheduleRun `json:"History,omitempty"`
+}
+
+// ScheduleRun is a time that a schedule submitted a job.
+type ScheduleRun struct {
+	// RunAt is when the job was submitted
+	RunAt time.Time `json:"RunAt"`
+	// JobID is the id of the job that was submitted, if it could be
+	JobID string `json:"JobID,omitempty"`
+	// Error is why the job couldn't be submitted, if it couldn't
+	Error string `json:"Error,omitempty"`
+	// Triggered is whether the run was triggered by the user rather than by
+	// the cron expression
+	Triggered bool `json:"Triggered,omitempty"`
+}
+
+// AddRun adds the run to the history of the schedule, dropping the oldest runs
+// beyond how many it keeps.
+func (s *JobSchedule) AddRun(run ScheduleRun) {
+	s.History = append(s.History, run)
+	if s.KeepRuns > 0 && len(s.History) > s.KeepRuns {
+		s.History = s.History[len(s.History)-s.KeepRuns:]
+	}
+}
+
+// ScheduleAction is a change a client makes to one of their schedules.
+type ScheduleAction string
+
+const (
+	// ScheduleActionPause stops the schedule submitting jobs.
+	ScheduleActionPause ScheduleAction = "pause"
+	// ScheduleActionResume starts the schedule submitting jobs again from the
+	// next time its cron expression fires.
+	ScheduleActionResume ScheduleAction = "resume"
+	// ScheduleActionTrigger submits a job now, without changing when the next
+	// one is submitted.
+	ScheduleActionTrigger ScheduleAction = "trigger"
+	// ScheduleActionDelete deletes the schedule, leaving the jobs it submitted.
+	ScheduleActionDelete ScheduleAction = "delete"
+)
+
+type ScheduleCreatePayload struct {
+	// the id of the client that is creating the schedule
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the cron expression of when to submit the job
+	Cron string `json:"Cron,omitempty" validate:"required"`
+
+	// how many of the most recent runs to keep in the history
+	KeepRuns int `json:"KeepRuns,omitempty"`
+
+	APIVersion string `json:"APIVersion,omitempty" example:"V1beta1" validate:"required"`
+
+	// The specification of the job to submit each time
+	Spec *Spec `json:"Spec,omitempty" validate:"required"`
+}
+
+func (s ScheduleCreatePayload) GetClientID() string {
+	return s.ClientID
+}
+
+type ScheduleUpdatePayload struct {
+	// the id of the client that created the schedule
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the id of the schedule to change
+	ScheduleID string `json:"ScheduleID,omitempty" validate:"required"`
+
+	// the change to make to the schedule
+	Action ScheduleAction `json:"Action,omitempty" validate:"required"`
+}
+
+func (s ScheduleUpdatePayload) GetClientID() string {
+	return s.ClientID
+}
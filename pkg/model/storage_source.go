@@ -21,6 +21,12 @@ const (
 	StorageSourceEstuary
 	StorageSourceInline
 	StorageSourceLocalDirectory
+	StorageSourceS3
+	StorageSourceGit
+	StorageSourceAzureBlob
+	StorageSourceGCS
+	StorageSourceHuggingFace
+	StorageSourceBitTorrent
 	storageSourceDone // must be last
 )
 
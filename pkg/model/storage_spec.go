@@ -1,5 +1,13 @@
 package model
 
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/multiformats/go-multihash"
+)
+
 // StorageSpec represents some data on a storage engine. Storage engines are
 // specific to particular execution engines, as different execution engines
 // will mount data in different ways.
@@ -31,6 +39,69 @@ type StorageSpec struct {
 
 	// Additional properties specific to each driver
 	Metadata map[string]string `json:"Metadata,omitempty"`
+
+	// Glob patterns of the files in an output volume to publish, matched
+	// against their paths relative to the volume, their names, or the
+	// directories they are in. All files are published if there are none.
+	Include []string `json:"Include,omitempty"`
+
+	// Glob patterns of the files in an output volume not to publish, even if
+	// they are included.
+	Exclude []string `json:"Exclude,omitempty"`
+
+	// The digest that the data must have once downloaded, either as an
+	// algorithm and hex digest, e.g. sha256:<hex>, or as a base58 multihash.
+	// The execution fails before the job runs if the data doesn't match.
+	Digest string `json:"Digest,omitempty"`
+
+	// The root of a Merkle tree over the files of published results, as
+	// sha256:<hex>, which downloaded results are checked against so that
+	// tampering between publication and retrieval is detected.
+	MerkleRoot string `json:"MerkleRoot,omitempty"`
+}
+
+// ExpectedDigest returns the digest that the data of the spec must have as a
+// multihash, or nil if it has none.
+func (s StorageSpec) ExpectedDigest() (multihash.Multihash, error) {
+	if s.Digest == "" {
+		return nil, nil
+	}
+
+	algorithm, encoded, found := strings.Cut(s.Digest, ":")
+	if !found {
+		mh, err := multihash.FromB58String(s.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid digest %q: not a multihash or algorithm:hex digest: %w", s.Digest, err)
+		}
+		return mh, nil
+	}
+
+	code, ok := multihash.Names[digestAlgorithmAliases[algorithm]]
+	if !ok {
+		code, ok = multihash.Names[algorithm]
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid digest %q: unsupported algorithm %s", s.Digest, algorithm)
+	}
+	digest, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest %q: %w", s.Digest, err)
+	}
+	hasher, err := multihash.GetHasher(code)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest %q: %w", s.Digest, err)
+	}
+	if len(digest) != hasher.Size() {
+		return nil, fmt.Errorf("invalid digest %q: %s digests are %d bytes long", s.Digest, algorithm, hasher.Size())
+	}
+	return multihash.Encode(digest, code)
+}
+
+// digestAlgorithmAliases are the names of algorithms in digests such as those
+// of OCI images, which are named differently as multihashes.
+var digestAlgorithmAliases = map[string]string{
+	"sha256": "sha2-256",
+	"sha512": "sha2-512",
 }
 
 // PublishedStorageSpec is a wrapper for a StorageSpec that has been published
@@ -39,4 +110,7 @@ type StorageSpec struct {
 type PublishedResult struct {
 	NodeID string      `json:"NodeID,omitempty"`
 	Data   StorageSpec `json:"Data,omitempty"`
+	// ResultSignature is the node's signature of the manifest of the result,
+	// if the node signed it
+	ResultSignature *ResultSignature `json:"ResultSignature,omitempty"`
 }
@@ -16,12 +16,18 @@ func _() {
 	_ = x[StorageSourceEstuary-5]
 	_ = x[StorageSourceInline-6]
 	_ = x[StorageSourceLocalDirectory-7]
-	_ = x[storageSourceDone-8]
+	_ = x[StorageSourceS3-8]
+	_ = x[StorageSourceGit-9]
+	_ = x[StorageSourceAzureBlob-10]
+	_ = x[StorageSourceGCS-11]
+	_ = x[StorageSourceHuggingFace-12]
+	_ = x[StorageSourceBitTorrent-13]
+	_ = x[storageSourceDone-14]
 }
 
-const _StorageSourceType_name = "storageSourceUnknownIPFSURLDownloadFilecoinUnsealedFilecoinEstuaryInlineLocalDirectorystorageSourceDone"
+const _StorageSourceType_name = "storageSourceUnknownIPFSURLDownloadFilecoinUnsealedFilecoinEstuaryInlineLocalDirectoryS3GitAzureBlobGCSHuggingFaceBitTorrentstorageSourceDone"
 
-var _StorageSourceType_index = [...]uint8{0, 20, 24, 35, 51, 59, 66, 72, 86, 103}
+var _StorageSourceType_index = [...]uint8{0, 20, 24, 35, 51, 59, 66, 72, 86, 88, 91, 100, 103, 114, 124, 141}
 
 func (i StorageSourceType) String() string {
 	if i < 0 || i >= StorageSourceType(len(_StorageSourceType_index)-1) {
@@ -0,0 +1,157 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TaintEffect is what a taint of a compute node does to the jobs that don't
+// tolerate it.
+type TaintEffect string
+
+const (
+	// TaintEffectNoSchedule keeps the jobs that don't tolerate the taint off
+	// the node.
+	TaintEffectNoSchedule TaintEffect = "NoSchedule"
+	// TaintEffectPreferNoSchedule places the jobs that don't tolerate the
+	// taint on the node only if other nodes don't suit them as well.
+	TaintEffectPreferNoSchedule TaintEffect = "PreferNoSchedule"
+)
+
+// IsValid returns whether the effect is known.
+func (e TaintEffect) IsValid() bool {
+	return e == TaintEffectNoSchedule || e == TaintEffectPreferNoSchedule
+}
+
+// Taint marks a compute node as dedicated to the jobs that tolerate it, such
+// as nodes reserved for a team.
+type Taint struct {
+	Key    string      `json:"Key"`
+	Value  string      `json:"Value,omitempty"`
+	Effect TaintEffect `json:"Effect"`
+}
+
+// String returns the taint as key=value:effect.
+func (t Taint) String() string {
+	if t.Value == "" {
+		return fmt.Sprintf("%s:%s", t.Key, t.Effect)
+	}
+	return fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect)
+}
+
+// ParseTaint parses a taint written as key=value:effect or key:effect.
+func ParseTaint(s string) (Taint, error) {
+	keyValue, effect, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return Taint{}, fmt.Errorf("taint %q must be key=value:effect or key:effect", s)
+	}
+	key, value, _ := strings.Cut(keyValue, "=")
+	taint := Taint{Key: key, Value: value, Effect: TaintEffect(effect)}
+	if key == "" {
+		return Taint{}, fmt.Errorf("taint %q has no key", s)
+	}
+	if !taint.Effect.IsValid() {
+		return Taint{}, fmt.Errorf("taint %q has effect %q rather than %s or %s",
+			s, effect, TaintEffectNoSchedule, TaintEffectPreferNoSchedule)
+	}
+	return taint, nil
+}
+
+// TolerationOperator is how a toleration matches the values of taints.
+type TolerationOperator string
+
+const (
+	// TolerationOpEqual tolerates taints with the key and value.
+	TolerationOpEqual TolerationOperator = "Equal"
+	// TolerationOpExists tolerates taints with the key whatever their value,
+	// or all taints if the key is empty.
+	TolerationOpExists TolerationOperator = "Exists"
+)
+
+// Toleration lets a job run on the compute nodes with the taints it matches.
+type Toleration struct {
+	Key string `json:"Key,omitempty"`
+	// Operator is Equal if it is empty
+	Operator TolerationOperator `json:"Operator,omitempty"`
+	Value    string             `json:"Value,omitempty"`
+	// Effect is the effect of the taints to tolerate, or any if it is empty
+	Effect TaintEffect `json:"Effect,omitempty"`
+}
+
+// Validate returns an error if the toleration can't match any taint.
+func (t Toleration) Validate() error {
+	switch t.Operator {
+	case "", TolerationOpEqual:
+		if t.Key == "" {
+			return fmt.Errorf("toleration with operator %s must have a key", TolerationOpEqual)
+		}
+	case TolerationOpExists:
+		if t.Value != "" {
+			return fmt.Errorf("toleration with operator %s can't have a value", TolerationOpExists)
+		}
+	default:
+		return fmt.Errorf("toleration has unknown operator %q", t.Operator)
+	}
+	if t.Effect != "" && !t.Effect.IsValid() {
+		return fmt.Errorf("toleration has unknown effect %q", t.Effect)
+	}
+	return nil
+}
+
+// String returns the toleration in the form that ParseToleration parses.
+func (t Toleration) String() string {
+	s := t.Key
+	if t.Operator != TolerationOpExists {
+		s += "=" + t.Value
+	}
+	if t.Effect != "" {
+		s += ":" + string(t.Effect)
+	}
+	return s
+}
+
+// Tolerates returns whether the toleration matches the taint.
+func (t Toleration) Tolerates(taint Taint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	if t.Operator == TolerationOpExists {
+		return t.Key == "" || t.Key == taint.Key
+	}
+	return t.Key == taint.Key && t.Value == taint.Value
+}
+
+// ParseToleration parses a toleration written as key=value:effect, key=value,
+// key:effect or key, where the forms without a value tolerate the key
+// whatever its value. The effect is any if it is left out.
+func ParseToleration(s string) (Toleration, error) {
+	keyValue, effect, _ := strings.Cut(strings.TrimSpace(s), ":")
+	key, value, hasValue := strings.Cut(keyValue, "=")
+	toleration := Toleration{Key: key, Value: value, Operator: TolerationOpEqual, Effect: TaintEffect(effect)}
+	if !hasValue {
+		toleration.Operator = TolerationOpExists
+	}
+	return toleration, toleration.Validate()
+}
+
+// UntoleratedTaints returns the taints with the effect that none of the
+// tolerations match.
+func UntoleratedTaints(taints []Taint, tolerations []Toleration, effect TaintEffect) []Taint {
+	var result []Taint
+	for _, taint := range taints {
+		if taint.Effect != effect {
+			continue
+		}
+		tolerated := false
+		for _, toleration := range tolerations {
+			if toleration.Tolerates(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			result = append(result, taint)
+		}
+	}
+	return result
+}
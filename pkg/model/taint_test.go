@@ -0,0 +1,46 @@
+//go:build unit || !integration
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaint(t *testing.T) {
+	taint, err := ParseTaint("reserved=teamA:NoSchedule")
+	require.NoError(t, err)
+	require.Equal(t, Taint{Key: "reserved", Value: "teamA", Effect: TaintEffectNoSchedule}, taint)
+	require.Equal(t, "reserved=teamA:NoSchedule", taint.String())
+
+	for _, invalid := range []string{"reserved=teamA", ":NoSchedule", "gpu:Evict"} {
+		_, err = ParseTaint(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestUntoleratedTaints(t *testing.T) {
+	taints := []Taint{
+		{Key: "reserved", Value: "teamA", Effect: TaintEffectNoSchedule},
+		{Key: "gpu", Value: "true", Effect: TaintEffectNoSchedule},
+		{Key: "spot", Effect: TaintEffectPreferNoSchedule},
+	}
+	parse := func(s ...string) []Toleration {
+		var result []Toleration
+		for _, toleration := range s {
+			parsed, err := ParseToleration(toleration)
+			require.NoError(t, err)
+			result = append(result, parsed)
+		}
+		return result
+	}
+
+	require.Len(t, UntoleratedTaints(taints, nil, TaintEffectNoSchedule), 2)
+	require.Equal(t, []Taint{taints[1]},
+		UntoleratedTaints(taints, parse("reserved=teamA"), TaintEffectNoSchedule))
+	require.Len(t, UntoleratedTaints(taints, parse("reserved=teamB", "gpu"), TaintEffectNoSchedule), 1)
+	require.Empty(t, UntoleratedTaints(taints, parse("reserved:NoSchedule", "gpu=true:NoSchedule"), TaintEffectNoSchedule))
+	require.Len(t, UntoleratedTaints(taints, parse("gpu:PreferNoSchedule"), TaintEffectNoSchedule), 2)
+	require.Empty(t, UntoleratedTaints(taints, []Toleration{{Operator: TolerationOpExists}}, TaintEffectPreferNoSchedule))
+}
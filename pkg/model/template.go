@@ -0,0 +1,66 @@
+package model
+
+import "time"
+
+// JobTemplate is a named job spec that the requester hosts so that clients can
+// submit jobs from it rather than each keeping a copy of the full spec. The
+// strings in the spec can refer to the parameters of the template, such as
+// {{.input}}, which clients give values when they submit a job from it.
+type JobTemplate struct {
+	// Name is the name that clients refer to the template by
+	Name string `json:"Name"`
+	// ClientID is the id of the client that created the template, which is
+	// the only client that can change or delete it
+	ClientID string `json:"ClientID"`
+	// Description says what the jobs submitted from the template do
+	Description string `json:"Description,omitempty"`
+	// APIVersion is the version of the API that the spec is for
+	APIVersion string `json:"APIVersion"`
+	// Spec is the spec of the jobs submitted from the template, such as their
+	// image, resources and publisher
+	Spec Spec `json:"Spec"`
+	// Parameters are the parameters that the spec refers to
+	Parameters []TemplateParameter `json:"Parameters,omitempty"`
+	// CreatedAt is when the template was created
+	CreatedAt time.Time `json:"CreatedAt"`
+	// UpdatedAt is when the template was last changed
+	UpdatedAt time.Time `json:"UpdatedAt"`
+}
+
+// TemplateParameter is a value that clients can give when they submit a job
+// from a template.
+type TemplateParameter struct {
+	// Name is how the spec refers to the parameter
+	Name string `json:"Name"`
+	// Description says what the parameter is for
+	Description string `json:"Description,omitempty"`
+	// Default is the value of the parameter when clients don't give one. The
+	// parameter is required if it's empty.
+	Default string `json:"Default,omitempty"`
+}
+
+type TemplateSetPayload struct {
+	// the id of the client that is setting the template, which must have
+	// created it if it exists
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the template to set, which replaces any template with the same name
+	Template JobTemplate `json:"Template"`
+}
+
+func (t TemplateSetPayload) GetClientID() string {
+	return t.ClientID
+}
+
+type TemplateDeletePayload struct {
+	// the id of the client that is deleting the template, which must have
+	// created it
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the name of the template to delete
+	Name string `json:"Name,omitempty" validate:"required"`
+}
+
+func (t TemplateDeletePayload) GetClientID() string {
+	return t.ClientID
+}
@@ -0,0 +1,151 @@
+package model
+
+import (
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// Workflow is a set of jobs, called stages, that the requester submits once
+// the stages they depend on have completed, mounting the results those stages
+// published as their inputs.
+type Workflow struct {
+	// ID is the id of the workflow, which is not the id of any of its jobs
+	ID string `json:"ID"`
+	// ClientID is the id of the client that submitted the workflow, whose jobs
+	// it submits
+	ClientID string `json:"ClientID"`
+	// APIVersion is the version of the API that the specs of the stages are for
+	APIVersion string `json:"APIVersion"`
+	// State is the state of the workflow as a whole
+	State WorkflowState `json:"State"`
+	// Stages are the stages of the workflow, with every stage after the stages
+	// it depends on
+	Stages []WorkflowStage `json:"Stages"`
+	// CreatedAt is when the workflow was submitted
+	CreatedAt time.Time `json:"CreatedAt"`
+	// UpdatedAt is when the state of the workflow or one of its stages last
+	// changed
+	UpdatedAt time.Time `json:"UpdatedAt"`
+}
+
+// WorkflowStageSpec is a stage of a workflow as it's submitted.
+type WorkflowStageSpec struct {
+	// Name identifies the stage to the stages that depend on it
+	Name string `json:"Name"`
+	// DependsOn are the names of the stages that must complete before this
+	// stage is submitted, besides the stages it reads the outputs of
+	DependsOn []string `json:"DependsOn,omitempty"`
+	// Inputs are the published outputs of other stages to mount in the job
+	Inputs []WorkflowInput `json:"Inputs,omitempty"`
+	// Spec is the spec of the job of the stage
+	Spec Spec `json:"Spec"`
+}
+
+// Dependencies returns the names of the stages the stage depends on,
+// including the stages it reads the outputs of.
+func (s WorkflowStageSpec) Dependencies() []string {
+	result := append([]string{}, s.DependsOn...)
+	for _, input := range s.Inputs {
+		if !slices.Contains(result, input.Stage) {
+			result = append(result, input.Stage)
+		}
+	}
+	return result
+}
+
+// WorkflowInput mounts the results published by a stage in the job of
+// another stage.
+type WorkflowInput struct {
+	// Stage is the name of the stage whose published results are mounted
+	Stage string `json:"Stage"`
+	// Path is where the results are mounted in the job. If the stage published
+	// more than one result, e.g. because it ran on several nodes, they are
+	// mounted in numbered directories under the path.
+	Path string `json:"Path"`
+}
+
+// WorkflowStage is a stage of a workflow and how far it has got.
+type WorkflowStage struct {
+	WorkflowStageSpec
+	// State is the state of the stage
+	State WorkflowStageState `json:"State"`
+	// JobID is the id of the job of the stage, once it has been submitted
+	JobID string `json:"JobID,omitempty"`
+	// Results are the results that the job of the stage published
+	Results []StorageSpec `json:"Results,omitempty"`
+	// Error is why the stage failed or was skipped
+	Error string `json:"Error,omitempty"`
+}
+
+// WorkflowState is the state of a workflow as a whole.
+type WorkflowState string
+
+const (
+	// WorkflowStateInProgress is a workflow with stages left to run.
+	WorkflowStateInProgress WorkflowState = "InProgress"
+	// WorkflowStateCompleted is a workflow whose stages all completed.
+	WorkflowStateCompleted WorkflowState = "Completed"
+	// WorkflowStateFailed is a workflow with a stage that failed, once the
+	// stages that didn't depend on it have finished.
+	WorkflowStateFailed WorkflowState = "Failed"
+	// WorkflowStateCancelled is a workflow that was cancelled by the user.
+	WorkflowStateCancelled WorkflowState = "Cancelled"
+)
+
+// IsTerminal returns true if the workflow won't submit any more jobs.
+func (s WorkflowState) IsTerminal() bool {
+	return s != WorkflowStateInProgress
+}
+
+// WorkflowStageState is the state of a stage of a workflow.
+type WorkflowStageState string
+
+const (
+	// WorkflowStagePending is a stage waiting for the stages it depends on.
+	WorkflowStagePending WorkflowStageState = "Pending"
+	// WorkflowStageRunning is a stage whose job has been submitted.
+	WorkflowStageRunning WorkflowStageState = "Running"
+	// WorkflowStageCompleted is a stage whose job completed.
+	WorkflowStageCompleted WorkflowStageState = "Completed"
+	// WorkflowStageFailed is a stage whose job failed or couldn't be submitted.
+	WorkflowStageFailed WorkflowStageState = "Failed"
+	// WorkflowStageSkipped is a stage that depends on a stage that failed.
+	WorkflowStageSkipped WorkflowStageState = "Skipped"
+	// WorkflowStageCancelled is a stage of a workflow that was cancelled.
+	WorkflowStageCancelled WorkflowStageState = "Cancelled"
+)
+
+// IsTerminal returns true if the stage won't change state again.
+func (s WorkflowStageState) IsTerminal() bool {
+	return s != WorkflowStagePending && s != WorkflowStageRunning
+}
+
+type WorkflowSubmitPayload struct {
+	// the id of the client that is submitting the workflow
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	APIVersion string `json:"APIVersion,omitempty" example:"V1beta1" validate:"required"`
+
+	// the stages of the workflow, in any order
+	Stages []WorkflowStageSpec `json:"Stages,omitempty" validate:"required"`
+}
+
+func (w WorkflowSubmitPayload) GetClientID() string {
+	return w.ClientID
+}
+
+type WorkflowCancelPayload struct {
+	// the id of the client that submitted the workflow
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the id of the workflow to cancel
+	WorkflowID string `json:"WorkflowID,omitempty" validate:"required"`
+
+	// the reason for cancelling the workflow
+	Reason string `json:"Reason,omitempty"`
+}
+
+func (w WorkflowCancelPayload) GetClientID() string {
+	return w.ClientID
+}
@@ -2,10 +2,10 @@ package node
 
 import (
 	"context"
+	"path/filepath"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
 	"github.com/bacalhau-project/bacalhau/pkg/compute"
-	compute_bidstrategies "github.com/bacalhau-project/bacalhau/pkg/compute/bidstrategy"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity/disk"
 	compute_publicapi "github.com/bacalhau-project/bacalhau/pkg/compute/publicapi"
@@ -13,14 +13,13 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/store/inlocalstore"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/store/inmemory"
+	pkgconfig "github.com/bacalhau-project/bacalhau/pkg/config"
 	"github.com/bacalhau-project/bacalhau/pkg/executor"
-	executor_util "github.com/bacalhau-project/bacalhau/pkg/executor/util"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher"
 	"github.com/bacalhau-project/bacalhau/pkg/simulator"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
-	storage_bidstrategy "github.com/bacalhau-project/bacalhau/pkg/storage/bidstrategy"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	"github.com/bacalhau-project/bacalhau/pkg/transport/bprotocol"
 	simulator_protocol "github.com/bacalhau-project/bacalhau/pkg/transport/simulator"
@@ -37,6 +36,7 @@ type Compute struct {
 	computeCallback     *bprotocol.CallbackProxy
 	cleanupFunc         func(ctx context.Context)
 	computeInfoProvider model.ComputeNodeInfoProvider
+	drainer             *compute.Drainer
 }
 
 //nolint:funlen
@@ -84,6 +84,11 @@ func NewComputeNode(
 		computeCallback = standardComputeCallback
 	}
 
+	checkpointOptions := config.CheckpointOptions
+	if checkpointOptions.Dir == "" {
+		checkpointOptions.Dir = filepath.Join(pkgconfig.GetStoragePath(), "checkpoints")
+	}
+	clientReputations := compute.NewClientReputations(config.ClientReputation)
 	baseExecutor := compute.NewBaseExecutor(compute.BaseExecutorParams{
 		ID:              host.ID().String(),
 		Callback:        computeCallback,
@@ -91,7 +96,15 @@ func NewComputeNode(
 		Executors:       executors,
 		Verifiers:       verifiers,
 		Publishers:      publishers,
+		Prefetcher:      config.prefetcher,
 		SimulatorConfig: config.SimulatorConfig,
+		PublishRetry:    config.PublishRetryOptions,
+
+		DiskUsageCheckInterval: config.DiskUsageCheckInterval,
+		Checkpoint:             checkpointOptions,
+		ResultCache:            config.ResultCacheOptions,
+		ClientReputations:      clientReputations,
+		SigningKey:             host.Peerstore().PrivKey(host.ID()),
 	})
 
 	bufferRunner := compute.NewExecutorBuffer(compute.ExecutorBufferParams{
@@ -102,6 +115,11 @@ func NewComputeNode(
 		EnqueuedCapacityTracker:    enqueuedCapacityTracker,
 		DefaultJobExecutionTimeout: config.DefaultJobExecutionTimeout,
 		BackoffDuration:            config.ExecutorBufferBackoffDuration,
+		ReservationTimeout:         config.JobNegotiationTimeout,
+		GPUAllocator: capacity.NewGPUAllocator(capacity.GPUAllocatorParams{
+			GPUs: config.GPUs,
+		}),
+		EngineConcurrency: config.EngineConcurrency,
 	})
 	runningInfoProvider := sensors.NewRunningExecutionsInfoProvider(sensors.RunningExecutionsInfoProviderParams{
 		Name:          "ActiveJobs",
@@ -119,6 +137,43 @@ func NewComputeNode(
 		})
 		go loggingSensor.Start(loggingCtx)
 	}
+	storageHealthSensor := sensors.NewStorageHealthSensor(sensors.StorageHealthSensorParams{
+		Storages: storages,
+		Interval: config.StorageHealthCheckInterval,
+	})
+	storageHealthCtx, cancelStorageHealth := context.WithCancel(ctx)
+	cleanupManager.RegisterCallback(func() error {
+		cancelStorageHealth()
+		return nil
+	})
+	go storageHealthSensor.Start(storageHealthCtx)
+
+	scratchGC := compute.NewScratchGC(compute.ScratchGCParams{
+		Store:   executionStore,
+		Options: config.ScratchGCOptions,
+	})
+	scratchGCCtx, cancelScratchGC := context.WithCancel(ctx)
+	cleanupManager.RegisterCallback(func() error {
+		cancelScratchGC()
+		return nil
+	})
+	go scratchGC.Start(scratchGCCtx)
+
+	var benchmarks compute.BenchmarkProvider
+	var benchmarkSensor *sensors.BenchmarkSensor
+	if config.Benchmark {
+		benchmarkSensor = sensors.NewBenchmarkSensor(sensors.BenchmarkSensorParams{
+			StoragePath: pkgconfig.GetStoragePath(),
+			NetworkURL:  config.BenchmarkNetworkURL,
+		})
+		benchmarkCtx, cancelBenchmark := context.WithCancel(ctx)
+		cleanupManager.RegisterCallback(func() error {
+			cancelBenchmark()
+			return nil
+		})
+		go benchmarkSensor.Start(benchmarkCtx)
+		benchmarks = benchmarkSensor
+	}
 
 	// endpoint/frontend
 	capacityCalculator := capacity.NewChainedUsageCalculator(capacity.ChainedUsageCalculatorParams{
@@ -132,40 +187,40 @@ func NewComputeNode(
 		},
 	})
 
-	biddingStrategy := bidstrategy.NewChainedBidStrategy(
-		bidstrategy.FromJobSelectionPolicy(config.JobSelectionPolicy),
-		compute_bidstrategies.NewMaxCapacityStrategy(compute_bidstrategies.MaxCapacityStrategyParams{
-			MaxJobRequirements: config.JobResourceLimits,
-		}),
-		compute_bidstrategies.NewAvailableCapacityStrategy(ctx, compute_bidstrategies.AvailableCapacityStrategyParams{
-			RunningCapacityTracker:  runningCapacityTracker,
-			EnqueuedCapacityTracker: enqueuedCapacityTracker,
-		}),
-		// TODO XXX: don't hardcode networkSize, calculate this dynamically from
-		//  libp2p instead somehow. https://github.com/bacalhau-project/bacalhau/issues/512
-		bidstrategy.NewDistanceDelayStrategy(bidstrategy.DistanceDelayStrategyParams{
-			NetworkSize: 1,
-		}),
-		executor_util.NewExecutorSpecificBidStrategy(executors),
-		executor_util.NewInputLocalityStrategy(executor_util.InputLocalityStrategyParams{
-			Locality:  config.JobSelectionPolicy.Locality,
-			Executors: executors,
-		}),
-		bidstrategy.NewProviderInstalledStrategy[model.Verifier, verifier.Verifier](
-			verifiers,
-			func(j *model.Job) model.Verifier { return j.Spec.Verifier },
-		),
-		bidstrategy.NewProviderInstalledStrategy[model.Publisher, publisher.Publisher](
-			publishers,
-			func(j *model.Job) model.Publisher { return j.Spec.Publisher },
-		),
-		storage_bidstrategy.NewStorageInstalledBidStrategy(storages),
-		bidstrategy.NewTimeoutStrategy(bidstrategy.TimeoutStrategyParams{
-			MaxJobExecutionTimeout:                config.MaxJobExecutionTimeout,
-			MinJobExecutionTimeout:                config.MinJobExecutionTimeout,
-			JobExecutionTimeoutClientIDBypassList: config.JobExecutionTimeoutClientIDBypassList,
-		}),
-	)
+	// executions can take up to the max execution timeout to finish after the node stops bidding
+	drainer := compute.NewDrainer(compute.DrainerParams{
+		ID:      host.ID().String(),
+		Store:   executionStore,
+		Timeout: config.MaxJobExecutionTimeout,
+		Admins:  append([]string{system.GetClientID()}, config.Admins...),
+	})
+	biddingWindows := compute.NewBiddingWindows(compute.BiddingWindowsParams{
+		Windows:  config.BiddingWindows,
+		Location: config.BiddingWindowsLocation,
+		Admins:   append([]string{system.GetClientID()}, config.Admins...),
+	})
+
+	bidStrategyChain := defaultBidStrategyChain(config)
+	if config.BidStrategyChain != nil {
+		bidStrategyChain = *config.BidStrategyChain
+	}
+	biddingStrategy, err := bidstrategy.NewChainFromConfig(bidStrategyChain, bidStrategyFactories(ctx, bidStrategyDependencies{
+		cleanupManager:          cleanupManager,
+		config:                  config,
+		executionStore:          executionStore,
+		runningCapacityTracker:  runningCapacityTracker,
+		enqueuedCapacityTracker: enqueuedCapacityTracker,
+		storages:                storages,
+		executors:               executors,
+		verifiers:               verifiers,
+		publishers:              publishers,
+		drainer:                 drainer,
+		biddingWindows:          biddingWindows,
+		clientReputations:       clientReputations,
+	}))
+	if err != nil {
+		return nil, err
+	}
 
 	// node info
 	nodeInfoProvider := compute.NewNodeInfoProvider(compute.NodeInfoProviderParams{
@@ -173,14 +228,42 @@ func NewComputeNode(
 		CapacityTracker:    runningCapacityTracker,
 		ExecutorBuffer:     bufferRunner,
 		MaxJobRequirements: config.JobResourceLimits,
+		StoragePath:        pkgconfig.GetStoragePath(),
+		Preemptible:        config.Preemptible,
+		LocalData:          config.localData,
+		Pricing:            config.Pricing,
+		GPUs:               config.GPUs,
+		Taints:             config.Taints,
+		TEE:                config.TEE,
+		Benchmarks:         benchmarks,
 	})
 
+	var attester compute.Attester
+	if config.TEE != "" {
+		attester, err = compute.NewTSMAttester(compute.TSMAttesterParams{
+			TEE:              config.TEE,
+			ReportDir:        config.TEEReportDir,
+			CertificatesFile: config.TEECertificates,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	bidHistory := compute.NewBidHistory(compute.DefaultBidHistorySize)
 	baseEndpoint := compute.NewBaseEndpoint(compute.BaseEndpointParams{
-		ID:              host.ID().String(),
-		ExecutionStore:  executionStore,
-		UsageCalculator: capacityCalculator,
-		BidStrategy:     biddingStrategy,
-		Executor:        bufferRunner,
+		ID:                host.ID().String(),
+		ExecutionStore:    executionStore,
+		UsageCalculator:   capacityCalculator,
+		BidStrategy:       biddingStrategy,
+		Executor:          bufferRunner,
+		Executors:         executors,
+		Prefetcher:        config.prefetcher,
+		Reserver:          bufferRunner,
+		BidHistory:        bidHistory,
+		Pricing:           config.Pricing,
+		ClientReputations: clientReputations,
+		Attester:          attester,
 	})
 
 	// if this node is the simulator, then we set the simulator request handler as the stream handler
@@ -193,6 +276,7 @@ func NewComputeNode(
 		bprotocol.NewComputeHandler(bprotocol.ComputeHandlerParams{
 			Host:            host,
 			ComputeEndpoint: baseEndpoint,
+			LogStreamer:     baseEndpoint,
 		})
 	}
 
@@ -200,14 +284,28 @@ func NewComputeNode(
 	debugInfoProviders := []model.DebugInfoProvider{
 		runningInfoProvider,
 		sensors.NewCompletedJobs(executionStore),
+		storageHealthSensor,
+	}
+	if benchmarkSensor != nil {
+		debugInfoProviders = append(debugInfoProviders, benchmarkSensor)
 	}
 
 	// register compute public http apis
 	computeAPIServer := compute_publicapi.NewComputeAPIServer(compute_publicapi.ComputeAPIServerParams{
 		APIServer:          apiServer,
 		DebugInfoProviders: debugInfoProviders,
+		Drainer:            drainer,
+		BiddingWindows:     biddingWindows,
+		Introspector: compute.NewIntrospector(compute.IntrospectorParams{
+			ID:                     host.ID().String(),
+			Store:                  executionStore,
+			ExecutorBuffer:         bufferRunner,
+			RunningCapacityTracker: runningCapacityTracker,
+			StorageCache:           config.storageCache,
+			BidHistory:             bidHistory,
+		}),
 	})
-	err := computeAPIServer.RegisterAllHandlers()
+	err = computeAPIServer.RegisterAllHandlers()
 	if err != nil {
 		return nil, err
 	}
@@ -225,9 +323,15 @@ func NewComputeNode(
 		computeCallback:     standardComputeCallback,
 		cleanupFunc:         cleanupFunc,
 		computeInfoProvider: nodeInfoProvider,
+		drainer:             drainer,
 	}, nil
 }
 
+// Drained is closed once the node has been drained and should shut down.
+func (c *Compute) Drained() <-chan struct{} {
+	return c.drainer.Drained()
+}
+
 func (c *Compute) RegisterLocalComputeCallback(callback compute.Callback) {
 	c.computeCallback.RegisterLocalComputeCallback(callback)
 }
@@ -0,0 +1,247 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	compute_bidstrategies "github.com/bacalhau-project/bacalhau/pkg/compute/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	pkgconfig "github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	executor_util "github.com/bacalhau-project/bacalhau/pkg/executor/util"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/wasm"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	storage_bidstrategy "github.com/bacalhau-project/bacalhau/pkg/storage/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/bacalhau-project/bacalhau/pkg/verifier"
+)
+
+// defaultBidStrategies are the strategies that the compute node asks whether to bid on jobs, in order, unless its
+// config sets a chain. The node's WASM modules and moderation service are asked last.
+var defaultBidStrategies = []string{
+	"drain",
+	"bidding-windows",
+	// before the strategies that trust the ClientID of the job
+	"client-signature",
+	"job-selection-policy",
+	"max-capacity",
+	"available-capacity",
+	"disk-space",
+	"load",
+	"client-reputation",
+	"distance-delay",
+	"executor-installed",
+	"input-locality",
+	"verifier-installed",
+	"publisher-installed",
+	"storage-installed",
+	"input-policy",
+	"timeout",
+	"price",
+	"min-price",
+	"gpu",
+	"taints",
+	"tee",
+	"workloads",
+	// after the strategies that could reject the job, so that the node bids on a share of the jobs it can run
+	"probability",
+}
+
+// defaultBidStrategyChain returns the chain of the default strategies, the node's WASM modules and its moderation
+// service, which is last so that the service is only asked about jobs the node would otherwise bid on.
+func defaultBidStrategyChain(config ComputeConfig) bidstrategy.ChainConfig {
+	var chain bidstrategy.ChainConfig
+	for _, name := range defaultBidStrategies {
+		chain.Strategies = append(chain.Strategies, bidstrategy.StrategyConfig{Name: name})
+	}
+	for _, path := range config.BidStrategyModules {
+		params, _ := json.Marshal(wasm.BidStrategyParams{Path: path})
+		chain.Strategies = append(chain.Strategies, bidstrategy.StrategyConfig{Name: "wasm", Params: params})
+	}
+	chain.Strategies = append(chain.Strategies, bidstrategy.StrategyConfig{Name: "moderation"})
+	return chain
+}
+
+// bidStrategyDependencies are what the strategies of the compute node are built with.
+type bidStrategyDependencies struct {
+	cleanupManager          *system.CleanupManager
+	config                  ComputeConfig
+	executionStore          store.ExecutionStore
+	runningCapacityTracker  capacity.Tracker
+	enqueuedCapacityTracker capacity.Tracker
+	storages                storage.StorageProvider
+	executors               executor.ExecutorProvider
+	verifiers               verifier.VerifierProvider
+	publishers              publisher.PublisherProvider
+	drainer                 *compute.Drainer
+	biddingWindows          *compute.BiddingWindows
+	clientReputations       *compute.ClientReputations
+}
+
+// bidStrategyFactories returns the strategies that the chain of the compute node can be made of, by name. Strategies
+// that are configured by the node's flags take them as their params, and the ones that take params can override them.
+//
+//nolint:funlen
+func bidStrategyFactories(ctx context.Context, deps bidStrategyDependencies) map[string]bidstrategy.StrategyFactory {
+	config := deps.config
+	return map[string]bidstrategy.StrategyFactory{
+		"drain":           bidstrategy.NoParams(deps.drainer),
+		"bidding-windows": bidstrategy.NoParams(deps.biddingWindows),
+		"client-signature": func(params json.RawMessage) (bidstrategy.BidStrategy, error) {
+			strategyParams := bidstrategy.ClientSignatureStrategyParams{Require: config.RequireClientSignatures}
+			if err := bidstrategy.DecodeParams(params, &strategyParams); err != nil {
+				return nil, err
+			}
+			return bidstrategy.NewClientSignatureStrategy(strategyParams), nil
+		},
+		"job-selection-policy": func(params json.RawMessage) (bidstrategy.BidStrategy, error) {
+			policy := config.JobSelectionPolicy
+			if err := bidstrategy.DecodeParams(params, &policy); err != nil {
+				return nil, err
+			}
+			return bidstrategy.FromJobSelectionPolicy(policy), nil
+		},
+		"max-capacity": bidstrategy.NoParams(
+			compute_bidstrategies.NewMaxCapacityStrategy(compute_bidstrategies.MaxCapacityStrategyParams{
+				MaxJobRequirements: config.JobResourceLimits,
+			})),
+		"available-capacity": bidstrategy.NoParams(
+			compute_bidstrategies.NewAvailableCapacityStrategy(ctx, compute_bidstrategies.AvailableCapacityStrategyParams{
+				RunningCapacityTracker:  deps.runningCapacityTracker,
+				EnqueuedCapacityTracker: deps.enqueuedCapacityTracker,
+			})),
+		"disk-space": bidstrategy.NoParams(
+			compute_bidstrategies.NewDiskSpaceStrategy(compute_bidstrategies.DiskSpaceStrategyParams{
+				StoragePath:             pkgconfig.GetStoragePath(),
+				EnqueuedCapacityTracker: deps.enqueuedCapacityTracker,
+			})),
+		"load": func(params json.RawMessage) (bidstrategy.BidStrategy, error) {
+			limits := config.LoadLimits
+			if err := bidstrategy.DecodeParams(params, &limits); err != nil {
+				return nil, err
+			}
+			return compute_bidstrategies.NewLoadStrategy(compute_bidstrategies.LoadStrategyParams{
+				Limits:      limits,
+				Store:       deps.executionStore,
+				StoragePath: pkgconfig.GetStoragePath(),
+			}), nil
+		},
+		"client-reputation": bidstrategy.NoParams(deps.clientReputations),
+		"distance-delay": func(params json.RawMessage) (bidstrategy.BidStrategy, error) {
+			// TODO XXX: don't hardcode networkSize, calculate this dynamically from
+			//  libp2p instead somehow. https://github.com/bacalhau-project/bacalhau/issues/512
+			distanceParams := bidstrategy.DistanceDelayStrategyParams{NetworkSize: 1}
+			if err := bidstrategy.DecodeParams(params, &distanceParams); err != nil {
+				return nil, err
+			}
+			if distanceParams.NetworkSize < 1 {
+				return nil, fmt.Errorf("NetworkSize must be at least 1")
+			}
+			return bidstrategy.NewDistanceDelayStrategy(distanceParams), nil
+		},
+		"executor-installed": bidstrategy.NoParams(executor_util.NewExecutorSpecificBidStrategy(deps.executors)),
+		"input-locality": bidstrategy.NoParams(
+			executor_util.NewInputLocalityStrategy(executor_util.InputLocalityStrategyParams{
+				Locality:  config.JobSelectionPolicy.Locality,
+				Executors: deps.executors,
+			})),
+		"verifier-installed": bidstrategy.NoParams(
+			bidstrategy.NewProviderInstalledStrategy[model.Verifier, verifier.Verifier](
+				deps.verifiers,
+				func(j *model.Job) model.Verifier { return j.Spec.Verifier },
+			)),
+		"publisher-installed": bidstrategy.NoParams(
+			bidstrategy.NewProviderInstalledArrayStrategy[model.Publisher, publisher.Publisher](
+				deps.publishers,
+				func(j *model.Job) []model.Publisher { return j.Spec.AllPublishers() },
+			)),
+		"storage-installed": bidstrategy.NoParams(storage_bidstrategy.NewStorageInstalledBidStrategy(deps.storages)),
+		"input-policy": func(params json.RawMessage) (bidstrategy.BidStrategy, error) {
+			policy := config.InputPolicy
+			if err := bidstrategy.DecodeParams(params, &policy); err != nil {
+				return nil, err
+			}
+			return storage_bidstrategy.NewInputPolicyStrategy(storage_bidstrategy.InputPolicyStrategyParams{
+				Policy:   policy,
+				Storages: deps.storages,
+			}), nil
+		},
+		"timeout": bidstrategy.NoParams(bidstrategy.NewTimeoutStrategy(bidstrategy.TimeoutStrategyParams{
+			MaxJobExecutionTimeout:                config.MaxJobExecutionTimeout,
+			MinJobExecutionTimeout:                config.MinJobExecutionTimeout,
+			JobExecutionTimeoutClientIDBypassList: config.JobExecutionTimeoutClientIDBypassList,
+		})),
+		"price": func(params json.RawMessage) (bidstrategy.BidStrategy, error) {
+			pricing, err := decodePricing(params, config.Pricing)
+			if err != nil {
+				return nil, err
+			}
+			return bidstrategy.NewPriceStrategy(bidstrategy.PriceStrategyParams{Pricing: pricing}), nil
+		},
+		"min-price": func(params json.RawMessage) (bidstrategy.BidStrategy, error) {
+			pricing, err := decodePricing(params, config.Pricing)
+			if err != nil {
+				return nil, err
+			}
+			return bidstrategy.NewMinPriceStrategy(bidstrategy.MinPriceStrategyParams{Pricing: pricing}), nil
+		},
+		"gpu": bidstrategy.NoParams(
+			compute_bidstrategies.NewGPUStrategy(compute_bidstrategies.GPUStrategyParams{GPUs: config.GPUs})),
+		"taints": bidstrategy.NoParams(
+			compute_bidstrategies.NewTaintsStrategy(compute_bidstrategies.TaintsStrategyParams{Taints: config.Taints})),
+		"tee": bidstrategy.NoParams(bidstrategy.NewTEEStrategy(bidstrategy.TEEStrategyParams{TEE: config.TEE})),
+		"workloads": func(params json.RawMessage) (bidstrategy.BidStrategy, error) {
+			workloads := config.Workloads
+			if err := bidstrategy.DecodeParams(params, &workloads); err != nil {
+				return nil, err
+			}
+			return compute_bidstrategies.NewWorkloadStrategy(workloads), nil
+		},
+		"probability": func(params json.RawMessage) (bidstrategy.BidStrategy, error) {
+			probability := struct {
+				Probability float64
+				ScaleByLoad bool
+			}{config.BidProbability, config.BidProbabilityScaleByLoad}
+			if err := bidstrategy.DecodeParams(params, &probability); err != nil {
+				return nil, err
+			}
+			if probability.Probability < 0 || probability.Probability > 1 {
+				return nil, fmt.Errorf("Probability must be between 0 and 1")
+			}
+			return compute_bidstrategies.NewProbabilityStrategy(compute_bidstrategies.ProbabilityStrategyParams{
+				Probability:            probability.Probability,
+				ScaleByLoad:            probability.ScaleByLoad,
+				RunningCapacityTracker: deps.runningCapacityTracker,
+			}), nil
+		},
+		"wasm": func(params json.RawMessage) (bidstrategy.BidStrategy, error) {
+			var moduleParams wasm.BidStrategyParams
+			if err := bidstrategy.DecodeParams(params, &moduleParams); err != nil {
+				return nil, err
+			}
+			if moduleParams.Path == "" {
+				return nil, fmt.Errorf("the Path of the WASM module is required")
+			}
+			moduleStrategy, err := wasm.NewBidStrategy(ctx, moduleParams)
+			if err != nil {
+				return nil, err
+			}
+			deps.cleanupManager.RegisterCallbackWithContext(moduleStrategy.Close)
+			return moduleStrategy, nil
+		},
+		"moderation": bidstrategy.NoParams(bidstrategy.NewModerationStrategy(config.Moderation)),
+	}
+}
+
+func decodePricing(params json.RawMessage, pricing model.Pricing) (model.Pricing, error) {
+	if err := bidstrategy.DecodeParams(params, &pricing); err != nil {
+		return pricing, err
+	}
+	return pricing, pricing.Validate()
+}
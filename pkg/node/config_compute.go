@@ -5,8 +5,35 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	compute_bidstrategies "github.com/bacalhau-project/bacalhau/pkg/compute/bidstrategy"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/apptainer"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/containerd"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/docker"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/duckdb"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/kubernetes"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/plugin"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/process"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/python"
+	"github.com/bacalhau-project/bacalhau/pkg/executor/ssh"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/azureblob"
+	storage_bidstrategy "github.com/bacalhau-project/bacalhau/pkg/storage/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/bittorrent"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/cache"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/extract"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/filecoin"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/gcs"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/huggingface"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/ipfs/mount"
+	localdirectory "github.com/bacalhau-project/bacalhau/pkg/storage/local_directory"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/locality"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/prefetch"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/s3"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/url/urldownload"
+	"github.com/rs/zerolog/log"
 )
 
 type ComputeConfigParams struct {
@@ -20,6 +47,16 @@ type ComputeConfigParams struct {
 
 	ExecutorBufferBackoffDuration time.Duration
 
+	// how many executions of each engine may run at once, with engines not in it uncapped
+	EngineConcurrency map[model.Engine]int
+
+	// how loaded the node can be and still bid on jobs
+	LoadLimits compute_bidstrategies.LoadLimits
+
+	// the chance that the node bids on jobs it could run, or always if zero, and whether it bids less when loaded
+	BidProbability            float64
+	BidProbabilityScaleByLoad bool
+
 	// Timeout config
 	JobNegotiationTimeout      time.Duration
 	MinJobExecutionTimeout     time.Duration
@@ -28,12 +65,105 @@ type ComputeConfigParams struct {
 
 	JobExecutionTimeoutClientIDBypassList []string
 
+	// whether the requester may preempt executions on the node
+	Preemptible bool
+
+	// what the node charges for the resources that executions use
+	Pricing model.Pricing
+
+	// the model and memory of each GPU of the node, detected if not set
+	GPUs []model.GPU
+
+	// the taints that keep the jobs that don't tolerate them off the node
+	Taints []model.Taint
+
+	// the kind of TEE that the node runs in, if any, where the kernel creates its attestation reports, and the PEM
+	// file of the certificates that its evidence is verified with if the kernel doesn't return them
+	TEE             model.TEEType
+	TEEReportDir    string
+	TEECertificates string
+
+	// whether the node only bids on jobs that are signed by their clients
+	RequireClientSignatures bool
+
+	// the clients that can drain the node or override its bidding windows, as well as the node's own client
+	Admins []string
+
+	// when the node bids on jobs, in the time zone of the location, or all the time if there are no windows
+	BiddingWindows         []compute.TimeWindow
+	BiddingWindowsLocation *time.Location
+
+	// whether the node benchmarks itself when it starts, and the URL it downloads to benchmark its network, if any
+	Benchmark           bool
+	BenchmarkNetworkURL string
+
 	// Bid strategies config
 	JobSelectionPolicy model.JobSelectionPolicy
 
+	// the WASM modules that decide whether the node bids on jobs
+	BidStrategyModules []string
+
+	// the strategies that decide whether the node bids on jobs, in order, or the default ones if nil
+	BidStrategyChain *bidstrategy.ChainConfig
+
+	// the images and WASM modules that the node runs
+	Workloads compute_bidstrategies.WorkloadStrategyParams
+
+	// what the inputs of the jobs that the node runs can be
+	InputPolicy storage_bidstrategy.InputPolicy
+
+	// the moderation service that approves jobs before the node bids on them, if its URL is set
+	Moderation bidstrategy.ModerationStrategyParams
+
+	// how the node delays and rejects bids on the jobs of clients that abuse it
+	ClientReputation compute.ClientReputationsParams
+
 	// logging running executions
 	LogRunningExecutionsInterval time.Duration
 
+	// checking the health of storages
+	StorageHealthCheckInterval time.Duration
+
+	// measuring the scratch space of running executions, or never if negative
+	DiskUsageCheckInterval time.Duration
+
+	// Executor config
+	DockerOptions     docker.ExecutorOptions
+	ContainerdOptions containerd.ExecutorOptions
+	KubernetesOptions kubernetes.ExecutorOptions
+	ApptainerOptions  apptainer.ExecutorOptions
+	ProcessOptions    process.ExecutorOptions
+	PythonOptions     python.ExecutorOptions
+	PluginOptions     plugin.ExecutorOptions
+	SSHOptions        ssh.ExecutorOptions
+	DuckDBOptions     duckdb.ExecutorOptions
+
+	// Storage config
+	S3Options             s3.StorageOptions
+	URLDownloadOptions    urldownload.StorageOptions
+	AzureBlobOptions      azureblob.StorageOptions
+	GCSOptions            gcs.StorageOptions
+	HuggingFaceOptions    huggingface.StorageOptions
+	LocalDirectoryOptions localdirectory.StorageOptions
+	BitTorrentOptions     bittorrent.StorageOptions
+	FilecoinOptions       filecoin.StorageOptions
+	ExtractOptions        extract.Options
+	StorageCacheOptions   cache.Options
+	IPFSMountOptions      mount.Options
+	PrefetchOptions       prefetch.Options
+
+	// Publishing config
+	PublishRetryOptions compute.PublishRetryOptions
+
+	// checkpointing executions, with the checkpoints kept in the storage path if no directory is set
+	CheckpointOptions compute.CheckpointOptions
+
+	// reusing published results for identical jobs, which is off if the TTL is zero
+	ResultCacheOptions compute.ResultCacheOptions
+
+	// removing the scratch space that executions left behind
+	ScratchGCOptions compute.ScratchGCOptions
+
 	SimulatorConfig model.SimulatorConfigCompute
 }
 
@@ -48,6 +178,21 @@ type ComputeConfig struct {
 	// How long the buffer would backoff before polling the queue again for new jobs
 	ExecutorBufferBackoffDuration time.Duration
 
+	// EngineConcurrency caps how many executions of each engine run at once, on top of the resources they use, as
+	// engines have very different overheads on the same hardware. Executions over the cap wait in the queue.
+	EngineConcurrency map[model.Engine]int
+
+	// LoadLimits are how many executions the node can have, and how loaded its host and disk can be, for it to bid
+	// on jobs. Unlike the resource limits, they account for other processes on the host and for what executions
+	// actually use.
+	LoadLimits compute_bidstrategies.LoadLimits
+
+	// BidProbability is the chance that the node bids on each job that it could run, or always if zero, so that in
+	// large networks every node doesn't race to bid on every job. BidProbabilityScaleByLoad lowers the chance as the
+	// node uses more of its capacity, so that busy nodes leave jobs to idle ones.
+	BidProbability            float64
+	BidProbabilityScaleByLoad bool
+
 	// JobNegotiationTimeout default timeout value to hold a bid for a job
 	JobNegotiationTimeout time.Duration
 	// MinJobExecutionTimeout default value for the minimum execution timeout this compute node supports. Jobs with
@@ -64,13 +209,167 @@ type ComputeConfig struct {
 	// check.
 	JobExecutionTimeoutClientIDBypassList []string
 
+	// Preemptible is whether the requester may stop executions on the node to make room for more urgent jobs. The
+	// jobs of the executions it stops are executed again later.
+	Preemptible bool
+
+	// Pricing is what the node charges per second for the resources that executions use, which it advertises to the
+	// requesters. The node doesn't bid on jobs whose max budget doesn't cover running them until their timeout.
+	Pricing model.Pricing
+
+	// GPUs are the model and memory of each GPU of the node, which it advertises to the requesters so that jobs
+	// that require GPUs of a model or with enough memory are only placed on nodes that have them.
+	GPUs []model.GPU
+
+	// Taints dedicate the node to the jobs that tolerate them, such as the jobs of a team. The node doesn't bid on
+	// jobs that don't tolerate its NoSchedule taints, and requesters prefer other nodes for jobs that don't
+	// tolerate its PreferNoSchedule taints.
+	Taints []model.Taint
+
+	// TEE is the kind of trusted execution environment, such as a SEV-SNP or TDX confidential VM, that the node runs
+	// in, which it advertises to the requesters. The node only bids on jobs that require attested execution if it runs
+	// in the TEE they require, and attaches evidence from the TEE's hardware to its bids on them, which it gets through
+	// the configfs-tsm reports in TEEReportDir, or compute.DefaultTSMReportDir if it is empty. TEECertificates is the
+	// PEM file of the certificates that the evidence of a SEV-SNP VM is verified with, if the kernel doesn't return
+	// them with the reports.
+	TEE             model.TEEType
+	TEEReportDir    string
+	TEECertificates string
+
+	// RequireClientSignatures is whether the node only bids on jobs that are signed by their clients. The node never
+	// bids on jobs whose signature is invalid, so its strategies can trust the ClientID of the jobs that are signed.
+	RequireClientSignatures bool
+
+	// Admins are the clients that can drain the node, which stops it bidding on new jobs and shuts it down once its
+	// executions finish, or override its bidding windows. The client of the node itself, which the CLI on the same
+	// host uses, is always an admin.
+	Admins []string
+
+	// BiddingWindows are the times of the week that the node bids on jobs, such as nights and weekends when a
+	// workstation is idle, in the time zone of BiddingWindowsLocation or the local one if it is nil. The node bids
+	// all the time if there are no windows. Admins can override the windows to open or close bidding for a while.
+	BiddingWindows         []compute.TimeWindow
+	BiddingWindowsLocation *time.Location
+
+	// Benchmark is whether the node measures how fast its CPU, disk and network are when it starts, and advertises
+	// the results for requesters to estimate how long jobs take to complete on the node. The network is only
+	// benchmarked if BenchmarkNetworkURL is set, by downloading it.
+	Benchmark           bool
+	BenchmarkNetworkURL string
+
 	// Bid strategies config
 	JobSelectionPolicy model.JobSelectionPolicy
 
+	// BidStrategyModules are the paths of the operator's WASM modules that decide whether the node bids on jobs,
+	// which let operators program their own policies without rebuilding the node. See wasm.BidStrategy.
+	BidStrategyModules []string
+
+	// BidStrategyChain lists the strategies that decide whether the node bids on jobs, in order, with their params
+	// and the phase of bidding that each is asked in. The node uses its default strategies, followed by its
+	// BidStrategyModules and its Moderation service, if it is nil.
+	BidStrategyChain *bidstrategy.ChainConfig
+
+	// Workloads are the images and WASM modules that the node allows or denies, so that operators can restrict the
+	// node to a catalog of workloads that they trust.
+	Workloads compute_bidstrategies.WorkloadStrategyParams
+
+	// InputPolicy is what the inputs of the jobs that the node runs can be: their storage sources, CIDs and URLs,
+	// and how large they can add up to. It is checked before any input is prepared.
+	InputPolicy storage_bidstrategy.InputPolicy
+
+	// Moderation is the operator's service that approves jobs before the node bids on them, such as by a person or a
+	// policy server. The node asks it again about jobs it hasn't decided on yet, until it decides or times out.
+	Moderation bidstrategy.ModerationStrategyParams
+
+	// ClientReputation is how the node keeps track of the clients whose jobs were cancelled while running, failed
+	// verification or used more resources than they declared, and how it delays and rejects bids on their jobs.
+	ClientReputation compute.ClientReputationsParams
+
 	// logging running executions
 	LogRunningExecutionsInterval time.Duration
 
+	// StorageHealthCheckInterval is how often the storages of the node are checked for whether they are available.
+	StorageHealthCheckInterval time.Duration
+
+	// DiskUsageCheckInterval is how often the scratch space that running executions use is measured. Executions
+	// that use more than the disk they declared are stopped, and how much they used is recorded in their state
+	// history. It is never measured if negative.
+	DiskUsageCheckInterval time.Duration
+
+	// DockerOptions restrict how docker jobs are run, e.g. in a user namespace or with specific security profiles.
+	DockerOptions docker.ExecutorOptions
+	// ContainerdOptions configure running docker jobs directly against containerd instead of the docker daemon,
+	// which is only done if an address is set.
+	ContainerdOptions containerd.ExecutorOptions
+	// KubernetesOptions configure running docker jobs as pods in a Kubernetes cluster instead of on the compute
+	// node, which is only done if enabled.
+	KubernetesOptions kubernetes.ExecutorOptions
+	// ApptainerOptions configure running apptainer jobs, which is done if apptainer is installed.
+	ApptainerOptions apptainer.ExecutorOptions
+	// ProcessOptions configure running jobs directly on the compute node, which is only done if enabled.
+	ProcessOptions process.ExecutorOptions
+	// PythonOptions configure the virtualenvs of python jobs, which run as processes so only if those are enabled.
+	PythonOptions python.ExecutorOptions
+	// PluginOptions configure running jobs with executor plugins, which is only done if a plugins directory is set.
+	PluginOptions plugin.ExecutorOptions
+	// SSHOptions configure running process jobs on a remote host instead of the compute node, which is only done if
+	// a host is set.
+	SSHOptions ssh.ExecutorOptions
+	// DuckDBOptions configure running SQL queries over the inputs of jobs, which is only done if enabled.
+	DuckDBOptions duckdb.ExecutorOptions
+	// S3Options configure where and as who inputs are downloaded from S3.
+	S3Options s3.StorageOptions
+	// URLDownloadOptions configure how URL inputs are retried, redirected and cached.
+	URLDownloadOptions urldownload.StorageOptions
+	// AzureBlobOptions configure as who inputs are downloaded from Azure Blob Storage, if they have no SAS token.
+	AzureBlobOptions azureblob.StorageOptions
+	// GCSOptions configure as who inputs are downloaded from Google Cloud Storage.
+	GCSOptions gcs.StorageOptions
+	// HuggingFaceOptions configure where models and datasets are downloaded from and kept.
+	HuggingFaceOptions huggingface.StorageOptions
+	// LocalDirectoryOptions configure which directories of the node jobs may mount.
+	LocalDirectoryOptions localdirectory.StorageOptions
+	// BitTorrentOptions configure how the node downloads and shares torrents.
+	BitTorrentOptions bittorrent.StorageOptions
+	// FilecoinOptions configure how inputs are retrieved from Filecoin deals.
+	FilecoinOptions filecoin.StorageOptions
+	// ExtractOptions limit what the archives of inputs are extracted to.
+	ExtractOptions extract.Options
+	// StorageCacheOptions configure how prepared volumes are kept for later
+	// executions that use the same data.
+	StorageCacheOptions cache.Options
+	// IPFSMountOptions configure which IPFS inputs are mounted lazily rather
+	// than fetched before executions start.
+	IPFSMountOptions mount.Options
+	// PrefetchOptions configure how much of the inputs of executions the node
+	// fetches as soon as it bids on them.
+	PrefetchOptions prefetch.Options
+	// PublishRetryOptions configure how publishing results is retried, and
+	// how long results are kept pending to be published before executions
+	// fail.
+	PublishRetryOptions compute.PublishRetryOptions
+	// CheckpointOptions configure how often the executions of executors that can checkpoint them are checkpointed,
+	// and where, for them to resume from their last checkpoint when they run again after the node restarts. Nodes
+	// that share the checkpoint directory resume each other's executions.
+	CheckpointOptions compute.CheckpointOptions
+	// ResultCacheOptions configure how long the results the node published are reused for identical jobs that are
+	// submitted again, rather than running them again.
+	ResultCacheOptions compute.ResultCacheOptions
+	// ScratchGCOptions configure how often the node removes the scratch space that executions left behind when the
+	// node crashed or their process was killed, and how long it must have gone unmodified first.
+	ScratchGCOptions compute.ScratchGCOptions
+
 	SimulatorConfig model.SimulatorConfigCompute
+
+	// hands over the inputs prefetched by the compute node to its executors,
+	// so is created once the node is
+	prefetcher *prefetch.Prefetcher
+	// records the inputs that the compute node holds locally, which it
+	// reports so that requesters prefer it for jobs that use them
+	localData *locality.Tracker
+	// keeps the volumes that the storages of the compute node prepare, which
+	// the node reports when introspected, so is created once the node is
+	storageCache *cache.Cache
 }
 
 func NewComputeConfigWithDefaults() ComputeConfig {
@@ -100,6 +399,12 @@ func NewComputeConfigWith(params ComputeConfigParams) (config ComputeConfig) {
 	if params.LogRunningExecutionsInterval == 0 {
 		params.LogRunningExecutionsInterval = DefaultComputeConfig.LogRunningExecutionsInterval
 	}
+	if params.StorageHealthCheckInterval == 0 {
+		params.StorageHealthCheckInterval = DefaultComputeConfig.StorageHealthCheckInterval
+	}
+	if params.DiskUsageCheckInterval == 0 {
+		params.DiskUsageCheckInterval = DefaultComputeConfig.DiskUsageCheckInterval
+	}
 	if params.ExecutorBufferBackoffDuration == 0 {
 		params.ExecutorBufferBackoffDuration = DefaultComputeConfig.ExecutorBufferBackoffDuration
 	}
@@ -113,6 +418,14 @@ func NewComputeConfigWith(params ComputeConfigParams) (config ComputeConfig) {
 	if err != nil {
 		return
 	}
+	if gpuProvider, ok := physicalResourcesProvider.(capacity.GPUProvider); ok && len(params.GPUs) == 0 {
+		var gpuErr error
+		params.GPUs, gpuErr = gpuProvider.GetGPUs(context.Background())
+		if gpuErr != nil {
+			// jobs that don't require GPUs of a model or with enough memory can still run
+			log.Warn().Err(gpuErr).Msg("Unable to describe the GPUs of the node")
+		}
+	}
 	// populate total resource limits with default values and physical resources if not set
 	totalResourceLimits := params.TotalResourceLimits.
 		Intersect(DefaultComputeConfig.TotalResourceLimits).
@@ -140,6 +453,10 @@ func NewComputeConfigWith(params ComputeConfigParams) (config ComputeConfig) {
 		DefaultJobResourceLimits:      defaultJobResourceLimits,
 		IgnorePhysicalResourceLimits:  params.IgnorePhysicalResourceLimits,
 		ExecutorBufferBackoffDuration: params.ExecutorBufferBackoffDuration,
+		EngineConcurrency:             params.EngineConcurrency,
+		LoadLimits:                    params.LoadLimits,
+		BidProbability:                params.BidProbability,
+		BidProbabilityScaleByLoad:     params.BidProbabilityScaleByLoad,
 
 		JobNegotiationTimeout:      params.JobNegotiationTimeout,
 		MinJobExecutionTimeout:     params.MinJobExecutionTimeout,
@@ -147,10 +464,56 @@ func NewComputeConfigWith(params ComputeConfigParams) (config ComputeConfig) {
 		DefaultJobExecutionTimeout: params.DefaultJobExecutionTimeout,
 
 		JobExecutionTimeoutClientIDBypassList: params.JobExecutionTimeoutClientIDBypassList,
+		Preemptible:                           params.Preemptible,
+		Pricing:                               params.Pricing,
+		GPUs:                                  params.GPUs,
+		Taints:                                params.Taints,
+		TEE:                                   params.TEE,
+		TEEReportDir:                          params.TEEReportDir,
+		TEECertificates:                       params.TEECertificates,
+		RequireClientSignatures:               params.RequireClientSignatures,
+		Admins:                                params.Admins,
+		BiddingWindows:                        params.BiddingWindows,
+		BiddingWindowsLocation:                params.BiddingWindowsLocation,
+		Benchmark:                             params.Benchmark,
+		BenchmarkNetworkURL:                   params.BenchmarkNetworkURL,
 
 		JobSelectionPolicy: params.JobSelectionPolicy,
+		BidStrategyModules: params.BidStrategyModules,
+		BidStrategyChain:   params.BidStrategyChain,
+		Workloads:          params.Workloads,
+		InputPolicy:        params.InputPolicy,
+		Moderation:         params.Moderation,
+		ClientReputation:   params.ClientReputation,
 
 		LogRunningExecutionsInterval: params.LogRunningExecutionsInterval,
+		StorageHealthCheckInterval:   params.StorageHealthCheckInterval,
+		DiskUsageCheckInterval:       params.DiskUsageCheckInterval,
+		DockerOptions:                params.DockerOptions,
+		ContainerdOptions:            params.ContainerdOptions,
+		KubernetesOptions:            params.KubernetesOptions,
+		ApptainerOptions:             params.ApptainerOptions,
+		ProcessOptions:               params.ProcessOptions,
+		PythonOptions:                params.PythonOptions,
+		PluginOptions:                params.PluginOptions,
+		SSHOptions:                   params.SSHOptions,
+		DuckDBOptions:                params.DuckDBOptions,
+		S3Options:                    params.S3Options,
+		URLDownloadOptions:           params.URLDownloadOptions,
+		AzureBlobOptions:             params.AzureBlobOptions,
+		GCSOptions:                   params.GCSOptions,
+		HuggingFaceOptions:           params.HuggingFaceOptions,
+		LocalDirectoryOptions:        params.LocalDirectoryOptions,
+		BitTorrentOptions:            params.BitTorrentOptions,
+		FilecoinOptions:              params.FilecoinOptions,
+		ExtractOptions:               params.ExtractOptions,
+		StorageCacheOptions:          params.StorageCacheOptions,
+		IPFSMountOptions:             params.IPFSMountOptions,
+		PrefetchOptions:              params.PrefetchOptions,
+		PublishRetryOptions:          params.PublishRetryOptions,
+		CheckpointOptions:            params.CheckpointOptions,
+		ResultCacheOptions:           params.ResultCacheOptions,
+		ScratchGCOptions:             params.ScratchGCOptions,
 		SimulatorConfig:              params.SimulatorConfig,
 	}
 
@@ -3,8 +3,11 @@ package node
 import (
 	"time"
 
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity/system"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester/discovery"
+	"github.com/bacalhau-project/bacalhau/pkg/requester/ranking"
 )
 
 var DefaultComputeConfig = ComputeConfigParams{
@@ -21,6 +24,8 @@ var DefaultComputeConfig = ComputeConfigParams{
 	DefaultJobExecutionTimeout: 10 * time.Minute,
 
 	LogRunningExecutionsInterval: 10 * time.Second,
+	StorageHealthCheckInterval:   time.Minute,
+	DiskUsageCheckInterval:       compute.DefaultDiskUsageCheckInterval,
 }
 
 var DefaultRequesterConfig = RequesterConfigParams{
@@ -30,6 +35,14 @@ var DefaultRequesterConfig = RequesterConfigParams{
 	HousekeepingBackgroundTaskInterval: 30 * time.Second,
 	NodeRankRandomnessRange:            10,
 
+	LostNodeReschedules: 3,
+	LostNodeGracePeriod: time.Minute,
+
+	ReputationHalfLife:    24 * time.Hour,
+	ExternalRankerTimeout: ranking.DefaultExternalRankerTimeout,
+
+	DNSDiscoveryRefreshInterval: discovery.DefaultDNSRefreshInterval,
+
 	MinBacalhauVersion: model.BuildVersionInfo{
 		Major: "0", Minor: "3", GitVersion: "v0.3.20",
 	},
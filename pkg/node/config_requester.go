@@ -2,9 +2,11 @@ package node
 
 import (
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
 )
 
 type RequesterConfigParams struct {
@@ -19,6 +21,62 @@ type RequesterConfigParams struct {
 
 	// minimum version of compute nodes that the requester will accept and route jobs to
 	MinBacalhauVersion model.BuildVersionInfo
+
+	// networks that the webhooks of jobs can be on, on top of public addresses
+	WebhookAllowedNetworks []*net.IPNet
+
+	// most high priority jobs that each client can have in flight, or zero for no limit
+	MaxHighPriorityJobsPerClient int
+
+	// whether the requester only accepts jobs that are signed by their clients
+	RequireClientSignatures bool
+
+	// when high priority jobs may preempt executions of less urgent jobs
+	Preemption requester.PreemptionPolicy
+
+	// quota of clients that don't have their own, and who can set their own
+	DefaultQuota model.ClientQuota
+	QuotaAdmins  []string
+
+	// the only compute nodes that executions are scheduled on if any, the nodes they aren't scheduled on, and who
+	// can change which nodes at runtime
+	NodeAllowlist    []string
+	NodeDenylist     []string
+	NodeAccessAdmins []string
+
+	// how many times executions are rescheduled when their compute node is lost, or negative to never reschedule them
+	LostNodeReschedules int
+	// how long a compute node can stay disconnected before it is considered lost
+	LostNodeGracePeriod time.Duration
+
+	// whether executions are spread over nodes or bin-packed onto few of them
+	Placement requester.PlacementStrategy
+
+	// when executions that run far slower than the others of their job are duplicated
+	Speculation requester.SpeculationPolicy
+
+	// which jobs start in leftover capacity while jobs queued before them wait
+	Backfill requester.BackfillPolicy
+
+	// which jobs prefer the compute nodes with the shortest round trip time from the requester
+	Latency requester.LatencyPolicy
+
+	// how long it takes for the outcomes of executions to count half as much in the reputations of their nodes
+	ReputationHalfLife time.Duration
+
+	// where an external service ranks the compute nodes for each job, if anywhere, and how long it is waited for
+	ExternalRankerURL     string
+	ExternalRankerTimeout time.Duration
+
+	// the file that lists the compute nodes to find, instead of finding them from the network, if any
+	NodeCatalogPath string
+
+	// the DNS name that compute nodes are also found from, if any, and how often it is resolved again
+	DNSDiscoveryName            string
+	DNSDiscoveryRefreshInterval time.Duration
+
+	// the PEM file of the vendor root certificates that the evidence of TEEs must chain to, if any
+	TEETrustRoots string
 }
 
 type RequesterConfig struct {
@@ -38,6 +96,105 @@ type RequesterConfig struct {
 
 	// minimum version of compute nodes that the requester will accept and route jobs to
 	MinBacalhauVersion model.BuildVersionInfo
+
+	// WebhookAllowedNetworks are the networks that the webhooks of jobs can be
+	// on, on top of public addresses. Notifications are signed with the key of
+	// the node.
+	WebhookAllowedNetworks []*net.IPNet
+
+	// MaxHighPriorityJobsPerClient is the most high priority jobs that each
+	// client can have in flight, so that no client can take all of the scarce
+	// capacity for itself. There is no limit if it is zero.
+	MaxHighPriorityJobsPerClient int
+
+	// RequireClientSignatures is whether the requester only accepts jobs that
+	// are signed by their clients, whose signatures it keeps on the jobs so
+	// that compute nodes can verify who submitted them. Runs of schedules and
+	// stages of workflows aren't signed, so they are rejected if it is set.
+	RequireClientSignatures bool
+
+	// Preemption governs when high priority jobs that no node has the capacity
+	// for may stop executions of less urgent jobs, which are executed again
+	// later.
+	Preemption requester.PreemptionPolicy
+
+	// DefaultQuota limits what clients that don't have their own quota can
+	// submit and run at once.
+	DefaultQuota model.ClientQuota
+	// QuotaAdmins are the clients that can set the quotas of other clients.
+	QuotaAdmins []string
+
+	// NodeAllowlist are the IDs of the only compute nodes that executions are
+	// scheduled on, unless it is empty.
+	NodeAllowlist []string
+	// NodeDenylist are the IDs of the compute nodes that executions aren't
+	// scheduled on, such as nodes that misbehave, which stay part of the
+	// network.
+	NodeDenylist []string
+	// NodeAccessAdmins are the clients that can allow and deny nodes at
+	// runtime, which replaces what the lists say about the nodes.
+	NodeAccessAdmins []string
+
+	// LostNodeReschedules is how many times each execution of a job is
+	// rescheduled on another node when its compute node is lost, on top of the
+	// retries that the retry policy of the job allows.
+	LostNodeReschedules int
+	// LostNodeGracePeriod is how long a compute node can stay disconnected
+	// before it is considered lost and its executions are rescheduled.
+	LostNodeGracePeriod time.Duration
+
+	// Placement is whether executions are spread over the compute nodes for
+	// fault tolerance, or bin-packed onto as few of them as possible so that
+	// the others stay idle. Executions are placed at random if it is empty.
+	Placement requester.PlacementStrategy
+
+	// Speculation governs when executions that run far slower than the
+	// executions of their job that already finished are duplicated on other
+	// nodes, with the job taking whichever finishes first.
+	Speculation requester.SpeculationPolicy
+
+	// Backfill governs which small, short jobs start in the capacity left over
+	// while jobs that were queued before them wait for enough capacity.
+	Backfill requester.BackfillPolicy
+
+	// Latency governs which jobs prefer the compute nodes with the shortest
+	// network round trip time from the requester, such as short interactive
+	// jobs. Nodes are never preferred for being near if it is empty.
+	Latency requester.LatencyPolicy
+
+	// ReputationHalfLife is how long it takes for the outcomes of executions
+	// to count half as much in the reputations of their compute nodes, which
+	// nodes are ranked by. Flaky nodes recover their reputation over time.
+	ReputationHalfLife time.Duration
+
+	// ExternalRankerURL is where the job and its candidate compute nodes are
+	// posted for an external service to rank the nodes, which lets operators
+	// add their own placement policy. The ranks it gives add to the ranks of
+	// the other rankers. There is no external ranker if it is empty.
+	ExternalRankerURL string
+	// ExternalRankerTimeout is how long the external ranker is waited for
+	// before the other rankers decide alone.
+	ExternalRankerTimeout time.Duration
+
+	// NodeCatalogPath is the YAML or JSON file that lists the compute nodes
+	// that jobs are placed on, for private clusters that don't discover their
+	// nodes from the network. It is reloaded when it changes. The nodes are
+	// discovered from the network if it is empty.
+	NodeCatalogPath string
+
+	// DNSDiscoveryName is the DNS name whose TXT and SRV records list more
+	// compute nodes to place jobs on, for clusters that run behind standard
+	// service discovery. No nodes are found from DNS if it is empty.
+	DNSDiscoveryName string
+	// DNSDiscoveryRefreshInterval is how long the nodes resolved from the DNS
+	// name are kept before it is resolved again.
+	DNSDiscoveryRefreshInterval time.Duration
+
+	// TEETrustRoots is the PEM file of the root certificates of the hardware
+	// vendors, such as AMD's ARKs and Intel's SGX root CA, that the evidence
+	// of the TEEs of compute nodes must chain to. Bids on jobs that require a
+	// TEE are rejected if it is empty.
+	TEETrustRoots string
 }
 
 func NewRequesterConfigWithDefaults() RequesterConfig {
@@ -65,6 +222,15 @@ func NewRequesterConfigWith(params RequesterConfigParams) (config RequesterConfi
 	if params.NodeRankRandomnessRange == 0 {
 		params.NodeRankRandomnessRange = DefaultRequesterConfig.NodeRankRandomnessRange
 	}
+	if params.LostNodeReschedules == 0 {
+		params.LostNodeReschedules = DefaultRequesterConfig.LostNodeReschedules
+	}
+	if params.LostNodeGracePeriod == 0 {
+		params.LostNodeGracePeriod = DefaultRequesterConfig.LostNodeGracePeriod
+	}
+	if params.ReputationHalfLife == 0 {
+		params.ReputationHalfLife = DefaultRequesterConfig.ReputationHalfLife
+	}
 	if params.MinBacalhauVersion == (model.BuildVersionInfo{}) {
 		params.MinBacalhauVersion = DefaultRequesterConfig.MinBacalhauVersion
 	}
@@ -77,6 +243,28 @@ func NewRequesterConfigWith(params RequesterConfigParams) (config RequesterConfi
 		NodeRankRandomnessRange:            params.NodeRankRandomnessRange,
 		SimulatorConfig:                    params.SimulatorConfig,
 		MinBacalhauVersion:                 params.MinBacalhauVersion,
+		WebhookAllowedNetworks:             params.WebhookAllowedNetworks,
+		MaxHighPriorityJobsPerClient:       params.MaxHighPriorityJobsPerClient,
+		RequireClientSignatures:            params.RequireClientSignatures,
+		Preemption:                         params.Preemption,
+		DefaultQuota:                       params.DefaultQuota,
+		QuotaAdmins:                        params.QuotaAdmins,
+		NodeAllowlist:                      params.NodeAllowlist,
+		NodeDenylist:                       params.NodeDenylist,
+		NodeAccessAdmins:                   params.NodeAccessAdmins,
+		LostNodeReschedules:                params.LostNodeReschedules,
+		LostNodeGracePeriod:                params.LostNodeGracePeriod,
+		Placement:                          params.Placement,
+		Speculation:                        params.Speculation,
+		Backfill:                           params.Backfill,
+		Latency:                            params.Latency,
+		ReputationHalfLife:                 params.ReputationHalfLife,
+		ExternalRankerURL:                  params.ExternalRankerURL,
+		ExternalRankerTimeout:              params.ExternalRankerTimeout,
+		NodeCatalogPath:                    params.NodeCatalogPath,
+		DNSDiscoveryName:                   params.DNSDiscoveryName,
+		DNSDiscoveryRefreshInterval:        params.DNSDiscoveryRefreshInterval,
+		TEETrustRoots:                      params.TEETrustRoots,
 	}
 
 	return config
@@ -79,6 +79,19 @@ func (f *StandardStorageProvidersFactory) Get(
 		executor_util.StandardStorageProviderOptions{
 			API:                  nodeConfig.IPFSClient,
 			FilecoinUnsealedPath: nodeConfig.FilecoinUnsealedPath,
+			S3:                   nodeConfig.ComputeConfig.S3Options,
+			URLDownload:          nodeConfig.ComputeConfig.URLDownloadOptions,
+			AzureBlob:            nodeConfig.ComputeConfig.AzureBlobOptions,
+			GCS:                  nodeConfig.ComputeConfig.GCSOptions,
+			HuggingFace:          nodeConfig.ComputeConfig.HuggingFaceOptions,
+			LocalDirectory:       nodeConfig.ComputeConfig.LocalDirectoryOptions,
+			BitTorrent:           nodeConfig.ComputeConfig.BitTorrentOptions,
+			Filecoin:             nodeConfig.ComputeConfig.FilecoinOptions,
+			Extract:              nodeConfig.ComputeConfig.ExtractOptions,
+			Cache:                nodeConfig.ComputeConfig.StorageCacheOptions,
+			StorageCache:         nodeConfig.ComputeConfig.storageCache,
+			PinningServices:      nodeConfig.IPFSPinningServices,
+			IPFSMount:            nodeConfig.ComputeConfig.IPFSMountOptions,
 		},
 	)
 }
@@ -96,10 +109,34 @@ func (f *StandardExecutorsFactory) Get(
 		ctx,
 		nodeConfig.CleanupManager,
 		executor_util.StandardExecutorOptions{
-			DockerID: fmt.Sprintf("bacalhau-%s", nodeConfig.Host.ID().String()),
+			DockerID:   fmt.Sprintf("bacalhau-%s", nodeConfig.Host.ID().String()),
+			Docker:     nodeConfig.ComputeConfig.DockerOptions,
+			Containerd: nodeConfig.ComputeConfig.ContainerdOptions,
+			Kubernetes: nodeConfig.ComputeConfig.KubernetesOptions,
+			Apptainer:  nodeConfig.ComputeConfig.ApptainerOptions,
+			Process:    nodeConfig.ComputeConfig.ProcessOptions,
+			Python:     nodeConfig.ComputeConfig.PythonOptions,
+			Plugin:     nodeConfig.ComputeConfig.PluginOptions,
+			SSH:        nodeConfig.ComputeConfig.SSHOptions,
+			DuckDB:     nodeConfig.ComputeConfig.DuckDBOptions,
+			Prefetcher: nodeConfig.ComputeConfig.prefetcher,
+			LocalData:  nodeConfig.ComputeConfig.localData,
 			Storage: executor_util.StandardStorageProviderOptions{
 				API:                  nodeConfig.IPFSClient,
 				FilecoinUnsealedPath: nodeConfig.FilecoinUnsealedPath,
+				S3:                   nodeConfig.ComputeConfig.S3Options,
+				URLDownload:          nodeConfig.ComputeConfig.URLDownloadOptions,
+				AzureBlob:            nodeConfig.ComputeConfig.AzureBlobOptions,
+				GCS:                  nodeConfig.ComputeConfig.GCSOptions,
+				HuggingFace:          nodeConfig.ComputeConfig.HuggingFaceOptions,
+				LocalDirectory:       nodeConfig.ComputeConfig.LocalDirectoryOptions,
+				BitTorrent:           nodeConfig.ComputeConfig.BitTorrentOptions,
+				Filecoin:             nodeConfig.ComputeConfig.FilecoinOptions,
+				Extract:              nodeConfig.ComputeConfig.ExtractOptions,
+				Cache:                nodeConfig.ComputeConfig.StorageCacheOptions,
+				StorageCache:         nodeConfig.ComputeConfig.storageCache,
+				PinningServices:      nodeConfig.IPFSPinningServices,
+				IPFSMount:            nodeConfig.ComputeConfig.IPFSMountOptions,
 			},
 		},
 	)
@@ -138,6 +175,8 @@ func (f *StandardPublishersFactory) Get(
 		nodeConfig.IPFSClient,
 		nodeConfig.EstuaryAPIKey,
 		nodeConfig.LotusConfig,
+		nodeConfig.LocalPublisherConfig,
+		nodeConfig.IPFSPinningServices,
 	)
 }
 
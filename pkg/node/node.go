@@ -7,15 +7,20 @@ import (
 
 	"github.com/bacalhau-project/bacalhau/pkg/config"
 	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs/pinning"
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
 	filecoinlotus "github.com/bacalhau-project/bacalhau/pkg/publisher/filecoin_lotus"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/local"
 	"github.com/bacalhau-project/bacalhau/pkg/pubsub"
 	"github.com/bacalhau-project/bacalhau/pkg/pubsub/libp2p"
 	"github.com/bacalhau-project/bacalhau/pkg/routing"
 	"github.com/bacalhau-project/bacalhau/pkg/routing/inmemory"
 	"github.com/bacalhau-project/bacalhau/pkg/simulator"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/cache"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/locality"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/prefetch"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	"github.com/bacalhau-project/bacalhau/pkg/version"
 	"github.com/imdario/mergo"
@@ -39,12 +44,14 @@ type NodeConfig struct {
 	Host                      host.Host
 	FilecoinUnsealedPath      string
 	EstuaryAPIKey             string
+	IPFSPinningServices       []pinning.Service
 	HostAddress               string
 	APIPort                   int
 	ComputeConfig             ComputeConfig
 	RequesterNodeConfig       RequesterConfig
 	APIServerConfig           publicapi.APIServerConfig
 	LotusConfig               *filecoinlotus.PublisherConfig
+	LocalPublisherConfig      *local.PublisherConfig
 	SimulatorNodeID           string
 	IsRequesterNode           bool
 	IsComputeNode             bool
@@ -106,6 +113,15 @@ func NewNode(
 		return nil, err
 	}
 
+	if config.IsComputeNode {
+		config.ComputeConfig.prefetcher = prefetch.New(config.ComputeConfig.PrefetchOptions)
+		config.ComputeConfig.localData = locality.New(locality.DefaultMaxCIDs)
+		config.ComputeConfig.storageCache, err = cache.New(config.CleanupManager, config.ComputeConfig.StorageCacheOptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	storageProviders, err := injector.StorageProvidersFactory.Get(ctx, config)
 	if err != nil {
 		return nil, err
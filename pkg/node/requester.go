@@ -2,8 +2,10 @@ package node
 
 import (
 	"context"
+	"crypto/x509"
 	"time"
 
+	"github.com/bacalhau-project/bacalhau/pkg/attestation"
 	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
 	"github.com/bacalhau-project/bacalhau/pkg/compute"
 	"github.com/bacalhau-project/bacalhau/pkg/eventhandler"
@@ -83,29 +85,102 @@ func NewRequesterNode(
 
 	// compute node discoverer
 	nodeDiscoveryChain := discovery.NewChain(true)
-	nodeDiscoveryChain.Add(
-		discovery.NewStoreNodeDiscoverer(discovery.StoreNodeDiscovererParams{
+	if config.NodeCatalogPath != "" {
+		// private clusters only place jobs on the nodes in their catalog
+		staticDiscoverer, err := discovery.NewStaticNodeDiscoverer(discovery.StaticNodeDiscovererParams{
+			Path:  config.NodeCatalogPath,
+			Host:  host,
 			Store: nodeInfoStore,
-		}),
-		discovery.NewIdentityNodeDiscoverer(discovery.IdentityNodeDiscovererParams{
-			Host: host,
-		}),
-	)
+		})
+		if err != nil {
+			return nil, err
+		}
+		nodeDiscoveryChain.Add(staticDiscoverer)
+	} else {
+		nodeDiscoveryChain.Add(
+			discovery.NewStoreNodeDiscoverer(discovery.StoreNodeDiscovererParams{
+				Store: nodeInfoStore,
+			}),
+			discovery.NewIdentityNodeDiscoverer(discovery.IdentityNodeDiscovererParams{
+				Host: host,
+			}),
+		)
+	}
+	if config.DNSDiscoveryName != "" {
+		nodeDiscoveryChain.Add(discovery.NewDNSNodeDiscoverer(discovery.DNSNodeDiscovererParams{
+			Name:            config.DNSDiscoveryName,
+			RefreshInterval: config.DNSDiscoveryRefreshInterval,
+			Host:            host,
+			Store:           nodeInfoStore,
+		}))
+	}
+
+	// record how reliably compute nodes run executions if the job store can keep their reputations
+	reputations, _ := jobStore.(jobstore.ReputationStore)
+
+	// allow and deny scheduling executions on compute nodes, at runtime if the job store can keep the rules
+	nodeAccessStore, _ := jobStore.(jobstore.NodeAccessStore)
+	nodeAccess := requester.NewNodeAccessManager(requester.NodeAccessManagerParams{
+		Store:     nodeAccessStore,
+		Allowlist: config.NodeAllowlist,
+		Denylist:  config.NodeDenylist,
+		Admins:    config.NodeAccessAdmins,
+	})
 
 	// compute node ranker
 	nodeRankerChain := ranking.NewChain()
 	nodeRankerChain.Add(
 		// rankers that act as filters and give a -1 score to nodes that do not match the filter
 		ranking.NewEnginesNodeRanker(),
+		ranking.NewNodeAccessNodeRanker(ranking.NodeAccessNodeRankerParams{Access: nodeAccess}),
 		ranking.NewLabelsNodeRanker(),
+		ranking.NewAntiAffinityNodeRanker(ranking.AntiAffinityNodeRankerParams{JobStore: jobStore}),
 		ranking.NewMaxUsageNodeRanker(),
+		ranking.NewDiskSpaceNodeRanker(),
+		ranking.NewGPUNodeRanker(),
+		ranking.NewTaintsNodeRanker(),
+		ranking.NewTEENodeRanker(),
 		ranking.NewMinVersionNodeRanker(ranking.MinVersionNodeRankerParams{MinVersion: config.MinBacalhauVersion}),
 
+		// rankers that prefer cheaper nodes, and filter out the nodes that the job can't afford
+		ranking.NewPriceNodeRanker(),
+		// rankers that prefer nodes that already hold the inputs of the job
+		ranking.NewLocalityNodeRanker(),
+		// rankers that prefer nodes that are estimated to complete the job sooner from their benchmarks
+		ranking.NewCompletionTimeNodeRanker(),
+		// rankers that place executions according to the placement strategy
+		ranking.NewPlacementNodeRanker(ranking.PlacementNodeRankerParams{Strategy: config.Placement}),
+
 		// arbitrary rankers
 		ranking.NewRandomNodeRanker(ranking.RandomNodeRankerParams{
 			RandomnessRange: config.NodeRankRandomnessRange,
 		}),
 	)
+	if config.Latency.Enabled() {
+		// rankers that prefer nodes near the requester for latency sensitive jobs
+		nodeRankerChain.Add(ranking.NewLatencyNodeRanker(ranking.LatencyNodeRankerParams{Host: host, Policy: config.Latency}))
+	}
+	if config.ExternalRankerURL != "" {
+		// rankers that apply the placement policy of the operator
+		nodeRankerChain.Add(ranking.NewExternalNodeRanker(ranking.ExternalNodeRankerParams{
+			URL:     config.ExternalRankerURL,
+			Timeout: config.ExternalRankerTimeout,
+		}))
+	}
+	if reputations != nil {
+		// rankers that prefer nodes that have run their executions reliably
+		nodeRankerChain.Add(ranking.NewReputationNodeRanker(ranking.ReputationNodeRankerParams{Store: reputations}))
+	}
+
+	// verify the evidence of the TEEs of compute nodes against the roots of their vendors
+	var teeRoots *x509.CertPool
+	if config.TEETrustRoots != "" {
+		var err error
+		teeRoots, err = attestation.LoadTrustRoots(config.TEETrustRoots)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	scheduler := requester.NewScheduler(requester.SchedulerParams{
 		ID:               host.ID().String(),
@@ -119,6 +194,14 @@ func NewRequesterNode(
 		EventEmitter: requester.NewEventEmitter(requester.EventEmitterParams{
 			EventConsumer: localJobEventConsumer,
 		}),
+		WebhookAllowedNetworks: config.WebhookAllowedNetworks,
+		Preemption:             config.Preemption,
+		LostNodeReschedules:    config.LostNodeReschedules,
+		Placement:              config.Placement,
+		Speculation:            config.Speculation,
+		Reputations:            reputations,
+		ReputationHalfLife:     config.ReputationHalfLife,
+		Attestations:           attestation.NewVerifier(attestation.VerifierParams{Roots: teeRoots}),
 	})
 
 	publicKey := host.Peerstore().PubKey(host.ID())
@@ -129,25 +212,84 @@ func NewRequesterNode(
 
 	selectionStrategy := bidstrategy.FromJobSelectionPolicy(config.JobSelectionPolicy)
 
+	// limit what each client can submit and run if the job store can keep their quotas
+	var quotas requester.Quotas
+	var quotaManager *requester.QuotaManager
+	if quotaStore, ok := jobStore.(jobstore.QuotaStore); ok {
+		quotaManager = requester.NewQuotaManager(requester.QuotaManagerParams{
+			Store:    quotaStore,
+			JobStore: jobStore,
+			Default:  config.DefaultQuota,
+			Admins:   config.QuotaAdmins,
+		})
+		quotas = quotaManager
+	}
+
 	endpoint := requester.NewBaseEndpoint(&requester.BaseEndpointParams{
-		ID:                         host.ID().String(),
-		PublicKey:                  marshaledPublicKey,
-		Selector:                   selectionStrategy,
-		Store:                      jobStore,
-		Scheduler:                  scheduler,
-		Verifiers:                  verifiers,
-		StorageProviders:           storageProviders,
-		MinJobExecutionTimeout:     config.MinJobExecutionTimeout,
-		DefaultJobExecutionTimeout: config.DefaultJobExecutionTimeout,
+		ID:                           host.ID().String(),
+		PublicKey:                    marshaledPublicKey,
+		Selector:                     selectionStrategy,
+		Store:                        jobStore,
+		Scheduler:                    scheduler,
+		Verifiers:                    verifiers,
+		StorageProviders:             storageProviders,
+		MinJobExecutionTimeout:       config.MinJobExecutionTimeout,
+		DefaultJobExecutionTimeout:   config.DefaultJobExecutionTimeout,
+		MaxHighPriorityJobsPerClient: config.MaxHighPriorityJobsPerClient,
+		RequireClientSignatures:      config.RequireClientSignatures,
+		Quotas:                       quotaManager,
+		Backfill:                     config.Backfill,
 	})
 
 	housekeeping := requester.NewHousekeeping(requester.HousekeepingParams{
-		Endpoint: endpoint,
-		JobStore: jobStore,
-		NodeID:   host.ID().String(),
-		Interval: config.HousekeepingBackgroundTaskInterval,
+		Endpoint:         endpoint,
+		JobStore:         jobStore,
+		ExecutionMonitor: scheduler,
+		NodeID:           host.ID().String(),
+		Interval:         config.HousekeepingBackgroundTaskInterval,
+	})
+
+	// reschedule the executions of compute nodes that disconnect without waiting for their node info to expire
+	nodeMonitor := requester.NewNodeMonitor(requester.NodeMonitorParams{
+		Host:             host,
+		NodeInfoStore:    nodeInfoStore,
+		JobStore:         jobStore,
+		ExecutionMonitor: scheduler,
+		NodeID:           host.ID().String(),
+		GracePeriod:      config.LostNodeGracePeriod,
 	})
 
+	// submit the jobs of schedules if the job store can keep them
+	var schedules requester.Schedules
+	var cron *requester.Cron
+	if scheduleStore, ok := jobStore.(jobstore.ScheduleStore); ok {
+		cron = requester.NewCron(requester.CronParams{
+			Endpoint: endpoint,
+			Store:    scheduleStore,
+		})
+		schedules = cron
+	}
+
+	// run the stages of workflows if the job store can keep them
+	var workflows requester.Workflows
+	var workflowRunner *requester.WorkflowRunner
+	if workflowStore, ok := jobStore.(jobstore.WorkflowStore); ok {
+		workflowRunner = requester.NewWorkflowRunner(requester.WorkflowRunnerParams{
+			Endpoint: endpoint,
+			JobStore: jobStore,
+			Store:    workflowStore,
+		})
+		workflows = workflowRunner
+	}
+
+	// host job templates if the job store can keep them
+	var templates requester.Templates
+	if templateStore, ok := jobStore.(jobstore.TemplateStore); ok {
+		templates = requester.NewTemplateManager(requester.TemplateManagerParams{
+			Store: templateStore,
+		})
+	}
+
 	// if this node is the simulator, then we pass incoming requests to the simulator before passing them to the endpoint
 	if simulatorRequestHandler != nil {
 		bprotocol.NewCallbackHandler(bprotocol.CallbackHandlerParams{
@@ -169,9 +311,17 @@ func NewRequesterNode(
 	requesterAPIServer := requester_publicapi.NewRequesterAPIServer(requester_publicapi.RequesterAPIServerParams{
 		APIServer:          apiServer,
 		Requester:          endpoint,
+		Schedules:          schedules,
+		Workflows:          workflows,
+		Quotas:             quotas,
+		NodeAccess:         nodeAccess,
+		Queue:              endpoint,
+		Templates:          templates,
+		Placements:         scheduler,
 		DebugInfoProviders: debugInfoProviders,
 		JobStore:           jobStore,
 		StorageProviders:   storageProviders,
+		LogStreamer:        standardComputeProxy,
 	})
 	err = requesterAPIServer.RegisterAllHandlers()
 	if err != nil {
@@ -220,6 +370,13 @@ func NewRequesterNode(
 	cleanupFunc := func(ctx context.Context) {
 		// stop the housekeeping background task
 		housekeeping.Stop()
+		nodeMonitor.Stop()
+		if cron != nil {
+			cron.Stop()
+		}
+		if workflowRunner != nil {
+			workflowRunner.Stop()
+		}
 
 		cleanupErr := bufferedJobEventPubSub.Close(ctx)
 		if cleanupErr != nil {
@@ -0,0 +1,67 @@
+package publicapi
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// maxClockSkew is how far ahead of this node's clock a client's clock may be
+// when it sets when an admin request expires.
+const maxClockSkew = time.Minute
+
+// adminRequests accepts each admin request for a node once, before it expires.
+type adminRequests struct {
+	nodeID string
+	mu     sync.Mutex
+	// nonces are the nonces of the requests that were accepted, with when the
+	// requests expire
+	nonces map[string]time.Time
+}
+
+func newAdminRequests(nodeID string) *adminRequests {
+	return &adminRequests{
+		nodeID: nodeID,
+		nonces: make(map[string]time.Time),
+	}
+}
+
+// accept returns an error if the request isn't for this node, has expired,
+// or was accepted before.
+func (a *adminRequests) accept(request model.AdminRequest, now time.Time) error {
+	if request.TargetNodeID != a.nodeID {
+		return fmt.Errorf("the request is for node %q rather than node %s", request.TargetNodeID, a.nodeID)
+	}
+	if request.Nonce == "" {
+		return errors.New("the request has no nonce")
+	}
+	if !now.Before(request.ExpiresAt) {
+		return fmt.Errorf("the request expired at %s", request.ExpiresAt)
+	}
+	if request.ExpiresAt.After(now.Add(model.AdminRequestTTL + maxClockSkew)) {
+		return fmt.Errorf("the request expires at %s, which is more than %s from now", request.ExpiresAt, model.AdminRequestTTL)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for nonce, expiresAt := range a.nonces {
+		if !now.Before(expiresAt) {
+			delete(a.nonces, nonce)
+		}
+	}
+	if _, ok := a.nonces[request.Nonce]; ok {
+		return errors.New("the request has already been made")
+	}
+	a.nonces[request.Nonce] = request.ExpiresAt
+	return nil
+}
+
+// AcceptAdminRequest returns an error if an admin request isn't for this node,
+// has expired, or was accepted before, so that a captured request can't be
+// replayed.
+func (apiServer *APIServer) AcceptAdminRequest(request model.AdminRequest) error {
+	return apiServer.adminRequests.accept(request, time.Now())
+}
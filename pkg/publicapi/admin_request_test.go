@@ -0,0 +1,57 @@
+//go:build unit || !integration
+
+package publicapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminRequestsAcceptsEachRequestOnce(t *testing.T) {
+	requests := newAdminRequests("node")
+	request, err := model.NewAdminRequest("node")
+	require.NoError(t, err)
+	now := time.Now()
+
+	require.NoError(t, requests.accept(request, now))
+	require.Error(t, requests.accept(request, now), "a replayed request is accepted")
+
+	other, err := model.NewAdminRequest("node")
+	require.NoError(t, err)
+	require.NoError(t, requests.accept(other, now))
+}
+
+func TestAdminRequestsRejectsRequestsForOtherNodesOrTimes(t *testing.T) {
+	requests := newAdminRequests("node")
+	now := time.Now()
+
+	for name, request := range map[string]model.AdminRequest{
+		"other node":   {TargetNodeID: "other", ExpiresAt: now.Add(time.Minute), Nonce: "a"},
+		"no node":      {ExpiresAt: now.Add(time.Minute), Nonce: "b"},
+		"no nonce":     {TargetNodeID: "node", ExpiresAt: now.Add(time.Minute)},
+		"expired":      {TargetNodeID: "node", ExpiresAt: now.Add(-time.Second), Nonce: "c"},
+		"no expiry":    {TargetNodeID: "node", Nonce: "d"},
+		"expires late": {TargetNodeID: "node", ExpiresAt: now.Add(time.Hour), Nonce: "e"},
+	} {
+		request := request
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, requests.accept(request, now))
+		})
+	}
+}
+
+func TestAdminRequestsForgetsExpiredNonces(t *testing.T) {
+	requests := newAdminRequests("node")
+	now := time.Now()
+	request := model.AdminRequest{TargetNodeID: "node", ExpiresAt: now.Add(time.Minute), Nonce: "nonce"}
+
+	require.NoError(t, requests.accept(request, now))
+	require.Len(t, requests.nonces, 1)
+
+	later := model.AdminRequest{TargetNodeID: "node", ExpiresAt: now.Add(3 * time.Minute), Nonce: "later"}
+	require.NoError(t, requests.accept(later, now.Add(2*time.Minute)))
+	require.Len(t, requests.nonces, 1)
+}
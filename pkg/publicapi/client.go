@@ -65,6 +65,41 @@ func (apiClient *APIClient) Alive(ctx context.Context) (bool, error) {
 	return res.StatusCode == http.StatusOK, nil
 }
 
+// ID returns the id of the node.
+func (apiClient *APIClient) ID(ctx context.Context) (string, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/publicapi.Client.ID")
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiClient.BaseURI+"/id", nil)
+	if err != nil {
+		return "", bacerrors.NewResponseUnknownError(fmt.Errorf("publicapi: error creating ID request: %v", err))
+	}
+	res, err := apiClient.Client.Do(req) //nolint:bodyclose // golangcilint is dumb - this is closed
+	if err != nil {
+		return "", bacerrors.NewResponseUnknownError(fmt.Errorf("publicapi: error getting the node's ID: %v", err))
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "apiClient response", res.Body)
+
+	if res.StatusCode != http.StatusOK {
+		return "", bacerrors.NewResponseUnknownError(fmt.Errorf("publicapi: getting the node's ID returned status %d", res.StatusCode))
+	}
+	var id string
+	if err = json.NewDecoder(res.Body).Decode(&id); err != nil {
+		return "", bacerrors.NewResponseUnknownError(fmt.Errorf("publicapi: error decoding the node's ID: %v", err))
+	}
+	return id, nil
+}
+
+// NewAdminRequest returns an admin request for the node, to sign as part of
+// the payload of a request that only admins can make.
+func (apiClient *APIClient) NewAdminRequest(ctx context.Context) (model.AdminRequest, error) {
+	nodeID, err := apiClient.ID(ctx)
+	if err != nil {
+		return model.AdminRequest{}, err
+	}
+	return model.NewAdminRequest(nodeID)
+}
+
 func (apiClient *APIClient) Version(ctx context.Context) (*model.BuildVersionInfo, error) {
 	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/publicapi.Client.Version")
 	defer span.End()
@@ -73,6 +73,7 @@ type APIServer struct {
 	handlers         map[string]http.Handler
 	handlersMu       sync.Mutex
 	started          bool
+	adminRequests    *adminRequests
 }
 
 func NewAPIServer(params APIServerParams) (*APIServer, error) {
@@ -83,6 +84,7 @@ func NewAPIServer(params APIServerParams) (*APIServer, error) {
 		nodeInfoProvider: params.NodeInfoProvider,
 		config:           params.Config,
 		handlers:         make(map[string]http.Handler),
+		adminRequests:    newAdminRequests(params.Host.ID().String()),
 	}
 
 	server.handlersMu.EnableTracerWithOpts(sync.Opts{
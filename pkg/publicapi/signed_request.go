@@ -0,0 +1,95 @@
+package publicapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/rs/zerolog/log"
+)
+
+// A weakly-typed signed request. We use this type only in our code
+// on both client and server to correctly validate signatures.
+type SignedRequest struct {
+	// The payload of the request
+	Payload *json.RawMessage `json:"payload" validate:"required"`
+
+	// A base64-encoded signature of the payload, signed by the client:
+	ClientSignature string `json:"signature" validate:"required"`
+
+	// The base64-encoded public key of the client:
+	ClientPublicKey string `json:"client_public_key" validate:"required"`
+}
+
+type ContainsClientID interface {
+	GetClientID() string
+}
+
+// UnmarshalSigned returns the payload of a signed request once it has checked that it was signed by the client that
+// it names.
+func UnmarshalSigned[PayloadType ContainsClientID](body io.Reader) (PayloadType, error) {
+	var signed SignedRequest
+	var payload PayloadType
+	if err := json.NewDecoder(body).Decode(&signed); err != nil {
+		return payload, fmt.Errorf("error unmarshalling envelope: %w", err)
+	}
+	if signed.Payload == nil {
+		return payload, errors.New("the request has no payload")
+	}
+
+	// first verify the signature on the raw bytes
+	if err := VerifyRequestSignature(*signed.Payload, signed.ClientSignature, signed.ClientPublicKey); err != nil {
+		return payload, err
+	}
+
+	// then decode the payload
+	if err := json.Unmarshal(*signed.Payload, &payload); err != nil {
+		return payload, fmt.Errorf("error unmarshalling payload: %w", err)
+	}
+
+	// check that the client id in the payload actually matches the key
+	if err := VerifyClientID(payload.GetClientID(), signed.ClientPublicKey); err != nil {
+		return payload, fmt.Errorf("error validating request: %w", err)
+	}
+	return payload, nil
+}
+
+// VerifyRequestSignature checks that msg was signed by the holder of clientPubKey.
+func VerifyRequestSignature(msg json.RawMessage, clientSignature string, clientPubKey string) error {
+	if clientSignature == "" {
+		return errors.New("client's signature is required")
+	}
+	if clientPubKey == "" {
+		return errors.New("client's public key is required")
+	}
+	if err := system.Verify(msg, clientSignature, clientPubKey); err != nil {
+		return fmt.Errorf("client's signature is invalid: %w", err)
+	}
+	return nil
+}
+
+// VerifyClientID checks that clientPubKey is the key of the client that a payload names.
+func VerifyClientID(clientID string, clientPubKey string) error {
+	if clientID == "" {
+		return errors.New("the payload must contain a client ID")
+	}
+	ok, err := system.PublicKeyMatchesID(clientPubKey, clientID)
+	if err != nil {
+		return fmt.Errorf("error verifying client ID: %w", err)
+	}
+	if !ok {
+		return errors.New("client's public key does not match client ID")
+	}
+	return nil
+}
+
+// HTTPError logs err and writes it to the response with the given status code.
+func HTTPError(ctx context.Context, res http.ResponseWriter, err error, statusCode int) {
+	log.Ctx(ctx).Error().Err(err).Send()
+	http.Error(res, bacerrors.ErrorToErrorResponse(err), statusCode)
+}
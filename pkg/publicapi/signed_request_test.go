@@ -0,0 +1,77 @@
+//go:build unit || !integration
+
+package publicapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/stretchr/testify/require"
+)
+
+type testPayload struct {
+	ClientID string `json:"ClientID"`
+	Value    string `json:"Value"`
+}
+
+func (p testPayload) GetClientID() string {
+	return p.ClientID
+}
+
+func signedBody(t *testing.T, payload testPayload, tamper func(*SignedRequest)) *bytes.Buffer {
+	raw, err := json.Marshal(payload)
+	require.NoError(t, err)
+	rawPayload := json.RawMessage(raw)
+	signature, err := system.SignForClient(rawPayload)
+	require.NoError(t, err)
+	req := SignedRequest{
+		Payload:         &rawPayload,
+		ClientSignature: signature,
+		ClientPublicKey: system.GetClientPublicKey(),
+	}
+	if tamper != nil {
+		tamper(&req)
+	}
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	return bytes.NewBuffer(body)
+}
+
+func TestUnmarshalSigned(t *testing.T) {
+	system.InitConfigForTesting(t)
+
+	payload, err := UnmarshalSigned[testPayload](signedBody(t, testPayload{ClientID: system.GetClientID(), Value: "v"}, nil))
+	require.NoError(t, err)
+	require.Equal(t, "v", payload.Value)
+
+	for name, test := range map[string]struct {
+		payload testPayload
+		tamper  func(*SignedRequest)
+	}{
+		"no client ID":    {payload: testPayload{Value: "v"}},
+		"other client ID": {payload: testPayload{ClientID: "someone-else", Value: "v"}},
+		"no signature": {
+			payload: testPayload{ClientID: system.GetClientID()},
+			tamper:  func(r *SignedRequest) { r.ClientSignature = "" },
+		},
+		"altered payload": {
+			payload: testPayload{ClientID: system.GetClientID()},
+			tamper: func(r *SignedRequest) {
+				altered := json.RawMessage(`{"ClientID":"` + system.GetClientID() + `","Value":"altered"}`)
+				r.Payload = &altered
+			},
+		},
+		"no payload": {
+			payload: testPayload{ClientID: system.GetClientID()},
+			tamper:  func(r *SignedRequest) { r.Payload = nil },
+		},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			_, err := UnmarshalSigned[testPayload](signedBody(t, test.payload, test.tamper))
+			require.Error(t, err)
+		})
+	}
+}
@@ -124,10 +124,13 @@ func fetchHostnameFromConfig(file string) (string, error) {
 type Client interface {
 	ClientDealPieceCID(context.Context, cid.Cid) (DataCIDSize, error)
 	ClientExport(context.Context, ExportRef, FileRef) error
+	ClientFindData(context.Context, cid.Cid, *cid.Cid) ([]QueryOffer, error)
 	ClientGetDealUpdates(ctx context.Context) (<-chan DealInfo, error)
 	ClientListImports(context.Context) ([]Import, error)
 	ClientImport(context.Context, FileRef) (*ImportRes, error)
 	ClientQueryAsk(context.Context, peer.ID, address.Address) (*StorageAsk, error)
+	ClientRetrieve(context.Context, RetrievalOrder) (*RestrievalRes, error)
+	ClientRetrieveWait(context.Context, retrievalmarket.DealID) error
 	ClientStartDeal(context.Context, *StartDealParams) (*cid.Cid, error)
 	StateGetNetworkParams(context.Context) (*NetworkParams, error)
 	StateListMiners(context.Context, TipSetKey) ([]address.Address, error)
@@ -145,10 +148,13 @@ type api struct {
 	internal struct {
 		ClientDealPieceCID    func(context.Context, cid.Cid) (DataCIDSize, error)
 		ClientExport          func(context.Context, ExportRef, FileRef) error
+		ClientFindData        func(context.Context, cid.Cid, *cid.Cid) ([]QueryOffer, error)
 		ClientGetDealUpdates  func(ctx context.Context) (<-chan DealInfo, error)
 		ClientListImports     func(context.Context) ([]Import, error)
 		ClientImport          func(context.Context, FileRef) (*ImportRes, error)
 		ClientQueryAsk        func(context.Context, peer.ID, address.Address) (*StorageAsk, error)
+		ClientRetrieve        func(context.Context, RetrievalOrder) (*RestrievalRes, error)
+		ClientRetrieveWait    func(context.Context, retrievalmarket.DealID) error
 		ClientStartDeal       func(context.Context, *StartDealParams) (*cid.Cid, error)
 		StateGetNetworkParams func(context.Context) (*NetworkParams, error)
 		StateListMiners       func(context.Context, TipSetKey) ([]address.Address, error)
@@ -174,6 +180,12 @@ func (a *api) ClientExport(ctx context.Context, exportRef ExportRef, fileRef Fil
 	return telemetry.RecordErrorOnSpan(span)(a.internal.ClientExport(ctx, exportRef, fileRef))
 }
 
+func (a *api) ClientFindData(ctx context.Context, root cid.Cid, piece *cid.Cid) ([]QueryOffer, error) {
+	ctx, span := a.span(ctx, "ClientFindData")
+	defer span.End()
+	return telemetry.RecordErrorOnSpanTwo[[]QueryOffer](span)(a.internal.ClientFindData(ctx, root, piece))
+}
+
 func (a *api) ClientGetDealUpdates(ctx context.Context) (<-chan DealInfo, error) {
 	ctx, span := a.span(ctx, "ClientGetDealUpdates")
 	defer span.End()
@@ -198,6 +210,18 @@ func (a *api) ClientQueryAsk(ctx context.Context, p peer.ID, miner address.Addre
 	return telemetry.RecordErrorOnSpanTwo[*StorageAsk](span)(a.internal.ClientQueryAsk(ctx, p, miner))
 }
 
+func (a *api) ClientRetrieve(ctx context.Context, order RetrievalOrder) (*RestrievalRes, error) {
+	ctx, span := a.span(ctx, "ClientRetrieve")
+	defer span.End()
+	return telemetry.RecordErrorOnSpanTwo[*RestrievalRes](span)(a.internal.ClientRetrieve(ctx, order))
+}
+
+func (a *api) ClientRetrieveWait(ctx context.Context, deal retrievalmarket.DealID) error {
+	ctx, span := a.span(ctx, "ClientRetrieveWait")
+	defer span.End()
+	return telemetry.RecordErrorOnSpan(span)(a.internal.ClientRetrieveWait(ctx, deal))
+}
+
 func (a *api) ClientStartDeal(ctx context.Context, params *StartDealParams) (*cid.Cid, error) {
 	ctx, span := a.span(ctx, "ClientStartDeal")
 	defer span.End()
@@ -436,3 +460,55 @@ type ExportRef struct {
 	FromLocalCAR string
 	DealID       retrievalmarket.DealID
 }
+
+type QueryOffer struct {
+	Err string
+
+	Root  cid.Cid
+	Piece *cid.Cid
+
+	Size                    uint64
+	MinPrice                big2.Int
+	UnsealPrice             big2.Int
+	PricePerByte            big2.Int
+	PaymentInterval         uint64
+	PaymentIntervalIncrease uint64
+	Miner                   address.Address
+	MinerPeer               retrievalmarket.RetrievalPeer
+}
+
+// Order returns the order to retrieve the offered data from the miner.
+func (o *QueryOffer) Order(client address.Address) RetrievalOrder {
+	return RetrievalOrder{
+		Root:                    o.Root,
+		Piece:                   o.Piece,
+		Size:                    o.Size,
+		Total:                   o.MinPrice,
+		UnsealPrice:             o.UnsealPrice,
+		PaymentInterval:         o.PaymentInterval,
+		PaymentIntervalIncrease: o.PaymentIntervalIncrease,
+		Client:                  client,
+		Miner:                   o.Miner,
+		MinerPeer:               &o.MinerPeer,
+	}
+}
+
+type RetrievalOrder struct {
+	Root         cid.Cid
+	Piece        *cid.Cid
+	DataSelector *Selector
+
+	Size                    uint64
+	Total                   big2.Int
+	UnsealPrice             big2.Int
+	PaymentInterval         uint64
+	PaymentIntervalIncrease uint64
+	Client                  address.Address
+	Miner                   address.Address
+	MinerPeer               *retrievalmarket.RetrievalPeer
+}
+
+// RestrievalRes is misspelt as it is in Lotus.
+type RestrievalRes struct {
+	DealID retrievalmarket.DealID
+}
@@ -1,3 +1,17 @@
 package retrievalmarket
 
+import (
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
 type DealID uint64
+
+// RetrievalPeer is a provider address/peer.ID pair (everything needed to make
+// deals for with a miner)
+type RetrievalPeer struct {
+	Address  address.Address
+	ID       peer.ID // optional
+	PieceCID *cid.Cid
+}
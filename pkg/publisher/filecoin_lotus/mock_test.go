@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	api "github.com/bacalhau-project/bacalhau/pkg/publisher/filecoin_lotus/api"
+	retrievalmarket "github.com/bacalhau-project/bacalhau/pkg/publisher/filecoin_lotus/api/retrievalmarket"
 	address "github.com/filecoin-project/go-address"
 	gomock "github.com/golang/mock/gomock"
 	cid "github.com/ipfs/go-cid"
@@ -66,6 +67,21 @@ func (mr *MockClientMockRecorder) ClientExport(arg0, arg1, arg2 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientExport", reflect.TypeOf((*MockClient)(nil).ClientExport), arg0, arg1, arg2)
 }
 
+// ClientFindData mocks base method.
+func (m *MockClient) ClientFindData(arg0 context.Context, arg1 cid.Cid, arg2 *cid.Cid) ([]api.QueryOffer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClientFindData", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]api.QueryOffer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClientFindData indicates an expected call of ClientFindData.
+func (mr *MockClientMockRecorder) ClientFindData(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientFindData", reflect.TypeOf((*MockClient)(nil).ClientFindData), arg0, arg1, arg2)
+}
+
 // ClientGetDealUpdates mocks base method.
 func (m *MockClient) ClientGetDealUpdates(ctx context.Context) (<-chan api.DealInfo, error) {
 	m.ctrl.T.Helper()
@@ -126,6 +142,35 @@ func (mr *MockClientMockRecorder) ClientQueryAsk(arg0, arg1, arg2 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientQueryAsk", reflect.TypeOf((*MockClient)(nil).ClientQueryAsk), arg0, arg1, arg2)
 }
 
+// ClientRetrieve mocks base method.
+func (m *MockClient) ClientRetrieve(arg0 context.Context, arg1 api.RetrievalOrder) (*api.RestrievalRes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClientRetrieve", arg0, arg1)
+	ret0, _ := ret[0].(*api.RestrievalRes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClientRetrieve indicates an expected call of ClientRetrieve.
+func (mr *MockClientMockRecorder) ClientRetrieve(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientRetrieve", reflect.TypeOf((*MockClient)(nil).ClientRetrieve), arg0, arg1)
+}
+
+// ClientRetrieveWait mocks base method.
+func (m *MockClient) ClientRetrieveWait(arg0 context.Context, arg1 retrievalmarket.DealID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClientRetrieveWait", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClientRetrieveWait indicates an expected call of ClientRetrieveWait.
+func (mr *MockClientMockRecorder) ClientRetrieveWait(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientRetrieveWait", reflect.TypeOf((*MockClient)(nil).ClientRetrieveWait), arg0, arg1)
+}
+
 // ClientStartDeal mocks base method.
 func (m *MockClient) ClientStartDeal(arg0 context.Context, arg1 *api.StartDealParams) (*cid.Cid, error) {
 	m.ctrl.T.Helper()
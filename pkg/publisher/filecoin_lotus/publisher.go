@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -102,13 +104,23 @@ func (l *Publisher) PublishResult(
 		return model.StorageSpec{}, err
 	}
 
-	dealCid, err := l.createDeal(ctx, contentCid)
+	deals, err := l.createDeals(ctx, contentCid, j.Spec.FilecoinDeal)
 	if err != nil {
 		return model.StorageSpec{}, err
 	}
 
 	spec := job.GetPublishedStorageSpec(j, model.StorageSourceFilecoin, hostID, contentCid.String())
-	spec.Metadata["deal_cid"] = dealCid
+	spec.Metadata["deal_cid"] = deals[0].ProposalCid.String()
+	spec.Metadata["verified_deal"] = strconv.FormatBool(j.Spec.FilecoinDeal.VerifiedDeal)
+	for i, deal := range deals {
+		prefix := fmt.Sprintf("deal_%d_", i)
+		spec.Metadata[prefix+"cid"] = deal.ProposalCid.String()
+		spec.Metadata[prefix+"miner"] = deal.Provider.String()
+		spec.Metadata[prefix+"state"] = storagemarket.DealStates[deal.State]
+		if deal.DealID != 0 {
+			spec.Metadata[prefix+"id"] = fmt.Sprint(deal.DealID)
+		}
+	}
 	return spec, nil
 }
 
@@ -147,27 +159,34 @@ func (l *Publisher) importData(ctx context.Context, filePath string) (cid.Cid, e
 	return res.Root, nil
 }
 
-func (l *Publisher) createDeal(ctx context.Context, contentCid cid.Cid) (string, error) {
+// createDeals makes deals for the content with as many of the cheapest miners
+// as the deal config replicates it to, moving on to the next cheapest miner if
+// a deal isn't accepted. It only fails if no deals are accepted.
+func (l *Publisher) createDeals(ctx context.Context, contentCid cid.Cid, config model.FilecoinDealConfig) ([]api.DealInfo, error) {
 	dataSize, err := l.client.ClientDealPieceCID(ctx, contentCid)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	params, err := l.client.StateGetNetworkParams(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	epochs := api.ChainEpoch(l.config.StorageDuration / (time.Duration(params.BlockDelaySecs) * time.Second))
+	duration := l.config.StorageDuration
+	if config.DurationDays > 0 {
+		duration = time.Duration(config.DurationDays) * 24 * time.Hour
+	}
+	epochs := api.ChainEpoch(duration / (time.Duration(params.BlockDelaySecs) * time.Second))
 
 	wallet, err := l.client.WalletDefaultAddress(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	miners, err := l.client.StateListMiners(ctx, api.TipSetKey{})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	log.Ctx(ctx).Debug().Int("count", len(miners)).Msg("Initial list of miners")
@@ -179,16 +198,55 @@ func (l *Publisher) createDeal(ctx context.Context, contentCid cid.Cid) (string,
 		log.Ctx(ctx).
 			Err(multierror.Append(nil, errs...)).
 			Msg("Couldn't find a miner")
-		return "", fmt.Errorf("unable to find a miner")
+		return nil, fmt.Errorf("unable to find a miner")
+	}
+
+	// verified deals are paid for at the verified price of the miner
+	price := func(a *ask) big2.Int {
+		if config.VerifiedDeal {
+			return a.verifiedEpochPrice
+		}
+		return a.epochPrice
 	}
+	sort.SliceStable(asks, func(i, j int) bool {
+		return price(asks[i]).LessThan(price(asks[j]))
+	})
 
-	cheapest := asks[0]
+	replication := system.Max(config.Replication, 1)
+	var deals []api.DealInfo
+	var dealErrs error
 	for _, a := range asks {
-		if a.epochPrice.LessThan(cheapest.epochPrice) {
-			cheapest = a
+		if len(deals) == replication {
+			break
 		}
+		deal, err := l.createDeal(ctx, contentCid, dataSize, wallet, a.miner, price(a), uint64(epochs), config.VerifiedDeal)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Stringer("miner", a.miner).Msg("Deal not made")
+			dealErrs = multierror.Append(dealErrs, err)
+			continue
+		}
+		deals = append(deals, deal)
 	}
 
+	if len(deals) == 0 {
+		return nil, fmt.Errorf("unable to make a deal: %w", dealErrs)
+	}
+	if len(deals) < replication {
+		log.Ctx(ctx).Warn().Err(dealErrs).Msgf("Only made %d of %d deals", len(deals), replication)
+	}
+	return deals, nil
+}
+
+func (l *Publisher) createDeal(
+	ctx context.Context,
+	contentCid cid.Cid,
+	dataSize api.DataCIDSize,
+	wallet address.Address,
+	miner address.Address,
+	epochPrice big2.Int,
+	epochs uint64,
+	verified bool,
+) (api.DealInfo, error) {
 	deal, err := l.client.ClientStartDeal(ctx, &api.StartDealParams{
 		Data: &api.DataRef{
 			TransferType: "graphsync", // storagemarket.TTGraphsync
@@ -197,31 +255,28 @@ func (l *Publisher) createDeal(ctx context.Context, contentCid cid.Cid) (string,
 			PieceSize:    dataSize.PieceSize.Unpadded(),
 		},
 		Wallet:            wallet,
-		Miner:             cheapest.miner,
-		EpochPrice:        cheapest.epochPrice,
-		MinBlocksDuration: uint64(epochs),
+		Miner:             miner,
+		EpochPrice:        epochPrice,
+		MinBlocksDuration: epochs,
+		VerifiedDeal:      verified,
 	})
 	if err != nil {
-		return "", err
+		return api.DealInfo{}, err
 	}
 
-	log.Ctx(ctx).Info().Stringer("cid", deal).Msg("Deal started")
-
-	if err := l.waitUntilDealIsReady(ctx, deal); err != nil {
-		return "", err
-	}
+	log.Ctx(ctx).Info().Stringer("cid", deal).Stringer("miner", miner).Msg("Deal started")
 
-	return deal.String(), nil
+	return l.waitUntilDealIsReady(ctx, deal)
 }
 
-func (l *Publisher) waitUntilDealIsReady(ctx context.Context, deal *cid.Cid) error {
+func (l *Publisher) waitUntilDealIsReady(ctx context.Context, deal *cid.Cid) (api.DealInfo, error) {
 	// The go-jsonrpc library that the `client` uses relies on the context to know when to stop writing to the info channel
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	infoChan, err := l.client.ClientGetDealUpdates(ctx)
 	if err != nil {
-		return err
+		return api.DealInfo{}, err
 	}
 
 	t := time.NewTicker(3 * time.Second)
@@ -238,7 +293,7 @@ func (l *Publisher) waitUntilDealIsReady(ctx context.Context, deal *cid.Cid) err
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return api.DealInfo{}, ctx.Err()
 		case info := <-infoChan:
 			if deal.Equals(info.ProposalCid) {
 				currentState = info.State
@@ -249,11 +304,11 @@ func (l *Publisher) waitUntilDealIsReady(ctx context.Context, deal *cid.Cid) err
 						Str("current", storagemarket.DealStates[currentState]).
 						Str("expected", storagemarket.DealStates[wanted]).
 						Msg("Deal in expected state")
-					return nil
+					return info, nil
 				}
 
 				if currentState == storagemarket.StorageDealFailing || currentState == storagemarket.StorageDealError {
-					return fmt.Errorf("deal not accepted: %s", info.Message)
+					return api.DealInfo{}, fmt.Errorf("deal not accepted: %s", info.Message)
 				}
 			}
 		case <-t.C:
@@ -298,17 +353,24 @@ func (l *Publisher) queryMiner(ctx context.Context, dataSize api.DataCIDSize, mi
 		return nil, fmt.Errorf("data size (%v) is too big for miner %s (%v)", dataSize.PieceSize, miner, query.Response.MaxPieceSize)
 	}
 
-	epochPrice := big2.Div(big2.Mul(query.Response.Price, big2.NewIntUnsigned(uint64(dataSize.PieceSize))), big2.NewInt(oneGibibyte))
+	pieceSize := big2.NewIntUnsigned(uint64(dataSize.PieceSize))
+	epochPrice := big2.Div(big2.Mul(query.Response.Price, pieceSize), big2.NewInt(oneGibibyte))
+	verifiedEpochPrice := big2.Zero()
+	if !query.Response.VerifiedPrice.Nil() {
+		verifiedEpochPrice = big2.Div(big2.Mul(query.Response.VerifiedPrice, pieceSize), big2.NewInt(oneGibibyte))
+	}
 
 	return &ask{
-		miner:      miner,
-		epochPrice: epochPrice,
+		miner:              miner,
+		epochPrice:         epochPrice,
+		verifiedEpochPrice: verifiedEpochPrice,
 	}, nil
 }
 
 type ask struct {
-	miner      address.Address
-	epochPrice big2.Int
+	miner              address.Address
+	epochPrice         big2.Int
+	verifiedEpochPrice big2.Int
 }
 
 var _ publisher.Publisher = &Publisher{}
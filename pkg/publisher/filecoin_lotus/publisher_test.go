@@ -119,6 +119,93 @@ func (s *PublisherTestSuite) TestPublishToLotus() {
 	s.Equal(contentCid.String(), spec.CID)
 }
 
+func (s *PublisherTestSuite) TestPublishToLotusWithDealConfig() {
+	contentCid := cid.MustParse("bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi")
+	wallet, err := address.NewIDAddress(1)
+	s.Require().NoError(err)
+	cheap, err := address.NewIDAddress(4321)
+	s.Require().NoError(err)
+	expensive, err := address.NewIDAddress(5432)
+	s.Require().NoError(err)
+	mostExpensive, err := address.NewIDAddress(6543)
+	s.Require().NoError(err)
+	miners := []address.Address{expensive, mostExpensive, cheap}
+	verifiedPrices := map[address.Address]int64{cheap: 1, expensive: 2, mostExpensive: 4}
+	dealCids := map[address.Address]cid.Cid{
+		cheap:     cid.MustParse("bafkqaaa"),
+		expensive: cid.MustParse("bafybeihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"),
+	}
+
+	s.client.EXPECT().
+		ClientImport(gomock.Any(), gomock.Any()).
+		Return(&api.ImportRes{Root: contentCid}, nil)
+	s.client.EXPECT().ClientDealPieceCID(gomock.Any(), contentCid).Return(api.DataCIDSize{
+		PieceSize: oneGibibyte,
+		PieceCID:  contentCid,
+	}, nil)
+	s.client.EXPECT().StateGetNetworkParams(gomock.Any()).Return(&api.NetworkParams{BlockDelaySecs: 30}, nil)
+	s.client.EXPECT().WalletDefaultAddress(gomock.Any()).Return(wallet, nil)
+	s.client.EXPECT().StateListMiners(gomock.Any(), gomock.Any()).Return(miners, nil)
+	s.client.EXPECT().StateMinerInfo(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, miner address.Address, _ api.TipSetKey) (api.MinerInfo, error) {
+			return api.MinerInfo{PeerId: pointer(peer.ID(miner.String()))}, nil
+		}).Times(len(miners))
+	s.client.EXPECT().StateMinerPower(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&api.MinerPower{HasMinPower: true}, nil).Times(len(miners))
+	s.client.EXPECT().ClientQueryAsk(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ peer.ID, miner address.Address) (*api.StorageAsk, error) {
+			return &api.StorageAsk{Response: &storagemarket.StorageAsk{
+				// unverified deals are cheapest with the miners that are most expensive for verified deals
+				Price:         big2.NewInt(8 - verifiedPrices[miner]),
+				VerifiedPrice: big2.NewInt(verifiedPrices[miner]),
+				MaxPieceSize:  oneGibibyte,
+			}}, nil
+		}).Times(len(miners))
+
+	var started []address.Address
+	s.client.EXPECT().
+		ClientStartDeal(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, deal *api.StartDealParams) (*cid.Cid, error) {
+			s.True(deal.VerifiedDeal)
+			s.Equal(big2.NewInt(verifiedPrices[deal.Miner]), deal.EpochPrice)
+			s.Equal(uint64(2*24*60*2), deal.MinBlocksDuration)
+			started = append(started, deal.Miner)
+			dealCid := dealCids[deal.Miner]
+			return &dealCid, nil
+		}).Times(2)
+	s.client.EXPECT().ClientGetDealUpdates(gomock.Any()).DoAndReturn(func(context.Context) (<-chan api.DealInfo, error) {
+		miner := started[len(started)-1]
+		c := make(chan api.DealInfo, 1)
+		c <- api.DealInfo{
+			ProposalCid: dealCids[miner],
+			State:       storagemarket.StorageDealCheckForAcceptance,
+			Provider:    miner,
+			DealID:      abi2.DealID(len(started)),
+		}
+		return c, nil
+	}).Times(2)
+
+	resultsDir := s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(resultsDir, "hello.txt"), []byte("world"), 0644))
+
+	j := model.Job{Metadata: model.Metadata{ID: "foo"}}
+	j.Spec.FilecoinDeal = model.FilecoinDealConfig{Replication: 2, DurationDays: 2, VerifiedDeal: true}
+	spec, err := s.executor.PublishResult(context.Background(), j, "1234", resultsDir)
+	s.Require().NoError(err)
+
+	s.Equal(contentCid.String(), spec.CID)
+	s.Equal(dealCids[cheap].String(), spec.Metadata["deal_cid"])
+	s.Equal("true", spec.Metadata["verified_deal"])
+	s.Equal(dealCids[cheap].String(), spec.Metadata["deal_0_cid"])
+	s.Equal(cheap.String(), spec.Metadata["deal_0_miner"])
+	s.Equal("1", spec.Metadata["deal_0_id"])
+	s.Equal("StorageDealCheckForAcceptance", spec.Metadata["deal_0_state"])
+	s.Equal(dealCids[expensive].String(), spec.Metadata["deal_1_cid"])
+	s.Equal(expensive.String(), spec.Metadata["deal_1_miner"])
+	s.Equal("2", spec.Metadata["deal_1_id"])
+	s.NotContains(spec.Metadata, "deal_2_cid")
+}
+
 func pointer[T any](t T) *T {
 	return &t
 }
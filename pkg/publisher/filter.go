@@ -0,0 +1,156 @@
+package publisher
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+)
+
+// maxSkippedPaths is how many of the paths of skipped files are reported, so
+// that skipping many temporary files doesn't bloat the state of the job.
+const maxSkippedPaths = 100
+
+// ValidateOutputFilters returns an error if an include or exclude pattern of
+// the output volume isn't a valid glob.
+func ValidateOutputFilters(output model.StorageSpec) error {
+	for _, pattern := range append(append([]string{}, output.Include...), output.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q for output volume %s: %w", pattern, output.Name, err)
+		}
+	}
+	return nil
+}
+
+// HasOutputFilters returns whether any of the output volumes of the job filter
+// which of their files are published.
+func HasOutputFilters(j model.Job) bool {
+	for _, output := range j.Spec.Outputs {
+		if len(output.Include) > 0 || len(output.Exclude) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterOutputs links, or copies if it can't, the results in the result path
+// into the output path, skipping the files in output volumes that the volumes
+// don't include or that they exclude, and reports what it skipped. Files that
+// aren't in an output volume, like stdout, are always kept.
+func FilterOutputs(j model.Job, resultPath, outputPath string) (model.SkippedOutputs, error) {
+	skipped := model.SkippedOutputs{}
+	err := filepath.WalkDir(resultPath, func(src string, d fs.DirEntry, err error) error {
+		if err != nil || src == resultPath {
+			return err
+		}
+		rel, err := filepath.Rel(resultPath, src)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(outputPath, rel)
+		if d.IsDir() {
+			return os.MkdirAll(dst, os.ModePerm)
+		}
+
+		if output, name, ok := outputVolume(j, filepath.ToSlash(rel)); ok && !published(output, name) {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			skipped.Count++
+			skipped.Size += info.Size()
+			if len(skipped.Paths) < maxSkippedPaths {
+				skipped.Paths = append(skipped.Paths, filepath.ToSlash(rel))
+			}
+			return nil
+		}
+
+		switch {
+		case d.Type().IsRegular():
+			return linkOrCopy(src, dst)
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(src)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(target, dst)
+		default:
+			return nil
+		}
+	})
+	return skipped, err
+}
+
+// outputVolume returns the output volume of the job that the path of a result
+// is in, and the path relative to the volume. The volumes of array jobs are in
+// the directory of each index.
+func outputVolume(j model.Job, rel string) (model.StorageSpec, string, bool) {
+	parts := strings.Split(rel, "/")
+	for _, output := range j.Spec.Outputs {
+		if len(parts) > 1 && parts[0] == output.Name {
+			return output, path.Join(parts[1:]...), true
+		}
+		if j.Spec.Array.Count > 0 && len(parts) > 2 && parts[1] == output.Name {
+			return output, path.Join(parts[2:]...), true
+		}
+	}
+	return model.StorageSpec{}, "", false
+}
+
+// published returns whether the output volume publishes the file at the path
+// relative to it.
+func published(output model.StorageSpec, name string) bool {
+	if len(output.Include) > 0 && !matches(output.Include, name) {
+		return false
+	}
+	return !matches(output.Exclude, name)
+}
+
+// matches returns whether any of the patterns match the path, the name of the
+// file, or one of the directories it is in. Patterns with a slash in them only
+// match paths, so that "*.csv" matches CSV files in any directory but "*/*.csv"
+// only matches those in a subdirectory.
+func matches(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		for p := name; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+			if strings.Contains(pattern, "/") {
+				continue
+			}
+			if ok, _ := path.Match(pattern, path.Base(p)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func linkOrCopy(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError(src, in)
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError(dst, out)
+	_, err = io.Copy(out, in)
+	return err
+}
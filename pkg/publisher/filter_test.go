@@ -0,0 +1,82 @@
+//go:build unit || !integration
+
+package publisher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterOutputs(t *testing.T) {
+	results := t.TempDir()
+	for name, content := range map[string]string{
+		"stdout":                       "hello",
+		"outputs/data.parquet":         "data",
+		"outputs/reports/summary.txt":  "summary",
+		"outputs/reports/data.parquet": "report data",
+		"outputs/tmp/data.parquet":     "temporary",
+		"outputs/scratch.bin":          "scratch",
+		"logs/run.log":                 "log",
+	} {
+		path := filepath.Join(results, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	j := model.Job{Spec: model.Spec{Outputs: []model.StorageSpec{
+		{Name: "outputs", Path: "/outputs", Include: []string{"*.parquet", "reports"}, Exclude: []string{"tmp"}},
+		{Name: "logs", Path: "/logs"},
+	}}}
+	require.True(t, HasOutputFilters(j))
+
+	filtered := t.TempDir()
+	skipped, err := FilterOutputs(j, results, filtered)
+	require.NoError(t, err)
+	require.Equal(t, 2, skipped.Count)
+	require.Equal(t, int64(len("temporary")+len("scratch")), skipped.Size)
+	require.ElementsMatch(t, []string{"outputs/tmp/data.parquet", "outputs/scratch.bin"}, skipped.Paths)
+
+	for _, name := range []string{
+		"stdout",
+		"outputs/data.parquet",
+		"outputs/reports/summary.txt",
+		"outputs/reports/data.parquet",
+		"logs/run.log",
+	} {
+		require.FileExists(t, filepath.Join(filtered, name))
+	}
+	require.NoFileExists(t, filepath.Join(filtered, "outputs/tmp/data.parquet"))
+	require.NoFileExists(t, filepath.Join(filtered, "outputs/scratch.bin"))
+}
+
+func TestFilterOutputsOfArrayJobs(t *testing.T) {
+	results := t.TempDir()
+	for _, name := range []string{"0/outputs/keep.csv", "0/outputs/drop.tmp", "1/outputs/keep.csv", "1/stdout"} {
+		path := filepath.Join(results, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+
+	j := model.Job{Spec: model.Spec{
+		Array:   model.ArrayConfig{Count: 2},
+		Outputs: []model.StorageSpec{{Name: "outputs", Path: "/outputs", Exclude: []string{"*.tmp"}}},
+	}}
+
+	filtered := t.TempDir()
+	skipped, err := FilterOutputs(j, results, filtered)
+	require.NoError(t, err)
+	require.Equal(t, []string{"0/outputs/drop.tmp"}, skipped.Paths)
+	require.FileExists(t, filepath.Join(filtered, "0/outputs/keep.csv"))
+	require.FileExists(t, filepath.Join(filtered, "1/outputs/keep.csv"))
+	require.FileExists(t, filepath.Join(filtered, "1/stdout"))
+}
+
+func TestValidateOutputFilters(t *testing.T) {
+	require.NoError(t, ValidateOutputFilters(model.StorageSpec{Include: []string{"*.csv", "data/[a-z]*"}}))
+	require.Error(t, ValidateOutputFilters(model.StorageSpec{Exclude: []string{"[a-"}}))
+	require.False(t, HasOutputFilters(model.Job{Spec: model.Spec{Outputs: []model.StorageSpec{{Name: "outputs"}}}}))
+}
@@ -2,8 +2,10 @@ package ipfs
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs/pinning"
 	"github.com/bacalhau-project/bacalhau/pkg/job"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher"
@@ -13,16 +15,22 @@ import (
 
 type IPFSPublisher struct {
 	IPFSClient ipfs.Client
+	Pinner     *pinning.Pinner
 }
 
+// NewIPFSPublisher returns a publisher that adds results to the IPFS node of
+// the client, and pins them to the remote pinning services of the pinner, if
+// any.
 func NewIPFSPublisher(
 	ctx context.Context,
 	_ *system.CleanupManager,
 	cl ipfs.Client,
+	pinner *pinning.Pinner,
 ) (*IPFSPublisher, error) {
 	log.Ctx(ctx).Debug().Msgf("IPFS publisher initialized for node: %s", cl.APIAddress())
 	return &IPFSPublisher{
 		IPFSClient: cl,
+		Pinner:     pinner,
 	}, nil
 }
 
@@ -41,7 +49,26 @@ func (publisher *IPFSPublisher) PublishResult(
 	if err != nil {
 		return model.StorageSpec{}, err
 	}
-	return job.GetPublishedStorageSpec(j, model.StorageSourceIPFS, hostID, cid), nil
+	spec := job.GetPublishedStorageSpec(j, model.StorageSourceIPFS, hostID, cid)
+	err = publisher.Pinner.Pin(ctx, cid, spec.Name, map[string]string{"job": j.ID(), "host": hostID})
+	if err != nil {
+		return model.StorageSpec{}, fmt.Errorf("failed to pin results %s remotely: %w", cid, err)
+	}
+	if j.Spec.IPNSName != "" {
+		name, err := publisher.IPFSClient.PublishName(ctx, ipnsKeyName(j), cid)
+		if err != nil {
+			return model.StorageSpec{}, err
+		}
+		spec.Metadata["ipns_name"] = name
+	}
+	return spec, nil
+}
+
+// ipnsKeyName is the name of the key that the results of the job are published
+// under with IPNS, which is only shared by the jobs of the same client with the
+// same IPNS name so that clients can't update each other's records.
+func ipnsKeyName(j model.Job) string {
+	return fmt.Sprintf("bacalhau-%s-%s", j.Metadata.ClientID, j.Spec.IPNSName)
 }
 
 // Compile-time check that Verifier implements the correct interface:
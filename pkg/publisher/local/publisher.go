@@ -0,0 +1,180 @@
+// Package local provides a publisher that keeps results in a directory of the
+// compute node and serves them over HTTP, for single node and air-gapped
+// deployments where results shouldn't be published to IPFS or Filecoin.
+//
+// Results are kept as gzipped tars until they are removed from the directory
+// by hand, and are published as URLs that clients download them from. Clients
+// must send the token of the node as a bearer token to download them. If the
+// node isn't given one, it generates one and keeps it in the directory.
+package local
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/bacalhau-project/bacalhau/pkg/util/targzip"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultAddress is where results are served if no other address is
+	// configured, which is only reachable from the node itself.
+	DefaultAddress = "127.0.0.1:6001"
+
+	resultsPath   = "/results/"
+	archiveSuffix = ".tar.gz"
+	// tokenFile keeps the generated token in the directory, which isn't
+	// served as it isn't an archive.
+	tokenFile   = ".token"
+	tokenLength = 32
+)
+
+// PublisherConfig configures where results are kept and served.
+type PublisherConfig struct {
+	// Directory that results are kept in.
+	Directory string
+	// Address that results are served on, defaulting to DefaultAddress.
+	Address string
+	// URL that clients download results from, defaulting to the address that
+	// results are served on. It must be set if the address is unspecified, as
+	// there is no single address that clients would reach.
+	URL string
+	// Token that clients must send to download results. If it isn't set, one
+	// is generated and kept in the directory.
+	Token string
+}
+
+type Publisher struct {
+	dir     string
+	baseURL string
+}
+
+// NewPublisher returns a publisher that keeps results in the directory of the
+// config, and starts serving them until the cleanup manager cleans up.
+func NewPublisher(ctx context.Context, cm *system.CleanupManager, config PublisherConfig) (*Publisher, error) {
+	if config.Address == "" {
+		config.Address = DefaultAddress
+	}
+	if err := os.MkdirAll(config.Directory, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create local publisher directory: %w", err)
+	}
+	if config.Token == "" {
+		token, err := loadOrGenerateToken(config.Directory)
+		if err != nil {
+			return nil, err
+		}
+		config.Token = token
+		log.Ctx(ctx).Warn().Str("file", filepath.Join(config.Directory, tokenFile)).
+			Msg("Local publisher has no token, so clients must set BACALHAU_LOCAL_PUBLISHER_TOKEN to the generated one to download results")
+	}
+	listener, err := net.Listen("tcp", config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serve local publisher results: %w", err)
+	}
+
+	baseURL := config.URL
+	if baseURL == "" {
+		addr := listener.Addr().(*net.TCPAddr)
+		if addr.IP.IsUnspecified() {
+			closer.CloseWithLogOnError("listener", listener)
+			return nil, fmt.Errorf("the local publisher serves results on all interfaces at %s, "+
+				"so the URL that clients download them from must be set", config.Address)
+		}
+		baseURL = "http://" + addr.String()
+	}
+	p := &Publisher{dir: config.Directory, baseURL: baseURL}
+
+	server := &http.Server{
+		Handler:           newHandler(config.Directory, config.Token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			log.Ctx(ctx).Error().Err(err).Msg("Local publisher stopped serving results")
+		}
+	}()
+	cm.RegisterCallback(func() error {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	})
+
+	log.Ctx(ctx).Debug().Str("dir", config.Directory).Str("url", baseURL).Msg("Local publisher serving results")
+	return p, nil
+}
+
+// loadOrGenerateToken returns the token kept in the directory, generating it
+// the first time so that clients keep working across restarts.
+func loadOrGenerateToken(dir string) (string, error) {
+	path := filepath.Join(dir, tokenFile)
+	token, err := os.ReadFile(path)
+	if err == nil && len(bytes.TrimSpace(token)) > 0 {
+		return string(bytes.TrimSpace(token)), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read local publisher token: %w", err)
+	}
+
+	b := make([]byte, tokenLength)
+	if _, err = rand.Read(b); err != nil {
+		return "", err
+	}
+	generated := hex.EncodeToString(b)
+	if err = os.WriteFile(path, []byte(generated), 0600); err != nil {
+		return "", fmt.Errorf("failed to keep local publisher token: %w", err)
+	}
+	return generated, nil
+}
+
+func (p *Publisher) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+// PublishResult archives the results into the directory, and returns the URL
+// that they are served from.
+func (p *Publisher) PublishResult(
+	ctx context.Context,
+	j model.Job,
+	hostID string,
+	resultPath string,
+) (model.StorageSpec, error) {
+	spec := job.GetPublishedStorageSpec(j, model.StorageSourceURLDownload, hostID, "")
+	name := spec.Name + archiveSuffix
+
+	// results are only served once they are complete
+	f, err := os.CreateTemp(p.dir, "."+name+"-*")
+	if err != nil {
+		return model.StorageSpec{}, err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	err = targzip.CompressDirectory(f, resultPath)
+	closer.CloseWithLogOnError("archive", f)
+	if err != nil {
+		return model.StorageSpec{}, fmt.Errorf("failed to archive results: %w", err)
+	}
+	if err = os.Rename(f.Name(), filepath.Join(p.dir, name)); err != nil {
+		return model.StorageSpec{}, err
+	}
+
+	spec.URL = p.baseURL + resultsPath + url.PathEscape(name)
+	log.Ctx(ctx).Debug().Str("url", spec.URL).Msg("Published results locally")
+	return spec, nil
+}
+
+// Compile-time check that publisher implements the correct interface:
+var _ publisher.Publisher = (*Publisher)(nil)
@@ -0,0 +1,129 @@
+//go:build unit || !integration
+
+package local
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/downloader/local"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishAndDownloadResult(t *testing.T) {
+	ctx := context.Background()
+	cm := system.NewCleanupManager()
+	t.Cleanup(func() { cm.Cleanup(ctx) })
+
+	publisher, err := NewPublisher(ctx, cm, PublisherConfig{
+		Directory: t.TempDir(),
+		Address:   "127.0.0.1:0",
+		Token:     "secret",
+	})
+	require.NoError(t, err)
+
+	resultPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(resultPath, "stdout"), []byte("hello"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(resultPath, "outputs", "nested"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(resultPath, "outputs", "nested", "file"), []byte("world"), 0644))
+
+	spec, err := publisher.PublishResult(ctx, model.Job{Metadata: model.Metadata{ID: "123"}}, "host", resultPath)
+	require.NoError(t, err)
+	require.Equal(t, model.StorageSourceURLDownload, spec.StorageSource)
+	require.Equal(t, "job-123-host-host", spec.Name)
+	require.Contains(t, spec.URL, "/results/job-123-host-host.tar.gz")
+
+	res, err := http.Get(spec.URL) //nolint:noctx
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode, "results can't be downloaded without the token")
+
+	downloadPath := filepath.Join(t.TempDir(), "result")
+	downloader := local.NewLocalDownloader(&model.DownloaderSettings{Timeout: time.Minute, LocalPublisherToken: "secret"})
+	require.NoError(t, downloader.FetchResult(ctx, model.PublishedResult{Data: spec}, downloadPath))
+
+	stdout, err := os.ReadFile(filepath.Join(downloadPath, "stdout"))
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(stdout))
+	file, err := os.ReadFile(filepath.Join(downloadPath, "outputs", "nested", "file"))
+	require.NoError(t, err)
+	require.Equal(t, "world", string(file))
+}
+
+func TestHandlerOnlyServesArchives(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret"), []byte("secret"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".partial.tar.gz-1"), []byte("partial"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "result.tar.gz"), []byte("result"), 0644))
+	handler := newHandler(dir, "secret")
+
+	for path, status := range map[string]int{
+		"/results/result.tar.gz":     http.StatusOK,
+		"/results/secret":            http.StatusNotFound,
+		"/results/.partial.tar.gz-1": http.StatusNotFound,
+		"/results/../result.tar.gz":  http.StatusNotFound,
+		"/results/missing.tar.gz":    http.StatusNotFound,
+		"/other/result.tar.gz":       http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Authorization", "Bearer secret")
+		handler.ServeHTTP(w, r)
+		require.Equal(t, status, w.Code, path)
+	}
+}
+
+func TestHandlerRequiresToken(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "result.tar.gz"), []byte("result"), 0644))
+
+	for _, token := range []string{"", "secret"} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/results/result.tar.gz", nil)
+		r.Header.Set("Authorization", "Bearer ")
+		newHandler(dir, token).ServeHTTP(w, r)
+		require.Equal(t, http.StatusUnauthorized, w.Code, "token %q", token)
+	}
+}
+
+func TestNewPublisherGeneratesToken(t *testing.T) {
+	ctx := context.Background()
+	cm := system.NewCleanupManager()
+	t.Cleanup(func() { cm.Cleanup(ctx) })
+
+	dir := t.TempDir()
+	_, err := NewPublisher(ctx, cm, PublisherConfig{Directory: dir, Address: "127.0.0.1:0"})
+	require.NoError(t, err)
+	token, err := os.ReadFile(filepath.Join(dir, ".token"))
+	require.NoError(t, err)
+	require.Len(t, token, 64)
+
+	// the token is kept across restarts
+	_, err = NewPublisher(ctx, cm, PublisherConfig{Directory: dir, Address: "127.0.0.1:0"})
+	require.NoError(t, err)
+	again, err := os.ReadFile(filepath.Join(dir, ".token"))
+	require.NoError(t, err)
+	require.Equal(t, token, again)
+}
+
+func TestNewPublisherRequiresURLOnAllInterfaces(t *testing.T) {
+	ctx := context.Background()
+	cm := system.NewCleanupManager()
+	t.Cleanup(func() { cm.Cleanup(ctx) })
+
+	_, err := NewPublisher(ctx, cm, PublisherConfig{Directory: t.TempDir(), Address: ":0", Token: "secret"})
+	require.ErrorContains(t, err, "must be set")
+
+	publisher, err := NewPublisher(ctx, cm, PublisherConfig{
+		Directory: t.TempDir(), Address: ":0", Token: "secret", URL: "http://results.example.com",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "http://results.example.com", publisher.baseURL)
+}
@@ -5,12 +5,14 @@ import (
 	"time"
 
 	ipfsClient "github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs/pinning"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher/combo"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher/estuary"
 	filecoinlotus "github.com/bacalhau-project/bacalhau/pkg/publisher/filecoin_lotus"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/local"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher/noop"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher/tracing"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
@@ -22,10 +24,12 @@ func NewIPFSPublishers(
 	cl ipfsClient.Client,
 	estuaryAPIKey string,
 	lotusConfig *filecoinlotus.PublisherConfig,
+	localConfig *local.PublisherConfig,
+	pinningServices []pinning.Service,
 ) (publisher.PublisherProvider, error) {
 	defaultPriorityPublisherTimeout := time.Second * 2
 	noopPublisher := noop.NewNoopPublisher()
-	ipfsPublisher, err := ipfs.NewIPFSPublisher(ctx, cm, cl)
+	ipfsPublisher, err := ipfs.NewIPFSPublisher(ctx, cm, cl, pinning.NewPinner(pinningServices, cl))
 	if err != nil {
 		return nil, err
 	}
@@ -52,12 +56,23 @@ func NewIPFSPublishers(
 		}
 	}
 
-	return model.NewMappedProvider(map[model.Publisher]publisher.Publisher{
+	publishers := map[model.Publisher]publisher.Publisher{
 		model.PublisherNoop:     tracing.Wrap(noopPublisher),
 		model.PublisherIpfs:     tracing.Wrap(ipfsPublisher),
 		model.PublisherEstuary:  tracing.Wrap(estuaryPublisher),
 		model.PublisherFilecoin: combo.NewPiggybackedPublisher(tracing.Wrap(ipfsPublisher), tracing.Wrap(lotus)),
-	}), nil
+	}
+
+	// results are only kept on the node if it is configured to serve them
+	if localConfig != nil {
+		localPublisher, err := local.NewPublisher(ctx, cm, *localConfig)
+		if err != nil {
+			return nil, err
+		}
+		publishers[model.PublisherLocal] = tracing.Wrap(localPublisher)
+	}
+
+	return model.NewMappedProvider(publishers), nil
 }
 
 func NewNoopPublishers(
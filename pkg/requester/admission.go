@@ -0,0 +1,57 @@
+package requester
+
+import (
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// clusterCapacity is the capacity that the nodes suitable for a job advertise
+// between them, which bounds what the job can ever be given no matter how
+// long it waits.
+type clusterCapacity struct {
+	// Nodes is how many nodes are suitable for the job.
+	Nodes int
+	// Fitting is how many of the nodes could run an execution of the job if
+	// they were idle. Nodes that don't advertise their capacity are assumed to.
+	Fitting int
+	// Total is the most of each resource that the nodes have between them.
+	Total model.ResourceUsageData
+	// Largest is the most of each resource that any one of the nodes has.
+	Largest model.ResourceUsageData
+}
+
+// newClusterCapacity adds up the capacity that the nodes advertise for
+// executions with the usage.
+func newClusterCapacity(usage model.ResourceUsageData, nodes []NodeRank) clusterCapacity {
+	c := clusterCapacity{Nodes: len(nodes)}
+	for _, node := range nodes {
+		maxCapacity := node.NodeInfo.ComputeNodeInfo.MaxCapacity
+		if maxCapacity.IsZero() || usage.LessThanEq(maxCapacity) {
+			c.Fitting++
+		}
+		c.Total = c.Total.Add(maxCapacity)
+		c.Largest = c.Largest.Max(maxCapacity)
+	}
+	return c
+}
+
+// checkSchedulable returns an ErrUnschedulable if fewer than minBids of the
+// nodes could ever run an execution of the job, even when they are idle, so
+// that the job fails rather than waiting for capacity that will never free up.
+func checkSchedulable(job model.Job, nodes []NodeRank, minBids int) error {
+	usage := capacity.ParseResourceUsageConfig(job.Spec.Resources)
+	cluster := newClusterCapacity(usage, nodes)
+	if cluster.Fitting >= minBids {
+		return nil
+	}
+
+	reason := fmt.Sprintf("only %d of the %d suitable nodes have enough capacity for it", cluster.Fitting, cluster.Nodes)
+	if exceeded := exceededResources(usage, cluster.Largest); exceeded != "" {
+		reason = "each execution requests " + exceeded + " on the largest node"
+	} else if exceeded := exceededResources(usage.Multi(float64(minBids)), cluster.Total); exceeded != "" {
+		reason = fmt.Sprintf("its %d executions request ", minBids) + exceeded + " on all the nodes"
+	}
+	return NewErrUnschedulable(minBids, cluster.Fitting, reason)
+}
@@ -0,0 +1,38 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSchedulable(t *testing.T) {
+	node := func(cpu float64) NodeRank {
+		return NodeRank{NodeInfo: model.NodeInfo{ComputeNodeInfo: model.ComputeNodeInfo{
+			MaxCapacity: model.ResourceUsageData{CPU: cpu, Memory: 1 << 30, Disk: 1 << 30, GPU: 1},
+		}}}
+	}
+	job := func(cpu string) model.Job {
+		return model.Job{Spec: model.Spec{Resources: model.ResourceUsageConfig{CPU: cpu}}}
+	}
+	nodes := []NodeRank{node(2), node(4), node(8)}
+
+	require.NoError(t, checkSchedulable(job("1"), nodes, 3))
+	require.NoError(t, checkSchedulable(job("4"), nodes, 2))
+	require.NoError(t, checkSchedulable(job("64"), append(nodes, NodeRank{}), 1),
+		"nodes that don't advertise their capacity are assumed to have enough")
+
+	var unschedulable ErrUnschedulable
+	require.ErrorAs(t, checkSchedulable(job("4"), nodes, 3), &unschedulable)
+	require.Equal(t, NewErrUnschedulable(3, 2, "only 2 of the 3 suitable nodes have enough capacity for it"), unschedulable)
+
+	require.ErrorAs(t, checkSchedulable(job("6"), nodes, 3), &unschedulable)
+	require.Equal(t, "its 3 executions request 18 CPU when it can request 14 on all the nodes", unschedulable.Reason)
+
+	require.ErrorAs(t, checkSchedulable(job("16"), nodes, 1), &unschedulable)
+	require.Equal(t, 0, unschedulable.SchedulableNodes)
+	require.Contains(t, unschedulable.Reason, "16 CPU")
+}
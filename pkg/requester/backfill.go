@@ -0,0 +1,90 @@
+package requester
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultBackfillMaxDelay is how long the job at the front of the queue waits
+// for capacity before jobs stop being backfilled ahead of it, unless
+// configured otherwise.
+const DefaultBackfillMaxDelay = 10 * time.Minute
+
+// BackfillPolicy governs which jobs the queue starts in the capacity left over
+// while more urgent jobs wait for enough capacity, such as gangs that need
+// many nodes at once or high priority jobs that need large nodes. Only jobs
+// with a single execution and a short timeout are backfilled, as they give
+// the capacity back soon.
+type BackfillPolicy struct {
+	// MaxTimeout is the longest timeout that a job can have to be backfilled.
+	// Jobs are never backfilled if it is zero.
+	MaxTimeout time.Duration
+	// MaxDelay is how long the job at the front of the queue can wait for
+	// capacity before jobs stop being backfilled ahead of it, so that it gets
+	// the capacity that frees up. There is no limit if it is zero.
+	MaxDelay time.Duration
+}
+
+// Enabled returns whether jobs are backfilled at all.
+func (p BackfillPolicy) Enabled() bool {
+	return p.MaxTimeout > 0
+}
+
+// canBackfill returns whether the job is small and short enough to start
+// ahead of the jobs that were queued before it.
+func (p BackfillPolicy) canBackfill(job model.Job) bool {
+	timeout := job.Spec.GetTimeout()
+	if maxWallClock := time.Duration(job.Spec.MaxWallClock * float64(time.Second)); maxWallClock > 0 && maxWallClock < timeout {
+		timeout = maxWallClock
+	}
+	return p.Enabled() && !job.Spec.Deal.Gang && job.Spec.Deal.Concurrency <= 1 &&
+		timeout > 0 && timeout <= p.MaxTimeout
+}
+
+// backfill starts the jobs behind the job at the front of the queue that can be
+// backfilled and that there is capacity for, in the order they would start
+// in otherwise, while the job at the front waits for enough capacity. It
+// returns the error starting the job with the ID like dispatch does.
+// make sure to call this function with the lock held
+func (q *queue) backfill(ctx context.Context, head *queuedJob, jobID string) error {
+	if !q.backfillPolicy.Enabled() ||
+		(q.backfillPolicy.MaxDelay > 0 && time.Since(head.queuedAt) > q.backfillPolicy.MaxDelay) {
+		return nil
+	}
+
+	var candidates queuedJobs
+	for _, queued := range q.waiting {
+		if queued != head && q.backfillPolicy.canBackfill(queued.job) {
+			candidates = append(candidates, queued)
+		}
+	}
+	sort.Sort(candidates)
+
+	var result error
+	for _, candidate := range candidates {
+		if !q.canStart(ctx, candidate.job) {
+			candidate.holdForQuota()
+			continue
+		}
+		err := q.scheduler.StartJob(ctx, StartJobRequest{Job: candidate.job})
+		var noCapacity ErrNoCapacity
+		if errors.As(err, &noCapacity) {
+			candidate.reason, candidate.message = model.QueueReasonNoCapacity, err.Error()
+			continue
+		}
+		q.remove(candidate)
+		if candidate.job.Metadata.ID == jobID {
+			result = err
+		}
+		q.settle(ctx, candidate.job, jobID, err)
+		if err == nil {
+			log.Ctx(ctx).Debug().Msgf("backfilled job %s while job %s waits for capacity", candidate.job.Metadata.ID, head.job.Metadata.ID)
+		}
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanBackfill(t *testing.T) {
+	policy := BackfillPolicy{MaxTimeout: time.Minute}
+	job := func(timeout, maxWallClock float64, deal model.Deal) model.Job {
+		return model.Job{Spec: model.Spec{Timeout: timeout, MaxWallClock: maxWallClock, Deal: deal}}
+	}
+
+	require.True(t, policy.canBackfill(job(60, 0, model.Deal{Concurrency: 1})))
+	require.False(t, policy.canBackfill(job(61, 0, model.Deal{Concurrency: 1})))
+	require.True(t, policy.canBackfill(job(3600, 30, model.Deal{Concurrency: 1})), "the deadline of the job bounds how long it runs")
+	require.False(t, policy.canBackfill(job(30, 0, model.Deal{Concurrency: 2})))
+	require.False(t, policy.canBackfill(job(30, 0, model.Deal{Concurrency: 1, Gang: true})))
+	require.False(t, BackfillPolicy{}.canBackfill(job(30, 0, model.Deal{Concurrency: 1})), "jobs aren't backfilled unless enabled")
+}
+
+func TestQueueBackfillsSmallJobs(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+	var started []string
+	q := NewQueue(store, &mockScheduler{
+		handleStartJob: func(ctx context.Context, sjr StartJobRequest) error {
+			if sjr.Job.Spec.Deal.Concurrency > 1 || sjr.Job.Metadata.ID == "small-but-too-big" {
+				return NewErrNoCapacity(sjr.Job.Spec.Deal.Concurrency, 1)
+			}
+			started = append(started, sjr.Job.Metadata.ID)
+			return nil
+		},
+	}, nil, BackfillPolicy{MaxTimeout: time.Minute, MaxDelay: time.Hour}).(*queue)
+	t.Cleanup(func() {
+		if q.retry != nil {
+			q.retry.Stop()
+		}
+	})
+
+	for _, j := range []struct {
+		id          string
+		priority    model.Priority
+		timeout     float64
+		concurrency int
+	}{
+		{"gang", model.PriorityHigh, 3600, 3},
+		{"long", model.PriorityNormal, 3600, 1},
+		{"small-but-too-big", model.PriorityNormal, 60, 1},
+		{"small-low", model.PriorityLow, 60, 1},
+		{"small", model.PriorityNormal, 60, 1},
+	} {
+		job := model.Job{
+			Metadata: model.Metadata{ID: j.id},
+			Spec:     model.Spec{Priority: j.priority, Timeout: j.timeout, Deal: model.Deal{Concurrency: j.concurrency}},
+		}
+		require.NoError(t, store.CreateJob(ctx, job))
+		require.NoError(t, q.EnqueueJob(ctx, job))
+		require.NoError(t, q.StartJob(ctx, StartJobRequest{Job: job}))
+	}
+	require.Equal(t, []string{"small-low", "small"}, started, "small jobs start while the gang waits for capacity")
+	require.Equal(t, 3, q.waiting.Len(), "the gang and the jobs that can't be backfilled keep waiting")
+	require.Equal(t, "gang", q.waiting[0].job.Metadata.ID)
+
+	// once the job at the front has waited too long, nothing more is backfilled
+	q.mu.Lock()
+	q.waiting[0].queuedAt = time.Now().Add(-2 * time.Hour)
+	q.mu.Unlock()
+	job := model.Job{Metadata: model.Metadata{ID: "late"}, Spec: model.Spec{Timeout: 60, Deal: model.Deal{Concurrency: 1}}}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, q.EnqueueJob(ctx, job))
+	require.NoError(t, q.StartJob(ctx, StartJobRequest{Job: job}))
+	require.Equal(t, []string{"small-low", "small"}, started)
+	require.Equal(t, 4, q.waiting.Len())
+}
@@ -0,0 +1,216 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultCronInterval is how often the requester checks for schedules
+	// that are due, which is well within the minute that cron expressions
+	// are precise to.
+	DefaultCronInterval = 10 * time.Second
+
+	// DefaultKeepRuns is how many of their most recent runs schedules keep
+	// in their history if they don't say.
+	DefaultKeepRuns = 10
+)
+
+type CronParams struct {
+	Endpoint Endpoint
+	Store    jobstore.ScheduleStore
+	Interval time.Duration
+}
+
+// Cron submits the jobs of the schedules in the store each time their cron
+// expression fires. Runs that are missed while the requester is down are
+// skipped rather than caught up on.
+type Cron struct {
+	endpoint Endpoint
+	store    jobstore.ScheduleStore
+	interval time.Duration
+	// serializes changes to schedules between the API and the background task
+	mu sync.Mutex
+
+	stopChannel chan struct{}
+	stopOnce    sync.Once
+}
+
+func NewCron(params CronParams) *Cron {
+	c := &Cron{
+		endpoint:    params.Endpoint,
+		store:       params.Store,
+		interval:    params.Interval,
+		stopChannel: make(chan struct{}),
+	}
+	if c.interval == 0 {
+		c.interval = DefaultCronInterval
+	}
+
+	go c.cronBackgroundTask()
+	return c
+}
+
+func (c *Cron) cronBackgroundTask() {
+	ctx := context.Background()
+	ticker := time.NewTicker(c.interval)
+	for {
+		select {
+		case <-ticker.C:
+			c.runDue(ctx, time.Now())
+		case <-c.stopChannel:
+			log.Ctx(ctx).Debug().Msg("stopped cron task")
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// runDue submits the jobs of the schedules that are due at the time.
+func (c *Cron) runDue(ctx context.Context, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	schedules, err := c.store.GetSchedules(ctx, "")
+	if err != nil {
+		log.Ctx(ctx).Err(err).Msg("failed to get schedules")
+		return
+	}
+	for i := range schedules {
+		schedule := &schedules[i]
+		if schedule.Paused || schedule.NextRunAt.After(now) {
+			continue
+		}
+		c.submit(ctx, schedule, now, false)
+		schedule.NextRunAt, err = nextRun(schedule.Cron, now)
+		if err != nil {
+			log.Ctx(ctx).Err(err).Msgf("pausing schedule %s", schedule.ID)
+			schedule.Paused = true
+		}
+		if err = c.store.UpdateSchedule(ctx, *schedule); err != nil {
+			log.Ctx(ctx).Err(err).Msgf("failed to update schedule %s", schedule.ID)
+		}
+	}
+}
+
+// submit submits a job with the spec of the schedule and adds the run to its
+// history.
+func (c *Cron) submit(ctx context.Context, schedule *model.JobSchedule, now time.Time, triggered bool) {
+	run := model.ScheduleRun{RunAt: now, Triggered: triggered}
+	j, err := c.endpoint.SubmitJob(ctx, model.JobCreatePayload{
+		ClientID:   schedule.ClientID,
+		APIVersion: schedule.APIVersion,
+		Spec:       copySpec(schedule.Spec),
+	})
+	if err != nil {
+		log.Ctx(ctx).Err(err).Msgf("failed to submit job for schedule %s", schedule.ID)
+		run.Error = err.Error()
+	} else {
+		log.Ctx(ctx).Info().Msgf("submitted job %s for schedule %s", j.Metadata.ID, schedule.ID)
+		run.JobID = j.Metadata.ID
+	}
+	schedule.AddRun(run)
+}
+
+// copySpec copies the spec so that transforming the job doesn't change the
+// schedule.
+func copySpec(spec model.Spec) *model.Spec {
+	var result model.Spec
+	data, err := model.JSONMarshalWithMax(spec)
+	if err == nil {
+		err = model.JSONUnmarshalWithMax(data, &result)
+	}
+	if err != nil {
+		return &spec
+	}
+	return &result
+}
+
+func (c *Cron) GetSchedule(ctx context.Context, id string) (model.JobSchedule, error) {
+	return c.store.GetSchedule(ctx, id)
+}
+
+func (c *Cron) GetSchedules(ctx context.Context, clientID string) ([]model.JobSchedule, error) {
+	return c.store.GetSchedules(ctx, clientID)
+}
+
+func (c *Cron) CreateSchedule(ctx context.Context, payload model.ScheduleCreatePayload) (model.JobSchedule, error) {
+	if payload.Spec == nil {
+		return model.JobSchedule{}, fmt.Errorf("schedule must have a job spec")
+	}
+	if payload.KeepRuns < 0 {
+		return model.JobSchedule{}, fmt.Errorf("keep runs must be >= 0")
+	}
+	now := time.Now()
+	next, err := nextRun(payload.Cron, now)
+	if err != nil {
+		return model.JobSchedule{}, err
+	}
+	schedule := model.JobSchedule{
+		ID:         uuid.NewString(),
+		ClientID:   payload.ClientID,
+		Cron:       payload.Cron,
+		APIVersion: payload.APIVersion,
+		Spec:       *payload.Spec,
+		KeepRuns:   payload.KeepRuns,
+		CreatedAt:  now,
+		NextRunAt:  next,
+	}
+	if schedule.KeepRuns == 0 {
+		schedule.KeepRuns = DefaultKeepRuns
+	}
+	return schedule, c.store.CreateSchedule(ctx, schedule)
+}
+
+func (c *Cron) UpdateSchedule(ctx context.Context, payload model.ScheduleUpdatePayload) (model.JobSchedule, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	schedule, err := c.store.GetSchedule(ctx, payload.ScheduleID)
+	if err != nil {
+		return schedule, err
+	}
+
+	now := time.Now()
+	switch payload.Action {
+	case model.ScheduleActionPause:
+		schedule.Paused = true
+	case model.ScheduleActionResume:
+		schedule.Paused = false
+		schedule.NextRunAt, err = nextRun(schedule.Cron, now)
+		if err != nil {
+			return schedule, err
+		}
+	case model.ScheduleActionTrigger:
+		c.submit(ctx, &schedule, now, true)
+	case model.ScheduleActionDelete:
+		return schedule, c.store.DeleteSchedule(ctx, schedule.ID)
+	default:
+		return schedule, fmt.Errorf("unknown schedule action %q", payload.Action)
+	}
+	return schedule, c.store.UpdateSchedule(ctx, schedule)
+}
+
+func (c *Cron) Stop() {
+	c.stopOnce.Do(func() {
+		c.stopChannel <- struct{}{}
+	})
+}
+
+// nextRun returns the first time after the time that the cron expression fires.
+func nextRun(expression string, after time.Time) (time.Time, error) {
+	schedule, err := cron.ParseStandard(expression)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+	}
+	return schedule.Next(after.UTC()), nil
+}
+
+// compile-time check that Cron implements the expected interfaces
+var _ Schedules = (*Cron)(nil)
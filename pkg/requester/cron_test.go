@@ -0,0 +1,126 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEndpoint struct {
+	Endpoint
+	// store keeps the submitted jobs, if it's set
+	store     jobstore.Store
+	submitted []model.JobCreatePayload
+	cancelled []string
+	err       error
+}
+
+func (f *fakeEndpoint) SubmitJob(ctx context.Context, payload model.JobCreatePayload) (*model.Job, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.submitted = append(f.submitted, payload)
+	payload.Spec.Annotations = append(payload.Spec.Annotations, "transformed")
+	j := &model.Job{Metadata: model.Metadata{ID: fmt.Sprintf("job-%04d", len(f.submitted))}, Spec: *payload.Spec}
+	if f.store != nil {
+		if err := f.store.CreateJob(ctx, *j); err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+func (f *fakeEndpoint) CancelJob(_ context.Context, request CancelJobRequest) (CancelJobResult, error) {
+	f.cancelled = append(f.cancelled, request.JobID)
+	return CancelJobResult{}, nil
+}
+
+func TestCronSubmitsDueSchedules(t *testing.T) {
+	ctx := context.Background()
+	endpoint := &fakeEndpoint{}
+	c := &Cron{endpoint: endpoint, store: inmemory.NewJobStore()}
+
+	schedule, err := c.CreateSchedule(ctx, model.ScheduleCreatePayload{
+		ClientID: "client",
+		Cron:     "0 * * * *",
+		KeepRuns: 2,
+		Spec:     &model.Spec{Priority: model.PriorityHigh},
+	})
+	require.NoError(t, err)
+	require.Zero(t, schedule.NextRunAt.Minute())
+	require.True(t, schedule.NextRunAt.After(schedule.CreatedAt))
+
+	c.runDue(ctx, schedule.NextRunAt.Add(-time.Second))
+	require.Empty(t, endpoint.submitted, "schedules aren't run before they're due")
+
+	// a requester that was down for a few hours runs the schedule once
+	now := schedule.NextRunAt.Add(3*time.Hour + time.Minute)
+	c.runDue(ctx, now)
+	require.Len(t, endpoint.submitted, 1)
+	require.Equal(t, "client", endpoint.submitted[0].ClientID)
+	require.Equal(t, model.PriorityHigh, endpoint.submitted[0].Spec.Priority)
+
+	schedule, err = c.GetSchedule(ctx, schedule.ID)
+	require.NoError(t, err)
+	require.True(t, now.Truncate(time.Hour).Add(time.Hour).Equal(schedule.NextRunAt), "missed runs are skipped")
+	require.Empty(t, schedule.Spec.Annotations, "submitting the job doesn't change the schedule")
+	require.Len(t, schedule.History, 1)
+	require.Equal(t, "job-0001", schedule.History[0].JobID)
+
+	endpoint.err = errors.New("no nodes")
+	c.runDue(ctx, schedule.NextRunAt)
+	_, err = c.UpdateSchedule(ctx, model.ScheduleUpdatePayload{ScheduleID: schedule.ID, Action: model.ScheduleActionPause})
+	require.NoError(t, err)
+	c.runDue(ctx, schedule.NextRunAt.Add(time.Hour))
+
+	schedule, err = c.GetSchedule(ctx, schedule.ID)
+	require.NoError(t, err)
+	require.True(t, schedule.Paused)
+	require.Len(t, schedule.History, 2, "paused schedules aren't run")
+	require.Equal(t, "no nodes", schedule.History[1].Error)
+}
+
+func TestCronUpdateSchedule(t *testing.T) {
+	ctx := context.Background()
+	endpoint := &fakeEndpoint{}
+	c := &Cron{endpoint: endpoint, store: inmemory.NewJobStore()}
+
+	_, err := c.CreateSchedule(ctx, model.ScheduleCreatePayload{Cron: "not cron", Spec: &model.Spec{}})
+	require.Error(t, err)
+
+	schedule, err := c.CreateSchedule(ctx, model.ScheduleCreatePayload{Cron: "@daily", Spec: &model.Spec{}})
+	require.NoError(t, err)
+	require.Equal(t, DefaultKeepRuns, schedule.KeepRuns)
+
+	triggered, err := c.UpdateSchedule(ctx, model.ScheduleUpdatePayload{ScheduleID: schedule.ID, Action: model.ScheduleActionTrigger})
+	require.NoError(t, err)
+	require.Len(t, endpoint.submitted, 1)
+	require.Len(t, triggered.History, 1)
+	require.True(t, triggered.History[0].Triggered)
+	require.Equal(t, schedule.NextRunAt, triggered.NextRunAt, "triggering doesn't change when the schedule next runs")
+
+	paused, err := c.UpdateSchedule(ctx, model.ScheduleUpdatePayload{ScheduleID: schedule.ID, Action: model.ScheduleActionPause})
+	require.NoError(t, err)
+	require.True(t, paused.Paused)
+	resumed, err := c.UpdateSchedule(ctx, model.ScheduleUpdatePayload{ScheduleID: schedule.ID, Action: model.ScheduleActionResume})
+	require.NoError(t, err)
+	require.False(t, resumed.Paused)
+
+	_, err = c.UpdateSchedule(ctx, model.ScheduleUpdatePayload{ScheduleID: schedule.ID, Action: "archive"})
+	require.Error(t, err)
+
+	_, err = c.UpdateSchedule(ctx, model.ScheduleUpdatePayload{ScheduleID: schedule.ID, Action: model.ScheduleActionDelete})
+	require.NoError(t, err)
+	schedules, err := c.GetSchedules(ctx, "")
+	require.NoError(t, err)
+	require.Empty(t, schedules)
+}
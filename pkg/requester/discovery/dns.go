@@ -0,0 +1,182 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/bacalhau-project/bacalhau/pkg/routing"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultDNSRefreshInterval is how long the nodes resolved from DNS are
+	// kept before the name is resolved again.
+	DefaultDNSRefreshInterval = time.Minute
+
+	// dnsAddrPrefix marks the TXT records of the name that hold the
+	// multiaddress of a node, as in libp2p's dnsaddr.
+	dnsAddrPrefix = "dnsaddr="
+	// dnsPeerPrefix marks the TXT record of an SRV target that holds the peer
+	// id of the node at the target.
+	dnsPeerPrefix = "peer="
+	// dnsService is the SRV service that nodes are resolved from, as in
+	// _bacalhau._tcp.<name>.
+	dnsService = "bacalhau"
+)
+
+// DNSResolver resolves the records that nodes are discovered from, which
+// net.Resolver does.
+type DNSResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+type DNSNodeDiscovererParams struct {
+	// Name is the DNS name that the nodes are resolved from
+	Name string
+	// RefreshInterval is how long the resolved nodes are kept, DefaultDNSRefreshInterval if zero
+	RefreshInterval time.Duration
+	// Resolver resolves the records, net.DefaultResolver if nil
+	Resolver DNSResolver
+	// Host is given the addresses of the resolved nodes to dial them, if set
+	Host host.Host
+	// Store fills in what the resolved nodes advertised about themselves, if set
+	Store routing.NodeInfoStore
+}
+
+// DNSNodeDiscoverer finds the compute nodes that a DNS name resolves to, for
+// clusters that run behind standard service discovery. The nodes are:
+//   - the multiaddresses in the TXT records of the name that start with
+//     dnsaddr=, e.g. dnsaddr=/ip4/10.0.0.1/tcp/1235/p2p/QmNode
+//   - the targets of the SRV records of _bacalhau._tcp.<name>, whose peer ids
+//     are in the TXT records of each target that start with peer=
+type DNSNodeDiscoverer struct {
+	name            string
+	refreshInterval time.Duration
+	resolver        DNSResolver
+	host            host.Host
+	store           routing.NodeInfoStore
+
+	mu         sync.Mutex
+	resolvedAt time.Time
+	nodes      []model.NodeInfo
+}
+
+func NewDNSNodeDiscoverer(params DNSNodeDiscovererParams) *DNSNodeDiscoverer {
+	d := &DNSNodeDiscoverer{
+		name:            params.Name,
+		refreshInterval: params.RefreshInterval,
+		resolver:        params.Resolver,
+		host:            params.Host,
+		store:           params.Store,
+	}
+	if d.refreshInterval == 0 {
+		d.refreshInterval = DefaultDNSRefreshInterval
+	}
+	if d.resolver == nil {
+		d.resolver = net.DefaultResolver
+	}
+	return d
+}
+
+// FindNodes returns the nodes the name resolves to that support the job's execution engine, or that didn't say
+// which engines they support. The nodes the name last resolved to are kept if it then fails to resolve.
+func (d *DNSNodeDiscoverer) FindNodes(ctx context.Context, job model.Job) ([]model.NodeInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if time.Since(d.resolvedAt) >= d.refreshInterval {
+		nodes, err := d.resolve(ctx)
+		if err != nil {
+			if d.resolvedAt.IsZero() {
+				return nil, err
+			}
+			log.Ctx(ctx).Warn().Err(err).Msgf("keeping the nodes that %s last resolved to", d.name)
+		} else {
+			d.nodes = nodes
+			d.resolvedAt = time.Now()
+		}
+	}
+	return nodesForJob(ctx, d.store, d.nodes, job), nil
+}
+
+func (d *DNSNodeDiscoverer) resolve(ctx context.Context) ([]model.NodeInfo, error) {
+	var addresses []string
+	txtRecords, txtErr := d.resolver.LookupTXT(ctx, d.name)
+	for _, record := range txtRecords {
+		if address, ok := strings.CutPrefix(record, dnsAddrPrefix); ok {
+			addresses = append(addresses, address)
+		}
+	}
+
+	_, srvRecords, srvErr := d.resolver.LookupSRV(ctx, dnsService, "tcp", d.name)
+	for _, record := range srvRecords {
+		target := strings.TrimSuffix(record.Target, ".")
+		peerID, err := d.resolvePeerID(ctx, target)
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msgf("skipping SRV target %s of %s", target, d.name)
+			continue
+		}
+		addresses = append(addresses, fmt.Sprintf("/dns/%s/tcp/%d/p2p/%s", target, record.Port, peerID))
+	}
+
+	if txtErr != nil && srvErr != nil {
+		return nil, fmt.Errorf("failed to resolve nodes from %s: %w", d.name, txtErr)
+	}
+
+	var nodes []model.NodeInfo
+	for _, address := range addresses {
+		// skip the records that are invalid rather than losing all the nodes
+		infos, err := parseNodeAddresses([]string{address})
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msgf("skipping node record of %s", d.name)
+			continue
+		}
+		nodes = appendNodeAddrs(nodes, infos[0])
+	}
+	if d.host != nil {
+		for _, node := range nodes {
+			d.host.Peerstore().AddAddrs(node.PeerInfo.ID, node.PeerInfo.Addrs, peerstore.TempAddrTTL+d.refreshInterval)
+		}
+	}
+	return nodes, nil
+}
+
+// resolvePeerID returns the peer id in the TXT records of the SRV target.
+func (d *DNSNodeDiscoverer) resolvePeerID(ctx context.Context, target string) (peer.ID, error) {
+	records, err := d.resolver.LookupTXT(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	for _, record := range records {
+		if id, ok := strings.CutPrefix(record, dnsPeerPrefix); ok {
+			return peer.Decode(id)
+		}
+	}
+	return "", fmt.Errorf("no %s TXT record", dnsPeerPrefix)
+}
+
+// appendNodeAddrs adds the addresses to the node of the same peer id, or a new node if there is none.
+func appendNodeAddrs(nodes []model.NodeInfo, info peer.AddrInfo) []model.NodeInfo {
+	for i := range nodes {
+		if nodes[i].PeerInfo.ID == info.ID {
+			nodes[i].PeerInfo.Addrs = append(nodes[i].PeerInfo.Addrs, info.Addrs...)
+			return nodes
+		}
+	}
+	return append(nodes, model.NodeInfo{
+		PeerInfo: info,
+		NodeType: model.NodeTypeCompute,
+	})
+}
+
+// compile time check that DNSNodeDiscoverer implements NodeDiscoverer
+var _ requester.NodeDiscoverer = (*DNSNodeDiscoverer)(nil)
@@ -0,0 +1,85 @@
+//go:build unit || !integration
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResolver struct {
+	txt map[string][]string
+	srv map[string][]*net.SRV
+}
+
+func (r *fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	records, ok := r.txt[name]
+	if !ok {
+		return nil, fmt.Errorf("no such host %s", name)
+	}
+	return records, nil
+}
+
+func (r *fakeResolver) LookupSRV(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	records, ok := r.srv[name]
+	if !ok {
+		return "", nil, fmt.Errorf("no such host _%s._%s.%s", service, proto, name)
+	}
+	return "", records, nil
+}
+
+func TestDNSNodeDiscoverer(t *testing.T) {
+	ctx := context.Background()
+	resolver := &fakeResolver{
+		txt: map[string][]string{
+			"nodes.example.com": {
+				"v=spf1 -all",
+				"dnsaddr=/ip4/10.0.0.1/tcp/1235/p2p/" + catalogNode1,
+				"dnsaddr=/ip6/::1/tcp/1235/p2p/" + catalogNode1,
+				"dnsaddr=/ip4/10.0.0.3/tcp/1235",
+			},
+			"node2.example.com":   {"peer=" + catalogNode2},
+			"unknown.example.com": {},
+		},
+		srv: map[string][]*net.SRV{
+			"nodes.example.com": {
+				{Target: "node2.example.com.", Port: 1235},
+				{Target: "unknown.example.com.", Port: 1235},
+			},
+		},
+	}
+	discoverer := NewDNSNodeDiscoverer(DNSNodeDiscovererParams{
+		Name:            "nodes.example.com",
+		RefreshInterval: time.Hour,
+		Resolver:        resolver,
+	})
+
+	nodes, err := discoverer.FindNodes(ctx, model.Job{Spec: model.Spec{Engine: model.EngineDocker}})
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	require.Equal(t, catalogNode1, nodes[0].PeerInfo.ID.String())
+	require.Len(t, nodes[0].PeerInfo.Addrs, 2)
+	require.Equal(t, catalogNode2, nodes[1].PeerInfo.ID.String())
+	require.Equal(t, "/dns/node2.example.com/tcp/1235", nodes[1].PeerInfo.Addrs[0].String())
+
+	// the resolved nodes are kept until the refresh interval passes
+	delete(resolver.txt, "nodes.example.com")
+	nodes, err = discoverer.FindNodes(ctx, model.Job{})
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+}
+
+func TestDNSNodeDiscovererUnresolved(t *testing.T) {
+	discoverer := NewDNSNodeDiscoverer(DNSNodeDiscovererParams{
+		Name:     "missing.example.com",
+		Resolver: &fakeResolver{},
+	})
+	_, err := discoverer.FindNodes(context.Background(), model.Job{})
+	require.ErrorContains(t, err, "failed to resolve nodes from missing.example.com")
+}
@@ -0,0 +1,181 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/bacalhau-project/bacalhau/pkg/routing"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/slices"
+	"sigs.k8s.io/yaml"
+)
+
+// NodeCatalog is the file of the compute nodes that a static node discoverer
+// finds, in YAML or JSON.
+type NodeCatalog struct {
+	Nodes []NodeCatalogEntry `json:"Nodes"`
+}
+
+// NodeCatalogEntry is a compute node in the catalog.
+type NodeCatalogEntry struct {
+	// Addresses are the multiaddresses of the node, each ending with its
+	// peer id, e.g. /ip4/10.0.0.1/tcp/1235/p2p/QmNode
+	Addresses []string `json:"Addresses"`
+	// Engines are the execution engines the node supports, or all if empty
+	Engines []model.Engine `json:"Engines,omitempty"`
+	// Labels are the labels of the node
+	Labels map[string]string `json:"Labels,omitempty"`
+}
+
+type StaticNodeDiscovererParams struct {
+	// Path is the node catalog file, which is reloaded when it changes
+	Path string
+	// Host is given the addresses of the nodes in the catalog to dial them, if set
+	Host host.Host
+	// Store fills in what the nodes in the catalog advertised about themselves, if set
+	Store routing.NodeInfoStore
+}
+
+// StaticNodeDiscoverer finds the compute nodes listed in a catalog file, for
+// private clusters that don't discover their nodes from the network. The file
+// is reloaded when it changes, and the last catalog that loaded is kept if it
+// then fails to.
+type StaticNodeDiscoverer struct {
+	path  string
+	host  host.Host
+	store routing.NodeInfoStore
+
+	mu      sync.Mutex
+	modTime time.Time
+	nodes   []model.NodeInfo
+}
+
+func NewStaticNodeDiscoverer(params StaticNodeDiscovererParams) (*StaticNodeDiscoverer, error) {
+	d := &StaticNodeDiscoverer{
+		path:  params.Path,
+		host:  params.Host,
+		store: params.Store,
+	}
+	if err := d.reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// FindNodes returns the nodes in the catalog that support the job's execution engine, or that didn't say which
+// engines they support.
+func (d *StaticNodeDiscoverer) FindNodes(ctx context.Context, job model.Job) ([]model.NodeInfo, error) {
+	if err := d.reload(); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msgf("keeping the previous node catalog as %s failed to reload", d.path)
+	}
+
+	d.mu.Lock()
+	catalog := d.nodes
+	d.mu.Unlock()
+	return nodesForJob(ctx, d.store, catalog, job), nil
+}
+
+// nodesForJob returns the nodes that support the job's execution engine, or that didn't say which engines they
+// support, with what they advertised about themselves in the store, if set.
+func nodesForJob(ctx context.Context, store routing.NodeInfoStore, candidates []model.NodeInfo, job model.Job) []model.NodeInfo {
+	var nodes []model.NodeInfo
+	for _, node := range candidates {
+		if store != nil {
+			advertised, err := store.Get(ctx, node.PeerInfo.ID)
+			if err == nil {
+				advertised.PeerInfo = node.PeerInfo
+				node = advertised
+			}
+		}
+		engines := node.ComputeNodeInfo.ExecutionEngines
+		if len(engines) == 0 || slices.Contains(engines, job.Spec.Engine) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// reload loads the catalog again if the file changed since it was last loaded.
+func (d *StaticNodeDiscoverer) reload() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return fmt.Errorf("failed to read node catalog %s: %w", d.path, err)
+	}
+	if info.ModTime().Equal(d.modTime) {
+		return nil
+	}
+	content, err := os.ReadFile(d.path)
+	if err != nil {
+		return fmt.Errorf("failed to read node catalog %s: %w", d.path, err)
+	}
+	nodes, err := parseNodeCatalog(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse node catalog %s: %w", d.path, err)
+	}
+	if d.host != nil {
+		for _, node := range nodes {
+			d.host.Peerstore().AddAddrs(node.PeerInfo.ID, node.PeerInfo.Addrs, peerstore.PermanentAddrTTL)
+		}
+	}
+	d.nodes = nodes
+	d.modTime = info.ModTime()
+	return nil
+}
+
+func parseNodeCatalog(content []byte) ([]model.NodeInfo, error) {
+	var catalog NodeCatalog
+	if err := yaml.Unmarshal(content, &catalog); err != nil {
+		return nil, err
+	}
+	nodes := make([]model.NodeInfo, 0, len(catalog.Nodes))
+	for i, entry := range catalog.Nodes {
+		infos, err := parseNodeAddresses(entry.Addresses)
+		if err != nil {
+			return nil, fmt.Errorf("node %d: %w", i, err)
+		}
+		if len(infos) != 1 {
+			return nil, fmt.Errorf("node %d must have addresses of one peer id, rather than %d", i, len(infos))
+		}
+		nodes = append(nodes, model.NodeInfo{
+			PeerInfo: infos[0],
+			NodeType: model.NodeTypeCompute,
+			Labels:   entry.Labels,
+			ComputeNodeInfo: model.ComputeNodeInfo{
+				ExecutionEngines: entry.Engines,
+			},
+		})
+	}
+	return nodes, nil
+}
+
+// parseNodeAddresses parses multiaddresses that end with peer ids into the addresses of each peer.
+func parseNodeAddresses(addresses []string) ([]peer.AddrInfo, error) {
+	addrs := make([]multiaddr.Multiaddr, 0, len(addresses))
+	for _, address := range addresses {
+		addr, err := multiaddr.NewMultiaddr(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", address, err)
+		}
+		addrs = append(addrs, addr)
+	}
+	infos, err := peer.AddrInfosFromP2pAddrs(addrs...)
+	if err != nil {
+		return nil, fmt.Errorf("address without its peer id: %w", err)
+	}
+	return infos, nil
+}
+
+// compile time check that StaticNodeDiscoverer implements NodeDiscoverer
+var _ requester.NodeDiscoverer = (*StaticNodeDiscoverer)(nil)
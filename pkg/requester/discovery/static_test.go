@@ -0,0 +1,85 @@
+//go:build unit || !integration
+
+package discovery
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/routing/inmemory"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	catalogNode1 = "QmYyQSo1c1Ym7orWxLYvCrM2EmxFTANf8wXmmE7DWjhx5N"
+	catalogNode2 = "QmcfgsJsMtx6qJb74akCw1M24X1zFwgGo11h1cuhwQjtJP"
+)
+
+func writeCatalog(t *testing.T, path string, content string, modTime time.Time) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestStaticNodeDiscoverer(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nodes.yaml")
+	now := time.Now()
+	writeCatalog(t, path, `
+Nodes:
+  - Addresses: [/ip4/10.0.0.1/tcp/1235/p2p/`+catalogNode1+`]
+    Engines: [Docker]
+    Labels: {reserved: teamA}
+  - Addresses: [/ip4/10.0.0.2/tcp/1235/p2p/`+catalogNode2+`]
+`, now)
+
+	store := inmemory.NewNodeInfoStore(inmemory.NodeInfoStoreParams{TTL: math.MaxInt64})
+	discoverer, err := NewStaticNodeDiscoverer(StaticNodeDiscovererParams{Path: path, Store: store})
+	require.NoError(t, err)
+
+	nodes, err := discoverer.FindNodes(ctx, model.Job{Spec: model.Spec{Engine: model.EngineDocker}})
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	require.Equal(t, map[string]string{"reserved": "teamA"}, nodes[0].Labels)
+
+	// node1 only runs docker jobs, and node2 advertised that it doesn't run wasm jobs either
+	node2, err := peer.Decode(catalogNode2)
+	require.NoError(t, err)
+	require.NoError(t, store.Add(ctx, model.NodeInfo{
+		PeerInfo:        peer.AddrInfo{ID: node2},
+		NodeType:        model.NodeTypeCompute,
+		ComputeNodeInfo: model.ComputeNodeInfo{ExecutionEngines: []model.Engine{model.EngineDocker}},
+	}))
+	nodes, err = discoverer.FindNodes(ctx, model.Job{Spec: model.Spec{Engine: model.EngineWasm}})
+	require.NoError(t, err)
+	require.Empty(t, nodes)
+
+	// the catalog is reloaded when it changes
+	writeCatalog(t, path, `{"Nodes": [{"Addresses": ["/ip4/10.0.0.2/tcp/1235/p2p/`+catalogNode2+`"]}]}`, now.Add(time.Minute))
+	nodes, err = discoverer.FindNodes(ctx, model.Job{Spec: model.Spec{Engine: model.EngineDocker}})
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	require.Equal(t, node2, nodes[0].PeerInfo.ID)
+	require.Len(t, nodes[0].PeerInfo.Addrs, 1, "the addresses come from the catalog")
+
+	// and the last catalog is kept if the change is invalid
+	writeCatalog(t, path, `Nodes: [{Addresses: [/ip4/10.0.0.3/tcp/1235]}]`, now.Add(2*time.Minute))
+	nodes, err = discoverer.FindNodes(ctx, model.Job{Spec: model.Spec{Engine: model.EngineDocker}})
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+}
+
+func TestStaticNodeDiscovererInvalidCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes.yaml")
+	writeCatalog(t, path, `Nodes: [{Addresses: [/ip4/10.0.0.1/tcp/1235]}]`, time.Now())
+	_, err := NewStaticNodeDiscoverer(StaticNodeDiscovererParams{Path: path})
+	require.ErrorContains(t, err, "node 0: address without its peer id")
+
+	_, err = NewStaticNodeDiscoverer(StaticNodeDiscovererParams{Path: filepath.Join(t.TempDir(), "missing.yaml")})
+	require.ErrorContains(t, err, "failed to read node catalog")
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/requester/jobtransform"
@@ -29,6 +30,16 @@ type BaseEndpointParams struct {
 	StorageProviders           storage.StorageProvider
 	MinJobExecutionTimeout     time.Duration
 	DefaultJobExecutionTimeout time.Duration
+	// MaxHighPriorityJobsPerClient is the most high priority jobs that each
+	// client can have in flight, or zero for no limit
+	MaxHighPriorityJobsPerClient int
+	// Quotas limit what each client can submit and run, or nil for no limits
+	Quotas *QuotaManager
+	// Backfill governs which jobs start ahead of jobs waiting for capacity
+	Backfill BackfillPolicy
+	// RequireClientSignatures rejects jobs that aren't signed by their
+	// client, such as the runs of schedules and the stages of workflows
+	RequireClientSignatures bool
 }
 
 // BaseEndpoint base implementation of requester Endpoint
@@ -38,6 +49,10 @@ type BaseEndpoint struct {
 	store      jobstore.Store
 	selector   bidstrategy.BidStrategy
 	transforms []jobtransform.Transformer
+	quotas     *QuotaManager
+
+	maxHighPriorityJobsPerClient int
+	requireClientSignatures      bool
 }
 
 func NewBaseEndpoint(params *BaseEndpointParams) *BaseEndpoint {
@@ -45,15 +60,20 @@ func NewBaseEndpoint(params *BaseEndpointParams) *BaseEndpoint {
 		jobtransform.NewInlineStoragePinner(params.StorageProviders),
 		jobtransform.NewTimeoutApplier(params.MinJobExecutionTimeout, params.DefaultJobExecutionTimeout),
 		jobtransform.NewRequesterInfo(params.ID, params.PublicKey),
+		jobtransform.NewReplicationApplier(params.Verifiers),
 	}
 
-	queue := NewQueue(params.Store, params.Scheduler)
+	queue := NewQueue(params.Store, params.Scheduler, params.Quotas, params.Backfill)
 	return &BaseEndpoint{
 		id:         params.ID,
 		queue:      queue,
 		selector:   params.Selector,
 		store:      params.Store,
 		transforms: transforms,
+		quotas:     params.Quotas,
+
+		maxHighPriorityJobsPerClient: params.MaxHighPriorityJobsPerClient,
+		requireClientSignatures:      params.RequireClientSignatures,
 	}
 }
 
@@ -63,6 +83,11 @@ func (node *BaseEndpoint) SubmitJob(ctx context.Context, data model.JobCreatePay
 		return &model.Job{}, fmt.Errorf("error creating job id: %w", err)
 	}
 	jobID := jobUUID.String()
+	if data.ClientSignature != nil {
+		// the ID of a signed job is derived from its payload, so that the
+		// signature can't be reused for another job
+		jobID = jobutils.SignedJobID(data.ClientSignature.Payload)
+	}
 
 	// Creates a new root context to track a job's lifecycle for tracing. This
 	// should be fine as only one node will call SubmitJob(...) - the other
@@ -87,9 +112,10 @@ func (node *BaseEndpoint) SubmitJob(ctx context.Context, data model.JobCreatePay
 	job := &model.Job{
 		APIVersion: data.APIVersion,
 		Metadata: model.Metadata{
-			ID:        jobID,
-			ClientID:  data.ClientID,
-			CreatedAt: time.Now(),
+			ID:              jobID,
+			ClientID:        data.ClientID,
+			ClientSignature: data.ClientSignature,
+			CreatedAt:       time.Now(),
 		},
 		Spec: *data.Spec,
 	}
@@ -101,6 +127,24 @@ func (node *BaseEndpoint) SubmitJob(ctx context.Context, data model.JobCreatePay
 		}
 	}
 
+	// the job is checked as compute nodes check it, once it is transformed
+	err = node.checkClientSignature(*job)
+	if err != nil {
+		return job, err
+	}
+
+	err = node.checkHighPriorityLimit(ctx, *job)
+	if err != nil {
+		return job, err
+	}
+
+	if node.quotas != nil {
+		err = node.quotas.CheckSubmission(ctx, *job)
+		if err != nil {
+			return job, err
+		}
+	}
+
 	err = node.store.CreateJob(ctx, *job)
 	if err != nil {
 		return job, err
@@ -120,6 +164,37 @@ func (node *BaseEndpoint) SubmitJob(ctx context.Context, data model.JobCreatePay
 	return job, node.handleBidResponse(ctx, *job, response)
 }
 
+// checkClientSignature returns an error if the job is signed by someone other than its client, or if it isn't signed
+// when the requester requires jobs to be.
+func (node *BaseEndpoint) checkClientSignature(job model.Job) error {
+	if job.Metadata.ClientSignature == nil && !node.requireClientSignatures {
+		return nil
+	}
+	return jobutils.VerifyClientSignature(job)
+}
+
+// checkHighPriorityLimit returns an error if the job is high priority and its
+// client already has as many high priority jobs in flight as it can have.
+func (node *BaseEndpoint) checkHighPriorityLimit(ctx context.Context, job model.Job) error {
+	if job.Spec.Priority < model.PriorityHigh || node.maxHighPriorityJobsPerClient <= 0 {
+		return nil
+	}
+	inProgress, err := node.store.GetInProgressJobs(ctx)
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, j := range inProgress {
+		if j.Job.Metadata.ClientID == job.Metadata.ClientID && j.Job.Spec.Priority >= model.PriorityHigh {
+			count++
+		}
+	}
+	if count >= node.maxHighPriorityJobsPerClient {
+		return fmt.Errorf("client already has %d high priority jobs in flight, which is the most it can have", count)
+	}
+	return nil
+}
+
 func (node *BaseEndpoint) ApproveJob(ctx context.Context, approval ApproveJobRequest) error {
 	// We deliberately expect this to be the empty string if unset. This is so
 	// that if this env variable is (accidentally) left unset, no jobs can be
@@ -141,6 +216,11 @@ func (node *BaseEndpoint) CancelJob(ctx context.Context, request CancelJobReques
 	return node.queue.CancelJob(ctx, request)
 }
 
+// GetQueuedJobs returns the jobs that wait in the queue to start.
+func (node *BaseEndpoint) GetQueuedJobs(ctx context.Context) ([]model.QueuedJob, error) {
+	return node.queue.GetQueuedJobs(ctx)
+}
+
 func (node *BaseEndpoint) handleBidResponse(ctx context.Context, job model.Job, response bidstrategy.BidStrategyResponse) error {
 	if response.ShouldWait {
 		return nil
@@ -160,3 +240,4 @@ func (node *BaseEndpoint) handleBidResponse(ctx context.Context, job model.Job,
 
 // Compile-time interface check:
 var _ Endpoint = (*BaseEndpoint)(nil)
+var _ QueueInfo = (*BaseEndpoint)(nil)
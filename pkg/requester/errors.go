@@ -23,6 +23,24 @@ func (e ErrNotEnoughNodes) Error() string {
 	return fmt.Sprintf("not enough nodes to run job. requested: %d, available: %d", e.RequestedNodes, e.AvailableNodes)
 }
 
+// ErrNoCapacity is returned when there are enough nodes in the network to run a
+// job, but not enough of them have the capacity to run it now
+type ErrNoCapacity struct {
+	RequestedNodes int
+	AvailableNodes int
+}
+
+func NewErrNoCapacity(requestedNodes, availableNodes int) ErrNoCapacity {
+	return ErrNoCapacity{
+		RequestedNodes: requestedNodes,
+		AvailableNodes: availableNodes,
+	}
+}
+
+func (e ErrNoCapacity) Error() string {
+	return fmt.Sprintf("not enough nodes with capacity to run job. requested: %d, available: %d", e.RequestedNodes, e.AvailableNodes)
+}
+
 // ErrNodeNotFound is returned when nodeInfo was not found for a requested peer id
 type ErrNodeNotFound struct {
 	peerID peer.ID
@@ -47,3 +65,39 @@ func NewErrJobAlreadyTerminal(jobID string) ErrJobAlreadyTerminal {
 func (e ErrJobAlreadyTerminal) Error() string {
 	return fmt.Errorf("job %s is already in a terminal state", e.JobID).Error()
 }
+
+// ErrQuotaExceeded is returned when a client submits a job beyond its quota
+type ErrQuotaExceeded struct {
+	ClientID string
+	Reason   string
+}
+
+func NewErrQuotaExceeded(clientID, reason string) ErrQuotaExceeded {
+	return ErrQuotaExceeded{ClientID: clientID, Reason: reason}
+}
+
+func (e ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("client %s exceeded its quota: %s", e.ClientID, e.Reason)
+}
+
+// ErrUnschedulable is returned when a job requests more resources than enough
+// of the nodes in the network have, so it could never run however long it
+// waited for capacity
+type ErrUnschedulable struct {
+	RequestedNodes   int
+	SchedulableNodes int
+	Reason           string
+}
+
+func NewErrUnschedulable(requestedNodes, schedulableNodes int, reason string) ErrUnschedulable {
+	return ErrUnschedulable{
+		RequestedNodes:   requestedNodes,
+		SchedulableNodes: schedulableNodes,
+		Reason:           reason,
+	}
+}
+
+func (e ErrUnschedulable) Error() string {
+	return fmt.Sprintf("job is unschedulable because %s. requested: %d, schedulable: %d",
+		e.Reason, e.RequestedNodes, e.SchedulableNodes)
+}
@@ -2,25 +2,30 @@ package requester
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/rs/zerolog/log"
 )
 
 type HousekeepingParams struct {
 	Endpoint Endpoint
 	JobStore jobstore.Store
-	NodeID   string
-	Interval time.Duration
+	// ExecutionMonitor optionally checks on the executions of the jobs in progress
+	ExecutionMonitor ExecutionMonitor
+	NodeID           string
+	Interval         time.Duration
 }
 
 type Housekeeping struct {
-	endpoint Endpoint
-	jobStore jobstore.Store
-	nodeID   string
-	interval time.Duration
+	endpoint         Endpoint
+	jobStore         jobstore.Store
+	executionMonitor ExecutionMonitor
+	nodeID           string
+	interval         time.Duration
 
 	stopChannel chan struct{}
 	stopOnce    sync.Once
@@ -28,11 +33,12 @@ type Housekeeping struct {
 
 func NewHousekeeping(params HousekeepingParams) *Housekeeping {
 	h := &Housekeeping{
-		endpoint:    params.Endpoint,
-		jobStore:    params.JobStore,
-		nodeID:      params.NodeID,
-		interval:    params.Interval,
-		stopChannel: make(chan struct{}),
+		endpoint:         params.Endpoint,
+		jobStore:         params.JobStore,
+		executionMonitor: params.ExecutionMonitor,
+		nodeID:           params.NodeID,
+		interval:         params.Interval,
+		stopChannel:      make(chan struct{}),
 	}
 
 	go h.housekeepingBackgroundTask()
@@ -56,18 +62,21 @@ func (h *Housekeeping) housekeepingBackgroundTask() {
 				if jobDescription.Job.Metadata.Requester.RequesterNodeID != h.nodeID {
 					continue
 				}
-				// cancel jobs that have been in progress beyond the timeout period
-				if now.Sub(jobDescription.State.CreateTime).Seconds() > jobDescription.Job.Spec.Timeout {
-					log.Ctx(ctx).Info().Msgf("job %s timed out. Canceling", jobDescription.Job.Metadata.ID)
+				// cancel jobs that have been in progress beyond the timeout period or their max wall clock
+				if reason, ok := timedOut(jobDescription, now); ok {
+					log.Ctx(ctx).Info().Msgf("job %s %s. Canceling", jobDescription.Job.Metadata.ID, reason)
 					go func(jobID string) {
 						_, innerErr := h.endpoint.CancelJob(ctx, CancelJobRequest{
-							JobID:  jobID,
-							Reason: "timed out",
+							JobID:    jobID,
+							Reason:   reason,
+							TimedOut: true,
 						})
 						if innerErr != nil {
 							log.Ctx(ctx).Err(innerErr).Msgf("failed to cancel job %s", jobID)
 						}
 					}(jobDescription.Job.Metadata.ID)
+				} else if h.executionMonitor != nil {
+					h.executionMonitor.CheckExecutions(ctx, jobDescription)
 				}
 			}
 		case <-h.stopChannel:
@@ -78,6 +87,19 @@ func (h *Housekeeping) housekeepingBackgroundTask() {
 	}
 }
 
+// timedOut returns why the job has run out of time if it has, which is when
+// it has been in progress for longer than its timeout or its max wall clock.
+func timedOut(jobDescription model.JobWithInfo, now time.Time) (string, bool) {
+	elapsed := now.Sub(jobDescription.State.CreateTime).Seconds()
+	if elapsed > jobDescription.Job.Spec.Timeout {
+		return "timed out", true
+	}
+	if maxWallClock := jobDescription.Job.Spec.MaxWallClock; maxWallClock > 0 && elapsed > maxWallClock {
+		return fmt.Sprintf("missed its deadline of %s after it was submitted", time.Duration(maxWallClock*float64(time.Second))), true
+	}
+	return "", false
+}
+
 func (h *Housekeeping) Stop() {
 	h.stopOnce.Do(func() {
 		h.stopChannel <- struct{}{}
@@ -0,0 +1,33 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimedOut(t *testing.T) {
+	submitted := time.Now()
+	job := func(timeout, maxWallClock float64) model.JobWithInfo {
+		return model.JobWithInfo{
+			Job:   model.Job{Spec: model.Spec{Timeout: timeout, MaxWallClock: maxWallClock}},
+			State: model.JobState{CreateTime: submitted},
+		}
+	}
+
+	_, ok := timedOut(job(60, 0), submitted.Add(time.Minute/2))
+	require.False(t, ok)
+	reason, ok := timedOut(job(60, 0), submitted.Add(2*time.Minute))
+	require.True(t, ok)
+	require.Equal(t, "timed out", reason)
+
+	_, ok = timedOut(job(600, 120), submitted.Add(time.Minute))
+	require.False(t, ok)
+	reason, ok = timedOut(job(600, 120), submitted.Add(3*time.Minute))
+	require.True(t, ok)
+	require.Equal(t, "missed its deadline of 2m0s after it was submitted", reason)
+}
@@ -20,9 +20,14 @@ const (
 // taken up by inline data. It will scan a job for StorageSpec objects that
 // store their data inline and move any that are too large into IPFS storage. It
 // also limits the total size taken up by inline specs and if this value is
-// exceeded it will move the largest specs into IPFS.
+// exceeded it will move the largest specs into IPFS. Jobs that are signed by
+// their client keep their inline data, because compute nodes check that the
+// spec of a signed job is the one its client signed.
 func NewInlineStoragePinner(provider storage.StorageProvider) Transformer {
 	return func(ctx context.Context, j *model.Job) (modified bool, err error) {
+		if j.Metadata.ClientSignature != nil {
+			return false, nil
+		}
 		return copy.CopyOversize(
 			ctx,
 			provider,
@@ -0,0 +1,34 @@
+package jobtransform
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/verifier"
+)
+
+// NewReplicationApplier returns a job transformer that raises the concurrency
+// of jobs whose verifier compares the results of several executions, so that
+// the requester places as many executions as the verifier needs on different
+// compute nodes.
+func NewReplicationApplier(provider verifier.VerifierProvider) Transformer {
+	return func(ctx context.Context, job *model.Job) (modified bool, err error) {
+		if provider == nil || !provider.Has(ctx, job.Spec.Verifier) {
+			// the job fails later on if its verifier isn't installed
+			return false, nil
+		}
+		jobVerifier, err := provider.Get(ctx, job.Spec.Verifier)
+		if err != nil {
+			return false, err
+		}
+		replicator, ok := jobVerifier.(verifier.Replicator)
+		if !ok {
+			return false, nil
+		}
+		if replicas := replicator.Replicas(*job); replicas > job.Spec.Deal.Concurrency {
+			job.Spec.Deal.Concurrency = replicas
+			return true, nil
+		}
+		return false, nil
+	}
+}
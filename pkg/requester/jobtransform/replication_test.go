@@ -0,0 +1,40 @@
+//go:build unit || !integration
+
+package jobtransform
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/bacalhau-project/bacalhau/pkg/verifier/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicationApplier(t *testing.T) {
+	ctx := context.Background()
+	noCrypto := func(_ context.Context, data []byte, _ []byte) ([]byte, error) { return data, nil }
+	verifiers, err := util.NewStandardVerifiers(ctx, system.NewCleanupManager(), noCrypto,
+		func(_ context.Context, data []byte) ([]byte, error) { return data, nil })
+	require.NoError(t, err)
+	applier := NewReplicationApplier(verifiers)
+
+	job := &model.Job{Spec: model.Spec{Verifier: model.VerifierDeterministic, Deal: model.Deal{Concurrency: 1}}}
+	modified, err := applier(ctx, job)
+	require.NoError(t, err)
+	require.True(t, modified)
+	require.Equal(t, 2, job.Spec.Deal.Concurrency)
+
+	job = &model.Job{Spec: model.Spec{Verifier: model.VerifierDeterministic, Deal: model.Deal{Concurrency: 3, Confidence: 4}}}
+	modified, err = applier(ctx, job)
+	require.NoError(t, err)
+	require.True(t, modified)
+	require.Equal(t, 4, job.Spec.Deal.Concurrency)
+
+	job = &model.Job{Spec: model.Spec{Verifier: model.VerifierNoop, Deal: model.Deal{Concurrency: 1}}}
+	modified, err = applier(ctx, job)
+	require.NoError(t, err)
+	require.False(t, modified)
+	require.Equal(t, 1, job.Spec.Deal.Concurrency)
+}
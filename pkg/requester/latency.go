@@ -0,0 +1,48 @@
+package requester
+
+import (
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"golang.org/x/exp/slices"
+)
+
+// DefaultMaxLatency is the round trip time to a compute node at which latency
+// sensitive jobs stop preferring it, unless configured otherwise.
+const DefaultMaxLatency = 200 * time.Millisecond
+
+// LatencySensitiveAnnotation marks jobs that prefer the compute nodes nearest
+// to the requester whatever their timeout.
+const LatencySensitiveAnnotation = "latency-sensitive"
+
+// LatencyPolicy governs which jobs prefer the compute nodes that are the
+// shortest network round trip away from the requester. Round trips weigh most
+// on how long short jobs take, such as interactive queries, and jobs that are
+// annotated as latency sensitive.
+type LatencyPolicy struct {
+	// MaxTimeout is the longest timeout that a job can have to prefer nearby
+	// nodes. Only jobs annotated as latency sensitive prefer them if it is
+	// zero.
+	MaxTimeout time.Duration
+	// MaxLatency is the round trip time to a node at which it stops being
+	// preferred at all. Nodes are never preferred for being nearby if it is
+	// zero.
+	MaxLatency time.Duration
+}
+
+// Enabled returns whether any jobs prefer nearby nodes.
+func (p LatencyPolicy) Enabled() bool {
+	return p.MaxLatency > 0
+}
+
+// IsLatencySensitive returns whether the job prefers nearby nodes.
+func (p LatencyPolicy) IsLatencySensitive(job model.Job) bool {
+	if !p.Enabled() {
+		return false
+	}
+	if slices.Contains(job.Spec.Annotations, LatencySensitiveAnnotation) {
+		return true
+	}
+	timeout := job.Spec.GetTimeout()
+	return timeout > 0 && timeout <= p.MaxTimeout
+}
@@ -0,0 +1,116 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"golang.org/x/exp/slices"
+)
+
+type NodeAccessManagerParams struct {
+	// Store keeps the rules that admins set at runtime, which can't be set if it is nil
+	Store jobstore.NodeAccessStore
+	// Allowlist are the only nodes that executions are scheduled on, unless it is empty
+	Allowlist []string
+	// Denylist are the nodes that executions aren't scheduled on
+	Denylist []string
+	// Admins are the clients that can change the rules at runtime
+	Admins []string
+}
+
+// NodeAccessManager allows or denies scheduling executions on compute nodes,
+// so that operators can quarantine nodes that misbehave, or keep a requester
+// to a set of nodes, without changing which nodes are part of the network.
+type NodeAccessManager struct {
+	store  jobstore.NodeAccessStore
+	rules  []model.NodeAccessRule
+	admins []string
+}
+
+func NewNodeAccessManager(params NodeAccessManagerParams) *NodeAccessManager {
+	m := &NodeAccessManager{
+		store:  params.Store,
+		admins: params.Admins,
+	}
+	for _, nodeID := range params.Allowlist {
+		m.rules = append(m.rules, model.NodeAccessRule{NodeID: nodeID, Access: model.NodeAccessAllow})
+	}
+	for _, nodeID := range params.Denylist {
+		m.rules = append(m.rules, model.NodeAccessRule{NodeID: nodeID, Access: model.NodeAccessDeny})
+	}
+	return m
+}
+
+// IsAdmin returns whether the client can change the node access rules.
+func (m *NodeAccessManager) IsAdmin(clientID string) bool {
+	return clientID != "" && slices.Contains(m.admins, clientID)
+}
+
+// GetNodeAccessRules returns the rules of all the nodes that have one, where runtime rules replace the rules the
+// requester was started with.
+func (m *NodeAccessManager) GetNodeAccessRules(ctx context.Context) ([]model.NodeAccessRule, error) {
+	byNode := make(map[string]model.NodeAccessRule, len(m.rules))
+	for _, rule := range m.rules {
+		// denying a node wins over allowing it in the lists the requester was started with
+		if existing, ok := byNode[rule.NodeID]; !ok || existing.Access != model.NodeAccessDeny {
+			byNode[rule.NodeID] = rule
+		}
+	}
+	if m.store != nil {
+		stored, err := m.store.GetNodeAccessRules(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range stored {
+			byNode[rule.NodeID] = rule
+		}
+	}
+	rules := make([]model.NodeAccessRule, 0, len(byNode))
+	for _, rule := range byNode {
+		rules = append(rules, rule)
+	}
+	jobstore.SortNodeAccessRules(rules)
+	return rules, nil
+}
+
+func (m *NodeAccessManager) SetNodeAccessRule(ctx context.Context, payload model.NodeAccessSetPayload) (model.NodeAccessRule, error) {
+	if m.store == nil {
+		return model.NodeAccessRule{}, errors.New("node access rules can't be set at runtime without a job store that keeps them")
+	}
+	rule := payload.Rule
+	if err := rule.Validate(); err != nil {
+		return model.NodeAccessRule{}, err
+	}
+	rule.CreatedBy = payload.ClientID
+	rule.CreatedAt = time.Now()
+	if err := m.store.SetNodeAccessRule(ctx, rule); err != nil {
+		return model.NodeAccessRule{}, err
+	}
+	return rule, nil
+}
+
+func (m *NodeAccessManager) DeleteNodeAccessRule(ctx context.Context, payload model.NodeAccessDeletePayload) error {
+	if m.store == nil {
+		return jobstore.NewErrNodeAccessRuleNotFound(payload.NodeID)
+	}
+	return m.store.DeleteNodeAccessRule(ctx, payload.NodeID)
+}
+
+// IsNodeAllowed returns whether the rules let executions be scheduled on the node, which they do unless they deny
+// it, or they allow some nodes but not it.
+func IsNodeAllowed(rules []model.NodeAccessRule, nodeID string) bool {
+	allowlist := false
+	for _, rule := range rules {
+		if rule.NodeID == nodeID {
+			return rule.Access == model.NodeAccessAllow
+		}
+		allowlist = allowlist || rule.Access == model.NodeAccessAllow
+	}
+	return !allowlist
+}
+
+// compile-time interface check
+var _ NodeAccess = (*NodeAccessManager)(nil)
@@ -0,0 +1,78 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeAccessManager(t *testing.T) {
+	ctx := context.Background()
+	access := NewNodeAccessManager(NodeAccessManagerParams{
+		Store:    inmemory.NewJobStore(),
+		Denylist: []string{"flaky"},
+		Admins:   []string{"admin"},
+	})
+	require.True(t, access.IsAdmin("admin"))
+	require.False(t, access.IsAdmin("client"))
+
+	rules, err := access.GetNodeAccessRules(ctx)
+	require.NoError(t, err)
+	require.True(t, IsNodeAllowed(rules, "healthy"))
+	require.False(t, IsNodeAllowed(rules, "flaky"))
+
+	// quarantine a node at runtime, and let a denied node back in
+	rule, err := access.SetNodeAccessRule(ctx, model.NodeAccessSetPayload{
+		ClientID: "admin",
+		Rule:     model.NodeAccessRule{NodeID: "healthy", Access: model.NodeAccessDeny, Reason: "corrupts results"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "admin", rule.CreatedBy)
+	require.False(t, rule.CreatedAt.IsZero())
+	_, err = access.SetNodeAccessRule(ctx, model.NodeAccessSetPayload{
+		ClientID: "admin",
+		Rule:     model.NodeAccessRule{NodeID: "flaky", Access: model.NodeAccessAllow},
+	})
+	require.NoError(t, err)
+
+	rules, err = access.GetNodeAccessRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	require.False(t, IsNodeAllowed(rules, "healthy"))
+	require.True(t, IsNodeAllowed(rules, "flaky"), "runtime rules replace the rules the requester was started with")
+	require.False(t, IsNodeAllowed(rules, "other"), "allowing a node only allows the nodes that are allowed")
+
+	require.NoError(t, access.DeleteNodeAccessRule(ctx, model.NodeAccessDeletePayload{ClientID: "admin", NodeID: "flaky"}))
+	require.ErrorAs(t, access.DeleteNodeAccessRule(ctx, model.NodeAccessDeletePayload{ClientID: "admin", NodeID: "flaky"}),
+		&jobstore.ErrNodeAccessRuleNotFound{})
+	rules, err = access.GetNodeAccessRules(ctx)
+	require.NoError(t, err)
+	require.False(t, IsNodeAllowed(rules, "flaky"))
+	require.True(t, IsNodeAllowed(rules, "other"))
+
+	_, err = access.SetNodeAccessRule(ctx, model.NodeAccessSetPayload{ClientID: "admin", Rule: model.NodeAccessRule{NodeID: "node"}})
+	require.ErrorContains(t, err, "has access")
+}
+
+func TestNodeAccessManagerAllowlist(t *testing.T) {
+	access := NewNodeAccessManager(NodeAccessManagerParams{
+		Allowlist: []string{"a", "b"},
+		Denylist:  []string{"b"},
+	})
+	rules, err := access.GetNodeAccessRules(context.Background())
+	require.NoError(t, err)
+	require.True(t, IsNodeAllowed(rules, "a"))
+	require.False(t, IsNodeAllowed(rules, "b"), "denying a node wins over allowing it")
+	require.False(t, IsNodeAllowed(rules, "c"))
+
+	_, err = access.SetNodeAccessRule(context.Background(), model.NodeAccessSetPayload{
+		Rule: model.NodeAccessRule{NodeID: "c", Access: model.NodeAccessAllow},
+	})
+	require.ErrorContains(t, err, "can't be set at runtime")
+}
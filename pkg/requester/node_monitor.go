@@ -0,0 +1,145 @@
+package requester
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/routing"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/rs/zerolog/log"
+)
+
+// reconnectTimeout is how long to try to reconnect to a compute node that
+// disconnected before it is considered lost.
+const reconnectTimeout = 10 * time.Second
+
+type NodeMonitorParams struct {
+	Host             host.Host
+	NodeInfoStore    routing.NodeInfoStore
+	JobStore         jobstore.Store
+	ExecutionMonitor ExecutionMonitor
+	NodeID           string
+	// GracePeriod is how long a compute node can stay disconnected before it
+	// is considered lost
+	GracePeriod time.Duration
+}
+
+// NodeMonitor watches for compute nodes that disconnect from the requester
+// node, so that their executions don't have to wait for the node info to
+// expire before they are rescheduled. Nodes that are still disconnected after
+// the grace period, and that can't be reconnected to, are forgotten until they
+// announce themselves again, and the executions that were running on them are
+// rescheduled on other nodes.
+type NodeMonitor struct {
+	host             host.Host
+	nodeInfoStore    routing.NodeInfoStore
+	jobStore         jobstore.Store
+	executionMonitor ExecutionMonitor
+	nodeID           string
+	gracePeriod      time.Duration
+
+	notifiee *network.NotifyBundle
+	// the nodes that disconnected, which are checked on when their grace period ends
+	disconnected map[peer.ID]*time.Timer
+	stopped      bool
+	mu           sync.Mutex
+}
+
+func NewNodeMonitor(params NodeMonitorParams) *NodeMonitor {
+	m := &NodeMonitor{
+		host:             params.Host,
+		nodeInfoStore:    params.NodeInfoStore,
+		jobStore:         params.JobStore,
+		executionMonitor: params.ExecutionMonitor,
+		nodeID:           params.NodeID,
+		gracePeriod:      params.GracePeriod,
+		disconnected:     make(map[peer.ID]*time.Timer),
+	}
+	m.notifiee = &network.NotifyBundle{
+		DisconnectedF: func(_ network.Network, conn network.Conn) {
+			m.onDisconnected(conn.RemotePeer())
+		},
+	}
+	m.host.Network().Notify(m.notifiee)
+	return m
+}
+
+func (m *NodeMonitor) onDisconnected(peerID peer.ID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return
+	}
+	if _, ok := m.disconnected[peerID]; ok {
+		return
+	}
+	m.disconnected[peerID] = time.AfterFunc(m.gracePeriod, func() {
+		m.checkNode(context.Background(), peerID)
+	})
+}
+
+// checkNode forgets the compute node and reschedules the executions that were
+// running on it if it is still disconnected and can't be reconnected to.
+func (m *NodeMonitor) checkNode(ctx context.Context, peerID peer.ID) {
+	m.mu.Lock()
+	delete(m.disconnected, peerID)
+	m.mu.Unlock()
+
+	nodeInfo, err := m.nodeInfoStore.Get(ctx, peerID)
+	if err != nil || !nodeInfo.IsComputeNode() {
+		// not a compute node, or one that is already forgotten
+		return
+	}
+	if m.host.Network().Connectedness(peerID) == network.Connected {
+		return
+	}
+	connectCtx, cancel := context.WithTimeout(ctx, reconnectTimeout)
+	defer cancel()
+	err = m.host.Connect(connectCtx, nodeInfo.PeerInfo)
+	if err == nil {
+		return
+	}
+
+	log.Ctx(ctx).Info().Err(err).Msgf("compute node %s is lost after it disconnected", peerID)
+	if err = m.nodeInfoStore.Delete(ctx, peerID); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to forget lost compute node %s", peerID)
+		return
+	}
+	m.checkExecutionsOn(ctx, peerID.String())
+}
+
+// checkExecutionsOn checks on the jobs of this requester node that have
+// executions running on the compute node.
+func (m *NodeMonitor) checkExecutionsOn(ctx context.Context, nodeID string) {
+	jobs, err := m.jobStore.GetInProgressJobs(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to get in progress jobs")
+		return
+	}
+	for _, jobDescription := range jobs {
+		if jobDescription.Job.Metadata.Requester.RequesterNodeID != m.nodeID {
+			continue
+		}
+		for _, execution := range jobDescription.State.Executions {
+			if execution.NodeID == nodeID && !execution.State.IsTerminal() {
+				m.executionMonitor.CheckExecutions(ctx, jobDescription)
+				break
+			}
+		}
+	}
+}
+
+func (m *NodeMonitor) Stop() {
+	m.host.Network().StopNotify(m.notifiee)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopped = true
+	for peerID, timer := range m.disconnected {
+		timer.Stop()
+		delete(m.disconnected, peerID)
+	}
+}
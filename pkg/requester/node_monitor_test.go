@@ -0,0 +1,121 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockNodeInfoStore struct {
+	nodes map[peer.ID]model.NodeInfo
+	mu    sync.Mutex
+}
+
+func (m *mockNodeInfoStore) FindPeer(context.Context, peer.ID) (peer.AddrInfo, error) {
+	return peer.AddrInfo{}, nil
+}
+
+func (m *mockNodeInfoStore) Add(_ context.Context, nodeInfo model.NodeInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nodes[nodeInfo.PeerInfo.ID] = nodeInfo
+	return nil
+}
+
+func (m *mockNodeInfoStore) Get(_ context.Context, peerID peer.ID) (model.NodeInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	nodeInfo, ok := m.nodes[peerID]
+	if !ok {
+		return model.NodeInfo{}, NewErrNodeNotFound(peerID)
+	}
+	return nodeInfo, nil
+}
+
+func (m *mockNodeInfoStore) List(context.Context) ([]model.NodeInfo, error) {
+	return nil, nil
+}
+
+func (m *mockNodeInfoStore) ListForEngine(context.Context, model.Engine) ([]model.NodeInfo, error) {
+	return nil, nil
+}
+
+func (m *mockNodeInfoStore) Delete(_ context.Context, peerID peer.ID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.nodes, peerID)
+	return nil
+}
+
+type mockExecutionMonitor struct {
+	checked chan string
+}
+
+func (m *mockExecutionMonitor) CheckExecutions(_ context.Context, jobDescription model.JobWithInfo) {
+	m.checked <- jobDescription.Job.Metadata.ID
+}
+
+func TestNodeMonitorReschedulesExecutionsOfLostNodes(t *testing.T) {
+	ctx := context.Background()
+	mn := mocknet.New()
+	t.Cleanup(func() { _ = mn.Close() })
+	requesterHost, err := mn.GenPeer()
+	require.NoError(t, err)
+	computeHost, err := mn.GenPeer()
+	require.NoError(t, err)
+	require.NoError(t, mn.LinkAll())
+	require.NoError(t, mn.ConnectAllButSelf())
+
+	nodeInfoStore := &mockNodeInfoStore{nodes: make(map[peer.ID]model.NodeInfo)}
+	require.NoError(t, nodeInfoStore.Add(ctx, model.NodeInfo{
+		PeerInfo: peer.AddrInfo{ID: computeHost.ID(), Addrs: computeHost.Addrs()},
+		NodeType: model.NodeTypeCompute,
+	}))
+
+	jobStore := inmemory.NewJobStore()
+	for _, job := range []model.Job{
+		{Metadata: model.Metadata{ID: "on-lost-node", Requester: model.JobRequester{RequesterNodeID: "requester"}}},
+		{Metadata: model.Metadata{ID: "elsewhere", Requester: model.JobRequester{RequesterNodeID: "requester"}}},
+	} {
+		require.NoError(t, jobStore.CreateJob(ctx, job))
+	}
+	require.NoError(t, jobStore.CreateExecution(ctx, model.ExecutionState{
+		JobID:            "on-lost-node",
+		NodeID:           computeHost.ID().String(),
+		ComputeReference: "execution-1",
+		State:            model.ExecutionStateBidAccepted,
+	}))
+
+	executionMonitor := &mockExecutionMonitor{checked: make(chan string, 2)}
+	monitor := NewNodeMonitor(NodeMonitorParams{
+		Host:             requesterHost,
+		NodeInfoStore:    nodeInfoStore,
+		JobStore:         jobStore,
+		ExecutionMonitor: executionMonitor,
+		NodeID:           "requester",
+		GracePeriod:      10 * time.Millisecond,
+	})
+	t.Cleanup(monitor.Stop)
+
+	require.NoError(t, mn.UnlinkPeers(requesterHost.ID(), computeHost.ID()))
+	require.NoError(t, mn.DisconnectPeers(requesterHost.ID(), computeHost.ID()))
+
+	select {
+	case jobID := <-executionMonitor.checked:
+		require.Equal(t, "on-lost-node", jobID)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the executions of the lost node weren't checked")
+	}
+	_, err = nodeInfoStore.Get(ctx, computeHost.ID())
+	require.ErrorAs(t, err, &ErrNodeNotFound{}, "the lost node is forgotten until it announces itself again")
+	require.Empty(t, executionMonitor.checked)
+}
@@ -0,0 +1,76 @@
+package requester
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// PlacementStrategy is how the requester places the executions of jobs on the
+// compute nodes that can run them.
+type PlacementStrategy string
+
+const (
+	// PlacementAny places executions on any suitable node, at random.
+	PlacementAny PlacementStrategy = ""
+
+	// PlacementSpread places executions on the nodes that are least busy, so
+	// that losing a node affects as few executions as possible.
+	PlacementSpread PlacementStrategy = "spread"
+
+	// PlacementBinPack places executions on the busiest nodes that still have
+	// the capacity for them, so that other nodes stay idle and can be scaled
+	// down or powered off.
+	PlacementBinPack PlacementStrategy = "bin-pack"
+)
+
+func PlacementStrategies() []PlacementStrategy {
+	return []PlacementStrategy{PlacementSpread, PlacementBinPack}
+}
+
+func ParsePlacementStrategy(s string) (PlacementStrategy, error) {
+	if s == "" {
+		return PlacementAny, nil
+	}
+	for _, strategy := range PlacementStrategies() {
+		if string(strategy) == s {
+			return strategy, nil
+		}
+	}
+	return PlacementAny, fmt.Errorf("unknown placement strategy '%s', which must be one of %v", s, PlacementStrategies())
+}
+
+// orderBids orders the bids by the rank of the nodes that made them, so that
+// the bids of the nodes that the placement strategy prefers are accepted
+// first. Bids of nodes that are no longer ranked go last.
+func orderBids(bids []model.ExecutionState, rankedNodes []NodeRank) {
+	order := make(map[string]int, len(rankedNodes))
+	for i, node := range rankedNodes {
+		order[node.NodeInfo.PeerInfo.ID.String()] = i
+	}
+	position := func(bid model.ExecutionState) int {
+		if i, ok := order[bid.NodeID]; ok {
+			return i
+		}
+		return len(rankedNodes)
+	}
+	sort.SliceStable(bids, func(i, j int) bool {
+		return position(bids[i]) < position(bids[j])
+	})
+}
+
+// orderBidsByQuote orders the bids by the cost that the nodes quoted for them,
+// cheapest first, keeping the order of bids with the same cost. Nodes that
+// don't quote don't charge, so their bids go first.
+func orderBidsByQuote(bids []model.ExecutionState) {
+	cost := func(bid model.ExecutionState) float64 {
+		if bid.Quote == nil {
+			return 0
+		}
+		return bid.Quote.Cost
+	}
+	sort.SliceStable(bids, func(i, j int) bool {
+		return cost(bids[i]) < cost(bids[j])
+	})
+}
@@ -0,0 +1,57 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlacementStrategy(t *testing.T) {
+	for s, expected := range map[string]PlacementStrategy{
+		"":         PlacementAny,
+		"spread":   PlacementSpread,
+		"bin-pack": PlacementBinPack,
+	} {
+		strategy, err := ParsePlacementStrategy(s)
+		require.NoError(t, err)
+		require.Equal(t, expected, strategy)
+	}
+	_, err := ParsePlacementStrategy("fill")
+	require.Error(t, err)
+}
+
+func TestOrderBids(t *testing.T) {
+	rankedNodes := []NodeRank{
+		{NodeInfo: model.NodeInfo{PeerInfo: peer.AddrInfo{ID: peer.ID("first")}}, Rank: 20},
+		{NodeInfo: model.NodeInfo{PeerInfo: peer.AddrInfo{ID: peer.ID("second")}}, Rank: 10},
+	}
+	bid := func(nodeID string) model.ExecutionState {
+		return model.ExecutionState{NodeID: peer.ID(nodeID).String()}
+	}
+	bids := []model.ExecutionState{bid("gone"), bid("second"), bid("first")}
+	orderBids(bids, rankedNodes)
+	require.Equal(t, []model.ExecutionState{bid("first"), bid("second"), bid("gone")}, bids)
+}
+
+func TestOrderBidsByQuote(t *testing.T) {
+	bid := func(nodeID string, quote *model.Quote) model.ExecutionState {
+		return model.ExecutionState{NodeID: nodeID, Quote: quote}
+	}
+	bids := []model.ExecutionState{
+		bid("expensive", &model.Quote{Cost: 2}),
+		bid("cheap", &model.Quote{Cost: 1}),
+		bid("free", nil),
+		bid("also-cheap", &model.Quote{Cost: 1}),
+	}
+	orderBidsByQuote(bids)
+	require.Equal(t, []model.ExecutionState{
+		bid("free", nil),
+		bid("cheap", &model.Quote{Cost: 1}),
+		bid("also-cheap", &model.Quote{Cost: 1}),
+		bid("expensive", &model.Quote{Cost: 2}),
+	}, bids)
+}
@@ -0,0 +1,158 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/slices"
+)
+
+// preemptionGracePeriod is how long a job that preempted executions waits for
+// them to stop, and for the nodes to report the capacity they freed, before it
+// can preempt any more.
+const preemptionGracePeriod = 30 * time.Second
+
+// preemptedStatus starts the status of executions that were preempted.
+const preemptedStatus = "preempted by high priority job"
+
+// PreemptionPolicy governs when the scheduler stops running executions of less
+// urgent jobs to make room for high priority jobs that no node has the capacity
+// for. Executions are only ever preempted on compute nodes that allow it.
+type PreemptionPolicy struct {
+	// Enabled is whether high priority jobs may preempt executions at all
+	Enabled bool
+	// ProtectedClients are the clients whose executions are never preempted
+	ProtectedClients []string
+}
+
+// preemptFor stops enough executions of lower priority jobs on the nodes to
+// make room for the job, if the preemption policy allows, and asks for the
+// jobs they belonged to to be executed again once there is capacity.
+// make sure to call this function with the lock held
+func (s *scheduler) preemptFor(ctx context.Context, job model.Job, nodes []NodeRank, needed int) {
+	for jobID, at := range s.preempting {
+		if time.Since(at) >= preemptionGracePeriod {
+			delete(s.preempting, jobID)
+		}
+	}
+	if _, ok := s.preempting[job.Metadata.ID]; ok {
+		return
+	}
+	running, err := s.jobStore.GetInProgressJobs(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[preemptFor] failed to get in progress jobs")
+		return
+	}
+	victims := preemptionVictims(s.preemption, job, nodes, running, needed)
+	if len(victims) == 0 {
+		return
+	}
+	s.preempting[job.Metadata.ID] = time.Now()
+
+	reason := fmt.Sprintf("%s %s", preemptedStatus, job.Metadata.ID)
+	requeue := make(map[string]bool)
+	for _, execution := range victims {
+		err = s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
+			ExecutionID: execution.ID(),
+			Condition: jobstore.UpdateExecutionCondition{
+				ExpectedState:   execution.State,
+				ExpectedVersion: execution.Version,
+			},
+			NewValues: model.ExecutionState{
+				State:  model.ExecutionStateCanceled,
+				Status: reason,
+			},
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[preemptFor] failed to update execution %s", execution)
+			continue
+		}
+		log.Ctx(ctx).Info().Msgf("execution %s was %s", execution, reason)
+		s.notifyCancel(ctx, reason, execution, false)
+		requeue[execution.JobID] = true
+	}
+
+	// the nodes that the executions were preempted on stay candidates for the
+	// jobs, which will wait for capacity on them like any other node
+	for jobID := range requeue {
+		s.replaceExecutions(ctx, jobID, func(execution model.ExecutionState) bool {
+			return execution.State == model.ExecutionStateCanceled && strings.HasPrefix(execution.Status, preemptedStatus)
+		})
+	}
+}
+
+// preemptionVictims returns the running executions to preempt so that needed
+// more of the nodes have the capacity for the job. Only high priority jobs may
+// preempt, and only executions of jobs with a lower priority whose clients
+// aren't protected, on nodes that allow preemption. On each node, executions of
+// the least urgent jobs that started most recently are preempted first, so that
+// as little work as possible is lost.
+func preemptionVictims(
+	policy PreemptionPolicy, job model.Job, nodes []NodeRank, running []model.JobWithInfo, needed int,
+) []model.ExecutionState {
+	if !policy.Enabled || job.Spec.Priority < model.PriorityHigh || needed <= 0 {
+		return nil
+	}
+
+	type candidate struct {
+		execution model.ExecutionState
+		priority  model.Priority
+		usage     model.ResourceUsageData
+	}
+	candidates := make(map[string][]candidate)
+	for _, other := range running {
+		if other.Job.Metadata.ID == job.Metadata.ID || other.Job.Spec.Priority >= job.Spec.Priority ||
+			slices.Contains(policy.ProtectedClients, other.Job.Metadata.ClientID) {
+			continue
+		}
+		for _, execution := range other.State.Executions {
+			if execution.State == model.ExecutionStateBidAccepted {
+				candidates[execution.NodeID] = append(candidates[execution.NodeID], candidate{
+					execution: execution,
+					priority:  other.Job.Spec.Priority,
+					usage:     capacity.ParseResourceUsageConfig(other.Job.Spec.Resources),
+				})
+			}
+		}
+	}
+
+	usage := capacity.ParseResourceUsageConfig(job.Spec.Resources)
+	var victims []model.ExecutionState
+	for _, node := range nodes {
+		if needed == 0 {
+			break
+		}
+		info := node.NodeInfo.ComputeNodeInfo
+		if !info.Preemptible || hasCapacity(usage, info) {
+			continue
+		}
+		onNode := candidates[node.NodeInfo.PeerInfo.ID.String()]
+		sort.SliceStable(onNode, func(i, j int) bool {
+			if onNode[i].priority != onNode[j].priority {
+				return onNode[i].priority < onNode[j].priority
+			}
+			return onNode[i].execution.CreateTime.After(onNode[j].execution.CreateTime)
+		})
+
+		freed := info
+		var chosen []model.ExecutionState
+		for _, c := range onNode {
+			freed.AvailableCapacity = freed.AvailableCapacity.Add(c.usage)
+			chosen = append(chosen, c.execution)
+			if hasCapacity(usage, freed) {
+				// only preempt if it makes enough room for the job
+				victims = append(victims, chosen...)
+				needed--
+				break
+			}
+		}
+	}
+	return victims
+}
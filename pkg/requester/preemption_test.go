@@ -0,0 +1,80 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func preemptionNode(id string, preemptible bool, available float64) NodeRank {
+	return NodeRank{NodeInfo: model.NodeInfo{
+		PeerInfo: peer.AddrInfo{ID: peer.ID(id)},
+		ComputeNodeInfo: model.ComputeNodeInfo{
+			MaxCapacity:       model.ResourceUsageData{CPU: 4},
+			AvailableCapacity: model.ResourceUsageData{CPU: available},
+			Preemptible:       preemptible,
+		},
+	}}
+}
+
+// runningJob returns a job of the client that is running on the node since
+// the minute.
+func runningJob(id, clientID, nodeID string, priority model.Priority, cpu string, minute int) model.JobWithInfo {
+	return model.JobWithInfo{
+		Job: model.Job{
+			Metadata: model.Metadata{ID: id, ClientID: clientID},
+			Spec:     model.Spec{Priority: priority, Resources: model.ResourceUsageConfig{CPU: cpu}},
+		},
+		State: model.JobState{JobID: id, Executions: []model.ExecutionState{{
+			JobID:            id,
+			NodeID:           peer.ID(nodeID).String(),
+			ComputeReference: "execution-" + id,
+			State:            model.ExecutionStateBidAccepted,
+			CreateTime:       time.Date(2023, 5, 1, 12, minute, 0, 0, time.UTC),
+		}}},
+	}
+}
+
+func TestPreemptionVictims(t *testing.T) {
+	policy := PreemptionPolicy{Enabled: true, ProtectedClients: []string{"protected"}}
+	job := model.Job{
+		Metadata: model.Metadata{ID: "urgent", ClientID: "client"},
+		Spec:     model.Spec{Priority: model.PriorityHigh, Resources: model.ResourceUsageConfig{CPU: "2"}},
+	}
+	nodes := []NodeRank{
+		preemptionNode("locked", false, 0),
+		preemptionNode("busy", true, 0),
+		preemptionNode("free", true, 4),
+		preemptionNode("small", true, 0),
+	}
+	running := []model.JobWithInfo{
+		runningJob("on-locked", "client", "locked", model.PriorityLow, "4", 0),
+		runningJob("low-old", "client", "busy", model.PriorityLow, "1", 0),
+		runningJob("normal", "client", "busy", model.PriorityNormal, "1", 5),
+		runningJob("low-new", "client", "busy", model.PriorityLow, "1", 10),
+		runningJob("protected", "protected", "busy", model.PriorityLow, "1", 15),
+		runningJob("on-free", "client", "free", model.PriorityLow, "1", 0),
+		runningJob("too-small", "client", "small", model.PriorityLow, "1", 0),
+		runningJob("also-high", "client", "small", model.PriorityHigh, "3", 0),
+	}
+
+	victimIDs := func(victims []model.ExecutionState) []string {
+		var ids []string
+		for _, victim := range victims {
+			ids = append(ids, victim.JobID)
+		}
+		return ids
+	}
+	require.Equal(t, []string{"low-new", "low-old"}, victimIDs(preemptionVictims(policy, job, nodes, running, 2)),
+		"the least urgent executions that started last are preempted, only on nodes that allow it and only if it makes room")
+
+	require.Empty(t, preemptionVictims(PreemptionPolicy{}, job, nodes, running, 1), "preemption is disabled")
+
+	job.Spec.Priority = model.PriorityNormal
+	require.Empty(t, preemptionVictims(policy, job, nodes, running, 1), "only high priority jobs preempt")
+}
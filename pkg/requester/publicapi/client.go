@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,8 +15,12 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/job"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog/log"
+	"go.uber.org/multierr"
 )
 
 // APIRetryCount - for some queries (like read events and read state)
@@ -119,7 +127,7 @@ func (apiClient *RequesterAPIClient) Cancel(ctx context.Context, jobID string, r
 	}
 	log.Ctx(ctx).Trace().Str("signature", signature).Msgf("signature")
 
-	req := signedRequest{
+	req := publicapi.SignedRequest{
 		Payload:         &rawPayloadJSON,
 		ClientSignature: signature,
 		ClientPublicKey: system.GetClientPublicKey(),
@@ -253,6 +261,66 @@ func (apiClient *RequesterAPIClient) GetResults(ctx context.Context, jobID strin
 	return res.Results, nil
 }
 
+// Logs returns the output of a job as a stream of logstream data frames. The output is streamed live if the job is
+// running, and if follow is true the stream stays open until the job stops producing output.
+func (apiClient *RequesterAPIClient) Logs(ctx context.Context, jobID string, follow bool) (io.ReadCloser, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.Logs")
+	defer span.End()
+
+	if jobID == "" {
+		return nil, fmt.Errorf("jobID must be non-empty in a Logs call")
+	}
+
+	addr, err := url.Parse(apiClient.BaseURI)
+	if err != nil {
+		return nil, err
+	}
+	addr.Scheme = strings.Replace(addr.Scheme, "http", "ws", 1)
+	addr = addr.JoinPath(APIPrefix, "websocket", "logs")
+	addr.RawQuery = url.Values{
+		"job_id": []string{jobID},
+		"follow": []string{strconv.FormatBool(follow)},
+	}.Encode()
+
+	conn, res, err := websocket.DefaultDialer.DialContext(ctx, addr.String(), nil)
+	if err != nil {
+		if res != nil && res.StatusCode != http.StatusSwitchingProtocols {
+			defer closer.DrainAndCloseWithLogOnError(ctx, "logs response", res.Body)
+			body, _ := io.ReadAll(res.Body)
+			return nil, fmt.Errorf("failed to stream logs: %s", strings.TrimSpace(string(body)))
+		}
+		return nil, err
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		for {
+			_, message, err := conn.ReadMessage()
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				writer.Close()
+				return
+			} else if err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+			if _, err = writer.Write(message); err != nil {
+				return
+			}
+		}
+	}()
+	return &websocketReader{PipeReader: reader, conn: conn}, nil
+}
+
+// websocketReader reads the messages received over a websocket, and closes the websocket when it is closed.
+type websocketReader struct {
+	*io.PipeReader
+	conn *websocket.Conn
+}
+
+func (r *websocketReader) Close() error {
+	return multierr.Combine(r.PipeReader.Close(), r.conn.Close())
+}
+
 // Submit submits a new job to the node's transport.
 func (apiClient *RequesterAPIClient) Submit(
 	ctx context.Context,
@@ -261,12 +329,54 @@ func (apiClient *RequesterAPIClient) Submit(
 	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.Submit")
 	defer span.End()
 
-	data := model.JobCreatePayload{
+	return apiClient.submit(ctx, model.JobCreatePayload{
 		ClientID:   system.GetClientID(),
 		APIVersion: j.APIVersion,
 		Spec:       &j.Spec,
+	})
+}
+
+// SubmitFromTemplate submits a job from the template with the values of its
+// parameters, overriding the spec of the template with the fields that are set
+// in the spec of the job, if there is one. The template is resolved here rather
+// than by the requester, so that the client signs the spec that the job runs.
+func (apiClient *RequesterAPIClient) SubmitFromTemplate(
+	ctx context.Context,
+	template string,
+	parameters map[string]string,
+	j *model.Job,
+) (*model.Job, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.SubmitFromTemplate")
+	defer span.End()
+
+	tmpl, err := apiClient.GetTemplate(ctx, template)
+	if err != nil {
+		return &model.Job{}, err
+	}
+	data := model.JobCreatePayload{
+		Spec:               &model.Spec{},
+		TemplateParameters: parameters,
+	}
+	if j != nil {
+		data.APIVersion = j.APIVersion
+		data.Spec = &j.Spec
 	}
+	if data, err = requester.ResolveTemplate(tmpl, data); err != nil {
+		return &model.Job{}, err
+	}
+	return apiClient.submit(ctx, model.JobCreatePayload{
+		ClientID:   system.GetClientID(),
+		APIVersion: data.APIVersion,
+		Spec:       data.Spec,
+	})
+}
 
+func (apiClient *RequesterAPIClient) submit(ctx context.Context, data model.JobCreatePayload) (*model.Job, error) {
+	nonce, err := model.NewNonce()
+	if err != nil {
+		return &model.Job{}, err
+	}
+	data.Nonce = nonce
 	jsonData, err := model.JSONMarshalWithMax(data)
 	if err != nil {
 		return &model.Job{}, err
@@ -308,3 +418,336 @@ func (apiClient *RequesterAPIClient) Debug(ctx context.Context) (map[string]mode
 
 	return res, nil
 }
+
+// CreateSchedule creates a schedule that submits the job each time the cron
+// expression fires.
+func (apiClient *RequesterAPIClient) CreateSchedule(
+	ctx context.Context,
+	j *model.Job,
+	cron string,
+	keepRuns int,
+) (model.JobSchedule, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.CreateSchedule")
+	defer span.End()
+
+	payload := model.ScheduleCreatePayload{
+		ClientID:   system.GetClientID(),
+		Cron:       cron,
+		KeepRuns:   keepRuns,
+		APIVersion: j.APIVersion,
+		Spec:       &j.Spec,
+	}
+	var res scheduleResponse
+	err := apiClient.postSigned(ctx, APIPrefix+"schedules/create", payload, &res)
+	return res.Schedule, err
+}
+
+// ListSchedules returns the schedules of this client.
+func (apiClient *RequesterAPIClient) ListSchedules(ctx context.Context) ([]model.JobSchedule, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.ListSchedules")
+	defer span.End()
+
+	req := listSchedulesRequest{ClientID: system.GetClientID()}
+	var res listSchedulesResponse
+	if err := apiClient.Post(ctx, APIPrefix+"schedules/list", req, &res); err != nil {
+		return nil, err
+	}
+	return res.Schedules, nil
+}
+
+// UpdateSchedule pauses, resumes, triggers or deletes one of this client's
+// schedules, and returns the schedule after the change.
+func (apiClient *RequesterAPIClient) UpdateSchedule(
+	ctx context.Context,
+	scheduleID string,
+	action model.ScheduleAction,
+) (model.JobSchedule, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.UpdateSchedule")
+	defer span.End()
+
+	payload := model.ScheduleUpdatePayload{
+		ClientID:   system.GetClientID(),
+		ScheduleID: scheduleID,
+		Action:     action,
+	}
+	var res scheduleResponse
+	err := apiClient.postSigned(ctx, APIPrefix+"schedules/update", payload, &res)
+	return res.Schedule, err
+}
+
+// SubmitWorkflow submits a workflow, whose stages are submitted once the
+// stages they depend on have completed.
+func (apiClient *RequesterAPIClient) SubmitWorkflow(
+	ctx context.Context,
+	apiVersion string,
+	stages []model.WorkflowStageSpec,
+) (model.Workflow, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.SubmitWorkflow")
+	defer span.End()
+
+	payload := model.WorkflowSubmitPayload{
+		ClientID:   system.GetClientID(),
+		APIVersion: apiVersion,
+		Stages:     stages,
+	}
+	var res workflowResponse
+	err := apiClient.postSigned(ctx, APIPrefix+"workflows/submit", payload, &res)
+	return res.Workflow, err
+}
+
+// GetWorkflow returns the workflow and the state of each of its stages.
+func (apiClient *RequesterAPIClient) GetWorkflow(ctx context.Context, workflowID string) (model.Workflow, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.GetWorkflow")
+	defer span.End()
+
+	req := getWorkflowRequest{WorkflowID: workflowID}
+	var res workflowResponse
+	err := apiClient.Post(ctx, APIPrefix+"workflows/get", req, &res)
+	return res.Workflow, err
+}
+
+// ListWorkflows returns the workflows of this client.
+func (apiClient *RequesterAPIClient) ListWorkflows(ctx context.Context) ([]model.Workflow, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.ListWorkflows")
+	defer span.End()
+
+	req := listWorkflowsRequest{ClientID: system.GetClientID()}
+	var res listWorkflowsResponse
+	if err := apiClient.Post(ctx, APIPrefix+"workflows/list", req, &res); err != nil {
+		return nil, err
+	}
+	return res.Workflows, nil
+}
+
+// CancelWorkflow cancels the running jobs and pending stages of one of this
+// client's workflows.
+func (apiClient *RequesterAPIClient) CancelWorkflow(ctx context.Context, workflowID, reason string) (model.Workflow, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.CancelWorkflow")
+	defer span.End()
+
+	payload := model.WorkflowCancelPayload{
+		ClientID:   system.GetClientID(),
+		WorkflowID: workflowID,
+		Reason:     reason,
+	}
+	var res workflowResponse
+	err := apiClient.postSigned(ctx, APIPrefix+"workflows/cancel", payload, &res)
+	return res.Workflow, err
+}
+
+// GetQuota returns the quota of the client, which is this client if the id is
+// empty, and how much of it the client is using.
+func (apiClient *RequesterAPIClient) GetQuota(ctx context.Context, clientID string) (model.ClientQuota, model.ClientUsage, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.GetQuota")
+	defer span.End()
+
+	if clientID == "" {
+		clientID = system.GetClientID()
+	}
+	req := getQuotaRequest{ClientID: clientID}
+	var res getQuotaResponse
+	err := apiClient.Post(ctx, APIPrefix+"quotas/get", req, &res)
+	return res.Quota, res.Usage, err
+}
+
+// ListQuotas returns the quotas of the clients that have their own.
+func (apiClient *RequesterAPIClient) ListQuotas(ctx context.Context) ([]model.ClientQuota, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.ListQuotas")
+	defer span.End()
+
+	var res listQuotasResponse
+	if err := apiClient.Post(ctx, APIPrefix+"quotas/list", struct{}{}, &res); err != nil {
+		return nil, err
+	}
+	return res.Quotas, nil
+}
+
+// SetQuota sets the quota of a client, which this client must be a quota
+// admin to do.
+func (apiClient *RequesterAPIClient) SetQuota(ctx context.Context, quota model.ClientQuota) (model.ClientQuota, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.SetQuota")
+	defer span.End()
+
+	adminRequest, err := apiClient.NewAdminRequest(ctx)
+	if err != nil {
+		return model.ClientQuota{}, err
+	}
+	payload := model.QuotaSetPayload{
+		ClientID:     system.GetClientID(),
+		AdminRequest: adminRequest,
+		Quota:        quota,
+	}
+	var res setQuotaResponse
+	err = apiClient.postSigned(ctx, APIPrefix+"quotas/set", payload, &res)
+	return res.Quota, err
+}
+
+// DeleteQuota deletes the quota of a client so that the default quota applies
+// to it, which this client must be a quota admin to do.
+func (apiClient *RequesterAPIClient) DeleteQuota(ctx context.Context, clientID string) error {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.DeleteQuota")
+	defer span.End()
+
+	adminRequest, err := apiClient.NewAdminRequest(ctx)
+	if err != nil {
+		return err
+	}
+	payload := model.QuotaDeletePayload{
+		ClientID:      system.GetClientID(),
+		AdminRequest:  adminRequest,
+		QuotaClientID: clientID,
+	}
+	return apiClient.postSigned(ctx, APIPrefix+"quotas/delete", payload, &struct{}{})
+}
+
+// ListNodeAccessRules returns the rules that allow or deny scheduling executions on compute nodes.
+func (apiClient *RequesterAPIClient) ListNodeAccessRules(ctx context.Context) ([]model.NodeAccessRule, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.ListNodeAccessRules")
+	defer span.End()
+
+	var res listNodeAccessRulesResponse
+	if err := apiClient.Post(ctx, APIPrefix+"nodes/access/list", struct{}{}, &res); err != nil {
+		return nil, err
+	}
+	return res.Rules, nil
+}
+
+// SetNodeAccessRule allows or denies scheduling executions on a compute node,
+// which this client must be a node admin to do.
+func (apiClient *RequesterAPIClient) SetNodeAccessRule(ctx context.Context, rule model.NodeAccessRule) (model.NodeAccessRule, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.SetNodeAccessRule")
+	defer span.End()
+
+	adminRequest, err := apiClient.NewAdminRequest(ctx)
+	if err != nil {
+		return model.NodeAccessRule{}, err
+	}
+	payload := model.NodeAccessSetPayload{
+		ClientID:     system.GetClientID(),
+		AdminRequest: adminRequest,
+		Rule:         rule,
+	}
+	var res setNodeAccessRuleResponse
+	err = apiClient.postSigned(ctx, APIPrefix+"nodes/access/set", payload, &res)
+	return res.Rule, err
+}
+
+// DeleteNodeAccessRule deletes the rule that an admin set for a compute node,
+// which this client must be a node admin to do.
+func (apiClient *RequesterAPIClient) DeleteNodeAccessRule(ctx context.Context, nodeID string) error {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.DeleteNodeAccessRule")
+	defer span.End()
+
+	adminRequest, err := apiClient.NewAdminRequest(ctx)
+	if err != nil {
+		return err
+	}
+	payload := model.NodeAccessDeletePayload{
+		ClientID:     system.GetClientID(),
+		AdminRequest: adminRequest,
+		NodeID:       nodeID,
+	}
+	return apiClient.postSigned(ctx, APIPrefix+"nodes/access/delete", payload, &struct{}{})
+}
+
+// GetQueue returns the jobs that wait to start, with the job that starts next
+// first, or just the job with the id if it isn't empty.
+func (apiClient *RequesterAPIClient) GetQueue(ctx context.Context, jobID string) ([]model.QueuedJob, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.GetQueue")
+	defer span.End()
+
+	req := queueRequest{JobID: jobID}
+	var res queueResponse
+	if err := apiClient.Post(ctx, APIPrefix+"queue", req, &res); err != nil {
+		return nil, err
+	}
+	return res.Jobs, nil
+}
+
+// GetTemplate returns the job template with the name.
+func (apiClient *RequesterAPIClient) GetTemplate(ctx context.Context, name string) (model.JobTemplate, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.GetTemplate")
+	defer span.End()
+
+	req := getTemplateRequest{Name: name}
+	var res templateResponse
+	err := apiClient.Post(ctx, APIPrefix+"templates/get", req, &res)
+	return res.Template, err
+}
+
+// ListTemplates returns the job templates.
+func (apiClient *RequesterAPIClient) ListTemplates(ctx context.Context) ([]model.JobTemplate, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.ListTemplates")
+	defer span.End()
+
+	var res listTemplatesResponse
+	if err := apiClient.Post(ctx, APIPrefix+"templates/list", struct{}{}, &res); err != nil {
+		return nil, err
+	}
+	return res.Templates, nil
+}
+
+// SetTemplate creates the job template, or replaces the template with the same
+// name, which this client must have created.
+func (apiClient *RequesterAPIClient) SetTemplate(ctx context.Context, template model.JobTemplate) (model.JobTemplate, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.SetTemplate")
+	defer span.End()
+
+	payload := model.TemplateSetPayload{
+		ClientID: system.GetClientID(),
+		Template: template,
+	}
+	var res templateResponse
+	err := apiClient.postSigned(ctx, APIPrefix+"templates/set", payload, &res)
+	return res.Template, err
+}
+
+// DeleteTemplate deletes the job template, which this client must have
+// created.
+func (apiClient *RequesterAPIClient) DeleteTemplate(ctx context.Context, name string) error {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.DeleteTemplate")
+	defer span.End()
+
+	payload := model.TemplateDeletePayload{
+		ClientID: system.GetClientID(),
+		Name:     name,
+	}
+	return apiClient.postSigned(ctx, APIPrefix+"templates/delete", payload, &struct{}{})
+}
+
+// SimulatePlacement returns which nodes the job would be placed on and why,
+// without running it.
+func (apiClient *RequesterAPIClient) SimulatePlacement(ctx context.Context, j *model.Job) (model.PlacementPlan, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.SimulatePlacement")
+	defer span.End()
+
+	payload := model.JobCreatePayload{
+		ClientID:   system.GetClientID(),
+		APIVersion: j.APIVersion,
+		Spec:       &j.Spec,
+	}
+	var res placementResponse
+	err := apiClient.postSigned(ctx, APIPrefix+"placement", payload, &res)
+	return res.Plan, err
+}
+
+// postSigned posts the payload signed with this client's key.
+func (apiClient *RequesterAPIClient) postSigned(ctx context.Context, api string, payload any, res any) error {
+	jsonData, err := model.JSONMarshalWithMax(payload)
+	if err != nil {
+		return err
+	}
+	rawPayloadJSON := json.RawMessage(jsonData)
+
+	signature, err := system.SignForClient(rawPayloadJSON)
+	if err != nil {
+		return err
+	}
+
+	req := publicapi.SignedRequest{
+		Payload:         &rawPayloadJSON,
+		ClientSignature: signature,
+		ClientPublicKey: system.GetClientPublicKey(),
+	}
+	return apiClient.Post(ctx, api, req, res)
+}
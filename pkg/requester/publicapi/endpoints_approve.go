@@ -3,6 +3,7 @@ package publicapi
 import (
 	"net/http"
 
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
 	"github.com/bacalhau-project/bacalhau/pkg/requester"
 	"github.com/rs/zerolog/log"
 )
@@ -17,16 +18,16 @@ func (j JobApprovePayload) GetClientID() string {
 
 func (s *RequesterAPIServer) approve(res http.ResponseWriter, req *http.Request) {
 	ctx := req.Context()
-	approval, err := unmarshalSignedJob[JobApprovePayload](ctx, req.Body)
+	approval, err := publicapi.UnmarshalSigned[JobApprovePayload](req.Body)
 	if err != nil {
-		httpError(ctx, res, err, http.StatusBadRequest)
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
 		return
 	}
 
 	ctx = log.Ctx(ctx).With().Str("JobID", approval.ApproveJobRequest.JobID).Logger().WithContext(ctx)
 	err = s.requester.ApproveJob(ctx, approval.ApproveJobRequest)
 	if err != nil {
-		httpError(ctx, res, err, http.StatusBadRequest)
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
 		return
 	}
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
 	"github.com/bacalhau-project/bacalhau/pkg/requester"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
@@ -37,9 +38,9 @@ type cancelResponse struct {
 //	@Router					/requester/cancel [post]
 func (s *RequesterAPIServer) cancel(res http.ResponseWriter, req *http.Request) {
 	ctx := req.Context()
-	jobCancelPayload, err := unmarshalSignedJob[model.JobCancelPayload](ctx, req.Body)
+	jobCancelPayload, err := publicapi.UnmarshalSigned[model.JobCancelPayload](req.Body)
 	if err != nil {
-		httpError(ctx, res, err, http.StatusBadRequest)
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
 		return
 	}
 
@@ -0,0 +1,124 @@
+package publicapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/logstream"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+// logs streams the stdout and stderr of a job over a websocket. Each binary message carries a single logstream
+// data frame. If the job has a running execution its output is streamed live from the compute node, following it
+// until it completes if requested. Otherwise, the output recorded for a completed execution is sent instead.
+func (s *RequesterAPIServer) logs(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	jobID := req.URL.Query().Get("job_id")
+	if jobID == "" {
+		http.Error(res, "job_id is required", http.StatusBadRequest)
+		return
+	}
+	follow, _ := strconv.ParseBool(req.URL.Query().Get("follow"))
+
+	job, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// look up where the logs are coming from before upgrading the connection,
+	// so that failures can be reported to the client as plain http errors
+	reader, err := s.openLogStream(ctx, job, jobState, follow)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer reader.Close()
+
+	conn, err := upgrader.Upgrade(res, req, nil)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("error upgrading logs connection")
+		return
+	}
+	defer conn.Close()
+
+	// stop streaming if the client disconnects
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				reader.Close()
+				return
+			}
+		}
+	}()
+
+	for {
+		df, err := logstream.NewDataFrameFromReader(reader)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Str("JobID", jobID).Msg("stopped streaming logs")
+			break
+		}
+		if err = conn.WriteMessage(websocket.BinaryMessage, df.ToBytes()); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Str("JobID", jobID).Msg("error writing logs to client")
+			return
+		}
+	}
+
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+func (s *RequesterAPIServer) openLogStream(
+	ctx context.Context, job model.Job, jobState model.JobState, follow bool,
+) (io.ReadCloser, error) {
+	// stdout and stderr are part of the encrypted results, so are only readable by the recipients
+	if len(job.Spec.Encryption.Recipients) > 0 {
+		return nil, fmt.Errorf("job %s has encrypted results, so its logs can't be shown", jobState.JobID)
+	}
+
+	for _, execution := range jobState.Executions {
+		if execution.State == model.ExecutionStateBidAccepted {
+			if s.logStreamer == nil {
+				return nil, fmt.Errorf("log streaming is not supported by this requester node")
+			}
+			return s.logStreamer.ExecutionLogs(ctx, compute.ExecutionLogsRequest{
+				RoutingMetadata: compute.RoutingMetadata{
+					TargetPeerID: execution.NodeID,
+				},
+				ExecutionID: execution.ComputeReference,
+				Follow:      follow,
+			})
+		}
+	}
+
+	for _, execution := range jobState.Executions {
+		if execution.RunOutput != nil {
+			return io.NopCloser(io.MultiReader(
+				newRecordedOutputReader(logstream.StdoutStreamTag, execution.RunOutput.STDOUT),
+				newRecordedOutputReader(logstream.StderrStreamTag, execution.RunOutput.STDERR),
+			)), nil
+		}
+	}
+
+	return nil, fmt.Errorf("job %s has no running or completed executions to show logs for", jobState.JobID)
+}
+
+func newRecordedOutputReader(tag logstream.StreamTag, output string) io.Reader {
+	if output == "" {
+		return bytes.NewReader(nil)
+	}
+	return bytes.NewReader(logstream.NewDataFrameFromData(tag, []byte(output)).ToBytes())
+}
@@ -0,0 +1,146 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+)
+
+var errNodeAccessNotSupported = errors.New("this requester node does not support node access rules")
+
+type setNodeAccessRuleRequest = SignedRequest[model.NodeAccessSetPayload] //nolint:unused // Swagger wants this
+
+type deleteNodeAccessRuleRequest = SignedRequest[model.NodeAccessDeletePayload] //nolint:unused // Swagger wants this
+
+type listNodeAccessRulesResponse struct {
+	Rules []model.NodeAccessRule `json:"rules"`
+}
+
+type setNodeAccessRuleResponse struct {
+	Rule model.NodeAccessRule `json:"rule"`
+}
+
+// listNodeAccessRules godoc
+//
+//	@ID				pkg/requester/publicapi/listNodeAccessRules
+//	@Summary		Lists the rules that allow or deny scheduling executions on compute nodes.
+//	@Tags			Node Access
+//	@Produce		json
+//	@Success		200	{object}	listNodeAccessRulesResponse
+//	@Failure		500	{object}	string
+//	@Router			/requester/nodes/access/list [post]
+func (s *RequesterAPIServer) listNodeAccessRules(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.nodeAccess == nil {
+		publicapi.HTTPError(ctx, res, errNodeAccessNotSupported, http.StatusNotImplemented)
+		return
+	}
+
+	rules, err := s.nodeAccess.GetNodeAccessRules(ctx)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(res).Encode(listNodeAccessRulesResponse{Rules: rules}); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
+
+// setNodeAccessRule godoc
+//
+//	@ID				pkg/requester/publicapi/setNodeAccessRule
+//	@Summary		Allows or denies scheduling executions on a compute node, which only admins can do.
+//	@Tags			Node Access
+//	@Accept			json
+//	@Produce		json
+//	@Param			setNodeAccessRuleRequest	body		setNodeAccessRuleRequest	true	" "
+//	@Success		200							{object}	setNodeAccessRuleResponse
+//	@Failure		400							{object}	string
+//	@Failure		403							{object}	string
+//	@Failure		500							{object}	string
+//	@Router			/requester/nodes/access/set [post]
+func (s *RequesterAPIServer) setNodeAccessRule(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.nodeAccess == nil {
+		publicapi.HTTPError(ctx, res, errNodeAccessNotSupported, http.StatusNotImplemented)
+		return
+	}
+	payload, err := publicapi.UnmarshalSigned[model.NodeAccessSetPayload](req.Body)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	// we know the admin is who signed the request
+	if !s.nodeAccess.IsAdmin(payload.ClientID) {
+		publicapi.HTTPError(ctx, res, fmt.Errorf("client %s can't change node access rules", payload.ClientID), http.StatusForbidden)
+		return
+	}
+	if err := s.apiServer.AcceptAdminRequest(payload.AdminRequest); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusForbidden)
+		return
+	}
+
+	rule, err := s.nodeAccess.SetNodeAccessRule(ctx, payload)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(res).Encode(setNodeAccessRuleResponse{Rule: rule}); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
+
+// deleteNodeAccessRule godoc
+//
+//	@ID				pkg/requester/publicapi/deleteNodeAccessRule
+//	@Summary		Deletes the rule that an admin set for a compute node, which only admins can do.
+//	@Tags			Node Access
+//	@Accept			json
+//	@Param			deleteNodeAccessRuleRequest	body	deleteNodeAccessRuleRequest	true	" "
+//	@Success		200
+//	@Failure		400	{object}	string
+//	@Failure		403	{object}	string
+//	@Failure		404	{object}	string
+//	@Router			/requester/nodes/access/delete [post]
+func (s *RequesterAPIServer) deleteNodeAccessRule(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.nodeAccess == nil {
+		publicapi.HTTPError(ctx, res, errNodeAccessNotSupported, http.StatusNotImplemented)
+		return
+	}
+	payload, err := publicapi.UnmarshalSigned[model.NodeAccessDeletePayload](req.Body)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	if !s.nodeAccess.IsAdmin(payload.ClientID) {
+		publicapi.HTTPError(ctx, res, fmt.Errorf("client %s can't change node access rules", payload.ClientID), http.StatusForbidden)
+		return
+	}
+	if err := s.apiServer.AcceptAdminRequest(payload.AdminRequest); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusForbidden)
+		return
+	}
+
+	if err = s.nodeAccess.DeleteNodeAccessRule(ctx, payload); err != nil {
+		status := http.StatusBadRequest
+		if errors.As(err, &jobstore.ErrNodeAccessRuleNotFound{}) {
+			status = http.StatusNotFound
+		}
+		publicapi.HTTPError(ctx, res, err, status)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
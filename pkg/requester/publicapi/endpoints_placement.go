@@ -0,0 +1,71 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+)
+
+var errPlacementNotSupported = errors.New("this requester node does not support simulating placements")
+
+type placementRequest = SignedRequest[model.JobCreatePayload] //nolint:unused // Swagger wants this
+
+type placementResponse struct {
+	Plan model.PlacementPlan `json:"plan"`
+}
+
+// placement godoc
+//
+//	@ID				pkg/requester/publicapi/placement
+//	@Summary		Returns which nodes a job would be placed on and why, without running it.
+//	@Description	Discovers, ranks and selects the nodes for the job like submitting it would, for capacity planning
+//	@Description	and debugging. The job is never stored or run.
+//	@Tags			Job
+//	@Accept			json
+//	@Produce		json
+//	@Param			placementRequest	body		placementRequest	true	" "
+//	@Success		200					{object}	placementResponse
+//	@Failure		400					{object}	string
+//	@Failure		500					{object}	string
+//	@Router			/requester/placement [post]
+func (s *RequesterAPIServer) placement(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.placements == nil {
+		publicapi.HTTPError(ctx, res, errPlacementNotSupported, http.StatusNotImplemented)
+		return
+	}
+	payload, err := publicapi.UnmarshalSigned[model.JobCreatePayload](req.Body)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	if err = job.VerifyJobCreatePayload(ctx, &payload); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+
+	plan, err := s.placements.SimulatePlacement(ctx, model.Job{
+		APIVersion: payload.APIVersion,
+		Metadata: model.Metadata{
+			ClientID:  payload.ClientID,
+			CreatedAt: time.Now(),
+		},
+		Spec: *payload.Spec,
+	})
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(res).Encode(placementResponse{Plan: plan}); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
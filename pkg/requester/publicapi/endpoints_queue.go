@@ -0,0 +1,75 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+)
+
+var errQueueNotSupported = errors.New("this requester node does not tell about its queue")
+
+type queueRequest struct {
+	// The id of the job to return, which can be a short id, or empty for all
+	// the queued jobs
+	JobID string `json:"job_id,omitempty"`
+}
+
+type queueResponse struct {
+	Jobs []model.QueuedJob `json:"jobs"`
+}
+
+// getQueue godoc
+//
+//	@ID				pkg/requester/publicapi/queue
+//	@Summary		Returns the jobs that wait to start, with why and until when.
+//	@Description	Returns where each job that waits to start is in the queue, why it hasn't started yet and when it is
+//	@Description	expected to start, with the job that starts next first.
+//	@Tags			Job
+//	@Accept			json
+//	@Produce		json
+//	@Param			queueRequest	body		queueRequest	true	" "
+//	@Success		200				{object}	queueResponse
+//	@Failure		400				{object}	string
+//	@Failure		404				{object}	string
+//	@Failure		500				{object}	string
+//	@Router			/requester/queue [post]
+func (s *RequesterAPIServer) getQueue(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.queue == nil {
+		publicapi.HTTPError(ctx, res, errQueueNotSupported, http.StatusNotImplemented)
+		return
+	}
+	var queueReq queueRequest
+	if err := json.NewDecoder(req.Body).Decode(&queueReq); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+
+	jobs, err := s.queue.GetQueuedJobs(ctx)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+	if queueReq.JobID != "" {
+		var found []model.QueuedJob
+		for _, queued := range jobs {
+			if strings.HasPrefix(queued.JobID, queueReq.JobID) {
+				found = append(found, queued)
+			}
+		}
+		if len(found) == 0 {
+			publicapi.HTTPError(ctx, res, fmt.Errorf("job %s isn't waiting in the queue", queueReq.JobID), http.StatusNotFound)
+			return
+		}
+		jobs = found
+	}
+	res.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(res).Encode(queueResponse{Jobs: jobs}); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
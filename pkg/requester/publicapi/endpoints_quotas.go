@@ -0,0 +1,185 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+)
+
+var errQuotasNotSupported = errors.New("this requester node does not support quotas")
+
+type setQuotaRequest = SignedRequest[model.QuotaSetPayload] //nolint:unused // Swagger wants this
+
+type deleteQuotaRequest = SignedRequest[model.QuotaDeletePayload] //nolint:unused // Swagger wants this
+
+type getQuotaRequest struct {
+	// The client whose quota to get
+	ClientID string `json:"client_id"`
+}
+
+type getQuotaResponse struct {
+	Quota model.ClientQuota `json:"quota"`
+	Usage model.ClientUsage `json:"usage"`
+}
+
+type listQuotasResponse struct {
+	Quotas []model.ClientQuota `json:"quotas"`
+}
+
+type setQuotaResponse struct {
+	Quota model.ClientQuota `json:"quota"`
+}
+
+// getQuota godoc
+//
+//	@ID				pkg/requester/publicapi/getQuota
+//	@Summary		Returns the quota of a client and how much of it the client is using.
+//	@Tags			Quota
+//	@Accept			json
+//	@Produce		json
+//	@Param			getQuotaRequest	body		getQuotaRequest	true	" "
+//	@Success		200				{object}	getQuotaResponse
+//	@Failure		400				{object}	string
+//	@Failure		500				{object}	string
+//	@Router			/requester/quotas/get [post]
+func (s *RequesterAPIServer) getQuota(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.quotas == nil {
+		publicapi.HTTPError(ctx, res, errQuotasNotSupported, http.StatusNotImplemented)
+		return
+	}
+	var getReq getQuotaRequest
+	if err := json.NewDecoder(req.Body).Decode(&getReq); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+
+	quota, usage, err := s.quotas.GetQuota(ctx, getReq.ClientID)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(res).Encode(getQuotaResponse{Quota: quota, Usage: usage}); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
+
+// listQuotas godoc
+//
+//	@ID				pkg/requester/publicapi/listQuotas
+//	@Summary		Lists the quotas of the clients that have their own.
+//	@Tags			Quota
+//	@Produce		json
+//	@Success		200	{object}	listQuotasResponse
+//	@Failure		500	{object}	string
+//	@Router			/requester/quotas/list [post]
+func (s *RequesterAPIServer) listQuotas(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.quotas == nil {
+		publicapi.HTTPError(ctx, res, errQuotasNotSupported, http.StatusNotImplemented)
+		return
+	}
+
+	quotas, err := s.quotas.GetQuotas(ctx)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(res).Encode(listQuotasResponse{Quotas: quotas}); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
+
+// setQuota godoc
+//
+//	@ID				pkg/requester/publicapi/setQuota
+//	@Summary		Sets the quota of a client, which only admins can do.
+//	@Tags			Quota
+//	@Accept			json
+//	@Produce		json
+//	@Param			setQuotaRequest	body		setQuotaRequest	true	" "
+//	@Success		200				{object}	setQuotaResponse
+//	@Failure		400				{object}	string
+//	@Failure		403				{object}	string
+//	@Failure		500				{object}	string
+//	@Router			/requester/quotas/set [post]
+func (s *RequesterAPIServer) setQuota(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.quotas == nil {
+		publicapi.HTTPError(ctx, res, errQuotasNotSupported, http.StatusNotImplemented)
+		return
+	}
+	payload, err := publicapi.UnmarshalSigned[model.QuotaSetPayload](req.Body)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	// we know the admin is who signed the request
+	if !s.quotas.IsAdmin(payload.ClientID) {
+		publicapi.HTTPError(ctx, res, fmt.Errorf("client %s can't change quotas", payload.ClientID), http.StatusForbidden)
+		return
+	}
+	if err := s.apiServer.AcceptAdminRequest(payload.AdminRequest); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusForbidden)
+		return
+	}
+
+	quota, err := s.quotas.SetQuota(ctx, payload)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(res).Encode(setQuotaResponse{Quota: quota}); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
+
+// deleteQuota godoc
+//
+//	@ID				pkg/requester/publicapi/deleteQuota
+//	@Summary		Deletes the quota of a client so the default quota applies to it, which only admins can do.
+//	@Tags			Quota
+//	@Accept			json
+//	@Param			deleteQuotaRequest	body	deleteQuotaRequest	true	" "
+//	@Success		200
+//	@Failure		400	{object}	string
+//	@Failure		403	{object}	string
+//	@Router			/requester/quotas/delete [post]
+func (s *RequesterAPIServer) deleteQuota(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.quotas == nil {
+		publicapi.HTTPError(ctx, res, errQuotasNotSupported, http.StatusNotImplemented)
+		return
+	}
+	payload, err := publicapi.UnmarshalSigned[model.QuotaDeletePayload](req.Body)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	if !s.quotas.IsAdmin(payload.ClientID) {
+		publicapi.HTTPError(ctx, res, fmt.Errorf("client %s can't change quotas", payload.ClientID), http.StatusForbidden)
+		return
+	}
+	if err := s.apiServer.AcceptAdminRequest(payload.AdminRequest); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusForbidden)
+		return
+	}
+
+	if err = s.quotas.DeleteQuota(ctx, payload); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
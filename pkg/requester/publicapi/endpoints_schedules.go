@@ -0,0 +1,165 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+)
+
+var errSchedulesNotSupported = errors.New("this requester node does not support schedules")
+
+type createScheduleRequest = SignedRequest[model.ScheduleCreatePayload] //nolint:unused // Swagger wants this
+
+type updateScheduleRequest = SignedRequest[model.ScheduleUpdatePayload] //nolint:unused // Swagger wants this
+
+type scheduleResponse struct {
+	Schedule model.JobSchedule `json:"schedule"`
+}
+
+type listSchedulesRequest struct {
+	// The client whose schedules to list, or all clients if empty
+	ClientID string `json:"client_id"`
+}
+
+type listSchedulesResponse struct {
+	Schedules []model.JobSchedule `json:"schedules"`
+}
+
+// createSchedule godoc
+//
+//	@ID				pkg/requester/publicapi/createSchedule
+//	@Summary		Creates a schedule that submits a job each time its cron expression fires.
+//	@Tags			Schedule
+//	@Accept			json
+//	@Produce		json
+//	@Param			createScheduleRequest	body		createScheduleRequest	true	" "
+//	@Success		200						{object}	scheduleResponse
+//	@Failure		400						{object}	string
+//	@Failure		500						{object}	string
+//	@Router			/requester/schedules/create [post]
+func (s *RequesterAPIServer) createSchedule(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.schedules == nil {
+		publicapi.HTTPError(ctx, res, errSchedulesNotSupported, http.StatusNotImplemented)
+		return
+	}
+	payload, err := publicapi.UnmarshalSigned[model.ScheduleCreatePayload](req.Body)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	// check the job could be submitted now rather than failing every run
+	err = job.VerifyJobCreatePayload(ctx, &model.JobCreatePayload{
+		ClientID:   payload.ClientID,
+		APIVersion: payload.APIVersion,
+		Spec:       payload.Spec,
+	})
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := s.schedules.CreateSchedule(ctx, payload)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	writeSchedule(res, schedule)
+}
+
+// listSchedules godoc
+//
+//	@ID				pkg/requester/publicapi/listSchedules
+//	@Summary		Lists the schedules of a client.
+//	@Tags			Schedule
+//	@Accept			json
+//	@Produce		json
+//	@Param			listSchedulesRequest	body		listSchedulesRequest	true	" "
+//	@Success		200						{object}	listSchedulesResponse
+//	@Failure		400						{object}	string
+//	@Failure		500						{object}	string
+//	@Router			/requester/schedules/list [post]
+func (s *RequesterAPIServer) listSchedules(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.schedules == nil {
+		publicapi.HTTPError(ctx, res, errSchedulesNotSupported, http.StatusNotImplemented)
+		return
+	}
+	var listReq listSchedulesRequest
+	if err := json.NewDecoder(req.Body).Decode(&listReq); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+
+	schedules, err := s.schedules.GetSchedules(ctx, listReq.ClientID)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(res).Encode(listSchedulesResponse{Schedules: schedules}); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
+
+// updateSchedule godoc
+//
+//	@ID				pkg/requester/publicapi/updateSchedule
+//	@Summary		Pauses, resumes, triggers or deletes a schedule.
+//	@Tags			Schedule
+//	@Accept			json
+//	@Produce		json
+//	@Param			updateScheduleRequest	body		updateScheduleRequest	true	" "
+//	@Success		200						{object}	scheduleResponse
+//	@Failure		400						{object}	string
+//	@Failure		403						{object}	string
+//	@Failure		500						{object}	string
+//	@Router			/requester/schedules/update [post]
+func (s *RequesterAPIServer) updateSchedule(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.schedules == nil {
+		publicapi.HTTPError(ctx, res, errSchedulesNotSupported, http.StatusNotImplemented)
+		return
+	}
+	payload, err := publicapi.UnmarshalSigned[model.ScheduleUpdatePayload](req.Body)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	// only the client that created the schedule can change it, which we know
+	// is the client that signed the request
+	schedule, err := s.schedules.GetSchedule(ctx, payload.ScheduleID)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	if schedule.ClientID != payload.ClientID {
+		publicapi.HTTPError(ctx, res, fmt.Errorf("mismatched client id: %s", payload.ClientID), http.StatusForbidden)
+		return
+	}
+
+	payload.ScheduleID = schedule.ID
+	schedule, err = s.schedules.UpdateSchedule(ctx, payload)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+	writeSchedule(res, schedule)
+}
+
+func writeSchedule(res http.ResponseWriter, schedule model.JobSchedule) {
+	res.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(res).Encode(scheduleResponse{Schedule: schedule}); err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -2,13 +2,16 @@ package publicapi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
 	"github.com/bacalhau-project/bacalhau/pkg/job"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	"github.com/rs/zerolog/log"
 	oteltrace "go.opentelemetry.io/otel/trace"
@@ -59,7 +62,7 @@ func (s *RequesterAPIServer) submit(res http.ResponseWriter, req *http.Request)
 	}
 
 	// first verify the signature on the raw bytes
-	if err := verifyRequestSignature(*submitReq.JobCreatePayload, submitReq.ClientSignature, submitReq.ClientPublicKey); err != nil {
+	if err := publicapi.VerifyRequestSignature(*submitReq.JobCreatePayload, submitReq.ClientSignature, submitReq.ClientPublicKey); err != nil {
 		log.Ctx(ctx).Debug().Msgf("====> VerifyRequestSignature error: %s", err)
 		errorResponse := bacerrors.ErrorToErrorResponse(err)
 		http.Error(res, errorResponse, http.StatusBadRequest)
@@ -75,12 +78,31 @@ func (s *RequesterAPIServer) submit(res http.ResponseWriter, req *http.Request)
 	}
 	res.Header().Set(handlerwrapper.HTTPHeaderClientID, jobCreatePayload.ClientID)
 
-	if err := verifySignedJobRequest(jobCreatePayload.ClientID, submitReq.ClientSignature, submitReq.ClientPublicKey); err != nil {
+	if err := publicapi.VerifyClientID(jobCreatePayload.ClientID, submitReq.ClientPublicKey); err != nil {
 		log.Ctx(ctx).Debug().Msgf("====> verifySignedJobRequest error: %s", err)
 		errorResponse := bacerrors.ErrorToErrorResponse(err)
 		http.Error(res, errorResponse, http.StatusBadRequest)
 		return
 	}
+	// the job keeps the signature so that compute nodes can verify who submitted it
+	jobCreatePayload.ClientSignature = &model.ClientSignature{
+		Payload:   *submitReq.JobCreatePayload,
+		Signature: submitReq.ClientSignature,
+		PublicKey: submitReq.ClientPublicKey,
+	}
+
+	if jobCreatePayload.Template != "" {
+		if s.templates == nil {
+			http.Error(res, bacerrors.ErrorToErrorResponse(errTemplatesNotSupported), http.StatusNotImplemented)
+			return
+		}
+		var err error
+		if jobCreatePayload, err = s.templates.ApplyTemplate(ctx, jobCreatePayload); err != nil {
+			log.Ctx(ctx).Debug().Msgf("====> ApplyTemplate error: %s", err)
+			http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+			return
+		}
+	}
 
 	if err := job.VerifyJobCreatePayload(ctx, &jobCreatePayload); err != nil {
 		log.Ctx(ctx).Debug().Msgf("====> VerifyJobCreate error: %s", err)
@@ -98,7 +120,13 @@ func (s *RequesterAPIServer) submit(res http.ResponseWriter, req *http.Request)
 	system.AddJobIDFromBaggageToSpan(ctx, oteltrace.SpanFromContext(ctx))
 
 	if err != nil {
-		http.Error(res, err.Error(), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.As(err, &requester.ErrQuotaExceeded{}) {
+			status = http.StatusTooManyRequests
+		} else if errors.As(err, &requester.ErrUnschedulable{}) {
+			status = http.StatusBadRequest
+		}
+		http.Error(res, err.Error(), status)
 		return
 	}
 
@@ -0,0 +1,183 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+)
+
+var errTemplatesNotSupported = errors.New("this requester node does not support job templates")
+
+type setTemplateRequest = SignedRequest[model.TemplateSetPayload] //nolint:unused // Swagger wants this
+
+type deleteTemplateRequest = SignedRequest[model.TemplateDeletePayload] //nolint:unused // Swagger wants this
+
+type getTemplateRequest struct {
+	// The name of the template to get
+	Name string `json:"name"`
+}
+
+type templateResponse struct {
+	Template model.JobTemplate `json:"template"`
+}
+
+type listTemplatesResponse struct {
+	Templates []model.JobTemplate `json:"templates"`
+}
+
+// getTemplate godoc
+//
+//	@ID				pkg/requester/publicapi/getTemplate
+//	@Summary		Returns a job template.
+//	@Tags			Template
+//	@Accept			json
+//	@Produce		json
+//	@Param			getTemplateRequest	body		getTemplateRequest	true	" "
+//	@Success		200					{object}	templateResponse
+//	@Failure		400					{object}	string
+//	@Failure		404					{object}	string
+//	@Failure		500					{object}	string
+//	@Router			/requester/templates/get [post]
+func (s *RequesterAPIServer) getTemplate(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.templates == nil {
+		publicapi.HTTPError(ctx, res, errTemplatesNotSupported, http.StatusNotImplemented)
+		return
+	}
+	var getReq getTemplateRequest
+	if err := json.NewDecoder(req.Body).Decode(&getReq); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+
+	template, err := s.templates.GetTemplate(ctx, getReq.Name)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.As(err, &jobstore.ErrTemplateNotFound{}) {
+			status = http.StatusNotFound
+		}
+		publicapi.HTTPError(ctx, res, err, status)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(res).Encode(templateResponse{Template: template}); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
+
+// listTemplates godoc
+//
+//	@ID				pkg/requester/publicapi/listTemplates
+//	@Summary		Lists the job templates.
+//	@Tags			Template
+//	@Produce		json
+//	@Success		200	{object}	listTemplatesResponse
+//	@Failure		500	{object}	string
+//	@Router			/requester/templates/list [post]
+func (s *RequesterAPIServer) listTemplates(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.templates == nil {
+		publicapi.HTTPError(ctx, res, errTemplatesNotSupported, http.StatusNotImplemented)
+		return
+	}
+
+	templates, err := s.templates.GetTemplates(ctx)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(res).Encode(listTemplatesResponse{Templates: templates}); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
+
+// setTemplate godoc
+//
+//	@ID				pkg/requester/publicapi/setTemplate
+//	@Summary		Creates a job template, or replaces the template with the same name if the client created it.
+//	@Tags			Template
+//	@Accept			json
+//	@Produce		json
+//	@Param			setTemplateRequest	body		setTemplateRequest	true	" "
+//	@Success		200					{object}	templateResponse
+//	@Failure		400					{object}	string
+//	@Failure		403					{object}	string
+//	@Failure		500					{object}	string
+//	@Router			/requester/templates/set [post]
+func (s *RequesterAPIServer) setTemplate(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.templates == nil {
+		publicapi.HTTPError(ctx, res, errTemplatesNotSupported, http.StatusNotImplemented)
+		return
+	}
+	payload, err := publicapi.UnmarshalSigned[model.TemplateSetPayload](req.Body)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	existing, err := s.templates.GetTemplate(ctx, payload.Template.Name)
+	if err == nil && existing.ClientID != payload.ClientID {
+		publicapi.HTTPError(ctx, res, fmt.Errorf("mismatched client id: %s", payload.ClientID), http.StatusForbidden)
+		return
+	}
+
+	template, err := s.templates.SetTemplate(ctx, payload)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(res).Encode(templateResponse{Template: template}); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
+
+// deleteTemplate godoc
+//
+//	@ID				pkg/requester/publicapi/deleteTemplate
+//	@Summary		Deletes a job template that the client created.
+//	@Tags			Template
+//	@Accept			json
+//	@Param			deleteTemplateRequest	body	deleteTemplateRequest	true	" "
+//	@Success		200
+//	@Failure		400	{object}	string
+//	@Failure		403	{object}	string
+//	@Router			/requester/templates/delete [post]
+func (s *RequesterAPIServer) deleteTemplate(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.templates == nil {
+		publicapi.HTTPError(ctx, res, errTemplatesNotSupported, http.StatusNotImplemented)
+		return
+	}
+	payload, err := publicapi.UnmarshalSigned[model.TemplateDeletePayload](req.Body)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	template, err := s.templates.GetTemplate(ctx, payload.Name)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	if template.ClientID != payload.ClientID {
+		publicapi.HTTPError(ctx, res, fmt.Errorf("mismatched client id: %s", payload.ClientID), http.StatusForbidden)
+		return
+	}
+
+	if err = s.templates.DeleteTemplate(ctx, payload); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
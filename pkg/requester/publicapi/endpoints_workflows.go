@@ -0,0 +1,204 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+)
+
+var errWorkflowsNotSupported = errors.New("this requester node does not support workflows")
+
+type submitWorkflowRequest = SignedRequest[model.WorkflowSubmitPayload] //nolint:unused // Swagger wants this
+
+type cancelWorkflowRequest = SignedRequest[model.WorkflowCancelPayload] //nolint:unused // Swagger wants this
+
+type workflowResponse struct {
+	Workflow model.Workflow `json:"workflow"`
+}
+
+type getWorkflowRequest struct {
+	// The id of the workflow, which can be a short id
+	WorkflowID string `json:"workflow_id"`
+}
+
+type listWorkflowsRequest struct {
+	// The client whose workflows to list, or all clients if empty
+	ClientID string `json:"client_id"`
+}
+
+type listWorkflowsResponse struct {
+	Workflows []model.Workflow `json:"workflows"`
+}
+
+// submitWorkflow godoc
+//
+//	@ID				pkg/requester/publicapi/submitWorkflow
+//	@Summary		Submits a workflow, whose stages are submitted once the stages they depend on complete.
+//	@Tags			Workflow
+//	@Accept			json
+//	@Produce		json
+//	@Param			submitWorkflowRequest	body		submitWorkflowRequest	true	" "
+//	@Success		200						{object}	workflowResponse
+//	@Failure		400						{object}	string
+//	@Failure		500						{object}	string
+//	@Router			/requester/workflows/submit [post]
+func (s *RequesterAPIServer) submitWorkflow(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.workflows == nil {
+		publicapi.HTTPError(ctx, res, errWorkflowsNotSupported, http.StatusNotImplemented)
+		return
+	}
+	payload, err := publicapi.UnmarshalSigned[model.WorkflowSubmitPayload](req.Body)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	// check every stage could be submitted rather than failing half way
+	for i := range payload.Stages {
+		err = job.VerifyJobCreatePayload(ctx, &model.JobCreatePayload{
+			ClientID:   payload.ClientID,
+			APIVersion: payload.APIVersion,
+			Spec:       &payload.Stages[i].Spec,
+		})
+		if err != nil {
+			publicapi.HTTPError(ctx, res, fmt.Errorf("stage %s: %w", payload.Stages[i].Name, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	workflow, err := s.workflows.SubmitWorkflow(ctx, payload)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	writeWorkflow(res, workflow)
+}
+
+// getWorkflow godoc
+//
+//	@ID				pkg/requester/publicapi/getWorkflow
+//	@Summary		Returns a workflow and the state of each of its stages.
+//	@Tags			Workflow
+//	@Accept			json
+//	@Produce		json
+//	@Param			getWorkflowRequest	body		getWorkflowRequest	true	" "
+//	@Success		200					{object}	workflowResponse
+//	@Failure		400					{object}	string
+//	@Failure		500					{object}	string
+//	@Router			/requester/workflows/get [post]
+func (s *RequesterAPIServer) getWorkflow(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.workflows == nil {
+		publicapi.HTTPError(ctx, res, errWorkflowsNotSupported, http.StatusNotImplemented)
+		return
+	}
+	var getReq getWorkflowRequest
+	if err := json.NewDecoder(req.Body).Decode(&getReq); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+
+	workflow, err := s.workflows.GetWorkflow(ctx, getReq.WorkflowID)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	writeWorkflow(res, workflow)
+}
+
+// listWorkflows godoc
+//
+//	@ID				pkg/requester/publicapi/listWorkflows
+//	@Summary		Lists the workflows of a client.
+//	@Tags			Workflow
+//	@Accept			json
+//	@Produce		json
+//	@Param			listWorkflowsRequest	body		listWorkflowsRequest	true	" "
+//	@Success		200						{object}	listWorkflowsResponse
+//	@Failure		400						{object}	string
+//	@Failure		500						{object}	string
+//	@Router			/requester/workflows/list [post]
+func (s *RequesterAPIServer) listWorkflows(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.workflows == nil {
+		publicapi.HTTPError(ctx, res, errWorkflowsNotSupported, http.StatusNotImplemented)
+		return
+	}
+	var listReq listWorkflowsRequest
+	if err := json.NewDecoder(req.Body).Decode(&listReq); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+
+	workflows, err := s.workflows.GetWorkflows(ctx, listReq.ClientID)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(res).Encode(listWorkflowsResponse{Workflows: workflows}); err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusInternalServerError)
+	}
+}
+
+// cancelWorkflow godoc
+//
+//	@ID				pkg/requester/publicapi/cancelWorkflow
+//	@Summary		Cancels the running jobs and pending stages of a workflow.
+//	@Tags			Workflow
+//	@Accept			json
+//	@Produce		json
+//	@Param			cancelWorkflowRequest	body		cancelWorkflowRequest	true	" "
+//	@Success		200						{object}	workflowResponse
+//	@Failure		400						{object}	string
+//	@Failure		403						{object}	string
+//	@Failure		500						{object}	string
+//	@Router			/requester/workflows/cancel [post]
+func (s *RequesterAPIServer) cancelWorkflow(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if s.workflows == nil {
+		publicapi.HTTPError(ctx, res, errWorkflowsNotSupported, http.StatusNotImplemented)
+		return
+	}
+	payload, err := publicapi.UnmarshalSigned[model.WorkflowCancelPayload](req.Body)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	// only the client that submitted the workflow can cancel it, which we know
+	// is the client that signed the request
+	workflow, err := s.workflows.GetWorkflow(ctx, payload.WorkflowID)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	if workflow.ClientID != payload.ClientID {
+		publicapi.HTTPError(ctx, res, fmt.Errorf("mismatched client id: %s", payload.ClientID), http.StatusForbidden)
+		return
+	}
+
+	payload.WorkflowID = workflow.ID
+	workflow, err = s.workflows.CancelWorkflow(ctx, payload)
+	if err != nil {
+		publicapi.HTTPError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	writeWorkflow(res, workflow)
+}
+
+func writeWorkflow(res http.ResponseWriter, workflow model.Workflow) {
+	res.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(res).Encode(workflowResponse{Workflow: workflow}); err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -3,6 +3,7 @@ package publicapi
 import (
 	"net/http"
 
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
@@ -15,19 +16,42 @@ import (
 const APIPrefix = "requester/"
 
 type RequesterAPIServerParams struct {
-	APIServer          *publicapi.APIServer
-	Requester          requester.Endpoint
+	APIServer *publicapi.APIServer
+	Requester requester.Endpoint
+	// Schedules is nil if the requester doesn't support schedules
+	Schedules requester.Schedules
+	// Workflows is nil if the requester doesn't support workflows
+	Workflows requester.Workflows
+	// Quotas is nil if the requester doesn't support quotas
+	Quotas requester.Quotas
+	// NodeAccess is nil if the requester doesn't support node access rules
+	NodeAccess requester.NodeAccess
+	// Queue is nil if the requester doesn't tell about its queue
+	Queue requester.QueueInfo
+	// Templates is nil if the requester doesn't support job templates
+	Templates requester.Templates
+	// Placements is nil if the requester doesn't support simulating placements
+	Placements         requester.Placements
 	DebugInfoProviders []model.DebugInfoProvider
 	JobStore           jobstore.Store
 	StorageProviders   storage.StorageProvider
+	LogStreamer        compute.LogStreamer
 }
 
 type RequesterAPIServer struct {
 	apiServer          *publicapi.APIServer
 	requester          requester.Endpoint
+	schedules          requester.Schedules
+	workflows          requester.Workflows
+	quotas             requester.Quotas
+	nodeAccess         requester.NodeAccess
+	queue              requester.QueueInfo
+	templates          requester.Templates
+	placements         requester.Placements
 	debugInfoProviders []model.DebugInfoProvider
 	jobStore           jobstore.Store
 	storageProviders   storage.StorageProvider
+	logStreamer        compute.LogStreamer
 	// jobId or "" (for all events) -> connections for that subscription
 	websockets      map[string][]*websocket.Conn
 	websocketsMutex sync.RWMutex
@@ -37,9 +61,17 @@ func NewRequesterAPIServer(params RequesterAPIServerParams) *RequesterAPIServer
 	return &RequesterAPIServer{
 		apiServer:          params.APIServer,
 		requester:          params.Requester,
+		schedules:          params.Schedules,
+		workflows:          params.Workflows,
+		quotas:             params.Quotas,
+		nodeAccess:         params.NodeAccess,
+		queue:              params.Queue,
+		templates:          params.Templates,
+		placements:         params.Placements,
 		debugInfoProviders: params.DebugInfoProviders,
 		jobStore:           params.JobStore,
 		storageProviders:   params.StorageProviders,
+		logStreamer:        params.LogStreamer,
 		websockets:         make(map[string][]*websocket.Conn),
 	}
 }
@@ -53,7 +85,28 @@ func (s *RequesterAPIServer) RegisterAllHandlers() error {
 		{URI: "/" + APIPrefix + "submit", Handler: http.HandlerFunc(s.submit)},
 		{URI: "/" + APIPrefix + "approve", Handler: http.HandlerFunc(s.approve)},
 		{URI: "/" + APIPrefix + "cancel", Handler: http.HandlerFunc(s.cancel)},
+		{URI: "/" + APIPrefix + "schedules/create", Handler: http.HandlerFunc(s.createSchedule)},
+		{URI: "/" + APIPrefix + "schedules/list", Handler: http.HandlerFunc(s.listSchedules)},
+		{URI: "/" + APIPrefix + "schedules/update", Handler: http.HandlerFunc(s.updateSchedule)},
+		{URI: "/" + APIPrefix + "workflows/submit", Handler: http.HandlerFunc(s.submitWorkflow)},
+		{URI: "/" + APIPrefix + "workflows/get", Handler: http.HandlerFunc(s.getWorkflow)},
+		{URI: "/" + APIPrefix + "workflows/list", Handler: http.HandlerFunc(s.listWorkflows)},
+		{URI: "/" + APIPrefix + "workflows/cancel", Handler: http.HandlerFunc(s.cancelWorkflow)},
+		{URI: "/" + APIPrefix + "quotas/get", Handler: http.HandlerFunc(s.getQuota)},
+		{URI: "/" + APIPrefix + "quotas/list", Handler: http.HandlerFunc(s.listQuotas)},
+		{URI: "/" + APIPrefix + "quotas/set", Handler: http.HandlerFunc(s.setQuota)},
+		{URI: "/" + APIPrefix + "quotas/delete", Handler: http.HandlerFunc(s.deleteQuota)},
+		{URI: "/" + APIPrefix + "nodes/access/list", Handler: http.HandlerFunc(s.listNodeAccessRules)},
+		{URI: "/" + APIPrefix + "nodes/access/set", Handler: http.HandlerFunc(s.setNodeAccessRule)},
+		{URI: "/" + APIPrefix + "nodes/access/delete", Handler: http.HandlerFunc(s.deleteNodeAccessRule)},
+		{URI: "/" + APIPrefix + "queue", Handler: http.HandlerFunc(s.getQueue)},
+		{URI: "/" + APIPrefix + "templates/get", Handler: http.HandlerFunc(s.getTemplate)},
+		{URI: "/" + APIPrefix + "templates/list", Handler: http.HandlerFunc(s.listTemplates)},
+		{URI: "/" + APIPrefix + "templates/set", Handler: http.HandlerFunc(s.setTemplate)},
+		{URI: "/" + APIPrefix + "templates/delete", Handler: http.HandlerFunc(s.deleteTemplate)},
+		{URI: "/" + APIPrefix + "placement", Handler: http.HandlerFunc(s.placement)},
 		{URI: "/" + APIPrefix + "websocket/events", Handler: http.HandlerFunc(s.websocketJobEvents), Raw: true},
+		{URI: "/" + APIPrefix + "websocket/logs", Handler: http.HandlerFunc(s.logs), Raw: true},
 		{URI: "/" + APIPrefix + "debug", Handler: http.HandlerFunc(s.debug)},
 	}
 	return s.apiServer.RegisterHandlers(handlerConfigs...)
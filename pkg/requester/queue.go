@@ -1,23 +1,61 @@
 package requester
 
 import (
+	"container/heap"
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	sync "github.com/bacalhau-project/golang-mutex-tracer"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 )
 
+const (
+	// queueRetryInterval is how often jobs that are waiting for compute
+	// capacity are tried again.
+	queueRetryInterval = 5 * time.Second
+
+	// recentStarts is how many of the jobs that most recently left the queue
+	// the start times of queued jobs are estimated from.
+	recentStarts = 20
+)
+
+// queue holds the jobs that have been approved to run until there is compute
+// capacity to start them, starting them in order of priority and then in the
+// order they were approved, so that urgent jobs jump ahead of bulk ones. Jobs
+// of clients that are running as many jobs as their quota allows are held
+// back until one of them finishes. Small, short jobs may be backfilled into
+// the capacity left over while the job at the front waits.
 type queue struct {
-	scheduler Scheduler
-	store     jobstore.Store
+	scheduler      Scheduler
+	store          jobstore.Store
+	quotas         *QuotaManager
+	backfillPolicy BackfillPolicy
+	waiting        queuedJobs
+	// when the jobs that most recently left the queue started, oldest first
+	starts []time.Time
+	seq    uint64
+	retry  *time.Timer
+	mu     sync.Mutex
 }
 
-func NewQueue(store jobstore.Store, scheduler Scheduler) Queue {
-	return &queue{
-		scheduler: scheduler,
-		store:     store,
+// NewQueue returns a queue that starts jobs with the scheduler, and doesn't
+// limit how many jobs each client runs if quotas is nil.
+func NewQueue(store jobstore.Store, scheduler Scheduler, quotas *QuotaManager, backfill BackfillPolicy) Queue {
+	q := &queue{
+		scheduler:      scheduler,
+		store:          store,
+		quotas:         quotas,
+		backfillPolicy: backfill,
 	}
+	q.mu.EnableTracerWithOpts(sync.Opts{
+		Threshold: 10 * time.Millisecond,
+		Id:        "Queue.mu",
+	})
+	return q
 }
 
 func (q *queue) EnqueueJob(ctx context.Context, job model.Job) error {
@@ -30,28 +68,155 @@ func (q *queue) EnqueueJob(ctx context.Context, job model.Job) error {
 	})
 }
 
+// StartJob starts the job if there is compute capacity for it and no more
+// urgent job is waiting, and otherwise leaves it queued until there is.
 func (q *queue) StartJob(ctx context.Context, req StartJobRequest) error {
-	err := q.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
-		JobID: req.Job.Metadata.ID,
-		Condition: jobstore.UpdateJobCondition{
-			ExpectedState: model.JobStateQueued,
-		},
-		NewState: model.JobStateNew,
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	heap.Push(&q.waiting, &queuedJob{job: req.Job, seq: q.seq, queuedAt: time.Now()})
+	return q.dispatch(ctx, req.Job.Metadata.ID)
+}
+
+// dispatch starts the waiting jobs in order until one of them has to wait for
+// capacity, and returns the error starting the job with the ID so that whoever
+// asked to start it hears why it couldn't be. Other jobs that can't be started
+// are cancelled, as there is no one to tell.
+func (q *queue) dispatch(ctx context.Context, jobID string) error {
+	var result error
+	var held []*queuedJob
+	defer func() {
+		for _, queued := range held {
+			heap.Push(&q.waiting, queued)
+		}
+		if len(held) > 0 {
+			q.retryLater()
+		}
+	}()
+
+	for q.waiting.Len() > 0 {
+		next := q.waiting[0]
+		if !q.canStart(ctx, next.job) {
+			next.holdForQuota()
+			held = append(held, heap.Pop(&q.waiting).(*queuedJob))
+			continue
+		}
+		err := q.scheduler.StartJob(ctx, StartJobRequest{Job: next.job})
+		var noCapacity ErrNoCapacity
+		if errors.As(err, &noCapacity) {
+			next.reason, next.message = model.QueueReasonNoCapacity, err.Error()
+			log.Ctx(ctx).Debug().Err(err).Int("waiting", q.waiting.Len()).Msgf("job %s is waiting for capacity", next.job.Metadata.ID)
+			if backfillErr := q.backfill(ctx, next, jobID); backfillErr != nil {
+				result = backfillErr
+			}
+			q.retryLater()
+			return result
+		}
+		heap.Pop(&q.waiting)
+		if next.job.Metadata.ID == jobID {
+			result = err
+		}
+		q.settle(ctx, next.job, jobID, err)
+	}
+	return result
+}
+
+// settle deals with the error starting a job that left the queue. Jobs that can
+// never be scheduled fail so that they don't hang in flight, and so do other
+// jobs that failed to start unless the job has the ID, as whoever asked to
+// start it hears why instead.
+func (q *queue) settle(ctx context.Context, job model.Job, jobID string, err error) {
+	if err == nil {
+		q.starts = append(q.starts, time.Now())
+		if len(q.starts) > recentStarts {
+			q.starts = q.starts[len(q.starts)-recentStarts:]
+		}
+		return
+	}
+	var alreadyTerminal jobstore.ErrJobAlreadyTerminal
+	var unschedulable ErrUnschedulable
+	switch {
+	case errors.As(err, &unschedulable):
+		q.abandon(ctx, job, err)
+	case job.Metadata.ID == jobID:
+	case errors.As(err, &alreadyTerminal):
+		// the job was cancelled while it was waiting
+	case err != nil:
+		q.abandon(ctx, job, err)
+	}
+}
+
+// abandon cancels a queued job that failed to start.
+func (q *queue) abandon(ctx context.Context, job model.Job, err error) {
+	log.Ctx(ctx).Error().Err(err).Msgf("failed to start queued job %s", job.Metadata.ID)
+	_, cancelErr := q.scheduler.CancelJob(ctx, CancelJobRequest{
+		JobID:  job.Metadata.ID,
+		Reason: err.Error(),
 	})
+	if cancelErr != nil {
+		log.Ctx(ctx).Error().Err(cancelErr).Msgf("failed to cancel queued job %s", job.Metadata.ID)
+	}
+}
+
+// remove takes the job out of the waiting jobs.
+func (q *queue) remove(queued *queuedJob) {
+	for i, other := range q.waiting {
+		if other == queued {
+			heap.Remove(&q.waiting, i)
+			return
+		}
+	}
+}
+
+// canStart returns whether the quota of the job's client lets it start now.
+func (q *queue) canStart(ctx context.Context, job model.Job) bool {
+	if q.quotas == nil {
+		return true
+	}
+	ok, err := q.quotas.CanStart(ctx, job)
 	if err != nil {
-		return err
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to check the quota of the client of job %s", job.Metadata.ID)
+	}
+	if !ok {
+		log.Ctx(ctx).Debug().Msgf("job %s is waiting for other jobs of client %s to finish", job.Metadata.ID, job.Metadata.ClientID)
 	}
+	return ok
+}
 
-	return q.scheduler.StartJob(ctx, req)
+// retryLater dispatches the waiting jobs again after a while, unless that is
+// already going to happen.
+func (q *queue) retryLater() {
+	if q.retry != nil {
+		return
+	}
+	q.retry = time.AfterFunc(queueRetryInterval, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.retry = nil
+		_ = q.dispatch(context.Background(), "")
+	})
 }
 
 func (q *queue) CancelJob(ctx context.Context, req CancelJobRequest) (CancelJobResult, error) {
+	q.mu.Lock()
+	for i, queued := range q.waiting {
+		if queued.job.Metadata.ID == req.JobID {
+			heap.Remove(&q.waiting, i)
+			break
+		}
+	}
+	q.mu.Unlock()
+
+	newState := model.JobStateCancelled
+	if req.TimedOut {
+		newState = model.JobStateTimedOut
+	}
 	err := q.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
 		JobID: req.JobID,
 		Condition: jobstore.UpdateJobCondition{
 			ExpectedState: model.JobStateQueued,
 		},
-		NewState: model.JobStateCancelled,
+		NewState: newState,
 		Comment:  req.Reason,
 	})
 	var invalidJobErr jobstore.ErrInvalidJobState
@@ -60,3 +225,45 @@ func (q *queue) CancelJob(ctx context.Context, req CancelJobRequest) (CancelJobR
 	}
 	return CancelJobResult{}, err
 }
+
+type queuedJob struct {
+	job      model.Job
+	seq      uint64
+	queuedAt time.Time
+	// why the job didn't start the last time it was tried, if it was
+	reason  model.QueueReason
+	message string
+}
+
+// holdForQuota records that the job waits for other jobs of its client to
+// finish.
+func (j *queuedJob) holdForQuota() {
+	j.reason = model.QueueReasonQuota
+	j.message = fmt.Sprintf("client %s is running as many jobs as its quota allows", j.job.Metadata.ClientID)
+}
+
+// queuedJobs is a heap of the jobs waiting to start, with the most urgent job
+// that was queued first at the top.
+type queuedJobs []*queuedJob
+
+func (h queuedJobs) Len() int { return len(h) }
+
+func (h queuedJobs) Less(i, j int) bool {
+	if h[i].job.Spec.Priority != h[j].job.Spec.Priority {
+		return h[i].job.Spec.Priority > h[j].job.Spec.Priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h queuedJobs) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *queuedJobs) Push(x any) { *h = append(*h, x.(*queuedJob)) }
+
+func (h *queuedJobs) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
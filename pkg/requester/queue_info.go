@@ -0,0 +1,89 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// GetQueuedJobs returns the jobs that wait in the queue in the order they start
+// in, with why each of them waits and when it is expected to start. Jobs that
+// wait behind others are checked for whether enough nodes suit them, so that
+// their clients hear before the jobs reach the front of the queue and fail.
+func (q *queue) GetQueuedJobs(ctx context.Context) ([]model.QueuedJob, error) {
+	q.mu.Lock()
+	waiting := make(queuedJobs, len(q.waiting))
+	copy(waiting, q.waiting)
+	sort.Sort(waiting)
+	result := make([]model.QueuedJob, len(waiting))
+	for i, queued := range waiting {
+		result[i] = model.QueuedJob{
+			JobID:    queued.job.Metadata.ID,
+			ClientID: queued.job.Metadata.ClientID,
+			Priority: queued.job.Spec.Priority,
+			Position: i + 1,
+			QueuedAt: queued.queuedAt,
+			Reason:   queued.reason,
+			Message:  queued.message,
+		}
+		if i > 0 && queued.reason != model.QueueReasonQuota {
+			result[i].Reason = model.QueueReasonPriority
+			result[i].Message = fmt.Sprintf("waiting for the %d more urgent or earlier jobs to start", i)
+		} else if result[i].Reason == "" {
+			result[i].Reason = model.QueueReasonStarting
+		}
+	}
+	interval := q.startInterval()
+	q.mu.Unlock()
+
+	// rank the nodes outside the lock, as it can take a while
+	placements, canSimulate := q.scheduler.(Placements)
+	now := time.Now()
+	for i := range result {
+		if canSimulate && result[i].Reason == model.QueueReasonPriority {
+			q.checkMatchingNodes(ctx, placements, waiting[i].job, &result[i])
+		}
+		switch result[i].Reason {
+		case model.QueueReasonQuota, model.QueueReasonNoMatchingNodes:
+		default:
+			if interval > 0 {
+				result[i].EstimatedStartAt = now.Add(time.Duration(result[i].Position) * interval)
+			}
+		}
+	}
+	return result, nil
+}
+
+// checkMatchingNodes records that the job waits for more nodes to suit it if
+// not enough of them do.
+func (q *queue) checkMatchingNodes(ctx context.Context, placements Placements, job model.Job, queued *model.QueuedJob) {
+	plan, err := placements.SimulatePlacement(ctx, job)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msgf("failed to check the nodes that suit queued job %s", job.Metadata.ID)
+		return
+	}
+	suitable := 0
+	for _, node := range plan.Nodes {
+		if node.Rank >= 0 {
+			suitable++
+		}
+	}
+	if suitable < plan.RequestedNodes {
+		queued.Reason = model.QueueReasonNoMatchingNodes
+		queued.Message = plan.Reason
+	}
+}
+
+// startInterval returns how long apart the jobs that most recently left the
+// queue started on average, or zero if too few have to tell.
+// make sure to call this function with the lock held
+func (q *queue) startInterval() time.Duration {
+	if len(q.starts) < 2 { //nolint:gomnd // an interval needs two starts
+		return 0
+	}
+	return q.starts[len(q.starts)-1].Sub(q.starts[0]) / time.Duration(len(q.starts)-1)
+}
@@ -0,0 +1,188 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueStartsWaitingJobsByPriority(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+	full := true
+	var started []string
+	q := NewQueue(store, &mockScheduler{
+		handleStartJob: func(ctx context.Context, sjr StartJobRequest) error {
+			if full {
+				return NewErrNoCapacity(1, 0)
+			}
+			started = append(started, sjr.Job.Metadata.ID)
+			return nil
+		},
+	}, nil, BackfillPolicy{}).(*queue)
+	t.Cleanup(func() {
+		if q.retry != nil {
+			q.retry.Stop()
+		}
+	})
+
+	for _, j := range []struct {
+		id       string
+		priority model.Priority
+	}{
+		{"low-job-1", model.PriorityLow},
+		{"normal-job-1", model.PriorityNormal},
+		{"high-job-1", model.PriorityHigh},
+		{"normal-job-2", model.PriorityNormal},
+		{"cancelled", model.PriorityHigh},
+		{"timed-out", model.PriorityNormal},
+	} {
+		job := model.Job{Metadata: model.Metadata{ID: j.id}, Spec: model.Spec{Priority: j.priority}}
+		require.NoError(t, store.CreateJob(ctx, job))
+		require.NoError(t, q.EnqueueJob(ctx, job))
+		require.NoError(t, q.StartJob(ctx, StartJobRequest{Job: job}), "jobs wait for capacity rather than failing")
+	}
+	require.Empty(t, started)
+	require.Equal(t, 6, q.waiting.Len())
+	require.NotNil(t, q.retry, "waiting jobs are tried again later")
+
+	_, err := q.CancelJob(ctx, CancelJobRequest{JobID: "cancelled"})
+	require.NoError(t, err)
+	state, err := store.GetJobState(ctx, "cancelled")
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateCancelled, state.State)
+
+	_, err = q.CancelJob(ctx, CancelJobRequest{JobID: "timed-out", TimedOut: true})
+	require.NoError(t, err)
+	state, err = store.GetJobState(ctx, "timed-out")
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateTimedOut, state.State)
+
+	full = false
+	q.mu.Lock()
+	require.NoError(t, q.dispatch(ctx, ""))
+	q.mu.Unlock()
+	require.Equal(t, []string{"high-job-1", "normal-job-1", "normal-job-2", "low-job-1"}, started)
+	require.Zero(t, q.waiting.Len())
+}
+
+func TestQueueReturnsErrorsStartingTheJob(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+	q := NewQueue(store, &mockScheduler{
+		handleStartJob: func(ctx context.Context, sjr StartJobRequest) error {
+			return NewErrNotEnoughNodes(3, 1)
+		},
+	}, nil, BackfillPolicy{})
+
+	job := model.Job{Metadata: model.Metadata{ID: "job-id-1"}}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, q.EnqueueJob(ctx, job))
+	require.ErrorAs(t, q.StartJob(ctx, StartJobRequest{Job: job}), &ErrNotEnoughNodes{})
+}
+
+func TestEndpointLimitsHighPriorityJobsPerClient(t *testing.T) {
+	strategy := mockBidStrategy{response: bidstrategy.BidStrategyResponse{ShouldBid: true}}
+	endpoint, store := getTestEndpoint(t, &strategy)
+	endpoint.(*BaseEndpoint).maxHighPriorityJobsPerClient = 1
+
+	submit := func(clientID string, priority model.Priority) (*model.Job, error) {
+		return endpoint.SubmitJob(context.Background(), model.JobCreatePayload{
+			ClientID: clientID,
+			Spec:     &model.Spec{Priority: priority},
+		})
+	}
+
+	job, err := submit("client", model.PriorityHigh)
+	require.NoError(t, err)
+	_, err = submit("client", model.PriorityHigh)
+	require.Error(t, err)
+	_, err = submit("client", model.PriorityNormal)
+	require.NoError(t, err, "the limit is only on high priority jobs")
+	_, err = submit("other-client", model.PriorityHigh)
+	require.NoError(t, err, "the limit is for each client")
+
+	require.NoError(t, store.UpdateJobState(context.Background(), jobstore.UpdateJobStateRequest{
+		JobID:    job.Metadata.ID,
+		NewState: model.JobStateCompleted,
+	}))
+	_, err = submit("client", model.PriorityHigh)
+	require.NoError(t, err, "jobs that are done don't count towards the limit")
+}
+
+func TestNodesWithCapacity(t *testing.T) {
+	node := func(maxCapacity, available model.ResourceUsageData) NodeRank {
+		return NodeRank{NodeInfo: model.NodeInfo{ComputeNodeInfo: model.ComputeNodeInfo{
+			MaxCapacity:       maxCapacity,
+			AvailableCapacity: available,
+		}}}
+	}
+	nodes := []NodeRank{
+		node(model.ResourceUsageData{}, model.ResourceUsageData{}),
+		node(model.ResourceUsageData{CPU: 4}, model.ResourceUsageData{CPU: 2}),
+		node(model.ResourceUsageData{CPU: 4}, model.ResourceUsageData{}),
+	}
+
+	require.Equal(t, 2, nodesWithCapacity(model.Job{}, nodes), "nodes that don't report capacity are assumed to have it")
+
+	job := model.Job{Spec: model.Spec{Resources: model.ResourceUsageConfig{CPU: "3"}}}
+	require.Equal(t, 1, nodesWithCapacity(job, nodes))
+}
+
+func TestGetQueuedJobs(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+	q := NewQueue(store, &mockScheduler{
+		handleStartJob: func(ctx context.Context, sjr StartJobRequest) error {
+			return NewErrNoCapacity(1, 0)
+		},
+	}, nil, BackfillPolicy{}).(*queue)
+	t.Cleanup(func() {
+		if q.retry != nil {
+			q.retry.Stop()
+		}
+	})
+
+	for _, j := range []struct {
+		id       string
+		priority model.Priority
+	}{
+		{"normal-job", model.PriorityNormal},
+		{"high-job", model.PriorityHigh},
+		{"low-job", model.PriorityLow},
+	} {
+		job := model.Job{Metadata: model.Metadata{ID: j.id}, Spec: model.Spec{Priority: j.priority}}
+		require.NoError(t, store.CreateJob(ctx, job))
+		require.NoError(t, q.EnqueueJob(ctx, job))
+		require.NoError(t, q.StartJob(ctx, StartJobRequest{Job: job}))
+	}
+
+	queued, err := q.GetQueuedJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, queued, 3)
+	for i, id := range []string{"high-job", "normal-job", "low-job"} {
+		require.Equal(t, id, queued[i].JobID)
+		require.Equal(t, i+1, queued[i].Position)
+		require.True(t, queued[i].EstimatedStartAt.IsZero(), "no jobs have started to estimate from")
+	}
+	require.Equal(t, model.QueueReasonNoCapacity, queued[0].Reason)
+	require.Equal(t, model.QueueReasonPriority, queued[1].Reason)
+	require.Equal(t, model.QueueReasonPriority, queued[2].Reason)
+
+	q.mu.Lock()
+	now := time.Now()
+	q.starts = []time.Time{now.Add(-2 * time.Minute), now.Add(-time.Minute), now}
+	q.mu.Unlock()
+	queued, err = q.GetQueuedJobs(ctx)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Minute), queued[0].EstimatedStartAt, time.Second)
+	require.WithinDuration(t, time.Now().Add(3*time.Minute), queued[2].EstimatedStartAt, time.Second)
+}
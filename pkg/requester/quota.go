@@ -0,0 +1,212 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/c2h5oh/datasize"
+	"golang.org/x/exp/slices"
+)
+
+// submissionWindow is the window that the submissions of clients are rate
+// limited over.
+const submissionWindow = time.Minute
+
+type QuotaManagerParams struct {
+	Store    jobstore.QuotaStore
+	JobStore jobstore.Store
+	// Default is the quota of clients that don't have their own
+	Default model.ClientQuota
+	// Admins are the clients that can change the quotas of other clients
+	Admins []string
+}
+
+// QuotaManager limits how many jobs each client can run, queue and submit,
+// and how many resources their jobs can request between them. Clients get the
+// default quota unless an admin has set one of their own.
+type QuotaManager struct {
+	store        jobstore.QuotaStore
+	jobStore     jobstore.Store
+	defaultQuota model.ClientQuota
+	admins       []string
+	// when each client submitted the jobs of the last window
+	submissions map[string][]time.Time
+	mu          sync.Mutex
+}
+
+func NewQuotaManager(params QuotaManagerParams) *QuotaManager {
+	return &QuotaManager{
+		store:        params.Store,
+		jobStore:     params.JobStore,
+		defaultQuota: params.Default,
+		admins:       params.Admins,
+		submissions:  make(map[string][]time.Time),
+	}
+}
+
+// IsAdmin returns whether the client can change quotas.
+func (q *QuotaManager) IsAdmin(clientID string) bool {
+	return clientID != "" && slices.Contains(q.admins, clientID)
+}
+
+// GetQuota returns the quota of the client, which is the default quota if it
+// doesn't have its own, and how much of it the client is using.
+func (q *QuotaManager) GetQuota(ctx context.Context, clientID string) (model.ClientQuota, model.ClientUsage, error) {
+	quota, err := q.quotaFor(ctx, clientID)
+	if err != nil {
+		return model.ClientQuota{}, model.ClientUsage{}, err
+	}
+	usage, err := q.usage(ctx, clientID)
+	return quota, usage, err
+}
+
+// GetQuotas returns the quotas of the clients that have their own.
+func (q *QuotaManager) GetQuotas(ctx context.Context) ([]model.ClientQuota, error) {
+	return q.store.GetQuotas(ctx)
+}
+
+func (q *QuotaManager) SetQuota(ctx context.Context, payload model.QuotaSetPayload) (model.ClientQuota, error) {
+	if payload.Quota.ClientID == "" {
+		return model.ClientQuota{}, errors.New("the quota must be for a client")
+	}
+	if err := q.store.SetQuota(ctx, payload.Quota); err != nil {
+		return model.ClientQuota{}, err
+	}
+	return payload.Quota, nil
+}
+
+func (q *QuotaManager) DeleteQuota(ctx context.Context, payload model.QuotaDeletePayload) error {
+	return q.store.DeleteQuota(ctx, payload.QuotaClientID)
+}
+
+// CheckSubmission returns an ErrQuotaExceeded if the client of the job can't
+// submit it, because it would queue too many jobs, request too many resources
+// or submit too many jobs in a minute. Otherwise it counts the job against the
+// rate that the client can submit jobs at.
+func (q *QuotaManager) CheckSubmission(ctx context.Context, job model.Job) error {
+	clientID := job.Metadata.ClientID
+	quota, err := q.quotaFor(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	usage, err := q.usage(ctx, clientID)
+	if err != nil {
+		return err
+	}
+
+	if quota.MaxQueuedJobs > 0 && usage.QueuedJobs >= quota.MaxQueuedJobs {
+		return NewErrQuotaExceeded(clientID,
+			fmt.Sprintf("it already has %d jobs queued, which is the most it can have", usage.QueuedJobs))
+	}
+	maxResources := capacity.ParseResourceUsageConfig(quota.MaxResources)
+	if !maxResources.IsZero() {
+		requested := usage.Resources.Add(capacity.ParseResourceUsageConfig(job.Spec.Resources))
+		if exceeded := exceededResources(requested, maxResources); exceeded != "" {
+			return NewErrQuotaExceeded(clientID, "its jobs in flight would request "+exceeded+" between them")
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	recent := q.recentSubmissions(clientID, now)
+	if quota.MaxSubmissionsPerMinute > 0 && len(recent) >= quota.MaxSubmissionsPerMinute {
+		return NewErrQuotaExceeded(clientID,
+			fmt.Sprintf("it already submitted %d jobs in the last minute, which is the most it can submit", len(recent)))
+	}
+	q.submissions[clientID] = append(recent, now)
+	return nil
+}
+
+// CanStart returns whether the client of the job has fewer jobs running than
+// its quota allows, so that the job can start.
+func (q *QuotaManager) CanStart(ctx context.Context, job model.Job) (bool, error) {
+	quota, err := q.quotaFor(ctx, job.Metadata.ClientID)
+	if err != nil || quota.MaxConcurrentJobs <= 0 {
+		return true, err
+	}
+	usage, err := q.usage(ctx, job.Metadata.ClientID)
+	if err != nil {
+		return true, err
+	}
+	return usage.RunningJobs < quota.MaxConcurrentJobs, nil
+}
+
+func (q *QuotaManager) quotaFor(ctx context.Context, clientID string) (model.ClientQuota, error) {
+	quota, err := q.store.GetQuota(ctx, clientID)
+	if errors.As(err, &jobstore.ErrQuotaNotFound{}) {
+		quota = q.defaultQuota
+		quota.ClientID = ""
+		return quota, nil
+	}
+	return quota, err
+}
+
+// usage returns how much of its quota the client is using.
+func (q *QuotaManager) usage(ctx context.Context, clientID string) (model.ClientUsage, error) {
+	inProgress, err := q.jobStore.GetInProgressJobs(ctx)
+	if err != nil {
+		return model.ClientUsage{}, err
+	}
+	var usage model.ClientUsage
+	for _, j := range inProgress {
+		if j.Job.Metadata.ClientID != clientID {
+			continue
+		}
+		switch j.State.State {
+		case model.JobStateNew, model.JobStateQueued:
+			usage.QueuedJobs++
+		default:
+			usage.RunningJobs++
+		}
+		usage.Resources = usage.Resources.Add(capacity.ParseResourceUsageConfig(j.Job.Spec.Resources))
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	usage.SubmissionsLastMinute = len(q.recentSubmissions(clientID, time.Now()))
+	return usage, nil
+}
+
+// recentSubmissions returns when the client submitted the jobs of the window
+// up to now, forgetting older submissions.
+// make sure to call this function with the lock held
+func (q *QuotaManager) recentSubmissions(clientID string, now time.Time) []time.Time {
+	submissions := q.submissions[clientID]
+	for len(submissions) > 0 && now.Sub(submissions[0]) >= submissionWindow {
+		submissions = submissions[1:]
+	}
+	if len(submissions) == 0 {
+		delete(q.submissions, clientID)
+		return nil
+	}
+	q.submissions[clientID] = submissions
+	return submissions
+}
+
+// exceededResources describes the resources that are requested beyond the
+// most that can be, or is empty if none are.
+func exceededResources(requested, most model.ResourceUsageData) string {
+	var exceeded []string
+	if most.CPU > 0 && requested.CPU > most.CPU {
+		exceeded = append(exceeded, fmt.Sprintf("%g CPU when it can request %g", requested.CPU, most.CPU))
+	}
+	if most.Memory > 0 && requested.Memory > most.Memory {
+		exceeded = append(exceeded, fmt.Sprintf("%s of memory when it can request %s",
+			datasize.ByteSize(requested.Memory).HR(), datasize.ByteSize(most.Memory).HR()))
+	}
+	if most.GPU > 0 && requested.GPU > most.GPU {
+		exceeded = append(exceeded, fmt.Sprintf("%d GPUs when it can request %d", requested.GPU, most.GPU))
+	}
+	return strings.Join(exceeded, " and ")
+}
+
+// compile-time check that QuotaManager implements the expected interfaces
+var _ Quotas = (*QuotaManager)(nil)
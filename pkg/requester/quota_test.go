@@ -0,0 +1,94 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaManagerChecksSubmissions(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+	quotas := NewQuotaManager(QuotaManagerParams{
+		Store:    store,
+		JobStore: store,
+		Default:  model.ClientQuota{MaxQueuedJobs: 1, MaxResources: model.ResourceUsageConfig{CPU: "2"}},
+		Admins:   []string{"admin"},
+	})
+	require.True(t, quotas.IsAdmin("admin"))
+	require.False(t, quotas.IsAdmin("client"))
+
+	job := func(id, clientID, cpu string) model.Job {
+		return model.Job{
+			Metadata: model.Metadata{ID: id, ClientID: clientID},
+			Spec:     model.Spec{Resources: model.ResourceUsageConfig{CPU: cpu}},
+		}
+	}
+	require.NoError(t, quotas.CheckSubmission(ctx, job("job-1", "client", "1")))
+	require.NoError(t, store.CreateJob(ctx, job("job-1", "client", "1")))
+	require.ErrorAs(t, quotas.CheckSubmission(ctx, job("job-2", "client", "1")), &ErrQuotaExceeded{},
+		"the client already has as many jobs queued as it can")
+	require.NoError(t, quotas.CheckSubmission(ctx, job("job-2", "other-client", "1")), "the quota is for each client")
+	require.ErrorAs(t, quotas.CheckSubmission(ctx, job("job-3", "other-client", "3")), &ErrQuotaExceeded{},
+		"the job requests more CPU than the client can")
+
+	_, err := quotas.SetQuota(ctx, model.QuotaSetPayload{ClientID: "admin", Quota: model.ClientQuota{
+		ClientID:                "client",
+		MaxSubmissionsPerMinute: 2,
+	}})
+	require.NoError(t, err)
+	require.NoError(t, quotas.CheckSubmission(ctx, job("job-2", "client", "4")), "the client's own quota replaces the default")
+	require.ErrorAs(t, quotas.CheckSubmission(ctx, job("job-3", "client", "1")), &ErrQuotaExceeded{},
+		"the client already submitted as many jobs this minute as it can")
+
+	quota, usage, err := quotas.GetQuota(ctx, "client")
+	require.NoError(t, err)
+	require.Equal(t, "client", quota.ClientID)
+	require.Equal(t, model.ClientUsage{
+		QueuedJobs:            1,
+		Resources:             model.ResourceUsageData{CPU: 1},
+		SubmissionsLastMinute: 2,
+	}, usage)
+
+	require.NoError(t, quotas.DeleteQuota(ctx, model.QuotaDeletePayload{ClientID: "admin", QuotaClientID: "client"}))
+	quota, _, err = quotas.GetQuota(ctx, "client")
+	require.NoError(t, err)
+	require.Empty(t, quota.ClientID, "the client has the default quota again")
+}
+
+func TestQuotaManagerLimitsRunningJobs(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+	quotas := NewQuotaManager(QuotaManagerParams{
+		Store:    store,
+		JobStore: store,
+		Default:  model.ClientQuota{MaxConcurrentJobs: 1},
+	})
+
+	running := model.Job{Metadata: model.Metadata{ID: "running", ClientID: "client"}}
+	waiting := model.Job{Metadata: model.Metadata{ID: "waiting", ClientID: "client"}}
+	require.NoError(t, store.CreateJob(ctx, running))
+	require.NoError(t, store.CreateJob(ctx, waiting))
+
+	canStart, err := quotas.CanStart(ctx, waiting)
+	require.NoError(t, err)
+	require.True(t, canStart)
+
+	require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    running.Metadata.ID,
+		NewState: model.JobStateInProgress,
+	}))
+	canStart, err = quotas.CanStart(ctx, waiting)
+	require.NoError(t, err)
+	require.False(t, canStart, "the client already runs as many jobs as it can")
+
+	canStart, err = quotas.CanStart(ctx, model.Job{Metadata: model.Metadata{ID: "other", ClientID: "other-client"}})
+	require.NoError(t, err)
+	require.True(t, canStart)
+}
@@ -0,0 +1,93 @@
+package ranking
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/rs/zerolog/log"
+)
+
+// antiAffinityRank is the rank of nodes that haven't run a sibling job of a
+// job with soft anti-affinity, and outweighs the randomness of the random
+// ranker.
+const antiAffinityRank = 20
+
+type AntiAffinityNodeRankerParams struct {
+	JobStore jobstore.Store
+}
+
+type AntiAffinityNodeRanker struct {
+	jobStore jobstore.Store
+}
+
+func NewAntiAffinityNodeRanker(params AntiAffinityNodeRankerParams) *AntiAffinityNodeRanker {
+	return &AntiAffinityNodeRanker{
+		jobStore: params.JobStore,
+	}
+}
+
+// RankNodes ranks nodes based on whether they are running or have run a
+// sibling job, which is another job of the same client with any of the
+// anti-affinity annotations of the job:
+// - Rank -1: Node ran a sibling job.
+// - Rank 0: Job has no anti-affinity, or has soft anti-affinity and the node ran a sibling job.
+// - Rank 20: Job has soft anti-affinity and the node didn't run a sibling job.
+func (s *AntiAffinityNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	ranks := make([]requester.NodeRank, len(nodes))
+	siblingNodes, err := s.siblingNodes(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	for i, node := range nodes {
+		rank := 0
+		if len(job.Spec.Affinity.AntiAffinity) > 0 {
+			_, ranSibling := siblingNodes[node.PeerInfo.ID.String()]
+			switch {
+			case !ranSibling && job.Spec.Affinity.SoftAntiAffinity:
+				rank = antiAffinityRank
+			case ranSibling && !job.Spec.Affinity.SoftAntiAffinity:
+				log.Ctx(ctx).Trace().Msgf("filtering node %s that ran a sibling job", node.PeerInfo.ID)
+				rank = -1
+			}
+		}
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     rank,
+		}
+	}
+	return ranks, nil
+}
+
+// siblingNodes returns the ids of the nodes that are running or have run the
+// sibling jobs of the job.
+func (s *AntiAffinityNodeRanker) siblingNodes(ctx context.Context, job model.Job) (map[string]struct{}, error) {
+	result := make(map[string]struct{})
+	if len(job.Spec.Affinity.AntiAffinity) == 0 {
+		return result, nil
+	}
+	query := jobstore.JobQuery{ClientID: job.Metadata.ClientID}
+	for _, annotation := range job.Spec.Affinity.AntiAffinity {
+		query.IncludeTags = append(query.IncludeTags, model.IncludedTag(annotation))
+	}
+	siblings, err := s.jobStore.GetJobs(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, sibling := range siblings {
+		if sibling.Metadata.ID == job.Metadata.ID {
+			continue
+		}
+		jobState, err := s.jobStore.GetJobState(ctx, sibling.Metadata.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, execution := range jobState.Executions {
+			if execution.State.IsActive() {
+				result[execution.NodeID] = struct{}{}
+			}
+		}
+	}
+	return result, nil
+}
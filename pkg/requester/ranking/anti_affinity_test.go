@@ -0,0 +1,71 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+// runJobOn creates a job of the client with the annotations that has an
+// execution in the state on the node.
+func runJobOn(t *testing.T, store jobstore.Store, id, clientID, nodeID string, state model.ExecutionStateType, annotations ...string) {
+	ctx := context.Background()
+	j := model.Job{Metadata: model.Metadata{ID: id, ClientID: clientID}, Spec: model.Spec{Annotations: annotations}}
+	require.NoError(t, store.CreateJob(ctx, j))
+	require.NoError(t, store.CreateExecution(ctx, model.ExecutionState{
+		JobID:            id,
+		NodeID:           peer.ID(nodeID).String(),
+		ComputeReference: "execution-" + id,
+		State:            state,
+	}))
+}
+
+func TestAntiAffinityNodeRanker(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+	runJobOn(t, store, "replica-1", "client", "busy", model.ExecutionStateBidAccepted, "replicas")
+	runJobOn(t, store, "replica-2", "client", "done", model.ExecutionStateCompleted, "other", "replicas")
+	runJobOn(t, store, "replica-3", "client", "failed", model.ExecutionStateFailed, "replicas")
+	runJobOn(t, store, "unrelated", "client", "unrelated", model.ExecutionStateBidAccepted, "other")
+	runJobOn(t, store, "not-mine", "someone-else", "not-mine", model.ExecutionStateBidAccepted, "replicas")
+
+	nodes := []model.NodeInfo{}
+	for _, id := range []string{"busy", "done", "failed", "unrelated", "not-mine", "idle"} {
+		nodes = append(nodes, model.NodeInfo{PeerInfo: peer.AddrInfo{ID: peer.ID(id)}})
+	}
+	ranker := NewAntiAffinityNodeRanker(AntiAffinityNodeRankerParams{JobStore: store})
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: "replica-4", ClientID: "client"},
+		Spec:     model.Spec{Annotations: []string{"replicas"}},
+	}
+	ranks, err := ranker.RankNodes(ctx, job, nodes)
+	require.NoError(t, err)
+	for _, node := range nodes {
+		assertEquals(t, ranks, string(node.PeerInfo.ID), 0)
+	}
+
+	job.Spec.Affinity.AntiAffinity = []string{"replicas"}
+	ranks, err = ranker.RankNodes(ctx, job, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "busy", -1)
+	assertEquals(t, ranks, "done", -1)
+	assertEquals(t, ranks, "failed", 0)
+	assertEquals(t, ranks, "unrelated", 0)
+	assertEquals(t, ranks, "not-mine", 0)
+	assertEquals(t, ranks, "idle", 0)
+
+	job.Spec.Affinity.SoftAntiAffinity = true
+	ranks, err = ranker.RankNodes(ctx, job, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "busy", 0)
+	assertEquals(t, ranks, "done", 0)
+	assertEquals(t, ranks, "idle", antiAffinityRank)
+}
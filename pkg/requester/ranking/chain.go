@@ -2,6 +2,8 @@ package ranking
 
 import (
 	"context"
+	"reflect"
+	"strings"
 
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/requester"
@@ -24,6 +26,23 @@ func (c *Chain) Add(ranker ...requester.NodeRanker) {
 }
 
 func (c *Chain) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	return c.rank(ctx, job, nodes, nil)
+}
+
+// ExplainRanks ranks the nodes like RankNodes does, and also returns the rank
+// that each ranker of the chain gave each node, by node ID and then by the
+// name of the ranker.
+func (c *Chain) ExplainRanks(
+	ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, map[string]map[string]int, error) {
+	explained := make(map[string]map[string]int, len(nodes))
+	nodeRanks, err := c.rank(ctx, job, nodes, explained)
+	return nodeRanks, explained, err
+}
+
+// rank ranks the nodes with each ranker of the chain in turn, recording the
+// rank that each ranker gave each node in explained unless it is nil.
+func (c *Chain) rank(
+	ctx context.Context, job model.Job, nodes []model.NodeInfo, explained map[string]map[string]int) ([]requester.NodeRank, error) {
 	// initialize map of node ranks
 	ranksMap := make(map[peer.ID]*requester.NodeRank, len(nodes))
 	for _, node := range nodes {
@@ -44,6 +63,13 @@ func (c *Chain) RankNodes(ctx context.Context, job model.Job, nodes []model.Node
 			} else {
 				ranksMap[nodeRank.NodeInfo.PeerInfo.ID].Rank += nodeRank.Rank
 			}
+			if explained != nil {
+				nodeID := nodeRank.NodeInfo.PeerInfo.ID.String()
+				if explained[nodeID] == nil {
+					explained[nodeID] = make(map[string]int, len(c.rankers))
+				}
+				explained[nodeID][rankerName(ranker)] = nodeRank.Rank
+			}
 		}
 	}
 
@@ -53,3 +79,14 @@ func (c *Chain) RankNodes(ctx context.Context, job model.Job, nodes []model.Node
 	}
 	return nodeRanks, nil
 }
+
+// rankerName names the ranker after its type, such as "engines" for the
+// EnginesNodeRanker.
+func rankerName(ranker requester.NodeRanker) string {
+	name := reflect.TypeOf(ranker).String()
+	name = name[strings.LastIndex(name, ".")+1:]
+	return strings.ToLower(strings.TrimSuffix(name, "NodeRanker"))
+}
+
+// compile-time check that Chain implements the expected interfaces
+var _ requester.NodeRankExplainer = (*Chain)(nil)
@@ -73,6 +73,23 @@ func (s *ChainSuite) TestRankNodes_AllNegative() {
 	assertEquals(s.T(), ranks, "peerID3", -1)
 }
 
+func (s *ChainSuite) TestExplainRanks() {
+	s.chain.Add(NewEnginesNodeRanker())
+	s.chain.Add(newFixedRanker(5, -1))
+
+	dockerNode := s.peerID1
+	dockerNode.ComputeNodeInfo.ExecutionEngines = []model.Engine{model.EngineDocker}
+	job := model.Job{Spec: model.Spec{Engine: model.EngineDocker}}
+	ranks, explained, err := s.chain.ExplainRanks(context.Background(), job, []model.NodeInfo{dockerNode, s.peerID2})
+	s.NoError(err)
+	assertEquals(s.T(), ranks, "peerID1", 15)
+	assertEquals(s.T(), ranks, "peerID2", -1)
+	s.Equal(map[string]map[string]int{
+		s.peerID1.PeerInfo.ID.String(): {"engines": 10, "fixedranker": 5},
+		s.peerID2.PeerInfo.ID.String(): {"engines": 0, "fixedranker": -1},
+	}, explained)
+}
+
 // node Ranker that always returns the same set of nodes
 type fixedRanker struct {
 	ranks []int
@@ -0,0 +1,84 @@
+package ranking
+
+import (
+	"context"
+	"math"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+)
+
+// completionTimeRank is the rank of the nodes that are estimated to complete
+// the job the soonest. Nodes that weren't benchmarked get half of it.
+const completionTimeRank = 10
+
+type CompletionTimeNodeRanker struct {
+}
+
+func NewCompletionTimeNodeRanker() *CompletionTimeNodeRanker {
+	return &CompletionTimeNodeRanker{}
+}
+
+// RankNodes ranks nodes based on how soon they are estimated to complete the
+// job from their benchmarks, relative to the other candidate nodes. The
+// estimate is from how fast the CPU of the node is, and for jobs with inputs
+// also how fast its disk and network are. A speed that a node didn't measure
+// is taken to be the slowest that other nodes measured:
+// - Rank 10: Node is estimated to complete the job the soonest.
+// - Rank 5: Node wasn't benchmarked, or is estimated to take twice as long.
+// - Rank 0: Node is estimated to take much longer than the soonest.
+func (s *CompletionTimeNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	speeds := []func(model.NodeBenchmark) float64{
+		func(b model.NodeBenchmark) float64 { return b.CPUScore },
+	}
+	if len(job.Spec.Inputs) > 0 {
+		speeds = append(speeds,
+			func(b model.NodeBenchmark) float64 { return b.DiskThroughput },
+			func(b model.NodeBenchmark) float64 { return b.NetworkBandwidth },
+		)
+	}
+
+	// the estimated time of each node is the mean of how many times slower it is than the fastest node at each speed
+	estimates := make([]float64, len(nodes))
+	for _, speed := range speeds {
+		fastest, slowest := speedRange(nodes, speed)
+		if fastest == 0 {
+			continue
+		}
+		for i, node := range nodes {
+			value := speed(node.ComputeNodeInfo.Benchmark)
+			if value == 0 {
+				value = slowest
+			}
+			estimates[i] += fastest / value / float64(len(speeds))
+		}
+	}
+
+	ranks := make([]requester.NodeRank, len(nodes))
+	for i, node := range nodes {
+		rank := completionTimeRank / 2
+		if !node.ComputeNodeInfo.Benchmark.IsZero() && estimates[i] > 0 {
+			rank = int(math.Round(completionTimeRank / estimates[i]))
+		}
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     rank,
+		}
+	}
+	return ranks, nil
+}
+
+// speedRange returns the highest and lowest speeds that the nodes measured, or zeros if none did.
+func speedRange(nodes []model.NodeInfo, speed func(model.NodeBenchmark) float64) (fastest float64, slowest float64) {
+	for _, node := range nodes {
+		value := speed(node.ComputeNodeInfo.Benchmark)
+		if value == 0 {
+			continue
+		}
+		fastest = math.Max(fastest, value)
+		if slowest == 0 || value < slowest {
+			slowest = value
+		}
+	}
+	return fastest, slowest
+}
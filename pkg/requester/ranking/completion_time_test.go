@@ -0,0 +1,55 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionTimeNodeRanker(t *testing.T) {
+	benchmarked := func(id string, benchmark model.NodeBenchmark) model.NodeInfo {
+		return model.NodeInfo{
+			PeerInfo:        peer.AddrInfo{ID: peer.ID(id)},
+			ComputeNodeInfo: model.ComputeNodeInfo{Benchmark: benchmark},
+		}
+	}
+	nodes := []model.NodeInfo{
+		benchmarked("fast", model.NodeBenchmark{CPUScore: 1000, DiskThroughput: 100, NetworkBandwidth: 10}),
+		benchmarked("slow-cpu", model.NodeBenchmark{CPUScore: 500, DiskThroughput: 100, NetworkBandwidth: 10}),
+		benchmarked("slow-network", model.NodeBenchmark{CPUScore: 1000, DiskThroughput: 100, NetworkBandwidth: 2}),
+		benchmarked("cpu-only", model.NodeBenchmark{CPUScore: 1000}),
+		{PeerInfo: peer.AddrInfo{ID: "unknown"}},
+	}
+	ranker := NewCompletionTimeNodeRanker()
+
+	ranks, err := ranker.RankNodes(context.Background(), model.Job{}, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "fast", 10)
+	assertEquals(t, ranks, "slow-cpu", 5)
+	assertEquals(t, ranks, "slow-network", 10)
+	assertEquals(t, ranks, "cpu-only", 10)
+	assertEquals(t, ranks, "unknown", 5)
+
+	// the disk and network count for jobs with inputs
+	job := model.Job{Spec: model.Spec{Inputs: []model.StorageSpec{{StorageSource: model.StorageSourceIPFS}}}}
+	ranks, err = ranker.RankNodes(context.Background(), job, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "fast", 10)
+	assertEquals(t, ranks, "slow-cpu", 8)     // (2 + 1 + 1) / 3
+	assertEquals(t, ranks, "slow-network", 4) // (1 + 1 + 5) / 3
+	assertEquals(t, ranks, "cpu-only", 4)     // takes the slowest disk and network measured
+	assertEquals(t, ranks, "unknown", 5)
+}
+
+func TestCompletionTimeNodeRankerWithoutBenchmarks(t *testing.T) {
+	nodes := []model.NodeInfo{{PeerInfo: peer.AddrInfo{ID: "a"}}, {PeerInfo: peer.AddrInfo{ID: "b"}}}
+	ranks, err := NewCompletionTimeNodeRanker().RankNodes(context.Background(), model.Job{}, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "a", 5)
+	assertEquals(t, ranks, "b", 5)
+}
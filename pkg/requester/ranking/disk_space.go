@@ -0,0 +1,45 @@
+package ranking
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/rs/zerolog/log"
+)
+
+// maxDiskSpaceRank is the rank of a node with an empty scratch disk.
+const maxDiskSpaceRank = 10
+
+type DiskSpaceNodeRanker struct {
+}
+
+func NewDiskSpaceNodeRanker() *DiskSpaceNodeRanker {
+	return &DiskSpaceNodeRanker{}
+}
+
+// RankNodes ranks nodes based on the scratch disk space the compute nodes have free:
+// - Rank -1: Node has less free space than the disk the job requires.
+// - Rank 0-10: Node has enough free space, ranked higher the less of its disk is in use.
+// - Rank 0: Node didn't report its disk space, e.g. because it is an older version.
+func (s *DiskSpaceNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	ranks := make([]requester.NodeRank, len(nodes))
+	jobDisk := capacity.ParseResourceUsageConfig(job.Spec.Resources).Disk
+	for i, node := range nodes {
+		rank := 0
+		if disk := node.ComputeNodeInfo.ScratchDisk; disk.Total > 0 {
+			if jobDisk > disk.Free {
+				log.Ctx(ctx).Trace().Msgf("filtering node %s with only %d bytes of disk free", node.PeerInfo.ID, disk.Free)
+				rank = -1
+			} else {
+				rank = int(maxDiskSpaceRank * (1 - disk.Utilization()))
+			}
+		}
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     rank,
+		}
+	}
+	return ranks, nil
+}
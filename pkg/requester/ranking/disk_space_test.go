@@ -0,0 +1,48 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskSpaceNodeRanker(t *testing.T) {
+	nodes := []model.NodeInfo{
+		{
+			PeerInfo:        peer.AddrInfo{ID: peer.ID("empty")},
+			ComputeNodeInfo: model.ComputeNodeInfo{ScratchDisk: model.DiskSpace{Total: 1000, Free: 1000}},
+		},
+		{
+			PeerInfo:        peer.AddrInfo{ID: peer.ID("half")},
+			ComputeNodeInfo: model.ComputeNodeInfo{ScratchDisk: model.DiskSpace{Total: 1000, Free: 500}},
+		},
+		{
+			PeerInfo:        peer.AddrInfo{ID: peer.ID("full")},
+			ComputeNodeInfo: model.ComputeNodeInfo{ScratchDisk: model.DiskSpace{Total: 1000, Free: 10}},
+		},
+		{
+			PeerInfo: peer.AddrInfo{ID: peer.ID("unknown")},
+		},
+	}
+
+	ranks, err := NewDiskSpaceNodeRanker().RankNodes(context.Background(), model.Job{}, nodes)
+	require.NoError(t, err)
+	require.Len(t, ranks, len(nodes))
+	assertEquals(t, ranks, "empty", 10)
+	assertEquals(t, ranks, "half", 5)
+	assertEquals(t, ranks, "full", 0)
+	assertEquals(t, ranks, "unknown", 0)
+
+	job := model.Job{Spec: model.Spec{Resources: model.ResourceUsageConfig{Disk: "600b"}}}
+	ranks, err = NewDiskSpaceNodeRanker().RankNodes(context.Background(), job, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "empty", 10)
+	assertEquals(t, ranks, "half", -1)
+	assertEquals(t, ranks, "full", -1)
+	assertEquals(t, ranks, "unknown", 0)
+}
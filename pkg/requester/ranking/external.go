@@ -0,0 +1,108 @@
+package ranking
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultExternalRankerTimeout is how long the external ranker is waited for
+// to rank the nodes for a job, unless configured otherwise.
+const DefaultExternalRankerTimeout = 5 * time.Second
+
+// ExternalRankRequest is what is posted to the external ranker for it to rank
+// the nodes for a job.
+type ExternalRankRequest struct {
+	Job   model.Job        `json:"Job"`
+	Nodes []model.NodeInfo `json:"Nodes"`
+}
+
+// ExternalRankResponse is how the external ranker ranks the nodes, by node
+// ID. Nodes it doesn't rank get a rank of 0, and nodes it ranks below 0 are
+// not considered for the job.
+type ExternalRankResponse struct {
+	Ranks map[string]int `json:"Ranks"`
+}
+
+type ExternalNodeRankerParams struct {
+	// URL is where the nodes are posted to be ranked
+	URL string
+	// Timeout is how long the external ranker is waited for, or the default
+	// if it is zero
+	Timeout time.Duration
+}
+
+type ExternalNodeRanker struct {
+	url    string
+	client *http.Client
+}
+
+func NewExternalNodeRanker(params ExternalNodeRankerParams) *ExternalNodeRanker {
+	timeout := params.Timeout
+	if timeout == 0 {
+		timeout = DefaultExternalRankerTimeout
+	}
+	return &ExternalNodeRanker{
+		url:    params.URL,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// RankNodes ranks nodes with the ranks that an external service gives them,
+// so that operators can add their own placement policy:
+// - Rank: Whatever the external ranker ranked the node, where below 0 filters it out.
+// - Rank 0: The external ranker didn't rank the node, or failed to rank the
+// nodes, in which case the other rankers decide alone.
+func (s *ExternalNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	ranks := make([]requester.NodeRank, len(nodes))
+	for i, node := range nodes {
+		ranks[i] = requester.NodeRank{NodeInfo: node}
+	}
+	response, err := s.fetchRanks(ctx, job, nodes)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msgf("external ranker %s failed to rank the nodes for job %s", s.url, job.Metadata.ID)
+		return ranks, nil
+	}
+	for i, node := range nodes {
+		ranks[i].Rank = response.Ranks[node.PeerInfo.ID.String()]
+	}
+	return ranks, nil
+}
+
+func (s *ExternalNodeRanker) fetchRanks(ctx context.Context, job model.Job, nodes []model.NodeInfo) (ExternalRankResponse, error) {
+	data, err := model.JSONMarshalWithMax(ExternalRankRequest{Job: job, Nodes: nodes})
+	if err != nil {
+		return ExternalRankResponse{}, fmt.Errorf("error marshaling the nodes to rank: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewBuffer(data))
+	if err != nil {
+		return ExternalRankResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req) //nolint:bodyclose // closed below
+	if err != nil {
+		return ExternalRankResponse{}, err
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, s.url, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return ExternalRankResponse{}, fmt.Errorf("returned %d status code", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(model.MaxSerializedStringInput)+1))
+	if err != nil {
+		return ExternalRankResponse{}, fmt.Errorf("error reading the ranks: %w", err)
+	}
+	var response ExternalRankResponse
+	if err = model.JSONUnmarshalWithMax(body, &response); err != nil {
+		return ExternalRankResponse{}, fmt.Errorf("error unmarshalling the ranks: %w", err)
+	}
+	return response, nil
+}
@@ -0,0 +1,57 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalNodeRanker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the ids of the test nodes aren't valid peer ids, so aren't decoded as such
+		var request struct {
+			Job   model.Job
+			Nodes []struct{ PeerInfo struct{ ID string } }
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if request.Job.Metadata.ID == "broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		response := ExternalRankResponse{Ranks: map[string]int{}}
+		for i, node := range request.Nodes {
+			response.Ranks[node.PeerInfo.ID] = i * 10
+		}
+		response.Ranks[peer.ID("banned").String()] = -1
+		delete(response.Ranks, peer.ID("unranked").String())
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	t.Cleanup(server.Close)
+
+	nodes := []model.NodeInfo{}
+	for _, id := range []string{"banned", "first", "second", "unranked"} {
+		nodes = append(nodes, model.NodeInfo{PeerInfo: peer.AddrInfo{ID: peer.ID(id)}})
+	}
+	ranker := NewExternalNodeRanker(ExternalNodeRankerParams{URL: server.URL})
+
+	ranks, err := ranker.RankNodes(context.Background(), model.Job{Metadata: model.Metadata{ID: "job"}}, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "banned", -1)
+	assertEquals(t, ranks, "first", 10)
+	assertEquals(t, ranks, "second", 20)
+	assertEquals(t, ranks, "unranked", 0)
+
+	ranks, err = ranker.RankNodes(context.Background(), model.Job{Metadata: model.Metadata{ID: "broken"}}, nodes)
+	require.NoError(t, err, "the other rankers decide alone when the external ranker fails")
+	assertEquals(t, ranks, "banned", 0)
+	assertEquals(t, ranks, "second", 0)
+}
@@ -0,0 +1,43 @@
+package ranking
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/rs/zerolog/log"
+)
+
+type GPUNodeRanker struct {
+}
+
+func NewGPUNodeRanker() *GPUNodeRanker {
+	return &GPUNodeRanker{}
+}
+
+// RankNodes ranks nodes based on whether the GPUs they advertise are of the
+// model and have the memory that the job requires of its GPUs:
+// - Rank 10: Node has enough GPUs that match the job's requirements.
+// - Rank -1: Node doesn't have enough GPUs that match the job's requirements.
+// - Rank 0: Job has no GPU model or memory requirements, or the node didn't
+// advertise its capacity, in which case it decides itself when bidding.
+func (s *GPUNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	ranks := make([]requester.NodeRank, len(nodes))
+	for i, node := range nodes {
+		rank := 0
+		if capacity.HasGPURequirements(job.Spec.Resources) && !node.ComputeNodeInfo.MaxCapacity.IsZero() {
+			if capacity.SatisfiesGPURequirements(job.Spec.Resources, node.ComputeNodeInfo.GPUs) {
+				rank = 10
+			} else {
+				log.Ctx(ctx).Trace().Msgf("filtering node %s that doesn't have the GPUs the job requires", node.PeerInfo.ID)
+				rank = -1
+			}
+		}
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     rank,
+		}
+	}
+	return ranks, nil
+}
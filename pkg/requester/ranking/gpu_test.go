@@ -0,0 +1,46 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGPUNodeRanker(t *testing.T) {
+	capacity := model.ResourceUsageData{CPU: 8, GPU: 1}
+	nodes := []model.NodeInfo{
+		{
+			PeerInfo: peer.AddrInfo{ID: "a100"},
+			ComputeNodeInfo: model.ComputeNodeInfo{
+				MaxCapacity: capacity,
+				GPUs:        []model.GPU{{Name: "NVIDIA A100-SXM4-80GB", Memory: 80 << 30}},
+			},
+		},
+		{
+			PeerInfo: peer.AddrInfo{ID: "t4"},
+			ComputeNodeInfo: model.ComputeNodeInfo{
+				MaxCapacity: capacity,
+				GPUs:        []model.GPU{{Name: "Tesla T4", Memory: 16 << 30}},
+			},
+		},
+		{PeerInfo: peer.AddrInfo{ID: "unknown"}},
+	}
+	ranker := NewGPUNodeRanker()
+
+	job := model.Job{Spec: model.Spec{Resources: model.ResourceUsageConfig{GPU: "1", GPUModel: "A100", GPUMemory: "80Gb"}}}
+	ranks, err := ranker.RankNodes(context.Background(), job, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "a100", 10)
+	assertEquals(t, ranks, "t4", -1)
+	assertEquals(t, ranks, "unknown", 0)
+
+	job.Spec.Resources = model.ResourceUsageConfig{GPU: "1"}
+	ranks, err = ranker.RankNodes(context.Background(), job, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "t4", 0)
+}
@@ -9,6 +9,10 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 )
 
+// preferredLabelsRank is added to the rank of nodes that match the preferred
+// selectors of a job, and outweighs the randomness of the random ranker.
+const preferredLabelsRank = 20
+
 type LabelsNodeRanker struct {
 }
 
@@ -20,15 +24,17 @@ func NewLabelsNodeRanker() *LabelsNodeRanker {
 // - Rank 1: Selectors match node labels.
 // - Rank -1: Selectors don't match node labels.
 // - Rank 0: Job selectors are not set.
+// Nodes that match the preferred selectors of the job, if it has any, are
+// ranked 20 higher.
 func (s *LabelsNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
 	ranks := make([]requester.NodeRank, len(nodes))
-	var selector labels.Selector
-	if len(job.Spec.NodeSelectors) > 0 {
-		requirements, err := model.FromLabelSelectorRequirements(job.Spec.NodeSelectors...)
-		if err != nil {
-			return nil, err
-		}
-		selector = labels.NewSelector().Add(requirements...)
+	selector, err := toSelector(job.Spec.NodeSelectors)
+	if err != nil {
+		return nil, err
+	}
+	preferred, err := toSelector(job.Spec.Affinity.PreferredNodeSelectors)
+	if err != nil {
+		return nil, err
 	}
 	for i, node := range nodes {
 		rank := 0
@@ -41,6 +47,9 @@ func (s *LabelsNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes [
 				rank = -1
 			}
 		}
+		if rank >= 0 && preferred != nil && preferred.Matches(labels.Set(node.Labels)) {
+			rank += preferredLabelsRank
+		}
 		ranks[i] = requester.NodeRank{
 			NodeInfo: node,
 			Rank:     rank,
@@ -48,3 +57,15 @@ func (s *LabelsNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes [
 	}
 	return ranks, nil
 }
+
+// toSelector returns a selector for the requirements, or nil if there are none.
+func toSelector(selectors []model.LabelSelectorRequirement) (labels.Selector, error) {
+	if len(selectors) == 0 {
+		return nil, nil
+	}
+	requirements, err := model.FromLabelSelectorRequirements(selectors...)
+	if err != nil {
+		return nil, err
+	}
+	return labels.NewSelector().Add(requirements...), nil
+}
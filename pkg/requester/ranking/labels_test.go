@@ -0,0 +1,37 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelsNodeRanker(t *testing.T) {
+	nodes := []model.NodeInfo{
+		{PeerInfo: peer.AddrInfo{ID: peer.ID("eu-gpu")}, Labels: map[string]string{"zone": "eu", "gpu": "true"}},
+		{PeerInfo: peer.AddrInfo{ID: peer.ID("eu")}, Labels: map[string]string{"zone": "eu"}},
+		{PeerInfo: peer.AddrInfo{ID: peer.ID("us-gpu")}, Labels: map[string]string{"zone": "us", "gpu": "true"}},
+	}
+	required, err := job.ParseNodeSelector("zone in (eu)")
+	require.NoError(t, err)
+	preferred, err := job.ParseNodeSelector("gpu")
+	require.NoError(t, err)
+
+	ranks, err := NewLabelsNodeRanker().RankNodes(context.Background(), model.Job{}, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "eu-gpu", 0)
+	assertEquals(t, ranks, "us-gpu", 0)
+
+	j := model.Job{Spec: model.Spec{NodeSelectors: required, Affinity: model.AffinityConfig{PreferredNodeSelectors: preferred}}}
+	ranks, err = NewLabelsNodeRanker().RankNodes(context.Background(), j, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "eu-gpu", 1+preferredLabelsRank)
+	assertEquals(t, ranks, "eu", 1)
+	assertEquals(t, ranks, "us-gpu", -1)
+}
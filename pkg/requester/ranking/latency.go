@@ -0,0 +1,102 @@
+package ranking
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxLatencyRank is the rank of the nodes nearest to the requester for
+	// latency sensitive jobs, and outweighs the randomness of the random
+	// ranker.
+	maxLatencyRank = 20
+	// latencyMeasureTimeout is how long a round trip to a node is waited for.
+	latencyMeasureTimeout = 10 * time.Second
+)
+
+type LatencyNodeRankerParams struct {
+	// Host is the host of the requester, which measures the round trip time
+	// to the nodes
+	Host   host.Host
+	Policy requester.LatencyPolicy
+}
+
+type LatencyNodeRanker struct {
+	latencies peerstore.Metrics
+	policy    requester.LatencyPolicy
+	// measure measures the round trip time to the node in the background
+	measure   func(peer.ID)
+	measuring map[peer.ID]struct{}
+	mu        sync.Mutex
+}
+
+func NewLatencyNodeRanker(params LatencyNodeRankerParams) *LatencyNodeRanker {
+	s := &LatencyNodeRanker{
+		latencies: params.Host.Peerstore(),
+		policy:    params.Policy,
+		measuring: make(map[peer.ID]struct{}),
+	}
+	s.measure = func(id peer.ID) {
+		ctx, cancel := context.WithTimeout(context.Background(), latencyMeasureTimeout)
+		defer cancel()
+		// pinging records the round trip time in the peerstore of the host
+		if result := <-ping.Ping(ctx, params.Host, id); result.Error != nil {
+			log.Debug().Err(result.Error).Msgf("failed to measure the round trip time to node %s", id)
+		}
+	}
+	return s
+}
+
+// RankNodes ranks nodes based on the round trip time to them from the
+// requester if the job is latency sensitive:
+// - Rank 0-20: Job is latency sensitive, higher the nearer the node is, down
+// to 0 when it is the policy's max latency away or more.
+// - Rank 0: Job isn't latency sensitive, or the round trip time to the node
+// isn't known yet. It is measured in the background for the next jobs.
+func (s *LatencyNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	ranks := make([]requester.NodeRank, len(nodes))
+	sensitive := s.policy.IsLatencySensitive(job)
+	for i, node := range nodes {
+		rank := 0
+		if sensitive {
+			latency := s.latencies.LatencyEWMA(node.PeerInfo.ID)
+			switch {
+			case latency == 0:
+				s.measureInBackground(node.PeerInfo.ID)
+			case latency < s.policy.MaxLatency:
+				rank = int(maxLatencyRank * (1 - float64(latency)/float64(s.policy.MaxLatency)))
+			}
+		}
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     rank,
+		}
+	}
+	return ranks, nil
+}
+
+// measureInBackground measures the round trip time to the node unless it is
+// already being measured.
+func (s *LatencyNodeRanker) measureInBackground(id peer.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.measuring[id]; ok {
+		return
+	}
+	s.measuring[id] = struct{}{}
+	go func() {
+		s.measure(id)
+		s.mu.Lock()
+		delete(s.measuring, id)
+		s.mu.Unlock()
+	}()
+}
@@ -0,0 +1,52 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/libp2p/go-libp2p/core/peer"
+	pstore "github.com/libp2p/go-libp2p/p2p/host/peerstore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyNodeRanker(t *testing.T) {
+	metrics := pstore.NewMetrics()
+	metrics.RecordLatency("near", 10*time.Millisecond)
+	metrics.RecordLatency("mid", 100*time.Millisecond)
+	metrics.RecordLatency("far", 300*time.Millisecond)
+	measured := make(chan peer.ID, 1)
+	ranker := &LatencyNodeRanker{
+		latencies: metrics,
+		policy:    requester.LatencyPolicy{MaxTimeout: time.Minute, MaxLatency: 200 * time.Millisecond},
+		measure:   func(id peer.ID) { measured <- id },
+		measuring: make(map[peer.ID]struct{}),
+	}
+	nodes := []model.NodeInfo{}
+	for _, id := range []string{"near", "mid", "far", "unknown"} {
+		nodes = append(nodes, model.NodeInfo{PeerInfo: peer.AddrInfo{ID: peer.ID(id)}})
+	}
+
+	short := model.Job{Spec: model.Spec{Timeout: 30}}
+	ranks, err := ranker.RankNodes(context.Background(), short, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "near", 19)
+	assertEquals(t, ranks, "mid", 10)
+	assertEquals(t, ranks, "far", 0)
+	assertEquals(t, ranks, "unknown", 0)
+	require.Equal(t, peer.ID("unknown"), <-measured, "unknown round trip times are measured for the next jobs")
+
+	long := model.Job{Spec: model.Spec{Timeout: 3600}}
+	ranks, err = ranker.RankNodes(context.Background(), long, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "near", 0)
+
+	long.Spec.Annotations = []string{requester.LatencySensitiveAnnotation}
+	ranks, err = ranker.RankNodes(context.Background(), long, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "near", 19)
+}
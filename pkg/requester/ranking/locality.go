@@ -0,0 +1,52 @@
+package ranking
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"golang.org/x/exp/slices"
+)
+
+// maxLocalityRank is the rank of a node that holds all of the inputs of a job
+// locally, and outweighs the randomness of the random ranker.
+const maxLocalityRank = 20
+
+type LocalityNodeRanker struct {
+}
+
+func NewLocalityNodeRanker() *LocalityNodeRanker {
+	return &LocalityNodeRanker{}
+}
+
+// RankNodes ranks nodes based on how many of the job's inputs they already
+// hold locally, so that less data has to be transferred:
+// - Rank 0-20: Node holds that fraction of the inputs with CIDs.
+// - Rank 0: Job has no inputs with CIDs, or node didn't report the CIDs it holds.
+func (s *LocalityNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	var cids []string
+	for _, input := range job.Spec.Inputs {
+		if input.CID != "" && !slices.Contains(cids, input.CID) {
+			cids = append(cids, input.CID)
+		}
+	}
+
+	ranks := make([]requester.NodeRank, len(nodes))
+	for i, node := range nodes {
+		rank := 0
+		if len(cids) > 0 && len(node.ComputeNodeInfo.LocalCIDs) > 0 {
+			local := 0
+			for _, cid := range cids {
+				if slices.Contains(node.ComputeNodeInfo.LocalCIDs, cid) {
+					local++
+				}
+			}
+			rank = maxLocalityRank * local / len(cids)
+		}
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     rank,
+		}
+	}
+	return ranks, nil
+}
@@ -0,0 +1,50 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalityNodeRanker(t *testing.T) {
+	nodes := []model.NodeInfo{
+		{
+			PeerInfo:        peer.AddrInfo{ID: peer.ID("all")},
+			ComputeNodeInfo: model.ComputeNodeInfo{LocalCIDs: []string{"other", "input-1", "input-2"}},
+		},
+		{
+			PeerInfo:        peer.AddrInfo{ID: peer.ID("half")},
+			ComputeNodeInfo: model.ComputeNodeInfo{LocalCIDs: []string{"input-2"}},
+		},
+		{
+			PeerInfo:        peer.AddrInfo{ID: peer.ID("none")},
+			ComputeNodeInfo: model.ComputeNodeInfo{LocalCIDs: []string{"other"}},
+		},
+		{
+			PeerInfo: peer.AddrInfo{ID: peer.ID("unknown")},
+		},
+	}
+
+	job := model.Job{Spec: model.Spec{Inputs: []model.StorageSpec{
+		{CID: "input-1"},
+		{CID: "input-2"},
+		{CID: "input-2", Path: "/inputs/again"},
+		{URL: "https://example.com/data"},
+	}}}
+	ranks, err := NewLocalityNodeRanker().RankNodes(context.Background(), job, nodes)
+	require.NoError(t, err)
+	require.Len(t, ranks, len(nodes))
+	assertEquals(t, ranks, "all", 20)
+	assertEquals(t, ranks, "half", 10)
+	assertEquals(t, ranks, "none", 0)
+	assertEquals(t, ranks, "unknown", 0)
+
+	ranks, err = NewLocalityNodeRanker().RankNodes(context.Background(), model.Job{}, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "all", 0)
+}
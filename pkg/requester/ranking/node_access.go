@@ -0,0 +1,46 @@
+package ranking
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/rs/zerolog/log"
+)
+
+type NodeAccessNodeRankerParams struct {
+	Access requester.NodeAccess
+}
+
+type NodeAccessNodeRanker struct {
+	access requester.NodeAccess
+}
+
+func NewNodeAccessNodeRanker(params NodeAccessNodeRankerParams) *NodeAccessNodeRanker {
+	return &NodeAccessNodeRanker{
+		access: params.Access,
+	}
+}
+
+// RankNodes ranks nodes based on whether the node access rules let executions be scheduled on them:
+// - Rank 0: Node is allowed, or isn't denied when no nodes are allowed.
+// - Rank -1: Node is denied, or isn't allowed when some nodes are.
+func (s *NodeAccessNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	rules, err := s.access.GetNodeAccessRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ranks := make([]requester.NodeRank, len(nodes))
+	for i, node := range nodes {
+		rank := 0
+		if !requester.IsNodeAllowed(rules, node.PeerInfo.ID.String()) {
+			log.Ctx(ctx).Trace().Msgf("filtering node %s that the node access rules deny", node.PeerInfo.ID)
+			rank = -1
+		}
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     rank,
+		}
+	}
+	return ranks, nil
+}
@@ -0,0 +1,27 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeAccessNodeRanker(t *testing.T) {
+	nodes := []model.NodeInfo{
+		{PeerInfo: peer.AddrInfo{ID: "healthy"}},
+		{PeerInfo: peer.AddrInfo{ID: "quarantined"}},
+	}
+	ranker := NewNodeAccessNodeRanker(NodeAccessNodeRankerParams{
+		Access: requester.NewNodeAccessManager(requester.NodeAccessManagerParams{Denylist: []string{peer.ID("quarantined").String()}}),
+	})
+	ranks, err := ranker.RankNodes(context.Background(), model.Job{}, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "healthy", 0)
+	assertEquals(t, ranks, "quarantined", -1)
+}
@@ -0,0 +1,76 @@
+package ranking
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+)
+
+// maxPlacementRank is the rank of the node that the placement strategy
+// prefers the most, and outweighs the randomness of the random ranker.
+const maxPlacementRank = 20
+
+type PlacementNodeRankerParams struct {
+	Strategy requester.PlacementStrategy
+}
+
+type PlacementNodeRanker struct {
+	strategy requester.PlacementStrategy
+}
+
+func NewPlacementNodeRanker(params PlacementNodeRankerParams) *PlacementNodeRanker {
+	return &PlacementNodeRanker{
+		strategy: params.Strategy,
+	}
+}
+
+// RankNodes ranks nodes based on how busy they are and the placement strategy:
+// - Spread: Rank 0-20, higher the less of its capacity the node is using.
+// - Bin-pack: Rank 0-20, higher the more of its capacity the node is using, as
+// long as it has the capacity for the job. Otherwise the rank is 0.
+// - Rank 0: No placement strategy, or the node didn't report its capacity.
+func (s *PlacementNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	ranks := make([]requester.NodeRank, len(nodes))
+	jobResourceUsage := capacity.ParseResourceUsageConfig(job.Spec.Resources)
+	for i, node := range nodes {
+		rank := 0
+		info := node.ComputeNodeInfo
+		if !info.MaxCapacity.IsZero() {
+			switch s.strategy {
+			case requester.PlacementSpread:
+				rank = int(maxPlacementRank * (1 - utilization(info)))
+			case requester.PlacementBinPack:
+				if jobResourceUsage.LessThanEq(info.AvailableCapacity) {
+					rank = int(maxPlacementRank * utilization(info))
+				}
+			}
+		}
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     rank,
+		}
+	}
+	return ranks, nil
+}
+
+// utilization returns the fraction of the node's capacity that is in use,
+// from 0 to 1, averaged over the resources that it has.
+func utilization(info model.ComputeNodeInfo) float64 {
+	var total float64
+	var resources int
+	add := func(available, max float64) {
+		if max > 0 {
+			total += 1 - available/max
+			resources++
+		}
+	}
+	add(info.AvailableCapacity.CPU, info.MaxCapacity.CPU)
+	add(float64(info.AvailableCapacity.Memory), float64(info.MaxCapacity.Memory))
+	add(float64(info.AvailableCapacity.GPU), float64(info.MaxCapacity.GPU))
+	if resources == 0 {
+		return 0
+	}
+	return total / float64(resources)
+}
@@ -0,0 +1,56 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlacementNodeRanker(t *testing.T) {
+	node := func(id string, availableCPU float64) model.NodeInfo {
+		return model.NodeInfo{
+			PeerInfo: peer.AddrInfo{ID: peer.ID(id)},
+			ComputeNodeInfo: model.ComputeNodeInfo{
+				MaxCapacity:       model.ResourceUsageData{CPU: 4, Memory: 100},
+				AvailableCapacity: model.ResourceUsageData{CPU: availableCPU, Memory: uint64(100 * availableCPU / 4)},
+			},
+		}
+	}
+	nodes := []model.NodeInfo{
+		node("idle", 4),
+		node("half", 2),
+		node("almost-full", 1),
+		node("full", 0),
+		{PeerInfo: peer.AddrInfo{ID: peer.ID("unknown")}},
+	}
+	job := model.Job{Spec: model.Spec{Resources: model.ResourceUsageConfig{CPU: "1"}}}
+
+	ranks, err := NewPlacementNodeRanker(PlacementNodeRankerParams{Strategy: requester.PlacementSpread}).
+		RankNodes(context.Background(), job, nodes)
+	require.NoError(t, err)
+	require.Len(t, ranks, len(nodes))
+	assertEquals(t, ranks, "idle", 20)
+	assertEquals(t, ranks, "half", 10)
+	assertEquals(t, ranks, "almost-full", 5)
+	assertEquals(t, ranks, "full", 0)
+	assertEquals(t, ranks, "unknown", 0)
+
+	ranks, err = NewPlacementNodeRanker(PlacementNodeRankerParams{Strategy: requester.PlacementBinPack}).
+		RankNodes(context.Background(), job, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "idle", 0)
+	assertEquals(t, ranks, "half", 10)
+	assertEquals(t, ranks, "almost-full", 15)
+	assertEquals(t, ranks, "full", 0)
+	assertEquals(t, ranks, "unknown", 0)
+
+	ranks, err = NewPlacementNodeRanker(PlacementNodeRankerParams{}).RankNodes(context.Background(), job, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "half", 0)
+}
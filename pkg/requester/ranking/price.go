@@ -0,0 +1,57 @@
+package ranking
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/rs/zerolog/log"
+)
+
+// maxPriceRank is the rank of the cheapest nodes for a job, and outweighs
+// the randomness of the random ranker.
+const maxPriceRank = 20
+
+type PriceNodeRanker struct{}
+
+func NewPriceNodeRanker() *PriceNodeRanker {
+	return &PriceNodeRanker{}
+}
+
+// RankNodes ranks nodes based on what running the job on them until its
+// timeout would cost at the pricing they advertise:
+// - Rank -1: Job would cost more than its max budget on the node.
+// - Rank 0-20: Job has a max budget, higher the less of it the job would cost.
+// - Rank 0-20: Job has no max budget, higher the cheaper the node is than the
+// most expensive of the nodes.
+// - Rank 0: No node charges for running the job.
+func (s *PriceNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	usage := capacity.ParseResourceUsageConfig(job.Spec.Resources)
+	costs := make([]float64, len(nodes))
+	maxCost := job.Spec.Deal.MaxBudget
+	for i, node := range nodes {
+		costs[i] = node.ComputeNodeInfo.Pricing.Cost(usage, job.Spec.GetTimeout())
+		if job.Spec.Deal.MaxBudget <= 0 && costs[i] > maxCost {
+			maxCost = costs[i]
+		}
+	}
+
+	ranks := make([]requester.NodeRank, len(nodes))
+	for i, node := range nodes {
+		rank := 0
+		switch {
+		case job.Spec.Deal.MaxBudget > 0 && costs[i] > job.Spec.Deal.MaxBudget:
+			log.Ctx(ctx).Trace().Msgf("filtering node %s that would cost %g, over the budget of %g",
+				node.PeerInfo.ID, costs[i], job.Spec.Deal.MaxBudget)
+			rank = -1
+		case maxCost > 0:
+			rank = int(maxPriceRank * (1 - costs[i]/maxCost))
+		}
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     rank,
+		}
+	}
+	return ranks, nil
+}
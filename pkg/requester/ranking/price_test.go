@@ -0,0 +1,37 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceNodeRanker(t *testing.T) {
+	nodes := []model.NodeInfo{}
+	for id, cpuSecond := range map[string]float64{"free": 0, "cheap": 0.01, "pricey": 0.04} {
+		nodes = append(nodes, model.NodeInfo{
+			PeerInfo:        peer.AddrInfo{ID: peer.ID(id)},
+			ComputeNodeInfo: model.ComputeNodeInfo{Pricing: model.Pricing{CPUSecond: cpuSecond}},
+		})
+	}
+	job := model.Job{Spec: model.Spec{Resources: model.ResourceUsageConfig{CPU: "1"}, Timeout: 100}}
+	ranker := NewPriceNodeRanker()
+
+	ranks, err := ranker.RankNodes(context.Background(), job, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "free", maxPriceRank)
+	assertEquals(t, ranks, "cheap", 15)
+	assertEquals(t, ranks, "pricey", 0)
+
+	job.Spec.Deal.MaxBudget = 2
+	ranks, err = ranker.RankNodes(context.Background(), job, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "free", maxPriceRank)
+	assertEquals(t, ranks, "cheap", 10)
+	assertEquals(t, ranks, "pricey", -1)
+}
@@ -0,0 +1,71 @@
+package ranking
+
+import (
+	"context"
+	"math"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+)
+
+const (
+	// reputationRank is the rank of nodes that have run all the executions
+	// they were given successfully, and outweighs the randomness of the
+	// random ranker. Nodes without a reputation get half of it.
+	reputationRank = 20
+	// reputationPrior is how many executions of neutral outcome every node is
+	// assumed to have run, so that a few outcomes of a new node don't swing
+	// its rank to either end.
+	reputationPrior = 2
+	// verificationFailureWeight is how many failed executions a rejected
+	// result counts as, since the node may have been dishonest.
+	verificationFailureWeight = 2
+)
+
+type ReputationNodeRankerParams struct {
+	Store jobstore.ReputationStore
+}
+
+type ReputationNodeRanker struct {
+	store jobstore.ReputationStore
+}
+
+func NewReputationNodeRanker(params ReputationNodeRankerParams) *ReputationNodeRanker {
+	return &ReputationNodeRanker{
+		store: params.Store,
+	}
+}
+
+// RankNodes ranks nodes based on how reliably they have run the executions
+// the requester gave them, where rejected results count against nodes more
+// than failures and timeouts do:
+// - Rank 0: Node failed all the executions it ran.
+// - Rank 10: Node hasn't run any executions, or failed half of them.
+// - Rank 20: Node ran all the executions it ran successfully.
+func (s *ReputationNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	reputations, err := s.store.GetReputations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byNode := make(map[string]model.NodeReputation, len(reputations))
+	for _, reputation := range reputations {
+		byNode[reputation.NodeID] = reputation
+	}
+
+	ranks := make([]requester.NodeRank, len(nodes))
+	for i, node := range nodes {
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     int(math.Round(reputationRank * reliability(byNode[node.PeerInfo.ID.String()]))),
+		}
+	}
+	return ranks, nil
+}
+
+// reliability scores the reputation between 0 and 1, starting from the middle
+// for nodes without one.
+func reliability(reputation model.NodeReputation) float64 {
+	failures := reputation.Failures + reputation.Timeouts + verificationFailureWeight*reputation.VerificationFailures
+	return (reputation.Successes + reputationPrior/2) / (reputation.Successes + failures + reputationPrior)
+}
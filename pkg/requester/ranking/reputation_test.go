@@ -0,0 +1,42 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReputationNodeRanker(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+	record := func(nodeID string, outcome model.ExecutionOutcome, times int) {
+		for i := 0; i < times; i++ {
+			_, err := store.RecordOutcome(ctx, peer.ID(nodeID).String(), outcome, time.Hour)
+			require.NoError(t, err)
+		}
+	}
+	record("reliable", model.ExecutionOutcomeSucceeded, 8)
+	record("flaky", model.ExecutionOutcomeSucceeded, 1)
+	record("flaky", model.ExecutionOutcomeFailed, 2)
+	record("flaky", model.ExecutionOutcomeTimedOut, 2)
+	record("cheat", model.ExecutionOutcomeVerificationFailed, 2)
+
+	nodes := []model.NodeInfo{}
+	for _, id := range []string{"reliable", "flaky", "cheat", "new"} {
+		nodes = append(nodes, model.NodeInfo{PeerInfo: peer.AddrInfo{ID: peer.ID(id)}})
+	}
+	ranker := NewReputationNodeRanker(ReputationNodeRankerParams{Store: store})
+	ranks, err := ranker.RankNodes(ctx, model.Job{}, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "reliable", 18)
+	assertEquals(t, ranks, "flaky", 6)
+	assertEquals(t, ranks, "cheat", 3)
+	assertEquals(t, ranks, "new", reputationRank/2)
+}
@@ -0,0 +1,39 @@
+package ranking
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/rs/zerolog/log"
+)
+
+type TaintsNodeRanker struct {
+}
+
+func NewTaintsNodeRanker() *TaintsNodeRanker {
+	return &TaintsNodeRanker{}
+}
+
+// RankNodes ranks nodes based on whether the job tolerates their taints:
+// - Rank 10: Job tolerates all the taints of the node, or the node has none.
+// - Rank 0: Job doesn't tolerate some PreferNoSchedule taints of the node.
+// - Rank -1: Job doesn't tolerate some NoSchedule taints of the node.
+func (s *TaintsNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	ranks := make([]requester.NodeRank, len(nodes))
+	for i, node := range nodes {
+		taints := node.ComputeNodeInfo.Taints
+		rank := 10
+		if untolerated := model.UntoleratedTaints(taints, job.Spec.Tolerations, model.TaintEffectNoSchedule); len(untolerated) > 0 {
+			log.Ctx(ctx).Trace().Msgf("filtering node %s with taints %v that the job doesn't tolerate", node.PeerInfo.ID, untolerated)
+			rank = -1
+		} else if len(model.UntoleratedTaints(taints, job.Spec.Tolerations, model.TaintEffectPreferNoSchedule)) > 0 {
+			rank = 0
+		}
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     rank,
+		}
+	}
+	return ranks, nil
+}
@@ -0,0 +1,47 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaintsNodeRanker(t *testing.T) {
+	nodes := []model.NodeInfo{
+		{PeerInfo: peer.AddrInfo{ID: "plain"}},
+		{
+			PeerInfo: peer.AddrInfo{ID: "reserved"},
+			ComputeNodeInfo: model.ComputeNodeInfo{Taints: []model.Taint{
+				{Key: "reserved", Value: "teamA", Effect: model.TaintEffectNoSchedule},
+			}},
+		},
+		{
+			PeerInfo: peer.AddrInfo{ID: "gpu"},
+			ComputeNodeInfo: model.ComputeNodeInfo{Taints: []model.Taint{
+				{Key: "gpu", Value: "true", Effect: model.TaintEffectPreferNoSchedule},
+			}},
+		},
+	}
+	ranker := NewTaintsNodeRanker()
+
+	ranks, err := ranker.RankNodes(context.Background(), model.Job{}, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "plain", 10)
+	assertEquals(t, ranks, "reserved", -1)
+	assertEquals(t, ranks, "gpu", 0)
+
+	job := model.Job{Spec: model.Spec{Tolerations: []model.Toleration{
+		{Key: "reserved", Operator: model.TolerationOpEqual, Value: "teamA"},
+		{Key: "gpu", Operator: model.TolerationOpExists},
+	}}}
+	ranks, err = ranker.RankNodes(context.Background(), job, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "plain", 10)
+	assertEquals(t, ranks, "reserved", 10)
+	assertEquals(t, ranks, "gpu", 10)
+}
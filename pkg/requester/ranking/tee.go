@@ -0,0 +1,35 @@
+package ranking
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/rs/zerolog/log"
+)
+
+type TEENodeRanker struct {
+}
+
+func NewTEENodeRanker() *TEENodeRanker {
+	return &TEENodeRanker{}
+}
+
+// RankNodes ranks nodes based on whether they run in the TEE that the job requires:
+// - Rank 10: Job doesn't require a TEE, or the node runs in the one it requires.
+// - Rank -1: Job requires a TEE that the node doesn't run in.
+func (s *TEENodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	ranks := make([]requester.NodeRank, len(nodes))
+	for i, node := range nodes {
+		rank := 10
+		if job.Spec.TEE != "" && !node.ComputeNodeInfo.TEE.Satisfies(job.Spec.TEE) {
+			log.Ctx(ctx).Trace().Msgf("filtering node %s that doesn't run in a %s TEE", node.PeerInfo.ID, job.Spec.TEE)
+			rank = -1
+		}
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     rank,
+		}
+	}
+	return ranks, nil
+}
@@ -0,0 +1,39 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTEENodeRanker(t *testing.T) {
+	nodes := []model.NodeInfo{
+		{PeerInfo: peer.AddrInfo{ID: "plain"}},
+		{PeerInfo: peer.AddrInfo{ID: "sev"}, ComputeNodeInfo: model.ComputeNodeInfo{TEE: model.TEESEVSNP}},
+		{PeerInfo: peer.AddrInfo{ID: "tdx"}, ComputeNodeInfo: model.ComputeNodeInfo{TEE: model.TEETDX}},
+	}
+	ranker := NewTEENodeRanker()
+
+	ranks, err := ranker.RankNodes(context.Background(), model.Job{}, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "plain", 10)
+	assertEquals(t, ranks, "sev", 10)
+	assertEquals(t, ranks, "tdx", 10)
+
+	ranks, err = ranker.RankNodes(context.Background(), model.Job{Spec: model.Spec{TEE: model.TEEAny}}, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "plain", -1)
+	assertEquals(t, ranks, "sev", 10)
+	assertEquals(t, ranks, "tdx", 10)
+
+	ranks, err = ranker.RankNodes(context.Background(), model.Job{Spec: model.Spec{TEE: model.TEETDX}}, nodes)
+	require.NoError(t, err)
+	assertEquals(t, ranks, "plain", -1)
+	assertEquals(t, ranks, "sev", -1)
+	assertEquals(t, ranks, "tdx", 10)
+}
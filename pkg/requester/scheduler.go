@@ -3,10 +3,14 @@ package requester
 import (
 	"context"
 	"fmt"
+	"net"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/bacalhau-project/bacalhau/pkg/attestation"
 	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
 	"github.com/bacalhau-project/bacalhau/pkg/logger"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
@@ -24,6 +28,15 @@ import (
 
 const OverAskForBidsFactor = 3 // ask up to 3 times the desired number of bids
 
+// DefaultGangTimeout is how long to wait for enough bids to place all the
+// executions of a gang job, unless the job has its own timeout. It is shorter
+// than compute nodes hold the capacity of their bids for by default.
+const DefaultGangTimeout = 2 * time.Minute
+
+// nodeLostStatus ends the status of executions that failed because their
+// compute node left the network.
+const nodeLostStatus = "left the network"
+
 type SchedulerParams struct {
 	ID               string
 	Host             host.Host
@@ -34,6 +47,30 @@ type SchedulerParams struct {
 	Verifiers        verifier.VerifierProvider
 	StorageProviders storage.StorageProvider
 	EventEmitter     EventEmitter
+	// WebhookAllowedNetworks are the networks that the webhooks of jobs can be
+	// on, on top of public addresses
+	WebhookAllowedNetworks []*net.IPNet
+	// Preemption governs when high priority jobs stop executions of others
+	Preemption PreemptionPolicy
+	// LostNodeReschedules is how many times each execution of a job is
+	// rescheduled on another node when its compute node is lost, on top of the
+	// retries that the retry policy of the job allows
+	LostNodeReschedules int
+	// Placement is how executions are placed on the nodes, which decides the
+	// order that bids are accepted in
+	Placement PlacementStrategy
+	// Speculation governs when executions that run far slower than the others
+	// of their job are duplicated on other nodes
+	Speculation SpeculationPolicy
+	// Reputations records how each execution ended in the reputation of its
+	// compute node, unless it is nil
+	Reputations jobstore.ReputationStore
+	// ReputationHalfLife is how long it takes for the outcomes of executions
+	// to count half as much in the reputations of their nodes
+	ReputationHalfLife time.Duration
+	// Attestations verifies the evidence of the TEEs of nodes that bid on jobs
+	// that require one. Bids on those jobs are rejected if it is nil.
+	Attestations *attestation.Verifier
 }
 
 type scheduler struct {
@@ -46,7 +83,23 @@ type scheduler struct {
 	verifiers        verifier.VerifierProvider
 	storageProviders storage.StorageProvider
 	eventEmitter     EventEmitter
-	mu               sync.Mutex
+	webhooks         *webhookNotifier
+	// jobs waiting to retry executions that failed
+	retrying map[string]struct{}
+	// when each high priority job last preempted executions
+	preempting map[string]time.Time
+	preemption PreemptionPolicy
+	// how many times each execution is rescheduled when its node is lost
+	lostNodeReschedules int
+	placement           PlacementStrategy
+	speculation         SpeculationPolicy
+	// the executions of each job that were duplicated because they were slow
+	speculated map[string]map[string]bool
+	// where the outcomes of executions are recorded, if anywhere
+	reputations        jobstore.ReputationStore
+	reputationHalfLife time.Duration
+	attestations       *attestation.Verifier
+	mu                 sync.Mutex
 }
 
 func NewScheduler(params SchedulerParams) *scheduler {
@@ -60,6 +113,21 @@ func NewScheduler(params SchedulerParams) *scheduler {
 		verifiers:        params.Verifiers,
 		storageProviders: params.StorageProviders,
 		eventEmitter:     params.EventEmitter,
+		webhooks:         newWebhookNotifier(hostKey(params.Host), params.WebhookAllowedNetworks),
+		retrying:         make(map[string]struct{}),
+		preempting:       make(map[string]time.Time),
+		preemption:       params.Preemption,
+
+		lostNodeReschedules: params.LostNodeReschedules,
+		placement:           params.Placement,
+		speculation:         params.Speculation,
+		speculated:          make(map[string]map[string]bool),
+		reputations:         params.Reputations,
+		reputationHalfLife:  params.ReputationHalfLife,
+		attestations:        params.Attestations,
+	}
+	if res.attestations == nil {
+		res.attestations = attestation.NewVerifier(attestation.VerifierParams{})
 	}
 
 	// TODO: replace with job level lock
@@ -71,40 +139,33 @@ func NewScheduler(params SchedulerParams) *scheduler {
 }
 
 func (s *scheduler) StartJob(ctx context.Context, req StartJobRequest) error {
-	nodeIDs, err := s.nodeDiscoverer.FindNodes(ctx, req.Job)
+	rankedNodes, err := s.rankNodes(ctx, req.Job)
 	if err != nil {
 		return err
 	}
-	log.Ctx(ctx).Debug().Msgf("found %d nodes for job %s", len(nodeIDs), req.Job.Metadata.ID)
 
-	rankedNodes, err := s.nodeRanker.RankNodes(ctx, req.Job, nodeIDs)
+	minBids := system.Max(req.Job.Spec.Deal.MinBids, req.Job.Spec.Deal.Concurrency)
+	err = placementError(req.Job, rankedNodes, minBids)
+	var noCapacity ErrNoCapacity
+	if errors.As(err, &noCapacity) && s.preemption.Enabled {
+		s.mu.Lock()
+		s.preemptFor(ctx, req.Job, rankedNodes, minBids-noCapacity.AvailableNodes)
+		s.mu.Unlock()
+	}
 	if err != nil {
 		return err
 	}
-
-	// filter nodes with rank below 0
-	var filteredNodes []NodeRank
-	for _, node := range rankedNodes {
-		if node.Rank >= 0 {
-			filteredNodes = append(filteredNodes, node)
-		}
-	}
-	rankedNodes = filteredNodes
-	log.Ctx(ctx).Debug().Msgf("ranked %d nodes for job %s", len(rankedNodes), req.Job.Metadata.ID)
-
-	minBids := system.Max(req.Job.Spec.Deal.MinBids, req.Job.Spec.Deal.Concurrency)
-	if len(rankedNodes) < minBids {
-		return NewErrNotEnoughNodes(minBids, len(rankedNodes))
+	if s.preemption.Enabled {
+		s.mu.Lock()
+		delete(s.preempting, req.Job.Metadata.ID)
+		s.mu.Unlock()
 	}
 
-	sort.Slice(rankedNodes, func(i, j int) bool {
-		return rankedNodes[i].Rank > rankedNodes[j].Rank
-	})
-
 	err = s.jobStore.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
 		JobID: req.Job.Metadata.ID,
 		Condition: jobstore.UpdateJobCondition{
-			ExpectedState: model.JobStateNew,
+			// jobs are started straight from the queue
+			UnexpectedStates: []model.JobStateType{model.JobStateInProgress},
 		},
 		NewState: model.JobStateInProgress,
 	})
@@ -115,9 +176,82 @@ func (s *scheduler) StartJob(ctx context.Context, req StartJobRequest) error {
 
 	selectedNodes := rankedNodes[:system.Min(len(rankedNodes), minBids*OverAskForBidsFactor)]
 	go s.notifyAskForBid(logger.ContextWithNodeIDLogger(context.Background(), s.id), trace.LinkFromContext(ctx), &req.Job, selectedNodes)
+	if req.Job.Spec.Deal.Gang {
+		s.watchGang(req.Job)
+	}
+	return nil
+}
+
+// rankNodes returns the nodes that are suitable to execute the job, with the
+// most preferable first.
+func (s *scheduler) rankNodes(ctx context.Context, job model.Job) ([]NodeRank, error) {
+	nodeIDs, err := s.nodeDiscoverer.FindNodes(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("found %d nodes for job %s", len(nodeIDs), job.Metadata.ID)
+
+	rankedNodes, err := s.nodeRanker.RankNodes(ctx, job, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// filter nodes with rank below 0
+	var filteredNodes []NodeRank
+	for _, node := range rankedNodes {
+		if node.Rank >= 0 {
+			filteredNodes = append(filteredNodes, node)
+		}
+	}
+	log.Ctx(ctx).Debug().Msgf("ranked %d nodes for job %s", len(filteredNodes), job.Metadata.ID)
+
+	sort.Slice(filteredNodes, func(i, j int) bool {
+		return filteredNodes[i].Rank > filteredNodes[j].Rank
+	})
+	return filteredNodes, nil
+}
+
+// placementError returns why the job can't start on the ranked nodes now, or
+// nil if it can.
+func placementError(job model.Job, rankedNodes []NodeRank, minBids int) error {
+	if len(rankedNodes) < minBids {
+		return NewErrNotEnoughNodes(minBids, len(rankedNodes))
+	}
+	if err := checkSchedulable(job, rankedNodes, minBids); err != nil {
+		return err
+	}
+	if withCapacity := nodesWithCapacity(job, rankedNodes); withCapacity < minBids {
+		return NewErrNoCapacity(minBids, withCapacity)
+	}
 	return nil
 }
 
+// nodesWithCapacity returns how many of the nodes have the capacity to run the
+// job now. Nodes that don't report their capacity are assumed to have it.
+func nodesWithCapacity(job model.Job, nodes []NodeRank) int {
+	usage := capacity.ParseResourceUsageConfig(job.Spec.Resources)
+	count := 0
+	for _, node := range nodes {
+		if hasCapacity(usage, node.NodeInfo.ComputeNodeInfo) {
+			count++
+		}
+	}
+	return count
+}
+
+// hasCapacity returns whether the node has the capacity to run a job with the
+// usage now.
+func hasCapacity(usage model.ResourceUsageData, info model.ComputeNodeInfo) bool {
+	switch {
+	case info.MaxCapacity.IsZero():
+		return true
+	case usage.IsZero():
+		return !info.AvailableCapacity.IsZero()
+	default:
+		return usage.LessThanEq(info.AvailableCapacity)
+	}
+}
+
 func (s *scheduler) CancelJob(ctx context.Context, request CancelJobRequest) (CancelJobResult, error) {
 	log.Ctx(ctx).Debug().Msgf("Requester node %s received CancelJob for job: %s with reason %s",
 		s.id, request.JobID, request.Reason)
@@ -132,7 +266,13 @@ func (s *scheduler) CancelJob(ctx context.Context, request CancelJobRequest) (Ca
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.stopJob(ctx, jobState.JobID, request.Reason, request.UserTriggered)
+	newState := model.JobStateError
+	if request.TimedOut {
+		newState = model.JobStateTimedOut
+	} else if request.UserTriggered {
+		newState = model.JobStateCancelled
+	}
+	s.stopJob(ctx, jobState.JobID, request.Reason, newState)
 	return CancelJobResult{}, nil
 }
 
@@ -291,7 +431,9 @@ func (s *scheduler) notifyResultAccepted(ctx context.Context, result verifier.Ve
 	}
 }
 
-func (s *scheduler) notifyResultRejected(ctx context.Context, result verifier.VerifierResult) {
+// notifyResultRejected rejects the results of the execution, recording the outcome in the reputation of its node
+// unless it is empty, as results that are rejected because the job itself failed aren't the node's fault.
+func (s *scheduler) notifyResultRejected(ctx context.Context, result verifier.VerifierResult, outcome model.ExecutionOutcome) {
 	log.Ctx(ctx).Debug().Msgf("Requester node %s responding with ResultRejected for bid: %s", s.id, result.Execution.ID())
 	err := s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
 		ExecutionID: result.Execution.ID(),
@@ -309,6 +451,9 @@ func (s *scheduler) notifyResultRejected(ctx context.Context, result verifier.Ve
 	if err != nil {
 		log.Ctx(ctx).Error().Err(err).Msgf("failed to update execution state to ResultRejected. %s", result.Execution.ID())
 	} else {
+		if outcome != "" {
+			s.recordOutcome(ctx, result.Execution.NodeID, outcome)
+		}
 		newCtx := util.NewDetachedContext(ctx)
 		go func(ctx context.Context) {
 			request := compute.ResultRejectedRequest{
@@ -328,8 +473,9 @@ func (s *scheduler) notifyResultRejected(ctx context.Context, result verifier.Ve
 }
 
 // notifyCancel only notifies compute nodes and doesn't update the execution state in the job store. This is because
-// the execution state is updated when stopping/failing the job itself.
-func (s *scheduler) notifyCancel(ctx context.Context, message string, execution model.ExecutionState) {
+// the execution state is updated when stopping/failing the job itself. byClient tells the compute node that the client
+// cancelled the job, which counts against the client's reputation on the node.
+func (s *scheduler) notifyCancel(ctx context.Context, message string, execution model.ExecutionState, byClient bool) {
 	log.Ctx(ctx).Debug().Msgf("Requester node %s responding with Cancel for bid: %s", s.id, execution.ComputeReference)
 
 	newCtx := util.NewDetachedContext(ctx)
@@ -337,6 +483,7 @@ func (s *scheduler) notifyCancel(ctx context.Context, message string, execution
 		request := compute.CancelExecutionRequest{
 			ExecutionID:   execution.ComputeReference,
 			Justification: message,
+			ByClient:      byClient,
 			RoutingMetadata: compute.RoutingMetadata{
 				SourcePeerID: s.id,
 				TargetPeerID: execution.NodeID,
@@ -376,7 +523,7 @@ func (s *scheduler) verifyResult(
 			s.notifyResultAccepted(ctx, verificationResult)
 			verifiedResults = append(verifiedResults, verificationResult)
 		} else {
-			s.notifyResultRejected(ctx, verificationResult)
+			s.notifyResultRejected(ctx, verificationResult, model.ExecutionOutcomeVerificationFailed)
 		}
 	}
 
@@ -400,6 +547,15 @@ func (s *scheduler) handleAskForBidResponse(ctx context.Context,
 	if response.Accepted {
 		newState = model.ExecutionStateAskForBidAccepted
 	}
+	status := response.Reason
+	// bids on jobs that require a TEE must come with evidence that the node runs in one
+	var attestationErr error
+	if response.Accepted && request.Job.Spec.TEE != "" {
+		attestationErr = s.attestations.Verify(response.Attestation, request.Job.Spec.TEE, response.JobID, request.TargetPeerID)
+		if attestationErr != nil {
+			status = attestationErr.Error()
+		}
+	}
 	err := s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
 		ExecutionID: executionID,
 		Condition: jobstore.UpdateExecutionCondition{
@@ -408,7 +564,9 @@ func (s *scheduler) handleAskForBidResponse(ctx context.Context,
 		NewValues: model.ExecutionState{
 			ComputeReference: response.ExecutionID,
 			State:            newState,
-			Status:           response.Reason,
+			Status:           status,
+			Quote:            response.Quote,
+			Attestation:      response.Attestation,
 		},
 	})
 	if err != nil {
@@ -416,6 +574,12 @@ func (s *scheduler) handleAskForBidResponse(ctx context.Context,
 		return
 	}
 
+	if attestationErr != nil {
+		log.Ctx(ctx).Warn().Err(attestationErr).Msgf("[handleAskForBidResponse] rejecting bid without a valid attestation")
+		s.rejectUnattestedBid(ctx, response.JobID, request.TargetPeerID, attestationErr)
+		return
+	}
+
 	// decide if we should notify compute node of the bid decision
 	// we only notify if we've already received more than MinBids
 	if response.Accepted {
@@ -428,6 +592,24 @@ func (s *scheduler) handleAskForBidResponse(ctx context.Context,
 	}
 }
 
+// rejectUnattestedBid rejects the bid of a node on a job that requires a TEE, when the node didn't attest that it runs
+// in one, and fails the job if it can't get enough attested bids.
+func (s *scheduler) rejectUnattestedBid(ctx context.Context, jobID string, nodeID string, attestationErr error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[rejectUnattestedBid] failed to get job state")
+		return
+	}
+	for _, execution := range jobState.Executions {
+		if execution.NodeID == nodeID && execution.State == model.ExecutionStateAskForBidAccepted {
+			s.notifyBidRejected(ctx, execution)
+		}
+	}
+	s.failIfRecoveryIsNotPossible(ctx, jobID, fmt.Errorf("not enough attested bids received: %w", attestationErr))
+}
+
 // startAcceptingBidsIfPossible is called when a compute node has accepted a bid
 // If we have received more than MinBids, we start accepting/rejecting bids, and notify the compute node of the decision
 func (s *scheduler) startAcceptingBidsIfPossible(ctx context.Context, response compute.AskForBidResponse) {
@@ -463,10 +645,25 @@ func (s *scheduler) startAcceptingBidsIfPossible(ctx context.Context, response c
 		return
 	}
 	// if we have more than MinBids, we start selecting the best bids and notify the compute nodes
-	if receivedBidsCount >= job.Spec.Deal.MinBids {
+	if receivedBidsCount >= job.Spec.Deal.MinBids && canPlaceGang(job, activeExecutionsCount, len(pendingBids)) {
+		if s.placement != PlacementAny && len(pendingBids) > 1 {
+			rankedNodes, rankErr := s.rankNodes(ctx, job)
+			if rankErr != nil {
+				log.Ctx(ctx).Warn().Err(rankErr).Msg("[startAcceptingBidsIfPossible] failed to rank nodes, so accepting bids as received")
+			} else {
+				orderBids(pendingBids, rankedNodes)
+			}
+		}
+		// jobs that set prices go to the nodes that quoted the least for them, in the order of the placement strategy
+		// when the quotes are the same
+		if job.Spec.Deal.MaxBudget > 0 || !job.Spec.Deal.Offer.IsZero() {
+			orderBidsByQuote(pendingBids)
+		}
+		// duplicates of slow executions run on top of the concurrency of the job
+		wanted := job.Spec.Deal.Concurrency + len(s.speculated[job.Metadata.ID])
 		// TODO: we should verify a bid acceptance was received by the compute node before rejecting other bids
 		for _, candidate := range pendingBids {
-			if activeExecutionsCount < job.Spec.Deal.Concurrency {
+			if activeExecutionsCount < wanted {
 				s.notifyBidAccepted(ctx, candidate)
 				activeExecutionsCount++
 			} else {
@@ -502,9 +699,66 @@ func (s *scheduler) OnRunComplete(ctx context.Context, result compute.RunResult)
 		return
 	}
 
+	if result.RunCommandResult != nil && result.RunCommandResult.ExitCode != 0 && s.retryFailedRun(ctx, result) {
+		return
+	}
 	s.startVerificationIfPossible(ctx, result.JobID)
 }
 
+// retryFailedRun rejects the results of an execution that exited with a
+// non-zero exit code and retries it on another node, if the retry policy of
+// the job allows.
+func (s *scheduler) retryFailedRun(ctx context.Context, result compute.RunResult) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, err := s.jobStore.GetJob(ctx, result.JobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[retryFailedRun] failed to get job")
+		return false
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, result.JobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[retryFailedRun] failed to get job state")
+		return false
+	}
+	if !canRetry(job, jobState, model.RetryOnExitCode) {
+		return false
+	}
+
+	// the node ran the job as it should, so the rejection doesn't count against it
+	for _, execution := range jobState.Executions {
+		if execution.NodeID == result.SourcePeerID && execution.ComputeReference == result.ExecutionID {
+			s.notifyResultRejected(ctx, verifier.VerifierResult{Execution: execution}, "")
+		}
+	}
+	return s.retryIfPossible(ctx, result.JobID, model.RetryOnExitCode)
+}
+
+// verifySignedExitCode returns an error if the exit code in the signed manifest
+// of a result isn't the one that the node reported when the execution ran.
+func (s *scheduler) verifySignedExitCode(
+	ctx context.Context, result compute.PublishResult, resultSignature *model.ResultSignature) error {
+	jobState, err := s.jobStore.GetJobState(ctx, result.JobID)
+	if err != nil {
+		return err
+	}
+	for _, execution := range jobState.Executions {
+		if execution.NodeID != result.SourcePeerID || execution.ComputeReference != result.ExecutionID {
+			continue
+		}
+		if execution.RunOutput == nil {
+			return fmt.Errorf("node %s signed exit code %d but didn't report how it ran job %s",
+				result.SourcePeerID, resultSignature.Manifest.ExitCode, result.JobID)
+		}
+		if execution.RunOutput.ExitCode != resultSignature.Manifest.ExitCode {
+			return fmt.Errorf("node %s signed exit code %d but reported that job %s exited with %d",
+				result.SourcePeerID, resultSignature.Manifest.ExitCode, result.JobID, execution.RunOutput.ExitCode)
+		}
+		return nil
+	}
+	return fmt.Errorf("node %s has no execution %s of job %s", result.SourcePeerID, result.ExecutionID, result.JobID)
+}
+
 func (s *scheduler) startVerificationIfPossible(ctx context.Context, jobID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -532,6 +786,10 @@ func (s *scheduler) startVerificationIfPossible(ctx context.Context, jobID strin
 	//  and concurrency. Though we will have ot handle the case where verification fails, but can still
 	//  succeed if we wait for more results.
 	if len(pendingVerifications) >= job.Spec.Deal.Concurrency {
+		if _, ok := s.speculated[jobID]; ok {
+			s.supersedeStragglers(ctx, jobState)
+			delete(s.speculated, jobID)
+		}
 		verifiedResults, verificationErr := s.verifyResult(ctx, job, pendingVerifications)
 		if verificationErr != nil {
 			s.failIfRecoveryIsNotPossible(ctx, jobID, fmt.Errorf("failed to verify job %s: %w", jobID, verificationErr))
@@ -551,6 +809,21 @@ func (s *scheduler) OnPublishComplete(ctx context.Context, result compute.Publis
 	// TODO: #831 verify that the published results are the same as the ones we expect, or let the verifier
 	//  publish the result and not all the compute nodes.
 
+	// results that are signed must be signed by the node that published them
+	status := publisherResultsStatus(result.PublisherResults)
+	resultSignature := result.ResultSignature
+	if resultSignature != nil {
+		err := resultSignature.Verify(result.JobID, result.SourcePeerID, result.PublishResult)
+		if err == nil {
+			err = s.verifySignedExitCode(ctx, result, resultSignature)
+		}
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msgf("[OnPublishComplete] dropping the result signature of execution %s", result.ExecutionID)
+			status = fmt.Sprintf("%s; %s", status, err)
+			resultSignature = nil
+		}
+	}
+
 	// update execution state
 	err := s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
 		ExecutionID: model.ExecutionID{
@@ -562,14 +835,19 @@ func (s *scheduler) OnPublishComplete(ctx context.Context, result compute.Publis
 			ExpectedState: model.ExecutionStateResultAccepted,
 		},
 		NewValues: model.ExecutionState{
-			PublishedResult: result.PublishResult,
-			State:           model.ExecutionStateCompleted,
+			PublishedResult:  result.PublishResult,
+			PublisherResults: result.PublisherResults,
+			SkippedOutputs:   result.SkippedOutputs,
+			ResultSignature:  resultSignature,
+			Status:           status,
+			State:            model.ExecutionStateCompleted,
 		},
 	})
 	if err != nil {
 		log.Ctx(ctx).Error().Err(err).Msgf("[OnPublishComplete] failed to update execution")
 		return
 	}
+	s.recordOutcome(ctx, result.SourcePeerID, model.ExecutionOutcomeSucceeded)
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -582,6 +860,10 @@ func (s *scheduler) OnPublishComplete(ctx context.Context, result compute.Publis
 		// An execution is still being worked on, so the job isn't completed yet.
 		return
 	}
+	if _, ok := s.retrying[result.JobID]; ok {
+		// An execution that failed is yet to be retried.
+		return
+	}
 	err = s.jobStore.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
 		JobID:    result.JobID,
 		NewState: model.JobStateCompleted,
@@ -592,6 +874,68 @@ func (s *scheduler) OnPublishComplete(ctx context.Context, result compute.Publis
 	} else {
 		log.Ctx(ctx).Info().Msgf("Job %s completed successfully", result.JobID)
 	}
+	s.notifyWebhook(ctx, result.JobID)
+}
+
+// publisherResultsStatus describes which publishers of the job failed to
+// publish the results of an execution, if only some of them succeeded.
+func publisherResultsStatus(results []model.PublisherResult) string {
+	var failures []string
+	for _, result := range results {
+		if result.Error != "" {
+			failures = append(failures, fmt.Sprintf("%s: %s", result.Publisher, result.Error))
+		}
+	}
+	if len(failures) == 0 {
+		// replaces the status of earlier attempts to publish that failed
+		return "published"
+	}
+	return fmt.Sprintf("published with %d of %d publishers, failed with %s",
+		len(results)-len(failures), len(results), strings.Join(failures, "; "))
+}
+
+// OnPublishPending updates the status of the execution with why publishing its
+// results failed, which the compute node tries again later.
+func (s *scheduler) OnPublishPending(ctx context.Context, result compute.PublishPendingResult) {
+	log.Ctx(ctx).Debug().Msgf("Requester node %s received PublishPending for execution: %s from %s",
+		s.id, result.ExecutionID, result.SourcePeerID)
+
+	err := s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: model.ExecutionID{
+			JobID:       result.JobID,
+			NodeID:      result.SourcePeerID,
+			ExecutionID: result.ExecutionID,
+		},
+		Condition: jobstore.UpdateExecutionCondition{
+			ExpectedState: model.ExecutionStateResultAccepted,
+		},
+		NewValues: model.ExecutionState{
+			Status: fmt.Sprintf("publish pending, retrying at %s: %s", result.RetryAt.Format(time.RFC3339), result.Err),
+		},
+		Comment: "publish pending",
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[OnPublishPending] failed to update execution")
+	}
+}
+
+// notifyWebhook notifies the webhook of the completed job, if it has one,
+// without holding up the scheduler.
+func (s *scheduler) notifyWebhook(ctx context.Context, jobID string) {
+	j, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[OnPublishComplete] failed to get job")
+		return
+	}
+	if j.Spec.Webhook == "" {
+		return
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[OnPublishComplete] failed to get job state")
+		return
+	}
+	go s.webhooks.notify(logger.ContextWithNodeIDLogger(context.Background(), s.id), j, jobState)
 }
 
 func (s *scheduler) OnCancelComplete(ctx context.Context, result compute.CancelResult) {
@@ -626,21 +970,120 @@ func (s *scheduler) OnComputeFailure(ctx context.Context, result compute.Compute
 		log.Ctx(ctx).Error().Err(err).Msgf("[OnComputeFailure] failed to update execution")
 		return
 	}
+	s.recordOutcome(ctx, result.SourcePeerID, model.ExecutionOutcomeFailed)
 
 	s.eventEmitter.EmitComputeFailure(ctx, result)
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.failIfRecoveryIsNotPossible(ctx, result.JobID, result)
+	s.retryIfRecoveryIsNotPossible(ctx, result.JobID, result, model.RetryOnError)
+}
+
+// CheckExecutions fails the executions of the job that are running on compute
+// nodes that have left the network, and reschedules them on other nodes. It
+// duplicates executions that straggle if the speculation policy allows.
+func (s *scheduler) CheckExecutions(ctx context.Context, jobDescription model.JobWithInfo) {
+	if s.speculation.Enabled() {
+		s.mu.Lock()
+		s.speculate(ctx, jobDescription)
+		s.mu.Unlock()
+	}
+
+	var running []model.ExecutionState
+	for _, execution := range jobDescription.State.Executions {
+		if execution.State == model.ExecutionStateBidAccepted || execution.State == model.ExecutionStateResultAccepted {
+			running = append(running, execution)
+		}
+	}
+	if len(running) == 0 {
+		return
+	}
+
+	nodes, err := s.nodeDiscoverer.FindNodes(ctx, jobDescription.Job)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[CheckExecutions] failed to find nodes")
+		return
+	}
+	found := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		found[node.PeerInfo.ID.String()] = true
+	}
+
+	for _, execution := range running {
+		if found[execution.NodeID] {
+			continue
+		}
+		failure := fmt.Errorf("compute node %s %s", model.ShortID(execution.NodeID), nodeLostStatus)
+		err = s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
+			ExecutionID: execution.ID(),
+			Condition: jobstore.UpdateExecutionCondition{
+				ExpectedState:   execution.State,
+				ExpectedVersion: execution.Version,
+			},
+			NewValues: model.ExecutionState{
+				State:  model.ExecutionStateFailed,
+				Status: failure.Error(),
+			},
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[CheckExecutions] failed to update execution")
+			continue
+		}
+		s.recordOutcome(ctx, execution.NodeID, model.ExecutionOutcomeFailed)
+		// in case the node comes back
+		s.notifyCancel(ctx, failure.Error(), execution, false)
+
+		s.mu.Lock()
+		s.rescheduleIfRecoveryIsNotPossible(ctx, execution.JobID, failure)
+		s.mu.Unlock()
+	}
+}
+
+// rescheduleIfRecoveryIsNotPossible replaces the executions of the job that
+// were lost with their compute nodes. They are retried if the retry policy of
+// the job allows, and are otherwise rescheduled on other nodes straight away,
+// unless they were lost too many times already.
+// make sure to call this function with the lock held
+func (s *scheduler) rescheduleIfRecoveryIsNotPossible(ctx context.Context, jobID string, failure error) {
+	if s.isRecoveryStillPossible(ctx, jobID) || s.retryIfPossible(ctx, jobID, model.RetryOnNodeLost) {
+		return
+	}
+	job, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[rescheduleIfRecoveryIsNotPossible] failed to get job")
+		return
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[rescheduleIfRecoveryIsNotPossible] failed to get job state")
+		return
+	}
+	if !canReschedule(job, jobState, s.lostNodeReschedules) {
+		s.stopJob(ctx, jobID, failure.Error(), model.JobStateError)
+		return
+	}
+	log.Ctx(ctx).Info().Msgf("rescheduling job %s after %s", jobID, failure)
+	s.replaceExecutions(ctx, jobID, func(model.ExecutionState) bool { return false })
 }
 
 // make sure to call this function with the lock held
 func (s *scheduler) failIfRecoveryIsNotPossible(ctx context.Context, jobID string, failure error) {
 	if !s.isRecoveryStillPossible(ctx, jobID) {
-		s.stopJob(ctx, jobID, failure.Error(), false)
+		s.stopJob(ctx, jobID, failure.Error(), model.JobStateError)
+	}
+}
+
+// make sure to call this function with the lock held
+func (s *scheduler) retryIfRecoveryIsNotPossible(ctx context.Context, jobID string, failure error, reason model.RetryReason) {
+	if !s.isRecoveryStillPossible(ctx, jobID) && !s.retryIfPossible(ctx, jobID, reason) {
+		s.stopJob(ctx, jobID, failure.Error(), model.JobStateError)
 	}
 }
 
 func (s *scheduler) isRecoveryStillPossible(ctx context.Context, jobID string) bool {
+	if _, ok := s.retrying[jobID]; ok {
+		// new executions will replace the ones that failed
+		return true
+	}
 	jobState, err := s.jobStore.GetJobState(ctx, jobID)
 	if err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("[isRecoveryStillPossible] failed to get job state")
@@ -662,25 +1105,224 @@ func (s *scheduler) isRecoveryStillPossible(ctx context.Context, jobID string) b
 	return activeExecutions >= job.Spec.Deal.Concurrency
 }
 
+// retryIfPossible starts new executions of the job on other nodes in place of
+// the ones that failed for the reason after a backoff, if the retry policy of
+// the job allows.
 // make sure to call this function with the lock held
-func (s *scheduler) stopJob(ctx context.Context, jobID, reason string, userRequested bool) {
-	if userRequested {
+func (s *scheduler) retryIfPossible(ctx context.Context, jobID string, reason model.RetryReason) bool {
+	if _, ok := s.retrying[jobID]; ok {
+		return true
+	}
+	job, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[retryIfPossible] failed to get job")
+		return false
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[retryIfPossible] failed to get job state")
+		return false
+	}
+	if !canRetry(job, jobState, reason) {
+		return false
+	}
+
+	backoff := retryBackoff(job.Spec.Retry, jobState)
+	log.Ctx(ctx).Info().Msgf("retrying job %s in %s after an execution failed with %s", jobID, backoff, reason)
+	s.retrying[jobID] = struct{}{}
+	retryCtx := logger.ContextWithNodeIDLogger(context.Background(), s.id)
+	time.AfterFunc(backoff, func() {
+		s.retryJob(retryCtx, jobID)
+	})
+	return true
+}
+
+// retryJob asks other nodes to bid on the job for as many executions as it
+// needs to replace the ones that failed.
+func (s *scheduler) retryJob(ctx context.Context, jobID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.retrying, jobID)
+	s.replaceExecutions(ctx, jobID, func(model.ExecutionState) bool { return false })
+}
+
+// replaceExecutions asks nodes to bid on the job for as many executions as it
+// needs to replace the ones that were discarded, avoiding the nodes that it
+// already had executions on unless reuseNode allows.
+// make sure to call this function with the lock held
+func (s *scheduler) replaceExecutions(ctx context.Context, jobID string, reuseNode func(model.ExecutionState) bool) {
+	job, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[replaceExecutions] failed to get job")
+		return
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[replaceExecutions] failed to get job state")
+		return
+	}
+	if jobState.State.IsTerminal() {
+		// e.g. the job was cancelled while waiting to retry
+		return
+	}
+
+	usedNodes := make(map[string]bool)
+	activeExecutions := 0
+	for _, execution := range jobState.Executions {
+		if !reuseNode(execution) {
+			usedNodes[execution.NodeID] = true
+		}
+		if !execution.State.IsDiscarded() {
+			activeExecutions++
+		}
+	}
+	needed := job.Spec.Deal.Concurrency - activeExecutions
+	if needed <= 0 {
+		return
+	}
+
+	rankedNodes, err := s.rankNodes(ctx, job)
+	if err != nil {
+		s.stopJob(ctx, jobID, err.Error(), model.JobStateError)
+		return
+	}
+	var otherNodes []NodeRank
+	for _, node := range rankedNodes {
+		if !usedNodes[node.NodeInfo.PeerInfo.ID.String()] {
+			otherNodes = append(otherNodes, node)
+		}
+	}
+	if len(otherNodes) < needed {
+		s.stopJob(ctx, jobID, NewErrNotEnoughNodes(needed, len(otherNodes)).Error(), model.JobStateError)
+		return
+	}
+
+	selectedNodes := otherNodes[:system.Min(len(otherNodes), needed*OverAskForBidsFactor)]
+	s.notifyAskForBid(ctx, trace.LinkFromContext(ctx), &job, selectedNodes)
+	if job.Spec.Deal.Gang {
+		s.watchGang(job)
+	}
+}
+
+// canPlaceGang returns whether the pending bids are enough to place all the
+// executions of the job that aren't placed yet. Only gang jobs wait for that,
+// so that none of their executions start unless all of them can.
+func canPlaceGang(job model.Job, placed, pending int) bool {
+	return !job.Spec.Deal.Gang || placed+pending >= job.Spec.Deal.Concurrency
+}
+
+// watchGang stops the job if its gang isn't placed within the gang timeout.
+// That cancels the executions that bid on it, so that their compute nodes
+// release the capacity that they reserved for them.
+func (s *scheduler) watchGang(job model.Job) {
+	timeout := job.Spec.Deal.GetGangTimeout()
+	if timeout == 0 {
+		timeout = DefaultGangTimeout
+	}
+	ctx := logger.ContextWithNodeIDLogger(context.Background(), s.id)
+	time.AfterFunc(timeout, func() {
+		s.checkGang(ctx, job.Metadata.ID, timeout)
+	})
+}
+
+func (s *scheduler) checkGang(ctx context.Context, jobID string, timeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[checkGang] failed to get job")
+		return
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[checkGang] failed to get job state")
+		return
+	}
+	if jobState.State.IsTerminal() {
+		return
+	}
+	placed := 0
+	for _, execution := range jobState.Executions {
+		if execution.State.IsActive() {
+			placed++
+		}
+	}
+	if placed < job.Spec.Deal.Concurrency {
+		s.stopJob(ctx, jobID, fmt.Sprintf("could only place %d of the %d executions of the gang within %s",
+			placed, job.Spec.Deal.Concurrency, timeout), model.JobStateError)
+	}
+}
+
+// canRetry returns whether the retry policy of the job allows for another
+// attempt at an execution that failed for the reason.
+func canRetry(job model.Job, jobState model.JobState, reason model.RetryReason) bool {
+	if !job.Spec.Retry.RetriesOn(reason) {
+		return false
+	}
+	attempts := 0
+	for _, execution := range jobState.Executions {
+		if execution.State.IsActive() || execution.State == model.ExecutionStateFailed ||
+			execution.State == model.ExecutionStateResultRejected {
+			attempts++
+		}
+	}
+	return attempts < job.Spec.Retry.MaxAttempts*system.Max(job.Spec.Deal.Concurrency, 1)
+}
+
+// canReschedule returns whether the executions of the job were lost with their
+// compute nodes no more times than they can be rescheduled.
+func canReschedule(job model.Job, jobState model.JobState, reschedules int) bool {
+	lost := 0
+	for _, execution := range jobState.Executions {
+		if execution.State == model.ExecutionStateFailed && strings.HasSuffix(execution.Status, nodeLostStatus) {
+			lost++
+		}
+	}
+	return lost <= reschedules*system.Max(job.Spec.Deal.Concurrency, 1)
+}
+
+// retryBackoff returns how long to wait before retrying, which doubles with
+// each execution of the job that failed.
+func retryBackoff(policy model.RetryPolicy, jobState model.JobState) time.Duration {
+	backoff := time.Duration(policy.Backoff * float64(time.Second))
+	failures := 0
+	for _, execution := range jobState.Executions {
+		if execution.State == model.ExecutionStateFailed || execution.State == model.ExecutionStateResultRejected {
+			failures++
+		}
+	}
+	for i := 1; i < failures; i++ {
+		backoff *= 2
+	}
+	return backoff
+}
+
+// make sure to call this function with the lock held
+func (s *scheduler) stopJob(ctx context.Context, jobID, reason string, newState model.JobStateType) {
+	eventName := model.JobEventError
+	switch newState {
+	case model.JobStateCancelled:
+		eventName = model.JobEventCanceled
 		log.Ctx(ctx).Info().Msgf("stopping job %s because the user requested it", jobID)
-	} else {
+	case model.JobStateTimedOut:
+		eventName = model.JobEventTimedOut
+		log.Ctx(ctx).Info().Msgf("stopping job %s because it %s", jobID, reason)
+	default:
 		log.Ctx(ctx).Error().Err(errors.New(reason)).Msgf("error completing job %s", jobID)
 	}
 
-	cancelledExecutions, err := jobstore.StopJob(ctx, s.jobStore, jobID, reason, userRequested)
+	delete(s.speculated, jobID)
+	cancelledExecutions, err := jobstore.StopJob(ctx, s.jobStore, jobID, reason, newState)
 	if err != nil {
 		log.Ctx(ctx).Error().Err(err).Msgf("[stopJob] failed to stop job")
 	}
 
 	for _, execution := range cancelledExecutions {
-		s.notifyCancel(ctx, reason, execution)
-	}
-	eventName := model.JobEventError
-	if userRequested {
-		eventName = model.JobEventCanceled
+		s.notifyCancel(ctx, reason, execution, newState == model.JobStateCancelled)
+		if newState == model.JobStateTimedOut &&
+			(execution.State == model.ExecutionStateBidAccepted || execution.State == model.ExecutionStateResultAccepted) {
+			s.recordOutcome(ctx, execution.NodeID, model.ExecutionOutcomeTimedOut)
+		}
 	}
 	s.eventEmitter.EmitEventSilently(ctx, model.JobEvent{
 		SourceNodeID: s.id,
@@ -691,6 +1333,17 @@ func (s *scheduler) stopJob(ctx context.Context, jobID, reason string, userReque
 	})
 }
 
+// recordOutcome counts how an execution ended in the reputation of its node.
+func (s *scheduler) recordOutcome(ctx context.Context, nodeID string, outcome model.ExecutionOutcome) {
+	if s.reputations == nil {
+		return
+	}
+	if _, err := s.reputations.RecordOutcome(ctx, nodeID, outcome, s.reputationHalfLife); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msgf("failed to record outcome %s of execution on node %s", outcome, nodeID)
+	}
+}
+
 // compile-time check that BackendCallback implements the expected interfaces
 var _ Scheduler = (*scheduler)(nil)
+var _ ExecutionMonitor = (*scheduler)(nil)
 var _ compute.Callback = (*scheduler)(nil)
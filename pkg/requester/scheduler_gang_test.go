@@ -0,0 +1,22 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanPlaceGang(t *testing.T) {
+	job := model.Job{Spec: model.Spec{Deal: model.Deal{Concurrency: 3}}}
+	require.True(t, canPlaceGang(job, 0, 1), "bids of jobs that aren't gangs are accepted as they come")
+
+	job.Spec.Deal.Gang = true
+	require.False(t, canPlaceGang(job, 0, 2))
+	require.True(t, canPlaceGang(job, 0, 3))
+	require.True(t, canPlaceGang(job, 0, 5))
+	require.False(t, canPlaceGang(job, 1, 1), "replacing an execution of a gang waits for as many bids as it needs")
+	require.True(t, canPlaceGang(job, 2, 1))
+}
@@ -0,0 +1,63 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanRetry(t *testing.T) {
+	job := model.Job{Spec: model.Spec{
+		Deal:  model.Deal{Concurrency: 1},
+		Retry: model.RetryPolicy{MaxAttempts: 3, RetryOn: []model.RetryReason{model.RetryOnNodeLost, model.RetryOnError}},
+	}}
+	jobState := func(states ...model.ExecutionStateType) model.JobState {
+		var executions []model.ExecutionState
+		for _, state := range states {
+			executions = append(executions, model.ExecutionState{State: state})
+		}
+		return model.JobState{Executions: executions}
+	}
+
+	require.True(t, canRetry(job, jobState(model.ExecutionStateFailed, model.ExecutionStateBidRejected), model.RetryOnError))
+	require.True(t, canRetry(job, jobState(model.ExecutionStateFailed, model.ExecutionStateFailed), model.RetryOnNodeLost))
+	require.False(t, canRetry(job, jobState(model.ExecutionStateFailed), model.RetryOnExitCode), "only the failures in the policy are retried")
+	require.False(t, canRetry(job, jobState(model.ExecutionStateFailed, model.ExecutionStateResultRejected, model.ExecutionStateFailed),
+		model.RetryOnError), "each execution is attempted at most max attempts times")
+
+	require.False(t, canRetry(model.Job{}, jobState(model.ExecutionStateFailed), model.RetryOnError), "jobs aren't retried by default")
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := model.RetryPolicy{MaxAttempts: 5, Backoff: 10}
+	jobState := model.JobState{Executions: []model.ExecutionState{{State: model.ExecutionStateFailed}}}
+	require.Equal(t, 10*time.Second, retryBackoff(policy, jobState))
+
+	jobState.Executions = append(jobState.Executions,
+		model.ExecutionState{State: model.ExecutionStateResultRejected},
+		model.ExecutionState{State: model.ExecutionStateFailed},
+		model.ExecutionState{State: model.ExecutionStateBidRejected},
+	)
+	require.Equal(t, 40*time.Second, retryBackoff(policy, jobState))
+}
+
+func TestCanReschedule(t *testing.T) {
+	job := model.Job{Spec: model.Spec{Deal: model.Deal{Concurrency: 2}}}
+	lost := model.ExecutionState{State: model.ExecutionStateFailed, Status: "compute node QmNode " + nodeLostStatus}
+	jobState := model.JobState{Executions: []model.ExecutionState{
+		lost,
+		{State: model.ExecutionStateFailed, Status: "exit code 1"},
+		{State: model.ExecutionStateBidAccepted},
+	}}
+
+	require.True(t, canReschedule(job, jobState, 1))
+	jobState.Executions = append(jobState.Executions, lost, lost)
+	require.False(t, canReschedule(job, jobState, 1), "each execution is rescheduled at most the given times")
+	require.True(t, canReschedule(job, jobState, 2))
+	require.False(t, canReschedule(job, model.JobState{Executions: []model.ExecutionState{lost}}, -1),
+		"executions are never rescheduled if it is negative")
+}
@@ -33,6 +33,11 @@ func (*mockScheduler) OnComputeFailure(ctx context.Context, err compute.ComputeE
 	panic("unimplemented")
 }
 
+// OnPublishPending implements Scheduler
+func (*mockScheduler) OnPublishPending(ctx context.Context, result compute.PublishPendingResult) {
+	panic("unimplemented")
+}
+
 // OnPublishComplete implements Scheduler
 func (*mockScheduler) OnPublishComplete(ctx context.Context, result compute.PublishResult) {
 	panic("unimplemented")
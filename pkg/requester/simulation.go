@@ -0,0 +1,95 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+)
+
+// SimulatePlacement discovers, ranks and selects the nodes for the job like
+// StartJob does, without running the job or preempting any executions for it.
+// Rankers that add randomness make the plan one of the placements that
+// starting the job could result in.
+func (s *scheduler) SimulatePlacement(ctx context.Context, job model.Job) (model.PlacementPlan, error) {
+	nodes, err := s.nodeDiscoverer.FindNodes(ctx, job)
+	if err != nil {
+		return model.PlacementPlan{}, err
+	}
+
+	var rankedNodes []NodeRank
+	var explained map[string]map[string]int
+	if explainer, ok := s.nodeRanker.(NodeRankExplainer); ok {
+		rankedNodes, explained, err = explainer.ExplainRanks(ctx, job, nodes)
+	} else {
+		rankedNodes, err = s.nodeRanker.RankNodes(ctx, job, nodes)
+	}
+	if err != nil {
+		return model.PlacementPlan{}, err
+	}
+	sort.SliceStable(rankedNodes, func(i, j int) bool {
+		return rankedNodes[i].Rank > rankedNodes[j].Rank
+	})
+
+	var suitable []NodeRank
+	for _, node := range rankedNodes {
+		if node.Rank >= 0 {
+			suitable = append(suitable, node)
+		}
+	}
+
+	minBids := system.Max(job.Spec.Deal.MinBids, job.Spec.Deal.Concurrency)
+	plan := model.PlacementPlan{RequestedNodes: minBids, CanStart: true}
+	if err = placementError(job, suitable, minBids); err != nil {
+		plan.CanStart = false
+		plan.Reason = err.Error()
+	}
+
+	asked := system.Min(len(suitable), minBids*OverAskForBidsFactor)
+	usage := capacity.ParseResourceUsageConfig(job.Spec.Resources)
+	for i, node := range rankedNodes {
+		nodeID := node.NodeInfo.PeerInfo.ID.String()
+		placement := model.NodePlacement{
+			NodeID:      nodeID,
+			Rank:        node.Rank,
+			Ranks:       explained[nodeID],
+			HasCapacity: hasCapacity(usage, node.NodeInfo.ComputeNodeInfo),
+		}
+		switch {
+		case node.Rank < 0:
+			placement.Reason = "not suitable for the job"
+			if rejectedBy := rejectingRankers(placement.Ranks); len(rejectedBy) > 0 {
+				placement.Reason += " according to " + strings.Join(rejectedBy, ", ")
+			}
+		case i < asked && plan.CanStart:
+			placement.Selected = true
+			placement.Reason = fmt.Sprintf("among the %d highest ranked suitable nodes", asked)
+		case i < asked:
+			placement.Reason = fmt.Sprintf("among the %d highest ranked suitable nodes, but the job can't start now", asked)
+		default:
+			placement.Reason = fmt.Sprintf("ranked below the %d nodes that would be asked to bid", asked)
+		}
+		plan.Nodes = append(plan.Nodes, placement)
+	}
+	return plan, nil
+}
+
+// rejectingRankers returns the names of the rankers that ranked a node below
+// zero, in order.
+func rejectingRankers(ranks map[string]int) []string {
+	var names []string
+	for name, rank := range ranks {
+		if rank < 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// compile-time check that scheduler implements the expected interfaces
+var _ Placements = (*scheduler)(nil)
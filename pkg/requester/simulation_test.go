@@ -0,0 +1,67 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedNodeDiscoverer []model.NodeInfo
+
+func (d fixedNodeDiscoverer) FindNodes(context.Context, model.Job) ([]model.NodeInfo, error) {
+	return d, nil
+}
+
+// fixedNodeRanker ranks each node by its id.
+type fixedNodeRanker map[string]int
+
+func (r fixedNodeRanker) RankNodes(_ context.Context, _ model.Job, nodes []model.NodeInfo) ([]NodeRank, error) {
+	ranks := make([]NodeRank, len(nodes))
+	for i, node := range nodes {
+		ranks[i] = NodeRank{NodeInfo: node, Rank: r[string(node.PeerInfo.ID)]}
+	}
+	return ranks, nil
+}
+
+func TestSimulatePlacement(t *testing.T) {
+	node := func(id string) model.NodeInfo {
+		return model.NodeInfo{PeerInfo: peer.AddrInfo{ID: peer.ID(id)}}
+	}
+	s := NewScheduler(SchedulerParams{
+		NodeDiscoverer: fixedNodeDiscoverer{node("a"), node("b"), node("c"), node("d"), node("e")},
+		NodeRanker:     fixedNodeRanker{"a": 10, "b": 30, "c": -1, "d": 20, "e": 5},
+	})
+	job := model.Job{Spec: model.Spec{Deal: model.Deal{Concurrency: 1}}}
+
+	plan, err := s.SimulatePlacement(context.Background(), job)
+	require.NoError(t, err)
+	require.True(t, plan.CanStart)
+	require.Equal(t, 1, plan.RequestedNodes)
+
+	var selected []string
+	for _, placement := range plan.Nodes {
+		if placement.Selected {
+			selected = append(selected, placement.NodeID)
+		}
+		require.True(t, placement.HasCapacity)
+		require.NotEmpty(t, placement.Reason)
+	}
+	require.Len(t, plan.Nodes, 5)
+	require.Equal(t, []string{peer.ID("b").String(), peer.ID("d").String(), peer.ID("a").String()}, selected)
+	require.Equal(t, peer.ID("c").String(), plan.Nodes[4].NodeID)
+	require.False(t, plan.Nodes[4].Selected)
+
+	job.Spec.Deal.Concurrency = 5
+	plan, err = s.SimulatePlacement(context.Background(), job)
+	require.NoError(t, err)
+	require.False(t, plan.CanStart)
+	require.Contains(t, plan.Reason, "not enough nodes")
+	for _, placement := range plan.Nodes {
+		require.False(t, placement.Selected)
+	}
+}
@@ -0,0 +1,151 @@
+package requester
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultSpeculationMinRuntime is how long executions run before they can be
+// duplicated, unless configured otherwise.
+const DefaultSpeculationMinRuntime = time.Minute
+
+// supersededStatus is the status of executions that were cancelled because
+// enough of the other executions of their job finished first.
+const supersededStatus = "superseded by executions that finished first"
+
+// SpeculationPolicy governs when the scheduler launches a duplicate of an
+// execution that is running far slower than the executions of its job that
+// already finished. The job takes whichever of the two finishes first, and
+// if both finish before the results are verified, the verifier of the job
+// decides which results to keep as it does for any other results.
+type SpeculationPolicy struct {
+	// SlowdownFactor is how many times longer than the finished executions of
+	// its job took an execution must run before it is duplicated on another
+	// node. Executions are never duplicated if it is zero.
+	SlowdownFactor float64
+	// MinRuntime is how long an execution must run before it is duplicated,
+	// so that executions of quick jobs aren't duplicated over noise
+	MinRuntime time.Duration
+}
+
+// Enabled returns whether slow executions are duplicated at all.
+func (p SpeculationPolicy) Enabled() bool {
+	return p.SlowdownFactor > 0
+}
+
+// speculate launches duplicates of the executions of the job that straggle
+// behind the ones that finished, on nodes that the job has no executions on.
+// Each execution is only duplicated once.
+// make sure to call this function with the lock held
+func (s *scheduler) speculate(ctx context.Context, jobDescription model.JobWithInfo) {
+	jobID := jobDescription.Job.Metadata.ID
+	duplicated := s.speculated[jobID]
+	var slow []model.ExecutionState
+	for _, execution := range stragglers(s.speculation, jobDescription.State, time.Now()) {
+		if !duplicated[execution.ComputeReference] {
+			slow = append(slow, execution)
+		}
+	}
+	if len(slow) == 0 {
+		return
+	}
+
+	rankedNodes, err := s.rankNodes(ctx, jobDescription.Job)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msgf("[speculate] failed to rank nodes for job %s", jobID)
+		return
+	}
+	usedNodes := make(map[string]bool)
+	for _, execution := range jobDescription.State.Executions {
+		usedNodes[execution.NodeID] = true
+	}
+	var otherNodes []NodeRank
+	for _, node := range rankedNodes {
+		if !usedNodes[node.NodeInfo.PeerInfo.ID.String()] {
+			otherNodes = append(otherNodes, node)
+		}
+	}
+	if len(otherNodes) == 0 {
+		log.Ctx(ctx).Debug().Msgf("no other nodes to duplicate the slow executions of job %s on", jobID)
+		return
+	}
+
+	if duplicated == nil {
+		duplicated = make(map[string]bool)
+		s.speculated[jobID] = duplicated
+	}
+	for _, execution := range slow {
+		log.Ctx(ctx).Info().Msgf("duplicating execution %s of job %s as it is running slower than the others", execution, jobID)
+		duplicated[execution.ComputeReference] = true
+	}
+	selectedNodes := otherNodes[:system.Min(len(otherNodes), len(slow)*OverAskForBidsFactor)]
+	s.notifyAskForBid(ctx, trace.LinkFromContext(ctx), &jobDescription.Job, selectedNodes)
+}
+
+// supersedeStragglers cancels the executions of the job that are still running
+// once enough of its executions finished to verify the results, which are the
+// losers of the race between executions and their duplicates.
+// make sure to call this function with the lock held
+func (s *scheduler) supersedeStragglers(ctx context.Context, jobState model.JobState) {
+	for _, execution := range jobState.Executions {
+		if execution.State != model.ExecutionStateBidAccepted {
+			continue
+		}
+		err := s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
+			ExecutionID: execution.ID(),
+			Condition: jobstore.UpdateExecutionCondition{
+				ExpectedState:   execution.State,
+				ExpectedVersion: execution.Version,
+			},
+			NewValues: model.ExecutionState{
+				State:  model.ExecutionStateCanceled,
+				Status: supersededStatus,
+			},
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[supersedeStragglers] failed to update execution %s", execution)
+			continue
+		}
+		s.notifyCancel(ctx, supersededStatus, execution, false)
+	}
+}
+
+// stragglers returns the running executions of the job that have been running
+// for longer than the policy allows compared to the median time that its
+// finished executions took.
+func stragglers(policy SpeculationPolicy, jobState model.JobState, now time.Time) []model.ExecutionState {
+	if !policy.Enabled() {
+		return nil
+	}
+	var finished []time.Duration
+	for _, execution := range jobState.Executions {
+		// executions wait in this state until all of them finished
+		if execution.State == model.ExecutionStateResultProposed {
+			finished = append(finished, execution.UpdateTime.Sub(execution.CreateTime))
+		}
+	}
+	if len(finished) == 0 {
+		return nil
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i] < finished[j] })
+	median := finished[len(finished)/2]
+
+	threshold := time.Duration(float64(median) * policy.SlowdownFactor)
+	if threshold < policy.MinRuntime {
+		threshold = policy.MinRuntime
+	}
+	var slow []model.ExecutionState
+	for _, execution := range jobState.Executions {
+		if execution.State == model.ExecutionStateBidAccepted && now.Sub(execution.CreateTime) > threshold {
+			slow = append(slow, execution)
+		}
+	}
+	return slow
+}
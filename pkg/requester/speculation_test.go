@@ -0,0 +1,49 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStragglers(t *testing.T) {
+	now := time.Now()
+	execution := func(id string, state model.ExecutionStateType, started, updated time.Duration) model.ExecutionState {
+		return model.ExecutionState{
+			ComputeReference: id,
+			State:            state,
+			CreateTime:       now.Add(-started),
+			UpdateTime:       now.Add(-updated),
+		}
+	}
+	jobState := model.JobState{Executions: []model.ExecutionState{
+		// finished after 1, 2 and 10 minutes
+		execution("e1", model.ExecutionStateResultProposed, 20*time.Minute, 19*time.Minute),
+		execution("e2", model.ExecutionStateResultProposed, 20*time.Minute, 18*time.Minute),
+		execution("e3", model.ExecutionStateResultProposed, 20*time.Minute, 10*time.Minute),
+		execution("slow", model.ExecutionStateBidAccepted, 7*time.Minute, 7*time.Minute),
+		execution("slower", model.ExecutionStateBidAccepted, 20*time.Minute, 20*time.Minute),
+		execution("failed", model.ExecutionStateFailed, 20*time.Minute, 19*time.Minute),
+	}}
+	ids := func(executions []model.ExecutionState) []string {
+		var result []string
+		for _, e := range executions {
+			result = append(result, e.ComputeReference)
+		}
+		return result
+	}
+
+	require.Empty(t, stragglers(SpeculationPolicy{}, jobState, now), "executions aren't duplicated unless enabled")
+	require.Equal(t, []string{"slow", "slower"}, ids(stragglers(SpeculationPolicy{SlowdownFactor: 3}, jobState, now)),
+		"executions straggle behind the median of the finished executions")
+	require.Equal(t, []string{"slower"}, ids(stragglers(SpeculationPolicy{SlowdownFactor: 4}, jobState, now)))
+	require.Equal(t, []string{"slower"}, ids(stragglers(SpeculationPolicy{SlowdownFactor: 3, MinRuntime: 10 * time.Minute}, jobState, now)),
+		"executions must run for the minimum runtime")
+
+	jobState.Executions = jobState.Executions[3:]
+	require.Empty(t, stragglers(SpeculationPolicy{SlowdownFactor: 3}, jobState, now), "there is nothing to compare with")
+}
@@ -0,0 +1,221 @@
+package requester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// templatePlaceholder is the value that parameters without a default take when
+// a template is checked before it's stored.
+const templatePlaceholder = "placeholder"
+
+type TemplateManagerParams struct {
+	Store jobstore.TemplateStore
+}
+
+// TemplateManager hosts the job templates that clients submit jobs from, and
+// renders the spec of each job from its template, the values of the template's
+// parameters and the parts of the spec that the client overrides.
+type TemplateManager struct {
+	store jobstore.TemplateStore
+}
+
+func NewTemplateManager(params TemplateManagerParams) *TemplateManager {
+	return &TemplateManager{
+		store: params.Store,
+	}
+}
+
+// SetTemplate creates the template, or replaces the template with the same
+// name, once it has checked that its spec only refers to its parameters.
+func (m *TemplateManager) SetTemplate(ctx context.Context, payload model.TemplateSetPayload) (model.JobTemplate, error) {
+	tmpl := payload.Template
+	if tmpl.Name == "" {
+		return model.JobTemplate{}, errors.New("the template must have a name")
+	}
+	if tmpl.APIVersion == "" {
+		return model.JobTemplate{}, errors.New("the template must have an API version")
+	}
+	values := make(map[string]string, len(tmpl.Parameters))
+	for _, param := range tmpl.Parameters {
+		if param.Name == "" {
+			return model.JobTemplate{}, errors.New("the parameters of the template must have names")
+		}
+		if _, ok := values[param.Name]; ok {
+			return model.JobTemplate{}, fmt.Errorf("the template has more than one parameter %s", param.Name)
+		}
+		values[param.Name] = param.Default
+		if param.Default == "" {
+			values[param.Name] = templatePlaceholder
+		}
+	}
+	if _, err := renderSpec(tmpl, values); err != nil {
+		return model.JobTemplate{}, err
+	}
+
+	now := time.Now()
+	tmpl.ClientID = payload.ClientID
+	tmpl.CreatedAt = now
+	tmpl.UpdatedAt = now
+	existing, err := m.store.GetTemplate(ctx, tmpl.Name)
+	if err == nil {
+		tmpl.CreatedAt = existing.CreatedAt
+	} else if !errors.As(err, &jobstore.ErrTemplateNotFound{}) {
+		return model.JobTemplate{}, err
+	}
+	if err = m.store.SetTemplate(ctx, tmpl); err != nil {
+		return model.JobTemplate{}, err
+	}
+	return tmpl, nil
+}
+
+// GetTemplate gets the template with the name.
+func (m *TemplateManager) GetTemplate(ctx context.Context, name string) (model.JobTemplate, error) {
+	return m.store.GetTemplate(ctx, name)
+}
+
+// GetTemplates gets all the templates.
+func (m *TemplateManager) GetTemplates(ctx context.Context) ([]model.JobTemplate, error) {
+	return m.store.GetTemplates(ctx)
+}
+
+// DeleteTemplate deletes the template, leaving the jobs that were submitted
+// from it.
+func (m *TemplateManager) DeleteTemplate(ctx context.Context, payload model.TemplateDeletePayload) error {
+	return m.store.DeleteTemplate(ctx, payload.Name)
+}
+
+// ApplyTemplate returns the payload with the spec rendered from the template
+// it refers to, overridden by the fields that are set in the spec of the
+// payload.
+func (m *TemplateManager) ApplyTemplate(ctx context.Context, payload model.JobCreatePayload) (model.JobCreatePayload, error) {
+	tmpl, err := m.store.GetTemplate(ctx, payload.Template)
+	if err != nil {
+		return payload, err
+	}
+	return ResolveTemplate(tmpl, payload)
+}
+
+// ResolveTemplate returns the payload with the spec rendered from the
+// template, overridden by the fields that are set in the spec of the payload.
+// Clients resolve templates themselves to sign the spec that their job runs.
+func ResolveTemplate(tmpl model.JobTemplate, payload model.JobCreatePayload) (model.JobCreatePayload, error) {
+	values := make(map[string]string, len(tmpl.Parameters))
+	for _, param := range tmpl.Parameters {
+		value, ok := payload.TemplateParameters[param.Name]
+		if !ok || value == "" {
+			value = param.Default
+		}
+		if value == "" {
+			return payload, fmt.Errorf("parameter %s of template %s is required", param.Name, tmpl.Name)
+		}
+		values[param.Name] = value
+	}
+	for name := range payload.TemplateParameters {
+		if _, ok := values[name]; !ok {
+			return payload, fmt.Errorf("template %s has no parameter %s", tmpl.Name, name)
+		}
+	}
+
+	rendered, err := renderSpec(tmpl, values)
+	if err != nil {
+		return payload, err
+	}
+	spec := rendered
+	if payload.Spec != nil {
+		if spec, err = overrideSpec(rendered, *payload.Spec); err != nil {
+			return payload, err
+		}
+	}
+	payload.Spec = &spec
+	if payload.APIVersion == "" {
+		payload.APIVersion = tmpl.APIVersion
+	}
+	return payload, nil
+}
+
+// renderSpec replaces the references to parameters in the strings of the spec
+// of the template with their values.
+func renderSpec(tmpl model.JobTemplate, values map[string]string) (model.Spec, error) {
+	raw, err := json.Marshal(tmpl.Spec)
+	if err != nil {
+		return model.Spec{}, err
+	}
+	parsed, err := template.New(tmpl.Name).Option("missingkey=error").Parse(string(raw))
+	if err != nil {
+		return model.Spec{}, fmt.Errorf("failed to parse the spec of template %s: %w", tmpl.Name, err)
+	}
+
+	// the values end up in JSON strings, so they are escaped like them
+	escaped := make(map[string]string, len(values))
+	for name, value := range values {
+		quoted, err := json.Marshal(value)
+		if err != nil {
+			return model.Spec{}, err
+		}
+		escaped[name] = strings.TrimSuffix(strings.TrimPrefix(string(quoted), `"`), `"`)
+	}
+	var out bytes.Buffer
+	if err = parsed.Execute(&out, escaped); err != nil {
+		return model.Spec{}, fmt.Errorf("failed to render the spec of template %s: %w", tmpl.Name, err)
+	}
+
+	var spec model.Spec
+	if err = json.Unmarshal(out.Bytes(), &spec); err != nil {
+		return model.Spec{}, fmt.Errorf("failed to render the spec of template %s: %w", tmpl.Name, err)
+	}
+	return spec, nil
+}
+
+// overrideSpec returns the spec with the fields that are set in the overrides
+// replaced by them. Objects are overridden field by field, and everything else
+// as a whole. Fields that have the value of an empty spec aren't set.
+func overrideSpec(spec, overrides model.Spec) (model.Spec, error) {
+	var base, over, zero map[string]any
+	for target, source := range map[*map[string]any]model.Spec{&base: spec, &over: overrides, &zero: {}} {
+		raw, err := json.Marshal(source)
+		if err != nil {
+			return model.Spec{}, err
+		}
+		if err = json.Unmarshal(raw, target); err != nil {
+			return model.Spec{}, err
+		}
+	}
+
+	raw, err := json.Marshal(mergeJSON(base, over, zero))
+	if err != nil {
+		return model.Spec{}, err
+	}
+	var result model.Spec
+	err = json.Unmarshal(raw, &result)
+	return result, err
+}
+
+func mergeJSON(base, over, zero map[string]any) map[string]any {
+	for key, value := range over {
+		overObject, isObject := value.(map[string]any)
+		baseObject, baseIsObject := base[key].(map[string]any)
+		switch {
+		case isObject && baseIsObject:
+			zeroObject, _ := zero[key].(map[string]any)
+			base[key] = mergeJSON(baseObject, overObject, zeroObject)
+		case reflect.DeepEqual(value, zero[key]):
+		default:
+			base[key] = value
+		}
+	}
+	return base
+}
+
+// compile-time check that TemplateManager implements the expected interfaces
+var _ Templates = (*TemplateManager)(nil)
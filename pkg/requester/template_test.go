@@ -0,0 +1,78 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyTemplate(t *testing.T) {
+	ctx := context.Background()
+	m := NewTemplateManager(TemplateManagerParams{Store: inmemory.NewJobStore()})
+
+	_, err := m.SetTemplate(ctx, model.TemplateSetPayload{
+		ClientID: "team",
+		Template: model.JobTemplate{
+			Name:       "train",
+			APIVersion: model.APIVersionLatest().String(),
+			Spec: model.Spec{
+				Engine:    model.EngineDocker,
+				Publisher: model.PublisherIpfs,
+				Docker: model.JobSpecDocker{
+					Image:      "trainer:1",
+					Entrypoint: []string{"train", "--input", "{{.input}}", "--epochs", "{{.epochs}}"},
+				},
+				Resources: model.ResourceUsageConfig{CPU: "4", GPU: "1"},
+				Deal:      model.Deal{Concurrency: 1},
+			},
+			Parameters: []model.TemplateParameter{{Name: "input"}, {Name: "epochs", Default: "10"}},
+		},
+	})
+	require.NoError(t, err)
+
+	payload, err := m.ApplyTemplate(ctx, model.JobCreatePayload{
+		ClientID:           "client",
+		Template:           "train",
+		TemplateParameters: map[string]string{"input": `s3://bucket/"data"`},
+		Spec:               &model.Spec{Resources: model.ResourceUsageConfig{CPU: "8"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, model.APIVersionLatest().String(), payload.APIVersion)
+	require.Equal(t, model.EngineDocker, payload.Spec.Engine)
+	require.Equal(t, model.PublisherIpfs, payload.Spec.Publisher)
+	require.Equal(t, "trainer:1", payload.Spec.Docker.Image)
+	require.Equal(t, []string{"train", "--input", `s3://bucket/"data"`, "--epochs", "10"}, payload.Spec.Docker.Entrypoint)
+	require.Equal(t, model.ResourceUsageConfig{CPU: "8", GPU: "1"}, payload.Spec.Resources, "only the fields set are overridden")
+	require.Equal(t, 1, payload.Spec.Deal.Concurrency)
+
+	_, err = m.ApplyTemplate(ctx, model.JobCreatePayload{Template: "train"})
+	require.ErrorContains(t, err, "parameter input of template train is required")
+
+	_, err = m.ApplyTemplate(ctx, model.JobCreatePayload{
+		Template:           "train",
+		TemplateParameters: map[string]string{"input": "x", "other": "y"},
+	})
+	require.ErrorContains(t, err, "template train has no parameter other")
+
+	_, err = m.ApplyTemplate(ctx, model.JobCreatePayload{Template: "missing"})
+	require.ErrorAs(t, err, &jobstore.ErrTemplateNotFound{})
+}
+
+func TestSetTemplateRejectsUndeclaredParameters(t *testing.T) {
+	m := NewTemplateManager(TemplateManagerParams{Store: inmemory.NewJobStore()})
+	_, err := m.SetTemplate(context.Background(), model.TemplateSetPayload{
+		ClientID: "team",
+		Template: model.JobTemplate{
+			Name:       "train",
+			APIVersion: model.APIVersionLatest().String(),
+			Spec:       model.Spec{Docker: model.JobSpecDocker{Image: "trainer:{{.version}}"}},
+		},
+	})
+	require.ErrorContains(t, err, "failed to render the spec of template train")
+}
@@ -17,18 +17,108 @@ type Endpoint interface {
 	CancelJob(context.Context, CancelJobRequest) (CancelJobResult, error)
 }
 
+// Schedules manages the jobs that the requester submits each time their cron expression fires.
+type Schedules interface {
+	// CreateSchedule creates a schedule that submits the job from the next time its cron expression fires.
+	CreateSchedule(context.Context, model.ScheduleCreatePayload) (model.JobSchedule, error)
+	// GetSchedule gets the schedule with the id, which can be a short id.
+	GetSchedule(ctx context.Context, id string) (model.JobSchedule, error)
+	// GetSchedules gets the schedules of the client, or of all clients if the client id is empty.
+	GetSchedules(ctx context.Context, clientID string) ([]model.JobSchedule, error)
+	// UpdateSchedule pauses, resumes, triggers or deletes a schedule.
+	UpdateSchedule(context.Context, model.ScheduleUpdatePayload) (model.JobSchedule, error)
+}
+
+// Workflows runs sets of jobs whose stages depend on the outputs of others.
+type Workflows interface {
+	// SubmitWorkflow submits the stages of the workflow that don't depend on any others.
+	SubmitWorkflow(context.Context, model.WorkflowSubmitPayload) (model.Workflow, error)
+	// GetWorkflow gets the workflow with the id, which can be a short id.
+	GetWorkflow(ctx context.Context, id string) (model.Workflow, error)
+	// GetWorkflows gets the workflows of the client, or of all clients if the client id is empty.
+	GetWorkflows(ctx context.Context, clientID string) ([]model.Workflow, error)
+	// CancelWorkflow cancels the running jobs of the workflow and the stages that are still pending.
+	CancelWorkflow(context.Context, model.WorkflowCancelPayload) (model.Workflow, error)
+}
+
+// Quotas limits what each client can submit to the requester and run at once.
+type Quotas interface {
+	// IsAdmin returns whether the client can change quotas.
+	IsAdmin(clientID string) bool
+	// GetQuota returns the quota of the client, which is the default quota if it doesn't have its own, and how much
+	// of it the client is using.
+	GetQuota(ctx context.Context, clientID string) (model.ClientQuota, model.ClientUsage, error)
+	// GetQuotas returns the quotas of the clients that have their own.
+	GetQuotas(ctx context.Context) ([]model.ClientQuota, error)
+	// SetQuota sets the quota of a client, replacing any it had.
+	SetQuota(context.Context, model.QuotaSetPayload) (model.ClientQuota, error)
+	// DeleteQuota deletes the quota of a client, after which the default quota applies to it.
+	DeleteQuota(context.Context, model.QuotaDeletePayload) error
+}
+
+// NodeAccess allows or denies scheduling executions on compute nodes, from the rules the requester was started with
+// and the rules that admins set at runtime.
+type NodeAccess interface {
+	// IsAdmin returns whether the client can change the node access rules.
+	IsAdmin(clientID string) bool
+	// GetNodeAccessRules returns the rules of all the nodes that have one, where runtime rules replace the rules the
+	// requester was started with.
+	GetNodeAccessRules(ctx context.Context) ([]model.NodeAccessRule, error)
+	// SetNodeAccessRule sets the rule of a node, replacing any it had.
+	SetNodeAccessRule(context.Context, model.NodeAccessSetPayload) (model.NodeAccessRule, error)
+	// DeleteNodeAccessRule deletes the runtime rule of a node.
+	DeleteNodeAccessRule(context.Context, model.NodeAccessDeletePayload) error
+}
+
+// Templates hosts the named job templates that clients submit jobs from, giving values to their parameters.
+type Templates interface {
+	// SetTemplate creates a template, or replaces the template with the same name.
+	SetTemplate(context.Context, model.TemplateSetPayload) (model.JobTemplate, error)
+	// GetTemplate gets the template with the name.
+	GetTemplate(ctx context.Context, name string) (model.JobTemplate, error)
+	// GetTemplates gets all the templates.
+	GetTemplates(ctx context.Context) ([]model.JobTemplate, error)
+	// DeleteTemplate deletes a template, leaving the jobs that were submitted from it.
+	DeleteTemplate(context.Context, model.TemplateDeletePayload) error
+	// ApplyTemplate returns the payload of a job that refers to a template with the spec rendered from the template,
+	// overridden by the fields that are set in the spec of the payload.
+	ApplyTemplate(context.Context, model.JobCreatePayload) (model.JobCreatePayload, error)
+}
+
+// Placements simulates where jobs would be placed, for capacity planning and debugging.
+type Placements interface {
+	// SimulatePlacement discovers, ranks and selects the nodes for the job like starting it would, without running
+	// it, and returns which nodes would be asked to bid on it and why.
+	SimulatePlacement(context.Context, model.Job) (model.PlacementPlan, error)
+}
+
 // Scheduler distributes jobs to the compute nodes and tracks the executions.
 type Scheduler interface {
 	StartJob(context.Context, StartJobRequest) error
 	CancelJob(context.Context, CancelJobRequest) (CancelJobResult, error)
 }
 
+// ExecutionMonitor checks on the executions of jobs that are in progress.
+type ExecutionMonitor interface {
+	// CheckExecutions fails the executions of the job that are running on compute nodes that have left the network,
+	// and reschedules them on other nodes. It may also duplicate executions that are running far slower than the
+	// others of the job on other nodes.
+	CheckExecutions(context.Context, model.JobWithInfo)
+}
+
 type Queue interface {
 	Scheduler
+	QueueInfo
 
 	EnqueueJob(context.Context, model.Job) error
 }
 
+// QueueInfo tells where the jobs that wait to start are in the queue, and why they wait.
+type QueueInfo interface {
+	// GetQueuedJobs returns the jobs that wait in the queue, with the job that starts next first.
+	GetQueuedJobs(context.Context) ([]model.QueuedJob, error)
+}
+
 // NodeDiscoverer discovers nodes in the network that are suitable to execute a job.
 type NodeDiscoverer interface {
 	FindNodes(ctx context.Context, job model.Job) ([]model.NodeInfo, error)
@@ -39,6 +129,14 @@ type NodeRanker interface {
 	RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]NodeRank, error)
 }
 
+// NodeRankExplainer is a NodeRanker that can tell the rank that each of the rankers it is made of gave the nodes.
+type NodeRankExplainer interface {
+	NodeRanker
+	// ExplainRanks ranks the nodes like RankNodes does, and also returns the rank that each ranker gave each node, by
+	// node ID and then by the name of the ranker.
+	ExplainRanks(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]NodeRank, map[string]map[string]int, error)
+}
+
 // NodeRank represents a node and its rank. The higher the rank, the more preferable a node is to execute the job.
 // A negative rank means the node is not suitable to execute the job.
 type NodeRank struct {
@@ -55,6 +153,9 @@ type CancelJobRequest struct {
 	JobID         string
 	Reason        string
 	UserTriggered bool
+	// TimedOut is whether the job is stopped because it didn't complete in
+	// time, which marks it as timed out rather than failed
+	TimedOut bool
 }
 
 type CancelJobResult struct{}
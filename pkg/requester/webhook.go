@@ -0,0 +1,255 @@
+package requester
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// WebhookSignatureHeader holds the base64 encoded signature of the body of
+	// a webhook notification, by the libp2p key of the requester node.
+	WebhookSignatureHeader = "X-Bacalhau-Signature"
+	// WebhookPublicKeyHeader holds the base64 encoded libp2p public key of the
+	// requester node, which its node ID is derived from.
+	WebhookPublicKeyHeader = "X-Bacalhau-Public-Key"
+
+	webhookTimeout = 30 * time.Second
+	webhookRetries = 3
+)
+
+// nonPublicNetworks are the networks that webhooks aren't sent to on top of
+// loopback, private, link-local and multicast addresses, unless allowed.
+var nonPublicNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("192.0.0.0/24"),
+	mustParseCIDR("198.18.0.0/15"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// WebhookPayload is POSTed as JSON to the webhook of a job when it completes.
+type WebhookPayload struct {
+	JobID       string          `json:"JobID"`
+	ClientID    string          `json:"ClientID"`
+	State       string          `json:"State"`
+	Annotations []string        `json:"Annotations,omitempty"`
+	Results     []WebhookResult `json:"Results"`
+	CompletedAt time.Time       `json:"CompletedAt"`
+	// RequesterNodeID is the node that sent the notification, whose key it is
+	// signed with
+	RequesterNodeID string `json:"RequesterNodeID"`
+}
+
+// WebhookResult is the result published by an execution of the job.
+type WebhookResult struct {
+	NodeID      string            `json:"NodeID"`
+	ExecutionID string            `json:"ExecutionID"`
+	Data        model.StorageSpec `json:"Data"`
+}
+
+// webhookNotifier notifies the webhooks of jobs when they complete, signing
+// the notifications with the key of the requester node so that receivers can
+// check which node sent them. Webhooks are only sent to public addresses, or
+// to the networks that are allowed, so that jobs can't use the requester node
+// to reach services on its own network.
+type webhookNotifier struct {
+	key    crypto.PrivKey
+	client *retryablehttp.Client
+}
+
+// hostKey returns the private key of the host that notifications are signed
+// with, if there is a host.
+func hostKey(h host.Host) crypto.PrivKey {
+	if h == nil {
+		return nil
+	}
+	return h.Peerstore().PrivKey(h.ID())
+}
+
+func newWebhookNotifier(key crypto.PrivKey, allowedNetworks []*net.IPNet) *webhookNotifier {
+	dialer := &net.Dialer{
+		Timeout:   webhookTimeout,
+		KeepAlive: webhookTimeout,
+		Control:   webhookDialControl(allowedNetworks),
+	}
+	client := retryablehttp.NewClient()
+	client.RetryMax = webhookRetries
+	client.HTTPClient.Timeout = webhookTimeout
+	// connections aren't made through proxies, as the address that the proxy
+	// connects to can't be checked
+	client.HTTPClient.Transport = &http.Transport{
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	client.Logger = nil
+	return &webhookNotifier{key: key, client: client}
+}
+
+// webhookDialControl refuses connections to addresses that aren't public,
+// unless they are in the allowed networks. Addresses are checked as they are
+// dialed, so that hostnames that resolve to other addresses and redirects are
+// checked too.
+func webhookDialControl(allowedNetworks []*net.IPNet) func(string, string, syscall.RawConn) error {
+	return func(_, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		ip := net.ParseIP(host)
+		if ip == nil {
+			return fmt.Errorf("webhook address %s is not an IP address", host)
+		}
+		for _, network := range allowedNetworks {
+			if network.Contains(ip) {
+				return nil
+			}
+		}
+		if !isPublicIP(ip) {
+			return fmt.Errorf("webhook address %s is not public", ip)
+		}
+		return nil
+	}
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// notify POSTs the results of the completed job to its webhook, if it has one.
+// Notifications aren't sent if the requester node has no key to sign them
+// with.
+func (n *webhookNotifier) notify(ctx context.Context, job model.Job, jobState model.JobState) {
+	if job.Spec.Webhook == "" {
+		return
+	}
+	if n.key == nil {
+		log.Ctx(ctx).Warn().Str("job", job.Metadata.ID).Msg("Not notifying the webhook of the job as the node has no key to sign it with")
+		return
+	}
+	if err := n.send(ctx, job.Spec.Webhook, newWebhookPayload(job, jobState)); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("job", job.Metadata.ID).Msg("Failed to notify the webhook of the job")
+	}
+}
+
+func (n *webhookNotifier) send(ctx context.Context, url string, payload WebhookPayload) error {
+	publicKey, err := crypto.MarshalPublicKey(n.key.GetPublic())
+	if err != nil {
+		return err
+	}
+	nodeID, err := peer.IDFromPrivateKey(n.key)
+	if err != nil {
+		return err
+	}
+	payload.RequesterNodeID = nodeID.String()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	signature, err := n.key.Sign(body)
+	if err != nil {
+		return fmt.Errorf("failed to sign webhook notification: %w", err)
+	}
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, base64.StdEncoding.EncodeToString(signature))
+	req.Header.Set(WebhookPublicKeyHeader, base64.StdEncoding.EncodeToString(publicKey))
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "webhook response", res.Body)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", res.Status)
+	}
+	return nil
+}
+
+func newWebhookPayload(job model.Job, jobState model.JobState) WebhookPayload {
+	payload := WebhookPayload{
+		JobID:       job.Metadata.ID,
+		ClientID:    job.Metadata.ClientID,
+		State:       jobState.State.String(),
+		Annotations: job.Spec.Annotations,
+		Results:     []WebhookResult{},
+		CompletedAt: jobState.UpdateTime,
+	}
+	for _, execution := range jobState.Executions {
+		if execution.State != model.ExecutionStateCompleted {
+			continue
+		}
+		payload.Results = append(payload.Results, WebhookResult{
+			NodeID:      execution.NodeID,
+			ExecutionID: execution.ComputeReference,
+			Data:        execution.PublishedResult,
+		})
+	}
+	return payload
+}
+
+// VerifyWebhookSignature returns an error unless the body of a webhook
+// notification is signed by the requester node with the ID, given the values
+// of the signature and public key headers, for receivers to check who sent it.
+func VerifyWebhookSignature(body []byte, signature, publicKey, nodeID string) error {
+	publicKeyBytes, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to decode the public key of the webhook notification: %w", err)
+	}
+	key, err := crypto.UnmarshalPublicKey(publicKeyBytes)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal the public key of the webhook notification: %w", err)
+	}
+	keyID, err := peer.IDFromPublicKey(key)
+	if err != nil {
+		return err
+	}
+	if keyID.String() != nodeID {
+		return fmt.Errorf("the webhook notification is signed by node %s rather than %s", keyID, nodeID)
+	}
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("failed to decode the signature of the webhook notification: %w", err)
+	}
+	ok, err := key.Verify(body, signatureBytes)
+	if err != nil || !ok {
+		return fmt.Errorf("the signature of the webhook notification is invalid")
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+var loopback = []*net.IPNet{mustParseCIDR("127.0.0.0/8")}
+
+func newTestKey(t *testing.T) (crypto.PrivKey, string) {
+	key, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	require.NoError(t, err)
+	id, err := peer.IDFromPrivateKey(key)
+	require.NoError(t, err)
+	return key, id.String()
+}
+
+func TestWebhookNotifierSignsResults(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received <- r
+		bodies <- body
+	}))
+	t.Cleanup(server.Close)
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: "job-id", ClientID: "client-id"},
+		Spec:     model.Spec{Webhook: server.URL, Annotations: []string{"nightly"}},
+	}
+	jobState := model.JobState{
+		State: model.JobStateCompleted,
+		Executions: []model.ExecutionState{
+			{NodeID: "node-1", ComputeReference: "e-1", State: model.ExecutionStateCompleted,
+				PublishedResult: model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "cid-1"}},
+			{NodeID: "node-2", ComputeReference: "e-2", State: model.ExecutionStateFailed},
+		},
+	}
+	key, nodeID := newTestKey(t)
+	newWebhookNotifier(key, loopback).notify(context.Background(), job, jobState)
+
+	req, body := <-received, <-bodies
+	require.Equal(t, http.MethodPost, req.Method)
+	signature, publicKey := req.Header.Get(WebhookSignatureHeader), req.Header.Get(WebhookPublicKeyHeader)
+	require.NoError(t, VerifyWebhookSignature(body, signature, publicKey, nodeID))
+
+	_, otherNodeID := newTestKey(t)
+	require.Error(t, VerifyWebhookSignature(body, signature, publicKey, otherNodeID))
+	require.Error(t, VerifyWebhookSignature(append(body, ' '), signature, publicKey, nodeID))
+
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(body, &payload))
+	require.Equal(t, "job-id", payload.JobID)
+	require.Equal(t, "client-id", payload.ClientID)
+	require.Equal(t, nodeID, payload.RequesterNodeID)
+	require.Equal(t, model.JobStateCompleted.String(), payload.State)
+	require.Equal(t, []string{"nightly"}, payload.Annotations)
+	require.Len(t, payload.Results, 1, "only completed executions have results")
+	require.Equal(t, "node-1", payload.Results[0].NodeID)
+	require.Equal(t, "cid-1", payload.Results[0].Data.CID)
+}
+
+func TestWebhookNotifierNeedsKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("notification sent without a key to sign it with")
+	}))
+	t.Cleanup(server.Close)
+
+	job := model.Job{Spec: model.Spec{Webhook: server.URL}}
+	newWebhookNotifier(nil, loopback).notify(context.Background(), job, model.JobState{})
+}
+
+func TestWebhookNotifierOnlySendsToPublicAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("notification sent to a loopback address")
+	}))
+	t.Cleanup(server.Close)
+
+	key, _ := newTestKey(t)
+	notifier := newWebhookNotifier(key, nil)
+	notifier.client.RetryMax = 0
+	err := notifier.send(context.Background(), server.URL, WebhookPayload{})
+	require.ErrorContains(t, err, "is not public")
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for ip, public := range map[string]bool{
+		"8.8.8.8":          true,
+		"2001:4860::8888":  true,
+		"127.0.0.1":        false,
+		"::1":              false,
+		"10.1.2.3":         false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"fd00::1":          false,
+		"fe80::1":          false,
+		"::ffff:127.0.0.1": false,
+	} {
+		require.Equal(t, public, isPublicIP(net.ParseIP(ip)), ip)
+	}
+}
@@ -0,0 +1,375 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultWorkflowInterval is how often the requester checks on the jobs of
+// workflows to submit the stages that depend on them.
+const DefaultWorkflowInterval = 5 * time.Second
+
+type WorkflowRunnerParams struct {
+	Endpoint Endpoint
+	JobStore jobstore.Store
+	Store    jobstore.WorkflowStore
+	Interval time.Duration
+}
+
+// WorkflowRunner submits the job of each stage of a workflow once the stages
+// it depends on have completed, mounting the results they published. Stages
+// that depend on a stage that failed are skipped, and the workflow fails once
+// the stages that don't depend on it have finished.
+type WorkflowRunner struct {
+	endpoint Endpoint
+	jobStore jobstore.Store
+	store    jobstore.WorkflowStore
+	interval time.Duration
+	// serializes changes to workflows between the API and the background task
+	mu sync.Mutex
+
+	stopChannel chan struct{}
+	stopOnce    sync.Once
+}
+
+func NewWorkflowRunner(params WorkflowRunnerParams) *WorkflowRunner {
+	r := &WorkflowRunner{
+		endpoint:    params.Endpoint,
+		jobStore:    params.JobStore,
+		store:       params.Store,
+		interval:    params.Interval,
+		stopChannel: make(chan struct{}),
+	}
+	if r.interval == 0 {
+		r.interval = DefaultWorkflowInterval
+	}
+
+	go r.workflowBackgroundTask()
+	return r
+}
+
+func (r *WorkflowRunner) workflowBackgroundTask() {
+	ctx := context.Background()
+	ticker := time.NewTicker(r.interval)
+	for {
+		select {
+		case <-ticker.C:
+			r.advanceAll(ctx)
+		case <-r.stopChannel:
+			log.Ctx(ctx).Debug().Msg("stopped workflow task")
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// advanceAll moves on the workflows that are in progress.
+func (r *WorkflowRunner) advanceAll(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	workflows, err := r.store.GetWorkflows(ctx, "")
+	if err != nil {
+		log.Ctx(ctx).Err(err).Msg("failed to get workflows")
+		return
+	}
+	for i := range workflows {
+		workflow := &workflows[i]
+		if workflow.State.IsTerminal() || !r.advance(ctx, workflow) {
+			continue
+		}
+		if err = r.store.UpdateWorkflow(ctx, *workflow); err != nil {
+			log.Ctx(ctx).Err(err).Msgf("failed to update workflow %s", workflow.ID)
+		}
+	}
+}
+
+// advance records the stages whose jobs have finished, submits the stages that
+// are ready and skips the ones that never will be, returning whether anything
+// changed. Stages are in dependency order, so a single pass sees the changes
+// to the stages that each stage depends on.
+func (r *WorkflowRunner) advance(ctx context.Context, workflow *model.Workflow) bool {
+	changed := false
+	stages := make(map[string]*model.WorkflowStage, len(workflow.Stages))
+	finished, completed := 0, 0
+	for i := range workflow.Stages {
+		stage := &workflow.Stages[i]
+		switch stage.State {
+		case model.WorkflowStageRunning:
+			changed = r.checkJob(ctx, stage) || changed
+		case model.WorkflowStagePending:
+			changed = r.startIfReady(ctx, workflow, stage, stages) || changed
+		}
+		if stage.State.IsTerminal() {
+			finished++
+		}
+		if stage.State == model.WorkflowStageCompleted {
+			completed++
+		}
+		stages[stage.Name] = stage
+	}
+
+	if finished == len(workflow.Stages) {
+		workflow.State = model.WorkflowStateFailed
+		if completed == len(workflow.Stages) {
+			workflow.State = model.WorkflowStateCompleted
+		}
+		log.Ctx(ctx).Info().Msgf("workflow %s is %s", workflow.ID, workflow.State)
+		changed = true
+	}
+	if changed {
+		workflow.UpdatedAt = time.Now()
+	}
+	return changed
+}
+
+// checkJob records whether the job of a running stage completed or failed,
+// once it has finished.
+func (r *WorkflowRunner) checkJob(ctx context.Context, stage *model.WorkflowStage) bool {
+	jobState, err := r.jobStore.GetJobState(ctx, stage.JobID)
+	if err != nil {
+		log.Ctx(ctx).Err(err).Msgf("failed to get state of job %s for stage %s", stage.JobID, stage.Name)
+		return false
+	}
+	if !jobState.State.IsTerminal() {
+		return false
+	}
+
+	if jobState.State != model.JobStateCompleted {
+		stage.State = model.WorkflowStageFailed
+		stage.Error = r.jobError(ctx, jobState)
+		return true
+	}
+	stage.State = model.WorkflowStageCompleted
+	for _, execution := range jobState.Executions {
+		if execution.State == model.ExecutionStateCompleted {
+			stage.Results = append(stage.Results, execution.PublishedResult)
+		}
+	}
+	return true
+}
+
+// jobError describes why the job didn't complete, using the comment of the
+// latest change to its state if there is one.
+func (r *WorkflowRunner) jobError(ctx context.Context, jobState model.JobState) string {
+	reason := fmt.Sprintf("job %s ended in state %s", jobState.JobID, jobState.State)
+	history, err := r.jobStore.GetJobHistory(ctx, jobState.JobID)
+	if err != nil {
+		return reason
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].JobState != nil && history[i].Comment != "" {
+			return reason + ": " + history[i].Comment
+		}
+	}
+	return reason
+}
+
+// startIfReady submits the job of a pending stage once the stages it depends
+// on have completed, or skips it if one of them didn't.
+func (r *WorkflowRunner) startIfReady(
+	ctx context.Context, workflow *model.Workflow, stage *model.WorkflowStage, stages map[string]*model.WorkflowStage,
+) bool {
+	for _, name := range stage.Dependencies() {
+		dependency := stages[name]
+		switch {
+		case dependency.State == model.WorkflowStageCompleted:
+			continue
+		case dependency.State.IsTerminal():
+			stage.State = model.WorkflowStageSkipped
+			stage.Error = fmt.Sprintf("stage %s did not complete", name)
+			return true
+		default:
+			return false
+		}
+	}
+
+	spec := copySpec(stage.Spec)
+	for _, input := range stage.Inputs {
+		spec.Inputs = append(spec.Inputs, stageInputs(stages[input.Stage].Results, input.Path)...)
+	}
+	j, err := r.endpoint.SubmitJob(ctx, model.JobCreatePayload{
+		ClientID:   workflow.ClientID,
+		APIVersion: workflow.APIVersion,
+		Spec:       spec,
+	})
+	if err != nil {
+		log.Ctx(ctx).Err(err).Msgf("failed to submit stage %s of workflow %s", stage.Name, workflow.ID)
+		stage.State = model.WorkflowStageFailed
+		stage.Error = err.Error()
+		return true
+	}
+	log.Ctx(ctx).Info().Msgf("submitted job %s for stage %s of workflow %s", j.Metadata.ID, stage.Name, workflow.ID)
+	stage.State = model.WorkflowStageRunning
+	stage.JobID = j.Metadata.ID
+	return true
+}
+
+// stageInputs mounts the results of a stage at the path, or in numbered
+// directories under it if there is more than one.
+func stageInputs(results []model.StorageSpec, mountPath string) []model.StorageSpec {
+	inputs := make([]model.StorageSpec, 0, len(results))
+	for i, result := range results {
+		result.Path = mountPath
+		if len(results) > 1 {
+			result.Path = path.Join(mountPath, strconv.Itoa(i))
+		}
+		inputs = append(inputs, result)
+	}
+	return inputs
+}
+
+func (r *WorkflowRunner) GetWorkflow(ctx context.Context, id string) (model.Workflow, error) {
+	return r.store.GetWorkflow(ctx, id)
+}
+
+func (r *WorkflowRunner) GetWorkflows(ctx context.Context, clientID string) ([]model.Workflow, error) {
+	return r.store.GetWorkflows(ctx, clientID)
+}
+
+func (r *WorkflowRunner) SubmitWorkflow(ctx context.Context, payload model.WorkflowSubmitPayload) (model.Workflow, error) {
+	specs, err := sortStages(payload.Stages)
+	if err != nil {
+		return model.Workflow{}, err
+	}
+	now := time.Now()
+	workflow := model.Workflow{
+		ID:         uuid.NewString(),
+		ClientID:   payload.ClientID,
+		APIVersion: payload.APIVersion,
+		State:      model.WorkflowStateInProgress,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	for _, spec := range specs {
+		workflow.Stages = append(workflow.Stages, model.WorkflowStage{
+			WorkflowStageSpec: spec,
+			State:             model.WorkflowStagePending,
+		})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err = r.store.CreateWorkflow(ctx, workflow); err != nil {
+		return workflow, err
+	}
+	// submit the stages that don't depend on any others straight away
+	r.advance(ctx, &workflow)
+	return workflow, r.store.UpdateWorkflow(ctx, workflow)
+}
+
+func (r *WorkflowRunner) CancelWorkflow(ctx context.Context, payload model.WorkflowCancelPayload) (model.Workflow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	workflow, err := r.store.GetWorkflow(ctx, payload.WorkflowID)
+	if err != nil {
+		return workflow, err
+	}
+	if workflow.State.IsTerminal() {
+		return workflow, fmt.Errorf("workflow %s is already %s", workflow.ID, workflow.State)
+	}
+
+	reason := payload.Reason
+	if reason == "" {
+		reason = "workflow cancelled by the user"
+	}
+	for i := range workflow.Stages {
+		stage := &workflow.Stages[i]
+		if stage.State.IsTerminal() {
+			continue
+		}
+		if stage.State == model.WorkflowStageRunning {
+			_, err = r.endpoint.CancelJob(ctx, CancelJobRequest{
+				JobID:         stage.JobID,
+				Reason:        reason,
+				UserTriggered: true,
+			})
+			if err != nil {
+				log.Ctx(ctx).Err(err).Msgf("failed to cancel job %s of stage %s", stage.JobID, stage.Name)
+			}
+		}
+		stage.State = model.WorkflowStageCancelled
+		stage.Error = reason
+	}
+	workflow.State = model.WorkflowStateCancelled
+	workflow.UpdatedAt = time.Now()
+	return workflow, r.store.UpdateWorkflow(ctx, workflow)
+}
+
+func (r *WorkflowRunner) Stop() {
+	r.stopOnce.Do(func() {
+		r.stopChannel <- struct{}{}
+	})
+}
+
+// sortStages checks that the stages form a graph without cycles and returns
+// them with every stage after the stages it depends on, otherwise keeping the
+// order they were submitted in.
+func sortStages(specs []model.WorkflowStageSpec) ([]model.WorkflowStageSpec, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("workflow must have at least one stage")
+	}
+	names := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		if spec.Name == "" {
+			return nil, fmt.Errorf("workflow stages must have a name")
+		}
+		if names[spec.Name] {
+			return nil, fmt.Errorf("workflow has more than one stage named %s", spec.Name)
+		}
+		names[spec.Name] = true
+	}
+	for _, spec := range specs {
+		for _, input := range spec.Inputs {
+			if input.Path == "" {
+				return nil, fmt.Errorf("stage %s must say where to mount the outputs of stage %s", spec.Name, input.Stage)
+			}
+		}
+		for _, name := range spec.Dependencies() {
+			if !names[name] {
+				return nil, fmt.Errorf("stage %s depends on stage %s, which is not in the workflow", spec.Name, name)
+			}
+			if name == spec.Name {
+				return nil, fmt.Errorf("stage %s depends on itself", spec.Name)
+			}
+		}
+	}
+
+	sorted := make([]model.WorkflowStageSpec, 0, len(specs))
+	added := make(map[string]bool, len(specs))
+	for len(sorted) < len(specs) {
+		progress := false
+		for _, spec := range specs {
+			if added[spec.Name] || !allAdded(spec.Dependencies(), added) {
+				continue
+			}
+			sorted = append(sorted, spec)
+			added[spec.Name] = true
+			progress = true
+		}
+		if !progress {
+			return nil, fmt.Errorf("workflow stages have a cycle of dependencies")
+		}
+	}
+	return sorted, nil
+}
+
+func allAdded(names []string, added map[string]bool) bool {
+	for _, name := range names {
+		if !added[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// compile-time check that WorkflowRunner implements the expected interfaces
+var _ Workflows = (*WorkflowRunner)(nil)
@@ -0,0 +1,175 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// finishJob ends the job in the state, with an execution that published each
+// of the results.
+func finishJob(t *testing.T, store jobstore.Store, jobID string, state model.JobStateType, comment string, cids ...string) {
+	ctx := context.Background()
+	for _, cid := range cids {
+		require.NoError(t, store.CreateExecution(ctx, model.ExecutionState{
+			JobID:            jobID,
+			NodeID:           "node-" + cid,
+			ComputeReference: "execution-" + cid,
+			State:            model.ExecutionStateCompleted,
+			PublishedResult:  model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: cid},
+		}))
+	}
+	require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    jobID,
+		NewState: state,
+		Comment:  comment,
+	}))
+}
+
+// stageJob returns the job that was submitted for the stage.
+func stageJob(t *testing.T, r *WorkflowRunner, workflowID, name string) model.Job {
+	ctx := context.Background()
+	workflow, err := r.GetWorkflow(ctx, workflowID)
+	require.NoError(t, err)
+	for _, stage := range workflow.Stages {
+		if stage.Name == name {
+			j, err := r.jobStore.GetJob(ctx, stage.JobID)
+			require.NoError(t, err)
+			return j
+		}
+	}
+	require.Failf(t, "missing stage", "workflow has no stage %s", name)
+	return model.Job{}
+}
+
+func stageStates(workflow model.Workflow) map[string]model.WorkflowStageState {
+	states := make(map[string]model.WorkflowStageState)
+	for _, stage := range workflow.Stages {
+		states[stage.Name] = stage.State
+	}
+	return states
+}
+
+func TestWorkflowRunsStagesInDependencyOrder(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+	endpoint := &fakeEndpoint{store: store}
+	r := &WorkflowRunner{endpoint: endpoint, jobStore: store, store: store}
+
+	workflow, err := r.SubmitWorkflow(ctx, model.WorkflowSubmitPayload{
+		ClientID: "client",
+		Stages: []model.WorkflowStageSpec{
+			{Name: "report", Inputs: []model.WorkflowInput{{Stage: "transform", Path: "/inputs/transformed"}}},
+			{Name: "transform", Inputs: []model.WorkflowInput{{Stage: "extract", Path: "/inputs/extracted"}}},
+			{Name: "extract"},
+			{Name: "audit", DependsOn: []string{"extract"}},
+			{Name: "cleanup", DependsOn: []string{"audit"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, model.WorkflowStateInProgress, workflow.State)
+	require.Equal(t, "extract", workflow.Stages[0].Name, "stages are sorted after the stages they depend on")
+	require.Equal(t, model.WorkflowStageRunning, workflow.Stages[0].State)
+	require.Equal(t, "job-0001", workflow.Stages[0].JobID)
+	require.Len(t, endpoint.submitted, 1, "only the stages without dependencies are submitted")
+
+	finishJob(t, store, "job-0001", model.JobStateCompleted, "", "QmExtract")
+	r.advanceAll(ctx)
+	require.Len(t, endpoint.submitted, 3)
+	transform := stageJob(t, r, workflow.ID, "transform").Spec
+	require.Equal(t, []model.StorageSpec{{StorageSource: model.StorageSourceIPFS, CID: "QmExtract", Path: "/inputs/extracted"}},
+		transform.Inputs, "the outputs of the stage it depends on are mounted")
+
+	finishJob(t, store, stageJob(t, r, workflow.ID, "transform").Metadata.ID, model.JobStateCompleted, "", "QmPart0", "QmPart1")
+	finishJob(t, store, stageJob(t, r, workflow.ID, "audit").Metadata.ID, model.JobStateError, "out of memory")
+	r.advanceAll(ctx)
+	workflow, err = r.GetWorkflow(ctx, workflow.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.WorkflowStateInProgress, workflow.State, "stages that don't depend on the failure carry on")
+	require.Equal(t, map[string]model.WorkflowStageState{
+		"extract":   model.WorkflowStageCompleted,
+		"transform": model.WorkflowStageCompleted,
+		"audit":     model.WorkflowStageFailed,
+		"cleanup":   model.WorkflowStageSkipped,
+		"report":    model.WorkflowStageRunning,
+	}, stageStates(workflow))
+	for _, stage := range workflow.Stages {
+		if stage.Name == "audit" {
+			require.Contains(t, stage.Error, "out of memory")
+		}
+	}
+	report := stageJob(t, r, workflow.ID, "report")
+	require.Len(t, report.Spec.Inputs, 2, "each result of a stage is mounted")
+	require.Equal(t, "/inputs/transformed/0", report.Spec.Inputs[0].Path)
+	require.Equal(t, "/inputs/transformed/1", report.Spec.Inputs[1].Path)
+
+	finishJob(t, store, report.Metadata.ID, model.JobStateCompleted, "")
+	r.advanceAll(ctx)
+	workflow, err = r.GetWorkflow(ctx, workflow.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.WorkflowStateFailed, workflow.State)
+	require.Len(t, endpoint.submitted, 4)
+}
+
+func TestCancelWorkflow(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+	endpoint := &fakeEndpoint{store: store}
+	r := &WorkflowRunner{endpoint: endpoint, jobStore: store, store: store}
+
+	workflow, err := r.SubmitWorkflow(ctx, model.WorkflowSubmitPayload{
+		Stages: []model.WorkflowStageSpec{{Name: "first"}, {Name: "second", DependsOn: []string{"first"}}},
+	})
+	require.NoError(t, err)
+
+	workflow, err = r.CancelWorkflow(ctx, model.WorkflowCancelPayload{WorkflowID: workflow.ID})
+	require.NoError(t, err)
+	require.Equal(t, model.WorkflowStateCancelled, workflow.State)
+	require.Equal(t, []string{"job-0001"}, endpoint.cancelled)
+	require.Equal(t, map[string]model.WorkflowStageState{
+		"first":  model.WorkflowStageCancelled,
+		"second": model.WorkflowStageCancelled,
+	}, stageStates(workflow))
+
+	_, err = r.CancelWorkflow(ctx, model.WorkflowCancelPayload{WorkflowID: workflow.ID})
+	require.Error(t, err, "finished workflows can't be cancelled")
+}
+
+func TestSortStages(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		stages []model.WorkflowStageSpec
+		err    string
+	}{
+		{name: "empty", err: "at least one stage"},
+		{name: "unnamed", stages: []model.WorkflowStageSpec{{}}, err: "must have a name"},
+		{name: "duplicate", stages: []model.WorkflowStageSpec{{Name: "a"}, {Name: "a"}}, err: "more than one stage named a"},
+		{name: "missing", stages: []model.WorkflowStageSpec{{Name: "a", DependsOn: []string{"b"}}}, err: "not in the workflow"},
+		{name: "self", stages: []model.WorkflowStageSpec{{Name: "a", DependsOn: []string{"a"}}}, err: "depends on itself"},
+		{
+			name:   "no path",
+			stages: []model.WorkflowStageSpec{{Name: "a"}, {Name: "b", Inputs: []model.WorkflowInput{{Stage: "a"}}}},
+			err:    "where to mount",
+		},
+		{
+			name: "cycle",
+			stages: []model.WorkflowStageSpec{
+				{Name: "a", DependsOn: []string{"c"}},
+				{Name: "b", DependsOn: []string{"a"}},
+				{Name: "c", DependsOn: []string{"b"}},
+			},
+			err: "cycle",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := sortStages(tc.stages)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
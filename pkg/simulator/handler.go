@@ -148,6 +148,10 @@ func (e *RequestHandler) OnPublishComplete(ctx context.Context, result compute.P
 	e.requesterProxy.OnPublishComplete(ctx, result)
 }
 
+func (e *RequestHandler) OnPublishPending(ctx context.Context, result compute.PublishPendingResult) {
+	e.requesterProxy.OnPublishPending(ctx, result)
+}
+
 func (e *RequestHandler) OnCancelComplete(ctx context.Context, result compute.CancelResult) {
 	e.requesterProxy.OnCancelComplete(ctx, result)
 }
@@ -0,0 +1,326 @@
+package azureblob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultDownloadConcurrency is how many blobs are downloaded at once if no
+// other number is configured.
+const DefaultDownloadConcurrency = 8
+
+// StorageOptions configures how the compute node connects to Azure Blob
+// Storage for inputs whose URL has no SAS token.
+type StorageOptions struct {
+	// ManagedIdentityClientID selects a user assigned managed identity of the
+	// compute node. Credentials are otherwise found as by the Azure SDKs, from
+	// the environment, the managed identity of the node or the Azure CLI.
+	ManagedIdentityClientID string
+	// DownloadConcurrency is how many blobs of an input are downloaded at once
+	DownloadConcurrency int
+}
+
+// StorageProvider downloads the blobs under a prefix of an Azure Blob Storage
+// container to a local directory in preparation for a job to run, and removes
+// them once complete.
+//
+// Inputs are given as the URL of the container and prefix, e.g.
+// https://account.blob.core.windows.net/container/prefix. A SAS token can be
+// given as the query of the URL, otherwise the blobs are downloaded as the
+// compute node.
+type StorageProvider struct {
+	localDir string
+	options  StorageOptions
+
+	credentialOnce sync.Once
+	credential     azcore.TokenCredential
+	credentialErr  error
+}
+
+func NewStorage(cm *system.CleanupManager, options StorageOptions) (*StorageProvider, error) {
+	dir, err := os.MkdirTemp(config.GetStoragePath(), "bacalhau-azureblob")
+	if err != nil {
+		return nil, err
+	}
+
+	cm.RegisterCallback(func() error {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("unable to remove storage folder: %w", err)
+		}
+		return nil
+	})
+
+	log.Debug().Str("dir", dir).Msg("Azure blob driver created with output dir")
+
+	return newStorage(dir, options), nil
+}
+
+func newStorage(dir string, options StorageOptions) *StorageProvider {
+	if options.DownloadConcurrency <= 0 {
+		options.DownloadConcurrency = DefaultDownloadConcurrency
+	}
+	return &StorageProvider{
+		localDir: dir,
+		options:  options,
+	}
+}
+
+func (sp *StorageProvider) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (sp *StorageProvider) HasStorageLocally(context.Context, model.StorageSpec) (bool, error) {
+	return false, nil
+}
+
+// GetVolumeSize returns the total size of the blobs under the prefix.
+func (sp *StorageProvider) GetVolumeSize(ctx context.Context, storageSpec model.StorageSpec) (uint64, error) {
+	blobURL, err := ParseURL(storageSpec.URL)
+	if err != nil {
+		return 0, err
+	}
+	client, err := sp.newClient(blobURL)
+	if err != nil {
+		return 0, err
+	}
+	blobs, err := listBlobs(ctx, client, blobURL)
+	if err != nil {
+		return 0, err
+	}
+
+	var size uint64
+	for _, blob := range blobs {
+		size += uint64(blob.size)
+	}
+	return size, nil
+}
+
+// PrepareStorage downloads the blobs under the prefix to a local directory,
+// keeping their names relative to the last "/" of the prefix as their paths.
+func (sp *StorageProvider) PrepareStorage(ctx context.Context, storageSpec model.StorageSpec) (storage.StorageVolume, error) {
+	blobURL, err := ParseURL(storageSpec.URL)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	client, err := sp.newClient(blobURL)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	blobs, err := listBlobs(ctx, client, blobURL)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	if len(blobs) == 0 {
+		return storage.StorageVolume{}, fmt.Errorf("no blobs found at %s", blobURL)
+	}
+
+	outputPath, err := os.MkdirTemp(sp.localDir, "*")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(sp.options.DownloadConcurrency)
+	for _, blob := range blobs {
+		name := blob.name
+		filePath, err := util.ObjectPath(outputPath, blobURL.Prefix, name)
+		if err != nil {
+			return storage.StorageVolume{}, err
+		}
+		group.Go(func() error {
+			return downloadBlob(groupCtx, client, blobURL.Container, name, filePath)
+		})
+	}
+	if err = group.Wait(); err != nil {
+		return storage.StorageVolume{}, fmt.Errorf("failed to download from %s: %w", blobURL, err)
+	}
+
+	log.Ctx(ctx).Debug().
+		Stringer("url", blobURL).
+		Int("blobs", len(blobs)).
+		Str("dir", outputPath).
+		Msg("Downloaded blobs")
+
+	return storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: outputPath,
+		Target: storageSpec.Path,
+	}, nil
+}
+
+func (sp *StorageProvider) CleanupStorage(
+	ctx context.Context,
+	_ model.StorageSpec,
+	volume storage.StorageVolume,
+) error {
+	log.Ctx(ctx).Debug().Str("Path", volume.Source).Msg("Cleaning up")
+	return os.RemoveAll(volume.Source)
+}
+
+func (sp *StorageProvider) Upload(context.Context, string) (model.StorageSpec, error) {
+	return model.StorageSpec{}, fmt.Errorf("not implemented")
+}
+
+// HealthCheck is always healthy, as each input is read from a storage account
+// of its own.
+func (sp *StorageProvider) HealthCheck(context.Context) error {
+	return nil
+}
+
+func (sp *StorageProvider) Explode(_ context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+	return []model.StorageSpec{
+		spec,
+	}, nil
+}
+
+// newClient returns a client of the storage account of the URL, which uses its
+// SAS token if it has one, and the credentials of the compute node if not.
+func (sp *StorageProvider) newClient(blobURL URL) (*azblob.Client, error) {
+	if blobURL.SAS != "" {
+		return azblob.NewClientWithNoCredential(blobURL.ServiceURL+"?"+blobURL.SAS, nil)
+	}
+
+	sp.credentialOnce.Do(func() {
+		if sp.options.ManagedIdentityClientID != "" {
+			sp.credential, sp.credentialErr = azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{
+				ID: azidentity.ClientID(sp.options.ManagedIdentityClientID),
+			})
+		} else {
+			sp.credential, sp.credentialErr = azidentity.NewDefaultAzureCredential(nil)
+		}
+	})
+	if sp.credentialErr != nil {
+		return nil, fmt.Errorf("no Azure credentials found on the compute node, and no SAS token given: %w", sp.credentialErr)
+	}
+	return azblob.NewClient(blobURL.ServiceURL, sp.credential, nil)
+}
+
+type blob struct {
+	name string
+	size int64
+}
+
+// listBlobs returns every blob under the prefix, leaving out the empty blobs
+// that some tools create to stand for directories.
+func listBlobs(ctx context.Context, client *azblob.Client, blobURL URL) ([]blob, error) {
+	var blobs []blob
+	pager := client.NewListBlobsFlatPager(blobURL.Container, &container.ListBlobsFlatOptions{
+		Prefix: &blobURL.Prefix,
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list blobs at %s: %w", blobURL, err)
+		}
+		for _, item := range page.Segment.BlobItems {
+			if item.Name == nil || strings.HasSuffix(*item.Name, "/") {
+				continue
+			}
+			b := blob{name: *item.Name}
+			if item.Properties != nil && item.Properties.ContentLength != nil {
+				b.size = *item.Properties.ContentLength
+			}
+			blobs = append(blobs, b)
+		}
+	}
+	return blobs, nil
+}
+
+func downloadBlob(ctx context.Context, client *azblob.Client, containerName, name, filePath string) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), util.OS_USER_RWX); err != nil {
+		return err
+	}
+
+	res, err := client.DownloadStream(ctx, containerName, name, nil)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "blob", res.Body)
+
+	w, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filePath, err)
+	}
+	defer closer.CloseWithLogOnError("file", w)
+
+	if _, err = io.Copy(w, res.Body); err != nil {
+		return fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	return w.Sync()
+}
+
+// URL is the location of the blobs of an input.
+type URL struct {
+	// ServiceURL is the URL of the storage account
+	ServiceURL string
+	Container  string
+	Prefix     string
+	// SAS is the token to access the blobs with, if any
+	SAS string
+}
+
+// String returns the URL without its SAS token, so that it can be logged.
+func (u URL) String() string {
+	return u.ServiceURL + "/" + u.Container + "/" + u.Prefix
+}
+
+// ParseURL returns the storage account, container and prefix of the URL of
+// blobs, e.g. https://account.blob.core.windows.net/container/prefix?<sas>.
+// The URLs of storage emulators such as Azurite, which have the account as
+// the first element of the path, are supported too.
+func ParseURL(rawURL string) (URL, error) {
+	u, err := url.Parse(strings.Trim(rawURL, " '\""))
+	if err != nil {
+		return URL{}, fmt.Errorf("invalid URL: %s", err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return URL{}, fmt.Errorf("azure blob URLs must begin with 'https'. The submitted one began with %s", u.Scheme)
+	}
+	if u.Host == "" {
+		return URL{}, fmt.Errorf("azure blob URL %s has no storage account", u.Redacted())
+	}
+
+	serviceURL := u.Scheme + "://" + u.Host
+	path := strings.TrimPrefix(u.Path, "/")
+	if isEmulator(u.Hostname()) {
+		account, rest, _ := strings.Cut(path, "/")
+		serviceURL += "/" + account
+		path = rest
+	}
+	containerName, prefix, _ := strings.Cut(path, "/")
+	if containerName == "" {
+		return URL{}, fmt.Errorf("azure blob URL %s has no container", u.Redacted())
+	}
+
+	return URL{ServiceURL: serviceURL, Container: containerName, Prefix: prefix, SAS: u.RawQuery}, nil
+}
+
+// isEmulator returns whether the host is that of a storage emulator rather than
+// a storage account, which would have its own domain name.
+func isEmulator(host string) bool {
+	return host == "localhost" || net.ParseIP(host) != nil
+}
+
+// Compile time interface check:
+var _ storage.Storage = (*StorageProvider)(nil)
@@ -0,0 +1,105 @@
+//go:build unit || !integration
+
+package azureblob
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURL(t *testing.T) {
+	blobURL, err := ParseURL("https://account.blob.core.windows.net/container/data/2023/?sv=2021&sig=secret")
+	require.NoError(t, err)
+	require.Equal(t, URL{
+		ServiceURL: "https://account.blob.core.windows.net",
+		Container:  "container",
+		Prefix:     "data/2023/",
+		SAS:        "sv=2021&sig=secret",
+	}, blobURL)
+	require.NotContains(t, blobURL.String(), "secret")
+
+	blobURL, err = ParseURL("http://127.0.0.1:10000/devstoreaccount1/container")
+	require.NoError(t, err)
+	require.Equal(t, URL{ServiceURL: "http://127.0.0.1:10000/devstoreaccount1", Container: "container"}, blobURL)
+
+	_, err = ParseURL("https://account.blob.core.windows.net/")
+	require.Error(t, err)
+	_, err = ParseURL("s3://bucket/prefix")
+	require.Error(t, err)
+}
+
+func TestPrepareStorage(t *testing.T) {
+	blobs := map[string]string{
+		"data/2023/a.csv":     "1,2",
+		"data/2023/sub/b.csv": "3,4",
+		"data/2023/sub/":      "",
+		"data/2024/c.csv":     "5,6",
+	}
+
+	// serve the blobs as a storage emulator would, only to requests with the SAS token
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sig") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Query().Get("comp") == "list" {
+			prefix := r.URL.Query().Get("prefix")
+			var items strings.Builder
+			for name, contents := range blobs {
+				if strings.HasPrefix(name, prefix) {
+					fmt.Fprintf(&items, "<Blob><Name>%s</Name><Properties><Content-Length>%d</Content-Length></Properties></Blob>",
+						name, len(contents))
+				}
+			}
+			w.Header().Set("Content-Type", "application/xml")
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?><EnumerationResults ContainerName="container">`+
+				`<Prefix>%s</Prefix><Blobs>%s</Blobs><NextMarker /></EnumerationResults>`, prefix, items.String())
+			return
+		}
+		contents, ok := blobs[strings.TrimPrefix(r.URL.Path, "/account/container/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(contents)))
+		_, _ = w.Write([]byte(contents))
+	}))
+	t.Cleanup(server.Close)
+
+	sp := newStorage(t.TempDir(), StorageOptions{})
+	spec := model.StorageSpec{
+		StorageSource: model.StorageSourceAzureBlob,
+		URL:           server.URL + "/account/container/data/2023/?sig=secret",
+		Path:          "/inputs",
+	}
+
+	size, err := sp.GetVolumeSize(context.Background(), spec)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), size)
+
+	volume, err := sp.PrepareStorage(context.Background(), spec)
+	require.NoError(t, err)
+	require.Equal(t, "/inputs", volume.Target)
+	for name, contents := range map[string]string{"a.csv": "1,2", "sub/b.csv": "3,4"} {
+		actual, err := os.ReadFile(filepath.Join(volume.Source, name))
+		require.NoError(t, err)
+		require.Equal(t, contents, string(actual))
+	}
+	require.NoFileExists(t, filepath.Join(volume.Source, "c.csv"))
+
+	require.NoError(t, sp.CleanupStorage(context.Background(), spec, volume))
+	require.NoDirExists(t, volume.Source)
+
+	spec.URL = server.URL + "/account/container/missing/?sig=secret"
+	_, err = sp.PrepareStorage(context.Background(), spec)
+	require.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package bidstrategy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/c2h5oh/datasize"
+	"golang.org/x/exp/slices"
+)
+
+// InputPolicy is what the inputs of the jobs that the node runs can be. Each
+// rule is disabled if empty.
+type InputPolicy struct {
+	// AllowedSources are the only storage sources that inputs can be from
+	AllowedSources []model.StorageSourceType
+	// AllowedCIDs are the only CIDs that inputs with a CID can be
+	AllowedCIDs []string
+	// AllowedURLPrefixes are what the URLs of inputs with a URL must start with
+	AllowedURLPrefixes []string
+	// MaxInputSize is the most that the inputs of a job can add up to
+	MaxInputSize uint64
+}
+
+type InputPolicyStrategyParams struct {
+	Policy   InputPolicy
+	Storages storage.StorageProvider
+}
+
+// InputPolicyStrategy only bids on jobs whose inputs the node's input policy
+// allows, so that operators control what their node downloads. The policy is
+// checked before any input is prepared, with the sizes of inputs asked of their
+// storages.
+type InputPolicyStrategy struct {
+	policy   InputPolicy
+	storages storage.StorageProvider
+}
+
+func NewInputPolicyStrategy(params InputPolicyStrategyParams) *InputPolicyStrategy {
+	return &InputPolicyStrategy{
+		policy:   params.Policy,
+		storages: params.Storages,
+	}
+}
+
+func (s *InputPolicyStrategy) ShouldBid(ctx context.Context, request bidstrategy.BidStrategyRequest) (bidstrategy.BidStrategyResponse, error) {
+	inputs := request.Job.Spec.Inputs
+	for _, input := range inputs {
+		if reason := s.checkInput(input); reason != "" {
+			return bidstrategy.BidStrategyResponse{ShouldBid: false, Reason: reason}, nil
+		}
+	}
+
+	if s.policy.MaxInputSize == 0 {
+		return bidstrategy.NewShouldBidResponse(), nil
+	}
+	var size uint64
+	for _, input := range inputs {
+		inputStorage, err := s.storages.Get(ctx, input.StorageSource)
+		if err != nil {
+			return bidstrategy.BidStrategyResponse{}, err
+		}
+		volumeSize, err := inputStorage.GetVolumeSize(ctx, input)
+		if err != nil {
+			return bidstrategy.BidStrategyResponse{
+				ShouldBid: false,
+				Reason:    fmt.Sprintf("unable to check the size of input %s: %s", describeInput(input), err),
+			}, nil
+		}
+		size += volumeSize
+	}
+	if size > s.policy.MaxInputSize {
+		return bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason: fmt.Sprintf("job inputs are %s, more than the node's limit of %s",
+				datasize.ByteSize(size).HR(), datasize.ByteSize(s.policy.MaxInputSize).HR()),
+		}, nil
+	}
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+func (s *InputPolicyStrategy) ShouldBidBasedOnUsage(
+	context.Context, bidstrategy.BidStrategyRequest, model.ResourceUsageData) (bidstrategy.BidStrategyResponse, error) {
+	return bidstrategy.NewShouldBidResponse(), nil
+}
+
+// checkInput returns why the policy doesn't allow the input, or nothing if it does.
+func (s *InputPolicyStrategy) checkInput(input model.StorageSpec) string {
+	if len(s.policy.AllowedSources) > 0 && !slices.Contains(s.policy.AllowedSources, input.StorageSource) {
+		return fmt.Sprintf("inputs from %s aren't allowed on this node", input.StorageSource)
+	}
+	if input.CID != "" && len(s.policy.AllowedCIDs) > 0 && !slices.Contains(s.policy.AllowedCIDs, input.CID) {
+		return fmt.Sprintf("input %s isn't allowed on this node", input.CID)
+	}
+	if input.URL != "" && len(s.policy.AllowedURLPrefixes) > 0 {
+		allowed := false
+		for _, prefix := range s.policy.AllowedURLPrefixes {
+			allowed = allowed || strings.HasPrefix(input.URL, prefix)
+		}
+		if !allowed {
+			return fmt.Sprintf("input %s isn't allowed on this node", input.URL)
+		}
+	}
+	return ""
+}
+
+func describeInput(input model.StorageSpec) string {
+	if input.CID != "" {
+		return input.CID
+	}
+	if input.URL != "" {
+		return input.URL
+	}
+	return input.Path
+}
+
+// compile-time interface check
+var _ bidstrategy.BidStrategy = (*InputPolicyStrategy)(nil)
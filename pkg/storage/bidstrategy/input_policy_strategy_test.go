@@ -0,0 +1,65 @@
+//go:build unit || !integration
+
+package bidstrategy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/noop"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInputPolicyStrategy(t *testing.T) {
+	noopStorage := noop.NewNoopStorage(noop.StorageConfig{
+		ExternalHooks: noop.StorageConfigExternalHooks{
+			GetVolumeSize: func(ctx context.Context, volume model.StorageSpec) (uint64, error) {
+				return 100, nil
+			},
+		},
+	})
+	provider := model.NewNoopProvider[model.StorageSourceType, storage.Storage](noopStorage)
+
+	ipfs := func(cid string) model.StorageSpec {
+		return model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: cid}
+	}
+	url := func(url string) model.StorageSpec {
+		return model.StorageSpec{StorageSource: model.StorageSourceURLDownload, URL: url}
+	}
+
+	testCases := []struct {
+		name   string
+		policy InputPolicy
+		inputs []model.StorageSpec
+		check  func(require.TestingT, bool, ...any)
+	}{
+		{"no policy", InputPolicy{}, []model.StorageSpec{ipfs("Qm1"), url("https://example.com")}, require.True},
+		{"allowed source", InputPolicy{AllowedSources: []model.StorageSourceType{model.StorageSourceIPFS}},
+			[]model.StorageSpec{ipfs("Qm1")}, require.True},
+		{"disallowed source", InputPolicy{AllowedSources: []model.StorageSourceType{model.StorageSourceIPFS}},
+			[]model.StorageSpec{ipfs("Qm1"), url("https://example.com")}, require.False},
+		{"allowed CID", InputPolicy{AllowedCIDs: []string{"Qm1"}}, []model.StorageSpec{ipfs("Qm1")}, require.True},
+		{"disallowed CID", InputPolicy{AllowedCIDs: []string{"Qm1"}}, []model.StorageSpec{ipfs("Qm2")}, require.False},
+		{"allowed URL", InputPolicy{AllowedURLPrefixes: []string{"https://example.com/"}},
+			[]model.StorageSpec{url("https://example.com/data")}, require.True},
+		{"disallowed URL", InputPolicy{AllowedURLPrefixes: []string{"https://example.com/"}},
+			[]model.StorageSpec{url("https://example.com.evil/data")}, require.False},
+		{"within max size", InputPolicy{MaxInputSize: 200}, []model.StorageSpec{ipfs("Qm1"), ipfs("Qm2")}, require.True},
+		{"over max size", InputPolicy{MaxInputSize: 199}, []model.StorageSpec{ipfs("Qm1"), ipfs("Qm2")}, require.False},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			strategy := NewInputPolicyStrategy(InputPolicyStrategyParams{Policy: testCase.policy, Storages: provider})
+			result, err := strategy.ShouldBid(context.Background(), bidstrategy.BidStrategyRequest{
+				Job: model.Job{Spec: model.Spec{Inputs: testCase.inputs}},
+			})
+			require.NoError(t, err)
+			testCase.check(t, result.ShouldBid, fmt.Sprintf("Reason: %q", result.Reason))
+		})
+	}
+}
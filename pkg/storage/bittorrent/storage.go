@@ -0,0 +1,298 @@
+// Package bittorrent provides a storage source that downloads inputs given as
+// BitTorrent magnet links, so that large public datasets can be spread over
+// many compute nodes, which share pieces with each other as they download,
+// rather than every node downloading them from a single origin.
+//
+// Magnet links can name web seeds with ws parameters, and the URL of the
+// .torrent file with an xs parameter, so that torrents can be downloaded over
+// HTTP before there are peers to download them from. More web seeds can be
+// given in the metadata of the storage spec.
+package bittorrent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	alog "github.com/anacrolix/log"
+	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
+	tstorage "github.com/anacrolix/torrent/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// WebSeedsMetadataKey is the metadata of the storage spec with a comma
+	// separated list of web seeds to download the torrent from, as well as
+	// any in the magnet link.
+	WebSeedsMetadataKey = "WebSeeds"
+
+	// URLPrefix marks the URL of an input as a magnet link.
+	URLPrefix = "magnet:"
+
+	// how often downloads are checked for completion
+	progressInterval = time.Second
+)
+
+// StorageOptions configures the BitTorrent client of the compute node.
+type StorageOptions struct {
+	// ListenPort is the port to accept connections from peers on, or 0 for
+	// any free port
+	ListenPort int
+	// NoUpload stops the node sharing pieces with other peers
+	NoUpload bool
+	// NoDHT stops the node finding peers through the DHT, so that they are
+	// only found through trackers
+	NoDHT bool
+}
+
+// StorageProvider downloads torrents to a local directory, and keeps sharing
+// them with other peers for as long as jobs use them.
+type StorageProvider struct {
+	localDir string
+	options  StorageOptions
+
+	clientOnce sync.Once
+	client     *torrent.Client
+	clientErr  error
+
+	mu sync.Mutex
+	// how many inputs use each torrent, which is dropped once none do
+	users map[metainfo.Hash]int
+}
+
+func NewStorage(cm *system.CleanupManager, options StorageOptions) (*StorageProvider, error) {
+	dir, err := os.MkdirTemp(config.GetStoragePath(), "bacalhau-bittorrent")
+	if err != nil {
+		return nil, err
+	}
+
+	sp := newStorage(dir, options)
+	cm.RegisterCallback(func() error {
+		sp.close()
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("unable to remove storage folder: %w", err)
+		}
+		return nil
+	})
+
+	log.Debug().Str("dir", dir).Msg("BitTorrent driver created with output dir")
+
+	return sp, nil
+}
+
+func newStorage(dir string, options StorageOptions) *StorageProvider {
+	return &StorageProvider{
+		localDir: dir,
+		options:  options,
+		users:    make(map[metainfo.Hash]int),
+	}
+}
+
+func (sp *StorageProvider) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (sp *StorageProvider) HasStorageLocally(context.Context, model.StorageSpec) (bool, error) {
+	return false, nil
+}
+
+// GetVolumeSize returns the size of the torrent, once its metadata has been
+// downloaded.
+func (sp *StorageProvider) GetVolumeSize(ctx context.Context, storageSpec model.StorageSpec) (uint64, error) {
+	t, err := sp.addTorrent(ctx, storageSpec)
+	if err != nil {
+		return 0, err
+	}
+	defer sp.release(t)
+	return uint64(t.Length()), nil
+}
+
+// PrepareStorage downloads the torrent, which is kept being shared until the
+// storage is cleaned up.
+func (sp *StorageProvider) PrepareStorage(ctx context.Context, storageSpec model.StorageSpec) (storage.StorageVolume, error) {
+	t, err := sp.addTorrent(ctx, storageSpec)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	t.DownloadAll()
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for t.BytesMissing() > 0 {
+		select {
+		case <-ctx.Done():
+			sp.release(t)
+			return storage.StorageVolume{}, fmt.Errorf("failed to download torrent %s: %w", t.InfoHash(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+
+	log.Ctx(ctx).Debug().
+		Stringer("infohash", t.InfoHash()).
+		Int64("bytes", t.Length()).
+		Str("dir", sp.torrentDir(t.InfoHash())).
+		Msg("Downloaded torrent")
+
+	return storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: sp.torrentDir(t.InfoHash()),
+		Target: storageSpec.Path,
+	}, nil
+}
+
+func (sp *StorageProvider) CleanupStorage(ctx context.Context, storageSpec model.StorageSpec, _ storage.StorageVolume) error {
+	spec, err := ParseURL(storageSpec.URL)
+	if err != nil {
+		return err
+	}
+	client, err := sp.getClient()
+	if err != nil {
+		return err
+	}
+	if t, ok := client.Torrent(spec.InfoHash); ok {
+		log.Ctx(ctx).Debug().Stringer("infohash", spec.InfoHash).Msg("Cleaning up")
+		sp.release(t)
+	}
+	return nil
+}
+
+func (sp *StorageProvider) Upload(context.Context, string) (model.StorageSpec, error) {
+	return model.StorageSpec{}, fmt.Errorf("not implemented")
+}
+
+// HealthCheck is always healthy, as each input is downloaded from the peers of
+// its own torrent.
+func (sp *StorageProvider) HealthCheck(context.Context) error {
+	return nil
+}
+
+func (sp *StorageProvider) Explode(_ context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+	return []model.StorageSpec{
+		spec,
+	}, nil
+}
+
+// getClient returns the client of the compute node, which is only started once
+// an input needs it so that nodes without BitTorrent inputs don't listen for
+// peers.
+func (sp *StorageProvider) getClient() (*torrent.Client, error) {
+	sp.clientOnce.Do(func() {
+		cfg := torrent.NewDefaultClientConfig()
+		cfg.ListenPort = sp.options.ListenPort
+		cfg.NoUpload = sp.options.NoUpload
+		cfg.Seed = !sp.options.NoUpload
+		cfg.NoDHT = sp.options.NoDHT
+		cfg.Logger = alog.Default.FilterLevel(alog.Warning)
+		cfg.DefaultStorage = tstorage.NewFileOpts(tstorage.NewFileClientOpts{
+			ClientBaseDir: sp.localDir,
+			TorrentDirMaker: func(baseDir string, _ *metainfo.Info, infoHash metainfo.Hash) string {
+				return filepath.Join(baseDir, infoHash.HexString())
+			},
+			// downloads don't outlive the node, so neither does their progress
+			PieceCompletion: tstorage.NewMapPieceCompletion(),
+		})
+		sp.client, sp.clientErr = torrent.NewClient(cfg)
+	})
+	if sp.clientErr != nil {
+		return nil, fmt.Errorf("failed to start BitTorrent client: %w", sp.clientErr)
+	}
+	return sp.client, nil
+}
+
+func (sp *StorageProvider) close() {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	if sp.client != nil {
+		for _, err := range sp.client.Close() {
+			log.Warn().Err(err).Msg("Failed to close BitTorrent client")
+		}
+	}
+}
+
+// addTorrent adds the torrent of the storage spec, or another use of it if it
+// has already been added, and waits for its metadata.
+func (sp *StorageProvider) addTorrent(ctx context.Context, storageSpec model.StorageSpec) (*torrent.Torrent, error) {
+	spec, err := ParseURL(storageSpec.URL)
+	if err != nil {
+		return nil, err
+	}
+	spec.Webseeds = append(spec.Webseeds, webSeeds(storageSpec)...)
+
+	client, err := sp.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	sp.mu.Lock()
+	t, _, err := client.AddTorrentSpec(spec)
+	if err == nil {
+		sp.users[spec.InfoHash]++
+	}
+	sp.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to add torrent %s: %w", spec.InfoHash, err)
+	}
+
+	select {
+	case <-t.GotInfo():
+		return t, nil
+	case <-ctx.Done():
+		sp.release(t)
+		return nil, fmt.Errorf("failed to find metadata of torrent %s: %w", spec.InfoHash, ctx.Err())
+	}
+}
+
+// release ends a use of the torrent, and drops it along with its data once it
+// has no more.
+func (sp *StorageProvider) release(t *torrent.Torrent) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	infoHash := t.InfoHash()
+	if sp.users[infoHash]--; sp.users[infoHash] > 0 {
+		return
+	}
+	delete(sp.users, infoHash)
+	t.Drop()
+	if err := os.RemoveAll(sp.torrentDir(infoHash)); err != nil {
+		log.Warn().Err(err).Stringer("infohash", infoHash).Msg("Failed to remove torrent data")
+	}
+}
+
+func (sp *StorageProvider) torrentDir(infoHash metainfo.Hash) string {
+	return filepath.Join(sp.localDir, infoHash.HexString())
+}
+
+func webSeeds(storageSpec model.StorageSpec) []string {
+	var seeds []string
+	for _, seed := range strings.Split(storageSpec.Metadata[WebSeedsMetadataKey], ",") {
+		if seed = strings.TrimSpace(seed); seed != "" {
+			seeds = append(seeds, seed)
+		}
+	}
+	return seeds
+}
+
+// ParseURL returns the torrent of a magnet link.
+func ParseURL(rawURL string) (*torrent.TorrentSpec, error) {
+	rawURL = strings.Trim(rawURL, " '\"")
+	if !strings.HasPrefix(rawURL, URLPrefix) {
+		return nil, fmt.Errorf("magnet links must begin with %q", URLPrefix)
+	}
+	spec, err := torrent.TorrentSpecFromMagnetUri(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid magnet link: %w", err)
+	}
+	return spec, nil
+}
+
+// Compile time interface check:
+var _ storage.Storage = (*StorageProvider)(nil)
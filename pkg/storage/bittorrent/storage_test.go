@@ -0,0 +1,85 @@
+//go:build unit || !integration
+
+package bittorrent
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/anacrolix/torrent/bencode"
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURL(t *testing.T) {
+	spec, err := ParseURL("'magnet:?xt=urn:btih:c9e15763f722f23e98a29decdfae341b98d53056&ws=https%3A%2F%2Fexample.com%2F'")
+	require.NoError(t, err)
+	require.Equal(t, "c9e15763f722f23e98a29decdfae341b98d53056", spec.InfoHash.HexString())
+	require.Equal(t, []string{"https://example.com/"}, spec.Webseeds)
+
+	_, err = ParseURL("https://example.com/data.torrent")
+	require.Error(t, err)
+	_, err = ParseURL("magnet:?dn=no-infohash")
+	require.Error(t, err)
+}
+
+func TestPrepareStorage(t *testing.T) {
+	// a torrent of a directory, served only by a web seed
+	dataDir := filepath.Join(t.TempDir(), "dataset")
+	require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "sub"), 0755))
+	files := map[string]string{"a.csv": "1,2", "sub/b.csv": "3,4"}
+	for name, contents := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dataDir, name), []byte(contents), 0644))
+	}
+	info := metainfo.Info{PieceLength: 16 * 1024}
+	require.NoError(t, info.BuildFromFilePath(dataDir))
+	infoBytes, err := bencode.Marshal(info)
+	require.NoError(t, err)
+	var torrentFile bytes.Buffer
+	require.NoError(t, (&metainfo.MetaInfo{InfoBytes: infoBytes}).Write(&torrentFile))
+	infoHash := metainfo.HashBytes(infoBytes)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dataset.torrent", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(torrentFile.Bytes())
+	})
+	mux.Handle("/seed/", http.StripPrefix("/seed/", http.FileServer(http.Dir(filepath.Dir(dataDir)))))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	sp := newStorage(t.TempDir(), StorageOptions{NoDHT: true, NoUpload: true})
+	t.Cleanup(sp.close)
+	spec := model.StorageSpec{
+		StorageSource: model.StorageSourceBitTorrent,
+		URL:           "magnet:?xt=urn:btih:" + infoHash.HexString() + "&xs=" + url.QueryEscape(server.URL+"/dataset.torrent"),
+		Path:          "/inputs",
+		Metadata:      map[string]string{WebSeedsMetadataKey: server.URL + "/seed/"},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	size, err := sp.GetVolumeSize(ctx, spec)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), size)
+
+	volume, err := sp.PrepareStorage(ctx, spec)
+	require.NoError(t, err)
+	require.Equal(t, "/inputs", volume.Target)
+	for name, contents := range files {
+		actual, err := os.ReadFile(filepath.Join(volume.Source, "dataset", name))
+		require.NoError(t, err)
+		require.Equal(t, contents, string(actual))
+	}
+
+	require.NoError(t, sp.CleanupStorage(ctx, spec, volume))
+	require.NoDirExists(t, volume.Source)
+}
@@ -0,0 +1,376 @@
+// Package cache keeps the volumes that storage providers prepare on the
+// compute node, so that jobs that use the same data as earlier ones don't
+// download it again.
+//
+// Volumes are only cached if their content can be identified, either by the
+// CID of the storage spec or by the storage provider implementing
+// storage.ContentIdentifier, so that changed data is never served from the
+// cache. Once the cache is larger than its budget, the volumes that were used
+// least recently and that no execution is using are evicted.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/c2h5oh/datasize"
+	"github.com/rs/zerolog/log"
+	"go.uber.org/multierr"
+)
+
+// Options configures the content cache of the compute node.
+type Options struct {
+	// Dir is where cached volumes are kept. It should be on the same
+	// filesystem as the storage path, so that volumes are moved into the
+	// cache rather than copied.
+	Dir string
+	// MaxSize is the disk budget of the cache. Nothing is cached if it is zero.
+	MaxSize datasize.ByteSize
+}
+
+// Cache keeps prepared volumes of any of the storage providers that it wraps,
+// within a single disk budget.
+type Cache struct {
+	dir     string
+	maxSize uint64
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	// entries that are ready, most recently used first
+	lru  *list.List
+	size uint64
+}
+
+type entry struct {
+	name string
+	// what the volume is of, to show what is cached
+	source   model.StorageSourceType
+	location string
+	lastUsed time.Time
+	// the cached volume, whose target is relative to the path of the spec
+	volume storage.StorageVolume
+	size   uint64
+	// how many executions are using or waiting for the volume
+	users   int
+	element *list.Element
+	// closed once the volume has been prepared, successfully or not
+	ready chan struct{}
+	err   error
+}
+
+// New returns a cache of the compute node. Its directory is emptied, as what
+// is in it isn't known after a restart.
+func New(cm *system.CleanupManager, options Options) (*Cache, error) {
+	if options.MaxSize == 0 {
+		return nil, nil
+	}
+	dir := options.Dir
+	if dir == "" {
+		dir = filepath.Join(config.GetStoragePath(), "bacalhau-storage-cache")
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("unable to empty storage cache: %w", err)
+	}
+	if err := os.MkdirAll(dir, util.OS_USER_RWX); err != nil {
+		return nil, err
+	}
+
+	cm.RegisterCallback(func() error {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("unable to remove storage cache: %w", err)
+		}
+		return nil
+	})
+
+	log.Debug().Str("dir", dir).Stringer("max-size", options.MaxSize).Msg("Storage cache created")
+
+	return newCache(dir, options.MaxSize.Bytes()), nil
+}
+
+func newCache(dir string, maxSize uint64) *Cache {
+	return &Cache{
+		dir:     dir,
+		maxSize: maxSize,
+		entries: make(map[string]*entry),
+		lru:     list.New(),
+	}
+}
+
+// Wrap returns a storage that caches the volumes of the delegate. The delegate
+// must own the volumes it prepares, so that they can be moved into the cache,
+// which isn't the case for e.g. directories of the compute node. A nil cache
+// returns the delegate as it is.
+func (c *Cache) Wrap(delegate storage.Storage) storage.Storage {
+	if c == nil {
+		return delegate
+	}
+	return &cachingStorage{delegate: delegate, cache: c}
+}
+
+// entryName returns the name of the cache entry of a storage spec, if its
+// content is known.
+func entryName(spec model.StorageSpec, contentID string) string {
+	keys := make([]string, 0, len(spec.Metadata))
+	for key := range spec.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, part := range []string{spec.StorageSource.String(), spec.URL, spec.SourcePath, contentID} {
+		_, _ = fmt.Fprintf(h, "%q\n", part)
+	}
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(h, "%q=%q\n", key, spec.Metadata[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// entryLocation returns where the data of a storage spec comes from, without
+// any query string that might hold credentials.
+func entryLocation(spec model.StorageSpec) string {
+	for _, location := range []string{spec.CID, spec.URL, spec.SourcePath} {
+		if location != "" {
+			location, _, _ = strings.Cut(location, "?")
+			return location
+		}
+	}
+	return ""
+}
+
+// Entry is a volume that is kept in the cache.
+type Entry struct {
+	StorageSource string `json:"StorageSource"`
+	// Location is the CID, URL or path of the data of the volume
+	Location string    `json:"Location,omitempty"`
+	Size     uint64    `json:"Size"`
+	LastUsed time.Time `json:"LastUsed"`
+	// Users is how many executions are using the volume, which isn't evicted
+	// while they do
+	Users int `json:"Users"`
+}
+
+// Entries returns the volumes in the cache, most recently used first. A nil
+// cache has none.
+func (c *Cache) Entries() []Entry {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]Entry, 0, c.lru.Len())
+	for element := c.lru.Front(); element != nil; element = element.Next() {
+		e := element.Value.(*entry)
+		entries = append(entries, Entry{
+			StorageSource: e.source.String(),
+			Location:      e.location,
+			Size:          e.size,
+			LastUsed:      e.lastUsed,
+			Users:         e.users,
+		})
+	}
+	return entries
+}
+
+// lookup returns the entry of the name, if it is ready.
+func (c *Cache) lookup(name string) (*entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[name]
+	if !ok || e.element == nil {
+		return nil, false
+	}
+	return e, true
+}
+
+// prepare returns the cached volume of the name, or prepares and caches it. If
+// the volume can't be cached, the volume from prepare is returned as it is.
+func (c *Cache) prepare(
+	ctx context.Context,
+	name string,
+	spec model.StorageSpec,
+	prepare func() (storage.StorageVolume, error),
+	cleanup func(storage.StorageVolume) error,
+) (storage.StorageVolume, error) {
+	c.mu.Lock()
+	e, ok := c.entries[name]
+	if ok {
+		e.users++
+		c.mu.Unlock()
+
+		select {
+		case <-e.ready:
+		case <-ctx.Done():
+			c.release(e)
+			return storage.StorageVolume{}, ctx.Err()
+		}
+		if e.err != nil {
+			c.release(e)
+			// the execution that was preparing the volume failed, so this
+			// one tries for itself
+			return prepare()
+		}
+
+		c.mu.Lock()
+		c.lru.MoveToFront(e.element)
+		e.lastUsed = time.Now()
+		c.mu.Unlock()
+		log.Ctx(ctx).Debug().Str("source", spec.StorageSource.String()).Str("entry", name).Msg("Using cached volume")
+		return e.volumeFor(spec), nil
+	}
+
+	e = &entry{
+		name:     name,
+		source:   spec.StorageSource,
+		location: entryLocation(spec),
+		lastUsed: time.Now(),
+		users:    1,
+		ready:    make(chan struct{}),
+	}
+	c.entries[name] = e
+	c.mu.Unlock()
+
+	volume, err := prepare()
+	if err == nil {
+		err = c.keep(ctx, e, spec, volume, cleanup)
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Str("entry", name).Msg("Not caching volume")
+		}
+	}
+
+	c.mu.Lock()
+	e.err = err
+	if err != nil {
+		delete(c.entries, name)
+	} else {
+		e.element = c.lru.PushFront(e)
+		c.size += e.size
+		c.evict(ctx)
+	}
+	close(e.ready)
+	c.mu.Unlock()
+
+	if err != nil {
+		// the volume is still usable, just not cached
+		return volume, nil
+	}
+	return e.volumeFor(spec), nil
+}
+
+// keep moves the prepared volume into the entry, and then lets the storage
+// provider clean up anything else it kept for it.
+func (c *Cache) keep(
+	ctx context.Context,
+	e *entry,
+	spec model.StorageSpec,
+	volume storage.StorageVolume,
+	cleanup func(storage.StorageVolume) error,
+) error {
+	if volume.ReadWrite {
+		return fmt.Errorf("writable volumes are not cached")
+	}
+	if volume.Type != storage.StorageVolumeConnectorBind {
+		return fmt.Errorf("only bind volumes are cached")
+	}
+	target, ok := strings.CutPrefix(volume.Target, spec.Path)
+	if !ok {
+		return fmt.Errorf("volume target %s is not beneath the path %s", volume.Target, spec.Path)
+	}
+	size, err := util.DirSize(volume.Source)
+	if err != nil {
+		return err
+	}
+	if size > c.maxSize {
+		return fmt.Errorf("volume of %s is larger than the cache", datasize.ByteSize(size).HR())
+	}
+
+	entryDir := filepath.Join(c.dir, e.name)
+	if err = os.MkdirAll(entryDir, util.OS_USER_RWX); err != nil {
+		return err
+	}
+	source := filepath.Join(entryDir, filepath.Base(volume.Source))
+	if err = os.Rename(volume.Source, source); err != nil {
+		// e.g. because the volume is on another filesystem
+		return multierr.Combine(err, os.RemoveAll(entryDir))
+	}
+	if err = cleanup(volume); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("source", volume.Source).Msg("Failed to clean up volume once cached")
+	}
+
+	e.size = size
+	e.volume = storage.StorageVolume{
+		Type:   volume.Type,
+		Source: source,
+		Target: target,
+	}
+	return nil
+}
+
+// release ends a use of the entry, which can then be evicted once there are no
+// more.
+func (c *Cache) release(e *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.users--
+	c.evict(context.Background())
+}
+
+// releaseVolume ends a use of the cached volume, or returns false if the
+// volume isn't cached.
+func (c *Cache) releaseVolume(volume storage.StorageVolume) bool {
+	rel, err := filepath.Rel(c.dir, volume.Source)
+	if err != nil || !filepath.IsLocal(rel) {
+		return false
+	}
+	name, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+
+	c.mu.Lock()
+	e, ok := c.entries[name]
+	c.mu.Unlock()
+	if !ok {
+		return false
+	}
+	c.release(e)
+	return true
+}
+
+// evict removes the least recently used entries that aren't in use until the
+// cache is within its budget. The lock must be held.
+func (c *Cache) evict(ctx context.Context) {
+	for element := c.lru.Back(); element != nil && c.size > c.maxSize; {
+		e := element.Value.(*entry)
+		element = element.Prev()
+		if e.users > 0 {
+			continue
+		}
+		c.lru.Remove(e.element)
+		delete(c.entries, e.name)
+		c.size -= e.size
+		if err := os.RemoveAll(filepath.Join(c.dir, e.name)); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("entry", e.name).Msg("Failed to remove evicted volume")
+		}
+		log.Ctx(ctx).Debug().Str("entry", e.name).Uint64("size", e.size).Msg("Evicted cached volume")
+	}
+}
+
+func (e *entry) volumeFor(spec model.StorageSpec) storage.StorageVolume {
+	return storage.StorageVolume{
+		Type:   e.volume.Type,
+		Source: e.volume.Source,
+		Target: spec.Path + e.volume.Target,
+	}
+}
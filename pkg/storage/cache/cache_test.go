@@ -0,0 +1,185 @@
+//go:build unit || !integration
+
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// downloadingStorage writes the CID of specs into a file of a volume of its
+// own, as if it had been downloaded.
+type downloadingStorage struct {
+	dir      string
+	prepares atomic.Int32
+	cleanups atomic.Int32
+}
+
+func (s *downloadingStorage) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (s *downloadingStorage) HasStorageLocally(context.Context, model.StorageSpec) (bool, error) {
+	return false, nil
+}
+
+func (s *downloadingStorage) GetVolumeSize(context.Context, model.StorageSpec) (uint64, error) {
+	return 0, nil
+}
+
+func (s *downloadingStorage) PrepareStorage(_ context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+	s.prepares.Add(1)
+	dir, err := os.MkdirTemp(s.dir, "*")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	if err = os.WriteFile(filepath.Join(dir, "data"), []byte(spec.CID+spec.URL), 0644); err != nil {
+		return storage.StorageVolume{}, err
+	}
+	return storage.StorageVolume{Type: storage.StorageVolumeConnectorBind, Source: dir, Target: spec.Path}, nil
+}
+
+func (s *downloadingStorage) CleanupStorage(_ context.Context, _ model.StorageSpec, volume storage.StorageVolume) error {
+	s.cleanups.Add(1)
+	return os.RemoveAll(volume.Source)
+}
+
+func (s *downloadingStorage) Upload(context.Context, string) (model.StorageSpec, error) {
+	return model.StorageSpec{}, nil
+}
+
+func (s *downloadingStorage) HealthCheck(context.Context) error {
+	return nil
+}
+
+func newTestStorage(t *testing.T, maxSize uint64) (*Cache, *downloadingStorage, storage.Storage) {
+	c := newCache(t.TempDir(), maxSize)
+	delegate := &downloadingStorage{dir: t.TempDir()}
+	return c, delegate, c.Wrap(delegate)
+}
+
+func TestCachesVolumesAcrossExecutions(t *testing.T) {
+	ctx := context.Background()
+	c, delegate, s := newTestStorage(t, 1024)
+	spec := model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "QmData", Path: "/inputs"}
+
+	first, err := s.PrepareStorage(ctx, spec)
+	require.NoError(t, err)
+	require.NoError(t, s.CleanupStorage(ctx, spec, first))
+	require.FileExists(t, filepath.Join(first.Source, "data"))
+
+	spec.Path = "/data"
+	second, err := s.PrepareStorage(ctx, spec)
+	require.NoError(t, err)
+	require.Equal(t, first.Source, second.Source)
+	require.Equal(t, "/data", second.Target)
+	require.Equal(t, int32(1), delegate.prepares.Load())
+
+	local, err := s.HasStorageLocally(ctx, spec)
+	require.NoError(t, err)
+	require.True(t, local)
+	size, err := s.GetVolumeSize(ctx, spec)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len("QmData")), size)
+
+	require.NoError(t, s.CleanupStorage(ctx, spec, second))
+	require.Equal(t, uint64(len("QmData")), c.size)
+
+	entries := c.Entries()
+	require.Len(t, entries, 1)
+	require.Equal(t, model.StorageSourceIPFS.String(), entries[0].StorageSource)
+	require.Equal(t, "QmData", entries[0].Location)
+	require.Equal(t, uint64(len("QmData")), entries[0].Size)
+	require.Zero(t, entries[0].Users)
+}
+
+func TestEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c, delegate, s := newTestStorage(t, 2*uint64(len("QmData1")))
+
+	volumes := map[string]storage.StorageVolume{}
+	for _, cid := range []string{"QmData1", "QmData2", "QmData1", "QmData3"} {
+		spec := model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: cid, Path: "/inputs"}
+		volume, err := s.PrepareStorage(ctx, spec)
+		require.NoError(t, err)
+		require.NoError(t, s.CleanupStorage(ctx, spec, volume))
+		volumes[cid] = volume
+	}
+
+	require.Equal(t, int32(3), delegate.prepares.Load())
+	require.DirExists(t, volumes["QmData1"].Source)
+	require.NoDirExists(t, volumes["QmData2"].Source)
+	require.DirExists(t, volumes["QmData3"].Source)
+	require.Len(t, c.entries, 2)
+}
+
+func TestDoesNotEvictVolumesInUse(t *testing.T) {
+	ctx := context.Background()
+	c, _, s := newTestStorage(t, uint64(len("QmData1")))
+
+	spec1 := model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "QmData1", Path: "/inputs"}
+	inUse, err := s.PrepareStorage(ctx, spec1)
+	require.NoError(t, err)
+
+	spec2 := model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "QmData2", Path: "/inputs"}
+	other, err := s.PrepareStorage(ctx, spec2)
+	require.NoError(t, err)
+	require.NoError(t, s.CleanupStorage(ctx, spec2, other))
+
+	require.DirExists(t, inUse.Source)
+	require.NoDirExists(t, other.Source)
+
+	require.NoError(t, s.CleanupStorage(ctx, spec1, inUse))
+	require.DirExists(t, inUse.Source, "within budget once released")
+	require.Len(t, c.entries, 1)
+}
+
+func TestPassesThroughUnidentifiedContent(t *testing.T) {
+	ctx := context.Background()
+	c, delegate, s := newTestStorage(t, 1024)
+	spec := model.StorageSpec{StorageSource: model.StorageSourceURLDownload, URL: "https://example.com/data", Path: "/inputs"}
+
+	for i := 0; i < 2; i++ {
+		volume, err := s.PrepareStorage(ctx, spec)
+		require.NoError(t, err)
+		require.NoError(t, s.CleanupStorage(ctx, spec, volume))
+		require.NoDirExists(t, volume.Source)
+	}
+	require.Equal(t, int32(2), delegate.prepares.Load())
+	require.Equal(t, int32(2), delegate.cleanups.Load())
+	require.Empty(t, c.entries)
+}
+
+func TestConcurrentExecutionsPrepareOnce(t *testing.T) {
+	ctx := context.Background()
+	_, delegate, s := newTestStorage(t, 1024)
+	spec := model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "QmData", Path: "/inputs"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			volume, err := s.PrepareStorage(ctx, spec)
+			require.NoError(t, err)
+			require.NoError(t, s.CleanupStorage(ctx, spec, volume))
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), delegate.prepares.Load())
+}
+
+func TestDisabledCache(t *testing.T) {
+	delegate := &downloadingStorage{}
+	c, err := New(nil, Options{})
+	require.NoError(t, err)
+	require.Same(t, delegate, c.Wrap(delegate))
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/rs/zerolog/log"
+)
+
+type cachingStorage struct {
+	delegate storage.Storage
+	cache    *Cache
+}
+
+func (s *cachingStorage) IsInstalled(ctx context.Context) (bool, error) {
+	return s.delegate.IsInstalled(ctx)
+}
+
+func (s *cachingStorage) HasStorageLocally(ctx context.Context, spec model.StorageSpec) (bool, error) {
+	if name, ok := s.entryName(ctx, spec); ok {
+		if _, ok := s.cache.lookup(name); ok {
+			return true, nil
+		}
+	}
+	return s.delegate.HasStorageLocally(ctx, spec)
+}
+
+func (s *cachingStorage) GetVolumeSize(ctx context.Context, spec model.StorageSpec) (uint64, error) {
+	if name, ok := s.entryName(ctx, spec); ok {
+		if e, ok := s.cache.lookup(name); ok {
+			return e.size, nil
+		}
+	}
+	return s.delegate.GetVolumeSize(ctx, spec)
+}
+
+func (s *cachingStorage) PrepareStorage(ctx context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+	name, ok := s.entryName(ctx, spec)
+	if !ok {
+		return s.delegate.PrepareStorage(ctx, spec)
+	}
+	return s.cache.prepare(ctx, name, spec,
+		func() (storage.StorageVolume, error) {
+			return s.delegate.PrepareStorage(ctx, spec)
+		},
+		func(volume storage.StorageVolume) error {
+			return s.delegate.CleanupStorage(ctx, spec, volume)
+		},
+	)
+}
+
+// CleanupStorage leaves cached volumes in the cache until they are evicted.
+func (s *cachingStorage) CleanupStorage(ctx context.Context, spec model.StorageSpec, volume storage.StorageVolume) error {
+	if s.cache.releaseVolume(volume) {
+		return nil
+	}
+	return s.delegate.CleanupStorage(ctx, spec, volume)
+}
+
+func (s *cachingStorage) Upload(ctx context.Context, path string) (model.StorageSpec, error) {
+	return s.delegate.Upload(ctx, path)
+}
+
+func (s *cachingStorage) HealthCheck(ctx context.Context) error {
+	return s.delegate.HealthCheck(ctx)
+}
+
+// entryName returns the name of the cache entry of the spec, or false if its
+// content can't be identified.
+func (s *cachingStorage) entryName(ctx context.Context, spec model.StorageSpec) (string, bool) {
+	contentID := spec.CID
+	if contentID == "" {
+		identifier, ok := s.delegate.(storage.ContentIdentifier)
+		if !ok {
+			return "", false
+		}
+		var err error
+		if contentID, err = identifier.ContentID(ctx, spec); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Failed to identify content, so not caching it")
+			return "", false
+		}
+		if contentID == "" {
+			return "", false
+		}
+	}
+	return entryName(spec, contentID), true
+}
+
+// Compile time interface check:
+var _ storage.Storage = (*cachingStorage)(nil)
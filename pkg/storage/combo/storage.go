@@ -6,6 +6,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"go.uber.org/multierr"
 )
 
 type AllProviderFetcher func(ctx context.Context) ([]storage.Storage, error)
@@ -99,6 +100,18 @@ func (driver *ComboStorageProvider) Upload(
 	return provider.Upload(ctx, localPath)
 }
 
+// HealthCheck returns the errors of all the storages that aren't healthy.
+func (driver *ComboStorageProvider) HealthCheck(ctx context.Context) error {
+	allProviders, err := driver.AllFetcher(ctx)
+	if err != nil {
+		return err
+	}
+	for _, provider := range allProviders {
+		err = multierr.Append(err, provider.HealthCheck(ctx))
+	}
+	return err
+}
+
 func (driver *ComboStorageProvider) getReadProvider(ctx context.Context, spec model.StorageSpec) (storage.Storage, error) {
 	return driver.ReadFetcher(ctx, spec)
 }
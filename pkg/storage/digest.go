@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/multiformats/go-multihash"
+)
+
+// DigestMismatchError is returned when the data of a storage spec doesn't
+// have the digest that the spec expects it to.
+type DigestMismatchError struct {
+	Spec     model.StorageSpec
+	Expected string
+	Actual   string
+}
+
+func (e *DigestMismatchError) Error() string {
+	return fmt.Sprintf("input %s failed verification: expected digest %s but got %s", describeSpec(e.Spec), e.Expected, e.Actual)
+}
+
+// VerifyDigest checks that the data of the volume has the digest that the spec
+// expects, if it has one. Digests are of a single file, so the volume must be
+// a file or a directory with a single file in it, as URL downloads are.
+func VerifyDigest(spec model.StorageSpec, volume StorageVolume) error {
+	expected, err := spec.ExpectedDigest()
+	if err != nil || expected == nil {
+		return err
+	}
+	decoded, err := multihash.Decode(expected)
+	if err != nil {
+		return err
+	}
+
+	filePath, err := singleFile(volume.Source)
+	if err != nil {
+		return fmt.Errorf("input %s can't be verified: %w", describeSpec(spec), err)
+	}
+	hasher, err := multihash.GetHasher(decoded.Code)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError("input", f)
+	if _, err = io.Copy(hasher, f); err != nil {
+		return err
+	}
+
+	actual := hasher.Sum(nil)
+	if !bytes.Equal(actual, decoded.Digest) {
+		return &DigestMismatchError{
+			Spec:     spec,
+			Expected: decoded.Name + ":" + hex.EncodeToString(decoded.Digest),
+			Actual:   decoded.Name + ":" + hex.EncodeToString(actual),
+		}
+	}
+	return nil
+}
+
+// singleFile returns the path of the file, or of the only file in the
+// directory.
+func singleFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			files = append(files, p)
+		}
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(files) != 1 {
+		return "", fmt.Errorf("a digest can only be verified for a single file, but found %d", len(files))
+	}
+	return files[0], nil
+}
+
+func describeSpec(spec model.StorageSpec) string {
+	switch {
+	case spec.URL != "":
+		return spec.URL
+	case spec.CID != "":
+		return spec.CID
+	case spec.SourcePath != "":
+		return spec.SourcePath
+	default:
+		return spec.Path
+	}
+}
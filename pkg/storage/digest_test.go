@@ -0,0 +1,50 @@
+//go:build unit || !integration
+
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyDigest(t *testing.T) {
+	contents := []byte("hello world")
+	sum := sha256.Sum256(contents)
+	mh, err := multihash.Sum(contents, multihash.SHA2_256, -1)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), contents, 0644))
+	volume := StorageVolume{Type: StorageVolumeConnectorBind, Source: dir}
+
+	for _, digest := range []string{"", "sha256:" + hex.EncodeToString(sum[:]), mh.B58String()} {
+		require.NoError(t, VerifyDigest(model.StorageSpec{Digest: digest}, volume), digest)
+	}
+
+	wrong := sha256.Sum256([]byte("goodbye world"))
+	err = VerifyDigest(model.StorageSpec{URL: "https://example.com/file.txt", Digest: "sha256:" + hex.EncodeToString(wrong[:])}, volume)
+	var mismatch *DigestMismatchError
+	require.ErrorAs(t, err, &mismatch)
+	require.Equal(t, "sha2-256:"+hex.EncodeToString(sum[:]), mismatch.Actual)
+	require.ErrorContains(t, err, "https://example.com/file.txt")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), contents, 0644))
+	err = VerifyDigest(model.StorageSpec{Digest: "sha256:" + hex.EncodeToString(sum[:])}, volume)
+	require.Error(t, err)
+	require.False(t, errors.As(err, &mismatch), "files that can't be verified don't mismatch")
+}
+
+func TestExpectedDigest(t *testing.T) {
+	for _, invalid := range []string{"sha256:abc", "md4:00", "sha256:zz", "not-a-multihash"} {
+		_, err := model.StorageSpec{Digest: invalid}.ExpectedDigest()
+		require.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/c2h5oh/datasize"
+	"github.com/rs/zerolog/log"
+)
+
+// InsufficientDiskSpaceError is returned when the inputs of an execution
+// wouldn't fit in the free space of the disk that they are prepared on.
+type InsufficientDiskSpaceError struct {
+	Path   string
+	Needed uint64
+	Free   uint64
+}
+
+func (e *InsufficientDiskSpaceError) Error() string {
+	return fmt.Sprintf("inputs need %s of disk space but only %s is free at %s",
+		datasize.ByteSize(e.Needed).HR(), datasize.ByteSize(e.Free).HR(), e.Path)
+}
+
+// CheckDiskSpace returns an InsufficientDiskSpaceError if the specs that
+// aren't already on the node are larger than the free space of the path, so
+// that executions fail before downloading anything rather than once the disk
+// is full. Specs whose size isn't known are left out.
+func CheckDiskSpace(ctx context.Context, provider StorageProvider, specs []model.StorageSpec, path string) error {
+	var needed uint64
+	for _, spec := range specs {
+		storageProvider, err := provider.Get(ctx, spec.StorageSource)
+		if err != nil {
+			return err
+		}
+		if local, err := storageProvider.HasStorageLocally(ctx, spec); err == nil && local {
+			continue
+		}
+		size, err := storageProvider.GetVolumeSize(ctx, spec)
+		if err != nil {
+			// preparing the storage will fail too if it can't be found
+			log.Ctx(ctx).Debug().Err(err).Str("source", spec.StorageSource.String()).Msg("Unable to size input")
+			continue
+		}
+		needed += size
+	}
+	if needed == 0 {
+		return nil
+	}
+
+	space, err := util.DiskSpace(path)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Unable to check free disk space before preparing inputs")
+		return nil
+	}
+	if needed > space.Free {
+		return &InsufficientDiskSpaceError{Path: path, Needed: needed, Free: space.Free}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+//go:build unit || !integration
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// sizedStorage has volumes of a fixed size, which are local if they have a
+// CID.
+type sizedStorage struct {
+	size uint64
+}
+
+func (s sizedStorage) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (s sizedStorage) HasStorageLocally(_ context.Context, spec model.StorageSpec) (bool, error) {
+	return spec.CID != "", nil
+}
+
+func (s sizedStorage) GetVolumeSize(context.Context, model.StorageSpec) (uint64, error) {
+	return s.size, nil
+}
+
+func (s sizedStorage) PrepareStorage(context.Context, model.StorageSpec) (StorageVolume, error) {
+	return StorageVolume{}, nil
+}
+
+func (s sizedStorage) CleanupStorage(context.Context, model.StorageSpec, StorageVolume) error {
+	return nil
+}
+
+func (s sizedStorage) Upload(context.Context, string) (model.StorageSpec, error) {
+	return model.StorageSpec{}, nil
+}
+
+func (s sizedStorage) HealthCheck(context.Context) error {
+	return nil
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	ctx := context.Background()
+	provider := model.NewMappedProvider(map[model.StorageSourceType]Storage{
+		model.StorageSourceURLDownload: sizedStorage{size: 1},
+		model.StorageSourceS3:          sizedStorage{size: math.MaxUint64 / 2},
+	})
+	dir := t.TempDir()
+
+	small := model.StorageSpec{StorageSource: model.StorageSourceURLDownload}
+	require.NoError(t, CheckDiskSpace(ctx, provider, []model.StorageSpec{small, small}, dir))
+
+	huge := model.StorageSpec{StorageSource: model.StorageSourceS3}
+	err := CheckDiskSpace(ctx, provider, []model.StorageSpec{small, huge}, dir)
+	var insufficient *InsufficientDiskSpaceError
+	require.True(t, errors.As(err, &insufficient))
+	require.Equal(t, uint64(math.MaxUint64/2+1), insufficient.Needed)
+
+	local := model.StorageSpec{StorageSource: model.StorageSourceS3, CID: "QmLocal"}
+	require.NoError(t, CheckDiskSpace(ctx, provider, []model.StorageSpec{local}, dir), "local inputs need no space")
+}
@@ -0,0 +1,366 @@
+package extract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs/car"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	carv2 "github.com/ipld/go-car/v2"
+)
+
+// tarMagicOffset is where a tar header has its magic, which is followed by
+// either a space or null depending on the tar format.
+const tarMagicOffset = 257
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+	// the magic of a zip without any files
+	emptyZipMagic = []byte("PK\x05\x06")
+	tarMagic      = []byte("ustar")
+)
+
+// archiveOf returns the archive of a volume, which is either the volume itself
+// or the only file in it.
+func archiveOf(source string) (string, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return source, nil
+	}
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) != 1 || !entries[0].Type().IsRegular() {
+		return "", fmt.Errorf("only inputs of a single archive can be extracted, but %s has %d entries", source, len(entries))
+	}
+	return filepath.Join(source, entries[0].Name()), nil
+}
+
+// extractArchive extracts the tar, gzipped tar, zip or CAR archive into the
+// directory, which must exist.
+func extractArchive(ctx context.Context, archive, dst string, options Options) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError(archive, f)
+
+	reader := bufio.NewReader(f)
+	header, err := reader.Peek(tarMagicOffset + len(tarMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	x := &extraction{dst: dst, options: options}
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		zr, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		err = x.tar(tar.NewReader(zr))
+		if err != nil {
+			return err
+		}
+	case bytes.HasPrefix(header, zipMagic), bytes.HasPrefix(header, emptyZipMagic):
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		zr, err := zip.NewReader(f, info.Size())
+		if err != nil {
+			return err
+		}
+		if err = x.zip(zr); err != nil {
+			return err
+		}
+	case len(header) > tarMagicOffset && bytes.HasPrefix(header[tarMagicOffset:], tarMagic):
+		if err = x.tar(tar.NewReader(reader)); err != nil {
+			return err
+		}
+	case isCAR(reader):
+		return x.car(ctx, archive)
+	default:
+		return fmt.Errorf("%s is not a tar, gzipped tar, zip or CAR archive", filepath.Base(archive))
+	}
+	return x.symlinks()
+}
+
+// isCAR returns whether the reader begins with the header of a CAR.
+func isCAR(reader *bufio.Reader) bool {
+	header, _ := reader.Peek(reader.Size())
+	version, err := carv2.ReadVersion(bytes.NewReader(header))
+	return err == nil && (version == 1 || version == 2)
+}
+
+// extraction writes the entries of an archive within its directory. Symlinks
+// are only made once everything else has been written, so that nothing is
+// written through them.
+type extraction struct {
+	dst     string
+	options Options
+
+	size  uint64
+	files int
+	links []symlink
+}
+
+type symlink struct {
+	path, target string
+}
+
+func (x *extraction) tar(tr *tar.Reader) error {
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = x.count(); err != nil {
+			return err
+		}
+		path, err := x.pathOf(header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0755)
+		case tar.TypeReg:
+			err = x.file(path, header.FileInfo().Mode(), tr)
+		case tar.TypeSymlink:
+			err = x.symlink(path, header.Name, header.Linkname)
+		case tar.TypeLink:
+			var target string
+			if target, err = x.pathOf(header.Linkname); err == nil {
+				err = os.Link(target, path)
+			}
+		default:
+			// devices and pipes aren't data
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (x *extraction) zip(zr *zip.Reader) error {
+	for _, f := range zr.File {
+		if err := x.count(); err != nil {
+			return err
+		}
+		path, err := x.pathOf(f.Name)
+		if err != nil {
+			return err
+		}
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			err = os.MkdirAll(path, 0755)
+		case mode&os.ModeSymlink != 0:
+			var target []byte
+			if target, err = x.readZipFile(f, 4096); err == nil {
+				err = x.symlink(path, f.Name, string(target))
+			}
+		case mode.IsRegular():
+			var rc io.ReadCloser
+			if rc, err = f.Open(); err == nil {
+				err = x.file(path, mode, rc)
+				closer.CloseWithLogOnError(f.Name, rc)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// car unpacks the UnixFS DAG of the CAR, counting what is extracted as it goes
+// as blocks can be linked many times over.
+func (x *extraction) car(ctx context.Context, archive string) error {
+	return car.ExtractCarWithLimits(ctx, archive, x.dst, car.Limits{
+		MaxSize:  x.options.MaxSize.Bytes(),
+		MaxFiles: x.options.MaxFiles,
+	})
+}
+
+func (x *extraction) readZipFile(f *zip.File, limit int64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer closer.CloseWithLogOnError(f.Name, rc)
+	return io.ReadAll(io.LimitReader(rc, limit))
+}
+
+func (x *extraction) count() error {
+	x.files++
+	if x.files > x.options.MaxFiles {
+		return fmt.Errorf("archive has more than %d entries", x.options.MaxFiles)
+	}
+	return nil
+}
+
+// pathOf returns where an entry of the archive is written, which must be
+// within the directory it is extracted to.
+func (x *extraction) pathOf(name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %q is outside of the archive", name)
+	}
+	return filepath.Join(x.dst, clean), nil
+}
+
+func (x *extraction) file(path string, mode os.FileMode, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// files must be readable by whoever runs the job
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm()|0444)
+	if err != nil {
+		return err
+	}
+	remaining := x.options.MaxSize.Bytes() - x.size
+	n, err := io.CopyN(f, r, int64(remaining)+1)
+	x.size += uint64(n)
+	if closeErr := f.Close(); err == nil || errors.Is(err, io.EOF) {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if x.size > x.options.MaxSize.Bytes() {
+		return fmt.Errorf("archive extracts to more than %s", x.options.MaxSize.HR())
+	}
+	return nil
+}
+
+func (x *extraction) symlink(path, name, target string) error {
+	resolved := filepath.Join(filepath.Dir(filepath.FromSlash(name)), filepath.FromSlash(target))
+	if filepath.IsAbs(target) {
+		resolved = target
+	}
+	if _, err := x.pathOf(resolved); err != nil {
+		return fmt.Errorf("archive entry %q links outside of the archive", name)
+	}
+	x.links = append(x.links, symlink{path: path, target: target})
+	return nil
+}
+
+// symlinks makes the symlinks of the archive, but not in directories that
+// earlier symlinks have led outside of it. Once they are all made, each link is
+// followed through the links it leads to, as a target that looks to be within
+// the archive can lead outside of it through other links.
+func (x *extraction) symlinks() error {
+	root, err := filepath.EvalSymlinks(x.dst)
+	if err != nil {
+		return err
+	}
+	for _, link := range x.links {
+		if err = x.mkdirWithin(root, filepath.Dir(link.path)); err != nil {
+			return err
+		}
+		if err = os.Symlink(link.target, link.path); err != nil {
+			return err
+		}
+	}
+	for _, link := range x.links {
+		dir, err := filepath.EvalSymlinks(filepath.Dir(link.path))
+		if err != nil {
+			return err
+		}
+		if err = resolveWithin(root, dir, link.target); err != nil {
+			return fmt.Errorf("archive entry %s links outside of the archive: %w", link.path, err)
+		}
+	}
+	return nil
+}
+
+// maxLinksFollowed is how many links are followed when resolving a link before
+// giving up, like the ELOOP limit of the kernel.
+const maxLinksFollowed = 255
+
+// resolveWithin follows the target relative to the directory, which is within
+// the root, one element at a time, and fails if it leaves the root.
+func resolveWithin(root, dir, target string) error {
+	current := dir
+	remaining := strings.Split(filepath.ToSlash(target), "/")
+	followed := 0
+	for len(remaining) > 0 {
+		element := remaining[0]
+		remaining = remaining[1:]
+		switch element {
+		case "", ".":
+			continue
+		case "..":
+			if current == root {
+				return fmt.Errorf("%s leads above the archive", target)
+			}
+			current = filepath.Dir(current)
+			continue
+		}
+
+		next := filepath.Join(current, element)
+		info, err := os.Lstat(next)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			current = next
+			continue
+		}
+
+		followed++
+		if followed > maxLinksFollowed {
+			return fmt.Errorf("%s follows too many links", target)
+		}
+		linkTarget, err := os.Readlink(next)
+		if err != nil {
+			return err
+		}
+		if filepath.IsAbs(linkTarget) {
+			return fmt.Errorf("%s leads to an absolute path", target)
+		}
+		remaining = append(strings.Split(filepath.ToSlash(linkTarget), "/"), remaining...)
+	}
+	return nil
+}
+
+// mkdirWithin makes the directory if the closest of it and its parents that
+// exists, with any symlinks followed, is within the root.
+func (x *extraction) mkdirWithin(root, dir string) error {
+	existing := dir
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return err
+	}
+	if resolved != root && !strings.HasPrefix(resolved, root+string(filepath.Separator)) {
+		return fmt.Errorf("archive links through %s to outside of the archive", existing)
+	}
+	return os.MkdirAll(dir, 0755)
+}
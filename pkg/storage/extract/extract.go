@@ -0,0 +1,154 @@
+// Package extract unpacks inputs that are tar, gzipped tar, zip or CAR archives
+// into the path that they are mounted at, so that jobs don't each need a step
+// of their own to unpack their data. CARs are unpacked from the UnixFS DAG
+// that they hold, so that data exported from IPFS or web3.storage can be used
+// without an IPFS node.
+//
+// Only inputs whose storage spec asks for it are extracted, by setting the
+// Extract metadata to true. Archives are checked for entries that would be
+// written outside of where they are extracted to, and are only extracted up to
+// a size and number of files, so that a small archive can't fill the disk of
+// the compute node.
+package extract
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/c2h5oh/datasize"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// MetadataKey is the metadata of the storage spec that asks for the input
+	// to be extracted when it is true.
+	MetadataKey = "Extract"
+
+	// DefaultMaxSize is the most that an archive extracts to if no other size
+	// is configured.
+	DefaultMaxSize = 10 * datasize.GB
+	// DefaultMaxFiles is the most entries that an archive may have if no
+	// other number is configured.
+	DefaultMaxFiles = 100_000
+)
+
+// Options configures the limits of extracting archives on the compute node.
+type Options struct {
+	// MaxSize is the most that an archive may extract to.
+	MaxSize datasize.ByteSize
+	// MaxFiles is the most entries that an archive may have.
+	MaxFiles int
+}
+
+// Extractor extracts the archives of inputs into directories of its own, and
+// knows which volume each was extracted from so that both can be cleaned up.
+type Extractor struct {
+	dir     string
+	options Options
+
+	mu sync.Mutex
+	// the volumes that archives were extracted from, by where they were
+	// extracted to
+	archives map[string]storage.StorageVolume
+}
+
+// New returns the extractor of the compute node.
+func New(cm *system.CleanupManager, options Options) (*Extractor, error) {
+	dir, err := os.MkdirTemp(config.GetStoragePath(), "bacalhau-extract")
+	if err != nil {
+		return nil, err
+	}
+	cm.RegisterCallback(func() error {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("unable to remove extract folder: %w", err)
+		}
+		return nil
+	})
+	return newExtractor(dir, options), nil
+}
+
+func newExtractor(dir string, options Options) *Extractor {
+	if options.MaxSize == 0 {
+		options.MaxSize = DefaultMaxSize
+	}
+	if options.MaxFiles <= 0 {
+		options.MaxFiles = DefaultMaxFiles
+	}
+	return &Extractor{
+		dir:      dir,
+		options:  options,
+		archives: make(map[string]storage.StorageVolume),
+	}
+}
+
+// Wrap returns a provider whose storages extract the inputs that ask for it
+// once they have prepared them.
+func (e *Extractor) Wrap(provider storage.StorageProvider) storage.StorageProvider {
+	return &extractingProvider{delegate: provider, extractor: e}
+}
+
+// IsRequested returns whether the storage spec asks for its input to be
+// extracted.
+func IsRequested(spec model.StorageSpec) bool {
+	extract, _ := strconv.ParseBool(spec.Metadata[MetadataKey])
+	return extract
+}
+
+// Request returns the storage spec asking for its input to be extracted.
+func Request(spec model.StorageSpec) model.StorageSpec {
+	metadata := make(map[string]string, len(spec.Metadata)+1)
+	for k, v := range spec.Metadata {
+		metadata[k] = v
+	}
+	metadata[MetadataKey] = "true"
+	spec.Metadata = metadata
+	return spec
+}
+
+// extract extracts the archive of the volume, and returns the volume of what
+// it extracted to.
+func (e *Extractor) extract(ctx context.Context, spec model.StorageSpec, volume storage.StorageVolume) (storage.StorageVolume, error) {
+	archive, err := archiveOf(volume.Source)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	dst, err := os.MkdirTemp(e.dir, "*")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	if err := extractArchive(ctx, archive, dst, e.options); err != nil {
+		_ = os.RemoveAll(dst)
+		return storage.StorageVolume{}, fmt.Errorf("failed to extract %s: %w", spec.Name, err)
+	}
+
+	log.Ctx(ctx).Debug().Str("archive", archive).Str("dir", dst).Msg("Extracted input")
+
+	e.mu.Lock()
+	e.archives[dst] = volume
+	e.mu.Unlock()
+	return storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: dst,
+		Target: spec.Path,
+	}, nil
+}
+
+// release removes what was extracted to the volume, and returns the volume of
+// the archive that it was extracted from.
+func (e *Extractor) release(volume storage.StorageVolume) (storage.StorageVolume, bool, error) {
+	e.mu.Lock()
+	archive, ok := e.archives[volume.Source]
+	delete(e.archives, volume.Source)
+	e.mu.Unlock()
+	if !ok {
+		return volume, false, nil
+	}
+	return archive, true, os.RemoveAll(volume.Source)
+}
@@ -0,0 +1,257 @@
+//go:build unit || !integration
+
+package extract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs/car"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+type entry struct {
+	name, contents, link string
+	dir                  bool
+}
+
+func tarOf(t *testing.T, entries ...entry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.contents))}
+		if e.dir {
+			header = &tar.Header{Name: e.name, Mode: 0755, Typeflag: tar.TypeDir}
+		} else if e.link != "" {
+			header = &tar.Header{Name: e.name, Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: e.link}
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		_, err := tw.Write([]byte(e.contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func gzipOf(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func zipOf(t *testing.T, entries ...entry) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(e.contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func extractBytes(t *testing.T, data []byte, options Options) (string, error) {
+	archive := filepath.Join(t.TempDir(), "archive")
+	require.NoError(t, os.WriteFile(archive, data, 0644))
+	dst := t.TempDir()
+	return dst, extractArchive(context.Background(), archive, dst, newExtractor("", options).options)
+}
+
+func requireFile(t *testing.T, path, contents string) {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, contents, string(data))
+}
+
+func TestExtractsArchives(t *testing.T) {
+	entries := []entry{
+		{name: "data/", dir: true},
+		{name: "data/a.csv", contents: "1,2"},
+		{name: "data/sub/b.csv", contents: "3,4"},
+	}
+	for name, data := range map[string][]byte{
+		"tar":    tarOf(t, entries...),
+		"tar.gz": gzipOf(t, tarOf(t, entries...)),
+		"zip":    zipOf(t, entries...),
+	} {
+		t.Run(name, func(t *testing.T) {
+			dst, err := extractBytes(t, data, Options{})
+			require.NoError(t, err)
+			requireFile(t, filepath.Join(dst, "data", "a.csv"), "1,2")
+			requireFile(t, filepath.Join(dst, "data", "sub", "b.csv"), "3,4")
+		})
+	}
+}
+
+func TestExtractsCARs(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "data"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data", "a.csv"), []byte("1,2"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data", "big.csv"), bytes.Repeat([]byte("1,2\n"), 10), 0644))
+	for _, version := range []int{1, 2} {
+		archive := filepath.Join(t.TempDir(), "data.car")
+		_, err := car.CreateCar(context.Background(), dir, archive, version)
+		require.NoError(t, err)
+		data, err := os.ReadFile(archive)
+		require.NoError(t, err)
+
+		dst, err := extractBytes(t, data, Options{})
+		require.NoError(t, err)
+		requireFile(t, filepath.Join(dst, "data", "a.csv"), "1,2")
+
+		_, err = extractBytes(t, data, Options{MaxSize: 10})
+		require.ErrorContains(t, err, "more than")
+
+		_, err = extractBytes(t, data, Options{MaxFiles: 2})
+		require.ErrorContains(t, err, "more than 2 entries")
+	}
+}
+
+func TestLimitsCARsByWhatTheyExtractTo(t *testing.T) {
+	// identical files are stored once in the CAR, but each is extracted
+	dir := t.TempDir()
+	contents := bytes.Repeat([]byte("x"), 1000)
+	for i := 0; i < 20; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.txt", i)), contents, 0644))
+	}
+	archive := filepath.Join(t.TempDir(), "data.car")
+	_, err := car.CreateCar(context.Background(), dir, archive, 1)
+	require.NoError(t, err)
+	data, err := os.ReadFile(archive)
+	require.NoError(t, err)
+	require.Less(t, len(data), 10000)
+
+	_, err = extractBytes(t, data, Options{MaxSize: 10000})
+	require.ErrorContains(t, err, "more than")
+}
+
+func TestExtractsSymlinksWithinArchive(t *testing.T) {
+	dst, err := extractBytes(t, tarOf(t,
+		entry{name: "latest", link: "v1"},
+		entry{name: "v1/data.csv", contents: "1,2"},
+	), Options{})
+	require.NoError(t, err)
+	requireFile(t, filepath.Join(dst, "latest", "data.csv"), "1,2")
+}
+
+func TestRejectsEntriesOutsideOfArchive(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"tar slip":         tarOf(t, entry{name: "../evil", contents: "x"}),
+		"absolute tar":     tarOf(t, entry{name: "/tmp/evil", contents: "x"}),
+		"zip slip":         zipOf(t, entry{name: "data/../../evil", contents: "x"}),
+		"symlink out":      tarOf(t, entry{name: "out", link: "../.."}),
+		"absolute symlink": tarOf(t, entry{name: "out", link: "/etc"}),
+		"symlink through symlink": tarOf(t,
+			entry{name: "here", link: "."},
+			entry{name: "up", link: "here/.."},
+			entry{name: "up/escaped", link: "."},
+		),
+		"symlink in symlinked dir": tarOf(t,
+			entry{name: "d", link: "."},
+			entry{name: "d/evil", link: "../evil"},
+		),
+		"target through symlink": tarOf(t,
+			entry{name: "here", link: "."},
+			entry{name: "evil", link: "here/.."},
+		),
+	} {
+		t.Run(name, func(t *testing.T) {
+			dst, err := extractBytes(t, data, Options{})
+			require.ErrorContains(t, err, "outside of the archive")
+			require.NoFileExists(t, filepath.Join(filepath.Dir(dst), "evil"))
+			require.NoFileExists(t, filepath.Join(filepath.Dir(dst), "escaped"))
+		})
+	}
+}
+
+func TestLimitsExtraction(t *testing.T) {
+	_, err := extractBytes(t, tarOf(t, entry{name: "big", contents: "0123456789"}), Options{MaxSize: 5})
+	require.ErrorContains(t, err, "more than")
+
+	_, err = extractBytes(t, zipOf(t, entry{name: "a"}, entry{name: "b"}, entry{name: "c"}), Options{MaxFiles: 2})
+	require.ErrorContains(t, err, "more than 2 entries")
+
+	_, err = extractBytes(t, []byte("just some text"), Options{})
+	require.ErrorContains(t, err, "is not a tar")
+}
+
+// downloadingStorage prepares a volume of a directory with the archive in it.
+type downloadingStorage struct {
+	archive []byte
+	dir     string
+	cleaned []storage.StorageVolume
+}
+
+func (s *downloadingStorage) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (s *downloadingStorage) HasStorageLocally(context.Context, model.StorageSpec) (bool, error) {
+	return false, nil
+}
+
+func (s *downloadingStorage) GetVolumeSize(context.Context, model.StorageSpec) (uint64, error) {
+	return uint64(len(s.archive)), nil
+}
+
+func (s *downloadingStorage) PrepareStorage(_ context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+	path := filepath.Join(s.dir, "data.tar.gz")
+	if err := os.WriteFile(path, s.archive, 0644); err != nil {
+		return storage.StorageVolume{}, err
+	}
+	return storage.StorageVolume{Type: storage.StorageVolumeConnectorBind, Source: s.dir, Target: spec.Path}, nil
+}
+
+func (s *downloadingStorage) CleanupStorage(_ context.Context, _ model.StorageSpec, volume storage.StorageVolume) error {
+	s.cleaned = append(s.cleaned, volume)
+	return nil
+}
+
+func (s *downloadingStorage) Upload(context.Context, string) (model.StorageSpec, error) {
+	return model.StorageSpec{}, nil
+}
+
+func (s *downloadingStorage) HealthCheck(context.Context) error {
+	return nil
+}
+
+func TestExtractingStorage(t *testing.T) {
+	ctx := context.Background()
+	delegate := &downloadingStorage{archive: gzipOf(t, tarOf(t, entry{name: "a.csv", contents: "1,2"})), dir: t.TempDir()}
+	provider := newExtractor(t.TempDir(), Options{}).Wrap(model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{
+		model.StorageSourceURLDownload: delegate,
+	}))
+	s, err := provider.Get(ctx, model.StorageSourceURLDownload)
+	require.NoError(t, err)
+
+	spec := Request(model.StorageSpec{StorageSource: model.StorageSourceURLDownload, Path: "/inputs"})
+	require.True(t, IsRequested(spec))
+	volume, err := s.PrepareStorage(ctx, spec)
+	require.NoError(t, err)
+	require.Equal(t, "/inputs", volume.Target)
+	requireFile(t, filepath.Join(volume.Source, "a.csv"), "1,2")
+
+	require.NoError(t, s.CleanupStorage(ctx, spec, volume))
+	require.NoDirExists(t, volume.Source)
+	require.Equal(t, delegate.dir, delegate.cleaned[0].Source, "the archive is cleaned up by its storage")
+
+	// inputs that don't ask to be extracted are left as they are
+	spec.Metadata = nil
+	volume, err = s.PrepareStorage(ctx, spec)
+	require.NoError(t, err)
+	require.Equal(t, delegate.dir, volume.Source)
+}
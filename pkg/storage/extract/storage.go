@@ -0,0 +1,58 @@
+package extract
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"go.uber.org/multierr"
+)
+
+type extractingProvider struct {
+	delegate  storage.StorageProvider
+	extractor *Extractor
+}
+
+func (p *extractingProvider) Get(ctx context.Context, sourceType model.StorageSourceType) (storage.Storage, error) {
+	s, err := p.delegate.Get(ctx, sourceType)
+	if err != nil {
+		return nil, err
+	}
+	return &extractingStorage{Storage: s, extractor: p.extractor}, nil
+}
+
+func (p *extractingProvider) Has(ctx context.Context, sourceType model.StorageSourceType) bool {
+	return p.delegate.Has(ctx, sourceType)
+}
+
+// extractingStorage extracts the archives of the inputs that ask for it, and
+// cleans up the archive along with what it extracted to. The size of an input
+// is that of its archive, as what it extracts to isn't known until then.
+type extractingStorage struct {
+	storage.Storage
+	extractor *Extractor
+}
+
+func (s *extractingStorage) PrepareStorage(ctx context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+	volume, err := s.Storage.PrepareStorage(ctx, spec)
+	if err != nil || !IsRequested(spec) {
+		return volume, err
+	}
+	extracted, err := s.extractor.extract(ctx, spec, volume)
+	if err != nil {
+		return storage.StorageVolume{}, multierr.Combine(err, s.Storage.CleanupStorage(ctx, spec, volume))
+	}
+	return extracted, nil
+}
+
+func (s *extractingStorage) CleanupStorage(ctx context.Context, spec model.StorageSpec, volume storage.StorageVolume) error {
+	archive, extracted, err := s.extractor.release(volume)
+	if !extracted {
+		return s.Storage.CleanupStorage(ctx, spec, volume)
+	}
+	return multierr.Combine(err, s.Storage.CleanupStorage(ctx, spec, archive))
+}
+
+// Compile time interface check:
+var _ storage.StorageProvider = (*extractingProvider)(nil)
+var _ storage.Storage = (*extractingStorage)(nil)
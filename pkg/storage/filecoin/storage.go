@@ -0,0 +1,307 @@
+// Package filecoin provides a storage source that retrieves inputs from the
+// storage deals that miners have made for them on Filecoin, through the Lotus
+// node of the compute node, so that sealed data can be used by jobs without
+// first retrieving it by hand.
+//
+// Inputs are given by the CID of their payload as filecoin://cid URLs, which
+// can name the miner to retrieve them from as filecoin://cid?miner=f01234.
+// Miners running Boost are retrieved from just the same, as Lotus retrieves
+// from them over graphsync.
+package filecoin
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/filecoin_lotus/api"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/filecoin-project/go-address"
+	big2 "github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// URLScheme marks the URL of an input as that of data stored on Filecoin.
+	URLScheme = "filecoin"
+
+	// MinerMetadataKey is the metadata of the storage spec with the address of
+	// the miner to retrieve the input from. Any miner with a deal for it is
+	// retrieved from if it isn't set.
+	MinerMetadataKey = "Miner"
+)
+
+// StorageOptions configures how the compute node retrieves from Filecoin.
+type StorageOptions struct {
+	// PathDir is the configuration directory of the Lotus node to retrieve
+	// with. Nothing is retrieved if it isn't set.
+	PathDir string
+	// ExportDir is where Lotus writes the inputs that it retrieves, which must
+	// be at the same path for both Lotus and the compute node. It defaults to
+	// the storage path of the compute node.
+	ExportDir string
+	// MaxPrice is the most that is paid to retrieve an input, in attoFIL, so
+	// only free retrievals are made by default.
+	MaxPrice big2.Int
+}
+
+// StorageProvider retrieves inputs with Lotus into a directory of its own.
+type StorageProvider struct {
+	localDir  string
+	options   StorageOptions
+	newClient func(context.Context) (api.Client, error)
+
+	clientOnce sync.Once
+	client     api.Client
+	clientErr  error
+}
+
+func NewStorage(cm *system.CleanupManager, options StorageOptions) (*StorageProvider, error) {
+	parent := options.ExportDir
+	if parent == "" {
+		parent = config.GetStoragePath()
+	}
+	dir, err := os.MkdirTemp(parent, "bacalhau-filecoin")
+	if err != nil {
+		return nil, err
+	}
+
+	sp := newStorage(dir, options, func(ctx context.Context) (api.Client, error) {
+		return api.NewClientFromConfigDir(ctx, options.PathDir)
+	})
+	cm.RegisterCallback(func() error {
+		sp.close()
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("unable to remove storage folder: %w", err)
+		}
+		return nil
+	})
+
+	log.Debug().Str("dir", dir).Msg("Filecoin driver created with output dir")
+
+	return sp, nil
+}
+
+func newStorage(dir string, options StorageOptions, newClient func(context.Context) (api.Client, error)) *StorageProvider {
+	if options.MaxPrice.Int == nil {
+		options.MaxPrice = big2.Zero()
+	}
+	return &StorageProvider{
+		localDir:  dir,
+		options:   options,
+		newClient: newClient,
+	}
+}
+
+// IsInstalled returns whether the compute node has a Lotus node to retrieve
+// with, and it can be reached.
+func (sp *StorageProvider) IsInstalled(ctx context.Context) (bool, error) {
+	if sp.options.PathDir == "" {
+		return false, nil
+	}
+	client, err := sp.getClient(ctx)
+	if err != nil {
+		return false, err
+	}
+	if _, err := client.Version(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (sp *StorageProvider) HasStorageLocally(context.Context, model.StorageSpec) (bool, error) {
+	return false, nil
+}
+
+// GetVolumeSize returns the size of the input as offered by the miner that it
+// would be retrieved from.
+func (sp *StorageProvider) GetVolumeSize(ctx context.Context, storageSpec model.StorageSpec) (uint64, error) {
+	offer, err := sp.findOffer(ctx, storageSpec)
+	if err != nil {
+		return 0, err
+	}
+	return offer.Size, nil
+}
+
+// PrepareStorage retrieves the input from the miner with the cheapest offer
+// for it, and has Lotus export it into a directory of the input's own.
+func (sp *StorageProvider) PrepareStorage(ctx context.Context, storageSpec model.StorageSpec) (storage.StorageVolume, error) {
+	client, err := sp.getClient(ctx)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	offer, err := sp.findOffer(ctx, storageSpec)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	wallet, err := client.WalletDefaultAddress(ctx)
+	if err != nil {
+		return storage.StorageVolume{}, fmt.Errorf("failed to find wallet to retrieve with: %w", err)
+	}
+
+	log.Ctx(ctx).Debug().
+		Stringer("cid", offer.Root).
+		Stringer("miner", offer.Miner).
+		Uint64("size", offer.Size).
+		Msg("Retrieving from Filecoin")
+
+	retrieval, err := client.ClientRetrieve(ctx, offer.Order(wallet))
+	if err != nil {
+		return storage.StorageVolume{}, fmt.Errorf("failed to retrieve %s from %s: %w", offer.Root, offer.Miner, err)
+	}
+	if err := client.ClientRetrieveWait(ctx, retrieval.DealID); err != nil {
+		return storage.StorageVolume{}, fmt.Errorf("failed to retrieve %s from %s: %w", offer.Root, offer.Miner, err)
+	}
+
+	dir, err := os.MkdirTemp(sp.localDir, "*")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	exportPath := filepath.Join(dir, offer.Root.String())
+	err = client.ClientExport(ctx, api.ExportRef{Root: offer.Root, DealID: retrieval.DealID}, api.FileRef{Path: exportPath})
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return storage.StorageVolume{}, fmt.Errorf("failed to export %s: %w", offer.Root, err)
+	}
+
+	return storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: exportPath,
+		Target: storageSpec.Path,
+	}, nil
+}
+
+func (sp *StorageProvider) CleanupStorage(ctx context.Context, _ model.StorageSpec, volume storage.StorageVolume) error {
+	log.Ctx(ctx).Debug().Str("source", volume.Source).Msg("Cleaning up")
+	return os.RemoveAll(filepath.Dir(volume.Source))
+}
+
+func (sp *StorageProvider) Upload(context.Context, string) (model.StorageSpec, error) {
+	return model.StorageSpec{}, fmt.Errorf("not implemented")
+}
+
+// HealthCheck returns an error if the Lotus node can't be reached.
+func (sp *StorageProvider) HealthCheck(ctx context.Context) error {
+	client, err := sp.getClient(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = client.Version(ctx)
+	return err
+}
+
+// getClient returns the Lotus client of the compute node, which is only
+// connected once an input needs it.
+func (sp *StorageProvider) getClient(ctx context.Context) (api.Client, error) {
+	sp.clientOnce.Do(func() {
+		if sp.options.PathDir == "" {
+			sp.clientErr = fmt.Errorf("no Lotus node is configured")
+			return
+		}
+		// the connection outlives the input that first needs it
+		sp.client, sp.clientErr = sp.newClient(log.Ctx(ctx).WithContext(context.Background()))
+	})
+	if sp.clientErr != nil {
+		return nil, fmt.Errorf("failed to connect to Lotus: %w", sp.clientErr)
+	}
+	return sp.client, nil
+}
+
+func (sp *StorageProvider) close() {
+	if sp.client != nil {
+		_ = sp.client.Close()
+	}
+}
+
+// findOffer returns the cheapest offer to retrieve the input, from the miner
+// of the storage spec if it has one, that costs no more than the maximum price.
+func (sp *StorageProvider) findOffer(ctx context.Context, storageSpec model.StorageSpec) (*api.QueryOffer, error) {
+	root, err := cid.Decode(storageSpec.CID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CID %q: %w", storageSpec.CID, err)
+	}
+	var miner address.Address
+	if m := storageSpec.Metadata[MinerMetadataKey]; m != "" {
+		if miner, err = address.NewFromString(m); err != nil {
+			return nil, fmt.Errorf("invalid miner %q: %w", m, err)
+		}
+	}
+
+	client, err := sp.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	offers, err := client.ClientFindData(ctx, root, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find offers to retrieve %s: %w", root, err)
+	}
+
+	var best *api.QueryOffer
+	for i := range offers {
+		offer := &offers[i]
+		if offer.Err != "" || (miner != address.Undef && offer.Miner != miner) {
+			continue
+		}
+		if big2.Cmp(price(offer), sp.options.MaxPrice) > 0 {
+			continue
+		}
+		if best == nil || big2.Cmp(price(offer), price(best)) < 0 {
+			best = offer
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no miner offers to retrieve %s for at most %s attoFIL", root, sp.options.MaxPrice)
+	}
+	return best, nil
+}
+
+// price returns the total price of the offer, including unsealing.
+func price(offer *api.QueryOffer) big2.Int {
+	total := big2.Zero()
+	for _, p := range []big2.Int{offer.MinPrice, offer.UnsealPrice} {
+		if p.Int != nil {
+			total = big2.Add(total, p)
+		}
+	}
+	return total
+}
+
+// ParseURL returns the storage spec of a filecoin://cid URL.
+func ParseURL(rawURL string) (model.StorageSpec, error) {
+	rawURL = strings.Trim(rawURL, " '\"")
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return model.StorageSpec{}, fmt.Errorf("invalid URL: %s", err)
+	}
+	if u.Scheme != URLScheme {
+		return model.StorageSpec{}, fmt.Errorf("filecoin URLs must begin with %q", URLScheme+"://")
+	}
+	if _, err := cid.Decode(u.Host); err != nil {
+		return model.StorageSpec{}, fmt.Errorf("filecoin URL %s must name a CID: %w", rawURL, err)
+	}
+
+	spec := model.StorageSpec{
+		StorageSource: model.StorageSourceFilecoin,
+		CID:           u.Host,
+		URL:           rawURL,
+		Path:          "/inputs",
+	}
+	if miner := u.Query().Get("miner"); miner != "" {
+		if _, err := address.NewFromString(miner); err != nil {
+			return model.StorageSpec{}, fmt.Errorf("invalid miner %q: %w", miner, err)
+		}
+		spec.Metadata = map[string]string{MinerMetadataKey: miner}
+	}
+	return spec, nil
+}
+
+// Compile time interface check:
+var _ storage.Storage = (*StorageProvider)(nil)
@@ -0,0 +1,144 @@
+//go:build unit || !integration
+
+package filecoin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/filecoin_lotus/api"
+	"github.com/bacalhau-project/bacalhau/pkg/publisher/filecoin_lotus/api/retrievalmarket"
+	"github.com/filecoin-project/go-address"
+	big2 "github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+)
+
+const testCID = "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"
+
+// fakeLotus offers data from the miners it is given, and exports it as a file
+// of the data's CID.
+type fakeLotus struct {
+	api.Client
+	offers    []api.QueryOffer
+	retrieved []api.RetrievalOrder
+}
+
+func (f *fakeLotus) ClientFindData(context.Context, cid.Cid, *cid.Cid) ([]api.QueryOffer, error) {
+	return f.offers, nil
+}
+
+func (f *fakeLotus) WalletDefaultAddress(context.Context) (address.Address, error) {
+	return address.NewIDAddress(100)
+}
+
+func (f *fakeLotus) ClientRetrieve(_ context.Context, order api.RetrievalOrder) (*api.RestrievalRes, error) {
+	f.retrieved = append(f.retrieved, order)
+	return &api.RestrievalRes{DealID: retrievalmarket.DealID(len(f.retrieved))}, nil
+}
+
+func (f *fakeLotus) ClientRetrieveWait(context.Context, retrievalmarket.DealID) error {
+	return nil
+}
+
+func (f *fakeLotus) ClientExport(_ context.Context, ref api.ExportRef, file api.FileRef) error {
+	return os.WriteFile(file.Path, []byte(ref.Root.String()), 0644)
+}
+
+func (f *fakeLotus) Close() error {
+	return nil
+}
+
+func offer(t *testing.T, miner uint64, price int64) api.QueryOffer {
+	root, err := cid.Decode(testCID)
+	require.NoError(t, err)
+	addr, err := address.NewIDAddress(miner)
+	require.NoError(t, err)
+	return api.QueryOffer{Root: root, Size: 1024 * miner, MinPrice: big2.NewInt(price), Miner: addr}
+}
+
+func newTestStorage(t *testing.T, lotus *fakeLotus, options StorageOptions) *StorageProvider {
+	options.PathDir = "/lotus"
+	return newStorage(t.TempDir(), options, func(context.Context) (api.Client, error) {
+		return lotus, nil
+	})
+}
+
+func TestParseURL(t *testing.T) {
+	spec, err := ParseURL("'filecoin://" + testCID + "?miner=f01234'")
+	require.NoError(t, err)
+	require.Equal(t, model.StorageSpec{
+		StorageSource: model.StorageSourceFilecoin,
+		CID:           testCID,
+		URL:           "filecoin://" + testCID + "?miner=f01234",
+		Path:          "/inputs",
+		Metadata:      map[string]string{MinerMetadataKey: "f01234"},
+	}, spec)
+
+	_, err = ParseURL("ipfs://" + testCID)
+	require.Error(t, err)
+	_, err = ParseURL("filecoin://not-a-cid")
+	require.Error(t, err)
+	_, err = ParseURL("filecoin://" + testCID + "?miner=nobody")
+	require.Error(t, err)
+}
+
+func TestRetrievesCheapestOffer(t *testing.T) {
+	ctx := context.Background()
+	expensive := offer(t, 3, 100)
+	failed := offer(t, 4, 0)
+	failed.Err = "no deal"
+	lotus := &fakeLotus{offers: []api.QueryOffer{expensive, offer(t, 1, 10), offer(t, 2, 5), failed}}
+	sp := newTestStorage(t, lotus, StorageOptions{MaxPrice: big2.NewInt(50)})
+	spec := model.StorageSpec{StorageSource: model.StorageSourceFilecoin, CID: testCID, Path: "/inputs"}
+
+	size, err := sp.GetVolumeSize(ctx, spec)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2048), size)
+
+	volume, err := sp.PrepareStorage(ctx, spec)
+	require.NoError(t, err)
+	require.Equal(t, "/inputs", volume.Target)
+	require.Len(t, lotus.retrieved, 1)
+	require.Equal(t, lotus.offers[2].Miner, lotus.retrieved[0].Miner)
+	contents, err := os.ReadFile(volume.Source)
+	require.NoError(t, err)
+	require.Equal(t, testCID, string(contents))
+
+	require.NoError(t, sp.CleanupStorage(ctx, spec, volume))
+	require.NoDirExists(t, filepath.Dir(volume.Source))
+}
+
+func TestRetrievesFromMiner(t *testing.T) {
+	ctx := context.Background()
+	lotus := &fakeLotus{offers: []api.QueryOffer{offer(t, 1, 0), offer(t, 2, 0)}}
+	sp := newTestStorage(t, lotus, StorageOptions{})
+	spec := model.StorageSpec{
+		StorageSource: model.StorageSourceFilecoin,
+		CID:           testCID,
+		Path:          "/inputs",
+		Metadata:      map[string]string{MinerMetadataKey: "t02"},
+	}
+
+	_, err := sp.PrepareStorage(ctx, spec)
+	require.NoError(t, err)
+	require.Equal(t, lotus.offers[1].Miner, lotus.retrieved[0].Miner)
+}
+
+func TestOnlyRetrievesForFreeByDefault(t *testing.T) {
+	lotus := &fakeLotus{offers: []api.QueryOffer{offer(t, 1, 10)}}
+	sp := newTestStorage(t, lotus, StorageOptions{})
+
+	_, err := sp.GetVolumeSize(context.Background(), model.StorageSpec{StorageSource: model.StorageSourceFilecoin, CID: testCID})
+	require.ErrorContains(t, err, "no miner offers")
+}
+
+func TestNotInstalledWithoutLotus(t *testing.T) {
+	sp := newStorage(t.TempDir(), StorageOptions{}, nil)
+	installed, err := sp.IsInstalled(context.Background())
+	require.NoError(t, err)
+	require.False(t, installed)
+}
@@ -80,6 +80,11 @@ func (driver *StorageProvider) Upload(context.Context, string) (model.StorageSpe
 	return model.StorageSpec{}, fmt.Errorf("not implemented")
 }
 
+// HealthCheck is always healthy, as inputs are read from the local filesystem.
+func (driver *StorageProvider) HealthCheck(context.Context) error {
+	return nil
+}
+
 func (driver *StorageProvider) getPathToVolume(volume model.StorageSpec) (string, error) {
 	var buffer bytes.Buffer
 	err := driver.localPathTemplate.Execute(&buffer, volume)
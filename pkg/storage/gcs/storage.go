@@ -0,0 +1,288 @@
+package gcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	gcstorage "cloud.google.com/go/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// DefaultDownloadConcurrency is how many objects are downloaded at once if no
+// other number is configured.
+const DefaultDownloadConcurrency = 8
+
+// StorageOptions configures how the compute node connects to Google Cloud
+// Storage.
+type StorageOptions struct {
+	// CredentialsFile is the JSON key of a service account, or the
+	// configuration of workload identity federation, to download objects as.
+	// Application default credentials are used if not set, which include the
+	// service account of the instance and workload identity on GKE.
+	CredentialsFile string
+	// DownloadConcurrency is how many objects of an input are downloaded at once
+	DownloadConcurrency int
+}
+
+// StorageProvider downloads an object, or the objects under a prefix, of a
+// Google Cloud Storage bucket to a local directory in preparation for a job to
+// run, and removes them once complete.
+type StorageProvider struct {
+	localDir string
+	options  StorageOptions
+
+	clientOnce sync.Once
+	client     *gcstorage.Client
+	clientErr  error
+}
+
+func NewStorage(cm *system.CleanupManager, options StorageOptions) (*StorageProvider, error) {
+	dir, err := os.MkdirTemp(config.GetStoragePath(), "bacalhau-gcs")
+	if err != nil {
+		return nil, err
+	}
+
+	sp := newStorage(dir, options)
+	cm.RegisterCallback(func() error {
+		if sp.client != nil {
+			if err := sp.client.Close(); err != nil {
+				return fmt.Errorf("unable to close GCS client: %w", err)
+			}
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("unable to remove storage folder: %w", err)
+		}
+		return nil
+	})
+
+	log.Debug().Str("dir", dir).Msg("GCS driver created with output dir")
+
+	return sp, nil
+}
+
+func newStorage(dir string, options StorageOptions) *StorageProvider {
+	if options.DownloadConcurrency <= 0 {
+		options.DownloadConcurrency = DefaultDownloadConcurrency
+	}
+	return &StorageProvider{
+		localDir: dir,
+		options:  options,
+	}
+}
+
+func (sp *StorageProvider) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (sp *StorageProvider) HasStorageLocally(context.Context, model.StorageSpec) (bool, error) {
+	return false, nil
+}
+
+// GetVolumeSize returns the size of the object, or the total size of the
+// objects under the prefix.
+func (sp *StorageProvider) GetVolumeSize(ctx context.Context, storageSpec model.StorageSpec) (uint64, error) {
+	objects, err := sp.listObjects(ctx, storageSpec)
+	if err != nil {
+		return 0, err
+	}
+
+	var size uint64
+	for _, object := range objects {
+		size += uint64(object.Size)
+	}
+	return size, nil
+}
+
+// PrepareStorage downloads the object, or the objects under the prefix, to a
+// local directory, keeping their names relative to the last "/" of the URL as
+// their paths.
+func (sp *StorageProvider) PrepareStorage(ctx context.Context, storageSpec model.StorageSpec) (storage.StorageVolume, error) {
+	bucket, prefix, err := ParseURL(storageSpec.URL)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	objects, err := sp.listObjects(ctx, storageSpec)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	if len(objects) == 0 {
+		return storage.StorageVolume{}, fmt.Errorf("no objects found at %s", storageSpec.URL)
+	}
+
+	client, err := sp.getClient()
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	outputPath, err := os.MkdirTemp(sp.localDir, "*")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(sp.options.DownloadConcurrency)
+	for _, object := range objects {
+		handle := client.Bucket(bucket).Object(object.Name)
+		filePath, err := util.ObjectPath(outputPath, prefix, object.Name)
+		if err != nil {
+			return storage.StorageVolume{}, err
+		}
+		group.Go(func() error {
+			return downloadObject(groupCtx, handle, filePath)
+		})
+	}
+	if err = group.Wait(); err != nil {
+		return storage.StorageVolume{}, fmt.Errorf("failed to download from %s: %w", storageSpec.URL, err)
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("url", storageSpec.URL).
+		Int("objects", len(objects)).
+		Str("dir", outputPath).
+		Msg("Downloaded objects")
+
+	return storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: outputPath,
+		Target: storageSpec.Path,
+	}, nil
+}
+
+func (sp *StorageProvider) CleanupStorage(
+	ctx context.Context,
+	_ model.StorageSpec,
+	volume storage.StorageVolume,
+) error {
+	log.Ctx(ctx).Debug().Str("Path", volume.Source).Msg("Cleaning up")
+	return os.RemoveAll(volume.Source)
+}
+
+func (sp *StorageProvider) Upload(context.Context, string) (model.StorageSpec, error) {
+	return model.StorageSpec{}, fmt.Errorf("not implemented")
+}
+
+// HealthCheck is always healthy, as each input is read from a bucket of its
+// own.
+func (sp *StorageProvider) HealthCheck(context.Context) error {
+	return nil
+}
+
+func (sp *StorageProvider) Explode(_ context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+	return []model.StorageSpec{
+		spec,
+	}, nil
+}
+
+// getClient returns the client of the compute node, which is only created once
+// an input needs it so that nodes without credentials can still start.
+func (sp *StorageProvider) getClient() (*gcstorage.Client, error) {
+	sp.clientOnce.Do(func() {
+		var opts []option.ClientOption
+		if sp.options.CredentialsFile != "" {
+			opts = append(opts, option.WithCredentialsFile(sp.options.CredentialsFile))
+		}
+		// the client outlives the context of the first input that needs it
+		sp.client, sp.clientErr = gcstorage.NewClient(context.Background(), opts...)
+	})
+	if sp.clientErr != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", sp.clientErr)
+	}
+	return sp.client, nil
+}
+
+// listObjects returns the object that the URL names, if there is one, and
+// every object under the prefix of the URL otherwise. The empty objects that
+// some tools create to stand for directories are left out.
+func (sp *StorageProvider) listObjects(ctx context.Context, storageSpec model.StorageSpec) ([]*gcstorage.ObjectAttrs, error) {
+	bucket, prefix, err := ParseURL(storageSpec.URL)
+	if err != nil {
+		return nil, err
+	}
+	client, err := sp.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	query := &gcstorage.Query{Prefix: prefix}
+	if err = query.SetAttrSelection([]string{"Name", "Size"}); err != nil {
+		return nil, err
+	}
+
+	var objects []*gcstorage.ObjectAttrs
+	it := client.Bucket(bucket).Objects(ctx, query)
+	for {
+		object, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects at %s: %w", storageSpec.URL, err)
+		}
+		if strings.HasSuffix(object.Name, "/") {
+			continue
+		}
+		if object.Name == prefix {
+			return []*gcstorage.ObjectAttrs{object}, nil
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+func downloadObject(ctx context.Context, handle *gcstorage.ObjectHandle, filePath string) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), util.OS_USER_RWX); err != nil {
+		return err
+	}
+
+	r, err := handle.NewReader(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", handle.ObjectName(), err)
+	}
+	defer closer.CloseWithLogOnError("object", r)
+
+	w, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filePath, err)
+	}
+	defer closer.CloseWithLogOnError("file", w)
+
+	if _, err = io.Copy(w, r); err != nil {
+		return fmt.Errorf("failed to download %s: %w", handle.ObjectName(), err)
+	}
+	return w.Sync()
+}
+
+// ParseURL returns the bucket and the object name or prefix of a
+// gs://bucket/path URL.
+func ParseURL(rawURL string) (bucket, prefix string, err error) {
+	u, err := url.Parse(strings.Trim(rawURL, " '\""))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid URL: %s", err)
+	}
+	if u.Scheme != "gs" {
+		return "", "", fmt.Errorf("GCS URLs must begin with 'gs'. The submitted one began with %s", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", "", fmt.Errorf("GCS URL %s has no bucket", rawURL)
+	}
+	return u.Host, strings.TrimPrefix(u.Path, "/"), nil
+}
+
+// Compile time interface check:
+var _ storage.Storage = (*StorageProvider)(nil)
@@ -0,0 +1,104 @@
+//go:build unit || !integration
+
+package gcs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURL(t *testing.T) {
+	bucket, prefix, err := ParseURL("gs://bucket/data/2023/")
+	require.NoError(t, err)
+	require.Equal(t, "bucket", bucket)
+	require.Equal(t, "data/2023/", prefix)
+
+	bucket, prefix, err = ParseURL("'gs://bucket'")
+	require.NoError(t, err)
+	require.Equal(t, "bucket", bucket)
+	require.Empty(t, prefix)
+
+	_, _, err = ParseURL("s3://bucket/data")
+	require.Error(t, err)
+	_, _, err = ParseURL("gs:///data")
+	require.Error(t, err)
+}
+
+func TestPrepareStorage(t *testing.T) {
+	objects := map[string]string{
+		"data/2023/a.csv":     "1,2",
+		"data/2023/sub/b.csv": "3,4",
+		"data/2023/sub/":      "",
+		"data/2024/c.csv":     "5,6",
+	}
+
+	// serve the objects as a storage emulator would
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/storage/v1/b/bucket/o" {
+			prefix := r.URL.Query().Get("prefix")
+			var items []map[string]string
+			for name, contents := range objects {
+				if strings.HasPrefix(name, prefix) {
+					items = append(items, map[string]string{"name": name, "size": fmt.Sprint(len(contents))})
+				}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"kind": "storage#objects", "items": items})
+			return
+		}
+		contents, ok := objects[strings.TrimPrefix(r.URL.Path, "/bucket/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(contents)))
+		_, _ = w.Write([]byte(contents))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("STORAGE_EMULATOR_HOST", server.URL)
+
+	sp := newStorage(t.TempDir(), StorageOptions{})
+	for _, test := range []struct {
+		name  string
+		url   string
+		size  uint64
+		files map[string]string
+	}{
+		{name: "prefix", url: "gs://bucket/data/2023/", size: 6, files: map[string]string{"a.csv": "1,2", "sub/b.csv": "3,4"}},
+		{name: "object", url: "gs://bucket/data/2023/a.csv", size: 3, files: map[string]string{"a.csv": "1,2"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			spec := model.StorageSpec{StorageSource: model.StorageSourceGCS, URL: test.url, Path: "/inputs"}
+
+			size, err := sp.GetVolumeSize(context.Background(), spec)
+			require.NoError(t, err)
+			require.Equal(t, test.size, size)
+
+			volume, err := sp.PrepareStorage(context.Background(), spec)
+			require.NoError(t, err)
+			require.Equal(t, "/inputs", volume.Target)
+			for name, contents := range test.files {
+				actual, err := os.ReadFile(filepath.Join(volume.Source, name))
+				require.NoError(t, err)
+				require.Equal(t, contents, string(actual))
+			}
+			require.NoFileExists(t, filepath.Join(volume.Source, "c.csv"))
+
+			require.NoError(t, sp.CleanupStorage(context.Background(), spec, volume))
+			require.NoDirExists(t, volume.Source)
+		})
+	}
+
+	_, err := sp.PrepareStorage(context.Background(), model.StorageSpec{URL: "gs://bucket/missing/"})
+	require.Error(t, err)
+}
@@ -0,0 +1,265 @@
+// Package git provides a storage source that clones a git repository into the
+// input mount of a job, so that jobs needing code and data from a repository
+// can reference it directly rather than it first being packed into IPFS.
+//
+// The repository is given as the URL of the storage spec. A branch, tag or
+// commit to check out can be given in its metadata, as can paths to check out
+// sparsely when only part of a large repository is needed. Repositories are
+// cloned with the git command of the compute node, so private repositories can
+// be cloned with whichever credentials it is configured with.
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// RefMetadataKey is the metadata of the storage spec with the branch, tag
+	// or commit to check out. The default branch is checked out if not set.
+	RefMetadataKey = "Ref"
+	// PathsMetadataKey is the metadata of the storage spec with a comma
+	// separated list of paths to check out, leaving out the rest of the tree.
+	PathsMetadataKey = "Paths"
+
+	// URLPrefix marks the URL of an input as that of a git repository.
+	URLPrefix = "git+"
+)
+
+// the URL schemes of repositories that can be cloned, which leave out file
+// URLs so that jobs can't clone repositories from the compute node itself
+var allowedSchemes = map[string]bool{
+	"https": true,
+	"http":  true,
+	"ssh":   true,
+	"git":   true,
+}
+
+type StorageProvider struct {
+	localDir string
+}
+
+func NewStorage(cm *system.CleanupManager) (*StorageProvider, error) {
+	dir, err := os.MkdirTemp(config.GetStoragePath(), "bacalhau-git")
+	if err != nil {
+		return nil, err
+	}
+
+	cm.RegisterCallback(func() error {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("unable to remove storage folder: %w", err)
+		}
+		return nil
+	})
+
+	log.Debug().Str("dir", dir).Msg("Git driver created with output dir")
+
+	return &StorageProvider{localDir: dir}, nil
+}
+
+// IsInstalled checks if git is on the PATH of the compute node.
+func (sp *StorageProvider) IsInstalled(context.Context) (bool, error) {
+	_, err := exec.LookPath("git")
+	return err == nil, nil
+}
+
+func (sp *StorageProvider) HasStorageLocally(context.Context, model.StorageSpec) (bool, error) {
+	return false, nil
+}
+
+func (sp *StorageProvider) GetVolumeSize(context.Context, model.StorageSpec) (uint64, error) {
+	// the size of a repository isn't known until it is cloned
+	return 0, nil
+}
+
+// PrepareStorage clones the repository at the ref of the storage spec.
+func (sp *StorageProvider) PrepareStorage(ctx context.Context, storageSpec model.StorageSpec) (storage.StorageVolume, error) {
+	if err := IsURLSupported(storageSpec.URL); err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	ref := storageSpec.Metadata[RefMetadataKey]
+	if err := validateRef(ctx, ref); err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	outputPath, err := os.MkdirTemp(sp.localDir, "*")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	if err = clone(ctx, storageSpec.URL, ref, sparsePaths(storageSpec), outputPath); err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("url", storageSpec.URL).
+		Str("ref", ref).
+		Str("dir", outputPath).
+		Msg("Cloned repository")
+
+	return storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: outputPath,
+		Target: storageSpec.Path,
+	}, nil
+}
+
+func (sp *StorageProvider) CleanupStorage(
+	ctx context.Context,
+	_ model.StorageSpec,
+	volume storage.StorageVolume,
+) error {
+	log.Ctx(ctx).Debug().Str("Path", volume.Source).Msg("Cleaning up")
+	return os.RemoveAll(volume.Source)
+}
+
+func (sp *StorageProvider) Upload(context.Context, string) (model.StorageSpec, error) {
+	return model.StorageSpec{}, fmt.Errorf("not implemented")
+}
+
+// HealthCheck is always healthy, as each input is cloned from a remote of its
+// own.
+func (sp *StorageProvider) HealthCheck(context.Context) error {
+	return nil
+}
+
+func (sp *StorageProvider) Explode(_ context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+	return []model.StorageSpec{
+		spec,
+	}, nil
+}
+
+// clone checks out the ref of the repository into the directory. Only the ref
+// is fetched, without its history, unless the server won't serve a commit on
+// its own, in which case the whole repository is fetched.
+func clone(ctx context.Context, repoURL, ref string, paths []string, dir string) error {
+	if err := runGit(ctx, dir, "init", "--quiet"); err != nil {
+		return err
+	}
+	if err := runGit(ctx, dir, "remote", "add", "origin", repoURL); err != nil {
+		return err
+	}
+	if len(paths) > 0 {
+		args := append([]string{"sparse-checkout", "set", "--no-cone", "--"}, paths...)
+		if err := runGit(ctx, dir, args...); err != nil {
+			return err
+		}
+	}
+
+	fetchRef := ref
+	if fetchRef == "" {
+		fetchRef = "HEAD"
+	}
+	if err := runGit(ctx, dir, "fetch", "--quiet", "--depth", "1", "origin", "--end-of-options", fetchRef); err != nil {
+		if ref == "" {
+			return err
+		}
+		log.Ctx(ctx).Debug().Err(err).Str("ref", ref).Msg("Shallow fetch failed, fetching the whole repository")
+		if err = runGit(ctx, dir, "fetch", "--quiet", "--tags", "origin"); err != nil {
+			return err
+		}
+		return runGit(ctx, dir, "checkout", "--quiet", "--detach", "--end-of-options", ref)
+	}
+	return runGit(ctx, dir, "checkout", "--quiet", "--detach", "FETCH_HEAD")
+}
+
+// commitPattern matches the full or abbreviated hex names of commits.
+var commitPattern = regexp.MustCompile(`^[0-9a-fA-F]{4,64}$`)
+
+// validateRef returns an error unless the ref is empty, the name of a commit,
+// or a valid branch or tag name, so that it can't be taken for an option of
+// the git commands it is passed to.
+func validateRef(ctx context.Context, ref string) error {
+	if err := checkRefPrefix(ref); err != nil {
+		return err
+	}
+	if ref == "" || commitPattern.MatchString(ref) {
+		return nil
+	}
+	if err := exec.CommandContext(ctx, "git", "check-ref-format", "--allow-onelevel", ref).Run(); err != nil {
+		return fmt.Errorf("invalid git ref %q: %w", ref, err)
+	}
+	return nil
+}
+
+// checkRefPrefix returns an error if the ref would be taken for an option.
+func checkRefPrefix(ref string) error {
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid git ref %q: refs can't begin with '-'", ref)
+	}
+	return nil
+}
+
+func runGit(ctx context.Context, dir string, args ...string) error {
+	// submodules are never cloned, and file URLs can't be used to read
+	// repositories of the compute node through them
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-c", "protocol.file.allow=never"}, args...)...)
+	cmd.Dir = dir
+	// fail rather than wait for credentials that will never be typed
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// sparsePaths returns the paths of the repository to check out, if only some
+// of them are wanted.
+func sparsePaths(storageSpec model.StorageSpec) []string {
+	var paths []string
+	for _, p := range strings.Split(storageSpec.Metadata[PathsMetadataKey], ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// ParseURL returns the repository URL and ref of an input given as a URL with
+// the git+ prefix, such as git+https://github.com/org/repo.git#v1.0, where
+// the ref is optional.
+func ParseURL(rawURL string) (repoURL, ref string, err error) {
+	rawURL = strings.Trim(rawURL, " '\"")
+	if !strings.HasPrefix(rawURL, URLPrefix) {
+		return "", "", fmt.Errorf("git URLs must begin with %q", URLPrefix)
+	}
+	repoURL, ref, _ = strings.Cut(strings.TrimPrefix(rawURL, URLPrefix), "#")
+	if err = checkRefPrefix(ref); err != nil {
+		return "", "", err
+	}
+	return repoURL, ref, IsURLSupported(repoURL)
+}
+
+// IsURLSupported checks that the repository can be cloned by compute nodes.
+// The scp-like syntax of ssh, e.g. git@github.com:org/repo.git, isn't a URL,
+// so it must be given as ssh://git@github.com/org/repo.git instead.
+func IsURLSupported(repoURL string) error {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return fmt.Errorf("invalid repository URL: %s", err)
+	}
+	if !allowedSchemes[u.Scheme] {
+		return fmt.Errorf("repository URLs must begin with 'https', 'http', 'ssh' or 'git'. The submitted one began with %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("repository URL %s has no host", repoURL)
+	}
+	return nil
+}
+
+// Compile time interface check:
+var _ storage.Storage = (*StorageProvider)(nil)
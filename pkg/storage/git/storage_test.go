@@ -0,0 +1,136 @@
+//go:build (unit || !integration) && unix
+
+package git
+
+import (
+	"context"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURL(t *testing.T) {
+	repoURL, ref, err := ParseURL("git+https://github.com/org/repo.git#v1.0")
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/org/repo.git", repoURL)
+	require.Equal(t, "v1.0", ref)
+
+	repoURL, ref, err = ParseURL("'git+ssh://git@github.com/org/repo.git'")
+	require.NoError(t, err)
+	require.Equal(t, "ssh://git@github.com/org/repo.git", repoURL)
+	require.Empty(t, ref)
+
+	_, _, err = ParseURL("https://github.com/org/repo.git")
+	require.Error(t, err)
+	_, _, err = ParseURL("git+file:///etc")
+	require.Error(t, err)
+	_, _, err = ParseURL("git+https://github.com/org/repo.git#--upload-pack=touch /tmp/pwned")
+	require.Error(t, err, "the ref would be taken for an option")
+}
+
+func TestPrepareStorage(t *testing.T) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	work := filepath.Join(root, "work")
+	require.NoError(t, os.Mkdir(work, 0755))
+	commit := func(files map[string]string) string {
+		for name, contents := range files {
+			require.NoError(t, os.MkdirAll(filepath.Join(work, filepath.Dir(name)), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(work, name), []byte(contents), 0644))
+		}
+		gitCommand(t, work, "add", "-A")
+		gitCommand(t, work, "commit", "--quiet", "-m", "commit")
+		return gitCommand(t, work, "rev-parse", "HEAD")
+	}
+	gitCommand(t, work, "init", "--quiet", "--initial-branch", "main")
+	first := commit(map[string]string{"code/run.py": "v1", "data/big.csv": "1,2"})
+	gitCommand(t, work, "tag", "v1")
+	commit(map[string]string{"code/run.py": "v2"})
+	gitCommand(t, root, "clone", "--quiet", "--bare", work, filepath.Join(root, "repo.git"))
+
+	// serve the repository over smart HTTP, as a git host would
+	server := httptest.NewServer(&cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+	})
+	t.Cleanup(server.Close)
+
+	sp := &StorageProvider{localDir: t.TempDir()}
+	installed, err := sp.IsInstalled(context.Background())
+	require.NoError(t, err)
+	require.True(t, installed)
+
+	for _, test := range []struct {
+		name     string
+		metadata map[string]string
+		files    map[string]string
+		missing  []string
+	}{
+		{name: "default branch", files: map[string]string{"code/run.py": "v2", "data/big.csv": "1,2"}},
+		{name: "tag", metadata: map[string]string{RefMetadataKey: "v1"}, files: map[string]string{"code/run.py": "v1"}},
+		{name: "commit", metadata: map[string]string{RefMetadataKey: first}, files: map[string]string{"code/run.py": "v1"}},
+		{
+			name:     "sparse",
+			metadata: map[string]string{PathsMetadataKey: "code"},
+			files:    map[string]string{"code/run.py": "v2"},
+			missing:  []string{"data/big.csv"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			spec := model.StorageSpec{
+				StorageSource: model.StorageSourceGit,
+				URL:           server.URL + "/repo.git",
+				Path:          "/inputs",
+				Metadata:      test.metadata,
+			}
+			volume, err := sp.PrepareStorage(context.Background(), spec)
+			require.NoError(t, err)
+			require.Equal(t, "/inputs", volume.Target)
+
+			for name, contents := range test.files {
+				actual, err := os.ReadFile(filepath.Join(volume.Source, name))
+				require.NoError(t, err)
+				require.Equal(t, contents, string(actual))
+			}
+			for _, name := range test.missing {
+				require.NoFileExists(t, filepath.Join(volume.Source, name))
+			}
+
+			require.NoError(t, sp.CleanupStorage(context.Background(), spec, volume))
+			require.NoDirExists(t, volume.Source)
+		})
+	}
+
+	_, err = sp.PrepareStorage(context.Background(), model.StorageSpec{URL: "file://" + filepath.Join(root, "repo.git")})
+	require.Error(t, err)
+
+	pwned := filepath.Join(root, "pwned")
+	for _, ref := range []string{"--upload-pack=touch " + pwned, "-b", "bad..ref", "refs/heads/bad ref"} {
+		_, err = sp.PrepareStorage(context.Background(), model.StorageSpec{
+			URL:      server.URL + "/repo.git",
+			Metadata: map[string]string{RefMetadataKey: ref},
+		})
+		require.Error(t, err, "ref %q is invalid", ref)
+	}
+	require.NoFileExists(t, pwned, "the ref was run as an option of git")
+}
+
+func gitCommand(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
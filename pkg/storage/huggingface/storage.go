@@ -0,0 +1,370 @@
+// Package huggingface provides a storage source that downloads the files of a
+// model or dataset repository on the Hugging Face Hub, so that jobs can use
+// large models without them first being mirrored into IPFS.
+//
+// Repositories are given as huggingface://org/name@revision URLs, or
+// huggingface://datasets/org/name@revision for datasets, where the revision is
+// a branch, tag or commit and defaults to main. Downloads are kept in a cache
+// of the compute node by the commit they resolve to, so each commit is only
+// downloaded once however many jobs use it.
+package huggingface
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// URLScheme marks the URL of an input as that of a Hugging Face repository.
+	URLScheme = "huggingface"
+
+	// DefaultEndpoint is the Hugging Face Hub.
+	DefaultEndpoint = "https://huggingface.co"
+	// DefaultRevision is checked out if the URL has no revision.
+	DefaultRevision = "main"
+	// DefaultDownloadConcurrency is how many files are downloaded at once if no
+	// other number is configured.
+	DefaultDownloadConcurrency = 4
+
+	datasetsPrefix = "datasets/"
+)
+
+// StorageOptions configures how the compute node downloads from the Hub.
+type StorageOptions struct {
+	// Endpoint to use instead of the Hub, such as a mirror of it
+	Endpoint string
+	// Token to access private and gated repositories with
+	Token string
+	// CacheDir is where repositories are kept by their commit
+	CacheDir string
+	// DownloadConcurrency is how many files of a repository are downloaded at once
+	DownloadConcurrency int
+}
+
+// StorageProvider mounts the files of a repository at a revision, downloading
+// them into its cache first if they aren't already there. The cache is never
+// mounted writable, as the docker executor mounts all inputs read only.
+type StorageProvider struct {
+	client  *http.Client
+	options StorageOptions
+}
+
+func NewStorage(options StorageOptions) *StorageProvider {
+	if options.CacheDir == "" {
+		options.CacheDir = filepath.Join(config.GetStoragePath(), "bacalhau-huggingface-cache")
+	}
+	return newStorage(options)
+}
+
+func newStorage(options StorageOptions) *StorageProvider {
+	if options.Endpoint == "" {
+		options.Endpoint = DefaultEndpoint
+	}
+	options.Endpoint = strings.TrimSuffix(options.Endpoint, "/")
+	if options.DownloadConcurrency <= 0 {
+		options.DownloadConcurrency = DefaultDownloadConcurrency
+	}
+	return &StorageProvider{
+		client:  &http.Client{},
+		options: options,
+	}
+}
+
+func (sp *StorageProvider) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+// HasStorageLocally checks if the revision is in the cache. Branches and tags
+// are only looked up by the commit they point at now.
+func (sp *StorageProvider) HasStorageLocally(ctx context.Context, storageSpec model.StorageSpec) (bool, error) {
+	repo, err := ParseURL(storageSpec.URL)
+	if err != nil {
+		return false, err
+	}
+	info, err := sp.getInfo(ctx, repo)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(sp.cachePath(repo, info.SHA))
+	return err == nil, nil
+}
+
+// GetVolumeSize returns the total size of the files of the revision.
+func (sp *StorageProvider) GetVolumeSize(ctx context.Context, storageSpec model.StorageSpec) (uint64, error) {
+	repo, err := ParseURL(storageSpec.URL)
+	if err != nil {
+		return 0, err
+	}
+	info, err := sp.getInfo(ctx, repo)
+	if err != nil {
+		return 0, err
+	}
+
+	var size uint64
+	for _, file := range info.Siblings {
+		size += uint64(file.Size)
+	}
+	return size, nil
+}
+
+// PrepareStorage mounts the files of the revision from the cache.
+func (sp *StorageProvider) PrepareStorage(ctx context.Context, storageSpec model.StorageSpec) (storage.StorageVolume, error) {
+	repo, err := ParseURL(storageSpec.URL)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	info, err := sp.getInfo(ctx, repo)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	cachePath := sp.cachePath(repo, info.SHA)
+	if _, err = os.Stat(cachePath); err != nil {
+		if err = sp.download(ctx, repo, info, cachePath); err != nil {
+			return storage.StorageVolume{}, err
+		}
+	}
+
+	log.Ctx(ctx).Debug().
+		Stringer("repo", repo).
+		Str("commit", info.SHA).
+		Str("dir", cachePath).
+		Msg("Prepared repository")
+
+	return storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: cachePath,
+		Target: storageSpec.Path,
+	}, nil
+}
+
+// CleanupStorage keeps the files in the cache for the next job to use them.
+func (sp *StorageProvider) CleanupStorage(context.Context, model.StorageSpec, storage.StorageVolume) error {
+	return nil
+}
+
+func (sp *StorageProvider) Upload(context.Context, string) (model.StorageSpec, error) {
+	return model.StorageSpec{}, fmt.Errorf("not implemented")
+}
+
+// HealthCheck returns an error if the Hub can't be reached.
+func (sp *StorageProvider) HealthCheck(ctx context.Context) error {
+	return util.CheckReachable(ctx, sp.client, sp.options.Endpoint)
+}
+
+func (sp *StorageProvider) Explode(_ context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+	return []model.StorageSpec{
+		spec,
+	}, nil
+}
+
+// cachePath returns where the files of the repository at the commit are kept.
+func (sp *StorageProvider) cachePath(repo Repo, sha string) string {
+	return filepath.Join(sp.options.CacheDir, repo.kind(), strings.ReplaceAll(repo.ID, "/", "--"), sha)
+}
+
+type repoInfo struct {
+	SHA      string `json:"sha"`
+	Siblings []struct {
+		Name string `json:"rfilename"`
+		Size int64  `json:"size"`
+	} `json:"siblings"`
+}
+
+// getInfo returns the commit that the revision resolves to, and its files.
+func (sp *StorageProvider) getInfo(ctx context.Context, repo Repo) (repoInfo, error) {
+	infoURL := fmt.Sprintf("%s/api/%s/%s/revision/%s?blobs=true",
+		sp.options.Endpoint, repo.kind(), repo.ID, url.PathEscape(repo.Revision))
+
+	res, err := sp.get(ctx, infoURL)
+	if err != nil {
+		return repoInfo{}, fmt.Errorf("failed to look up %s: %w", repo, err)
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "repository info", res.Body)
+
+	var info repoInfo
+	if err = json.NewDecoder(res.Body).Decode(&info); err != nil {
+		return repoInfo{}, fmt.Errorf("failed to look up %s: %w", repo, err)
+	}
+	// the commit names a directory of the cache, so must be nothing else
+	if info.SHA == "" || strings.Trim(info.SHA, "0123456789abcdef") != "" {
+		return repoInfo{}, fmt.Errorf("failed to look up %s: invalid commit %q returned", repo, info.SHA)
+	}
+	return info, nil
+}
+
+// download fetches the files of the commit into a temporary directory that is
+// only moved into the cache once complete, so that other jobs never see a
+// partial download.
+func (sp *StorageProvider) download(ctx context.Context, repo Repo, info repoInfo, cachePath string) error {
+	if err := os.MkdirAll(filepath.Dir(cachePath), util.OS_USER_RWX); err != nil {
+		return err
+	}
+	tmpPath, err := os.MkdirTemp(filepath.Dir(cachePath), filepath.Base(cachePath)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpPath) //nolint:errcheck // nothing is left to remove once the download is moved
+
+	resolvePrefix := sp.options.Endpoint + "/"
+	if repo.Dataset {
+		resolvePrefix += datasetsPrefix
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(sp.options.DownloadConcurrency)
+	for _, file := range info.Siblings {
+		name := file.Name
+		filePath, err := util.ObjectPath(tmpPath, "", name)
+		if err != nil {
+			return err
+		}
+		fileURL := fmt.Sprintf("%s%s/resolve/%s/%s", resolvePrefix, repo.ID, info.SHA, escapePath(name))
+		group.Go(func() error {
+			return sp.downloadFile(groupCtx, fileURL, filePath)
+		})
+	}
+	if err = group.Wait(); err != nil {
+		return fmt.Errorf("failed to download %s: %w", repo, err)
+	}
+
+	if err = os.Rename(tmpPath, cachePath); err != nil {
+		// another job downloaded the same commit first
+		if _, statErr := os.Stat(cachePath); statErr == nil {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (sp *StorageProvider) downloadFile(ctx context.Context, fileURL, filePath string) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), util.OS_USER_RWX); err != nil {
+		return err
+	}
+
+	res, err := sp.get(ctx, fileURL)
+	if err != nil {
+		return err
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "file", res.Body)
+
+	w, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filePath, err)
+	}
+	defer closer.CloseWithLogOnError("file", w)
+
+	if _, err = io.Copy(w, res.Body); err != nil {
+		return fmt.Errorf("failed to download %s: %w", fileURL, err)
+	}
+	return w.Sync()
+}
+
+func (sp *StorageProvider) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if sp.options.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+sp.options.Token)
+	}
+
+	res, err := sp.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		closer.DrainAndCloseWithLogOnError(ctx, "response", res.Body)
+		if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+			return nil, fmt.Errorf("%s: access denied, the repository may be private or gated: %s", rawURL, res.Status)
+		}
+		return nil, fmt.Errorf("%s: unexpected status %s", rawURL, res.Status)
+	}
+	return res, nil
+}
+
+// escapePath escapes each element of the path of a file in the repository.
+func escapePath(name string) string {
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// Repo is a repository on the Hub at a revision.
+type Repo struct {
+	// ID is the name of the repository, e.g. org/name
+	ID string
+	// Dataset is whether the repository is that of a dataset or of a model
+	Dataset  bool
+	Revision string
+}
+
+// kind returns the name that the Hub gives the type of the repository.
+func (r Repo) kind() string {
+	if r.Dataset {
+		return "datasets"
+	}
+	return "models"
+}
+
+func (r Repo) String() string {
+	if r.Dataset {
+		return URLScheme + "://" + datasetsPrefix + r.ID + "@" + r.Revision
+	}
+	return URLScheme + "://" + r.ID + "@" + r.Revision
+}
+
+// ParseURL returns the repository of a huggingface://org/name@revision URL,
+// or of a huggingface://datasets/org/name@revision one.
+func ParseURL(rawURL string) (Repo, error) {
+	rawURL = strings.Trim(rawURL, " '\"")
+	path, ok := strings.CutPrefix(rawURL, URLScheme+"://")
+	if !ok {
+		return Repo{}, fmt.Errorf("hugging face URLs must begin with %q", URLScheme+"://")
+	}
+
+	var repo Repo
+	if id, revision, ok := strings.Cut(path, "@"); ok {
+		repo.ID, repo.Revision = id, revision
+	} else {
+		repo.ID, repo.Revision = path, DefaultRevision
+	}
+	if repo.Revision == "" {
+		return Repo{}, fmt.Errorf("hugging face URL %s has an empty revision", rawURL)
+	}
+	if id, ok := strings.CutPrefix(repo.ID, datasetsPrefix); ok {
+		repo.ID, repo.Dataset = id, true
+	}
+
+	parts := strings.Split(repo.ID, "/")
+	if len(parts) > 2 {
+		return Repo{}, fmt.Errorf("hugging face URL %s must name a repository as org/name", rawURL)
+	}
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." || part != url.PathEscape(part) {
+			return Repo{}, fmt.Errorf("hugging face URL %s has an invalid repository name", rawURL)
+		}
+	}
+	return repo, nil
+}
+
+// Compile time interface check:
+var _ storage.Storage = (*StorageProvider)(nil)
@@ -0,0 +1,114 @@
+//go:build unit || !integration
+
+package huggingface
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURL(t *testing.T) {
+	repo, err := ParseURL("huggingface://openai/whisper-tiny@v1.0")
+	require.NoError(t, err)
+	require.Equal(t, Repo{ID: "openai/whisper-tiny", Revision: "v1.0"}, repo)
+
+	repo, err = ParseURL("'huggingface://datasets/squad'")
+	require.NoError(t, err)
+	require.Equal(t, Repo{ID: "squad", Dataset: true, Revision: DefaultRevision}, repo)
+	require.Equal(t, "huggingface://datasets/squad@main", repo.String())
+
+	for _, invalid := range []string{
+		"https://huggingface.co/openai/whisper-tiny",
+		"huggingface://openai/whisper-tiny@",
+		"huggingface://openai/whisper/tiny",
+		"huggingface://openai/..",
+		"huggingface://",
+	} {
+		_, err = ParseURL(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestPrepareStorage(t *testing.T) {
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	files := map[string]string{
+		"config.json":         "{}",
+		"tokenizer/vocab.txt": "hello",
+		"model.safetensors":   "weights",
+		"docs/README.md":      "readme",
+	}
+
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/models/org/model/revision/main":
+			type sibling struct {
+				Name string `json:"rfilename"`
+				Size int    `json:"size"`
+			}
+			var siblings []sibling
+			for name, contents := range files {
+				siblings = append(siblings, sibling{Name: name, Size: len(contents)})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"sha": sha, "siblings": siblings})
+			return
+		}
+		for name, contents := range files {
+			if r.URL.Path == "/org/model/resolve/"+sha+"/"+name {
+				downloads.Add(1)
+				_, _ = w.Write([]byte(contents))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	cacheDir := t.TempDir()
+	sp := newStorage(StorageOptions{Endpoint: server.URL, Token: "token", CacheDir: cacheDir})
+	spec := model.StorageSpec{StorageSource: model.StorageSourceHuggingFace, URL: "huggingface://org/model", Path: "/inputs"}
+
+	size, err := sp.GetVolumeSize(context.Background(), spec)
+	require.NoError(t, err)
+	require.Equal(t, uint64(20), size)
+
+	local, err := sp.HasStorageLocally(context.Background(), spec)
+	require.NoError(t, err)
+	require.False(t, local)
+
+	volume, err := sp.PrepareStorage(context.Background(), spec)
+	require.NoError(t, err)
+	require.Equal(t, "/inputs", volume.Target)
+	require.Equal(t, filepath.Join(cacheDir, "models", "org--model", sha), volume.Source)
+	for name, contents := range files {
+		actual, err := os.ReadFile(filepath.Join(volume.Source, name))
+		require.NoError(t, err)
+		require.Equal(t, contents, string(actual))
+	}
+	require.NoError(t, sp.CleanupStorage(context.Background(), spec, volume))
+
+	// the commit is served from the cache from then on
+	local, err = sp.HasStorageLocally(context.Background(), spec)
+	require.NoError(t, err)
+	require.True(t, local)
+	_, err = sp.PrepareStorage(context.Background(), spec)
+	require.NoError(t, err)
+	require.Equal(t, int32(len(files)), downloads.Load())
+
+	unauthorized := newStorage(StorageOptions{Endpoint: server.URL, CacheDir: t.TempDir()})
+	_, err = unauthorized.PrepareStorage(context.Background(), spec)
+	require.ErrorContains(t, err, "access denied")
+}
@@ -4,7 +4,9 @@
 //
 // It does this (currently) by encoding the data as a RFC 2397 "data:" URL, in
 // Base64 encoding. The data may be transparently compressed using Gzip
-// compression if the storage system thinks this would be sensible.
+// compression if the storage system thinks this would be sensible. The data
+// may also be a CAR of UnixFS files and directories, e.g. as made by `ipfs dag
+// export`, so that it keeps the same CIDs that it would have in IPFS.
 //
 // This helps us meet a number of use cases:
 //
@@ -19,19 +21,23 @@
 //     first persist storage and wait for this to complete. E.g. an IoT client
 //     could submit some data it has collected directly to the requestor node.
 //
-// The storage system doesn't enforce any maximum size of the stored data. It is
-// up to the rest of the system to pick a limit it thinks is suitable and
-// enforce it. This is so that e.g. a requestor node can decide that an inline
-// payload is too large and commit the data to IPFS instead, which would be out
-// of the scope of this package.
+// The encoded data can be no larger than MaximumSize, so that job specs stay
+// small enough to be passed around the network. Below that, it is up to the
+// rest of the system to pick a limit it thinks is suitable and enforce it. This
+// is so that e.g. a requestor node can decide that an inline payload is too
+// large and commit the data to IPFS instead, which would be out of the scope of
+// this package.
 package inline
 
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs/car"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
 	"github.com/bacalhau-project/bacalhau/pkg/util/targzip"
@@ -49,6 +55,16 @@ const maximumPlaintextSize datasize.ByteSize = 512 * datasize.B
 // prevalent (see https://superuser.com/q/901962)
 const gzipMimeType string = "application/gzip"
 
+// The MIME type that will be used to identify inline data that is a CAR of
+// UnixFS files and directories.
+const carMimeType string = "application/vnd.ipld.car"
+
+// MaximumSize is the largest that a "data:" URL of inline data can be.
+const MaximumSize datasize.ByteSize = 1 * datasize.MB
+
+// URLPrefix marks the URL of an input as inline data.
+const URLPrefix = "data:"
+
 type InlineStorage struct{}
 
 func NewStorage() *InlineStorage {
@@ -63,6 +79,8 @@ func (i *InlineStorage) CleanupStorage(_ context.Context, _ model.StorageSpec, v
 
 // For an inline storage, we define the volume size as uncompressed data size,
 // as this is how much resource using the storage will take up.
+// CARs are measured by their own size, which is only a little more than the
+// files in them.
 func (i *InlineStorage) GetVolumeSize(_ context.Context, spec model.StorageSpec) (uint64, error) {
 	data, err := dataurl.DecodeString(spec.URL)
 	if err != nil {
@@ -88,9 +106,9 @@ func (*InlineStorage) IsInstalled(context.Context) (bool, error) {
 }
 
 // PrepareStorage extracts the data from the "data:" URL and writes it to a
-// temporary directory. If the data was a compressed tarball, it decompresses it
-// into a directory structure.
-func (i *InlineStorage) PrepareStorage(_ context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+// temporary directory. If the data was a compressed tarball or a CAR, it
+// extracts it into a directory structure.
+func (i *InlineStorage) PrepareStorage(ctx context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
 	tempdir, err := os.MkdirTemp(os.TempDir(), "inline-storage")
 	if err != nil {
 		return storage.StorageVolume{}, err
@@ -102,7 +120,14 @@ func (i *InlineStorage) PrepareStorage(_ context.Context, spec model.StorageSpec
 	}
 
 	reader := bytes.NewReader(data.Data)
-	if data.ContentType() == gzipMimeType {
+	if data.ContentType() == carMimeType {
+		err = car.ExtractCarBytes(ctx, data.Data, tempdir)
+		return storage.StorageVolume{
+			Type:   storage.StorageVolumeConnectorBind,
+			Source: tempdir,
+			Target: spec.Path,
+		}, err
+	} else if data.ContentType() == gzipMimeType {
 		err = os.Remove(tempdir)
 		if err != nil {
 			return storage.StorageVolume{}, err
@@ -163,6 +188,11 @@ func (*InlineStorage) Upload(ctx context.Context, path string) (model.StorageSpe
 		}
 		url = dataurl.EncodeBytes(data)
 	}
+	if size := datasize.ByteSize(len(url)); size > MaximumSize {
+		return model.StorageSpec{}, fmt.Errorf(
+			"%s is too large to be sent inline (%s encoded, but the maximum is %s): add it to IPFS instead",
+			path, size.HR(), MaximumSize.HR())
+	}
 
 	return model.StorageSpec{
 		StorageSource: model.StorageSourceInline,
@@ -170,4 +200,26 @@ func (*InlineStorage) Upload(ctx context.Context, path string) (model.StorageSpe
 	}, err
 }
 
+// HealthCheck is always healthy, as inline inputs are part of the job.
+func (*InlineStorage) HealthCheck(context.Context) error {
+	return nil
+}
+
+// ParseURL returns the data of a "data:" URL, if it is no larger than
+// MaximumSize.
+func ParseURL(rawURL string) (*dataurl.DataURL, error) {
+	rawURL = strings.Trim(rawURL, " '\"")
+	if !strings.HasPrefix(rawURL, URLPrefix) {
+		return nil, fmt.Errorf("inline data URLs must begin with %q", URLPrefix)
+	}
+	if size := datasize.ByteSize(len(rawURL)); size > MaximumSize {
+		return nil, fmt.Errorf("inline data is too large (%s encoded, but the maximum is %s)", size.HR(), MaximumSize.HR())
+	}
+	data, err := dataurl.DecodeString(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inline data URL: %w", err)
+	}
+	return data, nil
+}
+
 var _ storage.Storage = (*InlineStorage)(nil)
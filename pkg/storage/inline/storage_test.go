@@ -4,13 +4,16 @@ package inline
 
 import (
 	"context"
+	"crypto/rand"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs/car"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
 	"github.com/stretchr/testify/require"
+	"github.com/vincent-petithory/dataurl"
 )
 
 func TestPlaintextInlineStorage(t *testing.T) {
@@ -64,3 +67,50 @@ func TestDirectoryInlineStorage(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []byte("more"), data)
 }
+
+func TestCARInlineStorage(t *testing.T) {
+	storage := NewStorage()
+
+	tempdir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempdir, "file1"), []byte("test"), util.OS_ALL_RWX)
+	require.NoError(t, err)
+	carPath := filepath.Join(t.TempDir(), "data.car")
+	_, err = car.CreateCar(context.Background(), tempdir, carPath, 1)
+	require.NoError(t, err)
+	carData, err := os.ReadFile(carPath)
+	require.NoError(t, err)
+
+	spec := model.StorageSpec{
+		StorageSource: model.StorageSourceInline,
+		URL:           dataurl.New(carData, carMimeType).String(),
+	}
+	_, err = ParseURL(spec.URL)
+	require.NoError(t, err)
+
+	size, err := storage.GetVolumeSize(context.Background(), spec)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(carData)), size)
+
+	root, err := storage.PrepareStorage(context.Background(), spec)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(root.Source, "file1"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("test"), data)
+}
+
+func TestMaximumSize(t *testing.T) {
+	tempfile := filepath.Join(t.TempDir(), "file")
+	large := make([]byte, MaximumSize.Bytes())
+	_, err := rand.Read(large)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(tempfile, large, util.OS_ALL_RWX))
+
+	_, err = NewStorage().Upload(context.Background(), tempfile)
+	require.ErrorContains(t, err, "too large")
+
+	_, err = ParseURL(dataurl.EncodeBytes(large))
+	require.ErrorContains(t, err, "too large")
+	_, err = ParseURL("https://example.com/file")
+	require.Error(t, err)
+}
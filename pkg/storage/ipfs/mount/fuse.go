@@ -0,0 +1,291 @@
+//go:build linux || darwin || freebsd
+
+package mount
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+
+	"bazil.org/fuse"
+	"bazil.org/fuse/fs"
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/ipfs/go-libipfs/files"
+	icore "github.com/ipfs/interface-go-ipfs-core"
+	icoreoptions "github.com/ipfs/interface-go-ipfs-core/options"
+	icorepath "github.com/ipfs/interface-go-ipfs-core/path"
+	"github.com/rs/zerolog/log"
+	"go.uber.org/multierr"
+)
+
+const supported = true
+
+func mountFUSE(ctx context.Context, cl ipfs.Client, cid, dir string) (string, func() error, error) {
+	root, isDir, err := rootOf(ctx, cl, cid)
+	if err != nil {
+		return "", nil, err
+	}
+
+	conn, err := fuse.Mount(dir,
+		fuse.ReadOnly(),
+		fuse.AllowOther(),
+		fuse.FSName("ipfs:"+cid),
+		fuse.Subtype("bacalhau"),
+	)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to mount %s: %w", cid, err)
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- fs.Serve(conn, &fileSystem{root: root})
+	}()
+	<-conn.Ready
+	if conn.MountError != nil {
+		_ = conn.Close()
+		return "", nil, fmt.Errorf("failed to mount %s: %w", cid, conn.MountError)
+	}
+
+	unmount := func() error {
+		if err := fuse.Unmount(dir); err != nil {
+			return err
+		}
+		err := <-served
+		return multierr.Combine(err, conn.Close())
+	}
+	return sourceOf(dir, cid, isDir), unmount, nil
+}
+
+// rootOf returns the root of the file system of the CID, which is the CID
+// itself if it is a directory, and otherwise a directory of only the CID.
+func rootOf(ctx context.Context, cl ipfs.Client, cid string) (fs.Node, bool, error) {
+	stat, err := cl.Stat(ctx, cid)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to stat %s: %w", cid, err)
+	}
+	p := icorepath.New("/ipfs/" + cid)
+	switch stat.Type {
+	case ipfs.IPLDDirectory:
+		return &dir{api: cl.API.Unixfs(), path: p}, true, nil
+	case ipfs.IPLDFile:
+		resolved, err := cl.API.ResolvePath(ctx, p)
+		if err != nil {
+			return nil, false, err
+		}
+		node, err := cl.API.Unixfs().Get(ctx, resolved)
+		if err != nil {
+			return nil, false, err
+		}
+		size, err := node.Size()
+		_ = node.Close()
+		if err != nil {
+			return nil, false, err
+		}
+		return &dir{api: cl.API.Unixfs(), entries: map[string]icore.DirEntry{
+			cid: {Name: cid, Cid: resolved.Cid(), Type: icore.TFile, Size: uint64(size)},
+		}}, false, nil
+	default:
+		return nil, false, fmt.Errorf("unknown ipld file type for %s: %v", cid, stat.Type)
+	}
+}
+
+type fileSystem struct {
+	root fs.Node
+}
+
+func (f *fileSystem) Root() (fs.Node, error) {
+	return f.root, nil
+}
+
+// dir is a UnixFS directory, whose entries are listed once they are first
+// needed.
+type dir struct {
+	api  icore.UnixfsAPI
+	path icorepath.Path
+
+	mu      sync.Mutex
+	entries map[string]icore.DirEntry
+}
+
+func (d *dir) Attr(_ context.Context, a *fuse.Attr) error {
+	a.Mode = os.ModeDir | 0555
+	return nil
+}
+
+func (d *dir) Lookup(ctx context.Context, name string) (fs.Node, error) {
+	entries, err := d.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := entries[name]
+	if !ok {
+		return nil, fuse.ENOENT
+	}
+	switch entry.Type {
+	case icore.TDirectory:
+		return &dir{api: d.api, path: icorepath.IpfsPath(entry.Cid)}, nil
+	case icore.TSymlink:
+		return &symlink{target: entry.Target}, nil
+	default:
+		return &file{api: d.api, path: icorepath.IpfsPath(entry.Cid), size: entry.Size}, nil
+	}
+}
+
+func (d *dir) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	entries, err := d.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dirents := make([]fuse.Dirent, 0, len(entries))
+	for name, entry := range entries {
+		dirent := fuse.Dirent{Name: name, Type: fuse.DT_File}
+		switch entry.Type {
+		case icore.TDirectory:
+			dirent.Type = fuse.DT_Dir
+		case icore.TSymlink:
+			dirent.Type = fuse.DT_Link
+		}
+		dirents = append(dirents, dirent)
+	}
+	return dirents, nil
+}
+
+func (d *dir) list(ctx context.Context) (map[string]icore.DirEntry, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.entries != nil {
+		return d.entries, nil
+	}
+
+	entries := make(map[string]icore.DirEntry)
+	ch, err := d.api.Ls(ctx, d.path, icoreoptions.Unixfs.ResolveChildren(true))
+	if err == nil {
+		for entry := range ch {
+			if entry.Err != nil {
+				err = entry.Err
+				break
+			}
+			entries[entry.Name] = entry
+		}
+	}
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("path", d.path.String()).Msg("Failed to list mounted IPFS directory")
+		return nil, syscall.EIO
+	}
+	d.entries = entries
+	return entries, nil
+}
+
+type symlink struct {
+	target string
+}
+
+func (s *symlink) Attr(_ context.Context, a *fuse.Attr) error {
+	a.Mode = os.ModeSymlink | 0444
+	return nil
+}
+
+func (s *symlink) Readlink(context.Context, *fuse.ReadlinkRequest) (string, error) {
+	return s.target, nil
+}
+
+// file is a UnixFS file, whose blocks are only fetched as they are read.
+type file struct {
+	api  icore.UnixfsAPI
+	path icorepath.Path
+	size uint64
+}
+
+func (f *file) Attr(_ context.Context, a *fuse.Attr) error {
+	a.Mode = 0444
+	a.Size = f.size
+	return nil
+}
+
+func (f *file) Open(_ context.Context, _ *fuse.OpenRequest, resp *fuse.OpenResponse) (fs.Handle, error) {
+	// the content of a CID never changes
+	resp.Flags |= fuse.OpenKeepCache
+	ctx, cancel := context.WithCancel(context.Background())
+	return &handle{file: f, ctx: ctx, cancel: cancel}, nil
+}
+
+// handle reads a file from where the last read ended, so that sequential reads
+// don't need to seek.
+type handle struct {
+	file *file
+	// outlives the reads, as reading the file may be bound to the context
+	// that it is opened with
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	reader files.File
+	offset int64
+}
+
+func (h *handle) Read(ctx context.Context, req *fuse.ReadRequest, resp *fuse.ReadResponse) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.seek(req.Offset); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("path", h.file.path.String()).Msg("Failed to read mounted IPFS file")
+		return syscall.EIO
+	}
+	buf := make([]byte, req.Size)
+	n, err := io.ReadFull(h.reader, buf)
+	h.offset += int64(n)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		log.Ctx(ctx).Warn().Err(err).Str("path", h.file.path.String()).Msg("Failed to read mounted IPFS file")
+		return syscall.EIO
+	}
+	resp.Data = buf[:n]
+	return nil
+}
+
+// seek moves the reader to the offset, opening the file again if it can't
+// seek.
+func (h *handle) seek(offset int64) error {
+	if h.reader != nil && h.offset == offset {
+		return nil
+	}
+	if h.reader != nil {
+		if _, err := h.reader.Seek(offset, io.SeekStart); err == nil {
+			h.offset = offset
+			return nil
+		}
+		_ = h.reader.Close()
+		h.reader = nil
+	}
+
+	node, err := h.file.api.Get(h.ctx, h.file.path)
+	if err != nil {
+		return err
+	}
+	reader, ok := node.(files.File)
+	if !ok {
+		_ = node.Close()
+		return fmt.Errorf("%s is not a file", h.file.path)
+	}
+	h.reader = reader
+	h.offset = 0
+	if _, err = reader.Seek(offset, io.SeekStart); err != nil {
+		if _, err = io.CopyN(io.Discard, reader, offset); err != nil {
+			return err
+		}
+	}
+	h.offset = offset
+	return nil
+}
+
+func (h *handle) Release(context.Context, *fuse.ReleaseRequest) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	defer h.cancel()
+	if h.reader != nil {
+		return h.reader.Close()
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+//go:build (unit || !integration) && (linux || darwin || freebsd)
+
+package mount
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"bazil.org/fuse"
+	"bazil.org/fuse/fs"
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/stretchr/testify/require"
+)
+
+// the file system is exercised without mounting it, as FUSE isn't available
+// everywhere that tests run
+
+func newTestNode(t *testing.T) ipfs.Client {
+	cm := system.NewCleanupManager()
+	t.Cleanup(func() {
+		cm.Cleanup(context.Background())
+	})
+	node, err := ipfs.NewLocalNode(context.Background(), cm, []string{})
+	require.NoError(t, err)
+	return node.Client()
+}
+
+func readAt(t *testing.T, ctx context.Context, h fs.Handle, offset int64, size int) string {
+	resp := &fuse.ReadResponse{}
+	require.NoError(t, h.(fs.HandleReader).Read(ctx, &fuse.ReadRequest{Offset: offset, Size: size}, resp))
+	return string(resp.Data)
+}
+
+func TestDirectoryFileSystem(t *testing.T) {
+	ctx := context.Background()
+	cl := newTestNode(t)
+
+	dataDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "a.txt"), []byte("hello world"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "sub", "b.txt"), []byte("nested"), 0644))
+	cid, err := cl.Put(ctx, dataDir)
+	require.NoError(t, err)
+
+	root, isDir, err := rootOf(ctx, cl, cid)
+	require.NoError(t, err)
+	require.True(t, isDir)
+
+	dirents, err := root.(fs.HandleReadDirAller).ReadDirAll(ctx)
+	require.NoError(t, err)
+	sort.Slice(dirents, func(i, j int) bool { return dirents[i].Name < dirents[j].Name })
+	require.Equal(t, []fuse.Dirent{{Name: "a.txt", Type: fuse.DT_File}, {Name: "sub", Type: fuse.DT_Dir}}, dirents)
+
+	_, err = root.(fs.NodeStringLookuper).Lookup(ctx, "missing")
+	require.ErrorIs(t, err, fuse.ENOENT)
+
+	node, err := root.(fs.NodeStringLookuper).Lookup(ctx, "a.txt")
+	require.NoError(t, err)
+	var attr fuse.Attr
+	require.NoError(t, node.Attr(ctx, &attr))
+	require.Equal(t, uint64(len("hello world")), attr.Size)
+
+	h, err := node.(fs.NodeOpener).Open(ctx, &fuse.OpenRequest{}, &fuse.OpenResponse{})
+	require.NoError(t, err)
+	require.Equal(t, "hello", readAt(t, ctx, h, 0, 5))
+	require.Equal(t, " world", readAt(t, ctx, h, 5, 100), "sequential reads carry on")
+	require.Equal(t, "world", readAt(t, ctx, h, 6, 5), "reads seek back")
+	require.Equal(t, "", readAt(t, ctx, h, 11, 5))
+	require.NoError(t, h.(fs.HandleReleaser).Release(ctx, &fuse.ReleaseRequest{}))
+
+	sub, err := root.(fs.NodeStringLookuper).Lookup(ctx, "sub")
+	require.NoError(t, err)
+	nested, err := sub.(fs.NodeStringLookuper).Lookup(ctx, "b.txt")
+	require.NoError(t, err)
+	h, err = nested.(fs.NodeOpener).Open(ctx, &fuse.OpenRequest{}, &fuse.OpenResponse{})
+	require.NoError(t, err)
+	require.Equal(t, "nested", readAt(t, ctx, h, 0, 100))
+}
+
+func TestFileFileSystem(t *testing.T) {
+	ctx := context.Background()
+	cl := newTestNode(t)
+
+	cid, err := ipfs.AddTextToNodes(ctx, []byte("just a file"), cl)
+	require.NoError(t, err)
+
+	root, isDir, err := rootOf(ctx, cl, cid)
+	require.NoError(t, err)
+	require.False(t, isDir)
+	require.Equal(t, "/mnt/"+cid, sourceOf("/mnt", cid, isDir))
+
+	node, err := root.(fs.NodeStringLookuper).Lookup(ctx, cid)
+	require.NoError(t, err)
+	var attr fuse.Attr
+	require.NoError(t, node.Attr(ctx, &attr))
+	require.Equal(t, uint64(len("just a file")), attr.Size)
+
+	h, err := node.(fs.NodeOpener).Open(ctx, &fuse.OpenRequest{}, &fuse.OpenResponse{})
+	require.NoError(t, err)
+	require.Equal(t, "a file", readAt(t, ctx, h, 5, 100))
+}
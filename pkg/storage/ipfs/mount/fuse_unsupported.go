@@ -0,0 +1,16 @@
+//go:build !(linux || darwin || freebsd)
+
+package mount
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+)
+
+const supported = false
+
+func mountFUSE(context.Context, ipfs.Client, string, string) (string, func() error, error) {
+	return "", nil, errors.New("FUSE is not supported on this platform")
+}
@@ -0,0 +1,169 @@
+// Package mount mounts large IPFS inputs lazily with FUSE, rather than fetching
+// all of them before an execution starts, so that jobs that only read part of
+// a huge dataset start sooner and take less disk. Blocks are only fetched as
+// files are read, and are kept by the IPFS node like any others.
+//
+// Mounting is supported on Linux, macOS and FreeBSD, and needs FUSE on the
+// compute node, including user_allow_other in /etc/fuse.conf unless the node
+// runs as root, so that executors can read the mounts. Inputs are fetched as
+// usual if they can't be mounted.
+package mount
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/c2h5oh/datasize"
+	"github.com/rs/zerolog/log"
+	"go.uber.org/multierr"
+)
+
+// Options configures the lazy mounting of IPFS inputs on the compute node.
+type Options struct {
+	// MinSize is the size from which IPFS inputs are mounted lazily rather
+	// than fetched. Nothing is mounted if it is zero.
+	MinSize datasize.ByteSize
+}
+
+// mountFunc mounts the content of the CID at the directory, and returns the
+// path of the content within it.
+type mountFunc func(ctx context.Context, cl ipfs.Client, cid, dir string) (source string, unmount func() error, err error)
+
+// Mounter mounts the IPFS inputs of the storage it wraps lazily, if they are
+// large enough.
+type Mounter struct {
+	dir     string
+	minSize uint64
+	cl      ipfs.Client
+	mount   mountFunc
+
+	mu sync.Mutex
+	// unmounts of the volumes that are mounted, by their source
+	mounts map[string]func() error
+}
+
+// New returns the mounter of the compute node, or nil if mounting is disabled
+// or isn't supported.
+func New(cm *system.CleanupManager, cl ipfs.Client, options Options) (*Mounter, error) {
+	if options.MinSize == 0 {
+		return nil, nil
+	}
+	if !supported {
+		log.Warn().Msg("Lazy mounting of IPFS inputs isn't supported on this platform, so they will be fetched")
+		return nil, nil
+	}
+	dir, err := os.MkdirTemp(config.GetStoragePath(), "bacalhau-ipfs-mounts")
+	if err != nil {
+		return nil, err
+	}
+
+	m := newMounter(dir, options.MinSize.Bytes(), cl, mountFUSE)
+	cm.RegisterCallback(func() error {
+		err := m.unmountAll()
+		if rerr := os.RemoveAll(dir); rerr != nil {
+			err = multierr.Append(err, fmt.Errorf("unable to remove IPFS mounts directory: %w", rerr))
+		}
+		return err
+	})
+
+	log.Debug().Str("dir", dir).Stringer("min-size", options.MinSize).Msg("IPFS lazy mounts enabled")
+
+	return m, nil
+}
+
+func newMounter(dir string, minSize uint64, cl ipfs.Client, mount mountFunc) *Mounter {
+	return &Mounter{
+		dir:     dir,
+		minSize: minSize,
+		cl:      cl,
+		mount:   mount,
+		mounts:  make(map[string]func() error),
+	}
+}
+
+// Wrap returns a storage that mounts the large inputs of the delegate lazily,
+// and prepares the others with the delegate. A nil mounter returns the
+// delegate as it is.
+func (m *Mounter) Wrap(delegate storage.Storage) storage.Storage {
+	if m == nil {
+		return delegate
+	}
+	return &mountingStorage{delegate: delegate, mounter: m}
+}
+
+// mountVolume mounts the content of the spec at a directory of its own.
+func (m *Mounter) mountVolume(ctx context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+	dir, err := os.MkdirTemp(m.dir, "*")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	source, unmount, err := m.mount(ctx, m.cl, spec.CID, dir)
+	if err != nil {
+		return storage.StorageVolume{}, multierr.Combine(err, os.RemoveAll(dir))
+	}
+
+	m.mu.Lock()
+	m.mounts[source] = func() error {
+		if err := unmount(); err != nil {
+			return err
+		}
+		return os.RemoveAll(dir)
+	}
+	m.mu.Unlock()
+
+	log.Ctx(ctx).Debug().Str("cid", spec.CID).Str("dir", dir).Msg("Mounted IPFS input lazily")
+
+	return storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: source,
+		Target: spec.Path,
+	}, nil
+}
+
+// unmountVolume unmounts the volume, or returns false if it isn't mounted.
+func (m *Mounter) unmountVolume(volume storage.StorageVolume) (bool, error) {
+	m.mu.Lock()
+	unmount, ok := m.mounts[volume.Source]
+	delete(m.mounts, volume.Source)
+	m.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	if err := unmount(); err != nil {
+		return true, fmt.Errorf("failed to unmount %s: %w", volume.Source, err)
+	}
+	return true, nil
+}
+
+func (m *Mounter) unmountAll() error {
+	m.mu.Lock()
+	sources := make([]string, 0, len(m.mounts))
+	for source := range m.mounts {
+		sources = append(sources, source)
+	}
+	m.mu.Unlock()
+
+	var err error
+	for _, source := range sources {
+		_, uerr := m.unmountVolume(storage.StorageVolume{Source: source})
+		err = multierr.Append(err, uerr)
+	}
+	return err
+}
+
+// sourceOf returns the path of the content within the directory it is mounted
+// at. Directories are mounted at the directory, and files beneath it.
+func sourceOf(dir, cid string, isDir bool) string {
+	if isDir {
+		return dir
+	}
+	return filepath.Join(dir, cid)
+}
@@ -0,0 +1,70 @@
+package mount
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/rs/zerolog/log"
+)
+
+type mountingStorage struct {
+	delegate storage.Storage
+	mounter  *Mounter
+}
+
+func (s *mountingStorage) IsInstalled(ctx context.Context) (bool, error) {
+	return s.delegate.IsInstalled(ctx)
+}
+
+func (s *mountingStorage) HasStorageLocally(ctx context.Context, spec model.StorageSpec) (bool, error) {
+	return s.delegate.HasStorageLocally(ctx, spec)
+}
+
+// GetVolumeSize returns zero for inputs that are mounted lazily, as they take
+// no space of the storage path.
+func (s *mountingStorage) GetVolumeSize(ctx context.Context, spec model.StorageSpec) (uint64, error) {
+	size, err := s.delegate.GetVolumeSize(ctx, spec)
+	if err != nil {
+		return 0, err
+	}
+	if s.mountable(spec, size) {
+		return 0, nil
+	}
+	return size, nil
+}
+
+func (s *mountingStorage) PrepareStorage(ctx context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+	size, err := s.delegate.GetVolumeSize(ctx, spec)
+	if err != nil || !s.mountable(spec, size) {
+		return s.delegate.PrepareStorage(ctx, spec)
+	}
+	volume, err := s.mounter.mountVolume(ctx, spec)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("cid", spec.CID).Msg("Failed to mount IPFS input lazily, so fetching it")
+		return s.delegate.PrepareStorage(ctx, spec)
+	}
+	return volume, nil
+}
+
+func (s *mountingStorage) CleanupStorage(ctx context.Context, spec model.StorageSpec, volume storage.StorageVolume) error {
+	if mounted, err := s.mounter.unmountVolume(volume); mounted {
+		return err
+	}
+	return s.delegate.CleanupStorage(ctx, spec, volume)
+}
+
+func (s *mountingStorage) Upload(ctx context.Context, path string) (model.StorageSpec, error) {
+	return s.delegate.Upload(ctx, path)
+}
+
+func (s *mountingStorage) HealthCheck(ctx context.Context) error {
+	return s.delegate.HealthCheck(ctx)
+}
+
+func (s *mountingStorage) mountable(spec model.StorageSpec, size uint64) bool {
+	return spec.CID != "" && size >= s.mounter.minSize
+}
+
+// Compile time interface check:
+var _ storage.Storage = (*mountingStorage)(nil)
@@ -0,0 +1,142 @@
+//go:build unit || !integration
+
+package mount
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// fetchingStorage prepares volumes as if it had fetched them, and sizes specs
+// by their CID.
+type fetchingStorage struct {
+	sizes    map[string]uint64
+	prepared []string
+	cleaned  []string
+}
+
+func (s *fetchingStorage) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (s *fetchingStorage) HasStorageLocally(context.Context, model.StorageSpec) (bool, error) {
+	return false, nil
+}
+
+func (s *fetchingStorage) GetVolumeSize(_ context.Context, spec model.StorageSpec) (uint64, error) {
+	size, ok := s.sizes[spec.CID]
+	if !ok {
+		return 0, errors.New("not found")
+	}
+	return size, nil
+}
+
+func (s *fetchingStorage) PrepareStorage(_ context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+	s.prepared = append(s.prepared, spec.CID)
+	return storage.StorageVolume{Type: storage.StorageVolumeConnectorBind, Source: "/fetched/" + spec.CID, Target: spec.Path}, nil
+}
+
+func (s *fetchingStorage) CleanupStorage(_ context.Context, spec model.StorageSpec, _ storage.StorageVolume) error {
+	s.cleaned = append(s.cleaned, spec.CID)
+	return nil
+}
+
+func (s *fetchingStorage) Upload(context.Context, string) (model.StorageSpec, error) {
+	return model.StorageSpec{}, nil
+}
+
+func (s *fetchingStorage) HealthCheck(context.Context) error {
+	return nil
+}
+
+// fakeMounts pretends to mount CIDs, failing for those it is told to.
+type fakeMounts struct {
+	failing string
+	mounted map[string]bool
+}
+
+func (f *fakeMounts) mount(_ context.Context, _ ipfs.Client, cid, dir string) (string, func() error, error) {
+	if cid == f.failing {
+		return "", nil, errors.New("no FUSE")
+	}
+	f.mounted[dir] = true
+	return sourceOf(dir, cid, true), func() error {
+		delete(f.mounted, dir)
+		return nil
+	}, nil
+}
+
+func newTestStorage(t *testing.T, failing string) (*Mounter, *fetchingStorage, *fakeMounts, storage.Storage) {
+	delegate := &fetchingStorage{sizes: map[string]uint64{"QmSmall": 10, "QmLarge": 1000, "QmBroken": 1000}}
+	mounts := &fakeMounts{failing: failing, mounted: map[string]bool{}}
+	m := newMounter(t.TempDir(), 100, ipfs.Client{}, mounts.mount)
+	return m, delegate, mounts, m.Wrap(delegate)
+}
+
+func TestMountsLargeInputs(t *testing.T) {
+	ctx := context.Background()
+	_, delegate, mounts, s := newTestStorage(t, "")
+
+	large := model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "QmLarge", Path: "/inputs"}
+	size, err := s.GetVolumeSize(ctx, large)
+	require.NoError(t, err)
+	require.Zero(t, size)
+
+	volume, err := s.PrepareStorage(ctx, large)
+	require.NoError(t, err)
+	require.Equal(t, "/inputs", volume.Target)
+	require.True(t, mounts.mounted[volume.Source])
+	require.Empty(t, delegate.prepared)
+
+	require.NoError(t, s.CleanupStorage(ctx, large, volume))
+	require.Empty(t, mounts.mounted)
+	require.NoDirExists(t, volume.Source)
+	require.Empty(t, delegate.cleaned)
+}
+
+func TestFetchesSmallInputs(t *testing.T) {
+	ctx := context.Background()
+	_, delegate, mounts, s := newTestStorage(t, "")
+
+	small := model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "QmSmall", Path: "/inputs"}
+	size, err := s.GetVolumeSize(ctx, small)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), size)
+
+	volume, err := s.PrepareStorage(ctx, small)
+	require.NoError(t, err)
+	require.Equal(t, "/fetched/QmSmall", volume.Source)
+	require.Empty(t, mounts.mounted)
+
+	require.NoError(t, s.CleanupStorage(ctx, small, volume))
+	require.Equal(t, []string{"QmSmall"}, delegate.cleaned)
+}
+
+func TestFetchesInputsThatFailToMount(t *testing.T) {
+	ctx := context.Background()
+	m, delegate, _, s := newTestStorage(t, "QmBroken")
+
+	broken := model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "QmBroken", Path: "/inputs"}
+	volume, err := s.PrepareStorage(ctx, broken)
+	require.NoError(t, err)
+	require.Equal(t, "/fetched/QmBroken", volume.Source)
+	require.Equal(t, []string{"QmBroken"}, delegate.prepared)
+
+	entries, err := os.ReadDir(m.dir)
+	require.NoError(t, err)
+	require.Empty(t, entries, "the mount point is removed")
+}
+
+func TestDisabledMounts(t *testing.T) {
+	delegate := &fetchingStorage{}
+	m, err := New(nil, ipfs.Client{}, Options{})
+	require.NoError(t, err)
+	require.Same(t, delegate, m.Wrap(delegate))
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/bacalhau-project/bacalhau/pkg/config"
 	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs/pinning"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
@@ -22,9 +23,12 @@ import (
 type StorageProvider struct {
 	localDir   string
 	ipfsClient ipfs.Client
+	pinner     *pinning.Pinner
 }
 
-func NewStorage(cm *system.CleanupManager, cl ipfs.Client) (*StorageProvider, error) {
+// NewStorage returns the IPFS storage of the node, which pins what it uploads
+// to the remote pinning services of the pinner, if any.
+func NewStorage(cm *system.CleanupManager, cl ipfs.Client, pinner *pinning.Pinner) (*StorageProvider, error) {
 	// TODO: consolidate the various config inputs into one package otherwise they are scattered across the codebase
 	dir, err := os.MkdirTemp(config.GetStoragePath(), "bacalhau-ipfs")
 	if err != nil {
@@ -41,6 +45,7 @@ func NewStorage(cm *system.CleanupManager, cl ipfs.Client) (*StorageProvider, er
 	storageHandler := &StorageProvider{
 		ipfsClient: cl,
 		localDir:   dir,
+		pinner:     pinner,
 	}
 
 	log.Trace().Msgf("IPFS API Copy driver created with address: %s", cl.APIAddress())
@@ -92,12 +97,31 @@ func (s *StorageProvider) Upload(ctx context.Context, localPath string) (model.S
 	if err != nil {
 		return model.StorageSpec{}, err
 	}
+	if err = s.pinner.Pin(ctx, cid, filepath.Base(localPath), nil); err != nil {
+		return model.StorageSpec{}, fmt.Errorf("failed to pin %s remotely: %w", cid, err)
+	}
 	return model.StorageSpec{
 		StorageSource: model.StorageSourceIPFS,
 		CID:           cid,
 	}, nil
 }
 
+// HealthCheck returns an error if the IPFS node can't be reached, or if it
+// isn't connected to any peers to fetch inputs from.
+func (s *StorageProvider) HealthCheck(ctx context.Context) error {
+	if _, err := s.ipfsClient.ID(ctx); err != nil {
+		return err
+	}
+	peers, err := s.ipfsClient.API.Swarm().Peers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list peers of IPFS node: %w", err)
+	}
+	if len(peers) == 0 {
+		return fmt.Errorf("IPFS node at %s isn't connected to any peers", s.ipfsClient.APIAddress())
+	}
+	return nil
+}
+
 func (s *StorageProvider) getFileFromIPFS(ctx context.Context, storageSpec model.StorageSpec) (storage.StorageVolume, error) {
 	outputPath := filepath.Join(s.localDir, storageSpec.CID)
 
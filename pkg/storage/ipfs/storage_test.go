@@ -28,7 +28,7 @@ func getIpfsStorage(t *testing.T) *StorageProvider {
 	node, err := ipfs.NewLocalNode(ctx, cm, []string{})
 	require.NoError(t, err)
 
-	storage, err := NewStorage(cm, node.Client())
+	storage, err := NewStorage(cm, node.Client(), nil)
 	require.NoError(t, err)
 
 	return storage
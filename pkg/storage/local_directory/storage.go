@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
@@ -14,34 +16,87 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-type StorageProvider struct {
-	LocalDirectoryPath string
+const (
+	// ReadWriteMetadataKey is the metadata of the storage spec that asks for
+	// the directory to be mounted writable, which is only allowed if the
+	// compute node allows writes to it.
+	ReadWriteMetadataKey = "ReadWrite"
+
+	// URLScheme marks the URL of an input as a directory of the compute node.
+	URLScheme = "file"
+
+	readWriteSuffix = ":rw"
+	readOnlySuffix  = ":ro"
+)
+
+// AllowedPath is a directory of the compute node that jobs may mount, along
+// with everything beneath it.
+type AllowedPath struct {
+	Path      string
+	ReadWrite bool
 }
 
-func NewStorage(_ *system.CleanupManager, localDirectoryPath string) (*StorageProvider, error) {
-	storageHandler := &StorageProvider{
-		LocalDirectoryPath: localDirectoryPath,
+// ParseAllowedPath parses a path that jobs may mount, which ends with :rw if
+// jobs may write to it too, and optionally :ro otherwise.
+func ParseAllowedPath(value string) (AllowedPath, error) {
+	allowed := AllowedPath{Path: value}
+	if path, ok := strings.CutSuffix(value, readWriteSuffix); ok {
+		allowed = AllowedPath{Path: path, ReadWrite: true}
+	} else if path, ok := strings.CutSuffix(value, readOnlySuffix); ok {
+		allowed = AllowedPath{Path: path}
 	}
-	log.Debug().Msgf("Local directory driver createde: %s", localDirectoryPath)
+	if !filepath.IsAbs(allowed.Path) {
+		return AllowedPath{}, fmt.Errorf("allowed local path %q must be absolute", allowed.Path)
+	}
+	return allowed, nil
+}
+
+func (a AllowedPath) String() string {
+	if a.ReadWrite {
+		return a.Path + readWriteSuffix
+	}
+	return a.Path + readOnlySuffix
+}
 
-	// check if the localDirectoryPath exists and error if it doesn't
-	if _, err := os.Stat(localDirectoryPath); errors.Is(err, os.ErrNotExist) {
-		return nil, fmt.Errorf("local directory path %s does not exist", localDirectoryPath)
+// StorageOptions configures which directories of the compute node jobs may
+// mount. Jobs may mount nothing if none are allowed.
+type StorageOptions struct {
+	AllowedPaths []AllowedPath
+}
+
+// StorageProvider mounts directories of the compute node that the operator has
+// allowed, so that jobs can be brought to data that is already on the node
+// rather than the data being copied to them.
+type StorageProvider struct {
+	allowedPaths []AllowedPath
+}
+
+func NewStorage(_ *system.CleanupManager, options StorageOptions) (*StorageProvider, error) {
+	storageHandler := &StorageProvider{}
+	for _, allowed := range options.AllowedPaths {
+		// check if the path exists and error if it doesn't, and resolve any
+		// links so that paths are compared by where they really are
+		path, err := filepath.EvalSymlinks(allowed.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("local directory path %s does not exist", allowed.Path)
+		} else if err != nil {
+			return nil, err
+		}
+		storageHandler.allowedPaths = append(storageHandler.allowedPaths, AllowedPath{Path: path, ReadWrite: allowed.ReadWrite})
 	}
+	log.Debug().Msgf("Local directory driver created: %v", storageHandler.allowedPaths)
 
 	return storageHandler, nil
 }
 
+// IsInstalled is only true if jobs may mount some directory, so that nodes
+// that allow none don't bid on jobs that need one.
 func (driver *StorageProvider) IsInstalled(context.Context) (bool, error) {
-	return true, nil
+	return len(driver.allowedPaths) > 0, nil
 }
 
 func (driver *StorageProvider) HasStorageLocally(_ context.Context, volume model.StorageSpec) (bool, error) {
-	localPath, err := driver.getPathToVolume(volume)
-	if err != nil {
-		return false, err
-	}
-	if _, err := os.Stat(localPath); errors.Is(err, os.ErrNotExist) {
+	if _, err := driver.getPathToVolume(volume); err != nil {
 		return false, nil
 	}
 	return true, nil
@@ -64,9 +119,10 @@ func (driver *StorageProvider) PrepareStorage(
 		return storage.StorageVolume{}, err
 	}
 	return storage.StorageVolume{
-		Type:   storage.StorageVolumeConnectorBind,
-		Source: localPath,
-		Target: storageSpec.Path,
+		Type:      storage.StorageVolumeConnectorBind,
+		Source:    localPath,
+		Target:    storageSpec.Path,
+		ReadWrite: isReadWrite(storageSpec),
 	}, nil
 }
 
@@ -78,17 +134,75 @@ func (driver *StorageProvider) Upload(context.Context, string) (model.StorageSpe
 	return model.StorageSpec{}, fmt.Errorf("not implemented")
 }
 
+// HealthCheck is always healthy, as inputs are read from the local filesystem.
+func (driver *StorageProvider) HealthCheck(context.Context) error {
+	return nil
+}
+
 func (driver *StorageProvider) Explode(_ context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
 	return []model.StorageSpec{
 		spec,
 	}, nil
 }
 
+// getPathToVolume returns the directory of the storage spec, if it exists and
+// is beneath a path that jobs may mount in the way that the spec asks for.
 func (driver *StorageProvider) getPathToVolume(volume model.StorageSpec) (string, error) {
-	// join the driver.LocalDirectoryPath with the volume.SourcePath
-	// use the os.PathSeparator to make sure we are using the correct separator for the OS
-	localPath := filepath.Join(driver.LocalDirectoryPath, volume.SourcePath)
-	return localPath, nil
+	if !filepath.IsAbs(volume.SourcePath) {
+		return "", fmt.Errorf("local directory path %q must be absolute", volume.SourcePath)
+	}
+	// resolve links so that they can't point jobs outside of allowed paths
+	localPath, err := filepath.EvalSymlinks(volume.SourcePath)
+	if err != nil {
+		return "", fmt.Errorf("local directory path %s: %w", volume.SourcePath, err)
+	}
+
+	readWrite := isReadWrite(volume)
+	for _, allowed := range driver.allowedPaths {
+		if readWrite && !allowed.ReadWrite {
+			continue
+		}
+		if rel, err := filepath.Rel(allowed.Path, localPath); err == nil && filepath.IsLocal(rel) {
+			return localPath, nil
+		}
+	}
+	if readWrite {
+		return "", fmt.Errorf("local directory path %s is not allowed to be written to by jobs on this node", volume.SourcePath)
+	}
+	return "", fmt.Errorf("local directory path %s is not allowed to be mounted by jobs on this node", volume.SourcePath)
+}
+
+func isReadWrite(volume model.StorageSpec) bool {
+	return volume.Metadata[ReadWriteMetadataKey] == "true"
+}
+
+// ParseURL returns the storage spec of a directory of the compute node given
+// as a file:///path URL, which is mounted writable if it has an rw query, e.g.
+// file:///data/results?rw.
+func ParseURL(rawURL string) (model.StorageSpec, error) {
+	u, err := url.Parse(strings.Trim(rawURL, " '\""))
+	if err != nil {
+		return model.StorageSpec{}, fmt.Errorf("invalid URL: %s", err)
+	}
+	if u.Scheme != URLScheme {
+		return model.StorageSpec{}, fmt.Errorf("local directory URLs must begin with %q", URLScheme+"://")
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return model.StorageSpec{}, fmt.Errorf("local directory URL %s must not name a host", rawURL)
+	}
+	if !filepath.IsAbs(u.Path) {
+		return model.StorageSpec{}, fmt.Errorf("local directory URL %s must have an absolute path", rawURL)
+	}
+
+	spec := model.StorageSpec{
+		StorageSource: model.StorageSourceLocalDirectory,
+		SourcePath:    filepath.Clean(u.Path),
+		Path:          "/inputs",
+	}
+	if u.Query().Has("rw") {
+		spec.Metadata = map[string]string{ReadWriteMetadataKey: "true"}
+	}
+	return spec, nil
 }
 
 // Compile time interface check:
@@ -31,8 +31,8 @@ func (suite *LocalDirectorySuite) prepareStorageSpec(sourcePath string) model.St
 	require.NoError(suite.T(), err)
 	return model.StorageSpec{
 		// source path is some kind of sub-path
-		// inside our local folder
-		SourcePath: sourcePath,
+		// inside our allowed folder
+		SourcePath: folderPath,
 		Path:       "/path/inside/the/container",
 	}
 }
@@ -49,8 +49,9 @@ func (suite *LocalDirectorySuite) SetupTest() {
 	logger.ConfigureTestLogging(suite.T())
 	cm = system.NewCleanupManager()
 	ctx = context.Background()
-	tempDir = suite.T().TempDir()
-	driver, setupErr = NewStorage(cm, tempDir)
+	tempDir, setupErr = filepath.EvalSymlinks(suite.T().TempDir())
+	require.NoError(suite.T(), setupErr)
+	driver, setupErr = NewStorage(cm, StorageOptions{AllowedPaths: []AllowedPath{{Path: tempDir}}})
 	require.NoError(suite.T(), setupErr)
 }
 
@@ -66,7 +67,7 @@ func (suite *LocalDirectorySuite) TestHasStorageLocally() {
 	hasStorageTrue, err := driver.HasStorageLocally(ctx, spec)
 	require.NoError(suite.T(), err)
 	require.True(suite.T(), hasStorageTrue, "file that exists should return true for HasStorageLocally")
-	spec.SourcePath = "apples/pears"
+	spec.SourcePath = filepath.Join(tempDir, "apples/pears")
 	hasStorageFalse, err := driver.HasStorageLocally(ctx, spec)
 	require.NoError(suite.T(), err)
 	require.False(suite.T(), hasStorageFalse, "file that does not exist should return false for HasStorageLocally")
@@ -91,6 +92,77 @@ func (suite *LocalDirectorySuite) TestPrepareStorage() {
 	volume, err := driver.PrepareStorage(ctx, spec)
 	require.NoError(suite.T(), err)
 	require.Equal(suite.T(), volume.Source, filepath.Join(tempDir, subpath), "the volume source is correct")
+	require.False(suite.T(), volume.ReadWrite, "the volume is read only")
+}
+
+func (suite *LocalDirectorySuite) TestPrepareStorageNotAllowed() {
+	spec := suite.prepareStorageSpec("apples/oranges")
+	spec.SourcePath = suite.T().TempDir()
+	_, err := driver.PrepareStorage(ctx, spec)
+	require.Error(suite.T(), err, "paths outside the allowed paths should not be mounted")
+
+	spec.SourcePath = filepath.Join(tempDir, "apples/oranges/../../..")
+	_, err = driver.PrepareStorage(ctx, spec)
+	require.Error(suite.T(), err, "paths should not escape the allowed paths")
+
+	outside := suite.T().TempDir()
+	link := filepath.Join(tempDir, "link")
+	require.NoError(suite.T(), os.Symlink(outside, link))
+	spec.SourcePath = link
+	_, err = driver.PrepareStorage(ctx, spec)
+	require.Error(suite.T(), err, "links should not escape the allowed paths")
+
+	spec.SourcePath = "apples/oranges"
+	_, err = driver.PrepareStorage(ctx, spec)
+	require.Error(suite.T(), err, "relative paths should not be mounted")
+}
+
+func (suite *LocalDirectorySuite) TestPrepareStorageReadWrite() {
+	spec := suite.prepareStorageSpec("apples/oranges")
+	spec.Metadata = map[string]string{ReadWriteMetadataKey: "true"}
+	_, err := driver.PrepareStorage(ctx, spec)
+	require.Error(suite.T(), err, "read only paths should not be mounted writable")
+
+	readWriteDriver, err := NewStorage(cm, StorageOptions{AllowedPaths: []AllowedPath{{Path: tempDir, ReadWrite: true}}})
+	require.NoError(suite.T(), err)
+	volume, err := readWriteDriver.PrepareStorage(ctx, spec)
+	require.NoError(suite.T(), err)
+	require.True(suite.T(), volume.ReadWrite, "the volume is writable")
+}
+
+func (suite *LocalDirectorySuite) TestIsInstalledWithoutAllowedPaths() {
+	noPaths, err := NewStorage(cm, StorageOptions{})
+	require.NoError(suite.T(), err)
+	installed, err := noPaths.IsInstalled(ctx)
+	require.NoError(suite.T(), err)
+	require.False(suite.T(), installed)
+
+	_, err = NewStorage(cm, StorageOptions{AllowedPaths: []AllowedPath{{Path: filepath.Join(tempDir, "missing")}}})
+	require.Error(suite.T(), err)
+}
+
+func (suite *LocalDirectorySuite) TestParseAllowedPath() {
+	allowed, err := ParseAllowedPath("/data/results:rw")
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), AllowedPath{Path: "/data/results", ReadWrite: true}, allowed)
+	allowed, err = ParseAllowedPath("/data")
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), AllowedPath{Path: "/data"}, allowed)
+	_, err = ParseAllowedPath("data:ro")
+	require.Error(suite.T(), err)
+}
+
+func (suite *LocalDirectorySuite) TestParseURL() {
+	spec, err := ParseURL("file:///data/results?rw")
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), model.StorageSpec{
+		StorageSource: model.StorageSourceLocalDirectory,
+		SourcePath:    "/data/results",
+		Path:          "/inputs",
+		Metadata:      map[string]string{ReadWriteMetadataKey: "true"},
+	}, spec)
+	_, err = ParseURL("file://host/data")
+	require.Error(suite.T(), err)
 }
 
 func (suite *LocalDirectorySuite) TestExplode() {
@@ -99,5 +171,5 @@ func (suite *LocalDirectorySuite) TestExplode() {
 	exploded, err := driver.Explode(ctx, spec)
 	require.NoError(suite.T(), err)
 	require.Equal(suite.T(), len(exploded), 1, "the exploded list should be 1 item long")
-	require.Equal(suite.T(), exploded[0].SourcePath, subpath, "the subpath is correct")
+	require.Equal(suite.T(), exploded[0].SourcePath, filepath.Join(tempDir, subpath), "the subpath is correct")
 }
@@ -0,0 +1,122 @@
+// Package locality keeps track of the inputs that a compute node holds
+// locally, so that the node can report them and requesters can prefer it for
+// jobs that use the same data, which then doesn't need to be transferred.
+//
+// Inputs are tracked by CID once an execution has prepared them, as storage
+// providers keep the data they fetched, e.g. in the blockstore of the IPFS
+// node or the content cache. Only the inputs that were prepared most recently
+// are tracked, and they may have been evicted since, so what is reported is a
+// hint rather than a guarantee.
+package locality
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+)
+
+// DefaultMaxCIDs is how many CIDs are tracked by default, which bounds the
+// size of the node info that compute nodes publish.
+const DefaultMaxCIDs = 100
+
+// Tracker keeps the CIDs of the inputs that were prepared most recently with
+// the storage providers that it wraps.
+type Tracker struct {
+	maxCIDs int
+
+	mu sync.Mutex
+	// the CIDs, most recently prepared first
+	lru      *list.List
+	elements map[string]*list.Element
+}
+
+// New returns a tracker of at most maxCIDs CIDs, or nil if it is zero.
+func New(maxCIDs int) *Tracker {
+	if maxCIDs <= 0 {
+		return nil
+	}
+	return &Tracker{
+		maxCIDs:  maxCIDs,
+		lru:      list.New(),
+		elements: make(map[string]*list.Element),
+	}
+}
+
+// Wrap returns a provider whose storages record the CIDs of the inputs they
+// prepare. A nil tracker returns the provider as it is.
+func (t *Tracker) Wrap(provider storage.StorageProvider) storage.StorageProvider {
+	if t == nil {
+		return provider
+	}
+	return &trackingProvider{delegate: provider, tracker: t}
+}
+
+// CIDs returns the CIDs of the inputs that were prepared most recently, most
+// recent first. A nil tracker returns none.
+func (t *Tracker) CIDs() []string {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cids := make([]string, 0, t.lru.Len())
+	for element := t.lru.Front(); element != nil; element = element.Next() {
+		cids = append(cids, element.Value.(string))
+	}
+	return cids
+}
+
+// record tracks the CID as the one prepared most recently, forgetting the one
+// prepared least recently if there are too many.
+func (t *Tracker) record(cid string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if element, ok := t.elements[cid]; ok {
+		t.lru.MoveToFront(element)
+		return
+	}
+	t.elements[cid] = t.lru.PushFront(cid)
+	if t.lru.Len() > t.maxCIDs {
+		oldest := t.lru.Back()
+		t.lru.Remove(oldest)
+		delete(t.elements, oldest.Value.(string))
+	}
+}
+
+type trackingProvider struct {
+	delegate storage.StorageProvider
+	tracker  *Tracker
+}
+
+func (p *trackingProvider) Get(ctx context.Context, sourceType model.StorageSourceType) (storage.Storage, error) {
+	s, err := p.delegate.Get(ctx, sourceType)
+	if err != nil {
+		return nil, err
+	}
+	return &trackingStorage{Storage: s, tracker: p.tracker}, nil
+}
+
+func (p *trackingProvider) Has(ctx context.Context, sourceType model.StorageSourceType) bool {
+	return p.delegate.Has(ctx, sourceType)
+}
+
+// trackingStorage records the CIDs of the inputs that it prepares.
+type trackingStorage struct {
+	storage.Storage
+	tracker *Tracker
+}
+
+func (s *trackingStorage) PrepareStorage(ctx context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+	volume, err := s.Storage.PrepareStorage(ctx, spec)
+	if err == nil && spec.CID != "" {
+		s.tracker.record(spec.CID)
+	}
+	return volume, err
+}
+
+// Compile time interface check:
+var _ storage.StorageProvider = (*trackingProvider)(nil)
+var _ storage.Storage = (*trackingStorage)(nil)
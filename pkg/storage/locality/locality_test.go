@@ -0,0 +1,49 @@
+//go:build unit || !integration
+
+package locality
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/noop"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackerRecordsPreparedCIDs(t *testing.T) {
+	ctx := context.Background()
+	tracker := New(2)
+	provider := tracker.Wrap(model.NewNoopProvider[model.StorageSourceType, storage.Storage](
+		noop.NewNoopStorageWithConfig(noop.StorageConfig{
+			ExternalHooks: noop.StorageConfigExternalHooks{
+				PrepareStorage: func(_ context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+					if spec.CID == "missing" {
+						return storage.StorageVolume{}, errors.New("not found")
+					}
+					return storage.StorageVolume{}, nil
+				},
+			},
+		}),
+	))
+	s, err := provider.Get(ctx, model.StorageSourceIPFS)
+	require.NoError(t, err)
+
+	prepare := func(spec model.StorageSpec) {
+		_, _ = s.PrepareStorage(ctx, spec)
+	}
+	prepare(model.StorageSpec{CID: "first"})
+	prepare(model.StorageSpec{URL: "https://example.com/data"})
+	prepare(model.StorageSpec{CID: "missing"})
+	prepare(model.StorageSpec{CID: "second"})
+	require.Equal(t, []string{"second", "first"}, tracker.CIDs(), "only inputs that were prepared and have CIDs are tracked")
+
+	prepare(model.StorageSpec{CID: "first"})
+	prepare(model.StorageSpec{CID: "third"})
+	require.Equal(t, []string{"third", "first"}, tracker.CIDs(), "the inputs prepared least recently are forgotten")
+
+	require.Nil(t, New(0), "nothing is tracked if the maximum is zero")
+	require.Empty(t, New(0).CIDs())
+}
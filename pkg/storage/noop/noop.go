@@ -14,6 +14,7 @@ type StroageHandlerPrepareStorage func(ctx context.Context, storageSpec model.St
 type StroageHandlerCleanupStorage func(ctx context.Context, storageSpec model.StorageSpec, volume storage.StorageVolume) error
 type StroageHandlerUpload func(ctx context.Context, localPath string) (model.StorageSpec, error)
 type StroageHandlerExplode func(ctx context.Context, storageSpec model.StorageSpec) ([]model.StorageSpec, error)
+type StroageHandlerHealthCheck func(ctx context.Context) error
 
 type StorageConfigExternalHooks struct {
 	IsInstalled       StroageHandlerIsInstalled
@@ -23,6 +24,7 @@ type StorageConfigExternalHooks struct {
 	CleanupStorage    StroageHandlerCleanupStorage
 	Upload            StroageHandlerUpload
 	Explode           StroageHandlerExplode
+	HealthCheck       StroageHandlerHealthCheck
 }
 
 type StorageConfig struct {
@@ -118,5 +120,13 @@ func (s *NoopStorage) CleanupStorage(ctx context.Context, storageSpec model.Stor
 	return nil
 }
 
+func (s *NoopStorage) HealthCheck(ctx context.Context) error {
+	if s.Config.ExternalHooks.HealthCheck != nil {
+		handler := s.Config.ExternalHooks.HealthCheck
+		return handler(ctx)
+	}
+	return nil
+}
+
 // Compile time interface check:
 var _ storage.Storage = (*NoopStorage)(nil)
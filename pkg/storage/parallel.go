@@ -3,19 +3,28 @@ package storage
 import (
 	"context"
 
+	"github.com/bacalhau-project/bacalhau/pkg/config"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/util/generic"
 	"go.ptx.dk/multierrgroup"
+	"go.uber.org/multierr"
 )
 
 // ParallelPrepareStorage downloads all of the data necessary for the passed
 // storage specs in parallel, and returns a map of specs to their download
-// volume counterparts.
+// volume counterparts. Data is verified against the digests of the specs
+// that have them, returning a DigestMismatchError if it doesn't match. Nothing
+// is prepared if the data wouldn't fit in the free space of the storage path,
+// returning an InsufficientDiskSpaceError.
 func ParallelPrepareStorage(
 	ctx context.Context,
 	provider StorageProvider,
 	specs []model.StorageSpec,
 ) (map[*model.StorageSpec]StorageVolume, error) {
+	if err := CheckDiskSpace(ctx, provider, specs, config.GetStoragePath()); err != nil {
+		return nil, err
+	}
+
 	volumes := generic.SyncMap[*model.StorageSpec, StorageVolume]{}
 	waitgroup := multierrgroup.Group{}
 
@@ -35,6 +44,11 @@ func ParallelPrepareStorage(
 				return err
 			}
 
+			// fail before the job can see data that isn't what was asked for
+			if err = VerifyDigest(spec, volumeMount); err != nil {
+				return multierr.Combine(err, storageProvider.CleanupStorage(ctx, spec, volumeMount))
+			}
+
 			volumes.Put(&spec, volumeMount)
 			return nil
 		}
@@ -0,0 +1,266 @@
+// Package prefetch starts fetching the inputs of executions as soon as a
+// compute node bids on them, so that the node whose bid is accepted can start
+// the execution sooner. Prefetched volumes are handed over to the executor
+// that runs the execution when it prepares the same inputs, and are cleaned up
+// if the bid is rejected or the execution ends without using them.
+//
+// Only so much data is prefetched at once, as bids are often rejected.
+package prefetch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/c2h5oh/datasize"
+	"github.com/rs/zerolog/log"
+)
+
+// Options configures the prefetching of inputs on the compute node.
+type Options struct {
+	// Budget is how much data is prefetched at once for executions that
+	// haven't started yet. Nothing is prefetched if it is zero.
+	Budget datasize.ByteSize
+}
+
+// Prefetcher prefetches the inputs of executions with the storage providers
+// that it wraps.
+type Prefetcher struct {
+	budget   uint64
+	provider storage.StorageProvider
+
+	mu   sync.Mutex
+	used uint64
+	// the volumes of each execution, and the same by the spec they are of
+	executions map[string][]*volume
+	bySpec     map[string][]*volume
+	// cancels the prefetching of each execution
+	cancels map[string]context.CancelFunc
+}
+
+// volume is an input that is being or has been prefetched.
+type volume struct {
+	executionID string
+	spec        model.StorageSpec
+	key         string
+	size        uint64
+	storage     storage.Storage
+
+	// closed once the volume has been prepared, successfully or not
+	ready  chan struct{}
+	volume storage.StorageVolume
+	err    error
+	// whether the volume is no longer wanted, and is cleaned up once ready
+	released bool
+}
+
+// New returns the prefetcher of the compute node, or nil if prefetching is
+// disabled.
+func New(options Options) *Prefetcher {
+	if options.Budget == 0 {
+		return nil
+	}
+	return &Prefetcher{
+		budget:     options.Budget.Bytes(),
+		executions: make(map[string][]*volume),
+		bySpec:     make(map[string][]*volume),
+		cancels:    make(map[string]context.CancelFunc),
+	}
+}
+
+// Wrap returns a provider whose storages hand over prefetched volumes when
+// they are asked to prepare them. The prefetcher prefetches with the provider,
+// so there can only be one. A nil prefetcher returns the provider as it is.
+func (p *Prefetcher) Wrap(provider storage.StorageProvider) storage.StorageProvider {
+	if p == nil {
+		return provider
+	}
+	p.provider = provider
+	return &prefetchingProvider{delegate: provider, prefetcher: p}
+}
+
+// Prefetch starts fetching the inputs of the execution in the background, for
+// as long as they fit in the budget. Inputs that are already on the node or
+// whose size isn't known are left to be prepared when the execution starts.
+func (p *Prefetcher) Prefetch(ctx context.Context, executionID string, specs []model.StorageSpec) {
+	if p == nil || p.provider == nil || len(specs) == 0 {
+		return
+	}
+	// fetching outlives the request to bid
+	ctx, cancel := context.WithCancel(log.Ctx(ctx).WithContext(context.Background()))
+	p.mu.Lock()
+	p.cancels[executionID] = cancel
+	p.mu.Unlock()
+
+	go p.prefetch(ctx, executionID, specs)
+}
+
+func (p *Prefetcher) prefetch(ctx context.Context, executionID string, specs []model.StorageSpec) {
+	for _, spec := range specs {
+		s, err := p.provider.Get(ctx, spec.StorageSource)
+		if err != nil {
+			continue
+		}
+		if local, err := s.HasStorageLocally(ctx, spec); err != nil || local {
+			continue
+		}
+		size, err := s.GetVolumeSize(ctx, spec)
+		if err != nil || size == 0 {
+			continue
+		}
+
+		p.mu.Lock()
+		if ctx.Err() != nil {
+			// the execution has been released already
+			p.mu.Unlock()
+			return
+		}
+		if p.used+size > p.budget {
+			p.mu.Unlock()
+			log.Ctx(ctx).Debug().
+				Str("execution", executionID).
+				Stringer("size", datasize.ByteSize(size)).
+				Msg("Not prefetching input beyond the prefetch budget")
+			continue
+		}
+		p.used += size
+		v := &volume{
+			executionID: executionID,
+			spec:        spec,
+			key:         specKey(spec),
+			size:        size,
+			storage:     s,
+			ready:       make(chan struct{}),
+		}
+		p.executions[executionID] = append(p.executions[executionID], v)
+		p.bySpec[v.key] = append(p.bySpec[v.key], v)
+		p.mu.Unlock()
+
+		go p.fetch(ctx, v)
+	}
+}
+
+func (p *Prefetcher) fetch(ctx context.Context, v *volume) {
+	prepared, err := v.storage.PrepareStorage(ctx, v.spec)
+
+	p.mu.Lock()
+	v.volume, v.err = prepared, err
+	close(v.ready)
+	cleanup := err == nil && v.released
+	p.mu.Unlock()
+
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Str("execution", v.executionID).Msg("Failed to prefetch input")
+		return
+	}
+	log.Ctx(ctx).Debug().Str("execution", v.executionID).Str("source", v.spec.StorageSource.String()).Msg("Prefetched input")
+	if cleanup {
+		p.cleanup(v)
+	}
+}
+
+// claim hands over the prefetched volume of the spec, waiting for it if it is
+// still being fetched. It returns false if there is none, or it failed.
+func (p *Prefetcher) claim(ctx context.Context, spec model.StorageSpec) (storage.StorageVolume, bool) {
+	key := specKey(spec)
+	p.mu.Lock()
+	volumes := p.bySpec[key]
+	if len(volumes) == 0 {
+		p.mu.Unlock()
+		return storage.StorageVolume{}, false
+	}
+	v := volumes[0]
+	p.remove(v)
+	p.mu.Unlock()
+
+	select {
+	case <-v.ready:
+	case <-ctx.Done():
+		// the prefetch carries on, and is cleaned up once it is ready
+		p.release(v)
+		return storage.StorageVolume{}, false
+	}
+	if v.err != nil {
+		return storage.StorageVolume{}, false
+	}
+	log.Ctx(ctx).Debug().Str("execution", v.executionID).Msg("Using prefetched input")
+	return v.volume, true
+}
+
+// Release stops prefetching the inputs of the execution, and cleans up the
+// volumes that haven't been handed over once they are ready.
+func (p *Prefetcher) Release(ctx context.Context, executionID string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	if cancel, ok := p.cancels[executionID]; ok {
+		cancel()
+		delete(p.cancels, executionID)
+	}
+	volumes := p.executions[executionID]
+	for _, v := range volumes {
+		p.remove(v)
+	}
+	p.mu.Unlock()
+
+	for _, v := range volumes {
+		p.release(v)
+	}
+	if len(volumes) > 0 {
+		log.Ctx(ctx).Debug().Str("execution", executionID).Int("inputs", len(volumes)).Msg("Released prefetched inputs")
+	}
+}
+
+// release cleans up the volume now if it is ready, or else once it is.
+func (p *Prefetcher) release(v *volume) {
+	p.mu.Lock()
+	v.released = true
+	select {
+	case <-v.ready:
+	default:
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+	if v.err == nil {
+		p.cleanup(v)
+	}
+}
+
+func (p *Prefetcher) cleanup(v *volume) {
+	if err := v.storage.CleanupStorage(context.Background(), v.spec, v.volume); err != nil {
+		log.Warn().Err(err).Str("execution", v.executionID).Msg("Failed to clean up prefetched input")
+	}
+}
+
+// remove stops tracking the volume and frees its share of the budget. The lock
+// must be held.
+func (p *Prefetcher) remove(v *volume) {
+	p.executions[v.executionID] = without(p.executions[v.executionID], v)
+	if len(p.executions[v.executionID]) == 0 {
+		delete(p.executions, v.executionID)
+	}
+	p.bySpec[v.key] = without(p.bySpec[v.key], v)
+	if len(p.bySpec[v.key]) == 0 {
+		delete(p.bySpec, v.key)
+	}
+	p.used -= v.size
+}
+
+func without(volumes []*volume, v *volume) []*volume {
+	for i, other := range volumes {
+		if other == v {
+			return append(volumes[:i:i], volumes[i+1:]...)
+		}
+	}
+	return volumes
+}
+
+// specKey identifies the input of a spec. Maps are formatted in key order, so
+// equal specs have equal keys.
+func specKey(spec model.StorageSpec) string {
+	return fmt.Sprintf("%#v", spec)
+}
@@ -0,0 +1,171 @@
+//go:build unit || !integration
+
+package prefetch
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/c2h5oh/datasize"
+	"github.com/stretchr/testify/require"
+)
+
+// fetchingStorage prepares volumes once it is released, if it is given a
+// release channel, and sizes specs by the length of their CID.
+type fetchingStorage struct {
+	release chan struct{}
+
+	mu       sync.Mutex
+	prepared []string
+	cleaned  []string
+}
+
+func (s *fetchingStorage) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (s *fetchingStorage) HasStorageLocally(context.Context, model.StorageSpec) (bool, error) {
+	return false, nil
+}
+
+func (s *fetchingStorage) GetVolumeSize(_ context.Context, spec model.StorageSpec) (uint64, error) {
+	return uint64(len(spec.CID)), nil
+}
+
+func (s *fetchingStorage) PrepareStorage(ctx context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+	if s.release != nil {
+		<-s.release
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prepared = append(s.prepared, spec.CID)
+	return storage.StorageVolume{Type: storage.StorageVolumeConnectorBind, Source: "/fetched/" + spec.CID, Target: spec.Path}, nil
+}
+
+func (s *fetchingStorage) CleanupStorage(_ context.Context, spec model.StorageSpec, _ storage.StorageVolume) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cleaned = append(s.cleaned, spec.CID)
+	return nil
+}
+
+func (s *fetchingStorage) Upload(context.Context, string) (model.StorageSpec, error) {
+	return model.StorageSpec{}, nil
+}
+
+func (s *fetchingStorage) HealthCheck(context.Context) error {
+	return nil
+}
+
+func (s *fetchingStorage) counts() (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.prepared), len(s.cleaned)
+}
+
+func newTestPrefetcher(budget datasize.ByteSize, delegate *fetchingStorage) (*Prefetcher, storage.StorageProvider) {
+	p := New(Options{Budget: budget})
+	provider := p.Wrap(model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{
+		model.StorageSourceIPFS: delegate,
+	}))
+	return p, provider
+}
+
+func ipfsSpec(cid string) model.StorageSpec {
+	return model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: cid, Path: "/inputs/" + cid}
+}
+
+func prefetched(t *testing.T, p *Prefetcher, executionID string, count int) {
+	require.Eventually(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return len(p.executions[executionID]) == count
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestHandsOverPrefetchedInputs(t *testing.T) {
+	ctx := context.Background()
+	delegate := &fetchingStorage{}
+	p, provider := newTestPrefetcher(100, delegate)
+
+	p.Prefetch(ctx, "e-1", []model.StorageSpec{ipfsSpec("QmData")})
+	prefetched(t, p, "e-1", 1)
+
+	s, err := provider.Get(ctx, model.StorageSourceIPFS)
+	require.NoError(t, err)
+	volume, err := s.PrepareStorage(ctx, ipfsSpec("QmData"))
+	require.NoError(t, err)
+	require.Equal(t, "/fetched/QmData", volume.Source)
+
+	p.Release(ctx, "e-1")
+	prepared, cleaned := delegate.counts()
+	require.Equal(t, 1, prepared)
+	require.Zero(t, cleaned, "handed over volumes belong to the execution")
+	require.Zero(t, p.used)
+}
+
+func TestCleansUpReleasedInputs(t *testing.T) {
+	ctx := context.Background()
+	delegate := &fetchingStorage{}
+	p, _ := newTestPrefetcher(100, delegate)
+
+	p.Prefetch(ctx, "e-1", []model.StorageSpec{ipfsSpec("QmData")})
+	prefetched(t, p, "e-1", 1)
+	require.Eventually(t, func() bool {
+		prepared, _ := delegate.counts()
+		return prepared == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	p.Release(ctx, "e-1")
+	_, cleaned := delegate.counts()
+	require.Equal(t, 1, cleaned)
+	require.Empty(t, p.bySpec)
+}
+
+func TestCleansUpInputsReleasedWhileFetching(t *testing.T) {
+	ctx := context.Background()
+	delegate := &fetchingStorage{release: make(chan struct{})}
+	p, _ := newTestPrefetcher(100, delegate)
+
+	p.Prefetch(ctx, "e-1", []model.StorageSpec{ipfsSpec("QmData")})
+	prefetched(t, p, "e-1", 1)
+	p.Release(ctx, "e-1")
+
+	close(delegate.release)
+	require.Eventually(t, func() bool {
+		_, cleaned := delegate.counts()
+		return cleaned == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPrefetchesWithinBudget(t *testing.T) {
+	ctx := context.Background()
+	delegate := &fetchingStorage{}
+	p, provider := newTestPrefetcher(datasize.ByteSize(len("QmFirst")+len("QmSecond")), delegate)
+
+	p.Prefetch(ctx, "e-1", []model.StorageSpec{ipfsSpec("QmFirst"), ipfsSpec("QmSecond")})
+	prefetched(t, p, "e-1", 2)
+	p.Prefetch(ctx, "e-2", []model.StorageSpec{ipfsSpec("QmThird")})
+	time.Sleep(100 * time.Millisecond)
+	prefetched(t, p, "e-2", 0)
+
+	// inputs that weren't prefetched are prepared when the execution runs
+	s, err := provider.Get(ctx, model.StorageSourceIPFS)
+	require.NoError(t, err)
+	volume, err := s.PrepareStorage(ctx, ipfsSpec("QmThird"))
+	require.NoError(t, err)
+	require.Equal(t, "/fetched/QmThird", volume.Source)
+}
+
+func TestDisabledPrefetching(t *testing.T) {
+	provider := model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{})
+	p := New(Options{})
+	require.Nil(t, p)
+	require.Same(t, provider, p.Wrap(provider))
+	p.Prefetch(context.Background(), "e-1", []model.StorageSpec{ipfsSpec("QmData")})
+	p.Release(context.Background(), "e-1")
+}
@@ -0,0 +1,44 @@
+package prefetch
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+)
+
+type prefetchingProvider struct {
+	delegate   storage.StorageProvider
+	prefetcher *Prefetcher
+}
+
+func (p *prefetchingProvider) Get(ctx context.Context, sourceType model.StorageSourceType) (storage.Storage, error) {
+	s, err := p.delegate.Get(ctx, sourceType)
+	if err != nil {
+		return nil, err
+	}
+	return &prefetchingStorage{Storage: s, prefetcher: p.prefetcher}, nil
+}
+
+func (p *prefetchingProvider) Has(ctx context.Context, sourceType model.StorageSourceType) bool {
+	return p.delegate.Has(ctx, sourceType)
+}
+
+// prefetchingStorage hands over prefetched volumes rather than preparing them
+// again. They are cleaned up by the storage that prefetched them, which is the
+// one it wraps.
+type prefetchingStorage struct {
+	storage.Storage
+	prefetcher *Prefetcher
+}
+
+func (s *prefetchingStorage) PrepareStorage(ctx context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+	if volume, ok := s.prefetcher.claim(ctx, spec); ok {
+		return volume, nil
+	}
+	return s.Storage.PrepareStorage(ctx, spec)
+}
+
+// Compile time interface check:
+var _ storage.StorageProvider = (*prefetchingProvider)(nil)
+var _ storage.Storage = (*prefetchingStorage)(nil)
@@ -0,0 +1,278 @@
+package s3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/bacalhau-project/bacalhau/pkg/config"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultDownloadConcurrency is how many objects are downloaded at once if no
+// other number is configured.
+const DefaultDownloadConcurrency = 8
+
+// StorageOptions configures how the compute node connects to S3. Credentials
+// are found the same way as by the AWS CLI, from the environment, the shared
+// credentials file or the role of the instance.
+type StorageOptions struct {
+	// Region of the buckets, if not set by the environment or shared config
+	Region string
+	// Endpoint to use instead of AWS, for S3 compatible stores such as MinIO
+	Endpoint string
+	// Profile of the shared config and credentials files to use
+	Profile string
+	// DownloadConcurrency is how many objects of an input are downloaded at once
+	DownloadConcurrency int
+}
+
+// StorageProvider downloads the objects under an S3 prefix to a local
+// directory in preparation for a job to run, and removes them once complete.
+type StorageProvider struct {
+	localDir string
+	client   *s3.S3
+	options  StorageOptions
+}
+
+func NewStorage(cm *system.CleanupManager, options StorageOptions) (*StorageProvider, error) {
+	dir, err := os.MkdirTemp(config.GetStoragePath(), "bacalhau-s3")
+	if err != nil {
+		return nil, err
+	}
+
+	cm.RegisterCallback(func() error {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("unable to remove storage folder: %w", err)
+		}
+		return nil
+	})
+
+	log.Debug().Str("dir", dir).Msg("S3 driver created with output dir")
+
+	return newStorage(dir, options)
+}
+
+func newStorage(dir string, options StorageOptions) (*StorageProvider, error) {
+	if options.DownloadConcurrency <= 0 {
+		options.DownloadConcurrency = DefaultDownloadConcurrency
+	}
+
+	awsConfig := aws.Config{}
+	if options.Region != "" {
+		awsConfig.Region = aws.String(options.Region)
+	}
+	if options.Endpoint != "" {
+		// S3 compatible stores rarely support bucket subdomains
+		awsConfig.Endpoint = aws.String(options.Endpoint)
+		awsConfig.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            awsConfig,
+		Profile:           options.Profile,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 session: %w", err)
+	}
+
+	return &StorageProvider{
+		localDir: dir,
+		client:   s3.New(sess),
+		options:  options,
+	}, nil
+}
+
+func (sp *StorageProvider) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (sp *StorageProvider) HasStorageLocally(context.Context, model.StorageSpec) (bool, error) {
+	return false, nil
+}
+
+// GetVolumeSize returns the total size of the objects under the prefix.
+func (sp *StorageProvider) GetVolumeSize(ctx context.Context, storageSpec model.StorageSpec) (uint64, error) {
+	objects, err := sp.listObjects(ctx, storageSpec)
+	if err != nil {
+		return 0, err
+	}
+
+	var size uint64
+	for _, object := range objects {
+		size += uint64(aws.Int64Value(object.Size))
+	}
+	return size, nil
+}
+
+// PrepareStorage downloads the objects under the prefix to a local directory,
+// keeping their keys relative to the last "/" of the prefix as their paths.
+func (sp *StorageProvider) PrepareStorage(ctx context.Context, storageSpec model.StorageSpec) (storage.StorageVolume, error) {
+	bucket, prefix, err := ParseURL(storageSpec.URL)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	objects, err := sp.listObjects(ctx, storageSpec)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	if len(objects) == 0 {
+		return storage.StorageVolume{}, fmt.Errorf("no objects found at %s", storageSpec.URL)
+	}
+
+	outputPath, err := os.MkdirTemp(sp.localDir, "*")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	downloader := s3manager.NewDownloaderWithClient(sp.client)
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(sp.options.DownloadConcurrency)
+	for _, object := range objects {
+		key := aws.StringValue(object.Key)
+		filePath, err := util.ObjectPath(outputPath, prefix, key)
+		if err != nil {
+			return storage.StorageVolume{}, err
+		}
+		group.Go(func() error {
+			return downloadObject(groupCtx, downloader, bucket, key, filePath)
+		})
+	}
+	if err = group.Wait(); err != nil {
+		return storage.StorageVolume{}, fmt.Errorf("failed to download from %s: %w", storageSpec.URL, err)
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("url", storageSpec.URL).
+		Int("objects", len(objects)).
+		Str("dir", outputPath).
+		Msg("Downloaded objects")
+
+	return storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: outputPath,
+		Target: storageSpec.Path,
+	}, nil
+}
+
+// ContentID identifies the objects under the prefix by their keys and ETags,
+// so that they are only downloaded again once some have changed.
+func (sp *StorageProvider) ContentID(ctx context.Context, storageSpec model.StorageSpec) (string, error) {
+	objects, err := sp.listObjects(ctx, storageSpec)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, object := range objects {
+		_, _ = fmt.Fprintf(h, "%q %q\n", aws.StringValue(object.Key), aws.StringValue(object.ETag))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (sp *StorageProvider) CleanupStorage(
+	ctx context.Context,
+	_ model.StorageSpec,
+	volume storage.StorageVolume,
+) error {
+	log.Ctx(ctx).Debug().Str("Path", volume.Source).Msg("Cleaning up")
+	return os.RemoveAll(volume.Source)
+}
+
+func (sp *StorageProvider) Upload(context.Context, string) (model.StorageSpec, error) {
+	return model.StorageSpec{}, fmt.Errorf("not implemented")
+}
+
+// HealthCheck returns an error if the S3 endpoint can't be reached.
+func (sp *StorageProvider) HealthCheck(ctx context.Context) error {
+	return util.CheckReachable(ctx, sp.client.Config.HTTPClient, sp.client.Endpoint)
+}
+
+func (sp *StorageProvider) Explode(_ context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+	return []model.StorageSpec{
+		spec,
+	}, nil
+}
+
+// listObjects returns every object under the prefix, leaving out the empty
+// objects that some tools create to stand for directories.
+func (sp *StorageProvider) listObjects(ctx context.Context, storageSpec model.StorageSpec) ([]*s3.Object, error) {
+	bucket, prefix, err := ParseURL(storageSpec.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []*s3.Object
+	err = sp.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			if !strings.HasSuffix(aws.StringValue(object.Key), "/") {
+				objects = append(objects, object)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects at %s: %w", storageSpec.URL, err)
+	}
+	return objects, nil
+}
+
+func downloadObject(ctx context.Context, downloader *s3manager.Downloader, bucket, key, filePath string) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), util.OS_USER_RWX); err != nil {
+		return err
+	}
+
+	w, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filePath, err)
+	}
+	defer closer.CloseWithLogOnError("file", w)
+
+	// the downloader fetches parts of large objects in parallel too
+	if _, err = downloader.DownloadWithContext(ctx, w, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		return fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	return w.Sync()
+}
+
+// ParseURL returns the bucket and key prefix of an s3://bucket/prefix URL.
+func ParseURL(rawURL string) (bucket, prefix string, err error) {
+	u, err := url.Parse(strings.Trim(rawURL, " '\""))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid URL: %s", err)
+	}
+	if u.Scheme != "s3" {
+		return "", "", fmt.Errorf("S3 URLs must begin with 's3'. The submitted one began with %s", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", "", fmt.Errorf("S3 URL %s has no bucket", rawURL)
+	}
+	return u.Host, strings.TrimPrefix(u.Path, "/"), nil
+}
+
+// Compile time interface checks:
+var _ storage.Storage = (*StorageProvider)(nil)
+var _ storage.ContentIdentifier = (*StorageProvider)(nil)
@@ -0,0 +1,120 @@
+//go:build unit || !integration
+
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURL(t *testing.T) {
+	bucket, prefix, err := ParseURL("s3://bucket/data/2023/")
+	require.NoError(t, err)
+	require.Equal(t, "bucket", bucket)
+	require.Equal(t, "data/2023/", prefix)
+
+	bucket, prefix, err = ParseURL("'s3://bucket'")
+	require.NoError(t, err)
+	require.Equal(t, "bucket", bucket)
+	require.Equal(t, "", prefix)
+
+	_, _, err = ParseURL("https://bucket/data")
+	require.Error(t, err)
+	_, _, err = ParseURL("s3:///data")
+	require.Error(t, err)
+}
+
+func TestPrepareStorage(t *testing.T) {
+	objects := map[string]string{
+		"data/a.csv":           "a,b\n1,2\n",
+		"data/nested/b.csv":    "c\n3\n",
+		"data/nested/":         "",
+		"other/ignored.csv":    "ignored",
+		"data-elsewhere/x.csv": "not under data/",
+	}
+	sp := newTestStorage(t, objects)
+
+	spec := model.StorageSpec{StorageSource: model.StorageSourceS3, URL: "s3://bucket/data/", Path: "/inputs"}
+	size, err := sp.GetVolumeSize(context.Background(), spec)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(objects["data/a.csv"])+len(objects["data/nested/b.csv"])), size)
+
+	volume, err := sp.PrepareStorage(context.Background(), spec)
+	require.NoError(t, err)
+	require.Equal(t, "/inputs", volume.Target)
+
+	contents, err := os.ReadFile(filepath.Join(volume.Source, "a.csv"))
+	require.NoError(t, err)
+	require.Equal(t, objects["data/a.csv"], string(contents))
+	contents, err = os.ReadFile(filepath.Join(volume.Source, "nested", "b.csv"))
+	require.NoError(t, err)
+	require.Equal(t, objects["data/nested/b.csv"], string(contents))
+	require.NoFileExists(t, filepath.Join(volume.Source, "x.csv"))
+
+	require.NoError(t, sp.CleanupStorage(context.Background(), spec, volume))
+	require.NoDirExists(t, volume.Source)
+
+	_, err = sp.PrepareStorage(context.Background(), model.StorageSpec{URL: "s3://bucket/missing/", Path: "/inputs"})
+	require.Error(t, err)
+}
+
+type listBucketResult struct {
+	XMLName     xml.Name `xml:"ListBucketResult"`
+	KeyCount    int
+	IsTruncated bool
+	Contents    []listBucketObject
+}
+
+type listBucketObject struct {
+	Key  string
+	Size int
+}
+
+// newTestStorage returns a storage provider for a fake S3 with the objects in
+// a single bucket, that only supports listing and getting objects.
+func newTestStorage(t *testing.T, objects map[string]string) *StorageProvider {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+		if key == "" || key == "/bucket" {
+			prefix := r.URL.Query().Get("prefix")
+			result := listBucketResult{}
+			for objectKey, contents := range objects {
+				if strings.HasPrefix(objectKey, prefix) {
+					result.Contents = append(result.Contents, listBucketObject{Key: objectKey, Size: len(contents)})
+				}
+			}
+			sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+			result.KeyCount = len(result.Contents)
+			_ = xml.NewEncoder(w).Encode(result)
+			return
+		}
+
+		contents, ok := objects[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		// the downloader asks for ranges, which are always larger than these objects
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(contents)-1, len(contents)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte(contents))
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	sp, err := newStorage(t.TempDir(), StorageOptions{Region: "us-east-1", Endpoint: server.URL})
+	require.NoError(t, err)
+	return sp
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/instrument"
+)
+
+// Metrics for monitoring how inputs are prepared, by their storage source:
+var (
+	meter                   = global.MeterProvider().Meter("storage")
+	prepareStorageFailed, _ = meter.Int64Counter(
+		"storage_prepare_failed",
+		instrument.WithDescription("Number of inputs that failed to be prepared"),
+	)
+
+	prepareStorageDuration, _ = meter.Float64Histogram(
+		"storage_prepare_duration",
+		instrument.WithDescription("Time taken to prepare inputs"),
+		instrument.WithUnit("s"),
+	)
+
+	downloadedBytes, _ = meter.Int64Counter(
+		"storage_downloaded_bytes",
+		instrument.WithDescription("Bytes of inputs downloaded to the node"),
+		instrument.WithUnit("By"),
+	)
+
+	downloadThroughput, _ = meter.Float64Histogram(
+		"storage_download_throughput",
+		instrument.WithDescription("Bytes per second that inputs are downloaded to the node at"),
+		instrument.WithUnit("By/s"),
+	)
+)
+
+// recordPrepareStorage records how long an input took to prepare, and how
+// fast it was downloaded if it wasn't already on the node and was prepared
+// as files. The size is measured on disk rather than asked of the storage, as
+// that can mean another request to the backend.
+func recordPrepareStorage(
+	ctx context.Context, spec model.StorageSpec, local bool, volume storage.StorageVolume, err error, elapsed time.Duration,
+) {
+	source := attribute.String("source", spec.StorageSource.String())
+	if err != nil {
+		prepareStorageFailed.Add(ctx, 1, source)
+		return
+	}
+	prepareStorageDuration.Record(ctx, elapsed.Seconds(), source)
+	if local || volume.Type != storage.StorageVolumeConnectorBind || elapsed <= 0 {
+		return
+	}
+	size, err := util.DirSize(volume.Source)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Str("source", volume.Source).Msg("Failed to measure prepared input")
+		return
+	}
+	downloadedBytes.Add(ctx, int64(size), source)
+	downloadThroughput.Record(ctx, float64(size)/elapsed.Seconds(), source)
+}
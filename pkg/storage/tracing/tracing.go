@@ -3,6 +3,7 @@ package tracing
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
@@ -47,7 +48,12 @@ func (t *tracingStorage) PrepareStorage(ctx context.Context, spec model.StorageS
 	ctx, span := system.NewSpan(ctx, system.GetTracer(), fmt.Sprintf("%s.PrepareStorage", t.name))
 	defer span.End()
 
-	return t.delegate.PrepareStorage(ctx, spec)
+	// inputs that are already on the node aren't downloads, so aren't measured
+	local, _ := t.delegate.HasStorageLocally(ctx, spec)
+	start := time.Now()
+	volume, err := t.delegate.PrepareStorage(ctx, spec)
+	recordPrepareStorage(ctx, spec, local, volume, err, time.Since(start))
+	return volume, err
 }
 
 func (t *tracingStorage) CleanupStorage(ctx context.Context, spec model.StorageSpec, volume storage.StorageVolume) error {
@@ -64,4 +70,11 @@ func (t *tracingStorage) Upload(ctx context.Context, s string) (model.StorageSpe
 	return t.delegate.Upload(ctx, s)
 }
 
+func (t *tracingStorage) HealthCheck(ctx context.Context) error {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), fmt.Sprintf("%s.HealthCheck", t.name))
+	defer span.End()
+
+	return t.delegate.HealthCheck(ctx)
+}
+
 var _ storage.Storage = &tracingStorage{}
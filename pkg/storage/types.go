@@ -25,6 +25,11 @@ type Storage interface {
 
 	// given a local file path - "store" it and return a StorageSpec
 	Upload(context.Context, string) (model.StorageSpec, error)
+
+	// HealthCheck returns an error if the backend that volumes are prepared
+	// from can't currently be used, such as when it can't be reached. Storages
+	// without a backend of their own are always healthy.
+	HealthCheck(context.Context) error
 }
 
 // a storage entity that is consumed are produced by a job
@@ -39,4 +44,16 @@ type StorageVolume struct {
 	Type   StorageVolumeConnectorType `json:"type"`
 	Source string                     `json:"source"`
 	Target string                     `json:"target"`
+	// ReadWrite is whether the job may write to the volume. Inputs are read
+	// only unless the storage allows otherwise.
+	ReadWrite bool `json:"readWrite,omitempty"`
+}
+
+// ContentIdentifier is implemented by storages that can identify the content
+// of a storage spec without a CID, so that it can be cached across executions.
+type ContentIdentifier interface {
+	// ContentID returns an identifier that changes whenever the content of the
+	// storage spec does, or an empty string if the content can't be
+	// identified.
+	ContentID(context.Context, model.StorageSpec) (string, error)
 }
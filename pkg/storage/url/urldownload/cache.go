@@ -0,0 +1,120 @@
+package urldownload
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"go.uber.org/multierr"
+)
+
+// cache keeps downloads that were served with an ETag, so that they are only
+// downloaded again if they have changed since. Downloads are kept by their URL
+// and request headers, so that content served to one set of credentials isn't
+// served to another. Nothing is kept if it has no directory.
+type cache struct {
+	dir string
+}
+
+// cacheEntry is the ETag of a kept download, stored next to its content.
+type cacheEntry struct {
+	ETag string
+}
+
+// key returns the name that the download of the request is kept under.
+func (c cache) key(rawURL string, headers http.Header) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	_, _ = io.WriteString(hash, rawURL)
+	for _, name := range names {
+		for _, value := range headers[name] {
+			_, _ = io.WriteString(hash, "\n"+name+": "+value)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// etag returns the ETag of the kept download, or nothing if it isn't kept.
+func (c cache) etag(key string) string {
+	if c.dir == "" {
+		return ""
+	}
+	contents, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return ""
+	}
+	var entry cacheEntry
+	if err = json.Unmarshal(contents, &entry); err != nil {
+		return ""
+	}
+	if _, err = os.Stat(filepath.Join(c.dir, key)); err != nil {
+		return ""
+	}
+	return entry.ETag
+}
+
+// copyTo copies the kept download to the file.
+func (c cache) copyTo(key, filePath string) error {
+	r, err := os.Open(filepath.Join(c.dir, key))
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError("cached download", r)
+	return writeFile(filePath, r)
+}
+
+// keep stores a copy of the downloaded file with its ETag. Each is written to
+// a temporary file first, so that concurrent downloads of the same URL never
+// see a partly written download.
+func (c cache) keep(key, etag, filePath string) error {
+	if c.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(c.dir, util.OS_USER_RWX); err != nil {
+		return err
+	}
+
+	r, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError("download", r)
+	if err = writeAtomically(filepath.Join(c.dir, key), r); err != nil {
+		return err
+	}
+
+	entry, err := json.Marshal(cacheEntry{ETag: etag})
+	if err != nil {
+		return err
+	}
+	return writeAtomically(filepath.Join(c.dir, key+".json"), bytes.NewReader(entry))
+}
+
+func writeAtomically(filePath string, r io.Reader) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tmp, r)
+	err = multierr.Combine(err, tmp.Close())
+	if err == nil {
+		err = os.Rename(tmp.Name(), filePath)
+	}
+	if err != nil {
+		return multierr.Combine(err, os.Remove(tmp.Name()))
+	}
+	return nil
+}
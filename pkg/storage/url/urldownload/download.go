@@ -0,0 +1,159 @@
+package urldownload
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+)
+
+// rangedDownload writes a file whose server supports range requests, so
+// that it can be downloaded in parallel ranges and resumed from where it was
+// interrupted.
+type rangedDownload struct {
+	client  *retryablehttp.Client
+	url     string
+	header  http.Header
+	file    *os.File
+	retries int
+}
+
+// download writes the body of the response to the file. Files that the server
+// can serve in ranges are downloaded in as many streams as configured if they
+// are at least two chunks long, and each stream picks up from where it was
+// interrupted if reading it fails.
+func (sp *StorageProvider) download(ctx context.Context, req *retryablehttp.Request, res *http.Response, filePath string) error {
+	validator := rangeValidator(res)
+	if validator == "" || res.ContentLength <= 0 || res.Header.Get("Content-Encoding") != "" {
+		return writeFile(filePath, res.Body)
+	}
+
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %s", filePath, err)
+	}
+	defer closer.CloseWithLogOnError("file", f)
+	size := res.ContentLength
+	if err = f.Truncate(size); err != nil {
+		return fmt.Errorf("failed to allocate file %s: %w", filePath, err)
+	}
+
+	header := req.Header.Clone()
+	header.Del("If-None-Match")
+	header.Set("If-Range", validator)
+	d := &rangedDownload{
+		client:  sp.client,
+		url:     res.Request.URL.String(),
+		header:  header,
+		file:    f,
+		retries: sp.client.RetryMax,
+	}
+
+	chunkSize := size
+	if sp.options.Streams > 1 && size >= 2*int64(sp.options.ChunkSize.Bytes()) {
+		chunkSize = int64(sp.options.ChunkSize.Bytes())
+	}
+	log.Ctx(ctx).Debug().
+		Str("url", d.url).
+		Int64("size", size).
+		Int64("chunkSize", chunkSize).
+		Msg("Downloading in ranges")
+
+	// the first range is read from the response that has already begun
+	body := res.Body
+	res.Body = http.NoBody
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(sp.options.Streams)
+	for start := int64(0); start < size; start += chunkSize {
+		start, end, rangeBody := start, min(start+chunkSize, size), body
+		group.Go(func() error {
+			return d.fetch(groupCtx, start, end, rangeBody)
+		})
+		body = nil
+	}
+	if err = group.Wait(); err != nil {
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// fetch writes the range of the file from start up to end, reading it from
+// the body if one is given. If reading fails, the rest of the range is
+// requested again from where it stopped.
+func (d *rangedDownload) fetch(ctx context.Context, start, end int64, body io.ReadCloser) error {
+	for attempt := 0; ; attempt++ {
+		if body == nil {
+			var err error
+			if body, err = d.request(ctx, start, end); err != nil {
+				return err
+			}
+		}
+		n, err := io.Copy(io.NewOffsetWriter(d.file, start), io.LimitReader(body, end-start))
+		closer.CloseWithLogOnError("response", body)
+		body = nil
+		start += n
+		if err == nil && start == end {
+			return nil
+		}
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		if attempt >= d.retries || ctx.Err() != nil {
+			return fmt.Errorf("failed to download %s: %w", d.url, err)
+		}
+		log.Ctx(ctx).Debug().Err(err).Str("url", d.url).Int64("offset", start).Msg("Resuming interrupted download")
+	}
+}
+
+// request begins to download the range of the file from start up to end.
+func (d *rangedDownload) request(ctx context.Context, start, end int64) (io.ReadCloser, error) {
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, d.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = d.header.Clone()
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+
+	res, err := d.client.Do(req) //nolint:bodyclose // closed by the caller, or here if it isn't the range
+	if err != nil {
+		return nil, fmt.Errorf("failed to download range of %s: %w", d.url, err)
+	}
+	if res.StatusCode != http.StatusPartialContent ||
+		!strings.HasPrefix(res.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", start)) {
+		closer.CloseWithLogOnError("response", res.Body)
+		return nil, fmt.Errorf("%s changed while it was downloaded, or stopped serving ranges of it: %s", d.url, res.Status)
+	}
+	return res.Body, nil
+}
+
+// rangeValidator returns what identifies the version of the file served by
+// the response, so that ranges are only served of that version, or an empty
+// string if it can't be downloaded in ranges.
+func rangeValidator(res *http.Response) string {
+	if res.Header.Get("Accept-Ranges") != "bytes" {
+		return ""
+	}
+	// weak ETags can't validate ranges
+	if etag := res.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return res.Header.Get("Last-Modified")
+}
+
+func min(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
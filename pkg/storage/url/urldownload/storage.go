@@ -2,6 +2,7 @@ package urldownload
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,6 +18,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/c2h5oh/datasize"
 	"github.com/google/uuid"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/rs/zerolog"
@@ -26,6 +28,56 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	// HeaderMetadataPrefix starts the keys of the metadata of a storage spec
+	// that are sent as request headers, e.g. "Header-Authorization" with the
+	// value "Bearer <token>". Anyone who can read the job can read them.
+	HeaderMetadataPrefix = "Header-"
+
+	// DefaultRetryWaitMin is how long to wait before the first retry if no
+	// other time is configured. Each retry waits twice as long as the last.
+	DefaultRetryWaitMin = 500 * time.Millisecond
+	// DefaultRetryWaitMax is the longest to wait between retries if no other
+	// time is configured.
+	DefaultRetryWaitMax = 30 * time.Second
+	// DefaultMaxRedirects is how many redirects are followed if no other number
+	// is configured, which is the same as for Go's HTTP client.
+	DefaultMaxRedirects = 10
+	// DefaultStreams is how many ranges of a file are downloaded at once if no
+	// other number is configured.
+	DefaultStreams = 4
+	// DefaultChunkSize is how large the ranges of a file are if no other size
+	// is configured.
+	DefaultChunkSize = 64 * datasize.MB
+)
+
+var errInsecureRedirect = errors.New("refusing to follow redirect from https")
+
+// StorageOptions configures how URLs are downloaded.
+type StorageOptions struct {
+	// RetryMax is how many times a failed download is retried, defaulting to
+	// the configured number of retries. Negative retries none.
+	RetryMax int
+	// RetryWaitMin and RetryWaitMax bound how long to wait between retries,
+	// unless a server asks for longer with Retry-After.
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
+	// MaxRedirects is how many redirects are followed, defaulting to
+	// DefaultMaxRedirects. Negative follows none. Redirects from https to http
+	// are never followed, so that credentials aren't sent in the clear.
+	MaxRedirects int
+	// CacheDir keeps downloads served with an ETag, so that they are only
+	// downloaded again if they have changed.
+	CacheDir string
+	// Streams is how many ranges of a file are downloaded at once, defaulting
+	// to DefaultStreams, if its server supports range requests. Files smaller
+	// than two chunks are downloaded in a single stream.
+	Streams int
+	// ChunkSize is how large the ranges of a file are, defaulting to
+	// DefaultChunkSize.
+	ChunkSize datasize.ByteSize
+}
+
 // a storage driver runs the downloads content
 // from a public URL source and copies it to
 // a local directory in preparation for
@@ -34,9 +86,11 @@ import (
 type StorageProvider struct {
 	localDir string
 	client   *retryablehttp.Client
+	cache    cache
+	options  StorageOptions
 }
 
-func NewStorage(cm *system.CleanupManager) (*StorageProvider, error) {
+func NewStorage(cm *system.CleanupManager, options StorageOptions) (*StorageProvider, error) {
 	// TODO: consolidate the various config inputs into one package otherwise they are scattered across the codebase
 	dir, err := os.MkdirTemp(config.GetStoragePath(), "bacalhau-url")
 	if err != nil {
@@ -52,19 +106,60 @@ func NewStorage(cm *system.CleanupManager) (*StorageProvider, error) {
 
 	log.Debug().Str("dir", dir).Msg("URL download driver created with output dir")
 
-	return newStorage(dir), nil
+	if options.CacheDir == "" {
+		options.CacheDir = filepath.Join(config.GetStoragePath(), "bacalhau-url-cache")
+	}
+
+	return newStorage(dir, options), nil
 }
 
-func newStorage(dir string) *StorageProvider {
+func newStorage(dir string, options StorageOptions) *StorageProvider {
+	if options.RetryMax == 0 {
+		options.RetryMax = config.GetDownloadURLRequestRetries()
+	} else if options.RetryMax < 0 {
+		options.RetryMax = 0
+	}
+	if options.RetryWaitMin == 0 {
+		options.RetryWaitMin = DefaultRetryWaitMin
+	}
+	if options.RetryWaitMax == 0 {
+		options.RetryWaitMax = DefaultRetryWaitMax
+	}
+	if options.MaxRedirects == 0 {
+		options.MaxRedirects = DefaultMaxRedirects
+	}
+	if options.Streams <= 0 {
+		options.Streams = DefaultStreams
+	}
+	if options.ChunkSize == 0 {
+		options.ChunkSize = DefaultChunkSize
+	}
+
 	client := retryablehttp.NewClient()
 	client.HTTPClient = &http.Client{
 		Timeout: config.GetDownloadURLRequestTimeout(),
 		Transport: otelhttp.NewTransport(nil, otelhttp.WithSpanNameFormatter(func(operation string, r *http.Request) string {
 			return fmt.Sprintf("%s %s", r.Method, r.URL.Path)
 		}), otelhttp.WithSpanOptions(trace.WithAttributes(semconv.PeerService("url-download")))),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if options.MaxRedirects < 0 {
+				// the redirect is reported as the response, so fails the download
+				return http.ErrUseLastResponse
+			}
+			if len(via) > options.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", options.MaxRedirects)
+			}
+			if via[len(via)-1].URL.Scheme == "https" && req.URL.Scheme != "https" {
+				return fmt.Errorf("%w to %s", errInsecureRedirect, req.URL.Scheme)
+			}
+			return nil
+		},
 	}
-	client.RetryMax = config.GetDownloadURLRequestRetries()
-	client.RetryWaitMax = time.Second * 1
+	client.RetryMax = options.RetryMax
+	client.RetryWaitMin = options.RetryWaitMin
+	client.RetryWaitMax = options.RetryWaitMax
+	// waits twice as long after each failure, or as long as the server asks
+	client.Backoff = retryablehttp.DefaultBackoff
 	client.Logger = retryLogger{}
 	client.CheckRetry = func(ctx context.Context, resp *http.Response, err error) (bool, error) {
 		if err := ctx.Err(); err != nil {
@@ -79,12 +174,17 @@ func newStorage(dir string) *StorageProvider {
 			return true, nil
 		}
 
+		if errors.Is(err, errInsecureRedirect) {
+			return false, err
+		}
 		return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
 	}
 
 	return &StorageProvider{
 		localDir: dir,
 		client:   client,
+		cache:    cache{dir: options.CacheDir},
+		options:  options,
 	}
 }
 
@@ -117,13 +217,26 @@ func (sp *StorageProvider) PrepareStorage(ctx context.Context, storageSpec model
 	if err != nil {
 		return storage.StorageVolume{}, err
 	}
+	for key, value := range storageSpec.Metadata {
+		if name, ok := strings.CutPrefix(key, HeaderMetadataPrefix); ok {
+			req.Header.Set(name, value)
+		}
+	}
+
+	cacheKey := sp.cache.key(u.String(), req.Header)
+	if etag := sp.cache.etag(cacheKey); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
 	res, err := sp.client.Do(req) //nolint:bodyclose // this is being closed - golangci-lint is wrong again
 	if err != nil {
 		return storage.StorageVolume{}, fmt.Errorf("failed to begin download from url %s: %w", u, err)
 	}
-	defer closer.DrainAndCloseWithLogOnError(ctx, "response", res.Body)
+	// the body is taken over by downloads in ranges
+	defer func() { closer.DrainAndCloseWithLogOnError(ctx, "response", res.Body) }()
 
-	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+	notModified := res.StatusCode == http.StatusNotModified && req.Header.Get("If-None-Match") != ""
+	if !notModified && (res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices) {
 		return storage.StorageVolume{}, fmt.Errorf("non-200 response from URL (%s): %s", storageSpec.URL, res.Status)
 	}
 
@@ -137,20 +250,20 @@ func (sp *StorageProvider) PrepareStorage(ctx context.Context, storageSpec model
 	}
 
 	filePath := filepath.Join(outputPath, fileName)
-	w, err := os.Create(filePath)
-	if err != nil {
-		return storage.StorageVolume{}, fmt.Errorf("failed to create file %s: %s", filePath, err)
-	}
-
-	defer closer.CloseWithLogOnError("file", w)
-
-	// stream the body to the client without fully loading it into memory
-	if _, err := io.Copy(w, res.Body); err != nil {
-		return storage.StorageVolume{}, fmt.Errorf("failed to write to file %s: %s", filePath, err)
-	}
-
-	if err := w.Sync(); err != nil {
-		return storage.StorageVolume{}, fmt.Errorf("failed to sync file %s: %w", filePath, err)
+	if notModified {
+		if err = sp.cache.copyTo(cacheKey, filePath); err != nil {
+			return storage.StorageVolume{}, fmt.Errorf("failed to copy cached download of %s: %w", u, err)
+		}
+	} else {
+		// stream the body to the client without fully loading it into memory
+		if err = sp.download(ctx, req, res, filePath); err != nil {
+			return storage.StorageVolume{}, err
+		}
+		if etag := res.Header.Get("ETag"); etag != "" {
+			if err = sp.cache.keep(cacheKey, etag, filePath); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Stringer("url", u).Msg("Failed to cache download")
+			}
+		}
 	}
 
 	targetPath := filepath.Join(storageSpec.Path, fileName)
@@ -160,6 +273,7 @@ func (sp *StorageProvider) PrepareStorage(ctx context.Context, storageSpec model
 		Stringer("final-url", res.Request.URL).
 		Str("file", filePath).
 		Str("targetFile", targetPath).
+		Bool("cached", notModified).
 		Msg("Downloaded file")
 
 	volume := storage.StorageVolume{
@@ -171,6 +285,25 @@ func (sp *StorageProvider) PrepareStorage(ctx context.Context, storageSpec model
 	return volume, nil
 }
 
+// writeFile writes everything read from the reader to a new file.
+func writeFile(filePath string, r io.Reader) error {
+	w, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %s", filePath, err)
+	}
+
+	defer closer.CloseWithLogOnError("file", w)
+
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("failed to write to file %s: %s", filePath, err)
+	}
+
+	if err := w.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file %s: %w", filePath, err)
+	}
+	return nil
+}
+
 func (sp *StorageProvider) CleanupStorage(
 	ctx context.Context,
 	_ model.StorageSpec,
@@ -186,6 +319,12 @@ func (sp *StorageProvider) Upload(context.Context, string) (model.StorageSpec, e
 	return model.StorageSpec{}, fmt.Errorf("not implemented")
 }
 
+// HealthCheck is always healthy, as each input is downloaded from a server of
+// its own. How downloads are faring is seen from the storage metrics.
+func (sp *StorageProvider) HealthCheck(context.Context) error {
+	return nil
+}
+
 func (sp *StorageProvider) Explode(_ context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
 	// for the url download - explode will always result in a single item
 	// mounted at the path specified in the spec
@@ -9,7 +9,10 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/logger"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
@@ -39,7 +42,7 @@ func (s *StorageSuite) SetupTest() {
 func (s *StorageSuite) TestNewStorageProvider() {
 	cm := system.NewCleanupManager()
 
-	sp, err := NewStorage(cm)
+	sp, err := NewStorage(cm, StorageOptions{})
 	s.Require().NoError(err, "failed to create storage provider")
 
 	// is dir writable?
@@ -54,7 +57,7 @@ func (s *StorageSuite) TestNewStorageProvider() {
 }
 
 func (s *StorageSuite) TestHasStorageLocally() {
-	sp := newStorage(s.T().TempDir())
+	sp := newStorage(s.T().TempDir(), StorageOptions{})
 
 	spec := model.StorageSpec{
 		StorageSource: model.StorageSourceURLDownload,
@@ -212,7 +215,7 @@ func (s *StorageSuite) TestPrepareStorageURL() {
 			}))
 			s.T().Cleanup(ts.Close)
 
-			subject := newStorage(s.T().TempDir())
+			subject := newStorage(s.T().TempDir(), StorageOptions{})
 
 			vol, err := subject.PrepareStorage(context.Background(), model.StorageSpec{
 				URL:  fmt.Sprintf("%s%s", ts.URL, test.requests[0].path),
@@ -239,3 +242,131 @@ func (s *StorageSuite) TestPrepareStorageURL() {
 		})
 	}
 }
+
+func (s *StorageSuite) TestPrepareStorageSendsHeaders() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "not allowed", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("secret"))
+	}))
+	s.T().Cleanup(ts.Close)
+
+	subject := newStorage(s.T().TempDir(), StorageOptions{RetryMax: -1})
+	vol, err := subject.PrepareStorage(context.Background(), model.StorageSpec{
+		URL:      ts.URL + "/file.txt",
+		Path:     "/inputs",
+		Metadata: map[string]string{HeaderMetadataPrefix + "Authorization": "Bearer token"},
+	})
+	s.Require().NoError(err)
+	actualContent, err := os.ReadFile(vol.Source)
+	s.Require().NoError(err)
+	s.Equal("secret", string(actualContent))
+
+	_, err = subject.PrepareStorage(context.Background(), model.StorageSpec{URL: ts.URL + "/file.txt", Path: "/inputs"})
+	s.Require().Error(err)
+}
+
+func (s *StorageSuite) TestPrepareStorageCachesByETag() {
+	downloads := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		_, _ = w.Write([]byte("cached content"))
+	}))
+	s.T().Cleanup(ts.Close)
+
+	subject := newStorage(s.T().TempDir(), StorageOptions{CacheDir: s.T().TempDir()})
+	for i := 0; i < 2; i++ {
+		vol, err := subject.PrepareStorage(context.Background(), model.StorageSpec{URL: ts.URL + "/file.txt", Path: "/inputs"})
+		s.Require().NoError(err)
+		actualContent, err := os.ReadFile(vol.Source)
+		s.Require().NoError(err)
+		s.Equal("cached content", string(actualContent))
+		s.Equal("file.txt", filepath.Base(vol.Source))
+	}
+	s.Equal(1, downloads)
+}
+
+func (s *StorageSuite) TestPrepareStorageRedirectPolicy() {
+	insecure := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("sent in the clear"))
+	}))
+	s.T().Cleanup(insecure.Close)
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, insecure.URL+"/file.txt", http.StatusFound)
+	}))
+	s.T().Cleanup(secure.Close)
+
+	subject := newStorage(s.T().TempDir(), StorageOptions{})
+	subject.client.HTTPClient.Transport = secure.Client().Transport
+	_, err := subject.PrepareStorage(context.Background(), model.StorageSpec{URL: secure.URL + "/file.txt", Path: "/inputs"})
+	s.Require().ErrorIs(err, errInsecureRedirect)
+
+	redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, insecure.URL+"/file.txt", http.StatusFound)
+	}))
+	s.T().Cleanup(redirecting.Close)
+
+	subject = newStorage(s.T().TempDir(), StorageOptions{MaxRedirects: -1})
+	_, err = subject.PrepareStorage(context.Background(), model.StorageSpec{URL: redirecting.URL + "/file.txt", Path: "/inputs"})
+	s.Require().Error(err)
+}
+
+// rangeServer serves the content in ranges, recording the ranges asked for.
+// The first response is cut off after the given number of bytes, if any.
+func (s *StorageSuite) rangeServer(content string, cutOffAfter int) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	var ranges []string
+	first := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		cutOff := first && cutOffAfter > 0
+		first = false
+		mu.Unlock()
+
+		w.Header().Set("ETag", `"v1"`)
+		if cutOff {
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+			_, _ = w.Write([]byte(content[:cutOffAfter]))
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "file.txt", time.Time{}, strings.NewReader(content))
+	}))
+	s.T().Cleanup(ts.Close)
+	return ts, &ranges
+}
+
+func (s *StorageSuite) TestPrepareStorageDownloadsInRanges() {
+	content := strings.Repeat("0123456789", 10)
+	ts, ranges := s.rangeServer(content, 0)
+
+	subject := newStorage(s.T().TempDir(), StorageOptions{Streams: 3, ChunkSize: 30})
+	vol, err := subject.PrepareStorage(context.Background(), model.StorageSpec{URL: ts.URL + "/file.txt", Path: "/inputs"})
+	s.Require().NoError(err)
+	actualContent, err := os.ReadFile(vol.Source)
+	s.Require().NoError(err)
+	s.Equal(content, string(actualContent))
+	s.ElementsMatch([]string{"", "bytes=30-59", "bytes=60-89", "bytes=90-99"}, *ranges)
+}
+
+func (s *StorageSuite) TestPrepareStorageResumesDownloads() {
+	content := strings.Repeat("0123456789", 10)
+	ts, ranges := s.rangeServer(content, 42)
+
+	subject := newStorage(s.T().TempDir(), StorageOptions{RetryWaitMin: time.Millisecond})
+	vol, err := subject.PrepareStorage(context.Background(), model.StorageSpec{URL: ts.URL + "/file.txt", Path: "/inputs"})
+	s.Require().NoError(err)
+	actualContent, err := os.ReadFile(vol.Source)
+	s.Require().NoError(err)
+	s.Equal(content, string(actualContent))
+	s.Equal([]string{"", "bytes=42-99"}, *ranges)
+}
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/ricochet2200/go-disk-usage/du"
+)
+
+// DiskSpace returns the size of the filesystem of the path, and how much of
+// it is free to the user that the node runs as.
+func DiskSpace(path string) (model.DiskSpace, error) {
+	usage := du.NewDiskUsage(path)
+	if usage == nil || usage.Size() == 0 {
+		return model.DiskSpace{}, fmt.Errorf("unable to get disk space for path %s", path)
+	}
+	return model.DiskSpace{
+		Total: usage.Size(),
+		Free:  usage.Available(),
+	}, nil
+}
@@ -0,0 +1,20 @@
+package util
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ObjectPath returns where an object listed under a prefix of an object store
+// is downloaded to in the directory, which is its key relative to the last "/"
+// of the prefix. Keys that would be downloaded outside of the directory are
+// an error.
+func ObjectPath(dir, prefix, key string) (string, error) {
+	relativeKey := strings.TrimPrefix(key, prefix[:strings.LastIndex(prefix, "/")+1])
+	relativePath := filepath.FromSlash(relativeKey)
+	if !filepath.IsLocal(relativePath) {
+		return "", fmt.Errorf("object key %q can't be downloaded as a local file", key)
+	}
+	return filepath.Join(dir, relativePath), nil
+}
@@ -0,0 +1,28 @@
+//go:build unit || !integration
+
+package util
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectPath(t *testing.T) {
+	for _, test := range []struct {
+		prefix, key, path string
+	}{
+		{prefix: "data/", key: "data/a/b.csv", path: "a/b.csv"},
+		{prefix: "data/2023", key: "data/2023-01.csv", path: "2023-01.csv"},
+		{prefix: "data/file.csv", key: "data/file.csv", path: "file.csv"},
+		{prefix: "", key: "file.csv", path: "file.csv"},
+	} {
+		p, err := ObjectPath("/dir", test.prefix, test.key)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join("/dir", test.path), p)
+	}
+
+	_, err := ObjectPath("/dir", "data/", "data/../../etc/passwd")
+	require.Error(t, err)
+}
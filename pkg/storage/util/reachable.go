@@ -0,0 +1,22 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// CheckReachable returns an error if the endpoint doesn't respond to a HEAD
+// request. Any response is enough, as endpoints commonly refuse requests for
+// their root without credentials.
+func CheckReachable(ctx context.Context, client *http.Client, endpoint string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", endpoint, err)
+	}
+	return resp.Body.Close()
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/docker"
+	"github.com/bacalhau-project/bacalhau/pkg/ipfs/car"
 	"github.com/bacalhau-project/bacalhau/pkg/job"
 	"github.com/bacalhau-project/bacalhau/pkg/logger"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
@@ -105,7 +106,7 @@ func (s *lotusNodeSuite) TestLotusNode() {
 
 	dir := s.T().TempDir()
 
-	require.NoError(s.T(), ExtractCar(ctx, imports[0].FilePath, dir))
+	require.NoError(s.T(), car.ExtractCar(ctx, imports[0].FilePath, dir))
 
 	lotusStdout, err := os.ReadFile(filepath.Join(dir, "stdout"))
 	require.NoError(s.T(), err)
@@ -48,7 +48,7 @@ func (suite *IPFSHostStorageSuite) TestIpfsApiCopyFile() {
 		func(ctx context.Context, cm *system.CleanupManager, api ipfs.Client) (
 			storage.Storage, error) {
 
-			return ipfs_storage.NewStorage(cm, api)
+			return ipfs_storage.NewStorage(cm, api, nil)
 		},
 	)
 }
@@ -60,7 +60,7 @@ func (suite *IPFSHostStorageSuite) TestIPFSAPICopyFolder() {
 		func(ctx context.Context, cm *system.CleanupManager, api ipfs.Client) (
 			storage.Storage, error) {
 
-			return ipfs_storage.NewStorage(cm, api)
+			return ipfs_storage.NewStorage(cm, api, nil)
 		},
 	)
 }
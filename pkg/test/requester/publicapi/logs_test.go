@@ -0,0 +1,59 @@
+//go:build unit || !integration
+
+package publicapi
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/logger"
+	"github.com/bacalhau-project/bacalhau/pkg/node"
+	requester_publicapi "github.com/bacalhau-project/bacalhau/pkg/requester/publicapi"
+	testutils "github.com/bacalhau-project/bacalhau/pkg/test/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type LogsSuite struct {
+	suite.Suite
+	node   *node.Node
+	client *requester_publicapi.RequesterAPIClient
+}
+
+func TestLogsSuite(t *testing.T) {
+	suite.Run(t, new(LogsSuite))
+}
+
+// Before each test
+func (s *LogsSuite) SetupTest() {
+	logger.ConfigureTestLogging(s.T())
+	n, client := setupNodeForTest(s.T())
+	s.node = n
+	s.client = client
+}
+
+// After each test
+func (s *LogsSuite) TearDownTest() {
+	s.node.CleanupManager.Cleanup(context.Background())
+}
+
+func (s *LogsSuite) TestLogsOfCompletedJob() {
+	ctx := context.Background()
+	j, err := s.client.Submit(ctx, testutils.MakeGenericJob())
+	s.Require().NoError(err)
+	s.Require().NoError(s.client.GetJobStateResolver().WaitUntilComplete(ctx, j.Metadata.ID))
+
+	// the noop executor records no output, so the stream should end cleanly without any frames
+	reader, err := s.client.Logs(ctx, j.Metadata.ID, false)
+	s.Require().NoError(err)
+	defer reader.Close()
+
+	output, err := io.ReadAll(reader)
+	s.Require().NoError(err)
+	s.Empty(output)
+}
+
+func (s *LogsSuite) TestLogsOfUnknownJob() {
+	_, err := s.client.Logs(context.Background(), "unknown-job-id", false)
+	s.Error(err)
+}
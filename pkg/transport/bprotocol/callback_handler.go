@@ -32,6 +32,7 @@ func NewCallbackHandler(params CallbackHandlerParams) *CallbackHandler {
 
 	handler.host.SetStreamHandler(OnRunComplete, handler.onRunSuccess)
 	handler.host.SetStreamHandler(OnPublishComplete, handler.onPublishSuccess)
+	handler.host.SetStreamHandler(OnPublishPending, handler.onPublishPending)
 	handler.host.SetStreamHandler(OnCancelComplete, handler.onCancelSuccess)
 	handler.host.SetStreamHandler(OnComputeFailure, handler.onComputeFailure)
 	return handler
@@ -46,6 +47,11 @@ func (h *CallbackHandler) onPublishSuccess(stream network.Stream) {
 	handleCallbackStream[compute.PublishResult](ctx, stream, h.callback.OnPublishComplete)
 }
 
+func (h *CallbackHandler) onPublishPending(stream network.Stream) {
+	ctx := logger.ContextWithNodeIDLogger(context.Background(), h.host.ID().String())
+	handleCallbackStream[compute.PublishPendingResult](ctx, stream, h.callback.OnPublishPending)
+}
+
 func (h *CallbackHandler) onCancelSuccess(stream network.Stream) {
 	ctx := logger.ContextWithNodeIDLogger(context.Background(), h.host.ID().String())
 	handleCallbackStream[compute.CancelResult](ctx, stream, h.callback.OnCancelComplete)
@@ -53,6 +53,12 @@ func (p *CallbackProxy) OnPublishComplete(ctx context.Context, result compute.Pu
 	})
 }
 
+func (p *CallbackProxy) OnPublishPending(ctx context.Context, result compute.PublishPendingResult) {
+	proxyCallbackRequest(ctx, p, result.RoutingMetadata, OnPublishPending, result, func(ctx2 context.Context) {
+		p.localCallback.OnPublishPending(ctx2, result)
+	})
+}
+
 func (p *CallbackProxy) OnCancelComplete(ctx context.Context, result compute.CancelResult) {
 	proxyCallbackRequest(ctx, p, result.RoutingMetadata, OnCancelComplete, result, func(ctx2 context.Context) {
 		p.localCallback.OnCancelComplete(ctx2, result)
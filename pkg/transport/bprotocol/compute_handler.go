@@ -3,6 +3,7 @@ package bprotocol
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"reflect"
 
 	"github.com/bacalhau-project/bacalhau/pkg/compute"
@@ -15,6 +16,7 @@ import (
 type ComputeHandlerParams struct {
 	Host            host.Host
 	ComputeEndpoint compute.Endpoint
+	LogStreamer     compute.LogStreamer // optional, execution logs are not served if nil
 }
 
 // ComputeHandler is a handler for compute requests that registers for incoming libp2p requests to Bacalhau compute
@@ -22,12 +24,14 @@ type ComputeHandlerParams struct {
 type ComputeHandler struct {
 	host            host.Host
 	computeEndpoint compute.Endpoint
+	logStreamer     compute.LogStreamer
 }
 
 func NewComputeHandler(params ComputeHandlerParams) *ComputeHandler {
 	handler := &ComputeHandler{
 		host:            params.Host,
 		computeEndpoint: params.ComputeEndpoint,
+		logStreamer:     params.LogStreamer,
 	}
 
 	handler.host.SetStreamHandler(AskForBidProtocolID, handler.onAskForBid)
@@ -36,6 +40,9 @@ func NewComputeHandler(params ComputeHandlerParams) *ComputeHandler {
 	handler.host.SetStreamHandler(ResultAcceptedProtocolID, handler.onResultAccepted)
 	handler.host.SetStreamHandler(ResultRejectedProtocolID, handler.onResultRejected)
 	handler.host.SetStreamHandler(CancelProtocolID, handler.onCancelJob)
+	if handler.logStreamer != nil {
+		handler.host.SetStreamHandler(ExecutionLogsProtocolID, handler.onExecutionLogs)
+	}
 	log.Debug().Msgf("ComputeHandler started on host %s", handler.host.ID().String())
 	return handler
 }
@@ -70,6 +77,56 @@ func (h *ComputeHandler) onCancelJob(stream network.Stream) {
 	handleStream[compute.CancelExecutionRequest, compute.CancelExecutionResponse](ctx, stream, h.computeEndpoint.CancelExecution)
 }
 
+// onExecutionLogs differs from the other handlers as the response is followed by the raw log stream, which is copied
+// to the libp2p stream until either the execution stops producing output or the requester closes the stream.
+//
+//nolint:errcheck
+func (h *ComputeHandler) onExecutionLogs(stream network.Stream) {
+	ctx := logger.ContextWithNodeIDLogger(context.Background(), h.host.ID().String())
+	if err := stream.Scope().SetService(ComputeServiceName); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("error attaching stream to compute service")
+		stream.Reset()
+		return
+	}
+
+	request := new(compute.ExecutionLogsRequest)
+	err := json.NewDecoder(stream).Decode(request)
+	if err != nil {
+		log.Ctx(ctx).Error().Msgf("error decoding %s: %s", reflect.TypeOf(request), err)
+		stream.Reset()
+		return
+	}
+	defer stream.Close()
+
+	// stop streaming if the requester goes away
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	response := compute.ExecutionLogsResponse{
+		ExecutionMetadata: compute.ExecutionMetadata{ExecutionID: request.ExecutionID},
+	}
+	reader, err := h.logStreamer.ExecutionLogs(ctx, *request)
+	if err != nil {
+		response.Err = err.Error()
+	}
+
+	err = json.NewEncoder(stream).Encode(response)
+	if err != nil || reader == nil {
+		if err != nil {
+			log.Ctx(ctx).Error().Msgf("error encoding %s: %s", reflect.TypeOf(response), err)
+			stream.Reset()
+		}
+		return
+	}
+	defer reader.Close()
+
+	_, err = io.Copy(stream, reader)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msgf("stopped streaming logs of execution %s", request.ExecutionID)
+		stream.Reset()
+	}
+}
+
 //nolint:errcheck
 func handleStream[Request any, Response any](
 	ctx context.Context,
@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
 
 	"github.com/bacalhau-project/bacalhau/pkg/compute"
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
 )
@@ -105,6 +107,69 @@ func (p *ComputeProxy) CancelExecution(
 		ctx, p.host, request.TargetPeerID, CancelProtocolID, request)
 }
 
+// ExecutionLogs opens a log stream to the compute node running the execution. Local calls are only possible if the
+// registered local compute endpoint is also a compute.LogStreamer.
+func (p *ComputeProxy) ExecutionLogs(ctx context.Context, request compute.ExecutionLogsRequest) (io.ReadCloser, error) {
+	if request.TargetPeerID == p.host.ID().String() {
+		localStreamer, ok := p.localEndpoint.(compute.LogStreamer)
+		if !ok {
+			return nil, fmt.Errorf("unable to dial to self, unless a local compute endpoint that streams logs is provided")
+		}
+		return localStreamer.ExecutionLogs(ctx, request)
+	}
+
+	peerID, err := peer.Decode(request.TargetPeerID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to decode peer ID %s: %w", reflect.TypeOf(request), request.TargetPeerID, err)
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to marshal request: %w", reflect.TypeOf(request), err)
+	}
+
+	stream, err := p.host.NewStream(ctx, peerID, ExecutionLogsProtocolID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to open stream to peer %s: %w", reflect.TypeOf(request), request.TargetPeerID, err)
+	}
+	if scopingErr := stream.Scope().SetService(ComputeServiceName); scopingErr != nil {
+		stream.Reset() //nolint:errcheck
+		return nil, fmt.Errorf("%s: failed to attach stream to compute service: %w", reflect.TypeOf(request), scopingErr)
+	}
+
+	_, err = stream.Write(data)
+	if err != nil {
+		stream.Reset() //nolint:errcheck
+		return nil, fmt.Errorf("%s: failed to write request to peer %s: %w", reflect.TypeOf(request), request.TargetPeerID, err)
+	}
+
+	var response compute.ExecutionLogsResponse
+	decoder := json.NewDecoder(stream)
+	err = decoder.Decode(&response)
+	if err != nil {
+		stream.Reset() //nolint:errcheck
+		return nil, fmt.Errorf("%s: failed to decode response from peer %s: %w", reflect.TypeOf(request), request.TargetPeerID, err)
+	}
+	if response.Err != "" {
+		stream.Close() //nolint:errcheck
+		return nil, fmt.Errorf("%s: peer %s failed to stream logs: %s", reflect.TypeOf(request), request.TargetPeerID, response.Err)
+	}
+
+	// the decoder may have buffered the start of the log stream along with the response
+	return &logStream{Reader: io.MultiReader(decoder.Buffered(), stream), stream: stream}, nil
+}
+
+// logStream reads the remainder of a libp2p stream after its response header has been decoded.
+type logStream struct {
+	io.Reader
+	stream network.Stream
+}
+
+func (s *logStream) Close() error {
+	// reset rather than close so that the compute node stops streaming immediately
+	return s.stream.Reset()
+}
+
 func proxyRequest[Request any, Response any](
 	ctx context.Context,
 	h host.Host,
@@ -156,3 +221,4 @@ func proxyRequest[Request any, Response any](
 
 // Compile-time interface check:
 var _ compute.Endpoint = (*ComputeProxy)(nil)
+var _ compute.LogStreamer = (*ComputeProxy)(nil)
@@ -56,6 +56,12 @@ func (p *CallbackProxy) OnPublishComplete(ctx context.Context, result compute.Pu
 	})
 }
 
+func (p *CallbackProxy) OnPublishPending(ctx context.Context, result compute.PublishPendingResult) {
+	proxyCallbackRequest(ctx, p, result.RoutingMetadata, bprotocol.OnPublishPending, result, func(ctx2 context.Context) {
+		p.localCallback.OnPublishPending(ctx2, result)
+	})
+}
+
 func (p *CallbackProxy) OnCancelComplete(ctx context.Context, result compute.CancelResult) {
 	proxyCallbackRequest(ctx, p, result.RoutingMetadata, bprotocol.OnCancelComplete, result, func(ctx2 context.Context) {
 		p.localCallback.OnCancelComplete(ctx2, result)
@@ -36,15 +36,18 @@ type DataFrame struct {
 	Data []byte
 }
 
+// NewDataFrameFromReader reads a single data frame from the reader. Frames
+// may arrive split across several reads when the reader is a network stream,
+// so this blocks until the whole frame is available. io.EOF is returned if the
+// reader ends cleanly between frames.
 func NewDataFrameFromReader(reader io.Reader) (DataFrame, error) {
 	header := make([]byte, headerLength)
 
-	n, err := reader.Read(header)
-	if err != nil {
-		return DataFrame{}, err
-	}
-	if n != headerLength {
+	_, err := io.ReadFull(reader, header)
+	if err == io.ErrUnexpectedEOF {
 		return DataFrame{}, fmt.Errorf("unable to read dataframe header")
+	} else if err != nil {
+		return DataFrame{}, err
 	}
 
 	df := DataFrame{}
@@ -52,12 +55,11 @@ func NewDataFrameFromReader(reader io.Reader) (DataFrame, error) {
 	df.Size = int(binary.BigEndian.Uint32(header[4:]))
 	df.Data = make([]byte, df.Size)
 
-	n, err = reader.Read(df.Data)
-	if err != nil {
-		return DataFrame{}, err
-	}
-	if n != df.Size {
+	_, err = io.ReadFull(reader, df.Data)
+	if err == io.ErrUnexpectedEOF || (err == io.EOF && df.Size > 0) {
 		return DataFrame{}, fmt.Errorf("unable to read dataframe data")
+	} else if err != nil {
+		return DataFrame{}, err
 	}
 
 	return df, nil
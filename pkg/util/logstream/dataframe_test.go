@@ -4,7 +4,9 @@ package logstream
 
 import (
 	"bytes"
+	"io"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -34,3 +36,26 @@ func (s *DataFrameTestSuite) TestBasic() {
 	require.Equal(s.T(), original.Size, df.Size)
 	require.Equal(s.T(), original.Data, df.Data)
 }
+
+func (s *DataFrameTestSuite) TestSplitReads() {
+	original := NewDataFrameFromData(StderrStreamTag, []byte("hello world"))
+
+	// network streams can hand us a frame a byte at a time
+	reader := iotest.OneByteReader(bytes.NewReader(original.ToBytes()))
+
+	df, err := NewDataFrameFromReader(reader)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), original, df)
+
+	_, err = NewDataFrameFromReader(reader)
+	require.ErrorIs(s.T(), err, io.EOF)
+}
+
+func (s *DataFrameTestSuite) TestTruncatedFrame() {
+	original := NewDataFrameFromData(StdoutStreamTag, []byte("hello"))
+	data := original.ToBytes()
+
+	_, err := NewDataFrameFromReader(bytes.NewReader(data[:len(data)-1]))
+	require.Error(s.T(), err)
+	require.NotErrorIs(s.T(), err, io.EOF)
+}
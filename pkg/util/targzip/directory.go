@@ -0,0 +1,135 @@
+package targzip
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
+	"github.com/rs/zerolog/log"
+)
+
+const worldReadOwnerWriteFilePermission fs.FileMode = 0644
+
+// CompressDirectory writes the directories and regular files in the directory
+// as a gzipped tar of any size, with names relative to the directory so that
+// it can be decompressed anywhere.
+func CompressDirectory(w io.Writer, dir string) error {
+	zr := gzip.NewWriter(w)
+	if err := TarDirectory(zr, dir); err != nil {
+		return err
+	}
+	return zr.Close()
+}
+
+// TarDirectory writes the directories and regular files in the directory as a
+// tar of any size, like CompressDirectory but leaving compression to the
+// writer.
+func TarDirectory(w io.Writer, dir string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err = tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer closer.CloseWithLogOnError(path, f)
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// DecompressDirectory writes the directories and regular files of a gzipped
+// tar of any size into the directory, refusing names that would be written
+// outside of it.
+func DecompressDirectory(r io.Reader, dir string) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	return UntarDirectory(zr, dir)
+}
+
+// UntarDirectory writes the directories and regular files of a tar into the
+// directory, like DecompressDirectory but leaving decompression to the reader.
+func UntarDirectory(r io.Reader, dir string) error {
+	if err := os.MkdirAll(dir, worldReadOwnerWritePermission); err != nil {
+		return err
+	}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		name := filepath.FromSlash(header.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("tar contained a name outside of its directory: %s", header.Name)
+		}
+		path := filepath.Join(dir, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, worldReadOwnerWritePermission)
+		case tar.TypeReg:
+			err = writeFile(path, tr)
+		default:
+			log.Debug().Msgf("Skipping %s in tar, which isn't a directory or regular file", strings.TrimSuffix(header.Name, "/"))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), worldReadOwnerWritePermission); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, worldReadOwnerWriteFilePermission)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError("file", f)
+	_, err = io.Copy(f, r)
+	return err
+}
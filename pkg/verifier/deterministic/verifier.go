@@ -11,6 +11,13 @@ import (
 	"golang.org/x/mod/sumdb/dirhash"
 )
 
+// MinReplicas is the fewest executions that the verifier compares, as the
+// results of a single execution can't be checked against anything.
+const MinReplicas = 2
+
+// DeterministicVerifier runs a job on several compute nodes and accepts the
+// results that a quorum of them agree on, by comparing the hashes of the
+// results that each node proposes.
 type DeterministicVerifier struct {
 	results   *results.Results
 	encrypter verifier.EncrypterFunction
@@ -70,6 +77,22 @@ func (deterministicVerifier *DeterministicVerifier) GetProposal(
 	return encryptedHash, nil
 }
 
+// Replicas returns how many executions of the job to compare, which is the
+// job's concurrency but at least its confidence and MinReplicas.
+func (deterministicVerifier *DeterministicVerifier) Replicas(job model.Job) int {
+	replicas := system.Max(job.Spec.Deal.Concurrency, job.Spec.Deal.Confidence)
+	return system.Max(replicas, MinReplicas)
+}
+
+// Quorum returns how many executions must produce the same results, which
+// is the job's confidence or else a majority of its executions.
+func (deterministicVerifier *DeterministicVerifier) Quorum(job model.Job) int {
+	if job.Spec.Deal.Confidence > 0 {
+		return job.Spec.Deal.Confidence
+	}
+	return job.Spec.Deal.Concurrency/2 + 1
+}
+
 func (deterministicVerifier *DeterministicVerifier) getHashGroups(
 	ctx context.Context,
 	executionStates []model.ExecutionState,
@@ -122,7 +145,7 @@ func (deterministicVerifier *DeterministicVerifier) Verify(
 	if err != nil {
 		return nil, err
 	}
-	confidence := job.Spec.Deal.Confidence
+	quorum := deterministicVerifier.Quorum(job)
 
 	largestGroupHash := ""
 	largestGroupSize := 0
@@ -149,8 +172,8 @@ func (deterministicVerifier *DeterministicVerifier) Verify(
 	}
 
 	// this means that the winning group size does not
-	// meet the confidence threshold
-	if confidence > 0 && largestGroupSize < confidence {
+	// meet the quorum
+	if largestGroupSize < quorum {
 		isVoidResult = true
 	}
 
@@ -178,3 +201,4 @@ func (deterministicVerifier *DeterministicVerifier) Verify(
 
 // Compile-time check that deterministicVerifier implements the correct interface:
 var _ verifier.Verifier = (*DeterministicVerifier)(nil)
+var _ verifier.Replicator = (*DeterministicVerifier)(nil)
@@ -0,0 +1,77 @@
+//go:build unit || !integration
+
+package deterministic
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicasAndQuorum(t *testing.T) {
+	v := &DeterministicVerifier{}
+	job := func(concurrency, confidence int) model.Job {
+		return model.Job{Spec: model.Spec{Deal: model.Deal{Concurrency: concurrency, Confidence: confidence}}}
+	}
+
+	require.Equal(t, MinReplicas, v.Replicas(job(1, 0)))
+	require.Equal(t, 5, v.Replicas(job(5, 0)))
+	require.Equal(t, 3, v.Replicas(job(2, 3)))
+
+	require.Equal(t, 2, v.Quorum(job(3, 0)))
+	require.Equal(t, 3, v.Quorum(job(4, 0)))
+	require.Equal(t, 4, v.Quorum(job(5, 4)))
+}
+
+func TestVerifyQuorum(t *testing.T) {
+	ctx := context.Background()
+	identity := func(_ context.Context, data []byte, _ []byte) ([]byte, error) { return data, nil }
+	v, err := NewDeterministicVerifier(ctx, system.NewCleanupManager(), identity,
+		func(_ context.Context, data []byte) ([]byte, error) { return data, nil })
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name       string
+		confidence int
+		hashes     []string
+		verified   int
+	}{
+		{name: "all agree", hashes: []string{"a", "a", "a"}, verified: 3},
+		{name: "majority agrees", hashes: []string{"a", "a", "b"}, verified: 2},
+		{name: "no majority", hashes: []string{"a", "a", "b", "c"}, verified: 0},
+		{name: "draw", hashes: []string{"a", "b"}, verified: 0},
+		{name: "below confidence", confidence: 3, hashes: []string{"a", "a", "b"}, verified: 0},
+		{name: "empty hashes", hashes: []string{"", "", "a"}, verified: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			job := model.Job{
+				Metadata: model.Metadata{ID: "job"},
+				Spec:     model.Spec{Deal: model.Deal{Concurrency: len(tc.hashes), Confidence: tc.confidence}},
+			}
+			var executions []model.ExecutionState
+			for i, hash := range tc.hashes {
+				executions = append(executions, model.ExecutionState{
+					JobID:                "job",
+					NodeID:               fmt.Sprintf("node-%d", i),
+					State:                model.ExecutionStateResultProposed,
+					VerificationProposal: []byte(hash),
+				})
+			}
+
+			results, err := v.Verify(ctx, job, executions)
+			require.NoError(t, err)
+			require.Len(t, results, len(tc.hashes))
+			verified := 0
+			for _, result := range results {
+				if result.Verified {
+					verified++
+				}
+			}
+			require.Equal(t, tc.verified, verified)
+		})
+	}
+}
@@ -62,3 +62,19 @@ type Verifier interface {
 		executions []model.ExecutionState,
 	) ([]VerifierResult, error)
 }
+
+// Replicator is implemented by verifiers that verify the results of a job by
+// running it on several compute nodes and comparing what they produce.
+type Replicator interface {
+	// requester node
+	//
+	// Replicas returns how many executions of the job the verifier compares,
+	// which the requester places on that many different compute nodes
+	Replicas(job model.Job) int
+
+	// requester node
+	//
+	// Quorum returns how many of the executions must agree on their results
+	// for the verifier to accept them
+	Quorum(job model.Job) int
+}